@@ -9,6 +9,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -18,26 +19,39 @@ import (
 	"syscall"
 	"time"
 
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
 	"go.uber.org/zap"
 
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
 	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
 	"github.com/fyrsmithlabs/contextd/internal/compression"
 	"github.com/fyrsmithlabs/contextd/internal/config"
+	"github.com/fyrsmithlabs/contextd/internal/conversation"
+	"github.com/fyrsmithlabs/contextd/internal/digest"
+	"github.com/fyrsmithlabs/contextd/internal/diskspace"
 	"github.com/fyrsmithlabs/contextd/internal/embeddings"
 	"github.com/fyrsmithlabs/contextd/internal/folding"
 	"github.com/fyrsmithlabs/contextd/internal/hooks"
 	httpserver "github.com/fyrsmithlabs/contextd/internal/http"
 	"github.com/fyrsmithlabs/contextd/internal/logging"
 	"github.com/fyrsmithlabs/contextd/internal/mcp"
+	"github.com/fyrsmithlabs/contextd/internal/notify"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
 	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
 	"github.com/fyrsmithlabs/contextd/internal/remediation"
 	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
 	"github.com/fyrsmithlabs/contextd/internal/secrets"
 	"github.com/fyrsmithlabs/contextd/internal/services"
 	"github.com/fyrsmithlabs/contextd/internal/telemetry"
 	"github.com/fyrsmithlabs/contextd/internal/tenant"
 	"github.com/fyrsmithlabs/contextd/internal/troubleshoot"
+	"github.com/fyrsmithlabs/contextd/internal/unixsock"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/fyrsmithlabs/contextd/internal/workflows"
 )
 
 // Version information (set at build time via ldflags)
@@ -58,6 +72,196 @@ func (a *foldingScrubberAdapter) Scrub(content string) (string, error) {
 	return result.Scrubbed, nil
 }
 
+// foldingCompressorAdapter adapts compression.Service to folding.ResultCompressor interface.
+type foldingCompressorAdapter struct {
+	svc *compression.Service
+}
+
+// Compress implements folding.ResultCompressor. Extractive compression keeps
+// sentences of the already scrubbed message instead of generating new text.
+func (a *foldingCompressorAdapter) Compress(ctx context.Context, content string, targetTokens int) (string, float64, error) {
+	// Folding estimates four characters per token
+	ratio := float64(len(content)) / float64(targetTokens*4)
+	result, err := a.svc.Compress(ctx, content, compression.AlgorithmExtractive, ratio)
+	if err != nil {
+		return "", 0, err
+	}
+	return result.Content, result.QualityScore, nil
+}
+
+// conversationScrubberAdapter adapts secrets.Scrubber to conversation.Scrubber interface.
+type conversationScrubberAdapter struct {
+	scrubber secrets.Scrubber
+}
+
+// Scrub implements conversation.Scrubber.
+func (a *conversationScrubberAdapter) Scrub(content string) conversation.ScrubResult {
+	return scrubbedContent(a.scrubber.Scrub(content).Scrubbed)
+}
+
+// scrubbedContent implements conversation.ScrubResult.
+type scrubbedContent string
+
+func (s scrubbedContent) GetScrubbed() string { return string(s) }
+
+// conversationRedaction converts the validated conversations config to
+// per-tenant redaction profiles.
+func conversationRedaction(cfg config.ConversationsConfig) conversation.RedactionConfig {
+	// Profiles were validated with the config, so parse errors cannot occur
+	profile, _ := conversation.ParseRedactionProfile(cfg.Redaction)
+	tenants := make(map[string]conversation.RedactionProfile, len(cfg.TenantRedaction))
+	for tenantID, name := range cfg.TenantRedaction {
+		tenants[tenantID], _ = conversation.ParseRedactionProfile(name)
+	}
+	return conversation.RedactionConfig{
+		Profile:         profile,
+		Tenants:         tenants,
+		InternalDomains: cfg.InternalDomains,
+	}
+}
+
+// conversationDomains converts the configured domain vocabulary, empty for
+// the default one.
+func conversationDomains(cfg config.ConversationsConfig) []conversation.Domain {
+	domains := make([]conversation.Domain, len(cfg.Domains))
+	for i, d := range cfg.Domains {
+		domains[i] = conversation.Domain{Name: d.Name, Description: d.Description}
+	}
+	return domains
+}
+
+// artifactLinker links memories and checkpoints to the indexed conversation
+// messages that created them.
+type artifactLinker struct {
+	memories    *reasoningbank.Service
+	checkpoints checkpoint.Service
+}
+
+// LinkArtifact implements conversation.ArtifactLinker. Artifacts are located
+// like the tool calls that created them: memories by project_id, checkpoints
+// by project_path and tenant_id, defaulting to the indexed project's.
+func (l *artifactLinker) LinkArtifact(ctx context.Context, a conversation.Artifact, origin conversation.Origin) error {
+	switch a.Type {
+	case conversation.ArtifactMemory:
+		if l.memories == nil {
+			return errors.New("memories are not available")
+		}
+		return l.memories.SetOrigin(ctx, a.Params["project_id"], a.ID, reasoningbank.ConversationOrigin{
+			SessionID:   origin.SessionID,
+			MessageUUID: origin.MessageUUID,
+			Timestamp:   origin.Timestamp,
+			Excerpt:     origin.Excerpt,
+		})
+	case conversation.ArtifactCheckpoint:
+		if l.checkpoints == nil {
+			return errors.New("checkpoints are not available")
+		}
+		projectPath := a.Params["project_path"]
+		if projectPath == "" {
+			projectPath = origin.ProjectPath
+		}
+		validPath, err := sanitize.ValidateProjectPath(projectPath)
+		if err != nil {
+			return err
+		}
+		tenantID := a.Params["tenant_id"]
+		if tenantID == "" {
+			tenantID = tenant.GetTenantIDForPath(validPath)
+		}
+		if tenantID == "" {
+			tenantID = origin.TenantID
+		}
+		base, err := sanitize.SafeBasename(validPath)
+		if err != nil {
+			return err
+		}
+		projectID := sanitize.Identifier(base)
+		ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{TenantID: tenantID, ProjectID: projectID})
+		return l.checkpoints.LinkConversation(ctx, tenantID, "", projectID, a.ID, checkpoint.ConversationOrigin{
+			SessionID:   origin.SessionID,
+			MessageUUID: origin.MessageUUID,
+			Timestamp:   origin.Timestamp,
+			Excerpt:     origin.Excerpt,
+		})
+	}
+	return fmt.Errorf("unknown artifact type %q", a.Type)
+}
+
+// remediationTaxonomies converts the validated remediations config to
+// category taxonomies by tenant.
+func remediationTaxonomies(cfg config.RemediationsConfig) (map[string]*remediation.Taxonomy, error) {
+	taxonomies := make(map[string]*remediation.Taxonomy, len(cfg.Taxonomies))
+	for tenantID, categories := range cfg.Taxonomies {
+		converted := make([]remediation.Category, 0, len(categories))
+		for _, c := range categories {
+			aliases := make([]remediation.ErrorCategory, 0, len(c.Aliases))
+			for _, alias := range c.Aliases {
+				aliases = append(aliases, remediation.ErrorCategory(alias))
+			}
+			converted = append(converted, remediation.Category{
+				Name:        remediation.ErrorCategory(c.Name),
+				Builtin:     remediation.ErrorCategory(c.Builtin),
+				Description: c.Description,
+				Aliases:     aliases,
+			})
+		}
+		taxonomy, err := remediation.NewTaxonomy(converted)
+		if err != nil {
+			return nil, fmt.Errorf("remediation taxonomy %q: %w", tenantID, err)
+		}
+		taxonomies[tenantID] = taxonomy
+	}
+	return taxonomies, nil
+}
+
+// checkpointStatePath returns the expanded path of the checkpoint state
+// store, defaulting by backend.
+func checkpointStatePath(cfg config.CheckpointConfig) string {
+	path := cfg.StatePath
+	if path == "" {
+		path = "~/.config/contextd/checkpoint-state"
+		if cfg.StateStore == checkpoint.StateStoreSQLite {
+			path += ".db"
+		}
+	}
+	return expandDataPath(path)
+}
+
+// repositoryPreprocessors converts the configured preprocessor rules.
+func repositoryPreprocessors(cfg config.RepositoryConfig) []repository.PreprocessorRule {
+	rules := make([]repository.PreprocessorRule, 0, len(cfg.Preprocessors))
+	for _, p := range cfg.Preprocessors {
+		rules = append(rules, repository.PreprocessorRule{Pattern: p.Pattern, Use: p.Use})
+	}
+	return rules
+}
+
+// searchRanking converts an experiment arm's config to its ranking.
+func searchRanking(cfg config.SearchRankingConfig) reasoningbank.RankingConfig {
+	return reasoningbank.RankingConfig{
+		Rerank:             cfg.Rerank,
+		ConsolidationBoost: float32(cfg.ConsolidationBoost),
+		EntityBoost:        float32(cfg.EntityBoost),
+	}
+}
+
+func searchScoring(cfg config.SearchScoringConfig) reasoningbank.ScoringConfig {
+	scoring := reasoningbank.ScoringConfig{
+		SimilarityWeight:   cfg.SimilarityWeight,
+		ConfidenceWeight:   cfg.ConfidenceWeight,
+		RecencyWeight:      cfg.RecencyWeight,
+		RecencyHalfLife:    cfg.RecencyHalfLife,
+		ConsolidationBoost: cfg.ConsolidationBoost,
+	}
+	if len(cfg.CategoryBoosts) > 0 {
+		scoring.CategoryBoosts = make(map[reasoningbank.MemoryCategory]float64, len(cfg.CategoryBoosts))
+		for category, boost := range cfg.CategoryBoosts {
+			scoring.CategoryBoosts[reasoningbank.MemoryCategory(category)] = boost
+		}
+	}
+	return scoring
+}
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -73,7 +277,11 @@ func run() error {
 	httpHost := flag.String("http-host", "", "HTTP server host (overrides config, default: localhost)")
 	noHTTP := flag.Bool("no-http", false, "disable HTTP server (allows multiple instances)")
 	mcpMode := flag.Bool("mcp", false, "run in MCP mode (stdio transport)")
+	httpSocket := flag.String("http-socket", "", "serve HTTP on this Unix socket instead of a TCP port (overrides config)")
+	mcpSocket := flag.String("mcp-socket", "", "serve MCP sessions on this Unix socket instead of stdio (overrides config)")
 	downloadModels := flag.Bool("download-models", false, "download embedding models and exit (for airgap/container builds)")
+	readOnly := flag.Bool("read-only", false, "start in read-only mode: reject writes, keep search and resume working")
+	profile := flag.String("profile", "", "configuration preset: solo, team or enterprise (overrides config)")
 	flag.Parse()
 
 	if *showVersion {
@@ -94,11 +302,14 @@ func run() error {
 	// Initialize Logging
 	// ============================================================================
 	logCfg := logging.NewDefaultConfig()
+	if err := logCfg.Output.File.LoadEnv(); err != nil {
+		return fmt.Errorf("initializing logger: %w", err)
+	}
 	logger, err := logging.NewLogger(logCfg, nil)
 	if err != nil {
 		return fmt.Errorf("initializing logger: %w", err)
 	}
-	defer func() { _ = logger.Sync() }()
+	defer func() { _ = logger.Close() }()
 
 	logger.Info(ctx, "starting contextd",
 		zap.String("version", version),
@@ -139,6 +350,41 @@ func run() error {
 		}
 	}
 
+	// Apply the configuration profile; settings changed explicitly in the
+	// config file or environment are kept
+	profileName := cfg.Profile
+	if *profile != "" {
+		profileName = *profile
+	}
+	if profileName != "" {
+		changes, err := config.ApplyProfile(cfg, profileName)
+		if err != nil {
+			return fmt.Errorf("applying config profile: %w", err)
+		}
+		// stderr keeps stdout free for the MCP stdio transport
+		fmt.Fprint(os.Stderr, config.FormatProfileChanges(profileName, changes))
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("config invalid with profile %s: %w", profileName, err)
+		}
+	}
+
+	// Category names are only known to reasoningbank, so the scoring config
+	// is checked here rather than by config.Validate
+	scoring := searchScoring(cfg.ReasoningBank.Scoring)
+	if err := scoring.Validate(); err != nil {
+		return fmt.Errorf("invalid reasoningbank scoring: %w", err)
+	}
+
+	// Resolve secret references (env:, file:, keychain:, vault:) so
+	// credentials can stay out of config.yaml
+	secretResolver, err := config.NewSecretResolver()
+	if err != nil {
+		return fmt.Errorf("initializing secret backends: %w", err)
+	}
+	if err := config.ResolveSecrets(ctx, cfg, secretResolver); err != nil {
+		return fmt.Errorf("resolving config secrets: %w", err)
+	}
+
 	// ============================================================================
 	// Initialize Telemetry (using config values)
 	// ============================================================================
@@ -146,6 +392,9 @@ func run() error {
 	telCfg.ServiceName = cfg.Observability.ServiceName
 	telCfg.ServiceVersion = version
 	telCfg.Enabled = cfg.Observability.EnableTelemetry
+	if cfg.Observability.SamplingRate > 0 {
+		telCfg.Sampling.Rate = cfg.Observability.SamplingRate
+	}
 	if cfg.Observability.OTLPEndpoint != "" {
 		telCfg.Endpoint = cfg.Observability.OTLPEndpoint
 	}
@@ -194,11 +443,26 @@ func run() error {
 	}
 	logger.Info(ctx, "secret scrubber initialized")
 
+	// ============================================================================
+	// Initialize Notifications (if enabled in config)
+	// ============================================================================
+	var notifier *notify.Notifier
+	if cfg.Notifications.Enabled {
+		notifier, err = notify.New(cfg.Notifications, logger.Underlying(), notify.WithScrubber(scrubber))
+		if err != nil {
+			return fmt.Errorf("initializing notifications: %w", err)
+		}
+		logger.Info(ctx, "notifications enabled",
+			zap.Int("channels", len(cfg.Notifications.Channels)),
+			zap.Int("routes", len(cfg.Notifications.Routes)))
+	}
+
 	// ============================================================================
 	// Initialize Infrastructure (VectorStore + Embeddings)
 	// ============================================================================
 	var store vectorstore.Store
 	var embeddingProvider embeddings.Provider
+	var embeddingQueue *embeddings.DepthTracker
 
 	// Initialize embeddings provider using config values
 	embeddingCfg := embeddings.ProviderConfig{
@@ -206,6 +470,7 @@ func run() error {
 		Model:    cfg.Embeddings.Model,
 		BaseURL:  cfg.Embeddings.BaseURL,
 		CacheDir: cfg.Embeddings.CacheDir,
+		APIKey:   cfg.Embeddings.APIKey.Value(),
 	}
 	embeddingProvider, err = embeddings.NewProvider(embeddingCfg)
 	if err != nil {
@@ -217,9 +482,14 @@ func run() error {
 	} else if embeddingProvider != nil {
 		defer embeddingProvider.Close()
 
+		// Count queued embedding requests for ctxd top
+		embeddingQueue = embeddings.TrackDepth(embeddingProvider)
+		embeddingProvider = embeddingQueue
+
 		// Get provider dimension and update config
 		providerDim := embeddingProvider.Dimension()
 		cfg.VectorStore.Chromem.VectorSize = providerDim
+		cfg.VectorStore.Pgvector.VectorSize = providerDim
 
 		logger.Info(ctx, "embeddings provider initialized",
 			zap.String("provider", cfg.Embeddings.Provider),
@@ -242,6 +512,61 @@ func run() error {
 		}
 	}
 
+	// Roll up MCP tool usage and LLM spend for GET /api/v1/stats/tools,
+	// GET /api/v1/stats/usage, `ctxd stats tools` and `ctxd usage`, and
+	// journal session calls for `ctxd replay`
+	var toolAnalytics *analytics.Tracker
+	if cfg.Analytics.Enabled {
+		toolAnalytics, err = analytics.NewTracker(analytics.Config{
+			Dir:                  expandDataPath(cfg.Analytics.Dir),
+			FlushInterval:        cfg.Analytics.FlushInterval,
+			RetentionDays:        cfg.Analytics.RetentionDays,
+			SessionRetentionDays: cfg.Analytics.SessionRetentionDays,
+		}, logger.Underlying())
+		if err != nil {
+			logger.Warn(ctx, "tool analytics initialization failed", zap.Error(err))
+		} else {
+			toolAnalytics.Start(ctx)
+		}
+	}
+	var usageRecorder analytics.UsageRecorder
+	if toolAnalytics != nil {
+		usageRecorder = toolAnalytics
+	}
+
+	// Degrade LLM features once a tenant's monthly token budget is used up
+	var llmBudget analytics.LLMBudget
+	if cfg.LLM.BudgetEnabled() {
+		if toolAnalytics == nil {
+			logger.Warn(ctx, "LLM token budget ignored, analytics is disabled")
+		} else if budget, err := analytics.NewBudget(toolAnalytics, analytics.BudgetConfig{
+			MonthlyTokens: cfg.LLM.MonthlyTokenBudget,
+			TenantTokens:  cfg.LLM.TenantTokenBudgets,
+		}, logger.Underlying()); err != nil {
+			logger.Warn(ctx, "LLM token budget initialization failed", zap.Error(err))
+		} else {
+			llmBudget = budget
+			logger.Info(ctx, "LLM token budget enabled",
+				zap.Int64("monthly_tokens", cfg.LLM.MonthlyTokenBudget),
+				zap.Int("tenant_overrides", len(cfg.LLM.TenantTokenBudgets)))
+		}
+	}
+
+	// Time out, retry and circuit-break LLM requests of every feature
+	llmRetries := cfg.LLM.MaxRetries
+	if llmRetries == 0 {
+		llmRetries = -1 // resilience treats 0 as its default
+	}
+	llmPolicy, err := resilience.New(resilience.Config{
+		Timeout:          cfg.LLM.Timeout,
+		MaxRetries:       llmRetries,
+		FailureThreshold: cfg.LLM.CircuitFailureThreshold,
+		Cooldown:         cfg.LLM.CircuitCooldown,
+	}, logger.Underlying())
+	if err != nil {
+		return fmt.Errorf("initializing LLM resilience policy: %w", err)
+	}
+
 	// ============================================================================
 	// Initialize Services
 	// ============================================================================
@@ -255,6 +580,14 @@ func run() error {
 	// TODO: Migrate to StoreProvider for database-per-project isolation
 	if store != nil {
 		checkpointCfg := checkpoint.DefaultServiceConfig()
+		checkpointCfg.BlobStore, err = checkpoint.OpenBlobStore(cfg.Checkpoint.StateStore, checkpointStatePath(cfg.Checkpoint))
+		if err != nil {
+			return fmt.Errorf("checkpoint state store: %w", err)
+		}
+		if checkpointCfg.BlobStore != nil {
+			defer checkpointCfg.BlobStore.Close()
+			logger.Info(ctx, "checkpoint state kept outside the vectorstore", zap.String("store", cfg.Checkpoint.StateStore))
+		}
 		checkpointSvc, err = checkpoint.NewServiceWithStore(checkpointCfg, store, logger.Underlying())
 		if err != nil {
 			logger.Warn(ctx, "checkpoint service initialization failed", zap.Error(err))
@@ -266,10 +599,15 @@ func run() error {
 	// Initialize remediation service
 	if store != nil {
 		remediationCfg := remediation.DefaultServiceConfig()
+		remediationCfg.Taxonomies, err = remediationTaxonomies(cfg.Remediations)
+		if err != nil {
+			return err
+		}
 		remediationSvc, err = remediation.NewService(remediationCfg, store, logger.Underlying())
 		if err != nil {
 			logger.Warn(ctx, "remediation service initialization failed", zap.Error(err))
 		} else {
+			remediationSvc = notify.Remediations(remediationSvc, notifier)
 			logger.Info(ctx, "remediation service initialized")
 		}
 	}
@@ -277,6 +615,9 @@ func run() error {
 	// Initialize repository service (depends on vectorstore)
 	if store != nil {
 		repositorySvc = repository.NewService(store)
+		if err := repositorySvc.SetPreprocessorRules(repositoryPreprocessors(cfg.Repository)); err != nil {
+			return fmt.Errorf("repository preprocessors: %w", err)
+		}
 		logger.Info(ctx, "repository service initialized")
 	}
 
@@ -287,6 +628,12 @@ func run() error {
 		if err != nil {
 			logger.Warn(ctx, "troubleshoot service initialization failed", zap.Error(err))
 		} else {
+			troubleshootSvc.SetUsageRecorder(usageRecorder)
+			troubleshootSvc.SetBudget(llmBudget)
+			troubleshootSvc.SetLLMPolicy(llmPolicy)
+			if repositorySvc != nil {
+				troubleshootSvc.SetCodeSearcher(repositorySvc, scrubber)
+			}
 			logger.Info(ctx, "troubleshoot service initialized")
 		}
 	}
@@ -297,6 +644,8 @@ func run() error {
 		// Build service options
 		rbOpts := []reasoningbank.ServiceOption{
 			reasoningbank.WithDefaultTenant(tenant.GetDefaultTenantID()),
+			reasoningbank.WithScoring(scoring),
+			reasoningbank.WithRecordListener(notify.MemoryListener(notifier)),
 		}
 
 		// Enable session granularity if configured
@@ -308,15 +657,60 @@ func run() error {
 				zap.Int("max_buffered_turns", cfg.ReasoningBank.MaxBufferedTurns))
 		}
 
+		// Reject low-quality memory_record calls
+		if strictness, err := reasoningbank.ParseQualityStrictness(cfg.ReasoningBank.RecordQuality); err != nil {
+			logger.Warn(ctx, "invalid record quality, quality checks disabled", zap.Error(err))
+		} else if strictness != reasoningbank.QualityOff {
+			rbOpts = append(rbOpts, reasoningbank.WithQualityGate(reasoningbank.NewQualityGate(strictness, nil)))
+		}
+
+		// Serve searches under a ranking experiment if configured
+		if exp := cfg.ReasoningBank.Experiment; exp.Enabled() {
+			rbOpts = append(rbOpts, reasoningbank.WithExperiment(&reasoningbank.Experiment{
+				Name:             exp.Name,
+				TreatmentPercent: exp.TreatmentPercent,
+				Control:          searchRanking(exp.Control),
+				Treatment:        searchRanking(exp.Treatment),
+			}))
+			logger.Info(ctx, "search experiment enabled",
+				zap.String("experiment", exp.Name),
+				zap.Int("treatment_percent", exp.TreatmentPercent))
+		}
+
+		// Coalesce feedback and usage updates into periodic writes
+		if cfg.ReasoningBank.WriteBatchInterval > 0 {
+			rbOpts = append(rbOpts, reasoningbank.WithWriteBatching(reasoningbank.WriteBatchConfig{
+				FlushInterval: cfg.ReasoningBank.WriteBatchInterval,
+				MaxPending:    cfg.ReasoningBank.WriteBatchSize,
+			}))
+		}
+
+		// Suppress memories an agent records again and again within minutes
+		if !cfg.ReasoningBank.DisableDuplicateGuard {
+			rbOpts = append(rbOpts,
+				reasoningbank.WithDuplicateGuard(reasoningbank.DuplicateGuardConfig{
+					Window:    cfg.ReasoningBank.DuplicateWindow,
+					Threshold: cfg.ReasoningBank.DuplicateThreshold,
+					BurstSize: cfg.ReasoningBank.DuplicateBurst,
+				}),
+				reasoningbank.WithDuplicateListener(notify.DuplicateListener(notifier)))
+		}
+
 		reasoningbankSvc, err = reasoningbank.NewService(store, logger.Underlying(), rbOpts...)
 		if err != nil {
 			logger.Warn(ctx, "reasoningbank service initialization failed", zap.Error(err))
 		} else {
 			logger.Info(ctx, "reasoningbank service initialized",
-				zap.String("granularity", cfg.ReasoningBank.Granularity))
+				zap.String("granularity", cfg.ReasoningBank.Granularity),
+				zap.Duration("write_batch_interval", cfg.ReasoningBank.WriteBatchInterval))
+			reasoningbankSvc.StartWriteBatching(ctx)
 
 			// Initialize distiller for memory consolidation
-			distillerSvc, err = reasoningbank.NewDistiller(reasoningbankSvc, logger.Underlying())
+			distillerSvc, err = reasoningbank.NewDistiller(reasoningbankSvc, logger.Underlying(),
+				reasoningbank.WithConsolidationListener(notify.ConsolidationListener(notifier)),
+				reasoningbank.WithUsageRecorder(usageRecorder),
+				reasoningbank.WithBudget(llmBudget),
+				reasoningbank.WithLLMPolicy(llmPolicy))
 			if err != nil {
 				logger.Warn(ctx, "distiller initialization failed", zap.Error(err))
 			} else {
@@ -325,6 +719,47 @@ func run() error {
 		}
 	}
 
+	// Initialize conversation service (conversation indexing and search)
+	var conversationSvc *conversation.Service
+	if store != nil {
+		conversationSvc = conversation.NewService(store, &conversationScrubberAdapter{scrubber: scrubber},
+			logger.Underlying(), conversation.ServiceConfig{Redaction: conversationRedaction(cfg.Conversations)})
+		conversationSvc.SetArtifactLinker(&artifactLinker{memories: reasoningbankSvc, checkpoints: checkpointSvc})
+		if embeddingProvider != nil && !strings.EqualFold(cfg.Conversations.DomainClassifier, "off") {
+			classifier, err := conversation.NewEmbeddingClassifier(embeddingProvider,
+				conversationDomains(cfg.Conversations), cfg.Conversations.DomainThreshold)
+			if err != nil {
+				logger.Warn(ctx, "conversation domain classifier initialization failed", zap.Error(err))
+			} else {
+				conversationSvc.SetDomainClassifier(classifier)
+			}
+		}
+		logger.Info(ctx, "conversation service initialized",
+			zap.String("redaction", cfg.Conversations.Redaction),
+			zap.Int("tenant_overrides", len(cfg.Conversations.TenantRedaction)))
+	}
+
+	// Initialize compression service
+	var compressionSvc *compression.Service
+	{
+		compressionCfg := compression.Config{
+			DefaultAlgorithm:  compression.AlgorithmHybrid,
+			TargetRatio:       2.0,
+			QualityThreshold:  0.7,
+			MaxProcessingTime: 30 * time.Second,
+			AnthropicAPIKey:   cfg.LLM.AnthropicAPIKey.Value(),
+			Usage:             usageRecorder,
+			Budget:            llmBudget,
+			LLMPolicy:         llmPolicy,
+		}
+		compressionSvc, err = compression.NewService(compressionCfg)
+		if err != nil {
+			logger.Warn(ctx, "compression service initialization failed", zap.Error(err))
+		} else {
+			logger.Info(ctx, "compression service initialized")
+		}
+	}
+
 	// Initialize folding service (context-folding for branch/return)
 	var foldingSvc *folding.BranchManager
 	{
@@ -334,42 +769,34 @@ func run() error {
 		foldingRepo := folding.NewMemoryBranchRepository()
 		foldingScrubber := &foldingScrubberAdapter{scrubber: scrubber}
 		foldingConfig := folding.DefaultFoldingConfig()
+		foldingConfig.ReturnTargetTokens = cfg.Folding.ReturnTargetTokens
 
 		// Create the branch manager with OTEL metrics
 		foldingMetrics, _ := folding.NewMetrics(nil) // uses global meter provider
 		foldingLogger := folding.NewLogger(logger.Underlying())
+		foldingOpts := []folding.BranchManagerOption{
+			folding.WithMetrics(foldingMetrics),
+			folding.WithLogger(foldingLogger),
+		}
+		// Without a compressor, oversized return messages are truncated
+		if compressionSvc != nil {
+			foldingOpts = append(foldingOpts, folding.WithResultCompressor(&foldingCompressorAdapter{svc: compressionSvc}))
+		}
 		foldingSvc = folding.NewBranchManager(
 			foldingRepo,
 			foldingBudget,
 			foldingScrubber,
 			foldingEmitter,
 			foldingConfig,
-			folding.WithMetrics(foldingMetrics),
-			folding.WithLogger(foldingLogger),
+			foldingOpts...,
 		)
 		logger.Info(ctx, "folding service initialized",
 			zap.Int("max_depth", foldingConfig.MaxDepth),
 			zap.Int("default_budget", foldingConfig.DefaultBudget),
+			zap.Int("return_target_tokens", foldingConfig.ReturnTargetTokens),
 		)
 	}
 
-	// Initialize compression service
-	var compressionSvc *compression.Service
-	{
-		compressionCfg := compression.Config{
-			DefaultAlgorithm:  compression.AlgorithmHybrid,
-			TargetRatio:       2.0,
-			QualityThreshold:  0.7,
-			MaxProcessingTime: 30 * time.Second,
-		}
-		compressionSvc, err = compression.NewService(compressionCfg)
-		if err != nil {
-			logger.Warn(ctx, "compression service initialization failed", zap.Error(err))
-		} else {
-			logger.Info(ctx, "compression service initialized")
-		}
-	}
-
 	// Initialize hooks manager
 	hooksCfg := &hooks.Config{
 		AutoCheckpointOnClear: true,
@@ -383,22 +810,47 @@ func run() error {
 
 	// Create services registry
 	registry := services.NewRegistry(services.Options{
-		Checkpoint:   checkpointSvc,
-		Remediation:  remediationSvc,
-		Memory:       reasoningbankSvc,
-		Repository:   repositorySvc,
-		Troubleshoot: troubleshootSvc,
-		Hooks:        hooksMgr,
-		Distiller:    distillerSvc,
-		Scrubber:     scrubber,
-		Compression:  compressionSvc,
-		VectorStore:  store,
+		Checkpoint:    checkpointSvc,
+		Remediation:   remediationSvc,
+		Memory:        reasoningbankSvc,
+		Repository:    repositorySvc,
+		Troubleshoot:  troubleshootSvc,
+		Hooks:         hooksMgr,
+		Distiller:     distillerSvc,
+		Scrubber:      scrubber,
+		Compression:   compressionSvc,
+		Conversations: conversationSvc,
+		VectorStore:   store,
 	})
 	logger.Info(ctx, "services registry initialized")
 
 	// ============================================================================
 	// Initialize Consolidation Scheduler (if enabled in config)
 	// ============================================================================
+	readOnlyMode := readonly.New(cfg.Server.ReadOnly || *readOnly, cfg.Server.ReadOnlyReason)
+	if readOnlyMode.Enabled() {
+		logger.Warn(ctx, "starting in read-only mode, write operations are disabled",
+			zap.String("reason", cfg.Server.ReadOnlyReason))
+	}
+
+	// Block writes before the disk fills; a write failing mid-encode corrupts
+	// chromem's gob files. Qdrant manages its own storage.
+	var diskMonitor *diskspace.Monitor
+	if cfg.StorageMonitor.Enabled && cfg.VectorStore.Provider == "chromem" && cfg.VectorStore.Chromem.Path != "" {
+		diskMonitor, err = diskspace.NewMonitor(diskspace.Config{
+			Path:            expandDataPath(cfg.VectorStore.Chromem.Path),
+			Interval:        cfg.StorageMonitor.Interval,
+			WarnPercent:     cfg.StorageMonitor.WarnPercent,
+			CriticalPercent: cfg.StorageMonitor.CriticalPercent,
+			MinFreeBytes:    uint64(cfg.StorageMonitor.MinFreeMB) << 20,
+		}, readOnlyMode, logger.Underlying())
+		if err != nil {
+			logger.Warn(ctx, "disk space monitor initialization failed", zap.Error(err))
+		} else {
+			diskMonitor.Start(ctx)
+		}
+	}
+
 	var consolidationScheduler *reasoningbank.ConsolidationScheduler
 	if cfg.ConsolidationScheduler.Enabled && distillerSvc != nil {
 		// Create consolidation options from config
@@ -415,6 +867,7 @@ func run() error {
 			logger.Underlying(),
 			reasoningbank.WithInterval(cfg.ConsolidationScheduler.Interval),
 			reasoningbank.WithConsolidationOptions(consolidationOpts),
+			reasoningbank.WithReadOnly(readOnlyMode),
 			// Note: WithProjectIDs should be configured in config file or via MCP
 		)
 		if err != nil {
@@ -436,6 +889,190 @@ func run() error {
 		logger.Warn(ctx, "consolidation scheduler enabled but distiller not available")
 	}
 
+	// ============================================================================
+	// Initialize Memory Pruning Scheduler (if enabled in config)
+	// ============================================================================
+	var pruningScheduler *reasoningbank.PruningScheduler
+	if cfg.MemoryPruning.Enabled && reasoningbankSvc != nil {
+		pruningPolicy := reasoningbank.PruningPolicy{
+			MaxMemories:   cfg.MemoryPruning.MaxMemories,
+			MinConfidence: cfg.MemoryPruning.MinConfidence,
+			MaxUnusedAge:  cfg.MemoryPruning.MaxUnusedAge,
+			Action:        reasoningbank.PruneAction(cfg.MemoryPruning.Action),
+			DryRun:        cfg.MemoryPruning.DryRun,
+		}
+
+		pruningScheduler, err = reasoningbank.NewPruningScheduler(
+			reasoningbankSvc,
+			pruningPolicy,
+			cfg.MemoryPruning.Interval,
+			cfg.MemoryPruning.ProjectIDs,
+			logger.Underlying(),
+		)
+		if err != nil {
+			logger.Warn(ctx, "pruning scheduler initialization failed", zap.Error(err))
+		} else {
+			pruningScheduler.SetReadOnly(readOnlyMode)
+			if err := pruningScheduler.Start(); err != nil {
+				logger.Warn(ctx, "failed to start pruning scheduler", zap.Error(err))
+			}
+		}
+	} else if cfg.MemoryPruning.Enabled {
+		logger.Warn(ctx, "memory pruning enabled but memory service not available")
+	}
+
+	// ============================================================================
+	// Initialize Knowledge Digest Scheduler (if enabled in config)
+	// ============================================================================
+	var digestScheduler *digest.Scheduler
+	if cfg.Digest.Enabled && reasoningbankSvc != nil {
+		digestOpts := []digest.Option{
+			digest.WithUsageRecorder(usageRecorder),
+			digest.WithBudget(llmBudget),
+			digest.WithLLMPolicy(llmPolicy),
+			digest.WithScrubber(scrubber),
+		}
+		if cfg.LLM.AnthropicAPIKey.IsSet() {
+			if llm, err := compression.NewClaudeClient(cfg.LLM.AnthropicAPIKey.Value(), "", ""); err != nil {
+				logger.Warn(ctx, "digest LLM client initialization failed, digests will be listings", zap.Error(err))
+			} else {
+				digestOpts = append(digestOpts, digest.WithLLMClient(llm))
+			}
+		}
+		var digestRemediations digest.RemediationSource
+		if remediationSvc != nil {
+			digestRemediations = remediationSvc
+		}
+		digestTenant := cfg.Digest.TenantID
+		if digestTenant == "" {
+			digestTenant = tenant.GetDefaultTenantID()
+		}
+		var digestDir string
+		if cfg.Digest.Dir != "" {
+			digestDir = expandDataPath(cfg.Digest.Dir)
+		}
+		var digestListener digest.Listener
+		if notifier != nil {
+			digestListener = notify.DigestListener(notifier)
+		}
+
+		digestGenerator, err := digest.NewGenerator(reasoningbankSvc, digestRemediations, logger.Underlying(), digestOpts...)
+		if err == nil {
+			digestScheduler, err = digest.NewScheduler(digestGenerator, digest.Options{
+				TenantID:      digestTenant,
+				TeamID:        cfg.Digest.TeamID,
+				ProjectIDs:    cfg.Digest.ProjectIDs,
+				MinConfidence: cfg.Digest.MinConfidence,
+			}, cfg.Digest.Interval, digestDir, digestListener, logger.Underlying())
+		}
+		if err != nil {
+			logger.Warn(ctx, "digest scheduler initialization failed", zap.Error(err))
+		} else if err := digestScheduler.Start(); err != nil {
+			logger.Warn(ctx, "failed to start digest scheduler", zap.Error(err))
+		}
+	} else if cfg.Digest.Enabled {
+		logger.Warn(ctx, "knowledge digest enabled but memory service not available")
+	}
+
+	// ============================================================================
+	// Initialize Temporal Workflow Worker (if enabled in config)
+	// ============================================================================
+	var temporalClient client.Client
+	var workflowWorker worker.Worker
+	if cfg.Workflows.Enabled {
+		dialCtx, dialCancel := context.WithTimeout(ctx, 10*time.Second)
+		temporalClient, err = client.DialContext(dialCtx, client.Options{
+			HostPort:  cfg.Workflows.TemporalHost,
+			Namespace: cfg.Workflows.Namespace,
+			Logger:    workflows.ZapLogger(logger.Underlying()),
+		})
+		dialCancel()
+		if err != nil {
+			logger.Warn(ctx, "temporal connection failed, workflows disabled",
+				zap.String("host", cfg.Workflows.TemporalHost),
+				zap.Error(err))
+			temporalClient = nil
+		} else {
+			workflowWorker = worker.New(temporalClient, cfg.Workflows.TaskQueue, worker.Options{})
+
+			var indexing *workflows.IndexingActivities
+			if repositorySvc != nil {
+				indexing = &workflows.IndexingActivities{Repository: repositorySvc}
+			}
+			var consolidation *workflows.ConsolidationActivities
+			if distillerSvc != nil {
+				consolidation = &workflows.ConsolidationActivities{Distiller: distillerSvc}
+			}
+			workflows.RegisterKnowledgeWorkflows(workflowWorker, indexing, consolidation)
+
+			// GitHub workflows started by the webhook server
+			if cfg.Workflows.GitHubToken.IsSet() {
+				gh, err := workflows.NewGitHubClient(ctx, cfg.Workflows.GitHubToken)
+				if err != nil {
+					logger.Warn(ctx, "GitHub workflows disabled", zap.Error(err))
+				} else {
+					// Draft remediations from CI failures
+					if troubleshootSvc != nil && remediationSvc != nil {
+						workflows.RegisterCIRemediationWorkflow(workflowWorker, &workflows.CIRemediationActivities{
+							GitHub:       gh,
+							Troubleshoot: troubleshootSvc,
+							Remediation:  remediationSvc,
+							Scrubber:     scrubber,
+						})
+						logger.Info(ctx, "CI remediation workflow registered")
+					}
+
+					// Comment past context on new pull requests
+					if remediationSvc != nil || reasoningbankSvc != nil {
+						prContext := &workflows.PRContextActivities{GitHub: gh, Remediation: remediationSvc}
+						if reasoningbankSvc != nil {
+							prContext.Memory = reasoningbankSvc
+						}
+						workflows.RegisterPRContextWorkflow(workflowWorker, prContext)
+						logger.Info(ctx, "PR context workflow registered")
+					}
+				}
+			}
+
+			if err := workflowWorker.Start(); err != nil {
+				logger.Warn(ctx, "failed to start workflow worker", zap.Error(err))
+				workflowWorker = nil
+				temporalClient.Close()
+				temporalClient = nil
+			} else {
+				logger.Info(ctx, "workflow worker started",
+					zap.String("host", cfg.Workflows.TemporalHost),
+					zap.String("namespace", cfg.Workflows.Namespace),
+					zap.String("task_queue", cfg.Workflows.TaskQueue))
+			}
+		}
+	}
+
+	// Unix sockets (HTTP and MCP) share one file mode; validated with the config
+	socketMode, err := cfg.Server.SocketFileMode()
+	if err != nil {
+		return err
+	}
+
+	// Optional features reported by /api/v1/capabilities and the
+	// contextd_capabilities tool; the auth mode is set with the HTTP server
+	features := capabilities.Features{
+		Analytics:     toolAnalytics != nil,
+		Conversations: conversationSvc != nil,
+		Folding:       foldingSvc != nil,
+		Consolidation: distillerSvc != nil,
+		Workflows:     temporalClient != nil,
+	}
+	if store != nil {
+		features.VectorStore = cfg.VectorStore.Provider
+	}
+	if embeddingProvider != nil {
+		features.Embeddings = cfg.Embeddings.Provider
+	}
+	if cfg.LLM.AnthropicAPIKey.IsSet() {
+		features.LLMProviders = append(features.LLMProviders, "anthropic")
+	}
+
 	// ============================================================================
 	// Initialize HTTP Server (unless --no-http)
 	// ============================================================================
@@ -443,6 +1080,7 @@ func run() error {
 	var httpErrChan chan error
 	var httpServerHost string
 	var httpServerPort int
+	var httpServerSocket string
 	var bgScanner *vectorstore.BackgroundScanner
 
 	if !*noHTTP {
@@ -457,17 +1095,15 @@ func run() error {
 			httpServerPort = *httpPort
 		}
 
+		httpServerSocket = cfg.Server.SocketPath
+		if *httpSocket != "" {
+			httpServerSocket = *httpSocket
+		}
+
 		// Create metadata health checker for vectorstore monitoring
 		var healthChecker *vectorstore.MetadataHealthChecker
 		if cfg.VectorStore.Provider == "chromem" && cfg.VectorStore.Chromem.Path != "" {
-			// Expand the path (handles ~ for home directory)
-			expandedPath := os.ExpandEnv(cfg.VectorStore.Chromem.Path)
-			if strings.HasPrefix(expandedPath, "~/") {
-				home, err := os.UserHomeDir()
-				if err == nil {
-					expandedPath = filepath.Join(home, expandedPath[2:])
-				}
-			}
+			expandedPath := expandDataPath(cfg.VectorStore.Chromem.Path)
 			healthChecker = vectorstore.NewMetadataHealthChecker(expandedPath, logger.Underlying())
 			logger.Info(ctx, "metadata health checker initialized",
 				zap.String("path", expandedPath))
@@ -490,11 +1126,62 @@ func run() error {
 			}
 		}
 
+		// Create embedding drift checker. The first check runs in the
+		// background so the drift gauge is populated without delaying startup.
+		var driftChecker *vectorstore.DriftChecker
+		if store != nil && embeddingProvider != nil {
+			dc, err := vectorstore.NewDriftChecker(store, embeddingProvider, vectorstore.DriftConfig{}, logger.Underlying())
+			if err != nil {
+				logger.Warn(ctx, "embedding drift checker unavailable", zap.Error(err))
+			} else {
+				driftChecker = dc
+				go func() {
+					if _, err := dc.Check(ctx); err != nil {
+						logger.Warn(ctx, "startup embedding drift check failed", zap.Error(err))
+					}
+				}()
+			}
+		}
+
+		// Create integrity verifier for ctxd verify
+		var verifier *vectorstore.Verifier
+		if store != nil {
+			v, err := vectorstore.NewVerifier(store, vectorstore.VerifyConfig{}, logger.Underlying())
+			if err != nil {
+				logger.Warn(ctx, "vectorstore verifier unavailable", zap.Error(err))
+			} else {
+				verifier = v
+			}
+		}
+
 		httpCfg := &httpserver.Config{
 			Host:          httpServerHost,
 			Port:          httpServerPort,
+			SocketPath:    httpServerSocket,
+			SocketMode:    socketMode,
 			Version:       version,
 			HealthChecker: healthChecker,
+			DriftChecker:  driftChecker,
+			Verifier:      verifier,
+			ReadOnly:      readOnlyMode,
+			Analytics:     toolAnalytics,
+			AdminToken:    cfg.Server.AdminToken.Value(),
+		}
+		features.AuthMode = capabilities.AuthLocalhost
+		if httpServerSocket != "" {
+			features.AuthMode = capabilities.AuthUnixSocket
+		}
+		features.AdminToken = cfg.Server.AdminToken.Value() != ""
+		httpCfg.Features = features
+		if temporalClient != nil {
+			httpCfg.Workflows = temporalClient
+			httpCfg.WorkflowTaskQueue = cfg.Workflows.TaskQueue
+		}
+		if foldingSvc != nil {
+			httpCfg.Branches = foldingSvc
+		}
+		if embeddingQueue != nil {
+			httpCfg.EmbeddingQueue = embeddingQueue
 		}
 
 		var err error
@@ -505,6 +1192,7 @@ func run() error {
 		logger.Info(ctx, "HTTP server initialized",
 			zap.String("host", httpServerHost),
 			zap.Int("port", httpServerPort),
+			zap.String("socket", httpServerSocket),
 		)
 
 		// Start background health scanner if health checker is available
@@ -543,7 +1231,15 @@ func run() error {
 	// ============================================================================
 	var mcpServer *mcp.Server
 	var mcpErrChan chan error
-	if *mcpMode {
+	// MCP sessions outlive the shutdown signal so Drain can let running
+	// calls finish; mcpCancel closes them afterwards.
+	mcpCtx, mcpCancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer mcpCancel()
+	mcpSocketPath := cfg.Server.MCPSocketPath
+	if *mcpSocket != "" {
+		mcpSocketPath = *mcpSocket
+	}
+	if *mcpMode || mcpSocketPath != "" {
 		// MCP mode requires all services
 		if checkpointSvc == nil || remediationSvc == nil || repositorySvc == nil ||
 			troubleshootSvc == nil || reasoningbankSvc == nil {
@@ -558,9 +1254,27 @@ func run() error {
 		}
 
 		mcpCfg := &mcp.Config{
-			Name:    "contextd-v2",
-			Version: version,
-			Logger:  logger.Underlying(),
+			Name:      "contextd-v2",
+			Version:   version,
+			Logger:    logger.Underlying(),
+			ReadOnly:  readOnlyMode,
+			Analytics: toolAnalytics,
+			Notifier:  notifier,
+			UserID:    tenant.GetDefaultUserID(),
+			TeamID:    tenant.GetDefaultTeamID(),
+
+			LogToolCalls:       cfg.Observability.ToolCallLog,
+			ToolCallSampleRate: cfg.Observability.ToolCallSampleRate,
+
+			Features: features,
+
+			DisableToolAliases: cfg.Server.DisableToolAliases,
+			RetiredToolAliases: cfg.Server.RetiredToolAliases,
+
+			SessionIdleTimeout: cfg.Server.MCPSessionIdleTimeout,
+		}
+		if conversationSvc != nil {
+			mcpCfg.Conversations = conversationSvc
 		}
 
 		mcpServer, err = mcp.NewServer(
@@ -579,16 +1293,35 @@ func run() error {
 		}
 		defer mcpServer.Close()
 
-		logger.Info(ctx, "MCP server initialized, starting stdio transport")
+		// List the MCP tools in /api/v1/capabilities
+		if httpSrv != nil {
+			httpSrv.SetToolCatalog(mcpServer)
+		}
 
 		// Run MCP server in background goroutine (no longer blocks)
 		mcpErrChan = make(chan error, 1)
-		go func() {
-			if err := mcpServer.Run(ctx); err != nil {
-				mcpErrChan <- fmt.Errorf("MCP server error: %w", err)
+		if mcpSocketPath != "" {
+			ln, err := unixsock.Listen(mcpSocketPath, socketMode)
+			if err != nil {
+				return fmt.Errorf("listening for MCP sessions: %w", err)
 			}
-			close(mcpErrChan)
-		}()
+			logger.Info(ctx, "MCP server initialized, serving sessions on unix socket",
+				zap.String("socket", mcpSocketPath))
+			go func() {
+				if err := mcpServer.Serve(mcpCtx, ln); err != nil {
+					mcpErrChan <- fmt.Errorf("MCP server error: %w", err)
+				}
+				close(mcpErrChan)
+			}()
+		} else {
+			logger.Info(ctx, "MCP server initialized, starting stdio transport")
+			go func() {
+				if err := mcpServer.Run(mcpCtx); err != nil {
+					mcpErrChan <- fmt.Errorf("MCP server error: %w", err)
+				}
+				close(mcpErrChan)
+			}()
+		}
 	}
 
 	// Log service availability summary
@@ -633,6 +1366,7 @@ func run() error {
 		logger.Info(ctx, "contextd initialized",
 			zap.String("http_host", httpServerHost),
 			zap.Int("http_port", httpServerPort),
+			zap.String("http_socket", httpServerSocket),
 			zap.Strings("services", serviceStatus),
 		)
 	} else {
@@ -674,6 +1408,19 @@ func run() error {
 
 	logger.Info(ctx, "shutting down contextd")
 
+	// Drain MCP tool calls first: new calls are rejected, running ones get
+	// up to the shutdown timeout to finish and deliver their results
+	if mcpServer != nil {
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		if err := mcpServer.Drain(drainCtx); err != nil {
+			logger.Warn(ctx, "MCP drain incomplete", zap.Error(err))
+		} else {
+			logger.Info(ctx, "MCP tool calls drained")
+		}
+		drainCancel()
+		mcpCancel()
+	}
+
 	// Gracefully stop consolidation scheduler (if running)
 	if consolidationScheduler != nil {
 		if err := consolidationScheduler.Stop(); err != nil {
@@ -683,6 +1430,30 @@ func run() error {
 		}
 	}
 
+	// Gracefully stop pruning scheduler (if running)
+	if pruningScheduler != nil {
+		if err := pruningScheduler.Stop(); err != nil {
+			logger.Error(ctx, "pruning scheduler shutdown error", zap.Error(err))
+		}
+	}
+
+	// Gracefully stop digest scheduler (if running)
+	if digestScheduler != nil {
+		if err := digestScheduler.Stop(); err != nil {
+			logger.Error(ctx, "digest scheduler shutdown error", zap.Error(err))
+		}
+	}
+
+	// Stop the workflow worker; interrupted runs resume on the next start
+	if workflowWorker != nil {
+		workflowWorker.Stop()
+		logger.Info(ctx, "workflow worker stopped")
+	}
+
+	if diskMonitor != nil {
+		diskMonitor.Stop()
+	}
+
 	// Stop background health scanner (if running)
 	if bgScanner != nil {
 		bgScanner.Stop()
@@ -701,10 +1472,71 @@ func run() error {
 		}
 	}
 
+	// With no calls left, settle the state they leave behind
+	settleCtx, settleCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer settleCancel()
+
+	// Force-return unfinished folding branches with a shutdown reason
+	if foldingSvc != nil {
+		if err := foldingSvc.Shutdown(settleCtx); err != nil {
+			logger.Error(ctx, "folding shutdown error", zap.Error(err))
+		}
+	}
+
+	// Persist turns buffered for session summaries
+	if reasoningbankSvc != nil {
+		ids, err := reasoningbankSvc.FlushAllSessions(settleCtx)
+		if err != nil {
+			logger.Error(ctx, "session buffer flush error", zap.Error(err))
+		}
+		if len(ids) > 0 {
+			logger.Info(ctx, "flushed buffered sessions", zap.Int("memories", len(ids)))
+		}
+
+		// Write batched feedback and usage updates
+		if err := reasoningbankSvc.StopWriteBatching(settleCtx); err != nil {
+			logger.Error(ctx, "batched memory update flush error", zap.Error(err))
+		}
+	}
+
+	// Let queued embeddings finish so their writes reach the vector store
+	if embeddingQueue != nil {
+		if err := embeddingQueue.Wait(settleCtx); err != nil {
+			logger.Warn(ctx, "embedding queue not drained", zap.Error(err))
+		}
+	}
+
+	// Close the Temporal client once the HTTP server can no longer start runs
+	if temporalClient != nil {
+		temporalClient.Close()
+	}
+
+	// Deliver queued notifications once no more events can arrive
+	notifier.Close()
+
+	// Flush the last tool usage rollup once no more calls can arrive
+	if toolAnalytics != nil {
+		if err := toolAnalytics.Stop(); err != nil {
+			logger.Error(ctx, "tool analytics flush error", zap.Error(err))
+		}
+	}
+
 	logger.Info(ctx, "contextd stopped")
 	return nil
 }
 
+// expandDataPath expands environment variables and a leading ~ in a
+// configured data path.
+func expandDataPath(path string) string {
+	expanded := os.ExpandEnv(path)
+	if strings.HasPrefix(expanded, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			expanded = filepath.Join(home, expanded[2:])
+		}
+	}
+	return expanded
+}
+
 // downloadEmbeddingModels downloads the FastEmbed models for airgap/container builds.
 // This is called with --download-models flag during Docker build or for local setup.
 func downloadEmbeddingModels() error {
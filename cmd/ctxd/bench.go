@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+var (
+	// bench command flags
+	benchDocs        int
+	benchCheckpoints int
+	benchQueries     int
+	benchBackends    []string
+	benchVectorSize  int
+	benchQdrantHost  string
+	benchQdrantPort  int
+	benchDir         string
+	benchSeed        int64
+)
+
+func init() {
+	benchCmd.Flags().IntVar(&benchDocs, "docs", 10000, "Number of synthetic memories to record")
+	benchCmd.Flags().IntVar(&benchCheckpoints, "checkpoints", 0, "Number of synthetic checkpoints to save (default: docs/10)")
+	benchCmd.Flags().IntVar(&benchQueries, "queries", 200, "Number of search queries to run per operation")
+	benchCmd.Flags().StringSliceVar(&benchBackends, "backends", []string{"chromem"}, "Backends to benchmark (chromem, qdrant)")
+	benchCmd.Flags().IntVar(&benchVectorSize, "vector-size", 384, "Synthetic embedding dimension")
+	benchCmd.Flags().StringVar(&benchQdrantHost, "qdrant-host", "localhost", "Qdrant server host")
+	benchCmd.Flags().IntVar(&benchQdrantPort, "qdrant-port", 6334, "Qdrant gRPC port")
+	benchCmd.Flags().StringVar(&benchDir, "dir", "", "Chromem data directory (default: temporary directory, removed afterwards)")
+	benchCmd.Flags().Int64Var(&benchSeed, "seed", 1, "Random seed for synthetic data generation")
+
+	_ = benchCmd.RegisterFlagCompletionFunc("backends", cobra.FixedCompletions(
+		[]string{"chromem", "qdrant"}, cobra.ShellCompDirectiveNoFileComp))
+
+	rootCmd.AddCommand(benchCmd)
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark record and search latency against vectorstore backends",
+	Long: `Generate synthetic memories and checkpoints at a configurable scale and
+measure p50/p95/p99 latency for record and search operations.
+
+Documents are embedded with a deterministic hashing embedder so results
+reflect vectorstore and service overhead, not embedding model speed. Each
+backend runs the same workload and a comparison report is printed.
+
+Qdrant runs write to a dedicated bench project and remove their data when
+finished. Chromem runs use a temporary directory unless --dir is given.
+
+Examples:
+  # 10k memories against chromem
+  ctxd bench
+
+  # 100k memories, compare chromem and Qdrant
+  ctxd bench --docs 100000 --backends chromem,qdrant
+
+  # Machine-readable output
+  ctxd bench --docs 1000 --output json`,
+	RunE: runBench,
+}
+
+// benchOpStats summarizes latencies for one operation on one backend.
+type benchOpStats struct {
+	Operation  string        `json:"operation"`
+	Count      int           `json:"count"`
+	Errors     int           `json:"errors"`
+	P50        time.Duration `json:"p50_ns"`
+	P95        time.Duration `json:"p95_ns"`
+	P99        time.Duration `json:"p99_ns"`
+	Throughput float64       `json:"ops_per_sec"`
+}
+
+// benchResult holds all operation stats for a backend.
+type benchResult struct {
+	Backend    string         `json:"backend"`
+	Docs       int            `json:"docs"`
+	Operations []benchOpStats `json:"operations"`
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchDocs <= 0 {
+		return fmt.Errorf("--docs must be positive")
+	}
+	if benchQueries <= 0 {
+		return fmt.Errorf("--queries must be positive")
+	}
+	if benchCheckpoints == 0 {
+		benchCheckpoints = benchDocs / 10
+	}
+
+	ctx := context.Background()
+	results := make([]benchResult, 0, len(benchBackends))
+
+	for _, backend := range benchBackends {
+		if !structuredOutput() {
+			fmt.Fprintf(os.Stderr, "Benchmarking %s (%d memories, %d checkpoints)...\n", backend, benchDocs, benchCheckpoints)
+		}
+		res, err := benchBackend(ctx, backend)
+		if err != nil {
+			return fmt.Errorf("benchmark %s: %w", backend, err)
+		}
+		results = append(results, *res)
+	}
+
+	return render(results, func() error {
+		printBenchReport(results)
+		return nil
+	})
+}
+
+// benchBackend runs the full workload against a single backend.
+func benchBackend(ctx context.Context, backend string) (*benchResult, error) {
+	embedder := &hashEmbedder{dim: benchVectorSize}
+	logger := zap.NewNop()
+
+	var store vectorstore.Store
+	var cleanup func()
+
+	switch backend {
+	case "chromem":
+		dir := benchDir
+		if dir == "" {
+			tmp, err := os.MkdirTemp("", "ctxd-bench-*")
+			if err != nil {
+				return nil, fmt.Errorf("creating temp dir: %w", err)
+			}
+			dir = tmp
+			cleanup = func() { _ = os.RemoveAll(tmp) }
+		}
+		s, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+			Path:       dir,
+			VectorSize: benchVectorSize,
+		}, embedder, logger)
+		if err != nil {
+			return nil, err
+		}
+		store = s
+
+	case "qdrant":
+		s, err := vectorstore.NewQdrantStore(vectorstore.QdrantConfig{
+			Host:           benchQdrantHost,
+			Port:           benchQdrantPort,
+			CollectionName: "ctxd_bench",
+			VectorSize:     uint64(benchVectorSize),
+		}, embedder)
+		if err != nil {
+			return nil, err
+		}
+		store = s
+
+	default:
+		return nil, fmt.Errorf("unknown backend %q (expected chromem or qdrant)", backend)
+	}
+	defer func() {
+		_ = store.Close()
+		if cleanup != nil {
+			cleanup()
+		}
+	}()
+
+	// A unique project per run keeps Qdrant runs from colliding
+	projectID := fmt.Sprintf("bench_%d", time.Now().Unix())
+	tenantID := "bench"
+	ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+	})
+
+	memSvc, err := reasoningbank.NewService(store, logger, reasoningbank.WithDefaultTenant(tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("creating memory service: %w", err)
+	}
+
+	cpCfg := checkpoint.DefaultServiceConfig()
+	cpCfg.VectorSize = uint64(benchVectorSize)
+	cpSvc, err := checkpoint.NewServiceWithStore(cpCfg, store, logger)
+	if err != nil {
+		return nil, fmt.Errorf("creating checkpoint service: %w", err)
+	}
+
+	gen := newBenchGenerator(benchSeed)
+	result := &benchResult{Backend: backend, Docs: benchDocs}
+
+	// Memory record
+	recordLat, recordErrs, recordElapsed := measure(benchDocs, func(i int) error {
+		title, content, tags := gen.memory(i)
+		mem, err := reasoningbank.NewMemory(projectID, title, content, reasoningbank.OutcomeSuccess, tags)
+		if err != nil {
+			return err
+		}
+		return memSvc.Record(ctx, mem)
+	})
+	result.Operations = append(result.Operations, summarize("memory_record", recordLat, recordErrs, recordElapsed))
+
+	// Memory search
+	searchLat, searchErrs, searchElapsed := measure(benchQueries, func(i int) error {
+		_, err := memSvc.Search(ctx, projectID, gen.query(), 5)
+		return err
+	})
+	result.Operations = append(result.Operations, summarize("memory_search", searchLat, searchErrs, searchElapsed))
+
+	// Checkpoint save
+	checkpointIDs := make([]string, 0, benchCheckpoints)
+	saveLat, saveErrs, saveElapsed := measure(benchCheckpoints, func(i int) error {
+		cp, err := cpSvc.Save(ctx, &checkpoint.SaveRequest{
+			SessionID:   fmt.Sprintf("bench-session-%d", i%50),
+			TenantID:    tenantID,
+			ProjectID:   projectID,
+			ProjectPath: "/bench/" + projectID,
+			Name:        fmt.Sprintf("checkpoint %d", i),
+			Summary:     gen.sentence(12),
+			Context:     gen.sentence(60),
+		})
+		if err == nil {
+			checkpointIDs = append(checkpointIDs, cp.ID)
+		}
+		return err
+	})
+	result.Operations = append(result.Operations, summarize("checkpoint_save", saveLat, saveErrs, saveElapsed))
+
+	// Checkpoint list (filtered search)
+	listLat, listErrs, listElapsed := measure(benchQueries, func(i int) error {
+		_, err := cpSvc.List(ctx, &checkpoint.ListRequest{
+			SessionID: fmt.Sprintf("bench-session-%d", i%50),
+			TenantID:  tenantID,
+			ProjectID: projectID,
+			Limit:     20,
+		})
+		return err
+	})
+	result.Operations = append(result.Operations, summarize("checkpoint_list", listLat, listErrs, listElapsed))
+
+	// Remove bench data from shared Qdrant collections
+	if backend == "qdrant" {
+		if name, err := project.GetCollectionName(projectID, project.CollectionMemories); err == nil {
+			_ = store.DeleteCollection(ctx, name)
+		}
+		for _, id := range checkpointIDs {
+			_ = cpSvc.Delete(ctx, tenantID, "", projectID, id)
+		}
+	}
+
+	return result, nil
+}
+
+// measure runs fn n times sequentially and returns per-call latencies, the
+// error count, and total wall time.
+func measure(n int, fn func(i int) error) ([]time.Duration, int, time.Duration) {
+	latencies := make([]time.Duration, 0, n)
+	errs := 0
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		opStart := time.Now()
+		if err := fn(i); err != nil {
+			errs++
+			continue
+		}
+		latencies = append(latencies, time.Since(opStart))
+	}
+	return latencies, errs, time.Since(start)
+}
+
+// summarize computes percentile statistics for an operation.
+func summarize(op string, latencies []time.Duration, errs int, elapsed time.Duration) benchOpStats {
+	stats := benchOpStats{
+		Operation: op,
+		Count:     len(latencies),
+		Errors:    errs,
+		P50:       percentile(latencies, 50),
+		P95:       percentile(latencies, 95),
+		P99:       percentile(latencies, 99),
+	}
+	if elapsed > 0 {
+		stats.Throughput = float64(len(latencies)) / elapsed.Seconds()
+	}
+	return stats
+}
+
+// percentile returns the p-th percentile using nearest-rank.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// printBenchReport prints a per-operation comparison table across backends.
+func printBenchReport(results []benchResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BACKEND\tOPERATION\tCOUNT\tERRORS\tP50\tP95\tP99\tOPS/SEC")
+	for _, r := range results {
+		for _, op := range r.Operations {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%.1f\n",
+				r.Backend, op.Operation, op.Count, op.Errors,
+				formatLatency(op.P50), formatLatency(op.P95), formatLatency(op.P99),
+				op.Throughput)
+		}
+	}
+	_ = w.Flush()
+
+	if len(results) < 2 {
+		return
+	}
+
+	// Relative p95 comparison against the first backend
+	base := results[0]
+	fmt.Printf("\np95 relative to %s:\n", base.Backend)
+	for _, r := range results[1:] {
+		for i, op := range r.Operations {
+			if i >= len(base.Operations) || base.Operations[i].P95 == 0 {
+				continue
+			}
+			ratio := float64(op.P95) / float64(base.Operations[i].P95)
+			fmt.Printf("  %-8s %-16s %.2fx\n", r.Backend, op.Operation, ratio)
+		}
+	}
+}
+
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%.0fµs", float64(d)/float64(time.Microsecond))
+	}
+}
+
+// benchVocabulary is the word pool for synthetic memories and queries.
+var benchVocabulary = strings.Fields(`
+	auth token refresh retry backoff timeout cache invalidation database
+	migration index query pagination cursor goroutine channel mutex deadlock
+	context cancel deploy rollback canary config flag test fixture mock
+	docker build image layer kubernetes pod probe memory leak profile
+	latency throughput batch stream parser schema validation error wrap
+	logging trace span metric alert dashboard webhook queue worker
+`)
+
+// benchGenerator produces reproducible synthetic text.
+type benchGenerator struct {
+	rng *rand.Rand
+}
+
+func newBenchGenerator(seed int64) *benchGenerator {
+	return &benchGenerator{rng: rand.New(rand.NewSource(seed))} // #nosec G404 -- synthetic benchmark data
+}
+
+func (g *benchGenerator) sentence(words int) string {
+	parts := make([]string, words)
+	for i := range parts {
+		parts[i] = benchVocabulary[g.rng.Intn(len(benchVocabulary))]
+	}
+	return strings.Join(parts, " ")
+}
+
+func (g *benchGenerator) memory(i int) (title, content string, tags []string) {
+	title = fmt.Sprintf("%s (%d)", g.sentence(5), i)
+	content = g.sentence(80)
+	tags = []string{benchVocabulary[g.rng.Intn(len(benchVocabulary))]}
+	return title, content, tags
+}
+
+func (g *benchGenerator) query() string {
+	return g.sentence(4)
+}
+
+// hashEmbedder is a deterministic feature-hashing embedder. It gives texts
+// that share words similar vectors without loading an embedding model.
+type hashEmbedder struct {
+	dim int
+}
+
+func (h *hashEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i] = h.embed(t)
+	}
+	return out, nil
+}
+
+func (h *hashEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return h.embed(text), nil
+}
+
+func (h *hashEmbedder) embed(text string) []float32 {
+	vec := make([]float32, h.dim)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		sum := sha256.Sum256([]byte(word))
+		idx := binary.BigEndian.Uint32(sum[:4]) % uint32(h.dim) // #nosec G115 -- dim is a positive int flag
+		if sum[4]&1 == 0 {
+			vec[idx]++
+		} else {
+			vec[idx]--
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		vec[0] = 1
+		return vec
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= scale
+	}
+	return vec
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(100-i) * time.Millisecond
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(latencies, 95))
+	assert.Equal(t, 99*time.Millisecond, percentile(latencies, 99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
+
+func TestHashEmbedder(t *testing.T) {
+	ctx := context.Background()
+	e := &hashEmbedder{dim: 64}
+
+	a, err := e.EmbedQuery(ctx, "retry backoff timeout")
+	require.NoError(t, err)
+	b, err := e.EmbedQuery(ctx, "retry backoff timeout")
+	require.NoError(t, err)
+	c, err := e.EmbedQuery(ctx, "docker image layer")
+	require.NoError(t, err)
+
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, b, "embedding must be deterministic")
+	assert.Greater(t, dot(a, b), dot(a, c), "shared words should be more similar")
+}
+
+func TestBenchBackend_Chromem(t *testing.T) {
+	docs, checkpoints, queries, vectorSize, dir, seed := benchDocs, benchCheckpoints, benchQueries, benchVectorSize, benchDir, benchSeed
+	t.Cleanup(func() {
+		benchDocs, benchCheckpoints, benchQueries, benchVectorSize, benchDir, benchSeed = docs, checkpoints, queries, vectorSize, dir, seed
+	})
+	benchDocs = 20
+	benchCheckpoints = 5
+	benchQueries = 5
+	benchVectorSize = 32
+	benchDir = t.TempDir()
+	benchSeed = 1
+
+	res, err := benchBackend(context.Background(), "chromem")
+	require.NoError(t, err)
+	require.Len(t, res.Operations, 4)
+
+	for _, op := range res.Operations {
+		assert.Zero(t, op.Errors, "%s should not error", op.Operation)
+		assert.Positive(t, op.Count, "%s should have samples", op.Operation)
+	}
+}
+
+func TestBenchBackend_Unknown(t *testing.T) {
+	_, err := benchBackend(context.Background(), "sqlite")
+	assert.ErrorContains(t, err, "unknown backend")
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
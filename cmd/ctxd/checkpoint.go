@@ -2,12 +2,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 
 	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
 	"github.com/fyrsmithlabs/contextd/internal/config"
@@ -30,7 +31,9 @@ var (
 	cpAutoOnly    bool
 	cpLimit       int
 	cpLevel       string
-	cpOutputJSON  bool
+	cpOutcome     string
+	cpQuarantined bool
+	cpParentID    string
 )
 
 func init() {
@@ -38,13 +41,14 @@ func init() {
 	checkpointCmd.AddCommand(checkpointSaveCmd)
 	checkpointCmd.AddCommand(checkpointListCmd)
 	checkpointCmd.AddCommand(checkpointResumeCmd)
+	checkpointCmd.AddCommand(checkpointReleaseCmd)
+	checkpointCmd.AddCommand(checkpointTreeCmd)
 
 	// Common flags for all checkpoint commands
 	checkpointCmd.PersistentFlags().StringVar(&cpTenantID, "tenant-id", "", "Tenant identifier (required)")
 	checkpointCmd.PersistentFlags().StringVar(&cpTeamID, "team-id", "", "Team identifier (defaults to tenant-id)")
 	checkpointCmd.PersistentFlags().StringVar(&cpProjectID, "project-id", "", "Project identifier (defaults to project path basename)")
 	checkpointCmd.PersistentFlags().StringVar(&cpProjectPath, "project-path", "", "Project path (defaults to current directory)")
-	checkpointCmd.PersistentFlags().BoolVar(&cpOutputJSON, "json", false, "Output results as JSON")
 
 	// Save-specific flags
 	checkpointSaveCmd.Flags().StringVar(&cpSessionID, "session-id", "", "Session ID (optional)")
@@ -52,15 +56,23 @@ func init() {
 	checkpointSaveCmd.Flags().StringVar(&cpDescription, "description", "", "Checkpoint description")
 	checkpointSaveCmd.Flags().StringVar(&cpSummary, "summary", "", "Brief summary of checkpoint")
 	checkpointSaveCmd.Flags().StringVar(&cpContext, "context", "", "Context content")
+	checkpointSaveCmd.Flags().StringVar(&cpParentID, "parent-id", "", "Checkpoint this one continues from (e.g. the one you resumed)")
 	_ = checkpointSaveCmd.MarkFlagRequired("name")
 
 	// List-specific flags
 	checkpointListCmd.Flags().StringVar(&cpSessionID, "session-id", "", "Filter by session ID")
 	checkpointListCmd.Flags().BoolVar(&cpAutoOnly, "auto-only", false, "Only show auto-created checkpoints")
 	checkpointListCmd.Flags().IntVar(&cpLimit, "limit", 20, "Maximum number of checkpoints to return")
+	checkpointListCmd.Flags().StringVar(&cpOutcome, "outcome", "", "Only show checkpoints annotated with this outcome: succeeded, failed, or partial")
+	checkpointListCmd.Flags().BoolVar(&cpQuarantined, "quarantined", false, "Only show checkpoints quarantined as possible prompt injection")
 
 	// Resume-specific flags
 	checkpointResumeCmd.Flags().StringVar(&cpLevel, "level", "context", "Resume level: summary, context, or full")
+
+	_ = checkpointListCmd.RegisterFlagCompletionFunc("outcome", cobra.FixedCompletions(
+		[]string{"succeeded", "failed", "partial"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = checkpointResumeCmd.RegisterFlagCompletionFunc("level", cobra.FixedCompletions(
+		[]string{"summary", "context", "full"}, cobra.ShellCompDirectiveNoFileComp))
 }
 
 var checkpointCmd = &cobra.Command{
@@ -82,7 +94,10 @@ Examples:
   ctxd checkpoint list --tenant-id dahendel --session-id sess_123
 
   # Resume from a checkpoint
-  ctxd checkpoint resume <checkpoint-id> --tenant-id dahendel --level context`,
+  ctxd checkpoint resume <checkpoint-id> --tenant-id dahendel --level context
+
+  # Show how a checkpoint's session branched
+  ctxd checkpoint tree <checkpoint-id> --tenant-id dahendel`,
 }
 
 var checkpointSaveCmd = &cobra.Command{
@@ -127,6 +142,9 @@ Examples:
   # List only auto-created checkpoints
   ctxd checkpoint list --tenant-id dahendel --auto-only
 
+  # Review checkpoints quarantined as possible prompt injection
+  ctxd checkpoint list --tenant-id dahendel --quarantined
+
   # Output as JSON
   ctxd checkpoint list --tenant-id dahendel --json`,
 	RunE: runCheckpointList,
@@ -155,6 +173,43 @@ Examples:
 	RunE: runCheckpointResume,
 }
 
+var checkpointReleaseCmd = &cobra.Command{
+	Use:   "release <checkpoint-id>",
+	Short: "Release a quarantined checkpoint",
+	Long: `Release a checkpoint that was quarantined as possible prompt injection.
+
+Checkpoints whose content looks like instructions to the model, chat-template
+markers or tool-call syntax are quarantined when saved: they are hidden from
+lists and searches and cannot be resumed. After reviewing one with
+"ctxd checkpoint list --quarantined", release it if it is safe.
+
+Examples:
+  # Release a reviewed checkpoint
+  ctxd checkpoint release ckpt_123 --tenant-id dahendel`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointRelease,
+}
+
+var checkpointTreeCmd = &cobra.Command{
+	Use:   "tree <checkpoint-id>",
+	Short: "Show the lineage of a checkpoint",
+	Long: `Show the lineage of a checkpoint as a tree.
+
+A checkpoint saved after resuming another records it as its parent, so
+resuming the same checkpoint twice starts two branches. The tree starts at
+the root of the checkpoint's lineage and shows every branch descending from
+it, marking the requested checkpoint with "*".
+
+Examples:
+  # Show how a session's branches diverged
+  ctxd checkpoint tree ckpt_123 --tenant-id dahendel
+
+  # Output as JSON
+  ctxd checkpoint tree ckpt_123 --tenant-id dahendel --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointTree,
+}
+
 func runCheckpointSave(cmd *cobra.Command, args []string) error {
 	// Validate required flags
 	if cpTenantID == "" {
@@ -202,6 +257,7 @@ func runCheckpointSave(cmd *cobra.Command, args []string) error {
 		Threshold:   0,
 		AutoCreated: false,
 		Metadata:    nil,
+		ParentID:    cpParentID,
 	}
 
 	// Call service
@@ -211,23 +267,22 @@ func runCheckpointSave(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output results
-	if cpOutputJSON {
-		return outputJSON(cp)
-	}
-
-	// Human-readable output
-	fmt.Printf("Checkpoint saved successfully\n")
-	fmt.Printf("ID: %s\n", cp.ID)
-	fmt.Printf("Name: %s\n", cp.Name)
-	fmt.Printf("Created: %s\n", cp.CreatedAt.Format("2006-01-02 15:04:05"))
-	if cp.Description != "" {
-		fmt.Printf("Description: %s\n", cp.Description)
-	}
-	if cp.Summary != "" {
-		fmt.Printf("Summary: %s\n", cp.Summary)
-	}
-
-	return nil
+	return render(checkpointObject(cp), func() error {
+		fmt.Printf("Checkpoint saved successfully\n")
+		fmt.Printf("ID: %s\n", cp.ID)
+		fmt.Printf("Name: %s\n", cp.Name)
+		fmt.Printf("Created: %s\n", cp.CreatedAt.Format("2006-01-02 15:04:05"))
+		if cp.ParentID != "" {
+			fmt.Printf("Parent: %s\n", cp.ParentID)
+		}
+		if cp.Description != "" {
+			fmt.Printf("Description: %s\n", cp.Description)
+		}
+		if cp.Summary != "" {
+			fmt.Printf("Summary: %s\n", cp.Summary)
+		}
+		return nil
+	})
 }
 
 func runCheckpointList(cmd *cobra.Command, args []string) error {
@@ -235,6 +290,9 @@ func runCheckpointList(cmd *cobra.Command, args []string) error {
 	if cpTenantID == "" {
 		return fmt.Errorf("--tenant-id is required")
 	}
+	if cpOutcome != "" && !checkpoint.Outcome(cpOutcome).Valid() {
+		return fmt.Errorf("--outcome must be succeeded, failed, or partial")
+	}
 
 	// Set defaults
 	if cpTeamID == "" {
@@ -266,6 +324,8 @@ func runCheckpointList(cmd *cobra.Command, args []string) error {
 		ProjectPath: cpProjectPath,
 		SessionID:   cpSessionID,
 		AutoOnly:    cpAutoOnly,
+		Outcome:     checkpoint.Outcome(cpOutcome),
+		Quarantined: cpQuarantined,
 		Limit:       cpLimit,
 	}
 
@@ -276,35 +336,43 @@ func runCheckpointList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output results
-	if cpOutputJSON {
-		return outputJSON(checkpoints)
+	objects := make([]CheckpointObject, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		objects = append(objects, checkpointObject(cp))
 	}
+	return render(objects, func() error {
+		return printCheckpointTable(checkpoints)
+	})
+}
 
-	// Human-readable table output
+func printCheckpointTable(checkpoints []*checkpoint.Checkpoint) error {
 	if len(checkpoints) == 0 {
 		fmt.Println("No checkpoints found")
 		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tSESSION\tCREATED\tAUTO\tTOKENS")
+	fmt.Fprintln(w, "ID\tNAME\tSESSION\tCREATED\tAUTO\tTOKENS\tOUTCOME")
 	for _, cp := range checkpoints {
 		autoStr := ""
 		if cp.AutoCreated {
 			autoStr = "yes"
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n",
+		outcomeStr := ""
+		if cp.Annotation != nil {
+			outcomeStr = string(cp.Annotation.Outcome)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
 			truncate(cp.ID, 12),
 			truncate(cp.Name, 30),
 			truncate(cp.SessionID, 12),
 			cp.CreatedAt.Format("2006-01-02 15:04"),
 			autoStr,
 			cp.TokenCount,
+			outcomeStr,
 		)
 	}
-	w.Flush()
-
-	return nil
+	return w.Flush()
 }
 
 func runCheckpointResume(cmd *cobra.Command, args []string) error {
@@ -364,25 +432,150 @@ func runCheckpointResume(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output results
-	if cpOutputJSON {
-		return outputJSON(resp)
+	obj := CheckpointResumeObject{
+		Checkpoint: checkpointObject(resp.Checkpoint),
+		Level:      cpLevel,
+		Content:    resp.Content,
+		TokenCount: int(resp.TokenCount),
+	}
+	return render(obj, func() error {
+		fmt.Printf("Checkpoint: %s\n", resp.Checkpoint.Name)
+		fmt.Printf("Description: %s\n", resp.Checkpoint.Description)
+		fmt.Printf("Created: %s\n", resp.Checkpoint.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Session: %s\n", resp.Checkpoint.SessionID)
+		fmt.Printf("Token Count: %d\n", resp.TokenCount)
+		fmt.Printf("\n--- Content (%s level) ---\n\n", cpLevel)
+		fmt.Println(resp.Content)
+		return nil
+	})
+}
+
+func runCheckpointRelease(cmd *cobra.Command, args []string) error {
+	if cpTenantID == "" {
+		return fmt.Errorf("--tenant-id is required")
+	}
+
+	// Set defaults
+	if cpTeamID == "" {
+		cpTeamID = cpTenantID
+	}
+	if cpProjectPath == "" {
+		var err error
+		cpProjectPath, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	if cpProjectID == "" {
+		cpProjectID = getProjectIDFromPath(cpProjectPath)
+	}
+
+	svc, err := initCheckpointService()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	cp, err := svc.Release(context.Background(), cpTenantID, cpTeamID, cpProjectID, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to release checkpoint: %w", err)
 	}
 
-	// Human-readable output
-	fmt.Printf("Checkpoint: %s\n", resp.Checkpoint.Name)
-	fmt.Printf("Description: %s\n", resp.Checkpoint.Description)
-	fmt.Printf("Created: %s\n", resp.Checkpoint.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("Session: %s\n", resp.Checkpoint.SessionID)
-	fmt.Printf("Token Count: %d\n", resp.TokenCount)
-	fmt.Printf("\n--- Content (%s level) ---\n\n", cpLevel)
-	fmt.Println(resp.Content)
+	return render(checkpointObject(cp), func() error {
+		fmt.Printf("Released checkpoint %s (%s)\n", cp.ID, cp.Name)
+		return nil
+	})
+}
+
+func runCheckpointTree(cmd *cobra.Command, args []string) error {
+	if cpTenantID == "" {
+		return fmt.Errorf("--tenant-id is required")
+	}
+
+	// Set defaults
+	if cpTeamID == "" {
+		cpTeamID = cpTenantID
+	}
+	if cpProjectPath == "" {
+		var err error
+		cpProjectPath, err = os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	if cpProjectID == "" {
+		cpProjectID = getProjectIDFromPath(cpProjectPath)
+	}
+
+	svc, err := initCheckpointService()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	lineage, err := svc.Lineage(context.Background(), cpTenantID, cpTeamID, cpProjectID, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint lineage: %w", err)
+	}
 
-	return nil
+	return render(checkpointTreeObject(args[0], lineage), func() error {
+		printCheckpointTree(os.Stdout, lineage.Root, args[0])
+		return nil
+	})
+}
+
+// printCheckpointTree prints node and its descendants, one checkpoint per
+// line, marking the checkpoint with ID target.
+func printCheckpointTree(w io.Writer, node *checkpoint.LineageNode, target string) {
+	var walk func(node *checkpoint.LineageNode, prefix, branch string)
+	walk = func(node *checkpoint.LineageNode, prefix, branch string) {
+		cp := node.Checkpoint
+		marker := ""
+		if cp.ID == target {
+			marker = " *"
+		}
+		fmt.Fprintf(w, "%s%s%s  %s  %s%s\n", prefix, branch, cp.ID,
+			cp.CreatedAt.Format("2006-01-02 15:04"), truncate(cp.Name, 40), marker)
+
+		switch branch {
+		case "├── ":
+			prefix += "│   "
+		case "└── ":
+			prefix += "    "
+		}
+		for i, child := range node.Children {
+			if i == len(node.Children)-1 {
+				walk(child, prefix, "└── ")
+			} else {
+				walk(child, prefix, "├── ")
+			}
+		}
+	}
+	walk(node, "", "")
 }
 
 // Helper functions
 
 func initCheckpointService() (checkpoint.Service, error) {
+	store, providerDim, logger, err := initVectorStore()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize checkpoint service (using legacy adapter for single store)
+	cpCfg := checkpoint.DefaultServiceConfig()
+	cpCfg.VectorSize = uint64(providerDim)
+	svc, err := checkpoint.NewServiceWithStore(cpCfg, store, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint service: %w", err)
+	}
+
+	return svc, nil
+}
+
+// initVectorStore opens the configured vector store with the configured
+// embeddings provider, returning the store, its vector size and a logger.
+func initVectorStore() (vectorstore.Store, int, *zap.Logger, error) {
 	// Load configuration (try file first, fallback to env vars)
 	cfg, err := config.LoadWithFile("")
 	if err != nil {
@@ -394,7 +587,7 @@ func initCheckpointService() (checkpoint.Service, error) {
 	logCfg := logging.NewDefaultConfig()
 	logger, err := logging.NewLogger(logCfg, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create logger: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to create logger: %w", err)
 	}
 
 	// Initialize embeddings provider
@@ -406,7 +599,7 @@ func initCheckpointService() (checkpoint.Service, error) {
 	}
 	embProvider, err := embeddings.NewProvider(embCfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create embeddings provider: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to create embeddings provider: %w", err)
 	}
 
 	// Get provider dimension and update config
@@ -416,18 +609,10 @@ func initCheckpointService() (checkpoint.Service, error) {
 	// Initialize vector store
 	store, err := vectorstore.NewStore(cfg, embProvider, logger.Underlying())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create vectorstore: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to create vectorstore: %w", err)
 	}
 
-	// Initialize checkpoint service (using legacy adapter for single store)
-	cpCfg := checkpoint.DefaultServiceConfig()
-	cpCfg.VectorSize = uint64(providerDim)
-	svc, err := checkpoint.NewServiceWithStore(cpCfg, store, logger.Underlying())
-	if err != nil {
-		return nil, fmt.Errorf("failed to create checkpoint service: %w", err)
-	}
-
-	return svc, nil
+	return store, providerDim, logger.Underlying(), nil
 }
 
 func getProjectIDFromPath(path string) string {
@@ -455,9 +640,3 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
-
-func outputJSON(v interface{}) error {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	return enc.Encode(v)
-}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"testing"
+	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
 )
 
 func TestGetProjectIDFromPath(t *testing.T) {
@@ -95,3 +99,27 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintCheckpointTree(t *testing.T) {
+	created := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	node := func(id, name string, children ...*checkpoint.LineageNode) *checkpoint.LineageNode {
+		return &checkpoint.LineageNode{
+			Checkpoint: &checkpoint.Checkpoint{ID: id, Name: name, CreatedAt: created},
+			Children:   children,
+		}
+	}
+	root := node("ckpt_1", "Root",
+		node("ckpt_2", "First branch", node("ckpt_4", "Leaf")),
+		node("ckpt_3", "Second branch"))
+
+	var buf bytes.Buffer
+	printCheckpointTree(&buf, root, "ckpt_4")
+	want := `ckpt_1  2026-10-15 09:00  Root
+├── ckpt_2  2026-10-15 09:00  First branch
+│   └── ckpt_4  2026-10-15 09:00  Leaf *
+└── ckpt_3  2026-10-15 09:00  Second branch
+`
+	if buf.String() != want {
+		t.Errorf("printCheckpointTree() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,227 @@
+// Package main implements the doctor diagnostics command for the ctxd CLI.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// Doctor check statuses.
+const (
+	doctorOK      = "ok"
+	doctorFail    = "fail"
+	doctorSkipped = "skipped"
+)
+
+// errDoctorProblems is returned when any doctor check fails, so the
+// command exits non-zero.
+var errDoctorProblems = errors.New("doctor found problems")
+
+// errNotConfigured marks diagnostics the server has not enabled.
+var errNotConfigured = errors.New("not configured on the server")
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with a running contextd server",
+	Long: `Run diagnostics against a running contextd server and report problems.
+
+Checks:
+  server      The HTTP server answers GET /health
+  metadata    Vectorstore collections have intact metadata
+  embeddings  Stored vectors match the current embedding model
+
+The embeddings check samples stored documents, re-embeds their content and
+measures drift (1 - cosine similarity) from the stored vectors. Large drift
+means the embedding model or its configuration changed without re-indexing,
+which silently degrades search.
+
+Diagnostics endpoints only answer requests from localhost. Exits non-zero
+if any check fails.
+
+Examples:
+  ctxd doctor
+  ctxd doctor --json`,
+	RunE: runDoctor,
+}
+
+// DoctorCheck is the outcome of one doctor check.
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok, fail, or skipped
+	Detail string `json:"detail"`
+}
+
+// DoctorReport is the output of ctxd doctor.
+type DoctorReport struct {
+	ServerURL  string                   `json:"server_url"`
+	Checks     []DoctorCheck            `json:"checks"`
+	Embeddings *vectorstore.DriftReport `json:"embeddings,omitempty"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := diagnose()
+
+	err := render(report, func() error {
+		fmt.Printf("contextd doctor (%s)\n\n", report.ServerURL)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+		for _, c := range report.Checks {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, c.Status, c.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if report.Embeddings != nil && len(report.Embeddings.Collections) > 0 {
+			fmt.Println()
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "COLLECTION\tSAMPLED\tMEAN DRIFT\tMAX DRIFT\tSTATUS")
+			for _, col := range report.Embeddings.Collections {
+				fmt.Fprintf(w, "%s\t%d\t%.4f\t%.4f\t%s\n",
+					col.Collection, col.Sampled, col.MeanDrift, col.MaxDrift, collectionDriftStatus(col))
+			}
+			return w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range report.Checks {
+		if c.Status == doctorFail {
+			return errDoctorProblems
+		}
+	}
+	return nil
+}
+
+// diagnose runs every check against serverURL. Checks after the server
+// check are skipped when the server is unreachable.
+func diagnose() *DoctorReport {
+	report := &DoctorReport{ServerURL: serverURL}
+
+	var health HealthResponse
+	err := doctorGet("/health", &health)
+	if errors.Is(err, errNotConfigured) {
+		// /health answers 503 when degraded; the metadata check explains why
+		health.Status, err = "degraded", nil
+	}
+	if err != nil {
+		report.Checks = append(report.Checks,
+			DoctorCheck{Name: "server", Status: doctorFail, Detail: err.Error()},
+			DoctorCheck{Name: "metadata", Status: doctorSkipped, Detail: "server unreachable"},
+			DoctorCheck{Name: "embeddings", Status: doctorSkipped, Detail: "server unreachable"},
+		)
+		return report
+	}
+	report.Checks = append(report.Checks, DoctorCheck{Name: "server", Status: doctorOK, Detail: "status " + health.Status})
+
+	var metadata MetadataHealthResponse
+	report.Checks = append(report.Checks, doctorCheck("metadata", doctorGet("/api/v1/health/metadata", &metadata), func() (bool, string) {
+		if metadata.CorruptCount > 0 {
+			return false, fmt.Sprintf("%d of %d collections corrupt; see ctxd metadata health", metadata.CorruptCount, metadata.Total)
+		}
+		return true, fmt.Sprintf("%d collections healthy", metadata.HealthyCount)
+	}))
+
+	var drift vectorstore.DriftReport
+	err = doctorGet("/api/v1/health/embeddings", &drift)
+	if err == nil {
+		report.Embeddings = &drift
+	}
+	report.Checks = append(report.Checks, doctorCheck("embeddings", err, func() (bool, string) {
+		return embeddingsCheck(&drift)
+	}))
+
+	return report
+}
+
+// embeddingsCheck summarizes a drift report for the embeddings check.
+func embeddingsCheck(drift *vectorstore.DriftReport) (bool, string) {
+	if drift.IsDrifted() {
+		drifted := 0
+		for _, col := range drift.Collections {
+			if col.Drifted {
+				drifted++
+			}
+		}
+		return false, fmt.Sprintf("%d collections drifted (max drift %.4f, threshold %.4f); the embedding model changed since indexing, re-index affected collections",
+			drifted, drift.MaxDrift, drift.Threshold)
+	}
+	if drift.Sampled == 0 {
+		return true, "no stored documents to compare"
+	}
+	return true, fmt.Sprintf("%d documents sampled, mean drift %.4f (threshold %.4f)", drift.Sampled, drift.MeanDrift, drift.Threshold)
+}
+
+// doctorCheck builds a check from a fetch error and, when the fetch
+// succeeded, the result of evaluate.
+func doctorCheck(name string, fetchErr error, evaluate func() (bool, string)) DoctorCheck {
+	if errors.Is(fetchErr, errNotConfigured) {
+		return DoctorCheck{Name: name, Status: doctorSkipped, Detail: fetchErr.Error()}
+	}
+	if fetchErr != nil {
+		return DoctorCheck{Name: name, Status: doctorFail, Detail: fetchErr.Error()}
+	}
+	ok, detail := evaluate()
+	if !ok {
+		return DoctorCheck{Name: name, Status: doctorFail, Detail: detail}
+	}
+	return DoctorCheck{Name: name, Status: doctorOK, Detail: detail}
+}
+
+// collectionDriftStatus renders one collection's drift for the table.
+func collectionDriftStatus(col vectorstore.CollectionDrift) string {
+	switch {
+	case col.Error != "":
+		return "error: " + col.Error
+	case col.DimensionMismatch:
+		return "drifted (dimension changed)"
+	case col.Drifted:
+		return "drifted"
+	case col.Sampled == 0:
+		return "empty"
+	default:
+		return doctorOK
+	}
+}
+
+// doctorGet fetches path from the server and decodes the JSON response
+// into v. A 503 response means the diagnostic is not configured.
+func doctorGet(path string, v interface{}) error {
+	// Drift checks re-embed samples from every collection
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Get(serverURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return errNotConfigured
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("server returned %d (failed to read response: %v)", resp.StatusCode, readErr)
+		}
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestDiagnose(t *testing.T) {
+	withServer := func(t *testing.T, handler http.HandlerFunc) {
+		t.Helper()
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		oldServerURL := serverURL
+		serverURL = server.URL
+		t.Cleanup(func() { serverURL = oldServerURL })
+	}
+	statuses := func(report *DoctorReport) map[string]string {
+		out := make(map[string]string)
+		for _, c := range report.Checks {
+			out[c.Name] = c.Status
+		}
+		return out
+	}
+
+	t.Run("reports embedding drift", func(t *testing.T) {
+		withServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/health":
+				_ = json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
+			case "/api/v1/health/metadata":
+				_ = json.NewEncoder(w).Encode(MetadataHealthResponse{Total: 2, HealthyCount: 2})
+			case "/api/v1/health/embeddings":
+				_ = json.NewEncoder(w).Encode(vectorstore.DriftReport{
+					Status:    "drifted",
+					Threshold: 0.05,
+					Sampled:   20,
+					MeanDrift: 0.41,
+					MaxDrift:  0.63,
+					Collections: []vectorstore.CollectionDrift{
+						{Collection: "org_memories", Sampled: 20, MeanDrift: 0.41, MaxDrift: 0.63, Drifted: true},
+					},
+				})
+			}
+		})
+
+		report := diagnose()
+		assert.Equal(t, map[string]string{"server": doctorOK, "metadata": doctorOK, "embeddings": doctorFail}, statuses(report))
+		require.NotNil(t, report.Embeddings)
+		assert.Equal(t, "org_memories", report.Embeddings.Collections[0].Collection)
+		assert.Contains(t, report.Checks[2].Detail, "1 collections drifted")
+	})
+
+	t.Run("skips diagnostics the server does not offer", func(t *testing.T) {
+		withServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" {
+				_ = json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
+				return
+			}
+			http.Error(w, "not configured", http.StatusServiceUnavailable)
+		})
+
+		report := diagnose()
+		assert.Equal(t, map[string]string{"server": doctorOK, "metadata": doctorSkipped, "embeddings": doctorSkipped}, statuses(report))
+		assert.Nil(t, report.Embeddings)
+	})
+
+	t.Run("server unreachable", func(t *testing.T) {
+		oldServerURL := serverURL
+		serverURL = "http://127.0.0.1:1"
+		defer func() { serverURL = oldServerURL }()
+
+		report := diagnose()
+		assert.Equal(t, map[string]string{"server": doctorFail, "metadata": doctorSkipped, "embeddings": doctorSkipped}, statuses(report))
+	})
+}
+
+func TestCollectionDriftStatus(t *testing.T) {
+	assert.Equal(t, "ok", collectionDriftStatus(vectorstore.CollectionDrift{Sampled: 3}))
+	assert.Equal(t, "empty", collectionDriftStatus(vectorstore.CollectionDrift{}))
+	assert.Equal(t, "drifted", collectionDriftStatus(vectorstore.CollectionDrift{Sampled: 3, Drifted: true}))
+	assert.Equal(t, "drifted (dimension changed)", collectionDriftStatus(vectorstore.CollectionDrift{Sampled: 3, Drifted: true, DimensionMismatch: true}))
+	assert.Equal(t, "error: boom", collectionDriftStatus(vectorstore.CollectionDrift{Error: "boom"}))
+}
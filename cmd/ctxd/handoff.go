@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fyrsmithlabs/contextd/internal/handoff"
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+var (
+	// handoff command flags
+	hoProjectPath string
+	hoTenantID    string
+	hoTo          string
+	hoFrom        string
+	hoNote        string
+	hoMemoryIDs   []string
+	hoFile        string
+	hoSessionID   string
+	hoRecipient   string
+)
+
+func init() {
+	rootCmd.AddCommand(handoffCmd)
+	handoffCmd.AddCommand(handoffExportCmd)
+	handoffCmd.AddCommand(handoffImportCmd)
+
+	handoffCmd.PersistentFlags().StringVar(&hoProjectPath, "project-path", "", "Project path (defaults to current directory)")
+	handoffCmd.PersistentFlags().StringVar(&hoTenantID, "tenant-id", "", "Tenant identifier (defaults to the project's tenant)")
+
+	handoffExportCmd.Flags().StringVar(&hoTo, "to", "", "User or agent the handoff is addressed to (required)")
+	handoffExportCmd.Flags().StringVar(&hoFrom, "from", "", "Who is handing the work off")
+	handoffExportCmd.Flags().StringVar(&hoNote, "note", "", "Message to the recipient")
+	handoffExportCmd.Flags().StringSliceVar(&hoMemoryIDs, "memory-id", nil, "Memory to include besides those the session used (repeatable)")
+	handoffExportCmd.Flags().StringVarP(&hoFile, "file", "f", "", "Write the bundle to this file instead of stdout")
+	_ = handoffExportCmd.MarkFlagRequired("to")
+
+	handoffImportCmd.Flags().StringVar(&hoSessionID, "session-id", "", "Your session ID; its next checkpoint continues from the imported one")
+	handoffImportCmd.Flags().StringVar(&hoRecipient, "recipient", "", "Reject the bundle unless it is addressed to this recipient")
+}
+
+var handoffCmd = &cobra.Command{
+	Use:   "handoff",
+	Short: "Hand work off to another user or agent",
+	Long: `Hand work off to another user or agent through a running contextd server.
+
+A handoff bundle packages a checkpoint, the memories its session used, open
+remediation drafts and a generated state-of-the-work summary into one JSON
+file. The recipient imports it into their own project and tenant, then
+resumes the imported checkpoint.`,
+}
+
+var handoffExportCmd = &cobra.Command{
+	Use:   "export <checkpoint-id>",
+	Short: "Export a handoff bundle",
+	Long: `Export a checkpoint and the knowledge around it as a handoff bundle.
+
+The bundle is written to stdout, or to --file. Secrets are scrubbed from its
+content before it leaves the server.
+
+Examples:
+  # Hand the current project's work to bob
+  ctxd handoff export ckpt_123 --to bob --note "timeout fix is still open" -f handoff.json
+
+  # Pipe the bundle somewhere else
+  ctxd handoff export ckpt_123 --to review-agent | gzip > handoff.json.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHandoffExport,
+}
+
+var handoffImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a handoff bundle",
+	Long: `Import a handoff bundle into your project and tenant. Use - to read
+the bundle from stdin.
+
+The bundle's checkpoint is saved (resume it with "ctxd checkpoint resume"),
+its memories are recorded and its remediations are recorded as pending
+drafts for review. Items that fail to import are listed as warnings.
+
+Examples:
+  # Import a bundle addressed to you
+  ctxd handoff import handoff.json --recipient bob
+
+  # Import from stdin
+  gunzip -c handoff.json.gz | ctxd handoff import -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHandoffImport,
+}
+
+func runHandoffExport(cmd *cobra.Command, args []string) error {
+	path, err := handoffProjectPath()
+	if err != nil {
+		return err
+	}
+
+	var bundle handoff.Bundle
+	err = postHandoff("export", ctxhttp.HandoffExportRequest{
+		CheckpointID: args[0],
+		ProjectPath:  path,
+		TenantID:     hoTenantID,
+		To:           hoTo,
+		From:         hoFrom,
+		Note:         hoNote,
+		MemoryIDs:    hoMemoryIDs,
+	}, &bundle)
+	if err != nil {
+		return fmt.Errorf("failed to export handoff: %w", err)
+	}
+
+	if hoFile == "" {
+		return outputJSON(bundle)
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	if err := os.WriteFile(hoFile, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", hoFile, err)
+	}
+
+	return render(bundle, func() error {
+		fmt.Printf("Wrote handoff %s for %s to %s\n", bundle.ID, bundle.To, hoFile)
+		fmt.Printf("Checkpoint: %s (%s)\n", bundle.Checkpoint.ID, bundle.Checkpoint.Name)
+		fmt.Printf("Memories: %d\n", len(bundle.Memories))
+		fmt.Printf("Open remediations: %d\n", len(bundle.Remediations))
+		for _, w := range bundle.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+		return nil
+	})
+}
+
+func runHandoffImport(cmd *cobra.Command, args []string) error {
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var bundle handoff.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("invalid handoff bundle: %w", err)
+	}
+
+	path, err := handoffProjectPath()
+	if err != nil {
+		return err
+	}
+
+	var result handoff.ImportResult
+	err = postHandoff("import", ctxhttp.HandoffImportRequest{
+		Bundle:      &bundle,
+		ProjectPath: path,
+		TenantID:    hoTenantID,
+		SessionID:   hoSessionID,
+		Recipient:   hoRecipient,
+	}, &result)
+	if err != nil {
+		return fmt.Errorf("failed to import handoff: %w", err)
+	}
+
+	return render(result, func() error {
+		fmt.Printf("Imported handoff %s as checkpoint %s\n", result.HandoffID, result.CheckpointID)
+		fmt.Printf("Memories: %d\n", len(result.MemoryIDs))
+		fmt.Printf("Remediations: %d (pending review)\n", len(result.RemediationIDs))
+		for _, w := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+		fmt.Printf("\nResume with: ctxd checkpoint resume %s\n", result.CheckpointID)
+		return nil
+	})
+}
+
+// handoffProjectPath returns --project-path, defaulting to the current
+// directory.
+func handoffProjectPath() (string, error) {
+	if hoProjectPath != "" {
+		return hoProjectPath, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return cwd, nil
+}
+
+// postHandoff posts req to /api/v1/handoff/<action> and decodes the
+// response into out.
+func postHandoff(action string, req, out any) error {
+	reqJSON, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/handoff/%s", serverURL, action)
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(reqJSON))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("server returned %d (failed to read response: %v)", resp.StatusCode, readErr)
+		}
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,547 @@
+// Package main implements the Claude Code project integration for the ctxd CLI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+// Markers delimiting the block ctxd maintains in CLAUDE.md. Text outside the
+// block is never touched.
+const (
+	protocolBegin = "<!-- BEGIN contextd (managed by ctxd integrate claude) -->"
+	protocolEnd   = "<!-- END contextd -->"
+)
+
+// hookCommandMarker identifies hook commands written by ctxd integrate, so
+// re-running it replaces them instead of adding duplicates.
+const hookCommandMarker = "integrate hook"
+
+// hookTimeoutSeconds bounds each generated hook.
+const hookTimeoutSeconds = 10
+
+var (
+	// integrate command flags
+	integrateDir     string
+	integrateProject string
+	integrateDryRun  bool
+
+	// integrate hook command flags
+	hookProject string
+)
+
+func init() {
+	rootCmd.AddCommand(integrateCmd)
+	integrateCmd.AddCommand(integrateClaudeCmd)
+	integrateCmd.AddCommand(integrateHookCmd)
+
+	integrateClaudeCmd.Flags().StringVar(&integrateDir, "dir", ".", "Repository root to write settings into")
+	integrateClaudeCmd.Flags().StringVar(&integrateProject, "project", "", "Project ID used by the hooks (default: name of --dir)")
+	integrateClaudeCmd.Flags().BoolVar(&integrateDryRun, "dry-run", false, "Show which files would change without writing them")
+
+	integrateHookCmd.Flags().StringVar(&hookProject, "project", "", "Project ID")
+}
+
+// integrateCmd is the parent command for editor integrations
+var integrateCmd = &cobra.Command{
+	Use:   "integrate",
+	Short: "Wire contextd into an editor for the current repository",
+}
+
+// integrateClaudeCmd generates Claude Code project settings
+var integrateClaudeCmd = &cobra.Command{
+	Use:   "claude",
+	Short: "Generate or update Claude Code settings for this repository",
+	Long: `Generate or update the Claude Code project settings of a repository from
+the running contextd server's capabilities:
+
+- .mcp.json registers contextd as the project's MCP server
+- .claude/settings.json wires SessionStart and SessionEnd hooks
+- CLAUDE.md gets a memory protocol block for the available services
+
+The SessionStart hook reminds Claude of the memory protocol. The SessionEnd
+hook saves a checkpoint, and is only wired when the checkpoint service is up
+and the server is not read-only. Re-run after changing the server's
+configuration to keep the wiring in sync; other settings, hooks and CLAUDE.md
+text are kept.
+
+Examples:
+  # Wire up the repository in the current directory
+  ctxd integrate claude
+
+  # Preview changes for another repository
+  ctxd integrate claude --dir ~/src/api --dry-run`,
+	RunE: runIntegrateClaude,
+}
+
+// integrateHookCmd runs a generated Claude Code hook
+var integrateHookCmd = &cobra.Command{
+	Use:       "hook <session-start|session-end>",
+	Short:     "Run a Claude Code hook (used by generated settings)",
+	Hidden:    true,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"session-start", "session-end"},
+	RunE:      runIntegrateHook,
+}
+
+// IntegrationResult is the result of "ctxd integrate claude".
+type IntegrationResult struct {
+	Dir       string            `json:"dir"`
+	ProjectID string            `json:"project_id"`
+	Services  []string          `json:"services"`
+	ReadOnly  bool              `json:"read_only"`
+	DryRun    bool              `json:"dry_run,omitempty"`
+	Files     []IntegrationFile `json:"files"`
+}
+
+// IntegrationFile is a file ctxd integrate claude generates.
+type IntegrationFile struct {
+	Path    string `json:"path"`
+	Changed bool   `json:"changed"`
+}
+
+// claudeCapabilities is what the running server can do, which decides the
+// generated wiring.
+type claudeCapabilities struct {
+	Memory      bool
+	Checkpoint  bool
+	Remediation bool
+	Repository  bool
+	ReadOnly    bool
+}
+
+func capabilitiesOf(status *StatusResponse) claudeCapabilities {
+	ok := func(name string) bool { return status.Services[name] == "ok" }
+	return claudeCapabilities{
+		Memory:      ok("memory"),
+		Checkpoint:  ok("checkpoint"),
+		Remediation: ok("remediation"),
+		Repository:  ok("repository"),
+		ReadOnly:    status.ReadOnly != nil && status.ReadOnly.Enabled,
+	}
+}
+
+// services lists the capabilities the wiring uses, for the result.
+func (c claudeCapabilities) services() []string {
+	var names []string
+	for _, s := range []struct {
+		name string
+		ok   bool
+	}{
+		{"memory", c.Memory},
+		{"checkpoint", c.Checkpoint},
+		{"remediation", c.Remediation},
+		{"repository", c.Repository},
+	} {
+		if s.ok {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+func runIntegrateClaude(cmd *cobra.Command, args []string) error {
+	dir, err := filepath.Abs(integrateDir)
+	if err != nil {
+		return fmt.Errorf("resolving --dir: %w", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("--dir %s is not a directory", integrateDir)
+	}
+	projectID := integrateProject
+	if projectID == "" {
+		projectID = filepath.Base(dir)
+	}
+
+	status, err := fetchStatusHTTP()
+	if err != nil {
+		return fmt.Errorf("reading server capabilities (is contextd running? see --server): %w", err)
+	}
+	caps := capabilitiesOf(status)
+
+	// Prefer the installed binary or image; fall back to contextd on PATH at
+	// session start.
+	_, command, cmdArgs, err := detectContextdInstallation()
+	if err != nil {
+		command, cmdArgs = "contextd", []string{}
+	}
+
+	result := IntegrationResult{
+		Dir:       dir,
+		ProjectID: projectID,
+		Services:  caps.services(),
+		ReadOnly:  caps.ReadOnly,
+		DryRun:    integrateDryRun,
+	}
+
+	updates := []struct {
+		path   string
+		update func([]byte) ([]byte, error)
+	}{
+		{filepath.Join(dir, ".mcp.json"), func(data []byte) ([]byte, error) {
+			return updateJSONFile(data, func(settings map[string]interface{}) error {
+				return setMCPServer(settings, command, cmdArgs)
+			})
+		}},
+		{filepath.Join(dir, ".claude", "settings.json"), func(data []byte) ([]byte, error) {
+			return updateJSONFile(data, func(settings map[string]interface{}) error {
+				return setClaudeHooks(settings, claudeHooks(caps, projectID, hookServer()))
+			})
+		}},
+		{filepath.Join(dir, "CLAUDE.md"), func(data []byte) ([]byte, error) {
+			return []byte(upsertProtocolBlock(string(data), protocolBlock(caps))), nil
+		}},
+	}
+	for _, u := range updates {
+		changed, err := updateFile(u.path, u.update, integrateDryRun)
+		if err != nil {
+			return err
+		}
+		result.Files = append(result.Files, IntegrationFile{Path: u.path, Changed: changed})
+	}
+
+	return render(result, func() error {
+		verb := "Updated"
+		if result.DryRun {
+			verb = "Would update"
+		}
+		fmt.Fprintf(stdout, "Claude Code integration for %s (project %s)\n", result.Dir, result.ProjectID)
+		fmt.Fprintf(stdout, "Server services: %s\n", orDash(strings.Join(result.Services, ", ")))
+		if result.ReadOnly {
+			fmt.Fprintln(stdout, "Server is read-only: the SessionEnd checkpoint hook is not wired")
+		}
+		fmt.Fprintln(stdout)
+		for _, f := range result.Files {
+			rel, err := filepath.Rel(result.Dir, f.Path)
+			if err != nil {
+				rel = f.Path
+			}
+			if f.Changed {
+				fmt.Fprintf(stdout, "  %s %s\n", verb, rel)
+			} else {
+				fmt.Fprintf(stdout, "  Unchanged %s\n", rel)
+			}
+		}
+		if !result.DryRun {
+			fmt.Fprintln(stdout, "\nRestart Claude Code in this repository to apply changes.")
+		}
+		return nil
+	})
+}
+
+// hookServer returns the --server value hooks should use, or "" for the
+// default.
+func hookServer() string {
+	server := serverAddress
+	if server == "" {
+		server = serverURL
+	}
+	if server == rootCmd.PersistentFlags().Lookup("server").DefValue {
+		return ""
+	}
+	return server
+}
+
+// updateFile rewrites path with update's result and reports whether the
+// content changed. A missing file is updated from empty content.
+func updateFile(path string, update func([]byte) ([]byte, error), dryRun bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	updated, err := update(data)
+	if err != nil {
+		return false, fmt.Errorf("updating %s: %w", path, err)
+	}
+	if bytes.Equal(data, updated) {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("creating directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, updated, 0o644); err != nil {
+		return false, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// updateJSONFile decodes a JSON object (empty for no data), applies update
+// and encodes it back with a trailing newline.
+func updateJSONFile(data []byte, update func(map[string]interface{}) error) ([]byte, error) {
+	settings := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+	if err := update(settings); err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// setMCPServer registers contextd in the mcpServers of a .mcp.json.
+func setMCPServer(settings map[string]interface{}, command string, args []string) error {
+	if settings["mcpServers"] == nil {
+		settings["mcpServers"] = make(map[string]interface{})
+	}
+	servers, ok := settings["mcpServers"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid mcpServers format")
+	}
+	servers["contextd"] = map[string]interface{}{
+		"type":    "stdio",
+		"command": command,
+		"args":    args,
+	}
+	return nil
+}
+
+// claudeHooks returns the hook command for each Claude Code event contextd
+// wires.
+func claudeHooks(caps claudeCapabilities, projectID, server string) map[string]string {
+	base := "ctxd"
+	if server != "" {
+		base += " --server " + shellEscape(server)
+	}
+	base += " " + hookCommandMarker
+
+	hooks := map[string]string{
+		"SessionStart": base + " session-start --project " + shellEscape(projectID),
+	}
+	if caps.Checkpoint && !caps.ReadOnly {
+		hooks["SessionEnd"] = base + " session-end --project " + shellEscape(projectID)
+	}
+	return hooks
+}
+
+// setClaudeHooks replaces the hooks ctxd generated before with hooks, keeping
+// every other hook.
+func setClaudeHooks(settings map[string]interface{}, hooks map[string]string) error {
+	if settings["hooks"] == nil {
+		settings["hooks"] = make(map[string]interface{})
+	}
+	events, ok := settings["hooks"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid hooks format")
+	}
+
+	for _, event := range []string{"SessionStart", "SessionEnd"} {
+		groups, _ := events[event].([]interface{})
+		kept := make([]interface{}, 0, len(groups))
+		for _, g := range groups {
+			if g = withoutGeneratedHooks(g); g != nil {
+				kept = append(kept, g)
+			}
+		}
+		if command, ok := hooks[event]; ok {
+			kept = append(kept, map[string]interface{}{
+				"hooks": []interface{}{
+					map[string]interface{}{
+						"type":    "command",
+						"command": command,
+						"timeout": hookTimeoutSeconds,
+					},
+				},
+			})
+		}
+		if len(kept) == 0 {
+			delete(events, event)
+		} else {
+			events[event] = kept
+		}
+	}
+	if len(events) == 0 {
+		delete(settings, "hooks")
+	}
+	return nil
+}
+
+// withoutGeneratedHooks drops the hooks ctxd generated from a hook group, or
+// returns nil if none are left. Groups it does not understand are kept as is.
+func withoutGeneratedHooks(group interface{}) interface{} {
+	g, ok := group.(map[string]interface{})
+	if !ok {
+		return group
+	}
+	hooks, ok := g["hooks"].([]interface{})
+	if !ok {
+		return group
+	}
+	kept := make([]interface{}, 0, len(hooks))
+	for _, h := range hooks {
+		if hm, ok := h.(map[string]interface{}); ok {
+			if command, _ := hm["command"].(string); strings.Contains(command, hookCommandMarker) {
+				continue
+			}
+		}
+		kept = append(kept, h)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	g["hooks"] = kept
+	return g
+}
+
+// protocolLines is the memory protocol for the available services.
+func protocolLines(caps claudeCapabilities) []string {
+	var lines []string
+	if caps.Memory {
+		lines = append(lines,
+			"Before starting a task, run `memory_search` for this project.",
+			"After finishing, record durable learnings with `memory_record` and rate the memories you used with `memory_feedback`.",
+		)
+	}
+	if caps.Repository {
+		lines = append(lines, "Prefer `semantic_search` over grep when exploring code.")
+	}
+	if caps.Remediation {
+		lines = append(lines, "On an error, check `remediation_search` before debugging; record new fixes with `remediation_record`.")
+	}
+	if caps.Checkpoint {
+		lines = append(lines, "Save progress with `checkpoint_save` before context runs low; continue later with `checkpoint_resume`.")
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "Pass `session_id` to contextd tools so the session can be replayed with `ctxd replay`.")
+	}
+	if caps.ReadOnly {
+		lines = append(lines, "contextd is read-only: searches and resumes work, writes are rejected.")
+	}
+	return lines
+}
+
+// protocolBlock renders the CLAUDE.md block for the available services.
+func protocolBlock(caps claudeCapabilities) string {
+	var b strings.Builder
+	b.WriteString(protocolBegin + "\n")
+	b.WriteString("## contextd\n\n")
+	lines := protocolLines(caps)
+	if len(lines) == 0 {
+		b.WriteString("contextd is configured but none of its services are available.\n")
+	}
+	for _, line := range lines {
+		b.WriteString("- " + line + "\n")
+	}
+	b.WriteString(protocolEnd + "\n")
+	return b.String()
+}
+
+// upsertProtocolBlock replaces the managed block in content, or appends it.
+func upsertProtocolBlock(content, block string) string {
+	start := strings.Index(content, protocolBegin)
+	if start >= 0 {
+		if end := strings.Index(content[start:], protocolEnd); end >= 0 {
+			end += start + len(protocolEnd)
+			if end < len(content) && content[end] == '\n' {
+				end++
+			}
+			return content[:start] + block + content[end:]
+		}
+	}
+	if content == "" {
+		return block
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + "\n" + block
+}
+
+// claudeHookInput is the part of Claude Code's hook input the hooks use.
+type claudeHookInput struct {
+	SessionID string `json:"session_id"`
+	Cwd       string `json:"cwd"`
+	Reason    string `json:"reason"`
+}
+
+// runIntegrateHook runs a generated hook. Hooks never fail the session: when
+// the server is unreachable they do nothing.
+func runIntegrateHook(cmd *cobra.Command, args []string) error {
+	var input claudeHookInput
+	if data, err := io.ReadAll(cmd.InOrStdin()); err == nil && len(bytes.TrimSpace(data)) > 0 {
+		_ = json.Unmarshal(data, &input)
+	}
+
+	switch args[0] {
+	case "session-start":
+		return sessionStartHook(cmd.OutOrStdout())
+	case "session-end":
+		if err := sessionEndHook(input); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "contextd: %v\n", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown hook %q (want session-start or session-end)", args[0])
+	}
+}
+
+// sessionStartHook adds the memory protocol for the services available now
+// to the session's context.
+func sessionStartHook(w io.Writer) error {
+	status, err := fetchStatusHTTP()
+	if err != nil {
+		return nil
+	}
+	lines := protocolLines(capabilitiesOf(status))
+	if len(lines) == 0 {
+		return nil
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"hookSpecificOutput": map[string]interface{}{
+			"hookEventName":     "SessionStart",
+			"additionalContext": "contextd is available. " + strings.Join(lines, " "),
+		},
+	})
+}
+
+// sessionEndHook saves a checkpoint of the ending session.
+func sessionEndHook(input claudeHookInput) error {
+	if hookProject == "" || input.SessionID == "" {
+		return fmt.Errorf("session-end hook needs --project and a session_id")
+	}
+	summary := "Session ended"
+	if input.Reason != "" {
+		summary += " (" + input.Reason + ")"
+	}
+	body, err := json.Marshal(ctxhttp.ThresholdRequest{
+		ProjectID:   hookProject,
+		SessionID:   input.SessionID,
+		Percent:     ctxhttp.MaxThresholdPercent,
+		Summary:     summary,
+		ProjectPath: input.Cwd,
+	})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(serverURL+"/api/v1/threshold", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("saving session checkpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("saving session checkpoint: server returned %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+)
+
+func statusServer(t *testing.T, status ctxhttp.StatusResponse) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/status", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	}))
+	t.Cleanup(server.Close)
+
+	oldServerURL := serverURL
+	serverURL = server.URL
+	t.Cleanup(func() { serverURL = oldServerURL })
+}
+
+func TestRunIntegrateClaude(t *testing.T) {
+	statusServer(t, ctxhttp.StatusResponse{
+		Status:   "ok",
+		Services: map[string]string{"memory": "ok", "checkpoint": "ok", "remediation": "unavailable"},
+	})
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".claude"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".claude", "settings.json"), []byte(`{
+  "permissions": {"allow": ["Bash(go test:*)"]},
+  "hooks": {
+    "SessionStart": [
+      {"hooks": [{"type": "command", "command": "./scripts/greet.sh"}]},
+      {"hooks": [{"type": "command", "command": "ctxd integrate hook session-start --project 'old'"}]}
+    ]
+  }
+}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("# Project\n\nRun make test.\n"), 0o644))
+
+	integrateDir, integrateProject = dir, "api"
+	defer func() { integrateDir, integrateProject = ".", "" }()
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	require.NoError(t, runIntegrateClaude(integrateClaudeCmd, nil))
+	assert.Contains(t, buf.String(), "Updated .claude/settings.json")
+
+	var mcp map[string]interface{}
+	data, err := os.ReadFile(filepath.Join(dir, ".mcp.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &mcp))
+	assert.Contains(t, mcp["mcpServers"], "contextd")
+
+	var settings map[string]interface{}
+	data, err = os.ReadFile(filepath.Join(dir, ".claude", "settings.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &settings))
+	assert.Contains(t, settings, "permissions")
+	hooks := settings["hooks"].(map[string]interface{})
+	start := hooks["SessionStart"].([]interface{})
+	require.Len(t, start, 2, "user hook kept, old generated hook replaced")
+	assert.Contains(t, string(data), "./scripts/greet.sh")
+	assert.Contains(t, string(data), "ctxd --server "+shellEscape(serverURL)+" integrate hook session-start --project 'api'")
+	assert.NotContains(t, string(data), "'old'")
+	assert.Contains(t, hooks, "SessionEnd")
+
+	data, err = os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "# Project\n\nRun make test.\n"))
+	assert.Contains(t, string(data), "memory_search")
+	assert.Contains(t, string(data), "checkpoint_save")
+	assert.NotContains(t, string(data), "remediation_search")
+
+	// A second run changes nothing
+	buf.Reset()
+	require.NoError(t, runIntegrateClaude(integrateClaudeCmd, nil))
+	assert.NotContains(t, buf.String(), "Updated")
+}
+
+func TestSetClaudeHooks_ReadOnly(t *testing.T) {
+	caps := capabilitiesOf(&ctxhttp.StatusResponse{
+		Services: map[string]string{"memory": "ok", "checkpoint": "ok"},
+		ReadOnly: &readonly.Status{Enabled: true},
+	})
+	hooks := claudeHooks(caps, "api", "unix:///tmp/contextd.sock")
+	assert.NotContains(t, hooks, "SessionEnd")
+	assert.Equal(t, "ctxd --server 'unix:///tmp/contextd.sock' integrate hook session-start --project 'api'", hooks["SessionStart"])
+
+	settings := map[string]interface{}{
+		"hooks": map[string]interface{}{
+			"SessionEnd": []interface{}{map[string]interface{}{
+				"hooks": []interface{}{map[string]interface{}{"type": "command", "command": "ctxd integrate hook session-end --project 'api'"}},
+			}},
+		},
+	}
+	require.NoError(t, setClaudeHooks(settings, hooks))
+	events := settings["hooks"].(map[string]interface{})
+	assert.NotContains(t, events, "SessionEnd")
+	assert.Contains(t, events, "SessionStart")
+}
+
+func TestUpsertProtocolBlock(t *testing.T) {
+	block := protocolBlock(claudeCapabilities{Memory: true})
+	assert.Equal(t, block, upsertProtocolBlock("", block))
+
+	content := upsertProtocolBlock("# Notes", block)
+	assert.Equal(t, "# Notes\n\n"+block, content)
+
+	updated := protocolBlock(claudeCapabilities{Memory: true, ReadOnly: true})
+	content = upsertProtocolBlock(content+"\nMore notes\n", updated)
+	assert.Equal(t, "# Notes\n\n"+updated+"\nMore notes\n", content)
+	assert.Equal(t, 1, strings.Count(content, protocolBegin))
+}
+
+func TestSessionEndHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/threshold", r.URL.Path)
+		var req ctxhttp.ThresholdRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "api", req.ProjectID)
+		assert.Equal(t, "sess-1", req.SessionID)
+		assert.Equal(t, ctxhttp.MaxThresholdPercent, req.Percent)
+		assert.Equal(t, "Session ended (logout)", req.Summary)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	oldServerURL := serverURL
+	serverURL = server.URL
+	defer func() { serverURL = oldServerURL }()
+
+	hookProject = "api"
+	defer func() { hookProject = "" }()
+
+	require.NoError(t, sessionEndHook(claudeHookInput{SessionID: "sess-1", Cwd: "/src/api", Reason: "logout"}))
+	assert.Error(t, sessionEndHook(claudeHookInput{}))
+}
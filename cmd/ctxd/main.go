@@ -36,12 +36,15 @@ Available commands:
   health   Check contextd server health status
 
 Use "ctxd [command] --help" for more information about a command.
-Use --server to specify a custom server URL (default: http://localhost:9090).`,
+Use --server to specify a custom server URL (default: http://localhost:9090),
+or unix:///path/to/contextd.sock for a server started with --http-socket.
+Use --output json or --output yaml for machine-readable output, and
+"ctxd completion --help" to set up shell completion.`,
 	Version: version,
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "http://localhost:9090", "contextd server URL")
+	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "http://localhost:9090", "contextd server URL, or unix:///path for a Unix socket")
 	rootCmd.AddCommand(scrubCmd)
 	rootCmd.AddCommand(healthCmd)
 }
@@ -91,9 +94,11 @@ type ScrubResponse struct {
 	FindingsCount int    `json:"findings_count"`
 }
 
-// HealthResponse matches internal/http/server.go HealthResponse
+// HealthResponse matches internal/http/server.go HealthResponse, plus the
+// server URL ctxd checked.
 type HealthResponse struct {
-	Status string `json:"status"`
+	Status    string `json:"status"`
+	ServerURL string `json:"server_url,omitempty"`
 }
 
 // runScrub handles the scrub command
@@ -164,15 +169,16 @@ func runScrub(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Output scrubbed content to stdout
-	fmt.Print(scrubResp.Content)
+	return render(scrubResp, func() error {
+		// Output scrubbed content to stdout
+		fmt.Print(scrubResp.Content)
 
-	// If findings were made, log to stderr
-	if scrubResp.FindingsCount > 0 {
-		fmt.Fprintf(os.Stderr, "\n[ctxd] Scrubbed %d secret(s)\n", scrubResp.FindingsCount)
-	}
-
-	return nil
+		// If findings were made, log to stderr
+		if scrubResp.FindingsCount > 0 {
+			fmt.Fprintf(os.Stderr, "\n[ctxd] Scrubbed %d secret(s)\n", scrubResp.FindingsCount)
+		}
+		return nil
+	})
 }
 
 // runHealth handles the health command
@@ -208,8 +214,10 @@ func runHealth(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	fmt.Printf("Server Status: %s\n", healthResp.Status)
-	fmt.Printf("Server URL: %s\n", serverURL)
-
-	return nil
+	healthResp.ServerURL = serverURL
+	return render(healthResp, func() error {
+		fmt.Printf("Server Status: %s\n", healthResp.Status)
+		fmt.Printf("Server URL: %s\n", serverURL)
+		return nil
+	})
 }
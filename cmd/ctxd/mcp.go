@@ -169,85 +169,119 @@ func runMCPInstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// MCPUninstallResult is the result of "ctxd mcp uninstall".
+type MCPUninstallResult struct {
+	SettingsPath string `json:"settings_path"`
+	Removed      bool   `json:"removed"`
+}
+
 // runMCPUninstall handles the mcp uninstall command
 func runMCPUninstall(cmd *cobra.Command, args []string) error {
-	settingsPath := getClaudeSettingsPath()
+	result := MCPUninstallResult{SettingsPath: getClaudeSettingsPath()}
 
-	settings, err := loadMCPSettings(settingsPath)
+	settings, err := loadMCPSettings(result.SettingsPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			fmt.Println("No Claude Code settings found, nothing to uninstall.")
-			return nil
+			return render(result, func() error {
+				fmt.Println("No Claude Code settings found, nothing to uninstall.")
+				return nil
+			})
 		}
 		return fmt.Errorf("failed to load settings: %w", err)
 	}
 
 	mcpServers, ok := settings["mcpServers"].(map[string]interface{})
 	if !ok || mcpServers["contextd"] == nil {
-		fmt.Println("contextd MCP server not configured, nothing to uninstall.")
-		return nil
+		return render(result, func() error {
+			fmt.Println("contextd MCP server not configured, nothing to uninstall.")
+			return nil
+		})
 	}
 
 	delete(mcpServers, "contextd")
 
-	if err := saveMCPSettings(settingsPath, settings); err != nil {
+	if err := saveMCPSettings(result.SettingsPath, settings); err != nil {
 		return fmt.Errorf("failed to save settings: %w", err)
 	}
+	result.Removed = true
 
-	fmt.Printf("Removed contextd MCP server from: %s\n", settingsPath)
-	fmt.Println("\nRestart Claude Code to apply changes.")
+	return render(result, func() error {
+		fmt.Printf("Removed contextd MCP server from: %s\n", result.SettingsPath)
+		fmt.Println("\nRestart Claude Code to apply changes.")
+		return nil
+	})
+}
 
-	return nil
+// MCPStatus is the result of "ctxd mcp status".
+type MCPStatus struct {
+	SettingsPath  string   `json:"settings_path"`
+	SettingsFound bool     `json:"settings_found"`
+	Configured    bool     `json:"configured"`
+	Type          string   `json:"type,omitempty"`
+	Command       string   `json:"command,omitempty"`
+	Args          []string `json:"args,omitempty"`
 }
 
 // runMCPStatus handles the mcp status command
 func runMCPStatus(cmd *cobra.Command, args []string) error {
-	settingsPath := getClaudeSettingsPath()
+	status := MCPStatus{SettingsPath: getClaudeSettingsPath()}
 
-	settings, err := loadMCPSettings(settingsPath)
+	settings, err := loadMCPSettings(status.SettingsPath)
 	if err != nil {
-		fmt.Printf("❌ Could not load Claude Code settings from: %s\n", settingsPath)
-		if os.IsNotExist(err) {
-			fmt.Println("   Settings file does not exist")
-		}
-		return nil
+		return render(status, func() error {
+			fmt.Printf("❌ Could not load Claude Code settings from: %s\n", status.SettingsPath)
+			if os.IsNotExist(err) {
+				fmt.Println("   Settings file does not exist")
+			}
+			return nil
+		})
 	}
+	status.SettingsFound = true
 
-	fmt.Println("📊 MCP Server Configuration Status")
-	fmt.Println()
-
-	mcpServers, ok := settings["mcpServers"].(map[string]interface{})
-	if !ok {
-		fmt.Println("❌ No MCP servers configured in Claude Code")
-		return nil
+	mcpServers, hasServers := settings["mcpServers"].(map[string]interface{})
+	contextdConfig, exists := mcpServers["contextd"]
+	if exists {
+		status.Configured = true
+		if configMap, ok := contextdConfig.(map[string]interface{}); ok {
+			status.Type, _ = configMap["type"].(string)
+			status.Command, _ = configMap["command"].(string)
+			if args, ok := configMap["args"].([]interface{}); ok {
+				for _, arg := range args {
+					status.Args = append(status.Args, fmt.Sprint(arg))
+				}
+			}
+		}
 	}
 
-	contextdConfig, exists := mcpServers["contextd"]
-	if !exists {
-		fmt.Println("❌ contextd MCP server not configured")
+	return render(status, func() error {
+		fmt.Println("📊 MCP Server Configuration Status")
 		fmt.Println()
-		fmt.Println("Run `ctxd mcp install` to configure it.")
-		return nil
-	}
 
-	fmt.Println("✅ contextd MCP server is configured")
-	fmt.Println()
-	fmt.Println("Configuration:")
+		if !hasServers {
+			fmt.Println("❌ No MCP servers configured in Claude Code")
+			return nil
+		}
+		if !status.Configured {
+			fmt.Println("❌ contextd MCP server not configured")
+			fmt.Println()
+			fmt.Println("Run `ctxd mcp install` to configure it.")
+			return nil
+		}
 
-	configMap, ok := contextdConfig.(map[string]interface{})
-	if ok {
-		if serverType, ok := configMap["type"].(string); ok {
-			fmt.Printf("  Type: %s\n", serverType)
+		fmt.Println("✅ contextd MCP server is configured")
+		fmt.Println()
+		fmt.Println("Configuration:")
+		if status.Type != "" {
+			fmt.Printf("  Type: %s\n", status.Type)
 		}
-		if command, ok := configMap["command"].(string); ok {
-			fmt.Printf("  Command: %s\n", command)
+		if status.Command != "" {
+			fmt.Printf("  Command: %s\n", status.Command)
 		}
-		if args, ok := configMap["args"].([]interface{}); ok {
-			fmt.Printf("  Args: %v\n", args)
+		if status.Args != nil {
+			fmt.Printf("  Args: %v\n", status.Args)
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // detectContextdInstallation detects how contextd is installed
@@ -0,0 +1,99 @@
+// Package main implements the MCP socket bridge for the ctxd CLI.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fyrsmithlabs/contextd/internal/unixsock"
+)
+
+// unixScheme prefixes --server values that name a Unix socket.
+const unixScheme = "unix://"
+
+// serverAddress is --server as given, before useServerSocket rewrites a
+// socket URL, for commands that pass it on to other processes.
+var serverAddress string
+
+func init() {
+	mcpCmd.AddCommand(mcpConnectCmd)
+}
+
+// mcpConnectCmd bridges stdio to an MCP socket
+var mcpConnectCmd = &cobra.Command{
+	Use:   "connect <socket>",
+	Short: "Bridge stdio to a contextd MCP socket",
+	Long: `Connect an MCP client that only speaks stdio to a contextd server
+started with --mcp-socket. Each connection is an independent MCP session,
+so several editors can share one contextd process.
+
+Use it as the MCP server command, for example in Claude Code settings:
+
+  "contextd": {
+    "type": "stdio",
+    "command": "ctxd",
+    "args": ["mcp", "connect", "~/.config/contextd/mcp.sock"]
+  }`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bridgeSocket(cmd.Context(), expandPath(args[0]), cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+// bridgeSocket copies in to the socket and the socket to out until either
+// side closes.
+func bridgeSocket(ctx context.Context, path string, in io.Reader, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	conn, err := unixsock.Dial(ctx, path)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", path, err)
+	}
+	defer conn.Close()
+
+	inDone := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(conn, in)
+		// Half-close so the server sees EOF and ends the session.
+		if uc, ok := conn.(*net.UnixConn); ok {
+			_ = uc.CloseWrite()
+		}
+		inDone <- err
+	}()
+
+	if _, err := io.Copy(out, conn); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	select {
+	case err := <-inDone:
+		return err
+	default:
+		// The server ended the session while the client was still open.
+		return nil
+	}
+}
+
+// useServerSocket routes HTTP requests to a Unix socket when --server is
+// unix:///path/to/socket.
+func useServerSocket() {
+	serverAddress = serverURL
+	if !strings.HasPrefix(serverURL, unixScheme) {
+		return
+	}
+	path := expandPath(strings.TrimPrefix(serverURL, unixScheme))
+	http.DefaultTransport = &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return unixsock.Dial(ctx, path)
+		},
+	}
+	// The host is ignored by the socket dialer but must be a valid URL.
+	serverURL = "http://localhost"
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/unixsock"
+)
+
+func shortSocketPath(t *testing.T, name string) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ctxd")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, name)
+}
+
+func TestBridgeSocket(t *testing.T) {
+	path := shortSocketPath(t, "mcp.sock")
+	ln, err := unixsock.Listen(path, 0)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// Echo server: replies with everything it reads, then ends the session.
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	var out bytes.Buffer
+	err = bridgeSocket(context.Background(), path, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n"), &out)
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n", out.String())
+
+	err = bridgeSocket(context.Background(), filepath.Join(filepath.Dir(path), "missing.sock"), strings.NewReader(""), &out)
+	assert.ErrorContains(t, err, "connecting to")
+}
+
+func TestUseServerSocket(t *testing.T) {
+	path := shortSocketPath(t, "http.sock")
+	ln, err := unixsock.Listen(path, 0)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	oldURL, oldTransport := serverURL, http.DefaultTransport
+	t.Cleanup(func() { serverURL, http.DefaultTransport = oldURL, oldTransport })
+
+	serverURL = "http://localhost:9090"
+	useServerSocket()
+	assert.Equal(t, "http://localhost:9090", serverURL, "TCP URLs are left alone")
+	assert.Same(t, oldTransport, http.DefaultTransport)
+
+	serverURL = unixScheme + path
+	useServerSocket()
+	resp, err := http.Get(serverURL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "/health", string(body))
+}
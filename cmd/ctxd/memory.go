@@ -0,0 +1,1045 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+var (
+	// memory command flags
+	memProjectID   string
+	memThreshold   float64
+	memMaxClusters int
+	memTags        []string
+	memOutcome     string
+	memQuery       string
+	memPreview     bool
+	memPropose     bool
+	memStatus      string
+	memApprove     bool
+	memReject      bool
+	memMaxMemories int
+	memMinConf     float64
+	memUnusedAge   time.Duration
+	memAction      string
+	memApply       bool
+	memLimit       int
+	memCSV         bool
+	memRelease     bool
+	memThresholds  []float64
+	memDelete      bool
+	memReason      string
+	memInject      bool
+	memMaxTokens   int
+)
+
+func init() {
+	rootCmd.AddCommand(memoryCmd)
+	memoryCmd.AddCommand(memorySearchCmd)
+	memoryCmd.AddCommand(memoryConsolidateCmd)
+	memoryCmd.AddCommand(memoryProposalsCmd)
+	memoryCmd.AddCommand(memoryReviewCmd)
+	memoryCmd.AddCommand(memoryPruneCmd)
+	memoryCmd.AddCommand(memoryMapCmd)
+	memoryCmd.AddCommand(memoryHistoryCmd)
+	memoryCmd.AddCommand(memoryQuarantineCmd)
+	memoryCmd.AddCommand(memoryTuneCmd)
+	memoryCmd.AddCommand(memoryUndoCmd)
+
+	memoryCmd.PersistentFlags().StringVar(&memProjectID, "project-id", "", "Project identifier (defaults to current directory basename)")
+
+	memorySearchCmd.Flags().IntVar(&memLimit, "limit", 10, "Maximum number of memories to return")
+	memorySearchCmd.Flags().BoolVar(&memInject, "inject", false, "Print the results as a prompt-ready block, ordered by confidence")
+	memorySearchCmd.Flags().IntVar(&memMaxTokens, "max-tokens", 0, "Token cap for the --inject block (0 = no cap)")
+
+	memoryConsolidateCmd.Flags().Float64Var(&memThreshold, "threshold", 0.8, "Minimum similarity for memories to cluster (0-1)")
+	memoryConsolidateCmd.Flags().IntVar(&memMaxClusters, "max-clusters", 0, "Maximum clusters to consider (0 = no limit)")
+	memoryConsolidateCmd.Flags().StringSliceVar(&memTags, "tags", nil, "Only consider memories with at least one of these tags")
+	memoryConsolidateCmd.Flags().StringVar(&memOutcome, "outcome", "", "Only consider memories with this outcome: success or failure")
+	memoryConsolidateCmd.Flags().StringVar(&memQuery, "query", "", "Only consider memories relevant to this query")
+	memoryConsolidateCmd.Flags().BoolVar(&memPreview, "preview", false, "Show clusters and extractive drafts without merging")
+	memoryConsolidateCmd.Flags().BoolVar(&memPropose, "propose", false, "Save clusters as pending proposals for review")
+
+	memoryMapCmd.Flags().Float64Var(&memThreshold, "threshold", 0.8, "Minimum similarity for memories to cluster (0-1)")
+	memoryMapCmd.Flags().StringSliceVar(&memTags, "tags", nil, "Only map memories with at least one of these tags")
+	memoryMapCmd.Flags().StringVar(&memOutcome, "outcome", "", "Only map memories with this outcome: success or failure")
+	memoryMapCmd.Flags().StringVar(&memQuery, "query", "", "Only map memories relevant to this query")
+	memoryMapCmd.Flags().BoolVar(&memCSV, "csv", false, "Write one CSV row per memory instead of a summary")
+
+	memoryProposalsCmd.Flags().StringVar(&memStatus, "status", "pending", "Filter by status: pending, approved, rejected, merged, expired, or all")
+
+	memoryReviewCmd.Flags().BoolVar(&memApprove, "approve", false, "Approve the given proposal")
+	memoryReviewCmd.Flags().BoolVar(&memReject, "reject", false, "Reject the given proposal")
+
+	memoryTuneCmd.Flags().Float64SliceVar(&memThresholds, "thresholds", nil, "Similarity thresholds to evaluate (default 0.70,0.75,0.80,0.85,0.90,0.95)")
+	memoryTuneCmd.Flags().BoolVar(&memApply, "apply", false, "Save the recommended threshold as the project's consolidation default")
+
+	memoryUndoCmd.Flags().BoolVar(&memDelete, "delete", false, "Delete the consolidated memory instead of archiving it")
+	memoryUndoCmd.Flags().StringVar(&memReason, "reason", "", "Why the consolidation is undone, recorded in the audit log")
+
+	memoryQuarantineCmd.Flags().BoolVar(&memRelease, "release", false, "Release the given memory so searches return it")
+	memoryQuarantineCmd.Flags().BoolVar(&memReject, "reject", false, "Delete the given memory")
+
+	memoryPruneCmd.Flags().IntVar(&memMaxMemories, "max-memories", 0, "Maximum active memories to keep (0 = no limit)")
+	memoryPruneCmd.Flags().Float64Var(&memMinConf, "min-confidence", 0, "Prune memories below this confidence (0 = disabled)")
+	memoryPruneCmd.Flags().DurationVar(&memUnusedAge, "max-unused-age", 0, "Prune never-used memories older than this, e.g. 2160h (0 = disabled)")
+	memoryPruneCmd.Flags().StringVar(&memAction, "action", "archive", "What to do with pruned memories: archive or delete")
+	memoryPruneCmd.Flags().BoolVar(&memApply, "apply", false, "Prune the reported memories (default: report only)")
+
+	_ = memoryConsolidateCmd.RegisterFlagCompletionFunc("outcome", cobra.FixedCompletions(
+		[]string{"success", "failure"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = memoryMapCmd.RegisterFlagCompletionFunc("outcome", cobra.FixedCompletions(
+		[]string{"success", "failure"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = memoryProposalsCmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(
+		[]string{"pending", "approved", "rejected", "merged", "expired", "all"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = memoryPruneCmd.RegisterFlagCompletionFunc("action", cobra.FixedCompletions(
+		[]string{"archive", "delete"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Manage ReasoningBank memories",
+	Long: `Manage ReasoningBank memories stored by contextd.
+
+Examples:
+  # Preview which memories consolidation would merge
+  ctxd memory consolidate --project-id contextd --preview
+
+  # Propose clusters, then approve or reject each interactively
+  ctxd memory consolidate --project-id contextd --propose
+  ctxd memory review --project-id contextd`,
+}
+
+var memorySearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search memories",
+	Long: `Search a project's memories by meaning, most relevant first.
+
+With --output json or yaml each memory is printed in the stable format
+described by "ctxd schema memory". With --inject the results are printed as
+the block contextd injects into agent prompts: guidance on applying them,
+then the memories ordered by confidence with anti-patterns marked AVOID,
+cut off at --max-tokens.
+
+Examples:
+  # Find memories about flaky tests
+  ctxd memory search "flaky integration tests" --project-id contextd
+
+  # Top three matches as JSON
+  ctxd memory search "retry backoff" --project-id contextd --limit 3 --output json
+
+  # A block for an agent prompt, at most 800 tokens
+  ctxd memory search "retry backoff" --project-id contextd --inject --max-tokens 800`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMemorySearch,
+}
+
+var memoryConsolidateCmd = &cobra.Command{
+	Use:   "consolidate",
+	Short: "Preview or propose memory consolidation",
+	Long: `Preview or propose memory consolidation for a project.
+
+--preview lists the clusters consolidation would merge, their similarity
+statistics and an extractive draft of each merged memory. No LLM calls are
+made and nothing is changed, so clusters can be reviewed before paying for
+synthesis.
+
+--propose saves the same clusters as pending proposals. Review them with
+"ctxd memory review"; only approved clusters are merged, by the contextd
+server's next consolidation run.
+
+Examples:
+  # Preview all clusters
+  ctxd memory consolidate --project-id contextd --preview
+
+  # Preview only testing-related memories
+  ctxd memory consolidate --project-id contextd --preview --tags testing
+
+  # Save proposals for review
+  ctxd memory consolidate --project-id contextd --propose
+
+  # Output as JSON
+  ctxd memory consolidate --project-id contextd --preview --json`,
+	RunE: runMemoryConsolidate,
+}
+
+var memoryProposalsCmd = &cobra.Command{
+	Use:   "proposals",
+	Short: "List consolidation proposals",
+	Long: `List consolidation proposals for a project.
+
+Examples:
+  # Pending proposals
+  ctxd memory proposals --project-id contextd
+
+  # Every proposal, as JSON
+  ctxd memory proposals --project-id contextd --status all --json`,
+	RunE: runMemoryProposals,
+}
+
+var memoryReviewCmd = &cobra.Command{
+	Use:   "review [proposal-id]",
+	Short: "Approve or reject consolidation proposals",
+	Long: `Approve or reject consolidation proposals.
+
+With a proposal ID, --approve or --reject records a single decision.
+Without one, each pending proposal is shown in turn and you are asked to
+approve, reject or skip it.
+
+Examples:
+  # Review pending proposals interactively
+  ctxd memory review --project-id contextd
+
+  # Approve one proposal
+  ctxd memory review 6f1c... --project-id contextd --approve`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMemoryReview,
+}
+
+var memoryPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Report or prune stale memories",
+	Long: `Report or prune stale memories by policy.
+
+Without --apply, prints the memories each rule would prune and changes
+nothing. Rules:
+  --min-confidence  memories below the confidence floor
+  --max-unused-age  never-used memories older than the given age
+  --max-memories    lowest-importance memories over the bank size limit
+
+Examples:
+  # See what a 500-memory cap would remove
+  ctxd memory prune --project-id contextd --max-memories 500
+
+  # Archive low-confidence memories
+  ctxd memory prune --project-id contextd --min-confidence 0.2 --apply
+
+  # Delete memories unused for 90 days
+  ctxd memory prune --project-id contextd --max-unused-age 2160h --action delete --apply`,
+	RunE: runMemoryPrune,
+}
+
+var memoryMapCmd = &cobra.Command{
+	Use:   "map",
+	Short: "Export a 2D map of memory embeddings",
+	Long: `Export a 2D projection of a project's memory embeddings for plotting.
+
+Memories are projected onto the first two principal components (PCA) of
+their embeddings and grouped into the clusters consolidation would merge.
+Each point carries its cluster, outcome, confidence and a red-to-green
+confidence color, so dense clusters of duplicated knowledge stand out
+before running consolidation.
+
+The default output summarizes the clusters. --csv writes one row per
+memory (id, title, x, y, cluster, cluster_label, outcome, confidence,
+color); --output json writes the full map.
+
+Examples:
+  # Summarize clusters
+  ctxd memory map --project-id contextd
+
+  # Export points for a spreadsheet or plotting library
+  ctxd memory map --project-id contextd --csv > memories.csv
+
+  # Full map as JSON, with a looser clustering threshold
+  ctxd memory map --project-id contextd --threshold 0.7 --output json`,
+	RunE: runMemoryMap,
+}
+
+var memoryHistoryCmd = &cobra.Command{
+	Use:   "history <memory-id>",
+	Short: "Show how a memory's confidence has changed",
+	Long: `Show every confidence adjustment of a memory, oldest first, with a
+sparkline of the trend, so you can see whether a memory is gaining trust or
+being steadily penalized.
+
+Each change lists its cause (recorded, feedback_helpful, feedback_unhelpful,
+outcome_success, outcome_failure), the delta and the resulting confidence.
+Up to the last 100 changes are kept.
+
+Examples:
+  # Confidence timeline of one memory
+  ctxd memory history 6f1c... --project-id contextd
+
+  # As JSON
+  ctxd memory history 6f1c... --project-id contextd --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMemoryHistory,
+}
+
+var memoryQuarantineCmd = &cobra.Command{
+	Use:   "quarantine [memory-id]",
+	Short: "Review memories quarantined as possible prompt injection",
+	Long: `List memories quarantined as possible prompt injection, or release or
+reject one of them.
+
+Memories whose content looks like instructions to the model, chat-template
+markers or tool-call syntax are quarantined when recorded. Quarantined
+memories are kept for review but never returned by searches. Release a
+memory that is safe, or reject it to delete it.
+
+Examples:
+  # List quarantined memories and the rules they matched
+  ctxd memory quarantine --project-id contextd
+
+  # Release a reviewed memory
+  ctxd memory quarantine 6f1c... --project-id contextd --release
+
+  # Delete a malicious memory
+  ctxd memory quarantine 6f1c... --project-id contextd --reject`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMemoryQuarantine,
+}
+
+var memoryTuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Recommend a consolidation threshold for a project",
+	Long: `Score the clusters consolidation would form at several similarity
+thresholds and recommend the one with the best silhouette: tight clusters
+that are well separated from other memories.
+
+A threshold that is too low merges unrelated memories; one that is too high
+merges nothing. The best value depends on the project and embedding model.
+With --apply the recommendation is saved in the project's settings and
+scheduled consolidation uses it instead of the configured threshold.
+
+Examples:
+  # Show scores and the recommendation
+  ctxd memory tune --project-id contextd
+
+  # Save the recommendation for scheduled runs
+  ctxd memory tune --project-id contextd --apply`,
+	RunE: runMemoryTune,
+}
+
+func runMemorySearch(cmd *cobra.Command, args []string) error {
+	if memLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	if memMaxTokens < 0 {
+		return fmt.Errorf("--max-tokens must not be negative")
+	}
+
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	svc, _, err := initMemoryService()
+	if err != nil {
+		return err
+	}
+
+	results, err := svc.SearchWithScores(memoryContext(projectID), projectID, args[0], memLimit)
+	if err != nil {
+		return fmt.Errorf("failed to search memories: %w", err)
+	}
+
+	if memInject {
+		injection := reasoningbank.FormatInjection(results, reasoningbank.InjectionOptions{MaxTokens: memMaxTokens})
+		return render(injection, func() error {
+			fmt.Print(injection.Text)
+			return nil
+		})
+	}
+
+	memories := make([]MemoryObject, 0, len(results))
+	for _, r := range results {
+		memories = append(memories, scoredMemoryObject(r))
+	}
+	return render(memories, func() error {
+		if len(memories) == 0 {
+			fmt.Println("No memories found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tOUTCOME\tCONFIDENCE\tRELEVANCE\tTITLE")
+		for _, m := range memories {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%s\n",
+				m.ID, m.Outcome, m.Confidence, m.Relevance, truncate(m.Title, 60))
+		}
+		return w.Flush()
+	})
+}
+
+func runMemoryConsolidate(cmd *cobra.Command, args []string) error {
+	if memPreview == memPropose {
+		return fmt.Errorf("pass exactly one of --preview or --propose; merging runs in the contextd server (memory_consolidate)")
+	}
+
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	distiller, err := initDistiller()
+	if err != nil {
+		return err
+	}
+
+	ctx := memoryContext(projectID)
+
+	opts := reasoningbank.ConsolidationOptions{
+		SimilarityThreshold: memThreshold,
+		MaxClustersPerRun:   memMaxClusters,
+		Tags:                memTags,
+		Outcome:             reasoningbank.Outcome(memOutcome),
+		Query:               memQuery,
+	}
+
+	if memPropose {
+		opts.RequireApproval = true
+		opts.ForceAll = true
+		result, err := distiller.Consolidate(ctx, projectID, opts)
+		if err != nil {
+			return fmt.Errorf("failed to propose clusters: %w", err)
+		}
+		return render(result, func() error {
+			fmt.Printf("Saved %d proposals for review\n", len(result.ProposalIDs))
+			return nil
+		})
+	}
+
+	previews, err := distiller.PreviewClusters(ctx, projectID, opts)
+	if err != nil {
+		return fmt.Errorf("failed to preview clusters: %w", err)
+	}
+
+	return render(previews, func() error {
+		printClusterPreviews(previews)
+		return nil
+	})
+}
+
+func printClusterPreviews(previews []reasoningbank.ClusterPreview) {
+	if len(previews) == 0 {
+		fmt.Println("No clusters found")
+		return
+	}
+
+	for _, p := range previews {
+		fmt.Printf("Cluster %d: %d memories (avg similarity %.2f, min %.2f)\n",
+			p.Index, len(p.Members), p.AverageSimilarity, p.MinSimilarity)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  ID\tCONFIDENCE\tTITLE")
+		for _, m := range p.Members {
+			fmt.Fprintf(w, "  %s\t%.2f\t%s\n", m.ID, m.Confidence, truncate(m.Title, 60))
+		}
+		w.Flush()
+
+		if p.Draft != nil {
+			fmt.Printf("  Draft: %s (confidence %.2f)\n", p.Draft.Title, p.Draft.Confidence)
+			fmt.Printf("  %s\n", truncate(p.Draft.Content, 200))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Total: %d clusters\n", len(previews))
+}
+
+func runMemoryMap(cmd *cobra.Command, args []string) error {
+	if memCSV && structuredOutput() {
+		return fmt.Errorf("--csv cannot be combined with --output %s", outputFormat)
+	}
+
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	distiller, err := initDistiller()
+	if err != nil {
+		return err
+	}
+
+	m, err := distiller.EmbeddingMap(memoryContext(projectID), projectID, reasoningbank.ConsolidationOptions{
+		SimilarityThreshold: memThreshold,
+		Tags:                memTags,
+		Outcome:             reasoningbank.Outcome(memOutcome),
+		Query:               memQuery,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build memory map: %w", err)
+	}
+
+	if memCSV {
+		return writeEmbeddingMapCSV(os.Stdout, m)
+	}
+	return render(m, func() error {
+		printEmbeddingMap(m)
+		return nil
+	})
+}
+
+// writeEmbeddingMapCSV writes one row per point of m.
+func writeEmbeddingMapCSV(out io.Writer, m *reasoningbank.EmbeddingMap) error {
+	labels := make(map[int]string, len(m.Clusters))
+	for _, c := range m.Clusters {
+		labels[c.Index] = c.Label
+	}
+
+	w := csv.NewWriter(out)
+	_ = w.Write([]string{"id", "title", "x", "y", "cluster", "cluster_label", "outcome", "confidence", "color"})
+	for _, p := range m.Points {
+		_ = w.Write([]string{
+			p.ID,
+			p.Title,
+			strconv.FormatFloat(p.X, 'f', 6, 64),
+			strconv.FormatFloat(p.Y, 'f', 6, 64),
+			strconv.Itoa(p.Cluster),
+			labels[p.Cluster],
+			string(p.Outcome),
+			strconv.FormatFloat(p.Confidence, 'f', 2, 64),
+			p.Color,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func printEmbeddingMap(m *reasoningbank.EmbeddingMap) {
+	if len(m.Points) == 0 {
+		fmt.Println("No memories with embeddings found")
+		return
+	}
+
+	clustered := 0
+	for _, c := range m.Clusters {
+		clustered += c.Size
+	}
+	fmt.Printf("%d memories, %d in %d clusters (threshold %.2f)\n",
+		len(m.Points), clustered, len(m.Clusters), m.Threshold)
+	fmt.Printf("PCA axes explain %.0f%% and %.0f%% of variance\n\n",
+		m.ExplainedVariance[0]*100, m.ExplainedVariance[1]*100)
+
+	if len(m.Clusters) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CLUSTER\tSIZE\tAVG SIM\tMIN SIM\tLABEL")
+		for _, c := range m.Clusters {
+			fmt.Fprintf(w, "%d\t%d\t%.2f\t%.2f\t%s\n",
+				c.Index, c.Size, c.AverageSimilarity, c.MinSimilarity, c.Label)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	fmt.Println("Use --csv or --output json to export the points.")
+}
+
+// MemoryHistory is the output of "ctxd memory history".
+type MemoryHistory struct {
+	MemoryID   string                           `json:"memory_id"`
+	Title      string                           `json:"title"`
+	Confidence float64                          `json:"confidence"`
+	History    []reasoningbank.ConfidenceChange `json:"history"`
+}
+
+func runMemoryHistory(cmd *cobra.Command, args []string) error {
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	svc, _, err := initMemoryService()
+	if err != nil {
+		return err
+	}
+
+	ctx := memoryContext(projectID)
+	memory, err := svc.Get(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get memory: %w", err)
+	}
+	history, err := svc.ConfidenceHistory(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get confidence history: %w", err)
+	}
+
+	out := MemoryHistory{
+		MemoryID:   memory.ID,
+		Title:      memory.Title,
+		Confidence: memory.Confidence,
+		History:    history,
+	}
+	return render(out, func() error {
+		printMemoryHistory(os.Stdout, out)
+		return nil
+	})
+}
+
+func printMemoryHistory(out io.Writer, h MemoryHistory) {
+	fmt.Fprintf(out, "%s (confidence %.2f)\n", h.Title, h.Confidence)
+	if len(h.History) == 0 {
+		fmt.Fprintln(out, "No confidence changes recorded")
+		return
+	}
+
+	values := make([]float64, len(h.History))
+	for i, c := range h.History {
+		values[i] = c.Confidence
+	}
+	first, last := values[0], values[len(values)-1]
+	fmt.Fprintf(out, "Trend: %s  %.2f → %.2f (%+.2f over %d changes)\n\n",
+		sparkline(values), first, last, last-first, len(values))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tCAUSE\tDELTA\tCONFIDENCE\tEVIDENCE")
+	for _, c := range h.History {
+		fmt.Fprintf(w, "%s\t%s\t%+.2f\t%.2f\t%s\n",
+			c.Timestamp.Local().Format("2006-01-02 15:04"), c.Cause, c.Delta, c.Confidence, truncate(c.Evidence, 60))
+	}
+	w.Flush()
+}
+
+// sparklineBlocks are the bar glyphs of a sparkline, lowest first.
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders confidence values in [0, 1] as a row of bars. The scale
+// is fixed rather than fitted to the values so small wobbles look small.
+func sparkline(values []float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		v = math.Max(0, math.Min(1, v))
+		b.WriteRune(sparklineBlocks[int(math.Round(v*float64(len(sparklineBlocks)-1)))])
+	}
+	return b.String()
+}
+
+var memoryUndoCmd = &cobra.Command{
+	Use:   "undo <consolidated-memory-id>",
+	Short: "Undo a consolidation",
+	Long: `Revert a consolidation whose merged memory is wrong or hallucinated.
+
+The source memories merged into the given memory are made active again and
+unlinked from it, and the merged memory is archived (or deleted with
+--delete). The undo is recorded in the project's audit log.
+
+A later consolidation run may merge the restored memories again; preview
+it first or raise the project's threshold with "ctxd memory tune".
+
+Examples:
+  # Restore the sources and archive the merged memory
+  ctxd memory undo 6f1c... --project-id contextd --reason "merged unrelated fixes"
+
+  # Delete the merged memory instead
+  ctxd memory undo 6f1c... --project-id contextd --delete`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMemoryUndo,
+}
+
+func runMemoryUndo(cmd *cobra.Command, args []string) error {
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	distiller, err := initDistiller()
+	if err != nil {
+		return err
+	}
+
+	result, err := distiller.Undo(memoryContext(projectID), projectID, args[0], reasoningbank.UndoOptions{
+		Delete: memDelete,
+		Reason: memReason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to undo consolidation: %w", err)
+	}
+
+	return render(result, func() error {
+		action := "Archived"
+		if result.Deleted {
+			action = "Deleted"
+		}
+		fmt.Printf("%s consolidated memory %s\n", action, result.ConsolidatedID)
+		fmt.Printf("Restored %d source memories:\n", len(result.RestoredIDs))
+		for _, id := range result.RestoredIDs {
+			fmt.Printf("  %s\n", id)
+		}
+		return nil
+	})
+}
+
+func runMemoryTune(cmd *cobra.Command, args []string) error {
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	distiller, err := initDistiller()
+	if err != nil {
+		return err
+	}
+
+	ctx := memoryContext(projectID)
+	tuning, err := distiller.TuneThreshold(ctx, projectID, memThresholds)
+	if err != nil {
+		return fmt.Errorf("failed to tune threshold: %w", err)
+	}
+	if memApply {
+		if err := distiller.SaveTunedThreshold(ctx, tuning); err != nil {
+			return fmt.Errorf("failed to save threshold: %w", err)
+		}
+	}
+
+	return render(tuning, func() error {
+		printThresholdTuning(os.Stdout, tuning, memApply)
+		return nil
+	})
+}
+
+func printThresholdTuning(out io.Writer, t *reasoningbank.ThresholdTuning, applied bool) {
+	fmt.Fprintf(out, "Scored %d memories\n\n", t.Memories)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "THRESHOLD\tCLUSTERS\tCLUSTERED\tSILHOUETTE\tCOHESION\t")
+	for _, s := range t.Scores {
+		marker := ""
+		if s.Threshold == t.Recommended {
+			marker = "← recommended"
+		}
+		fmt.Fprintf(w, "%.2f\t%d\t%d\t%.3f\t%.3f\t%s\n",
+			s.Threshold, s.Clusters, s.Clustered, s.Silhouette, s.Cohesion, marker)
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nRecommended threshold: %.2f\n", t.Recommended)
+	if applied {
+		fmt.Fprintln(out, "Saved as the project's consolidation threshold")
+	} else {
+		fmt.Fprintln(out, "Run again with --apply to use it for scheduled consolidation")
+	}
+}
+
+func runMemoryQuarantine(cmd *cobra.Command, args []string) error {
+	if memRelease && memReject {
+		return fmt.Errorf("--release and --reject are mutually exclusive")
+	}
+	if len(args) == 0 && (memRelease || memReject) {
+		return fmt.Errorf("--release and --reject require a memory ID")
+	}
+	if len(args) == 1 && !memRelease && !memReject {
+		return fmt.Errorf("pass --release or --reject with a memory ID")
+	}
+
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	svc, _, err := initMemoryService()
+	if err != nil {
+		return err
+	}
+	ctx := memoryContext(projectID)
+
+	if len(args) == 0 {
+		quarantined, err := svc.ListQuarantined(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to list quarantined memories: %w", err)
+		}
+		memories := make([]MemoryObject, 0, len(quarantined))
+		for _, m := range quarantined {
+			memories = append(memories, memoryObject(m))
+		}
+		return render(memories, func() error {
+			if len(memories) == 0 {
+				fmt.Println("No quarantined memories")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tCREATED\tRULES\tTITLE")
+			for _, m := range memories {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+					m.ID, m.CreatedAt.Local().Format("2006-01-02 15:04"),
+					strings.Join(m.QuarantineRules, ","), truncate(m.Title, 60))
+			}
+			return w.Flush()
+		})
+	}
+
+	memoryID := args[0]
+	memory, err := svc.GetByProjectID(ctx, projectID, memoryID)
+	if err != nil {
+		return fmt.Errorf("failed to get memory: %w", err)
+	}
+	if memory.State != reasoningbank.MemoryStateQuarantined {
+		return fmt.Errorf("memory %s is not quarantined", memoryID)
+	}
+
+	if memReject {
+		if err := svc.DeleteByProjectID(ctx, projectID, memoryID); err != nil {
+			return fmt.Errorf("failed to delete memory: %w", err)
+		}
+		fmt.Printf("Deleted memory %s\n", memoryID)
+		return nil
+	}
+
+	released, err := svc.ReleaseQuarantine(ctx, projectID, memoryID)
+	if err != nil {
+		return fmt.Errorf("failed to release memory: %w", err)
+	}
+	return render(memoryObject(*released), func() error {
+		fmt.Printf("Released memory %s (%s)\n", released.ID, released.Title)
+		return nil
+	})
+}
+
+func runMemoryPrune(cmd *cobra.Command, args []string) error {
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	svc, _, err := initMemoryService()
+	if err != nil {
+		return err
+	}
+
+	report, err := svc.Prune(memoryContext(projectID), projectID, reasoningbank.PruningPolicy{
+		MaxMemories:   memMaxMemories,
+		MinConfidence: memMinConf,
+		MaxUnusedAge:  memUnusedAge,
+		Action:        reasoningbank.PruneAction(memAction),
+		DryRun:        !memApply,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune memories: %w", err)
+	}
+
+	if err := render(report, func() error {
+		printPruneReport(report)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if !report.DryRun && len(report.Failed) > 0 {
+		return fmt.Errorf("failed to prune %d memories", len(report.Failed))
+	}
+	return nil
+}
+
+func printPruneReport(report *reasoningbank.PruneReport) {
+	if len(report.Candidates) == 0 {
+		fmt.Printf("Scanned %d memories, nothing to prune\n", report.Scanned)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tRULE\tCONFIDENCE\tIMPORTANCE\tCREATED\tTITLE")
+	for _, c := range report.Candidates {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%s\t%s\n",
+			c.ID, c.Rule, c.Confidence, c.Importance,
+			c.CreatedAt.Format("2006-01-02"), truncate(c.Title, 50))
+	}
+	w.Flush()
+
+	if report.DryRun {
+		fmt.Printf("\nWould %s %d of %d memories (rerun with --apply)\n",
+			report.Action, len(report.Candidates), report.Scanned)
+		return
+	}
+	fmt.Printf("\nPruned %d of %d memories (%s)\n", report.Pruned, report.Scanned, report.Action)
+}
+
+func runMemoryProposals(cmd *cobra.Command, args []string) error {
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	distiller, err := initDistiller()
+	if err != nil {
+		return err
+	}
+
+	status := reasoningbank.ProposalStatus(memStatus)
+	if memStatus == "all" {
+		status = ""
+	}
+
+	proposals, err := distiller.ListProposals(memoryContext(projectID), projectID, status)
+	if err != nil {
+		return fmt.Errorf("failed to list proposals: %w", err)
+	}
+
+	return render(proposals, func() error {
+		printProposals(proposals)
+		return nil
+	})
+}
+
+func printProposals(proposals []reasoningbank.ConsolidationProposal) {
+	if len(proposals) == 0 {
+		fmt.Println("No proposals found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tMEMBERS\tSIMILARITY\tEXPIRES\tDRAFT")
+	for _, p := range proposals {
+		title := ""
+		if p.Draft != nil {
+			title = truncate(p.Draft.Title, 50)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%s\t%s\n",
+			p.ID, p.Status, len(p.MemberIDs), p.AverageSimilarity,
+			p.ExpiresAt.Format("2006-01-02 15:04"), title)
+	}
+	w.Flush()
+
+	fmt.Printf("\nTotal: %d proposals\n", len(proposals))
+}
+
+func runMemoryReview(cmd *cobra.Command, args []string) error {
+	projectID, err := resolveMemoryProjectID()
+	if err != nil {
+		return err
+	}
+
+	distiller, err := initDistiller()
+	if err != nil {
+		return err
+	}
+	ctx := memoryContext(projectID)
+
+	if len(args) == 1 {
+		if memApprove == memReject {
+			return fmt.Errorf("pass exactly one of --approve or --reject")
+		}
+		p, err := distiller.ReviewProposal(ctx, projectID, args[0], memApprove)
+		if err != nil {
+			return fmt.Errorf("failed to review proposal: %w", err)
+		}
+		return render(p, func() error {
+			fmt.Printf("Proposal %s is now %s\n", p.ID, p.Status)
+			return nil
+		})
+	}
+
+	if structuredOutput() {
+		return fmt.Errorf("interactive review only prints text; pass a proposal ID with --approve or --reject for --output %s", outputFormat)
+	}
+
+	pending, err := distiller.ListProposals(ctx, projectID, reasoningbank.ProposalPending)
+	if err != nil {
+		return fmt.Errorf("failed to list proposals: %w", err)
+	}
+	if len(pending) == 0 {
+		fmt.Println("No pending proposals")
+		return nil
+	}
+
+	return reviewInteractively(ctx, distiller, projectID, pending, os.Stdin, os.Stdout)
+}
+
+// reviewInteractively asks for a decision on each proposal, reading answers
+// from in. Unrecognized answers skip the proposal.
+func reviewInteractively(ctx context.Context, distiller *reasoningbank.Distiller, projectID string, proposals []reasoningbank.ConsolidationProposal, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	approved, rejected := 0, 0
+
+	for i, p := range proposals {
+		fmt.Fprintf(out, "Proposal %d/%d: %s (avg similarity %.2f)\n", i+1, len(proposals), p.ID, p.AverageSimilarity)
+		for _, title := range p.MemberTitles {
+			fmt.Fprintf(out, "  - %s\n", truncate(title, 70))
+		}
+		if p.Draft != nil {
+			fmt.Fprintf(out, "  Draft: %s\n  %s\n", p.Draft.Title, truncate(p.Draft.Content, 200))
+		}
+		fmt.Fprint(out, "Approve? [y]es / [n]o / [s]kip / [q]uit: ")
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			break
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			if _, err := distiller.ReviewProposal(ctx, projectID, p.ID, true); err != nil {
+				return fmt.Errorf("failed to approve proposal %s: %w", p.ID, err)
+			}
+			approved++
+		case "n", "no":
+			if _, err := distiller.ReviewProposal(ctx, projectID, p.ID, false); err != nil {
+				return fmt.Errorf("failed to reject proposal %s: %w", p.ID, err)
+			}
+			rejected++
+		case "q", "quit":
+			fmt.Fprintf(out, "\nApproved %d, rejected %d\n", approved, rejected)
+			return nil
+		}
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintf(out, "Approved %d, rejected %d\n", approved, rejected)
+	return nil
+}
+
+// memoryContext scopes ctx to projectID. ProjectID serves as both tenant and
+// project scope, matching the MCP memory tools.
+func memoryContext(projectID string) context.Context {
+	return vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{
+		TenantID:  projectID,
+		ProjectID: projectID,
+	})
+}
+
+func resolveMemoryProjectID() (string, error) {
+	projectID := memProjectID
+	if projectID == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+		projectID = getProjectIDFromPath(cwd)
+	}
+	if err := sanitize.ValidateProjectID(projectID); err != nil {
+		return "", fmt.Errorf("invalid --project-id: %w", err)
+	}
+	return projectID, nil
+}
+
+func initMemoryService() (*reasoningbank.Service, *zap.Logger, error) {
+	store, _, logger, err := initVectorStore()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc, err := reasoningbank.NewService(store, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create memory service: %w", err)
+	}
+	return svc, logger, nil
+}
+
+func initDistiller() (*reasoningbank.Distiller, error) {
+	svc, logger, err := initMemoryService()
+	if err != nil {
+		return nil, err
+	}
+
+	distiller, err := reasoningbank.NewDistiller(svc, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create distiller: %w", err)
+	}
+	return distiller, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+func TestWriteEmbeddingMapCSV(t *testing.T) {
+	m := &reasoningbank.EmbeddingMap{
+		Clusters: []reasoningbank.EmbeddingCluster{{Index: 1, Label: "errors", Size: 1}},
+		Points: []reasoningbank.EmbeddingPoint{
+			{ID: "m1", Title: "Wrap errors, with context", X: 1.5, Y: -0.25, Cluster: 1,
+				Outcome: reasoningbank.OutcomeSuccess, Confidence: 0.9, Color: "#3ea75c"},
+			{ID: "m2", Title: "Migrations", Outcome: reasoningbank.OutcomeFailure, Confidence: 0.4, Color: "#f5a35f"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeEmbeddingMapCSV(&buf, m))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "title", "x", "y", "cluster", "cluster_label", "outcome", "confidence", "color"},
+		{"m1", "Wrap errors, with context", "1.500000", "-0.250000", "1", "errors", "success", "0.90", "#3ea75c"},
+		{"m2", "Migrations", "0.000000", "0.000000", "0", "", "failure", "0.40", "#f5a35f"},
+	}, rows)
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▅█", sparkline([]float64{0, 0.5, 1}))
+	assert.Equal(t, "▁█", sparkline([]float64{-1, 2}))
+	assert.Empty(t, sparkline(nil))
+}
+
+func TestPrintMemoryHistory(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	var buf bytes.Buffer
+	printMemoryHistory(&buf, MemoryHistory{
+		Title:      "Wrap errors",
+		Confidence: 0.6,
+		History: []reasoningbank.ConfidenceChange{
+			{Timestamp: at, Cause: reasoningbank.CauseRecorded, Confidence: 0.8},
+			{Timestamp: at.Add(time.Hour), Cause: reasoningbank.CauseOutcomeFailure, Delta: -0.2, Confidence: 0.6},
+			{Timestamp: at.Add(2 * time.Hour), Cause: reasoningbank.CauseFeedbackHelpful, Delta: 0.05, Confidence: 0.65, Evidence: "retried with backoff, request succeeded"},
+		},
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "Wrap errors (confidence 0.60)")
+	assert.Contains(t, out, "Trend: ▇▅▆  0.80 → 0.65 (-0.15 over 3 changes)")
+	assert.Contains(t, out, "2026-03-01 13:00  outcome_failure   -0.20  0.60")
+	assert.Contains(t, out, "+0.05  0.65        retried with backoff, request succeeded")
+
+	buf.Reset()
+	printMemoryHistory(&buf, MemoryHistory{Title: "New", Confidence: 0.8})
+	assert.Contains(t, buf.String(), "No confidence changes recorded")
+}
+
+func TestPrintThresholdTuning(t *testing.T) {
+	tuning := &reasoningbank.ThresholdTuning{
+		Memories:    12,
+		Recommended: 0.85,
+		Scores: []reasoningbank.ThresholdScore{
+			{Threshold: 0.8, Clusters: 2, Clustered: 10, Silhouette: 0.2, Cohesion: 0.82},
+			{Threshold: 0.85, Clusters: 3, Clustered: 9, Silhouette: 0.41, Cohesion: 0.9},
+		},
+	}
+
+	var buf bytes.Buffer
+	printThresholdTuning(&buf, tuning, false)
+	out := buf.String()
+	assert.Contains(t, out, "Scored 12 memories")
+	assert.Contains(t, out, "0.85       3         9          0.410       0.900     ← recommended")
+	assert.NotContains(t, out, "0.80       2         10         0.200       0.820     ←")
+	assert.Contains(t, out, "Run again with --apply")
+
+	buf.Reset()
+	printThresholdTuning(&buf, tuning, true)
+	assert.Contains(t, buf.String(), "Saved as the project's consolidation threshold")
+}
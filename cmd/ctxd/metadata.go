@@ -162,6 +162,23 @@ type MetadataHealthResponse struct {
 	Details       map[string]string `json:"details"`
 }
 
+// CollectionEntry is a collection directory in the vectorstore or quarantine.
+type CollectionEntry struct {
+	Hash      string `json:"hash"`
+	Name      string `json:"name,omitempty"`
+	Documents int    `json:"documents"`
+	Status    string `json:"status,omitempty"` // healthy, corrupt, or empty
+	Path      string `json:"path,omitempty"`
+}
+
+// RecoveryResult is the result of recovering or restoring a collection.
+type RecoveryResult struct {
+	Collection string `json:"collection,omitempty"`
+	Hash       string `json:"hash"`
+	Path       string `json:"path"`
+	Status     string `json:"status"` // recovered, restored, exists, or quarantined
+}
+
 func getVectorstorePath() (string, error) {
 	if vectorstorePath != "" {
 		// Check for path traversal attempts in the raw input
@@ -194,8 +211,7 @@ func runMetadataHealth(cmd *cobra.Command, args []string) error {
 	if vectorstorePath == "" {
 		health, err := fetchHealthHTTP()
 		if err == nil {
-			printHealthReport(health)
-			return nil
+			return renderHealthReport(health)
 		}
 		fmt.Fprintf(os.Stderr, "HTTP server unavailable, scanning filesystem...\n\n")
 	}
@@ -211,8 +227,7 @@ func runMetadataHealth(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to scan filesystem: %w", err)
 	}
 
-	printHealthReport(health)
-	return nil
+	return renderHealthReport(health)
 }
 
 func fetchHealthHTTP() (*MetadataHealthResponse, error) {
@@ -302,6 +317,13 @@ func scanFilesystemHealth(path string) (*MetadataHealthResponse, error) {
 	return health, nil
 }
 
+func renderHealthReport(health *MetadataHealthResponse) error {
+	return render(health, func() error {
+		printHealthReport(health)
+		return nil
+	})
+}
+
 func printHealthReport(health *MetadataHealthResponse) {
 	fmt.Printf("Vectorstore Health Report\n")
 	fmt.Printf("========================\n\n")
@@ -338,10 +360,6 @@ func runMetadataList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read vectorstore directory: %w", err)
 	}
 
-	fmt.Printf("Collections in %s\n", path)
-	fmt.Printf("%-10s %-30s %-8s %s\n", "HASH", "NAME", "DOCS", "STATUS")
-	fmt.Printf("%s\n", strings.Repeat("-", 70))
-
 	// Known collection names for reverse lookup
 	knownCollections := []string{
 		"contextd_memories",
@@ -356,6 +374,7 @@ func runMetadataList(cmd *cobra.Command, args []string) error {
 		hashToName[fmt.Sprintf("%x", h)[:8]] = name
 	}
 
+	collections := []CollectionEntry{}
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
@@ -388,19 +407,32 @@ func runMetadataList(cmd *cobra.Command, args []string) error {
 		}
 
 		// Check status
-		status := "✅ healthy"
+		status := "healthy"
 		if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
 			if docCount > 0 {
-				status = "❌ CORRUPT"
+				status = "corrupt"
 			} else {
-				status = "⚪ empty"
+				status = "empty"
 			}
 		}
 
-		fmt.Printf("%-10s %-30s %-8d %s\n", hash, name, docCount, status)
+		collections = append(collections, CollectionEntry{Hash: hash, Name: name, Documents: docCount, Status: status})
 	}
 
-	return nil
+	return render(collections, func() error {
+		statusLabels := map[string]string{
+			"healthy": "✅ healthy",
+			"corrupt": "❌ CORRUPT",
+			"empty":   "⚪ empty",
+		}
+		fmt.Printf("Collections in %s\n", path)
+		fmt.Printf("%-10s %-30s %-8s %s\n", "HASH", "NAME", "DOCS", "STATUS")
+		fmt.Printf("%s\n", strings.Repeat("-", 70))
+		for _, c := range collections {
+			fmt.Printf("%-10s %-30s %-8d %s\n", c.Hash, c.Name, c.Documents, statusLabels[c.Status])
+		}
+		return nil
+	})
 }
 
 func tryReadCollectionName(metadataPath string) string {
@@ -446,18 +478,24 @@ func runMetadataRecover(cmd *cobra.Command, args []string) error {
 		// Also check quarantine
 		quarantinePath := filepath.Join(path, ".quarantine", hash)
 		if _, err := os.Stat(quarantinePath); err == nil {
-			fmt.Printf("Collection %s (hash: %s) is in quarantine.\n", collectionName, hash)
-			fmt.Printf("Run: ctxd metadata quarantine restore %s\n", hash)
-			return nil
+			result := RecoveryResult{Collection: collectionName, Hash: hash, Path: quarantinePath, Status: "quarantined"}
+			return render(result, func() error {
+				fmt.Printf("Collection %s (hash: %s) is in quarantine.\n", collectionName, hash)
+				fmt.Printf("Run: ctxd metadata quarantine restore %s\n", hash)
+				return nil
+			})
 		}
 		return fmt.Errorf("collection directory not found: %s (hash: %s)\n\nCommon collection names:\n  - contextd_memories\n  - contextd_checkpoints\n  - contextd_remediations\n  - contextd_repository\n\nRun 'ctxd metadata list' to see available collections.", collectionName, hash)
 	}
 
 	// Check if metadata already exists
 	if _, err := os.Stat(metadataPath); err == nil {
-		fmt.Printf("Metadata file already exists for %s\n", collectionName)
-		fmt.Printf("Path: %s\n", metadataPath)
-		return nil
+		result := RecoveryResult{Collection: collectionName, Hash: hash, Path: metadataPath, Status: "exists"}
+		return render(result, func() error {
+			fmt.Printf("Metadata file already exists for %s\n", collectionName)
+			fmt.Printf("Path: %s\n", metadataPath)
+			return nil
+		})
 	}
 
 	pc := persistedCollection{
@@ -482,12 +520,14 @@ func runMetadataRecover(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to sync metadata file: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully recovered metadata for %s\n", collectionName)
-	fmt.Printf("   Hash: %s\n", hash)
-	fmt.Printf("   Path: %s\n", metadataPath)
-	fmt.Printf("\nRestart contextd to load the recovered collection.\n")
-
-	return nil
+	result := RecoveryResult{Collection: collectionName, Hash: hash, Path: metadataPath, Status: "recovered"}
+	return render(result, func() error {
+		fmt.Printf("✅ Successfully recovered metadata for %s\n", collectionName)
+		fmt.Printf("   Hash: %s\n", hash)
+		fmt.Printf("   Path: %s\n", metadataPath)
+		fmt.Printf("\nRestart contextd to load the recovered collection.\n")
+		return nil
+	})
 }
 
 func runQuarantineList(cmd *cobra.Command, args []string) error {
@@ -498,25 +538,13 @@ func runQuarantineList(cmd *cobra.Command, args []string) error {
 
 	quarantinePath := filepath.Join(path, ".quarantine")
 
-	if _, err := os.Stat(quarantinePath); os.IsNotExist(err) {
-		fmt.Println("No quarantine directory found. No collections have been quarantined.")
-		return nil
-	}
-
 	entries, err := os.ReadDir(quarantinePath)
-	if err != nil {
+	hasQuarantine := err == nil
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to read quarantine directory: %w", err)
 	}
 
-	if len(entries) == 0 {
-		fmt.Println("Quarantine is empty. No collections have been quarantined.")
-		return nil
-	}
-
-	fmt.Printf("Quarantined Collections in %s\n", quarantinePath)
-	fmt.Printf("%-10s %-8s %s\n", "HASH", "DOCS", "PATH")
-	fmt.Printf("%s\n", strings.Repeat("-", 60))
-
+	collections := []CollectionEntry{}
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
@@ -538,15 +566,32 @@ func runQuarantineList(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		fmt.Printf("%-10s %-8d %s\n", hash, docCount, collectionPath)
+		collections = append(collections, CollectionEntry{Hash: hash, Documents: docCount, Path: collectionPath})
 	}
 
-	fmt.Printf("\nTo restore a collection:\n")
-	fmt.Printf("  1. Identify the collection name for the hash\n")
-	fmt.Printf("  2. Recover metadata: ctxd metadata recover <name>\n")
-	fmt.Printf("  3. Restore: ctxd metadata quarantine restore <hash>\n")
+	return render(collections, func() error {
+		if !hasQuarantine {
+			fmt.Println("No quarantine directory found. No collections have been quarantined.")
+			return nil
+		}
+		if len(entries) == 0 {
+			fmt.Println("Quarantine is empty. No collections have been quarantined.")
+			return nil
+		}
+
+		fmt.Printf("Quarantined Collections in %s\n", quarantinePath)
+		fmt.Printf("%-10s %-8s %s\n", "HASH", "DOCS", "PATH")
+		fmt.Printf("%s\n", strings.Repeat("-", 60))
+		for _, c := range collections {
+			fmt.Printf("%-10s %-8d %s\n", c.Hash, c.Documents, c.Path)
+		}
 
-	return nil
+		fmt.Printf("\nTo restore a collection:\n")
+		fmt.Printf("  1. Identify the collection name for the hash\n")
+		fmt.Printf("  2. Recover metadata: ctxd metadata recover <name>\n")
+		fmt.Printf("  3. Restore: ctxd metadata quarantine restore <hash>\n")
+		return nil
+	})
 }
 
 func runQuarantineRestore(cmd *cobra.Command, args []string) error {
@@ -586,9 +631,11 @@ func runQuarantineRestore(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to restore collection: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully restored collection %s from quarantine\n", hash)
-	fmt.Printf("   Path: %s\n", targetPath)
-	fmt.Printf("\nRestart contextd to load the restored collection.\n")
-
-	return nil
+	result := RecoveryResult{Hash: hash, Path: targetPath, Status: "restored"}
+	return render(result, func() error {
+		fmt.Printf("✅ Successfully restored collection %s from quarantine\n", hash)
+		fmt.Printf("   Path: %s\n", targetPath)
+		fmt.Printf("\nRestart contextd to load the restored collection.\n")
+		return nil
+	})
 }
@@ -66,6 +66,13 @@ Examples:
 	RunE: runMigrate,
 }
 
+// MigrationResult is the result of "ctxd migrate".
+type MigrationResult struct {
+	Collections []string `json:"collections"`
+	Documents   int      `json:"documents"`
+	DryRun      bool     `json:"dry_run"`
+}
+
 func runMigrate(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 	defer cancel()
@@ -73,14 +80,14 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	// Expand chromem path
 	expandedPath := expandPath(chromemPath)
 
-	fmt.Printf("Migration: Qdrant -> Chromem\n")
-	fmt.Printf("  Source: %s:%d\n", qdrantHost, qdrantPort)
-	fmt.Printf("  Destination: %s\n", expandedPath)
-	fmt.Printf("  Batch size: %d\n", batchSize)
+	fmt.Fprintf(progress(), "Migration: Qdrant -> Chromem\n")
+	fmt.Fprintf(progress(), "  Source: %s:%d\n", qdrantHost, qdrantPort)
+	fmt.Fprintf(progress(), "  Destination: %s\n", expandedPath)
+	fmt.Fprintf(progress(), "  Batch size: %d\n", batchSize)
 	if dryRun {
-		fmt.Printf("  Mode: DRY RUN (no changes will be made)\n")
+		fmt.Fprintf(progress(), "  Mode: DRY RUN (no changes will be made)\n")
 	}
-	fmt.Println()
+	fmt.Fprintln(progress())
 
 	// Connect to Qdrant
 	qdrantAddr := fmt.Sprintf("%s:%d", qdrantHost, qdrantPort)
@@ -101,10 +108,10 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		}
 		collections = append(collections, result...)
 		if len(collections) == 0 {
-			fmt.Println("No collections found in Qdrant")
-			return nil
+			fmt.Fprintln(progress(), "No collections found in Qdrant")
+			return render(MigrationResult{Collections: []string{}, DryRun: dryRun}, func() error { return nil })
 		}
-		fmt.Printf("Found %d collections to migrate\n\n", len(collections))
+		fmt.Fprintf(progress(), "Found %d collections to migrate\n\n", len(collections))
 	} else {
 		collections = []string{qdrantCollection}
 	}
@@ -131,19 +138,21 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		totalDocs += count
 	}
 
-	fmt.Printf("\n========================================\n")
-	if dryRun {
-		fmt.Printf("DRY RUN: Would migrate %d documents from %d collection(s)\n", totalDocs, len(collections))
-	} else {
-		fmt.Printf("Migration complete: %d documents from %d collection(s)\n", totalDocs, len(collections))
-	}
-	fmt.Printf("========================================\n")
-
-	return nil
+	result := MigrationResult{Collections: collections, Documents: totalDocs, DryRun: dryRun}
+	return render(result, func() error {
+		fmt.Printf("\n========================================\n")
+		if dryRun {
+			fmt.Printf("DRY RUN: Would migrate %d documents from %d collection(s)\n", totalDocs, len(collections))
+		} else {
+			fmt.Printf("Migration complete: %d documents from %d collection(s)\n", totalDocs, len(collections))
+		}
+		fmt.Printf("========================================\n")
+		return nil
+	})
 }
 
 func migrateCollection(ctx context.Context, client *qdrant.Client, chromemDB *chromem.DB, collName string) (int, error) {
-	fmt.Printf("Migrating collection: %s\n", collName)
+	fmt.Fprintf(progress(), "Migrating collection: %s\n", collName)
 
 	// Get collection info
 	collInfo, err := client.GetCollectionInfo(ctx, collName)
@@ -154,11 +163,11 @@ func migrateCollection(ctx context.Context, client *qdrant.Client, chromemDB *ch
 	pointCount := collInfo.GetPointsCount()
 	vectorSize := collInfo.GetConfig().GetParams().GetVectorsConfig().GetParams().GetSize()
 
-	fmt.Printf("  Points: %d\n", pointCount)
-	fmt.Printf("  Vector size: %d\n", vectorSize)
+	fmt.Fprintf(progress(), "  Points: %d\n", pointCount)
+	fmt.Fprintf(progress(), "  Vector size: %d\n", vectorSize)
 
 	if pointCount == 0 {
-		fmt.Printf("  Skipping empty collection\n\n")
+		fmt.Fprintf(progress(), "  Skipping empty collection\n\n")
 		return 0, nil
 	}
 
@@ -207,7 +216,7 @@ func migrateCollection(ctx context.Context, client *qdrant.Client, chromemDB *ch
 		}
 
 		batchNum++
-		fmt.Printf("  Batch %d: %d points", batchNum, len(points))
+		fmt.Fprintf(progress(), "  Batch %d: %d points", batchNum, len(points))
 
 		if !dryRun && chromemColl != nil {
 			// Convert and insert into Chromem
@@ -224,9 +233,9 @@ func migrateCollection(ctx context.Context, client *qdrant.Client, chromemDB *ch
 					return migratedCount, fmt.Errorf("failed to add documents to Chromem: %w", err)
 				}
 			}
-			fmt.Printf(" -> migrated %d\n", len(docs))
+			fmt.Fprintf(progress(), " -> migrated %d\n", len(docs))
 		} else {
-			fmt.Printf(" (dry run)\n")
+			fmt.Fprintf(progress(), " (dry run)\n")
 		}
 
 		migratedCount += len(points)
@@ -238,7 +247,7 @@ func migrateCollection(ctx context.Context, client *qdrant.Client, chromemDB *ch
 		offset = nextOffset
 	}
 
-	fmt.Printf("  Total migrated: %d\n\n", migratedCount)
+	fmt.Fprintf(progress(), "  Total migrated: %d\n\n", migratedCount)
 	return migratedCount, nil
 }
 
@@ -0,0 +1,131 @@
+// Package main implements output formatting for the ctxd CLI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+var (
+	// outputFormat is the global --output flag
+	outputFormat string
+	// jsonShorthand is the global --json flag, kept for existing scripts
+	jsonShorthand bool
+
+	// stdout is where results are rendered, replaced in tests
+	stdout io.Writer = os.Stdout
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", formatTable, "Output format: table, json, or yaml")
+	rootCmd.PersistentFlags().BoolVar(&jsonShorthand, "json", false, "Shorthand for --output json")
+	_ = rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{formatTable, formatJSON, formatYAML}, cobra.ShellCompDirectiveNoFileComp))
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		useServerSocket()
+		return resolveOutputFormat(cmd.Flags().Changed("output"))
+	}
+}
+
+// resolveOutputFormat validates --output and applies --json. explicit
+// reports whether --output was given on the command line.
+func resolveOutputFormat(explicit bool) error {
+	if jsonShorthand {
+		if explicit && outputFormat != formatJSON {
+			return fmt.Errorf("--json conflicts with --output %s", outputFormat)
+		}
+		outputFormat = formatJSON
+	}
+	switch outputFormat {
+	case formatTable, formatJSON, formatYAML:
+		return nil
+	default:
+		return fmt.Errorf("--output must be table, json, or yaml")
+	}
+}
+
+// structuredOutput reports whether results are rendered as JSON or YAML.
+func structuredOutput() bool {
+	return outputFormat != formatTable
+}
+
+// render writes v to stdout in the --output format. table prints the
+// human-readable form and is only called for table output, so commands
+// keep their existing text while JSON and YAML share one encoding of v.
+func render(v interface{}, table func() error) error {
+	switch outputFormat {
+	case formatJSON:
+		return outputJSON(v)
+	case formatYAML:
+		return outputYAML(v)
+	default:
+		return table()
+	}
+}
+
+// progress returns where commands print human-readable progress: stdout
+// for table output and stderr otherwise, so that stdout carries exactly one
+// JSON or YAML document.
+func progress() io.Writer {
+	if structuredOutput() {
+		return os.Stderr
+	}
+	return stdout
+}
+
+func outputJSON(v interface{}) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// outputYAML writes v as YAML. v is encoded as JSON first so YAML output
+// uses the same field names, omissions and value formats as JSON output.
+func outputYAML(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// Decoding into a node keeps the JSON field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = stdout.Write(buf.Bytes())
+	return err
+}
+
+// blockStyle clears the flow and quoting styles decoded from JSON so the
+// encoder picks plain block YAML, quoting only where needed.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
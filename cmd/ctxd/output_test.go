@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+// withOutput sets the output format and captures rendered output for the
+// duration of a test.
+func withOutput(t *testing.T, format string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prevFormat, prevStdout := outputFormat, stdout
+	outputFormat, stdout = format, &buf
+	t.Cleanup(func() { outputFormat, stdout = prevFormat, prevStdout })
+	return &buf
+}
+
+func TestResolveOutputFormat(t *testing.T) {
+	t.Cleanup(func() { outputFormat, jsonShorthand = formatTable, false })
+
+	tests := []struct {
+		name     string
+		format   string
+		explicit bool
+		json     bool
+		want     string
+		wantErr  string
+	}{
+		{name: "default", format: formatTable, want: formatTable},
+		{name: "yaml", format: formatYAML, explicit: true, want: formatYAML},
+		{name: "json shorthand", format: formatTable, json: true, want: formatJSON},
+		{name: "json shorthand with output json", format: formatJSON, explicit: true, json: true, want: formatJSON},
+		{name: "json shorthand conflicts", format: formatYAML, explicit: true, json: true, wantErr: "--json conflicts with --output yaml"},
+		{name: "unknown format", format: "xml", explicit: true, wantErr: "--output must be table, json, or yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFormat, jsonShorthand = tt.format, tt.json
+			err := resolveOutputFormat(tt.explicit)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, outputFormat)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	v := struct {
+		Name    string   `json:"name"`
+		Flag    string   `json:"flag"`
+		Notes   string   `json:"notes"`
+		Count   int      `json:"count"`
+		Tags    []string `json:"tags"`
+		Omitted string   `json:"omitted,omitempty"`
+	}{Name: "alpha", Flag: "true", Notes: "line one\nline two", Count: 3, Tags: []string{"go"}}
+
+	t.Run("table", func(t *testing.T) {
+		buf := withOutput(t, formatTable)
+		called := false
+		require.NoError(t, render(v, func() error {
+			called = true
+			return nil
+		}))
+		assert.True(t, called)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := withOutput(t, formatJSON)
+		require.NoError(t, render(v, func() error {
+			t.Fatal("table output called for json")
+			return nil
+		}))
+		assert.JSONEq(t, `{"name":"alpha","flag":"true","notes":"line one\nline two","count":3,"tags":["go"]}`, buf.String())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		buf := withOutput(t, formatYAML)
+		require.NoError(t, render(v, func() error {
+			t.Fatal("table output called for yaml")
+			return nil
+		}))
+		assert.Equal(t, `name: alpha
+flag: "true"
+notes: |-
+  line one
+  line two
+count: 3
+tags:
+  - go
+`, buf.String())
+	})
+}
+
+func TestObjectSchema(t *testing.T) {
+	for _, name := range objectNames() {
+		t.Run(name, func(t *testing.T) {
+			schema, err := objectSchema(name)
+			require.NoError(t, err)
+			assert.Equal(t, "ctxd "+name, schema.Title)
+			assert.Equal(t, "object", schema.Type)
+			assert.Contains(t, schema.Required, objectRequiredField(name))
+		})
+	}
+
+	_, err := objectSchema("session")
+	assert.ErrorContains(t, err, `unknown object "session"`)
+}
+
+// objectRequiredField returns a field every object of the named kind has.
+func objectRequiredField(name string) string {
+	switch name {
+	case "checkpoint-resume":
+		return "checkpoint"
+	case "checkpoint-tree":
+		return "checkpoint_id"
+	}
+	return "id"
+}
+
+func TestOutputObjects(t *testing.T) {
+	created := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	t.Run("memory", func(t *testing.T) {
+		obj := scoredMemoryObject(reasoningbank.ScoredMemory{
+			Memory: reasoningbank.Memory{
+				ID:         "mem_1",
+				ProjectID:  "api",
+				Title:      "Retry with backoff",
+				Outcome:    reasoningbank.OutcomeSuccess,
+				Confidence: 0.8,
+				State:      reasoningbank.MemoryStateActive,
+				CreatedAt:  created,
+			},
+			Relevance: 0.7,
+			Highlight: &reasoningbank.ChunkHighlight{Text: "use exponential backoff"},
+		})
+		assert.Equal(t, "success", obj.Outcome)
+		assert.Equal(t, "active", obj.State)
+		assert.Equal(t, []string{}, obj.Tags, "tags are an array, never null")
+		assert.Equal(t, 0.7, obj.Relevance)
+		assert.Equal(t, "use exponential backoff", obj.Highlight)
+	})
+
+	t.Run("checkpoint", func(t *testing.T) {
+		obj := checkpointObject(&checkpoint.Checkpoint{
+			ID:         "ckpt_1",
+			Name:       "Before refactor",
+			TokenCount: 1200,
+			Annotation: &checkpoint.Annotation{Outcome: checkpoint.OutcomeSucceeded, PRURL: "https://example.com/pr/1"},
+			CreatedAt:  created,
+		})
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"token_count":1200`)
+		assert.Contains(t, string(data), `"annotation":{"outcome":"succeeded","pr_url":"https://example.com/pr/1"`)
+		assert.NotContains(t, string(data), "full_state")
+	})
+}
+
+func TestRemediationSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/remediations/search", r.URL.Path)
+		var req ctxhttp.RemediationSearchRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "undefined: NewClient", req.Query)
+		assert.Equal(t, "acme", req.TenantID)
+		assert.Equal(t, 5, req.Limit)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ctxhttp.RemediationSearchResponse{
+			Remediations: []ctxhttp.RemediationSearchHit{{ID: "rem_1", Title: "Import the client package", Category: "compile", Confidence: 0.9, Score: 0.75}},
+			Count:        1,
+		})
+	}))
+	defer server.Close()
+
+	prevServer := serverURL
+	serverURL = server.URL
+	t.Cleanup(func() {
+		serverURL = prevServer
+		remTenantID, remLimit = "", 10
+	})
+	remTenantID, remLimit = "acme", 5
+
+	buf := withOutput(t, formatJSON)
+	require.NoError(t, runRemediationSearch(nil, []string{"undefined: NewClient"}))
+
+	var got []RemediationObject
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, []RemediationObject{{
+		ID:         "rem_1",
+		Title:      "Import the client package",
+		Category:   "compile",
+		Confidence: 0.9,
+		Score:      0.75,
+	}}, got)
+}
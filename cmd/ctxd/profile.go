@@ -0,0 +1,172 @@
+// Package main implements profile capture commands for the ctxd CLI.
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// profile capture flags
+	profileType       string
+	profileSeconds    int
+	profileOutputFile string
+	profileAdminToken string
+)
+
+// profileTypes are the profiles ctxd profile capture accepts.
+var profileTypes = []string{"cpu", "heap", "allocs", "goroutine", "block", "mutex"}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileCaptureCmd)
+
+	profileCaptureCmd.Flags().StringVar(&profileType, "type", "cpu", "Profile to capture: cpu, heap, allocs, goroutine, block, mutex")
+	profileCaptureCmd.Flags().IntVar(&profileSeconds, "seconds", 30, "Duration of a CPU profile")
+	profileCaptureCmd.Flags().StringVarP(&profileOutputFile, "output-file", "o", "", "File to write (default: contextd-<type>-<time>.pprof)")
+	profileCaptureCmd.Flags().StringVar(&profileAdminToken, "admin-token", "", "Server admin token (default: $SERVER_ADMIN_TOKEN)")
+
+	_ = profileCaptureCmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(
+		profileTypes, cobra.ShellCompDirectiveNoFileComp))
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Profile a running contextd server",
+	Long:  `Capture Go runtime profiles from a running contextd server.`,
+}
+
+var profileCaptureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "Capture a CPU or heap profile to a file",
+	Long: `Capture a CPU, heap or other runtime profile from a running contextd
+server and write it to a file for go tool pprof.
+
+Profiles are read from the server's /debug/pprof endpoints, which are only
+served when the server has an admin token (SERVER_ADMIN_TOKEN or
+server.admin_token). Pass the same token with --admin-token or
+SERVER_ADMIN_TOKEN.
+
+Examples:
+  # 30 second CPU profile
+  ctxd profile capture
+
+  # Heap profile to a named file
+  ctxd profile capture --type heap -o heap.pprof
+
+  # Inspect a capture
+  go tool pprof -top contextd-cpu-20260102-150405.pprof`,
+	Args: cobra.NoArgs,
+	RunE: runProfileCapture,
+}
+
+func runProfileCapture(cmd *cobra.Command, args []string) error {
+	token := profileAdminToken
+	if token == "" {
+		token = os.Getenv("SERVER_ADMIN_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("admin token required: pass --admin-token or set SERVER_ADMIN_TOKEN")
+	}
+
+	endpoint, timeout, err := profileEndpoint(profileType, profileSeconds)
+	if err != nil {
+		return err
+	}
+
+	output := profileOutputFile
+	if output == "" {
+		output = fmt.Sprintf("contextd-%s-%s.pprof", profileType, time.Now().Format("20060102-150405"))
+	}
+
+	if profileType == "cpu" {
+		fmt.Fprintf(os.Stderr, "Capturing %ds CPU profile...\n", profileSeconds)
+	}
+	n, err := captureProfile(endpoint, token, timeout, output)
+	if err != nil {
+		return fmt.Errorf("failed to capture %s profile: %w", profileType, err)
+	}
+
+	result := profileCaptureResult{Type: profileType, File: output, Bytes: n}
+	return render(result, func() error {
+		fmt.Printf("Wrote %s profile to %s (%d bytes)\n", result.Type, result.File, result.Bytes)
+		fmt.Printf("Inspect it with: go tool pprof %s\n", result.File)
+		return nil
+	})
+}
+
+// profileCaptureResult is the --output form of ctxd profile capture.
+type profileCaptureResult struct {
+	Type  string `json:"type"`
+	File  string `json:"file"`
+	Bytes int64  `json:"bytes"`
+}
+
+// profileEndpoint returns the URL of a profile and the time to allow for
+// capturing it.
+func profileEndpoint(typ string, seconds int) (string, time.Duration, error) {
+	switch typ {
+	case "cpu":
+		if seconds <= 0 {
+			return "", 0, fmt.Errorf("--seconds must be positive")
+		}
+		query := url.Values{"seconds": {fmt.Sprint(seconds)}}
+		return fmt.Sprintf("%s/debug/pprof/profile?%s", serverURL, query.Encode()),
+			time.Duration(seconds)*time.Second + 30*time.Second, nil
+	case "heap", "allocs", "goroutine", "block", "mutex":
+		return fmt.Sprintf("%s/debug/pprof/%s", serverURL, typ), 30 * time.Second, nil
+	default:
+		return "", 0, fmt.Errorf("unknown profile type %q (valid: cpu, heap, allocs, goroutine, block, mutex)", typ)
+	}
+}
+
+// captureProfile downloads a profile to output and returns its size. The
+// file is only created once the server has accepted the request.
+func captureProfile(endpoint, token string, timeout time.Duration, output string) (int64, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, fmt.Errorf("profiling is not enabled on the server (set SERVER_ADMIN_TOKEN)")
+	case http.StatusUnauthorized:
+		return 0, fmt.Errorf("server rejected the admin token")
+	default:
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return 0, fmt.Errorf("server returned %d (failed to read response: %v)", resp.StatusCode, readErr)
+		}
+		return 0, fmt.Errorf("server returned %d: %s", resp.StatusCode, body)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileEndpoint(t *testing.T) {
+	oldServerURL := serverURL
+	serverURL = "http://localhost:9090"
+	defer func() { serverURL = oldServerURL }()
+
+	endpoint, timeout, err := profileEndpoint("cpu", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9090/debug/pprof/profile?seconds=10", endpoint)
+	assert.Greater(t, timeout, 10*time.Second)
+
+	endpoint, _, err = profileEndpoint("heap", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9090/debug/pprof/heap", endpoint)
+
+	_, _, err = profileEndpoint("cpu", 0)
+	assert.Error(t, err)
+	_, _, err = profileEndpoint("threadcreate", 10)
+	assert.Error(t, err)
+}
+
+func TestCaptureProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("profile"))
+	}))
+	defer server.Close()
+
+	t.Run("writes the profile", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "heap.pprof")
+		n, err := captureProfile(server.URL+"/debug/pprof/heap", "s3cret", time.Second, output)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), n)
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Equal(t, "profile", string(data))
+	})
+
+	t.Run("rejected token leaves no file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "heap.pprof")
+		_, err := captureProfile(server.URL+"/debug/pprof/heap", "wrong", time.Second, output)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rejected the admin token")
+		assert.NoFileExists(t, output)
+	})
+}
+
+func TestRunProfileCapture_Output(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("heap-profile"))
+	}))
+	defer server.Close()
+
+	oldServerURL, oldType, oldFile, oldToken := serverURL, profileType, profileOutputFile, profileAdminToken
+	t.Cleanup(func() {
+		serverURL, profileType, profileOutputFile, profileAdminToken = oldServerURL, oldType, oldFile, oldToken
+	})
+	output := filepath.Join(t.TempDir(), "heap.pprof")
+	serverURL, profileType, profileOutputFile, profileAdminToken = server.URL, "heap", output, "s3cret"
+
+	buf := withOutput(t, formatJSON)
+	require.NoError(t, runProfileCapture(profileCaptureCmd, nil))
+	var result profileCaptureResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, profileCaptureResult{Type: "heap", File: output, Bytes: int64(len("heap-profile"))}, result)
+}
@@ -0,0 +1,321 @@
+// Package main implements remediation commands for the ctxd CLI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+)
+
+var (
+	// remediation command flags
+	remProjectPath      string
+	remTenantID         string
+	remTeamID           string
+	remScope            string
+	remCategory         string
+	remMinConfidence    float64
+	remIncludeHierarchy bool
+	remLimit            int
+	remTo               string
+	remSplit            []string
+	remDryRun           bool
+)
+
+func init() {
+	rootCmd.AddCommand(remediationCmd)
+	remediationCmd.AddCommand(remediationSearchCmd)
+	remediationCmd.AddCommand(remediationCategoriesCmd)
+	remediationCmd.AddCommand(remediationMigrateCategoryCmd)
+
+	remediationSearchCmd.Flags().StringVar(&remProjectPath, "project-path", "", "Project path (defaults to current directory)")
+	remediationSearchCmd.Flags().StringVar(&remTenantID, "tenant-id", "", "Tenant identifier (defaults to the project's tenant)")
+	remediationSearchCmd.Flags().StringVar(&remTeamID, "team-id", "", "Team identifier")
+	remediationSearchCmd.Flags().StringVar(&remScope, "scope", "", "Only search this scope: project, team, or org")
+	remediationSearchCmd.Flags().StringVar(&remCategory, "category", "", "Only search this error category, e.g. compile or test")
+	remediationSearchCmd.Flags().Float64Var(&remMinConfidence, "min-confidence", 0, "Minimum remediation confidence (0-1)")
+	remediationSearchCmd.Flags().BoolVar(&remIncludeHierarchy, "include-hierarchy", false, "Also search team and org scopes above --scope")
+	remediationSearchCmd.Flags().IntVar(&remLimit, "limit", 10, "Maximum number of remediations to return")
+
+	_ = remediationSearchCmd.RegisterFlagCompletionFunc("scope", cobra.FixedCompletions(
+		[]string{"project", "team", "org"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = remediationSearchCmd.RegisterFlagCompletionFunc("category", cobra.FixedCompletions(
+		[]string{"compile", "runtime", "test", "lint", "security", "performance", "other"}, cobra.ShellCompDirectiveNoFileComp))
+
+	for _, cmd := range []*cobra.Command{remediationCategoriesCmd, remediationMigrateCategoryCmd} {
+		cmd.Flags().StringVar(&remProjectPath, "project-path", "", "Project path (defaults to current directory)")
+		cmd.Flags().StringVar(&remTenantID, "tenant-id", "", "Tenant identifier (defaults to the project's tenant)")
+		cmd.Flags().StringVar(&remTeamID, "team-id", "", "Team identifier")
+		cmd.Flags().StringVar(&remScope, "scope", "", "Only this scope: project, team, or org")
+		cmd.Flags().BoolVar(&remIncludeHierarchy, "include-hierarchy", false, "Also include team and org scopes above --scope")
+		_ = cmd.RegisterFlagCompletionFunc("scope", cobra.FixedCompletions(
+			[]string{"project", "team", "org"}, cobra.ShellCompDirectiveNoFileComp))
+	}
+	remediationMigrateCategoryCmd.Flags().StringVar(&remTo, "to", "", "Category for remediations no --split rule matches")
+	remediationMigrateCategoryCmd.Flags().StringArrayVar(&remSplit, "split", nil, "Move matching remediations: category=keyword[,keyword...] (repeatable, first match wins)")
+	remediationMigrateCategoryCmd.Flags().BoolVar(&remDryRun, "dry-run", false, "Show what would move without changing anything")
+}
+
+var remediationCmd = &cobra.Command{
+	Use:   "remediation",
+	Short: "Search recorded error fixes",
+	Long: `Search the error fixes recorded by a running contextd server and manage
+their categories.`,
+}
+
+var remediationSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search remediations",
+	Long: `Search remediations by error message or problem description.
+
+With --output json or yaml each remediation is printed in the stable format
+described by "ctxd schema remediation".
+
+Examples:
+  # Fixes for an error in the current project
+  ctxd remediation search "undefined: NewClient"
+
+  # High-confidence test fixes across the organization, as JSON
+  ctxd remediation search "flaky timeout" --category test --scope org --min-confidence 0.8 --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemediationSearch,
+}
+
+var remediationCategoriesCmd = &cobra.Command{
+	Use:   "categories",
+	Short: "Show remediation counts per category",
+	Long: `Show how many remediations are recorded under each category.
+
+Every category of the tenant's taxonomy is listed, including unused ones.
+Categories marked "no" in the TAXONOMY column are not part of the taxonomy,
+for example after a rename; move them with "ctxd remediation migrate-category".
+
+Examples:
+  # Categories used across the organization
+  ctxd remediation categories --scope org`,
+	Args: cobra.NoArgs,
+	RunE: runRemediationCategories,
+}
+
+var remediationMigrateCategoryCmd = &cobra.Command{
+	Use:   "migrate-category <from>",
+	Short: "Rename or split a remediation category",
+	Long: `Move the remediations recorded under a category to categories of the
+tenant's taxonomy.
+
+Each --split rule moves remediations whose title, problem, root cause,
+symptoms or tags contain one of its keywords (case-insensitive); the first
+matching rule wins. Remediations no rule matches move to --to, or stay put
+without it. Run with --dry-run first to review the moves.
+
+Examples:
+  # Rename a category
+  ctxd remediation migrate-category network --to connectivity --scope org
+
+  # Split a category, leaving the rest in connectivity
+  ctxd remediation migrate-category network --scope org \
+    --split "timeout=timed out,deadline exceeded" --to connectivity --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemediationMigrateCategory,
+}
+
+func runRemediationSearch(cmd *cobra.Command, args []string) error {
+	if remLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	path, err := remediationProjectPath()
+	if err != nil {
+		return err
+	}
+
+	result, err := searchRemediations(ctxhttp.RemediationSearchRequest{
+		Query:            args[0],
+		ProjectPath:      path,
+		TenantID:         remTenantID,
+		TeamID:           remTeamID,
+		Scope:            remScope,
+		Category:         remCategory,
+		MinConfidence:    remMinConfidence,
+		IncludeHierarchy: remIncludeHierarchy,
+		Limit:            remLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search remediations: %w", err)
+	}
+
+	remediations := make([]RemediationObject, 0, len(result.Remediations))
+	for _, hit := range result.Remediations {
+		remediations = append(remediations, remediationObject(hit))
+	}
+	return render(remediations, func() error {
+		if len(remediations) == 0 {
+			fmt.Println("No remediations found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCATEGORY\tCONFIDENCE\tSCORE\tTITLE")
+		for _, r := range remediations {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%s\n",
+				r.ID, orDash(r.Category), r.Confidence, r.Score, truncate(r.Title, 60))
+		}
+		return w.Flush()
+	})
+}
+
+func runRemediationCategories(cmd *cobra.Command, args []string) error {
+	path, err := remediationProjectPath()
+	if err != nil {
+		return err
+	}
+
+	var result ctxhttp.RemediationCategoriesResponse
+	err = postRemediations("categories", ctxhttp.RemediationCategoriesRequest{
+		ProjectPath:      path,
+		TenantID:         remTenantID,
+		TeamID:           remTeamID,
+		Scope:            remScope,
+		IncludeHierarchy: remIncludeHierarchy,
+	}, &result)
+	if err != nil {
+		return fmt.Errorf("failed to get remediation categories: %w", err)
+	}
+
+	return render(result, func() error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CATEGORY\tBUILTIN\tTAXONOMY\tCOUNT\tPENDING\tAVG CONFIDENCE\tLAST RECORDED")
+		for _, c := range result.Categories {
+			last := "-"
+			if !c.LastRecorded.IsZero() {
+				last = c.LastRecorded.Format("2006-01-02")
+			}
+			taxonomy := "yes"
+			if !c.InTaxonomy {
+				taxonomy = "no"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%.2f\t%s\n",
+				c.Category, orDash(string(c.Builtin)), taxonomy, c.Count, c.Pending, c.AvgConfidence, last)
+		}
+		return w.Flush()
+	})
+}
+
+func runRemediationMigrateCategory(cmd *cobra.Command, args []string) error {
+	split := make([]remediation.SplitRule, 0, len(remSplit))
+	for _, rule := range remSplit {
+		category, keywords, ok := strings.Cut(rule, "=")
+		if !ok || category == "" || keywords == "" {
+			return fmt.Errorf("invalid --split %q: want category=keyword[,keyword...]", rule)
+		}
+		split = append(split, remediation.SplitRule{
+			Category: remediation.ErrorCategory(category),
+			Keywords: strings.Split(keywords, ","),
+		})
+	}
+	if remTo == "" && len(split) == 0 {
+		return fmt.Errorf("--to or --split is required")
+	}
+
+	path, err := remediationProjectPath()
+	if err != nil {
+		return err
+	}
+
+	var result remediation.MigrateCategoryResult
+	err = postRemediations("categories/migrate", ctxhttp.RemediationCategoryMigrateRequest{
+		ProjectPath:      path,
+		TenantID:         remTenantID,
+		TeamID:           remTeamID,
+		Scope:            remScope,
+		IncludeHierarchy: remIncludeHierarchy,
+		From:             args[0],
+		To:               remTo,
+		Split:            split,
+		DryRun:           remDryRun,
+	}, &result)
+	if err != nil {
+		return fmt.Errorf("failed to migrate remediation category: %w", err)
+	}
+
+	return render(result, func() error {
+		verb := "Moved"
+		if result.DryRun {
+			verb = "Would move"
+		}
+		fmt.Printf("%s %d remediation(s) out of %s\n", verb, len(result.Moves), result.From)
+		if result.Unmatched > 0 {
+			fmt.Printf("%d remediation(s) matched no --split rule and stay in %s\n", result.Unmatched, result.From)
+		}
+		if len(result.Moves) == 0 {
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nID\tTO\tTITLE")
+		for _, m := range result.Moves {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", m.ID, m.To, truncate(m.Title, 60))
+		}
+		return w.Flush()
+	})
+}
+
+// remediationProjectPath returns --project-path, defaulting to the current
+// directory unless --tenant-id is set.
+func remediationProjectPath() (string, error) {
+	if remProjectPath != "" || remTenantID != "" {
+		return remProjectPath, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return cwd, nil
+}
+
+// searchRemediations posts req to /api/v1/remediations/search.
+func searchRemediations(req ctxhttp.RemediationSearchRequest) (*ctxhttp.RemediationSearchResponse, error) {
+	var result ctxhttp.RemediationSearchResponse
+	if err := postRemediations("search", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// postRemediations posts req to /api/v1/remediations/<action> and decodes
+// the response into out.
+func postRemediations(action string, req, out any) error {
+	reqJSON, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/remediations/%s", serverURL, action)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(reqJSON))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("server returned %d (failed to read response: %v)", resp.StatusCode, readErr)
+		}
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
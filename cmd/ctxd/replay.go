@@ -0,0 +1,145 @@
+// Package main implements the session replay command for the ctxd CLI.
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+// replayShownIDs is how many result IDs a timeline row lists.
+const replayShownIDs = 5
+
+var (
+	// replay command flags
+	replaySession string
+	replayDays    int
+)
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().StringVar(&replaySession, "session", "", "Session identifier to replay (required)")
+	replayCmd.Flags().IntVar(&replayDays, "days", ctxhttp.DefaultStatsDays, "Number of days to search, ending today")
+	_ = replayCmd.MarkFlagRequired("session")
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay a session's contextd interactions",
+	Long: `Show the tool calls a session made to contextd as a timeline: searches
+issued and the memories they returned, feedback and outcomes given,
+checkpoints saved, and failures, to diagnose why an agent did or didn't
+find a memory.
+
+Calls are journaled by the contextd server when they pass session_id, with
+secrets scrubbed from queries and only IDs of the results kept. Journals are
+kept for ANALYTICS_SESSION_RETENTION_DAYS (default 14) and read from
+GET /api/v1/stats/sessions/:id, which only answers requests from localhost.
+
+Examples:
+  # Timeline of a session from the last 7 days
+  ctxd replay --session sess-123
+
+  # A session from last month, as JSON
+  ctxd replay --session sess-123 --days 30 --json`,
+	RunE: runReplay,
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	replay, err := fetchSessionReplay()
+	if err != nil {
+		return fmt.Errorf("failed to fetch session replay: %w", err)
+	}
+
+	return render(replay, func() error {
+		return writeReplay(stdout, replay)
+	})
+}
+
+func fetchSessionReplay() (*ctxhttp.SessionReplayResponse, error) {
+	query := url.Values{}
+	query.Set("days", fmt.Sprint(replayDays))
+	var replay ctxhttp.SessionReplayResponse
+	if err := fetchStats("/api/v1/stats/sessions/"+url.PathEscape(replaySession), query, &replay); err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
+
+// writeReplay renders the session's events as a timeline.
+func writeReplay(w io.Writer, replay *ctxhttp.SessionReplayResponse) error {
+	if len(replay.Events) == 0 {
+		fmt.Fprintf(w, "No calls recorded for session %s from %s to %s\n", replay.SessionID, replay.From, replay.To)
+		fmt.Fprintln(w, "Only tool calls that pass session_id are journaled.")
+		return nil
+	}
+
+	events := replay.Events
+	start, end := events[0].Time.UTC(), events[len(events)-1].Time.UTC()
+	failed := 0
+	for _, e := range events {
+		if e.Failed {
+			failed++
+		}
+	}
+	fmt.Fprintf(w, "Session %s: %d calls, %d failed, %s to %s UTC\n\n",
+		replay.SessionID, len(events), failed,
+		start.Format("2006-01-02 15:04:05"), end.Format("15:04:05"))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tELAPSED\tTOOL\tPROJECT\tMS\tDETAILS")
+	for _, e := range events {
+		fmt.Fprintf(tw, "%s\t+%s\t%s\t%s\t%.0f\t%s\n",
+			e.Time.UTC().Format("15:04:05"),
+			e.Time.Sub(start).Round(time.Second),
+			e.Tool,
+			orDash(truncate(e.ProjectID, 20)),
+			e.DurationMs,
+			replayDetails(e),
+		)
+	}
+	return tw.Flush()
+}
+
+// replayDetails summarizes what an event asked for and what it got back.
+func replayDetails(e analytics.Event) string {
+	keys := make([]string, 0, len(e.Args))
+	for k := range e.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		if s, ok := e.Args[k].(string); ok {
+			parts = append(parts, fmt.Sprintf("%s=%q", k, s))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, e.Args[k]))
+		}
+	}
+
+	switch {
+	case e.Failed:
+		parts = append(parts, "ERROR: "+orDash(e.Error))
+	case len(e.ResultIDs) > 0:
+		shown := e.ResultIDs[:min(len(e.ResultIDs), replayShownIDs)]
+		result := fmt.Sprintf("-> %d: %s", len(e.ResultIDs), strings.Join(shown, ", "))
+		if more := len(e.ResultIDs) - len(shown); more > 0 {
+			result += fmt.Sprintf(" (+%d more)", more)
+		}
+		parts = append(parts, result)
+	case strings.HasSuffix(e.Tool, "_search"):
+		parts = append(parts, "-> no results")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+func TestFetchSessionReplay(t *testing.T) {
+	start := time.Date(2026, 10, 15, 9, 1, 2, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/stats/sessions/sess-123", r.URL.Path)
+		assert.Equal(t, "30", r.URL.Query().Get("days"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ctxhttp.SessionReplayResponse{
+			SessionID: "sess-123",
+			From:      "2026-09-16",
+			To:        "2026-10-15",
+			Events:    []analytics.Event{{Time: start, SessionID: "sess-123", Tool: "memory_search"}},
+		})
+	}))
+	defer server.Close()
+
+	oldServerURL := serverURL
+	serverURL = server.URL
+	defer func() { serverURL = oldServerURL }()
+
+	replaySession, replayDays = "sess-123", 30
+	defer func() { replaySession, replayDays = "", ctxhttp.DefaultStatsDays }()
+
+	replay, err := fetchSessionReplay()
+	require.NoError(t, err)
+	require.Len(t, replay.Events, 1)
+	assert.Equal(t, "memory_search", replay.Events[0].Tool)
+}
+
+func TestWriteReplay(t *testing.T) {
+	start := time.Date(2026, 10, 15, 9, 1, 2, 0, time.UTC)
+	replay := &ctxhttp.SessionReplayResponse{
+		SessionID: "sess-123",
+		From:      "2026-10-09",
+		To:        "2026-10-15",
+		Events: []analytics.Event{
+			{Time: start, Tool: "memory_search", ProjectID: "api", DurationMs: 38,
+				Args: map[string]any{"query": "retry with backoff", "limit": 5.0}},
+			{Time: start.Add(30 * time.Second), Tool: "memory_search", ProjectID: "api", DurationMs: 41,
+				Args:      map[string]any{"query": "retry policy"},
+				ResultIDs: []string{"m1", "m2", "m3", "m4", "m5", "m6", "m7"}},
+			{Time: start.Add(2 * time.Minute), Tool: "memory_feedback", DurationMs: 5,
+				Args: map[string]any{"memory_id": "m2", "helpful": true}, ResultIDs: []string{"m2"}},
+			{Time: start.Add(3 * time.Minute), Tool: "checkpoint_save", Failed: true, Error: "name is required"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeReplay(&buf, replay))
+	out := buf.String()
+
+	assert.Contains(t, out, "Session sess-123: 4 calls, 1 failed, 2026-10-15 09:01:02 to 09:04:02 UTC")
+	assert.Contains(t, out, `limit=5 query="retry with backoff" -> no results`)
+	assert.Contains(t, out, "-> 7: m1, m2, m3, m4, m5 (+2 more)")
+	assert.Contains(t, out, "+2m0s")
+	assert.Contains(t, out, `helpful=true memory_id="m2" -> 1: m2`)
+	assert.Contains(t, out, "ERROR: name is required")
+
+	buf.Reset()
+	require.NoError(t, writeReplay(&buf, &ctxhttp.SessionReplayResponse{SessionID: "none", From: "2026-10-09", To: "2026-10-15"}))
+	assert.Contains(t, buf.String(), "No calls recorded for session none")
+}
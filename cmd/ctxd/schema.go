@@ -0,0 +1,287 @@
+// Package main implements the stable JSON objects of the ctxd CLI.
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/spf13/cobra"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+// MemoryObject is a memory as printed by ctxd with --output json or yaml.
+// Its fields only change by addition; "ctxd schema memory" prints the schema.
+type MemoryObject struct {
+	ID          string    `json:"id" jsonschema:"memory identifier"`
+	ProjectID   string    `json:"project_id" jsonschema:"project the memory belongs to"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Content     string    `json:"content"`
+	Outcome     string    `json:"outcome" jsonschema:"success or failure"`
+	Confidence  float64   `json:"confidence" jsonschema:"reliability from 0 to 1"`
+	Importance  float64   `json:"importance" jsonschema:"learned usefulness from 0 to 1"`
+	UsageCount  int       `json:"usage_count"`
+	Tags        []string  `json:"tags"`
+	State       string    `json:"state" jsonschema:"active, archived or quarantined"`
+	SessionID   string    `json:"session_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relevance and Highlight are set for search results.
+	Relevance float64 `json:"relevance,omitempty" jsonschema:"search relevance from 0 to 1"`
+	Highlight string  `json:"highlight,omitempty" jsonschema:"best-matching chunk of a long memory"`
+
+	// QuarantineRules is set for quarantined memories.
+	QuarantineRules []string `json:"quarantine_rules,omitempty" jsonschema:"prompt-injection rules that matched"`
+}
+
+// CheckpointObject is a checkpoint as printed by ctxd with --output json or
+// yaml. Its fields only change by addition; "ctxd schema checkpoint" prints
+// the schema.
+type CheckpointObject struct {
+	ID          string            `json:"id" jsonschema:"checkpoint identifier"`
+	SessionID   string            `json:"session_id"`
+	TenantID    string            `json:"tenant_id"`
+	TeamID      string            `json:"team_id"`
+	ProjectID   string            `json:"project_id"`
+	ProjectPath string            `json:"project_path"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Summary     string            `json:"summary"`
+	Context     string            `json:"context,omitempty"`
+	FullState   string            `json:"full_state,omitempty"`
+	TokenCount  int               `json:"token_count"`
+	Threshold   float64           `json:"threshold" jsonschema:"context usage at which an auto checkpoint was saved"`
+	AutoCreated bool              `json:"auto_created"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Annotation  *AnnotationObject `json:"annotation,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+
+	QuarantineRules []string `json:"quarantine_rules,omitempty" jsonschema:"prompt-injection rules that matched; quarantined checkpoints cannot be resumed until released"`
+
+	ParentID string `json:"parent_id,omitempty" jsonschema:"checkpoint this one continues from"`
+}
+
+// AnnotationObject is the outcome annotation of a CheckpointObject.
+type AnnotationObject struct {
+	Outcome     string    `json:"outcome,omitempty" jsonschema:"succeeded, failed, or partial"`
+	PRURL       string    `json:"pr_url,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+	AnnotatedAt time.Time `json:"annotated_at"`
+}
+
+// CheckpointResumeObject is the result of "ctxd checkpoint resume".
+type CheckpointResumeObject struct {
+	Checkpoint CheckpointObject `json:"checkpoint"`
+	Level      string           `json:"level" jsonschema:"summary, context, or full"`
+	Content    string           `json:"content"`
+	TokenCount int              `json:"token_count"`
+}
+
+// CheckpointTreeObject is the result of "ctxd checkpoint tree".
+type CheckpointTreeObject struct {
+	CheckpointID string               `json:"checkpoint_id" jsonschema:"checkpoint the tree was printed for"`
+	Ancestors    []string             `json:"ancestors" jsonschema:"checkpoint IDs from the root of the lineage to checkpoint_id"`
+	Nodes        []CheckpointTreeNode `json:"nodes" jsonschema:"the root and its descendants, depth first"`
+}
+
+// CheckpointTreeNode is a checkpoint in a CheckpointTreeObject.
+type CheckpointTreeNode struct {
+	Checkpoint CheckpointObject `json:"checkpoint"`
+	Depth      int              `json:"depth" jsonschema:"distance from the root"`
+}
+
+// RemediationObject is a remediation as printed by ctxd with --output json
+// or yaml. Its fields only change by addition; "ctxd schema remediation"
+// prints the schema.
+type RemediationObject struct {
+	ID         string  `json:"id" jsonschema:"remediation identifier"`
+	Title      string  `json:"title"`
+	Problem    string  `json:"problem"`
+	RootCause  string  `json:"root_cause"`
+	Solution   string  `json:"solution"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence" jsonschema:"reliability from 0 to 1"`
+
+	// Score is set for search results.
+	Score float64 `json:"score,omitempty" jsonschema:"search score"`
+}
+
+// objectTypes maps the names accepted by "ctxd schema" to their types.
+var objectTypes = map[string]reflect.Type{
+	"memory":            reflect.TypeFor[MemoryObject](),
+	"checkpoint":        reflect.TypeFor[CheckpointObject](),
+	"checkpoint-resume": reflect.TypeFor[CheckpointResumeObject](),
+	"checkpoint-tree":   reflect.TypeFor[CheckpointTreeObject](),
+	"remediation":       reflect.TypeFor[RemediationObject](),
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema <object>",
+	Short: "Print the JSON Schema of a ctxd output object",
+	Long: `Print the JSON Schema of an object that ctxd prints with --output json
+or yaml: memory, checkpoint, checkpoint-resume, checkpoint-tree, or
+remediation.
+
+These objects are stable: fields are only ever added, never renamed or
+removed, so scripts can rely on them across ctxd versions.
+
+Examples:
+  # Schema of memories printed by "ctxd memory search"
+  ctxd schema memory
+
+  # Validate checkpoint output in a script
+  ctxd schema checkpoint > checkpoint.schema.json`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: objectNames(),
+	RunE:      runSchema,
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	schema, err := objectSchema(args[0])
+	if err != nil {
+		return err
+	}
+	// A schema is a JSON document whatever the output format
+	if outputFormat == formatYAML {
+		return outputYAML(schema)
+	}
+	return outputJSON(schema)
+}
+
+// objectSchema returns the JSON Schema of the named output object.
+func objectSchema(name string) (*jsonschema.Schema, error) {
+	t, ok := objectTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown object %q (valid: %v)", name, objectNames())
+	}
+	schema, err := jsonschema.ForType(t, &jsonschema.ForOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate schema: %w", err)
+	}
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "ctxd " + name
+	return schema, nil
+}
+
+func objectNames() []string {
+	names := make([]string, 0, len(objectTypes))
+	for name := range objectTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkpointTreeObject converts the lineage of checkpointID to its output
+// object.
+func checkpointTreeObject(checkpointID string, lineage *checkpoint.Lineage) CheckpointTreeObject {
+	obj := CheckpointTreeObject{CheckpointID: checkpointID, Ancestors: []string{}}
+	for _, cp := range lineage.Ancestors {
+		obj.Ancestors = append(obj.Ancestors, cp.ID)
+	}
+	var walk func(node *checkpoint.LineageNode, depth int)
+	walk = func(node *checkpoint.LineageNode, depth int) {
+		obj.Nodes = append(obj.Nodes, CheckpointTreeNode{Checkpoint: checkpointObject(node.Checkpoint), Depth: depth})
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(lineage.Root, 0)
+	return obj
+}
+
+// memoryObject converts a memory to its output object.
+func memoryObject(m reasoningbank.Memory) MemoryObject {
+	tags := m.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return MemoryObject{
+		ID:          m.ID,
+		ProjectID:   m.ProjectID,
+		Title:       m.Title,
+		Description: m.Description,
+		Content:     m.Content,
+		Outcome:     string(m.Outcome),
+		Confidence:  m.Confidence,
+		Importance:  m.Importance,
+		UsageCount:  m.UsageCount,
+		Tags:        tags,
+		State:       string(m.State),
+		SessionID:   m.SessionID,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+
+		QuarantineRules: m.QuarantineRules,
+	}
+}
+
+// scoredMemoryObject converts a search result to its output object.
+func scoredMemoryObject(sm reasoningbank.ScoredMemory) MemoryObject {
+	obj := memoryObject(sm.Memory)
+	obj.Relevance = sm.Relevance
+	if sm.Highlight != nil {
+		obj.Highlight = sm.Highlight.Text
+	}
+	return obj
+}
+
+// checkpointObject converts a checkpoint to its output object.
+func checkpointObject(cp *checkpoint.Checkpoint) CheckpointObject {
+	obj := CheckpointObject{
+		ID:          cp.ID,
+		SessionID:   cp.SessionID,
+		TenantID:    cp.TenantID,
+		TeamID:      cp.TeamID,
+		ProjectID:   cp.ProjectID,
+		ProjectPath: cp.ProjectPath,
+		Name:        cp.Name,
+		Description: cp.Description,
+		Summary:     cp.Summary,
+		Context:     cp.Context,
+		FullState:   cp.FullState,
+		TokenCount:  int(cp.TokenCount),
+		Threshold:   cp.Threshold,
+		AutoCreated: cp.AutoCreated,
+		Metadata:    cp.Metadata,
+		CreatedAt:   cp.CreatedAt,
+
+		QuarantineRules: cp.QuarantineRules,
+
+		ParentID: cp.ParentID,
+	}
+	if a := cp.Annotation; a != nil {
+		obj.Annotation = &AnnotationObject{
+			Outcome:     string(a.Outcome),
+			PRURL:       a.PRURL,
+			Notes:       a.Notes,
+			AnnotatedAt: a.AnnotatedAt,
+		}
+	}
+	return obj
+}
+
+// remediationObject converts a remediation search hit to its output object.
+func remediationObject(hit ctxhttp.RemediationSearchHit) RemediationObject {
+	return RemediationObject{
+		ID:         hit.ID,
+		Title:      hit.Title,
+		Problem:    hit.Problem,
+		RootCause:  hit.RootCause,
+		Solution:   hit.Solution,
+		Category:   hit.Category,
+		Confidence: hit.Confidence,
+		Score:      hit.Score,
+	}
+}
@@ -0,0 +1,196 @@
+// Package main implements secret management commands for the ctxd CLI.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/fyrsmithlabs/contextd/internal/config"
+)
+
+var (
+	// secrets command flags
+	secretsBackend string
+	secretsReveal  bool
+)
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+
+	secretsCmd.PersistentFlags().StringVar(&secretsBackend, "backend", config.SecretBackendKeychain, "Secret backend: keychain, file, vault or env")
+	secretsGetCmd.Flags().BoolVar(&secretsReveal, "reveal", false, "Print the secret value instead of a masked preview")
+
+	_ = secretsCmd.RegisterFlagCompletionFunc("backend", cobra.FixedCompletions(
+		[]string{config.SecretBackendKeychain, config.SecretBackendFile, config.SecretBackendVault, config.SecretBackendEnv},
+		cobra.ShellCompDirectiveNoFileComp))
+}
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Store and read credentials outside config.yaml",
+	Long: `Store and read credentials such as LLM and embedding API keys in a
+secret backend, so they never live in plaintext config.
+
+Any secret setting in config.yaml can reference a stored secret instead of
+holding it; contextd resolves references at startup:
+
+  llm:
+    anthropic_api_key: keychain:anthropic_api_key
+  embeddings:
+    api_key: vault:contextd/embeddings#api_key
+
+Backends:
+  keychain  OS keychain (macOS Keychain, Secret Service on Linux)
+  file      0600 files in ~/.config/contextd/secrets
+  vault     HashiCorp Vault KV v2 (VAULT_ADDR, VAULT_TOKEN, VAULT_MOUNT)
+  env       environment variables (read-only)`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Store a secret",
+	Long: `Store a secret in a backend. The value is read from stdin; on a
+terminal it is prompted for without echo.
+
+Examples:
+  # Store the Anthropic key in the OS keychain
+  ctxd secrets set anthropic_api_key
+
+  # Store a key in Vault from a pipe
+  vault-print-key | ctxd secrets set --backend vault contextd/llm#anthropic`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsSet,
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <name|reference>",
+	Short: "Check or print a stored secret",
+	Long: `Look up a secret by name in the selected backend, or by a full
+reference such as keychain:anthropic_api_key. The value is masked unless
+--reveal is given.
+
+Examples:
+  # Check that the key resolves
+  ctxd secrets get keychain:anthropic_api_key
+
+  # Print the value for a script
+  ctxd secrets get --reveal anthropic_api_key`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretsGet,
+}
+
+// secretResult is the --output form of ctxd secrets set and get.
+type secretResult struct {
+	Reference string `json:"reference"`        // backend:name, as used in config.yaml
+	Masked    string `json:"masked,omitempty"` // Masked preview of the value (get)
+	Value     string `json:"value,omitempty"`  // Value, only with --reveal (get)
+}
+
+// secretBackendFor returns the backend and name for a name or reference.
+func secretBackendFor(arg string) (config.SecretBackend, string, string, error) {
+	backend, name := secretsBackend, arg
+	if b, n, ok := config.ParseSecretRef(arg); ok {
+		backend, name = b, n
+	}
+	resolver, err := config.NewSecretResolver()
+	if err != nil {
+		return nil, "", "", err
+	}
+	b, err := resolver.Backend(backend)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return b, backend, name, nil
+}
+
+func runSecretsSet(cmd *cobra.Command, args []string) error {
+	b, backend, name, err := secretBackendFor(args[0])
+	if err != nil {
+		return err
+	}
+	value, err := readSecretValue(cmd.InOrStdin(), cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	if err := b.Set(context.Background(), name, value); err != nil {
+		return fmt.Errorf("storing secret: %w", err)
+	}
+	result := secretResult{Reference: backend + ":" + name}
+	return render(result, func() error {
+		fmt.Fprintf(cmd.OutOrStdout(), "Stored. Reference it in config.yaml as: %s\n", result.Reference)
+		return nil
+	})
+}
+
+func runSecretsGet(cmd *cobra.Command, args []string) error {
+	b, backend, name, err := secretBackendFor(args[0])
+	if err != nil {
+		return err
+	}
+	value, err := b.Get(context.Background(), name)
+	if errors.Is(err, config.ErrSecretNotFound) {
+		return fmt.Errorf("no %s secret named %q", backend, name)
+	}
+	if err != nil {
+		return fmt.Errorf("reading secret: %w", err)
+	}
+	result := secretResult{Reference: backend + ":" + name}
+	if secretsReveal {
+		result.Value = value
+	} else {
+		result.Masked = maskSecret(value)
+	}
+	return render(result, func() error {
+		if secretsReveal {
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s = %s\n", result.Reference, result.Masked)
+		return nil
+	})
+}
+
+// readSecretValue reads a secret from in, prompting without echo when in is
+// a terminal.
+func readSecretValue(in io.Reader, prompt io.Writer) (string, error) {
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(prompt, "Secret value: ")
+		raw, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(prompt)
+		if err != nil {
+			return "", fmt.Errorf("reading secret: %w", err)
+		}
+		return requireSecretValue(string(raw))
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("reading secret: %w", err)
+	}
+	return requireSecretValue(line)
+}
+
+func requireSecretValue(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("empty secret value")
+	}
+	return s, nil
+}
+
+// maskSecret shows just enough of a secret to tell keys apart.
+func maskSecret(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + strings.Repeat("*", 8) + s[len(s)-2:]
+}
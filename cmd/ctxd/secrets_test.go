@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/config"
+)
+
+func TestSecretsSetGet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldBackend, oldReveal := secretsBackend, secretsReveal
+	t.Cleanup(func() { secretsBackend, secretsReveal = oldBackend, oldReveal })
+	secretsBackend = config.SecretBackendFile
+
+	var out bytes.Buffer
+	secretsSetCmd.SetIn(strings.NewReader("sk-ant-api03-abcdef\n"))
+	secretsSetCmd.SetOut(&out)
+	require.NoError(t, runSecretsSet(secretsSetCmd, []string{"anthropic_api_key"}))
+	assert.Contains(t, out.String(), "file:anthropic_api_key")
+
+	out.Reset()
+	secretsGetCmd.SetOut(&out)
+	require.NoError(t, runSecretsGet(secretsGetCmd, []string{"file:anthropic_api_key"}))
+	assert.Equal(t, "file:anthropic_api_key = sk-a********ef\n", out.String())
+
+	out.Reset()
+	secretsReveal = true
+	require.NoError(t, runSecretsGet(secretsGetCmd, []string{"anthropic_api_key"}))
+	assert.Equal(t, "sk-ant-api03-abcdef\n", out.String())
+
+	err := runSecretsGet(secretsGetCmd, []string{"missing"})
+	assert.ErrorContains(t, err, `no file secret named "missing"`)
+
+	secretsSetCmd.SetIn(strings.NewReader("\n"))
+	assert.ErrorContains(t, runSecretsSet(secretsSetCmd, []string{"empty"}), "empty secret value")
+}
+
+func TestSecretsGet_Output(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldBackend, oldReveal := secretsBackend, secretsReveal
+	t.Cleanup(func() { secretsBackend, secretsReveal = oldBackend, oldReveal })
+	secretsBackend, secretsReveal = config.SecretBackendFile, false
+
+	secretsSetCmd.SetIn(strings.NewReader("sk-ant-api03-abcdef\n"))
+	secretsSetCmd.SetOut(&bytes.Buffer{})
+	require.NoError(t, runSecretsSet(secretsSetCmd, []string{"anthropic_api_key"}))
+
+	buf := withOutput(t, formatJSON)
+	require.NoError(t, runSecretsGet(secretsGetCmd, []string{"anthropic_api_key"}))
+	var result secretResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, secretResult{Reference: "file:anthropic_api_key", Masked: "sk-a********ef"}, result)
+}
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "****", maskSecret("abcd"))
+	assert.Equal(t, "sk-a********yz", maskSecret("sk-ant-xyz"))
+}
@@ -0,0 +1,205 @@
+// Package main implements tool statistics commands for the ctxd CLI.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+var (
+	// stats command flags
+	statsDays      int
+	statsTool      string
+	statsTenantID  string
+	statsProjectID string
+	statsGroupBy   string
+)
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsToolsCmd)
+	statsCmd.AddCommand(statsExperimentCmd)
+
+	statsToolsCmd.Flags().IntVar(&statsDays, "days", ctxhttp.DefaultStatsDays, "Number of days to include, ending today")
+	statsToolsCmd.Flags().StringVar(&statsTool, "tool", "", "Filter by tool name")
+	statsToolsCmd.Flags().StringVar(&statsTenantID, "tenant-id", "", "Filter by tenant identifier")
+	statsToolsCmd.Flags().StringVar(&statsProjectID, "project-id", "", "Filter by project identifier")
+	statsToolsCmd.Flags().StringVar(&statsGroupBy, "group-by", "tool", "Comma-separated grouping: tool, tenant, project, day")
+
+	_ = statsToolsCmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions(
+		[]string{"tool", "tenant", "project", "day"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show contextd usage statistics",
+	Long:  `Show usage statistics collected by a running contextd server.`,
+}
+
+var statsToolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Show MCP tool usage",
+	Long: `Show MCP tool call counts, latencies, result sizes and error rates.
+
+Statistics are rolled up per day by the contextd server and read from
+GET /api/v1/stats/tools, which only answers requests from localhost.
+
+Examples:
+  # Per-tool usage for the last 7 days
+  ctxd stats tools
+
+  # Daily memory_search usage for one project over the last 30 days
+  ctxd stats tools --days 30 --tool memory_search --project-id contextd --group-by day
+
+  # Usage per tool and tenant as JSON
+  ctxd stats tools --group-by tool,tenant --json`,
+	RunE: runStatsTools,
+}
+
+func runStatsTools(cmd *cobra.Command, args []string) error {
+	stats, err := fetchToolStats()
+	if err != nil {
+		return fmt.Errorf("failed to fetch tool stats: %w", err)
+	}
+
+	return render(stats, func() error {
+		fmt.Printf("Tool usage %s to %s\n\n", stats.From, stats.To)
+		if len(stats.Tools) == 0 {
+			fmt.Println("No tool calls recorded")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DAY\tTOOL\tTENANT\tPROJECT\tCALLS\tERRORS\tERROR RATE\tAVG MS\tP95 MS\tMAX MS\tAVG BYTES")
+		for _, row := range stats.Tools {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%.1f%%\t%.1f\t%.0f\t%.1f\t%.0f\n",
+				orDash(row.Day),
+				orDash(row.Tool),
+				orDash(truncate(row.TenantID, 20)),
+				orDash(truncate(row.ProjectID, 20)),
+				row.Calls,
+				row.Errors,
+				row.ErrorRate*100,
+				row.AvgLatencyMs,
+				row.P95LatencyMs,
+				row.MaxLatencyMs,
+				row.AvgResultBytes,
+			)
+		}
+		return w.Flush()
+	})
+}
+
+func fetchToolStats() (*ctxhttp.ToolStatsResponse, error) {
+	query := url.Values{}
+	query.Set("days", fmt.Sprint(statsDays))
+	query.Set("group_by", statsGroupBy)
+	if statsTool != "" {
+		query.Set("tool", statsTool)
+	}
+	if statsTenantID != "" {
+		query.Set("tenant_id", statsTenantID)
+	}
+	if statsProjectID != "" {
+		query.Set("project_id", statsProjectID)
+	}
+	var stats ctxhttp.ToolStatsResponse
+	if err := fetchStats("/api/v1/stats/tools", query, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+var statsExperimentCmd = &cobra.Command{
+	Use:   "experiment",
+	Short: "Show search ranking experiment results",
+	Long: `Show searches, served memories and outcome success rates per arm of the
+search ranking experiment configured under reasoningbank.experiment.
+
+Totals cover the sessions seen since the contextd server started and are
+read from GET /api/v1/stats/experiment, which only answers requests from
+localhost.
+
+Examples:
+  ctxd stats experiment
+  ctxd stats experiment --json`,
+	RunE: runStatsExperiment,
+}
+
+func runStatsExperiment(cmd *cobra.Command, args []string) error {
+	var report reasoningbank.ExperimentReport
+	if err := fetchStats("/api/v1/stats/experiment", url.Values{}, &report); err != nil {
+		return fmt.Errorf("failed to fetch experiment stats: %w", err)
+	}
+
+	return render(report, func() error {
+		fmt.Printf("Experiment %s (%d%% treatment) since %s\n\n",
+			report.Name, report.TreatmentPercent, report.Since.Format(time.RFC3339))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ARM\tRERANK\tCONSOLIDATION BOOST\tENTITY BOOST\tSESSIONS\tSEARCHES\tSERVED\tOUTCOMES\tSUCCESS RATE")
+		for _, arm := range report.Arms {
+			fmt.Fprintf(w, "%s\t%t\t%s\t%s\t%d\t%d\t%d\t%d\t%.1f%%\n",
+				arm.Arm,
+				arm.Ranking.Rerank,
+				boostOrDefault(arm.Ranking.ConsolidationBoost),
+				boostOrDefault(arm.Ranking.EntityBoost),
+				arm.Sessions,
+				arm.Searches,
+				arm.Served,
+				arm.Outcomes,
+				arm.SuccessRate*100,
+			)
+		}
+		return w.Flush()
+	})
+}
+
+// boostOrDefault renders an unset ranking boost as "default".
+func boostOrDefault(boost float32) string {
+	if boost == 0 {
+		return "default"
+	}
+	return fmt.Sprintf("%.2f", boost)
+}
+
+// fetchStats GETs a stats endpoint and decodes the JSON response into v.
+func fetchStats(path string, query url.Values, v interface{}) error {
+	endpoint := fmt.Sprintf("%s%s?%s", serverURL, path, query.Encode())
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("server returned %d (failed to read response: %v)", resp.StatusCode, readErr)
+		}
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// orDash renders an ungrouped dimension as "-".
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+func TestFetchToolStats(t *testing.T) {
+	t.Run("sends filters and decodes response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/stats/tools", r.URL.Path)
+			assert.Equal(t, "30", r.URL.Query().Get("days"))
+			assert.Equal(t, "tool,project", r.URL.Query().Get("group_by"))
+			assert.Equal(t, "memory_search", r.URL.Query().Get("tool"))
+			assert.Equal(t, "api", r.URL.Query().Get("project_id"))
+			assert.False(t, r.URL.Query().Has("tenant_id"))
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(ctxhttp.ToolStatsResponse{
+				From:    "2026-09-16",
+				To:      "2026-10-15",
+				GroupBy: []string{"tool", "project"},
+				Tools:   []analytics.Summary{{Tool: "memory_search", ProjectID: "api", Calls: 4, Errors: 1, ErrorRate: 0.25}},
+			})
+		}))
+		defer server.Close()
+
+		oldServerURL := serverURL
+		serverURL = server.URL
+		defer func() { serverURL = oldServerURL }()
+
+		statsDays, statsGroupBy, statsTool, statsTenantID, statsProjectID = 30, "tool,project", "memory_search", "", "api"
+		defer func() {
+			statsDays, statsGroupBy, statsTool, statsTenantID, statsProjectID = ctxhttp.DefaultStatsDays, "tool", "", "", ""
+		}()
+
+		stats, err := fetchToolStats()
+		require.NoError(t, err)
+		require.Len(t, stats.Tools, 1)
+		assert.Equal(t, int64(4), stats.Tools[0].Calls)
+		assert.Equal(t, "api", stats.Tools[0].ProjectID)
+	})
+
+	t.Run("returns server error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "tool analytics not configured", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		oldServerURL := serverURL
+		serverURL = server.URL
+		defer func() { serverURL = oldServerURL }()
+
+		_, err := fetchToolStats()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	})
+}
@@ -548,6 +548,12 @@ func runStatuslineUninstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// StatuslineTestResult is the result of "ctxd statusline test".
+type StatuslineTestResult struct {
+	Status     *StatusResponse `json:"status"`
+	Statusline string          `json:"statusline"`
+}
+
 // runStatuslineTest handles the statusline test command
 func runStatuslineTest(cmd *cobra.Command, args []string) error {
 	var status *StatusResponse
@@ -562,28 +568,30 @@ func runStatuslineTest(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to fetch status: %w", err)
 	}
 
-	// Show raw status
-	fmt.Println("=== Raw Status ===")
-	fmt.Printf("Status: %s\n", status.Status)
-	fmt.Printf("Services: %v\n", status.Services)
-	fmt.Printf("Counts: memories=%d, checkpoints=%d\n", status.Counts.Memories, status.Counts.Checkpoints)
-
-	if status.Context != nil {
-		fmt.Printf("Context: usage=%d%%, warning=%v\n", status.Context.UsagePercent, status.Context.ThresholdWarning)
-	}
-	if status.Memory != nil {
-		fmt.Printf("Memory: lastConfidence=%.2f\n", status.Memory.LastConfidence)
-	}
-	if status.Compression != nil {
-		fmt.Printf("Compression: ratio=%.2f, quality=%.2f, ops=%d\n",
-			status.Compression.LastRatio, status.Compression.LastQuality, status.Compression.OperationsTotal)
-	}
+	result := StatuslineTestResult{Status: status, Statusline: formatStatusline(status)}
+	return render(result, func() error {
+		// Show raw status
+		fmt.Println("=== Raw Status ===")
+		fmt.Printf("Status: %s\n", status.Status)
+		fmt.Printf("Services: %v\n", status.Services)
+		fmt.Printf("Counts: memories=%d, checkpoints=%d\n", status.Counts.Memories, status.Counts.Checkpoints)
 
-	// Show formatted line
-	fmt.Println("\n=== Formatted Statusline ===")
-	fmt.Println(formatStatusline(status))
+		if status.Context != nil {
+			fmt.Printf("Context: usage=%d%%, warning=%v\n", status.Context.UsagePercent, status.Context.ThresholdWarning)
+		}
+		if status.Memory != nil {
+			fmt.Printf("Memory: lastConfidence=%.2f\n", status.Memory.LastConfidence)
+		}
+		if status.Compression != nil {
+			fmt.Printf("Compression: ratio=%.2f, quality=%.2f, ops=%d\n",
+				status.Compression.LastRatio, status.Compression.LastQuality, status.Compression.OperationsTotal)
+		}
 
-	return nil
+		// Show formatted line
+		fmt.Println("\n=== Formatted Statusline ===")
+		fmt.Println(result.Statusline)
+		return nil
+	})
 }
 
 // getClaudeSettingsPath returns the path to Claude Code settings
@@ -0,0 +1,309 @@
+// Package main implements the live status view for the ctxd CLI.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+var (
+	// top command flags
+	topInterval time.Duration
+	topOnce     bool
+)
+
+const (
+	// topMaxRows bounds the rows shown per table.
+	topMaxRows = 10
+
+	// topMaxBackoff bounds the wait between reconnects.
+	topMaxBackoff = 10 * time.Second
+
+	// clearScreen moves the cursor home and clears the terminal.
+	clearScreen = "\033[H\033[2J"
+)
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+
+	topCmd.Flags().DurationVar(&topInterval, "interval", ctxhttp.DefaultEventsInterval, "Time between refreshes (1s-1m)")
+	topCmd.Flags().BoolVar(&topOnce, "once", false, "Print one snapshot and exit")
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show live contextd activity",
+	Long: `Show a live view of a running contextd server: service health, sessions
+with active context-folding branches, recent tool calls, vector store
+collection sizes and embedding queue depth.
+
+The view refreshes from the server-sent events stream at GET /api/v1/events,
+which only answers requests from localhost, and reconnects when the server
+restarts. Press Ctrl+C to exit.
+
+Examples:
+  # Live view of the local server
+  ctxd top
+
+  # Refresh every 5 seconds
+  ctxd top --interval 5s
+
+  # Print one snapshot, e.g. for a bug report
+  ctxd top --once
+
+  # One JSON document per snapshot, for scripts
+  ctxd top --output json`,
+	RunE: runTop,
+}
+
+func runTop(cmd *cobra.Command, args []string) error {
+	if topInterval < ctxhttp.MinEventsInterval || topInterval > ctxhttp.MaxEventsInterval {
+		return fmt.Errorf("--interval must be between %s and %s", ctxhttp.MinEventsInterval, ctxhttp.MaxEventsInterval)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if topOnce {
+		err := streamSnapshots(ctx, serverURL, topInterval, func(snap *ctxhttp.LiveSnapshot) error {
+			if err := render(snap, func() error {
+				fmt.Print(renderTop(snap))
+				return nil
+			}); err != nil {
+				return err
+			}
+			return errStopStream
+		})
+		if errors.Is(err, errStopStream) {
+			return nil
+		}
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+
+	// Redraw in place on a terminal; append frames when piped. Structured
+	// output prints one document per snapshot.
+	prefix := ""
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 && !structuredOutput() {
+		prefix = clearScreen
+	}
+
+	backoff := time.Second
+	for {
+		err := streamSnapshots(ctx, serverURL, topInterval, func(snap *ctxhttp.LiveSnapshot) error {
+			backoff = time.Second
+			if outputFormat == formatYAML {
+				fmt.Fprintln(stdout, "---")
+			}
+			return render(snap, func() error {
+				fmt.Print(prefix + renderTop(snap))
+				return nil
+			})
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		fmt.Fprintf(progress(), "%sDisconnected from %s: %v\nRetrying in %s...\n", prefix, serverURL, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, topMaxBackoff)
+	}
+}
+
+// errStopStream ends streamSnapshots without an error of its own.
+var errStopStream = errors.New("stop stream")
+
+// streamSnapshots connects to the events stream of server and calls fn with
+// every snapshot until fn or the stream fails or ctx is done.
+func streamSnapshots(ctx context.Context, server string, interval time.Duration, fn func(*ctxhttp.LiveSnapshot) error) error {
+	query := url.Values{}
+	query.Set("interval", interval.String())
+	endpoint := fmt.Sprintf("%s/api/v1/events?%s", server, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// No client timeout: the stream stays open while the view runs
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return readEvents(resp.Body, func(event string, data []byte) error {
+		if event != "snapshot" {
+			return nil
+		}
+		var snap ctxhttp.LiveSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return fmt.Errorf("decoding snapshot: %w", err)
+		}
+		return fn(&snap)
+	})
+}
+
+// readEvents parses a server-sent event stream and calls fn with the type
+// and data of each event. It returns io.ErrUnexpectedEOF when the stream ends.
+func readEvents(r io.Reader, fn func(event string, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	event, data := "", []byte(nil)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data != nil {
+				if event == "" {
+					event = "message"
+				}
+				if err := fn(event, data); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				if data != nil {
+					data = append(data, '\n')
+				}
+				data = append(data, value...)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// renderTop formats a snapshot as a screen of tables.
+func renderTop(snap *ctxhttp.LiveSnapshot) string {
+	var b strings.Builder
+	status := snap.Status
+
+	fmt.Fprintf(&b, "contextd %s  %s  updated %s\n", orDash(status.Version), strings.ToUpper(status.Status),
+		snap.Time.Local().Format("15:04:05"))
+	if status.ReadOnly != nil {
+		fmt.Fprintf(&b, "READ-ONLY: %s\n", orDash(status.ReadOnly.Reason))
+	}
+
+	names := make([]string, 0, len(status.Services))
+	for name := range status.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	services := make([]string, 0, len(names))
+	for _, name := range names {
+		services = append(services, name+" "+status.Services[name])
+	}
+	fmt.Fprintf(&b, "Services: %s\n", strings.Join(services, ", "))
+
+	queue := "-"
+	if snap.EmbeddingQueue != nil {
+		queue = fmt.Sprint(*snap.EmbeddingQueue)
+	}
+	fmt.Fprintf(&b, "Memories: %s  Checkpoints: %s  Embedding queue: %s\n",
+		countOrUnknown(status.Counts.Memories), countOrUnknown(status.Counts.Checkpoints), queue)
+
+	branches := 0
+	for _, s := range snap.Sessions {
+		branches += len(s.Branches)
+	}
+	fmt.Fprintf(&b, "\nSESSIONS (%d, %d active branches)\n", len(snap.Sessions), branches)
+	if branches > 0 {
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SESSION\tPROJECT\tBRANCH\tDEPTH\tBUDGET\tAGE\tDESCRIPTION")
+		rows := 0
+		for _, s := range snap.Sessions {
+			for _, br := range s.Branches {
+				if rows == topMaxRows {
+					break
+				}
+				rows++
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d/%d\t%s\t%s\n",
+					truncate(s.SessionID, 20), orDash(truncate(s.ProjectID, 20)), br.ID, br.Depth,
+					br.BudgetUsed, br.BudgetTotal, snap.Time.Sub(br.CreatedAt).Round(time.Second),
+					truncate(br.Description, 40))
+			}
+		}
+		_ = w.Flush()
+	}
+
+	fmt.Fprintf(&b, "\nRECENT TOOL CALLS\n")
+	if len(snap.RecentCalls) == 0 {
+		fmt.Fprintln(&b, "No tool calls since the server started")
+	} else {
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tTOOL\tTENANT\tPROJECT\tMS\tRESULT")
+		for _, call := range snap.RecentCalls[:min(len(snap.RecentCalls), topMaxRows)] {
+			result := "ok"
+			if call.Failed {
+				result = "error"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f\t%s\n",
+				call.Time.Local().Format("15:04:05"), call.Tool, orDash(truncate(call.TenantID, 20)),
+				orDash(truncate(call.ProjectID, 20)), call.DurationMs, result)
+		}
+		_ = w.Flush()
+	}
+
+	total := 0
+	for _, c := range snap.Collections {
+		total += c.Points
+	}
+	fmt.Fprintf(&b, "\nCOLLECTIONS (%d, %d vectors)\n", len(snap.Collections), total)
+	if len(snap.Collections) > 0 {
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVECTORS")
+		for _, c := range snap.Collections[:min(len(snap.Collections), topMaxRows)] {
+			fmt.Fprintf(w, "%s\t%d\n", truncate(c.Name, 60), c.Points)
+		}
+		_ = w.Flush()
+	}
+	return b.String()
+}
+
+// countOrUnknown renders -1, the status endpoint's unknown count, as "?".
+func countOrUnknown(n int) string {
+	if n < 0 {
+		return "?"
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+)
+
+func TestReadEvents(t *testing.T) {
+	stream := ": keep-alive\n\n" +
+		"event: snapshot\ndata: {\"a\":1}\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		"event: snapshot\ndata:{}\n\n"
+
+	var events, data []string
+	err := readEvents(strings.NewReader(stream), func(event string, d []byte) error {
+		events = append(events, event)
+		data = append(data, string(d))
+		return nil
+	})
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, []string{"snapshot", "message", "snapshot"}, events)
+	assert.Equal(t, []string{`{"a":1}`, "line one\nline two", "{}"}, data)
+}
+
+func TestStreamSnapshots(t *testing.T) {
+	depth := int64(2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/events", r.URL.Path)
+		assert.Equal(t, "5s", r.URL.Query().Get("interval"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			data, _ := json.Marshal(ctxhttp.LiveSnapshot{EmbeddingQueue: &depth})
+			fmt.Fprintf(w, "event: snapshot\ndata: %s\n\n", data)
+		}
+	}))
+	defer server.Close()
+
+	var seen int
+	err := streamSnapshots(context.Background(), server.URL, 5*time.Second, func(snap *ctxhttp.LiveSnapshot) error {
+		seen++
+		assert.Equal(t, int64(2), *snap.EmbeddingQueue)
+		if seen == 2 {
+			return errStopStream
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStopStream)
+	assert.Equal(t, 2, seen)
+
+	t.Run("returns server error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "events endpoint is restricted to localhost", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := streamSnapshots(context.Background(), server.URL, time.Second, func(*ctxhttp.LiveSnapshot) error { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "403")
+	})
+}
+
+func TestRenderTop(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	depth := int64(4)
+	out := renderTop(&ctxhttp.LiveSnapshot{
+		Time: now,
+		Status: ctxhttp.StatusResponse{
+			Status:   "ok",
+			Version:  "1.2.3",
+			Services: map[string]string{"memory": "ok", "checkpoint": "unavailable"},
+			Counts:   ctxhttp.StatusCounts{Memories: 12, Checkpoints: -1},
+			ReadOnly: &readonly.Status{Enabled: true, Reason: "disk full"},
+		},
+		Sessions: []ctxhttp.SessionActivity{{
+			SessionID: "sess_a",
+			ProjectID: "api",
+			Branches: []ctxhttp.BranchActivity{
+				{ID: "br_1", Description: "find config", BudgetUsed: 100, BudgetTotal: 8192, CreatedAt: now.Add(-90 * time.Second)},
+			},
+		}},
+		RecentCalls: []ctxhttp.RecentToolCall{
+			{Tool: "memory_search", ProjectID: "api", Time: now, DurationMs: 12.5, Failed: true},
+		},
+		Collections:    []ctxhttp.CollectionSize{{Name: "acme_api_memories", Points: 40}, {Name: "acme_api_checkpoints", Points: 2}},
+		EmbeddingQueue: &depth,
+	})
+
+	assert.Contains(t, out, "contextd 1.2.3  OK")
+	assert.Contains(t, out, "READ-ONLY: disk full")
+	assert.Contains(t, out, "Services: checkpoint unavailable, memory ok")
+	assert.Contains(t, out, "Memories: 12  Checkpoints: ?  Embedding queue: 4")
+	assert.Contains(t, out, "SESSIONS (1, 1 active branches)")
+	assert.Regexp(t, `sess_a\s+api\s+br_1\s+0\s+100/8192\s+1m30s\s+find config`, out)
+	assert.Regexp(t, `memory_search\s+-\s+api\s+12.5\s+error`, out)
+	assert.Contains(t, out, "COLLECTIONS (2, 42 vectors)")
+	assert.Regexp(t, `acme_api_memories\s+40`, out)
+
+	empty := renderTop(&ctxhttp.LiveSnapshot{Time: now, Status: ctxhttp.StatusResponse{Status: "ok"}})
+	assert.Contains(t, empty, "No tool calls since the server started")
+	assert.Contains(t, empty, "Embedding queue: -")
+}
@@ -0,0 +1,127 @@
+// Package main implements the LLM usage command for the ctxd CLI.
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+var (
+	// usage command flags
+	usageDays      int
+	usageFeature   string
+	usageTenantID  string
+	usageProjectID string
+	usageSessionID string
+	usageGroupBy   string
+)
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+
+	usageCmd.Flags().IntVar(&usageDays, "days", ctxhttp.DefaultStatsDays, "Number of days to include, ending today")
+	usageCmd.Flags().StringVar(&usageFeature, "feature", "", "Filter by feature: compression, extraction, distiller, troubleshoot, digest")
+	usageCmd.Flags().StringVar(&usageTenantID, "tenant-id", "", "Filter by tenant identifier")
+	usageCmd.Flags().StringVar(&usageProjectID, "project-id", "", "Filter by project identifier")
+	usageCmd.Flags().StringVar(&usageSessionID, "session-id", "", "Filter by session identifier")
+	usageCmd.Flags().StringVar(&usageGroupBy, "group-by", "feature", "Comma-separated grouping: feature, provider, model, tenant, project, session, day")
+
+	_ = usageCmd.RegisterFlagCompletionFunc("feature", cobra.FixedCompletions(
+		[]string{"compression", "extraction", "distiller", "troubleshoot", "digest"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = usageCmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions(
+		[]string{"feature", "provider", "model", "tenant", "project", "session", "day"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show LLM token usage and estimated cost",
+	Long: `Show tokens and estimated cost of the LLM requests contextd makes for
+abstractive compression, decision extraction, memory distillation,
+troubleshooting and knowledge digests.
+
+Usage is rolled up per day by the contextd server and read from
+GET /api/v1/stats/usage, which only answers requests from localhost.
+Costs are estimates at list prices; models without a known price count
+tokens but cost 0. Distillation, troubleshooting and digest clients that
+do not report token counts are estimated at four characters per token.
+
+Examples:
+  # Spend per feature for the last 7 days
+  ctxd usage
+
+  # Daily spend per model for one project over the last 30 days
+  ctxd usage --days 30 --project-id contextd --group-by day,model
+
+  # What one session cost
+  ctxd usage --session-id sess-123 --group-by session,feature
+
+  # Spend per tenant as JSON
+  ctxd usage --group-by tenant --json`,
+	RunE: runUsage,
+}
+
+func runUsage(cmd *cobra.Command, args []string) error {
+	usage, err := fetchUsageStats()
+	if err != nil {
+		return fmt.Errorf("failed to fetch usage stats: %w", err)
+	}
+
+	return render(usage, func() error {
+		fmt.Printf("LLM usage %s to %s\n\n", usage.From, usage.To)
+		if len(usage.Usage) == 0 {
+			fmt.Println("No LLM requests recorded")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DAY\tFEATURE\tPROVIDER\tMODEL\tTENANT\tPROJECT\tSESSION\tREQUESTS\tERRORS\tINPUT TOKENS\tOUTPUT TOKENS\tEST. COST")
+		for _, row := range usage.Usage {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t$%.4f\n",
+				orDash(row.Day),
+				orDash(row.Feature),
+				orDash(row.Provider),
+				orDash(row.Model),
+				orDash(truncate(row.TenantID, 20)),
+				orDash(truncate(row.ProjectID, 20)),
+				orDash(truncate(row.SessionID, 20)),
+				row.Requests,
+				row.Errors,
+				row.InputTokens,
+				row.OutputTokens,
+				row.CostUSD,
+			)
+		}
+		total := usage.Total
+		fmt.Fprintf(w, "TOTAL\t\t\t\t\t\t\t%d\t%d\t%d\t%d\t$%.4f\n",
+			total.Requests, total.Errors, total.InputTokens, total.OutputTokens, total.CostUSD)
+		return w.Flush()
+	})
+}
+
+func fetchUsageStats() (*ctxhttp.UsageStatsResponse, error) {
+	query := url.Values{}
+	query.Set("days", fmt.Sprint(usageDays))
+	query.Set("group_by", usageGroupBy)
+	for param, value := range map[string]string{
+		"feature":    usageFeature,
+		"tenant_id":  usageTenantID,
+		"project_id": usageProjectID,
+		"session_id": usageSessionID,
+	} {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+
+	var usage ctxhttp.UsageStatsResponse
+	if err := fetchStats("/api/v1/stats/usage", query, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+func TestFetchUsageStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/stats/usage", r.URL.Path)
+		assert.Equal(t, "30", r.URL.Query().Get("days"))
+		assert.Equal(t, "session,model", r.URL.Query().Get("group_by"))
+		assert.Equal(t, "sess-123", r.URL.Query().Get("session_id"))
+		assert.False(t, r.URL.Query().Has("feature"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ctxhttp.UsageStatsResponse{
+			From:    "2026-09-16",
+			To:      "2026-10-15",
+			GroupBy: []string{"session", "model"},
+			Total:   analytics.UsageStats{Requests: 3, InputTokens: 900, CostUSD: 0.01},
+			Usage:   []analytics.UsageStats{{SessionID: "sess-123", Model: "claude-3-haiku-20240307", Requests: 3, InputTokens: 900, CostUSD: 0.01}},
+		})
+	}))
+	defer server.Close()
+
+	oldServerURL := serverURL
+	serverURL = server.URL
+	defer func() { serverURL = oldServerURL }()
+
+	usageDays, usageGroupBy, usageSessionID = 30, "session,model", "sess-123"
+	defer func() {
+		usageDays, usageGroupBy, usageSessionID = ctxhttp.DefaultStatsDays, "feature", ""
+	}()
+
+	usage, err := fetchUsageStats()
+	require.NoError(t, err)
+	require.Len(t, usage.Usage, 1)
+	assert.Equal(t, "sess-123", usage.Usage[0].SessionID)
+	assert.Equal(t, int64(3), usage.Total.Requests)
+}
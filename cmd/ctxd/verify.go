@@ -0,0 +1,234 @@
+// Package main implements the verify command for the ctxd CLI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+var (
+	verifyRepair     bool
+	verifyReportPath string
+)
+
+// errVerifyIssues is returned when verification finds issues, so the
+// command exits non-zero.
+var errVerifyIssues = errors.New("verification found issues")
+
+// errRepairFailed is returned when any repair fails.
+var errRepairFailed = errors.New("some repairs failed")
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyRepair, "repair", false, "repair the repairable issues")
+	verifyCmd.Flags().StringVar(&verifyReportPath, "report", "", "with --repair, repair the issues of a saved JSON report (- for stdin) instead of verifying again")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check vectorstore data integrity",
+	Long: `Walk every vectorstore collection of a running contextd server and check:
+
+  undecodable         every stored document decodes
+  dimension_mismatch  embeddings have the collection's dimension
+  missing_tenant      documents carry tenant_id when tenants are isolated
+  dangling_reference  consolidation IDs of memories and parent IDs of
+                      checkpoints name documents that exist
+
+Each issue lists the repair that fixes it: delete, reembed, set_metadata or
+clear_metadata. Issues without a repair need manual attention. With
+--repair the server verifies the affected collections again and repairs
+only the issues still present, so a saved report can be reviewed first.
+
+The verify endpoints only answer requests from localhost. Exits non-zero
+if issues are found or a repair fails.
+
+Examples:
+  ctxd verify
+  ctxd verify -o json > report.json
+  ctxd verify --repair --report report.json
+  ctxd verify --repair`,
+	RunE: runVerify,
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if verifyReportPath != "" && !verifyRepair {
+		return errors.New("--report is only used with --repair")
+	}
+
+	if !verifyRepair {
+		var report vectorstore.VerifyReport
+		if err := verifyRequest(http.MethodGet, "/api/v1/admin/verify", nil, &report); err != nil {
+			return err
+		}
+		if err := render(&report, func() error { return printVerifyReport(&report) }); err != nil {
+			return err
+		}
+		if report.HasIssues() {
+			return errVerifyIssues
+		}
+		return nil
+	}
+
+	issues, err := issuesToRepair()
+	if err != nil {
+		return err
+	}
+	var result vectorstore.RepairResult
+	err = verifyRequest(http.MethodPost, "/api/v1/admin/verify/repair", ctxhttp.VerifyRepairRequest{Issues: issues}, &result)
+	if err != nil {
+		return err
+	}
+	if err := render(&result, func() error { return printRepairResult(&result) }); err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		return errRepairFailed
+	}
+	return nil
+}
+
+// issuesToRepair reads the issues of the saved report, or verifies now
+// when no report was given.
+func issuesToRepair() ([]vectorstore.VerifyIssue, error) {
+	var report vectorstore.VerifyReport
+	if verifyReportPath == "" {
+		if err := verifyRequest(http.MethodGet, "/api/v1/admin/verify", nil, &report); err != nil {
+			return nil, err
+		}
+		return report.Issues, nil
+	}
+
+	var data []byte
+	var err error
+	if verifyReportPath == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(verifyReportPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading report: %w", err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing report (save it with -o json): %w", err)
+	}
+	return report.Issues, nil
+}
+
+// printVerifyReport prints a verification report as tables.
+func printVerifyReport(report *vectorstore.VerifyReport) error {
+	fmt.Fprintf(stdout, "Verified %d documents in %d collections (%s): %d issues, %d repairable\n",
+		report.Documents, len(report.Collections), report.CheckDuration.Round(time.Millisecond), len(report.Issues), report.Repairable)
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	header := false
+	for _, col := range report.Collections {
+		if col.Error == "" {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nCOLLECTION\tERROR")
+			header = true
+		}
+		fmt.Fprintf(w, "%s\t%s\n", col.Collection, col.Error)
+	}
+	if len(report.Issues) > 0 {
+		fmt.Fprintln(w, "\nCOLLECTION\tDOCUMENT\tKIND\tREPAIR\tDETAIL")
+		for _, issue := range report.Issues {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				issue.Collection, issueDocument(issue), issue.Kind, issueRepair(issue), issue.Detail)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if report.Repairable > 0 {
+		fmt.Fprintln(stdout, "\nSave the report with 'ctxd verify -o json > report.json', review it, then run 'ctxd verify --repair --report report.json'.")
+	}
+	return nil
+}
+
+// printRepairResult prints the outcome of a repair.
+func printRepairResult(result *vectorstore.RepairResult) error {
+	fmt.Fprintf(stdout, "Repaired %d issues, skipped %d, failed %d\n",
+		len(result.Repaired), len(result.Skipped), len(result.Failed))
+	if len(result.Failed) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nCOLLECTION\tDOCUMENT\tKIND\tREPAIR\tERROR")
+	for _, f := range result.Failed {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			f.Issue.Collection, issueDocument(f.Issue), f.Issue.Kind, f.Issue.Repair, f.Error)
+	}
+	return w.Flush()
+}
+
+// issueDocument names the document of an issue, falling back to where it
+// is stored when its ID could not be read.
+func issueDocument(issue vectorstore.VerifyIssue) string {
+	if issue.DocumentID != "" {
+		return issue.DocumentID
+	}
+	return issue.Location
+}
+
+// issueRepair names the repair of an issue for the table.
+func issueRepair(issue vectorstore.VerifyIssue) string {
+	if issue.Repair == "" {
+		return "manual"
+	}
+	return issue.Repair
+}
+
+// verifyRequest sends a request to a verify endpoint and decodes the JSON
+// response into out. A 503 response means the server has no verifier.
+func verifyRequest(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, serverURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Verification reads every document of every collection
+	client := &http.Client{Timeout: 30 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return fmt.Errorf("server returned %d (failed to read response: %v)", resp.StatusCode, readErr)
+		}
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, data)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestRunVerify(t *testing.T) {
+	issue := vectorstore.VerifyIssue{
+		Kind:       vectorstore.IssueDanglingReference,
+		Collection: "proj_memories",
+		DocumentID: "m3",
+		Field:      "consolidation_id",
+		Value:      "gone",
+		Detail:     `consolidation_id "gone" does not exist`,
+		Repair:     vectorstore.RepairClearMetadata,
+	}
+	var repaired []vectorstore.VerifyIssue
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/admin/verify":
+			_ = json.NewEncoder(w).Encode(vectorstore.VerifyReport{
+				Status:      "issues",
+				Documents:   3,
+				Repairable:  1,
+				Collections: []vectorstore.CollectionVerification{{Collection: "proj_memories", Documents: 3, Issues: 1}},
+				Issues:      []vectorstore.VerifyIssue{issue},
+			})
+		case "/api/v1/admin/verify/repair":
+			var req ctxhttp.VerifyRepairRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			repaired = req.Issues
+			_ = json.NewEncoder(w).Encode(vectorstore.RepairResult{Repaired: req.Issues})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldServerURL := serverURL
+	serverURL = server.URL
+	defer func() { serverURL = oldServerURL }()
+	defer func() { verifyRepair, verifyReportPath = false, "" }()
+
+	t.Run("reports issues and fails", func(t *testing.T) {
+		buf := withOutput(t, formatTable)
+		err := runVerify(verifyCmd, nil)
+		assert.ErrorIs(t, err, errVerifyIssues)
+		assert.Contains(t, buf.String(), "1 issues, 1 repairable")
+		assert.Contains(t, buf.String(), "clear_metadata")
+	})
+
+	t.Run("repairs a saved report", func(t *testing.T) {
+		buf := withOutput(t, formatJSON)
+		require.Error(t, runVerify(verifyCmd, nil))
+		path := filepath.Join(t.TempDir(), "report.json")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+		withOutput(t, formatTable)
+		verifyRepair, verifyReportPath = true, path
+		require.NoError(t, runVerify(verifyCmd, nil))
+		assert.Equal(t, []vectorstore.VerifyIssue{issue}, repaired)
+	})
+
+	t.Run("report requires repair", func(t *testing.T) {
+		verifyRepair, verifyReportPath = false, "report.json"
+		assert.Error(t, runVerify(verifyCmd, nil))
+	})
+}
@@ -0,0 +1,195 @@
+// Package main implements durable workflow commands for the ctxd CLI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+)
+
+var (
+	// workflow command flags
+	wfProjectPath string
+	wfProjectID   string
+	wfBranch      string
+	wfInclude     []string
+	wfExclude     []string
+	wfMaxFileSize int64
+	wfBatchSize   int
+	wfThreshold   float64
+	wfMaxClusters int
+	wfTags        []string
+	wfOutcome     string
+	wfQuery       string
+	wfDryRun      bool
+)
+
+func init() {
+	rootCmd.AddCommand(workflowCmd)
+	workflowCmd.AddCommand(workflowIndexCmd)
+	workflowCmd.AddCommand(workflowConsolidateCmd)
+
+	workflowIndexCmd.Flags().StringVar(&wfProjectPath, "path", "", "Repository path (defaults to current directory)")
+	workflowIndexCmd.Flags().StringVar(&wfBranch, "branch", "", "Branch to record (defaults to the checked out branch)")
+	workflowIndexCmd.Flags().StringSliceVar(&wfInclude, "include", nil, "Glob patterns of files to include")
+	workflowIndexCmd.Flags().StringSliceVar(&wfExclude, "exclude", nil, "Glob patterns of files to exclude")
+	workflowIndexCmd.Flags().Int64Var(&wfMaxFileSize, "max-file-size", 0, "Skip files larger than this many bytes (0 = server default)")
+	workflowIndexCmd.Flags().IntVar(&wfBatchSize, "batch-size", 0, "Files indexed per activity (0 = server default)")
+
+	workflowConsolidateCmd.Flags().StringVar(&wfProjectID, "project-id", "", "Project identifier (defaults to current directory basename)")
+	workflowConsolidateCmd.Flags().Float64Var(&wfThreshold, "threshold", 0.8, "Minimum similarity for memories to cluster (0-1)")
+	workflowConsolidateCmd.Flags().IntVar(&wfMaxClusters, "max-clusters", 0, "Maximum clusters to merge (0 = no limit)")
+	workflowConsolidateCmd.Flags().StringSliceVar(&wfTags, "tags", nil, "Only consider memories with at least one of these tags")
+	workflowConsolidateCmd.Flags().StringVar(&wfOutcome, "outcome", "", "Only consider memories with this outcome: success or failure")
+	workflowConsolidateCmd.Flags().StringVar(&wfQuery, "query", "", "Only consider memories relevant to this query")
+	workflowConsolidateCmd.Flags().BoolVar(&wfDryRun, "dry-run", false, "Find clusters without merging them")
+
+	_ = workflowConsolidateCmd.RegisterFlagCompletionFunc("outcome", cobra.FixedCompletions(
+		[]string{"success", "failure"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+var workflowCmd = &cobra.Command{
+	Use:   "workflow",
+	Short: "Start durable workflows on the contextd server",
+	Long: `Start durable workflows on a running contextd server.
+
+Workflows run on Temporal and survive server restarts. The server must be
+started with WORKFLOWS_ENABLED=true and a reachable Temporal frontend.
+Progress is shown in the Temporal UI under the returned workflow ID.`,
+}
+
+var workflowIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Index a repository in resumable batches",
+	Long: `Index a repository for semantic search as a durable workflow.
+
+Files are indexed in batches; an interrupted run resumes with the next
+batch instead of starting over. Starting a run for a repository that is
+already being indexed returns the running workflow.
+
+Examples:
+  # Index the current directory
+  ctxd workflow index
+
+  # Index only Go files of another checkout, 50 files per batch
+  ctxd workflow index --path ~/src/api --include '*.go' --batch-size 50`,
+	RunE: runWorkflowIndex,
+}
+
+var workflowConsolidateCmd = &cobra.Command{
+	Use:   "consolidate",
+	Short: "Consolidate similar memories in resumable steps",
+	Long: `Merge clusters of similar memories as a durable workflow.
+
+Each cluster is merged in its own activity, so an interrupted run resumes
+with the next cluster and a retried merge does not create duplicates.
+Use 'ctxd memory consolidate --propose' to review merges before they happen.
+
+Examples:
+  # Consolidate memories of the current project
+  ctxd workflow consolidate
+
+  # See what would be merged for one project
+  ctxd workflow consolidate --project-id contextd --dry-run`,
+	RunE: runWorkflowConsolidate,
+}
+
+func runWorkflowIndex(cmd *cobra.Command, args []string) error {
+	path := wfProjectPath
+	if path == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		path = cwd
+	}
+
+	run, err := startWorkflow("repository-index", ctxhttp.RepositoryIndexWorkflowRequest{
+		ProjectPath:     path,
+		Branch:          wfBranch,
+		IncludePatterns: wfInclude,
+		ExcludePatterns: wfExclude,
+		MaxFileSize:     wfMaxFileSize,
+		BatchSize:       wfBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start repository indexing: %w", err)
+	}
+	return printWorkflowRun(run)
+}
+
+func runWorkflowConsolidate(cmd *cobra.Command, args []string) error {
+	projectID := wfProjectID
+	if projectID == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		projectID = getProjectIDFromPath(cwd)
+	}
+	if err := sanitize.ValidateProjectID(projectID); err != nil {
+		return fmt.Errorf("invalid --project-id: %w", err)
+	}
+
+	run, err := startWorkflow("memory-consolidation", ctxhttp.MemoryConsolidationWorkflowRequest{
+		ProjectID:           projectID,
+		SimilarityThreshold: wfThreshold,
+		MaxClusters:         wfMaxClusters,
+		Tags:                wfTags,
+		Outcome:             wfOutcome,
+		Query:               wfQuery,
+		DryRun:              wfDryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start memory consolidation: %w", err)
+	}
+	return printWorkflowRun(run)
+}
+
+// startWorkflow posts req to /api/v1/workflows/<name>.
+func startWorkflow(name string, req interface{}) (*ctxhttp.WorkflowStartResponse, error) {
+	reqJSON, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/workflows/%s", serverURL, name)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(reqJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, fmt.Errorf("server returned %d (failed to read response: %v)", resp.StatusCode, readErr)
+		}
+		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, body)
+	}
+
+	var run ctxhttp.WorkflowStartResponse
+	if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func printWorkflowRun(run *ctxhttp.WorkflowStartResponse) error {
+	return render(run, func() error {
+		fmt.Printf("Workflow started\n")
+		fmt.Printf("  Workflow ID: %s\n", run.WorkflowID)
+		fmt.Printf("  Run ID:      %s\n", run.RunID)
+		return nil
+	})
+}
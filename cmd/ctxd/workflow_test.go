@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+func TestStartWorkflow(t *testing.T) {
+	t.Run("posts request and decodes run", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/api/v1/workflows/memory-consolidation", r.URL.Path)
+
+			var req ctxhttp.MemoryConsolidationWorkflowRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "contextd", req.ProjectID)
+			assert.True(t, req.DryRun)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(ctxhttp.WorkflowStartResponse{
+				WorkflowID: "memory-consolidation-contextd",
+				RunID:      "run-1",
+			})
+		}))
+		defer server.Close()
+
+		oldServerURL := serverURL
+		serverURL = server.URL
+		defer func() { serverURL = oldServerURL }()
+
+		run, err := startWorkflow("memory-consolidation", ctxhttp.MemoryConsolidationWorkflowRequest{
+			ProjectID: "contextd",
+			DryRun:    true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "memory-consolidation-contextd", run.WorkflowID)
+		assert.Equal(t, "run-1", run.RunID)
+	})
+
+	t.Run("returns server error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "workflows not configured", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		oldServerURL := serverURL
+		serverURL = server.URL
+		defer func() { serverURL = oldServerURL }()
+
+		_, err := startWorkflow("repository-index", ctxhttp.RepositoryIndexWorkflowRequest{ProjectPath: "/tmp"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+		assert.Contains(t, err.Error(), "workflows not configured")
+	})
+}
@@ -3,6 +3,16 @@
 // This server receives GitHub webhook events and triggers corresponding Temporal
 // workflows for plugin validation and other automation tasks.
 //
+// With CI_REMEDIATION_ENABLED=true, failed workflow_run and check_run events
+// start a workflow on contextd's worker that diagnoses the failing jobs and
+// drafts pending remediations for them.
+//
+// With PR_CONTEXT_ENABLED=true, opened pull requests start a workflow on
+// contextd's worker that comments past fixes and memories relevant to the
+// changed files. PR_CONTEXT_CONFIG names a JSON file listing the
+// repositories that opt in (see PRContextSettings). Pull requests from forks
+// or from authors who are not owners, members or collaborators are skipped.
+//
 // Usage:
 //
 //	TEMPORAL_HOST=localhost:7233 \
@@ -48,6 +58,13 @@ type Config struct {
 	WebhookSecret config.Secret
 	GitHubToken   config.Secret
 	Port          string
+
+	// ContextdTaskQueue is the task queue contextd's worker polls. CI
+	// remediation and PR context workflows are started on it.
+	ContextdTaskQueue    string
+	CIRemediationEnabled bool
+	PRContextEnabled     bool
+	PRContextConfigPath  string
 }
 
 type WebhookServer struct {
@@ -56,8 +73,13 @@ type WebhookServer struct {
 	gitHubToken    config.Secret
 	logger         *logging.Logger
 	rateLimiters   map[string]*rate.Limiter
+	repoLimiters   map[string]*rate.Limiter // PR context comments per repository
 	mu             sync.RWMutex
 	lastCleanup    time.Time
+
+	contextdTaskQueue string
+	ciRemediation     bool
+	prContext         *PRContextSettings // nil when PR context is disabled
 }
 
 func main() {
@@ -86,6 +108,8 @@ func run() error {
 	logger.Info(ctx, "github webhook server starting",
 		zap.String("port", cfg.Port),
 		zap.String("temporal_host", cfg.TemporalHost),
+		zap.Bool("ci_remediation", cfg.CIRemediationEnabled),
+		zap.Bool("pr_context", cfg.PRContextEnabled),
 	)
 
 	// Validate configuration
@@ -96,6 +120,14 @@ func run() error {
 		return fmt.Errorf("GITHUB_TOKEN not set")
 	}
 
+	var prContext *PRContextSettings
+	if cfg.PRContextEnabled {
+		prContext, err = loadPRContextSettings(cfg.PRContextConfigPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create Temporal client
 	c, err := client.Dial(client.Options{
 		HostPort: cfg.TemporalHost,
@@ -113,6 +145,10 @@ func run() error {
 		webhookSecret:  cfg.WebhookSecret,
 		gitHubToken:    cfg.GitHubToken,
 		logger:         logger,
+
+		contextdTaskQueue: cfg.ContextdTaskQueue,
+		ciRemediation:     cfg.CIRemediationEnabled,
+		prContext:         prContext,
 	}
 
 	// Setup routes
@@ -170,11 +206,20 @@ func loadConfig() *Config {
 		port = "3000"
 	}
 
+	contextdTaskQueue := os.Getenv("CONTEXTD_TASK_QUEUE")
+	if contextdTaskQueue == "" {
+		contextdTaskQueue = workflows.DefaultTaskQueue
+	}
+
 	return &Config{
-		TemporalHost:  temporalHost,
-		WebhookSecret: config.Secret(os.Getenv("GITHUB_WEBHOOK_SECRET")),
-		GitHubToken:   config.Secret(os.Getenv("GITHUB_TOKEN")),
-		Port:          port,
+		TemporalHost:         temporalHost,
+		WebhookSecret:        config.Secret(os.Getenv("GITHUB_WEBHOOK_SECRET")),
+		GitHubToken:          config.Secret(os.Getenv("GITHUB_TOKEN")),
+		Port:                 port,
+		ContextdTaskQueue:    contextdTaskQueue,
+		CIRemediationEnabled: os.Getenv("CI_REMEDIATION_ENABLED") == "true",
+		PRContextEnabled:     os.Getenv("PR_CONTEXT_ENABLED") == "true",
+		PRContextConfigPath:  os.Getenv("PR_CONTEXT_CONFIG"),
 	}
 }
 
@@ -269,6 +314,25 @@ func (s *WebhookServer) handleWebhook(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Internal error", http.StatusInternalServerError)
 			return
 		}
+		if err := s.handlePRContextEvent(ctx, e); err != nil {
+			s.logger.Error(ctx, "error handling PR context", zap.Error(err))
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+
+	case *github.WorkflowRunEvent:
+		if err := s.handleWorkflowRunEvent(ctx, e); err != nil {
+			s.logger.Error(ctx, "error handling workflow run event", zap.Error(err))
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+
+	case *github.CheckRunEvent:
+		if err := s.handleCheckRunEvent(ctx, e); err != nil {
+			s.logger.Error(ctx, "error handling check run event", zap.Error(err))
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
 
 	default:
 		s.logger.Debug(ctx, "ignoring event type", zap.String("type", fmt.Sprintf("%T", event)))
@@ -374,6 +438,131 @@ func (s *WebhookServer) handlePullRequestEvent(ctx context.Context, event *githu
 	return nil
 }
 
+// validateRepo validates repository and commit data to prevent injection attacks
+func validateRepo(repo *github.Repository, sha string) error {
+	if repo == nil || repo.Owner == nil || !validNameRegex.MatchString(repo.GetOwner().GetLogin()) {
+		return fmt.Errorf("invalid repository owner format")
+	}
+	if !validNameRegex.MatchString(repo.GetName()) {
+		return fmt.Errorf("invalid repository name format")
+	}
+	if !validSHARegex.MatchString(sha) {
+		return fmt.Errorf("invalid SHA format")
+	}
+	return nil
+}
+
+// isCIFailure reports whether a completed run or check failed.
+func isCIFailure(action, conclusion string) bool {
+	return action == "completed" && (conclusion == "failure" || conclusion == "timed_out")
+}
+
+func (s *WebhookServer) handleWorkflowRunEvent(ctx context.Context, event *github.WorkflowRunEvent) error {
+	run := event.GetWorkflowRun()
+	if !s.ciRemediation || !isCIFailure(event.GetAction(), run.GetConclusion()) {
+		s.logger.Debug(ctx, "ignoring workflow run event",
+			zap.String("action", event.GetAction()),
+			zap.String("conclusion", run.GetConclusion()))
+		return nil
+	}
+	if err := validateRepo(event.GetRepo(), run.GetHeadSHA()); err != nil {
+		s.logger.Warn(ctx, "invalid workflow run event data", zap.Error(err))
+		return fmt.Errorf("invalid workflow run event: %w", err)
+	}
+	if run.GetID() <= 0 {
+		return fmt.Errorf("invalid workflow run event: invalid run ID")
+	}
+
+	config := workflows.CIFailureRemediationConfig{
+		Owner:      event.GetRepo().GetOwner().GetLogin(),
+		Repo:       event.GetRepo().GetName(),
+		HeadSHA:    run.GetHeadSHA(),
+		HeadBranch: run.GetHeadBranch(),
+		RunID:      run.GetID(),
+	}
+
+	// One workflow per run attempt, so re-runs are diagnosed again
+	workflowID := fmt.Sprintf("ci-remediation-%s-%s-run-%d-%d",
+		config.Owner, config.Repo, config.RunID, run.GetRunAttempt())
+	return s.startCIRemediation(ctx, workflowID, config)
+}
+
+func (s *WebhookServer) handleCheckRunEvent(ctx context.Context, event *github.CheckRunEvent) error {
+	check := event.GetCheckRun()
+	if !s.ciRemediation || !isCIFailure(event.GetAction(), check.GetConclusion()) {
+		s.logger.Debug(ctx, "ignoring check run event",
+			zap.String("action", event.GetAction()),
+			zap.String("conclusion", check.GetConclusion()))
+		return nil
+	}
+	if err := validateRepo(event.GetRepo(), check.GetHeadSHA()); err != nil {
+		s.logger.Warn(ctx, "invalid check run event data", zap.Error(err))
+		return fmt.Errorf("invalid check run event: %w", err)
+	}
+	if check.GetID() <= 0 {
+		return fmt.Errorf("invalid check run event: invalid check run ID")
+	}
+
+	config := workflows.CIFailureRemediationConfig{
+		Owner:      event.GetRepo().GetOwner().GetLogin(),
+		Repo:       event.GetRepo().GetName(),
+		HeadSHA:    check.GetHeadSHA(),
+		HeadBranch: check.GetCheckSuite().GetHeadBranch(),
+	}
+
+	var workflowID string
+	if check.GetApp().GetSlug() == "github-actions" {
+		// Actions check runs are jobs; their logs are fetched by job ID
+		config.JobID = check.GetID()
+		workflowID = fmt.Sprintf("ci-remediation-%s-%s-job-%d", config.Owner, config.Repo, config.JobID)
+	} else {
+		// Other apps only report their findings in the check output
+		output := check.GetOutput()
+		config.CheckName = check.GetName()
+		config.CheckURL = check.GetHTMLURL()
+		config.CheckOutput = strings.TrimSpace(strings.Join([]string{
+			output.GetTitle(), output.GetSummary(), output.GetText(),
+		}, "\n"))
+		if config.CheckOutput == "" {
+			config.CheckOutput = fmt.Sprintf("Check %q concluded %s", check.GetName(), check.GetConclusion())
+		}
+		workflowID = fmt.Sprintf("ci-remediation-%s-%s-check-%d", config.Owner, config.Repo, check.GetID())
+	}
+	return s.startCIRemediation(ctx, workflowID, config)
+}
+
+// startCIRemediation starts a CI failure remediation workflow on contextd's
+// worker. A job reported by both a workflow_run and a check_run event is
+// drafted once; the workflow reuses the existing draft.
+func (s *WebhookServer) startCIRemediation(ctx context.Context, workflowID string, config workflows.CIFailureRemediationConfig) error {
+	s.logger.Info(ctx, "processing CI failure",
+		zap.String("owner", config.Owner),
+		zap.String("repo", config.Repo),
+		zap.String("sha", config.HeadSHA),
+		zap.Int64("run_id", config.RunID),
+		zap.Int64("job_id", config.JobID),
+	)
+
+	options := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: s.contextdTaskQueue,
+	}
+
+	workflowCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	we, err := s.temporalClient.ExecuteWorkflow(workflowCtx, options, workflows.CIFailureRemediationWorkflow, config)
+	if err != nil {
+		return fmt.Errorf("failed to start workflow: %w", err)
+	}
+
+	s.logger.Info(ctx, "workflow started",
+		zap.String("workflow_id", we.GetID()),
+		zap.String("run_id", we.GetRunID()),
+	)
+	return nil
+}
+
 func handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
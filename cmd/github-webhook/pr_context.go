@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/workflows"
+)
+
+// Defaults for repositories without PR context settings.
+const (
+	defaultPRCommentsPerHour = 10
+	defaultPROptOutLabel     = "no-contextd"
+)
+
+// PRContextSettings configures PR context comments. Repos overrides
+// Defaults per repository, keyed by "owner/repo".
+//
+// Comments are opt-in: a repository gets them when it is listed in Repos,
+// or when Defaults sets "enabled": true for every repository.
+//
+// Example PR_CONTEXT_CONFIG file:
+//
+//	{
+//	  "repos": {
+//	    "acme/api": {"project_id": "api", "max_comments_per_hour": 5},
+//	    "acme/web": {"enabled": false}
+//	  }
+//	}
+type PRContextSettings struct {
+	Defaults PRContextRepoSettings            `json:"defaults"`
+	Repos    map[string]PRContextRepoSettings `json:"repos"`
+}
+
+// PRContextRepoSettings configures PR context comments for a repository.
+// Zero values inherit the defaults.
+type PRContextRepoSettings struct {
+	Enabled            *bool    `json:"enabled,omitempty"`               // Comment on this repository (default: true if listed in repos)
+	TenantID           string   `json:"tenant_id,omitempty"`             // Remediation tenant (default: owner)
+	ProjectID          string   `json:"project_id,omitempty"`            // Memory project (default: repository name)
+	MaxResults         int      `json:"max_results,omitempty"`           // Items listed per file (default 3)
+	MaxCommentsPerHour int      `json:"max_comments_per_hour,omitempty"` // Pull requests commented per hour (default 10)
+	OptOutLabels       []string `json:"opt_out_labels,omitempty"`        // Labels that skip a pull request (default no-contextd)
+}
+
+// loadPRContextSettings reads settings from a JSON file. An empty path
+// returns the defaults.
+func loadPRContextSettings(path string) (*PRContextSettings, error) {
+	settings := &PRContextSettings{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading PR context config: %w", err)
+		}
+		if err := json.Unmarshal(data, settings); err != nil {
+			return nil, fmt.Errorf("parsing PR context config: %w", err)
+		}
+	}
+
+	repos := make(map[string]PRContextRepoSettings, len(settings.Repos))
+	for name, repo := range settings.Repos {
+		owner, repoName, ok := strings.Cut(name, "/")
+		if !ok || !validNameRegex.MatchString(owner) || !validNameRegex.MatchString(repoName) {
+			return nil, fmt.Errorf("invalid PR context repository %q: want owner/repo", name)
+		}
+		if err := repo.validate(); err != nil {
+			return nil, fmt.Errorf("invalid PR context settings for %s: %w", name, err)
+		}
+		repos[strings.ToLower(name)] = repo
+	}
+	settings.Repos = repos
+
+	if err := settings.Defaults.validate(); err != nil {
+		return nil, fmt.Errorf("invalid PR context defaults: %w", err)
+	}
+	return settings, nil
+}
+
+func (r *PRContextRepoSettings) validate() error {
+	if r.TenantID != "" {
+		if err := sanitize.ValidateTenantID(r.TenantID); err != nil {
+			return err
+		}
+	}
+	if r.ProjectID != "" {
+		if err := sanitize.ValidateProjectID(r.ProjectID); err != nil {
+			return err
+		}
+	}
+	if r.MaxResults < 0 || r.MaxCommentsPerHour < 0 {
+		return fmt.Errorf("max_results and max_comments_per_hour cannot be negative")
+	}
+	return nil
+}
+
+// forRepo returns the settings for owner/repo with defaults filled in.
+func (s *PRContextSettings) forRepo(owner, repo string) PRContextRepoSettings {
+	merged := s.Defaults
+	if merged.Enabled == nil {
+		disabled := false
+		merged.Enabled = &disabled
+	}
+	if r, ok := s.Repos[strings.ToLower(owner+"/"+repo)]; ok {
+		enabled := r.Enabled == nil || *r.Enabled
+		merged.Enabled = &enabled
+		if r.TenantID != "" {
+			merged.TenantID = r.TenantID
+		}
+		if r.ProjectID != "" {
+			merged.ProjectID = r.ProjectID
+		}
+		if r.MaxResults != 0 {
+			merged.MaxResults = r.MaxResults
+		}
+		if r.MaxCommentsPerHour != 0 {
+			merged.MaxCommentsPerHour = r.MaxCommentsPerHour
+		}
+		if r.OptOutLabels != nil {
+			merged.OptOutLabels = r.OptOutLabels
+		}
+	}
+
+	if merged.MaxCommentsPerHour == 0 {
+		merged.MaxCommentsPerHour = defaultPRCommentsPerHour
+	}
+	if merged.OptOutLabels == nil {
+		merged.OptOutLabels = []string{defaultPROptOutLabel}
+	}
+	return merged
+}
+
+// trustedAssociations are the author associations whose pull requests get
+// context comments. Comments quote stored fixes and memories, so they are not
+// posted for outside contributors.
+var trustedAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// untrustedReason explains why pr must not get a context comment, or returns
+// "" for a pull request from a trusted author in the repository itself.
+func untrustedReason(pr *github.PullRequest) string {
+	if pr.GetHead().GetRepo().GetFork() {
+		return "opened from a fork"
+	}
+	if !trustedAssociations[pr.GetAuthorAssociation()] {
+		return "author is not a member or collaborator"
+	}
+	return ""
+}
+
+// optOutLabel returns the first label of pr that opts it out, if any.
+func optOutLabel(pr *github.PullRequest, optOut []string) (string, bool) {
+	for _, label := range pr.Labels {
+		for _, name := range optOut {
+			if strings.EqualFold(label.GetName(), name) {
+				return label.GetName(), true
+			}
+		}
+	}
+	return "", false
+}
+
+// getRepoLimiter returns the PR context comment limiter for a repository.
+func (s *WebhookServer) getRepoLimiter(repo string, perHour int) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.repoLimiters == nil {
+		s.repoLimiters = make(map[string]*rate.Limiter)
+	}
+
+	limiter, exists := s.repoLimiters[repo]
+	if !exists || limiter.Burst() != perHour {
+		limiter = rate.NewLimiter(rate.Every(time.Hour/time.Duration(perHour)), perHour)
+		s.repoLimiters[repo] = limiter
+	}
+	return limiter
+}
+
+// handlePRContextEvent starts a PR context workflow when a pull request is
+// opened in a repository with PR context comments enabled.
+func (s *WebhookServer) handlePRContextEvent(ctx context.Context, event *github.PullRequestEvent) error {
+	if s.prContext == nil || event.GetAction() != "opened" {
+		return nil
+	}
+	if err := validatePREvent(event); err != nil {
+		s.logger.Warn(ctx, "invalid PR event data", zap.Error(err))
+		return fmt.Errorf("invalid PR event: %w", err)
+	}
+
+	pr := event.GetPullRequest()
+	owner, repo := event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName()
+	settings := s.prContext.forRepo(owner, repo)
+
+	if !*settings.Enabled {
+		s.logger.Debug(ctx, "PR context disabled for repository", zap.String("repo", owner+"/"+repo))
+		return nil
+	}
+	if reason := untrustedReason(pr); reason != "" {
+		s.logger.Info(ctx, "skipping PR context comment",
+			zap.String("repo", owner+"/"+repo),
+			zap.Int("pr_number", pr.GetNumber()),
+			zap.String("reason", reason))
+		return nil
+	}
+	if label, ok := optOutLabel(pr, settings.OptOutLabels); ok {
+		s.logger.Info(ctx, "PR opted out of context comment",
+			zap.String("repo", owner+"/"+repo),
+			zap.Int("pr_number", pr.GetNumber()),
+			zap.String("label", label))
+		return nil
+	}
+	if !s.getRepoLimiter(strings.ToLower(owner+"/"+repo), settings.MaxCommentsPerHour).Allow() {
+		s.logger.Warn(ctx, "PR context rate limit exceeded",
+			zap.String("repo", owner+"/"+repo),
+			zap.Int("pr_number", pr.GetNumber()))
+		return nil
+	}
+
+	config := workflows.PRContextConfig{
+		Owner:      owner,
+		Repo:       repo,
+		PRNumber:   pr.GetNumber(),
+		TenantID:   settings.TenantID,
+		ProjectID:  settings.ProjectID,
+		MaxResults: settings.MaxResults,
+	}
+
+	options := client.StartWorkflowOptions{
+		ID:        fmt.Sprintf("pr-context-%s-%s-pr-%d", owner, repo, config.PRNumber),
+		TaskQueue: s.contextdTaskQueue,
+	}
+
+	workflowCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	we, err := s.temporalClient.ExecuteWorkflow(workflowCtx, options, workflows.PRContextWorkflow, config)
+	if err != nil {
+		return fmt.Errorf("failed to start workflow: %w", err)
+	}
+
+	s.logger.Info(ctx, "workflow started",
+		zap.String("workflow_id", we.GetID()),
+		zap.String("run_id", we.GetRunID()),
+	)
+	return nil
+}
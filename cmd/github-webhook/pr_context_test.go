@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSettings(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pr-context.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPRContextSettings(t *testing.T) {
+	t.Run("defaults without a file", func(t *testing.T) {
+		settings, err := loadPRContextSettings("")
+		require.NoError(t, err)
+
+		repo := settings.forRepo("acme", "api")
+		assert.False(t, *repo.Enabled, "repositories opt in")
+		assert.Equal(t, defaultPRCommentsPerHour, repo.MaxCommentsPerHour)
+		assert.Equal(t, []string{defaultPROptOutLabel}, repo.OptOutLabels)
+		assert.Empty(t, repo.TenantID)
+	})
+
+	t.Run("repository overrides defaults", func(t *testing.T) {
+		settings, err := loadPRContextSettings(writeSettings(t, `{
+			"defaults": {"enabled": false, "max_results": 2},
+			"repos": {
+				"Acme/API": {"enabled": true, "project_id": "api_service", "max_comments_per_hour": 3, "opt_out_labels": ["skip-bot"]}
+			}
+		}`))
+		require.NoError(t, err)
+
+		repo := settings.forRepo("acme", "api")
+		assert.True(t, *repo.Enabled, "keys match case-insensitively")
+		assert.Equal(t, "api_service", repo.ProjectID)
+		assert.Equal(t, 2, repo.MaxResults)
+		assert.Equal(t, 3, repo.MaxCommentsPerHour)
+		assert.Equal(t, []string{"skip-bot"}, repo.OptOutLabels)
+
+		assert.False(t, *settings.forRepo("acme", "web").Enabled)
+	})
+
+	t.Run("listing a repository enables it", func(t *testing.T) {
+		settings, err := loadPRContextSettings(writeSettings(t, `{
+			"repos": {"acme/api": {"project_id": "api"}, "acme/web": {"enabled": false}}
+		}`))
+		require.NoError(t, err)
+
+		assert.True(t, *settings.forRepo("acme", "api").Enabled)
+		assert.False(t, *settings.forRepo("acme", "web").Enabled)
+		assert.False(t, *settings.forRepo("acme", "docs").Enabled)
+	})
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"malformed":       `{"repos": `,
+			"repository name": `{"repos": {"api": {}}}`,
+			"project ID":      `{"repos": {"acme/api": {"project_id": "../etc"}}}`,
+			"negative limit":  `{"defaults": {"max_comments_per_hour": -1}}`,
+		} {
+			_, err := loadPRContextSettings(writeSettings(t, content))
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestOptOutLabel(t *testing.T) {
+	pr := &github.PullRequest{Labels: []*github.Label{
+		{Name: github.String("bug")},
+		{Name: github.String("No-Contextd")},
+	}}
+
+	label, ok := optOutLabel(pr, []string{"no-contextd"})
+	assert.True(t, ok)
+	assert.Equal(t, "No-Contextd", label)
+
+	_, ok = optOutLabel(pr, []string{"skip-bot"})
+	assert.False(t, ok)
+}
+
+func TestUntrustedReason(t *testing.T) {
+	pr := func(association string, fork bool) *github.PullRequest {
+		return &github.PullRequest{
+			AuthorAssociation: github.String(association),
+			Head:              &github.PullRequestBranch{Repo: &github.Repository{Fork: github.Bool(fork)}},
+		}
+	}
+
+	assert.Empty(t, untrustedReason(pr("MEMBER", false)))
+	assert.Empty(t, untrustedReason(pr("OWNER", false)))
+	assert.Empty(t, untrustedReason(pr("COLLABORATOR", false)))
+	assert.NotEmpty(t, untrustedReason(pr("MEMBER", true)), "forks are skipped")
+	assert.NotEmpty(t, untrustedReason(pr("CONTRIBUTOR", false)))
+	assert.NotEmpty(t, untrustedReason(pr("FIRST_TIME_CONTRIBUTOR", false)))
+	assert.NotEmpty(t, untrustedReason(&github.PullRequest{}))
+}
+
+func TestGetRepoLimiter(t *testing.T) {
+	s := &WebhookServer{}
+	limiter := s.getRepoLimiter("acme/api", 2)
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, s.getRepoLimiter("acme/api", 2).Allow(), "limit is shared per repository")
+	assert.True(t, s.getRepoLimiter("acme/web", 2).Allow())
+}
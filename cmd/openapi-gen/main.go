@@ -0,0 +1,41 @@
+// Package main writes the OpenAPI document of the contextd HTTP API, which
+// "make clients" generates the TypeScript and Python clients from.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	ctxhttp "github.com/fyrsmithlabs/contextd/internal/http"
+)
+
+func main() {
+	out := flag.String("o", "", "output file (default: stdout)")
+	version := flag.String("version", "dev", "API version recorded in the document")
+	flag.Parse()
+
+	if err := run(*out, *version); err != nil {
+		fmt.Fprintf(os.Stderr, "openapi-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(out, version string) error {
+	doc, err := ctxhttp.OpenAPI(version)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding document: %w", err)
+	}
+	data = append(data, '\n')
+
+	if out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
@@ -26,9 +26,12 @@ func main() {
 	verbose := flag.Bool("v", false, "Verbose output")
 	listScenarios := flag.Bool("list", false, "List available scenarios")
 	runScenario := flag.String("run", "", "Run a specific scenario by name")
-	analyzeConvos := flag.String("analyze", "", "Analyze conversation exports from directory")
+	analyzeConvos := flag.String("analyze", "", "Analyze conversation exports from directory (for live servers use ctxd stats tools)")
 	generateFrom := flag.String("generate", "", "Generate scenarios from conversation exports")
 	outputFile := flag.String("output", "", "Output file for generated scenarios")
+	serverCmd := flag.String("server", "", "Run against a real contextd MCP server started with this command (e.g. \"contextd --mcp --no-http\")")
+	recordPath := flag.String("record", "", "Record MCP tool traffic to this cassette file (requires -server)")
+	replayPath := flag.String("replay", "", "Replay MCP tool traffic from this cassette file instead of calling a server")
 	flag.Parse()
 
 	// Handle analyze mode
@@ -101,8 +104,48 @@ func main() {
 		scenarios = filtered
 	}
 
-	// Create mock client for now (will be replaced with real client)
-	client := agent.NewMockContextdClient()
+	ctx := context.Background()
+
+	if *recordPath != "" && *serverCmd == "" {
+		logger.Fatal("-record requires -server")
+	}
+	if *replayPath != "" && *serverCmd != "" {
+		logger.Fatal("-replay and -server are mutually exclusive")
+	}
+
+	// Select client: mock (default), live server, live+record, or replay
+	var client agent.ContextdClient
+	var recorder *agent.Recorder
+	var replayer *agent.Replayer
+	switch {
+	case *replayPath != "":
+		cassette, err := agent.LoadCassette(*replayPath)
+		if err != nil {
+			logger.Fatal("Failed to load cassette", zap.Error(err))
+		}
+		replayer = agent.NewReplayer(cassette, nil)
+		client = agent.NewMCPClient(replayer)
+		logger.Info("Replaying cassette",
+			zap.String("path", *replayPath),
+			zap.Int("interactions", len(cassette.Interactions)))
+
+	case *serverCmd != "":
+		session, err := agent.ConnectCommand(ctx, *serverCmd)
+		if err != nil {
+			logger.Fatal("Failed to connect to contextd", zap.Error(err))
+		}
+		defer func() { _ = session.Close() }()
+
+		var caller agent.ToolCaller = session
+		if *recordPath != "" {
+			recorder = agent.NewRecorder(session)
+			caller = recorder
+		}
+		client = agent.NewMCPClient(caller)
+
+	default:
+		client = agent.NewMockContextdClient()
+	}
 
 	// Create runner
 	runner, err := agent.NewRunner(agent.RunnerConfig{
@@ -114,15 +157,27 @@ func main() {
 	}
 
 	// Run scenarios
-	ctx := context.Background()
 	results, err := runner.RunScenarios(ctx, scenarios)
 	if err != nil {
 		logger.Fatal("Failed to run scenarios", zap.Error(err))
 	}
 
+	if recorder != nil {
+		if err := recorder.Cassette().Save(*recordPath); err != nil {
+			logger.Fatal("Failed to save cassette", zap.Error(err))
+		}
+		logger.Info("Recorded cassette", zap.String("path", *recordPath))
+	}
+
 	// Print results
 	printResults(results, *verbose)
 
+	// A replay that leaves recorded calls unused has diverged from the recording
+	if replayer != nil && replayer.Remaining() > 0 {
+		fmt.Printf("\nReplay incomplete: %d recorded interactions not replayed\n", replayer.Remaining())
+		os.Exit(1)
+	}
+
 	// Exit with error if any failed
 	for _, r := range results {
 		if !r.Passed {
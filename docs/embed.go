@@ -0,0 +1,10 @@
+// Package docs embeds the parts of the documentation that contextd serves
+// at runtime, so the binary describes the version it was built from.
+package docs
+
+import _ "embed"
+
+// Configuration is the configuration reference, configuration.md.
+//
+//go:embed configuration.md
+var Configuration string
@@ -86,6 +86,13 @@ var (
 		},
 		[]string{"project_id", "succeeded"},
 	)
+	memoryThresholdCrossings = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "contextd_memory_confidence_threshold_crossings_total",
+			Help: "Total number of memories crossing the search confidence threshold by direction and cause",
+		},
+		[]string{"project_id", "direction", "cause"},
+	)
 	memoryErrors = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "contextd_memory_errors_total",
@@ -349,6 +356,7 @@ func init() {
 		memoryRecords,
 		memoryFeedbacks,
 		memoryOutcomes,
+		memoryThresholdCrossings,
 		memoryErrors,
 		memoryCount,
 		memoryConfidence,
@@ -470,6 +478,9 @@ func generateSampleData() {
 	for i := 0; i < 20; i++ {
 		memoryOutcomes.WithLabelValues(randomChoice(projects), randomChoice([]string{"true", "false"})).Inc()
 	}
+	for i := 0; i < 10; i++ {
+		memoryThresholdCrossings.WithLabelValues(randomChoice(projects), randomChoice([]string{"promoted", "filtered_out"}), randomChoice([]string{"feedback", "outcome"})).Inc()
+	}
 	for i := 0; i < 8; i++ {
 		memoryErrors.WithLabelValues(randomChoice(projects), randomChoice(operations), randomChoice(reasons)).Inc()
 	}
@@ -619,6 +630,9 @@ func generateContinuousData(ctx context.Context) {
 			if rand.Float64() > 0.8 {
 				memoryOutcomes.WithLabelValues(randomChoice(projects), randomChoice([]string{"true", "false"})).Inc()
 			}
+			if rand.Float64() > 0.9 {
+				memoryThresholdCrossings.WithLabelValues(randomChoice(projects), randomChoice([]string{"promoted", "filtered_out"}), randomChoice([]string{"feedback", "outcome"})).Inc()
+			}
 			if rand.Float64() > 0.7 {
 				remediationSearches.WithLabelValues(randomChoice(projects), randomChoice([]string{"org", "team", "project"}), fmt.Sprintf("%d", rand.Intn(5))).Inc()
 			}
@@ -0,0 +1,663 @@
+// Package analytics counts MCP tool usage and LLM spend on a live server.
+//
+// The Tracker aggregates every tool call into per-day counters keyed by tool,
+// tenant and project: call and error counts, latency (total, max and a
+// histogram for percentiles) and result size. LLM requests made by
+// compression, extraction, distillation and troubleshooting are aggregated
+// the same way into token counts and estimated cost (see usage.go). Counters
+// are merged into one JSON rollup file per kind and UTC day on a timer and on
+// Stop, so usage survives restarts without keeping individual events.
+//
+// The one exception is the session journal (see journal.go): calls that
+// name a session are also appended to a per-day JSON lines file, kept for a
+// shorter SessionRetentionDays, so a session's interactions can be replayed.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// dayLayout names rollup files and the Day field.
+const dayLayout = "2006-01-02"
+
+// Rollup file name prefixes.
+const (
+	toolsPrefix = "tools-"
+	usagePrefix = "usage-"
+)
+
+// latencyBoundsMs are the upper bounds of the latency histogram buckets. A
+// final overflow bucket counts everything slower.
+var latencyBoundsMs = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// recentCalls is how many calls Recent can return.
+const recentCalls = 50
+
+// ErrInvalidQuery is returned by Query for unknown dimensions or an inverted
+// date range.
+var ErrInvalidQuery = errors.New("invalid analytics query")
+
+// Dimensions accepted by Query.GroupBy.
+const (
+	DimensionTool    = "tool"
+	DimensionTenant  = "tenant"
+	DimensionProject = "project"
+	DimensionDay     = "day"
+)
+
+// Call is one tool invocation.
+type Call struct {
+	Tool        string
+	TenantID    string
+	ProjectID   string
+	Time        time.Time
+	Duration    time.Duration
+	ResultBytes int
+	Failed      bool
+
+	// SessionID, when set, also journals the call for replay with the
+	// details below. Callers scrub Error and Args.
+	SessionID string
+	Error     string
+	Args      map[string]any
+	ResultIDs []string
+}
+
+// ToolStats is the rollup of calls sharing a day, tool, tenant and project.
+// In query results, dimensions that were not grouped on are empty.
+type ToolStats struct {
+	Day            string  `json:"day,omitempty"`
+	Tool           string  `json:"tool,omitempty"`
+	TenantID       string  `json:"tenant_id,omitempty"`
+	ProjectID      string  `json:"project_id,omitempty"`
+	Calls          int64   `json:"calls"`
+	Errors         int64   `json:"errors"`
+	TotalLatencyMs float64 `json:"total_latency_ms"`
+	MaxLatencyMs   float64 `json:"max_latency_ms"`
+	ResultBytes    int64   `json:"result_bytes"`
+	LatencyBuckets []int64 `json:"latency_buckets"`
+}
+
+func (s *ToolStats) add(c Call) {
+	ms := float64(c.Duration) / float64(time.Millisecond)
+	s.Calls++
+	if c.Failed {
+		s.Errors++
+	}
+	s.TotalLatencyMs += ms
+	if ms > s.MaxLatencyMs {
+		s.MaxLatencyMs = ms
+	}
+	s.ResultBytes += int64(c.ResultBytes)
+
+	if len(s.LatencyBuckets) != len(latencyBoundsMs)+1 {
+		s.LatencyBuckets = make([]int64, len(latencyBoundsMs)+1)
+	}
+	s.LatencyBuckets[sort.SearchFloat64s(latencyBoundsMs, ms)]++
+}
+
+func (s *ToolStats) merge(o ToolStats) {
+	s.Calls += o.Calls
+	s.Errors += o.Errors
+	s.TotalLatencyMs += o.TotalLatencyMs
+	if o.MaxLatencyMs > s.MaxLatencyMs {
+		s.MaxLatencyMs = o.MaxLatencyMs
+	}
+	s.ResultBytes += o.ResultBytes
+
+	if len(s.LatencyBuckets) != len(latencyBoundsMs)+1 {
+		s.LatencyBuckets = make([]int64, len(latencyBoundsMs)+1)
+	}
+	for i := 0; i < len(o.LatencyBuckets) && i < len(s.LatencyBuckets); i++ {
+		s.LatencyBuckets[i] += o.LatencyBuckets[i]
+	}
+}
+
+// ErrorRate returns the failed share of calls, 0-1.
+func (s ToolStats) ErrorRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Calls)
+}
+
+// AvgLatencyMs returns the mean call latency.
+func (s ToolStats) AvgLatencyMs() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatencyMs / float64(s.Calls)
+}
+
+// AvgResultBytes returns the mean result size.
+func (s ToolStats) AvgResultBytes() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.ResultBytes) / float64(s.Calls)
+}
+
+// PercentileMs estimates the p-th latency percentile (0-100) as the upper
+// bound of the histogram bucket it falls in, capped at the observed maximum.
+func (s ToolStats) PercentileMs(p float64) float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	rank := int64(float64(s.Calls)*p/100 + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for i, n := range s.LatencyBuckets {
+		seen += n
+		if seen < rank {
+			continue
+		}
+		if i < len(latencyBoundsMs) && latencyBoundsMs[i] < s.MaxLatencyMs {
+			return latencyBoundsMs[i]
+		}
+		break
+	}
+	return s.MaxLatencyMs
+}
+
+// Summary is a ToolStats row with derived values, as served over HTTP.
+type Summary struct {
+	Day            string  `json:"day,omitempty"`
+	Tool           string  `json:"tool,omitempty"`
+	TenantID       string  `json:"tenant_id,omitempty"`
+	ProjectID      string  `json:"project_id,omitempty"`
+	Calls          int64   `json:"calls"`
+	Errors         int64   `json:"errors"`
+	ErrorRate      float64 `json:"error_rate"`
+	AvgLatencyMs   float64 `json:"avg_latency_ms"`
+	P95LatencyMs   float64 `json:"p95_latency_ms"`
+	MaxLatencyMs   float64 `json:"max_latency_ms"`
+	AvgResultBytes float64 `json:"avg_result_bytes"`
+}
+
+// Summary returns the derived view of s.
+func (s ToolStats) Summary() Summary {
+	return Summary{
+		Day:            s.Day,
+		Tool:           s.Tool,
+		TenantID:       s.TenantID,
+		ProjectID:      s.ProjectID,
+		Calls:          s.Calls,
+		Errors:         s.Errors,
+		ErrorRate:      s.ErrorRate(),
+		AvgLatencyMs:   s.AvgLatencyMs(),
+		P95LatencyMs:   s.PercentileMs(95),
+		MaxLatencyMs:   s.MaxLatencyMs,
+		AvgResultBytes: s.AvgResultBytes(),
+	}
+}
+
+// Query selects and groups rollups.
+type Query struct {
+	// From and To bound the days included, inclusive. Zero To means today;
+	// zero From means To.
+	From time.Time
+	To   time.Time
+
+	// Tool, TenantID and ProjectID filter rows when set.
+	Tool      string
+	TenantID  string
+	ProjectID string
+
+	// GroupBy lists the Dimension* values rows are grouped on.
+	// Default: tool.
+	GroupBy []string
+}
+
+// Config configures a Tracker.
+type Config struct {
+	// Dir holds the daily rollup files. Required.
+	Dir string
+
+	// FlushInterval between rollup writes. Default: 1 minute.
+	FlushInterval time.Duration
+
+	// RetentionDays is how many days of rollups are kept. Default: 90.
+	RetentionDays int
+
+	// SessionRetentionDays is how many days of session journals are kept.
+	// Default: 14.
+	SessionRetentionDays int
+}
+
+// Tracker aggregates tool calls and persists daily rollups.
+type Tracker struct {
+	config Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu            sync.Mutex
+	pending       map[string]*ToolStats  // calls not yet flushed, keyed by rowKey
+	pendingUsage  map[string]*UsageStats // LLM usage not yet flushed, keyed by usageKey
+	pendingEvents []Event                // session events not yet journaled
+	recent        []Call                 // ring of the last recentCalls calls
+	next          int                    // index in recent of the next call
+	metrics       *usageMetrics
+
+	// flushMu keeps Query from reading rollups while a flush has moved
+	// pending counters out but not yet written them.
+	flushMu sync.Mutex
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewTracker creates a Tracker, creating Dir if needed.
+func NewTracker(cfg Config, logger *zap.Logger) (*Tracker, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("dir is required")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Minute
+	}
+	if cfg.RetentionDays <= 0 {
+		cfg.RetentionDays = 90
+	}
+	if cfg.SessionRetentionDays <= 0 {
+		cfg.SessionRetentionDays = 14
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating analytics directory: %w", err)
+	}
+
+	return &Tracker{
+		config:       cfg,
+		logger:       logger,
+		now:          time.Now,
+		pending:      make(map[string]*ToolStats),
+		pendingUsage: make(map[string]*UsageStats),
+		metrics:      newUsageMetrics(logger),
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}, nil
+}
+
+// Record counts one call. It never blocks on disk.
+func (t *Tracker) Record(c Call) {
+	if c.Time.IsZero() {
+		c.Time = t.now()
+	}
+	row := ToolStats{
+		Day:       c.Time.UTC().Format(dayLayout),
+		Tool:      c.Tool,
+		TenantID:  c.TenantID,
+		ProjectID: c.ProjectID,
+	}
+	key := rowKey(row)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.pending[key]
+	if !ok {
+		s = &row
+		t.pending[key] = s
+	}
+	s.add(c)
+	if c.SessionID != "" {
+		t.pendingEvents = append(t.pendingEvents, newEvent(c))
+	}
+
+	if len(t.recent) < recentCalls {
+		t.recent = append(t.recent, c)
+	} else {
+		t.recent[t.next] = c
+	}
+	t.next = (t.next + 1) % recentCalls
+}
+
+// Recent returns up to n of the latest calls, newest first. Recent calls are
+// kept in memory only.
+func (t *Tracker) Recent(n int) []Call {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n = min(n, len(t.recent))
+	calls := make([]Call, 0, n)
+	for i := 1; i <= n; i++ {
+		calls = append(calls, t.recent[(t.next-i+recentCalls)%recentCalls])
+	}
+	return calls
+}
+
+// Start flushes every FlushInterval until Stop is called or ctx is done.
+func (t *Tracker) Start(ctx context.Context) {
+	go func() {
+		defer close(t.doneCh)
+		ticker := time.NewTicker(t.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.stopCh:
+				return
+			case <-ticker.C:
+				if err := t.Flush(); err != nil {
+					t.logger.Warn("failed to flush tool analytics", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop halts periodic flushes and writes what is still pending. It must only
+// be called after Start.
+func (t *Tracker) Stop() error {
+	t.stopOnce.Do(func() { close(t.stopCh) })
+	<-t.doneCh
+	return t.Flush()
+}
+
+// Flush merges pending counters into the rollup files, appends pending
+// session events to the journal and removes files past their retention.
+func (t *Tracker) Flush() error {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[string]*ToolStats)
+	t.mu.Unlock()
+
+	byDay := make(map[string][]ToolStats)
+	for _, s := range pending {
+		byDay[s.Day] = append(byDay[s.Day], *s)
+	}
+
+	var errs []error
+	for day, rows := range byDay {
+		if err := t.mergeDay(day, rows); err != nil {
+			errs = append(errs, err)
+			// Put the counters back so the next flush retries them
+			t.mu.Lock()
+			for _, row := range rows {
+				key := rowKey(row)
+				if s, ok := t.pending[key]; ok {
+					s.merge(row)
+				} else {
+					r := row
+					t.pending[key] = &r
+				}
+			}
+			t.mu.Unlock()
+		}
+	}
+
+	if err := t.flushUsage(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := t.flushEvents(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := t.prune(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Query returns rollups matching q, grouped and sorted by call count.
+// Counters not yet flushed are included.
+func (t *Tracker) Query(q Query) ([]ToolStats, error) {
+	groupBy := q.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{DimensionTool}
+	}
+	for _, d := range groupBy {
+		switch d {
+		case DimensionTool, DimensionTenant, DimensionProject, DimensionDay:
+		default:
+			return nil, fmt.Errorf("%w: unknown group_by dimension %q", ErrInvalidQuery, d)
+		}
+	}
+
+	to := q.To
+	if to.IsZero() {
+		to = t.now()
+	}
+	from := q.From
+	if from.IsZero() {
+		from = to
+	}
+	fromDay, toDay := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+	if fromDay > toDay {
+		return nil, fmt.Errorf("%w: from %s is after to %s", ErrInvalidQuery, fromDay, toDay)
+	}
+
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	var rows []ToolStats
+	days, err := t.days(toolsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		if day < fromDay || day > toDay {
+			continue
+		}
+		dayRows, err := t.readDay(day)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, dayRows...)
+	}
+
+	t.mu.Lock()
+	for _, s := range t.pending {
+		if s.Day >= fromDay && s.Day <= toDay {
+			rows = append(rows, *s)
+		}
+	}
+	t.mu.Unlock()
+
+	grouped := make(map[string]*ToolStats)
+	for _, row := range rows {
+		if (q.Tool != "" && row.Tool != q.Tool) ||
+			(q.TenantID != "" && row.TenantID != q.TenantID) ||
+			(q.ProjectID != "" && row.ProjectID != q.ProjectID) {
+			continue
+		}
+
+		g := groupRow(row, groupBy)
+		key := rowKey(g)
+		if s, ok := grouped[key]; ok {
+			s.merge(row)
+		} else {
+			g.merge(row)
+			grouped[key] = &g
+		}
+	}
+
+	result := make([]ToolStats, 0, len(grouped))
+	for _, s := range grouped {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Calls != result[j].Calls {
+			return result[i].Calls > result[j].Calls
+		}
+		return rowKey(result[i]) < rowKey(result[j])
+	})
+	return result, nil
+}
+
+// groupRow keeps only the grouped dimensions of row, with zero counters.
+func groupRow(row ToolStats, groupBy []string) ToolStats {
+	var g ToolStats
+	for _, d := range groupBy {
+		switch d {
+		case DimensionTool:
+			g.Tool = row.Tool
+		case DimensionTenant:
+			g.TenantID = row.TenantID
+		case DimensionProject:
+			g.ProjectID = row.ProjectID
+		case DimensionDay:
+			g.Day = row.Day
+		}
+	}
+	return g
+}
+
+func rowKey(s ToolStats) string {
+	return strings.Join([]string{s.Day, s.Tool, s.TenantID, s.ProjectID}, "\x00")
+}
+
+// dayFile is the on-disk form of one day's rollup.
+type dayFile struct {
+	Day  string      `json:"day"`
+	Rows []ToolStats `json:"rows"`
+}
+
+func (t *Tracker) dayPath(day string) string {
+	return t.rollupPath(toolsPrefix, day)
+}
+
+func (t *Tracker) rollupPath(prefix, day string) string {
+	return filepath.Join(t.config.Dir, prefix+day+".json")
+}
+
+// days lists the days that have a rollup file with the given prefix, oldest
+// first.
+func (t *Tracker) days(prefix string) ([]string, error) {
+	return t.files(prefix, ".json")
+}
+
+// files lists the days that have a file named prefix+day+ext, oldest first.
+func (t *Tracker) files(prefix, ext string) ([]string, error) {
+	entries, err := os.ReadDir(t.config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading analytics directory: %w", err)
+	}
+
+	var days []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		day := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(dayLayout, day); err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+func (t *Tracker) readDay(day string) ([]ToolStats, error) {
+	data, err := os.ReadFile(t.dayPath(day))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading rollup for %s: %w", day, err)
+	}
+
+	var f dayFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decoding rollup for %s: %w", day, err)
+	}
+	for i := range f.Rows {
+		f.Rows[i].Day = day
+	}
+	return f.Rows, nil
+}
+
+// mergeDay adds rows to the day's rollup file, replacing it atomically.
+func (t *Tracker) mergeDay(day string, rows []ToolStats) error {
+	existing, err := t.readDay(day)
+	if err != nil {
+		return err
+	}
+
+	merged := make(map[string]*ToolStats, len(existing)+len(rows))
+	for _, row := range append(existing, rows...) {
+		key := rowKey(row)
+		if s, ok := merged[key]; ok {
+			s.merge(row)
+		} else {
+			r := row
+			merged[key] = &r
+		}
+	}
+
+	f := dayFile{Day: day, Rows: make([]ToolStats, 0, len(merged))}
+	for _, s := range merged {
+		f.Rows = append(f.Rows, *s)
+	}
+	sort.Slice(f.Rows, func(i, j int) bool { return rowKey(f.Rows[i]) < rowKey(f.Rows[j]) })
+
+	return t.writeRollup(t.dayPath(day), day, f)
+}
+
+// writeRollup replaces the rollup file at path with v atomically.
+func (t *Tracker) writeRollup(path, day string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding rollup for %s: %w", day, err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing rollup for %s: %w", day, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing rollup for %s: %w", day, err)
+	}
+	return nil
+}
+
+// prune removes rollups older than RetentionDays and session journals older
+// than SessionRetentionDays.
+func (t *Tracker) prune() error {
+	for _, kind := range []struct {
+		prefix, ext   string
+		retentionDays int
+	}{
+		{toolsPrefix, ".json", t.config.RetentionDays},
+		{usagePrefix, ".json", t.config.RetentionDays},
+		{journalPrefix, journalExt, t.config.SessionRetentionDays},
+	} {
+		cutoff := t.now().UTC().AddDate(0, 0, -kind.retentionDays).Format(dayLayout)
+		days, err := t.files(kind.prefix, kind.ext)
+		if err != nil {
+			return err
+		}
+		for _, day := range days {
+			if day >= cutoff {
+				break
+			}
+			path := filepath.Join(t.config.Dir, kind.prefix+day+kind.ext)
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("removing expired rollup %s: %w", kind.prefix+day, err)
+			}
+		}
+	}
+	return nil
+}
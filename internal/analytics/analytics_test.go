@@ -0,0 +1,168 @@
+package analytics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestTracker(t *testing.T, now time.Time) *Tracker {
+	t.Helper()
+	tracker, err := NewTracker(Config{Dir: t.TempDir(), RetentionDays: 30}, zap.NewNop())
+	require.NoError(t, err)
+	tracker.now = func() time.Time { return now }
+	return tracker
+}
+
+func TestNewTracker_Validation(t *testing.T) {
+	_, err := NewTracker(Config{}, zap.NewNop())
+	assert.Error(t, err)
+
+	_, err = NewTracker(Config{Dir: t.TempDir()}, nil)
+	assert.Error(t, err)
+}
+
+func TestTracker_RecordAndQuery(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.Record(Call{Tool: "memory_search", TenantID: "acme", ProjectID: "api", Duration: 20 * time.Millisecond, ResultBytes: 1000})
+	tracker.Record(Call{Tool: "memory_search", TenantID: "acme", ProjectID: "web", Duration: 40 * time.Millisecond, ResultBytes: 3000, Failed: true})
+	tracker.Record(Call{Tool: "checkpoint_save", TenantID: "acme", ProjectID: "api", Duration: 5 * time.Millisecond})
+
+	rows, err := tracker.Query(Query{})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	search := rows[0]
+	assert.Equal(t, "memory_search", search.Tool)
+	assert.Empty(t, search.ProjectID, "project is not a grouped dimension")
+	assert.Equal(t, int64(2), search.Calls)
+	assert.Equal(t, int64(1), search.Errors)
+	assert.InDelta(t, 0.5, search.ErrorRate(), 1e-9)
+	assert.InDelta(t, 30, search.AvgLatencyMs(), 1e-9)
+	assert.InDelta(t, 40, search.MaxLatencyMs, 1e-9)
+	assert.InDelta(t, 2000, search.AvgResultBytes(), 1e-9)
+
+	rows, err = tracker.Query(Query{GroupBy: []string{DimensionProject}, Tool: "memory_search"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.ElementsMatch(t, []string{"api", "web"}, []string{rows[0].ProjectID, rows[1].ProjectID})
+}
+
+func TestTracker_Recent(t *testing.T) {
+	tracker := newTestTracker(t, time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	assert.Empty(t, tracker.Recent(10))
+
+	for i := 0; i < recentCalls+5; i++ {
+		tracker.Record(Call{Tool: "memory_search", ResultBytes: i})
+	}
+
+	calls := tracker.Recent(3)
+	require.Len(t, calls, 3)
+	assert.Equal(t, []int{recentCalls + 4, recentCalls + 3, recentCalls + 2},
+		[]int{calls[0].ResultBytes, calls[1].ResultBytes, calls[2].ResultBytes}, "newest first")
+	assert.Len(t, tracker.Recent(1000), recentCalls)
+	assert.Equal(t, 5, tracker.Recent(recentCalls)[recentCalls-1].ResultBytes, "oldest calls are dropped")
+}
+
+func TestTracker_FlushPersistsAndMerges(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.Record(Call{Tool: "memory_search", Duration: time.Millisecond})
+	require.NoError(t, tracker.Flush())
+	tracker.Record(Call{Tool: "memory_search", Duration: time.Millisecond})
+	require.NoError(t, tracker.Flush())
+	assert.FileExists(t, filepath.Join(tracker.config.Dir, "tools-2026-10-15.json"))
+
+	// A new tracker on the same directory sees the flushed rollups
+	reopened, err := NewTracker(tracker.config, zap.NewNop())
+	require.NoError(t, err)
+	reopened.now = tracker.now
+	reopened.Record(Call{Tool: "memory_search", Duration: time.Millisecond})
+
+	rows, err := reopened.Query(Query{})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(3), rows[0].Calls)
+}
+
+func TestTracker_QueryDateRange(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.Record(Call{Tool: "memory_search", Time: now.AddDate(0, 0, -3)})
+	tracker.Record(Call{Tool: "memory_search", Time: now.AddDate(0, 0, -1)})
+	tracker.Record(Call{Tool: "memory_search", Time: now})
+	require.NoError(t, tracker.Flush())
+
+	rows, err := tracker.Query(Query{From: now.AddDate(0, 0, -1)})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(2), rows[0].Calls)
+
+	rows, err = tracker.Query(Query{From: now.AddDate(0, 0, -7), GroupBy: []string{DimensionDay}})
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	_, err = tracker.Query(Query{From: now, To: now.AddDate(0, 0, -1)})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = tracker.Query(Query{GroupBy: []string{"session"}})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestTracker_PrunesExpiredRollups(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.Record(Call{Tool: "memory_search", Time: now.AddDate(0, 0, -31)})
+	tracker.Record(Call{Tool: "memory_search", Time: now})
+	require.NoError(t, tracker.Flush())
+
+	days, err := tracker.days(toolsPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-10-15"}, days)
+}
+
+func TestTracker_StopFlushes(t *testing.T) {
+	tracker := newTestTracker(t, time.Now())
+	tracker.Start(context.Background())
+	tracker.Record(Call{Tool: "memory_search"})
+	require.NoError(t, tracker.Stop())
+
+	days, err := tracker.days(toolsPrefix)
+	require.NoError(t, err)
+	assert.Len(t, days, 1)
+}
+
+func TestTracker_IgnoresUnrelatedFiles(t *testing.T) {
+	tracker := newTestTracker(t, time.Now())
+	require.NoError(t, os.WriteFile(filepath.Join(tracker.config.Dir, "notes.txt"), []byte("x"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tracker.config.Dir, "tools-garbage.json"), []byte("x"), 0o600))
+
+	_, err := tracker.Query(Query{})
+	assert.NoError(t, err)
+}
+
+func TestToolStats_PercentileMs(t *testing.T) {
+	var s ToolStats
+	for i := 0; i < 95; i++ {
+		s.add(Call{Duration: 3 * time.Millisecond})
+	}
+	for i := 0; i < 5; i++ {
+		s.add(Call{Duration: 700 * time.Millisecond})
+	}
+
+	assert.Equal(t, 5.0, s.PercentileMs(50))
+	assert.Equal(t, 5.0, s.PercentileMs(95))
+	assert.Equal(t, 700.0, s.PercentileMs(99))
+	assert.Equal(t, 0.0, ToolStats{}.PercentileMs(95))
+}
@@ -0,0 +1,166 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// ErrBudgetExhausted is returned by Budget.Allow once a tenant has used its
+// monthly LLM tokens.
+var ErrBudgetExhausted = errors.New("monthly LLM token budget exhausted")
+
+// LLMBudget gates LLM requests. Features call Allow before each request and
+// degrade instead of calling the LLM when it returns an error. *Budget
+// implements it; a nil *Budget allows everything.
+type LLMBudget interface {
+	Allow(ctx context.Context, feature string) error
+}
+
+// BudgetConfig configures a Budget.
+type BudgetConfig struct {
+	// MonthlyTokens caps the input plus output tokens each tenant may use per
+	// UTC calendar month. 0 means unlimited.
+	MonthlyTokens int64
+
+	// TenantTokens overrides MonthlyTokens per tenant ID. 0 means unlimited
+	// for that tenant.
+	TenantTokens map[string]int64
+
+	// CacheTTL is how long a tenant's monthly total is reused before the
+	// rollups are read again. Default: 30 seconds.
+	CacheTTL time.Duration
+}
+
+// Budget enforces monthly token caps per tenant using the usage recorded by
+// a Tracker. Totals are cached for CacheTTL, so a tenant may overshoot its
+// cap by the requests made within that window.
+type Budget struct {
+	tracker *Tracker
+	config  BudgetConfig
+	logger  *zap.Logger
+	now     func() time.Time
+
+	degraded metric.Int64Counter
+
+	mu    sync.Mutex
+	spent map[string]monthSpend // keyed by tenant ID
+}
+
+type monthSpend struct {
+	month  string
+	tokens int64
+	at     time.Time
+}
+
+// NewBudget creates a Budget over tracker's usage rollups.
+func NewBudget(tracker *Tracker, cfg BudgetConfig, logger *zap.Logger) (*Budget, error) {
+	if tracker == nil {
+		return nil, fmt.Errorf("tracker is required")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 30 * time.Second
+	}
+
+	b := &Budget{
+		tracker: tracker,
+		config:  cfg,
+		logger:  logger,
+		now:     time.Now,
+		spent:   make(map[string]monthSpend),
+	}
+
+	var err error
+	b.degraded, err = otel.Meter(usageInstrumentationName).Int64Counter(
+		"contextd.llm.degraded_total",
+		metric.WithDescription("Operations that skipped the LLM because the tenant's monthly token budget is exhausted, labeled by feature."),
+		metric.WithUnit("{operation}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create LLM degraded counter", zap.Error(err))
+	}
+	return b, nil
+}
+
+// Limit returns the monthly token cap for tenantID, 0 when unlimited.
+func (b *Budget) Limit(tenantID string) int64 {
+	if limit, ok := b.config.TenantTokens[tenantID]; ok {
+		return limit
+	}
+	return b.config.MonthlyTokens
+}
+
+// Allow returns an error wrapping ErrBudgetExhausted when the tenant in ctx's
+// Scope has used its monthly tokens, and counts the caller's operation as
+// degraded. Errors reading the rollups allow the request.
+func (b *Budget) Allow(ctx context.Context, feature string) error {
+	if b == nil {
+		return nil
+	}
+	scope, _ := ScopeFromContext(ctx)
+	limit := b.Limit(scope.TenantID)
+	if limit <= 0 {
+		return nil
+	}
+
+	used, err := b.monthTokens(scope.TenantID)
+	if err != nil {
+		b.logger.Warn("failed to read LLM usage for budget check", zap.Error(err))
+		return nil
+	}
+	if used < limit {
+		return nil
+	}
+
+	if b.degraded != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		b.degraded.Add(ctx, 1, metric.WithAttributes(attribute.String("feature", feature)))
+	}
+	return fmt.Errorf("%w: tenant %q used %d of %d tokens this month", ErrBudgetExhausted, scope.TenantID, used, limit)
+}
+
+// monthTokens returns the tokens tenantID used this UTC month.
+func (b *Budget) monthTokens(tenantID string) (int64, error) {
+	now := b.now().UTC()
+	month := now.Format("2006-01")
+
+	b.mu.Lock()
+	cached, ok := b.spent[tenantID]
+	b.mu.Unlock()
+	if ok && cached.month == month && now.Sub(cached.at) < b.config.CacheTTL {
+		return cached.tokens, nil
+	}
+
+	rows, err := b.tracker.QueryUsage(UsageQuery{
+		From:     time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		To:       now,
+		TenantID: tenantID,
+		GroupBy:  []string{DimensionTenant},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var tokens int64
+	for _, row := range rows {
+		if row.TenantID == tenantID {
+			tokens += row.InputTokens + row.OutputTokens
+		}
+	}
+
+	b.mu.Lock()
+	b.spent[tenantID] = monthSpend{month: month, tokens: tokens, at: now}
+	b.mu.Unlock()
+	return tokens, nil
+}
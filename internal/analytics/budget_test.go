@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBudget_Allow(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+	budget, err := NewBudget(tracker, BudgetConfig{
+		MonthlyTokens: 1000,
+		TenantTokens:  map[string]int64{"bigco": 0, "small": 100},
+	}, zap.NewNop())
+	require.NoError(t, err)
+	budget.now = func() time.Time { return now }
+
+	acme := ContextWithScope(context.Background(), Scope{TenantID: "acme"})
+	small := ContextWithScope(context.Background(), Scope{TenantID: "small"})
+	bigco := ContextWithScope(context.Background(), Scope{TenantID: "bigco"})
+
+	// Last month's usage does not count
+	tracker.RecordUsage(acme, LLMUsage{Feature: FeatureDistiller, Time: now.AddDate(0, -1, 0), InputTokens: 5000})
+	tracker.RecordUsage(acme, LLMUsage{Feature: FeatureDistiller, InputTokens: 600, OutputTokens: 100})
+	tracker.RecordUsage(small, LLMUsage{Feature: FeatureDistiller, InputTokens: 100})
+	tracker.RecordUsage(bigco, LLMUsage{Feature: FeatureDistiller, InputTokens: 1_000_000})
+
+	assert.NoError(t, budget.Allow(acme, FeatureDistiller))
+	assert.NoError(t, budget.Allow(bigco, FeatureDistiller), "0 override means unlimited")
+
+	err = budget.Allow(small, FeatureCompression)
+	require.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.Contains(t, err.Error(), `tenant "small" used 100 of 100 tokens`)
+
+	// Totals are cached until CacheTTL passes
+	tracker.RecordUsage(acme, LLMUsage{Feature: FeatureDistiller, InputTokens: 300})
+	assert.NoError(t, budget.Allow(acme, FeatureDistiller))
+	now = now.Add(time.Minute)
+	assert.ErrorIs(t, budget.Allow(acme, FeatureDistiller), ErrBudgetExhausted)
+
+	// A new month starts over
+	now = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	assert.NoError(t, budget.Allow(acme, FeatureDistiller))
+
+	var nilBudget *Budget
+	assert.NoError(t, nilBudget.Allow(small, FeatureDistiller))
+}
+
+func TestNewBudget_Validation(t *testing.T) {
+	_, err := NewBudget(nil, BudgetConfig{}, zap.NewNop())
+	assert.Error(t, err)
+}
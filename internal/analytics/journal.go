@@ -0,0 +1,202 @@
+package analytics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// journalPrefix names the session journal files, one JSON event per line.
+const (
+	journalPrefix = "sessions-"
+	journalExt    = ".jsonl"
+)
+
+// maxJournalLine bounds the size of one journal line when reading.
+const maxJournalLine = 1 << 20
+
+// Event is one tool call made in a session, as kept for replay. Unlike the
+// rollups, events keep what was asked and returned, so they are only kept
+// for SessionRetentionDays.
+type Event struct {
+	Time       time.Time      `json:"time"`
+	SessionID  string         `json:"session_id"`
+	Tool       string         `json:"tool"`
+	TenantID   string         `json:"tenant_id,omitempty"`
+	ProjectID  string         `json:"project_id,omitempty"`
+	DurationMs float64        `json:"duration_ms"`
+	Failed     bool           `json:"failed,omitempty"`
+	Error      string         `json:"error,omitempty"`      // Scrubbed error message
+	Args       map[string]any `json:"args,omitempty"`       // Scrubbed arguments that identify the request
+	ResultIDs  []string       `json:"result_ids,omitempty"` // IDs of the memories, checkpoints or remediations returned
+}
+
+// newEvent converts a call made in a session to its journal event.
+func newEvent(c Call) Event {
+	return Event{
+		Time:       c.Time.UTC(),
+		SessionID:  c.SessionID,
+		Tool:       c.Tool,
+		TenantID:   c.TenantID,
+		ProjectID:  c.ProjectID,
+		DurationMs: float64(c.Duration) / float64(time.Millisecond),
+		Failed:     c.Failed,
+		Error:      c.Error,
+		Args:       c.Args,
+		ResultIDs:  c.ResultIDs,
+	}
+}
+
+// SessionQuery selects the events of one session.
+type SessionQuery struct {
+	// SessionID is the session to replay. Required.
+	SessionID string
+
+	// From and To bound the days searched, inclusive. Zero To means today;
+	// zero From means To.
+	From time.Time
+	To   time.Time
+}
+
+// SessionEvents returns the tool calls made in a session, oldest first.
+// Events not yet flushed are included.
+func (t *Tracker) SessionEvents(q SessionQuery) ([]Event, error) {
+	if q.SessionID == "" {
+		return nil, fmt.Errorf("%w: session_id is required", ErrInvalidQuery)
+	}
+	to := q.To
+	if to.IsZero() {
+		to = t.now()
+	}
+	from := q.From
+	if from.IsZero() {
+		from = to
+	}
+	fromDay, toDay := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+	if fromDay > toDay {
+		return nil, fmt.Errorf("%w: from %s is after to %s", ErrInvalidQuery, fromDay, toDay)
+	}
+
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	var events []Event
+	days, err := t.files(journalPrefix, journalExt)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		if day < fromDay || day > toDay {
+			continue
+		}
+		dayEvents, err := t.readJournal(day, q.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, dayEvents...)
+	}
+
+	t.mu.Lock()
+	for _, e := range t.pendingEvents {
+		day := e.Time.Format(dayLayout)
+		if e.SessionID == q.SessionID && day >= fromDay && day <= toDay {
+			events = append(events, e)
+		}
+	}
+	t.mu.Unlock()
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+func (t *Tracker) journalPath(day string) string {
+	return filepath.Join(t.config.Dir, journalPrefix+day+journalExt)
+}
+
+// readJournal returns the day's events of sessionID. Lines that fail to
+// decode, such as one torn by a crash mid-write, are skipped.
+func (t *Tracker) readJournal(day, sessionID string) ([]Event, error) {
+	f, err := os.Open(t.journalPath(day))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading session journal for %s: %w", day, err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxJournalLine)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if e.SessionID == sessionID {
+			events = append(events, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading session journal for %s: %w", day, err)
+	}
+	return events, nil
+}
+
+// flushEvents appends pending session events to the journal files. The
+// caller holds flushMu.
+func (t *Tracker) flushEvents() error {
+	t.mu.Lock()
+	pending := t.pendingEvents
+	t.pendingEvents = nil
+	t.mu.Unlock()
+
+	byDay := make(map[string][]Event)
+	for _, e := range pending {
+		day := e.Time.Format(dayLayout)
+		byDay[day] = append(byDay[day], e)
+	}
+
+	var errs []error
+	for day, events := range byDay {
+		if err := t.appendJournal(day, events); err != nil {
+			errs = append(errs, err)
+			// Put the events back so the next flush retries them
+			t.mu.Lock()
+			t.pendingEvents = append(events, t.pendingEvents...)
+			t.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// appendJournal appends events to the day's journal, one write per flush so
+// a failed write leaves at most one torn line.
+func (t *Tracker) appendJournal(day string, events []Event) error {
+	var buf []byte
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encoding session event for %s: %w", day, err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	f, err := os.OpenFile(t.journalPath(day), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("writing session journal for %s: %w", day, err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing session journal for %s: %w", day, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing session journal for %s: %w", day, err)
+	}
+	return nil
+}
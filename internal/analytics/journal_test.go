@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_SessionEvents(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.Record(Call{
+		Tool: "memory_search", TenantID: "acme", ProjectID: "api", SessionID: "sess-1",
+		Time: now.Add(-time.Hour), Duration: 20 * time.Millisecond,
+		Args: map[string]any{"query": "retry with backoff"}, ResultIDs: []string{"mem-1", "mem-2"},
+	})
+	tracker.Record(Call{Tool: "memory_search", TenantID: "acme", ProjectID: "api", SessionID: "sess-2", Time: now.Add(-time.Hour)})
+	tracker.Record(Call{Tool: "checkpoint_list", TenantID: "acme", ProjectID: "api", Time: now.Add(-time.Hour)})
+
+	// Flushed events are read back from the journal
+	require.NoError(t, tracker.Flush())
+	tracker.Record(Call{
+		Tool: "memory_feedback", SessionID: "sess-1", Time: now.Add(-time.Minute),
+		Failed: true, Error: "memory not found", Args: map[string]any{"memory_id": "mem-2", "helpful": true},
+	})
+
+	events, err := tracker.SessionEvents(SessionQuery{SessionID: "sess-1"})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	search := events[0]
+	assert.Equal(t, "memory_search", search.Tool)
+	assert.Equal(t, "api", search.ProjectID)
+	assert.InDelta(t, 20, search.DurationMs, 1e-9)
+	assert.Equal(t, "retry with backoff", search.Args["query"])
+	assert.Equal(t, []string{"mem-1", "mem-2"}, search.ResultIDs)
+
+	feedback := events[1]
+	assert.Equal(t, "memory_feedback", feedback.Tool, "pending events are included")
+	assert.True(t, feedback.Failed)
+	assert.Equal(t, "memory not found", feedback.Error)
+
+	// Calls without a session are counted but not journaled
+	rows, err := tracker.Query(Query{})
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	_, err = tracker.SessionEvents(SessionQuery{})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestTracker_SessionEvents_Window(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.Record(Call{Tool: "memory_search", SessionID: "sess-1", Time: now.AddDate(0, 0, -3)})
+	tracker.Record(Call{Tool: "memory_outcome", SessionID: "sess-1", Time: now})
+	require.NoError(t, tracker.Flush())
+
+	events, err := tracker.SessionEvents(SessionQuery{SessionID: "sess-1"})
+	require.NoError(t, err)
+	require.Len(t, events, 1, "defaults to today")
+	assert.Equal(t, "memory_outcome", events[0].Tool)
+
+	events, err = tracker.SessionEvents(SessionQuery{SessionID: "sess-1", From: now.AddDate(0, 0, -7)})
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
+
+func TestTracker_SessionJournalRetention(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.Record(Call{Tool: "memory_search", SessionID: "old", Time: now.AddDate(0, 0, -20)})
+	tracker.Record(Call{Tool: "memory_search", SessionID: "new", Time: now})
+	require.NoError(t, tracker.Flush())
+
+	_, err := os.Stat(filepath.Join(tracker.config.Dir, "sessions-2026-09-25.jsonl"))
+	assert.True(t, os.IsNotExist(err), "journals past the 14 day default are pruned")
+	_, err = os.Stat(filepath.Join(tracker.config.Dir, "tools-2026-09-25.json"))
+	assert.NoError(t, err, "rollups keep their own retention")
+	_, err = os.Stat(filepath.Join(tracker.config.Dir, "sessions-2026-10-15.jsonl"))
+	assert.NoError(t, err)
+}
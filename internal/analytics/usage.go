@@ -0,0 +1,535 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+const usageInstrumentationName = "github.com/fyrsmithlabs/contextd/internal/analytics"
+
+// Features that spend LLM tokens.
+const (
+	FeatureCompression  = "compression"
+	FeatureExtraction   = "extraction"
+	FeatureDistiller    = "distiller"
+	FeatureTroubleshoot = "troubleshoot"
+	FeatureDigest       = "digest"
+)
+
+// Providers with known prices.
+const (
+	ProviderAnthropic = "anthropic"
+	ProviderOpenAI    = "openai"
+)
+
+// Additional dimensions accepted by UsageQuery.GroupBy, alongside
+// DimensionTenant, DimensionProject and DimensionDay.
+const (
+	DimensionFeature  = "feature"
+	DimensionProvider = "provider"
+	DimensionModel    = "model"
+	DimensionSession  = "session"
+)
+
+// LLMUsage is one LLM request made on behalf of a feature.
+type LLMUsage struct {
+	Feature      string
+	Provider     string
+	Model        string
+	TenantID     string
+	ProjectID    string
+	SessionID    string
+	Time         time.Time
+	InputTokens  int64
+	OutputTokens int64
+	Failed       bool
+}
+
+// UsageRecorder receives LLM usage. *Tracker implements it; a nil *Tracker
+// discards usage.
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, u LLMUsage)
+}
+
+// Price is the cost in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// prices maps "provider/model prefix" to list prices. The longest matching
+// prefix wins, so dated model names resolve to their family.
+var prices = map[string]Price{
+	ProviderAnthropic + "/claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	ProviderAnthropic + "/claude-3-5-haiku":  {Input: 0.80, Output: 4},
+	ProviderAnthropic + "/claude-haiku-4":    {Input: 1, Output: 5},
+	ProviderAnthropic + "/claude-3-5-sonnet": {Input: 3, Output: 15},
+	ProviderAnthropic + "/claude-3-7-sonnet": {Input: 3, Output: 15},
+	ProviderAnthropic + "/claude-sonnet-4":   {Input: 3, Output: 15},
+	ProviderAnthropic + "/claude-3-opus":     {Input: 15, Output: 75},
+	ProviderAnthropic + "/claude-opus-4":     {Input: 15, Output: 75},
+	ProviderOpenAI + "/gpt-4o":               {Input: 2.50, Output: 10},
+	ProviderOpenAI + "/gpt-4o-mini":          {Input: 0.15, Output: 0.60},
+	ProviderOpenAI + "/gpt-4.1":              {Input: 2, Output: 8},
+	ProviderOpenAI + "/gpt-4.1-mini":         {Input: 0.40, Output: 1.60},
+	ProviderOpenAI + "/gpt-4.1-nano":         {Input: 0.10, Output: 0.40},
+}
+
+// PriceFor returns the list price of a provider's model and whether it is
+// known.
+func PriceFor(provider, model string) (Price, bool) {
+	key := provider + "/" + model
+	var (
+		best    Price
+		bestLen int
+	)
+	for prefix, p := range prices {
+		if strings.HasPrefix(key, prefix) && len(prefix) > bestLen {
+			best, bestLen = p, len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// EstimateCost returns the estimated cost in USD of a request. Unknown models
+// cost 0.
+func EstimateCost(provider, model string, inputTokens, outputTokens int64) float64 {
+	p, ok := PriceFor(provider, model)
+	if !ok {
+		return 0
+	}
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1e6
+}
+
+// EstimateTokens approximates the token count of text at four characters per
+// token, for clients that do not report usage.
+func EstimateTokens(text string) int64 {
+	return int64((len(text) + 3) / 4)
+}
+
+// ModelDescriber is implemented by LLM clients that can name the provider
+// and model they call, so their usage can be priced.
+type ModelDescriber interface {
+	LLMModel() (provider, model string)
+}
+
+// DescribeModel returns client's provider and model, or empty strings when
+// it does not implement ModelDescriber.
+func DescribeModel(client any) (provider, model string) {
+	if d, ok := client.(ModelDescriber); ok {
+		return d.LLMModel()
+	}
+	return "", ""
+}
+
+// Scope attributes LLM usage to the tenant, project and session that caused
+// it.
+type Scope struct {
+	TenantID  string
+	ProjectID string
+	SessionID string
+}
+
+type scopeKey struct{}
+
+// ContextWithScope returns ctx carrying scope. RecordUsage fills empty
+// attribution fields from it.
+func ContextWithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFromContext returns the scope carried by ctx, if any.
+func ScopeFromContext(ctx context.Context) (Scope, bool) {
+	if ctx == nil {
+		return Scope{}, false
+	}
+	scope, ok := ctx.Value(scopeKey{}).(Scope)
+	return scope, ok
+}
+
+// UsageStats is the rollup of LLM requests sharing a day, feature, provider,
+// model, tenant, project and session. In query results, dimensions that were
+// not grouped on are empty.
+type UsageStats struct {
+	Day          string  `json:"day,omitempty"`
+	Feature      string  `json:"feature,omitempty"`
+	Provider     string  `json:"provider,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	TenantID     string  `json:"tenant_id,omitempty"`
+	ProjectID    string  `json:"project_id,omitempty"`
+	SessionID    string  `json:"session_id,omitempty"`
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+func (s *UsageStats) merge(o UsageStats) {
+	s.Requests += o.Requests
+	s.Errors += o.Errors
+	s.InputTokens += o.InputTokens
+	s.OutputTokens += o.OutputTokens
+	s.CostUSD += o.CostUSD
+}
+
+// UsageQuery selects and groups LLM usage rollups.
+type UsageQuery struct {
+	// From and To bound the days included, inclusive. Zero To means today;
+	// zero From means To.
+	From time.Time
+	To   time.Time
+
+	// Feature, TenantID, ProjectID and SessionID filter rows when set.
+	Feature   string
+	TenantID  string
+	ProjectID string
+	SessionID string
+
+	// GroupBy lists the dimensions rows are grouped on: feature, provider,
+	// model, tenant, project, session and day. Default: feature.
+	GroupBy []string
+}
+
+// RecordUsage counts one LLM request, prices it and fills attribution missing
+// from u from the Scope in ctx. It never blocks on disk.
+func (t *Tracker) RecordUsage(ctx context.Context, u LLMUsage) {
+	if t == nil {
+		return
+	}
+	if u.Time.IsZero() {
+		u.Time = t.now()
+	}
+	if scope, ok := ScopeFromContext(ctx); ok {
+		if u.TenantID == "" {
+			u.TenantID = scope.TenantID
+		}
+		if u.ProjectID == "" {
+			u.ProjectID = scope.ProjectID
+		}
+		if u.SessionID == "" {
+			u.SessionID = scope.SessionID
+		}
+	}
+
+	row := UsageStats{
+		Day:       u.Time.UTC().Format(dayLayout),
+		Feature:   u.Feature,
+		Provider:  u.Provider,
+		Model:     u.Model,
+		TenantID:  u.TenantID,
+		ProjectID: u.ProjectID,
+		SessionID: u.SessionID,
+	}
+	cost := EstimateCost(u.Provider, u.Model, u.InputTokens, u.OutputTokens)
+	delta := UsageStats{
+		Requests:     1,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		CostUSD:      cost,
+	}
+	if u.Failed {
+		delta.Errors = 1
+	}
+
+	key := usageKey(row)
+	t.mu.Lock()
+	s, ok := t.pendingUsage[key]
+	if !ok {
+		s = &row
+		t.pendingUsage[key] = s
+	}
+	s.merge(delta)
+	t.mu.Unlock()
+
+	t.metrics.record(ctx, u, cost)
+}
+
+// QueryUsage returns LLM usage rollups matching q, grouped and sorted by cost
+// then tokens. Usage not yet flushed is included.
+func (t *Tracker) QueryUsage(q UsageQuery) ([]UsageStats, error) {
+	groupBy := q.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{DimensionFeature}
+	}
+	for _, d := range groupBy {
+		switch d {
+		case DimensionFeature, DimensionProvider, DimensionModel,
+			DimensionTenant, DimensionProject, DimensionSession, DimensionDay:
+		default:
+			return nil, fmt.Errorf("%w: unknown group_by dimension %q", ErrInvalidQuery, d)
+		}
+	}
+
+	to := q.To
+	if to.IsZero() {
+		to = t.now()
+	}
+	from := q.From
+	if from.IsZero() {
+		from = to
+	}
+	fromDay, toDay := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+	if fromDay > toDay {
+		return nil, fmt.Errorf("%w: from %s is after to %s", ErrInvalidQuery, fromDay, toDay)
+	}
+
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	var rows []UsageStats
+	days, err := t.days(usagePrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		if day < fromDay || day > toDay {
+			continue
+		}
+		dayRows, err := t.readUsageDay(day)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, dayRows...)
+	}
+
+	t.mu.Lock()
+	for _, s := range t.pendingUsage {
+		if s.Day >= fromDay && s.Day <= toDay {
+			rows = append(rows, *s)
+		}
+	}
+	t.mu.Unlock()
+
+	grouped := make(map[string]*UsageStats)
+	for _, row := range rows {
+		if (q.Feature != "" && row.Feature != q.Feature) ||
+			(q.TenantID != "" && row.TenantID != q.TenantID) ||
+			(q.ProjectID != "" && row.ProjectID != q.ProjectID) ||
+			(q.SessionID != "" && row.SessionID != q.SessionID) {
+			continue
+		}
+
+		g := groupUsageRow(row, groupBy)
+		key := usageKey(g)
+		if s, ok := grouped[key]; ok {
+			s.merge(row)
+		} else {
+			g.merge(row)
+			grouped[key] = &g
+		}
+	}
+
+	result := make([]UsageStats, 0, len(grouped))
+	for _, s := range grouped {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CostUSD != result[j].CostUSD {
+			return result[i].CostUSD > result[j].CostUSD
+		}
+		ti := result[i].InputTokens + result[i].OutputTokens
+		tj := result[j].InputTokens + result[j].OutputTokens
+		if ti != tj {
+			return ti > tj
+		}
+		return usageKey(result[i]) < usageKey(result[j])
+	})
+	return result, nil
+}
+
+// groupUsageRow keeps only the grouped dimensions of row, with zero counters.
+func groupUsageRow(row UsageStats, groupBy []string) UsageStats {
+	var g UsageStats
+	for _, d := range groupBy {
+		switch d {
+		case DimensionFeature:
+			g.Feature = row.Feature
+		case DimensionProvider:
+			g.Provider = row.Provider
+		case DimensionModel:
+			g.Provider, g.Model = row.Provider, row.Model
+		case DimensionTenant:
+			g.TenantID = row.TenantID
+		case DimensionProject:
+			g.ProjectID = row.ProjectID
+		case DimensionSession:
+			g.SessionID = row.SessionID
+		case DimensionDay:
+			g.Day = row.Day
+		}
+	}
+	return g
+}
+
+func usageKey(s UsageStats) string {
+	return strings.Join([]string{s.Day, s.Feature, s.Provider, s.Model, s.TenantID, s.ProjectID, s.SessionID}, "\x00")
+}
+
+// usageDayFile is the on-disk form of one day's LLM usage rollup.
+type usageDayFile struct {
+	Day  string       `json:"day"`
+	Rows []UsageStats `json:"rows"`
+}
+
+// flushUsage merges pending LLM usage into the usage rollup files. The
+// caller holds flushMu.
+func (t *Tracker) flushUsage() error {
+	t.mu.Lock()
+	pending := t.pendingUsage
+	t.pendingUsage = make(map[string]*UsageStats)
+	t.mu.Unlock()
+
+	byDay := make(map[string][]UsageStats)
+	for _, s := range pending {
+		byDay[s.Day] = append(byDay[s.Day], *s)
+	}
+
+	var errs []error
+	for day, rows := range byDay {
+		if err := t.mergeUsageDay(day, rows); err != nil {
+			errs = append(errs, err)
+			// Put the counters back so the next flush retries them
+			t.mu.Lock()
+			for _, row := range rows {
+				key := usageKey(row)
+				if s, ok := t.pendingUsage[key]; ok {
+					s.merge(row)
+				} else {
+					r := row
+					t.pendingUsage[key] = &r
+				}
+			}
+			t.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Tracker) readUsageDay(day string) ([]UsageStats, error) {
+	data, err := os.ReadFile(t.rollupPath(usagePrefix, day))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading usage rollup for %s: %w", day, err)
+	}
+
+	var f usageDayFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decoding usage rollup for %s: %w", day, err)
+	}
+	for i := range f.Rows {
+		f.Rows[i].Day = day
+	}
+	return f.Rows, nil
+}
+
+// mergeUsageDay adds rows to the day's usage rollup file.
+func (t *Tracker) mergeUsageDay(day string, rows []UsageStats) error {
+	existing, err := t.readUsageDay(day)
+	if err != nil {
+		return err
+	}
+
+	merged := make(map[string]*UsageStats, len(existing)+len(rows))
+	for _, row := range append(existing, rows...) {
+		key := usageKey(row)
+		if s, ok := merged[key]; ok {
+			s.merge(row)
+		} else {
+			r := row
+			merged[key] = &r
+		}
+	}
+
+	f := usageDayFile{Day: day, Rows: make([]UsageStats, 0, len(merged))}
+	for _, s := range merged {
+		f.Rows = append(f.Rows, *s)
+	}
+	sort.Slice(f.Rows, func(i, j int) bool { return usageKey(f.Rows[i]) < usageKey(f.Rows[j]) })
+
+	return t.writeRollup(t.rollupPath(usagePrefix, day), day, f)
+}
+
+// usageMetrics exports LLM usage as OTEL counters. Tenant, project and session
+// are left to the rollups to keep metric cardinality bounded.
+type usageMetrics struct {
+	requests metric.Int64Counter
+	tokens   metric.Int64Counter
+	cost     metric.Float64Counter
+}
+
+func newUsageMetrics(logger *zap.Logger) *usageMetrics {
+	meter := otel.Meter(usageInstrumentationName)
+	m := &usageMetrics{}
+	var err error
+
+	m.requests, err = meter.Int64Counter(
+		"contextd.llm.requests_total",
+		metric.WithDescription("LLM requests labeled by feature, provider, model and status (ok, error)."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create LLM requests counter", zap.Error(err))
+	}
+
+	m.tokens, err = meter.Int64Counter(
+		"contextd.llm.tokens_total",
+		metric.WithDescription("LLM tokens labeled by feature, provider, model and direction (input, output)."),
+		metric.WithUnit("{token}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create LLM tokens counter", zap.Error(err))
+	}
+
+	m.cost, err = meter.Float64Counter(
+		"contextd.llm.cost_usd_total",
+		metric.WithDescription("Estimated LLM spend in USD at list prices, labeled by feature, provider and model."),
+		metric.WithUnit("USD"),
+	)
+	if err != nil {
+		logger.Warn("failed to create LLM cost counter", zap.Error(err))
+	}
+	return m
+}
+
+func (m *usageMetrics) record(ctx context.Context, u LLMUsage, cost float64) {
+	if m == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("feature", u.Feature),
+		attribute.String("provider", u.Provider),
+		attribute.String("model", u.Model),
+	}
+
+	status := "ok"
+	if u.Failed {
+		status = "error"
+	}
+	if m.requests != nil {
+		m.requests.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("status", status))...))
+	}
+	if m.tokens != nil {
+		m.tokens.Add(ctx, u.InputTokens, metric.WithAttributes(append(attrs, attribute.String("direction", "input"))...))
+		m.tokens.Add(ctx, u.OutputTokens, metric.WithAttributes(append(attrs, attribute.String("direction", "output"))...))
+	}
+	if m.cost != nil && cost > 0 {
+		m.cost.Add(ctx, cost, metric.WithAttributes(attrs...))
+	}
+}
@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_RecordUsageAndQuery(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	ctx := ContextWithScope(context.Background(), Scope{TenantID: "acme", ProjectID: "api", SessionID: "s1"})
+	tracker.RecordUsage(ctx, LLMUsage{Feature: FeatureCompression, Provider: ProviderAnthropic, Model: "claude-3-haiku-20240307", InputTokens: 1_000_000, OutputTokens: 200_000})
+	tracker.RecordUsage(ctx, LLMUsage{Feature: FeatureCompression, Provider: ProviderAnthropic, Model: "claude-3-haiku-20240307", InputTokens: 10, Failed: true})
+	tracker.RecordUsage(context.Background(), LLMUsage{Feature: FeatureExtraction, Provider: ProviderOpenAI, Model: "gpt-4o-mini", TenantID: "acme", ProjectID: "web", InputTokens: 2_000_000, OutputTokens: 1_000_000})
+	require.NoError(t, tracker.Flush())
+	tracker.RecordUsage(ctx, LLMUsage{Feature: FeatureDistiller, Provider: "local", Model: "llama3", InputTokens: 500, OutputTokens: 50})
+
+	rows, err := tracker.QueryUsage(UsageQuery{})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	extraction := rows[0]
+	assert.Equal(t, FeatureExtraction, extraction.Feature)
+	assert.InDelta(t, 0.30+0.60, extraction.CostUSD, 1e-9)
+
+	compression := rows[1]
+	assert.Equal(t, FeatureCompression, compression.Feature)
+	assert.Empty(t, compression.TenantID, "tenant is not a grouped dimension")
+	assert.Equal(t, int64(2), compression.Requests)
+	assert.Equal(t, int64(1), compression.Errors)
+	assert.Equal(t, int64(1_000_010), compression.InputTokens)
+	assert.InDelta(t, 0.5000025, compression.CostUSD, 1e-9)
+
+	distiller := rows[2]
+	assert.Equal(t, FeatureDistiller, distiller.Feature)
+	assert.Zero(t, distiller.CostUSD, "unknown models are not priced")
+	assert.Equal(t, int64(550), distiller.InputTokens+distiller.OutputTokens)
+
+	rows, err = tracker.QueryUsage(UsageQuery{SessionID: "s1", GroupBy: []string{DimensionSession, DimensionModel}})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		assert.Equal(t, "s1", row.SessionID)
+		assert.NotEmpty(t, row.Provider, "model grouping keeps the provider")
+	}
+
+	rows, err = tracker.QueryUsage(UsageQuery{GroupBy: []string{DimensionProject}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"api", "web"}, []string{rows[0].ProjectID, rows[1].ProjectID})
+
+	_, err = tracker.QueryUsage(UsageQuery{GroupBy: []string{"tool"}})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestTracker_UsageRollupsArePruned(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, now)
+
+	tracker.RecordUsage(context.Background(), LLMUsage{Feature: FeatureCompression, Time: now.AddDate(0, 0, -31)})
+	tracker.RecordUsage(context.Background(), LLMUsage{Feature: FeatureCompression, Time: now})
+	require.NoError(t, tracker.Flush())
+
+	days, err := tracker.days(usagePrefix)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-10-15"}, days)
+
+	days, err = tracker.days(toolsPrefix)
+	require.NoError(t, err)
+	assert.Empty(t, days, "usage rollups are not tool rollups")
+}
+
+func TestTracker_RecordUsageNil(t *testing.T) {
+	var tracker *Tracker
+	assert.NotPanics(t, func() {
+		tracker.RecordUsage(context.Background(), LLMUsage{Feature: FeatureCompression})
+	})
+}
+
+func TestPriceFor(t *testing.T) {
+	p, ok := PriceFor(ProviderOpenAI, "gpt-4o-mini-2024-07-18")
+	require.True(t, ok)
+	assert.Equal(t, 0.15, p.Input, "longest prefix wins over gpt-4o")
+
+	_, ok = PriceFor(ProviderAnthropic, "gpt-4o")
+	assert.False(t, ok, "prices are per provider")
+
+	assert.InDelta(t, 18.0, EstimateCost(ProviderAnthropic, "claude-sonnet-4-20250514", 1_000_000, 1_000_000), 1e-9)
+	assert.Equal(t, int64(3), EstimateTokens("0123456789"))
+}
@@ -0,0 +1,398 @@
+// Package bootstrap composes the context an agent needs at session start
+// into a single bundle: relevant memories, the latest checkpoint, fixes for
+// recent errors, and reflection insights.
+//
+// It backs the session_bootstrap MCP tool and the HTTP endpoint
+// POST /api/v1/session/bootstrap, replacing four separate calls with one.
+// Sections are gathered concurrently; a failing or unavailable service
+// leaves its section empty and adds a warning rather than failing the call.
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/fanout"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/reflection"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// DefaultMemoryLimit is the number of memories returned by default.
+	DefaultMemoryLimit = 5
+
+	// DefaultRemediationLimit is the number of remediations returned by default.
+	DefaultRemediationLimit = 3
+
+	// DefaultInsightLimit is the number of insights returned by default.
+	DefaultInsightLimit = 3
+
+	// recentErrorWindow is how far back failure memories count as recent errors.
+	recentErrorWindow = 14 * 24 * time.Hour
+
+	// maxRecentErrors caps the failure memories used as remediation queries.
+	maxRecentErrors = 3
+)
+
+// ErrMissingFields is returned when the request lacks required fields.
+var ErrMissingFields = ctxerrors.New(ctxerrors.CodeInvalidInput, "project_id and task are required")
+
+// Request describes the session being started.
+type Request struct {
+	// ProjectID scopes memories and insights.
+	ProjectID string
+
+	// TenantID and ProjectPath scope checkpoints and remediations.
+	TenantID    string
+	TeamID      string
+	ProjectPath string
+
+	// Task describes what the session is about to do. It is the query for
+	// memories and remediations.
+	Task string
+
+	MemoryLimit      int
+	RemediationLimit int
+	InsightLimit     int
+}
+
+// Bundle is the composed session-start context.
+type Bundle struct {
+	ProjectID    string               `json:"project_id"`
+	Task         string               `json:"task"`
+	Memories     []Memory             `json:"memories"`
+	Checkpoint   *Checkpoint          `json:"checkpoint,omitempty"`
+	Remediations []Remediation        `json:"remediations"`
+	Insights     []reflection.Insight `json:"insights"`
+	Warnings     []string             `json:"warnings,omitempty"`
+	GeneratedAt  time.Time            `json:"generated_at"`
+}
+
+// Memory is a memory relevant to the task.
+type Memory struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Outcome    string   `json:"outcome"`
+	Confidence float64  `json:"confidence"`
+	Relevance  float64  `json:"relevance"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// Checkpoint summarizes the project's most recent checkpoint.
+type Checkpoint struct {
+	ID         string    `json:"id"`
+	SessionID  string    `json:"session_id"`
+	Name       string    `json:"name"`
+	Summary    string    `json:"summary"`
+	TokenCount int32     `json:"token_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Remediation is a fix for an error category seen recently.
+type Remediation struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Problem    string  `json:"problem"`
+	Solution   string  `json:"solution"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Score      float64 `json:"score"`
+}
+
+// Builder composes bundles from the contextd services. Any service may be
+// nil, in which case its section is skipped with a warning.
+type Builder struct {
+	memory       *reasoningbank.Service
+	checkpoints  checkpoint.Service
+	remediations remediation.Service
+	reporter     *reflection.DefaultReporter
+	scrubber     secrets.Scrubber
+	logger       *zap.Logger
+}
+
+// NewBuilder creates a Builder.
+func NewBuilder(memory *reasoningbank.Service, checkpoints checkpoint.Service, remediations remediation.Service, scrubber secrets.Scrubber, logger *zap.Logger) *Builder {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	b := &Builder{
+		memory:       memory,
+		checkpoints:  checkpoints,
+		remediations: remediations,
+		scrubber:     scrubber,
+		logger:       logger,
+	}
+	if memory != nil {
+		b.reporter = reflection.NewReporter(memory)
+	}
+	return b
+}
+
+// Build gathers every section of the bundle concurrently.
+func (b *Builder) Build(ctx context.Context, req Request) (*Bundle, error) {
+	if req.ProjectID == "" || req.Task == "" {
+		return nil, ErrMissingFields
+	}
+	if err := validation.Default.Query("task", req.Task); err != nil {
+		return nil, err
+	}
+	if req.MemoryLimit <= 0 {
+		req.MemoryLimit = DefaultMemoryLimit
+	}
+	if req.RemediationLimit <= 0 {
+		req.RemediationLimit = DefaultRemediationLimit
+	}
+	if req.InsightLimit <= 0 {
+		req.InsightLimit = DefaultInsightLimit
+	}
+
+	bundle := &Bundle{
+		ProjectID:    req.ProjectID,
+		Task:         req.Task,
+		Memories:     []Memory{},
+		Remediations: []Remediation{},
+		Insights:     []reflection.Insight{},
+		GeneratedAt:  time.Now(),
+	}
+
+	// Memory tools use the project ID as both tenant and project scope
+	memCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.ProjectID,
+		ProjectID: req.ProjectID,
+	})
+	pathCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.TenantID,
+		TeamID:    req.TeamID,
+		ProjectID: req.ProjectID,
+	})
+
+	sections := fanout.New(b.logger, "session bootstrap section failed", "section",
+		zap.String("project_id", req.ProjectID))
+	sections.Go("memories", func() error {
+		memories, err := b.relevantMemories(memCtx, req)
+		bundle.Memories = memories
+		return err
+	})
+	sections.Go("checkpoint", func() error {
+		cp, err := b.latestCheckpoint(pathCtx, req)
+		bundle.Checkpoint = cp
+		return err
+	})
+	sections.Go("remediations", func() error {
+		remediations, err := b.recentRemediations(memCtx, pathCtx, req)
+		bundle.Remediations = remediations
+		return err
+	})
+	sections.Go("insights", func() error {
+		insights, err := b.activeInsights(memCtx, req)
+		bundle.Insights = insights
+		return err
+	})
+	bundle.Warnings = sections.Wait()
+	return bundle, nil
+}
+
+func (b *Builder) relevantMemories(ctx context.Context, req Request) ([]Memory, error) {
+	if b.memory == nil {
+		return []Memory{}, errors.New("memory service unavailable")
+	}
+	scored, err := b.memory.SearchWithScores(ctx, req.ProjectID, req.Task, req.MemoryLimit)
+	if err != nil {
+		return []Memory{}, err
+	}
+	memories := make([]Memory, 0, len(scored))
+	for _, sm := range scored {
+		memories = append(memories, Memory{
+			ID:         sm.Memory.ID,
+			Title:      sm.Memory.Title,
+			Content:    b.scrub(sm.Memory.Content),
+			Outcome:    string(sm.Memory.Outcome),
+			Confidence: sm.Memory.Confidence,
+			Relevance:  sm.Relevance,
+			Tags:       sm.Memory.Tags,
+		})
+	}
+	return memories, nil
+}
+
+func (b *Builder) latestCheckpoint(ctx context.Context, req Request) (*Checkpoint, error) {
+	if b.checkpoints == nil {
+		return nil, errors.New("checkpoint service unavailable")
+	}
+	if req.TenantID == "" {
+		return nil, errors.New("tenant_id or project_path required")
+	}
+
+	var latest *checkpoint.Checkpoint
+	err := b.checkpoints.Stream(ctx, &checkpoint.ListRequest{
+		TenantID:    req.TenantID,
+		TeamID:      req.TeamID,
+		ProjectID:   req.ProjectID,
+		ProjectPath: req.ProjectPath,
+	}, func(cp *checkpoint.Checkpoint) error {
+		if latest == nil || cp.CreatedAt.After(latest.CreatedAt) {
+			latest = cp
+		}
+		return nil
+	})
+	if err != nil || latest == nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		ID:         latest.ID,
+		SessionID:  latest.SessionID,
+		Name:       latest.Name,
+		Summary:    b.scrub(latest.Summary),
+		TokenCount: latest.TokenCount,
+		CreatedAt:  latest.CreatedAt,
+	}, nil
+}
+
+// recentRemediations searches remediations for the task and for the
+// project's recent failure memories, then picks the best fix per error
+// category before filling the remaining slots by score.
+func (b *Builder) recentRemediations(memCtx, pathCtx context.Context, req Request) ([]Remediation, error) {
+	if b.remediations == nil {
+		return []Remediation{}, errors.New("remediation service unavailable")
+	}
+	if req.TenantID == "" {
+		return []Remediation{}, errors.New("tenant_id or project_path required")
+	}
+
+	queries := []string{req.Task}
+	if b.memory != nil {
+		failures, err := b.recentFailures(memCtx, req.ProjectID)
+		if err != nil {
+			b.logger.Debug("failed to load recent failures", zap.Error(err))
+		}
+		queries = append(queries, failures...)
+	}
+
+	best := make(map[string]*remediation.ScoredRemediation)
+	for _, q := range queries {
+		results, err := b.remediations.Search(pathCtx, &remediation.SearchRequest{
+			Query:       q,
+			TenantID:    req.TenantID,
+			TeamID:      req.TeamID,
+			ProjectPath: req.ProjectPath,
+			Limit:       req.RemediationLimit,
+		})
+		if err != nil {
+			return []Remediation{}, err
+		}
+		for _, r := range results {
+			if prev, ok := best[r.Remediation.ID]; !ok || r.Score > prev.Score {
+				best[r.Remediation.ID] = r
+			}
+		}
+	}
+
+	ranked := make([]*remediation.ScoredRemediation, 0, len(best))
+	for _, r := range best {
+		ranked = append(ranked, r)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Remediation.ID < ranked[j].Remediation.ID
+	})
+
+	// Top remediation per category first, then the rest by score
+	picked := make([]*remediation.ScoredRemediation, 0, req.RemediationLimit)
+	seenCategory := make(map[remediation.ErrorCategory]bool)
+	var rest []*remediation.ScoredRemediation
+	for _, r := range ranked {
+		if !seenCategory[r.Remediation.Category] && len(picked) < req.RemediationLimit {
+			seenCategory[r.Remediation.Category] = true
+			picked = append(picked, r)
+			continue
+		}
+		rest = append(rest, r)
+	}
+	for _, r := range rest {
+		if len(picked) >= req.RemediationLimit {
+			break
+		}
+		picked = append(picked, r)
+	}
+
+	remediations := make([]Remediation, 0, len(picked))
+	for _, r := range picked {
+		remediations = append(remediations, Remediation{
+			ID:         r.Remediation.ID,
+			Title:      r.Remediation.Title,
+			Problem:    b.scrub(r.Remediation.Problem),
+			Solution:   b.scrub(r.Remediation.Solution),
+			Category:   string(r.Remediation.Category),
+			Confidence: r.Remediation.Confidence,
+			Score:      float64(r.Score),
+		})
+	}
+	return remediations, nil
+}
+
+// recentFailures returns titles of the project's most recent failure
+// memories, newest first.
+func (b *Builder) recentFailures(ctx context.Context, projectID string) ([]string, error) {
+	cutoff := time.Now().Add(-recentErrorWindow)
+	var failures []reasoningbank.Memory
+	err := b.memory.StreamMemories(ctx, projectID, 0, func(m reasoningbank.Memory) error {
+		if m.Outcome == reasoningbank.OutcomeFailure && m.State == reasoningbank.MemoryStateActive && m.CreatedAt.After(cutoff) {
+			failures = append(failures, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].CreatedAt.After(failures[j].CreatedAt)
+	})
+	if len(failures) > maxRecentErrors {
+		failures = failures[:maxRecentErrors]
+	}
+	titles := make([]string, len(failures))
+	for i, m := range failures {
+		titles[i] = m.Title
+	}
+	return titles, nil
+}
+
+func (b *Builder) activeInsights(ctx context.Context, req Request) ([]reflection.Insight, error) {
+	if b.reporter == nil {
+		return []reflection.Insight{}, errors.New("memory service unavailable")
+	}
+	report, err := b.reporter.Generate(ctx, reflection.ReportOptions{
+		ProjectID:       req.ProjectID,
+		IncludePatterns: true,
+		IncludeInsights: true,
+		MaxInsights:     req.InsightLimit,
+	})
+	if err != nil {
+		return []reflection.Insight{}, err
+	}
+	if report.Insights == nil {
+		return []reflection.Insight{}, nil
+	}
+	return report.Insights, nil
+}
+
+func (b *Builder) scrub(content string) string {
+	if b.scrubber == nil {
+		return content
+	}
+	return b.scrubber.Scrub(content).Scrubbed
+}
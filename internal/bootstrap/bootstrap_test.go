@@ -0,0 +1,143 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// constantEmbedder returns the same vector for every text so every memory
+// matches every query.
+type constantEmbedder struct{ dim int }
+
+func (e *constantEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i], _ = e.EmbedQuery(ctx, texts[i])
+	}
+	return out, nil
+}
+
+func (e *constantEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	v := make([]float32, e.dim)
+	v[0] = 1
+	return v, nil
+}
+
+// fakeCheckpoints implements only Stream.
+type fakeCheckpoints struct {
+	checkpoint.Service
+	checkpoints []*checkpoint.Checkpoint
+}
+
+func (f *fakeCheckpoints) Stream(ctx context.Context, req *checkpoint.ListRequest, fn func(*checkpoint.Checkpoint) error) error {
+	for _, cp := range f.checkpoints {
+		if err := fn(cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeRemediations implements only Search, returning canned results per query.
+type fakeRemediations struct {
+	remediation.Service
+	byQuery map[string][]*remediation.ScoredRemediation
+	queries []string
+}
+
+func (f *fakeRemediations) Search(ctx context.Context, req *remediation.SearchRequest) ([]*remediation.ScoredRemediation, error) {
+	f.queries = append(f.queries, req.Query)
+	return f.byQuery[req.Query], nil
+}
+
+func newMemoryService(t *testing.T) *reasoningbank.Service {
+	t.Helper()
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	svc, err := reasoningbank.NewService(store, zap.NewNop(), reasoningbank.WithDefaultTenant("proj"))
+	require.NoError(t, err)
+	return svc
+}
+
+func scored(id string, category remediation.ErrorCategory, score float64) *remediation.ScoredRemediation {
+	return &remediation.ScoredRemediation{
+		Remediation: remediation.Remediation{ID: id, Title: id, Category: category},
+		Score:       score,
+	}
+}
+
+func TestBuilder_Build(t *testing.T) {
+	ctx := context.Background()
+	memorySvc := newMemoryService(t)
+
+	memCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{TenantID: "proj", ProjectID: "proj"})
+	success, _ := reasoningbank.NewMemory("proj", "Use table tests", "content", reasoningbank.OutcomeSuccess, []string{"go"})
+	require.NoError(t, memorySvc.Record(memCtx, success))
+	failure, _ := reasoningbank.NewMemory("proj", "Nil map panic", "content", reasoningbank.OutcomeFailure, []string{"go"})
+	require.NoError(t, memorySvc.Record(memCtx, failure))
+
+	now := time.Now()
+	checkpoints := &fakeCheckpoints{checkpoints: []*checkpoint.Checkpoint{
+		{ID: "old", Summary: "older", CreatedAt: now.Add(-time.Hour)},
+		{ID: "new", Summary: "newest", CreatedAt: now},
+	}}
+	remediations := &fakeRemediations{byQuery: map[string][]*remediation.ScoredRemediation{
+		"add caching":   {scored("r1", remediation.ErrorRuntime, 0.9), scored("r2", remediation.ErrorRuntime, 0.8)},
+		"Nil map panic": {scored("r3", remediation.ErrorTest, 0.5), scored("r1", remediation.ErrorRuntime, 0.95)},
+	}}
+
+	builder := NewBuilder(memorySvc, checkpoints, remediations, nil, zap.NewNop())
+	bundle, err := builder.Build(ctx, Request{
+		ProjectID:        "proj",
+		TenantID:         "tenant",
+		Task:             "add caching",
+		RemediationLimit: 2,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, bundle.Warnings)
+
+	assert.Len(t, bundle.Memories, 2)
+
+	require.NotNil(t, bundle.Checkpoint)
+	assert.Equal(t, "new", bundle.Checkpoint.ID)
+
+	// Recent failure titles are used as extra remediation queries
+	assert.ElementsMatch(t, []string{"add caching", "Nil map panic"}, remediations.queries)
+
+	// One remediation per category before a second runtime fix
+	require.Len(t, bundle.Remediations, 2)
+	assert.Equal(t, "r1", bundle.Remediations[0].ID)
+	assert.Equal(t, 0.95, bundle.Remediations[0].Score, "best score across queries wins")
+	assert.Equal(t, "r3", bundle.Remediations[1].ID)
+
+	assert.NotNil(t, bundle.Insights)
+}
+
+func TestBuilder_Build_PartialFailure(t *testing.T) {
+	builder := NewBuilder(nil, nil, nil, nil, zap.NewNop())
+
+	_, err := builder.Build(context.Background(), Request{ProjectID: "proj"})
+	assert.True(t, errors.Is(err, ErrMissingFields))
+
+	bundle, err := builder.Build(context.Background(), Request{ProjectID: "proj", Task: "anything"})
+	require.NoError(t, err)
+	assert.Len(t, bundle.Warnings, 4)
+	assert.Empty(t, bundle.Memories)
+	assert.Nil(t, bundle.Checkpoint)
+}
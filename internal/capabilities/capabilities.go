@@ -0,0 +1,227 @@
+// Package capabilities describes what a running contextd offers, so
+// plugins and clients can adapt to older or newer servers instead of
+// failing on a missing tool or a changed argument.
+//
+// A Capabilities document is served by the contextd_capabilities MCP tool
+// and GET /api/v1/capabilities. It carries the server version, the protocol
+// version range the server speaks, the optional features it was started
+// with, the schema version of each MCP tool and notices for deprecated
+// tools, endpoints and settings.
+//
+// Clients negotiate by sending the protocol version they were built
+// against: the document then reports whether the two are compatible. A
+// client older than MinProtocolVersion must be upgraded; a client newer
+// than ProtocolVersion should fall back to the features listed.
+package capabilities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const (
+	// ProtocolVersion is the revision of contextd's MCP and HTTP contracts.
+	// It is raised when clients must change to keep working, such as a
+	// removed tool or a renamed argument.
+	ProtocolVersion = 1
+
+	// MinProtocolVersion is the oldest client protocol still served.
+	MinProtocolVersion = 1
+
+	// ServerName identifies contextd in the document.
+	ServerName = "contextd"
+)
+
+// AuthMode says how the HTTP API authenticates callers.
+type AuthMode string
+
+const (
+	// AuthLocalhost serves TCP; operator endpoints only answer loopback.
+	AuthLocalhost AuthMode = "localhost"
+
+	// AuthUnixSocket serves a Unix socket; file permissions decide who
+	// connects and every caller counts as local.
+	AuthUnixSocket AuthMode = "unix_socket"
+)
+
+// Features lists the optional parts of contextd and whether they are on.
+type Features struct {
+	VectorStore  string   `json:"vector_store"`
+	Embeddings   string   `json:"embeddings"`
+	LLMProviders []string `json:"llm_providers"`
+	AuthMode     AuthMode `json:"auth_mode,omitempty"` // empty when HTTP is off
+
+	// AdminToken is set when the admin bearer token is configured, which
+	// serves the /debug/pprof endpoints.
+	AdminToken bool `json:"admin_token"`
+
+	ReadOnly      bool `json:"read_only"`
+	Analytics     bool `json:"analytics"`
+	Conversations bool `json:"conversations"`
+	Folding       bool `json:"folding"`
+	Consolidation bool `json:"consolidation"`
+	Workflows     bool `json:"workflows"`
+}
+
+// Tool is the contract of one MCP tool.
+type Tool struct {
+	Name string `json:"name"`
+
+	// SchemaVersion is raised when the tool's arguments or result change
+	// incompatibly.
+	SchemaVersion int `json:"schema_version"`
+
+	// SchemaHash fingerprints the input schema, so clients that cache
+	// schemas can tell when any argument changed.
+	SchemaHash string `json:"schema_hash"`
+
+	// Deprecated is set when the tool has a deprecation notice.
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// DeprecationKind is what a deprecation notice applies to.
+type DeprecationKind string
+
+const (
+	DeprecatedTool     DeprecationKind = "tool"
+	DeprecatedEndpoint DeprecationKind = "endpoint"
+	DeprecatedSetting  DeprecationKind = "setting"
+)
+
+// Deprecation announces that a tool, endpoint or setting will be removed.
+type Deprecation struct {
+	Kind        DeprecationKind `json:"kind"`
+	Name        string          `json:"name"`
+	Since       string          `json:"since"`
+	RemovedIn   string          `json:"removed_in,omitempty"`
+	Replacement string          `json:"replacement,omitempty"`
+	Notice      string          `json:"notice"`
+}
+
+// Compatibility is the outcome of negotiating with a client's protocol
+// version.
+type Compatibility struct {
+	ClientProtocol int    `json:"client_protocol"`
+	Compatible     bool   `json:"compatible"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// Capabilities is the document served to clients.
+type Capabilities struct {
+	Server             string         `json:"server"`
+	Version            string         `json:"version"`
+	ProtocolVersion    int            `json:"protocol_version"`
+	MinProtocolVersion int            `json:"min_protocol_version"`
+	Features           Features       `json:"features"`
+	Tools              []Tool         `json:"tools,omitempty"`
+	Deprecations       []Deprecation  `json:"deprecations"`
+	Compatibility      *Compatibility `json:"compatibility,omitempty"`
+}
+
+// New returns the document for a server of the given version with the
+// given features and tools. Notices adds deprecations only the server
+// knows, such as its accepted tool aliases, to the built-in ones. Tools are
+// sorted by name and marked deprecated from the deprecation notices.
+func New(version string, features Features, tools []Tool, notices ...Deprecation) *Capabilities {
+	deprecated := append(Deprecations(), notices...)
+	sorted := make([]Tool, len(tools))
+	copy(sorted, tools)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for i := range sorted {
+		_, sorted[i].Deprecated = find(deprecated, DeprecatedTool, sorted[i].Name)
+	}
+	if features.LLMProviders == nil {
+		features.LLMProviders = []string{}
+	}
+	return &Capabilities{
+		Server:             ServerName,
+		Version:            version,
+		ProtocolVersion:    ProtocolVersion,
+		MinProtocolVersion: MinProtocolVersion,
+		Features:           features,
+		Tools:              sorted,
+		Deprecations:       deprecated,
+	}
+}
+
+// Negotiate records whether a client speaking clientProtocol can use this
+// server. Zero means the client did not say and leaves the document as is.
+func (c *Capabilities) Negotiate(clientProtocol int) {
+	if clientProtocol == 0 {
+		return
+	}
+	result := &Compatibility{ClientProtocol: clientProtocol, Compatible: true}
+	switch {
+	case clientProtocol < c.MinProtocolVersion:
+		result.Compatible = false
+		result.Reason = fmt.Sprintf("client protocol %d is older than the oldest supported, %d; upgrade the client", clientProtocol, c.MinProtocolVersion)
+	case clientProtocol > c.ProtocolVersion:
+		result.Reason = fmt.Sprintf("server speaks protocol %d; use only the tools and features listed", c.ProtocolVersion)
+	}
+	c.Compatibility = result
+}
+
+// ToolContract returns the contract of the named tool with the given input
+// schema.
+func ToolContract(name string, inputSchema any) Tool {
+	return Tool{
+		Name:          name,
+		SchemaVersion: SchemaVersion(name),
+		SchemaHash:    schemaHash(inputSchema),
+	}
+}
+
+// schemaVersions holds the schema version of tools past version 1. Raise a
+// tool's version here, and ProtocolVersion if old clients would break, when
+// its arguments or result change incompatibly.
+var schemaVersions = map[string]int{}
+
+// SchemaVersion returns the schema version of the named tool.
+func SchemaVersion(tool string) int {
+	if v, ok := schemaVersions[tool]; ok {
+		return v
+	}
+	return 1
+}
+
+// deprecations lists the current deprecation notices, oldest first.
+var deprecations = []Deprecation{}
+
+// Deprecations returns the current deprecation notices.
+func Deprecations() []Deprecation {
+	out := make([]Deprecation, len(deprecations))
+	copy(out, deprecations)
+	return out
+}
+
+// Deprecated returns the deprecation notice for the named tool, endpoint
+// or setting.
+func Deprecated(kind DeprecationKind, name string) (Deprecation, bool) {
+	return find(deprecations, kind, name)
+}
+
+func find(notices []Deprecation, kind DeprecationKind, name string) (Deprecation, bool) {
+	for _, d := range notices {
+		if d.Kind == kind && d.Name == name {
+			return d, true
+		}
+	}
+	return Deprecation{}, false
+}
+
+// schemaHash returns the first 12 hex digits of the SHA-256 of the schema's
+// JSON. Map keys marshal sorted, so equal schemas hash equally.
+func schemaHash(schema any) string {
+	if schema == nil {
+		return ""
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,82 @@
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	saved := deprecations
+	t.Cleanup(func() { deprecations = saved })
+	deprecations = []Deprecation{{Kind: DeprecatedTool, Name: "old_search", Since: "1.4.0", Replacement: "new_search", Notice: "renamed"}}
+
+	caps := New("1.5.0", Features{VectorStore: "chromem"}, []Tool{
+		ToolContract("new_search", map[string]any{"type": "object"}),
+		ToolContract("old_search", map[string]any{"type": "object"}),
+		ToolContract("checkpoint_save", nil),
+	})
+
+	assert.Equal(t, ServerName, caps.Server)
+	assert.Equal(t, "1.5.0", caps.Version)
+	assert.Equal(t, ProtocolVersion, caps.ProtocolVersion)
+	assert.Equal(t, MinProtocolVersion, caps.MinProtocolVersion)
+	assert.Equal(t, "chromem", caps.Features.VectorStore)
+	require.Len(t, caps.Deprecations, 1)
+
+	require.Len(t, caps.Tools, 3)
+	assert.Equal(t, []string{"checkpoint_save", "new_search", "old_search"},
+		[]string{caps.Tools[0].Name, caps.Tools[1].Name, caps.Tools[2].Name})
+	assert.False(t, caps.Tools[1].Deprecated)
+	assert.True(t, caps.Tools[2].Deprecated)
+	assert.Equal(t, 1, caps.Tools[0].SchemaVersion)
+	assert.Nil(t, caps.Compatibility)
+
+	notice, ok := Deprecated(DeprecatedTool, "old_search")
+	require.True(t, ok)
+	assert.Equal(t, "new_search", notice.Replacement)
+	_, ok = Deprecated(DeprecatedEndpoint, "old_search")
+	assert.False(t, ok)
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		min, max   int
+		client     int
+		compatible bool
+		reason     bool
+	}{
+		{"same protocol", 1, 1, 1, true, false},
+		{"newer client", 1, 1, 2, true, true},
+		{"older than supported", 2, 3, 1, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			caps := New("1.0.0", Features{}, nil)
+			caps.MinProtocolVersion, caps.ProtocolVersion = tc.min, tc.max
+			caps.Negotiate(tc.client)
+			require.NotNil(t, caps.Compatibility)
+			assert.Equal(t, tc.client, caps.Compatibility.ClientProtocol)
+			assert.Equal(t, tc.compatible, caps.Compatibility.Compatible)
+			assert.Equal(t, tc.reason, caps.Compatibility.Reason != "")
+		})
+	}
+
+	t.Run("unknown client protocol", func(t *testing.T) {
+		caps := New("1.0.0", Features{}, nil)
+		caps.Negotiate(0)
+		assert.Nil(t, caps.Compatibility)
+	})
+}
+
+func TestToolContract_SchemaHash(t *testing.T) {
+	a := ToolContract("memory_search", map[string]any{"type": "object", "properties": map[string]any{"query": map[string]any{"type": "string"}}})
+	b := ToolContract("memory_search", map[string]any{"properties": map[string]any{"query": map[string]any{"type": "string"}}, "type": "object"})
+	c := ToolContract("memory_search", map[string]any{"type": "object", "properties": map[string]any{"query": map[string]any{"type": "integer"}}})
+
+	assert.Len(t, a.SchemaHash, 12)
+	assert.Equal(t, a.SchemaHash, b.SchemaHash)
+	assert.NotEqual(t, a.SchemaHash, c.SchemaHash)
+	assert.Empty(t, ToolContract("no_schema", nil).SchemaHash)
+}
@@ -0,0 +1,189 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// Names of the places the full state of checkpoints can be kept.
+const (
+	// StateStoreVectorstore keeps the full state in the checkpoint's
+	// vectorstore payload, next to its summary and context.
+	StateStoreVectorstore = "vectorstore"
+
+	// StateStoreFilesystem keeps each full state in a file.
+	StateStoreFilesystem = "filesystem"
+
+	// StateStoreSQLite keeps the full states in a SQLite database.
+	StateStoreSQLite = "sqlite"
+)
+
+// ErrStateNotFound is returned when the full state of a checkpoint is
+// missing from the blob store.
+var ErrStateNotFound = ctxerrors.NotFound("checkpoint state not found")
+
+// BlobKey identifies the full state of a checkpoint.
+type BlobKey struct {
+	TenantID     string
+	TeamID       string
+	ProjectID    string
+	CheckpointID string
+}
+
+// BlobStore keeps the full state of checkpoints outside the vectorstore.
+// Full states are large and only read when a checkpoint is resumed in
+// full, so storing them as vectorstore payload bloats every list and
+// search without helping either; only the summary and context need to be
+// embedded.
+type BlobStore interface {
+	// Put stores data under key, replacing what was there.
+	Put(ctx context.Context, key BlobKey, data []byte) error
+
+	// Get returns the data stored under key, or ErrStateNotFound.
+	Get(ctx context.Context, key BlobKey) ([]byte, error)
+
+	// Delete removes the data stored under key. Deleting a missing key is
+	// not an error.
+	Delete(ctx context.Context, key BlobKey) error
+
+	// Close releases the store's resources.
+	Close() error
+}
+
+// OpenBlobStore opens the blob store named by backend at path: a directory
+// for StateStoreFilesystem, a database file for StateStoreSQLite. It
+// returns nil for StateStoreVectorstore and the empty backend.
+func OpenBlobStore(backend, path string) (BlobStore, error) {
+	switch backend {
+	case "", StateStoreVectorstore:
+		return nil, nil
+	case StateStoreFilesystem:
+		return NewFileBlobStore(path)
+	case StateStoreSQLite:
+		return NewSQLiteBlobStore(path)
+	default:
+		return nil, fmt.Errorf("unknown checkpoint state store %q: must be %s, %s or %s",
+			backend, StateStoreVectorstore, StateStoreFilesystem, StateStoreSQLite)
+	}
+}
+
+// FileBlobStore keeps each full state in a file under a root directory,
+// laid out as <tenant>/<team>/<project>/<checkpoint>.state.
+type FileBlobStore struct {
+	root string
+}
+
+// NewFileBlobStore creates the root directory if needed and returns a
+// store keeping files under it.
+func NewFileBlobStore(root string) (*FileBlobStore, error) {
+	if root == "" {
+		return nil, errors.New("checkpoint state directory is required")
+	}
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, fmt.Errorf("creating checkpoint state directory: %w", err)
+	}
+	return &FileBlobStore{root: root}, nil
+}
+
+// path returns the file of key. Components are escaped so that no ID can
+// name a file outside the root.
+func (f *FileBlobStore) path(key BlobKey) (string, error) {
+	if key.CheckpointID == "" {
+		return "", errors.New("checkpoint ID is required")
+	}
+	team := key.TeamID
+	if team == "" {
+		team = "_"
+	}
+	parts := []string{f.root}
+	for _, part := range []string{key.TenantID, team, key.ProjectID} {
+		parts = append(parts, escapePathPart(part))
+	}
+	parts = append(parts, escapePathPart(key.CheckpointID)+".state")
+	return filepath.Join(parts...), nil
+}
+
+// escapePathPart makes an ID safe to use as one path component.
+func escapePathPart(s string) string {
+	s = url.PathEscape(s)
+	// "." and ".." are valid path segments to url.PathEscape
+	return strings.ReplaceAll(s, ".", "%2E")
+}
+
+// Put writes data to a temporary file and renames it into place, so a
+// reader never sees a partial state.
+func (f *FileBlobStore) Put(ctx context.Context, key BlobKey, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating checkpoint state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*")
+	if err != nil {
+		return fmt.Errorf("writing checkpoint state: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing checkpoint state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing checkpoint state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing checkpoint state: %w", err)
+	}
+	return nil
+}
+
+// Get reads the file of key.
+func (f *FileBlobStore) Get(ctx context.Context, key BlobKey) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrStateNotFound, key.CheckpointID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint state: %w", err)
+	}
+	return data, nil
+}
+
+// Delete removes the file of key.
+func (f *FileBlobStore) Delete(ctx context.Context, key BlobKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("deleting checkpoint state: %w", err)
+	}
+	return nil
+}
+
+// Close is a no-op.
+func (f *FileBlobStore) Close() error {
+	return nil
+}
@@ -0,0 +1,93 @@
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver
+)
+
+// sqliteSchema creates the table of full states.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS checkpoint_state (
+	tenant_id     TEXT NOT NULL,
+	team_id       TEXT NOT NULL,
+	project_id    TEXT NOT NULL,
+	checkpoint_id TEXT NOT NULL,
+	data          BLOB NOT NULL,
+	PRIMARY KEY (tenant_id, team_id, project_id, checkpoint_id)
+)`
+
+// SQLiteBlobStore keeps the full states of checkpoints in a SQLite
+// database, one file for all projects.
+type SQLiteBlobStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteBlobStore opens, and creates if needed, the database at path.
+func NewSQLiteBlobStore(path string) (*SQLiteBlobStore, error) {
+	if path == "" {
+		return nil, errors.New("checkpoint state database path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating checkpoint state directory: %w", err)
+	}
+	// WAL lets resumes read while a save writes
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("opening checkpoint state database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating checkpoint state table: %w", err)
+	}
+	return &SQLiteBlobStore{db: db}, nil
+}
+
+// Put stores data under key, replacing what was there.
+func (s *SQLiteBlobStore) Put(ctx context.Context, key BlobKey, data []byte) error {
+	if key.CheckpointID == "" {
+		return errors.New("checkpoint ID is required")
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO checkpoint_state (tenant_id, team_id, project_id, checkpoint_id, data) VALUES (?, ?, ?, ?, ?)`,
+		key.TenantID, key.TeamID, key.ProjectID, key.CheckpointID, data)
+	if err != nil {
+		return fmt.Errorf("writing checkpoint state: %w", err)
+	}
+	return nil
+}
+
+// Get returns the data stored under key.
+func (s *SQLiteBlobStore) Get(ctx context.Context, key BlobKey) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT data FROM checkpoint_state WHERE tenant_id = ? AND team_id = ? AND project_id = ? AND checkpoint_id = ?`,
+		key.TenantID, key.TeamID, key.ProjectID, key.CheckpointID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrStateNotFound, key.CheckpointID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint state: %w", err)
+	}
+	return data, nil
+}
+
+// Delete removes the data stored under key.
+func (s *SQLiteBlobStore) Delete(ctx context.Context, key BlobKey) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM checkpoint_state WHERE tenant_id = ? AND team_id = ? AND project_id = ? AND checkpoint_id = ?`,
+		key.TenantID, key.TeamID, key.ProjectID, key.CheckpointID)
+	if err != nil {
+		return fmt.Errorf("deleting checkpoint state: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *SQLiteBlobStore) Close() error {
+	return s.db.Close()
+}
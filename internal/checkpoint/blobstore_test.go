@@ -0,0 +1,139 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+func TestBlobStores(t *testing.T) {
+	backends := map[string]func(t *testing.T) BlobStore{
+		StateStoreFilesystem: func(t *testing.T) BlobStore {
+			store, err := OpenBlobStore(StateStoreFilesystem, filepath.Join(t.TempDir(), "state"))
+			require.NoError(t, err)
+			return store
+		},
+		StateStoreSQLite: func(t *testing.T) BlobStore {
+			store, err := OpenBlobStore(StateStoreSQLite, filepath.Join(t.TempDir(), "state.db"))
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+			ctx := context.Background()
+			key := BlobKey{TenantID: "tenant_1", ProjectID: "proj_1", CheckpointID: "cp_1"}
+
+			_, err := store.Get(ctx, key)
+			assert.ErrorIs(t, err, ErrStateNotFound)
+			assert.Equal(t, ctxerrors.CodeNotFound, ctxerrors.CodeOf(err))
+
+			require.NoError(t, store.Put(ctx, key, []byte("first")))
+			require.NoError(t, store.Put(ctx, key, []byte("second")))
+			data, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, "second", string(data))
+
+			// Keys of other tenants do not collide
+			other := key
+			other.TenantID = "tenant_2"
+			_, err = store.Get(ctx, other)
+			assert.ErrorIs(t, err, ErrStateNotFound)
+
+			require.NoError(t, store.Delete(ctx, key))
+			require.NoError(t, store.Delete(ctx, key), "deleting a missing key")
+			_, err = store.Get(ctx, key)
+			assert.ErrorIs(t, err, ErrStateNotFound)
+		})
+	}
+}
+
+func TestFileBlobStore_ConfinesKeysToRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "state")
+	store, err := NewFileBlobStore(root)
+	require.NoError(t, err)
+
+	key := BlobKey{TenantID: "..", TeamID: "..", ProjectID: "../..", CheckpointID: "../escape"}
+	require.NoError(t, store.Put(context.Background(), key, []byte("x")))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "only the root is written to")
+}
+
+func TestOpenBlobStore(t *testing.T) {
+	store, err := OpenBlobStore("", "")
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	store, err = OpenBlobStore(StateStoreVectorstore, "")
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	_, err = OpenBlobStore("s3", "bucket")
+	assert.Error(t, err)
+}
+
+func TestService_BlobStore(t *testing.T) {
+	blobs, err := NewFileBlobStore(t.TempDir())
+	require.NoError(t, err)
+	store := newMockStore()
+	cfg := DefaultServiceConfig()
+	cfg.BlobStore = blobs
+	svc, err := NewServiceWithStore(cfg, store, zap.NewNop())
+	require.NoError(t, err)
+	defer svc.Close()
+	ctx := context.Background()
+
+	cp, err := svc.Save(ctx, &SaveRequest{
+		SessionID: "sess_1",
+		TenantID:  "tenant_1",
+		ProjectID: "proj_1",
+		Name:      "Large session",
+		Summary:   "Migrated the billing service",
+		Context:   "Context",
+		FullState: "the whole transcript",
+	})
+	require.NoError(t, err)
+	assert.True(t, cp.StateExternal)
+
+	docs := store.documents[collectionCheckpoints]
+	require.Len(t, docs, 1)
+	assert.NotContains(t, docs[0].Metadata, "full_state", "state is not stored in the vectorstore")
+
+	listed, err := svc.List(ctx, &ListRequest{TenantID: "tenant_1", ProjectID: "proj_1"})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Empty(t, listed[0].FullState)
+	assert.True(t, listed[0].StateExternal)
+
+	got, err := svc.Get(ctx, "tenant_1", "", "proj_1", cp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "the whole transcript", got.FullState)
+
+	resp, err := svc.Resume(ctx, &ResumeRequest{TenantID: "tenant_1", ProjectID: "proj_1", CheckpointID: cp.ID, Level: ResumeFull})
+	require.NoError(t, err)
+	assert.Equal(t, "the whole transcript", resp.Content)
+
+	// Rewriting the checkpoint keeps the state where it is
+	_, err = svc.Annotate(ctx, &AnnotateRequest{TenantID: "tenant_1", ProjectID: "proj_1", CheckpointID: cp.ID, Outcome: OutcomeSucceeded})
+	require.NoError(t, err)
+	got, err = svc.Get(ctx, "tenant_1", "", "proj_1", cp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "the whole transcript", got.FullState)
+
+	require.NoError(t, svc.Delete(ctx, "tenant_1", "", "proj_1", cp.ID))
+	_, err = blobs.Get(ctx, BlobKey{TenantID: "tenant_1", ProjectID: "proj_1", CheckpointID: cp.ID})
+	assert.ErrorIs(t, err, ErrStateNotFound)
+}
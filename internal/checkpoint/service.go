@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +20,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/injection"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
@@ -24,8 +31,27 @@ import (
 // need prefixed collection names like "{tenant}_{team}_{project}_checkpoints".
 const collectionCheckpoints = "checkpoints"
 
+// streamBatchSize is the number of checkpoints read per batch by Stream.
+const streamBatchSize = 200
+
+// maxListFetch caps the single List read Stream falls back to for stores
+// that cannot scroll.
+const maxListFetch = 10000
+
 const instrumentationName = "github.com/fyrsmithlabs/contextd/internal/checkpoint"
 
+var (
+	// ErrNotFound is returned when a checkpoint does not exist.
+	ErrNotFound = ctxerrors.NotFound("checkpoint not found")
+
+	// ErrInvalidAnnotation is returned when an annotation is empty or malformed.
+	ErrInvalidAnnotation = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid annotation")
+
+	// ErrQuarantined is returned when resuming a checkpoint that was flagged
+	// as possible prompt injection and has not been released.
+	ErrQuarantined = ctxerrors.New(ctxerrors.CodeInvalidInput, "checkpoint is quarantined as possible prompt injection")
+)
+
 // Service provides checkpoint management operations.
 type Service interface {
 	// Save creates a new checkpoint.
@@ -34,6 +60,14 @@ type Service interface {
 	// List retrieves checkpoints for a session or project.
 	List(ctx context.Context, req *ListRequest) ([]*Checkpoint, error)
 
+	// Search returns checkpoints ranked by similarity of their summary to
+	// req.Query.
+	Search(ctx context.Context, req *SearchRequest) ([]*ScoredCheckpoint, error)
+
+	// Stream calls fn for every checkpoint matching req, reading the store in
+	// batches. req.Limit is ignored. Iteration stops at the first error from fn.
+	Stream(ctx context.Context, req *ListRequest, fn func(*Checkpoint) error) error
+
 	// Resume restores a checkpoint at the specified level.
 	Resume(ctx context.Context, req *ResumeRequest) (*ResumeResponse, error)
 
@@ -43,6 +77,22 @@ type Service interface {
 	// Delete removes a checkpoint.
 	Delete(ctx context.Context, tenantID, teamID, projectID, checkpointID string) error
 
+	// Annotate records the outcome of the work a checkpoint captured and
+	// returns the updated checkpoint.
+	Annotate(ctx context.Context, req *AnnotateRequest) (*Checkpoint, error)
+
+	// Release clears a checkpoint's quarantine after review so it can be
+	// listed, searched and resumed again.
+	Release(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*Checkpoint, error)
+
+	// LinkConversation records the conversation message that saved a
+	// checkpoint.
+	LinkConversation(ctx context.Context, tenantID, teamID, projectID, checkpointID string, origin ConversationOrigin) error
+
+	// Lineage returns the chain of parents leading to a checkpoint and the
+	// tree of branches descending from the chain's root.
+	Lineage(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*Lineage, error)
+
 	// Close closes the service.
 	Close() error
 }
@@ -57,6 +107,16 @@ type Config struct {
 
 	// AutoCheckpointThresholds are context % levels for auto-checkpoint.
 	AutoCheckpointThresholds []float64
+
+	// ScanInjection quarantines saved checkpoints that look like prompt
+	// injection (default: true)
+	ScanInjection bool
+
+	// BlobStore keeps the full state of new checkpoints outside the
+	// vectorstore; nil keeps it in the vectorstore. Checkpoints saved
+	// before are read from wherever they were stored. The service does not
+	// close it.
+	BlobStore BlobStore
 }
 
 // DefaultServiceConfig returns sensible defaults.
@@ -65,6 +125,7 @@ func DefaultServiceConfig() *Config {
 		VectorSize:               1536,
 		MaxCheckpointsPerSession: 10,
 		AutoCheckpointThresholds: []float64{0.25, 0.5, 0.75, 0.9},
+		ScanInjection:            true,
 	}
 }
 
@@ -74,6 +135,9 @@ type service struct {
 	stores vectorstore.StoreProvider
 	logger *zap.Logger
 
+	// scanner flags prompt injection on Save; nil disables scanning
+	scanner *injection.Scanner
+
 	// Telemetry
 	tracer         trace.Tracer
 	meter          metric.Meter
@@ -88,6 +152,11 @@ type service struct {
 
 	mu     sync.RWMutex
 	closed bool
+
+	// heads maps tenant and session to the checkpoint the session last
+	// resumed or saved, the parent of its next checkpoint
+	headsMu sync.Mutex
+	heads   map[string]string
 }
 
 // NewService creates a new checkpoint service.
@@ -110,6 +179,9 @@ func NewService(cfg *Config, stores vectorstore.StoreProvider, logger *zap.Logge
 		meter:  otel.Meter(instrumentationName),
 	}
 
+	if cfg.ScanInjection {
+		s.scanner = injection.NewScanner()
+	}
 	s.initMetrics()
 
 	return s, nil
@@ -137,6 +209,9 @@ func NewServiceWithStore(cfg *Config, store vectorstore.Store, logger *zap.Logge
 		meter:  otel.Meter(instrumentationName),
 	}
 
+	if cfg.ScanInjection {
+		s.scanner = injection.NewScanner()
+	}
 	s.initMetrics()
 
 	return s, nil
@@ -302,6 +377,11 @@ func (s *service) Save(ctx context.Context, req *SaveRequest) (*Checkpoint, erro
 	}
 	s.mu.RUnlock()
 
+	if err := checkSaveLimits(req); err != nil {
+		s.recordError(ctx, "save", "validation_failed")
+		return nil, err
+	}
+
 	// Get project-scoped store
 	store, err := s.getProjectStore(ctx, req.TenantID, req.TeamID, req.ProjectID)
 	if err != nil {
@@ -311,9 +391,21 @@ func (s *service) Save(ctx context.Context, req *SaveRequest) (*Checkpoint, erro
 		return nil, fmt.Errorf("failed to get project store: %w", err)
 	}
 
+	// Continue the session's lineage unless the caller names a parent
+	parentID := req.ParentID
+	if parentID != "" {
+		if _, err := s.get(ctx, req.TenantID, req.TeamID, req.ProjectID, parentID, false); err != nil {
+			s.recordError(ctx, "save", "parent_not_found")
+			return nil, fmt.Errorf("parent checkpoint: %w", err)
+		}
+	} else if req.SessionID != "" {
+		parentID = s.head(req.TenantID, req.SessionID)
+	}
+
 	// Create checkpoint
 	cp := &Checkpoint{
 		ID:          uuid.New().String(),
+		ParentID:    parentID,
 		SessionID:   req.SessionID,
 		TenantID:    req.TenantID,
 		TeamID:      req.TeamID,
@@ -330,6 +422,13 @@ func (s *service) Save(ctx context.Context, req *SaveRequest) (*Checkpoint, erro
 		Metadata:    req.Metadata,
 		CreatedAt:   time.Now(),
 	}
+	cp.QuarantineRules = s.scanner.ScanFields(cp.Name, cp.Description, cp.Summary, cp.Context, cp.FullState)
+	if cp.Quarantined() {
+		s.logger.Warn("checkpoint quarantined as possible prompt injection",
+			zap.String("id", cp.ID),
+			zap.String("project_id", cp.ProjectID),
+			zap.Strings("rules", cp.QuarantineRules))
+	}
 
 	// Ensure collection exists (simple name, no routing prefix)
 	exists, err := store.CollectionExists(ctx, collectionCheckpoints)
@@ -347,6 +446,18 @@ func (s *service) Save(ctx context.Context, req *SaveRequest) (*Checkpoint, erro
 		}
 	}
 
+	// Large state goes to the blob store; only what is searched stays in
+	// the vectorstore
+	if s.config.BlobStore != nil && cp.FullState != "" {
+		if err := s.config.BlobStore.Put(ctx, blobKey(cp), []byte(cp.FullState)); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			s.recordError(ctx, "save", "state_store_failed")
+			return nil, fmt.Errorf("failed to save checkpoint state: %w", err)
+		}
+		cp.StateExternal = true
+	}
+
 	// Convert checkpoint to document for storage
 	doc := s.checkpointToDocument(cp, collectionCheckpoints)
 
@@ -355,9 +466,14 @@ func (s *service) Save(ctx context.Context, req *SaveRequest) (*Checkpoint, erro
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		s.recordError(ctx, "save", "store_failed")
+		s.deleteState(ctx, cp)
 		return nil, fmt.Errorf("failed to save checkpoint: %w", err)
 	}
 
+	if req.SessionID != "" {
+		s.setHead(req.TenantID, req.SessionID, cp.ID)
+	}
+
 	// Record metrics
 	if s.saveCounter != nil {
 		s.saveCounter.Add(ctx, 1, metric.WithAttributes(
@@ -385,13 +501,33 @@ func (s *service) Save(ctx context.Context, req *SaveRequest) (*Checkpoint, erro
 	s.logger.Info("saved checkpoint",
 		zap.String("id", cp.ID),
 		zap.String("session_id", cp.SessionID),
+		zap.String("parent_id", cp.ParentID),
 		zap.Bool("auto_created", cp.AutoCreated),
+		zap.Bool("quarantined", cp.Quarantined()),
 	)
 
 	span.SetAttributes(attribute.String("checkpoint_id", cp.ID))
 	return cp, nil
 }
 
+// checkSaveLimits rejects checkpoints larger than validation.Checkpoint
+// allows.
+func checkSaveLimits(req *SaveRequest) error {
+	limits := validation.Checkpoint
+	for _, field := range []struct{ name, value string }{
+		{"name", req.Name},
+		{"description", req.Description},
+		{"summary", req.Summary},
+		{"context", req.Context},
+		{"full_state", req.FullState},
+	} {
+		if err := limits.Content(field.name, field.value); err != nil {
+			return err
+		}
+	}
+	return limits.Metadata("metadata", len(req.Metadata))
+}
+
 // List retrieves checkpoints for a session or project.
 func (s *service) List(ctx context.Context, req *ListRequest) ([]*Checkpoint, error) {
 	start := time.Now()
@@ -434,17 +570,7 @@ func (s *service) List(ctx context.Context, req *ListRequest) ([]*Checkpoint, er
 		return []*Checkpoint{}, nil
 	}
 
-	// Build filter
-	filters := make(map[string]interface{})
-	if req.SessionID != "" {
-		filters["session_id"] = req.SessionID
-	}
-	if req.ProjectPath != "" {
-		filters["project_path"] = req.ProjectPath
-	}
-	if req.AutoOnly {
-		filters["auto_created"] = true
-	}
+	filters := listFilters(req)
 
 	limit := req.Limit
 	if limit == 0 {
@@ -464,7 +590,7 @@ func (s *service) List(ctx context.Context, req *ListRequest) ([]*Checkpoint, er
 	checkpoints := make([]*Checkpoint, 0, len(results))
 	for _, r := range results {
 		cp := s.resultToCheckpoint(r)
-		if cp != nil {
+		if cp != nil && cp.Quarantined() == req.Quarantined {
 			checkpoints = append(checkpoints, cp)
 		}
 	}
@@ -480,6 +606,177 @@ func (s *service) List(ctx context.Context, req *ListRequest) ([]*Checkpoint, er
 	return checkpoints, nil
 }
 
+// Search ranks the project's checkpoints by semantic similarity to the query.
+func (s *service) Search(ctx context.Context, req *SearchRequest) ([]*ScoredCheckpoint, error) {
+	ctx, span := s.tracer.Start(ctx, "checkpoint.search")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", req.TenantID),
+		attribute.String("team_id", req.TeamID),
+		attribute.String("project_id", req.ProjectID),
+		attribute.String("project_path", req.ProjectPath),
+		attribute.Int("limit", req.Limit),
+	)
+
+	if req.Query == "" {
+		return nil, errors.New("query is required")
+	}
+	if err := validation.Checkpoint.Query("query", req.Query); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	store, err := s.getProjectStore(ctx, req.TenantID, req.TeamID, req.ProjectID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "search", "get_store_failed")
+		return nil, fmt.Errorf("failed to get project store: %w", err)
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionCheckpoints)
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "search", "check_collection_failed")
+		return nil, fmt.Errorf("failed to check collection: %w", err)
+	}
+	if !exists {
+		return []*ScoredCheckpoint{}, nil
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	filters := listFilters(&ListRequest{
+		TenantID:    req.TenantID,
+		TeamID:      req.TeamID,
+		ProjectID:   req.ProjectID,
+		ProjectPath: req.ProjectPath,
+	})
+	results, err := store.SearchInCollection(ctx, collectionCheckpoints, req.Query, limit, filters)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "search", "search_failed")
+		return nil, fmt.Errorf("failed to search checkpoints: %w", err)
+	}
+
+	scored := make([]*ScoredCheckpoint, 0, len(results))
+	for _, r := range results {
+		if cp := s.resultToCheckpoint(r); cp != nil && !cp.Quarantined() {
+			scored = append(scored, &ScoredCheckpoint{Checkpoint: cp, Score: float64(r.Score)})
+		}
+	}
+
+	span.SetAttributes(attribute.Int("result_count", len(scored)))
+	return scored, nil
+}
+
+// Stream calls fn for every checkpoint matching req without loading them
+// all at once. Stores that cannot scroll fall back to List.
+func (s *service) Stream(ctx context.Context, req *ListRequest, fn func(*Checkpoint) error) error {
+	ctx, span := s.tracer.Start(ctx, "checkpoint.stream")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", req.TenantID),
+		attribute.String("team_id", req.TeamID),
+		attribute.String("project_id", req.ProjectID),
+		attribute.String("session_id", req.SessionID),
+		attribute.String("project_path", req.ProjectPath),
+	)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	store, err := s.getProjectStore(ctx, req.TenantID, req.TeamID, req.ProjectID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "stream", "get_store_failed")
+		return fmt.Errorf("failed to get project store: %w", err)
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionCheckpoints)
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "stream", "check_collection_failed")
+		return fmt.Errorf("failed to check collection: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	count := 0
+	err = vectorstore.Scroll(ctx, store, collectionCheckpoints, streamBatchSize, listFilters(req),
+		func(results []vectorstore.SearchResult) error {
+			for _, r := range results {
+				cp := s.resultToCheckpoint(r)
+				if cp == nil || cp.Quarantined() != req.Quarantined {
+					continue
+				}
+				count++
+				if err := fn(cp); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if errors.Is(err, vectorstore.ErrScrollUnsupported) {
+		all := *req
+		all.Limit = maxListFetch
+		var checkpoints []*Checkpoint
+		checkpoints, err = s.List(ctx, &all)
+		if err != nil {
+			return err
+		}
+		for _, cp := range checkpoints {
+			count++
+			if err = fn(cp); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("result_count", count))
+	return nil
+}
+
+// listFilters builds the metadata filters shared by List and Stream.
+func listFilters(req *ListRequest) map[string]interface{} {
+	filters := make(map[string]interface{})
+	if req.SessionID != "" {
+		filters["session_id"] = req.SessionID
+	}
+	if req.ProjectPath != "" {
+		filters["project_path"] = req.ProjectPath
+	}
+	if req.AutoOnly {
+		filters["auto_created"] = true
+	}
+	if req.Outcome != "" {
+		filters["outcome"] = string(req.Outcome)
+	}
+	return filters
+}
+
 // Resume restores a checkpoint at the specified level.
 func (s *service) Resume(ctx context.Context, req *ResumeRequest) (*ResumeResponse, error) {
 	start := time.Now()
@@ -499,13 +796,23 @@ func (s *service) Resume(ctx context.Context, req *ResumeRequest) (*ResumeRespon
 	}
 	s.mu.RUnlock()
 
-	// Get the checkpoint
-	cp, err := s.Get(ctx, req.TenantID, req.TeamID, req.ProjectID, req.CheckpointID)
+	// Get the checkpoint; externally stored state is read for full
+	// resumes only
+	cp, err := s.get(ctx, req.TenantID, req.TeamID, req.ProjectID, req.CheckpointID, req.Level == ResumeFull)
 	if err != nil {
 		span.RecordError(err)
 		s.recordError(ctx, "resume", "get_checkpoint_failed")
 		return nil, err
 	}
+	if cp.Quarantined() {
+		s.recordError(ctx, "resume", "quarantined")
+		return nil, fmt.Errorf("%w: %s (rules: %s)", ErrQuarantined, cp.ID, strings.Join(cp.QuarantineRules, ", "))
+	}
+
+	// The session's next checkpoint continues from this one
+	if req.SessionID != "" {
+		s.setHead(req.TenantID, req.SessionID, cp.ID)
+	}
 
 	// Determine content based on level
 	var content string
@@ -555,8 +862,14 @@ func (s *service) Resume(ctx context.Context, req *ResumeRequest) (*ResumeRespon
 	}, nil
 }
 
-// Get retrieves a checkpoint by ID.
+// Get retrieves a checkpoint by ID, with its full state.
 func (s *service) Get(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*Checkpoint, error) {
+	return s.get(ctx, tenantID, teamID, projectID, checkpointID, true)
+}
+
+// get retrieves a checkpoint by ID. withState reads a full state kept in
+// the blob store; without it, FullState is empty for such checkpoints.
+func (s *service) get(ctx context.Context, tenantID, teamID, projectID, checkpointID string, withState bool) (*Checkpoint, error) {
 	ctx, span := s.tracer.Start(ctx, "checkpoint.get")
 	defer span.End()
 
@@ -587,7 +900,7 @@ func (s *service) Get(ctx context.Context, tenantID, teamID, projectID, checkpoi
 	exists, err := store.CollectionExists(ctx, collectionCheckpoints)
 	if err != nil || !exists {
 		s.recordError(ctx, "get", "not_found")
-		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, checkpointID)
 	}
 
 	// Search by ID using filter
@@ -605,7 +918,7 @@ func (s *service) Get(ctx context.Context, tenantID, teamID, projectID, checkpoi
 
 	if len(results) == 0 {
 		s.recordError(ctx, "get", "not_found")
-		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, checkpointID)
 	}
 
 	cp := s.resultToCheckpoint(results[0])
@@ -614,6 +927,20 @@ func (s *service) Get(ctx context.Context, tenantID, teamID, projectID, checkpoi
 		return nil, fmt.Errorf("invalid checkpoint data: %s", checkpointID)
 	}
 
+	if withState && cp.StateExternal {
+		if s.config.BlobStore == nil {
+			s.recordError(ctx, "get", "state_store_missing")
+			return nil, fmt.Errorf("checkpoint %s: state is kept in a blob store, but none is configured", checkpointID)
+		}
+		state, err := s.config.BlobStore.Get(ctx, blobKey(cp))
+		if err != nil {
+			span.RecordError(err)
+			s.recordError(ctx, "get", "state_read_failed")
+			return nil, fmt.Errorf("failed to read checkpoint state: %w", err)
+		}
+		cp.FullState = string(state)
+	}
+
 	return cp, nil
 }
 
@@ -650,11 +977,372 @@ func (s *service) Delete(ctx context.Context, tenantID, teamID, projectID, check
 		s.recordError(ctx, "delete", "delete_failed")
 		return fmt.Errorf("failed to delete checkpoint: %w", err)
 	}
+	s.deleteState(ctx, &Checkpoint{ID: checkpointID, TenantID: tenantID, TeamID: teamID, ProjectID: projectID})
 
 	s.logger.Info("deleted checkpoint", zap.String("id", checkpointID))
 	return nil
 }
 
+// Annotate records the outcome of the work a checkpoint captured.
+func (s *service) Annotate(ctx context.Context, req *AnnotateRequest) (*Checkpoint, error) {
+	ctx, span := s.tracer.Start(ctx, "checkpoint.annotate")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", req.TenantID),
+		attribute.String("team_id", req.TeamID),
+		attribute.String("project_id", req.ProjectID),
+		attribute.String("checkpoint_id", req.CheckpointID),
+		attribute.String("outcome", string(req.Outcome)),
+	)
+
+	if err := validateAnnotation(req); err != nil {
+		s.recordError(ctx, "annotate", "invalid_annotation")
+		return nil, err
+	}
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	cp, err := s.get(ctx, req.TenantID, req.TeamID, req.ProjectID, req.CheckpointID, false)
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "annotate", "get_checkpoint_failed")
+		return nil, err
+	}
+	original := s.checkpointToDocument(cp, collectionCheckpoints)
+
+	annotation := Annotation{}
+	if cp.Annotation != nil {
+		annotation = *cp.Annotation
+	}
+	if req.Outcome != "" {
+		annotation.Outcome = req.Outcome
+	}
+	if req.PRURL != "" {
+		annotation.PRURL = req.PRURL
+	}
+	if req.Notes != "" {
+		annotation.Notes = req.Notes
+	}
+	annotation.AnnotatedAt = time.Now()
+	cp.Annotation = &annotation
+
+	store, err := s.getProjectStore(ctx, req.TenantID, req.TeamID, req.ProjectID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "annotate", "get_store_failed")
+		return nil, fmt.Errorf("failed to get project store: %w", err)
+	}
+
+	// Delete old version
+	if err := store.DeleteDocumentsFromCollection(ctx, collectionCheckpoints, []string{cp.ID}); err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "annotate", "delete_old_failed")
+		return nil, fmt.Errorf("failed to delete old checkpoint: %w", err)
+	}
+
+	// Re-add with the annotation
+	doc := s.checkpointToDocument(cp, collectionCheckpoints)
+	if _, err := store.AddDocuments(ctx, []vectorstore.Document{doc}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "annotate", "update_failed")
+		s.restoreCheckpoint(ctx, store, original)
+		return nil, fmt.Errorf("failed to update checkpoint: %w", err)
+	}
+
+	s.logger.Info("annotated checkpoint",
+		zap.String("id", cp.ID),
+		zap.String("outcome", string(annotation.Outcome)),
+	)
+
+	return cp, nil
+}
+
+// Release clears a checkpoint's quarantine. Releasing a checkpoint that is
+// not quarantined is a no-op.
+func (s *service) Release(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*Checkpoint, error) {
+	ctx, span := s.tracer.Start(ctx, "checkpoint.release")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", tenantID),
+		attribute.String("team_id", teamID),
+		attribute.String("project_id", projectID),
+		attribute.String("checkpoint_id", checkpointID),
+	)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	cp, err := s.get(ctx, tenantID, teamID, projectID, checkpointID, false)
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "release", "get_checkpoint_failed")
+		return nil, err
+	}
+	if !cp.Quarantined() {
+		return cp, nil
+	}
+
+	store, err := s.getProjectStore(ctx, tenantID, teamID, projectID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "release", "get_store_failed")
+		return nil, fmt.Errorf("failed to get project store: %w", err)
+	}
+
+	original := s.checkpointToDocument(cp, collectionCheckpoints)
+	rules := cp.QuarantineRules
+	cp.QuarantineRules = nil
+
+	if err := store.DeleteDocumentsFromCollection(ctx, collectionCheckpoints, []string{cp.ID}); err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "release", "delete_old_failed")
+		return nil, fmt.Errorf("failed to delete old checkpoint: %w", err)
+	}
+
+	doc := s.checkpointToDocument(cp, collectionCheckpoints)
+	if _, err := store.AddDocuments(ctx, []vectorstore.Document{doc}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "release", "update_failed")
+		s.restoreCheckpoint(ctx, store, original)
+		return nil, fmt.Errorf("failed to update checkpoint: %w", err)
+	}
+
+	s.logger.Info("released checkpoint from quarantine",
+		zap.String("id", cp.ID),
+		zap.Strings("rules", rules),
+	)
+
+	return cp, nil
+}
+
+// LinkConversation records the conversation message that saved a
+// checkpoint. Linking the same message again is a no-op.
+func (s *service) LinkConversation(ctx context.Context, tenantID, teamID, projectID, checkpointID string, origin ConversationOrigin) error {
+	ctx, span := s.tracer.Start(ctx, "checkpoint.link_conversation")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", tenantID),
+		attribute.String("project_id", projectID),
+		attribute.String("checkpoint_id", checkpointID),
+		attribute.String("session_id", origin.SessionID),
+	)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	cp, err := s.get(ctx, tenantID, teamID, projectID, checkpointID, false)
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "link_conversation", "get_checkpoint_failed")
+		return err
+	}
+	if o := cp.Origin; o != nil && o.SessionID == origin.SessionID &&
+		o.MessageUUID == origin.MessageUUID && o.Excerpt == origin.Excerpt {
+		return nil
+	}
+	original := s.checkpointToDocument(cp, collectionCheckpoints)
+	cp.Origin = &origin
+
+	store, err := s.getProjectStore(ctx, tenantID, teamID, projectID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "link_conversation", "get_store_failed")
+		return fmt.Errorf("failed to get project store: %w", err)
+	}
+
+	if err := store.DeleteDocumentsFromCollection(ctx, collectionCheckpoints, []string{cp.ID}); err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "link_conversation", "delete_old_failed")
+		return fmt.Errorf("failed to delete old checkpoint: %w", err)
+	}
+
+	doc := s.checkpointToDocument(cp, collectionCheckpoints)
+	if _, err := store.AddDocuments(ctx, []vectorstore.Document{doc}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(ctx, "link_conversation", "update_failed")
+		s.restoreCheckpoint(ctx, store, original)
+		return fmt.Errorf("failed to update checkpoint: %w", err)
+	}
+
+	s.logger.Debug("linked checkpoint to conversation",
+		zap.String("id", cp.ID),
+		zap.String("session_id", origin.SessionID),
+		zap.String("message_uuid", origin.MessageUUID),
+	)
+
+	return nil
+}
+
+// restoreCheckpoint re-adds the previous version of a checkpoint whose
+// updated version failed to store, so a failed update does not lose it.
+func (s *service) restoreCheckpoint(ctx context.Context, store vectorstore.Store, original vectorstore.Document) {
+	if _, err := store.AddDocuments(ctx, []vectorstore.Document{original}); err != nil {
+		s.recordError(ctx, "restore", "restore_failed")
+		s.logger.Error("failed to rollback checkpoint after update failure",
+			zap.String("id", original.ID),
+			zap.Error(err))
+	}
+}
+
+// validateAnnotation checks that req sets at least one well-formed field.
+func validateAnnotation(req *AnnotateRequest) error {
+	if req.CheckpointID == "" {
+		return fmt.Errorf("%w: checkpoint ID is required", ErrInvalidAnnotation)
+	}
+	if req.Outcome == "" && req.PRURL == "" && req.Notes == "" {
+		return fmt.Errorf("%w: one of outcome, pr_url or notes is required", ErrInvalidAnnotation)
+	}
+	if req.Outcome != "" && !req.Outcome.Valid() {
+		return fmt.Errorf("%w: outcome must be %s, %s or %s",
+			ErrInvalidAnnotation, OutcomeSucceeded, OutcomeFailed, OutcomePartial)
+	}
+	if req.PRURL != "" {
+		u, err := url.Parse(req.PRURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: pr_url must be an http or https URL", ErrInvalidAnnotation)
+		}
+	}
+	if len(req.Notes) > MaxAnnotationNotesLength {
+		return fmt.Errorf("%w: notes exceed %d characters", ErrInvalidAnnotation, MaxAnnotationNotesLength)
+	}
+	return nil
+}
+
+// Lineage returns the chain of parents leading to a checkpoint and the tree
+// of branches descending from the chain's root. Parents that were deleted
+// or are quarantined end the chain.
+func (s *service) Lineage(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*Lineage, error) {
+	ctx, span := s.tracer.Start(ctx, "checkpoint.lineage")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", tenantID),
+		attribute.String("project_id", projectID),
+		attribute.String("checkpoint_id", checkpointID),
+	)
+
+	target, err := s.get(ctx, tenantID, teamID, projectID, checkpointID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*Checkpoint{target.ID: target}
+	err = s.Stream(ctx, &ListRequest{TenantID: tenantID, TeamID: teamID, ProjectID: projectID}, func(cp *Checkpoint) error {
+		if cp.ID != target.ID {
+			byID[cp.ID] = cp
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "lineage", "stream_failed")
+		return nil, fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+
+	// Walk up to the root, guarding against cycles in stored data
+	ancestors := []*Checkpoint{target}
+	seen := map[string]bool{target.ID: true}
+	for cp := target; cp.ParentID != ""; {
+		parent, ok := byID[cp.ParentID]
+		if !ok || seen[parent.ID] {
+			break
+		}
+		seen[parent.ID] = true
+		ancestors = append(ancestors, parent)
+		cp = parent
+	}
+	slices.Reverse(ancestors)
+
+	children := make(map[string][]*Checkpoint)
+	for _, cp := range byID {
+		if cp.ParentID != "" {
+			children[cp.ParentID] = append(children[cp.ParentID], cp)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("depth", len(ancestors)))
+	return &Lineage{
+		Ancestors: ancestors,
+		Root:      lineageTree(ancestors[0], children, make(map[string]bool)),
+	}, nil
+}
+
+// lineageTree builds the tree of cp and its descendants, children ordered
+// by creation time.
+func lineageTree(cp *Checkpoint, children map[string][]*Checkpoint, visited map[string]bool) *LineageNode {
+	visited[cp.ID] = true
+	node := &LineageNode{Checkpoint: cp}
+
+	kids := children[cp.ID]
+	sort.Slice(kids, func(i, j int) bool {
+		return kids[i].CreatedAt.Before(kids[j].CreatedAt)
+	})
+	for _, child := range kids {
+		if !visited[child.ID] {
+			node.Children = append(node.Children, lineageTree(child, children, visited))
+		}
+	}
+	return node
+}
+
+// blobKey returns the blob store key of a checkpoint's full state.
+func blobKey(cp *Checkpoint) BlobKey {
+	return BlobKey{TenantID: cp.TenantID, TeamID: cp.TeamID, ProjectID: cp.ProjectID, CheckpointID: cp.ID}
+}
+
+// deleteState removes a checkpoint's full state from the blob store. A
+// failure is logged: the state is orphaned, not lost.
+func (s *service) deleteState(ctx context.Context, cp *Checkpoint) {
+	if s.config.BlobStore == nil {
+		return
+	}
+	if err := s.config.BlobStore.Delete(ctx, blobKey(cp)); err != nil {
+		s.recordError(ctx, "delete", "state_delete_failed")
+		s.logger.Warn("failed to delete checkpoint state",
+			zap.String("id", cp.ID),
+			zap.Error(err))
+	}
+}
+
+// head returns the checkpoint the session last resumed or saved.
+func (s *service) head(tenantID, sessionID string) string {
+	s.headsMu.Lock()
+	defer s.headsMu.Unlock()
+	return s.heads[tenantID+"/"+sessionID]
+}
+
+// setHead records the checkpoint the session's next checkpoint continues.
+func (s *service) setHead(tenantID, sessionID, checkpointID string) {
+	s.headsMu.Lock()
+	defer s.headsMu.Unlock()
+	if s.heads == nil {
+		s.heads = make(map[string]string)
+	}
+	s.heads[tenantID+"/"+sessionID] = checkpointID
+}
+
 // Close closes the service.
 func (s *service) Close() error {
 	s.mu.Lock()
@@ -685,11 +1373,40 @@ func (s *service) checkpointToDocument(cp *Checkpoint, collectionName string) ve
 		"description":  cp.Description,
 		"summary":      cp.Summary,
 		"context":      cp.Context,
-		"full_state":   cp.FullState,
 		"token_count":  int64(cp.TokenCount),
 		"threshold":    cp.Threshold,
 		"auto_created": cp.AutoCreated,
 		"created_at":   cp.CreatedAt.Unix(),
+		// Always present so an outcome filter never matches unannotated checkpoints
+		"outcome": "",
+	}
+
+	if cp.StateExternal {
+		metadata["state_external"] = true
+	} else {
+		metadata["full_state"] = cp.FullState
+	}
+
+	if cp.ParentID != "" {
+		metadata["parent_id"] = cp.ParentID
+	}
+
+	if len(cp.QuarantineRules) > 0 {
+		metadata["quarantine_rules"] = strings.Join(cp.QuarantineRules, ",")
+	}
+
+	if o := cp.Origin; o != nil {
+		metadata["origin_session_id"] = o.SessionID
+		metadata["origin_message_uuid"] = o.MessageUUID
+		metadata["origin_timestamp"] = o.Timestamp.Unix()
+		metadata["origin_excerpt"] = o.Excerpt
+	}
+
+	if a := cp.Annotation; a != nil {
+		metadata["outcome"] = string(a.Outcome)
+		metadata["pr_url"] = a.PRURL
+		metadata["notes"] = a.Notes
+		metadata["annotated_at"] = a.AnnotatedAt.Unix()
 	}
 
 	// Add metadata
@@ -752,6 +1469,12 @@ func (s *service) resultToCheckpoint(result vectorstore.SearchResult) *Checkpoin
 	if v, ok := result.Metadata["full_state"].(string); ok {
 		cp.FullState = v
 	}
+	switch v := result.Metadata["state_external"].(type) {
+	case bool:
+		cp.StateExternal = v
+	case string:
+		cp.StateExternal = v == "true"
+	}
 	if v, ok := result.Metadata["token_count"].(int64); ok {
 		cp.TokenCount = int32(v)
 	} else if v, ok := result.Metadata["token_count"].(float64); ok {
@@ -770,10 +1493,35 @@ func (s *service) resultToCheckpoint(result vectorstore.SearchResult) *Checkpoin
 	} else if v, ok := result.Metadata["auto_created"].(string); ok {
 		cp.AutoCreated = v == "true"
 	}
-	if v, ok := result.Metadata["created_at"].(int64); ok {
-		cp.CreatedAt = time.Unix(v, 0)
-	} else if v, ok := result.Metadata["created_at"].(float64); ok {
-		cp.CreatedAt = time.Unix(int64(v), 0)
+	if t, ok := metadataTime(result.Metadata["created_at"]); ok {
+		cp.CreatedAt = t
+	}
+	if v, ok := result.Metadata["parent_id"].(string); ok {
+		cp.ParentID = v
+	}
+	if v, ok := result.Metadata["quarantine_rules"].(string); ok && v != "" {
+		cp.QuarantineRules = strings.Split(v, ",")
+	}
+	if v, ok := result.Metadata["origin_session_id"].(string); ok && v != "" {
+		o := &ConversationOrigin{SessionID: v}
+		o.MessageUUID, _ = result.Metadata["origin_message_uuid"].(string)
+		o.Excerpt, _ = result.Metadata["origin_excerpt"].(string)
+		o.Timestamp, _ = metadataTime(result.Metadata["origin_timestamp"])
+		cp.Origin = o
+	}
+	if v := result.Metadata["annotated_at"]; v != nil {
+		a := &Annotation{}
+		if outcome, ok := result.Metadata["outcome"].(string); ok {
+			a.Outcome = Outcome(outcome)
+		}
+		if prURL, ok := result.Metadata["pr_url"].(string); ok {
+			a.PRURL = prURL
+		}
+		if notes, ok := result.Metadata["notes"].(string); ok {
+			a.Notes = notes
+		}
+		a.AnnotatedAt, _ = metadataTime(v)
+		cp.Annotation = a
 	}
 
 	// Extract metadata
@@ -788,6 +1536,22 @@ func (s *service) resultToCheckpoint(result vectorstore.SearchResult) *Checkpoin
 	return cp
 }
 
+// metadataTime parses a Unix timestamp stored as int64, float64, or (by
+// chromem) a decimal string.
+func metadataTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case int64:
+		return time.Unix(t, 0), true
+	case float64:
+		return time.Unix(int64(t), 0), true
+	case string:
+		if parsed, err := strconv.ParseInt(t, 10, 64); err == nil {
+			return time.Unix(parsed, 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
 // checkpointToPayload converts a Checkpoint to a map for storage (used by tests).
 func checkpointToPayload(cp *Checkpoint) map[string]interface{} {
 	payload := map[string]interface{}{
@@ -16,6 +16,30 @@ const (
 	ResumeFull ResumeLevel = "full"
 )
 
+// Outcome records how the work captured by a checkpoint turned out.
+type Outcome string
+
+const (
+	// OutcomeSucceeded means the task completed as intended.
+	OutcomeSucceeded Outcome = "succeeded"
+	// OutcomeFailed means the task failed or had to be rolled back.
+	OutcomeFailed Outcome = "failed"
+	// OutcomePartial means the task was only partly completed.
+	OutcomePartial Outcome = "partial"
+)
+
+// Valid reports whether o is a known outcome.
+func (o Outcome) Valid() bool {
+	switch o {
+	case OutcomeSucceeded, OutcomeFailed, OutcomePartial:
+		return true
+	}
+	return false
+}
+
+// MaxAnnotationNotesLength is the maximum length of Annotation.Notes.
+const MaxAnnotationNotesLength = 4096
+
 // Checkpoint represents a saved session state.
 type Checkpoint struct {
 	// ID is the unique identifier for this checkpoint.
@@ -51,6 +75,11 @@ type Checkpoint struct {
 	// FullState contains the complete session state.
 	FullState string `json:"full_state"`
 
+	// StateExternal reports that FullState is kept in the blob store. It is
+	// read by Get and full resumes only; listed and searched checkpoints
+	// leave FullState empty.
+	StateExternal bool `json:"state_external,omitempty"`
+
 	// TokenCount is the approximate token count of full state.
 	TokenCount int32 `json:"token_count"`
 
@@ -63,10 +92,59 @@ type Checkpoint struct {
 	// Metadata contains additional checkpoint metadata.
 	Metadata map[string]string `json:"metadata,omitempty"`
 
+	// ParentID is the checkpoint this one continues from: the one resumed
+	// into the session, or the session's previous checkpoint. Empty for the
+	// first checkpoint of a lineage.
+	ParentID string `json:"parent_id,omitempty"`
+
+	// Annotation records the outcome of the work, added after the fact.
+	Annotation *Annotation `json:"annotation,omitempty"`
+
+	// QuarantineRules lists the injection rules that matched when the
+	// checkpoint was saved. Quarantined checkpoints are hidden from lists
+	// and searches and cannot be resumed until released.
+	QuarantineRules []string `json:"quarantine_rules,omitempty"`
+
+	// Origin is the conversation message that saved the checkpoint, linked
+	// when the conversation is indexed. Nil until then.
+	Origin *ConversationOrigin `json:"origin,omitempty"`
+
 	// CreatedAt is when this checkpoint was created.
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Quarantined reports whether the checkpoint was flagged as possible prompt
+// injection and has not been released.
+func (c *Checkpoint) Quarantined() bool {
+	return len(c.QuarantineRules) > 0
+}
+
+// Annotation is outcome metadata attached to a checkpoint after it was saved.
+type Annotation struct {
+	// Outcome is how the task turned out.
+	Outcome Outcome `json:"outcome,omitempty"`
+
+	// PRURL links the pull request that carried the work.
+	PRURL string `json:"pr_url,omitempty"`
+
+	// Notes is free-form context, such as what went wrong.
+	Notes string `json:"notes,omitempty"`
+
+	// AnnotatedAt is when the annotation was last changed.
+	AnnotatedAt time.Time `json:"annotated_at"`
+}
+
+// ConversationOrigin is the moment in an indexed conversation a checkpoint
+// was saved from.
+type ConversationOrigin struct {
+	SessionID   string    `json:"session_id"`
+	MessageUUID string    `json:"message_uuid"`
+	Timestamp   time.Time `json:"timestamp"`
+
+	// Excerpt is the start of the redacted message.
+	Excerpt string `json:"excerpt,omitempty"`
+}
+
 // SaveRequest represents parameters for saving a checkpoint.
 type SaveRequest struct {
 	SessionID   string
@@ -83,6 +161,10 @@ type SaveRequest struct {
 	Threshold   float64
 	AutoCreated bool
 	Metadata    map[string]string
+
+	// ParentID overrides the parent recorded for the checkpoint. When empty,
+	// the parent is the checkpoint last resumed into or saved by SessionID.
+	ParentID string
 }
 
 // ListRequest represents parameters for listing checkpoints.
@@ -93,7 +175,37 @@ type ListRequest struct {
 	ProjectID   string
 	ProjectPath string
 	Limit       int
-	AutoOnly    bool // Only return auto-created checkpoints
+	AutoOnly    bool    // Only return auto-created checkpoints
+	Outcome     Outcome // Only return checkpoints annotated with this outcome
+	Quarantined bool    // Only return quarantined checkpoints instead of hiding them
+}
+
+// SearchRequest represents parameters for a semantic checkpoint search.
+type SearchRequest struct {
+	Query       string
+	TenantID    string
+	TeamID      string
+	ProjectID   string
+	ProjectPath string
+	Limit       int
+}
+
+// ScoredCheckpoint is a checkpoint with its similarity to a search query.
+type ScoredCheckpoint struct {
+	*Checkpoint
+	Score float64 `json:"score"`
+}
+
+// AnnotateRequest represents parameters for annotating a checkpoint. Empty
+// fields leave the existing annotation value unchanged.
+type AnnotateRequest struct {
+	CheckpointID string
+	TenantID     string
+	TeamID       string
+	ProjectID    string
+	Outcome      Outcome
+	PRURL        string
+	Notes        string
 }
 
 // ResumeRequest represents parameters for resuming from a checkpoint.
@@ -103,6 +215,10 @@ type ResumeRequest struct {
 	TeamID       string
 	ProjectID    string
 	Level        ResumeLevel
+
+	// SessionID is the session resuming the checkpoint. Its next saved
+	// checkpoint records the resumed one as parent. Optional.
+	SessionID string
 }
 
 // ResumeResponse contains the restored checkpoint data.
@@ -111,3 +227,21 @@ type ResumeResponse struct {
 	Content    string // Content based on resume level
 	TokenCount int32
 }
+
+// Lineage is the chain of checkpoints leading to a checkpoint and the tree
+// of every branch descending from the chain's root.
+type Lineage struct {
+	// Ancestors lists the checkpoint's parents, root first, ending with the
+	// checkpoint itself.
+	Ancestors []*Checkpoint `json:"ancestors"`
+
+	// Root is the lineage tree from the oldest ancestor. Children are
+	// ordered by creation time.
+	Root *LineageNode `json:"root"`
+}
+
+// LineageNode is a checkpoint in a lineage tree.
+type LineageNode struct {
+	Checkpoint *Checkpoint    `json:"checkpoint"`
+	Children   []*LineageNode `json:"children,omitempty"`
+}
@@ -15,6 +15,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
@@ -49,9 +51,16 @@ type anthropicResponse struct {
 	Role    string             `json:"role"`
 	Content []anthropicContent `json:"content"`
 	Model   string             `json:"model"`
+	Usage   anthropicUsage     `json:"usage"`
 	Error   *anthropicError    `json:"error,omitempty"`
 }
 
+// anthropicUsage reports the tokens a request consumed
+type anthropicUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
 // anthropicContent represents content in the response
 type anthropicContent struct {
 	Type string `json:"type"`
@@ -72,11 +81,16 @@ type AbstractiveCompressor struct {
 
 // NewAbstractiveCompressor creates a new abstractive compressor
 func NewAbstractiveCompressor(config Config) *AbstractiveCompressor {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	if config.LLMPolicy != nil {
+		// The policy bounds each attempt instead
+		client.Timeout = 0
+	}
 	return &AbstractiveCompressor{
 		config: config,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: client,
 	}
 }
 
@@ -156,8 +170,33 @@ Provide only the compressed version without any explanations or meta-commentary.
 	}, nil
 }
 
-// callClaudeAPI makes a request to the Anthropic Claude API
-func (c *AbstractiveCompressor) callClaudeAPI(ctx context.Context, prompt string) (string, error) {
+// callClaudeAPI makes a request to the Anthropic Claude API under the
+// configured LLM policy
+func (c *AbstractiveCompressor) callClaudeAPI(ctx context.Context, prompt string) (text string, err error) {
+	err = c.config.LLMPolicy.Do(ctx, analytics.FeatureCompression, func(ctx context.Context) error {
+		text, err = c.callClaudeAPIOnce(ctx, prompt)
+		return err
+	})
+	return text, err
+}
+
+// callClaudeAPIOnce makes a single request to the Anthropic Claude API and
+// reports its token usage
+func (c *AbstractiveCompressor) callClaudeAPIOnce(ctx context.Context, prompt string) (text string, err error) {
+	var usage anthropicUsage
+	if c.config.Usage != nil {
+		defer func() {
+			c.config.Usage.RecordUsage(ctx, analytics.LLMUsage{
+				Feature:      analytics.FeatureCompression,
+				Provider:     analytics.ProviderAnthropic,
+				Model:        claudeModel,
+				InputTokens:  usage.InputTokens,
+				OutputTokens: usage.OutputTokens,
+				Failed:       err != nil,
+			})
+		}()
+	}
+
 	// Prepare request
 	reqBody := anthropicRequest{
 		Model:     claudeModel,
@@ -201,7 +240,7 @@ func (c *AbstractiveCompressor) callClaudeAPI(ctx context.Context, prompt string
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return "", resilience.NewStatusError(resp, string(body))
 	}
 
 	// Parse response
@@ -210,6 +249,8 @@ func (c *AbstractiveCompressor) callClaudeAPI(ctx context.Context, prompt string
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	usage = apiResp.Usage
+
 	// Check for API errors
 	if apiResp.Error != nil {
 		return "", fmt.Errorf("API error: %s - %s", apiResp.Error.Type, apiResp.Error.Message)
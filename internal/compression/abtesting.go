@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 // Common errors for A/B testing
@@ -16,7 +18,7 @@ var (
 	ErrInsufficientVariants = errors.New("experiment must have at least 2 variants")
 	ErrInvalidSessionID     = errors.New("session ID cannot be empty")
 	ErrAlgorithmNotInExp    = errors.New("algorithm not in experiment variants")
-	ErrExperimentNotFound   = errors.New("experiment not found")
+	ErrExperimentNotFound   = ctxerrors.NotFound("experiment not found")
 )
 
 // CompressionOutcome represents the result of a single compression operation
@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"regexp"
 	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
 )
 
 // ClaudeClient defines the interface for Claude API interactions
@@ -24,6 +27,8 @@ type HTTPClaudeClient struct {
 	baseURL    string
 	model      string
 	httpClient *http.Client
+	usage      analytics.UsageRecorder
+	policy     *resilience.Policy
 }
 
 // ClaudeRequest represents the request format for Claude API
@@ -89,8 +94,51 @@ func NewClaudeClient(apiKey, baseURL, model string) (*HTTPClaudeClient, error) {
 	}, nil
 }
 
+// SetUsageRecorder reports the token usage of every Summarize call to r.
+func (c *HTTPClaudeClient) SetUsageRecorder(r analytics.UsageRecorder) {
+	c.usage = r
+}
+
+// SetLLMPolicy applies p's timeouts, retries and circuit breaking to every
+// Summarize call. The policy then bounds each attempt instead of the HTTP
+// client timeout.
+func (c *HTTPClaudeClient) SetLLMPolicy(p *resilience.Policy) {
+	c.policy = p
+	if p != nil {
+		c.httpClient.Timeout = 0
+	}
+}
+
+// LLMModel implements analytics.ModelDescriber.
+func (c *HTTPClaudeClient) LLMModel() (provider, model string) {
+	return analytics.ProviderAnthropic, c.model
+}
+
 // Summarize generates an abstractive summary using Claude API
-func (c *HTTPClaudeClient) Summarize(ctx context.Context, content string, targetRatio float64) (string, error) {
+func (c *HTTPClaudeClient) Summarize(ctx context.Context, content string, targetRatio float64) (summary string, err error) {
+	err = c.policy.Do(ctx, analytics.FeatureCompression, func(ctx context.Context) error {
+		summary, err = c.summarize(ctx, content, targetRatio)
+		return err
+	})
+	return summary, err
+}
+
+// summarize makes a single summarization request and reports its token usage
+func (c *HTTPClaudeClient) summarize(ctx context.Context, content string, targetRatio float64) (summary string, err error) {
+	var inputTokens, outputTokens int64
+	if c.usage != nil {
+		defer func() {
+			c.usage.RecordUsage(ctx, analytics.LLMUsage{
+				Feature:      analytics.FeatureCompression,
+				Provider:     analytics.ProviderAnthropic,
+				Model:        c.model,
+				InputTokens:  inputTokens,
+				OutputTokens: outputTokens,
+				Failed:       err != nil,
+			})
+		}()
+	}
+
 	// Scrub secrets before sending to API
 	scrubbedContent := scrubSecrets(content)
 
@@ -131,16 +179,58 @@ Generate ONLY the compressed summary, with no preamble or explanation.`, targetR
 		},
 	}
 
+	claudeResp, err := c.send(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	inputTokens, outputTokens = int64(claudeResp.Usage.InputTokens), int64(claudeResp.Usage.OutputTokens)
+
+	// Extract summary text
+	if len(claudeResp.Content) == 0 {
+		return "", fmt.Errorf("empty response from API")
+	}
+
+	summary = claudeResp.Content[0].Text
+	if summary == "" {
+		return "", fmt.Errorf("empty summary text")
+	}
+
+	return summary, nil
+}
+
+// Complete sends prompt as a single user message and returns the reply,
+// implementing reasoningbank.LLMClient for features that write their own
+// prompts. Unlike Summarize it neither applies the policy nor records
+// usage; callers attribute both to their own feature.
+func (c *HTTPClaudeClient) Complete(ctx context.Context, prompt string) (string, error) {
+	resp, err := c.send(ctx, ClaudeRequest{
+		Model:       c.model,
+		MaxTokens:   4096,
+		Temperature: 0.3,
+		Messages:    []ClaudeMessage{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Content) == 0 || resp.Content[0].Text == "" {
+		return "", fmt.Errorf("empty response from API")
+	}
+	return resp.Content[0].Text, nil
+}
+
+// send makes one Messages API request.
+func (c *HTTPClaudeClient) send(ctx context.Context, req ClaudeRequest) (*ClaudeResponse, error) {
 	// Marshal request
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Create HTTP request
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -151,42 +241,31 @@ Generate ONLY the compressed summary, with no preamble or explanation.`, targetR
 	// Send request
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		return nil, fmt.Errorf("API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		var errResp ClaudeError
 		if err := json.Unmarshal(body, &errResp); err == nil {
-			return "", fmt.Errorf("API error (%d): %s", resp.StatusCode, errResp.Error.Message)
+			return nil, resilience.NewStatusError(resp, errResp.Error.Message)
 		}
-		return "", fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+		return nil, resilience.NewStatusError(resp, string(body))
 	}
 
 	// Parse response
 	var claudeResp ClaudeResponse
 	if err := json.Unmarshal(body, &claudeResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
-
-	// Extract summary text
-	if len(claudeResp.Content) == 0 {
-		return "", fmt.Errorf("empty response from API")
-	}
-
-	summary := claudeResp.Content[0].Text
-	if summary == "" {
-		return "", fmt.Errorf("empty summary text")
-	}
-
-	return summary, nil
+	return &claudeResp, nil
 }
 
 // scrubSecrets removes common secret patterns from content before sending to API
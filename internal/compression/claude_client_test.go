@@ -2,8 +2,13 @@ package compression
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
 )
 
 // MockClaudeClient implements ClaudeClient for testing
@@ -209,3 +214,72 @@ Line 4`,
 		})
 	}
 }
+
+// usageRecorderFunc adapts a function to analytics.UsageRecorder
+type usageRecorderFunc func(ctx context.Context, u analytics.LLMUsage)
+
+func (f usageRecorderFunc) RecordUsage(ctx context.Context, u analytics.LLMUsage) { f(ctx, u) }
+
+// TestHTTPClaudeClient_RecordsUsage tests that token usage is reported
+func TestHTTPClaudeClient_RecordsUsage(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"short"}],"usage":{"input_tokens":120,"output_tokens":30}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClaudeClient("sk-ant-test123", server.URL, "claude-3-haiku-20240307")
+	if err != nil {
+		t.Fatalf("NewClaudeClient() error = %v", err)
+	}
+	var got []analytics.LLMUsage
+	client.SetUsageRecorder(usageRecorderFunc(func(ctx context.Context, u analytics.LLMUsage) {
+		got = append(got, u)
+	}))
+
+	if _, err := client.Summarize(context.Background(), strings.Repeat("content ", 50), 2.0); err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	status = http.StatusInternalServerError
+	if _, err := client.Summarize(context.Background(), "content", 2.0); err == nil {
+		t.Fatal("Summarize() expected error")
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("recorded %d usages, want 2", len(got))
+	}
+	if got[0].Feature != analytics.FeatureCompression || got[0].Model != "claude-3-haiku-20240307" ||
+		got[0].InputTokens != 120 || got[0].OutputTokens != 30 || got[0].Failed {
+		t.Errorf("first usage = %+v", got[0])
+	}
+	if !got[1].Failed {
+		t.Errorf("second usage should be marked failed: %+v", got[1])
+	}
+}
+
+// TestHTTPClaudeClient_Complete tests that prompts are sent as is
+func TestHTTPClaudeClient_Complete(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"# Digest"}],"usage":{"input_tokens":12,"output_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClaudeClient("sk-ant-test123", server.URL, "")
+	if err != nil {
+		t.Fatalf("NewClaudeClient() error = %v", err)
+	}
+	got, err := client.Complete(context.Background(), "Write the digest")
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != "# Digest" {
+		t.Errorf("Complete() = %q", got)
+	}
+	if !strings.Contains(body, `"content":"Write the digest"`) || strings.Contains(body, `"system"`) {
+		t.Errorf("request body = %s", body)
+	}
+}
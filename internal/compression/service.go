@@ -2,6 +2,7 @@ package compression
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -11,6 +12,9 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
 )
 
 const tracerName = "github.com/fyrsmithlabs/contextd/internal/compression"
@@ -104,6 +108,17 @@ func (s *Service) Compress(ctx context.Context, content string, algorithm Algori
 			algorithm, AlgorithmExtractive, AlgorithmAbstractive, AlgorithmHybrid)
 	}
 
+	// Without LLM budget, degrade to extractive compression
+	var warnings []string
+	if algorithm != AlgorithmExtractive && s.config.Budget != nil {
+		if err := s.config.Budget.Allow(ctx, analytics.FeatureCompression); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s compression fell back to extractive: %v", algorithm, err))
+			span.SetAttributes(attribute.Bool("degraded", true))
+			algorithm = AlgorithmExtractive
+			compressor = s.extractive
+		}
+	}
+
 	// Check capabilities
 	caps := compressor.GetCapabilities(ctx)
 	if len(content) > caps.MaxContentLength {
@@ -111,8 +126,15 @@ func (s *Service) Compress(ctx context.Context, content string, algorithm Algori
 			len(content), caps.MaxContentLength, algorithm)
 	}
 
-	// Perform compression
+	// Perform compression, degrading to extractive while the LLM circuit
+	// breaker is open
 	result, err := compressor.Compress(ctx, content, algorithm, targetRatio)
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		warnings = append(warnings, fmt.Sprintf("%s compression fell back to extractive: %v", algorithm, err))
+		span.SetAttributes(attribute.Bool("degraded", true))
+		algorithm = AlgorithmExtractive
+		result, err = s.extractive.Compress(ctx, content, algorithm, targetRatio)
+	}
 	if err != nil {
 		span.RecordError(err)
 		s.compressionErrors.Add(ctx, 1,
@@ -123,6 +145,7 @@ func (s *Service) Compress(ctx context.Context, content string, algorithm Algori
 		)
 		return nil, fmt.Errorf("compression failed: %w", err)
 	}
+	result.Warnings = append(result.Warnings, warnings...)
 
 	// Record metrics
 	processingTime := float64(time.Since(start).Milliseconds()) / 1000.0 // Convert to seconds
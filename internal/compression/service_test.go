@@ -2,12 +2,17 @@ package compression
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
 )
 
 func TestService_Compress_Extractive(t *testing.T) {
@@ -95,6 +100,71 @@ func TestService_Compress_Hybrid(t *testing.T) {
 	assert.True(t, result.QualityScore >= 0.0 && result.QualityScore <= 1.0)
 }
 
+type budgetFunc func(ctx context.Context, feature string) error
+
+func (f budgetFunc) Allow(ctx context.Context, feature string) error { return f(ctx, feature) }
+
+func TestService_Compress_BudgetExhausted(t *testing.T) {
+	var checked []string
+	config := Config{
+		DefaultAlgorithm:  AlgorithmAbstractive,
+		TargetRatio:       2.0,
+		QualityThreshold:  0.3,
+		MaxProcessingTime: time.Second * 5,
+		Budget: budgetFunc(func(ctx context.Context, feature string) error {
+			checked = append(checked, feature)
+			return fmt.Errorf("%w: tenant \"acme\" used 10 of 10 tokens this month", analytics.ErrBudgetExhausted)
+		}),
+	}
+
+	service, err := NewService(config)
+	require.NoError(t, err)
+
+	content := "This is a test document. It contains multiple sentences. Each sentence has some content. The compression algorithm should work on this text."
+
+	for _, algorithm := range []Algorithm{AlgorithmAbstractive, AlgorithmHybrid} {
+		result, err := service.Compress(context.Background(), content, algorithm, 2.0)
+		require.NoError(t, err)
+		assert.Equal(t, AlgorithmExtractive, Algorithm(result.Metadata.Algorithm))
+		require.Len(t, result.Warnings, 1)
+		assert.Contains(t, result.Warnings[0], "fell back to extractive")
+	}
+
+	result, err := service.Compress(context.Background(), content, AlgorithmExtractive, 2.0)
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+	assert.Equal(t, []string{"compression", "compression"}, checked, "extractive does not consult the budget")
+}
+
+func TestService_Compress_CircuitOpen(t *testing.T) {
+	policy, err := resilience.New(resilience.Config{MaxRetries: -1, FailureThreshold: 1}, zap.NewNop())
+	require.NoError(t, err)
+
+	// Open the compression breaker
+	_ = policy.Do(context.Background(), analytics.FeatureCompression, func(context.Context) error {
+		return &resilience.StatusError{StatusCode: 503, Message: "overloaded"}
+	})
+	require.Equal(t, resilience.StateOpen, policy.State(analytics.FeatureCompression))
+
+	service, err := NewService(Config{
+		DefaultAlgorithm:  AlgorithmAbstractive,
+		TargetRatio:       2.0,
+		QualityThreshold:  0.3,
+		MaxProcessingTime: time.Second * 5,
+		AnthropicAPIKey:   "sk-ant-test",
+		LLMPolicy:         policy,
+	})
+	require.NoError(t, err)
+
+	content := "This is a test document. It contains multiple sentences. Each sentence has some content. The compression algorithm should work on this text."
+
+	result, err := service.Compress(context.Background(), content, AlgorithmAbstractive, 2.0)
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmExtractive, Algorithm(result.Metadata.Algorithm))
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "circuit breaker open")
+}
+
 func TestService_Compress_Validation(t *testing.T) {
 	config := Config{}
 	service, err := NewService(config)
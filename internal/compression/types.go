@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
@@ -41,6 +43,10 @@ type Result struct {
 
 	// Quality score (0.0 to 1.0, higher is better)
 	QualityScore float64
+
+	// Warnings explain degraded results, such as an extractive fallback
+	// when the LLM budget is exhausted
+	Warnings []string
 }
 
 // Capabilities describes what a compressor can do
@@ -77,4 +83,17 @@ type Config struct {
 
 	// Anthropic API key for abstractive compression
 	AnthropicAPIKey string
+
+	// Usage receives token counts of abstractive compression requests.
+	// Optional.
+	Usage analytics.UsageRecorder
+
+	// Budget is checked before abstractive and hybrid compression; when the
+	// tenant's budget is exhausted they fall back to extractive. Optional.
+	Budget analytics.LLMBudget
+
+	// LLMPolicy applies timeouts, retries and circuit breaking to Claude API
+	// requests. While the compression breaker is open, abstractive and
+	// hybrid compression fall back to extractive. Optional.
+	LLMPolicy *resilience.Policy
 }
@@ -18,6 +18,10 @@ import (
 
 // Config holds the complete contextd v2 configuration.
 type Config struct {
+	// Profile names the preset applied over the built-in defaults: "solo",
+	// "team" or "enterprise" (see ApplyProfile). Empty applies none.
+	Profile string `koanf:"profile"`
+
 	Production             ProductionConfig
 	Server                 ServerConfig
 	Observability          ObservabilityConfig
@@ -26,9 +30,19 @@ type Config struct {
 	VectorStore            VectorStoreConfig
 	Qdrant                 QdrantConfig
 	Embeddings             EmbeddingsConfig
+	LLM                    LLMConfig
 	Repository             RepositoryConfig
 	Statusline             StatuslineConfig
 	ConsolidationScheduler ConsolidationSchedulerConfig
+	MemoryPruning          MemoryPruningConfig
+	Digest                 DigestConfig
+	StorageMonitor         StorageMonitorConfig
+	Analytics              AnalyticsConfig
+	Workflows              WorkflowsConfig
+	Notifications          NotificationsConfig
+	Conversations          ConversationsConfig
+	Remediations           RemediationsConfig
+	Folding                FoldingConfig
 	ReasoningBank          ReasoningBankConfig
 	Fallback               FallbackConfig
 }
@@ -67,12 +81,42 @@ type RepositoryConfig struct {
 	// FallbackExcludes are used when no ignore files are found in the project.
 	// Default: [".git/**", "node_modules/**", "vendor/**", "__pycache__/**"]
 	FallbackExcludes []string `koanf:"fallback_excludes"`
+
+	// Preprocessors transform matching files before they are embedded, e.g.
+	// {pattern: "*.ipynb", use: [render_notebook]}. Set in the config file.
+	Preprocessors []RepositoryPreprocessorConfig `koanf:"preprocessors"`
+}
+
+// RepositoryPreprocessorConfig runs preprocessors on the files matching a
+// glob pattern.
+type RepositoryPreprocessorConfig struct {
+	Pattern string   `koanf:"pattern"` // Glob matched against the file name or repository-relative path
+	Use     []string `koanf:"use"`     // Preprocessors to run, in order: strip_license_header, render_notebook, expand_terraform_modules
+}
+
+// Validate checks that every preprocessor rule has a valid pattern and
+// names at least one preprocessor. Names are checked when the repository
+// service starts, since preprocessors can be registered by name.
+func (r *RepositoryConfig) Validate() error {
+	for i, p := range r.Preprocessors {
+		if p.Pattern == "" {
+			return fmt.Errorf("preprocessor %d: pattern is required", i+1)
+		}
+		if _, err := filepath.Match(p.Pattern, "test"); err != nil {
+			return fmt.Errorf("preprocessor %d: invalid pattern %q: %w", i+1, p.Pattern, err)
+		}
+		if len(p.Use) == 0 {
+			return fmt.Errorf("preprocessor %d (%s): use must name at least one preprocessor", i+1, p.Pattern)
+		}
+	}
+	return nil
 }
 
 // VectorStoreConfig holds vectorstore provider configuration.
 type VectorStoreConfig struct {
-	Provider string         `koanf:"provider"` // "chromem" or "qdrant" (default: "chromem")
+	Provider string         `koanf:"provider"` // "chromem", "qdrant" or "pgvector" (default: "chromem")
 	Chromem  ChromemConfig  `koanf:"chromem"`
+	Pgvector PgvectorConfig `koanf:"pgvector"`
 	Fallback FallbackConfig `koanf:"fallback"`
 }
 
@@ -84,8 +128,10 @@ func (c *VectorStoreConfig) Validate() error {
 	case "qdrant":
 		// Qdrant validation handled elsewhere
 		return nil
+	case "pgvector":
+		return c.Pgvector.Validate()
 	default:
-		return fmt.Errorf("unsupported provider: %s (supported: chromem, qdrant)", c.Provider)
+		return fmt.Errorf("unsupported provider: %s (supported: chromem, qdrant, pgvector)", c.Provider)
 	}
 }
 
@@ -108,6 +154,60 @@ type ChromemConfig struct {
 	// Must match the embedder's output dimension.
 	// Default: 384 (for FastEmbed bge-small-en-v1.5)
 	VectorSize int `koanf:"vector_size"`
+
+	// LazyLoad loads each persisted collection on first access instead of
+	// all of them at startup.
+	// Default: false
+	LazyLoad bool `koanf:"lazy_load"`
+
+	// Warmup lists collections loaded in the background after a lazy
+	// startup. Ignored unless LazyLoad is set.
+	Warmup []string `koanf:"warmup"`
+
+	// MmapVectors keeps loaded embeddings in memory maps outside the Go heap.
+	// Default: false
+	MmapVectors bool `koanf:"mmap_vectors"`
+}
+
+// PgvectorConfig holds configuration for the Postgres pgvector store.
+type PgvectorConfig struct {
+	// DSN is the Postgres connection string. Set search_path in it to keep
+	// contextd's tables in their own schema.
+	DSN Secret `koanf:"dsn"`
+
+	// DefaultCollection is the default collection name.
+	// Default: "contextd_default"
+	DefaultCollection string `koanf:"default_collection"`
+
+	// VectorSize is the embedding dimension the schema is created with.
+	// Must match the embedder's output dimension.
+	// Default: 384 (for FastEmbed bge-small-en-v1.5)
+	VectorSize int `koanf:"vector_size"`
+
+	// BatchSize is the number of documents per upsert round trip.
+	// Default: 500
+	BatchSize int `koanf:"batch_size"`
+
+	// EfSearch is the HNSW candidate list size at query time.
+	// Default: 100
+	EfSearch int `koanf:"ef_search"`
+}
+
+// Validate validates PgvectorConfig.
+func (c *PgvectorConfig) Validate() error {
+	if !c.DSN.IsSet() {
+		return fmt.Errorf("pgvector dsn is required")
+	}
+	if c.VectorSize <= 0 {
+		return fmt.Errorf("vector_size must be positive, got %d", c.VectorSize)
+	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("batch_size must not be negative, got %d", c.BatchSize)
+	}
+	if c.EfSearch < 0 || c.EfSearch > 1000 {
+		return fmt.Errorf("ef_search must be between 0 and 1000, got %d", c.EfSearch)
+	}
+	return nil
 }
 
 // FallbackConfig holds configuration for fallback storage.
@@ -149,6 +249,12 @@ type QdrantConfig struct {
 	CollectionName string `koanf:"collection_name"`
 	VectorSize     uint64 `koanf:"vector_size"`
 	DataPath       string `koanf:"data_path"`
+
+	// HNSW index tuning; zero values keep Qdrant's defaults
+	HNSWM           int  `koanf:"hnsw_m"`
+	HNSWEfConstruct int  `koanf:"hnsw_ef_construct"`
+	HNSWEfSearch    int  `koanf:"hnsw_ef_search"`
+	HNSWOnDisk      bool `koanf:"hnsw_on_disk"`
 }
 
 // EmbeddingsConfig holds embeddings service configuration.
@@ -158,11 +264,104 @@ type EmbeddingsConfig struct {
 	Model       string `koanf:"model"`
 	CacheDir    string `koanf:"cache_dir"`    // Model cache directory (for fastembed)
 	ONNXVersion string `koanf:"onnx_version"` // Optional ONNX runtime version override
+	APIKey      Secret `koanf:"api_key"`      // Bearer token for hosted TEI endpoints
+}
+
+// LLMConfig holds credentials for hosted LLM providers, the monthly token
+// budget of the features that call them and the timeout, retry and circuit
+// breaker policy of their requests. Keys may be secret references (env:,
+// file:, keychain:, vault:) so they stay out of config.yaml.
+type LLMConfig struct {
+	AnthropicAPIKey Secret `koanf:"anthropic_api_key"` // Abstractive compression; unset disables it
+
+	// MonthlyTokenBudget caps the LLM tokens each tenant may use per UTC
+	// month (0 = unlimited). Once exhausted, abstractive compression falls
+	// back to extractive, consolidation pauses and troubleshooting runs
+	// pattern-only. Requires analytics, which records the usage.
+	MonthlyTokenBudget int64 `koanf:"monthly_token_budget"`
+
+	// TenantTokenBudgets overrides MonthlyTokenBudget per tenant ID
+	// (0 = unlimited for that tenant).
+	TenantTokenBudgets map[string]int64 `koanf:"tenant_token_budgets"`
+
+	// Timeout bounds each LLM request attempt (default: 60s).
+	Timeout time.Duration `koanf:"timeout"`
+
+	// MaxRetries is how often a request failing with 429, 5xx or a timeout
+	// is retried, with jittered exponential backoff (0 = no retries).
+	MaxRetries int `koanf:"max_retries"`
+
+	// CircuitFailureThreshold is how many requests of one feature must fail
+	// in a row before its circuit breaker opens and the feature degrades as
+	// if its budget were exhausted.
+	CircuitFailureThreshold int `koanf:"circuit_failure_threshold"`
+
+	// CircuitCooldown is how long an open breaker stays open before a
+	// single probe request is let through.
+	CircuitCooldown time.Duration `koanf:"circuit_cooldown"`
+}
+
+// Validate checks the LLM budget and resilience settings.
+func (c *LLMConfig) Validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", c.Timeout)
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative, got %d", c.MaxRetries)
+	}
+	if c.CircuitFailureThreshold < 0 {
+		return fmt.Errorf("circuit_failure_threshold must not be negative, got %d", c.CircuitFailureThreshold)
+	}
+	if c.CircuitCooldown < 0 {
+		return fmt.Errorf("circuit_cooldown must not be negative, got %s", c.CircuitCooldown)
+	}
+	if c.MonthlyTokenBudget < 0 {
+		return fmt.Errorf("monthly_token_budget must not be negative, got %d", c.MonthlyTokenBudget)
+	}
+	for tenant, budget := range c.TenantTokenBudgets {
+		if budget < 0 {
+			return fmt.Errorf("tenant_token_budgets[%s] must not be negative, got %d", tenant, budget)
+		}
+	}
+	return nil
+}
+
+// BudgetEnabled reports whether any tenant has a token budget.
+func (c *LLMConfig) BudgetEnabled() bool {
+	if c.MonthlyTokenBudget > 0 {
+		return true
+	}
+	for _, budget := range c.TenantTokenBudgets {
+		if budget > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // CheckpointConfig holds checkpoint service configuration.
 type CheckpointConfig struct {
 	MaxContentSizeKB int `koanf:"max_content_size_kb"` // Maximum content size in KB (default: 1024 = 1MB)
+
+	// StateStore is where the full state of new checkpoints is kept:
+	// vectorstore (default), filesystem or sqlite. Outside the vectorstore
+	// only the summary and context are embedded and stored there.
+	StateStore string `koanf:"state_store"`
+
+	// StatePath is the directory (filesystem) or database file (sqlite) of
+	// the state store. Default: ~/.config/contextd/checkpoint-state, with
+	// a .db extension for sqlite.
+	StatePath string `koanf:"state_path"`
+}
+
+// Validate checks the state store.
+func (c *CheckpointConfig) Validate() error {
+	switch c.StateStore {
+	case "", "vectorstore", "filesystem", "sqlite":
+		return nil
+	default:
+		return fmt.Errorf("CHECKPOINT_STATE_STORE must be vectorstore, filesystem or sqlite, got %q", c.StateStore)
+	}
 }
 
 // ReasoningBankConfig holds memory granularity and buffering configuration.
@@ -175,29 +374,465 @@ type ReasoningBankConfig struct {
 	// MaxBufferedTurns is the maximum number of turns to buffer per session.
 	// When exceeded, oldest turns are dropped. Default: 500.
 	MaxBufferedTurns int `koanf:"max_buffered_turns"`
+
+	// RecordQuality is how strictly memory_record rejects low-quality
+	// memories: "off", "lenient", "standard" (default) or "strict".
+	RecordQuality string `koanf:"record_quality"`
+
+	// WriteBatchInterval batches feedback and outcome updates, writing
+	// each memory at most once per interval instead of on every event. Zero (default) writes every update immediately.
+	// WriteBatchSize flushes early once that many memories are pending
+	// (default: 100).
+	WriteBatchInterval time.Duration `koanf:"write_batch_interval"`
+	WriteBatchSize     int           `koanf:"write_batch_size"`
+
+	// DisableDuplicateGuard records every memory, even one repeating a
+	// memory the same requester recorded minutes before. By default such
+	// duplicates are rejected: DuplicateWindow is how long a memory
+	// suppresses its duplicates (default: 10m), DuplicateThreshold the word
+	// overlap that makes a duplicate (default: 0.85) and DuplicateBurst the
+	// suppressed duplicates that raise a memory.duplicate_burst warning
+	// (default: 3).
+	DisableDuplicateGuard bool          `koanf:"disable_duplicate_guard"`
+	DuplicateWindow       time.Duration `koanf:"duplicate_window"`
+	DuplicateThreshold    float64       `koanf:"duplicate_threshold"`
+	DuplicateBurst        int           `koanf:"duplicate_burst"`
+
+	// Experiment compares two search rankings on live sessions. Configured
+	// in config.yaml only; an empty name disables it.
+	Experiment SearchExperimentConfig `koanf:"experiment"`
+
+	// Scoring weighs the signals that rank memory search results.
+	Scoring SearchScoringConfig `koanf:"scoring"`
+}
+
+// SearchScoringConfig weighs the signals that rank memory search results.
+// A result's base score is the weighted sum of its similarity to the query,
+// its confidence and its recency, which halves every RecencyHalfLife; the
+// boosts then multiply it. All weights zero means similarity alone.
+type SearchScoringConfig struct {
+	SimilarityWeight   float64            `koanf:"similarity_weight"`   // default: 1
+	ConfidenceWeight   float64            `koanf:"confidence_weight"`   // default: 0
+	RecencyWeight      float64            `koanf:"recency_weight"`      // default: 0
+	RecencyHalfLife    time.Duration      `koanf:"recency_half_life"`   // default: 720h
+	ConsolidationBoost float64            `koanf:"consolidation_boost"` // Consolidated memory multiplier (default: 1.2)
+	CategoryBoosts     map[string]float64 `koanf:"category_boosts"`     // Multiplier per memory category; config file only
+}
+
+// Validate checks the scoring weights and boosts. Category names in
+// category_boosts are checked by reasoningbank.ScoringConfig.Validate when
+// the memory service starts, which owns the category list.
+func (c *SearchScoringConfig) Validate() error {
+	if c.SimilarityWeight < 0 || c.ConfidenceWeight < 0 || c.RecencyWeight < 0 {
+		return errors.New("weights must not be negative")
+	}
+	if c.RecencyHalfLife < 0 {
+		return fmt.Errorf("recency_half_life must not be negative, got %s", c.RecencyHalfLife)
+	}
+	if c.ConsolidationBoost < 0 {
+		return fmt.Errorf("consolidation_boost must not be negative, got %g", c.ConsolidationBoost)
+	}
+	for category, boost := range c.CategoryBoosts {
+		if boost <= 0 {
+			return fmt.Errorf("category_boosts.%s must be positive, got %g", category, boost)
+		}
+	}
+	return nil
+}
+
+// SearchExperimentConfig assigns each memory_search session to one of two
+// ranking arms and reports outcomes per arm.
+type SearchExperimentConfig struct {
+	Name             string              `koanf:"name"`              // Experiment name; renaming reshuffles sessions
+	TreatmentPercent int                 `koanf:"treatment_percent"` // Share of sessions served treatment (default: 50)
+	Control          SearchRankingConfig `koanf:"control"`
+	Treatment        SearchRankingConfig `koanf:"treatment"`
+}
+
+// SearchRankingConfig is the ranking one experiment arm serves. Zero boosts
+// keep the defaults; 1 turns a boost off.
+type SearchRankingConfig struct {
+	Rerank             bool    `koanf:"rerank"`              // Rerank results (default: false)
+	ConsolidationBoost float64 `koanf:"consolidation_boost"` // Consolidated memory multiplier (default: 1.2)
+	EntityBoost        float64 `koanf:"entity_boost"`        // Query entity match multiplier (default: 1.3)
+}
+
+// Enabled reports whether an experiment is configured.
+func (c *SearchExperimentConfig) Enabled() bool {
+	return c.Name != ""
+}
+
+// Validate checks the experiment settings.
+func (c *SearchExperimentConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.TreatmentPercent < 0 || c.TreatmentPercent > 100 {
+		return fmt.Errorf("treatment_percent must be between 0 and 100, got %d", c.TreatmentPercent)
+	}
+	for arm, r := range map[string]SearchRankingConfig{"control": c.Control, "treatment": c.Treatment} {
+		if r.ConsolidationBoost < 0 || r.EntityBoost < 0 {
+			return fmt.Errorf("%s boosts must not be negative", arm)
+		}
+	}
+	return nil
 }
 
 // ConsolidationSchedulerConfig holds automatic memory consolidation configuration.
 type ConsolidationSchedulerConfig struct {
 	Enabled             bool          `koanf:"enabled"`              // Enable automatic consolidation (default: false)
 	Interval            time.Duration `koanf:"interval"`             // Time between consolidation runs (default: 24h)
-	SimilarityThreshold float64       `koanf:"similarity_threshold"` // Similarity threshold for consolidation (default: 0.8)
+	SimilarityThreshold float64       `koanf:"similarity_threshold"` // Similarity threshold for projects without a tuned one (default: 0.8)
+}
+
+// MemoryPruningConfig holds automatic memory pruning configuration.
+// Zero-valued limits disable the corresponding rule.
+type MemoryPruningConfig struct {
+	Enabled       bool          `koanf:"enabled"`        // Enable scheduled pruning (default: false)
+	Interval      time.Duration `koanf:"interval"`       // Time between pruning runs (default: 24h)
+	ProjectIDs    []string      `koanf:"project_ids"`    // Projects to prune
+	MaxMemories   int           `koanf:"max_memories"`   // Max active memories per project (default: 0, unlimited)
+	MinConfidence float64       `koanf:"min_confidence"` // Confidence floor (default: 0, disabled)
+	MaxUnusedAge  time.Duration `koanf:"max_unused_age"` // Max age for never-used memories (default: 0, disabled)
+	Action        string        `koanf:"action"`         // "archive" or "delete" (default: archive)
+	DryRun        bool          `koanf:"dry_run"`        // Log the report without pruning (default: false)
+}
+
+// DigestConfig holds scheduled knowledge digest configuration. Each run
+// summarizes the memories and remediations of the interval just ended,
+// written by the LLM when an Anthropic API key is set, into a markdown file
+// in Dir and a digest.ready notification.
+type DigestConfig struct {
+	Enabled       bool          `koanf:"enabled"`        // Generate scheduled digests (default: false)
+	Interval      time.Duration `koanf:"interval"`       // Time between digests and the period each covers (default: 168h)
+	TenantID      string        `koanf:"tenant_id"`      // Tenant to digest (default: the default tenant)
+	TeamID        string        `koanf:"team_id"`        // Team whose remediations are included; org-wide only when empty
+	ProjectIDs    []string      `koanf:"project_ids"`    // Projects whose memories are included
+	MinConfidence float64       `koanf:"min_confidence"` // Confidence floor for included items (default: 0.7)
+	Dir           string        `koanf:"dir"`            // Digest directory (default: ~/.config/contextd/digests)
+}
+
+// Validate checks the digest interval and confidence floor.
+func (d *DigestConfig) Validate() error {
+	if d.Interval < 0 {
+		return fmt.Errorf("interval must be non-negative, got %s", d.Interval)
+	}
+	if d.MinConfidence < 0 || d.MinConfidence > 1 {
+		return fmt.Errorf("min_confidence must be between 0 and 1, got %g", d.MinConfidence)
+	}
+	return nil
+}
+
+// StorageMonitorConfig holds disk space guardrail configuration. When free
+// space on the vectorstore volume crosses CriticalPercent or MinFreeMB, the
+// server switches to read-only mode until space is freed.
+type StorageMonitorConfig struct {
+	Enabled         bool          `koanf:"enabled"`          // Watch the chromem data path (default: true)
+	Interval        time.Duration `koanf:"interval"`         // Time between checks (default: 30s)
+	WarnPercent     float64       `koanf:"warn_percent"`     // Log a warning at this usage (default: 85)
+	CriticalPercent float64       `koanf:"critical_percent"` // Block writes at this usage (default: 95)
+	MinFreeMB       int           `koanf:"min_free_mb"`      // Block writes below this free space (default: 512)
+}
+
+// AnalyticsConfig holds MCP tool usage analytics configuration. Per-tool call
+// counts, latencies, result sizes and errors are rolled up per day and
+// served by GET /api/v1/stats/tools and `ctxd stats tools`. LLM tokens and
+// estimated cost are rolled up alongside them and served by
+// GET /api/v1/stats/usage and `ctxd usage`.
+type AnalyticsConfig struct {
+	Enabled       bool          `koanf:"enabled"`        // Record tool and LLM usage (default: true)
+	Dir           string        `koanf:"dir"`            // Rollup directory (default: ~/.config/contextd/analytics)
+	FlushInterval time.Duration `koanf:"flush_interval"` // Time between rollup writes (default: 1m)
+	RetentionDays int           `koanf:"retention_days"` // Days of rollups to keep (default: 90)
+
+	// SessionRetentionDays is how many days of session journals, which keep
+	// the scrubbed queries and result IDs of each session's tool calls for
+	// ctxd replay, are kept (default: 14).
+	SessionRetentionDays int `koanf:"session_retention_days"`
+}
+
+// WorkflowsConfig holds Temporal configuration for the repository indexing,
+// memory consolidation and CI remediation workflows. When enabled, contextd
+// runs a worker for them and the HTTP API can start them.
+type WorkflowsConfig struct {
+	Enabled      bool   `koanf:"enabled"`       // Run the worker and accept workflow requests (default: false)
+	TemporalHost string `koanf:"temporal_host"` // Temporal frontend address (default: localhost:7233)
+	Namespace    string `koanf:"namespace"`     // Temporal namespace (default: default)
+	TaskQueue    string `koanf:"task_queue"`    // Task queue to poll (default: contextd-workflows)
+	GitHubToken  Secret `koanf:"github_token"`  // Reads CI job logs; CI remediation is off when unset
+}
+
+// NotificationsConfig routes notable knowledge events to Slack or Matrix:
+// org-scope or high-confidence remediations, consolidation runs that merged
+// memories, and finished reflection reports.
+type NotificationsConfig struct {
+	Enabled         bool                                 `koanf:"enabled"`           // Send notifications (default: false)
+	SlackWebhookURL Secret                               `koanf:"slack_webhook_url"` // Shorthand for a Slack channel named "slack"
+	Channels        map[string]NotificationChannelConfig `koanf:"channels"`          // Destinations by name
+	Routes          []NotificationRouteConfig            `koanf:"routes"`            // Which events go where; all events go everywhere when empty
+}
+
+// NotificationChannelConfig is a Slack incoming webhook or a Matrix room.
+type NotificationChannelConfig struct {
+	Type        string `koanf:"type"`         // "slack" or "matrix"
+	WebhookURL  Secret `koanf:"webhook_url"`  // Slack incoming webhook URL
+	Homeserver  string `koanf:"homeserver"`   // Matrix homeserver URL
+	RoomID      string `koanf:"room_id"`      // Matrix room ID, e.g. !abc:example.org
+	AccessToken Secret `koanf:"access_token"` // Matrix access token of the posting user
+}
+
+// NotificationRouteConfig sends matching events to channels. Empty filters
+// match everything.
+type NotificationRouteConfig struct {
+	Channels      []string `koanf:"channels"`       // Channel names
+	Events        []string `koanf:"events"`         // Event types, e.g. remediation.recorded
+	Tenants       []string `koanf:"tenants"`        // Tenant IDs
+	Projects      []string `koanf:"projects"`       // Project IDs
+	Scopes        []string `koanf:"scopes"`         // Remediation scopes
+	MinConfidence float64  `koanf:"min_confidence"` // Minimum remediation confidence
+}
+
+// Validate checks that channels are complete and routes name known
+// channels. Secret URLs are never included in errors.
+func (n *NotificationsConfig) Validate() error {
+	if !n.SlackWebhookURL.IsSet() && len(n.Channels) == 0 {
+		return errors.New("no channels configured")
+	}
+	if n.SlackWebhookURL.IsSet() && !isHTTPURL(n.SlackWebhookURL.Value()) {
+		return errors.New("slack_webhook_url must be an http(s) URL")
+	}
+	for name, ch := range n.Channels {
+		switch ch.Type {
+		case "slack":
+			if !isHTTPURL(ch.WebhookURL.Value()) {
+				return fmt.Errorf("channel %q: webhook_url must be an http(s) URL", name)
+			}
+		case "matrix":
+			if !isHTTPURL(ch.Homeserver) {
+				return fmt.Errorf("channel %q: homeserver must be an http(s) URL", name)
+			}
+			if !strings.HasPrefix(ch.RoomID, "!") || !strings.Contains(ch.RoomID, ":") {
+				return fmt.Errorf("channel %q: room_id must look like !room:server", name)
+			}
+			if !ch.AccessToken.IsSet() {
+				return fmt.Errorf("channel %q: access_token is required", name)
+			}
+		default:
+			return fmt.Errorf("channel %q: type must be 'slack' or 'matrix', got %q", name, ch.Type)
+		}
+	}
+	for i, route := range n.Routes {
+		if len(route.Channels) == 0 {
+			return fmt.Errorf("route %d: channels is required", i+1)
+		}
+		for _, name := range route.Channels {
+			_, ok := n.Channels[name]
+			if !ok && !(name == "slack" && n.SlackWebhookURL.IsSet()) {
+				return fmt.Errorf("route %d: unknown channel %q", i+1, name)
+			}
+		}
+		if route.MinConfidence < 0 || route.MinConfidence > 1 {
+			return fmt.Errorf("route %d: min_confidence must be between 0 and 1", i+1)
+		}
+	}
+	return nil
+}
+
+// ConversationsConfig selects how much of indexed conversation transcripts
+// is redacted. The "standard" profile scrubs secrets; "strict" also replaces
+// paths outside the project, usernames and internal hostnames.
+type ConversationsConfig struct {
+	Redaction       string            `koanf:"redaction"`        // Default profile: standard or strict (default: standard)
+	TenantRedaction map[string]string `koanf:"tenant_redaction"` // Profile by tenant ID, overriding the default
+	InternalDomains []string          `koanf:"internal_domains"` // Domains whose hosts strict redaction replaces
+
+	DomainClassifier string                     `koanf:"domain_classifier"` // How indexed messages get a domain: embedding or off (default: embedding)
+	Domains          []ConversationDomainConfig `koanf:"domains"`           // Domain vocabulary (default: frontend, infra, database, auth)
+	DomainThreshold  float64                    `koanf:"domain_threshold"`  // Minimum similarity to a domain to be tagged with it (default: 0.5)
+}
+
+// ConversationDomainConfig is one domain conversations are tagged with.
+type ConversationDomainConfig struct {
+	Name        string `koanf:"name"`        // Domain name, as filtered on by conversation_search
+	Description string `koanf:"description"` // Topics the domain covers, compared to messages
+}
+
+// Validate checks that every redaction profile is known and that the domain
+// vocabulary is well-formed.
+func (c *ConversationsConfig) Validate() error {
+	if !isRedactionProfile(c.Redaction) {
+		return fmt.Errorf("redaction must be 'standard' or 'strict', got %q", c.Redaction)
+	}
+	for tenant, profile := range c.TenantRedaction {
+		if !isRedactionProfile(profile) {
+			return fmt.Errorf("tenant_redaction %q: must be 'standard' or 'strict', got %q", tenant, profile)
+		}
+	}
+	switch strings.ToLower(c.DomainClassifier) {
+	case "", "embedding", "off":
+	default:
+		return fmt.Errorf("domain_classifier must be 'embedding' or 'off', got %q", c.DomainClassifier)
+	}
+	if c.DomainThreshold < 0 || c.DomainThreshold > 1 {
+		return fmt.Errorf("domain_threshold must be between 0 and 1, got %v", c.DomainThreshold)
+	}
+	seen := make(map[string]bool, len(c.Domains))
+	for i, d := range c.Domains {
+		if d.Name == "" {
+			return fmt.Errorf("domains[%d]: name is required", i)
+		}
+		if seen[d.Name] {
+			return fmt.Errorf("domains[%d]: %q is defined twice", i, d.Name)
+		}
+		seen[d.Name] = true
+	}
+	return nil
+}
+
+func isRedactionProfile(s string) bool {
+	switch strings.ToLower(s) {
+	case "", "standard", "strict":
+		return true
+	default:
+		return false
+	}
+}
+
+// RemediationsConfig holds the remediation category taxonomies of
+// organizations. Tenants without a taxonomy record any category.
+type RemediationsConfig struct {
+	Taxonomies map[string][]RemediationCategoryConfig `koanf:"taxonomies"` // Categories by tenant ID
+}
+
+// RemediationCategoryConfig is one category of an organization's taxonomy.
+type RemediationCategoryConfig struct {
+	Name        string   `koanf:"name"`        // Category name
+	Builtin     string   `koanf:"builtin"`     // Built-in category it refines: compile, runtime, test, lint, security, performance or other
+	Description string   `koanf:"description"` // When to use the category
+	Aliases     []string `koanf:"aliases"`     // Former names, recorded and searched as this category
+}
+
+// Validate checks that every category is named once and refines a built-in
+// category.
+func (r *RemediationsConfig) Validate() error {
+	for tenant, categories := range r.Taxonomies {
+		seen := make(map[string]string)
+		for i, c := range categories {
+			name := strings.ToLower(strings.TrimSpace(c.Name))
+			if name == "" {
+				return fmt.Errorf("taxonomy %q: category %d: name is required", tenant, i+1)
+			}
+			builtin := strings.ToLower(strings.TrimSpace(c.Builtin))
+			if !isBuiltinRemediationCategory(builtin) {
+				return fmt.Errorf("taxonomy %q: category %q: builtin must be compile, runtime, test, lint, security, performance or other, got %q", tenant, name, c.Builtin)
+			}
+			if isBuiltinRemediationCategory(name) && name != builtin {
+				return fmt.Errorf("taxonomy %q: category %q: a built-in category can only refine itself", tenant, name)
+			}
+			for _, n := range append([]string{c.Name}, c.Aliases...) {
+				n = strings.ToLower(strings.TrimSpace(n))
+				if other, ok := seen[n]; ok {
+					return fmt.Errorf("taxonomy %q: category %q: %q is already used by %q", tenant, name, n, other)
+				}
+				seen[n] = name
+			}
+		}
+	}
+	return nil
+}
+
+func isBuiltinRemediationCategory(s string) bool {
+	switch s {
+	case "compile", "runtime", "test", "lint", "security", "performance", "other":
+		return true
+	default:
+		return false
+	}
+}
+
+// FoldingConfig holds context-folding settings exposed to operators.
+type FoldingConfig struct {
+	// ReturnTargetTokens is the default token target for branch return
+	// messages. Longer messages are compressed, then truncated. The parent
+	// branch's remaining budget always caps the target (0 = no default).
+	ReturnTargetTokens int `koanf:"return_target_tokens"`
+}
+
+// Validate checks the folding settings.
+func (c *FoldingConfig) Validate() error {
+	if c.ReturnTargetTokens < 0 {
+		return fmt.Errorf("return_target_tokens must not be negative, got %d", c.ReturnTargetTokens)
+	}
+	return nil
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
 }
 
 // ServerConfig holds HTTP server configuration.
 type ServerConfig struct {
 	Port            int           `koanf:"http_port"`
 	ShutdownTimeout time.Duration `koanf:"shutdown_timeout"`
+
+	// ReadOnly starts the server in emergency read-only mode: writes are
+	// rejected while search and resume keep working. It can be toggled at
+	// runtime through the HTTP admin API.
+	ReadOnly       bool   `koanf:"read_only"`
+	ReadOnlyReason string `koanf:"read_only_reason"`
+
+	// SocketPath serves HTTP on a Unix domain socket instead of the TCP
+	// port, and MCPSocketPath serves MCP sessions on one instead of stdio.
+	// SocketMode is the octal file mode of both sockets (default "0600",
+	// owner only; "0660" admits the owning group).
+	SocketPath    string `koanf:"socket_path"`
+	MCPSocketPath string `koanf:"mcp_socket_path"`
+	SocketMode    string `koanf:"socket_mode"`
+
+	// AdminToken is the bearer token required by the /debug/pprof profiling
+	// endpoints. They are not served when it is unset.
+	AdminToken Secret `koanf:"admin_token"`
+
+	// DisableToolAliases rejects the legacy names of renamed MCP tools
+	// instead of translating them. RetiredToolAliases rejects only the
+	// named legacy tools, once their callers have migrated.
+	DisableToolAliases bool     `koanf:"disable_tool_aliases"`
+	RetiredToolAliases []string `koanf:"retired_tool_aliases"`
+
+	// MCPSessionIdleTimeout finalizes MCP sessions after this long without
+	// tool calls: a final checkpoint, branch cleanup, a feedback flush and
+	// outcome inference. Zero disables it (default).
+	MCPSessionIdleTimeout time.Duration `koanf:"mcp_session_idle_timeout"`
+}
+
+// SocketFileMode parses SocketMode. Zero means the default.
+func (c ServerConfig) SocketFileMode() (os.FileMode, error) {
+	if c.SocketMode == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(c.SocketMode, 8, 32)
+	if err != nil || mode&^0777 != 0 || mode&0600 != 0600 {
+		return 0, fmt.Errorf("invalid socket mode %q: want octal permissions granting the owner read/write, e.g. 0600", c.SocketMode)
+	}
+	return os.FileMode(mode), nil
 }
 
 // ObservabilityConfig holds OpenTelemetry configuration.
 type ObservabilityConfig struct {
-	EnableTelemetry   bool   `koanf:"enable_telemetry"`
-	ServiceName       string `koanf:"service_name"`
-	OTLPEndpoint      string `koanf:"otlp_endpoint"`        // OTLP endpoint (default: localhost:4317)
-	OTLPProtocol      string `koanf:"otlp_protocol"`        // "grpc" or "http/protobuf" (default: grpc)
-	OTLPInsecure      bool   `koanf:"otlp_insecure"`        // Use insecure connection (default: true for localhost)
-	OTLPTLSSkipVerify bool   `koanf:"otlp_tls_skip_verify"` // Skip TLS verification for internal CAs
+	EnableTelemetry   bool    `koanf:"enable_telemetry"`
+	ServiceName       string  `koanf:"service_name"`
+	OTLPEndpoint      string  `koanf:"otlp_endpoint"`        // OTLP endpoint (default: localhost:4317)
+	OTLPProtocol      string  `koanf:"otlp_protocol"`        // "grpc" or "http/protobuf" (default: grpc)
+	OTLPInsecure      bool    `koanf:"otlp_insecure"`        // Use insecure connection (default: true for localhost)
+	OTLPTLSSkipVerify bool    `koanf:"otlp_tls_skip_verify"` // Skip TLS verification for internal CAs
+	SamplingRate      float64 `koanf:"sampling_rate"`        // Fraction of traces sampled, 0-1 (default: 1.0)
+
+	// ToolCallLog writes a "tool call" log entry per MCP tool call with its
+	// duration, tenant, project, result tokens and error code.
+	// ToolCallSampleRate is the fraction of successful calls logged; failed
+	// calls are always logged.
+	ToolCallLog        bool    `koanf:"tool_call_log"`         // default: true
+	ToolCallSampleRate float64 `koanf:"tool_call_sample_rate"` // 0-1 (default: 1.0)
 }
 
 // PreFetchConfig holds pre-fetch engine configuration.
@@ -230,7 +865,7 @@ type RuleConfig struct {
 //   - CONTEXTD_DATA_PATH: Base data path (default: /data)
 //   - EMBEDDINGS_PROVIDER: fastembed (default, local) or tei (remote)
 //   - EMBEDDINGS_CACHE_DIR: Model cache directory (default: ./local_cache)
-//   - VECTORSTORE_PROVIDER: chromem (default, embedded) or qdrant (external)
+//   - VECTORSTORE_PROVIDER: chromem (default, embedded), qdrant or pgvector (external)
 //   - CHECKPOINT_MAX_CONTENT_SIZE_KB: Max checkpoint size in KB (default: 1024)
 //   - CONTEXTD_PRODUCTION_MODE: Enable production safety checks (default: false)
 //
@@ -239,6 +874,12 @@ type RuleConfig struct {
 // Server:
 //   - SERVER_PORT: HTTP server port (default: 9090)
 //   - SERVER_SHUTDOWN_TIMEOUT: Graceful shutdown timeout (default: 10s)
+//   - SERVER_READ_ONLY: Start in read-only mode (default: false)
+//   - SERVER_READ_ONLY_REASON: Reason shown in read-only errors and status
+//   - SERVER_ADMIN_TOKEN: Bearer token for /debug/pprof (unset: not served)
+//   - SERVER_DISABLE_TOOL_ALIASES: Reject legacy names of renamed MCP tools (default: false)
+//   - SERVER_RETIRED_TOOL_ALIASES: Comma-separated legacy tool names no longer accepted
+//   - SERVER_MCP_SESSION_IDLE_TIMEOUT: Finalize MCP sessions idle this long, e.g. 30m (default: 0, disabled)
 //
 // Qdrant:
 //   - QDRANT_HOST: Qdrant host (default: localhost)
@@ -246,8 +887,19 @@ type RuleConfig struct {
 //   - QDRANT_HTTP_PORT: Qdrant HTTP port (default: 6333)
 //   - QDRANT_COLLECTION: Default collection name (default: contextd_default)
 //   - QDRANT_VECTOR_SIZE: Vector dimensions (default: 384 for FastEmbed)
+//   - QDRANT_HNSW_M: HNSW edges per node (default: Qdrant's, 16)
+//   - QDRANT_HNSW_EF_CONSTRUCT: HNSW build candidate list size (default: Qdrant's, 100)
+//   - QDRANT_HNSW_EF_SEARCH: HNSW query candidate list size (default: Qdrant's)
+//   - QDRANT_HNSW_ON_DISK: Serve the HNSW index from disk (default: false)
 //   - CONTEXTD_DATA_PATH: Base data path (default: /data)
 //
+// Pgvector (CONTEXTD_VECTORSTORE_PROVIDER=pgvector):
+//   - CONTEXTD_VECTORSTORE_PGVECTOR_DSN: Postgres connection string (required)
+//   - CONTEXTD_VECTORSTORE_PGVECTOR_COLLECTION: Default collection name (default: contextd_default)
+//   - CONTEXTD_VECTORSTORE_PGVECTOR_VECTOR_SIZE: Vector dimensions (default: 384 for FastEmbed)
+//   - CONTEXTD_VECTORSTORE_PGVECTOR_BATCH_SIZE: Documents per upsert round trip (default: 500)
+//   - CONTEXTD_VECTORSTORE_PGVECTOR_EF_SEARCH: HNSW query candidate list size (default: 100)
+//
 // Embeddings:
 //   - EMBEDDINGS_PROVIDER: Provider type: fastembed or tei (default: fastembed)
 //   - EMBEDDINGS_MODEL: Embedding model (default: BAAI/bge-small-en-v1.5)
@@ -256,15 +908,87 @@ type RuleConfig struct {
 //
 // Checkpoint:
 //   - CHECKPOINT_MAX_CONTENT_SIZE_KB: Max checkpoint size in KB (default: 1024)
+//   - CHECKPOINT_STATE_STORE: Where full checkpoint state is kept: vectorstore, filesystem or sqlite (default: vectorstore)
+//   - CHECKPOINT_STATE_PATH: State directory or database file (default: ~/.config/contextd/checkpoint-state[.db])
 //
 // Consolidation Scheduler:
 //   - CONSOLIDATION_SCHEDULER_ENABLED: Enable automatic consolidation (default: false)
 //   - CONSOLIDATION_SCHEDULER_INTERVAL: Time between runs (default: 24h)
 //   - CONSOLIDATION_SCHEDULER_SIMILARITY_THRESHOLD: Similarity threshold (default: 0.8)
 //
+// Memory Pruning:
+//   - MEMORY_PRUNING_ENABLED: Enable scheduled pruning (default: false)
+//   - MEMORY_PRUNING_INTERVAL: Time between runs (default: 24h)
+//   - MEMORY_PRUNING_PROJECT_IDS: Comma-separated projects to prune
+//   - MEMORY_PRUNING_MAX_MEMORIES: Max active memories per project (default: 0, unlimited)
+//   - MEMORY_PRUNING_MIN_CONFIDENCE: Confidence floor (default: 0, disabled)
+//   - MEMORY_PRUNING_MAX_UNUSED_AGE: Max age for never-used memories (default: 0, disabled)
+//   - MEMORY_PRUNING_ACTION: archive or delete (default: archive)
+//   - MEMORY_PRUNING_DRY_RUN: Log the report without pruning (default: false)
+//
+// Knowledge Digest:
+//   - DIGEST_ENABLED: Generate scheduled knowledge digests (default: false)
+//   - DIGEST_INTERVAL: Time between digests and the period each covers (default: 168h)
+//   - DIGEST_TENANT_ID: Tenant to digest (default: the default tenant)
+//   - DIGEST_TEAM_ID: Team whose remediations are included (default: org-wide only)
+//   - DIGEST_PROJECT_IDS: Comma-separated projects whose memories are included
+//   - DIGEST_MIN_CONFIDENCE: Confidence floor for included items (default: 0.7)
+//   - DIGEST_DIR: Digest directory (default: ~/.config/contextd/digests)
+//
+// Storage Monitor:
+//   - STORAGE_MONITOR_ENABLED: Block writes before the disk fills (default: true)
+//   - STORAGE_MONITOR_INTERVAL: Time between disk checks (default: 30s)
+//   - STORAGE_WARN_PERCENT: Disk usage that logs a warning (default: 85)
+//   - STORAGE_CRITICAL_PERCENT: Disk usage that blocks writes (default: 95)
+//   - STORAGE_MIN_FREE_MB: Free space below which writes are blocked (default: 512)
+//
+// Tool Analytics:
+//   - ANALYTICS_ENABLED: Record MCP tool usage and LLM spend rollups (default: true)
+//   - ANALYTICS_DIR: Rollup directory (default: ~/.config/contextd/analytics)
+//   - ANALYTICS_FLUSH_INTERVAL: Time between rollup writes (default: 1m)
+//   - ANALYTICS_RETENTION_DAYS: Days of rollups to keep (default: 90)
+//   - ANALYTICS_SESSION_RETENTION_DAYS: Days of session journals to keep (default: 14)
+//
+// LLM Budget (per-tenant overrides need a config file):
+//   - LLM_MONTHLY_TOKEN_BUDGET: Tokens each tenant may use per month before LLM features degrade (default: 0, unlimited)
+//
+// LLM Resilience:
+//   - LLM_TIMEOUT: Timeout per LLM request attempt (default: 60s)
+//   - LLM_MAX_RETRIES: Retries of requests failing with 429, 5xx or a timeout (default: 2)
+//   - LLM_CIRCUIT_FAILURE_THRESHOLD: Failed requests in a row before a feature's breaker opens (default: 5)
+//   - LLM_CIRCUIT_COOLDOWN: Time an open breaker waits before probing the provider again (default: 30s)
+//
+// Workflows:
+//   - WORKFLOWS_ENABLED: Run the Temporal worker for indexing and consolidation (default: false)
+//   - WORKFLOWS_TEMPORAL_HOST: Temporal frontend address (default: localhost:7233)
+//   - WORKFLOWS_NAMESPACE: Temporal namespace (default: default)
+//   - WORKFLOWS_TASK_QUEUE: Task queue to poll (default: contextd-workflows)
+//   - GITHUB_TOKEN: Token for reading CI job logs; enables CI failure remediation drafts
+//
+// Notifications (channels and routes need a config file):
+//   - NOTIFICATIONS_ENABLED: Post notable knowledge events (default: false)
+//   - NOTIFICATIONS_SLACK_WEBHOOK_URL: Slack incoming webhook that receives all events
+//
+// Conversations (per-tenant profiles need a config file):
+//   - CONVERSATIONS_REDACTION: Redaction profile, standard or strict (default: standard)
+//   - CONVERSATIONS_INTERNAL_DOMAINS: Comma-separated domains whose hosts strict redaction replaces
+//   - CONVERSATIONS_DOMAIN_CLASSIFIER: Domain tagging of indexed messages, embedding or off (default: embedding)
+//   - CONVERSATIONS_DOMAIN_THRESHOLD: Minimum similarity to tag a domain (default: 0.5)
+//
+// Remediations (category taxonomies need a config file)
+//
+// Folding:
+//   - FOLDING_RETURN_TARGET_TOKENS: Default token target for branch return messages (default: 0, parent budget only)
+//
 // Telemetry:
 //   - OTEL_ENABLE: Enable OpenTelemetry (default: false, requires OTEL collector)
 //   - OTEL_SERVICE_NAME: Service name for traces (default: contextd)
+//   - OTEL_SAMPLING_RATE: Fraction of traces sampled, 0-1 (default: 1.0)
+//   - MCP_TOOL_CALL_LOG: Log one entry per MCP tool call (default: true)
+//   - MCP_TOOL_CALL_SAMPLE_RATE: Fraction of successful tool calls logged, 0-1; failures are always logged (default: 1.0)
+//
+// Profile:
+//   - CONTEXTD_PROFILE: Preset applied over the defaults: solo, team or enterprise (default: none)
 //
 // Pre-fetch:
 //   - PREFETCH_ENABLED: Enable pre-fetch engine (default: true)
@@ -277,6 +1001,7 @@ type RuleConfig struct {
 //	fmt.Println("Qdrant host:", cfg.Qdrant.Host)
 func Load() *Config {
 	cfg := &Config{
+		Profile: getEnvString("CONTEXTD_PROFILE", ""),
 		Production: ProductionConfig{
 			Enabled:               getEnvBool("CONTEXTD_PRODUCTION_MODE", false),
 			LocalModeAcknowledged: getEnvBool("CONTEXTD_LOCAL_MODE", false),
@@ -287,10 +1012,25 @@ func Load() *Config {
 		Server: ServerConfig{
 			Port:            getEnvInt("SERVER_PORT", 9090),
 			ShutdownTimeout: getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 10*time.Second),
+			ReadOnly:        getEnvBool("SERVER_READ_ONLY", false),
+			ReadOnlyReason:  getEnvString("SERVER_READ_ONLY_REASON", ""),
+			SocketPath:      getEnvString("SERVER_SOCKET_PATH", ""),
+			MCPSocketPath:   getEnvString("SERVER_MCP_SOCKET_PATH", ""),
+			SocketMode:      getEnvString("SERVER_SOCKET_MODE", ""),
+			AdminToken:      Secret(os.Getenv("SERVER_ADMIN_TOKEN")),
+
+			DisableToolAliases: getEnvBool("SERVER_DISABLE_TOOL_ALIASES", false),
+			RetiredToolAliases: getEnvStringSlice("SERVER_RETIRED_TOOL_ALIASES", nil),
+
+			MCPSessionIdleTimeout: getEnvDuration("SERVER_MCP_SESSION_IDLE_TIMEOUT", 0),
 		},
 		Observability: ObservabilityConfig{
 			EnableTelemetry: getEnvBool("OTEL_ENABLE", false),
 			ServiceName:     getEnvString("OTEL_SERVICE_NAME", "contextd"),
+			SamplingRate:    getEnvFloat("OTEL_SAMPLING_RATE", 1.0),
+
+			ToolCallLog:        getEnvBool("MCP_TOOL_CALL_LOG", true),
+			ToolCallSampleRate: getEnvFloat("MCP_TOOL_CALL_SAMPLE_RATE", 1.0),
 		},
 		PreFetch: PreFetchConfig{
 			Enabled:         getEnvBool("PREFETCH_ENABLED", true),
@@ -322,6 +1062,8 @@ func Load() *Config {
 	// Checkpoint configuration
 	cfg.Checkpoint = CheckpointConfig{
 		MaxContentSizeKB: getEnvInt("CHECKPOINT_MAX_CONTENT_SIZE_KB", 1024), // Default 1MB
+		StateStore:       getEnvString("CHECKPOINT_STATE_STORE", "vectorstore"),
+		StatePath:        getEnvString("CHECKPOINT_STATE_PATH", ""),
 	}
 
 	// Consolidation Scheduler configuration
@@ -331,20 +1073,110 @@ func Load() *Config {
 		SimilarityThreshold: getEnvFloat("CONSOLIDATION_SCHEDULER_SIMILARITY_THRESHOLD", 0.8), // Default: 0.8
 	}
 
+	// Memory pruning configuration
+	cfg.MemoryPruning = MemoryPruningConfig{
+		Enabled:       getEnvBool("MEMORY_PRUNING_ENABLED", false),
+		Interval:      getEnvDuration("MEMORY_PRUNING_INTERVAL", 24*time.Hour),
+		ProjectIDs:    getEnvStringSlice("MEMORY_PRUNING_PROJECT_IDS", nil),
+		MaxMemories:   getEnvInt("MEMORY_PRUNING_MAX_MEMORIES", 0),
+		MinConfidence: getEnvFloat("MEMORY_PRUNING_MIN_CONFIDENCE", 0),
+		MaxUnusedAge:  getEnvDuration("MEMORY_PRUNING_MAX_UNUSED_AGE", 0),
+		Action:        getEnvString("MEMORY_PRUNING_ACTION", "archive"),
+		DryRun:        getEnvBool("MEMORY_PRUNING_DRY_RUN", false),
+	}
+
+	// Knowledge digest configuration
+	cfg.Digest = DigestConfig{
+		Enabled:       getEnvBool("DIGEST_ENABLED", false),
+		Interval:      getEnvDuration("DIGEST_INTERVAL", 7*24*time.Hour),
+		TenantID:      getEnvString("DIGEST_TENANT_ID", ""),
+		TeamID:        getEnvString("DIGEST_TEAM_ID", ""),
+		ProjectIDs:    getEnvStringSlice("DIGEST_PROJECT_IDS", nil),
+		MinConfidence: getEnvFloat("DIGEST_MIN_CONFIDENCE", 0.7),
+		Dir:           getEnvString("DIGEST_DIR", "~/.config/contextd/digests"),
+	}
+
+	// Storage monitor configuration
+	cfg.StorageMonitor = StorageMonitorConfig{
+		Enabled:         getEnvBool("STORAGE_MONITOR_ENABLED", true),
+		Interval:        getEnvDuration("STORAGE_MONITOR_INTERVAL", 30*time.Second),
+		WarnPercent:     getEnvFloat("STORAGE_WARN_PERCENT", 85),
+		CriticalPercent: getEnvFloat("STORAGE_CRITICAL_PERCENT", 95),
+		MinFreeMB:       getEnvInt("STORAGE_MIN_FREE_MB", 512),
+	}
+
+	// Tool analytics configuration
+	cfg.Analytics = AnalyticsConfig{
+		Enabled:              getEnvBool("ANALYTICS_ENABLED", true),
+		Dir:                  getEnvString("ANALYTICS_DIR", "~/.config/contextd/analytics"),
+		FlushInterval:        getEnvDuration("ANALYTICS_FLUSH_INTERVAL", time.Minute),
+		RetentionDays:        getEnvInt("ANALYTICS_RETENTION_DAYS", 90),
+		SessionRetentionDays: getEnvInt("ANALYTICS_SESSION_RETENTION_DAYS", 14),
+	}
+
+	// Temporal workflows configuration
+	cfg.Workflows = WorkflowsConfig{
+		Enabled:      getEnvBool("WORKFLOWS_ENABLED", false),
+		TemporalHost: getEnvString("WORKFLOWS_TEMPORAL_HOST", "localhost:7233"),
+		Namespace:    getEnvString("WORKFLOWS_NAMESPACE", "default"),
+		TaskQueue:    getEnvString("WORKFLOWS_TASK_QUEUE", "contextd-workflows"),
+		GitHubToken:  Secret(os.Getenv("GITHUB_TOKEN")),
+	}
+
+	// Notifications configuration
+	cfg.Notifications = NotificationsConfig{
+		Enabled:         getEnvBool("NOTIFICATIONS_ENABLED", false),
+		SlackWebhookURL: Secret(os.Getenv("NOTIFICATIONS_SLACK_WEBHOOK_URL")),
+	}
+
+	// Conversations configuration
+	cfg.Conversations = ConversationsConfig{
+		Redaction:        getEnvString("CONVERSATIONS_REDACTION", "standard"),
+		InternalDomains:  getEnvStringSlice("CONVERSATIONS_INTERNAL_DOMAINS", nil),
+		DomainClassifier: getEnvString("CONVERSATIONS_DOMAIN_CLASSIFIER", "embedding"),
+		DomainThreshold:  getEnvFloat("CONVERSATIONS_DOMAIN_THRESHOLD", 0.5),
+	}
+
+	// Folding configuration
+	cfg.Folding = FoldingConfig{
+		ReturnTargetTokens: getEnvInt("FOLDING_RETURN_TARGET_TOKENS", 0),
+	}
+
 	// ReasoningBank configuration
 	cfg.ReasoningBank = ReasoningBankConfig{
 		Granularity:      getEnvString("CONTEXTD_REASONINGBANK_GRANULARITY", "turn"),
 		MaxBufferedTurns: getEnvInt("CONTEXTD_REASONINGBANK_MAX_BUFFERED_TURNS", 500),
+		RecordQuality:    getEnvString("CONTEXTD_REASONINGBANK_RECORD_QUALITY", "standard"),
+
+		WriteBatchInterval: getEnvDuration("CONTEXTD_REASONINGBANK_WRITE_BATCH_INTERVAL", 0),
+		WriteBatchSize:     getEnvInt("CONTEXTD_REASONINGBANK_WRITE_BATCH_SIZE", 100),
+
+		DisableDuplicateGuard: getEnvBool("CONTEXTD_REASONINGBANK_DISABLE_DUPLICATE_GUARD", false),
+		DuplicateWindow:       getEnvDuration("CONTEXTD_REASONINGBANK_DUPLICATE_WINDOW", 10*time.Minute),
+		DuplicateThreshold:    getEnvFloat("CONTEXTD_REASONINGBANK_DUPLICATE_THRESHOLD", 0.85),
+		DuplicateBurst:        getEnvInt("CONTEXTD_REASONINGBANK_DUPLICATE_BURST", 3),
+
+		Scoring: SearchScoringConfig{
+			SimilarityWeight:   getEnvFloat("CONTEXTD_REASONINGBANK_SCORING_SIMILARITY_WEIGHT", 1),
+			ConfidenceWeight:   getEnvFloat("CONTEXTD_REASONINGBANK_SCORING_CONFIDENCE_WEIGHT", 0),
+			RecencyWeight:      getEnvFloat("CONTEXTD_REASONINGBANK_SCORING_RECENCY_WEIGHT", 0),
+			RecencyHalfLife:    getEnvDuration("CONTEXTD_REASONINGBANK_SCORING_RECENCY_HALF_LIFE", 720*time.Hour),
+			ConsolidationBoost: getEnvFloat("CONTEXTD_REASONINGBANK_SCORING_CONSOLIDATION_BOOST", 1.2),
+		},
 	}
 
 	// Qdrant configuration
 	cfg.Qdrant = QdrantConfig{
-		Host:           getEnvString("QDRANT_HOST", "localhost"),
-		Port:           getEnvInt("QDRANT_PORT", 6334),
-		HTTPPort:       getEnvInt("QDRANT_HTTP_PORT", 6333),
-		CollectionName: getEnvString("QDRANT_COLLECTION", "contextd_default"),
-		VectorSize:     uint64(getEnvInt("QDRANT_VECTOR_SIZE", 384)), // FastEmbed default
-		DataPath:       getEnvString("CONTEXTD_DATA_PATH", "/data"),
+		Host:            getEnvString("QDRANT_HOST", "localhost"),
+		Port:            getEnvInt("QDRANT_PORT", 6334),
+		HTTPPort:        getEnvInt("QDRANT_HTTP_PORT", 6333),
+		CollectionName:  getEnvString("QDRANT_COLLECTION", "contextd_default"),
+		VectorSize:      uint64(getEnvInt("QDRANT_VECTOR_SIZE", 384)), // FastEmbed default
+		HNSWM:           getEnvInt("QDRANT_HNSW_M", 0),
+		HNSWEfConstruct: getEnvInt("QDRANT_HNSW_EF_CONSTRUCT", 0),
+		HNSWEfSearch:    getEnvInt("QDRANT_HNSW_EF_SEARCH", 0),
+		HNSWOnDisk:      getEnvBool("QDRANT_HNSW_ON_DISK", false),
+		DataPath:        getEnvString("CONTEXTD_DATA_PATH", "/data"),
 	}
 
 	// Embeddings configuration
@@ -354,6 +1186,18 @@ func Load() *Config {
 		Model:       getEnvString("EMBEDDINGS_MODEL", "BAAI/bge-small-en-v1.5"),
 		CacheDir:    getEnvString("EMBEDDINGS_CACHE_DIR", ""),
 		ONNXVersion: getEnvString("EMBEDDINGS_ONNX_VERSION", ""),
+		APIKey:      Secret(os.Getenv("EMBEDDINGS_API_KEY")),
+	}
+
+	// LLM provider credentials
+	cfg.LLM = LLMConfig{
+		AnthropicAPIKey:    Secret(os.Getenv("ANTHROPIC_API_KEY")),
+		MonthlyTokenBudget: int64(getEnvInt("LLM_MONTHLY_TOKEN_BUDGET", 0)),
+
+		Timeout:                 getEnvDuration("LLM_TIMEOUT", 60*time.Second),
+		MaxRetries:              getEnvInt("LLM_MAX_RETRIES", 2),
+		CircuitFailureThreshold: getEnvInt("LLM_CIRCUIT_FAILURE_THRESHOLD", 5),
+		CircuitCooldown:         getEnvDuration("LLM_CIRCUIT_COOLDOWN", 30*time.Second),
 	}
 
 	// Repository indexing configuration
@@ -379,6 +1223,16 @@ func Load() *Config {
 			Compress:          getEnvBool("CONTEXTD_VECTORSTORE_CHROMEM_COMPRESS", false),
 			DefaultCollection: getEnvString("CONTEXTD_VECTORSTORE_CHROMEM_COLLECTION", "contextd_default"),
 			VectorSize:        getEnvInt("CONTEXTD_VECTORSTORE_CHROMEM_VECTOR_SIZE", 384),
+			LazyLoad:          getEnvBool("CONTEXTD_VECTORSTORE_CHROMEM_LAZY_LOAD", false),
+			Warmup:            getEnvStringSlice("CONTEXTD_VECTORSTORE_CHROMEM_WARMUP", nil),
+			MmapVectors:       getEnvBool("CONTEXTD_VECTORSTORE_CHROMEM_MMAP_VECTORS", false),
+		},
+		Pgvector: PgvectorConfig{
+			DSN:               Secret(os.Getenv("CONTEXTD_VECTORSTORE_PGVECTOR_DSN")),
+			DefaultCollection: getEnvString("CONTEXTD_VECTORSTORE_PGVECTOR_COLLECTION", "contextd_default"),
+			VectorSize:        getEnvInt("CONTEXTD_VECTORSTORE_PGVECTOR_VECTOR_SIZE", 384),
+			BatchSize:         getEnvInt("CONTEXTD_VECTORSTORE_PGVECTOR_BATCH_SIZE", 500),
+			EfSearch:          getEnvInt("CONTEXTD_VECTORSTORE_PGVECTOR_EF_SEARCH", 100),
 		},
 	}
 
@@ -418,6 +1272,7 @@ func Load() *Config {
 // Returns an error if:
 //   - Server port is not between 1 and 65535
 //   - Shutdown timeout is not positive
+//   - MCP session idle timeout is negative
 //   - Service name is empty (when telemetry is enabled)
 func (c *Config) Validate() error {
 	// Validate server configuration
@@ -429,10 +1284,28 @@ func (c *Config) Validate() error {
 		return errors.New("shutdown timeout must be positive")
 	}
 
+	if _, err := c.Server.SocketFileMode(); err != nil {
+		return err
+	}
+
+	if c.Server.MCPSessionIdleTimeout < 0 {
+		return fmt.Errorf("server mcp_session_idle_timeout must not be negative, got %s", c.Server.MCPSessionIdleTimeout)
+	}
+
 	// Validate observability configuration
 	if c.Observability.EnableTelemetry && c.Observability.ServiceName == "" {
 		return errors.New("service name required when telemetry is enabled")
 	}
+	if c.Observability.SamplingRate < 0 || c.Observability.SamplingRate > 1 {
+		return fmt.Errorf("observability sampling_rate must be between 0 and 1, got %g", c.Observability.SamplingRate)
+	}
+	if c.Observability.ToolCallSampleRate < 0 || c.Observability.ToolCallSampleRate > 1 {
+		return fmt.Errorf("observability tool_call_sample_rate must be between 0 and 1, got %g", c.Observability.ToolCallSampleRate)
+	}
+
+	if err := ValidateProfile(c.Profile); err != nil {
+		return err
+	}
 
 	// Validate environment variable inputs
 	if err := validateHostname(c.Qdrant.Host); err != nil {
@@ -476,6 +1349,77 @@ func (c *Config) Validate() error {
 	if c.ReasoningBank.MaxBufferedTurns < 0 {
 		return fmt.Errorf("CONTEXTD_REASONINGBANK_MAX_BUFFERED_TURNS must be non-negative, got %d", c.ReasoningBank.MaxBufferedTurns)
 	}
+	switch c.ReasoningBank.RecordQuality {
+	case "", "off", "lenient", "standard", "strict":
+		// Valid; empty defaults to "standard" at runtime
+	default:
+		return fmt.Errorf("invalid CONTEXTD_REASONINGBANK_RECORD_QUALITY: %q (must be 'off', 'lenient', 'standard' or 'strict')", c.ReasoningBank.RecordQuality)
+	}
+	if c.ReasoningBank.WriteBatchInterval < 0 {
+		return fmt.Errorf("CONTEXTD_REASONINGBANK_WRITE_BATCH_INTERVAL must be non-negative, got %s", c.ReasoningBank.WriteBatchInterval)
+	}
+	if c.ReasoningBank.WriteBatchSize < 0 {
+		return fmt.Errorf("CONTEXTD_REASONINGBANK_WRITE_BATCH_SIZE must be non-negative, got %d", c.ReasoningBank.WriteBatchSize)
+	}
+	if c.ReasoningBank.DuplicateWindow < 0 {
+		return fmt.Errorf("CONTEXTD_REASONINGBANK_DUPLICATE_WINDOW must be non-negative, got %s", c.ReasoningBank.DuplicateWindow)
+	}
+	if c.ReasoningBank.DuplicateThreshold < 0 || c.ReasoningBank.DuplicateThreshold > 1 {
+		return fmt.Errorf("CONTEXTD_REASONINGBANK_DUPLICATE_THRESHOLD must be between 0 and 1, got %g", c.ReasoningBank.DuplicateThreshold)
+	}
+	if c.ReasoningBank.DuplicateBurst < 0 {
+		return fmt.Errorf("CONTEXTD_REASONINGBANK_DUPLICATE_BURST must be non-negative, got %d", c.ReasoningBank.DuplicateBurst)
+	}
+	if err := c.ReasoningBank.Experiment.Validate(); err != nil {
+		return fmt.Errorf("invalid reasoningbank experiment: %w", err)
+	}
+	if err := c.ReasoningBank.Scoring.Validate(); err != nil {
+		return fmt.Errorf("invalid reasoningbank scoring: %w", err)
+	}
+
+	// Validate knowledge digest configuration
+	if c.Digest.Enabled {
+		if err := c.Digest.Validate(); err != nil {
+			return fmt.Errorf("invalid digest config: %w", err)
+		}
+	}
+
+	// Validate notifications configuration
+	if c.Notifications.Enabled {
+		if err := c.Notifications.Validate(); err != nil {
+			return fmt.Errorf("invalid notifications config: %w", err)
+		}
+	}
+
+	// Validate conversation redaction profiles
+	if err := c.Conversations.Validate(); err != nil {
+		return fmt.Errorf("invalid conversations config: %w", err)
+	}
+
+	// Validate checkpoint state store
+	if err := c.Checkpoint.Validate(); err != nil {
+		return fmt.Errorf("invalid checkpoint config: %w", err)
+	}
+
+	// Validate repository preprocessor rules
+	if err := c.Repository.Validate(); err != nil {
+		return fmt.Errorf("invalid repository config: %w", err)
+	}
+
+	// Validate remediation category taxonomies
+	if err := c.Remediations.Validate(); err != nil {
+		return fmt.Errorf("invalid remediations config: %w", err)
+	}
+
+	// Validate LLM token budgets
+	if err := c.LLM.Validate(); err != nil {
+		return fmt.Errorf("invalid llm config: %w", err)
+	}
+
+	// Validate folding return settings
+	if err := c.Folding.Validate(); err != nil {
+		return fmt.Errorf("invalid folding config: %w", err)
+	}
 	return nil
 }
 
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,6 +27,9 @@ func TestLoad(t *testing.T) {
 				if cfg.Server.ShutdownTimeout != 10*time.Second {
 					t.Errorf("Server.ShutdownTimeout = %v, want 10s", cfg.Server.ShutdownTimeout)
 				}
+				if cfg.Server.MCPSessionIdleTimeout != 0 {
+					t.Errorf("Server.MCPSessionIdleTimeout = %v, want 0 (disabled by default)", cfg.Server.MCPSessionIdleTimeout)
+				}
 				if cfg.Observability.EnableTelemetry {
 					t.Error("Observability.EnableTelemetry = true, want false (disabled by default)")
 				}
@@ -54,6 +58,10 @@ func TestLoad(t *testing.T) {
 				"SERVER_SHUTDOWN_TIMEOUT": "5s",
 				"OTEL_ENABLE":             "false",
 				"OTEL_SERVICE_NAME":       "test-service",
+				"SERVER_READ_ONLY":        "true",
+				"SERVER_READ_ONLY_REASON": "migration",
+
+				"SERVER_MCP_SESSION_IDLE_TIMEOUT": "30m",
 			},
 			validate: func(t *testing.T, cfg *Config) {
 				if cfg.Server.Port != 9090 {
@@ -62,6 +70,15 @@ func TestLoad(t *testing.T) {
 				if cfg.Server.ShutdownTimeout != 5*time.Second {
 					t.Errorf("Server.ShutdownTimeout = %v, want 5s", cfg.Server.ShutdownTimeout)
 				}
+				if !cfg.Server.ReadOnly {
+					t.Error("Server.ReadOnly = false, want true")
+				}
+				if cfg.Server.ReadOnlyReason != "migration" {
+					t.Errorf("Server.ReadOnlyReason = %q, want migration", cfg.Server.ReadOnlyReason)
+				}
+				if cfg.Server.MCPSessionIdleTimeout != 30*time.Minute {
+					t.Errorf("Server.MCPSessionIdleTimeout = %v, want 30m", cfg.Server.MCPSessionIdleTimeout)
+				}
 				if cfg.Observability.EnableTelemetry {
 					t.Error("Observability.EnableTelemetry = true, want false")
 				}
@@ -163,6 +180,17 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative session idle timeout",
+			cfg: &Config{
+				Server: ServerConfig{
+					Port:                  8080,
+					ShutdownTimeout:       10 * time.Second,
+					MCPSessionIdleTimeout: -time.Minute,
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty service name",
 			cfg: &Config{
@@ -177,6 +205,39 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid record quality",
+			cfg: &Config{
+				Server: ServerConfig{
+					Port:            8080,
+					ShutdownTimeout: 10 * time.Second,
+				},
+				ReasoningBank: ReasoningBankConfig{RecordQuality: "paranoid"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative write batch interval",
+			cfg: &Config{
+				Server: ServerConfig{
+					Port:            8080,
+					ShutdownTimeout: 10 * time.Second,
+				},
+				ReasoningBank: ReasoningBankConfig{WriteBatchInterval: -time.Second},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate threshold above one",
+			cfg: &Config{
+				Server: ServerConfig{
+					Port:            8080,
+					ShutdownTimeout: 10 * time.Second,
+				},
+				ReasoningBank: ReasoningBankConfig{DuplicateThreshold: 1.5},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -228,11 +289,14 @@ func TestLoad_VectorStoreConfig(t *testing.T) {
 		{
 			name: "vectorstore environment overrides",
 			env: map[string]string{
-				"CONTEXTD_VECTORSTORE_PROVIDER":            "qdrant",
-				"CONTEXTD_VECTORSTORE_CHROMEM_PATH":        "/custom/path/vectorstore",
-				"CONTEXTD_VECTORSTORE_CHROMEM_COMPRESS":    "false",
-				"CONTEXTD_VECTORSTORE_CHROMEM_COLLECTION":  "custom_collection",
-				"CONTEXTD_VECTORSTORE_CHROMEM_VECTOR_SIZE": "768",
+				"CONTEXTD_VECTORSTORE_PROVIDER":             "qdrant",
+				"CONTEXTD_VECTORSTORE_CHROMEM_PATH":         "/custom/path/vectorstore",
+				"CONTEXTD_VECTORSTORE_CHROMEM_COMPRESS":     "false",
+				"CONTEXTD_VECTORSTORE_CHROMEM_COLLECTION":   "custom_collection",
+				"CONTEXTD_VECTORSTORE_CHROMEM_VECTOR_SIZE":  "768",
+				"CONTEXTD_VECTORSTORE_CHROMEM_LAZY_LOAD":    "true",
+				"CONTEXTD_VECTORSTORE_CHROMEM_WARMUP":       "hot_a,hot_b",
+				"CONTEXTD_VECTORSTORE_CHROMEM_MMAP_VECTORS": "true",
 			},
 			validate: func(t *testing.T, cfg *Config) {
 				if cfg.VectorStore.Provider != "qdrant" {
@@ -250,6 +314,41 @@ func TestLoad_VectorStoreConfig(t *testing.T) {
 				if cfg.VectorStore.Chromem.VectorSize != 768 {
 					t.Errorf("VectorStore.Chromem.VectorSize = %d, want 768", cfg.VectorStore.Chromem.VectorSize)
 				}
+				if !cfg.VectorStore.Chromem.LazyLoad {
+					t.Error("VectorStore.Chromem.LazyLoad should be true when overridden")
+				}
+				if len(cfg.VectorStore.Chromem.Warmup) != 2 || cfg.VectorStore.Chromem.Warmup[1] != "hot_b" {
+					t.Errorf("VectorStore.Chromem.Warmup = %v, want [hot_a hot_b]", cfg.VectorStore.Chromem.Warmup)
+				}
+				if !cfg.VectorStore.Chromem.MmapVectors {
+					t.Error("VectorStore.Chromem.MmapVectors should be true when overridden")
+				}
+			},
+		},
+		{
+			name: "pgvector environment overrides",
+			env: map[string]string{
+				"CONTEXTD_VECTORSTORE_PROVIDER":             "pgvector",
+				"CONTEXTD_VECTORSTORE_PGVECTOR_DSN":         "postgres://contextd:pw@db/contextd",
+				"CONTEXTD_VECTORSTORE_PGVECTOR_COLLECTION":  "shared",
+				"CONTEXTD_VECTORSTORE_PGVECTOR_VECTOR_SIZE": "768",
+				"CONTEXTD_VECTORSTORE_PGVECTOR_BATCH_SIZE":  "200",
+				"CONTEXTD_VECTORSTORE_PGVECTOR_EF_SEARCH":   "400",
+			},
+			validate: func(t *testing.T, cfg *Config) {
+				pg := cfg.VectorStore.Pgvector
+				if cfg.VectorStore.Provider != "pgvector" {
+					t.Errorf("VectorStore.Provider = %q, want pgvector", cfg.VectorStore.Provider)
+				}
+				if pg.DSN.Value() != "postgres://contextd:pw@db/contextd" {
+					t.Error("VectorStore.Pgvector.DSN not loaded")
+				}
+				if pg.DefaultCollection != "shared" || pg.VectorSize != 768 || pg.BatchSize != 200 || pg.EfSearch != 400 {
+					t.Errorf("VectorStore.Pgvector = %+v, want shared/768/200/400", pg)
+				}
+				if err := cfg.VectorStore.Validate(); err != nil {
+					t.Errorf("VectorStore.Validate() = %v", err)
+				}
 			},
 		},
 	}
@@ -364,6 +463,23 @@ func TestVectorStoreConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid pgvector config",
+			cfg: VectorStoreConfig{
+				Provider: "pgvector",
+				Pgvector: PgvectorConfig{DSN: "postgres://localhost/contextd", VectorSize: 384},
+			},
+			wantErr: false,
+		},
+		{
+			name: "pgvector without dsn",
+			cfg: VectorStoreConfig{
+				Provider: "pgvector",
+				Pgvector: PgvectorConfig{VectorSize: 384},
+			},
+			wantErr: true,
+			errMsg:  "dsn is required",
+		},
 		{
 			name: "invalid provider",
 			cfg: VectorStoreConfig{
@@ -518,6 +634,236 @@ func TestLoad_ConsolidationScheduler(t *testing.T) {
 	}
 }
 
+// TestLoad_MemoryPruning tests memory pruning configuration loading
+func TestLoad_MemoryPruning(t *testing.T) {
+	originalEnv := saveEnv()
+	defer restoreEnv(originalEnv)
+
+	t.Run("defaults", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		if cfg.MemoryPruning.Enabled {
+			t.Error("MemoryPruning.Enabled = true, want false (disabled by default)")
+		}
+		if cfg.MemoryPruning.Action != "archive" {
+			t.Errorf("MemoryPruning.Action = %q, want archive", cfg.MemoryPruning.Action)
+		}
+		if cfg.MemoryPruning.MaxMemories != 0 || cfg.MemoryPruning.MinConfidence != 0 || cfg.MemoryPruning.MaxUnusedAge != 0 {
+			t.Error("MemoryPruning rules should be disabled by default")
+		}
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MEMORY_PRUNING_ENABLED", "true")
+		os.Setenv("MEMORY_PRUNING_PROJECT_IDS", "contextd,other")
+		os.Setenv("MEMORY_PRUNING_MAX_MEMORIES", "500")
+		os.Setenv("MEMORY_PRUNING_MIN_CONFIDENCE", "0.2")
+		os.Setenv("MEMORY_PRUNING_MAX_UNUSED_AGE", "2160h")
+		os.Setenv("MEMORY_PRUNING_ACTION", "delete")
+
+		cfg := Load()
+		if !cfg.MemoryPruning.Enabled {
+			t.Error("MemoryPruning.Enabled = false, want true")
+		}
+		if len(cfg.MemoryPruning.ProjectIDs) != 2 {
+			t.Errorf("MemoryPruning.ProjectIDs = %v, want 2 projects", cfg.MemoryPruning.ProjectIDs)
+		}
+		if cfg.MemoryPruning.MaxMemories != 500 {
+			t.Errorf("MemoryPruning.MaxMemories = %d, want 500", cfg.MemoryPruning.MaxMemories)
+		}
+		if cfg.MemoryPruning.MinConfidence != 0.2 {
+			t.Errorf("MemoryPruning.MinConfidence = %v, want 0.2", cfg.MemoryPruning.MinConfidence)
+		}
+		if cfg.MemoryPruning.MaxUnusedAge != 90*24*time.Hour {
+			t.Errorf("MemoryPruning.MaxUnusedAge = %v, want 2160h", cfg.MemoryPruning.MaxUnusedAge)
+		}
+		if cfg.MemoryPruning.Action != "delete" {
+			t.Errorf("MemoryPruning.Action = %q, want delete", cfg.MemoryPruning.Action)
+		}
+	})
+}
+
+// TestLoad_Digest tests knowledge digest configuration loading
+func TestLoad_Digest(t *testing.T) {
+	originalEnv := saveEnv()
+	defer restoreEnv(originalEnv)
+
+	t.Run("defaults", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		if cfg.Digest.Enabled {
+			t.Error("Digest.Enabled = true, want false (disabled by default)")
+		}
+		if cfg.Digest.Interval != 7*24*time.Hour {
+			t.Errorf("Digest.Interval = %v, want 168h", cfg.Digest.Interval)
+		}
+		if cfg.Digest.MinConfidence != 0.7 {
+			t.Errorf("Digest.MinConfidence = %v, want 0.7", cfg.Digest.MinConfidence)
+		}
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("DIGEST_ENABLED", "true")
+		os.Setenv("DIGEST_INTERVAL", "24h")
+		os.Setenv("DIGEST_TEAM_ID", "platform")
+		os.Setenv("DIGEST_PROJECT_IDS", "api,web")
+		os.Setenv("DIGEST_MIN_CONFIDENCE", "0.5")
+
+		cfg := Load()
+		if !cfg.Digest.Enabled {
+			t.Error("Digest.Enabled = false, want true")
+		}
+		if cfg.Digest.Interval != 24*time.Hour {
+			t.Errorf("Digest.Interval = %v, want 24h", cfg.Digest.Interval)
+		}
+		if cfg.Digest.TeamID != "platform" || len(cfg.Digest.ProjectIDs) != 2 {
+			t.Errorf("Digest team/projects = %q/%v, want platform and 2 projects", cfg.Digest.TeamID, cfg.Digest.ProjectIDs)
+		}
+		if cfg.Digest.MinConfidence != 0.5 {
+			t.Errorf("Digest.MinConfidence = %v, want 0.5", cfg.Digest.MinConfidence)
+		}
+	})
+
+	t.Run("invalid confidence", func(t *testing.T) {
+		d := DigestConfig{Interval: time.Hour, MinConfidence: 1.5}
+		if err := d.Validate(); err == nil {
+			t.Error("Validate() = nil, want error for min_confidence above 1")
+		}
+	})
+}
+
+// TestLoad_StorageMonitor tests disk space guardrail configuration loading
+func TestLoad_StorageMonitor(t *testing.T) {
+	originalEnv := saveEnv()
+	defer restoreEnv(originalEnv)
+
+	t.Run("defaults", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		if !cfg.StorageMonitor.Enabled {
+			t.Error("StorageMonitor.Enabled = false, want true (enabled by default)")
+		}
+		if cfg.StorageMonitor.WarnPercent != 85 || cfg.StorageMonitor.CriticalPercent != 95 {
+			t.Errorf("StorageMonitor thresholds = %v/%v, want 85/95",
+				cfg.StorageMonitor.WarnPercent, cfg.StorageMonitor.CriticalPercent)
+		}
+		if cfg.StorageMonitor.MinFreeMB != 512 {
+			t.Errorf("StorageMonitor.MinFreeMB = %d, want 512", cfg.StorageMonitor.MinFreeMB)
+		}
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("STORAGE_MONITOR_ENABLED", "false")
+		os.Setenv("STORAGE_MONITOR_INTERVAL", "1m")
+		os.Setenv("STORAGE_CRITICAL_PERCENT", "98")
+		os.Setenv("STORAGE_MIN_FREE_MB", "2048")
+
+		cfg := Load()
+		if cfg.StorageMonitor.Enabled {
+			t.Error("StorageMonitor.Enabled = true, want false")
+		}
+		if cfg.StorageMonitor.Interval != time.Minute {
+			t.Errorf("StorageMonitor.Interval = %v, want 1m", cfg.StorageMonitor.Interval)
+		}
+		if cfg.StorageMonitor.CriticalPercent != 98 {
+			t.Errorf("StorageMonitor.CriticalPercent = %v, want 98", cfg.StorageMonitor.CriticalPercent)
+		}
+		if cfg.StorageMonitor.MinFreeMB != 2048 {
+			t.Errorf("StorageMonitor.MinFreeMB = %d, want 2048", cfg.StorageMonitor.MinFreeMB)
+		}
+	})
+}
+
+// TestLoad_Analytics tests tool usage analytics configuration loading
+func TestLoad_Analytics(t *testing.T) {
+	originalEnv := saveEnv()
+	defer restoreEnv(originalEnv)
+
+	t.Run("defaults", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		if !cfg.Analytics.Enabled {
+			t.Error("Analytics.Enabled = false, want true (enabled by default)")
+		}
+		if cfg.Analytics.Dir != "~/.config/contextd/analytics" {
+			t.Errorf("Analytics.Dir = %q, want ~/.config/contextd/analytics", cfg.Analytics.Dir)
+		}
+		if cfg.Analytics.FlushInterval != time.Minute {
+			t.Errorf("Analytics.FlushInterval = %v, want 1m", cfg.Analytics.FlushInterval)
+		}
+		if cfg.Analytics.RetentionDays != 90 {
+			t.Errorf("Analytics.RetentionDays = %d, want 90", cfg.Analytics.RetentionDays)
+		}
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("ANALYTICS_ENABLED", "false")
+		os.Setenv("ANALYTICS_DIR", "/var/lib/contextd/analytics")
+		os.Setenv("ANALYTICS_FLUSH_INTERVAL", "30s")
+		os.Setenv("ANALYTICS_RETENTION_DAYS", "14")
+
+		cfg := Load()
+		if cfg.Analytics.Enabled {
+			t.Error("Analytics.Enabled = true, want false")
+		}
+		if cfg.Analytics.Dir != "/var/lib/contextd/analytics" {
+			t.Errorf("Analytics.Dir = %q, want /var/lib/contextd/analytics", cfg.Analytics.Dir)
+		}
+		if cfg.Analytics.FlushInterval != 30*time.Second {
+			t.Errorf("Analytics.FlushInterval = %v, want 30s", cfg.Analytics.FlushInterval)
+		}
+		if cfg.Analytics.RetentionDays != 14 {
+			t.Errorf("Analytics.RetentionDays = %d, want 14", cfg.Analytics.RetentionDays)
+		}
+	})
+}
+
+// TestLoad_Workflows tests Temporal workflow configuration loading
+func TestLoad_Workflows(t *testing.T) {
+	originalEnv := saveEnv()
+	defer restoreEnv(originalEnv)
+
+	t.Run("defaults", func(t *testing.T) {
+		os.Clearenv()
+		cfg := Load()
+		if cfg.Workflows.Enabled {
+			t.Error("Workflows.Enabled = true, want false (disabled by default)")
+		}
+		if cfg.Workflows.TemporalHost != "localhost:7233" {
+			t.Errorf("Workflows.TemporalHost = %q, want localhost:7233", cfg.Workflows.TemporalHost)
+		}
+		if cfg.Workflows.TaskQueue != "contextd-workflows" {
+			t.Errorf("Workflows.TaskQueue = %q, want contextd-workflows", cfg.Workflows.TaskQueue)
+		}
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("WORKFLOWS_ENABLED", "true")
+		os.Setenv("WORKFLOWS_TEMPORAL_HOST", "temporal:7233")
+		os.Setenv("WORKFLOWS_NAMESPACE", "contextd")
+		os.Setenv("GITHUB_TOKEN", "ghp_test")
+
+		cfg := Load()
+		if !cfg.Workflows.Enabled {
+			t.Error("Workflows.Enabled = false, want true")
+		}
+		if cfg.Workflows.TemporalHost != "temporal:7233" {
+			t.Errorf("Workflows.TemporalHost = %q, want temporal:7233", cfg.Workflows.TemporalHost)
+		}
+		if cfg.Workflows.Namespace != "contextd" {
+			t.Errorf("Workflows.Namespace = %q, want contextd", cfg.Workflows.Namespace)
+		}
+		if cfg.Workflows.GitHubToken.Value() != "ghp_test" {
+			t.Error("Workflows.GitHubToken not loaded from GITHUB_TOKEN")
+		}
+	})
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsAt(s, substr))
 }
@@ -546,3 +892,331 @@ func restoreEnv(env map[string]string) {
 		os.Setenv(k, v)
 	}
 }
+
+func TestNotificationsConfig_Validate(t *testing.T) {
+	slack := NotificationChannelConfig{Type: "slack", WebhookURL: "https://hooks.slack.com/services/x"}
+	matrix := NotificationChannelConfig{Type: "matrix", Homeserver: "https://matrix.org", RoomID: "!a:matrix.org", AccessToken: "t"}
+
+	tests := []struct {
+		name    string
+		cfg     NotificationsConfig
+		wantErr string
+	}{
+		{"shorthand slack", NotificationsConfig{SlackWebhookURL: "https://hooks.slack.com/services/x"}, ""},
+		{"channels and routes", NotificationsConfig{
+			Channels: map[string]NotificationChannelConfig{"eng": slack, "ops": matrix},
+			Routes:   []NotificationRouteConfig{{Channels: []string{"eng", "ops"}, MinConfidence: 0.9}},
+		}, ""},
+		{"no channels", NotificationsConfig{}, "no channels"},
+		{"unknown type", NotificationsConfig{Channels: map[string]NotificationChannelConfig{"x": {Type: "email"}}}, "type must be"},
+		{"slack without url", NotificationsConfig{Channels: map[string]NotificationChannelConfig{"x": {Type: "slack"}}}, "webhook_url"},
+		{"matrix without token", NotificationsConfig{Channels: map[string]NotificationChannelConfig{
+			"x": {Type: "matrix", Homeserver: "https://matrix.org", RoomID: "!a:matrix.org"},
+		}}, "access_token"},
+		{"unknown route channel", NotificationsConfig{
+			Channels: map[string]NotificationChannelConfig{"eng": slack},
+			Routes:   []NotificationRouteConfig{{Channels: []string{"ops"}}},
+		}, "unknown channel"},
+		{"confidence out of range", NotificationsConfig{
+			Channels: map[string]NotificationChannelConfig{"eng": slack},
+			Routes:   []NotificationRouteConfig{{Channels: []string{"eng"}, MinConfidence: 1.5}},
+		}, "min_confidence"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), "hooks.slack.com") {
+				t.Errorf("Validate() error leaks the webhook URL: %v", err)
+			}
+		})
+	}
+}
+
+func TestRemediationsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RemediationsConfig
+		wantErr string
+	}{
+		{"no taxonomies", RemediationsConfig{}, ""},
+		{"valid taxonomy", RemediationsConfig{Taxonomies: map[string][]RemediationCategoryConfig{
+			"acme": {
+				{Name: "flaky-test", Builtin: "test", Aliases: []string{"flaky"}},
+				{Name: "security", Builtin: "Security"},
+			},
+		}}, ""},
+		{"missing name", RemediationsConfig{Taxonomies: map[string][]RemediationCategoryConfig{
+			"acme": {{Builtin: "test"}},
+		}}, "name is required"},
+		{"unknown builtin", RemediationsConfig{Taxonomies: map[string][]RemediationCategoryConfig{
+			"acme": {{Name: "dependency", Builtin: "build"}},
+		}}, "builtin must be"},
+		{"builtin refining another", RemediationsConfig{Taxonomies: map[string][]RemediationCategoryConfig{
+			"acme": {{Name: "lint", Builtin: "compile"}},
+		}}, "can only refine itself"},
+		{"alias reused", RemediationsConfig{Taxonomies: map[string][]RemediationCategoryConfig{
+			"acme": {
+				{Name: "flaky-test", Builtin: "test"},
+				{Name: "timeout", Builtin: "runtime", Aliases: []string{"flaky-test"}},
+			},
+		}}, `"flaky-test" is already used`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepositoryConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RepositoryConfig
+		wantErr string
+	}{
+		{"no preprocessors", RepositoryConfig{}, ""},
+		{"valid rules", RepositoryConfig{Preprocessors: []RepositoryPreprocessorConfig{
+			{Pattern: "*.ipynb", Use: []string{"render_notebook"}},
+			{Pattern: "infra/**", Use: []string{"strip_license_header", "expand_terraform_modules"}},
+		}}, ""},
+		{"missing pattern", RepositoryConfig{Preprocessors: []RepositoryPreprocessorConfig{
+			{Use: []string{"render_notebook"}},
+		}}, "pattern is required"},
+		{"invalid pattern", RepositoryConfig{Preprocessors: []RepositoryPreprocessorConfig{
+			{Pattern: "[", Use: []string{"render_notebook"}},
+		}}, "invalid pattern"},
+		{"nothing to use", RepositoryConfig{Preprocessors: []RepositoryPreprocessorConfig{
+			{Pattern: "*.go"},
+		}}, "use must name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckpointConfig_Validate(t *testing.T) {
+	for _, store := range []string{"", "vectorstore", "filesystem", "sqlite"} {
+		cfg := CheckpointConfig{StateStore: store}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with state store %q error = %v, want nil", store, err)
+		}
+	}
+	cfg := CheckpointConfig{StateStore: "s3"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "CHECKPOINT_STATE_STORE") {
+		t.Errorf("Validate() error = %v, want CHECKPOINT_STATE_STORE error", err)
+	}
+}
+
+func TestConversationsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ConversationsConfig
+		wantErr string
+	}{
+		{"default", ConversationsConfig{}, ""},
+		{"strict with tenant override", ConversationsConfig{
+			Redaction:       "strict",
+			TenantRedaction: map[string]string{"oss": "standard"},
+			InternalDomains: []string{"acme.net"},
+		}, ""},
+		{"unknown default", ConversationsConfig{Redaction: "paranoid"}, "redaction must be"},
+		{"unknown tenant profile", ConversationsConfig{TenantRedaction: map[string]string{"acme": "none"}}, `tenant_redaction "acme"`},
+		{"custom domains", ConversationsConfig{
+			DomainClassifier: "embedding",
+			DomainThreshold:  0.6,
+			Domains:          []ConversationDomainConfig{{Name: "payments", Description: "billing, invoices, Stripe"}},
+		}, ""},
+		{"unknown classifier", ConversationsConfig{DomainClassifier: "llm"}, "domain_classifier must be"},
+		{"threshold out of range", ConversationsConfig{DomainThreshold: 2}, "domain_threshold must be"},
+		{"unnamed domain", ConversationsConfig{Domains: []ConversationDomainConfig{{Description: "billing"}}}, "name is required"},
+		{"duplicate domain", ConversationsConfig{Domains: []ConversationDomainConfig{{Name: "auth"}, {Name: "auth"}}}, "defined twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFoldingConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     FoldingConfig
+		wantErr bool
+	}{
+		{"default", FoldingConfig{}, false},
+		{"target", FoldingConfig{ReturnTargetTokens: 1024}, false},
+		{"negative target", FoldingConfig{ReturnTargetTokens: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLLMConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     LLMConfig
+		wantErr bool
+		enabled bool
+	}{
+		{"default", LLMConfig{}, false, false},
+		{"monthly budget", LLMConfig{MonthlyTokenBudget: 1_000_000}, false, true},
+		{"tenant budget", LLMConfig{TenantTokenBudgets: map[string]int64{"acme": 500}}, false, true},
+		{"unlimited tenant", LLMConfig{TenantTokenBudgets: map[string]int64{"acme": 0}}, false, false},
+		{"negative budget", LLMConfig{MonthlyTokenBudget: -1}, true, false},
+		{"negative tenant budget", LLMConfig{TenantTokenBudgets: map[string]int64{"acme": -5}}, true, false},
+		{"resilience", LLMConfig{Timeout: 30 * time.Second, MaxRetries: 3, CircuitFailureThreshold: 5, CircuitCooldown: time.Minute}, false, false},
+		{"negative timeout", LLMConfig{Timeout: -time.Second}, true, false},
+		{"negative retries", LLMConfig{MaxRetries: -1}, true, false},
+		{"negative circuit cooldown", LLMConfig{CircuitCooldown: -time.Second}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tt.cfg.BudgetEnabled() != tt.enabled {
+				t.Errorf("BudgetEnabled() = %v, want %v", tt.cfg.BudgetEnabled(), tt.enabled)
+			}
+		})
+	}
+}
+
+func TestSearchScoringConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SearchScoringConfig
+		wantErr bool
+	}{
+		{"zero", SearchScoringConfig{}, false},
+		{"weights", SearchScoringConfig{SimilarityWeight: 0.7, ConfidenceWeight: 0.2, RecencyWeight: 0.1, RecencyHalfLife: 336 * time.Hour}, false},
+		{"category boosts", SearchScoringConfig{CategoryBoosts: map[string]float64{"security": 1.5, "general": 0.8}}, false},
+		{"negative weight", SearchScoringConfig{RecencyWeight: -0.1}, true},
+		{"negative half-life", SearchScoringConfig{RecencyHalfLife: -time.Hour}, true},
+		{"negative consolidation boost", SearchScoringConfig{ConsolidationBoost: -1}, true},
+		{"zero category boost", SearchScoringConfig{CategoryBoosts: map[string]float64{"debugging": 0}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	originalEnv := saveEnv()
+	defer restoreEnv(originalEnv)
+	os.Clearenv()
+	os.Setenv("CONTEXTD_REASONINGBANK_SCORING_CONFIDENCE_WEIGHT", "0.3")
+	os.Setenv("CONTEXTD_REASONINGBANK_SCORING_RECENCY_HALF_LIFE", "168h")
+	scoring := Load().ReasoningBank.Scoring
+	if scoring.SimilarityWeight != 1 || scoring.ConfidenceWeight != 0.3 || scoring.RecencyWeight != 0 {
+		t.Errorf("weights = %g/%g/%g, want 1/0.3/0", scoring.SimilarityWeight, scoring.ConfidenceWeight, scoring.RecencyWeight)
+	}
+	if scoring.RecencyHalfLife != 168*time.Hour {
+		t.Errorf("RecencyHalfLife = %v, want 168h", scoring.RecencyHalfLife)
+	}
+	if scoring.ConsolidationBoost != 1.2 {
+		t.Errorf("ConsolidationBoost = %g, want 1.2", scoring.ConsolidationBoost)
+	}
+}
+
+func TestSearchExperimentConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SearchExperimentConfig
+		wantErr bool
+	}{
+		{"disabled", SearchExperimentConfig{TreatmentPercent: 500}, false},
+		{"rerank treatment", SearchExperimentConfig{Name: "rerank", Treatment: SearchRankingConfig{Rerank: true}}, false},
+		{"boosts", SearchExperimentConfig{Name: "boost", TreatmentPercent: 20, Treatment: SearchRankingConfig{ConsolidationBoost: 1.5}}, false},
+		{"percent too high", SearchExperimentConfig{Name: "x", TreatmentPercent: 101}, true},
+		{"negative percent", SearchExperimentConfig{Name: "x", TreatmentPercent: -1}, true},
+		{"negative boost", SearchExperimentConfig{Name: "x", Control: SearchRankingConfig{EntityBoost: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerConfig_SocketFileMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0600", 0600, false},
+		{"660", 0660, false},
+		{"0400", 0, true},
+		{"0777x", 0, true},
+		{"01777", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ServerConfig{SocketMode: tt.mode}.SocketFileMode()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SocketFileMode(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SocketFileMode(%q) = %o, want %o", tt.mode, got, tt.want)
+		}
+	}
+}
@@ -20,6 +20,21 @@ const (
 	maxConfigFileSize = 1024 * 1024 // 1MB
 )
 
+// defaultOn lists boolean settings that are on unless configured off. They
+// are seeded before the file and environment are loaded, since applyDefaults
+// cannot tell an explicit false from a missing key.
+var defaultOn = []string{
+	"storagemonitor.enabled",
+	"analytics.enabled",
+}
+
+// defaultValues lists other settings whose zero value is a valid choice,
+// seeded the same way. A similarity weight of 0 drops similarity from
+// ranking, so it must not stand in for a missing key.
+var defaultValues = map[string]interface{}{
+	"reasoningbank.scoring.similarity_weight": 1.0,
+}
+
 // getHomeDir returns the user's home directory.
 // It prefers the HOME environment variable when set (for testability),
 // falling back to os.UserHomeDir() which may use platform-specific methods.
@@ -85,6 +100,17 @@ func LoadWithFile(configPath string) (*Config, error) {
 	if err := validateConfigPath(configPath); err != nil {
 		return nil, fmt.Errorf("config path validation failed: %w", err)
 	}
+	for _, key := range defaultOn {
+		if err := k.Set(key, true); err != nil {
+			return nil, fmt.Errorf("failed to set default %s: %w", key, err)
+		}
+	}
+	for key, value := range defaultValues {
+		if err := k.Set(key, value); err != nil {
+			return nil, fmt.Errorf("failed to set default %s: %w", key, err)
+		}
+	}
+
 	// Load from YAML file if it exists
 	if _, err := os.Stat(configPath); err == nil {
 		// Open file once and validate using file descriptor to avoid TOCTOU race
@@ -291,10 +317,26 @@ func applyDefaults(cfg *Config) {
 		}
 	}
 
+	// Profile can also be chosen from the environment
+	if cfg.Profile == "" {
+		cfg.Profile = os.Getenv("CONTEXTD_PROFILE")
+	}
+
 	// Observability defaults
 	if cfg.Observability.ServiceName == "" {
 		cfg.Observability.ServiceName = "contextd"
 	}
+	if cfg.Observability.SamplingRate == 0 {
+		cfg.Observability.SamplingRate = 1.0
+	}
+
+	// Consolidation scheduler defaults
+	if cfg.ConsolidationScheduler.Interval == 0 {
+		cfg.ConsolidationScheduler.Interval = 24 * time.Hour
+	}
+	if cfg.ConsolidationScheduler.SimilarityThreshold == 0 {
+		cfg.ConsolidationScheduler.SimilarityThreshold = 0.8
+	}
 
 	// PreFetch defaults (only if enabled but values not set)
 	if cfg.PreFetch.Enabled {
@@ -333,9 +375,62 @@ func applyDefaults(cfg *Config) {
 	if cfg.VectorStore.Chromem.VectorSize == 0 {
 		cfg.VectorStore.Chromem.VectorSize = 384 // bge-small-en-v1.5 dimensions
 	}
+	if cfg.VectorStore.Pgvector.DefaultCollection == "" {
+		cfg.VectorStore.Pgvector.DefaultCollection = "contextd_default"
+	}
+	if cfg.VectorStore.Pgvector.VectorSize == 0 {
+		cfg.VectorStore.Pgvector.VectorSize = 384
+	}
 	// Compress defaults to true (set explicitly since zero value is false)
 	// Note: This is handled in Load() with getEnvBool
 
+	// Storage monitor thresholds; Enabled defaults to true via defaultOn
+	if cfg.StorageMonitor.Interval == 0 {
+		cfg.StorageMonitor.Interval = 30 * time.Second
+	}
+	if cfg.StorageMonitor.WarnPercent == 0 {
+		cfg.StorageMonitor.WarnPercent = 85
+	}
+	if cfg.StorageMonitor.CriticalPercent == 0 {
+		cfg.StorageMonitor.CriticalPercent = 95
+	}
+	if cfg.StorageMonitor.MinFreeMB == 0 {
+		cfg.StorageMonitor.MinFreeMB = 512
+	}
+
+	// Tool analytics settings; Enabled defaults to true via defaultOn
+	if cfg.Analytics.Dir == "" {
+		cfg.Analytics.Dir = "~/.config/contextd/analytics"
+	}
+	if cfg.Analytics.FlushInterval == 0 {
+		cfg.Analytics.FlushInterval = time.Minute
+	}
+	if cfg.Analytics.RetentionDays == 0 {
+		cfg.Analytics.RetentionDays = 90
+	}
+	if cfg.Analytics.SessionRetentionDays == 0 {
+		cfg.Analytics.SessionRetentionDays = 14
+	}
+
+	// Temporal workflows are off by default; fill in connection defaults
+	if cfg.Workflows.TemporalHost == "" {
+		cfg.Workflows.TemporalHost = "localhost:7233"
+	}
+	if cfg.Workflows.Namespace == "" {
+		cfg.Workflows.Namespace = "default"
+	}
+	if cfg.Workflows.TaskQueue == "" {
+		cfg.Workflows.TaskQueue = "contextd-workflows"
+	}
+
+	// Conversation transcripts are only scrubbed of secrets by default
+	if cfg.Conversations.Redaction == "" {
+		cfg.Conversations.Redaction = "standard"
+	}
+	if cfg.Conversations.DomainClassifier == "" {
+		cfg.Conversations.DomainClassifier = "embedding"
+	}
+
 	// Embeddings defaults
 	if cfg.Embeddings.BaseURL == "" {
 		cfg.Embeddings.BaseURL = "http://localhost:8080"
@@ -343,6 +438,11 @@ func applyDefaults(cfg *Config) {
 	if cfg.Embeddings.Model == "" {
 		cfg.Embeddings.Model = "BAAI/bge-small-en-v1.5"
 	}
+
+	// The Anthropic key falls back to the provider's conventional variable
+	if !cfg.LLM.AnthropicAPIKey.IsSet() {
+		cfg.LLM.AnthropicAPIKey = Secret(os.Getenv("ANTHROPIC_API_KEY"))
+	}
 }
 
 // loadProductionConfig loads production configuration from environment variables.
@@ -342,3 +342,259 @@ func TestLoadWithFile_FileTooLarge(t *testing.T) {
 		t.Errorf("Expected 'too large' error, got: %v", err)
 	}
 }
+
+// TestLoadWithFile_Notifications tests loading notification channels and routes.
+func TestLoadWithFile_Notifications(t *testing.T) {
+	home, cleanup := setupTestHome(t)
+	defer cleanup()
+
+	configDir := filepath.Join(home, ".config", "contextd")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	yamlContent := `notifications:
+  enabled: true
+  channels:
+    eng:
+      type: slack
+      webhook_url: https://hooks.slack.com/services/T0/B0/secret
+    ops:
+      type: matrix
+      homeserver: https://matrix.example.org
+      room_id: "!ops:example.org"
+      access_token: syt_secret
+  routes:
+    - channels: [eng]
+      events: [remediation.recorded]
+      min_confidence: 0.9
+    - channels: [eng, ops]
+      events: [consolidation.completed, reflection.report_ready]
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+
+	n := cfg.Notifications
+	if !n.Enabled {
+		t.Error("Notifications.Enabled = false, want true")
+	}
+	if got := n.Channels["eng"].WebhookURL.Value(); got != "https://hooks.slack.com/services/T0/B0/secret" {
+		t.Errorf("eng webhook_url = %q", got)
+	}
+	if got := n.Channels["ops"].RoomID; got != "!ops:example.org" {
+		t.Errorf("ops room_id = %q", got)
+	}
+	if len(n.Routes) != 2 {
+		t.Fatalf("len(Routes) = %d, want 2", len(n.Routes))
+	}
+	if n.Routes[0].MinConfidence != 0.9 {
+		t.Errorf("Routes[0].MinConfidence = %v, want 0.9", n.Routes[0].MinConfidence)
+	}
+	if len(n.Routes[1].Channels) != 2 || n.Routes[1].Events[1] != "reflection.report_ready" {
+		t.Errorf("Routes[1] = %+v", n.Routes[1])
+	}
+}
+
+// TestLoadWithFile_ConversationRedaction tests loading per-tenant redaction profiles.
+func TestLoadWithFile_ConversationRedaction(t *testing.T) {
+	home, cleanup := setupTestHome(t)
+	defer cleanup()
+
+	configDir := filepath.Join(home, ".config", "contextd")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	yamlContent := `conversations:
+  tenant_redaction:
+    acme: strict
+  internal_domains: [acme.net]
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+
+	c := cfg.Conversations
+	if c.Redaction != "standard" {
+		t.Errorf("Redaction = %q, want standard", c.Redaction)
+	}
+	if c.TenantRedaction["acme"] != "strict" {
+		t.Errorf("TenantRedaction[acme] = %q, want strict", c.TenantRedaction["acme"])
+	}
+	if len(c.InternalDomains) != 1 || c.InternalDomains[0] != "acme.net" {
+		t.Errorf("InternalDomains = %v, want [acme.net]", c.InternalDomains)
+	}
+}
+
+// TestLoadWithFile_RemediationTaxonomy tests loading a per-tenant remediation
+// category taxonomy.
+func TestLoadWithFile_RemediationTaxonomy(t *testing.T) {
+	home, cleanup := setupTestHome(t)
+	defer cleanup()
+
+	configDir := filepath.Join(home, ".config", "contextd")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	yamlContent := `remediations:
+  taxonomies:
+    acme:
+      - name: flaky-test
+        builtin: test
+        description: Tests that fail intermittently
+        aliases: [flaky]
+      - name: dependency
+        builtin: compile
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+
+	categories := cfg.Remediations.Taxonomies["acme"]
+	if len(categories) != 2 {
+		t.Fatalf("len(Taxonomies[acme]) = %d, want 2", len(categories))
+	}
+	if c := categories[0]; c.Name != "flaky-test" || c.Builtin != "test" || len(c.Aliases) != 1 || c.Aliases[0] != "flaky" {
+		t.Errorf("Taxonomies[acme][0] = %+v", c)
+	}
+}
+
+// TestLoadWithFile_StorageMonitorDisabled tests that the storage monitor,
+// which is on by default, can be turned off in YAML.
+func TestLoadWithFile_StorageMonitorDisabled(t *testing.T) {
+	home, cleanup := setupTestHome(t)
+	defer cleanup()
+
+	configDir := filepath.Join(home, ".config", "contextd")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	cfg, err := LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+	if !cfg.StorageMonitor.Enabled {
+		t.Error("StorageMonitor.Enabled = false without a config file, want true")
+	}
+
+	yamlContent := `storagemonitor:
+  enabled: false
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err = LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+	if cfg.StorageMonitor.Enabled {
+		t.Error("StorageMonitor.Enabled = true, want false")
+	}
+	if cfg.StorageMonitor.CriticalPercent != 95 {
+		t.Errorf("StorageMonitor.CriticalPercent = %v, want 95", cfg.StorageMonitor.CriticalPercent)
+	}
+}
+
+func TestLoadWithFile_AnalyticsDisabled(t *testing.T) {
+	home, cleanup := setupTestHome(t)
+	defer cleanup()
+
+	configDir := filepath.Join(home, ".config", "contextd")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	cfg, err := LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+	if !cfg.Analytics.Enabled {
+		t.Error("Analytics.Enabled = false without a config file, want true")
+	}
+
+	yamlContent := `analytics:
+  enabled: false
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err = LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+	if cfg.Analytics.Enabled {
+		t.Error("Analytics.Enabled = true, want false")
+	}
+	if cfg.Analytics.RetentionDays != 90 {
+		t.Errorf("Analytics.RetentionDays = %v, want 90", cfg.Analytics.RetentionDays)
+	}
+}
+
+func TestLoadWithFile_ScoringSimilarityDefault(t *testing.T) {
+	home, cleanup := setupTestHome(t)
+	defer cleanup()
+
+	configDir := filepath.Join(home, ".config", "contextd")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	yamlContent := `reasoningbank:
+  scoring:
+    confidence_weight: 0.3
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+	if got := cfg.ReasoningBank.Scoring; got.SimilarityWeight != 1 || got.ConfidenceWeight != 0.3 {
+		t.Errorf("weights = %g/%g, want 1/0.3", got.SimilarityWeight, got.ConfidenceWeight)
+	}
+
+	yamlContent = `reasoningbank:
+  scoring:
+    similarity_weight: 0
+    confidence_weight: 1
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0600); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err = LoadWithFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadWithFile() error = %v, want nil", err)
+	}
+	if got := cfg.ReasoningBank.Scoring.SimilarityWeight; got != 0 {
+		t.Errorf("SimilarityWeight = %g, want an explicit 0 kept", got)
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Profile names accepted by `profile:` in config.yaml and `contextd --profile`.
+const (
+	ProfileSolo       = "solo"
+	ProfileTeam       = "team"
+	ProfileEnterprise = "enterprise"
+)
+
+// profileSetting is one value a profile sets. The profile only changes the
+// setting while it still holds its built-in default, so anything set
+// explicitly in config.yaml or the environment wins.
+type profileSetting struct {
+	key   string // YAML key, for the printed diff
+	value any
+	get   func(*Config) any
+	set   func(*Config)
+}
+
+// ProfileChange is a setting a profile changed from its built-in default.
+type ProfileChange struct {
+	Key     string
+	Default string
+	Value   string
+}
+
+// profiles maps each profile to the settings it applies:
+//
+//   - solo: one developer on localhost. The built-in defaults: no
+//     authentication, telemetry off, consolidation run on demand.
+//   - team: a shared instance. Nightly consolidation and sampled telemetry.
+//   - enterprise: production mode, which requires authentication and TLS and
+//     rejects NoIsolation, with lightly sampled telemetry and consolidation
+//     twice a day.
+var profiles = map[string][]profileSetting{
+	ProfileSolo: nil,
+	ProfileTeam: {
+		consolidationEnabled(true),
+		consolidationInterval(24 * time.Hour),
+		telemetryEnabled(true),
+		samplingRate(0.25),
+	},
+	ProfileEnterprise: {
+		productionFlag("production.enabled", true, func(p *ProductionConfig) *bool { return &p.Enabled }),
+		productionFlag("production.require_authentication", true, func(p *ProductionConfig) *bool { return &p.RequireAuthentication }),
+		productionFlag("production.require_tls", true, func(p *ProductionConfig) *bool { return &p.RequireTLS }),
+		consolidationEnabled(true),
+		consolidationInterval(12 * time.Hour),
+		telemetryEnabled(true),
+		samplingRate(0.1),
+	},
+}
+
+// defaults holds the built-in value of each setting a profile may change.
+var defaults = map[string]any{
+	"production.enabled":                false,
+	"production.require_authentication": false,
+	"production.require_tls":            false,
+	"consolidationscheduler.enabled":    false,
+	"consolidationscheduler.interval":   24 * time.Hour,
+	"observability.enable_telemetry":    false,
+	"observability.sampling_rate":       1.0,
+}
+
+// Profiles returns the names of the available profiles, sorted.
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateProfile returns an error if name is not a known profile. An empty
+// name means no profile.
+func ValidateProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("unknown profile %q (must be one of: %s)", name, strings.Join(Profiles(), ", "))
+	}
+	return nil
+}
+
+// ApplyProfile applies the named profile to cfg and returns what it changed
+// relative to the built-in defaults. Settings already changed from their
+// defaults are left alone. An empty name applies no profile.
+func ApplyProfile(cfg *Config, name string) ([]ProfileChange, error) {
+	if err := ValidateProfile(name); err != nil {
+		return nil, err
+	}
+	cfg.Profile = name
+
+	var changes []ProfileChange
+	for _, s := range profiles[name] {
+		def := defaults[s.key]
+		if s.get(cfg) != def || s.value == def {
+			continue
+		}
+		s.set(cfg)
+		changes = append(changes, ProfileChange{
+			Key:     s.key,
+			Default: fmt.Sprint(def),
+			Value:   fmt.Sprint(s.value),
+		})
+	}
+	return changes, nil
+}
+
+// FormatProfileChanges renders the changes a profile made as a diff against
+// the built-in defaults.
+func FormatProfileChanges(name string, changes []ProfileChange) string {
+	var b strings.Builder
+	if len(changes) == 0 {
+		fmt.Fprintf(&b, "profile %s: no changes from defaults\n", name)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "profile %s: %d settings changed from defaults\n", name, len(changes))
+	for _, c := range changes {
+		fmt.Fprintf(&b, "  - %s: %s\n  + %s: %s\n", c.Key, c.Default, c.Key, c.Value)
+	}
+	return b.String()
+}
+
+func productionFlag(key string, value bool, field func(*ProductionConfig) *bool) profileSetting {
+	return profileSetting{
+		key:   key,
+		value: value,
+		get:   func(c *Config) any { return *field(&c.Production) },
+		set:   func(c *Config) { *field(&c.Production) = value },
+	}
+}
+
+func consolidationEnabled(value bool) profileSetting {
+	return profileSetting{
+		key:   "consolidationscheduler.enabled",
+		value: value,
+		get:   func(c *Config) any { return c.ConsolidationScheduler.Enabled },
+		set:   func(c *Config) { c.ConsolidationScheduler.Enabled = value },
+	}
+}
+
+func consolidationInterval(value time.Duration) profileSetting {
+	return profileSetting{
+		key:   "consolidationscheduler.interval",
+		value: value,
+		get:   func(c *Config) any { return c.ConsolidationScheduler.Interval },
+		set:   func(c *Config) { c.ConsolidationScheduler.Interval = value },
+	}
+}
+
+func telemetryEnabled(value bool) profileSetting {
+	return profileSetting{
+		key:   "observability.enable_telemetry",
+		value: value,
+		get:   func(c *Config) any { return c.Observability.EnableTelemetry },
+		set:   func(c *Config) { c.Observability.EnableTelemetry = value },
+	}
+}
+
+func samplingRate(value float64) profileSetting {
+	return profileSetting{
+		key:   "observability.sampling_rate",
+		value: value,
+		get:   func(c *Config) any { return c.Observability.SamplingRate },
+		set:   func(c *Config) { c.Observability.SamplingRate = value },
+	}
+}
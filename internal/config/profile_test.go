@@ -0,0 +1,116 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyProfile(t *testing.T) {
+	t.Run("solo keeps defaults", func(t *testing.T) {
+		cfg := Load()
+		changes, err := ApplyProfile(cfg, ProfileSolo)
+		if err != nil {
+			t.Fatalf("ApplyProfile() error = %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("ApplyProfile(solo) changes = %v, want none", changes)
+		}
+		if cfg.Profile != ProfileSolo {
+			t.Errorf("Profile = %q, want %q", cfg.Profile, ProfileSolo)
+		}
+	})
+
+	t.Run("team enables consolidation and sampled telemetry", func(t *testing.T) {
+		cfg := Load()
+		changes, err := ApplyProfile(cfg, ProfileTeam)
+		if err != nil {
+			t.Fatalf("ApplyProfile() error = %v", err)
+		}
+		if !cfg.ConsolidationScheduler.Enabled {
+			t.Error("ConsolidationScheduler.Enabled = false, want true")
+		}
+		if !cfg.Observability.EnableTelemetry {
+			t.Error("Observability.EnableTelemetry = false, want true")
+		}
+		if cfg.Observability.SamplingRate != 0.25 {
+			t.Errorf("Observability.SamplingRate = %v, want 0.25", cfg.Observability.SamplingRate)
+		}
+		// The 24h interval is already the default, so it is not a change
+		if len(changes) != 3 {
+			t.Errorf("ApplyProfile(team) changes = %v, want 3", changes)
+		}
+		if cfg.Production.Enabled {
+			t.Error("Production.Enabled = true, want false")
+		}
+	})
+
+	t.Run("enterprise requires production settings", func(t *testing.T) {
+		cfg := Load()
+		if _, err := ApplyProfile(cfg, ProfileEnterprise); err != nil {
+			t.Fatalf("ApplyProfile() error = %v", err)
+		}
+		if !cfg.Production.Enabled || !cfg.Production.RequireAuthentication || !cfg.Production.RequireTLS {
+			t.Errorf("Production = %+v, want enabled with auth and TLS required", cfg.Production)
+		}
+		if cfg.ConsolidationScheduler.Interval != 12*time.Hour {
+			t.Errorf("ConsolidationScheduler.Interval = %v, want 12h", cfg.ConsolidationScheduler.Interval)
+		}
+		// Authentication has not been configured, so validation must fail
+		if err := cfg.Validate(); err == nil {
+			t.Error("Validate() = nil, want authentication error")
+		}
+		cfg.Production.AuthenticationConfigured = true
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+	})
+
+	t.Run("explicit settings win", func(t *testing.T) {
+		cfg := Load()
+		cfg.Observability.SamplingRate = 0.5
+		cfg.ConsolidationScheduler.Interval = 6 * time.Hour
+		changes, err := ApplyProfile(cfg, ProfileEnterprise)
+		if err != nil {
+			t.Fatalf("ApplyProfile() error = %v", err)
+		}
+		if cfg.Observability.SamplingRate != 0.5 {
+			t.Errorf("Observability.SamplingRate = %v, want 0.5", cfg.Observability.SamplingRate)
+		}
+		if cfg.ConsolidationScheduler.Interval != 6*time.Hour {
+			t.Errorf("ConsolidationScheduler.Interval = %v, want 6h", cfg.ConsolidationScheduler.Interval)
+		}
+		for _, c := range changes {
+			if c.Key == "observability.sampling_rate" || c.Key == "consolidationscheduler.interval" {
+				t.Errorf("ApplyProfile() reported change to explicit setting %s", c.Key)
+			}
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		cfg := Load()
+		if _, err := ApplyProfile(cfg, "startup"); err == nil {
+			t.Error("ApplyProfile(startup) error = nil, want error")
+		}
+		cfg.Profile = "startup"
+		if err := cfg.Validate(); err == nil {
+			t.Error("Validate() error = nil, want unknown profile error")
+		}
+	})
+}
+
+func TestFormatProfileChanges(t *testing.T) {
+	got := FormatProfileChanges(ProfileTeam, []ProfileChange{
+		{Key: "observability.sampling_rate", Default: "1", Value: "0.25"},
+	})
+	want := "profile team: 1 settings changed from defaults\n" +
+		"  - observability.sampling_rate: 1\n" +
+		"  + observability.sampling_rate: 0.25\n"
+	if got != want {
+		t.Errorf("FormatProfileChanges() = %q, want %q", got, want)
+	}
+
+	if got := FormatProfileChanges(ProfileSolo, nil); !strings.Contains(got, "no changes") {
+		t.Errorf("FormatProfileChanges(solo, nil) = %q, want no changes", got)
+	}
+}
@@ -0,0 +1,526 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Secret references.
+//
+// A Secret value in config.yaml or the environment may name where the secret
+// lives instead of holding it, so credentials never sit in plaintext config:
+//
+//	env:ANTHROPIC_API_KEY          environment variable
+//	file:anthropic_api_key         file in ~/.config/contextd/secrets (or an absolute path)
+//	keychain:anthropic_api_key     OS keychain (macOS Keychain, Secret Service on Linux)
+//	vault:contextd/llm#anthropic   HashiCorp Vault KV v2 (path#field, field defaults to "value")
+//
+// Values without a known scheme are used as is.
+
+// Secret backend names, used as reference schemes.
+const (
+	SecretBackendEnv      = "env"
+	SecretBackendFile     = "file"
+	SecretBackendKeychain = "keychain"
+	SecretBackendVault    = "vault"
+)
+
+// keychainService is the service name secrets are stored under in the OS
+// keychain.
+const keychainService = "contextd"
+
+var (
+	// ErrSecretNotFound is returned when a backend has no secret by that name.
+	ErrSecretNotFound = errors.New("secret not found")
+
+	// ErrSecretBackendUnsupported is returned for unknown backends and for
+	// operations a backend cannot perform on this platform.
+	ErrSecretBackendUnsupported = errors.New("secret backend unsupported")
+
+	// secretNameRegex restricts secret names so they are safe as file names,
+	// keychain accounts and environment variable names.
+	secretNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// SecretBackend stores and retrieves named secrets.
+type SecretBackend interface {
+	// Get returns the secret stored under name, or ErrSecretNotFound.
+	Get(ctx context.Context, name string) (string, error)
+
+	// Set stores value under name, replacing any existing value.
+	Set(ctx context.Context, name, value string) error
+}
+
+// ParseSecretRef splits a secret reference into its backend and name. ok is
+// false for values that are not references.
+func ParseSecretRef(s string) (backend, name string, ok bool) {
+	backend, name, found := strings.Cut(s, ":")
+	if !found || name == "" {
+		return "", "", false
+	}
+	switch backend {
+	case SecretBackendEnv, SecretBackendFile, SecretBackendKeychain, SecretBackendVault:
+		return backend, name, true
+	default:
+		return "", "", false
+	}
+}
+
+// SecretResolver resolves secret references against a set of backends.
+type SecretResolver struct {
+	backends map[string]SecretBackend
+}
+
+// NewSecretResolver returns a resolver with the env, file, keychain and vault
+// backends. The vault backend is configured from VAULT_ADDR, VAULT_TOKEN and
+// VAULT_MOUNT.
+func NewSecretResolver() (*SecretResolver, error) {
+	dir, err := DefaultSecretsDir()
+	if err != nil {
+		return nil, err
+	}
+	return &SecretResolver{backends: map[string]SecretBackend{
+		SecretBackendEnv:      EnvSecretBackend{},
+		SecretBackendFile:     &FileSecretBackend{Dir: dir},
+		SecretBackendKeychain: &KeychainSecretBackend{},
+		SecretBackendVault:    NewVaultSecretBackendFromEnv(),
+	}}, nil
+}
+
+// WithBackend returns the resolver with the named backend replaced.
+func (r *SecretResolver) WithBackend(name string, b SecretBackend) *SecretResolver {
+	if r.backends == nil {
+		r.backends = make(map[string]SecretBackend)
+	}
+	r.backends[name] = b
+	return r
+}
+
+// Backend returns the named backend.
+func (r *SecretResolver) Backend(name string) (SecretBackend, error) {
+	b, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (want env, file, keychain or vault)", ErrSecretBackendUnsupported, name)
+	}
+	return b, nil
+}
+
+// Resolve returns the secret a reference points to. Values that are not
+// references are returned unchanged.
+func (r *SecretResolver) Resolve(ctx context.Context, s Secret) (Secret, error) {
+	backend, name, ok := ParseSecretRef(s.Value())
+	if !ok {
+		return s, nil
+	}
+	b, err := r.Backend(backend)
+	if err != nil {
+		return "", err
+	}
+	v, err := b.Get(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s secret %q: %w", backend, name, err)
+	}
+	return Secret(v), nil
+}
+
+// ResolveSecrets replaces every Secret reference in cfg with the value it
+// points to. Errors name the config field but never the secret.
+func ResolveSecrets(ctx context.Context, cfg *Config, r *SecretResolver) error {
+	return resolveSecretsIn(ctx, reflect.ValueOf(cfg).Elem(), "", r)
+}
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// resolveSecretsIn walks v, resolving Secret fields in structs, maps and
+// slices.
+func resolveSecretsIn(ctx context.Context, v reflect.Value, path string, r *SecretResolver) error {
+	switch {
+	case v.Type() == secretType:
+		resolved, err := r.Resolve(ctx, Secret(v.String()))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetString(string(resolved))
+		return nil
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if err := resolveSecretsIn(ctx, v.Field(i), joinSecretPath(path, f.Name), r); err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Pointer:
+		if !v.IsNil() {
+			return resolveSecretsIn(ctx, v.Elem(), path, r)
+		}
+	case v.Kind() == reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveSecretsIn(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i), r); err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Map:
+		// Map values are not addressable: resolve a copy and store it back.
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := resolveSecretsIn(ctx, elem, fmt.Sprintf("%s[%v]", path, iter.Key()), r); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+func joinSecretPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// validateSecretName rejects names that could escape the file backend's
+// directory or be misread by the keychain tools.
+func validateSecretName(name string) error {
+	if !secretNameRegex.MatchString(name) || name == "." || name == ".." {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// EnvSecretBackend reads secrets from environment variables. It is read-only.
+type EnvSecretBackend struct{}
+
+// Get returns the environment variable name.
+func (EnvSecretBackend) Get(_ context.Context, name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+// Set is not supported: the environment belongs to the parent process.
+func (EnvSecretBackend) Set(context.Context, string, string) error {
+	return fmt.Errorf("%w: environment variables are read-only", ErrSecretBackendUnsupported)
+}
+
+// DefaultSecretsDir returns the file backend directory,
+// ~/.config/contextd/secrets.
+func DefaultSecretsDir() (string, error) {
+	home, err := getHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "contextd", "secrets"), nil
+}
+
+// FileSecretBackend stores each secret in its own 0600 file under Dir.
+// References may also name an absolute path, e.g. a mounted Kubernetes or
+// Docker secret; the file is read with surrounding whitespace trimmed.
+type FileSecretBackend struct {
+	Dir string
+}
+
+// Get reads the secret file.
+func (b *FileSecretBackend) Get(_ context.Context, name string) (string, error) {
+	path := name
+	if !filepath.IsAbs(name) {
+		if err := validateSecretName(name); err != nil {
+			return "", err
+		}
+		path = filepath.Join(b.Dir, name)
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path is a validated name under Dir or an explicit absolute path
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(string(data))
+	if v == "" {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+// Set writes the secret file with owner-only permissions.
+func (b *FileSecretBackend) Set(_ context.Context, name, value string) error {
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(b.Dir, 0700); err != nil {
+		return fmt.Errorf("creating secrets directory: %w", err)
+	}
+	path := filepath.Join(b.Dir, name)
+	tmp, err := os.CreateTemp(b.Dir, "."+name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.WriteString(value + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// commandRunner runs an external command with optional stdin and returns its
+// stdout.
+type commandRunner func(ctx context.Context, stdin string, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, stdin string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204 -- fixed keychain tool names
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	return cmd.Output()
+}
+
+// KeychainSecretBackend stores secrets in the OS keychain under the
+// "contextd" service: the login Keychain via security(1) on macOS and the
+// Secret Service (GNOME Keyring, KWallet) via secret-tool(1) on Linux.
+type KeychainSecretBackend struct {
+	// GOOS overrides runtime.GOOS; for tests.
+	GOOS string
+
+	run commandRunner
+}
+
+func (b *KeychainSecretBackend) goos() string {
+	if b.GOOS != "" {
+		return b.GOOS
+	}
+	return runtime.GOOS
+}
+
+func (b *KeychainSecretBackend) runner() commandRunner {
+	if b.run != nil {
+		return b.run
+	}
+	return runCommand
+}
+
+// Get looks up the secret in the keychain.
+func (b *KeychainSecretBackend) Get(ctx context.Context, name string) (string, error) {
+	if err := validateSecretName(name); err != nil {
+		return "", err
+	}
+	var out []byte
+	var err error
+	switch b.goos() {
+	case "darwin":
+		out, err = b.runner()(ctx, "", "security", "find-generic-password", "-s", keychainService, "-a", name, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		out, err = b.runner()(ctx, "", "secret-tool", "lookup", "service", keychainService, "account", name)
+	default:
+		return "", fmt.Errorf("%w: no keychain support on %s", ErrSecretBackendUnsupported, b.goos())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// Both tools exit non-zero when the item does not exist.
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("keychain lookup: %w", err)
+	}
+	v := strings.TrimRight(string(out), "\r\n")
+	if v == "" {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+// Set stores the secret in the keychain, replacing any existing item.
+func (b *KeychainSecretBackend) Set(ctx context.Context, name, value string) error {
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+	var err error
+	switch b.goos() {
+	case "darwin":
+		// security(1) only takes the password as an argument, so it is
+		// briefly visible to other processes of the same user.
+		_, err = b.runner()(ctx, "", "security", "add-generic-password", "-U", "-s", keychainService, "-a", name, "-w", value)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		_, err = b.runner()(ctx, value, "secret-tool", "store", "--label=contextd "+name, "service", keychainService, "account", name)
+	default:
+		return fmt.Errorf("%w: no keychain support on %s", ErrSecretBackendUnsupported, b.goos())
+	}
+	if err != nil {
+		return fmt.Errorf("keychain store: %w", err)
+	}
+	return nil
+}
+
+// VaultSecretBackend reads and writes secrets in a HashiCorp Vault KV v2
+// engine. Names are "path#field"; the field defaults to "value".
+type VaultSecretBackend struct {
+	// Addr is the Vault address, e.g. https://vault.example.com:8200.
+	Addr string
+
+	// Token authenticates requests.
+	Token Secret
+
+	// Mount is the KV v2 mount path (default "secret").
+	Mount string
+
+	// Client is the HTTP client (default: 10s timeout).
+	Client *http.Client
+}
+
+// NewVaultSecretBackendFromEnv configures a Vault backend from VAULT_ADDR,
+// VAULT_TOKEN and VAULT_MOUNT, the variables the vault CLI uses.
+func NewVaultSecretBackendFromEnv() *VaultSecretBackend {
+	return &VaultSecretBackend{
+		Addr:  os.Getenv("VAULT_ADDR"),
+		Token: Secret(os.Getenv("VAULT_TOKEN")),
+		Mount: os.Getenv("VAULT_MOUNT"),
+	}
+}
+
+// vaultField is the KV field used when a name has no "#field" suffix.
+const vaultField = "value"
+
+func (b *VaultSecretBackend) endpoint(name string) (string, string, error) {
+	if b.Addr == "" || !b.Token.IsSet() {
+		return "", "", fmt.Errorf("%w: vault needs VAULT_ADDR and VAULT_TOKEN", ErrSecretBackendUnsupported)
+	}
+	path, field, _ := strings.Cut(name, "#")
+	if field == "" {
+		field = vaultField
+	}
+	path = strings.Trim(path, "/")
+	if path == "" || strings.Contains(path, "..") {
+		return "", "", fmt.Errorf("invalid vault secret path %q", name)
+	}
+	mount := strings.Trim(b.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	u, err := url.JoinPath(b.Addr, "v1", mount, "data", path)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid vault address: %w", err)
+	}
+	return u, field, nil
+}
+
+func (b *VaultSecretBackend) do(ctx context.Context, method, u, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", b.Token.Value())
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	client := b.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return client.Do(req)
+}
+
+// Get reads a field of the latest version of a KV v2 secret.
+func (b *VaultSecretBackend) Get(ctx context.Context, name string) (string, error) {
+	u, field, err := b.endpoint(name)
+	if err != nil {
+		return "", err
+	}
+	resp, err := b.do(ctx, http.MethodGet, u, "", nil)
+	if err != nil {
+		return "", fmt.Errorf("vault request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrSecretNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+	var payload struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decoding vault response: %w", err)
+	}
+	v, ok := payload.Data.Data[field].(string)
+	if !ok || v == "" {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+// Set writes a new version of the secret with the field set, keeping the
+// other fields at the same path. An existing secret is patched; a missing
+// one is created with check-and-set so a concurrent writer is not
+// overwritten.
+func (b *VaultSecretBackend) Set(ctx context.Context, name, value string) error {
+	u, field, err := b.endpoint(name)
+	if err != nil {
+		return err
+	}
+	data := map[string]string{field: value}
+
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+	resp, err := b.do(ctx, http.MethodPatch, u, "application/merge-patch+json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("vault request: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		return vaultWriteStatus(resp)
+	}
+
+	// cas 0 only writes if the secret still does not exist
+	body, err = json.Marshal(map[string]interface{}{"options": map[string]int{"cas": 0}, "data": data})
+	if err != nil {
+		return err
+	}
+	resp, err = b.do(ctx, http.MethodPost, u, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("vault request: %w", err)
+	}
+	resp.Body.Close()
+	return vaultWriteStatus(resp)
+}
+
+// vaultWriteStatus returns an error unless resp reports a successful write.
+func vaultWriteStatus(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("vault returned %s", resp.Status)
+	}
+	return nil
+}
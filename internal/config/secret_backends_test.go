@@ -0,0 +1,254 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		in      string
+		backend string
+		name    string
+		ok      bool
+	}{
+		{"env:ANTHROPIC_API_KEY", SecretBackendEnv, "ANTHROPIC_API_KEY", true},
+		{"keychain:anthropic_api_key", SecretBackendKeychain, "anthropic_api_key", true},
+		{"vault:contextd/llm#anthropic", SecretBackendVault, "contextd/llm#anthropic", true},
+		{"file:/run/secrets/key", SecretBackendFile, "/run/secrets/key", true},
+		{"https://hooks.slack.com/services/T0/B0/x", "", "", false},
+		{"sk-ant-plaintext", "", "", false},
+		{"env:", "", "", false},
+	}
+	for _, tt := range tests {
+		backend, name, ok := ParseSecretRef(tt.in)
+		if backend != tt.backend || name != tt.name || ok != tt.ok {
+			t.Errorf("ParseSecretRef(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.in, backend, name, ok, tt.backend, tt.name, tt.ok)
+		}
+	}
+}
+
+func TestFileSecretBackend(t *testing.T) {
+	ctx := context.Background()
+	b := &FileSecretBackend{Dir: filepath.Join(t.TempDir(), "secrets")}
+
+	if _, err := b.Get(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Get(missing) error = %v, want ErrSecretNotFound", err)
+	}
+	if err := b.Set(ctx, "anthropic_api_key", "sk-ant-123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := b.Get(ctx, "anthropic_api_key")
+	if err != nil || got != "sk-ant-123" {
+		t.Fatalf("Get() = %q, %v; want sk-ant-123", got, err)
+	}
+	info, err := os.Stat(filepath.Join(b.Dir, "anthropic_api_key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("secret file mode = %o, want 0600", perm)
+	}
+
+	for _, name := range []string{"../escape", "a/b", ".."} {
+		if err := b.Set(ctx, name, "x"); err == nil {
+			t.Errorf("Set(%q) succeeded, want invalid name error", name)
+		}
+	}
+}
+
+func TestKeychainSecretBackend(t *testing.T) {
+	ctx := context.Background()
+	type call struct {
+		stdin string
+		args  []string
+	}
+	var calls []call
+	fake := func(out string, err error) commandRunner {
+		return func(_ context.Context, stdin string, name string, args ...string) ([]byte, error) {
+			calls = append(calls, call{stdin: stdin, args: append([]string{name}, args...)})
+			return []byte(out), err
+		}
+	}
+
+	t.Run("linux uses secret-tool with value on stdin", func(t *testing.T) {
+		calls = nil
+		b := &KeychainSecretBackend{GOOS: "linux", run: fake("sk-ant-123\n", nil)}
+		if err := b.Set(ctx, "anthropic_api_key", "sk-ant-123"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := b.Get(ctx, "anthropic_api_key")
+		if err != nil || got != "sk-ant-123" {
+			t.Fatalf("Get() = %q, %v", got, err)
+		}
+		if calls[0].stdin != "sk-ant-123" || strings.Contains(strings.Join(calls[0].args, " "), "sk-ant-123") {
+			t.Errorf("store call = %+v, want value on stdin only", calls[0])
+		}
+		if want := "secret-tool lookup service contextd account anthropic_api_key"; strings.Join(calls[1].args, " ") != want {
+			t.Errorf("lookup call = %q, want %q", strings.Join(calls[1].args, " "), want)
+		}
+	})
+
+	t.Run("darwin uses security", func(t *testing.T) {
+		calls = nil
+		b := &KeychainSecretBackend{GOOS: "darwin", run: fake("v\n", nil)}
+		if _, err := b.Get(ctx, "k"); err != nil {
+			t.Fatal(err)
+		}
+		if want := "security find-generic-password -s contextd -a k -w"; strings.Join(calls[0].args, " ") != want {
+			t.Errorf("call = %q, want %q", strings.Join(calls[0].args, " "), want)
+		}
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		b := &KeychainSecretBackend{GOOS: "linux", run: fake("", &exec.ExitError{})}
+		if _, err := b.Get(ctx, "k"); !errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("Get() error = %v, want ErrSecretNotFound", err)
+		}
+	})
+
+	t.Run("unsupported platform", func(t *testing.T) {
+		b := &KeychainSecretBackend{GOOS: "plan9"}
+		if _, err := b.Get(ctx, "k"); !errors.Is(err, ErrSecretBackendUnsupported) {
+			t.Errorf("Get() error = %v, want ErrSecretBackendUnsupported", err)
+		}
+	})
+}
+
+func TestVaultSecretBackend(t *testing.T) {
+	ctx := context.Background()
+	stored := map[string]map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/v1/kv/data/")
+		var body struct {
+			Options map[string]int    `json:"options"`
+			Data    map[string]string `json:"data"`
+		}
+		switch r.Method {
+		case http.MethodPatch:
+			data, ok := stored[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Header.Get("Content-Type") != "application/merge-patch+json" {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			for k, v := range body.Data {
+				data[k] = v
+			}
+			w.WriteHeader(http.StatusOK)
+		case http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if cas, ok := body.Options["cas"]; ok && cas == 0 && stored[path] != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			stored[path] = body.Data
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			data, ok := stored[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+		}
+	}))
+	defer server.Close()
+
+	b := &VaultSecretBackend{Addr: server.URL, Token: "root", Mount: "kv"}
+	if err := b.Set(ctx, "contextd/llm#anthropic", "sk-ant-123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := b.Get(ctx, "contextd/llm#anthropic")
+	if err != nil || got != "sk-ant-123" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if err := b.Set(ctx, "contextd/llm#openai", "sk-openai"); err != nil {
+		t.Fatalf("Set(second field) error = %v", err)
+	}
+	if got, err := b.Get(ctx, "contextd/llm#anthropic"); err != nil || got != "sk-ant-123" {
+		t.Errorf("Get() after setting another field = %q, %v; want the field kept", got, err)
+	}
+	if _, err := b.Get(ctx, "contextd/llm"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get(default field) error = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := b.Get(ctx, "contextd/other"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get(missing path) error = %v, want ErrSecretNotFound", err)
+	}
+
+	bad := &VaultSecretBackend{Addr: server.URL, Token: "wrong", Mount: "kv"}
+	if _, err := bad.Get(ctx, "contextd/llm#anthropic"); err == nil || strings.Contains(err.Error(), "sk-ant") {
+		t.Errorf("Get() with bad token error = %v", err)
+	}
+
+	unconfigured := &VaultSecretBackend{}
+	if _, err := unconfigured.Get(ctx, "x"); !errors.Is(err, ErrSecretBackendUnsupported) {
+		t.Errorf("Get() without VAULT_ADDR error = %v, want ErrSecretBackendUnsupported", err)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("CONTEXTD_TEST_ANTHROPIC_KEY", "sk-ant-env")
+	files := &FileSecretBackend{Dir: t.TempDir()}
+	if err := files.Set(ctx, "matrix_token", "syt_matrix"); err != nil {
+		t.Fatal(err)
+	}
+	r := (&SecretResolver{}).
+		WithBackend(SecretBackendEnv, EnvSecretBackend{}).
+		WithBackend(SecretBackendFile, files)
+
+	cfg := &Config{
+		LLM:        LLMConfig{AnthropicAPIKey: "env:CONTEXTD_TEST_ANTHROPIC_KEY"},
+		Embeddings: EmbeddingsConfig{APIKey: "plaintext"},
+		Notifications: NotificationsConfig{
+			SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+			Channels: map[string]NotificationChannelConfig{
+				"ops": {Type: "matrix", AccessToken: "file:matrix_token"},
+			},
+		},
+	}
+	if err := ResolveSecrets(ctx, cfg, r); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+	if got := cfg.LLM.AnthropicAPIKey.Value(); got != "sk-ant-env" {
+		t.Errorf("AnthropicAPIKey = %q, want sk-ant-env", got)
+	}
+	if got := cfg.Embeddings.APIKey.Value(); got != "plaintext" {
+		t.Errorf("plaintext secret changed to %q", got)
+	}
+	if got := cfg.Notifications.SlackWebhookURL.Value(); got != "https://hooks.slack.com/services/T0/B0/x" {
+		t.Errorf("webhook URL changed to %q", got)
+	}
+	if got := cfg.Notifications.Channels["ops"].AccessToken.Value(); got != "syt_matrix" {
+		t.Errorf("channel AccessToken = %q, want syt_matrix", got)
+	}
+
+	cfg = &Config{LLM: LLMConfig{AnthropicAPIKey: "env:CONTEXTD_TEST_UNSET_KEY"}}
+	err := ResolveSecrets(ctx, cfg, r)
+	if !errors.Is(err, ErrSecretNotFound) || !strings.Contains(err.Error(), "LLM.AnthropicAPIKey") {
+		t.Errorf("ResolveSecrets() error = %v, want not found naming the field", err)
+	}
+
+	cfg = &Config{LLM: LLMConfig{AnthropicAPIKey: "vault:x"}}
+	if err := ResolveSecrets(ctx, cfg, r); !errors.Is(err, ErrSecretBackendUnsupported) {
+		t.Errorf("ResolveSecrets() with unregistered backend error = %v", err)
+	}
+}
@@ -55,4 +55,28 @@
 // If a Scrubber is provided, all message content is scrubbed before indexing
 // and before returning search results. This prevents accidental storage or
 // exposure of secrets like API keys or tokens.
+//
+// # Redaction Profiles
+//
+// ServiceConfig.Redaction selects a RedactionProfile per tenant. The
+// standard profile only scrubs secrets. The strict profile also replaces
+// paths outside the project with [PATH] (paths inside it become relative),
+// usernames with [USER], and internal hostnames and private IPs with
+// [HOST]. Profiles apply at index time and again to search results, so
+// documents indexed under a looser profile are still redacted.
+//
+// # Domain Tagging
+//
+// With a DomainClassifier set, Index tags each message with a domain such as
+// frontend or database, which SearchOptions.Domain filters on.
+// EmbeddingClassifier picks the domain whose description is nearest to the
+// message, classifying a session's messages in one batch.
+//
+// # Artifact Links
+//
+// Index detects successful memory_record and checkpoint_save calls in a
+// transcript (see DetectArtifacts) and stores the created IDs with the
+// message that made the call, as ConversationDocument.Artifacts. With an
+// ArtifactLinker set, it also links each memory or checkpoint back to the
+// message, so a memory found later shows the conversation it came from.
 package conversation
@@ -0,0 +1,216 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// DefaultDomainThreshold is the minimum similarity between a message
+	// and a domain for the message to be tagged with it.
+	DefaultDomainThreshold = 0.5
+
+	// minClassifyLength is the shortest message, in runes, that is
+	// classified. Shorter messages ("thanks", "go ahead") have no domain.
+	minClassifyLength = 40
+
+	// maxClassifyLength caps the text embedded per message, in runes.
+	maxClassifyLength = 2000
+)
+
+// Domain is an entry of the domain vocabulary messages are tagged with.
+type Domain struct {
+	// Name is stored as the message's domain and used by the search filter.
+	Name string
+
+	// Description lists what the domain covers. It is what messages are
+	// compared to, so it should use the words conversations about the
+	// domain use.
+	Description string
+}
+
+// DefaultDomains is the vocabulary used when none is configured.
+var DefaultDomains = []Domain{
+	{Name: "frontend", Description: "frontend user interface: React, Vue, components, CSS, HTML, layout, browser rendering, accessibility, JavaScript bundling"},
+	{Name: "infra", Description: "infrastructure and deployment: Kubernetes, Docker, Terraform, Helm, CI/CD pipelines, cloud resources, networking, load balancers"},
+	{Name: "database", Description: "databases: SQL queries, schema migrations, indexes, Postgres, MySQL, transactions, query performance, ORM"},
+	{Name: "auth", Description: "authentication and authorization: login, sessions, OAuth, JWT tokens, passwords, permissions, roles, SSO"},
+}
+
+// DomainClassifier assigns domains to message content.
+type DomainClassifier interface {
+	// Classify returns the domain of each text, or "" for texts no domain
+	// fits.
+	Classify(ctx context.Context, texts []string) ([]string, error)
+}
+
+// SetDomainClassifier makes Index tag each indexed message with its domain,
+// which SearchOptions.Domain filters on. Must be called before the service
+// is used.
+func (s *Service) SetDomainClassifier(c DomainClassifier) {
+	s.domains = c
+}
+
+// EmbeddingClassifier tags a text with the domain whose description is
+// nearest to it in embedding space.
+type EmbeddingClassifier struct {
+	embedder  vectorstore.Embedder
+	domains   []Domain
+	threshold float64
+
+	mu      sync.Mutex
+	vectors [][]float32 // Domain description embeddings, computed on first use
+}
+
+// NewEmbeddingClassifier creates a classifier over domains, or
+// DefaultDomains when empty. Texts less similar than threshold to every
+// domain are left untagged; zero means DefaultDomainThreshold.
+func NewEmbeddingClassifier(embedder vectorstore.Embedder, domains []Domain, threshold float64) (*EmbeddingClassifier, error) {
+	if embedder == nil {
+		return nil, fmt.Errorf("embedder is required")
+	}
+	if len(domains) == 0 {
+		domains = DefaultDomains
+	}
+	seen := make(map[string]bool, len(domains))
+	for i, d := range domains {
+		if d.Name == "" {
+			return nil, fmt.Errorf("domain %d: name is required", i+1)
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("domain %q is defined twice", d.Name)
+		}
+		seen[d.Name] = true
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be between 0 and 1, got %v", threshold)
+	}
+	if threshold == 0 {
+		threshold = DefaultDomainThreshold
+	}
+	return &EmbeddingClassifier{
+		embedder:  embedder,
+		domains:   domains,
+		threshold: threshold,
+	}, nil
+}
+
+// Classify implements DomainClassifier.
+func (c *EmbeddingClassifier) Classify(ctx context.Context, texts []string) ([]string, error) {
+	vectors, err := c.domainVectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(texts))
+	var batch []string
+	var positions []int
+	for i, text := range texts {
+		text = strings.TrimSpace(text)
+		if utf8.RuneCountInString(text) < minClassifyLength {
+			continue
+		}
+		if utf8.RuneCountInString(text) > maxClassifyLength {
+			text = string([]rune(text)[:maxClassifyLength])
+		}
+		batch = append(batch, text)
+		positions = append(positions, i)
+	}
+	if len(batch) == 0 {
+		return result, nil
+	}
+
+	embeddings, err := c.embedder.EmbedDocuments(ctx, batch)
+	if err != nil {
+		return nil, fmt.Errorf("embedding messages: %w", err)
+	}
+	if len(embeddings) != len(batch) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d messages", len(embeddings), len(batch))
+	}
+	for i, e := range embeddings {
+		best, bestScore := -1, c.threshold
+		for j, v := range vectors {
+			if score := cosineSimilarity(e, v); score >= bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best >= 0 {
+			result[positions[i]] = c.domains[best].Name
+		}
+	}
+	return result, nil
+}
+
+// domainVectors embeds the domain descriptions once.
+func (c *EmbeddingClassifier) domainVectors(ctx context.Context) ([][]float32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.vectors != nil {
+		return c.vectors, nil
+	}
+
+	texts := make([]string, len(c.domains))
+	for i, d := range c.domains {
+		texts[i] = d.Name
+		if d.Description != "" {
+			texts[i] += ": " + d.Description
+		}
+	}
+	vectors, err := c.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding domains: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d domains", len(vectors), len(texts))
+	}
+	c.vectors = vectors
+	return vectors, nil
+}
+
+// tagDomains sets the domain of each document. A failing classifier leaves
+// the documents untagged rather than failing the index.
+func (s *Service) tagDomains(ctx context.Context, docs []*MessageDocument) {
+	if s.domains == nil || len(docs) == 0 {
+		return
+	}
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Content
+	}
+	domains, err := s.domains.Classify(ctx, texts)
+	if err != nil {
+		s.logger.Warn("domain classification failed", zap.Error(err))
+		return
+	}
+	for i, domain := range domains {
+		if i < len(docs) && domain != "" {
+			docs[i].Domain = domain
+		}
+	}
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 when
+// they differ in length or either is zero.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
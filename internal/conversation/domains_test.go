@@ -0,0 +1,156 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// keywordEmbedder embeds text as counts of a fixed set of words, so texts
+// sharing words are similar.
+type keywordEmbedder struct {
+	words []string
+	calls int
+	err   error
+}
+
+func (e *keywordEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, len(e.words))
+		for j, w := range e.words {
+			v[j] = float32(strings.Count(strings.ToLower(text), w))
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+func (e *keywordEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func TestEmbeddingClassifier_Classify(t *testing.T) {
+	embedder := &keywordEmbedder{words: []string{"css", "kubernetes", "sql"}}
+	c, err := NewEmbeddingClassifier(embedder, []Domain{
+		{Name: "frontend", Description: "css"},
+		{Name: "infra", Description: "kubernetes"},
+		{Name: "database", Description: "sql"},
+	}, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Classify(context.Background(), []string{
+		"The kubernetes deployment keeps restarting because the probe times out",
+		"Move the button styles into the shared css module so they stop leaking",
+		"thanks!",
+		"Let me think about how to structure this change across the packages",
+		"The css grid breaks when the sql report table has more than ten columns",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last message is as close to frontend as to database, under 0.8
+	want := []string{"infra", "frontend", "", "", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Classify() = %q, want %q", got, want)
+	}
+
+	// Domain descriptions are embedded once
+	if _, err := c.Classify(context.Background(), []string{"short"}); err != nil {
+		t.Fatal(err)
+	}
+	if embedder.calls != 2 {
+		t.Errorf("embedder called %d times, want 2", embedder.calls)
+	}
+}
+
+func TestNewEmbeddingClassifier_Validation(t *testing.T) {
+	embedder := &keywordEmbedder{}
+	tests := []struct {
+		name      string
+		domains   []Domain
+		threshold float64
+	}{
+		{"unnamed domain", []Domain{{Description: "css"}}, 0},
+		{"duplicate domain", []Domain{{Name: "auth"}, {Name: "auth"}}, 0},
+		{"threshold above 1", nil, 1.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEmbeddingClassifier(embedder, tt.domains, tt.threshold); err == nil {
+				t.Error("NewEmbeddingClassifier() succeeded, want error")
+			}
+		})
+	}
+
+	c, err := NewEmbeddingClassifier(embedder, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.domains) != len(DefaultDomains) || c.threshold != DefaultDomainThreshold {
+		t.Errorf("defaults not applied: %d domains, threshold %v", len(c.domains), c.threshold)
+	}
+}
+
+func TestService_Index_TagsDomains(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `{"type":"user","message":{"content":[{"type":"text","text":"Why does the kubernetes rollout of the api hang at the readiness probe?"}],"role":"user"},"timestamp":"2025-01-01T10:00:00Z","uuid":"uuid-1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"ok"}],"role":"assistant"},"timestamp":"2025-01-01T10:00:30Z","uuid":"uuid-2"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "session-1.jsonl"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	embedder := &keywordEmbedder{words: []string{"css", "kubernetes"}}
+	classifier, err := NewEmbeddingClassifier(embedder, []Domain{
+		{Name: "frontend", Description: "css"},
+		{Name: "infra", Description: "kubernetes"},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMockStore()
+	svc := NewService(store, &mockScrubber{}, zap.NewNop(), ServiceConfig{ConversationsPath: tmpDir})
+	svc.SetDomainClassifier(classifier)
+
+	if _, err := svc.Index(context.Background(), IndexOptions{TenantID: "acme", ProjectPath: tmpDir}); err != nil {
+		t.Fatal(err)
+	}
+	domains := make(map[interface{}]interface{})
+	for _, doc := range store.documents {
+		domains[doc.Metadata["message_uuid"]] = doc.Metadata["domain"]
+	}
+	if domains["uuid-1"] != "infra" || domains["uuid-2"] != nil {
+		t.Errorf("domains = %v, want uuid-1 infra and uuid-2 untagged", domains)
+	}
+
+	// Classification failures leave messages untagged
+	embedder.err = errors.New("embedder unavailable")
+	failing, _ := NewEmbeddingClassifier(embedder, nil, 0)
+	store = newMockStore()
+	svc = NewService(store, &mockScrubber{}, zap.NewNop(), ServiceConfig{ConversationsPath: tmpDir})
+	svc.SetDomainClassifier(failing)
+	result, err := svc.Index(context.Background(), IndexOptions{TenantID: "acme", ProjectPath: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MessagesIndexed != 2 {
+		t.Errorf("MessagesIndexed = %d, want 2", result.MessagesIndexed)
+	}
+}
@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ArtifactType is the kind of contextd record a tool call created.
+type ArtifactType string
+
+const (
+	// ArtifactMemory is a memory recorded with memory_record.
+	ArtifactMemory ArtifactType = "memory"
+	// ArtifactCheckpoint is a checkpoint saved with checkpoint_save.
+	ArtifactCheckpoint ArtifactType = "checkpoint"
+)
+
+// artifactTools maps contextd tool names to the artifacts they create.
+var artifactTools = map[string]ArtifactType{
+	"memory_record":   ArtifactMemory,
+	"checkpoint_save": ArtifactCheckpoint,
+}
+
+// maxExcerptLength caps Origin.Excerpt, in runes.
+const maxExcerptLength = 200
+
+// Artifact is a contextd record created by a tool call in a conversation.
+type Artifact struct {
+	Type ArtifactType `json:"type"`
+	ID   string       `json:"id"`
+
+	// Params are the parameters of the tool call that created the
+	// artifact, such as project_id, which locate it for linking. They are
+	// not stored with the conversation.
+	Params map[string]string `json:"-"`
+}
+
+// Origin is the conversation message an artifact was created in.
+type Origin struct {
+	TenantID    string
+	ProjectPath string
+	SessionID   string
+	MessageUUID string
+	Timestamp   time.Time
+
+	// Excerpt is the start of the message, redacted like the indexed
+	// message.
+	Excerpt string
+}
+
+// ArtifactLinker stores the reverse link from an artifact to the
+// conversation message that created it.
+type ArtifactLinker interface {
+	LinkArtifact(ctx context.Context, artifact Artifact, origin Origin) error
+}
+
+// SetArtifactLinker makes Index link the memories and checkpoints created
+// by contextd tool calls back to the messages that made the calls. Must be
+// called before the service is used.
+func (s *Service) SetArtifactLinker(l ArtifactLinker) {
+	s.linker = l
+}
+
+// artifactIDPatterns find the created ID in the text result of a tool call.
+var artifactIDPatterns = []*regexp.Regexp{
+	// checkpoint_save: "Checkpoint saved: <id>"
+	regexp.MustCompile(`Checkpoint saved: ([\w-]+)`),
+	// memory_record: "Memory recorded: <title> (id: <id>, ...)"
+	regexp.MustCompile(`\bid: ([\w-]+)`),
+}
+
+// DetectArtifacts returns the artifacts created by a message's contextd tool
+// calls. Calls that failed, or whose result does not name the created ID,
+// are skipped.
+func DetectArtifacts(msg RawMessage) []Artifact {
+	var artifacts []Artifact
+	for _, tc := range msg.ToolCalls {
+		typ, ok := artifactTools[toolName(tc.Name)]
+		if !ok {
+			continue
+		}
+		if id := artifactID(tc.Result); id != "" {
+			artifacts = append(artifacts, Artifact{Type: typ, ID: id, Params: tc.Params})
+		}
+	}
+	return artifacts
+}
+
+// toolName strips the mcp__<server>__ prefix Claude Code adds to MCP tools.
+func toolName(name string) string {
+	if strings.HasPrefix(name, "mcp__") {
+		if i := strings.LastIndex(name, "__"); i > len("mcp_") {
+			return name[i+2:]
+		}
+	}
+	return name
+}
+
+// artifactID extracts the created ID from a tool result, either structured
+// output or the tool's text.
+func artifactID(result string) string {
+	var structured struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(result), &structured); err == nil && structured.ID != "" {
+		return structured.ID
+	}
+	for _, re := range artifactIDPatterns {
+		if m := re.FindStringSubmatch(result); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// excerpt shortens content for Origin.Excerpt.
+func excerpt(content string) string {
+	content = strings.TrimSpace(content)
+	if utf8.RuneCountInString(content) <= maxExcerptLength {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:maxExcerptLength]) + "..."
+}
+
+// metadataStrings reads a list stored in document metadata. Stores with
+// string-only metadata, like chromem, keep it formatted as "[a b]".
+func metadataStrings(v interface{}) []string {
+	var out []string
+	switch list := v.(type) {
+	case []interface{}:
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+	case []string:
+		out = append(out, list...)
+	case string:
+		out = strings.Fields(strings.TrimSuffix(strings.TrimPrefix(list, "["), "]"))
+	}
+	return out
+}
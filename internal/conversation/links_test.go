@@ -0,0 +1,175 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// linkedSession records a memory and saves a checkpoint through contextd,
+// with the tool results in the following user messages as Claude Code
+// writes them.
+const linkedSession = `{"type":"user","message":{"content":[{"type":"text","text":"The upload retries finally work"}],"role":"user"},"timestamp":"2025-01-01T10:00:00Z","uuid":"uuid-1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Recording what fixed the retries."},{"type":"tool_use","id":"toolu_1","name":"mcp__contextd__memory_record","input":{"project_id":"api","title":"Retry uploads"}}],"role":"assistant"},"timestamp":"2025-01-01T10:00:10Z","uuid":"uuid-2"}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_1","content":[{"type":"text","text":"Memory recorded: Retry uploads (id: 3f2b9c1e-0d4a-4c55-9a61-6f7e2d8b1a90, confidence: 0.80)"}]}],"role":"user"},"timestamp":"2025-01-01T10:00:11Z","uuid":"uuid-3"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Saving a checkpoint."},{"type":"tool_use","id":"toolu_2","name":"checkpoint_save","input":{"project_path":"/home/dev/api"}},{"type":"tool_use","id":"toolu_3","name":"mcp__contextd__memory_record","input":{"project_id":"api"}}],"role":"assistant"},"timestamp":"2025-01-01T10:01:00Z","uuid":"uuid-4"}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_2","content":"Checkpoint saved: 7c0e4f7a-2b1d-4e8f-b3a5-1d9c6e2f4a10"},{"type":"tool_result","tool_use_id":"toolu_3","content":"memory record failed: quality check"}],"role":"user"},"timestamp":"2025-01-01T10:01:01Z","uuid":"uuid-5"}`
+
+func TestParser_Parse_ToolResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session-1.jsonl")
+	if err := os.WriteFile(path, []byte(linkedSession), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := NewParser().Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Messages with nothing but tool results are folded into the calls
+	if len(messages) != 3 {
+		t.Fatalf("Parse() got %d messages, want 3", len(messages))
+	}
+	calls := messages[1].ToolCalls
+	if len(calls) != 1 || calls[0].ID != "toolu_1" {
+		t.Fatalf("messages[1].ToolCalls = %+v", calls)
+	}
+	if want := "Memory recorded: Retry uploads (id: 3f2b9c1e-0d4a-4c55-9a61-6f7e2d8b1a90, confidence: 0.80)"; calls[0].Result != want {
+		t.Errorf("Result = %q, want %q", calls[0].Result, want)
+	}
+	if got := messages[2].ToolCalls[0].Result; got != "Checkpoint saved: 7c0e4f7a-2b1d-4e8f-b3a5-1d9c6e2f4a10" {
+		t.Errorf("Result = %q", got)
+	}
+}
+
+func TestDetectArtifacts(t *testing.T) {
+	tests := []struct {
+		name  string
+		calls []ToolCall
+		want  []Artifact
+	}{
+		{
+			name: "memory text result",
+			calls: []ToolCall{{Name: "mcp__contextd__memory_record", Params: map[string]string{"project_id": "api"},
+				Result: "Memory recorded: Retry uploads (id: 3f2b9c1e-0d4a-4c55-9a61-6f7e2d8b1a90, confidence: 0.80)"}},
+			want: []Artifact{{Type: ArtifactMemory, ID: "3f2b9c1e-0d4a-4c55-9a61-6f7e2d8b1a90", Params: map[string]string{"project_id": "api"}}},
+		},
+		{
+			name:  "structured result",
+			calls: []ToolCall{{Name: "checkpoint_save", Result: `{"id":"cp-1","session_id":"s"}`}},
+			want:  []Artifact{{Type: ArtifactCheckpoint, ID: "cp-1"}},
+		},
+		{
+			name: "failed call and other tools",
+			calls: []ToolCall{
+				{Name: "mcp__contextd__memory_record", Result: "memory record failed: quality check"},
+				{Name: "Read", Result: "id: 42"},
+				{Name: "mcp__contextd__memory_search", Result: "id: 42"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectArtifacts(RawMessage{ToolCalls: tt.calls})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectArtifacts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// mockLinker records links and fails for IDs in fail.
+type mockLinker struct {
+	links     map[string]Origin
+	fail      map[string]bool
+	artifacts []Artifact
+}
+
+func (m *mockLinker) LinkArtifact(ctx context.Context, a Artifact, origin Origin) error {
+	if m.fail[a.ID] {
+		return errors.New("memory not found")
+	}
+	m.links[a.ID] = origin
+	m.artifacts = append(m.artifacts, a)
+	return nil
+}
+
+func TestService_Index_LinksArtifacts(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "session-1.jsonl"), []byte(linkedSession), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMockStore()
+	linker := &mockLinker{links: make(map[string]Origin)}
+	svc := NewService(store, &mockScrubber{}, zap.NewNop(), ServiceConfig{ConversationsPath: tmpDir})
+	svc.SetArtifactLinker(linker)
+
+	result, err := svc.Index(context.Background(), IndexOptions{TenantID: "acme", ProjectPath: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ArtifactsLinked != 2 {
+		t.Errorf("ArtifactsLinked = %d, want 2", result.ArtifactsLinked)
+	}
+
+	origin, ok := linker.links["3f2b9c1e-0d4a-4c55-9a61-6f7e2d8b1a90"]
+	if !ok {
+		t.Fatalf("memory not linked: %+v", linker.links)
+	}
+	if origin.MessageUUID != "uuid-2" || origin.SessionID != "session-1" || origin.TenantID != "acme" ||
+		origin.Excerpt != "Recording what fixed the retries." {
+		t.Errorf("origin = %+v", origin)
+	}
+	if linker.artifacts[0].Params["project_id"] != "api" {
+		t.Errorf("params = %+v", linker.artifacts[0].Params)
+	}
+	if linker.links["7c0e4f7a-2b1d-4e8f-b3a5-1d9c6e2f4a10"].MessageUUID != "uuid-4" {
+		t.Errorf("checkpoint not linked to uuid-4: %+v", linker.links)
+	}
+
+	// The message documents carry the forward links
+	var stored *vectorstore.Document
+	for i, doc := range store.documents {
+		if doc.Metadata["message_uuid"] == "uuid-4" {
+			stored = &store.documents[i]
+		}
+	}
+	if stored == nil {
+		t.Fatal("message uuid-4 not indexed")
+	}
+	if ids, _ := stored.Metadata["checkpoint_ids"].([]string); !reflect.DeepEqual(ids, []string{"7c0e4f7a-2b1d-4e8f-b3a5-1d9c6e2f4a10"}) {
+		t.Errorf("checkpoint_ids = %v", stored.Metadata["checkpoint_ids"])
+	}
+
+	// Stores with string-only metadata return lists formatted as strings
+	doc := svc.resultToDocument(vectorstore.SearchResult{ID: stored.ID, Metadata: map[string]interface{}{
+		"checkpoint_ids": "[7c0e4f7a-2b1d-4e8f-b3a5-1d9c6e2f4a10]",
+		"memory_ids":     []interface{}{"m-1", "m-2"},
+	}})
+	want := []Artifact{
+		{Type: ArtifactMemory, ID: "m-1"},
+		{Type: ArtifactMemory, ID: "m-2"},
+		{Type: ArtifactCheckpoint, ID: "7c0e4f7a-2b1d-4e8f-b3a5-1d9c6e2f4a10"},
+	}
+	if !reflect.DeepEqual(doc.Artifacts, want) {
+		t.Errorf("Artifacts = %+v, want %+v", doc.Artifacts, want)
+	}
+
+	// Failed links are not counted and do not fail indexing
+	linker.fail = map[string]bool{"3f2b9c1e-0d4a-4c55-9a61-6f7e2d8b1a90": true}
+	result, err = svc.Index(context.Background(), IndexOptions{TenantID: "acme", ProjectPath: tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ArtifactsLinked != 1 || len(result.Errors) != 0 {
+		t.Errorf("ArtifactsLinked = %d, errors = %v", result.ArtifactsLinked, result.Errors)
+	}
+}
@@ -40,10 +40,11 @@ type contentBlock struct {
 	Type      string          `json:"type"`
 	Text      string          `json:"text,omitempty"`
 	ToolUse   *toolUseBlock   `json:"tool_use,omitempty"`
+	ID        string          `json:"id,omitempty"`
 	Name      string          `json:"name,omitempty"`
 	Input     json.RawMessage `json:"input,omitempty"`
 	ToolUseID string          `json:"tool_use_id,omitempty"`
-	Content   string          `json:"content,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"` // A string, or text blocks for tool results
 }
 
 // toolUseBlock represents a tool use within content.
@@ -95,6 +96,11 @@ func (p *Parser) ParseWithErrors(path string) (*ParseResult, error) {
 	buf := make([]byte, maxScanTokenSize)
 	scanner.Buffer(buf, maxScanTokenSize)
 
+	// Tool results arrive in a later message than their tool call; index
+	// calls by tool use ID to attach them
+	type callRef struct{ msg, call int }
+	calls := make(map[string]callRef)
+
 	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
@@ -121,7 +127,7 @@ func (p *Parser) ParseWithErrors(path string) (*ParseResult, error) {
 			continue
 		}
 
-		msg, err := p.parseMessage(jm, path)
+		msg, results, err := p.parseMessage(jm, path)
 		if err != nil {
 			// Track error but continue parsing
 			result.ErrorCount++
@@ -134,7 +140,17 @@ func (p *Parser) ParseWithErrors(path string) (*ParseResult, error) {
 			continue
 		}
 
+		for id, content := range results {
+			if ref, ok := calls[id]; ok {
+				result.Messages[ref.msg].ToolCalls[ref.call].Result = content
+			}
+		}
 		if msg != nil {
+			for i, tc := range msg.ToolCalls {
+				if tc.ID != "" {
+					calls[tc.ID] = callRef{msg: len(result.Messages), call: i}
+				}
+			}
 			result.Messages = append(result.Messages, *msg)
 		}
 	}
@@ -146,8 +162,10 @@ func (p *Parser) ParseWithErrors(path string) (*ParseResult, error) {
 	return result, nil
 }
 
-// parseMessage converts a jsonlMessage to a RawMessage.
-func (p *Parser) parseMessage(jm jsonlMessage, path string) (*RawMessage, error) {
+// parseMessage converts a jsonlMessage to a RawMessage. It also returns the
+// message's tool results by tool use ID; a message with nothing but tool
+// results is nil.
+func (p *Parser) parseMessage(jm jsonlMessage, path string) (*RawMessage, map[string]string, error) {
 	// Extract session ID from file path if not in message
 	sessionID := jm.SessionID
 	if sessionID == "" {
@@ -172,6 +190,7 @@ func (p *Parser) parseMessage(jm jsonlMessage, path string) (*RawMessage, error)
 	// Parse the nested message content
 	var content string
 	var toolCalls []ToolCall
+	var results map[string]string
 	var role Role
 
 	if jm.Type == "user" {
@@ -184,20 +203,20 @@ func (p *Parser) parseMessage(jm jsonlMessage, path string) (*RawMessage, error)
 			// Try parsing as content blocks
 			var cm claudeMessage
 			if err := json.Unmarshal(jm.Message, &cm); err == nil {
-				content, toolCalls = p.extractContent(cm.Content)
+				content, toolCalls, results = p.extractContent(cm.Content)
 			}
 		}
 	} else if jm.Type == "assistant" {
 		role = RoleAssistant
 		var cm claudeMessage
 		if err := json.Unmarshal(jm.Message, &cm); err == nil {
-			content, toolCalls = p.extractContent(cm.Content)
+			content, toolCalls, results = p.extractContent(cm.Content)
 		}
 	}
 
 	// Skip empty messages
 	if content == "" && len(toolCalls) == 0 {
-		return nil, nil
+		return nil, results, nil
 	}
 
 	return &RawMessage{
@@ -209,13 +228,15 @@ func (p *Parser) parseMessage(jm jsonlMessage, path string) (*RawMessage, error)
 		ToolCalls:  toolCalls,
 		GitBranch:  jm.GitBranch,
 		ParentUUID: jm.ParentUUID,
-	}, nil
+	}, results, nil
 }
 
-// extractContent extracts text content and tool calls from content blocks.
-func (p *Parser) extractContent(blocks []contentBlock) (string, []ToolCall) {
+// extractContent extracts text content, tool calls and tool results by tool
+// use ID from content blocks.
+func (p *Parser) extractContent(blocks []contentBlock) (string, []ToolCall, map[string]string) {
 	var textParts []string
 	var toolCalls []ToolCall
+	var results map[string]string
 
 	for _, block := range blocks {
 		switch block.Type {
@@ -226,6 +247,7 @@ func (p *Parser) extractContent(blocks []contentBlock) (string, []ToolCall) {
 		case "tool_use":
 			if block.ToolUse != nil {
 				tc := ToolCall{
+					ID:     block.ToolUse.ID,
 					Name:   block.ToolUse.Name,
 					Params: make(map[string]string),
 				}
@@ -240,6 +262,7 @@ func (p *Parser) extractContent(blocks []contentBlock) (string, []ToolCall) {
 			} else if block.Name != "" {
 				// Alternative format
 				tc := ToolCall{
+					ID:     block.ID,
 					Name:   block.Name,
 					Params: make(map[string]string),
 				}
@@ -253,14 +276,45 @@ func (p *Parser) extractContent(blocks []contentBlock) (string, []ToolCall) {
 			}
 		case "tool_result":
 			// Store tool results for cross-referencing
-			if block.Content != "" && len(toolCalls) > 0 {
+			content := blockText(block.Content)
+			switch {
+			case content == "":
+			case block.ToolUseID != "":
+				if results == nil {
+					results = make(map[string]string)
+				}
+				results[block.ToolUseID] = content
+			case len(toolCalls) > 0:
 				// Associate result with previous tool call
-				toolCalls[len(toolCalls)-1].Result = block.Content
+				toolCalls[len(toolCalls)-1].Result = content
 			}
 		}
 	}
 
-	return strings.Join(textParts, "\n"), toolCalls
+	return strings.Join(textParts, "\n"), toolCalls, results
+}
+
+// blockText returns the text of a content field, which is either a string
+// or a list of text blocks.
+func blockText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var blocks []contentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" && b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
 }
 
 // ParseAll reads all JSONL files in a directory and returns messages grouped by session.
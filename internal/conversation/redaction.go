@@ -0,0 +1,240 @@
+package conversation
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RedactionProfile selects what is removed from conversation content before
+// it is indexed and again before search results are returned.
+type RedactionProfile string
+
+const (
+	// ProfileStandard scrubs secrets only.
+	ProfileStandard RedactionProfile = "standard"
+
+	// ProfileStrict also replaces file paths outside the project, usernames
+	// and internal hostnames, so indexed transcripts do not describe
+	// internal infrastructure. Paths inside the project are kept relative
+	// to the project root.
+	ProfileStrict RedactionProfile = "strict"
+)
+
+// Placeholders substituted by the strict profile.
+const (
+	redactedPath = "[PATH]"
+	redactedUser = "[USER]"
+	redactedHost = "[HOST]"
+)
+
+// internalTLDs are suffixes that only resolve inside private networks.
+var internalTLDs = []string{"internal", "local", "localdomain", "corp", "lan", "intranet", "private", "home.arpa"}
+
+// RedactionConfig selects the redaction profile per tenant.
+type RedactionConfig struct {
+	// Profile applies to tenants without an override (default: standard).
+	Profile RedactionProfile
+
+	// Tenants overrides Profile for individual tenant IDs.
+	Tenants map[string]RedactionProfile
+
+	// InternalDomains are extra domains whose hosts the strict profile
+	// replaces, e.g. "acme.net" for build01.acme.net.
+	InternalDomains []string
+}
+
+// ParseRedactionProfile parses a profile name. An empty name is standard.
+func ParseRedactionProfile(name string) (RedactionProfile, error) {
+	switch p := RedactionProfile(strings.ToLower(strings.TrimSpace(name))); p {
+	case "":
+		return ProfileStandard, nil
+	case ProfileStandard, ProfileStrict:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown redaction profile %q (must be 'standard' or 'strict')", name)
+	}
+}
+
+// ProfileFor returns the profile that applies to tenantID.
+func (c RedactionConfig) ProfileFor(tenantID string) RedactionProfile {
+	if p, ok := c.Tenants[tenantID]; ok && p != "" {
+		return p
+	}
+	if c.Profile != "" {
+		return c.Profile
+	}
+	return ProfileStandard
+}
+
+var (
+	// pathPattern matches Unix, home-relative and Windows absolute paths
+	// that start a token. Colons end a path so file:line stays readable.
+	pathPattern = regexp.MustCompile(`(^|[\s"'` + "`" + `(\[=,:])((?:~/|/)[^\s"'` + "`" + `()\[\],;:<>]+|[A-Za-z]:\\[^\s"'` + "`" + `()\[\],;:<>]*)`)
+
+	// userAtHostPattern matches user@host as in emails, ssh and scp
+	// targets. A user preceded by "/" is part of a module path, not a user.
+	userAtHostPattern = regexp.MustCompile(`(^|[^\w./@-])[\w.+-]+@([A-Za-z][\w-]*(?:\.[\w-]+)*)`)
+
+	// urlHostPattern matches the user info and host of a URL.
+	urlHostPattern = regexp.MustCompile(`://([^\s/@]+@)?([A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*)`)
+
+	// hostnamePattern matches dotted hostnames.
+	hostnamePattern = regexp.MustCompile(`\b(?:[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?\.)+[A-Za-z][A-Za-z0-9-]*\b`)
+
+	// ipv4Pattern matches dotted-quad addresses.
+	ipv4Pattern = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)
+)
+
+// redactor applies the redaction profile of one tenant and project.
+type redactor struct {
+	scrubber        Scrubber
+	strict          bool
+	projectPath     string
+	internalDomains []string
+	localHost       string
+	localHostWord   *regexp.Regexp
+}
+
+// redactorFor returns the redactor for a tenant's project.
+func (s *Service) redactorFor(tenantID, projectPath string) *redactor {
+	r := &redactor{
+		scrubber: s.scrubber,
+		strict:   s.redaction.ProfileFor(tenantID) == ProfileStrict,
+	}
+	if !r.strict {
+		return r
+	}
+	if projectPath != "" {
+		r.projectPath = filepath.Clean(projectPath)
+	}
+	for _, d := range s.redaction.InternalDomains {
+		if d = strings.Trim(strings.ToLower(d), ". "); d != "" {
+			r.internalDomains = append(r.internalDomains, d)
+		}
+	}
+	if host := strings.ToLower(s.localHost); host != "" && host != "localhost" {
+		r.localHost = host
+		r.localHostWord = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(host) + `\b`)
+	}
+	return r
+}
+
+// content scrubs secrets and, for the strict profile, infrastructure
+// details from text.
+func (r *redactor) content(text string) string {
+	if r.scrubber != nil {
+		text = r.scrubber.Scrub(text).GetScrubbed()
+	}
+	if !r.strict {
+		return text
+	}
+
+	text = pathPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := pathPattern.FindStringSubmatch(m)
+		prefix, path := sub[1], sub[2]
+		// "//" is the rest of a URL scheme, not a path
+		if strings.HasPrefix(path, "//") {
+			return m
+		}
+		// Keep sentence punctuation outside the path
+		trimmed := strings.TrimRight(path, ".")
+		return prefix + r.path(trimmed) + path[len(trimmed):]
+	})
+	text = urlHostPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := urlHostPattern.FindStringSubmatch(m)
+		userinfo, host := sub[1], sub[2]
+		if userinfo != "" {
+			userinfo = redactedUser + "@"
+		}
+		// A single-label host in a URL only resolves on a private network
+		if !strings.Contains(host, ".") && !strings.EqualFold(host, "localhost") {
+			host = redactedHost
+		}
+		return "://" + userinfo + host
+	})
+	text = userAtHostPattern.ReplaceAllString(text, "${1}"+redactedUser+"@${2}")
+	text = hostnamePattern.ReplaceAllStringFunc(text, func(host string) string {
+		if r.internalHost(host) {
+			return redactedHost
+		}
+		return host
+	})
+	text = ipv4Pattern.ReplaceAllStringFunc(text, func(addr string) string {
+		if ip := net.ParseIP(addr); ip != nil && (ip.IsPrivate() || ip.IsLinkLocalUnicast()) {
+			return redactedHost
+		}
+		return addr
+	})
+	if r.localHostWord != nil {
+		text = r.localHostWord.ReplaceAllString(text, redactedHost)
+	}
+	return text
+}
+
+// path returns p relative to the project, or the path placeholder for
+// paths outside it.
+func (r *redactor) path(p string) string {
+	if !r.strict {
+		return p
+	}
+	if rel, ok := r.relative(p); ok {
+		return rel
+	}
+	return redactedPath
+}
+
+// relative returns p relative to the project root, with forward slashes.
+// Relative paths are already project paths.
+func (r *redactor) relative(p string) (string, bool) {
+	if !strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "~") && !strings.HasPrefix(p, `\`) && !(len(p) > 2 && p[1] == ':') {
+		return p, true
+	}
+	if r.projectPath == "" {
+		return "", false
+	}
+	unix := func(s string) string { return strings.ReplaceAll(s, `\`, "/") }
+	project, path := strings.TrimSuffix(unix(r.projectPath), "/"), unix(p)
+	if path == project {
+		return ".", true
+	}
+	if rest, ok := strings.CutPrefix(path, project+"/"); ok {
+		return rest, true
+	}
+	return "", false
+}
+
+// files redacts file references, dropping files outside the project under
+// the strict profile.
+func (r *redactor) files(refs []FileReference) []FileReference {
+	if !r.strict {
+		return refs
+	}
+	kept := make([]FileReference, 0, len(refs))
+	for _, ref := range refs {
+		if rel, ok := r.relative(ref.Path); ok {
+			ref.Path = rel
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
+
+// internalHost reports whether host is under an internal TLD or a
+// configured internal domain.
+func (r *redactor) internalHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, suffix := range internalTLDs {
+		if strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+	for _, d := range r.internalDomains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return r.localHost != "" && strings.HasPrefix(host, r.localHost+".")
+}
@@ -0,0 +1,190 @@
+package conversation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestParseRedactionProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    RedactionProfile
+		wantErr bool
+	}{
+		{"", ProfileStandard, false},
+		{"standard", ProfileStandard, false},
+		{"Strict", ProfileStrict, false},
+		{"paranoid", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRedactionProfile(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRedactionProfile(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseRedactionProfile(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRedactionConfig_ProfileFor(t *testing.T) {
+	cfg := RedactionConfig{
+		Profile: ProfileStrict,
+		Tenants: map[string]RedactionProfile{"oss": ProfileStandard},
+	}
+	if got := cfg.ProfileFor("acme"); got != ProfileStrict {
+		t.Errorf("ProfileFor(acme) = %q, want strict", got)
+	}
+	if got := cfg.ProfileFor("oss"); got != ProfileStandard {
+		t.Errorf("ProfileFor(oss) = %q, want standard", got)
+	}
+	if got := (RedactionConfig{}).ProfileFor("acme"); got != ProfileStandard {
+		t.Errorf("zero config ProfileFor(acme) = %q, want standard", got)
+	}
+}
+
+// secretScrubber replaces a fixed secret.
+type secretScrubber struct{}
+
+func (secretScrubber) Scrub(content string) ScrubResult {
+	return &mockScrubResult{scrubbed: strings.ReplaceAll(content, "hunter2", "[REDACTED]")}
+}
+
+func TestRedactor_Content(t *testing.T) {
+	svc := NewService(newMockStore(), secretScrubber{}, zap.NewNop(), ServiceConfig{
+		ConversationsPath: t.TempDir(),
+		Redaction: RedactionConfig{
+			Tenants:         map[string]RedactionProfile{"acme": ProfileStrict},
+			InternalDomains: []string{"acme.net"},
+		},
+	})
+	svc.localHost = "devbox"
+	strict := svc.redactorFor("acme", "/home/alice/src/api")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"secret", "password is hunter2", "password is [REDACTED]"},
+		{"project path", "edit /home/alice/src/api/internal/server.go:42 next", "edit internal/server.go:42 next"},
+		{"project root", "cd /home/alice/src/api.", "cd .."},
+		{"outside path", "cat /etc/kubernetes/admin.conf", "cat [PATH]"},
+		{"home path", "see ~/notes/todo.md", "see [PATH]"},
+		{"windows path", `open C:\Users\alice\deploy.ps1`, "open [PATH]"},
+		{"ssh target", "ssh alice@build01.corp", "ssh [USER]@[HOST]"},
+		{"email", "ask bob@acme.net", "ask [USER]@[HOST]"},
+		{"internal domain", "curl https://grafana.ops.acme.net/d/1", "curl https://[HOST]/d/1"},
+		{"single-label url", "open http://jenkins:8080/job", "open http://[HOST]:8080/job"},
+		{"url user", "clone https://ci@git.internal/repo", "clone https://[USER]@[HOST]/repo"},
+		{"private ip", "db at 10.1.2.3:5432", "db at [HOST]:5432"},
+		{"local hostname", "running on DEVBOX now", "running on [HOST] now"},
+		{"public host kept", "see https://github.com/acme/api", "see https://github.com/acme/api"},
+		{"module version kept", "go get github.com/acme/api@v1.2.0", "go get github.com/acme/api@v1.2.0"},
+		{"public ip kept", "resolves to 8.8.8.8", "resolves to 8.8.8.8"},
+		{"relative path kept", "edit internal/server.go", "edit internal/server.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strict.content(tt.in); got != tt.want {
+				t.Errorf("content(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("standard scrubs secrets only", func(t *testing.T) {
+		in := "ssh alice@build01.corp with hunter2 in /etc/app"
+		want := "ssh alice@build01.corp with [REDACTED] in /etc/app"
+		if got := svc.redactorFor("other", "/home/alice/src/api").content(in); got != want {
+			t.Errorf("content(%q) = %q, want %q", in, got, want)
+		}
+	})
+}
+
+func TestService_Index_StrictRedaction(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := "/home/alice/src/api"
+
+	line := `{"type":"user","message":{"id":"msg1","content":[{"type":"text","text":"Fix /home/alice/src/api/main.go and /opt/internal/app.yaml on build01.corp"}],"role":"user"},"timestamp":"2025-01-01T10:00:00Z","uuid":"uuid-1"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "session.jsonl"), []byte(line), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	store := newMockStore()
+	svc := NewService(store, &mockScrubber{}, zap.NewNop(), ServiceConfig{
+		ConversationsPath: tmpDir,
+		Redaction:         RedactionConfig{Profile: ProfileStrict},
+	})
+
+	result, err := svc.Index(context.Background(), IndexOptions{
+		TenantID:    "acme",
+		ProjectPath: projectPath,
+	})
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if len(store.documents) != 1 {
+		t.Fatalf("len(documents) = %d, want 1", len(store.documents))
+	}
+
+	want := "Fix main.go and [PATH] on [HOST]"
+	if got := store.documents[0].Content; got != want {
+		t.Errorf("indexed content = %q, want %q", got, want)
+	}
+	for _, path := range result.FilesReferenced {
+		if strings.HasPrefix(path, "/") {
+			t.Errorf("FilesReferenced contains absolute path %q", path)
+		}
+	}
+	if files, ok := store.documents[0].Metadata["files_discussed"].([]string); ok {
+		for _, f := range files {
+			if strings.HasPrefix(f, "/") {
+				t.Errorf("files_discussed contains absolute path %q", f)
+			}
+		}
+	}
+}
+
+func TestService_Search_StrictRedaction(t *testing.T) {
+	store := newMockStore()
+	store.searchResults = []vectorstore.SearchResult{{
+		ID:       "doc1",
+		Content:  "indexed before strict: /srv/secrets/app.env on 192.168.1.20",
+		Score:    0.9,
+		Metadata: map[string]interface{}{"type": "message"},
+	}}
+
+	svc := NewService(store, &mockScrubber{}, zap.NewNop(), ServiceConfig{
+		Redaction: RedactionConfig{Tenants: map[string]RedactionProfile{"acme": ProfileStrict}},
+	})
+
+	search := func(tenantID string) string {
+		t.Helper()
+		result, err := svc.Search(context.Background(), SearchOptions{
+			TenantID:    tenantID,
+			ProjectPath: "/srv/api",
+			Query:       "env file",
+		})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(result.Results) != 1 {
+			t.Fatalf("len(Results) = %d, want 1", len(result.Results))
+		}
+		return result.Results[0].Document.Content
+	}
+
+	if got, want := search("acme"), "indexed before strict: [PATH] on [HOST]"; got != want {
+		t.Errorf("strict tenant content = %q, want %q", got, want)
+	}
+	if got, want := search("other"), store.searchResults[0].Content; got != want {
+		t.Errorf("standard tenant content = %q, want %q", got, want)
+	}
+}
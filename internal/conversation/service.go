@@ -14,7 +14,9 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
 	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
@@ -34,15 +36,20 @@ type Service struct {
 	extractor *Extractor
 	store     vectorstore.Store
 	scrubber  Scrubber
+	linker    ArtifactLinker
+	domains   DomainClassifier
 	logger    *zap.Logger
 
 	// Configuration
 	conversationsPath string
+	redaction         RedactionConfig
+	localHost         string
 }
 
 // ServiceConfig holds configuration for the conversation service.
 type ServiceConfig struct {
-	ConversationsPath string // Base path for conversation files
+	ConversationsPath string          // Base path for conversation files
+	Redaction         RedactionConfig // Redaction profiles per tenant
 }
 
 // NewService creates a new conversation service.
@@ -58,6 +65,8 @@ func NewService(
 		home, _ := os.UserHomeDir()
 		conversationsPath = filepath.Join(home, ".claude", "projects")
 	}
+	// The strict profile redacts this machine's hostname
+	localHost, _ := os.Hostname()
 
 	return &Service{
 		parser:            NewParser(),
@@ -66,6 +75,8 @@ func NewService(
 		scrubber:          scrubber,
 		logger:            logger,
 		conversationsPath: conversationsPath,
+		redaction:         cfg.Redaction,
+		localHost:         localHost,
 	}
 }
 
@@ -197,6 +208,7 @@ func (s *Service) Index(ctx context.Context, opts IndexOptions) (*IndexResult, e
 	}
 
 	filesSet := make(map[string]bool)
+	redact := s.redactorFor(opts.TenantID, opts.ProjectPath)
 
 	// Process each session
 	for sessionID, messages := range sessionMessages {
@@ -211,8 +223,9 @@ func (s *Service) Index(ctx context.Context, opts IndexOptions) (*IndexResult, e
 		})
 
 		// Convert messages to documents
+		docs := make([]*MessageDocument, 0, len(messages))
 		for idx, msg := range messages {
-			doc, err := s.messageToDocument(msg, idx, sessionID)
+			doc, err := s.messageToDocument(msg, idx, sessionID, redact)
 			if err != nil {
 				indexErrors = append(indexErrors, err)
 				continue
@@ -222,7 +235,14 @@ func (s *Service) Index(ctx context.Context, opts IndexOptions) (*IndexResult, e
 			for _, ref := range doc.FilesDiscussed {
 				filesSet[ref.Path] = true
 			}
+			doc.Artifacts = DetectArtifacts(msg)
+			docs = append(docs, doc)
+		}
+
+		// Classify the session's messages in one batch
+		s.tagDomains(ctx, docs)
 
+		for _, doc := range docs {
 			// Convert to vectorstore document
 			vsDoc := s.toVectorstoreDocument(doc)
 
@@ -234,6 +254,7 @@ func (s *Service) Index(ctx context.Context, opts IndexOptions) (*IndexResult, e
 			}
 
 			result.MessagesIndexed++
+			result.ArtifactsLinked += s.linkArtifacts(ctx, doc, opts)
 		}
 
 		result.SessionsIndexed++
@@ -250,6 +271,7 @@ func (s *Service) Index(ctx context.Context, opts IndexOptions) (*IndexResult, e
 		zap.Int("messages", result.MessagesIndexed),
 		zap.Int("decisions", result.DecisionsExtracted),
 		zap.Int("files", len(result.FilesReferenced)),
+		zap.Int("artifacts_linked", result.ArtifactsLinked),
 		zap.Int("errors", len(result.Errors)),
 		zap.Duration("duration", time.Since(startTime)),
 	)
@@ -257,6 +279,37 @@ func (s *Service) Index(ctx context.Context, opts IndexOptions) (*IndexResult, e
 	return result, nil
 }
 
+// linkArtifacts links the artifacts created by an indexed message back to
+// it and returns how many were linked. Failures are logged, not returned:
+// the artifact may have been deleted since the conversation.
+func (s *Service) linkArtifacts(ctx context.Context, doc *MessageDocument, opts IndexOptions) int {
+	if s.linker == nil || len(doc.Artifacts) == 0 {
+		return 0
+	}
+	origin := Origin{
+		TenantID:    opts.TenantID,
+		ProjectPath: opts.ProjectPath,
+		SessionID:   doc.SessionID,
+		MessageUUID: doc.MessageUUID,
+		Timestamp:   doc.Timestamp,
+		Excerpt:     excerpt(doc.Content),
+	}
+	linked := 0
+	for _, a := range doc.Artifacts {
+		if err := s.linker.LinkArtifact(ctx, a, origin); err != nil {
+			s.logger.Warn("failed to link artifact to conversation",
+				zap.String("type", string(a.Type)),
+				zap.String("id", a.ID),
+				zap.String("session_id", doc.SessionID),
+				zap.Error(err),
+			)
+			continue
+		}
+		linked++
+	}
+	return linked
+}
+
 // getConversationDir determines the conversation directory for a project.
 // Returns the directory path and a boolean indicating if fallback was used.
 func (s *Service) getConversationDir(projectPath string) (string, bool) {
@@ -292,16 +345,14 @@ func hasJSONLFiles(dir string) bool {
 }
 
 // messageToDocument converts a RawMessage to a MessageDocument.
-func (s *Service) messageToDocument(msg RawMessage, index int, sessionID string) (*MessageDocument, error) {
-	// Scrub content
-	scrubbedContent := msg.Content
-	if s.scrubber != nil {
-		result := s.scrubber.Scrub(msg.Content)
-		scrubbedContent = result.GetScrubbed()
-	}
+// Content and file references are redacted with the tenant's profile.
+func (s *Service) messageToDocument(msg RawMessage, index int, sessionID string, redact *redactor) (*MessageDocument, error) {
+	// Scrub and redact content
+	scrubbedContent := redact.content(msg.Content)
 
-	// Extract metadata
+	// Extract metadata from the original message so paths resolve
 	files, commits := s.extractor.ExtractMetadata(msg)
+	files = redact.files(files)
 
 	doc := &MessageDocument{
 		ConversationDocument: ConversationDocument{
@@ -363,6 +414,19 @@ func (s *Service) toVectorstoreDocument(doc *MessageDocument) vectorstore.Docume
 		metadata["commits_made"] = commits
 	}
 
+	// Add artifact links
+	for _, typ := range []ArtifactType{ArtifactMemory, ArtifactCheckpoint} {
+		var ids []string
+		for _, a := range doc.Artifacts {
+			if a.Type == typ {
+				ids = append(ids, a.ID)
+			}
+		}
+		if len(ids) > 0 {
+			metadata[string(typ)+"_ids"] = ids
+		}
+	}
+
 	return vectorstore.Document{
 		ID:       doc.ID,
 		Content:  doc.Content,
@@ -374,12 +438,26 @@ func (s *Service) toVectorstoreDocument(doc *MessageDocument) vectorstore.Docume
 func (s *Service) Search(ctx context.Context, opts SearchOptions) (*SearchResult, error) {
 	startTime := time.Now()
 
+	if err := validation.Conversation.Query("query", opts.Query); err != nil {
+		return nil, err
+	}
+	if err := validation.Conversation.Tags(opts.Tags); err != nil {
+		return nil, err
+	}
+
 	// Set default limit
 	limit := opts.Limit
 	if limit <= 0 {
 		limit = 10
 	}
 
+	// Resolve the page offset; the cursor is bound to the query and filters.
+	hash := searchHash(opts)
+	offset, err := pagination.Decode(opts.Cursor, hash)
+	if err != nil {
+		return nil, err
+	}
+
 	collName := s.collectionName(opts.TenantID, opts.ProjectPath)
 
 	// Add tenant context
@@ -396,37 +474,73 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) (*SearchResult
 	if len(opts.Tags) > 0 {
 		filters["tags"] = opts.Tags
 	}
+	redact := s.redactorFor(opts.TenantID, opts.ProjectPath)
 	if opts.FilePath != "" {
-		filters["files_discussed"] = opts.FilePath
+		// Strict indexing stores project-relative paths
+		filePath := opts.FilePath
+		if rel, ok := redact.relative(filePath); ok && redact.strict {
+			filePath = rel
+		}
+		filters["files_discussed"] = filePath
 	}
 	if opts.Domain != "" {
 		filters["domain"] = opts.Domain
 	}
 
-	// Search vectorstore
-	results, err := s.store.SearchInCollection(ctx, collName, opts.Query, limit, filters)
+	// Search vectorstore, fetching one extra result to detect another page
+	results, err := s.store.SearchInCollection(ctx, collName, opts.Query, offset+limit+1, filters)
 	if err != nil {
 		return nil, fmt.Errorf("searching conversations: %w", err)
 	}
 
-	// Convert results
+	// Convert results, redacting again in case the documents were indexed
+	// before the tenant's profile was tightened
 	hits := make([]SearchHit, len(results))
 	for i, r := range results {
 		doc := s.resultToDocument(r)
+		doc.Content = redact.content(doc.Content)
 		hits[i] = SearchHit{
 			Document: doc,
 			Score:    float64(r.Score),
 		}
 	}
 
+	// Break score ties by ID so pages are stable across calls
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Document.ID < hits[j].Document.ID
+	})
+	hits, next := pagination.Paginate(hits, offset, limit, hash)
+
 	return &SearchResult{
-		Query:   opts.Query,
-		Results: hits,
-		Total:   len(hits),
-		Took:    time.Since(startTime),
+		Query:      opts.Query,
+		Results:    hits,
+		Total:      len(hits),
+		Took:       time.Since(startTime),
+		NextCursor: next,
 	}, nil
 }
 
+// searchHash identifies a search for cursor validation.
+func searchHash(opts SearchOptions) string {
+	types := make([]string, len(opts.Types))
+	for i, t := range opts.Types {
+		types[i] = string(t)
+	}
+	return pagination.QueryHash(
+		"conversation",
+		opts.Query,
+		opts.TenantID,
+		opts.ProjectPath,
+		strings.Join(types, ","),
+		strings.Join(opts.Tags, ","),
+		opts.FilePath,
+		opts.Domain,
+	)
+}
+
 // resultToDocument converts a vectorstore result to a ConversationDocument.
 func (s *Service) resultToDocument(r vectorstore.SearchResult) ConversationDocument {
 	doc := ConversationDocument{
@@ -454,6 +568,11 @@ func (s *Service) resultToDocument(r vectorstore.SearchResult) ConversationDocum
 	if domain, ok := r.Metadata["domain"].(string); ok {
 		doc.Domain = domain
 	}
+	for _, typ := range []ArtifactType{ArtifactMemory, ArtifactCheckpoint} {
+		for _, id := range metadataStrings(r.Metadata[string(typ)+"_ids"]) {
+			doc.Artifacts = append(doc.Artifacts, Artifact{Type: typ, ID: id})
+		}
+	}
 
 	return doc
 }
@@ -2,6 +2,8 @@ package conversation
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +11,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
@@ -315,6 +318,60 @@ func TestService_Search_DefaultLimit(t *testing.T) {
 	_ = result
 }
 
+func TestService_Search_Cursor(t *testing.T) {
+	store := newMockStore()
+	for i := 0; i < 5; i++ {
+		store.searchResults = append(store.searchResults, vectorstore.SearchResult{
+			ID:       fmt.Sprintf("doc%d", i),
+			Content:  "content",
+			Score:    float32(1.0 - float64(i)*0.1),
+			Metadata: map[string]interface{}{"type": "message"},
+		})
+	}
+	service := NewService(store, &mockScrubber{}, zap.NewNop(), ServiceConfig{})
+
+	opts := SearchOptions{
+		TenantID:    "test-tenant",
+		ProjectPath: "/test/project",
+		Query:       "test query",
+		Limit:       2,
+	}
+
+	var ids []string
+	for {
+		result, err := service.Search(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		for _, hit := range result.Results {
+			ids = append(ids, hit.Document.ID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		opts.Cursor = result.NextCursor
+	}
+
+	want := []string{"doc0", "doc1", "doc2", "doc3", "doc4"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("paged ids = %v, want %v", ids, want)
+	}
+
+	// A cursor cannot be replayed against a different query.
+	first, err := service.Search(context.Background(), SearchOptions{
+		TenantID: "test-tenant", ProjectPath: "/test/project", Query: "test query", Limit: 2,
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	_, err = service.Search(context.Background(), SearchOptions{
+		TenantID: "test-tenant", ProjectPath: "/test/project", Query: "other", Limit: 2, Cursor: first.NextCursor,
+	})
+	if !errors.Is(err, pagination.ErrCursorMismatch) {
+		t.Errorf("Search() error = %v, want ErrCursorMismatch", err)
+	}
+}
+
 func TestService_CollectionName(t *testing.T) {
 	store := newMockStore()
 	logger := zap.NewNop()
@@ -0,0 +1,138 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+var (
+	// ErrSessionNotFound is returned when a project has no conversation
+	// with the requested session ID.
+	ErrSessionNotFound = ctxerrors.NotFound("conversation session not found")
+
+	// ErrInvalidRange is returned for a message range outside the session.
+	ErrInvalidRange = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid message range")
+)
+
+// TranscriptOptions selects a range of a session's messages.
+type TranscriptOptions struct {
+	ProjectPath string
+	TenantID    string
+	SessionID   string // Session to read (default: the most recent session)
+	Start       int    // Index of the first message (default: 0)
+	End         int    // Index after the last message (default: end of session)
+}
+
+// Transcript is a range of a session's messages, scrubbed and redacted with
+// the tenant's profile.
+type Transcript struct {
+	SessionID string
+	Messages  []TranscriptMessage
+	Start     int // Index of the first message
+	End       int // Index after the last message
+	Total     int // Messages in the session
+}
+
+// TranscriptMessage is one message of a Transcript. Index is the position
+// in the session, the same message_index conversation_index stores.
+type TranscriptMessage struct {
+	Index     int
+	Role      Role
+	Timestamp time.Time
+	Content   string
+}
+
+// Text renders the transcript as one "role: content" block per message.
+// Messages without text, such as bare tool calls, are skipped.
+func (t *Transcript) Text() string {
+	var b strings.Builder
+	for _, msg := range t.Messages {
+		content := strings.TrimSpace(msg.Content)
+		if content == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%s: %s", msg.Role, content)
+	}
+	return b.String()
+}
+
+// Transcript reads a range of a session's messages from the project's
+// conversation files. Messages are ordered by timestamp, as they are when
+// indexed, so ranges line up with indexed message indexes.
+func (s *Service) Transcript(ctx context.Context, opts TranscriptOptions) (*Transcript, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	convDir, _ := s.getConversationDir(opts.ProjectPath)
+	if _, err := os.Stat(convDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: no conversations for project", ErrSessionNotFound)
+	}
+	parsed, err := s.parser.ParseAllWithErrors(convDir)
+	if err != nil {
+		return nil, fmt.Errorf("parsing conversations: %w", err)
+	}
+
+	sessionID := opts.SessionID
+	if sessionID == "" {
+		sessionID = latestSession(parsed.Messages)
+	}
+	messages, ok := parsed.Messages[sessionID]
+	if !ok || len(messages) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+
+	end := opts.End
+	if end == 0 {
+		end = len(messages)
+	}
+	if opts.Start < 0 || end > len(messages) || opts.Start >= end {
+		return nil, fmt.Errorf("%w: [%d, %d) of %d messages", ErrInvalidRange, opts.Start, opts.End, len(messages))
+	}
+
+	redact := s.redactorFor(opts.TenantID, opts.ProjectPath)
+	t := &Transcript{
+		SessionID: sessionID,
+		Messages:  make([]TranscriptMessage, 0, end-opts.Start),
+		Start:     opts.Start,
+		End:       end,
+		Total:     len(messages),
+	}
+	for i := opts.Start; i < end; i++ {
+		msg := messages[i]
+		t.Messages = append(t.Messages, TranscriptMessage{
+			Index:     i,
+			Role:      msg.Role,
+			Timestamp: msg.Timestamp,
+			Content:   redact.content(msg.Content),
+		})
+	}
+	return t, nil
+}
+
+// latestSession returns the session with the most recent message.
+func latestSession(sessions map[string][]RawMessage) string {
+	var latest string
+	var latestAt time.Time
+	for id, messages := range sessions {
+		for _, msg := range messages {
+			// Ties go to the smaller ID so the choice is deterministic
+			if msg.Timestamp.After(latestAt) || (msg.Timestamp.Equal(latestAt) && (latest == "" || id < latest)) {
+				latest, latestAt = id, msg.Timestamp
+			}
+		}
+	}
+	return latest
+}
@@ -0,0 +1,90 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// writeSession writes a conversation file with one text message per line.
+func writeSession(t *testing.T, dir, sessionID string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, sessionID+".jsonl"), []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+}
+
+func TestService_Transcript(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeSession(t, tmpDir, "old-session",
+		`{"type":"user","sessionId":"old-session","message":{"content":[{"type":"text","text":"Old question"}],"role":"user"},"timestamp":"2025-01-01T09:00:00Z","uuid":"o1"}`,
+	)
+	writeSession(t, tmpDir, "new-session",
+		`{"type":"assistant","sessionId":"new-session","message":{"content":[{"type":"text","text":"Use the retry helper in /home/alice/api/retry.go"}],"role":"assistant"},"timestamp":"2025-01-02T10:00:30Z","uuid":"n2"}`,
+		`{"type":"user","sessionId":"new-session","message":{"content":[{"type":"text","text":"How do I retry failed uploads?"}],"role":"user"},"timestamp":"2025-01-02T10:00:00Z","uuid":"n1"}`,
+		`{"type":"user","sessionId":"new-session","message":{"content":[{"type":"text","text":"Thanks"}],"role":"user"},"timestamp":"2025-01-02T10:01:00Z","uuid":"n3"}`,
+	)
+
+	svc := NewService(newMockStore(), &mockScrubber{}, zap.NewNop(), ServiceConfig{
+		ConversationsPath: tmpDir,
+		Redaction:         RedactionConfig{Tenants: map[string]RedactionProfile{"acme": ProfileStrict}},
+	})
+	ctx := context.Background()
+
+	t.Run("defaults to the most recent session", func(t *testing.T) {
+		tr, err := svc.Transcript(ctx, TranscriptOptions{ProjectPath: "/home/alice/api", TenantID: "dev"})
+		if err != nil {
+			t.Fatalf("Transcript() error = %v", err)
+		}
+		if tr.SessionID != "new-session" {
+			t.Errorf("SessionID = %q, want new-session", tr.SessionID)
+		}
+		if tr.Start != 0 || tr.End != 3 || tr.Total != 3 {
+			t.Errorf("range = [%d, %d) of %d, want [0, 3) of 3", tr.Start, tr.End, tr.Total)
+		}
+		want := "user: How do I retry failed uploads?\n\n" +
+			"assistant: Use the retry helper in /home/alice/api/retry.go\n\n" +
+			"user: Thanks"
+		if got := tr.Text(); got != want {
+			t.Errorf("Text() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("selects a message range with the tenant profile", func(t *testing.T) {
+		tr, err := svc.Transcript(ctx, TranscriptOptions{
+			ProjectPath: "/home/alice/api",
+			TenantID:    "acme",
+			SessionID:   "new-session",
+			Start:       1,
+			End:         2,
+		})
+		if err != nil {
+			t.Fatalf("Transcript() error = %v", err)
+		}
+		if len(tr.Messages) != 1 || tr.Messages[0].Index != 1 {
+			t.Fatalf("Messages = %+v, want message 1 only", tr.Messages)
+		}
+		if got, want := tr.Messages[0].Content, "Use the retry helper in retry.go"; got != want {
+			t.Errorf("Content = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := svc.Transcript(ctx, TranscriptOptions{ProjectPath: "/home/alice/api", SessionID: "missing"})
+		if !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("unknown session error = %v, want ErrSessionNotFound", err)
+		}
+		_, err = svc.Transcript(ctx, TranscriptOptions{ProjectPath: "/home/alice/api", SessionID: "new-session", Start: 2, End: 5})
+		if !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("out-of-range error = %v, want ErrInvalidRange", err)
+		}
+	})
+}
@@ -52,6 +52,7 @@ type RawMessage struct {
 
 // ToolCall represents a tool invocation within a message.
 type ToolCall struct {
+	ID     string            `json:"id,omitempty"` // Tool use ID, matching the call to its result
 	Name   string            `json:"name"`
 	Params map[string]string `json:"params,omitempty"`
 	Result string            `json:"result,omitempty"`
@@ -88,6 +89,7 @@ type ConversationDocument struct {
 	// Cross-references
 	FilesDiscussed []FileReference   `json:"files_discussed,omitempty"`
 	CommitsMade    []CommitReference `json:"commits_made,omitempty"`
+	Artifacts      []Artifact        `json:"artifacts,omitempty"` // Memories and checkpoints created here
 
 	// Metadata
 	IndexedAt        time.Time `json:"indexed_at"`
@@ -131,6 +133,7 @@ type IndexResult struct {
 	MessagesIndexed    int      `json:"messages_indexed"`
 	DecisionsExtracted int      `json:"decisions_extracted"`
 	FilesReferenced    []string `json:"files_referenced"`
+	ArtifactsLinked    int      `json:"artifacts_linked"`
 	Errors             []error  `json:"errors,omitempty"`
 }
 
@@ -144,14 +147,16 @@ type SearchOptions struct {
 	FilePath    string         `json:"file_path,omitempty"`
 	Domain      string         `json:"domain,omitempty"`
 	Limit       int            `json:"limit"`
+	Cursor      string         `json:"cursor,omitempty"` // Resume from a previous NextCursor
 }
 
 // SearchResult contains the results of a search operation.
 type SearchResult struct {
-	Query   string        `json:"query"`
-	Results []SearchHit   `json:"results"`
-	Total   int           `json:"total"`
-	Took    time.Duration `json:"took"`
+	Query      string        `json:"query"`
+	Results    []SearchHit   `json:"results"`
+	Total      int           `json:"total"`
+	Took       time.Duration `json:"took"`
+	NextCursor string        `json:"next_cursor,omitempty"` // Empty on the last page
 }
 
 // SearchHit represents a single search result.
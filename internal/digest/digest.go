@@ -0,0 +1,505 @@
+// Package digest writes a periodic knowledge newsletter for a team or an
+// organization.
+//
+// A Generator collects the memories and remediations recorded during the
+// period with high confidence, groups them by project and topic (a memory's
+// first tag, a remediation's category) and asks the LLM to write them up as
+// a markdown digest. Without an LLM, or when the LLM budget is exhausted or
+// the request fails, the digest is the grouped listing itself and says why
+// in its warnings. A Scheduler generates a digest every interval and
+// delivers it to a directory, to a listener such as a notification
+// channel, or both.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// DefaultPeriod is the period a digest covers when Options.Since is zero.
+	DefaultPeriod = 7 * 24 * time.Hour
+
+	// DefaultMinConfidence is the confidence items need to be included.
+	DefaultMinConfidence = 0.7
+
+	// DefaultMaxItems bounds the items of one section, keeping the most
+	// confident, so the prompt stays small.
+	DefaultMaxItems = 25
+
+	// summaryLen bounds the text shown for one item.
+	summaryLen = 300
+)
+
+// Item kinds.
+const (
+	KindMemory      = "memory"
+	KindRemediation = "remediation"
+)
+
+// Sections holding remediations shared beyond one project.
+const (
+	SectionTeam = "Team-wide"
+	SectionOrg  = "Organization-wide"
+)
+
+// LLMClient writes the digest from a prompt. It has the shape of
+// reasoningbank.LLMClient.
+type LLMClient interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// MemorySource streams the memories of a project. *reasoningbank.Service
+// implements it.
+type MemorySource interface {
+	StreamMemories(ctx context.Context, projectID string, batchSize int, fn func(reasoningbank.Memory) error) error
+}
+
+// RemediationSource lists remediations. remediation.Service implements it.
+type RemediationSource interface {
+	List(ctx context.Context, req *remediation.ListRequest) ([]*remediation.Remediation, error)
+}
+
+// Options selects what a digest covers.
+type Options struct {
+	TenantID   string   // Organization (required)
+	TeamID     string   // Team; empty for the whole organization
+	ProjectIDs []string // Projects whose memories are included
+
+	// Since and Until bound the period. Until defaults to now, Since to
+	// DefaultPeriod before Until.
+	Since time.Time
+	Until time.Time
+
+	MinConfidence float64 // Default: DefaultMinConfidence
+	MaxItems      int     // Items per section; default: DefaultMaxItems
+}
+
+// Item is a memory or remediation included in a digest.
+type Item struct {
+	Kind       string  `json:"kind"`
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Summary    string  `json:"summary,omitempty"`
+	Outcome    string  `json:"outcome,omitempty"` // Memories: success or failure
+	Confidence float64 `json:"confidence"`
+
+	topic string // Tag or category the item is grouped under
+}
+
+// Topic groups the items of a section by tag or category.
+type Topic struct {
+	Name  string `json:"name"`
+	Items []Item `json:"items"`
+}
+
+// Section groups the items of a project, or the remediations shared by the
+// team or organization.
+type Section struct {
+	Name   string  `json:"name"`
+	Topics []Topic `json:"topics"`
+}
+
+// Digest is a generated newsletter.
+type Digest struct {
+	TenantID     string    `json:"tenant_id"`
+	TeamID       string    `json:"team_id,omitempty"`
+	Since        time.Time `json:"since"`
+	Until        time.Time `json:"until"`
+	Memories     int       `json:"memories"`
+	Remediations int       `json:"remediations"`
+	Sections     []Section `json:"sections"`
+
+	// Markdown is the newsletter. It is the grouped listing when the LLM
+	// did not write it.
+	Markdown    string    `json:"markdown"`
+	LLMWritten  bool      `json:"llm_written"`
+	Warnings    []string  `json:"warnings,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Scope names what the digest covers: the team ID, or "org".
+func (d *Digest) Scope() string {
+	if d.TeamID != "" {
+		return d.TeamID
+	}
+	return "org"
+}
+
+// Generator generates digests.
+type Generator struct {
+	memories     MemorySource
+	remediations RemediationSource
+	logger       *zap.Logger
+
+	llm      LLMClient
+	usage    analytics.UsageRecorder
+	budget   analytics.LLMBudget
+	policy   *resilience.Policy
+	scrubber secrets.Scrubber
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithLLMClient has client write digests. Without one, digests are the
+// grouped listing.
+func WithLLMClient(client LLMClient) Option {
+	return func(g *Generator) {
+		g.llm = client
+	}
+}
+
+// WithUsageRecorder reports the estimated token usage of digests to r.
+func WithUsageRecorder(r analytics.UsageRecorder) Option {
+	return func(g *Generator) {
+		g.usage = r
+	}
+}
+
+// WithBudget checks b before each LLM request; digests of tenants whose
+// budget is exhausted are the grouped listing.
+func WithBudget(b analytics.LLMBudget) Option {
+	return func(g *Generator) {
+		g.budget = b
+	}
+}
+
+// WithLLMPolicy applies p's timeouts, retries and circuit breaking to LLM
+// requests.
+func WithLLMPolicy(p *resilience.Policy) Option {
+	return func(g *Generator) {
+		g.policy = p
+	}
+}
+
+// WithScrubber redacts secrets from items before they are sent to the LLM
+// or written out.
+func WithScrubber(s secrets.Scrubber) Option {
+	return func(g *Generator) {
+		g.scrubber = s
+	}
+}
+
+// NewGenerator creates a Generator. remediations may be nil to digest
+// memories only.
+func NewGenerator(memories MemorySource, remediations RemediationSource, logger *zap.Logger, opts ...Option) (*Generator, error) {
+	if memories == nil {
+		return nil, errors.New("memory source is required for digests")
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	g := &Generator{memories: memories, remediations: remediations, logger: logger}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, nil
+}
+
+// Generate collects the items of the period and writes the digest. A
+// digest of a quiet period says so rather than failing.
+func (g *Generator) Generate(ctx context.Context, opts Options) (*Digest, error) {
+	if opts.TenantID == "" {
+		return nil, errors.New("tenant_id is required")
+	}
+	if opts.Until.IsZero() {
+		opts.Until = time.Now()
+	}
+	if opts.Since.IsZero() {
+		opts.Since = opts.Until.Add(-DefaultPeriod)
+	}
+	if !opts.Since.Before(opts.Until) {
+		return nil, errors.New("since must be before until")
+	}
+	if opts.MinConfidence <= 0 {
+		opts.MinConfidence = DefaultMinConfidence
+	}
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = DefaultMaxItems
+	}
+
+	d := &Digest{
+		TenantID:    opts.TenantID,
+		TeamID:      opts.TeamID,
+		Since:       opts.Since,
+		Until:       opts.Until,
+		GeneratedAt: time.Now(),
+	}
+	for _, projectID := range dedupe(opts.ProjectIDs) {
+		items, err := g.projectMemories(ctx, opts, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("collecting memories of %s: %w", projectID, err)
+		}
+		d.Memories += len(items)
+		d.addSection(projectID, items, opts.MaxItems)
+	}
+	if err := g.addRemediations(ctx, opts, d); err != nil {
+		return nil, err
+	}
+
+	listing := d.listing(opts.MinConfidence)
+	d.Markdown = listing
+	if d.Memories+d.Remediations > 0 {
+		g.write(ctx, d, listing)
+	}
+
+	g.logger.Info("knowledge digest generated",
+		zap.String("tenant_id", d.TenantID),
+		zap.String("team_id", d.TeamID),
+		zap.Int("memories", d.Memories),
+		zap.Int("remediations", d.Remediations),
+		zap.Bool("llm_written", d.LLMWritten))
+	return d, nil
+}
+
+// projectMemories returns the active memories of a project recorded in the
+// period with enough confidence.
+func (g *Generator) projectMemories(ctx context.Context, opts Options, projectID string) ([]Item, error) {
+	ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{TenantID: opts.TenantID, ProjectID: projectID})
+	var items []Item
+	err := g.memories.StreamMemories(ctx, projectID, 0, func(m reasoningbank.Memory) error {
+		if m.State != "" && m.State != reasoningbank.MemoryStateActive {
+			return nil
+		}
+		if m.Confidence < opts.MinConfidence || m.CreatedAt.Before(opts.Since) || !m.CreatedAt.Before(opts.Until) {
+			return nil
+		}
+		summary := m.Description
+		if summary == "" {
+			summary = m.Content
+		}
+		topic := "general"
+		if len(m.Tags) > 0 {
+			topic = strings.ToLower(m.Tags[0])
+		}
+		items = append(items, Item{
+			Kind:       KindMemory,
+			ID:         m.ID,
+			Title:      g.scrub(m.Title),
+			Summary:    g.scrub(truncate(summary, summaryLen)),
+			Outcome:    string(m.Outcome),
+			Confidence: m.Confidence,
+			topic:      topic,
+		})
+		return nil
+	})
+	return items, err
+}
+
+// addRemediations adds the team- and org-scoped remediations of the period.
+func (g *Generator) addRemediations(ctx context.Context, opts Options, d *Digest) error {
+	if g.remediations == nil {
+		return nil
+	}
+	req := &remediation.ListRequest{
+		TenantID:      opts.TenantID,
+		Scope:         remediation.ScopeOrg,
+		Since:         opts.Since,
+		MinConfidence: opts.MinConfidence,
+	}
+	if opts.TeamID != "" {
+		req.Scope, req.TeamID, req.IncludeHierarchy = remediation.ScopeTeam, opts.TeamID, true
+	}
+	rems, err := g.remediations.List(ctx, req)
+	if err != nil {
+		return fmt.Errorf("collecting remediations: %w", err)
+	}
+
+	var team, org []Item
+	for _, rem := range rems {
+		if !rem.CreatedAt.Before(opts.Until) {
+			continue
+		}
+		item := Item{
+			Kind:       KindRemediation,
+			ID:         rem.ID,
+			Title:      g.scrub(rem.Title),
+			Summary:    g.scrub(truncate(rem.Solution, summaryLen)),
+			Confidence: rem.Confidence,
+			topic:      string(rem.Category),
+		}
+		if rem.Scope == remediation.ScopeTeam {
+			team = append(team, item)
+		} else {
+			org = append(org, item)
+		}
+	}
+	d.Remediations = len(team) + len(org)
+	d.addSection(SectionTeam, team, opts.MaxItems)
+	d.addSection(SectionOrg, org, opts.MaxItems)
+	return nil
+}
+
+// addSection groups items by topic into a section, keeping the maxItems
+// most confident. Topics with the most items come first.
+func (d *Digest) addSection(name string, items []Item, maxItems int) {
+	if len(items) == 0 {
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Confidence > items[j].Confidence
+	})
+	if len(items) > maxItems {
+		items = items[:maxItems]
+	}
+
+	byTopic := make(map[string][]Item)
+	for _, item := range items {
+		byTopic[item.topic] = append(byTopic[item.topic], item)
+	}
+	section := Section{Name: name}
+	for topic, topicItems := range byTopic {
+		section.Topics = append(section.Topics, Topic{Name: topic, Items: topicItems})
+	}
+	sort.Slice(section.Topics, func(i, j int) bool {
+		if len(section.Topics[i].Items) != len(section.Topics[j].Items) {
+			return len(section.Topics[i].Items) > len(section.Topics[j].Items)
+		}
+		return section.Topics[i].Name < section.Topics[j].Name
+	})
+	d.Sections = append(d.Sections, section)
+}
+
+// listing renders the grouped items as markdown.
+func (d *Digest) listing(minConfidence float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Knowledge digest: %s\n\n", d.Scope())
+	fmt.Fprintf(&b, "%s to %s. ", d.Since.Format(time.DateOnly), d.Until.Format(time.DateOnly))
+	if d.Memories+d.Remediations == 0 {
+		fmt.Fprintf(&b, "No memories or remediations with a confidence of at least %.2f were recorded.\n", minConfidence)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d memories and %d remediations with a confidence of at least %.2f.\n",
+		d.Memories, d.Remediations, minConfidence)
+
+	for _, section := range d.Sections {
+		fmt.Fprintf(&b, "\n## %s\n", section.Name)
+		for _, topic := range section.Topics {
+			fmt.Fprintf(&b, "\n### %s\n\n", topic.Name)
+			for _, item := range topic.Items {
+				detail := item.Kind
+				if item.Outcome != "" {
+					detail += ", " + item.Outcome
+				}
+				fmt.Fprintf(&b, "- **%s** (%s, confidence %.2f)", item.Title, detail, item.Confidence)
+				if item.Summary != "" {
+					fmt.Fprintf(&b, ": %s", oneLine(item.Summary))
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// digestPrompt asks the LLM to write the newsletter from the listing.
+const digestPrompt = `You write a weekly engineering newsletter from an organization's knowledge base.
+Below are the memories (lessons agents learned while working) and remediations
+(fixes for recurring errors) recorded during the period with high confidence,
+grouped by project and topic.
+
+Write the newsletter in markdown:
+- Start with a level 1 heading and a two or three sentence overview of the period.
+- Keep one level 2 section per project and one level 3 section per topic, in the given order.
+- Summarize each item in one or two sentences; merge items that say the same thing.
+- Use only facts from the items below.
+
+Reply with the markdown only.
+
+`
+
+// write has the LLM write the digest from listing. The listing stays the
+// digest when the LLM is unavailable or fails.
+func (g *Generator) write(ctx context.Context, d *Digest, listing string) {
+	if g.llm == nil {
+		return
+	}
+	ctx = analytics.ContextWithScope(ctx, analytics.Scope{TenantID: d.TenantID})
+	if g.budget != nil {
+		if err := g.budget.Allow(ctx, analytics.FeatureDigest); err != nil {
+			g.logger.Warn("LLM budget exhausted, digest is the plain listing", zap.Error(err))
+			d.Warnings = append(d.Warnings, fmt.Sprintf("digest not written by the LLM: %v", err))
+			return
+		}
+	}
+
+	prompt := digestPrompt + listing
+	var markdown string
+	err := g.policy.Do(ctx, analytics.FeatureDigest, func(ctx context.Context) error {
+		var err error
+		markdown, err = g.llm.Complete(ctx, prompt)
+		if g.usage != nil {
+			// LLMClient does not expose token counts, so estimate them
+			u := analytics.LLMUsage{
+				Feature:      analytics.FeatureDigest,
+				TenantID:     d.TenantID,
+				InputTokens:  analytics.EstimateTokens(prompt),
+				OutputTokens: analytics.EstimateTokens(markdown),
+				Failed:       err != nil,
+			}
+			u.Provider, u.Model = analytics.DescribeModel(g.llm)
+			g.usage.RecordUsage(ctx, u)
+		}
+		return err
+	})
+	markdown = strings.TrimSpace(markdown)
+	if err == nil && markdown == "" {
+		err = errors.New("empty response")
+	}
+	if err != nil {
+		g.logger.Warn("LLM digest writing failed, digest is the plain listing", zap.Error(err))
+		d.Warnings = append(d.Warnings, fmt.Sprintf("digest not written by the LLM: %v", err))
+		return
+	}
+	d.Markdown = markdown + "\n"
+	d.LLMWritten = true
+}
+
+func (g *Generator) scrub(s string) string {
+	if g.scrubber == nil {
+		return s
+	}
+	return g.scrubber.Scrub(s).Scrubbed
+}
+
+// dedupe returns values without duplicates or empty strings, in order.
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// oneLine joins the lines of s so it fits a list item.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,189 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+var testUntil = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+// fakeMemories serves memories by project and records the tenant asked for.
+type fakeMemories struct {
+	byProject map[string][]reasoningbank.Memory
+	tenants   []string
+}
+
+func (f *fakeMemories) StreamMemories(ctx context.Context, projectID string, batchSize int, fn func(reasoningbank.Memory) error) error {
+	if info, err := vectorstore.TenantFromContext(ctx); err == nil {
+		f.tenants = append(f.tenants, info.TenantID)
+	}
+	for _, m := range f.byProject[projectID] {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeRemediations returns fixed remediations and records the request.
+type fakeRemediations struct {
+	rems []*remediation.Remediation
+	req  *remediation.ListRequest
+}
+
+func (f *fakeRemediations) List(ctx context.Context, req *remediation.ListRequest) ([]*remediation.Remediation, error) {
+	f.req = req
+	return f.rems, nil
+}
+
+// fakeLLM returns a fixed reply and records the prompt.
+type fakeLLM struct {
+	reply  string
+	err    error
+	prompt string
+}
+
+func (f *fakeLLM) Complete(ctx context.Context, prompt string) (string, error) {
+	f.prompt = prompt
+	return f.reply, f.err
+}
+
+func newTestSources() (*fakeMemories, *fakeRemediations) {
+	day := testUntil.Add(-24 * time.Hour)
+	memories := &fakeMemories{byProject: map[string][]reasoningbank.Memory{
+		"api": {
+			{ID: "m1", Title: "Retry idempotent writes", Content: "Use idempotency keys.", Outcome: reasoningbank.OutcomeSuccess, Confidence: 0.9, Tags: []string{"Reliability"}, State: reasoningbank.MemoryStateActive, CreatedAt: day},
+			{ID: "m2", Title: "Pin the Go toolchain", Description: "CI broke on a toolchain upgrade.", Confidence: 0.8, Tags: []string{"ci"}, CreatedAt: day},
+			{ID: "m3", Title: "Unsure idea", Confidence: 0.4, CreatedAt: day},
+			{ID: "m4", Title: "Last month", Confidence: 0.9, CreatedAt: testUntil.AddDate(0, -1, 0)},
+			{ID: "m5", Title: "Merged away", Confidence: 0.9, State: reasoningbank.MemoryStateArchived, CreatedAt: day},
+		},
+		"web": {
+			{ID: "m6", Title: "Lazy-load the editor", Confidence: 0.75, CreatedAt: day},
+		},
+	}}
+	remediations := &fakeRemediations{rems: []*remediation.Remediation{
+		{ID: "r1", Title: "Connection reset by proxy", Solution: "Raise the idle timeout.", Category: "network", Confidence: 0.85, Scope: remediation.ScopeTeam, CreatedAt: day},
+		{ID: "r2", Title: "Expired signing key", Solution: "Rotate the key.", Category: "security", Confidence: 0.95, Scope: remediation.ScopeOrg, CreatedAt: day},
+	}}
+	return memories, remediations
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	memories, remediations := newTestSources()
+	g, err := NewGenerator(memories, remediations, zap.NewNop())
+	require.NoError(t, err)
+
+	d, err := g.Generate(context.Background(), Options{
+		TenantID:   "acme",
+		TeamID:     "platform",
+		ProjectIDs: []string{"api", "web", "api"},
+		Until:      testUntil,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, testUntil.Add(-DefaultPeriod), d.Since)
+	assert.Equal(t, 3, d.Memories, "low confidence, old and archived memories are left out")
+	assert.Equal(t, 2, d.Remediations)
+	assert.Equal(t, []string{"acme", "acme"}, memories.tenants)
+	assert.Equal(t, remediation.ScopeTeam, remediations.req.Scope)
+	assert.True(t, remediations.req.IncludeHierarchy)
+	assert.Equal(t, DefaultMinConfidence, remediations.req.MinConfidence)
+
+	require.Len(t, d.Sections, 4)
+	assert.Equal(t, "api", d.Sections[0].Name)
+	assert.Equal(t, "ci", d.Sections[0].Topics[0].Name)
+	assert.Equal(t, "reliability", d.Sections[0].Topics[1].Name)
+	assert.Equal(t, "general", d.Sections[1].Topics[0].Name)
+	assert.Equal(t, SectionTeam, d.Sections[2].Name)
+	assert.Equal(t, SectionOrg, d.Sections[3].Name)
+
+	assert.False(t, d.LLMWritten)
+	assert.Contains(t, d.Markdown, "# Knowledge digest: platform")
+	assert.Contains(t, d.Markdown, "### reliability")
+	assert.Contains(t, d.Markdown, "- **Retry idempotent writes** (memory, success, confidence 0.90): Use idempotency keys.")
+	assert.Contains(t, d.Markdown, "- **Expired signing key** (remediation, confidence 0.95): Rotate the key.")
+	assert.NotContains(t, d.Markdown, "Unsure idea")
+}
+
+func TestGenerator_GenerateQuietPeriod(t *testing.T) {
+	g, err := NewGenerator(&fakeMemories{}, nil, zap.NewNop(), WithLLMClient(&fakeLLM{err: errors.New("not called")}))
+	require.NoError(t, err)
+
+	d, err := g.Generate(context.Background(), Options{TenantID: "acme", ProjectIDs: []string{"api"}, Until: testUntil})
+	require.NoError(t, err)
+	assert.Contains(t, d.Markdown, "No memories or remediations")
+	assert.Empty(t, d.Warnings, "the LLM is not asked to write an empty digest")
+
+	_, err = g.Generate(context.Background(), Options{ProjectIDs: []string{"api"}})
+	assert.Error(t, err, "tenant is required")
+}
+
+func TestGenerator_LLM(t *testing.T) {
+	memories, remediations := newTestSources()
+	llm := &fakeLLM{reply: "# This week at acme\n\nA quiet week.\n"}
+	g, err := NewGenerator(memories, remediations, zap.NewNop(), WithLLMClient(llm))
+	require.NoError(t, err)
+
+	d, err := g.Generate(context.Background(), Options{TenantID: "acme", ProjectIDs: []string{"api"}, Until: testUntil})
+	require.NoError(t, err)
+	assert.True(t, d.LLMWritten)
+	assert.Equal(t, "# This week at acme\n\nA quiet week.\n", d.Markdown)
+	assert.Contains(t, llm.prompt, "Retry idempotent writes", "the prompt carries the listing")
+
+	llm.err = errors.New("overloaded")
+	d, err = g.Generate(context.Background(), Options{TenantID: "acme", ProjectIDs: []string{"api"}, Until: testUntil})
+	require.NoError(t, err)
+	assert.False(t, d.LLMWritten)
+	assert.Contains(t, d.Markdown, "# Knowledge digest: org")
+	require.Len(t, d.Warnings, 1)
+	assert.Contains(t, d.Warnings[0], "overloaded")
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	memories, remediations := newTestSources()
+	g, err := NewGenerator(memories, remediations, zap.NewNop())
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "digests")
+	var delivered *Digest
+	var deliveredPath string
+	s, err := NewScheduler(g, Options{TenantID: "acme", TeamID: "../platform", ProjectIDs: []string{"api"}}, 24*time.Hour, dir,
+		func(ctx context.Context, d *Digest, path string) { delivered, deliveredPath = d, path }, zap.NewNop())
+	require.NoError(t, err)
+
+	d, err := s.RunOnce(context.Background(), testUntil)
+	require.NoError(t, err)
+	assert.Same(t, d, delivered)
+	assert.Equal(t, testUntil.Add(-24*time.Hour), d.Since)
+	assert.Equal(t, filepath.Join(dir, "digest----platform-2026-10-16.md"), deliveredPath)
+
+	data, err := os.ReadFile(deliveredPath)
+	require.NoError(t, err)
+	assert.Equal(t, d.Markdown, string(data))
+}
+
+func TestNewScheduler(t *testing.T) {
+	g, err := NewGenerator(&fakeMemories{}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	_, err = NewScheduler(g, Options{TenantID: "acme"}, 0, "", nil, zap.NewNop())
+	assert.Error(t, err, "a directory or listener is required")
+	_, err = NewScheduler(g, Options{}, 0, t.TempDir(), nil, zap.NewNop())
+	assert.Error(t, err, "tenant is required")
+	s, err := NewScheduler(g, Options{TenantID: "acme"}, 0, t.TempDir(), nil, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPeriod, s.interval)
+}
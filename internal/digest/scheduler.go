@@ -0,0 +1,195 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Listener receives every scheduled digest and the path it was written to,
+// empty when it was not written to a directory.
+type Listener func(ctx context.Context, d *Digest, path string)
+
+// Write writes d's markdown to dir as digest-<scope>-<date>.md, dated by the
+// end of its period, and returns the path. A digest of the same scope and
+// date is replaced.
+func Write(dir string, d *Digest) (string, error) {
+	if dir == "" {
+		return "", errors.New("digest directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("creating digest directory: %w", err)
+	}
+	name := fmt.Sprintf("digest-%s-%s.md", fileSafe(d.Scope()), d.Until.Format(time.DateOnly))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(d.Markdown), 0o640); err != nil {
+		return "", fmt.Errorf("writing digest: %w", err)
+	}
+	return path, nil
+}
+
+// fileSafe replaces the characters of s that do not belong in a file name,
+// so team IDs cannot name a path outside the directory.
+func fileSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+}
+
+// Scheduler generates a digest every interval covering the interval just
+// ended, writes it to a directory and passes it to a listener.
+//
+// Like the memory schedulers, it is started and stopped explicitly and
+// recovers from panics in individual runs.
+type Scheduler struct {
+	generator *Generator
+	opts      Options
+	interval  time.Duration
+	dir       string
+	listener  Listener
+
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+
+	logger *zap.Logger
+}
+
+// NewScheduler creates a digest scheduler. Digests are written to dir when
+// it is set and passed to listener when it is not nil; one of them is
+// required. opts.Since and opts.Until are set for each run.
+func NewScheduler(generator *Generator, opts Options, interval time.Duration, dir string, listener Listener, logger *zap.Logger) (*Scheduler, error) {
+	if generator == nil {
+		return nil, errors.New("generator cannot be nil")
+	}
+	if logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	if opts.TenantID == "" {
+		return nil, errors.New("tenant_id is required")
+	}
+	if dir == "" && listener == nil {
+		return nil, errors.New("a digest directory or listener is required")
+	}
+	if interval <= 0 {
+		interval = DefaultPeriod
+	}
+
+	return &Scheduler{
+		generator: generator,
+		opts:      opts,
+		interval:  interval,
+		dir:       dir,
+		listener:  listener,
+		stopCh:    make(chan struct{}),
+		logger:    logger,
+	}, nil
+}
+
+// Start begins scheduled digests. Returns an error if already running.
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return errors.New("scheduler is already running")
+	}
+
+	s.stopCh = make(chan struct{})
+	s.running = true
+
+	s.logger.Info("digest scheduler started",
+		zap.Duration("interval", s.interval),
+		zap.String("team_id", s.opts.TeamID),
+		zap.Int("project_count", len(s.opts.ProjectIDs)),
+	)
+
+	go s.run()
+
+	return nil
+}
+
+// Stop stops scheduled digests. Calling Stop on a stopped scheduler is a
+// no-op.
+func (s *Scheduler) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.logger.Info("stopping digest scheduler")
+	s.running = false
+	close(s.stopCh)
+
+	return nil
+}
+
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.safeRunDigest()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// safeRunDigest wraps RunOnce with panic recovery.
+func (s *Scheduler) safeRunDigest() {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("digest run panicked, continuing scheduler",
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if _, err := s.RunOnce(ctx, time.Now()); err != nil {
+		s.logger.Error("digest failed", zap.Error(err))
+	}
+}
+
+// RunOnce generates and delivers the digest of the interval ending at
+// until.
+func (s *Scheduler) RunOnce(ctx context.Context, until time.Time) (*Digest, error) {
+	opts := s.opts
+	opts.Since, opts.Until = until.Add(-s.interval), until
+	d, err := s.generator.Generate(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var path string
+	if s.dir != "" {
+		path, err = Write(s.dir, d)
+		if err != nil {
+			return d, err
+		}
+		s.logger.Info("digest written", zap.String("path", path))
+	}
+	if s.listener != nil {
+		s.listener(ctx, d, path)
+	}
+	return d, nil
+}
@@ -0,0 +1,354 @@
+// Package diskspace watches free space on the volume holding the vectorstore.
+//
+// The chromem store serializes collections with gob; a write that fails
+// mid-encode because the disk is full leaves a corrupt file behind. The
+// Monitor avoids that by switching the server to read-only mode before the
+// disk fills, and switching back once space is freed.
+package diskspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+)
+
+const instrumentationName = "github.com/fyrsmithlabs/contextd/internal/diskspace"
+
+// Level is the severity of the current disk usage.
+type Level int
+
+const (
+	// LevelOK means usage is below the warning threshold.
+	LevelOK Level = iota
+	// LevelWarning means usage crossed WarnPercent; writes still succeed.
+	LevelWarning
+	// LevelCritical means writes are blocked until space is freed.
+	LevelCritical
+)
+
+// String returns the level name used in logs and status output.
+func (l Level) String() string {
+	switch l {
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return "ok"
+	}
+}
+
+// Usage is a snapshot of the volume holding Path.
+type Usage struct {
+	Path       string `json:"path"`
+	TotalBytes uint64 `json:"total_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"`
+}
+
+// UsedPercent returns the used share of the volume, 0-100.
+func (u Usage) UsedPercent() float64 {
+	if u.TotalBytes == 0 {
+		return 0
+	}
+	return float64(u.TotalBytes-u.FreeBytes) / float64(u.TotalBytes) * 100
+}
+
+// Config configures a Monitor.
+type Config struct {
+	// Path is the directory to watch, typically the chromem data path. It
+	// need not exist yet; its nearest existing parent is measured.
+	Path string
+
+	// Interval between checks. Default: 30 seconds.
+	Interval time.Duration
+
+	// WarnPercent logs a warning once usage reaches it. Default: 85.
+	WarnPercent float64
+
+	// CriticalPercent blocks writes once usage reaches it. Default: 95.
+	CriticalPercent float64
+
+	// MinFreeBytes blocks writes when free space drops below it, regardless
+	// of percentage, so large disks still keep headroom. Default: 512 MiB.
+	MinFreeBytes uint64
+}
+
+// Monitor periodically measures disk usage and engages read-only mode when
+// space runs out.
+type Monitor struct {
+	config    Config
+	mode      *readonly.Mode
+	logger    *zap.Logger
+	usageFn   func(path string) (Usage, error)
+	freeGauge metric.Int64ObservableGauge
+	usedGauge metric.Float64ObservableGauge
+
+	mu       sync.RWMutex
+	last     Usage
+	level    Level
+	reason   string // read-only reason we set; empty when not engaged
+	measured bool
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewMonitor creates a Monitor. mode is the read-only switch engaged at the
+// critical level; it may be nil, in which case the monitor only warns.
+func NewMonitor(cfg Config, mode *readonly.Mode, logger *zap.Logger) (*Monitor, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.WarnPercent <= 0 {
+		cfg.WarnPercent = 85
+	}
+	if cfg.CriticalPercent <= 0 {
+		cfg.CriticalPercent = 95
+	}
+	if cfg.MinFreeBytes == 0 {
+		cfg.MinFreeBytes = 512 << 20
+	}
+	if cfg.WarnPercent > cfg.CriticalPercent || cfg.CriticalPercent > 100 {
+		return nil, fmt.Errorf("invalid thresholds: warn %.0f%% must not exceed critical %.0f%% (max 100%%)",
+			cfg.WarnPercent, cfg.CriticalPercent)
+	}
+
+	m := &Monitor{
+		config:  cfg,
+		mode:    mode,
+		logger:  logger,
+		usageFn: diskUsage,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	m.initMetrics()
+	return m, nil
+}
+
+// initMetrics registers observable gauges reporting the last measurement.
+func (m *Monitor) initMetrics() {
+	meter := otel.Meter(instrumentationName)
+
+	var err error
+	m.freeGauge, err = meter.Int64ObservableGauge(
+		"contextd.storage.free_bytes",
+		metric.WithDescription("Free bytes on the volume holding the vectorstore"),
+		metric.WithUnit("By"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			if usage, ok := m.observed(); ok {
+				o.Observe(int64(usage.FreeBytes), metric.WithAttributes(attribute.String("path", usage.Path)))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		m.logger.Warn("failed to create free bytes gauge", zap.Error(err))
+	}
+
+	m.usedGauge, err = meter.Float64ObservableGauge(
+		"contextd.storage.used_percent",
+		metric.WithDescription("Used share of the volume holding the vectorstore, 0-100. Writes are blocked at the critical threshold."),
+		metric.WithUnit("%"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			if usage, ok := m.observed(); ok {
+				o.Observe(usage.UsedPercent(), metric.WithAttributes(attribute.String("path", usage.Path)))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		m.logger.Warn("failed to create used percent gauge", zap.Error(err))
+	}
+}
+
+func (m *Monitor) observed() (Usage, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.last, m.measured
+}
+
+// Start runs an immediate check, then checks every Interval until Stop is
+// called or ctx is canceled.
+func (m *Monitor) Start(ctx context.Context) {
+	m.Check()
+
+	go func() {
+		defer close(m.doneCh)
+
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+				m.Check()
+			}
+		}
+	}()
+}
+
+// Stop halts periodic checks and waits for the goroutine to exit. It must
+// only be called after Start.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+	<-m.doneCh
+}
+
+// Usage returns the last measurement and its level.
+func (m *Monitor) Usage() (Usage, Level) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.last, m.level
+}
+
+// Check measures disk usage once, logs level changes, and engages or
+// releases read-only mode.
+func (m *Monitor) Check() Level {
+	usage, err := m.usageFn(m.config.Path)
+	if err != nil {
+		m.logger.Warn("disk usage check failed", zap.String("path", m.config.Path), zap.Error(err))
+		_, level := m.Usage()
+		return level
+	}
+
+	level := m.levelFor(usage)
+
+	m.mu.Lock()
+	previous := m.level
+	m.last = usage
+	m.level = level
+	m.measured = true
+	m.mu.Unlock()
+
+	fields := []zap.Field{
+		zap.String("path", usage.Path),
+		zap.Uint64("free_bytes", usage.FreeBytes),
+		zap.Float64("used_percent", usage.UsedPercent()),
+	}
+	switch {
+	case level == LevelCritical:
+		m.engage(usage)
+		if previous != LevelCritical {
+			m.logger.Error("disk space critical, writes blocked", fields...)
+		}
+	case level == LevelWarning && previous != LevelWarning:
+		m.release()
+		m.logger.Warn("disk space running low", fields...)
+	case level == LevelOK:
+		m.release()
+		if previous != LevelOK {
+			m.logger.Info("disk space recovered", fields...)
+		}
+	}
+	return level
+}
+
+func (m *Monitor) levelFor(u Usage) Level {
+	used := u.UsedPercent()
+	switch {
+	case used >= m.config.CriticalPercent || u.FreeBytes < m.config.MinFreeBytes:
+		return LevelCritical
+	case used >= m.config.WarnPercent:
+		return LevelWarning
+	default:
+		return LevelOK
+	}
+}
+
+// engage turns on read-only mode with an actionable reason. The reason is
+// refreshed on every critical check so it reflects current free space.
+func (m *Monitor) engage(u Usage) {
+	if m.mode == nil {
+		return
+	}
+
+	reason := fmt.Sprintf(
+		"disk space low: %s free (%.1f%% used) on the volume holding %s; free space or move the data path, writes resume automatically below %.0f%% used and above %s free",
+		formatBytes(u.FreeBytes), u.UsedPercent(), u.Path, m.config.CriticalPercent, formatBytes(m.config.MinFreeBytes))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Don't take over a read-only mode someone else turned on
+	status := m.mode.Status()
+	if status.Enabled && status.Reason != m.reason {
+		return
+	}
+	m.mode.Set(true, reason)
+	m.reason = reason
+}
+
+// release turns off read-only mode if the monitor turned it on and nobody
+// has changed it since.
+func (m *Monitor) release() {
+	if m.mode == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.reason == "" {
+		return
+	}
+	if status := m.mode.Status(); status.Enabled && status.Reason == m.reason {
+		m.mode.Set(false, "")
+	}
+	m.reason = ""
+}
+
+// nearestExisting returns path or its closest existing ancestor, so a data
+// directory that has not been created yet is measured on its future volume.
+func nearestExisting(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", fmt.Errorf("no existing ancestor for %s", path)
+		}
+		path = parent
+	}
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
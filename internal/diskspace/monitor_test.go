@@ -0,0 +1,127 @@
+package diskspace
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+)
+
+const gib = 1 << 30
+
+func newTestMonitor(t *testing.T, mode *readonly.Mode, usage *Usage) *Monitor {
+	t.Helper()
+	m, err := NewMonitor(Config{Path: "/data"}, mode, zap.NewNop())
+	require.NoError(t, err)
+	m.usageFn = func(path string) (Usage, error) {
+		u := *usage
+		u.Path = path
+		return u, nil
+	}
+	return m
+}
+
+func TestMonitor_Levels(t *testing.T) {
+	mode := readonly.New(false, "")
+	usage := &Usage{TotalBytes: 100 * gib, FreeBytes: 50 * gib}
+	m := newTestMonitor(t, mode, usage)
+
+	assert.Equal(t, LevelOK, m.Check())
+	assert.False(t, mode.Enabled())
+
+	usage.FreeBytes = 10 * gib
+	assert.Equal(t, LevelWarning, m.Check())
+	assert.False(t, mode.Enabled(), "warning level does not block writes")
+
+	usage.FreeBytes = 4 * gib
+	assert.Equal(t, LevelCritical, m.Check())
+	require.True(t, mode.Enabled())
+	err := mode.Check("memory_record")
+	require.ErrorIs(t, err, readonly.ErrReadOnly)
+	assert.Contains(t, err.Error(), "disk space low")
+	assert.Contains(t, err.Error(), "4.0 GiB free")
+
+	usage.FreeBytes = 30 * gib
+	assert.Equal(t, LevelOK, m.Check())
+	assert.False(t, mode.Enabled(), "recovery releases read-only mode")
+
+	got, level := m.Usage()
+	assert.Equal(t, LevelOK, level)
+	assert.Equal(t, "/data", got.Path)
+}
+
+func TestMonitor_MinFreeBytes(t *testing.T) {
+	// 1% used, but under the absolute free-space floor
+	m := newTestMonitor(t, nil, &Usage{TotalBytes: 1 << 29, FreeBytes: 1 << 28})
+	assert.Equal(t, LevelCritical, m.Check())
+}
+
+func TestMonitor_RespectsManualReadOnly(t *testing.T) {
+	usage := &Usage{TotalBytes: 100 * gib, FreeBytes: 1 * gib}
+
+	t.Run("does not override an operator reason", func(t *testing.T) {
+		mode := readonly.New(true, "migration")
+		m := newTestMonitor(t, mode, usage)
+
+		m.Check()
+		assert.Equal(t, "migration", mode.Status().Reason)
+
+		usage.FreeBytes = 50 * gib
+		m.Check()
+		assert.True(t, mode.Enabled(), "recovery must not release a manual read-only mode")
+		usage.FreeBytes = 1 * gib
+	})
+
+	t.Run("leaves mode alone once an operator changes it", func(t *testing.T) {
+		mode := readonly.New(false, "")
+		m := newTestMonitor(t, mode, usage)
+
+		m.Check()
+		require.True(t, mode.Enabled())
+		mode.Set(true, "investigating corruption")
+
+		usage.FreeBytes = 50 * gib
+		m.Check()
+		assert.True(t, mode.Enabled())
+		assert.Equal(t, "investigating corruption", mode.Status().Reason)
+	})
+}
+
+func TestMonitor_CheckError(t *testing.T) {
+	m := newTestMonitor(t, nil, &Usage{TotalBytes: 100 * gib, FreeBytes: 1 * gib})
+	require.Equal(t, LevelCritical, m.Check())
+
+	m.usageFn = func(string) (Usage, error) { return Usage{}, errors.New("statfs failed") }
+	assert.Equal(t, LevelCritical, m.Check(), "a failed check keeps the last level")
+}
+
+func TestNewMonitor_Validation(t *testing.T) {
+	_, err := NewMonitor(Config{}, nil, zap.NewNop())
+	assert.Error(t, err)
+
+	_, err = NewMonitor(Config{Path: "/data", WarnPercent: 96, CriticalPercent: 90}, nil, zap.NewNop())
+	assert.Error(t, err)
+
+	_, err = NewMonitor(Config{Path: "/data"}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestDiskUsage_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not", "created", "yet")
+	usage, err := diskUsage(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, usage.Path)
+	assert.NotZero(t, usage.TotalBytes)
+	assert.LessOrEqual(t, usage.FreeBytes, usage.TotalBytes)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "512.0 MiB", formatBytes(512<<20))
+	assert.Equal(t, "1.5 GiB", formatBytes(3<<29))
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package diskspace
+
+import "syscall"
+
+// diskUsage measures the volume holding path.
+func diskUsage(path string) (Usage, error) {
+	dir, err := nearestExisting(path)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return Usage{}, err
+	}
+
+	// Bavail (not Bfree) is what unprivileged writes can actually use
+	return Usage{
+		Path:       path,
+		TotalBytes: uint64(st.Blocks) * uint64(st.Bsize),
+		FreeBytes:  uint64(st.Bavail) * uint64(st.Bsize),
+	}, nil
+}
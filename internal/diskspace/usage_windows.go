@@ -0,0 +1,25 @@
+//go:build windows
+
+package diskspace
+
+import "golang.org/x/sys/windows"
+
+// diskUsage measures the volume holding path.
+func diskUsage(path string) (Usage, error) {
+	dir, err := nearestExisting(path)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	p, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, &totalFree); err != nil {
+		return Usage{}, err
+	}
+
+	return Usage{Path: path, TotalBytes: total, FreeBytes: free}, nil
+}
@@ -0,0 +1,60 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DepthTracker wraps a Provider and counts the embedding requests that are
+// queued or running, so operators can see when embedding is the bottleneck.
+type DepthTracker struct {
+	Provider
+	depth atomic.Int64
+}
+
+// TrackDepth wraps p in a DepthTracker.
+func TrackDepth(p Provider) *DepthTracker {
+	return &DepthTracker{Provider: p}
+}
+
+// Depth returns the number of embedding requests queued or running.
+func (t *DepthTracker) Depth() int64 {
+	return t.depth.Load()
+}
+
+// EmbedDocuments implements vectorstore.Embedder.
+func (t *DepthTracker) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	t.depth.Add(1)
+	defer t.depth.Add(-1)
+	return t.Provider.EmbedDocuments(ctx, texts)
+}
+
+// EmbedQuery implements vectorstore.Embedder.
+func (t *DepthTracker) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	t.depth.Add(1)
+	defer t.depth.Add(-1)
+	return t.Provider.EmbedQuery(ctx, text)
+}
+
+// drainPollInterval is how often Wait checks the queue depth.
+const drainPollInterval = 10 * time.Millisecond
+
+// Wait blocks until no embedding requests are queued or running, or ctx is
+// done. It is used at shutdown so writes that are being embedded reach the
+// vector store before it closes.
+func (t *DepthTracker) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		if t.Depth() == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d embedding requests still running: %w", t.Depth(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package embeddings
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingProvider blocks embedding calls until release is closed.
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return make([][]float32, len(texts)), nil
+}
+
+func (p *blockingProvider) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return nil, nil
+}
+
+func (p *blockingProvider) Dimension() int { return 384 }
+func (p *blockingProvider) Close() error   { return nil }
+
+func TestDepthTracker(t *testing.T) {
+	inner := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	tracker := TrackDepth(inner)
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = tracker.EmbedQuery(context.Background(), "a")
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = tracker.EmbedDocuments(context.Background(), []string{"b", "c"})
+		done <- struct{}{}
+	}()
+	<-inner.started
+	<-inner.started
+
+	if got := tracker.Depth(); got != 2 {
+		t.Errorf("Depth() = %d while embedding, want 2", got)
+	}
+
+	close(inner.release)
+	<-done
+	<-done
+	if got := tracker.Depth(); got != 0 {
+		t.Errorf("Depth() = %d after embedding, want 0", got)
+	}
+	if err := tracker.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() on an empty queue = %v", err)
+	}
+	if got := tracker.Dimension(); got != 384 {
+		t.Errorf("Dimension() = %d, want 384", got)
+	}
+}
+
+func TestDepthTracker_Wait(t *testing.T) {
+	inner := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	tracker := TrackDepth(inner)
+
+	go func() { _, _ = tracker.EmbedQuery(context.Background(), "a") }()
+	<-inner.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := tracker.Wait(ctx); err == nil {
+		t.Fatal("Wait() returned nil while an embedding is running")
+	}
+
+	close(inner.release)
+	if err := tracker.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() after release = %v", err)
+	}
+}
@@ -26,6 +26,8 @@ type ProviderConfig struct {
 	BaseURL string
 	// CacheDir is the model cache directory (only used for FastEmbed)
 	CacheDir string
+	// APIKey is the bearer token for hosted TEI endpoints (only used for TEI)
+	APIKey string
 }
 
 // detectDimensionFromModel returns the embedding dimension for a model name.
@@ -73,6 +75,7 @@ func NewProvider(cfg ProviderConfig) (Provider, error) {
 		svc, err := NewService(Config{
 			BaseURL: cfg.BaseURL,
 			Model:   cfg.Model,
+			APIKey:  cfg.APIKey,
 		})
 		if err != nil {
 			return nil, err
@@ -12,6 +12,7 @@ import (
 	"os"
 	"time"
 
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 	"go.uber.org/zap"
 )
@@ -24,7 +25,7 @@ var (
 	ErrInvalidConfig = errors.New("invalid configuration")
 
 	// ErrEmbeddingFailed indicates embedding generation failure
-	ErrEmbeddingFailed = errors.New("embedding generation failed")
+	ErrEmbeddingFailed = ctxerrors.New(ctxerrors.CodeDependencyUnavailable, "embedding generation failed")
 )
 
 // Config holds configuration for the embedding service.
@@ -129,6 +130,9 @@ func (s *Service) EmbedDocuments(ctx context.Context, texts []string) ([][]float
 		return nil, genErr
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	if s.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
 
 	resp, err := s.client.Do(httpReq)
 	if err != nil {
@@ -182,6 +186,9 @@ func (s *Service) EmbedQuery(ctx context.Context, text string) ([]float32, error
 		return nil, genErr
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	if s.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
 
 	resp, err := s.client.Do(httpReq)
 	if err != nil {
@@ -0,0 +1,256 @@
+// Package errors defines the contextd error taxonomy: a registry of stable
+// error codes, each mapped to an HTTP status and a retry policy, and typed
+// wrappers that attach a code to an error.
+//
+// The HTTP API and the MCP tools render errors through Payload, so clients
+// can branch on the code, and retry or back off, instead of matching message
+// strings. Import it as ctxerrors to avoid shadowing the standard library:
+//
+//	var ErrNotFound = ctxerrors.NotFound("checkpoint not found")
+//
+//	if ctxerrors.Retryable(err) { ... }
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+)
+
+// Code identifies a class of failure. Codes are stable across versions.
+type Code string
+
+const (
+	// CodeInvalidInput means the request was malformed; fix it before retrying.
+	CodeInvalidInput Code = "INVALID_INPUT"
+	// CodeNotFound means the requested resource does not exist.
+	CodeNotFound Code = "NOT_FOUND"
+	// CodeUnauthorized means the caller may not access the tenant or resource.
+	CodeUnauthorized Code = "UNAUTHORIZED"
+	// CodeQuotaExceeded means a rate limit or budget was hit; retry after
+	// RetryAfter, or with backoff.
+	CodeQuotaExceeded Code = "QUOTA_EXCEEDED"
+	// CodeReadOnly means writes are disabled by an operator.
+	CodeReadOnly Code = "READ_ONLY"
+	// CodeConflict means the resource changed since it was read; re-read it
+	// and retry the update.
+	CodeConflict Code = "CONFLICT"
+	// CodeDependencyUnavailable means a backing service (vector store,
+	// embedder) failed or timed out; retry with backoff.
+	CodeDependencyUnavailable Code = "DEPENDENCY_UNAVAILABLE"
+	// CodeShuttingDown means the server is draining before exit; retry once
+	// it is back, possibly against another instance.
+	CodeShuttingDown Code = "SHUTTING_DOWN"
+	// CodeInternal is any unclassified failure.
+	CodeInternal Code = "INTERNAL_ERROR"
+)
+
+// Spec describes how a code is surfaced to clients.
+type Spec struct {
+	// HTTPStatus is the status the HTTP API responds with.
+	HTTPStatus int
+	// Retryable reports whether the same request may succeed later.
+	Retryable bool
+}
+
+// registry maps every code contextd returns to its Spec. The
+// ERR_VALIDATION_* codes refine INVALID_INPUT.
+var registry = map[Code]Spec{
+	CodeInvalidInput:          {HTTPStatus: http.StatusBadRequest},
+	CodeNotFound:              {HTTPStatus: http.StatusNotFound},
+	CodeUnauthorized:          {HTTPStatus: http.StatusForbidden},
+	CodeQuotaExceeded:         {HTTPStatus: http.StatusTooManyRequests, Retryable: true},
+	CodeReadOnly:              {HTTPStatus: http.StatusServiceUnavailable},
+	CodeConflict:              {HTTPStatus: http.StatusConflict, Retryable: true},
+	CodeDependencyUnavailable: {HTTPStatus: http.StatusServiceUnavailable, Retryable: true},
+	CodeShuttingDown:          {HTTPStatus: http.StatusServiceUnavailable, Retryable: true},
+	CodeInternal:              {HTTPStatus: http.StatusInternalServerError},
+
+	Code(validation.CodeQueryTooLong):        {HTTPStatus: http.StatusBadRequest},
+	Code(validation.CodeContentTooLong):      {HTTPStatus: http.StatusBadRequest},
+	Code(validation.CodeTooManyTags):         {HTTPStatus: http.StatusBadRequest},
+	Code(validation.CodeTagTooLong):          {HTTPStatus: http.StatusBadRequest},
+	Code(validation.CodeTooManyMetadataKeys): {HTTPStatus: http.StatusBadRequest},
+}
+
+// Lookup returns the Spec for code. Unknown codes are treated as internal.
+func Lookup(code Code) Spec {
+	if spec, ok := registry[code]; ok {
+		return spec
+	}
+	return registry[CodeInternal]
+}
+
+// Error is an error with a code from the registry.
+type Error struct {
+	Code    Code
+	Message string
+
+	// RetryAfter, when set, is how long the client should wait before
+	// retrying.
+	RetryAfter time.Duration
+
+	// Details carries machine-readable context, such as the field that
+	// failed validation.
+	Details map[string]any
+
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+// New creates an Error. It is typically used to declare sentinel errors.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap attaches code to err. It returns nil if err is nil.
+func Wrap(code Code, err error) *Error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// NotFound creates a NOT_FOUND error.
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+// Unauthorized creates an UNAUTHORIZED error.
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message)
+}
+
+// Conflict creates a CONFLICT error.
+func Conflict(message string) *Error {
+	return New(CodeConflict, message)
+}
+
+// QuotaExceeded creates a QUOTA_EXCEEDED error. retryAfter may be zero when
+// the wait is unknown.
+func QuotaExceeded(message string, retryAfter time.Duration) *Error {
+	return &Error{Code: CodeQuotaExceeded, Message: message, RetryAfter: retryAfter}
+}
+
+// DependencyUnavailable creates a DEPENDENCY_UNAVAILABLE error for a failed
+// call to dependency. err may be nil.
+func DependencyUnavailable(dependency string, err error) *Error {
+	return &Error{
+		Code:    CodeDependencyUnavailable,
+		Message: dependency + " unavailable",
+		Details: map[string]any{"dependency": dependency},
+		Err:     err,
+	}
+}
+
+// Error implements the error interface. The code is not included so that
+// typed sentinels keep their original messages.
+func (e *Error) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	default:
+		return e.Message + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Classifier is implemented by error types that map themselves onto the
+// registry, so packages with their own error types can take part without
+// this package importing them.
+type Classifier interface {
+	// Classify returns the registry error for the receiver. From sets its
+	// Err to the classified error.
+	Classify() *Error
+}
+
+// From classifies err. It returns the *Error in err's chain if there is one,
+// and otherwise maps validation errors, Classifier errors such as those of
+// context folding, and timeouts onto the registry. Anything else is INTERNAL_ERROR. From returns nil for a
+// nil error.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var cerr *Error
+	if stderrors.As(err, &cerr) {
+		return cerr
+	}
+	if verr, ok := validation.As(err); ok {
+		return &Error{
+			Code:    Code(verr.Code),
+			Message: verr.Message(),
+			Details: map[string]any{"field": verr.Field, "limit": verr.Limit, "actual": verr.Actual},
+			Err:     err,
+		}
+	}
+	var classifier Classifier
+	if stderrors.As(err, &classifier) {
+		cerr := classifier.Classify()
+		cerr.Err = err
+		return cerr
+	}
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return Wrap(CodeDependencyUnavailable, err)
+	}
+	return Wrap(CodeInternal, err)
+}
+
+// CodeOf returns the code for err, or "" for a nil error.
+func CodeOf(err error) Code {
+	if cerr := From(err); cerr != nil {
+		return cerr.Code
+	}
+	return ""
+}
+
+// HTTPStatus returns the HTTP status for err.
+func HTTPStatus(err error) int {
+	return Lookup(CodeOf(err)).HTTPStatus
+}
+
+// Retryable reports whether a request that failed with err may succeed if
+// retried.
+func Retryable(err error) bool {
+	return err != nil && Lookup(CodeOf(err)).Retryable
+}
+
+// Payload is the wire form of an error, returned as the body of HTTP error
+// responses and as _meta.error on failed MCP tool calls.
+type Payload struct {
+	Code              Code           `json:"code"`
+	Message           string         `json:"message"`
+	Retryable         bool           `json:"retryable"`
+	RetryAfterSeconds int            `json:"retry_after_seconds,omitempty"`
+	Details           map[string]any `json:"details,omitempty"`
+}
+
+// PayloadFor builds the Payload for err. The message is err's full text, the
+// same text MCP clients see. It returns nil for a nil error.
+func PayloadFor(err error) *Payload {
+	cerr := From(err)
+	if cerr == nil {
+		return nil
+	}
+	p := &Payload{
+		Code:      cerr.Code,
+		Message:   err.Error(),
+		Retryable: Lookup(cerr.Code).Retryable,
+		Details:   cerr.Details,
+	}
+	if cerr.RetryAfter > 0 {
+		// Round up so clients never retry early
+		p.RetryAfterSeconds = int((cerr.RetryAfter + time.Second - 1) / time.Second)
+	}
+	return p
+}
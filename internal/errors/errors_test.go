@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+)
+
+func TestFrom(t *testing.T) {
+	errMissing := NotFound("memory not found")
+
+	tests := []struct {
+		name      string
+		err       error
+		code      Code
+		status    int
+		retryable bool
+	}{
+		{"wrapped sentinel", fmt.Errorf("get %s: %w", "m-1", errMissing), CodeNotFound, http.StatusNotFound, false},
+		{"unauthorized", Unauthorized("access denied"), CodeUnauthorized, http.StatusForbidden, false},
+		{"quota", QuotaExceeded("rate limit exceeded", time.Second), CodeQuotaExceeded, http.StatusTooManyRequests, true},
+		{"dependency", DependencyUnavailable("embedder", stderrors.New("timeout")), CodeDependencyUnavailable, http.StatusServiceUnavailable, true},
+		{"read-only", New(CodeReadOnly, "writes disabled"), CodeReadOnly, http.StatusServiceUnavailable, false},
+		{"conflict", Conflict("changed concurrently"), CodeConflict, http.StatusConflict, true},
+		{"shutting down", New(CodeShuttingDown, "draining"), CodeShuttingDown, http.StatusServiceUnavailable, true},
+		{"validation", validation.NewError(validation.CodeTooManyTags, "tags", 20, 25), Code(validation.CodeTooManyTags), http.StatusBadRequest, false},
+		{"deadline", fmt.Errorf("search: %w", context.DeadlineExceeded), CodeDependencyUnavailable, http.StatusServiceUnavailable, true},
+		{"unclassified", stderrors.New("boom"), CodeInternal, http.StatusInternalServerError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, CodeOf(tt.err))
+			assert.Equal(t, tt.status, HTTPStatus(tt.err))
+			assert.Equal(t, tt.retryable, Retryable(tt.err))
+		})
+	}
+
+	assert.Nil(t, From(nil))
+	assert.Equal(t, Code(""), CodeOf(nil))
+	assert.False(t, Retryable(nil))
+	assert.True(t, stderrors.Is(fmt.Errorf("wrapped: %w", errMissing), errMissing))
+}
+
+func TestError_Error(t *testing.T) {
+	assert.Equal(t, "checkpoint not found", NotFound("checkpoint not found").Error())
+	assert.Equal(t, "qdrant unavailable: dial tcp: refused", DependencyUnavailable("qdrant", stderrors.New("dial tcp: refused")).Error())
+
+	cause := stderrors.New("disk full")
+	wrapped := Wrap(CodeInternal, cause)
+	assert.Equal(t, "disk full", wrapped.Error())
+	assert.ErrorIs(t, wrapped, cause)
+	assert.Nil(t, Wrap(CodeInternal, nil))
+}
+
+func TestPayloadFor(t *testing.T) {
+	assert.Nil(t, PayloadFor(nil))
+
+	p := PayloadFor(fmt.Errorf("branch_create: %w", QuotaExceeded("rate limit exceeded", 1500*time.Millisecond)))
+	require.NotNil(t, p)
+	assert.Equal(t, CodeQuotaExceeded, p.Code)
+	assert.Equal(t, "branch_create: rate limit exceeded", p.Message)
+	assert.True(t, p.Retryable)
+	assert.Equal(t, 2, p.RetryAfterSeconds)
+
+	p = PayloadFor(validation.NewError(validation.CodeQueryTooLong, "query", 2000, 2001))
+	assert.Equal(t, map[string]any{"field": "query", "limit": 2000, "actual": 2001}, p.Details)
+}
+
+func TestLookup(t *testing.T) {
+	assert.Equal(t, Spec{HTTPStatus: http.StatusInternalServerError}, Lookup("NO_SUCH_CODE"))
+	assert.Equal(t, Spec{HTTPStatus: http.StatusTooManyRequests, Retryable: true}, Lookup(CodeQuotaExceeded))
+}
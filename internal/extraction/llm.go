@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"golang.org/x/time/rate"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
 )
 
 // Default configuration values.
@@ -40,10 +42,11 @@ type anthropicSummarizer struct {
 	httpClient *http.Client
 	limiter    *rate.Limiter
 	maxRetries int
+	usage      analytics.UsageRecorder
 }
 
 // newAnthropicSummarizer creates a new Anthropic summarizer.
-func newAnthropicSummarizer(cfg Config) (Summarizer, error) {
+func newAnthropicSummarizer(cfg Config, usage analytics.UsageRecorder) (Summarizer, error) {
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("anthropic API key required")
 	}
@@ -72,6 +75,7 @@ func newAnthropicSummarizer(cfg Config) (Summarizer, error) {
 		},
 		limiter:    rate.NewLimiter(rate.Limit(defaultRateLimit), defaultBurst),
 		maxRetries: defaultMaxRetries,
+		usage:      usage,
 	}, nil
 }
 
@@ -197,8 +201,23 @@ func (a *anthropicSummarizer) Summarize(ctx context.Context, candidate DecisionC
 	return Decision{}, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-// doRequest performs the actual HTTP request to the Claude API.
-func (a *anthropicSummarizer) doRequest(ctx context.Context, req anthropicRequest, fallbackConfidence float64) (Decision, error) {
+// doRequest performs the actual HTTP request to the Claude API. Every
+// attempt is billed, so each one reports its usage.
+func (a *anthropicSummarizer) doRequest(ctx context.Context, req anthropicRequest, fallbackConfidence float64) (decision Decision, err error) {
+	var inputTokens, outputTokens int
+	if a.usage != nil {
+		defer func() {
+			a.usage.RecordUsage(ctx, analytics.LLMUsage{
+				Feature:      analytics.FeatureExtraction,
+				Provider:     analytics.ProviderAnthropic,
+				Model:        a.model,
+				InputTokens:  int64(inputTokens),
+				OutputTokens: int64(outputTokens),
+				Failed:       err != nil,
+			})
+		}()
+	}
+
 	jsonData, err := json.Marshal(req)
 	if err != nil {
 		return Decision{}, fmt.Errorf("failed to marshal request: %w", err)
@@ -247,6 +266,8 @@ func (a *anthropicSummarizer) doRequest(ctx context.Context, req anthropicReques
 		return Decision{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	inputTokens, outputTokens = claudeResp.Usage.InputTokens, claudeResp.Usage.OutputTokens
+
 	if len(claudeResp.Content) == 0 {
 		return Decision{}, fmt.Errorf("empty response from API")
 	}
@@ -267,10 +288,11 @@ type openAISummarizer struct {
 	httpClient *http.Client
 	limiter    *rate.Limiter
 	maxRetries int
+	usage      analytics.UsageRecorder
 }
 
 // newOpenAISummarizer creates a new OpenAI summarizer.
-func newOpenAISummarizer(cfg Config) (Summarizer, error) {
+func newOpenAISummarizer(cfg Config, usage analytics.UsageRecorder) (Summarizer, error) {
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("openai API key required")
 	}
@@ -299,6 +321,7 @@ func newOpenAISummarizer(cfg Config) (Summarizer, error) {
 		},
 		limiter:    rate.NewLimiter(rate.Limit(defaultRateLimit), defaultBurst),
 		maxRetries: defaultMaxRetries,
+		usage:      usage,
 	}, nil
 }
 
@@ -414,8 +437,23 @@ func (o *openAISummarizer) Summarize(ctx context.Context, candidate DecisionCand
 	return Decision{}, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-// doRequest performs the actual HTTP request to the OpenAI API.
-func (o *openAISummarizer) doRequest(ctx context.Context, req openAIRequest, fallbackConfidence float64) (Decision, error) {
+// doRequest performs the actual HTTP request to the OpenAI API. Every
+// attempt is billed, so each one reports its usage.
+func (o *openAISummarizer) doRequest(ctx context.Context, req openAIRequest, fallbackConfidence float64) (decision Decision, err error) {
+	var inputTokens, outputTokens int
+	if o.usage != nil {
+		defer func() {
+			o.usage.RecordUsage(ctx, analytics.LLMUsage{
+				Feature:      analytics.FeatureExtraction,
+				Provider:     analytics.ProviderOpenAI,
+				Model:        o.model,
+				InputTokens:  int64(inputTokens),
+				OutputTokens: int64(outputTokens),
+				Failed:       err != nil,
+			})
+		}()
+	}
+
 	jsonData, err := json.Marshal(req)
 	if err != nil {
 		return Decision{}, fmt.Errorf("failed to marshal request: %w", err)
@@ -463,6 +501,8 @@ func (o *openAISummarizer) doRequest(ctx context.Context, req openAIRequest, fal
 		return Decision{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	inputTokens, outputTokens = openAIResp.Usage.PromptTokens, openAIResp.Usage.CompletionTokens
+
 	if len(openAIResp.Choices) == 0 {
 		return Decision{}, fmt.Errorf("empty response from API")
 	}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
 )
 
 // TestNewAnthropicSummarizer tests the Anthropic summarizer creation.
@@ -54,7 +56,7 @@ func TestNewAnthropicSummarizer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			summarizer, err := newAnthropicSummarizer(tt.cfg)
+			summarizer, err := newAnthropicSummarizer(tt.cfg, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("newAnthropicSummarizer() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -106,7 +108,7 @@ func TestNewOpenAISummarizer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			summarizer, err := newOpenAISummarizer(tt.cfg)
+			summarizer, err := newOpenAISummarizer(tt.cfg, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("newOpenAISummarizer() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -244,7 +246,7 @@ func TestAnthropicSummarizer_Summarize(t *testing.T) {
 				Model:   "claude-3-5-sonnet-20241022",
 			}
 
-			summarizer, err := newAnthropicSummarizer(cfg)
+			summarizer, err := newAnthropicSummarizer(cfg, nil)
 			if err != nil {
 				t.Fatalf("Failed to create summarizer: %v", err)
 			}
@@ -360,7 +362,7 @@ func TestOpenAISummarizer_Summarize(t *testing.T) {
 				Model:   "gpt-4o-mini",
 			}
 
-			summarizer, err := newOpenAISummarizer(cfg)
+			summarizer, err := newOpenAISummarizer(cfg, nil)
 			if err != nil {
 				t.Fatalf("Failed to create summarizer: %v", err)
 			}
@@ -405,7 +407,7 @@ func TestAnthropicSummarizer_RateLimiting(t *testing.T) {
 		BaseURL: server.URL,
 	}
 
-	summarizer, err := newAnthropicSummarizer(cfg)
+	summarizer, err := newAnthropicSummarizer(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create summarizer: %v", err)
 	}
@@ -461,7 +463,7 @@ func TestAnthropicSummarizer_Retry(t *testing.T) {
 		BaseURL: server.URL,
 	}
 
-	summarizer, err := newAnthropicSummarizer(cfg)
+	summarizer, err := newAnthropicSummarizer(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create summarizer: %v", err)
 	}
@@ -499,7 +501,7 @@ func TestAnthropicSummarizer_ContextCancellation(t *testing.T) {
 		BaseURL: server.URL,
 	}
 
-	summarizer, err := newAnthropicSummarizer(cfg)
+	summarizer, err := newAnthropicSummarizer(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create summarizer: %v", err)
 	}
@@ -769,7 +771,7 @@ func TestAnthropicSummarizer_WithContext(t *testing.T) {
 		BaseURL: server.URL,
 	}
 
-	summarizer, err := newAnthropicSummarizer(cfg)
+	summarizer, err := newAnthropicSummarizer(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create summarizer: %v", err)
 	}
@@ -838,7 +840,7 @@ func TestOpenAISummarizer_Retry(t *testing.T) {
 		BaseURL: server.URL,
 	}
 
-	summarizer, err := newOpenAISummarizer(cfg)
+	summarizer, err := newOpenAISummarizer(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create summarizer: %v", err)
 	}
@@ -863,6 +865,57 @@ func TestOpenAISummarizer_Retry(t *testing.T) {
 	}
 }
 
+// usageRecorderFunc adapts a function to analytics.UsageRecorder.
+type usageRecorderFunc func(ctx context.Context, u analytics.LLMUsage)
+
+func (f usageRecorderFunc) RecordUsage(ctx context.Context, u analytics.LLMUsage) { f(ctx, u) }
+
+// TestOpenAISummarizer_RecordsUsage tests that every attempt reports its usage.
+func TestOpenAISummarizer_RecordsUsage(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if requestCount == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"choices": [{"message": {"role": "assistant", "content": "{\"summary\": \"Use Postgres\", \"confidence\": 0.9}"}}],
+			"usage": {"prompt_tokens": 210, "completion_tokens": 40, "total_tokens": 250}
+		}`))
+	}))
+	defer server.Close()
+
+	var got []analytics.LLMUsage
+	recorder := usageRecorderFunc(func(ctx context.Context, u analytics.LLMUsage) { got = append(got, u) })
+	summarizer, err := newOpenAISummarizer(Config{APIKey: "sk-test123", BaseURL: server.URL}, recorder)
+	if err != nil {
+		t.Fatalf("Failed to create summarizer: %v", err)
+	}
+
+	if _, err := summarizer.Summarize(context.Background(), DecisionCandidate{Content: "Test", Confidence: 0.9}); err != nil {
+		t.Fatalf("Summarize() failed: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("recorded %d usages, want 2", len(got))
+	}
+	if !got[0].Failed {
+		t.Errorf("rate-limited attempt should be marked failed: %+v", got[0])
+	}
+	want := analytics.LLMUsage{
+		Feature:      analytics.FeatureExtraction,
+		Provider:     analytics.ProviderOpenAI,
+		Model:        defaultOpenAIModel,
+		InputTokens:  210,
+		OutputTokens: 40,
+	}
+	if got[1] != want {
+		t.Errorf("usage = %+v, want %+v", got[1], want)
+	}
+}
+
 // TestScrubSecretsWithContext tests that secrets in context are also scrubbed.
 func TestScrubSecretsWithContext(t *testing.T) {
 	var receivedBody map[string]interface{}
@@ -888,7 +941,7 @@ func TestScrubSecretsWithContext(t *testing.T) {
 		BaseURL: server.URL,
 	}
 
-	summarizer, err := newAnthropicSummarizer(cfg)
+	summarizer, err := newAnthropicSummarizer(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create summarizer: %v", err)
 	}
@@ -28,9 +28,9 @@ func NewSummarizer(cfg ExtractionConfig) (Summarizer, error) {
 
 	switch cfg.Provider {
 	case "anthropic":
-		return newAnthropicSummarizer(providerCfg)
+		return newAnthropicSummarizer(providerCfg, cfg.Usage)
 	case "openai":
-		return newOpenAISummarizer(providerCfg)
+		return newOpenAISummarizer(providerCfg, cfg.Usage)
 	default:
 		return nil, fmt.Errorf("unknown provider: %s", cfg.Provider)
 	}
@@ -5,6 +5,8 @@ package extraction
 
 import (
 	"context"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
 )
 
 // Pattern represents a decision detection pattern.
@@ -78,6 +80,9 @@ type ExtractionConfig struct {
 	ConfidenceThreshold   float64   `json:"confidence_threshold"`
 	LLMRefineThreshold    float64   `json:"llm_refine_threshold"`
 	ContextWindowMessages int       `json:"context_window_messages"`
+
+	// Usage receives the token counts of LLM summarizer requests. Optional.
+	Usage analytics.UsageRecorder `json:"-"`
 }
 
 // Config holds provider-specific configuration.
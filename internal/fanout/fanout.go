@@ -0,0 +1,58 @@
+// Package fanout runs independent lookups concurrently for calls that
+// assemble one response from several sources, such as session bootstrap and
+// federated knowledge search. A failing source does not fail the call; its
+// error is logged and reported back as a warning.
+package fanout
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Group runs named tasks concurrently and collects their failures.
+type Group struct {
+	logger  *zap.Logger
+	msg     string
+	nameKey string
+	fields  []zap.Field
+
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	warnings []string
+}
+
+// New creates a Group that logs failed tasks as msg, with the task name
+// under nameKey alongside fields.
+func New(logger *zap.Logger, msg, nameKey string, fields ...zap.Field) *Group {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Group{logger: logger, msg: msg, nameKey: nameKey, fields: fields}
+}
+
+// Go runs fn in its own goroutine. If fn fails, the failure is logged and
+// recorded as a "name: error" warning.
+func (g *Group) Go(name string, fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := fn(); err != nil {
+			fields := append([]zap.Field{zap.String(g.nameKey, name)}, g.fields...)
+			g.logger.Warn(g.msg, append(fields, zap.Error(err))...)
+			g.mu.Lock()
+			g.warnings = append(g.warnings, fmt.Sprintf("%s: %v", name, err))
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for every task and returns the warnings, sorted since tasks
+// finish in any order.
+func (g *Group) Wait() []string {
+	g.wg.Wait()
+	sort.Strings(g.warnings)
+	return g.warnings
+}
@@ -0,0 +1,27 @@
+package fanout
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	var ran atomic.Int32
+	g := New(nil, "section failed", "section")
+	g.Go("memories", func() error { ran.Add(1); return errors.New("store down") })
+	g.Go("checkpoint", func() error { ran.Add(1); return nil })
+	g.Go("insights", func() error { ran.Add(1); return errors.New("timeout") })
+
+	warnings := g.Wait()
+	assert.Equal(t, int32(3), ran.Load())
+	assert.Equal(t, []string{"insights: timeout", "memories: store down"}, warnings)
+}
+
+func TestGroup_NoFailures(t *testing.T) {
+	g := New(nil, "section failed", "section")
+	g.Go("memories", func() error { return nil })
+	assert.Empty(t, g.Wait())
+}
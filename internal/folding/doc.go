@@ -60,6 +60,16 @@
 //	}
 //	fmt.Printf("Used %d tokens, result: %s\n", returnResp.TokensUsed, returnResp.ScrubbedMsg)
 //
+// # Return Pipeline
+//
+// Return messages are scrubbed, then fitted to a token target so a large
+// result cannot overrun the context the branch was meant to protect. The
+// target is ReturnRequest.TargetTokens, else FoldingConfig.ReturnTargetTokens,
+// capped by the parent branch's remaining budget. Messages over target are
+// compressed by the ResultCompressor set with WithResultCompressor, and
+// truncated if still too long. ReturnResponse reports the token counts,
+// whether the message was compressed or truncated, and the quality score.
+//
 // # Use Cases
 //
 // Context-folding is ideal for:
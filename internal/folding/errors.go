@@ -3,6 +3,8 @@ package folding
 import (
 	"errors"
 	"fmt"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 // Error codes for structured error handling.
@@ -85,6 +87,23 @@ func (e *FoldingError) Unwrap() error {
 	return e.Cause
 }
 
+// Classify maps the error onto the contextd error registry using the
+// categories below, keeping the folding code as the reason.
+func (e *FoldingError) Classify() *ctxerrors.Error {
+	code := ctxerrors.CodeInternal
+	switch {
+	case IsAuthorizationError(e):
+		code = ctxerrors.CodeUnauthorized
+	case IsRetryable(e):
+		code = ctxerrors.CodeQuotaExceeded
+	case IsNotFoundError(e):
+		code = ctxerrors.CodeNotFound
+	case IsUserError(e):
+		code = ctxerrors.CodeInvalidInput
+	}
+	return &ctxerrors.Error{Code: code, Details: map[string]any{"reason": e.Code}}
+}
+
 // NewFoldingError creates a new FoldingError with the given parameters.
 func NewFoldingError(code, message string, cause error, branchID, sessionID string) *FoldingError {
 	return &FoldingError{
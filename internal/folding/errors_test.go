@@ -2,7 +2,11 @@ package folding
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 func TestErrorCodes(t *testing.T) {
@@ -404,6 +408,40 @@ func TestWrapError(t *testing.T) {
 	}
 }
 
+func TestFoldingError_Classify(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      ctxerrors.Code
+		status    int
+		retryable bool
+	}{
+		{"rate limit", NewFoldingError(ErrCodeRateLimitExceeded, "rate limit exceeded", nil, "", "sess-1"), ctxerrors.CodeQuotaExceeded, http.StatusTooManyRequests, true},
+		{"not found", NewFoldingError(ErrCodeBranchNotFound, "branch not found", nil, "b-1", ""), ctxerrors.CodeNotFound, http.StatusNotFound, false},
+		{"user error", fmt.Errorf("branch_create: %w", NewFoldingError(ErrCodeEmptyPrompt, "prompt is required", nil, "", "")), ctxerrors.CodeInvalidInput, http.StatusBadRequest, false},
+		{"unauthorized", NewFoldingError(ErrCodeSessionUnauthorized, "session access unauthorized", nil, "", "sess-1"), ctxerrors.CodeUnauthorized, http.StatusForbidden, false},
+		{"system error", NewFoldingError(ErrCodeScrubbingFailed, "scrubbing failed", nil, "", ""), ctxerrors.CodeInternal, http.StatusInternalServerError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ctxerrors.CodeOf(tt.err); got != tt.code {
+				t.Errorf("CodeOf() = %v, want %v", got, tt.code)
+			}
+			if got := ctxerrors.HTTPStatus(tt.err); got != tt.status {
+				t.Errorf("HTTPStatus() = %v, want %v", got, tt.status)
+			}
+			if got := ctxerrors.Retryable(tt.err); got != tt.retryable {
+				t.Errorf("Retryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+
+	p := ctxerrors.PayloadFor(NewFoldingError(ErrCodeBudgetExhausted, "budget exhausted", nil, "b-1", ""))
+	if reason := p.Details["reason"]; reason != ErrCodeBudgetExhausted {
+		t.Errorf("PayloadFor().Details[reason] = %v, want %v", reason, ErrCodeBudgetExhausted)
+	}
+}
+
 func TestIsAuthorizationError(t *testing.T) {
 	tests := []struct {
 		name     string
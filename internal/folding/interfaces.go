@@ -28,6 +28,13 @@ type TokenCounter interface {
 	Count(content string) (int, error)
 }
 
+// ResultCompressor shortens branch results that exceed their token target.
+type ResultCompressor interface {
+	// Compress reduces content toward targetTokens, returning the compressed
+	// content and a quality score between 0.0 and 1.0.
+	Compress(ctx context.Context, content string, targetTokens int) (string, float64, error)
+}
+
 // BranchEvent represents an event in the branch lifecycle.
 type BranchEvent interface {
 	// Type returns the event type identifier.
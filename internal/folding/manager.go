@@ -2,6 +2,7 @@ package folding
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
@@ -25,6 +26,7 @@ type FoldingConfig struct {
 	MemoryMaxItems           int     `json:"memory_max_items" koanf:"memory_max_items"`
 	MaxConcurrentPerSession  int     `json:"max_concurrent_per_session" koanf:"max_concurrent_per_session"`
 	MaxConcurrentPerInstance int     `json:"max_concurrent_per_instance" koanf:"max_concurrent_per_instance"`
+	ReturnTargetTokens       int     `json:"return_target_tokens" koanf:"return_target_tokens"` // Default token target for return messages (0 = parent budget only)
 }
 
 // DefaultFoldingConfig returns sensible defaults.
@@ -53,6 +55,10 @@ type BranchManager struct {
 	metrics  *Metrics
 	logger   *Logger
 
+	// Return pipeline (optional)
+	compressor   ResultCompressor
+	tokenCounter TokenCounter
+
 	// Session validation (SEC-004)
 	sessionValidator SessionValidator
 
@@ -94,6 +100,22 @@ func WithSessionValidator(v SessionValidator) BranchManagerOption {
 	}
 }
 
+// WithResultCompressor sets the compressor used to fit return messages to
+// their token target. Without one, oversized messages are truncated.
+func WithResultCompressor(c ResultCompressor) BranchManagerOption {
+	return func(bm *BranchManager) {
+		bm.compressor = c
+	}
+}
+
+// WithTokenCounter sets the token counter for return messages.
+// If not set, tokens are estimated from message length.
+func WithTokenCounter(c TokenCounter) BranchManagerOption {
+	return func(bm *BranchManager) {
+		bm.tokenCounter = c
+	}
+}
+
 // NewBranchManager creates a new branch manager.
 func NewBranchManager(
 	repo BranchRepository,
@@ -373,6 +395,9 @@ func (m *BranchManager) Return(ctx context.Context, req ReturnRequest) (*ReturnR
 		return nil, ErrScrubbingFailed
 	}
 
+	// Fit the result to its token target so it cannot overrun the parent budget
+	result := m.fitResult(ctx, branch, scrubbedMsg, m.returnTarget(branch, req.TargetTokens))
+
 	// Cancel timeout
 	m.cancelTimeout(branch.ID)
 
@@ -386,7 +411,7 @@ func (m *BranchManager) Return(ctx context.Context, req ReturnRequest) (*ReturnR
 		SetSpanStatus(ctx, codes.Error, "invalid state transition")
 		return nil, err
 	}
-	branch.Result = &scrubbedMsg
+	branch.Result = &result.message
 	branch.CompletedAt = &now
 	branch.BudgetUsed = tokensUsed
 
@@ -418,9 +443,15 @@ func (m *BranchManager) Return(ctx context.Context, req ReturnRequest) (*ReturnR
 
 	SetSpanStatus(ctx, codes.Ok, "branch returned successfully")
 	return &ReturnResponse{
-		Success:     true,
-		TokensUsed:  tokensUsed,
-		ScrubbedMsg: scrubbedMsg,
+		Success:        true,
+		TokensUsed:     tokensUsed,
+		ScrubbedMsg:    result.message,
+		OriginalTokens: result.originalTokens,
+		ResultTokens:   result.tokens,
+		TargetTokens:   result.target,
+		Compressed:     result.compressed,
+		Truncated:      result.truncated,
+		QualityScore:   result.quality,
 	}, nil
 }
 
@@ -521,6 +552,35 @@ func (m *BranchManager) ListBySession(ctx context.Context, sessionID string) ([]
 	return m.repo.ListBySession(ctx, sessionID)
 }
 
+// ListActive returns the unfinished branches of all sessions, oldest first.
+func (m *BranchManager) ListActive(ctx context.Context) ([]*Branch, error) {
+	// Every unfinished branch has a timeout watcher
+	m.timeoutMu.Lock()
+	ids := make([]string, 0, len(m.timeoutCancels))
+	for id := range m.timeoutCancels {
+		ids = append(ids, id)
+	}
+	m.timeoutMu.Unlock()
+
+	branches := make([]*Branch, 0, len(ids))
+	for _, id := range ids {
+		branch, err := m.repo.Get(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrBranchNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if !branch.Status.IsTerminal() {
+			branches = append(branches, branch)
+		}
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].CreatedAt.Before(branches[j].CreatedAt)
+	})
+	return branches, nil
+}
+
 // CleanupSession force-returns all active branches for a session (FR-010).
 func (m *BranchManager) CleanupSession(ctx context.Context, sessionID string) error {
 	// Start tracing span
@@ -622,8 +682,12 @@ func (m *BranchManager) Health() HealthStatus {
 	}
 }
 
+// ReasonShutdown is the error recorded on branches force-returned because
+// the server is shutting down.
+const ReasonShutdown = "server shutdown"
+
 // Shutdown gracefully shuts down the manager, canceling all timeout watchers
-// and force-returning all active branches.
+// and force-returning all active branches with ReasonShutdown.
 func (m *BranchManager) Shutdown(ctx context.Context) error {
 	m.shutdownMu.Lock()
 	if m.isShutdown {
@@ -636,6 +700,18 @@ func (m *BranchManager) Shutdown(ctx context.Context) error {
 
 	m.logger.Debug(ctx, "starting graceful shutdown")
 
+	// Force-return unfinished branches so their sessions see why they
+	// ended; ListActive relies on the timeout watchers, so run it first
+	active, err := m.ListActive(ctx)
+	if err != nil {
+		m.logger.Error(ctx, "failed to list active branches during shutdown", err)
+	}
+	for _, branch := range active {
+		if err := m.ForceReturn(ctx, branch.ID, ReasonShutdown); err != nil {
+			m.logger.Error(ctx, "failed to force-return branch during shutdown", err)
+		}
+	}
+
 	// Cancel all timeout watchers
 	m.timeoutMu.Lock()
 	for branchID, cancel := range m.timeoutCancels {
@@ -2,6 +2,7 @@ package folding
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -229,6 +230,127 @@ func TestBranchManager_ReturnScrubberFailure(t *testing.T) {
 	}
 }
 
+// MockCompressor is a test implementation of ResultCompressor.
+type MockCompressor struct {
+	CompressFunc func(content string, targetTokens int) (string, float64, error)
+}
+
+func (m *MockCompressor) Compress(ctx context.Context, content string, targetTokens int) (string, float64, error) {
+	return m.CompressFunc(content, targetTokens)
+}
+
+func TestBranchManager_ReturnPipeline(t *testing.T) {
+	longMsg := strings.Repeat("finding ", 200) // 1600 chars, 400 tokens
+	ctx := context.Background()
+
+	newManager := func(config *FoldingConfig, opts ...BranchManagerOption) *BranchManager {
+		emitter := NewSimpleEventEmitter()
+		return NewBranchManager(NewMemoryBranchRepository(), NewBudgetTracker(emitter), &MockScrubber{}, emitter, config, opts...)
+	}
+
+	t.Run("under target is unchanged", func(t *testing.T) {
+		manager := newManager(nil)
+		created, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_001", Description: "test", Prompt: "test"})
+
+		resp, err := manager.Return(ctx, ReturnRequest{BranchID: created.BranchID, Message: "found it", TargetTokens: 100})
+		if err != nil {
+			t.Fatalf("Return() error = %v", err)
+		}
+		if resp.ScrubbedMsg != "found it" || resp.Compressed || resp.Truncated {
+			t.Errorf("Return() = %+v, want message unchanged", resp)
+		}
+		if resp.OriginalTokens != 2 || resp.ResultTokens != 2 {
+			t.Errorf("tokens = %d -> %d, want 2 -> 2", resp.OriginalTokens, resp.ResultTokens)
+		}
+	})
+
+	t.Run("compresses toward caller target", func(t *testing.T) {
+		var gotTarget int
+		manager := newManager(nil, WithResultCompressor(&MockCompressor{
+			CompressFunc: func(content string, targetTokens int) (string, float64, error) {
+				gotTarget = targetTokens
+				return "finding summary", 0.8, nil
+			},
+		}))
+		created, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_001", Description: "test", Prompt: "test"})
+
+		resp, err := manager.Return(ctx, ReturnRequest{BranchID: created.BranchID, Message: longMsg, TargetTokens: 50})
+		if err != nil {
+			t.Fatalf("Return() error = %v", err)
+		}
+		if gotTarget != 50 {
+			t.Errorf("compressor target = %d, want 50", gotTarget)
+		}
+		if !resp.Compressed || resp.Truncated || resp.QualityScore != 0.8 {
+			t.Errorf("Return() = %+v, want compressed with quality 0.8", resp)
+		}
+		if resp.ScrubbedMsg != "finding summary" || resp.OriginalTokens != 400 || resp.TargetTokens != 50 {
+			t.Errorf("Return() = %+v, want compressed summary of 400 tokens", resp)
+		}
+
+		branch, _ := manager.Get(ctx, created.BranchID)
+		if branch.Result == nil || *branch.Result != "finding summary" {
+			t.Errorf("branch.Result = %v, want compressed message", branch.Result)
+		}
+	})
+
+	t.Run("truncates when compression fails", func(t *testing.T) {
+		manager := newManager(&FoldingConfig{
+			DefaultBudget: 8192, MaxBudget: 32768, MaxDepth: 3, MaxTimeoutSeconds: 600,
+			MaxConcurrentPerSession: 10, MaxConcurrentPerInstance: 100, ReturnTargetTokens: 40,
+		}, WithResultCompressor(&MockCompressor{
+			CompressFunc: func(content string, targetTokens int) (string, float64, error) {
+				return "", 0, errors.New("model unavailable")
+			},
+		}))
+		created, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_001", Description: "test", Prompt: "test"})
+
+		resp, err := manager.Return(ctx, ReturnRequest{BranchID: created.BranchID, Message: longMsg})
+		if err != nil {
+			t.Fatalf("Return() error = %v", err)
+		}
+		if resp.Compressed || !resp.Truncated {
+			t.Errorf("Return() = %+v, want truncated without compression", resp)
+		}
+		if resp.ResultTokens > 40 || resp.TargetTokens != 40 {
+			t.Errorf("ResultTokens = %d of target %d, want at most 40", resp.ResultTokens, resp.TargetTokens)
+		}
+		if !strings.HasSuffix(resp.ScrubbedMsg, truncationMarker) || !strings.HasPrefix(resp.ScrubbedMsg, "finding finding") {
+			t.Errorf("ScrubbedMsg = %q, want truncated prefix with marker", resp.ScrubbedMsg)
+		}
+	})
+
+	t.Run("capped by parent budget", func(t *testing.T) {
+		manager := newManager(nil)
+		parent, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_001", Description: "parent", Prompt: "test", Budget: 1000})
+		child, err := manager.Create(ctx, BranchRequest{SessionID: "sess_001", Description: "child", Prompt: "test"})
+		if err != nil {
+			t.Fatalf("Create() child error = %v", err)
+		}
+		if err := manager.ConsumeTokens(ctx, parent.BranchID, 970); err != nil {
+			t.Fatalf("ConsumeTokens() error = %v", err)
+		}
+
+		resp, err := manager.Return(ctx, ReturnRequest{BranchID: child.BranchID, Message: longMsg, TargetTokens: 200})
+		if err != nil {
+			t.Fatalf("Return() error = %v", err)
+		}
+		if resp.TargetTokens != 30 || resp.ResultTokens > 30 || !resp.Truncated {
+			t.Errorf("Return() = %+v, want truncated to the parent's 30 remaining tokens", resp)
+		}
+	})
+
+	t.Run("negative target", func(t *testing.T) {
+		manager := newManager(nil)
+		created, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_001", Description: "test", Prompt: "test"})
+
+		_, err := manager.Return(ctx, ReturnRequest{BranchID: created.BranchID, Message: "x", TargetTokens: -1})
+		if !errors.Is(err, ErrInvalidBudget) {
+			t.Errorf("Return() error = %v, want ErrInvalidBudget", err)
+		}
+	})
+}
+
 func TestBranchManager_ReturnNilScrubber(t *testing.T) {
 	// CORR-004: Verify fail-closed behavior when scrubber is nil
 	repo := NewMemoryBranchRepository()
@@ -489,6 +611,30 @@ func TestBranchManager_GetActive(t *testing.T) {
 	}
 }
 
+func TestBranchManager_ListActive(t *testing.T) {
+	manager, _, _ := newTestManager()
+	ctx := context.Background()
+
+	first, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_001", Description: "first", Prompt: "test"})
+	second, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_002", Description: "second", Prompt: "test"})
+	done, _ := manager.Create(ctx, BranchRequest{SessionID: "sess_003", Description: "done", Prompt: "test"})
+	if _, err := manager.Return(ctx, ReturnRequest{BranchID: done.BranchID, Message: "finished"}); err != nil {
+		t.Fatalf("Return() error = %v", err)
+	}
+
+	active, err := manager.ListActive(ctx)
+	if err != nil {
+		t.Fatalf("ListActive() error = %v", err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("ListActive() returned %d branches, want 2", len(active))
+	}
+	if active[0].ID != first.BranchID || active[1].ID != second.BranchID {
+		t.Errorf("ListActive() = [%s %s], want [%s %s] oldest first",
+			active[0].ID, active[1].ID, first.BranchID, second.BranchID)
+	}
+}
+
 func TestBranchManager_Health(t *testing.T) {
 	manager, _, _ := newTestManager()
 	ctx := context.Background()
@@ -520,19 +666,34 @@ func TestBranchManager_Shutdown(t *testing.T) {
 	ctx := context.Background()
 
 	// Create a branch with a timeout
-	_, _ = manager.Create(ctx, BranchRequest{
+	resp, err := manager.Create(ctx, BranchRequest{
 		SessionID:      "sess_001",
 		Description:    "test",
 		Prompt:         "test",
 		TimeoutSeconds: 300,
 	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
 
 	// Shutdown should succeed
-	err := manager.Shutdown(ctx)
+	err = manager.Shutdown(ctx)
 	if err != nil {
 		t.Fatalf("Shutdown() error = %v", err)
 	}
 
+	// The active branch is force-returned with the shutdown reason
+	branch, err := manager.Get(ctx, resp.BranchID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if branch.Status != BranchStatusFailed {
+		t.Errorf("branch status = %s, want %s", branch.Status, BranchStatusFailed)
+	}
+	if branch.Error == nil || *branch.Error != ReasonShutdown {
+		t.Errorf("branch error = %v, want %q", branch.Error, ReasonShutdown)
+	}
+
 	// IsShutdown should return true
 	if !manager.IsShutdown() {
 		t.Error("IsShutdown() = false, want true")
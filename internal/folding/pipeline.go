@@ -0,0 +1,118 @@
+package folding
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// charsPerToken estimates token counts when no TokenCounter is configured.
+const charsPerToken = 4
+
+// truncationMarker ends return messages that were cut to fit their target.
+const truncationMarker = "\n[truncated]"
+
+// returnResult is a scrubbed return message after fitting it to its target.
+type returnResult struct {
+	message        string
+	originalTokens int
+	tokens         int
+	target         int
+	compressed     bool
+	truncated      bool
+	quality        float64
+}
+
+// returnTarget returns the token target for a branch's return message: the
+// caller's target or the configured default, capped by what remains of the
+// parent's budget. Zero means the message is returned as is.
+func (m *BranchManager) returnTarget(branch *Branch, requested int) int {
+	target := requested
+	if target == 0 {
+		target = m.config.ReturnTargetTokens
+	}
+	if branch.ParentID == nil {
+		return target
+	}
+	remaining, err := m.budget.Remaining(*branch.ParentID)
+	if err != nil {
+		return target
+	}
+	// An exhausted parent still gets a target, so the message is dropped
+	// rather than returned in full
+	remaining = max(remaining, 1)
+	if target == 0 || remaining < target {
+		return remaining
+	}
+	return target
+}
+
+// fitResult runs the return pipeline after scrubbing: messages over target
+// are compressed when a ResultCompressor is configured, then truncated if
+// still too long, so a result never exceeds its target.
+func (m *BranchManager) fitResult(ctx context.Context, branch *Branch, message string, target int) returnResult {
+	r := returnResult{message: message, target: target}
+	r.originalTokens = m.countTokens(message)
+	r.tokens = r.originalTokens
+	if target <= 0 || r.tokens <= target {
+		return r
+	}
+
+	if m.compressor != nil {
+		compressed, quality, err := m.compressor.Compress(ctx, message, target)
+		if err != nil {
+			// Compression is best effort; truncation below still bounds the result
+			m.logger.Warn(ctx, "return message compression failed",
+				zap.String("branch_id", branch.ID),
+				zap.Error(err),
+			)
+		} else if tokens := m.countTokens(compressed); tokens < r.tokens {
+			r.message, r.tokens = compressed, tokens
+			r.compressed, r.quality = true, quality
+		}
+	}
+
+	if r.tokens > target {
+		r.message = m.truncate(r.message, target)
+		r.tokens = m.countTokens(r.message)
+		r.truncated = true
+	}
+	return r
+}
+
+// truncate cuts content so that it and the truncation marker fit in target
+// tokens. Content is empty if not even the marker fits.
+func (m *BranchManager) truncate(content string, target int) string {
+	runes := []rune(content)
+	keep := len(runes)
+	for {
+		tokens := m.countTokens(string(runes[:keep]) + truncationMarker)
+		if tokens <= target {
+			break
+		}
+		if keep == 0 {
+			return ""
+		}
+		// Shrink in proportion to the overrun; token counts are not linear
+		// in length, so repeat until it fits
+		next := keep * target / tokens
+		if next >= keep {
+			next = keep - 1
+		}
+		keep = next
+	}
+	return strings.TrimRightFunc(string(runes[:keep]), unicode.IsSpace) + truncationMarker
+}
+
+// countTokens counts tokens with the configured TokenCounter, falling back
+// to a length estimate.
+func (m *BranchManager) countTokens(content string) int {
+	if m.tokenCounter != nil {
+		if n, err := m.tokenCounter.Count(content); err == nil {
+			return n
+		}
+	}
+	return (len(content) + charsPerToken - 1) / charsPerToken
+}
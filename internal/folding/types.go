@@ -146,6 +146,7 @@ type ReturnRequest struct {
 	CallerID      string `json:"caller_id,omitempty"` // SEC-004: Caller identity for authorization
 	Message       string `json:"message"`
 	ExtractMemory bool   `json:"extract_memory,omitempty"`
+	TargetTokens  int    `json:"target_tokens,omitempty"` // Token target for the returned message (0 = configured default)
 }
 
 // Validate checks the return request.
@@ -156,6 +157,9 @@ func (r *ReturnRequest) Validate() error {
 	if len(r.Message) > MaxReturnMsgLength {
 		return ErrMessageTooLong
 	}
+	if r.TargetTokens < 0 {
+		return ErrInvalidBudget
+	}
 	return nil
 }
 
@@ -164,5 +168,13 @@ type ReturnResponse struct {
 	Success      bool   `json:"success"`
 	TokensUsed   int    `json:"tokens_used"`
 	MemoryQueued bool   `json:"memory_queued"`
-	ScrubbedMsg  string `json:"scrubbed_message"` // Message after secret scrubbing
+	ScrubbedMsg  string `json:"scrubbed_message"` // Message after secret scrubbing and fitting to the target
+
+	// Return pipeline results
+	OriginalTokens int     `json:"original_tokens"`         // Tokens in the scrubbed message before fitting
+	ResultTokens   int     `json:"result_tokens"`           // Tokens in ScrubbedMsg
+	TargetTokens   int     `json:"target_tokens,omitempty"` // Effective token target (0 = none)
+	Compressed     bool    `json:"compressed,omitempty"`    // ResultCompressor shortened the message
+	Truncated      bool    `json:"truncated,omitempty"`     // Message was cut to fit the target
+	QualityScore   float64 `json:"quality_score,omitempty"` // Compression quality (0.0-1.0)
 }
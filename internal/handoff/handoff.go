@@ -0,0 +1,615 @@
+// Package handoff packages the state of a piece of work so another user or
+// agent can pick it up: a checkpoint, the memories used while working on it,
+// the remediation drafts still awaiting confirmation, and a generated "state
+// of the work" summary.
+//
+// Export produces a Bundle, a self-contained JSON artifact addressed to a
+// recipient. Import reconstructs its context under the recipient's tenant
+// and project: the checkpoint is saved and can be resumed, memories are
+// recorded with fresh confidence, and remediations are recorded as pending
+// drafts. Imported content passes through the same secret scrubbing and
+// injection scanning as any other write.
+//
+// It backs the handoff_export and handoff_import MCP tools and the HTTP
+// endpoints POST /api/v1/handoff/export and POST /api/v1/handoff/import.
+package handoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// FormatVersion is the bundle format written by Export. Import rejects
+	// bundles with a newer version.
+	FormatVersion = 1
+
+	// DefaultMemoryLimit is the number of memories exported by default.
+	DefaultMemoryLimit = 10
+
+	// DefaultRemediationLimit is the number of open remediations exported by default.
+	DefaultRemediationLimit = 5
+
+	// sessionLookback bounds the analytics journal days searched for the
+	// memories a session used.
+	sessionLookback = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrMissingFields is returned when an export request lacks required fields.
+	ErrMissingFields = ctxerrors.New(ctxerrors.CodeInvalidInput, "checkpoint_id, tenant_id, project_id and to are required")
+
+	// ErrInvalidBundle is returned when importing a bundle that is empty,
+	// lacks its checkpoint or was written by a newer contextd.
+	ErrInvalidBundle = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid handoff bundle")
+
+	// ErrWrongRecipient is returned when importing a bundle addressed to
+	// someone else.
+	ErrWrongRecipient = ctxerrors.New(ctxerrors.CodeInvalidInput, "handoff bundle is addressed to another recipient")
+)
+
+// memoryTools are the tools whose results count as memories used by a
+// session.
+var memoryTools = map[string]bool{
+	"memory_search":     true,
+	"session_bootstrap": true,
+	"knowledge_search":  true,
+}
+
+// ExportRequest selects the work to hand off.
+type ExportRequest struct {
+	// CheckpointID is the checkpoint the recipient resumes from.
+	CheckpointID string
+
+	// TenantID, TeamID, ProjectID and ProjectPath scope the checkpoint and
+	// remediations. Memories are scoped by ProjectID.
+	TenantID    string
+	TeamID      string
+	ProjectID   string
+	ProjectPath string
+
+	// To is the user or agent the bundle is addressed to; From is the sender.
+	To   string
+	From string
+
+	// Note is a free-form message to the recipient.
+	Note string
+
+	// MemoryIDs are memories to include in addition to those the
+	// checkpoint's session retrieved.
+	MemoryIDs []string
+
+	MemoryLimit      int
+	RemediationLimit int
+}
+
+// Bundle is a shareable handoff artifact. Its JSON form is what is passed
+// between users; fields are only ever added.
+type Bundle struct {
+	Version        int           `json:"version"`
+	ID             string        `json:"id"`
+	From           string        `json:"from,omitempty"`
+	To             string        `json:"to"`
+	Note           string        `json:"note,omitempty"`
+	SourceTenantID string        `json:"source_tenant_id"`
+	ProjectID      string        `json:"project_id"`
+	Summary        string        `json:"summary"`
+	Checkpoint     *Checkpoint   `json:"checkpoint"`
+	Memories       []Memory      `json:"memories"`
+	Remediations   []Remediation `json:"remediations"`
+	Warnings       []string      `json:"warnings,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// Checkpoint is the checkpoint the recipient resumes from.
+type Checkpoint struct {
+	ID          string    `json:"id"`
+	SessionID   string    `json:"session_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Summary     string    `json:"summary"`
+	Context     string    `json:"context,omitempty"`
+	FullState   string    `json:"full_state,omitempty"`
+	TokenCount  int32     `json:"token_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Memory is a memory used while working on the handed-off task.
+type Memory struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Outcome    string   `json:"outcome"`
+	Confidence float64  `json:"confidence"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// Remediation is a remediation draft still awaiting confirmation.
+type Remediation struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Problem    string   `json:"problem"`
+	Symptoms   []string `json:"symptoms,omitempty"`
+	RootCause  string   `json:"root_cause,omitempty"`
+	Solution   string   `json:"solution"`
+	Category   string   `json:"category"`
+	Confidence float64  `json:"confidence"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// ImportRequest places a bundle under the recipient's scope.
+type ImportRequest struct {
+	Bundle *Bundle
+
+	// TenantID, TeamID, ProjectID and ProjectPath are the recipient's scope.
+	TenantID    string
+	TeamID      string
+	ProjectID   string
+	ProjectPath string
+
+	// SessionID is the recipient's session. Its next checkpoint continues
+	// from the imported one.
+	SessionID string
+
+	// Recipient, when set, must match the bundle's To.
+	Recipient string
+}
+
+// ImportResult lists what an import created.
+type ImportResult struct {
+	HandoffID      string   `json:"handoff_id"`
+	CheckpointID   string   `json:"checkpoint_id"`
+	MemoryIDs      []string `json:"memory_ids"`
+	RemediationIDs []string `json:"remediation_ids"`
+	Warnings       []string `json:"warnings,omitempty"`
+}
+
+// Service exports and imports handoff bundles. The memory and remediation
+// services and the tracker may be nil, in which case their sections are
+// skipped with a warning.
+type Service struct {
+	memory       *reasoningbank.Service
+	checkpoints  checkpoint.Service
+	remediations remediation.Service
+	tracker      *analytics.Tracker
+	scrubber     secrets.Scrubber
+	logger       *zap.Logger
+}
+
+// NewService creates a Service.
+func NewService(memory *reasoningbank.Service, checkpoints checkpoint.Service, remediations remediation.Service, tracker *analytics.Tracker, scrubber secrets.Scrubber, logger *zap.Logger) *Service {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Service{
+		memory:       memory,
+		checkpoints:  checkpoints,
+		remediations: remediations,
+		tracker:      tracker,
+		scrubber:     scrubber,
+		logger:       logger,
+	}
+}
+
+// Export packages a checkpoint and the knowledge around it into a bundle.
+// A missing or quarantined checkpoint fails the export; other sections that
+// fail are reported in the bundle's warnings.
+func (s *Service) Export(ctx context.Context, req ExportRequest) (*Bundle, error) {
+	if req.CheckpointID == "" || req.TenantID == "" || req.ProjectID == "" || req.To == "" {
+		return nil, ErrMissingFields
+	}
+	if s.checkpoints == nil {
+		return nil, errors.New("checkpoint service unavailable")
+	}
+	if req.MemoryLimit <= 0 {
+		req.MemoryLimit = DefaultMemoryLimit
+	}
+	if req.RemediationLimit <= 0 {
+		req.RemediationLimit = DefaultRemediationLimit
+	}
+
+	pathCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.TenantID,
+		TeamID:    req.TeamID,
+		ProjectID: req.ProjectID,
+	})
+	// Memory tools use the project ID as both tenant and project scope
+	memCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.ProjectID,
+		ProjectID: req.ProjectID,
+	})
+
+	cp, err := s.checkpoints.Get(pathCtx, req.TenantID, req.TeamID, req.ProjectID, req.CheckpointID)
+	if err != nil {
+		return nil, err
+	}
+	if cp.Quarantined() {
+		return nil, fmt.Errorf("%w: %s", checkpoint.ErrQuarantined, cp.ID)
+	}
+
+	bundle := &Bundle{
+		Version:        FormatVersion,
+		ID:             uuid.New().String(),
+		From:           req.From,
+		To:             req.To,
+		Note:           s.scrub(req.Note),
+		SourceTenantID: req.TenantID,
+		ProjectID:      req.ProjectID,
+		Checkpoint: &Checkpoint{
+			ID:          cp.ID,
+			SessionID:   cp.SessionID,
+			Name:        cp.Name,
+			Description: s.scrub(cp.Description),
+			Summary:     s.scrub(cp.Summary),
+			Context:     s.scrub(cp.Context),
+			FullState:   s.scrub(cp.FullState),
+			TokenCount:  cp.TokenCount,
+			CreatedAt:   cp.CreatedAt,
+		},
+		Memories:     []Memory{},
+		Remediations: []Remediation{},
+		CreatedAt:    time.Now(),
+	}
+
+	query := cp.Summary
+	if query == "" {
+		query = cp.Name
+	}
+
+	memories, err := s.usedMemories(memCtx, req, cp, query)
+	if err != nil {
+		bundle.Warnings = append(bundle.Warnings, fmt.Sprintf("memories: %v", err))
+	}
+	bundle.Memories = memories
+
+	remediations, err := s.openRemediations(pathCtx, req, query)
+	if err != nil {
+		bundle.Warnings = append(bundle.Warnings, fmt.Sprintf("remediations: %v", err))
+	}
+	bundle.Remediations = remediations
+
+	bundle.Summary = stateOfWork(bundle, cp.Annotation)
+
+	for _, w := range bundle.Warnings {
+		s.logger.Warn("handoff export section failed",
+			zap.String("checkpoint_id", cp.ID),
+			zap.String("warning", w))
+	}
+	s.logger.Info("exported handoff bundle",
+		zap.String("handoff_id", bundle.ID),
+		zap.String("checkpoint_id", cp.ID),
+		zap.String("to", bundle.To),
+		zap.Int("memories", len(bundle.Memories)),
+		zap.Int("remediations", len(bundle.Remediations)))
+	return bundle, nil
+}
+
+// usedMemories returns the memories named in the request and those the
+// checkpoint's session retrieved, according to the analytics journal. When
+// neither yields any, it falls back to the memories most relevant to query.
+func (s *Service) usedMemories(ctx context.Context, req ExportRequest, cp *checkpoint.Checkpoint, query string) ([]Memory, error) {
+	if s.memory == nil {
+		return []Memory{}, errors.New("memory service unavailable")
+	}
+
+	ids := append([]string(nil), req.MemoryIDs...)
+	if s.tracker != nil && cp.SessionID != "" {
+		events, err := s.tracker.SessionEvents(analytics.SessionQuery{
+			SessionID: cp.SessionID,
+			From:      cp.CreatedAt.Add(-sessionLookback),
+			To:        cp.CreatedAt,
+		})
+		if err != nil {
+			s.logger.Debug("failed to read session journal", zap.String("session_id", cp.SessionID), zap.Error(err))
+		}
+		for _, e := range events {
+			if memoryTools[e.Tool] {
+				ids = append(ids, e.ResultIDs...)
+			}
+		}
+	}
+
+	memories := []Memory{}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] || len(memories) >= req.MemoryLimit {
+			continue
+		}
+		seen[id] = true
+		// Result IDs of federated tools include checkpoints and
+		// remediations; only IDs of this project's memories resolve
+		m, err := s.memory.GetByProjectID(ctx, req.ProjectID, id)
+		if err != nil || m.State != reasoningbank.MemoryStateActive {
+			continue
+		}
+		memories = append(memories, s.memoryOf(*m))
+	}
+	if len(memories) > 0 || query == "" {
+		return memories, nil
+	}
+
+	scored, err := s.memory.SearchWithScores(ctx, req.ProjectID, query, req.MemoryLimit)
+	if err != nil {
+		return memories, err
+	}
+	for _, sm := range scored {
+		memories = append(memories, s.memoryOf(sm.Memory))
+	}
+	return memories, nil
+}
+
+func (s *Service) memoryOf(m reasoningbank.Memory) Memory {
+	return Memory{
+		ID:         m.ID,
+		Title:      m.Title,
+		Content:    s.scrub(m.Content),
+		Outcome:    string(m.Outcome),
+		Confidence: m.Confidence,
+		Tags:       m.Tags,
+	}
+}
+
+// openRemediations returns the pending remediation drafts most relevant to
+// query: fixes someone recorded but nobody has confirmed yet.
+func (s *Service) openRemediations(ctx context.Context, req ExportRequest, query string) ([]Remediation, error) {
+	if s.remediations == nil {
+		return []Remediation{}, errors.New("remediation service unavailable")
+	}
+	if query == "" {
+		return []Remediation{}, nil
+	}
+	results, err := s.remediations.Search(ctx, &remediation.SearchRequest{
+		Query:       query,
+		TenantID:    req.TenantID,
+		TeamID:      req.TeamID,
+		ProjectPath: req.ProjectPath,
+		Status:      remediation.StatusPending,
+		Limit:       req.RemediationLimit,
+	})
+	if err != nil {
+		return []Remediation{}, err
+	}
+
+	remediations := make([]Remediation, 0, len(results))
+	for _, r := range results {
+		remediations = append(remediations, Remediation{
+			ID:         r.ID,
+			Title:      r.Title,
+			Problem:    s.scrub(r.Problem),
+			Symptoms:   r.Symptoms,
+			RootCause:  s.scrub(r.RootCause),
+			Solution:   s.scrub(r.Solution),
+			Category:   string(r.Category),
+			Confidence: r.Confidence,
+			Tags:       r.Tags,
+		})
+	}
+	return remediations, nil
+}
+
+// stateOfWork generates the bundle's summary: where the work stands, what
+// was learned along the way and what is still open.
+func stateOfWork(b *Bundle, annotation *checkpoint.Annotation) string {
+	var sb strings.Builder
+	cp := b.Checkpoint
+
+	fmt.Fprintf(&sb, "## State of the work: %s\n\n", cp.Name)
+	if b.From != "" {
+		fmt.Fprintf(&sb, "Handed off by %s to %s", b.From, b.To)
+	} else {
+		fmt.Fprintf(&sb, "Handed off to %s", b.To)
+	}
+	fmt.Fprintf(&sb, " from checkpoint %s, saved %s.\n", cp.ID, cp.CreatedAt.UTC().Format("2006-01-02 15:04 MST"))
+	if b.Note != "" {
+		fmt.Fprintf(&sb, "\n> %s\n", strings.ReplaceAll(b.Note, "\n", "\n> "))
+	}
+
+	if cp.Summary != "" {
+		fmt.Fprintf(&sb, "\n### Where it stands\n\n%s\n", cp.Summary)
+	}
+	if annotation != nil && annotation.Outcome != "" {
+		fmt.Fprintf(&sb, "\nOutcome so far: %s", annotation.Outcome)
+		if annotation.PRURL != "" {
+			fmt.Fprintf(&sb, " (%s)", annotation.PRURL)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(b.Memories) > 0 {
+		// Successes first, then the pitfalls to avoid
+		memories := append([]Memory(nil), b.Memories...)
+		sort.SliceStable(memories, func(i, j int) bool {
+			return memories[i].Outcome == string(reasoningbank.OutcomeSuccess) &&
+				memories[j].Outcome != string(reasoningbank.OutcomeSuccess)
+		})
+		sb.WriteString("\n### What was learned\n\n")
+		for _, m := range memories {
+			label := "works"
+			if m.Outcome == string(reasoningbank.OutcomeFailure) {
+				label = "avoid"
+			}
+			fmt.Fprintf(&sb, "- [%s] %s (confidence %.2f)\n", label, m.Title, m.Confidence)
+		}
+	}
+
+	if len(b.Remediations) > 0 {
+		sb.WriteString("\n### Open remediation threads\n\n")
+		for _, r := range b.Remediations {
+			fmt.Fprintf(&sb, "- [%s] %s: unconfirmed fix awaiting review\n", r.Category, r.Title)
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Import saves a bundle's checkpoint under the recipient's scope, records
+// its memories and remediation drafts, and returns what was created. A
+// checkpoint that cannot be saved fails the import; memories and
+// remediations that fail are reported in the result's warnings.
+func (s *Service) Import(ctx context.Context, req ImportRequest) (*ImportResult, error) {
+	b := req.Bundle
+	switch {
+	case b == nil || b.Checkpoint == nil:
+		return nil, fmt.Errorf("%w: bundle has no checkpoint", ErrInvalidBundle)
+	case b.Version < 1 || b.Version > FormatVersion:
+		return nil, fmt.Errorf("%w: unsupported version %d (supported: 1-%d)", ErrInvalidBundle, b.Version, FormatVersion)
+	case b.ID == "":
+		return nil, fmt.Errorf("%w: bundle has no id", ErrInvalidBundle)
+	}
+	if req.Recipient != "" && req.Recipient != b.To {
+		return nil, fmt.Errorf("%w: bundle is for %q", ErrWrongRecipient, b.To)
+	}
+	if req.TenantID == "" || req.ProjectID == "" {
+		return nil, ctxerrors.New(ctxerrors.CodeInvalidInput, "tenant_id and project_id are required")
+	}
+	if s.checkpoints == nil {
+		return nil, errors.New("checkpoint service unavailable")
+	}
+
+	pathCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.TenantID,
+		TeamID:    req.TeamID,
+		ProjectID: req.ProjectID,
+	})
+	memCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.ProjectID,
+		ProjectID: req.ProjectID,
+	})
+
+	src := b.Checkpoint
+	description := fmt.Sprintf("Handoff %s", b.ID)
+	if b.From != "" {
+		description += " from " + b.From
+	}
+	if src.Description != "" {
+		description += ": " + src.Description
+	}
+	// The generated summary leads the context so a context-level resume
+	// shows where the work stands
+	checkpointContext := b.Summary
+	if src.Context != "" {
+		checkpointContext += "\n\n" + src.Context
+	}
+	metadata := map[string]string{
+		"trigger":              "handoff",
+		"handoff_id":           b.ID,
+		"source_checkpoint_id": src.ID,
+		"source_tenant_id":     b.SourceTenantID,
+	}
+	if b.From != "" {
+		metadata["handoff_from"] = b.From
+	}
+
+	cp, err := s.checkpoints.Save(pathCtx, &checkpoint.SaveRequest{
+		SessionID:   req.SessionID,
+		TenantID:    req.TenantID,
+		TeamID:      req.TeamID,
+		ProjectID:   req.ProjectID,
+		ProjectPath: req.ProjectPath,
+		Name:        src.Name,
+		Description: description,
+		Summary:     src.Summary,
+		Context:     checkpointContext,
+		FullState:   src.FullState,
+		TokenCount:  src.TokenCount,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save handoff checkpoint: %w", err)
+	}
+
+	result := &ImportResult{
+		HandoffID:      b.ID,
+		CheckpointID:   cp.ID,
+		MemoryIDs:      []string{},
+		RemediationIDs: []string{},
+	}
+
+	if len(b.Memories) > 0 && s.memory == nil {
+		result.Warnings = append(result.Warnings, "memories: memory service unavailable")
+	} else {
+		for _, m := range b.Memories {
+			id, err := s.importMemory(memCtx, req.ProjectID, b, m)
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("memory %s: %v", m.ID, err))
+				continue
+			}
+			result.MemoryIDs = append(result.MemoryIDs, id)
+		}
+	}
+
+	if len(b.Remediations) > 0 && s.remediations == nil {
+		result.Warnings = append(result.Warnings, "remediations: remediation service unavailable")
+	} else {
+		for _, r := range b.Remediations {
+			rem, err := s.remediations.Record(pathCtx, &remediation.RecordRequest{
+				Title:       r.Title,
+				Problem:     r.Problem,
+				Symptoms:    r.Symptoms,
+				RootCause:   r.RootCause,
+				Solution:    r.Solution,
+				Category:    remediation.ErrorCategory(r.Category),
+				Tags:        r.Tags,
+				Scope:       remediation.ScopeProject,
+				TenantID:    req.TenantID,
+				TeamID:      req.TeamID,
+				ProjectPath: req.ProjectPath,
+				SessionID:   req.SessionID,
+				Confidence:  r.Confidence,
+				Status:      remediation.StatusPending,
+			})
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("remediation %s: %v", r.ID, err))
+				continue
+			}
+			result.RemediationIDs = append(result.RemediationIDs, rem.ID)
+		}
+	}
+
+	s.logger.Info("imported handoff bundle",
+		zap.String("handoff_id", b.ID),
+		zap.String("checkpoint_id", cp.ID),
+		zap.String("tenant_id", req.TenantID),
+		zap.String("project_id", req.ProjectID),
+		zap.Int("memories", len(result.MemoryIDs)),
+		zap.Int("remediations", len(result.RemediationIDs)),
+		zap.Int("warnings", len(result.Warnings)))
+	return result, nil
+}
+
+// importMemory records a bundle memory in projectID. It starts at the
+// confidence of a newly recorded memory: knowledge from another project has
+// to prove itself in this one.
+func (s *Service) importMemory(ctx context.Context, projectID string, b *Bundle, m Memory) (string, error) {
+	mem, err := reasoningbank.NewMemory(projectID, m.Title, m.Content, reasoningbank.Outcome(m.Outcome), m.Tags)
+	if err != nil {
+		return "", err
+	}
+	mem.Description = fmt.Sprintf("Imported from handoff %s (project %s)", b.ID, b.ProjectID)
+	if err := s.memory.Record(ctx, mem); err != nil {
+		return "", err
+	}
+	return mem.ID, nil
+}
+
+func (s *Service) scrub(content string) string {
+	if s.scrubber == nil || content == "" {
+		return content
+	}
+	return s.scrubber.Scrub(content).Scrubbed
+}
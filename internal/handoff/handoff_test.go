@@ -0,0 +1,298 @@
+package handoff
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// constantEmbedder returns the same vector for every text so every memory
+// matches every query.
+type constantEmbedder struct{ dim int }
+
+func (e *constantEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i], _ = e.EmbedQuery(ctx, texts[i])
+	}
+	return out, nil
+}
+
+func (e *constantEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	v := make([]float32, e.dim)
+	v[0] = 1
+	return v, nil
+}
+
+// fakeCheckpoints implements Get and Save.
+type fakeCheckpoints struct {
+	checkpoint.Service
+	byID  map[string]*checkpoint.Checkpoint
+	saved []*checkpoint.SaveRequest
+}
+
+func (f *fakeCheckpoints) Get(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*checkpoint.Checkpoint, error) {
+	cp, ok := f.byID[checkpointID]
+	if !ok {
+		return nil, checkpoint.ErrNotFound
+	}
+	return cp, nil
+}
+
+func (f *fakeCheckpoints) Save(ctx context.Context, req *checkpoint.SaveRequest) (*checkpoint.Checkpoint, error) {
+	f.saved = append(f.saved, req)
+	return &checkpoint.Checkpoint{ID: "imported", SessionID: req.SessionID, Name: req.Name}, nil
+}
+
+// fakeRemediations implements Search and Record.
+type fakeRemediations struct {
+	remediation.Service
+	results  []*remediation.ScoredRemediation
+	searches []*remediation.SearchRequest
+	recorded []*remediation.RecordRequest
+}
+
+func (f *fakeRemediations) Search(ctx context.Context, req *remediation.SearchRequest) ([]*remediation.ScoredRemediation, error) {
+	f.searches = append(f.searches, req)
+	return f.results, nil
+}
+
+func (f *fakeRemediations) Record(ctx context.Context, req *remediation.RecordRequest) (*remediation.Remediation, error) {
+	f.recorded = append(f.recorded, req)
+	return &remediation.Remediation{ID: "rem-imported", Title: req.Title, Status: req.Status}, nil
+}
+
+func newMemoryService(t *testing.T) *reasoningbank.Service {
+	t.Helper()
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	svc, err := reasoningbank.NewService(store, zap.NewNop(), reasoningbank.WithDefaultTenant("proj"))
+	require.NoError(t, err)
+	return svc
+}
+
+func recordMemory(t *testing.T, svc *reasoningbank.Service, projectID, title string, outcome reasoningbank.Outcome) *reasoningbank.Memory {
+	t.Helper()
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: projectID, ProjectID: projectID})
+	mem, err := reasoningbank.NewMemory(projectID, title, "content of "+title, outcome, []string{"go"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Record(ctx, mem))
+	return mem
+}
+
+func TestService_Export(t *testing.T) {
+	ctx := context.Background()
+	memorySvc := newMemoryService(t)
+	used := recordMemory(t, memorySvc, "proj", "Retry with backoff", reasoningbank.OutcomeSuccess)
+	recordMemory(t, memorySvc, "proj", "Unrelated memory", reasoningbank.OutcomeSuccess)
+
+	checkpoints := &fakeCheckpoints{byID: map[string]*checkpoint.Checkpoint{
+		"cp1": {
+			ID:        "cp1",
+			SessionID: "sess-1",
+			Name:      "Flaky upload fix",
+			Summary:   "Uploads retry; the S3 timeout is still open",
+			Context:   "Touched uploader.go",
+			CreatedAt: time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+			Annotation: &checkpoint.Annotation{
+				Outcome: checkpoint.OutcomePartial,
+				PRURL:   "https://example.com/pr/7",
+			},
+		},
+	}}
+	remediations := &fakeRemediations{results: []*remediation.ScoredRemediation{{
+		Remediation: remediation.Remediation{
+			ID:       "rem1",
+			Title:    "S3 timeout",
+			Problem:  "Uploads time out",
+			Solution: "Raise the client timeout",
+			Category: remediation.ErrorRuntime,
+			Status:   remediation.StatusPending,
+		},
+		Score: 0.9,
+	}}}
+
+	svc := NewService(memorySvc, checkpoints, remediations, nil, nil, zap.NewNop())
+	bundle, err := svc.Export(ctx, ExportRequest{
+		CheckpointID: "cp1",
+		TenantID:     "tenant",
+		ProjectID:    "proj",
+		To:           "bob",
+		From:         "alice",
+		Note:         "Please finish the timeout fix",
+		MemoryIDs:    []string{used.ID, "not-a-memory"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, bundle.Warnings)
+
+	assert.Equal(t, FormatVersion, bundle.Version)
+	assert.NotEmpty(t, bundle.ID)
+	assert.Equal(t, "tenant", bundle.SourceTenantID)
+	assert.Equal(t, "cp1", bundle.Checkpoint.ID)
+	assert.Equal(t, "Touched uploader.go", bundle.Checkpoint.Context)
+
+	require.Len(t, bundle.Memories, 1, "named memories replace the relevance fallback")
+	assert.Equal(t, used.ID, bundle.Memories[0].ID)
+
+	require.Len(t, remediations.searches, 1)
+	assert.Equal(t, remediation.StatusPending, remediations.searches[0].Status, "only unconfirmed fixes are open threads")
+	require.Len(t, bundle.Remediations, 1)
+	assert.Equal(t, "rem1", bundle.Remediations[0].ID)
+
+	assert.Contains(t, bundle.Summary, "Handed off by alice to bob from checkpoint cp1")
+	assert.Contains(t, bundle.Summary, "> Please finish the timeout fix")
+	assert.Contains(t, bundle.Summary, "Outcome so far: partial (https://example.com/pr/7)")
+	assert.Contains(t, bundle.Summary, "- [works] Retry with backoff")
+	assert.Contains(t, bundle.Summary, "- [runtime] S3 timeout")
+
+	t.Run("falls back to relevant memories", func(t *testing.T) {
+		bundle, err := svc.Export(ctx, ExportRequest{CheckpointID: "cp1", TenantID: "tenant", ProjectID: "proj", To: "bob"})
+		require.NoError(t, err)
+		assert.Len(t, bundle.Memories, 2)
+	})
+
+	t.Run("missing checkpoint", func(t *testing.T) {
+		_, err := svc.Export(ctx, ExportRequest{CheckpointID: "nope", TenantID: "tenant", ProjectID: "proj", To: "bob"})
+		assert.ErrorIs(t, err, checkpoint.ErrNotFound)
+	})
+
+	t.Run("quarantined checkpoint", func(t *testing.T) {
+		checkpoints.byID["bad"] = &checkpoint.Checkpoint{ID: "bad", QuarantineRules: []string{"override"}}
+		_, err := svc.Export(ctx, ExportRequest{CheckpointID: "bad", TenantID: "tenant", ProjectID: "proj", To: "bob"})
+		assert.ErrorIs(t, err, checkpoint.ErrQuarantined)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, err := svc.Export(ctx, ExportRequest{CheckpointID: "cp1", TenantID: "tenant", ProjectID: "proj"})
+		assert.ErrorIs(t, err, ErrMissingFields)
+	})
+}
+
+func TestService_Export_PartialFailure(t *testing.T) {
+	checkpoints := &fakeCheckpoints{byID: map[string]*checkpoint.Checkpoint{
+		"cp1": {ID: "cp1", Name: "Work", Summary: "Halfway"},
+	}}
+	svc := NewService(nil, checkpoints, nil, nil, nil, zap.NewNop())
+
+	bundle, err := svc.Export(context.Background(), ExportRequest{CheckpointID: "cp1", TenantID: "tenant", ProjectID: "proj", To: "bob"})
+	require.NoError(t, err)
+	assert.Len(t, bundle.Warnings, 2)
+	assert.Empty(t, bundle.Memories)
+	assert.Empty(t, bundle.Remediations)
+}
+
+func TestService_Import(t *testing.T) {
+	ctx := context.Background()
+	memorySvc := newMemoryService(t)
+	checkpoints := &fakeCheckpoints{}
+	remediations := &fakeRemediations{}
+	svc := NewService(memorySvc, checkpoints, remediations, nil, nil, zap.NewNop())
+
+	bundle := &Bundle{
+		Version:        FormatVersion,
+		ID:             "handoff-1",
+		From:           "alice",
+		To:             "bob",
+		SourceTenantID: "alice-tenant",
+		ProjectID:      "alice-proj",
+		Summary:        "## State of the work: Flaky upload fix",
+		Checkpoint: &Checkpoint{
+			ID:        "cp1",
+			Name:      "Flaky upload fix",
+			Summary:   "Uploads retry",
+			Context:   "Touched uploader.go",
+			FullState: "everything",
+		},
+		Memories: []Memory{
+			{ID: "m1", Title: "Retry with backoff", Content: "Use exponential backoff", Outcome: "success", Confidence: 0.9},
+			{ID: "m2", Title: "Broken", Content: "No outcome"},
+		},
+		Remediations: []Remediation{
+			{ID: "rem1", Title: "S3 timeout", Problem: "Uploads time out", Solution: "Raise the timeout", Category: "runtime", Confidence: 0.4},
+		},
+	}
+
+	// Bundles travel as JSON
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	var received Bundle
+	require.NoError(t, json.Unmarshal(data, &received))
+
+	result, err := svc.Import(ctx, ImportRequest{
+		Bundle:      &received,
+		TenantID:    "bob-tenant",
+		ProjectID:   "bob-proj",
+		ProjectPath: "/work/bob-proj",
+		SessionID:   "bob-sess",
+		Recipient:   "bob",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "handoff-1", result.HandoffID)
+	assert.Equal(t, "imported", result.CheckpointID)
+
+	require.Len(t, checkpoints.saved, 1)
+	saved := checkpoints.saved[0]
+	assert.Equal(t, "bob-tenant", saved.TenantID)
+	assert.Equal(t, "bob-proj", saved.ProjectID)
+	assert.Equal(t, "bob-sess", saved.SessionID)
+	assert.Equal(t, "## State of the work: Flaky upload fix\n\nTouched uploader.go", saved.Context)
+	assert.Equal(t, "everything", saved.FullState)
+	assert.Equal(t, "handoff-1", saved.Metadata["handoff_id"])
+	assert.Equal(t, "cp1", saved.Metadata["source_checkpoint_id"])
+	assert.Equal(t, "alice", saved.Metadata["handoff_from"])
+
+	require.Len(t, result.MemoryIDs, 1)
+	require.Len(t, result.Warnings, 1, "the memory without an outcome is reported")
+	assert.Contains(t, result.Warnings[0], "memory m2")
+	mem, err := memorySvc.GetByProjectID(
+		vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{TenantID: "bob-proj", ProjectID: "bob-proj"}),
+		"bob-proj", result.MemoryIDs[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Retry with backoff", mem.Title)
+	assert.NotEqual(t, 0.9, mem.Confidence, "imported memories start at the confidence of a new memory")
+	assert.Contains(t, mem.Description, "handoff-1")
+
+	require.Len(t, remediations.recorded, 1)
+	assert.Equal(t, remediation.StatusPending, remediations.recorded[0].Status)
+	assert.Equal(t, "bob-tenant", remediations.recorded[0].TenantID)
+	assert.Equal(t, []string{"rem-imported"}, result.RemediationIDs)
+}
+
+func TestService_Import_Invalid(t *testing.T) {
+	svc := NewService(nil, &fakeCheckpoints{}, nil, nil, nil, zap.NewNop())
+	valid := func() *Bundle {
+		return &Bundle{Version: FormatVersion, ID: "h1", To: "bob", Checkpoint: &Checkpoint{ID: "cp1", Name: "Work"}}
+	}
+
+	tests := []struct {
+		name    string
+		req     ImportRequest
+		wantErr error
+	}{
+		{"no bundle", ImportRequest{TenantID: "t", ProjectID: "p"}, ErrInvalidBundle},
+		{"newer version", ImportRequest{Bundle: func() *Bundle { b := valid(); b.Version = FormatVersion + 1; return b }(), TenantID: "t", ProjectID: "p"}, ErrInvalidBundle},
+		{"no checkpoint", ImportRequest{Bundle: func() *Bundle { b := valid(); b.Checkpoint = nil; return b }(), TenantID: "t", ProjectID: "p"}, ErrInvalidBundle},
+		{"wrong recipient", ImportRequest{Bundle: valid(), TenantID: "t", ProjectID: "p", Recipient: "carol"}, ErrWrongRecipient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Import(context.Background(), tt.req)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
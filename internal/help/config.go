@@ -0,0 +1,134 @@
+package help
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ConfigDocuments splits a markdown configuration reference into documents:
+// one per section for its prose and examples, and one per table row, so a
+// single setting can be found by its variable name or description. Rows are
+// titled by their first cell and described by the table's headers.
+func ConfigDocuments(markdown string) []Document {
+	var (
+		docs    []Document
+		heading string
+		prose   []string
+		headers []string
+		inCode  bool
+		seen    = make(map[string]int)
+	)
+
+	// id returns a unique ID for a section or row
+	id := func(name string) string {
+		base := "config:" + slug(name)
+		seen[base]++
+		if n := seen[base]; n > 1 {
+			return base + "-" + strconv.Itoa(n)
+		}
+		return base
+	}
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(prose, "\n"))
+		if heading != "" && text != "" {
+			docs = append(docs, Document{ID: id(heading), Kind: KindConfig, Title: heading, Text: text})
+		}
+		prose = nil
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			prose = append(prose, line)
+			continue
+		}
+		if inCode {
+			prose = append(prose, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			headers = nil
+		case strings.HasPrefix(trimmed, "|"):
+			cells := tableCells(trimmed)
+			switch {
+			case headers == nil:
+				headers = cells
+			case isSeparatorRow(cells):
+			default:
+				if row, ok := rowDocument(heading, headers, cells); ok {
+					row.ID = id(row.Title)
+					docs = append(docs, row)
+				}
+			}
+		default:
+			headers = nil
+			if trimmed != "---" {
+				prose = append(prose, line)
+			}
+		}
+	}
+	flush()
+	return docs
+}
+
+// rowDocument describes one table row as "Header: value" lines.
+func rowDocument(heading string, headers, cells []string) (Document, bool) {
+	if len(cells) == 0 || cells[0] == "" {
+		return Document{}, false
+	}
+	title := strings.Trim(cells[0], "`")
+	lines := []string{heading}
+	for i, cell := range cells {
+		if i == 0 || cell == "" {
+			continue
+		}
+		if i < len(headers) {
+			lines = append(lines, headers[i]+": "+cell)
+		} else {
+			lines = append(lines, cell)
+		}
+	}
+	return Document{Kind: KindConfig, Title: title, Text: strings.Join(lines, "\n")}, true
+}
+
+// tableCells returns the trimmed cells of a markdown table row.
+func tableCells(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+// isSeparatorRow reports whether cells are a header separator like |---|:-:|.
+func isSeparatorRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.Trim(cell, "-: ") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// slug lowercases name and replaces runs of other characters with "-".
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,221 @@
+// Package help answers questions about contextd itself: which tools it
+// offers, what their parameters are and how it is configured.
+//
+// It backs the contextd_help MCP tool. The server builds an Index from the
+// tools it has registered, the capabilities it was started with and the
+// configuration reference embedded in the binary, so answers describe the
+// running version rather than whichever documentation the agent last saw.
+//
+// The index is small and built once, so it is kept in memory and ranked
+// lexically: each query term scores its inverse document frequency, doubled
+// when it appears in a document's title.
+package help
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+const (
+	// DefaultLimit is the number of results returned by default.
+	DefaultLimit = 5
+
+	// MaxLimit caps the number of results.
+	MaxLimit = 20
+
+	// titleWeight scales the score of terms found in a document's title.
+	titleWeight = 2
+)
+
+// Kind identifies what a document describes.
+type Kind string
+
+const (
+	KindTool       Kind = "tool"
+	KindConfig     Kind = "config"
+	KindCapability Kind = "capability"
+)
+
+// AllKinds lists every document kind in display order.
+var AllKinds = []Kind{KindTool, KindConfig, KindCapability}
+
+// Valid reports whether k is a known kind.
+func (k Kind) Valid() bool {
+	for _, known := range AllKinds {
+		if k == known {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// ErrMissingQuery is returned when a search has no query.
+	ErrMissingQuery = ctxerrors.New(ctxerrors.CodeInvalidInput, "query is required")
+
+	// ErrInvalidKind is returned when a search names an unknown kind.
+	ErrInvalidKind = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid help kind")
+)
+
+// ParseKinds converts kind names, as given to the MCP tool, to kinds.
+func ParseKinds(names []string) ([]Kind, error) {
+	kinds := make([]Kind, 0, len(names))
+	for _, name := range names {
+		k := Kind(strings.ToLower(strings.TrimSpace(name)))
+		if !k.Valid() {
+			return nil, fmt.Errorf("%w %q: must be one of tool, config, capability", ErrInvalidKind, name)
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds, nil
+}
+
+// Document is one entry of the index: a tool, a configuration setting or
+// section, or a capability of the server.
+type Document struct {
+	ID    string `json:"id"`
+	Kind  Kind   `json:"kind"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// Result is a document matching a query.
+type Result struct {
+	Document
+
+	// Score is the summed weight of the query terms the document contains.
+	Score float64 `json:"score"`
+}
+
+// indexedDocument is a document with its terms.
+type indexedDocument struct {
+	doc   Document
+	title map[string]bool
+	body  map[string]bool
+}
+
+// Index ranks documents against queries. It is safe for concurrent use.
+type Index struct {
+	docs []indexedDocument
+
+	// idf is the inverse document frequency of each term
+	idf map[string]float64
+}
+
+// NewIndex indexes docs.
+func NewIndex(docs []Document) *Index {
+	idx := &Index{
+		docs: make([]indexedDocument, len(docs)),
+		idf:  make(map[string]float64),
+	}
+	df := make(map[string]int)
+	for i, doc := range docs {
+		indexed := indexedDocument{
+			doc:   doc,
+			title: termSet(doc.Title),
+			body:  termSet(doc.Title + " " + doc.Text),
+		}
+		for term := range indexed.body {
+			df[term]++
+		}
+		idx.docs[i] = indexed
+	}
+	for term, n := range df {
+		idx.idf[term] = math.Log(1 + float64(len(docs))/float64(n))
+	}
+	return idx
+}
+
+// Len returns the number of indexed documents.
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns up to limit documents of the given kinds matching query,
+// best first. Empty kinds searches all; limit defaults to DefaultLimit and
+// is capped at MaxLimit.
+func (idx *Index) Search(query string, kinds []Kind, limit int) ([]Result, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrMissingQuery
+	}
+	for _, k := range kinds {
+		if !k.Valid() {
+			return nil, fmt.Errorf("%w %q", ErrInvalidKind, k)
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	terms := termSet(query)
+	results := []Result{}
+	for _, indexed := range idx.docs {
+		if len(kinds) > 0 && !containsKind(kinds, indexed.doc.Kind) {
+			continue
+		}
+		var score float64
+		for term := range terms {
+			switch {
+			case indexed.title[term]:
+				score += titleWeight * idx.idf[term]
+			case indexed.body[term]:
+				score += idx.idf[term]
+			}
+		}
+		if score > 0 {
+			results = append(results, Result{Document: indexed.doc, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func containsKind(kinds []Kind, k Kind) bool {
+	for _, known := range kinds {
+		if known == k {
+			return true
+		}
+	}
+	return false
+}
+
+// stopwords are ignored in queries and documents.
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true,
+	"how": true, "what": true, "which": true, "when": true, "can": true,
+	"does": true, "are": true, "was": true, "this": true, "that": true,
+	"use": true, "using": true, "into": true, "its": true, "you": true,
+}
+
+// termSet splits text into lowercase terms. Identifiers are split on
+// underscores as well, so "checkpoint_save" matches "save a checkpoint",
+// and a trailing plural "s" is dropped.
+func termSet(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) < 2 || stopwords[word] {
+			continue
+		}
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+		terms[word] = true
+	}
+	return terms
+}
@@ -0,0 +1,111 @@
+package help
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/docs"
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex([]Document{
+		{ID: "tool:checkpoint_save", Kind: KindTool, Title: "checkpoint_save", Text: "Save a session checkpoint"},
+		{ID: "tool:checkpoint_list", Kind: KindTool, Title: "checkpoint_list", Text: "List checkpoints for a session"},
+		{ID: "tool:memory_record", Kind: KindTool, Title: "memory_record", Text: "Record a memory so it can be saved for later"},
+		{ID: "config:checkpoint_max_content_size_kb", Kind: KindConfig, Title: "CHECKPOINT_MAX_CONTENT_SIZE_KB", Text: "Maximum checkpoint content size (KB)"},
+	})
+	require.Equal(t, 4, idx.Len())
+
+	t.Run("title matches rank first", func(t *testing.T) {
+		results, err := idx.Search("How do I save a checkpoint?", nil, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "tool:checkpoint_save", results[0].ID)
+		for i := 1; i < len(results); i++ {
+			assert.GreaterOrEqual(t, results[i-1].Score, results[i].Score)
+		}
+	})
+
+	t.Run("plurals match singular terms", func(t *testing.T) {
+		results, err := idx.Search("checkpoints", []Kind{KindTool}, 0)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+	})
+
+	t.Run("kinds and limit restrict results", func(t *testing.T) {
+		results, err := idx.Search("checkpoint", []Kind{KindConfig}, 0)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, KindConfig, results[0].Kind)
+
+		results, err = idx.Search("checkpoint", nil, 1)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("no match returns an empty list", func(t *testing.T) {
+		results, err := idx.Search("kubernetes", nil, 0)
+		require.NoError(t, err)
+		assert.NotNil(t, results)
+		assert.Empty(t, results)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := idx.Search("  ", nil, 0)
+		assert.ErrorIs(t, err, ErrMissingQuery)
+
+		_, err = idx.Search("checkpoint", []Kind{"recipe"}, 0)
+		assert.ErrorIs(t, err, ErrInvalidKind)
+		assert.Equal(t, ctxerrors.CodeInvalidInput, ctxerrors.CodeOf(err))
+	})
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := ParseKinds([]string{" Tool", "config"})
+	require.NoError(t, err)
+	assert.Equal(t, []Kind{KindTool, KindConfig}, kinds)
+
+	_, err = ParseKinds([]string{"recipe"})
+	assert.ErrorIs(t, err, ErrInvalidKind)
+}
+
+func TestConfigDocuments(t *testing.T) {
+	markdown := "# Configuration\n\n" +
+		"## Checkpoints\n\n" +
+		"Checkpoints are kept until deleted.\n\n" +
+		"```bash\n# not a heading\nexport CHECKPOINT_MAX_CONTENT_SIZE_KB=2048\n```\n\n" +
+		"| Variable | Default | Description |\n" +
+		"|----------|---------|-------------|\n" +
+		"| `CHECKPOINT_MAX_CONTENT_SIZE_KB` | `1024` | Maximum checkpoint content size (KB) |\n"
+
+	documents := make(map[string]Document)
+	for _, doc := range ConfigDocuments(markdown) {
+		documents[doc.ID] = doc
+	}
+	require.Len(t, documents, 2)
+
+	section := documents["config:checkpoints"]
+	assert.Equal(t, "Checkpoints", section.Title)
+	assert.Contains(t, section.Text, "# not a heading")
+	assert.NotContains(t, section.Text, "| Variable")
+
+	row := documents["config:checkpoint_max_content_size_kb"]
+	assert.Equal(t, "CHECKPOINT_MAX_CONTENT_SIZE_KB", row.Title)
+	assert.Equal(t, "Checkpoints\nDefault: `1024`\nDescription: Maximum checkpoint content size (KB)", row.Text)
+}
+
+func TestConfigDocuments_EmbeddedReference(t *testing.T) {
+	documents := ConfigDocuments(docs.Configuration)
+	require.NotEmpty(t, documents)
+
+	ids := make(map[string]bool, len(documents))
+	for _, doc := range documents {
+		assert.False(t, ids[doc.ID], "duplicate id %s", doc.ID)
+		ids[doc.ID] = true
+	}
+	assert.True(t, ids["config:server_port"])
+	assert.True(t, ids["config:contextd_logging_file_path"])
+}
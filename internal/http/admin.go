@@ -0,0 +1,120 @@
+package http
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// ReadOnlyRequest is the request body for PUT /api/v1/admin/read-only.
+type ReadOnlyRequest struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// handleGetReadOnly returns the current read-only status.
+func (s *Server) handleGetReadOnly(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "admin endpoints are restricted to localhost")
+	}
+	if s.readOnly == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "read-only mode not configured")
+	}
+	return c.JSON(http.StatusOK, s.readOnly.Status())
+}
+
+// handlePutReadOnly turns read-only mode on or off at runtime.
+func (s *Server) handlePutReadOnly(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "admin endpoints are restricted to localhost")
+	}
+	if s.readOnly == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "read-only mode not configured")
+	}
+
+	var req ReadOnlyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if len(req.Reason) > MaxSummaryLength {
+		return echo.NewHTTPError(http.StatusBadRequest, "reason is too long")
+	}
+
+	s.readOnly.Set(req.Enabled, req.Reason)
+	s.logger.Warn("read-only mode changed",
+		zap.Bool("enabled", req.Enabled),
+		zap.String("reason", req.Reason),
+	)
+
+	return c.JSON(http.StatusOK, s.readOnly.Status())
+}
+
+// VerifyRepairRequest is the request body for POST
+// /api/v1/admin/verify/repair: issues from a verification report.
+type VerifyRepairRequest struct {
+	Issues []vectorstore.VerifyIssue `json:"issues"`
+}
+
+// handleVerify checks the integrity of every vectorstore collection.
+func (s *Server) handleVerify(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "admin endpoints are restricted to localhost")
+	}
+	if s.verifier == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "vectorstore verifier not configured")
+	}
+
+	report, err := s.verifier.Verify(c.Request().Context())
+	if err != nil {
+		s.logger.Error("vectorstore verification failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "vectorstore verification failed")
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// handleVerifyRepair repairs the issues of a verification report that are
+// still present.
+func (s *Server) handleVerifyRepair(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "admin endpoints are restricted to localhost")
+	}
+	if s.verifier == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "vectorstore verifier not configured")
+	}
+
+	var req VerifyRepairRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if err := s.readOnly.Check("vectorstore repair"); err != nil {
+		return err
+	}
+
+	result, err := s.verifier.Repair(c.Request().Context(), req.Issues)
+	if err != nil {
+		s.logger.Error("vectorstore repair failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "vectorstore repair failed")
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// isLoopbackRequest reports whether the request came from localhost. It uses
+// RemoteAddr rather than c.RealIP(), which trusts X-Forwarded-For/X-Real-IP
+// headers that clients can spoof. Requests on the Unix socket are local by
+// construction.
+func isLoopbackRequest(c echo.Context) bool {
+	if addr, ok := c.Request().Context().Value(http.LocalAddrContextKey).(net.Addr); ok && addr.Network() == "unix" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		// RemoteAddr without port (shouldn't happen with net/http, but be safe)
+		host = c.Request().RemoteAddr
+	}
+	remoteIP := net.ParseIP(host)
+	return remoteIP != nil && remoteIP.IsLoopback()
+}
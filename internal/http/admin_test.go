@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func adminRequest(t *testing.T, server *Server, method, remoteAddr string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, "/api/v1/admin/read-only", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReadOnlyAdmin(t *testing.T) {
+	mode := readonly.New(false, "")
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{ReadOnly: mode})
+	require.NoError(t, err)
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := adminRequest(t, server, http.MethodPut, "192.0.2.1:1234", ReadOnlyRequest{Enabled: true})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.False(t, mode.Enabled())
+	})
+
+	t.Run("enable and disable", func(t *testing.T) {
+		rec := adminRequest(t, server, http.MethodPut, "127.0.0.1:1234", ReadOnlyRequest{Enabled: true, Reason: "migration"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, mode.Enabled())
+
+		rec = adminRequest(t, server, http.MethodGet, "127.0.0.1:1234", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var status readonly.Status
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		assert.True(t, status.Enabled)
+		assert.Equal(t, "migration", status.Reason)
+
+		rec = adminRequest(t, server, http.MethodPut, "[::1]:1234", ReadOnlyRequest{Enabled: false})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, mode.Enabled())
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{})
+		require.NoError(t, err)
+		rec := adminRequest(t, server, http.MethodGet, "127.0.0.1:1234", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestThreshold_ReadOnlySkipsCheckpoint(t *testing.T) {
+	// No Checkpoint expectation: the mock fails the test if Save is reached
+	registry := &mockRegistry{}
+	server, err := NewServer(registry, zap.NewNop(), &Config{ReadOnly: readonly.New(true, "disk full")})
+	require.NoError(t, err)
+
+	rec := postJSON(t, server, "/api/v1/threshold", ThresholdRequest{
+		ProjectID: "proj",
+		SessionID: "sess",
+		Percent:   70,
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp ThresholdResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Empty(t, resp.CheckpointID)
+	assert.Contains(t, resp.Message, "read-only")
+	registry.AssertExpectations(t)
+}
+
+func TestVerifyAdmin(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "acme"})
+	_, err = store.AddDocuments(ctx, []vectorstore.Document{
+		{ID: "cp_2", Content: "checkpoint", Collection: "acme_api_checkpoints", Metadata: map[string]interface{}{"parent_id": "cp_1"}},
+	})
+	require.NoError(t, err)
+
+	verifier, err := vectorstore.NewVerifier(store, vectorstore.VerifyConfig{}, zap.NewNop())
+	require.NoError(t, err)
+	mode := readonly.New(false, "")
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Verifier: verifier, ReadOnly: mode})
+	require.NoError(t, err)
+
+	request := func(method, path, remoteAddr string, body interface{}) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodGet, "/api/v1/admin/verify", "192.0.2.1:1234", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = request(http.MethodGet, "/api/v1/admin/verify", "127.0.0.1:1234", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report vectorstore.VerifyReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, vectorstore.IssueDanglingReference, report.Issues[0].Kind)
+
+	repair := VerifyRepairRequest{Issues: report.Issues}
+	mode.Set(true, "migration")
+	rec = request(http.MethodPost, "/api/v1/admin/verify/repair", "127.0.0.1:1234", repair)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	mode.Set(false, "")
+	rec = request(http.MethodPost, "/api/v1/admin/verify/repair", "127.0.0.1:1234", repair)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result vectorstore.RepairResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Len(t, result.Repaired, 1)
+
+	unconfigured, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{})
+	require.NoError(t, err)
+	server = unconfigured
+	rec = request(http.MethodGet, "/api/v1/admin/verify", "127.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
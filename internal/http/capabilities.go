@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
+)
+
+// ToolCatalog lists the contracts of the MCP tools served alongside the
+// HTTP API and the deprecation notices of their legacy aliases.
+// *mcp.Server implements it.
+type ToolCatalog interface {
+	ToolContracts(ctx context.Context) ([]capabilities.Tool, error)
+	ToolDeprecations() []capabilities.Deprecation
+}
+
+// SetToolCatalog adds the MCP tools to GET /api/v1/capabilities. It may be
+// called after Start, since the MCP server is built after the HTTP server.
+func (s *Server) SetToolCatalog(catalog ToolCatalog) {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	s.tools = catalog
+}
+
+// handleCapabilities describes the server for clients that adapt to its
+// version: features, tool schema versions and deprecation notices. A
+// protocol_version query parameter negotiates compatibility with the
+// client. Tools are listed only when an MCP server is running.
+func (s *Server) handleCapabilities(c echo.Context) error {
+	var clientProtocol int
+	if raw := c.QueryParam("protocol_version"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "protocol_version must be a non-negative integer")
+		}
+		clientProtocol = v
+	}
+
+	s.toolsMu.RLock()
+	catalog := s.tools
+	s.toolsMu.RUnlock()
+
+	var (
+		tools   []capabilities.Tool
+		notices []capabilities.Deprecation
+	)
+	if catalog != nil {
+		var err error
+		tools, err = catalog.ToolContracts(c.Request().Context())
+		if err != nil {
+			s.logger.Error("listing tool contracts failed", zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "listing tools failed")
+		}
+		notices = catalog.ToolDeprecations()
+	}
+
+	features := s.config.Features
+	features.ReadOnly = s.readOnly != nil && s.readOnly.Enabled()
+	caps := capabilities.New(s.config.Version, features, tools, notices...)
+	caps.Negotiate(clientProtocol)
+	return c.JSON(http.StatusOK, caps)
+}
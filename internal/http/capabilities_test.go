@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+)
+
+type stubToolCatalog struct {
+	tools   []capabilities.Tool
+	notices []capabilities.Deprecation
+}
+
+func (c stubToolCatalog) ToolContracts(context.Context) ([]capabilities.Tool, error) {
+	return c.tools, nil
+}
+
+func (c stubToolCatalog) ToolDeprecations() []capabilities.Deprecation {
+	return c.notices
+}
+
+func TestHandleCapabilities(t *testing.T) {
+	mode := readonly.New(false, "")
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{
+		Version:  "1.2.3",
+		ReadOnly: mode,
+		Features: capabilities.Features{VectorStore: "qdrant", AuthMode: capabilities.AuthLocalhost},
+	})
+	require.NoError(t, err)
+
+	get := func(t *testing.T, target string) (*httptest.ResponseRecorder, capabilities.Capabilities) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var caps capabilities.Capabilities
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &caps))
+		}
+		return rec, caps
+	}
+
+	t.Run("features without MCP tools", func(t *testing.T) {
+		rec, caps := get(t, "/api/v1/capabilities")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1.2.3", caps.Version)
+		assert.Equal(t, capabilities.ProtocolVersion, caps.ProtocolVersion)
+		assert.Equal(t, "qdrant", caps.Features.VectorStore)
+		assert.Equal(t, capabilities.AuthLocalhost, caps.Features.AuthMode)
+		assert.False(t, caps.Features.ReadOnly)
+		assert.Empty(t, caps.Tools)
+		assert.Nil(t, caps.Compatibility)
+	})
+
+	t.Run("tools and current read-only state", func(t *testing.T) {
+		server.SetToolCatalog(stubToolCatalog{
+			tools: []capabilities.Tool{
+				capabilities.ToolContract("memory_search", map[string]any{"type": "object"}),
+				capabilities.ToolContract("memory_store", map[string]any{"type": "object"}),
+			},
+			notices: []capabilities.Deprecation{{
+				Kind: capabilities.DeprecatedTool, Name: "memory_store", Replacement: "memory_record",
+			}},
+		})
+		mode.Set(true, "migration")
+		defer mode.Set(false, "")
+
+		rec, caps := get(t, "/api/v1/capabilities?protocol_version=1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, caps.Tools, 2)
+		assert.Equal(t, "memory_search", caps.Tools[0].Name)
+		assert.False(t, caps.Tools[0].Deprecated)
+		assert.Equal(t, "memory_store", caps.Tools[1].Name)
+		assert.True(t, caps.Tools[1].Deprecated)
+		require.Len(t, caps.Deprecations, 1)
+		assert.Equal(t, "memory_record", caps.Deprecations[0].Replacement)
+		assert.True(t, caps.Features.ReadOnly)
+		require.NotNil(t, caps.Compatibility)
+		assert.True(t, caps.Compatibility.Compatible)
+	})
+
+	t.Run("invalid protocol version", func(t *testing.T) {
+		rec, _ := get(t, "/api/v1/capabilities?protocol_version=two")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
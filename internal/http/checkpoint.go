@@ -0,0 +1,338 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/compression"
+	"github.com/fyrsmithlabs/contextd/internal/conversation"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CheckpointAnnotateRequest is the request body for PATCH /api/v1/checkpoints/:id.
+// Omitted fields keep their current value.
+type CheckpointAnnotateRequest struct {
+	ProjectPath string `json:"project_path"`
+	TenantID    string `json:"tenant_id,omitempty"`
+	Outcome     string `json:"outcome,omitempty"`
+	PRURL       string `json:"pr_url,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// CheckpointAnnotateResponse is the response body for PATCH /api/v1/checkpoints/:id.
+type CheckpointAnnotateResponse struct {
+	CheckpointID string                 `json:"checkpoint_id"`
+	Annotation   *checkpoint.Annotation `json:"annotation"`
+}
+
+// handleCheckpointAnnotate records the outcome of a checkpoint's work. The
+// checkpoint is looked up in the tenant and project derived from project_path,
+// the same scope checkpoint_save wrote it to.
+func (s *Server) handleCheckpointAnnotate(c echo.Context) error {
+	var req CheckpointAnnotateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.ProjectPath == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "project_path field is required")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	projectID, err := projectIDFromPath(validPath)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_path")
+	}
+
+	if err := s.readOnly.Check("checkpoint annotate"); err != nil {
+		return err
+	}
+
+	checkpointSvc := s.registry.Checkpoint()
+	if checkpointSvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "checkpoint service unavailable")
+	}
+
+	ctx := vectorstore.ContextWithTenant(c.Request().Context(), &vectorstore.TenantInfo{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+	})
+
+	cp, err := checkpointSvc.Annotate(ctx, &checkpoint.AnnotateRequest{
+		CheckpointID: c.Param("id"),
+		TenantID:     tenantID,
+		ProjectID:    projectID,
+		Outcome:      checkpoint.Outcome(req.Outcome),
+		PRURL:        req.PRURL,
+		Notes:        req.Notes,
+	})
+	switch {
+	case isClientVisible(err):
+		return err
+	case err != nil:
+		s.logger.Error("failed to annotate checkpoint", zap.String("checkpoint_id", c.Param("id")), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to annotate checkpoint")
+	}
+
+	annotation := *cp.Annotation
+	annotation.Notes = s.scrub(annotation.Notes)
+	return c.JSON(http.StatusOK, CheckpointAnnotateResponse{
+		CheckpointID: cp.ID,
+		Annotation:   &annotation,
+	})
+}
+
+// projectIDFromPath derives the project ID checkpoint_save uses from a
+// validated project path.
+func projectIDFromPath(validPath string) (string, error) {
+	baseName, err := sanitize.SafeBasename(validPath)
+	if err != nil {
+		return "", err
+	}
+	projectID := sanitize.Identifier(baseName)
+	if err := sanitize.ValidateProjectID(projectID); err != nil {
+		return "", err
+	}
+	return projectID, nil
+}
+
+// Compression ratios for synthesized checkpoint levels. The full level is
+// the transcript itself.
+const (
+	synthesizeSummaryRatio = 20.0
+	synthesizeContextRatio = 4.0
+)
+
+// CheckpointSynthesizeRequest is the request body for POST
+// /api/v1/checkpoints/synthesize.
+type CheckpointSynthesizeRequest struct {
+	ProjectPath  string `json:"project_path"`
+	TenantID     string `json:"tenant_id,omitempty"`
+	SessionID    string `json:"session_id,omitempty"`    // Default: the most recent session
+	MessageStart int    `json:"message_start,omitempty"` // Index of the first message (default: 0)
+	MessageEnd   int    `json:"message_end,omitempty"`   // Index after the last message (default: end of session)
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Algorithm    string `json:"algorithm,omitempty"` // extractive, abstractive, or hybrid (default: extractive)
+}
+
+// CheckpointSynthesizeResponse is the response body for POST
+// /api/v1/checkpoints/synthesize.
+type CheckpointSynthesizeResponse struct {
+	CheckpointID string   `json:"checkpoint_id"`
+	SessionID    string   `json:"session_id"`
+	MessageStart int      `json:"message_start"`
+	MessageEnd   int      `json:"message_end"`
+	Summary      string   `json:"summary"`
+	TokenCount   int32    `json:"token_count"`
+	Truncated    bool     `json:"truncated,omitempty"` // Levels were compressed from the end of a transcript too long to compress whole
+	Warnings     []string `json:"warnings,omitempty"`  // Degraded compression, e.g. extractive fallback when the LLM budget is exhausted
+}
+
+// handleCheckpointSynthesize creates a checkpoint from a conversation that
+// was never checkpointed. The session's messages, redacted with the
+// tenant's conversation profile, become the full level; the compression
+// service derives the context and summary levels from them. The checkpoint
+// is saved in the same tenant and project scope as checkpoint_save.
+func (s *Server) handleCheckpointSynthesize(c echo.Context) error {
+	var req CheckpointSynthesizeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.ProjectPath == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "project_path field is required")
+	}
+	if len(req.Name) > CheckpointNameMaxLength {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("name must be at most %d characters", CheckpointNameMaxLength))
+	}
+	if len(req.Description) > MaxSummaryLength {
+		return validation.NewError(validation.CodeContentTooLong, "description", MaxSummaryLength, len(req.Description))
+	}
+	algorithm := compression.AlgorithmExtractive
+	if req.Algorithm != "" {
+		algorithm = compression.Algorithm(req.Algorithm)
+	}
+	switch algorithm {
+	case compression.AlgorithmExtractive, compression.AlgorithmAbstractive, compression.AlgorithmHybrid:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "algorithm must be extractive, abstractive, or hybrid")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	projectID, err := projectIDFromPath(validPath)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_path")
+	}
+
+	if err := s.readOnly.Check("checkpoint synthesize"); err != nil {
+		return err
+	}
+
+	checkpointSvc := s.registry.Checkpoint()
+	if checkpointSvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "checkpoint service unavailable")
+	}
+	conversationSvc := s.registry.Conversations()
+	if conversationSvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "conversation service unavailable")
+	}
+	compressionSvc := s.registry.Compression()
+	if compressionSvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "compression service unavailable")
+	}
+
+	ctx := vectorstore.ContextWithTenant(c.Request().Context(), &vectorstore.TenantInfo{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+	})
+
+	transcript, err := conversationSvc.Transcript(ctx, conversation.TranscriptOptions{
+		ProjectPath: validPath,
+		TenantID:    tenantID,
+		SessionID:   req.SessionID,
+		Start:       req.MessageStart,
+		End:         req.MessageEnd,
+	})
+	switch {
+	case isClientVisible(err):
+		return err
+	case err != nil:
+		s.logger.Error("failed to read conversation", zap.String("session_id", req.SessionID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to read conversation")
+	}
+
+	// LLM usage and budget checks are attributed to the resolved session
+	ctx = analytics.ContextWithScope(ctx, analytics.Scope{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		SessionID: transcript.SessionID,
+	})
+
+	fullState := transcript.Text()
+	if strings.TrimSpace(fullState) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "selected messages have no text")
+	}
+
+	// Compressors have an input limit; long sessions are compressed from
+	// their most recent messages, which matter most when resuming
+	input := fullState
+	limit := compressionSvc.GetCapabilities(ctx)[algorithm].MaxContentLength
+	truncated := limit > 0 && len(input) > limit
+	if truncated {
+		input = input[len(input)-limit:]
+		if i := strings.Index(input, "\n\n"); i >= 0 {
+			input = input[i+2:]
+		}
+		for len(input) > 0 && !utf8.RuneStart(input[0]) {
+			input = input[1:]
+		}
+	}
+
+	contextResult, err := compressionSvc.Compress(ctx, input, algorithm, synthesizeContextRatio)
+	if err != nil {
+		s.logger.Error("failed to compress conversation", zap.String("level", "context"), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to compress conversation")
+	}
+	summaryResult, err := compressionSvc.Compress(ctx, input, algorithm, synthesizeSummaryRatio)
+	if err != nil {
+		s.logger.Error("failed to compress conversation", zap.String("level", "summary"), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to compress conversation")
+	}
+	summary := truncateRunes(summaryResult.Content, MaxSummaryLength)
+	checkpointContext := truncateRunes(contextResult.Content, MaxContextLength)
+	var warnings []string
+	for _, w := range append(contextResult.Warnings, summaryResult.Warnings...) {
+		if !slices.Contains(warnings, w) {
+			warnings = append(warnings, w)
+		}
+	}
+
+	name := req.Name
+	if name == "" {
+		// Session IDs are UUIDs; the first block identifies them in lists
+		shortID, _, _ := strings.Cut(transcript.SessionID, "-")
+		name = fmt.Sprintf("Synthesized from session %s", shortID)
+		if len(name) > CheckpointNameMaxLength {
+			name = name[:CheckpointNameMaxLength-len(CheckpointNameTruncationSuffix)] + CheckpointNameTruncationSuffix
+		}
+	}
+	description := req.Description
+	if description == "" {
+		description = fmt.Sprintf("Synthesized from messages %d-%d of %d in session %s",
+			transcript.Start, transcript.End-1, transcript.Total, transcript.SessionID)
+	}
+	metadata := map[string]string{
+		"trigger":       "synthesized",
+		"algorithm":     string(algorithm),
+		"message_start": strconv.Itoa(transcript.Start),
+		"message_end":   strconv.Itoa(transcript.End),
+	}
+	if truncated {
+		metadata["truncated"] = "true"
+	}
+
+	cp, err := checkpointSvc.Save(ctx, &checkpoint.SaveRequest{
+		SessionID:   transcript.SessionID,
+		TenantID:    tenantID,
+		ProjectID:   projectID,
+		ProjectPath: validPath,
+		Name:        name,
+		Description: description,
+		Summary:     summary,
+		Context:     checkpointContext,
+		FullState:   fullState,
+		TokenCount:  int32(len(fullState) / 4), // ~4 characters per token
+		Metadata:    metadata,
+	})
+	if err != nil {
+		s.logger.Error("failed to save synthesized checkpoint",
+			zap.String("session_id", transcript.SessionID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create checkpoint")
+	}
+
+	s.logger.Info("synthesized checkpoint from conversation",
+		zap.String("checkpoint_id", cp.ID),
+		zap.String("session_id", transcript.SessionID),
+		zap.Int("messages", len(transcript.Messages)),
+		zap.Bool("truncated", truncated),
+	)
+
+	return c.JSON(http.StatusOK, CheckpointSynthesizeResponse{
+		CheckpointID: cp.ID,
+		SessionID:    transcript.SessionID,
+		MessageStart: transcript.Start,
+		MessageEnd:   transcript.End,
+		Summary:      summary,
+		TokenCount:   cp.TokenCount,
+		Truncated:    truncated,
+		Warnings:     warnings,
+	})
+}
+
+// truncateRunes shortens s to at most max bytes without splitting a rune.
+func truncateRunes(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/compression"
+	"github.com/fyrsmithlabs/contextd/internal/conversation"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+)
+
+func patchJSON(t *testing.T, server *Server, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPatch, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func setupCheckpointServer(t *testing.T, cfg *Config) (*Server, *mockCheckpointService) {
+	t.Helper()
+
+	scrubber, err := secrets.New(nil)
+	require.NoError(t, err)
+
+	mockCp := &mockCheckpointService{}
+	registry := &mockRegistry{}
+	registry.On("Scrubber").Return(scrubber)
+	registry.On("Checkpoint").Return(mockCp)
+
+	server, err := NewServer(registry, zap.NewNop(), cfg)
+	require.NoError(t, err)
+	return server, mockCp
+}
+
+func TestHandleCheckpointAnnotate(t *testing.T) {
+	t.Run("annotates checkpoint in the project scope", func(t *testing.T) {
+		server, mockCp := setupCheckpointServer(t, nil)
+		annotatedAt := time.Now()
+		mockCp.On("Annotate", mock.Anything, mock.MatchedBy(func(req *checkpoint.AnnotateRequest) bool {
+			return req.CheckpointID == "cp-1" &&
+				req.TenantID == "acme" &&
+				req.ProjectID == "api" &&
+				req.Outcome == checkpoint.OutcomeFailed &&
+				req.Notes == "broke login"
+		})).Return(&checkpoint.Checkpoint{
+			ID: "cp-1",
+			Annotation: &checkpoint.Annotation{
+				Outcome:     checkpoint.OutcomeFailed,
+				Notes:       "broke login",
+				AnnotatedAt: annotatedAt,
+			},
+		}, nil)
+
+		rec := patchJSON(t, server, "/api/v1/checkpoints/cp-1", CheckpointAnnotateRequest{
+			ProjectPath: "/home/user/api",
+			TenantID:    "acme",
+			Outcome:     "failed",
+			Notes:       "broke login",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp CheckpointAnnotateResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "cp-1", resp.CheckpointID)
+		assert.Equal(t, checkpoint.OutcomeFailed, resp.Annotation.Outcome)
+		mockCp.AssertExpectations(t)
+	})
+
+	t.Run("maps service errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			err  error
+			want int
+		}{
+			{fmt.Errorf("%w: outcome must be succeeded, failed or partial", checkpoint.ErrInvalidAnnotation), http.StatusBadRequest},
+			{fmt.Errorf("%w: cp-1", checkpoint.ErrNotFound), http.StatusNotFound},
+			{fmt.Errorf("store unavailable"), http.StatusInternalServerError},
+		} {
+			server, mockCp := setupCheckpointServer(t, nil)
+			mockCp.On("Annotate", mock.Anything, mock.Anything).Return(nil, tc.err)
+
+			rec := patchJSON(t, server, "/api/v1/checkpoints/cp-1", CheckpointAnnotateRequest{
+				ProjectPath: "/home/user/api",
+				TenantID:    "acme",
+				Outcome:     "failed",
+			})
+			assert.Equal(t, tc.want, rec.Code, tc.err.Error())
+		}
+	})
+
+	t.Run("requires project_path", func(t *testing.T) {
+		server, mockCp := setupCheckpointServer(t, nil)
+
+		rec := patchJSON(t, server, "/api/v1/checkpoints/cp-1", CheckpointAnnotateRequest{TenantID: "acme", Outcome: "failed"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockCp.AssertNotCalled(t, "Annotate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejected in read-only mode", func(t *testing.T) {
+		server, mockCp := setupCheckpointServer(t, &Config{ReadOnly: readonly.New(true, "store migration")})
+
+		rec := patchJSON(t, server, "/api/v1/checkpoints/cp-1", CheckpointAnnotateRequest{
+			ProjectPath: "/home/user/api",
+			TenantID:    "acme",
+			Outcome:     "failed",
+		})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Contains(t, rec.Body.String(), "read-only")
+		mockCp.AssertNotCalled(t, "Annotate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleCheckpointSynthesize(t *testing.T) {
+	convDir := t.TempDir()
+	session := strings.Join([]string{
+		`{"type":"user","sessionId":"5f1c2a9e-0000-4000-8000-000000000001","message":{"content":[{"type":"text","text":"The upload job fails with a timeout. How should we retry it?"}],"role":"user"},"timestamp":"2025-01-02T10:00:00Z","uuid":"m1"}`,
+		`{"type":"assistant","sessionId":"5f1c2a9e-0000-4000-8000-000000000001","message":{"content":[{"type":"text","text":"Wrap the upload in the retry helper. Use exponential backoff starting at one second. Cap the attempts at five so the job still fails fast."}],"role":"assistant"},"timestamp":"2025-01-02T10:00:30Z","uuid":"m2"}`,
+		`{"type":"user","sessionId":"5f1c2a9e-0000-4000-8000-000000000001","message":{"content":[{"type":"text","text":"Done, the tests pass now."}],"role":"user"},"timestamp":"2025-01-02T10:05:00Z","uuid":"m3"}`,
+	}, "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(convDir, "session.jsonl"), []byte(session), 0644))
+
+	setup := func(t *testing.T, cfg *Config) (*Server, *mockCheckpointService) {
+		t.Helper()
+		scrubber, err := secrets.New(nil)
+		require.NoError(t, err)
+		compressionSvc, err := compression.NewService(compression.Config{})
+		require.NoError(t, err)
+
+		mockCp := &mockCheckpointService{}
+		registry := &mockRegistry{}
+		registry.On("Scrubber").Return(scrubber)
+		registry.On("Checkpoint").Return(mockCp)
+		registry.On("Compression").Return(compressionSvc)
+		registry.On("Conversations").Return(conversation.NewService(nil, nil, zap.NewNop(), conversation.ServiceConfig{
+			ConversationsPath: convDir,
+		}))
+
+		server, err := NewServer(registry, zap.NewNop(), cfg)
+		require.NoError(t, err)
+		return server, mockCp
+	}
+
+	t.Run("saves all three levels from the latest session", func(t *testing.T) {
+		server, mockCp := setup(t, nil)
+		var saved *checkpoint.SaveRequest
+		mockCp.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*checkpoint.SaveRequest)
+		}).Return(&checkpoint.Checkpoint{ID: "cp-1", TokenCount: 80}, nil)
+
+		rec := postJSON(t, server, "/api/v1/checkpoints/synthesize", CheckpointSynthesizeRequest{
+			ProjectPath: "/home/user/api",
+			TenantID:    "acme",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp CheckpointSynthesizeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "cp-1", resp.CheckpointID)
+		assert.Equal(t, "5f1c2a9e-0000-4000-8000-000000000001", resp.SessionID)
+		assert.Equal(t, 0, resp.MessageStart)
+		assert.Equal(t, 3, resp.MessageEnd)
+		assert.False(t, resp.Truncated)
+
+		require.NotNil(t, saved)
+		assert.Equal(t, "acme", saved.TenantID)
+		assert.Equal(t, "api", saved.ProjectID)
+		assert.Equal(t, "5f1c2a9e-0000-4000-8000-000000000001", saved.SessionID)
+		assert.Equal(t, "Synthesized from session 5f1c2a9e", saved.Name)
+		assert.True(t, strings.HasPrefix(saved.FullState, "user: The upload job fails"))
+		assert.Contains(t, saved.FullState, "assistant: Wrap the upload")
+		assert.NotEmpty(t, saved.Context)
+		assert.NotEmpty(t, saved.Summary)
+		assert.LessOrEqual(t, len(saved.Summary), len(saved.FullState))
+		assert.False(t, saved.AutoCreated)
+		assert.Equal(t, "synthesized", saved.Metadata["trigger"])
+		assert.Equal(t, "extractive", saved.Metadata["algorithm"])
+		assert.Equal(t, resp.Summary, saved.Summary)
+	})
+
+	t.Run("message range", func(t *testing.T) {
+		server, mockCp := setup(t, nil)
+		mockCp.On("Save", mock.Anything, mock.MatchedBy(func(req *checkpoint.SaveRequest) bool {
+			return req.FullState == "user: Done, the tests pass now." &&
+				req.Metadata["message_start"] == "2" && req.Metadata["message_end"] == "3"
+		})).Return(&checkpoint.Checkpoint{ID: "cp-2"}, nil)
+
+		rec := postJSON(t, server, "/api/v1/checkpoints/synthesize", CheckpointSynthesizeRequest{
+			ProjectPath:  "/home/user/api",
+			TenantID:     "acme",
+			MessageStart: 2,
+			Name:         "After the retry fix",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		mockCp.AssertExpectations(t)
+	})
+
+	t.Run("client errors", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			req  CheckpointSynthesizeRequest
+			want int
+		}{
+			"missing project_path": {CheckpointSynthesizeRequest{TenantID: "acme"}, http.StatusBadRequest},
+			"unknown algorithm":    {CheckpointSynthesizeRequest{ProjectPath: "/home/user/api", Algorithm: "magic"}, http.StatusBadRequest},
+			"unknown session":      {CheckpointSynthesizeRequest{ProjectPath: "/home/user/api", SessionID: "nope"}, http.StatusNotFound},
+			"range past the end":   {CheckpointSynthesizeRequest{ProjectPath: "/home/user/api", MessageStart: 1, MessageEnd: 9}, http.StatusBadRequest},
+		} {
+			t.Run(name, func(t *testing.T) {
+				server, mockCp := setup(t, nil)
+				rec := postJSON(t, server, "/api/v1/checkpoints/synthesize", tc.req)
+				assert.Equal(t, tc.want, rec.Code, rec.Body.String())
+				mockCp.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("rejected in read-only mode", func(t *testing.T) {
+		server, mockCp := setup(t, &Config{ReadOnly: readonly.New(true, "store migration")})
+
+		rec := postJSON(t, server, "/api/v1/checkpoints/synthesize", CheckpointSynthesizeRequest{ProjectPath: "/home/user/api"})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		mockCp.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// errorResponses is middleware that renders coded errors returned by a
+// handler, directly or from a service, as a status from the error registry
+// with a ctxerrors.Payload body. Errors that are already *echo.HTTPError, or
+// that are unclassified, are left to echo's default handling.
+func errorResponses(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		if !isClientVisible(err) {
+			return err
+		}
+		payload := ctxerrors.PayloadFor(err)
+		if payload.RetryAfterSeconds > 0 {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(payload.RetryAfterSeconds))
+		}
+		return echo.NewHTTPError(ctxerrors.HTTPStatus(err), payload).SetInternal(err)
+	}
+}
+
+// isClientVisible reports whether err carries a code worth returning to the
+// client. Internal errors are not; their messages may leak implementation
+// details.
+func isClientVisible(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return false
+	}
+	return ctxerrors.CodeOf(err) != ctxerrors.CodeInternal
+}
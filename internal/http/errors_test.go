@@ -0,0 +1,106 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+)
+
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   ctxerrors.Code
+		retryAfter string
+	}{
+		{"not found", fmt.Errorf("get: %w", ctxerrors.NotFound("memory not found")), http.StatusNotFound, ctxerrors.CodeNotFound, ""},
+		{"unauthorized", ctxerrors.Unauthorized("access denied"), http.StatusForbidden, ctxerrors.CodeUnauthorized, ""},
+		{"quota", ctxerrors.QuotaExceeded("rate limit exceeded", 1500*time.Millisecond), http.StatusTooManyRequests, ctxerrors.CodeQuotaExceeded, "2"},
+		{"dependency", ctxerrors.DependencyUnavailable("qdrant", errors.New("dial tcp: refused")), http.StatusServiceUnavailable, ctxerrors.CodeDependencyUnavailable, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(errorResponses)
+			e.GET("/", func(c echo.Context) error { return tt.err })
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			require.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.retryAfter, rec.Header().Get("Retry-After"))
+
+			var payload ctxerrors.Payload
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload))
+			assert.Equal(t, tt.wantCode, payload.Code)
+			assert.Equal(t, tt.err.Error(), payload.Message)
+			assert.Equal(t, ctxerrors.Retryable(tt.err), payload.Retryable)
+		})
+	}
+
+	t.Run("internal errors are not exposed", func(t *testing.T) {
+		e := echo.New()
+		e.Use(errorResponses)
+		e.GET("/", func(c echo.Context) error { return errors.New("open /var/lib/contextd: permission denied") })
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "permission denied")
+	})
+}
+
+func TestValidationErrors(t *testing.T) {
+	t.Run("handler limit", func(t *testing.T) {
+		server := setupTestServer(t)
+
+		rec := postJSON(t, server, "/api/v1/threshold", ThresholdRequest{
+			ProjectID: "tenant_456",
+			SessionID: "sess_123",
+			Percent:   70,
+			Summary:   strings.Repeat("x", MaxSummaryLength+1),
+		})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var payload ctxerrors.Payload
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload))
+		assert.Equal(t, ctxerrors.Code(validation.CodeContentTooLong), payload.Code)
+		assert.False(t, payload.Retryable)
+		assert.Contains(t, payload.Message, "summary exceeds maximum length")
+		assert.Equal(t, "summary", payload.Details["field"])
+		assert.Equal(t, float64(MaxSummaryLength), payload.Details["limit"])
+		assert.Equal(t, float64(MaxSummaryLength+1), payload.Details["actual"])
+	})
+
+	t.Run("service limit", func(t *testing.T) {
+		server, mockCp := setupCheckpointServer(t, nil)
+		registry := server.registry.(*mockRegistry)
+		registry.On("Memory").Return(nil)
+		registry.On("Remediation").Return(nil)
+		registry.On("Repository").Return(nil)
+
+		query := strings.Repeat("q", validation.Default.MaxQueryLength+1)
+		rec := postJSON(t, server, "/api/v1/knowledge/search", KnowledgeSearchRequest{Query: query})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var payload ctxerrors.Payload
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload))
+		assert.Equal(t, ctxerrors.Code(validation.CodeQueryTooLong), payload.Code)
+		assert.Equal(t, "query", payload.Details["field"])
+		assert.Equal(t, float64(validation.Default.MaxQueryLength+1), payload.Details["actual"])
+		mockCp.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+}
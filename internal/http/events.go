@@ -0,0 +1,209 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/folding"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultEventsInterval is how often GET /api/v1/events sends a snapshot.
+	DefaultEventsInterval = 2 * time.Second
+	// MinEventsInterval and MaxEventsInterval bound the interval parameter.
+	MinEventsInterval = time.Second
+	MaxEventsInterval = time.Minute
+
+	// recentCallsInSnapshot is how many tool calls a snapshot lists.
+	recentCallsInSnapshot = 20
+)
+
+// BranchLister lists the unfinished context-folding branches.
+type BranchLister interface {
+	ListActive(ctx context.Context) ([]*folding.Branch, error)
+}
+
+// EmbeddingQueue reports how many embedding requests are queued or running.
+type EmbeddingQueue interface {
+	Depth() int64
+}
+
+// LiveSnapshot is the data of a "snapshot" event on GET /api/v1/events.
+// RecentCalls, Collections and EmbeddingQueue are omitted when their source
+// is not configured.
+type LiveSnapshot struct {
+	Time           time.Time         `json:"time"`
+	Status         StatusResponse    `json:"status"`
+	Sessions       []SessionActivity `json:"sessions"`
+	RecentCalls    []RecentToolCall  `json:"recent_calls,omitempty"`
+	Collections    []CollectionSize  `json:"collections,omitempty"`
+	EmbeddingQueue *int64            `json:"embedding_queue,omitempty"`
+}
+
+// SessionActivity is a session with unfinished branches.
+type SessionActivity struct {
+	SessionID string           `json:"session_id"`
+	ProjectID string           `json:"project_id,omitempty"`
+	Branches  []BranchActivity `json:"branches"`
+}
+
+// BranchActivity is an unfinished context-folding branch.
+type BranchActivity struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	Depth       int       `json:"depth"`
+	BudgetUsed  int       `json:"budget_used"`
+	BudgetTotal int       `json:"budget_total"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RecentToolCall is one recent MCP tool call.
+type RecentToolCall struct {
+	Tool       string    `json:"tool"`
+	TenantID   string    `json:"tenant_id,omitempty"`
+	ProjectID  string    `json:"project_id,omitempty"`
+	Time       time.Time `json:"time"`
+	DurationMs float64   `json:"duration_ms"`
+	Failed     bool      `json:"failed,omitempty"`
+}
+
+// CollectionSize is the number of vectors in a collection.
+type CollectionSize struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+}
+
+// handleEvents streams live server state as server-sent events: a
+// "snapshot" event right away and then every interval. Restricted to
+// localhost because snapshots name tenants, projects and sessions.
+//
+// Query parameters:
+//   - interval: time between snapshots, 1s-1m (default 2s)
+func (s *Server) handleEvents(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "events endpoint is restricted to localhost")
+	}
+
+	interval := DefaultEventsInterval
+	if raw := c.QueryParam("interval"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < MinEventsInterval || d > MaxEventsInterval {
+			return echo.NewHTTPError(http.StatusBadRequest, "interval must be a duration between 1s and 1m")
+		}
+		interval = d
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+	resp.Header().Set(echo.HeaderConnection, "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+
+	ctx := c.Request().Context()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		data, err := json.Marshal(s.snapshot(ctx))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(resp, "event: snapshot\ndata: %s\n\n", data); err != nil {
+			return nil // Client went away
+		}
+		resp.Flush()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stopping:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshot collects the current server state.
+func (s *Server) snapshot(ctx context.Context) LiveSnapshot {
+	snap := LiveSnapshot{
+		Time:     time.Now().UTC(),
+		Status:   s.status(ctx),
+		Sessions: []SessionActivity{},
+	}
+
+	if s.branches != nil {
+		branches, err := s.branches.ListActive(ctx)
+		if err != nil {
+			s.logger.Warn("failed to list active branches", zap.Error(err))
+		}
+		snap.Sessions = groupBranches(branches)
+	}
+
+	if s.analytics != nil {
+		for _, call := range s.analytics.Recent(recentCallsInSnapshot) {
+			snap.RecentCalls = append(snap.RecentCalls, RecentToolCall{
+				Tool:       call.Tool,
+				TenantID:   call.TenantID,
+				ProjectID:  call.ProjectID,
+				Time:       call.Time.UTC(),
+				DurationMs: float64(call.Duration.Microseconds()) / 1000,
+				Failed:     call.Failed,
+			})
+		}
+	}
+
+	if store := s.registry.VectorStore(); store != nil {
+		names, err := store.ListCollections(ctx)
+		if err != nil {
+			s.logger.Warn("failed to list collections", zap.Error(err))
+		}
+		for _, name := range names {
+			info, err := store.GetCollectionInfo(ctx, name)
+			if err != nil || info == nil {
+				continue
+			}
+			snap.Collections = append(snap.Collections, CollectionSize{Name: name, Points: info.PointCount})
+		}
+		sort.Slice(snap.Collections, func(i, j int) bool {
+			if snap.Collections[i].Points != snap.Collections[j].Points {
+				return snap.Collections[i].Points > snap.Collections[j].Points
+			}
+			return snap.Collections[i].Name < snap.Collections[j].Name
+		})
+	}
+
+	if s.embeddingQueue != nil {
+		depth := s.embeddingQueue.Depth()
+		snap.EmbeddingQueue = &depth
+	}
+	return snap
+}
+
+// groupBranches groups branches by session, keeping their order.
+func groupBranches(branches []*folding.Branch) []SessionActivity {
+	sessions := []SessionActivity{}
+	index := make(map[string]int)
+	for _, b := range branches {
+		i, ok := index[b.SessionID]
+		if !ok {
+			i = len(sessions)
+			index[b.SessionID] = i
+			sessions = append(sessions, SessionActivity{SessionID: b.SessionID, ProjectID: b.ProjectID})
+		}
+		sessions[i].Branches = append(sessions[i].Branches, BranchActivity{
+			ID:          b.ID,
+			Description: b.Description,
+			Depth:       b.Depth,
+			BudgetUsed:  b.BudgetUsed,
+			BudgetTotal: b.BudgetTotal,
+			CreatedAt:   b.CreatedAt.UTC(),
+		})
+	}
+	return sessions
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/folding"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+type fakeBranchLister []*folding.Branch
+
+func (f fakeBranchLister) ListActive(ctx context.Context) ([]*folding.Branch, error) {
+	return f, nil
+}
+
+type fakeEmbeddingQueue int64
+
+func (f fakeEmbeddingQueue) Depth() int64 { return int64(f) }
+
+// newEventsRegistry returns a registry with only a vector store.
+func newEventsRegistry(store vectorstore.Store) *mockRegistry {
+	registry := &mockRegistry{}
+	for _, name := range []string{"Checkpoint", "Memory", "Remediation", "Repository", "Troubleshoot", "Scrubber", "Compression"} {
+		registry.On(name).Return(nil).Maybe()
+	}
+	registry.On("VectorStore").Return(store).Maybe()
+	return registry
+}
+
+// readSnapshot reads the next snapshot event from an event stream.
+func readSnapshot(t *testing.T, r *bufio.Reader) LiveSnapshot {
+	t.Helper()
+	var event, data string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			break
+		}
+		if v, ok := strings.CutPrefix(line, "event: "); ok {
+			event = v
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			data = v
+		}
+	}
+	require.Equal(t, "snapshot", event)
+
+	var snap LiveSnapshot
+	require.NoError(t, json.Unmarshal([]byte(data), &snap))
+	return snap
+}
+
+func TestEvents(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.CreateCollection(context.Background(), "acme_api_memories", 8))
+
+	tracker, err := analytics.NewTracker(analytics.Config{Dir: t.TempDir()}, zap.NewNop())
+	require.NoError(t, err)
+	tracker.Record(analytics.Call{Tool: "memory_search", ProjectID: "api", Duration: 1500 * time.Microsecond})
+	tracker.Record(analytics.Call{Tool: "checkpoint_save", ProjectID: "api", Failed: true})
+
+	created := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	server, err := NewServer(newEventsRegistry(store), zap.NewNop(), &Config{
+		Version:   "1.2.3",
+		Analytics: tracker,
+		Branches: fakeBranchLister{
+			{ID: "br_1", SessionID: "sess_a", ProjectID: "api", Description: "find config", BudgetTotal: 8192, CreatedAt: created},
+			{ID: "br_2", SessionID: "sess_b", Description: "read logs", CreatedAt: created},
+			{ID: "br_3", SessionID: "sess_a", Description: "nested", Depth: 1, CreatedAt: created},
+		},
+		EmbeddingQueue: fakeEmbeddingQueue(3),
+	})
+	require.NoError(t, err)
+
+	t.Run("streams snapshots", func(t *testing.T) {
+		ts := httptest.NewServer(server.echo)
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/v1/events?interval=1s", nil)
+		require.NoError(t, err)
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		r := bufio.NewReader(resp.Body)
+		snap := readSnapshot(t, r)
+		assert.Equal(t, "1.2.3", snap.Status.Version)
+		assert.Equal(t, "unavailable", snap.Status.Services["memory"])
+
+		require.Len(t, snap.Sessions, 2)
+		assert.Equal(t, "sess_a", snap.Sessions[0].SessionID)
+		assert.Equal(t, "api", snap.Sessions[0].ProjectID)
+		require.Len(t, snap.Sessions[0].Branches, 2)
+		assert.Equal(t, "br_3", snap.Sessions[0].Branches[1].ID)
+
+		require.Len(t, snap.RecentCalls, 2)
+		assert.Equal(t, "checkpoint_save", snap.RecentCalls[0].Tool, "newest first")
+		assert.True(t, snap.RecentCalls[0].Failed)
+		assert.InDelta(t, 1.5, snap.RecentCalls[1].DurationMs, 1e-9)
+
+		assert.Equal(t, []CollectionSize{{Name: "acme_api_memories", Points: 0}}, snap.Collections)
+		require.NotNil(t, snap.EmbeddingQueue)
+		assert.Equal(t, int64(3), *snap.EmbeddingQueue)
+
+		tracker.Record(analytics.Call{Tool: "memory_record"})
+		snap = readSnapshot(t, r)
+		assert.Equal(t, "memory_record", snap.RecentCalls[0].Tool)
+	})
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		for _, interval := range []string{"fast", "100ms", "2h"} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/events?interval="+interval, nil)
+			req.RemoteAddr = "127.0.0.1:1234"
+			rec := httptest.NewRecorder()
+			server.echo.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, interval)
+		}
+	})
+}
+
+func TestEvents_EndOnShutdown(t *testing.T) {
+	server, err := NewServer(newEventsRegistry(nil), zap.NewNop(), &Config{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(server.echo)
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL + "/api/v1/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+	snap := readSnapshot(t, r)
+	assert.Empty(t, snap.Sessions)
+	assert.Nil(t, snap.EmbeddingQueue)
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	_, err = r.ReadString('\n')
+	assert.Error(t, err, "stream ends when the server shuts down")
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/fyrsmithlabs/contextd/internal/handoff"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// HandoffExportRequest is the request body for POST /api/v1/handoff/export.
+type HandoffExportRequest struct {
+	CheckpointID     string   `json:"checkpoint_id"`
+	ProjectPath      string   `json:"project_path"`
+	TenantID         string   `json:"tenant_id,omitempty"`
+	To               string   `json:"to"`
+	From             string   `json:"from,omitempty"`
+	Note             string   `json:"note,omitempty"`
+	MemoryIDs        []string `json:"memory_ids,omitempty"`
+	MemoryLimit      int      `json:"memory_limit,omitempty"`
+	RemediationLimit int      `json:"remediation_limit,omitempty"`
+}
+
+// HandoffImportRequest is the request body for POST /api/v1/handoff/import.
+type HandoffImportRequest struct {
+	Bundle      *handoff.Bundle `json:"bundle"`
+	ProjectPath string          `json:"project_path"`
+	TenantID    string          `json:"tenant_id,omitempty"`
+	SessionID   string          `json:"session_id,omitempty"`
+	Recipient   string          `json:"recipient,omitempty"`
+}
+
+// handleHandoffExport packages a checkpoint, the memories its session used,
+// open remediation drafts and a state-of-the-work summary into a bundle
+// addressed to another user or agent.
+func (s *Server) handleHandoffExport(c echo.Context) error {
+	var req HandoffExportRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.CheckpointID == "" || req.ProjectPath == "" || req.To == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "checkpoint_id, project_path and to fields are required")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	projectID, err := projectIDFromPath(validPath)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_path")
+	}
+
+	if s.registry.Checkpoint() == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "checkpoint service unavailable")
+	}
+
+	bundle, err := s.handoffService().Export(c.Request().Context(), handoff.ExportRequest{
+		CheckpointID:     req.CheckpointID,
+		TenantID:         tenantID,
+		ProjectID:        projectID,
+		ProjectPath:      validPath,
+		To:               req.To,
+		From:             req.From,
+		Note:             req.Note,
+		MemoryIDs:        req.MemoryIDs,
+		MemoryLimit:      req.MemoryLimit,
+		RemediationLimit: req.RemediationLimit,
+	})
+	if isClientVisible(err) {
+		return err
+	}
+	if err != nil {
+		s.logger.Error("handoff export failed", zap.String("checkpoint_id", req.CheckpointID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "handoff export failed")
+	}
+
+	return c.JSON(http.StatusOK, bundle)
+}
+
+// handleHandoffImport reconstructs a handoff bundle under the caller's
+// tenant and project. Memories and remediations that fail to import are
+// listed in the result's warnings.
+func (s *Server) handleHandoffImport(c echo.Context) error {
+	var req HandoffImportRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Bundle == nil || req.ProjectPath == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bundle and project_path fields are required")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	projectID, err := projectIDFromPath(validPath)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_path")
+	}
+
+	if err := s.readOnly.Check("handoff import"); err != nil {
+		return err
+	}
+	if s.registry.Checkpoint() == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "checkpoint service unavailable")
+	}
+
+	result, err := s.handoffService().Import(c.Request().Context(), handoff.ImportRequest{
+		Bundle:      req.Bundle,
+		TenantID:    tenantID,
+		ProjectID:   projectID,
+		ProjectPath: validPath,
+		SessionID:   req.SessionID,
+		Recipient:   req.Recipient,
+	})
+	if isClientVisible(err) {
+		return err
+	}
+	if err != nil {
+		s.logger.Error("handoff import failed", zap.String("handoff_id", req.Bundle.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "handoff import failed")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) handoffService() *handoff.Service {
+	return handoff.NewService(s.registry.Memory(), s.registry.Checkpoint(), s.registry.Remediation(),
+		s.analytics, s.registry.Scrubber(), s.logger)
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/handoff"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+)
+
+func TestHandleHandoff(t *testing.T) {
+	setup := func(t *testing.T, cfg *Config) (*Server, *mockCheckpointService) {
+		t.Helper()
+		scrubber, err := secrets.New(nil)
+		require.NoError(t, err)
+
+		mockCp := &mockCheckpointService{}
+		registry := &mockRegistry{}
+		registry.On("Scrubber").Return(scrubber)
+		registry.On("Checkpoint").Return(mockCp)
+		registry.On("Memory").Return(nil)
+		registry.On("Remediation").Return(nil)
+
+		server, err := NewServer(registry, zap.NewNop(), cfg)
+		require.NoError(t, err)
+		return server, mockCp
+	}
+
+	t.Run("exports a checkpoint in the project scope", func(t *testing.T) {
+		server, mockCp := setup(t, nil)
+		mockCp.On("Get", mock.Anything, "acme", "", "api", "cp-1").Return(&checkpoint.Checkpoint{
+			ID:      "cp-1",
+			Name:    "Upload retries",
+			Summary: "Retries done, timeout open",
+		}, nil)
+
+		rec := postJSON(t, server, "/api/v1/handoff/export", HandoffExportRequest{
+			CheckpointID: "cp-1",
+			ProjectPath:  "/home/user/api",
+			TenantID:     "acme",
+			To:           "bob",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var bundle handoff.Bundle
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bundle))
+		assert.Equal(t, "bob", bundle.To)
+		assert.Equal(t, "acme", bundle.SourceTenantID)
+		assert.Equal(t, "cp-1", bundle.Checkpoint.ID)
+		assert.Contains(t, bundle.Summary, "Retries done, timeout open")
+		assert.Len(t, bundle.Warnings, 2, "memory and remediation services are unavailable")
+	})
+
+	t.Run("export of unknown checkpoint", func(t *testing.T) {
+		server, mockCp := setup(t, nil)
+		mockCp.On("Get", mock.Anything, "acme", "", "api", "missing").Return(nil, checkpoint.ErrNotFound)
+
+		rec := postJSON(t, server, "/api/v1/handoff/export", HandoffExportRequest{
+			CheckpointID: "missing",
+			ProjectPath:  "/home/user/api",
+			TenantID:     "acme",
+			To:           "bob",
+		})
+		assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+	})
+
+	t.Run("export requires a recipient", func(t *testing.T) {
+		server, _ := setup(t, nil)
+		rec := postJSON(t, server, "/api/v1/handoff/export", HandoffExportRequest{
+			CheckpointID: "cp-1",
+			ProjectPath:  "/home/user/api",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "checkpoint_id, project_path and to fields are required")
+	})
+
+	bundle := &handoff.Bundle{
+		Version:    handoff.FormatVersion,
+		ID:         "h-1",
+		To:         "bob",
+		Summary:    "State of the work",
+		Checkpoint: &handoff.Checkpoint{ID: "cp-1", Name: "Upload retries", Summary: "Retries done"},
+	}
+
+	t.Run("imports into the caller's tenant", func(t *testing.T) {
+		server, mockCp := setup(t, nil)
+		mockCp.On("Save", mock.Anything, mock.MatchedBy(func(req *checkpoint.SaveRequest) bool {
+			return req.TenantID == "bob_tenant" &&
+				req.ProjectID == "web" &&
+				req.Metadata["handoff_id"] == "h-1"
+		})).Return(&checkpoint.Checkpoint{ID: "cp-imported"}, nil)
+
+		rec := postJSON(t, server, "/api/v1/handoff/import", HandoffImportRequest{
+			Bundle:      bundle,
+			ProjectPath: "/home/bob/web",
+			TenantID:    "bob_tenant",
+			Recipient:   "bob",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var result handoff.ImportResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, "h-1", result.HandoffID)
+		assert.Equal(t, "cp-imported", result.CheckpointID)
+	})
+
+	t.Run("import addressed to someone else", func(t *testing.T) {
+		server, mockCp := setup(t, nil)
+		rec := postJSON(t, server, "/api/v1/handoff/import", HandoffImportRequest{
+			Bundle:      bundle,
+			ProjectPath: "/home/carol/web",
+			TenantID:    "carol_tenant",
+			Recipient:   "carol",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		mockCp.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("import rejected in read-only mode", func(t *testing.T) {
+		server, mockCp := setup(t, &Config{ReadOnly: readonly.New(true, "store migration")})
+		rec := postJSON(t, server, "/api/v1/handoff/import", HandoffImportRequest{
+			Bundle:      bundle,
+			ProjectPath: "/home/bob/web",
+			TenantID:    "bob_tenant",
+		})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		mockCp.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
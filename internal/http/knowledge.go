@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/fyrsmithlabs/contextd/internal/knowledge"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// KnowledgeSearchRequest is the request body for POST /api/v1/knowledge/search.
+type KnowledgeSearchRequest struct {
+	Query       string   `json:"query"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	ProjectPath string   `json:"project_path,omitempty"`
+	TenantID    string   `json:"tenant_id,omitempty"`
+	TeamID      string   `json:"team_id,omitempty"`
+	Types       []string `json:"types,omitempty"`
+	Limit       int      `json:"limit,omitempty"`
+}
+
+// handleKnowledgeSearch searches every knowledge type and returns one ranked
+// list. Conversations are only searchable over MCP, so they are reported as
+// unavailable here.
+func (s *Server) handleKnowledgeSearch(c echo.Context) error {
+	var req KnowledgeSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "query field is required")
+	}
+	if err := sanitize.ValidateTeamID(req.TeamID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team_id")
+	}
+	types, err := knowledge.ParseTypes(req.Types)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	searchReq := knowledge.Request{
+		Query:     req.Query,
+		ProjectID: req.ProjectID,
+		TeamID:    req.TeamID,
+		Types:     types,
+		Limit:     req.Limit,
+	}
+	if err := scopeKnowledgeRequest(&searchReq, req.ProjectPath, req.TenantID); err != nil {
+		return err
+	}
+
+	resp, err := s.knowledgeSearcher().Search(c.Request().Context(), searchReq)
+	switch {
+	case isClientVisible(err):
+		return err
+	case err != nil:
+		s.logger.Error("knowledge search failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "knowledge search failed")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// scopeKnowledgeRequest resolves the tenant and project of a knowledge
+// search. Everything but memories is tenant-scoped; without a tenant those
+// types are reported as warnings.
+func scopeKnowledgeRequest(searchReq *knowledge.Request, projectPath, tenantID string) error {
+	if projectPath != "" || tenantID != "" {
+		validPath, resolvedTenant, err := resolveTenant(projectPath, tenantID)
+		if err != nil {
+			return err
+		}
+		searchReq.ProjectPath = validPath
+		searchReq.TenantID = resolvedTenant
+		if searchReq.ProjectID == "" && validPath != "" {
+			if searchReq.ProjectID, err = projectIDFromPath(validPath); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid project_path")
+			}
+		}
+	}
+	if searchReq.ProjectID != "" {
+		if err := sanitize.ValidateProjectID(searchReq.ProjectID); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid project_id")
+		}
+	}
+	return nil
+}
+
+// knowledgeSearcher returns a federated searcher over the registry's
+// services.
+func (s *Server) knowledgeSearcher() *knowledge.Searcher {
+	sources := knowledge.Sources{
+		Memory:       s.registry.Memory(),
+		Remediations: s.registry.Remediation(),
+		Checkpoints:  s.registry.Checkpoint(),
+	}
+	// Avoid wrapping a nil *repository.Service in a non-nil interface
+	if repo := s.registry.Repository(); repo != nil {
+		sources.Code = repo
+	}
+	return knowledge.NewSearcher(sources, s.registry.Scrubber(), s.logger)
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/knowledge"
+)
+
+func TestHandleKnowledgeSearch(t *testing.T) {
+	t.Run("merges available sources and warns for the rest", func(t *testing.T) {
+		server, mockCp := setupCheckpointServer(t, nil)
+		registry := server.registry.(*mockRegistry)
+		registry.On("Memory").Return(nil)
+		registry.On("Remediation").Return(nil)
+		registry.On("Repository").Return(nil)
+
+		mockCp.On("Search", mock.Anything, mock.MatchedBy(func(req *checkpoint.SearchRequest) bool {
+			return req.Query == "token refresh" &&
+				req.TenantID == "acme" &&
+				req.ProjectID == "api" &&
+				req.ProjectPath == "/home/user/api"
+		})).Return([]*checkpoint.ScoredCheckpoint{
+			{Checkpoint: &checkpoint.Checkpoint{ID: "cp-1", Name: "Auth refactor", Summary: "moved refresh"}, Score: 0.8},
+		}, nil)
+
+		rec := postJSON(t, server, "/api/v1/knowledge/search", KnowledgeSearchRequest{
+			Query:       "token refresh",
+			ProjectPath: "/home/user/api",
+			TenantID:    "acme",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp knowledge.Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, knowledge.TypeCheckpoint, resp.Results[0].Type)
+		assert.Equal(t, "cp-1", resp.Results[0].ID)
+		assert.Equal(t, []string{
+			"code: repository service unavailable",
+			"conversation: conversation service unavailable",
+			"memory: memory service unavailable",
+			"remediation: remediation service unavailable",
+		}, resp.Warnings)
+		mockCp.AssertExpectations(t)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		server, mockCp := setupCheckpointServer(t, nil)
+
+		rec := postJSON(t, server, "/api/v1/knowledge/search", KnowledgeSearchRequest{TenantID: "acme"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "query field is required")
+
+		rec = postJSON(t, server, "/api/v1/knowledge/search", KnowledgeSearchRequest{Query: "q", Types: []string{"email"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid knowledge type")
+
+		rec = postJSON(t, server, "/api/v1/knowledge/search", KnowledgeSearchRequest{Query: "q", TenantID: "../bad"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid tenant_id")
+
+		mockCp.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+}
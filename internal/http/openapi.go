@@ -0,0 +1,213 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/bootstrap"
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
+	"github.com/fyrsmithlabs/contextd/internal/handoff"
+	"github.com/fyrsmithlabs/contextd/internal/knowledge"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+)
+
+// apiOperation describes a public endpoint for the OpenAPI document. Its
+// schemas are generated from the request and response types the handler
+// binds and returns.
+type apiOperation struct {
+	Method      string
+	Path        string // echo path, e.g. /api/v1/checkpoints/:id
+	OperationID string
+	Tag         string
+	Summary     string
+	Request     reflect.Type // nil when the operation takes no body
+	Response    reflect.Type
+	Status      int // success status (default: 200)
+}
+
+// apiOperations is the public REST surface, in document order. Every route
+// registered by registerRoutes is either listed here or in
+// openAPIExcluded; TestOpenAPI_CoversRoutes keeps them in sync.
+var apiOperations = []apiOperation{
+	{http.MethodPost, "/api/v1/memories/search", "searchMemories", "memories",
+		"Search a project's memories", reflect.TypeFor[MemorySearchRequest](), reflect.TypeFor[MemorySearchResponse](), 0},
+	{http.MethodPost, "/api/v1/remediations/search", "searchRemediations", "remediations",
+		"Search remediations for an error", reflect.TypeFor[RemediationSearchRequest](), reflect.TypeFor[RemediationSearchResponse](), 0},
+	{http.MethodPost, "/api/v1/remediations/categories", "getRemediationCategories", "remediations",
+		"Count remediations per category of the tenant's taxonomy", reflect.TypeFor[RemediationCategoriesRequest](), reflect.TypeFor[RemediationCategoriesResponse](), 0},
+	{http.MethodPost, "/api/v1/remediations/categories/migrate", "migrateRemediationCategory", "remediations",
+		"Rename or split a remediation category", reflect.TypeFor[RemediationCategoryMigrateRequest](), reflect.TypeFor[remediation.MigrateCategoryResult](), 0},
+	{http.MethodPost, "/api/v1/repository/search", "searchRepository", "repository",
+		"Search an indexed repository", reflect.TypeFor[RepositorySearchRequest](), reflect.TypeFor[RepositorySearchResponse](), 0},
+	{http.MethodPost, "/api/v1/knowledge/search", "searchKnowledge", "knowledge",
+		"Search every knowledge type in one ranked list", reflect.TypeFor[KnowledgeSearchRequest](), reflect.TypeFor[knowledge.Response](), 0},
+	{http.MethodPost, "/api/v1/retrieve", "retrieve", "knowledge",
+		"Retrieve documents for a RAG pipeline from memories and indexed code", reflect.TypeFor[RetrieveRequest](), reflect.TypeFor[RetrieveResponse](), 0},
+	{http.MethodPost, "/api/v1/session/bootstrap", "bootstrapSession", "sessions",
+		"Get the context to start a session on a task with", reflect.TypeFor[SessionBootstrapRequest](), reflect.TypeFor[bootstrap.Bundle](), 0},
+	{http.MethodPost, "/api/v1/handoff/export", "exportHandoff", "sessions",
+		"Package a checkpoint and its knowledge for another user or agent", reflect.TypeFor[HandoffExportRequest](), reflect.TypeFor[handoff.Bundle](), 0},
+	{http.MethodPost, "/api/v1/handoff/import", "importHandoff", "sessions",
+		"Import a handoff bundle into the caller's project", reflect.TypeFor[HandoffImportRequest](), reflect.TypeFor[handoff.ImportResult](), 0},
+	{http.MethodPost, "/api/v1/threshold", "saveThresholdCheckpoint", "checkpoints",
+		"Save a checkpoint when a session reaches a context threshold", reflect.TypeFor[ThresholdRequest](), reflect.TypeFor[ThresholdResponse](), 0},
+	{http.MethodPatch, "/api/v1/checkpoints/:id", "annotateCheckpoint", "checkpoints",
+		"Record how a checkpoint's work turned out", reflect.TypeFor[CheckpointAnnotateRequest](), reflect.TypeFor[CheckpointAnnotateResponse](), 0},
+	{http.MethodPost, "/api/v1/checkpoints/synthesize", "synthesizeCheckpoint", "checkpoints",
+		"Create a checkpoint from a conversation that was never saved", reflect.TypeFor[CheckpointSynthesizeRequest](), reflect.TypeFor[CheckpointSynthesizeResponse](), 0},
+	{http.MethodPost, "/api/v1/scrub", "scrubSecrets", "utility",
+		"Redact secrets from text", reflect.TypeFor[ScrubRequest](), reflect.TypeFor[ScrubResponse](), 0},
+	{http.MethodPost, "/api/v1/workflows/repository-index", "startRepositoryIndex", "workflows",
+		"Start a durable repository indexing run", reflect.TypeFor[RepositoryIndexWorkflowRequest](), reflect.TypeFor[WorkflowStartResponse](), http.StatusAccepted},
+	{http.MethodPost, "/api/v1/workflows/memory-consolidation", "startMemoryConsolidation", "workflows",
+		"Start a durable memory consolidation run", reflect.TypeFor[MemoryConsolidationWorkflowRequest](), reflect.TypeFor[WorkflowStartResponse](), http.StatusAccepted},
+	{http.MethodGet, "/api/v1/capabilities", "getCapabilities", "utility",
+		"Get the server version, features, tool schema versions and deprecations", nil, reflect.TypeFor[capabilities.Capabilities](), 0},
+	{http.MethodGet, "/api/v1/status", "getStatus", "utility",
+		"Get service availability and counts", nil, reflect.TypeFor[StatusResponse](), 0},
+	{http.MethodGet, "/health", "getHealth", "utility",
+		"Check server health", nil, reflect.TypeFor[HealthResponse](), 0},
+}
+
+// openAPIExcluded lists registered routes left out of the document:
+// operator endpoints that only answer localhost or the admin token, the
+// event stream, Prometheus metrics and the document itself.
+var openAPIExcluded = map[string]bool{
+	"GET /metrics":                       true,
+	"GET /debug/pprof/cmdline":           true,
+	"GET /debug/pprof/profile":           true,
+	"GET /debug/pprof/symbol":            true,
+	"POST /debug/pprof/symbol":           true,
+	"GET /debug/pprof/trace":             true,
+	"GET /debug/pprof/*":                 true,
+	"GET /api/v1/openapi.json":           true,
+	"GET /api/v1/events":                 true,
+	"GET /api/v1/health/metadata":        true,
+	"GET /api/v1/health/embeddings":      true,
+	"GET /api/v1/admin/read-only":        true,
+	"PUT /api/v1/admin/read-only":        true,
+	"GET /api/v1/admin/verify":           true,
+	"POST /api/v1/admin/verify/repair":   true,
+	"POST /api/v1/admin/scoring/explain": true,
+	"GET /api/v1/stats/tools":            true,
+	"GET /api/v1/stats/usage":            true,
+	"GET /api/v1/stats/experiment":       true,
+	"GET /api/v1/stats/sessions/:id":     true,
+}
+
+// errorSchema is the body of every error response.
+var errorSchema = &jsonschema.Schema{
+	Type: "object",
+	Properties: map[string]*jsonschema.Schema{
+		"message": {Type: "string", Description: "what went wrong"},
+	},
+}
+
+// OpenAPI returns the OpenAPI 3.1 document of the public HTTP API for the
+// given server version.
+func OpenAPI(version string) (map[string]any, error) {
+	if version == "" {
+		version = "dev"
+	}
+	paths := make(map[string]any)
+	for _, op := range apiOperations {
+		path, params := openAPIPath(op.Path)
+		operation := map[string]any{
+			"operationId": op.OperationID,
+			"summary":     op.Summary,
+			"tags":        []string{op.Tag},
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if op.Request != nil {
+			schema, err := jsonschema.ForType(op.Request, &jsonschema.ForOptions{IgnoreInvalidTypes: true})
+			if err != nil {
+				return nil, fmt.Errorf("schema for %s request: %w", op.OperationID, err)
+			}
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schema}},
+			}
+		}
+		schema, err := jsonschema.ForType(op.Response, &jsonschema.ForOptions{IgnoreInvalidTypes: true})
+		if err != nil {
+			return nil, fmt.Errorf("schema for %s response: %w", op.OperationID, err)
+		}
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		operation["responses"] = map[string]any{
+			fmt.Sprint(status): map[string]any{
+				"description": http.StatusText(status),
+				"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+			},
+			"default": map[string]any{"$ref": "#/components/responses/Error"},
+		}
+
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "contextd HTTP API",
+			"version":     version,
+			"description": "Memories, checkpoints, remediations and search for AI agents. Localhost-only operator endpoints are not included.",
+		},
+		"servers": []any{map[string]any{"url": "http://localhost:9090"}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": map[string]any{"Error": errorSchema},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error",
+					"content": map[string]any{"application/json": map[string]any{
+						"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+					}},
+				},
+			},
+		},
+	}, nil
+}
+
+// openAPIPath converts an echo path to an OpenAPI path and its path
+// parameters.
+func openAPIPath(path string) (string, []any) {
+	segments := strings.Split(path, "/")
+	var params []any
+	for i, seg := range segments {
+		if name, ok := strings.CutPrefix(seg, ":"); ok {
+			segments[i] = "{" + name + "}"
+			params = append(params, map[string]any{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// handleOpenAPI serves the OpenAPI document of the public API.
+func (s *Server) handleOpenAPI(c echo.Context) error {
+	doc, err := OpenAPI(s.config.Version)
+	if err != nil {
+		s.logger.Error("failed to build OpenAPI document", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to build OpenAPI document")
+	}
+	return c.JSON(http.StatusOK, doc)
+}
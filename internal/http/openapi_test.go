@@ -0,0 +1,87 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{AdminToken: "secret"})
+	require.NoError(t, err)
+
+	documented := make(map[string]bool)
+	for _, op := range apiOperations {
+		documented[op.Method+" "+op.Path] = true
+	}
+	for _, r := range server.echo.Routes() {
+		key := r.Method + " " + r.Path
+		assert.True(t, documented[key] || openAPIExcluded[key],
+			"route %s is neither in apiOperations nor openAPIExcluded", key)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	doc, err := OpenAPI("1.2.3")
+	require.NoError(t, err)
+
+	// Round-trip through JSON to inspect the document as clients see it
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var got struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]struct {
+			OperationID string           `json:"operationId"`
+			Parameters  []map[string]any `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema struct {
+						Required   []string       `json:"required"`
+						Properties map[string]any `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]any `json:"responses"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, "3.1.0", got.OpenAPI)
+	assert.Equal(t, "1.2.3", got.Info.Version)
+	assert.Len(t, got.Paths, len(apiOperations))
+
+	search := got.Paths["/api/v1/memories/search"]["post"]
+	assert.Equal(t, "searchMemories", search.OperationID)
+	body := search.RequestBody.Content["application/json"].Schema
+	assert.ElementsMatch(t, []string{"project_id", "query"}, body.Required)
+	assert.Contains(t, body.Properties, "cursor")
+	assert.Contains(t, search.Responses, "200")
+
+	annotate := got.Paths["/api/v1/checkpoints/{id}"]["patch"]
+	require.Len(t, annotate.Parameters, 1)
+	assert.Equal(t, "id", annotate.Parameters[0]["name"])
+
+	assert.Contains(t, got.Paths["/api/v1/workflows/repository-index"]["post"].Responses, "202")
+}
+
+func TestHandleOpenAPI(t *testing.T) {
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Version: "1.2.3"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, "1.2.3", doc["info"].(map[string]any)["version"])
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// registerProfiling serves the net/http/pprof endpoints under /debug/pprof
+// when an admin token is configured. Profiles expose memory contents, so
+// unlike the other admin endpoints they are not opened to localhost alone.
+func (s *Server) registerProfiling() {
+	if s.config.AdminToken == "" {
+		return
+	}
+	for path, handler := range map[string]http.HandlerFunc{
+		"/debug/pprof/cmdline": pprof.Cmdline,
+		"/debug/pprof/profile": pprof.Profile,
+		"/debug/pprof/symbol":  pprof.Symbol,
+		"/debug/pprof/trace":   pprof.Trace,
+		// The index, and named profiles such as heap, goroutine and allocs
+		"/debug/pprof/*": pprof.Index,
+	} {
+		s.echo.GET(path, echo.WrapHandler(handler), s.requireAdminToken)
+	}
+	s.echo.POST("/debug/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)), s.requireAdminToken)
+}
+
+// requireAdminToken rejects requests without the admin bearer token.
+func (s *Server) requireAdminToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminToken)) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized, "admin token required")
+		}
+		return next(c)
+	}
+}
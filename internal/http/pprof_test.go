@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func pprofRequest(server *Server, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestProfiling(t *testing.T) {
+	t.Run("not served without an admin token", func(t *testing.T) {
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{})
+		require.NoError(t, err)
+
+		rec := pprofRequest(server, "/debug/pprof/heap", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{AdminToken: "s3cret"})
+	require.NoError(t, err)
+
+	t.Run("requires the admin token", func(t *testing.T) {
+		rec := pprofRequest(server, "/debug/pprof/heap", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = pprofRequest(server, "/debug/pprof/heap", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("heap profile", func(t *testing.T) {
+		rec := pprofRequest(server, "/debug/pprof/heap", "s3cret")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/octet-stream", rec.Header().Get("Content-Type"))
+		assert.NotZero(t, rec.Body.Len())
+	})
+
+	t.Run("index", func(t *testing.T) {
+		rec := pprofRequest(server, "/debug/pprof/", "s3cret")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "goroutine")
+	})
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// RemediationCategoriesRequest is the request body for
+// POST /api/v1/remediations/categories.
+type RemediationCategoriesRequest struct {
+	ProjectPath      string `json:"project_path,omitempty"`
+	TenantID         string `json:"tenant_id,omitempty"`
+	TeamID           string `json:"team_id,omitempty"`
+	Scope            string `json:"scope,omitempty"`
+	IncludeHierarchy bool   `json:"include_hierarchy,omitempty"`
+}
+
+// RemediationCategoriesResponse is the response body for
+// POST /api/v1/remediations/categories.
+type RemediationCategoriesResponse struct {
+	TenantID   string                      `json:"tenant_id"`
+	Categories []remediation.CategoryStats `json:"categories"`
+}
+
+// RemediationCategoryMigrateRequest is the request body for
+// POST /api/v1/remediations/categories/migrate.
+type RemediationCategoryMigrateRequest struct {
+	ProjectPath      string                  `json:"project_path,omitempty"`
+	TenantID         string                  `json:"tenant_id,omitempty"`
+	TeamID           string                  `json:"team_id,omitempty"`
+	Scope            string                  `json:"scope,omitempty"`
+	IncludeHierarchy bool                    `json:"include_hierarchy,omitempty"`
+	From             string                  `json:"from"`
+	To               string                  `json:"to,omitempty"`
+	Split            []remediation.SplitRule `json:"split,omitempty"`
+	DryRun           bool                    `json:"dry_run,omitempty"`
+}
+
+// handleRemediationCategories returns per-category remediation statistics,
+// including every category of the tenant's taxonomy.
+func (s *Server) handleRemediationCategories(c echo.Context) error {
+	var req RemediationCategoriesRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := sanitize.ValidateTeamID(req.TeamID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team_id")
+	}
+
+	remediationSvc := s.registry.Remediation()
+	if remediationSvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "remediation service unavailable")
+	}
+
+	ctx := vectorstore.ContextWithTenant(c.Request().Context(), &vectorstore.TenantInfo{
+		TenantID: tenantID,
+		TeamID:   req.TeamID,
+	})
+	stats, err := remediationSvc.CategoryStats(ctx, &remediation.CategoryStatsRequest{
+		TenantID:         tenantID,
+		TeamID:           req.TeamID,
+		ProjectPath:      validPath,
+		Scope:            remediation.Scope(req.Scope),
+		IncludeHierarchy: req.IncludeHierarchy,
+	})
+	if err != nil {
+		s.logger.Error("remediation category stats failed", zap.String("tenant_id", tenantID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "remediation category stats failed")
+	}
+
+	return c.JSON(http.StatusOK, RemediationCategoriesResponse{
+		TenantID:   tenantID,
+		Categories: stats,
+	})
+}
+
+// handleRemediationCategoryMigrate renames or splits a remediation category,
+// for example after the tenant's taxonomy renamed it.
+func (s *Server) handleRemediationCategoryMigrate(c echo.Context) error {
+	var req RemediationCategoryMigrateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.From == "" || (req.To == "" && len(req.Split) == 0) {
+		return echo.NewHTTPError(http.StatusBadRequest, "from and to or split fields are required")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := sanitize.ValidateTeamID(req.TeamID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team_id")
+	}
+
+	if !req.DryRun {
+		if err := s.readOnly.Check("remediation category migration"); err != nil {
+			return err
+		}
+	}
+	remediationSvc := s.registry.Remediation()
+	if remediationSvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "remediation service unavailable")
+	}
+
+	ctx := vectorstore.ContextWithTenant(c.Request().Context(), &vectorstore.TenantInfo{
+		TenantID: tenantID,
+		TeamID:   req.TeamID,
+	})
+	result, err := remediationSvc.MigrateCategory(ctx, &remediation.MigrateCategoryRequest{
+		TenantID:         tenantID,
+		TeamID:           req.TeamID,
+		ProjectPath:      validPath,
+		Scope:            remediation.Scope(req.Scope),
+		IncludeHierarchy: req.IncludeHierarchy,
+		From:             remediation.ErrorCategory(req.From),
+		To:               remediation.ErrorCategory(req.To),
+		Split:            req.Split,
+		DryRun:           req.DryRun,
+	})
+	if isClientVisible(err) {
+		return err
+	}
+	if err != nil {
+		s.logger.Error("remediation category migration failed",
+			zap.String("tenant_id", tenantID), zap.String("from", req.From), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "remediation category migration failed")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHandleRemediationCategories(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	tax, err := remediation.NewTaxonomy([]remediation.Category{
+		{Name: "timeout", Builtin: "runtime"},
+		{Name: "connectivity", Builtin: "runtime", Aliases: []remediation.ErrorCategory{"network"}},
+	})
+	require.NoError(t, err)
+	cfg := remediation.DefaultServiceConfig()
+	cfg.Taxonomies = map[string]*remediation.Taxonomy{"acme": tax}
+	remediationSvc, err := remediation.NewService(cfg, store, zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "acme"})
+	for _, title := range []string{"Upload timed out", "Connection refused"} {
+		_, err := remediationSvc.Record(ctx, &remediation.RecordRequest{
+			Title:     title,
+			Problem:   title,
+			RootCause: "cause",
+			Solution:  "fix",
+			Category:  "network",
+			Scope:     remediation.ScopeOrg,
+			TenantID:  "acme",
+		})
+		require.NoError(t, err)
+	}
+
+	scrubber, err := secrets.New(nil)
+	require.NoError(t, err)
+	registry := &mockRegistry{}
+	registry.On("Scrubber").Return(scrubber)
+	registry.On("Remediation").Return(remediationSvc)
+
+	server, err := NewServer(registry, zap.NewNop(), nil)
+	require.NoError(t, err)
+
+	categories := func(t *testing.T) map[remediation.ErrorCategory]int {
+		t.Helper()
+		rec := postJSON(t, server, "/api/v1/remediations/categories", RemediationCategoriesRequest{TenantID: "acme", Scope: "org"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp RemediationCategoriesResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		counts := make(map[remediation.ErrorCategory]int)
+		for _, c := range resp.Categories {
+			counts[c.Category] = c.Count
+		}
+		return counts
+	}
+
+	counts := categories(t)
+	assert.Equal(t, 2, counts["connectivity"], "aliases record under the canonical category")
+	assert.Contains(t, counts, remediation.ErrorCategory("timeout"))
+
+	t.Run("split", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/remediations/categories/migrate", RemediationCategoryMigrateRequest{
+			TenantID: "acme",
+			Scope:    "org",
+			From:     "connectivity",
+			Split:    []remediation.SplitRule{{Category: "timeout", Keywords: []string{"timed out"}}},
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var result remediation.MigrateCategoryResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		require.Len(t, result.Moves, 1)
+		assert.Equal(t, "Upload timed out", result.Moves[0].Title)
+		assert.Equal(t, 1, result.Unmatched)
+
+		counts := categories(t)
+		assert.Equal(t, 1, counts["connectivity"])
+		assert.Equal(t, 1, counts["timeout"])
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/remediations/categories/migrate", RemediationCategoryMigrateRequest{
+			TenantID: "acme",
+			Scope:    "org",
+			From:     "timeout",
+			To:       "dns",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unknown remediation category")
+	})
+
+	t.Run("missing target", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/remediations/categories/migrate", RemediationCategoryMigrateRequest{
+			TenantID: "acme",
+			From:     "timeout",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
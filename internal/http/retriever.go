@@ -0,0 +1,142 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fyrsmithlabs/contextd/internal/knowledge"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// defaultRetrieveK matches the default k of LangChain retrievers.
+const defaultRetrieveK = 4
+
+// retrieverSources maps the sources a retriever may query to the index
+// behind them.
+var retrieverSources = map[knowledge.Type]string{
+	knowledge.TypeMemory: "reasoningbank",
+	knowledge.TypeCode:   "repository",
+}
+
+// RetrieveRequest is the request body for POST /api/v1/retrieve.
+type RetrieveRequest struct {
+	Query       string `json:"query"`
+	K           int    `json:"k,omitempty"` // Documents to return (default 4, max 50)
+	ProjectID   string `json:"project_id,omitempty"`
+	ProjectPath string `json:"project_path,omitempty"`
+	TenantID    string `json:"tenant_id,omitempty"`
+	TeamID      string `json:"team_id,omitempty"`
+
+	// Sources restricts retrieval to "memory" (reasoningbank) or "code"
+	// (repository). Empty queries both.
+	Sources []string `json:"sources,omitempty"`
+
+	// ScoreThreshold drops documents scoring below it.
+	ScoreThreshold float64 `json:"score_threshold,omitempty"`
+}
+
+// RetrievedDocument is a document in the shape retriever interfaces expect:
+// text to put in a prompt plus metadata describing where it came from.
+type RetrievedDocument struct {
+	ID          string                 `json:"id"`
+	PageContent string                 `json:"page_content"`
+	Score       float64                `json:"score"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// RetrieveResponse is the response body for POST /api/v1/retrieve.
+type RetrieveResponse struct {
+	Documents []RetrievedDocument `json:"documents"`
+	Warnings  []string            `json:"warnings,omitempty"`
+}
+
+// handleRetrieve returns the k documents most relevant to a query from the
+// reasoningbank and repository indexes, for use as the retrieval layer of a
+// RAG pipeline.
+func (s *Server) handleRetrieve(c echo.Context) error {
+	var req RetrieveRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "query field is required")
+	}
+	if req.K < 0 || req.K > knowledge.MaxLimit {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("k must be between 1 and %d", knowledge.MaxLimit))
+	}
+	if req.K == 0 {
+		req.K = defaultRetrieveK
+	}
+	if err := sanitize.ValidateTeamID(req.TeamID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team_id")
+	}
+	types, err := knowledge.ParseTypes(req.Sources)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid source: must be memory or code")
+	}
+	for _, t := range types {
+		if _, ok := retrieverSources[t]; !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid source %q: must be memory or code", t))
+		}
+	}
+	if len(types) == 0 {
+		types = []knowledge.Type{knowledge.TypeMemory, knowledge.TypeCode}
+	}
+
+	searchReq := knowledge.Request{
+		Query:          req.Query,
+		ProjectID:      req.ProjectID,
+		TeamID:         req.TeamID,
+		Types:          types,
+		Limit:          req.K,
+		IncludeContent: true,
+	}
+	if err := scopeKnowledgeRequest(&searchReq, req.ProjectPath, req.TenantID); err != nil {
+		return err
+	}
+
+	resp, err := s.knowledgeSearcher().Search(c.Request().Context(), searchReq)
+	switch {
+	case isClientVisible(err):
+		return err
+	case err != nil:
+		s.logger.Error("retrieve failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "retrieve failed")
+	}
+
+	docs := make([]RetrievedDocument, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.Score < req.ScoreThreshold {
+			continue
+		}
+		docs = append(docs, retrievedDocument(r))
+	}
+	return c.JSON(http.StatusOK, RetrieveResponse{Documents: docs, Warnings: resp.Warnings})
+}
+
+// retrievedDocument converts a knowledge result to a retriever document.
+// The result's own metadata is kept; the keys added here take precedence.
+func retrievedDocument(r knowledge.Result) RetrievedDocument {
+	metadata := make(map[string]interface{}, len(r.Metadata)+5)
+	for k, v := range r.Metadata {
+		metadata[k] = v
+	}
+	metadata["source"] = retrieverSources[r.Type]
+	metadata["type"] = string(r.Type)
+	metadata["title"] = r.Title
+	metadata["score"] = r.Score
+	metadata["similarity"] = r.Similarity
+
+	content := r.Content
+	if content == "" {
+		content = r.Snippet
+	}
+	return RetrievedDocument{
+		ID:          r.ID,
+		PageContent: content,
+		Score:       r.Score,
+		Metadata:    metadata,
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/knowledge"
+)
+
+func TestHandleRetrieve(t *testing.T) {
+	t.Run("warns for unavailable indexes", func(t *testing.T) {
+		registry := &mockRegistry{}
+		registry.On("Memory").Return(nil)
+		registry.On("Remediation").Return(nil)
+		registry.On("Checkpoint").Return(nil)
+		registry.On("Repository").Return(nil)
+		registry.On("Scrubber").Return(nil)
+		server, err := NewServer(registry, zap.NewNop(), nil)
+		require.NoError(t, err)
+
+		rec := postJSON(t, server, "/api/v1/retrieve", RetrieveRequest{
+			Query:       "token refresh",
+			ProjectPath: "/home/user/api",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp RetrieveResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Empty(t, resp.Documents)
+		assert.Equal(t, []string{
+			"code: repository service unavailable",
+			"memory: memory service unavailable",
+		}, resp.Warnings)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), nil)
+		require.NoError(t, err)
+
+		for name, tc := range map[string]struct {
+			req  RetrieveRequest
+			want string
+		}{
+			"missing query":  {RetrieveRequest{ProjectID: "api"}, "query field is required"},
+			"k too large":    {RetrieveRequest{Query: "q", K: 51}, "k must be between 1 and 50"},
+			"unknown source": {RetrieveRequest{Query: "q", Sources: []string{"email"}}, "invalid source"},
+			"other type":     {RetrieveRequest{Query: "q", Sources: []string{"checkpoint"}}, `invalid source \"checkpoint\"`},
+			"bad tenant":     {RetrieveRequest{Query: "q", TenantID: "../bad"}, "invalid tenant_id"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				rec := postJSON(t, server, "/api/v1/retrieve", tc.req)
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), tc.want)
+			})
+		}
+	})
+}
+
+func TestRetrievedDocument(t *testing.T) {
+	doc := retrievedDocument(knowledge.Result{
+		Type:       knowledge.TypeCode,
+		ID:         "auth/refresh.go",
+		Title:      "auth/refresh.go",
+		Snippet:    "func Refresh()...",
+		Content:    "func Refresh() error { return nil }",
+		Score:      0.68,
+		Similarity: 0.8,
+		Metadata:   map[string]interface{}{"file_path": "auth/refresh.go", "source": "ignored"},
+	})
+
+	assert.Equal(t, "auth/refresh.go", doc.ID)
+	assert.Equal(t, "func Refresh() error { return nil }", doc.PageContent)
+	assert.Equal(t, 0.68, doc.Score)
+	assert.Equal(t, "repository", doc.Metadata["source"])
+	assert.Equal(t, "code", doc.Metadata["type"])
+	assert.Equal(t, "auth/refresh.go", doc.Metadata["file_path"])
+	assert.Equal(t, 0.8, doc.Metadata["similarity"])
+
+	// Falls back to the snippet when content was not requested
+	doc = retrievedDocument(knowledge.Result{Type: knowledge.TypeMemory, ID: "m1", Snippet: "use middleware"})
+	assert.Equal(t, "use middleware", doc.PageContent)
+	assert.Equal(t, "reasoningbank", doc.Metadata["source"])
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// ScoreExplainRequest is the request body for POST
+// /api/v1/admin/scoring/explain.
+type ScoreExplainRequest struct {
+	ProjectID string   `json:"project_id"`
+	Query     string   `json:"query"`
+	MemoryID  string   `json:"memory_id"`
+	Languages []string `json:"languages,omitempty"`  // Optional language scope, as for memory search
+	Filter    string   `json:"filter,omitempty"`     // Optional filter expression, as for memory search
+	SessionID string   `json:"session_id,omitempty"` // Optional session, applies its experiment arm's ranking
+}
+
+// handleScoreExplain scores a query against one memory with the active
+// scoring pipeline and returns the breakdown, for tuning the weights and
+// boosts of the reasoningbank scoring config.
+func (s *Server) handleScoreExplain(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "admin endpoints are restricted to localhost")
+	}
+
+	var req ScoreExplainRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.ProjectID == "" || req.Query == "" || req.MemoryID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "project_id, query and memory_id fields are required")
+	}
+	if err := sanitize.ValidateProjectID(req.ProjectID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_id")
+	}
+	if _, err := uuid.Parse(req.MemoryID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid memory_id")
+	}
+
+	var filter *vectorstore.FilterExpr
+	if req.Filter != "" {
+		var err error
+		if filter, err = vectorstore.ParseFilterExpr(req.Filter); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid filter: "+err.Error())
+		}
+	}
+
+	memorySvc := s.registry.Memory()
+	if memorySvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "memory service unavailable")
+	}
+
+	// ProjectID serves as both tenant and project scope, matching memory_search
+	ctx := vectorstore.ContextWithTenant(c.Request().Context(), &vectorstore.TenantInfo{
+		TenantID:  req.ProjectID,
+		ProjectID: req.ProjectID,
+	})
+	ctx = reasoningbank.ContextWithLanguages(ctx, req.Languages...)
+	ctx = reasoningbank.ContextWithFilter(ctx, filter)
+	ctx = reasoningbank.ContextWithSession(ctx, req.SessionID)
+
+	explanation, err := memorySvc.ExplainScore(ctx, req.ProjectID, req.Query, req.MemoryID)
+	if err != nil {
+		return s.searchError("memory", err)
+	}
+	return c.JSON(http.StatusOK, explanation)
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestScoreExplain(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	scoring := reasoningbank.ScoringConfig{SimilarityWeight: 0.6, ConfidenceWeight: 0.4}
+	memorySvc, err := reasoningbank.NewService(store, zap.NewNop(),
+		reasoningbank.WithDefaultTenant("tune"),
+		reasoningbank.WithScoring(scoring))
+	require.NoError(t, err)
+
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "tune", ProjectID: "tune"})
+	mem, err := reasoningbank.NewMemory("tune", "Retry", "retry with backoff", reasoningbank.OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, memorySvc.Record(ctx, mem))
+
+	server := setupSearchServer(t, memorySvc)
+	request := func(remoteAddr string, body ScoreExplainRequest) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/scoring/explain", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := request("192.0.2.1:1234", ScoreExplainRequest{ProjectID: "tune", Query: "retry", MemoryID: mem.ID})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("explains the score", func(t *testing.T) {
+		rec := request("127.0.0.1:1234", ScoreExplainRequest{ProjectID: "tune", Query: "retry", MemoryID: mem.ID})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var explanation reasoningbank.ScoreExplanation
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &explanation))
+		assert.Equal(t, mem.ID, explanation.MemoryID)
+		assert.InDelta(t, 0.6*explanation.Similarity+0.4*explanation.Confidence, explanation.BaseScore, 1e-3)
+		assert.Equal(t, scoring.Effective(), explanation.Scoring)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, body := range []ScoreExplainRequest{
+			{ProjectID: "tune", Query: "retry"},
+			{ProjectID: "tune", Query: "retry", MemoryID: "not-a-uuid"},
+			{ProjectID: "../etc", Query: "retry", MemoryID: mem.ID},
+			{ProjectID: "tune", Query: "retry", MemoryID: mem.ID, Filter: "confidence >"},
+		} {
+			assert.Equal(t, http.StatusBadRequest, request("127.0.0.1:1234", body).Code, "%+v", body)
+		}
+	})
+
+	t.Run("unknown memory", func(t *testing.T) {
+		rec := request("127.0.0.1:1234", ScoreExplainRequest{ProjectID: "tune", Query: "retry", MemoryID: "00000000-0000-0000-0000-000000000000"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,342 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/tenant"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// MemorySearchRequest is the request body for POST /api/v1/memories/search.
+type MemorySearchRequest struct {
+	ProjectID string   `json:"project_id"`
+	Query     string   `json:"query"`
+	Languages []string `json:"languages,omitempty"` // Optional language scope, see reasoningbank.ContextWithLanguages
+	Filter    string   `json:"filter,omitempty"`    // Optional filter expression, see vectorstore.FilterExpr
+	Limit     int      `json:"limit,omitempty"`
+	Cursor    string   `json:"cursor,omitempty"`
+	SessionID string   `json:"session_id,omitempty"` // Optional session, assigns the search an experiment arm
+
+	// Inject also renders the page as a prompt-ready block, see
+	// reasoningbank.FormatInjection. MaxTokens caps the block.
+	Inject    bool `json:"inject,omitempty"`
+	MaxTokens int  `json:"max_tokens,omitempty"`
+}
+
+// MemorySearchHit is a single memory in a search response.
+type MemorySearchHit struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Outcome    string   `json:"outcome"`
+	Confidence float64  `json:"confidence"`
+	Relevance  float64  `json:"relevance"`
+	Tags       []string `json:"tags,omitempty"`
+	Languages  []string `json:"languages,omitempty"`
+
+	// Highlight is the best-matching chunk of a long memory.
+	Highlight string `json:"highlight,omitempty"`
+}
+
+// MemorySearchResponse is the response body for POST /api/v1/memories/search.
+type MemorySearchResponse struct {
+	Memories   []MemorySearchHit        `json:"memories"`
+	Count      int                      `json:"count"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	Injection  *reasoningbank.Injection `json:"injection,omitempty"` // Set when the request asks to inject
+}
+
+// RemediationSearchRequest is the request body for POST /api/v1/remediations/search.
+type RemediationSearchRequest struct {
+	Query            string  `json:"query"`
+	ProjectPath      string  `json:"project_path,omitempty"`
+	TenantID         string  `json:"tenant_id,omitempty"`
+	TeamID           string  `json:"team_id,omitempty"`
+	Scope            string  `json:"scope,omitempty"`
+	Category         string  `json:"category,omitempty"`
+	MinConfidence    float64 `json:"min_confidence,omitempty"`
+	IncludeHierarchy bool    `json:"include_hierarchy,omitempty"`
+	Limit            int     `json:"limit,omitempty"`
+	Cursor           string  `json:"cursor,omitempty"`
+}
+
+// RemediationSearchHit is a single remediation in a search response.
+type RemediationSearchHit struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Problem    string  `json:"problem"`
+	RootCause  string  `json:"root_cause"`
+	Solution   string  `json:"solution"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Score      float64 `json:"score"`
+}
+
+// RemediationSearchResponse is the response body for POST /api/v1/remediations/search.
+type RemediationSearchResponse struct {
+	Remediations []RemediationSearchHit `json:"remediations"`
+	Count        int                    `json:"count"`
+	NextCursor   string                 `json:"next_cursor,omitempty"`
+}
+
+// RepositorySearchRequest is the request body for POST /api/v1/repository/search.
+type RepositorySearchRequest struct {
+	Query       string `json:"query"`
+	ProjectPath string `json:"project_path"`
+	TenantID    string `json:"tenant_id,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	DocType     string `json:"doc_type,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+	Cursor      string `json:"cursor,omitempty"`
+}
+
+// RepositorySearchResponse is the response body for POST /api/v1/repository/search.
+// Content is omitted; use the MCP repository_search tool for file content.
+type RepositorySearchResponse struct {
+	Results    []repository.RepoSearchResult `json:"results"`
+	Count      int                           `json:"count"`
+	NextCursor string                        `json:"next_cursor,omitempty"`
+}
+
+// handleMemorySearch returns one page of memories for a project.
+func (s *Server) handleMemorySearch(c echo.Context) error {
+	var req MemorySearchRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.ProjectID == "" || req.Query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "project_id and query fields are required")
+	}
+	if err := sanitize.ValidateProjectID(req.ProjectID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_id")
+	}
+	if req.MaxTokens < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "max_tokens must not be negative")
+	}
+
+	var filter *vectorstore.FilterExpr
+	if req.Filter != "" {
+		var err error
+		if filter, err = vectorstore.ParseFilterExpr(req.Filter); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid filter: "+err.Error())
+		}
+	}
+
+	memorySvc := s.registry.Memory()
+	if memorySvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "memory service unavailable")
+	}
+
+	// ProjectID serves as both tenant and project scope, matching memory_search
+	ctx := vectorstore.ContextWithTenant(c.Request().Context(), &vectorstore.TenantInfo{
+		TenantID:  req.ProjectID,
+		ProjectID: req.ProjectID,
+	})
+	ctx = reasoningbank.ContextWithLanguages(ctx, req.Languages...)
+	ctx = reasoningbank.ContextWithFilter(ctx, filter)
+	ctx = reasoningbank.ContextWithSession(ctx, req.SessionID)
+
+	page, err := memorySvc.SearchPage(ctx, req.ProjectID, req.Query, req.Limit, req.Cursor)
+	if err != nil {
+		return s.searchError("memory", err)
+	}
+
+	hits := make([]MemorySearchHit, 0, len(page.Memories))
+	scrubbed := make([]reasoningbank.ScoredMemory, 0, len(page.Memories))
+	for _, sm := range page.Memories {
+		hit := MemorySearchHit{
+			ID:         sm.Memory.ID,
+			Title:      sm.Memory.Title,
+			Content:    s.scrub(sm.Memory.Content),
+			Outcome:    string(sm.Memory.Outcome),
+			Confidence: sm.Memory.Confidence,
+			Relevance:  sm.Relevance,
+			Tags:       sm.Memory.Tags,
+			Languages:  sm.Memory.Languages,
+		}
+		if sm.Highlight != nil {
+			hit.Highlight = s.scrub(sm.Highlight.Text)
+		}
+		hits = append(hits, hit)
+
+		sm.Memory.Title = s.scrub(sm.Memory.Title)
+		sm.Memory.Content = hit.Content
+		if sm.Highlight != nil {
+			h := *sm.Highlight
+			h.Text = hit.Highlight
+			sm.Highlight = &h
+		}
+		scrubbed = append(scrubbed, sm)
+	}
+
+	resp := MemorySearchResponse{
+		Memories:   hits,
+		Count:      len(hits),
+		NextCursor: page.NextCursor,
+	}
+	if req.Inject {
+		resp.Injection = reasoningbank.FormatInjection(scrubbed, reasoningbank.InjectionOptions{MaxTokens: req.MaxTokens})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleRemediationSearch returns one page of remediations.
+func (s *Server) handleRemediationSearch(c echo.Context) error {
+	var req RemediationSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "query field is required")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := sanitize.ValidateTeamID(req.TeamID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team_id")
+	}
+
+	remediationSvc := s.registry.Remediation()
+	if remediationSvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "remediation service unavailable")
+	}
+
+	ctx := vectorstore.ContextWithTenant(c.Request().Context(), &vectorstore.TenantInfo{
+		TenantID: tenantID,
+		TeamID:   req.TeamID,
+	})
+
+	results, next, err := remediationSvc.SearchPage(ctx, &remediation.SearchRequest{
+		Query:            req.Query,
+		TenantID:         tenantID,
+		TeamID:           req.TeamID,
+		ProjectPath:      validPath,
+		Scope:            remediation.Scope(req.Scope),
+		Category:         remediation.ErrorCategory(req.Category),
+		MinConfidence:    req.MinConfidence,
+		IncludeHierarchy: req.IncludeHierarchy,
+		Limit:            req.Limit,
+		Cursor:           req.Cursor,
+	})
+	if err != nil {
+		return s.searchError("remediation", err)
+	}
+
+	hits := make([]RemediationSearchHit, 0, len(results))
+	for _, r := range results {
+		hits = append(hits, RemediationSearchHit{
+			ID:         r.ID,
+			Title:      r.Title,
+			Problem:    s.scrub(r.Problem),
+			RootCause:  s.scrub(r.RootCause),
+			Solution:   s.scrub(r.Solution),
+			Category:   string(r.Category),
+			Confidence: r.Confidence,
+			Score:      r.Score,
+		})
+	}
+
+	return c.JSON(http.StatusOK, RemediationSearchResponse{
+		Remediations: hits,
+		Count:        len(hits),
+		NextCursor:   next,
+	})
+}
+
+// handleRepositorySearch returns one page of indexed repository files.
+func (s *Server) handleRepositorySearch(c echo.Context) error {
+	var req RepositorySearchRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Query == "" || req.ProjectPath == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "query and project_path fields are required")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+
+	repositorySvc := s.registry.Repository()
+	if repositorySvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "repository service unavailable")
+	}
+
+	results, next, err := repositorySvc.SearchPage(c.Request().Context(), req.Query, repository.SearchOptions{
+		ProjectPath: validPath,
+		TenantID:    tenantID,
+		Branch:      req.Branch,
+		DocType:     req.DocType,
+		Limit:       req.Limit,
+		Cursor:      req.Cursor,
+	})
+	if err != nil {
+		return s.searchError("repository", err)
+	}
+
+	// Strip content and metadata; this endpoint is for locating files.
+	for i := range results {
+		results[i].Content = ""
+		results[i].Metadata = nil
+	}
+
+	return c.JSON(http.StatusOK, RepositorySearchResponse{
+		Results:    results,
+		Count:      len(results),
+		NextCursor: next,
+	})
+}
+
+// resolveTenant validates projectPath and derives the tenant ID from it when
+// tenantID is not given explicitly.
+func resolveTenant(projectPath, tenantID string) (string, string, error) {
+	var validPath string
+	if projectPath != "" {
+		var err error
+		validPath, err = sanitize.ValidateProjectPath(projectPath)
+		if err != nil {
+			return "", "", echo.NewHTTPError(http.StatusBadRequest, "invalid project_path")
+		}
+	}
+
+	if tenantID == "" && validPath != "" {
+		tenantID = tenant.GetTenantIDForPath(validPath)
+	}
+	if tenantID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "tenant_id or project_path is required")
+	}
+	if err := sanitize.ValidateTenantID(tenantID); err != nil {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "invalid tenant_id")
+	}
+
+	return validPath, tenantID, nil
+}
+
+// searchError maps a search failure to an HTTP error. Coded errors, such as
+// invalid cursors or oversized queries, are returned as-is for the
+// errorResponses middleware; everything else is logged and reported as a
+// server error.
+func (s *Server) searchError(kind string, err error) error {
+	if isClientVisible(err) {
+		return err
+	}
+	s.logger.Error("search failed", zap.String("kind", kind), zap.Error(err))
+	return echo.NewHTTPError(http.StatusInternalServerError, kind+" search failed")
+}
+
+// scrub removes secrets from content when a scrubber is configured.
+func (s *Server) scrub(content string) string {
+	if scrubber := s.registry.Scrubber(); scrubber != nil {
+		return scrubber.Scrub(content).Scrubbed
+	}
+	return content
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// constantEmbedder returns the same vector for every text, so all documents
+// rank equally and ordering is left to the store.
+type constantEmbedder struct{ dim int }
+
+func (e *constantEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i], _ = e.EmbedQuery(ctx, texts[i])
+	}
+	return out, nil
+}
+
+func (e *constantEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	v := make([]float32, e.dim)
+	v[0] = 1
+	return v, nil
+}
+
+func setupSearchServer(t *testing.T, memorySvc *reasoningbank.Service) *Server {
+	t.Helper()
+
+	scrubber, err := secrets.New(nil)
+	require.NoError(t, err)
+
+	registry := &mockRegistry{}
+	registry.On("Scrubber").Return(scrubber)
+	if memorySvc != nil {
+		registry.On("Memory").Return(memorySvc)
+	} else {
+		registry.On("Memory").Return(nil)
+	}
+	registry.On("Remediation").Return(nil)
+	registry.On("Repository").Return(nil)
+
+	server, err := NewServer(registry, zap.NewNop(), nil)
+	require.NoError(t, err)
+	return server
+}
+
+func postJSON(t *testing.T, server *Server, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleMemorySearch_Pagination(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	memorySvc, err := reasoningbank.NewService(store, zap.NewNop(), reasoningbank.WithDefaultTenant("paging"))
+	require.NoError(t, err)
+
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{
+		TenantID:  "paging",
+		ProjectID: "paging",
+	})
+	for i := 0; i < 5; i++ {
+		mem, err := reasoningbank.NewMemory("paging", fmt.Sprintf("Memory %d", i), "retry with backoff", reasoningbank.OutcomeSuccess, nil)
+		require.NoError(t, err)
+		require.NoError(t, memorySvc.Record(ctx, mem))
+	}
+
+	server := setupSearchServer(t, memorySvc)
+
+	seen := make(map[string]bool)
+	req := MemorySearchRequest{ProjectID: "paging", Query: "retry", Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "pagination did not terminate")
+
+		rec := postJSON(t, server, "/api/v1/memories/search", req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp MemorySearchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		for _, m := range resp.Memories {
+			assert.False(t, seen[m.ID], "memory %s returned twice", m.ID)
+			seen[m.ID] = true
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		req.Cursor = resp.NextCursor
+	}
+	assert.Len(t, seen, 5)
+
+	t.Run("inject", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/memories/search", MemorySearchRequest{
+			ProjectID: "paging", Query: "retry", Limit: 3, Inject: true,
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp MemorySearchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.NotNil(t, resp.Injection)
+		assert.Len(t, resp.Injection.MemoryIDs, 3)
+		assert.Contains(t, resp.Injection.Text, "<relevant_memories>")
+		assert.Contains(t, resp.Injection.Text, "retry with backoff")
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/memories/search", MemorySearchRequest{
+			ProjectID: "paging", Query: "retry", Cursor: "not-a-cursor",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid cursor")
+	})
+}
+
+func TestHandleSearch_Validation(t *testing.T) {
+	server := setupSearchServer(t, nil)
+
+	tests := []struct {
+		name     string
+		path     string
+		body     interface{}
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "memory missing query",
+			path:     "/api/v1/memories/search",
+			body:     MemorySearchRequest{ProjectID: "proj"},
+			wantCode: http.StatusBadRequest,
+			wantBody: "project_id and query fields are required",
+		},
+		{
+			name:     "memory invalid filter",
+			path:     "/api/v1/memories/search",
+			body:     MemorySearchRequest{ProjectID: "proj", Query: "q", Filter: "confidence >= high"},
+			wantCode: http.StatusBadRequest,
+			wantBody: "invalid filter",
+		},
+		{
+			name:     "memory filter on tenant field",
+			path:     "/api/v1/memories/search",
+			body:     MemorySearchRequest{ProjectID: "proj", Query: "q", Filter: "project_id = other"},
+			wantCode: http.StatusBadRequest,
+			wantBody: "tenant fields",
+		},
+		{
+			name:     "memory invalid project_id",
+			path:     "/api/v1/memories/search",
+			body:     MemorySearchRequest{ProjectID: "../etc", Query: "q"},
+			wantCode: http.StatusBadRequest,
+			wantBody: "invalid project_id",
+		},
+		{
+			name:     "memory service unavailable",
+			path:     "/api/v1/memories/search",
+			body:     MemorySearchRequest{ProjectID: "proj", Query: "q"},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "memory service unavailable",
+		},
+		{
+			name:     "remediation missing tenant",
+			path:     "/api/v1/remediations/search",
+			body:     RemediationSearchRequest{Query: "q"},
+			wantCode: http.StatusBadRequest,
+			wantBody: "tenant_id or project_path is required",
+		},
+		{
+			name:     "remediation service unavailable",
+			path:     "/api/v1/remediations/search",
+			body:     RemediationSearchRequest{Query: "q", TenantID: "tenant1"},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "remediation service unavailable",
+		},
+		{
+			name:     "repository missing project_path",
+			path:     "/api/v1/repository/search",
+			body:     RepositorySearchRequest{Query: "q"},
+			wantCode: http.StatusBadRequest,
+			wantBody: "query and project_path fields are required",
+		},
+		{
+			name:     "repository path traversal",
+			path:     "/api/v1/repository/search",
+			body:     RepositorySearchRequest{Query: "q", ProjectPath: "../../etc"},
+			wantCode: http.StatusBadRequest,
+			wantBody: "invalid project_path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postJSON(t, server, tt.path, tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -4,15 +4,21 @@ package http
 import (
 	"context"
 	"fmt"
-	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
 	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
 	"github.com/fyrsmithlabs/contextd/internal/hooks"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
 	"github.com/fyrsmithlabs/contextd/internal/services"
+	"github.com/fyrsmithlabs/contextd/internal/unixsock"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -42,7 +48,20 @@ type Server struct {
 	logger        *zap.Logger
 	config        *Config
 	healthChecker *vectorstore.MetadataHealthChecker
+	driftChecker  *vectorstore.DriftChecker
+	verifier      *vectorstore.Verifier
 	metrics       *HTTPMetrics
+	readOnly      *readonly.Mode
+	analytics     *analytics.Tracker
+	workflows     WorkflowStarter
+
+	branches       BranchLister
+	embeddingQueue EmbeddingQueue
+	stopping       chan struct{} // closed by Shutdown to end event streams
+	stopOnce       sync.Once
+
+	toolsMu sync.RWMutex
+	tools   ToolCatalog // MCP tools for /api/v1/capabilities, set by SetToolCatalog
 }
 
 // Config holds HTTP server configuration.
@@ -51,6 +70,35 @@ type Config struct {
 	Port          int
 	Version       string
 	HealthChecker *vectorstore.MetadataHealthChecker // Optional metadata health checker
+	DriftChecker  *vectorstore.DriftChecker          // Optional embedding drift checker served by /api/v1/health/embeddings
+	Verifier      *vectorstore.Verifier              // Optional integrity verifier served by /api/v1/admin/verify
+	ReadOnly      *readonly.Mode                     // Optional read-only switch, toggled via the admin API
+	Analytics     *analytics.Tracker                 // Optional usage tracker served by /api/v1/stats/tools, /api/v1/stats/usage and /api/v1/stats/sessions
+
+	// SocketPath serves HTTP on a Unix domain socket instead of Host:Port.
+	// Access is controlled by SocketMode (default unixsock.DefaultMode,
+	// owner only).
+	SocketPath string
+	SocketMode os.FileMode
+
+	// Workflows is an optional Temporal client used by /api/v1/workflows/*
+	// to start indexing and consolidation runs on WorkflowTaskQueue
+	// (default: workflows.DefaultTaskQueue).
+	Workflows         WorkflowStarter
+	WorkflowTaskQueue string
+
+	// Branches and EmbeddingQueue are optional sources for the live
+	// snapshots of /api/v1/events.
+	Branches       BranchLister
+	EmbeddingQueue EmbeddingQueue
+
+	// AdminToken is the bearer token of the /debug/pprof profiling
+	// endpoints, which are only served when it is set.
+	AdminToken string
+
+	// Features describes the optional parts of contextd that are on, for
+	// /api/v1/capabilities. Read-only state is filled in per request.
+	Features capabilities.Features
 }
 
 // NewServer creates a new HTTP server.
@@ -96,6 +144,8 @@ func NewServer(registry services.Registry, logger *zap.Logger, cfg *Config) (*Se
 			return err
 		}
 	})
+	e.Use(errorResponses)   // Coded errors become structured responses
+	e.Use(requesterHeaders) // Access labels apply to the calling user
 
 	s := &Server{
 		echo:          e,
@@ -103,7 +153,16 @@ func NewServer(registry services.Registry, logger *zap.Logger, cfg *Config) (*Se
 		logger:        logger,
 		config:        cfg,
 		healthChecker: cfg.HealthChecker,
+		driftChecker:  cfg.DriftChecker,
+		verifier:      cfg.Verifier,
 		metrics:       httpMetrics,
+		readOnly:      cfg.ReadOnly,
+		analytics:     cfg.Analytics,
+		workflows:     cfg.Workflows,
+
+		branches:       cfg.Branches,
+		embeddingQueue: cfg.EmbeddingQueue,
+		stopping:       make(chan struct{}),
 	}
 
 	// Register routes
@@ -120,12 +179,75 @@ func (s *Server) registerRoutes() {
 	// Prometheus metrics endpoint
 	s.echo.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 
+	// CPU and heap profiles behind the admin token (see pprof.go)
+	s.registerProfiling()
+
 	// API v1 routes
 	v1 := s.echo.Group("/api/v1")
 	v1.POST("/scrub", s.handleScrub)
 	v1.POST("/threshold", s.handleThreshold)
 	v1.GET("/status", s.handleStatus)
+
+	// Version, features, tool schema versions and deprecations (see capabilities.go)
+	v1.GET("/capabilities", s.handleCapabilities)
 	v1.GET("/health/metadata", s.handleMetadataHealth)
+	v1.GET("/health/embeddings", s.handleEmbeddingHealth)
+
+	// OpenAPI document of the public endpoints (see openapi.go)
+	v1.GET("/openapi.json", s.handleOpenAPI)
+
+	// Paginated search (see search.go)
+	v1.POST("/memories/search", s.handleMemorySearch)
+	v1.POST("/remediations/search", s.handleRemediationSearch)
+	v1.POST("/repository/search", s.handleRepositorySearch)
+
+	// Remediation category statistics and migration (see remediation_categories.go)
+	v1.POST("/remediations/categories", s.handleRemediationCategories)
+	v1.POST("/remediations/categories/migrate", s.handleRemediationCategoryMigrate)
+
+	// Session start (see session.go)
+	v1.POST("/session/bootstrap", s.handleSessionBootstrap)
+
+	// Cross-session handoff bundles (see handoff.go)
+	v1.POST("/handoff/export", s.handleHandoffExport)
+	v1.POST("/handoff/import", s.handleHandoffImport)
+
+	// Federated search across knowledge types (see knowledge.go)
+	v1.POST("/knowledge/search", s.handleKnowledgeSearch)
+
+	// Retriever adapter over the reasoningbank and repository indexes (see retriever.go)
+	v1.POST("/retrieve", s.handleRetrieve)
+
+	// Emergency read-only switch (see admin.go)
+	v1.GET("/admin/read-only", s.handleGetReadOnly)
+	v1.PUT("/admin/read-only", s.handlePutReadOnly)
+
+	// Vectorstore integrity verification and repair for ctxd verify (see admin.go)
+	v1.GET("/admin/verify", s.handleVerify)
+	v1.POST("/admin/verify/repair", s.handleVerifyRepair)
+
+	// Memory scoring breakdown for tuning the scoring config (see scoring.go)
+	v1.POST("/admin/scoring/explain", s.handleScoreExplain)
+
+	// MCP tool usage, LLM spend, search experiment and session replay
+	// analytics (see stats.go)
+	v1.GET("/stats/tools", s.handleToolStats)
+	v1.GET("/stats/usage", s.handleUsageStats)
+	v1.GET("/stats/experiment", s.handleExperimentStats)
+	v1.GET("/stats/sessions/:id", s.handleSessionReplay)
+
+	// Live status stream for ctxd top (see events.go)
+	v1.GET("/events", s.handleEvents)
+
+	// Checkpoint outcome annotations (see checkpoint.go), scoped by project_path
+	v1.PATCH("/checkpoints/:id", s.handleCheckpointAnnotate)
+
+	// Checkpoints synthesized from conversations that were never saved (see checkpoint.go)
+	v1.POST("/checkpoints/synthesize", s.handleCheckpointSynthesize)
+
+	// Durable indexing and consolidation runs on Temporal (see workflows.go)
+	v1.POST("/workflows/repository-index", s.handleRepositoryIndexWorkflow)
+	v1.POST("/workflows/memory-consolidation", s.handleMemoryConsolidationWorkflow)
 
 	// Note: Checkpoint management is available via MCP tools (checkpoint_save, checkpoint_list, checkpoint_resume)
 	// HTTP endpoints were removed due to security concerns (CVE-2025-CONTEXTD-001)
@@ -211,15 +333,7 @@ func (s *Server) handleHealth(c echo.Context) error {
 // Restricted to localhost connections only to prevent internal metadata exposure.
 func (s *Server) handleMetadataHealth(c echo.Context) error {
 	// Restrict to localhost only (CWE-200: prevent internal metadata exposure)
-	// Use c.Request().RemoteAddr directly instead of c.RealIP() which trusts
-	// X-Forwarded-For/X-Real-IP headers that can be spoofed by clients.
-	host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
-	if err != nil {
-		// RemoteAddr without port (shouldn't happen with net/http, but be safe)
-		host = c.Request().RemoteAddr
-	}
-	remoteIP := net.ParseIP(host)
-	if remoteIP == nil || !remoteIP.IsLoopback() {
+	if !isLoopbackRequest(c) {
 		return echo.NewHTTPError(http.StatusForbidden, "metadata health endpoint is restricted to localhost")
 	}
 
@@ -238,10 +352,35 @@ func (s *Server) handleMetadataHealth(c echo.Context) error {
 	return c.JSON(http.StatusOK, health)
 }
 
+// handleEmbeddingHealth reports drift between stored vectors and fresh
+// embeddings of sampled documents. The report is cached by the drift
+// checker, so polling does not re-embed samples on every request.
+// Restricted to localhost connections only, like the metadata endpoint.
+func (s *Server) handleEmbeddingHealth(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "embedding health endpoint is restricted to localhost")
+	}
+
+	if s.driftChecker == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "embedding drift checker not configured")
+	}
+
+	report, err := s.driftChecker.Report(c.Request().Context())
+	if err != nil {
+		s.logger.Error("embedding drift check failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "embedding drift check failed")
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
 // handleStatus returns service status and resource counts.
 func (s *Server) handleStatus(c echo.Context) error {
-	ctx := c.Request().Context()
+	return c.JSON(http.StatusOK, s.status(c.Request().Context()))
+}
 
+// status reports service availability and resource counts.
+func (s *Server) status(ctx context.Context) StatusResponse {
 	// Build service status map
 	services := make(map[string]string)
 
@@ -327,7 +466,12 @@ func (s *Server) handleStatus(c echo.Context) error {
 		}
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	if s.readOnly.Enabled() {
+		status := s.readOnly.Status()
+		resp.ReadOnly = &status
+	}
+
+	return resp
 }
 
 // handleScrub scrubs secrets from the provided content.
@@ -381,16 +525,12 @@ func (s *Server) handleThreshold(c echo.Context) error {
 			fmt.Sprintf("percent must be between %d and %d", MinThresholdPercent, MaxThresholdPercent))
 	}
 
-	// Validate and sanitize summary length
+	// Validate summary and context length
 	if len(req.Summary) > MaxSummaryLength {
-		return echo.NewHTTPError(http.StatusBadRequest,
-			fmt.Sprintf("summary exceeds maximum length of %d characters", MaxSummaryLength))
+		return validation.NewError(validation.CodeContentTooLong, "summary", MaxSummaryLength, len(req.Summary))
 	}
-
-	// Validate and sanitize context length
 	if len(req.Context) > MaxContextLength {
-		return echo.NewHTTPError(http.StatusBadRequest,
-			fmt.Sprintf("context exceeds maximum length of %d characters", MaxContextLength))
+		return validation.NewError(validation.CodeContentTooLong, "context", MaxContextLength, len(req.Context))
 	}
 
 	// Use provided values or fall back to defaults
@@ -421,6 +561,18 @@ func (s *Server) handleThreshold(c echo.Context) error {
 		}
 	}
 
+	// Hooks fire this automatically, so read-only mode skips the checkpoint
+	// without failing the caller
+	if s.readOnly.Enabled() {
+		s.logger.Info("skipped auto-checkpoint in read-only mode",
+			zap.String("session_id", req.SessionID),
+			zap.Int("percent", req.Percent),
+		)
+		return c.JSON(http.StatusOK, ThresholdResponse{
+			Message: "checkpoint skipped: contextd is in read-only mode",
+		})
+	}
+
 	// Check if checkpoint service is available
 	checkpointSvc := s.registry.Checkpoint()
 	if checkpointSvc == nil {
@@ -481,6 +633,36 @@ func (s *Server) handleThreshold(c echo.Context) error {
 	})
 }
 
+// Requester headers identify the user and team behind a request, so private
+// and team documents are only returned to them. They are taken as sent and
+// not authenticated, so they scope results for cooperating clients but are
+// not an access boundary: anyone who can reach the server can claim any user.
+const (
+	headerUser = "X-Contextd-User"
+	headerTeam = "X-Contextd-Team"
+
+	maxRequesterLength = 256
+)
+
+// requesterHeaders records the X-Contextd-User and X-Contextd-Team headers
+// as the request's vectorstore.Requester.
+func requesterHeaders(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := vectorstore.Requester{
+			UserID: strings.TrimSpace(c.Request().Header.Get(headerUser)),
+			TeamID: strings.TrimSpace(c.Request().Header.Get(headerTeam)),
+		}
+		if len(r.UserID) > maxRequesterLength || len(r.TeamID) > maxRequesterLength {
+			return echo.NewHTTPError(http.StatusBadRequest, "requester headers too long")
+		}
+		if r != (vectorstore.Requester{}) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(vectorstore.ContextWithRequester(req.Context(), r)))
+		}
+		return next(c)
+	}
+}
+
 // Note: handleCheckpointSave, handleCheckpointList, and handleCheckpointResume methods
 // were removed to address CVE-2025-CONTEXTD-001 (missing tenant context injection).
 // Checkpoint operations are available via MCP tools with proper security:
@@ -490,6 +672,15 @@ func (s *Server) handleThreshold(c echo.Context) error {
 
 // Start starts the HTTP server.
 func (s *Server) Start() error {
+	if s.config.SocketPath != "" {
+		ln, err := unixsock.Listen(s.config.SocketPath, s.config.SocketMode)
+		if err != nil {
+			return err
+		}
+		s.logger.Info("starting http server", zap.String("socket", s.config.SocketPath))
+		s.echo.Listener = ln
+		return s.echo.Start("")
+	}
 	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 	s.logger.Info("starting http server", zap.String("addr", addr))
 	return s.echo.Start(addr)
@@ -498,5 +689,6 @@ func (s *Server) Start() error {
 // Shutdown gracefully shuts down the server.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("shutting down http server")
+	s.stopOnce.Do(func() { close(s.stopping) })
 	return s.echo.Shutdown(ctx)
 }
@@ -4,20 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
 	"github.com/fyrsmithlabs/contextd/internal/compression"
+	"github.com/fyrsmithlabs/contextd/internal/conversation"
 	"github.com/fyrsmithlabs/contextd/internal/hooks"
 	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
 	"github.com/fyrsmithlabs/contextd/internal/remediation"
 	"github.com/fyrsmithlabs/contextd/internal/repository"
 	"github.com/fyrsmithlabs/contextd/internal/secrets"
 	"github.com/fyrsmithlabs/contextd/internal/troubleshoot"
+	"github.com/fyrsmithlabs/contextd/internal/unixsock"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -98,6 +103,57 @@ func TestHandleHealth(t *testing.T) {
 	assert.Equal(t, "ok", resp.Status)
 }
 
+func TestHandleEmbeddingHealth(t *testing.T) {
+	embedder := &constantEmbedder{dim: 8}
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+		Isolation:  vectorstore.NewNoIsolation(),
+	}, embedder, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	_, err = store.AddDocuments(context.Background(), []vectorstore.Document{
+		{ID: "a", Content: "first", Collection: "drift_memories"},
+		{ID: "b", Content: "second", Collection: "drift_memories"},
+	})
+	require.NoError(t, err)
+
+	checker, err := vectorstore.NewDriftChecker(store, embedder, vectorstore.DriftConfig{}, zap.NewNop())
+	require.NoError(t, err)
+
+	get := func(server *Server, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health/embeddings", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{DriftChecker: checker})
+	require.NoError(t, err)
+
+	t.Run("reports drift", func(t *testing.T) {
+		rec := get(server, "127.0.0.1:1234")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var report vectorstore.DriftReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, "ok", report.Status)
+		assert.Equal(t, 2, report.Sampled)
+	})
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := get(server, "192.0.2.1:1234")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		unconfigured, err := NewServer(&mockRegistry{}, zap.NewNop(), nil)
+		require.NoError(t, err)
+		rec := get(unconfigured, "127.0.0.1:1234")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
 func TestHandleScrub(t *testing.T) {
 	t.Run("scrubs secrets from content", func(t *testing.T) {
 		server := setupTestServer(t)
@@ -294,6 +350,59 @@ func TestServerLifecycle(t *testing.T) {
 			t.Fatal("server did not shut down in time")
 		}
 	})
+
+	t.Run("serves on a unix socket", func(t *testing.T) {
+		scrubber, err := secrets.New(nil)
+		require.NoError(t, err)
+
+		registry := &mockRegistry{}
+		registry.On("Scrubber").Return(scrubber)
+
+		dir, err := os.MkdirTemp("", "ctxd")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		socketPath := filepath.Join(dir, "contextd.sock")
+
+		server, err := NewServer(registry, zap.NewNop(), &Config{SocketPath: socketPath})
+		require.NoError(t, err)
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- server.Start()
+		}()
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return unixsock.Dial(ctx, socketPath)
+			},
+		}}
+		require.Eventually(t, func() bool {
+			resp, err := client.Get("http://contextd/health")
+			if err != nil {
+				return false
+			}
+			resp.Body.Close()
+			return resp.StatusCode == http.StatusOK
+		}, 2*time.Second, 20*time.Millisecond)
+
+		// Socket clients count as local for localhost-only endpoints.
+		resp, err := client.Get("http://contextd/api/v1/health/metadata")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, server.Shutdown(ctx))
+		select {
+		case err := <-errChan:
+			assert.True(t, err == nil || err == http.ErrServerClosed)
+		case <-time.After(6 * time.Second):
+			t.Fatal("server did not shut down in time")
+		}
+		_, err = os.Lstat(socketPath)
+		assert.True(t, os.IsNotExist(err), "socket should be removed on shutdown")
+	})
 }
 
 func TestMiddleware(t *testing.T) {
@@ -326,6 +435,31 @@ func TestMiddleware(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+
+	t.Run("records requester headers", func(t *testing.T) {
+		server := setupTestServer(t)
+
+		var got vectorstore.Requester
+		server.echo.GET("/whoami", func(c echo.Context) error {
+			got = vectorstore.RequesterFromContext(c.Request().Context())
+			return c.NoContent(http.StatusNoContent)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		req.Header.Set(headerUser, "alice@example.com")
+		req.Header.Set(headerTeam, "core")
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, vectorstore.Requester{UserID: "alice@example.com", TeamID: "core"}, got)
+
+		req = httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		req.Header.Set(headerUser, strings.Repeat("a", maxRequesterLength+1))
+		rec = httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
 }
 
 func TestScrubWithDisabledScrubber(t *testing.T) {
@@ -448,6 +582,14 @@ func (m *mockRegistry) Compression() *compression.Service {
 	return args.Get(0).(*compression.Service)
 }
 
+func (m *mockRegistry) Conversations() *conversation.Service {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*conversation.Service)
+}
+
 func (m *mockRegistry) VectorStore() vectorstore.Store {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -477,6 +619,19 @@ func (m *mockCheckpointService) List(ctx context.Context, req *checkpoint.ListRe
 	return args.Get(0).([]*checkpoint.Checkpoint), args.Error(1)
 }
 
+func (m *mockCheckpointService) Search(ctx context.Context, req *checkpoint.SearchRequest) ([]*checkpoint.ScoredCheckpoint, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*checkpoint.ScoredCheckpoint), args.Error(1)
+}
+
+func (m *mockCheckpointService) Stream(ctx context.Context, req *checkpoint.ListRequest, fn func(*checkpoint.Checkpoint) error) error {
+	args := m.Called(ctx, req, fn)
+	return args.Error(0)
+}
+
 func (m *mockCheckpointService) Resume(ctx context.Context, req *checkpoint.ResumeRequest) (*checkpoint.ResumeResponse, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
@@ -498,6 +653,35 @@ func (m *mockCheckpointService) Delete(ctx context.Context, tenantID, teamID, pr
 	return args.Error(0)
 }
 
+func (m *mockCheckpointService) Annotate(ctx context.Context, req *checkpoint.AnnotateRequest) (*checkpoint.Checkpoint, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*checkpoint.Checkpoint), args.Error(1)
+}
+
+func (m *mockCheckpointService) Release(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*checkpoint.Checkpoint, error) {
+	args := m.Called(ctx, tenantID, teamID, projectID, checkpointID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*checkpoint.Checkpoint), args.Error(1)
+}
+
+func (m *mockCheckpointService) LinkConversation(ctx context.Context, tenantID, teamID, projectID, checkpointID string, origin checkpoint.ConversationOrigin) error {
+	args := m.Called(ctx, tenantID, teamID, projectID, checkpointID, origin)
+	return args.Error(0)
+}
+
+func (m *mockCheckpointService) Lineage(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*checkpoint.Lineage, error) {
+	args := m.Called(ctx, tenantID, teamID, projectID, checkpointID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*checkpoint.Lineage), args.Error(1)
+}
+
 func (m *mockCheckpointService) Close() error {
 	args := m.Called()
 	return args.Error(0)
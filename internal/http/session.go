@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/fyrsmithlabs/contextd/internal/bootstrap"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// SessionBootstrapRequest is the request body for POST /api/v1/session/bootstrap.
+type SessionBootstrapRequest struct {
+	ProjectID        string `json:"project_id"`
+	Task             string `json:"task"`
+	ProjectPath      string `json:"project_path,omitempty"`
+	TenantID         string `json:"tenant_id,omitempty"`
+	TeamID           string `json:"team_id,omitempty"`
+	MemoryLimit      int    `json:"memory_limit,omitempty"`
+	RemediationLimit int    `json:"remediation_limit,omitempty"`
+	InsightLimit     int    `json:"insight_limit,omitempty"`
+}
+
+// handleSessionBootstrap returns the session-start bundle: relevant memories,
+// the latest checkpoint, remediations and reflection insights. Sections that
+// fail are listed in the bundle's warnings rather than failing the request.
+func (s *Server) handleSessionBootstrap(c echo.Context) error {
+	var req SessionBootstrapRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.ProjectID == "" || req.Task == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "project_id and task fields are required")
+	}
+	if err := sanitize.ValidateProjectID(req.ProjectID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_id")
+	}
+	if err := sanitize.ValidateTeamID(req.TeamID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team_id")
+	}
+
+	bootstrapReq := bootstrap.Request{
+		ProjectID:        req.ProjectID,
+		Task:             req.Task,
+		TeamID:           req.TeamID,
+		MemoryLimit:      req.MemoryLimit,
+		RemediationLimit: req.RemediationLimit,
+		InsightLimit:     req.InsightLimit,
+	}
+
+	// Checkpoints and remediations need a tenant; without one those sections
+	// are reported as warnings.
+	if req.ProjectPath != "" || req.TenantID != "" {
+		validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+		if err != nil {
+			return err
+		}
+		bootstrapReq.ProjectPath = validPath
+		bootstrapReq.TenantID = tenantID
+	}
+
+	// Unavailable services are nil; the builder reports their sections as
+	// warnings and returns what it could gather
+	builder := bootstrap.NewBuilder(s.registry.Memory(), s.registry.Checkpoint(), s.registry.Remediation(), s.registry.Scrubber(), s.logger)
+	bundle, err := builder.Build(c.Request().Context(), bootstrapReq)
+	if isClientVisible(err) {
+		return err
+	}
+	if err != nil {
+		s.logger.Error("session bootstrap failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "session bootstrap failed")
+	}
+
+	return c.JSON(http.StatusOK, bundle)
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/fyrsmithlabs/contextd/internal/bootstrap"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHandleSessionBootstrap(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	memorySvc, err := reasoningbank.NewService(store, zap.NewNop(), reasoningbank.WithDefaultTenant("boot"))
+	require.NoError(t, err)
+
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{
+		TenantID:  "boot",
+		ProjectID: "boot",
+	})
+	mem, err := reasoningbank.NewMemory("boot", "Retry flaky uploads", "retry with backoff", reasoningbank.OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, memorySvc.Record(ctx, mem))
+
+	scrubber, err := secrets.New(nil)
+	require.NoError(t, err)
+	registry := &mockRegistry{}
+	registry.On("Scrubber").Return(scrubber)
+	registry.On("Memory").Return(memorySvc)
+	registry.On("Checkpoint").Return(nil)
+	registry.On("Remediation").Return(nil)
+
+	server, err := NewServer(registry, zap.NewNop(), nil)
+	require.NoError(t, err)
+
+	t.Run("returns bundle with warnings for unavailable sections", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/session/bootstrap", SessionBootstrapRequest{
+			ProjectID: "boot",
+			Task:      "fix uploads",
+			TenantID:  "tenant1",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var bundle bootstrap.Bundle
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bundle))
+		require.Len(t, bundle.Memories, 1)
+		assert.Equal(t, mem.ID, bundle.Memories[0].ID)
+		assert.Nil(t, bundle.Checkpoint)
+		assert.Equal(t, []string{
+			"checkpoint: checkpoint service unavailable",
+			"remediations: remediation service unavailable",
+		}, bundle.Warnings)
+	})
+
+	t.Run("missing task", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/session/bootstrap", SessionBootstrapRequest{ProjectID: "boot"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "project_id and task fields are required")
+	})
+
+	t.Run("invalid tenant", func(t *testing.T) {
+		rec := postJSON(t, server, "/api/v1/session/bootstrap", SessionBootstrapRequest{
+			ProjectID: "boot", Task: "t", TenantID: "../bad",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid tenant_id")
+	})
+}
+
+func TestHandleSessionBootstrap_WithoutMemoryService(t *testing.T) {
+	server := setupSearchServer(t, nil)
+	registry := server.registry.(*mockRegistry)
+	registry.On("Checkpoint").Return(nil)
+
+	rec := postJSON(t, server, "/api/v1/session/bootstrap", SessionBootstrapRequest{
+		ProjectID: "boot",
+		Task:      "fix uploads",
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var bundle bootstrap.Bundle
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bundle))
+	assert.Empty(t, bundle.Memories)
+	assert.Contains(t, bundle.Warnings, "memories: memory service unavailable")
+	assert.Contains(t, bundle.Warnings, "insights: memory service unavailable")
+}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultStatsDays is the window the stats endpoints cover by default.
+	DefaultStatsDays = 7
+	// MaxStatsDays is the longest window the stats endpoints accept.
+	MaxStatsDays = 366
+)
+
+// ToolStatsResponse is the response body for GET /api/v1/stats/tools.
+type ToolStatsResponse struct {
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	GroupBy []string            `json:"group_by"`
+	Tools   []analytics.Summary `json:"tools"`
+}
+
+// handleToolStats returns MCP tool usage rollups.
+//
+// Query parameters:
+//   - days: window ending today, 1-366 (default 7)
+//   - tool, tenant_id, project_id: filters
+//   - group_by: comma-separated tool, tenant, project, day (default tool)
+func (s *Server) handleToolStats(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "stats endpoints are restricted to localhost")
+	}
+	if s.analytics == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "tool analytics not configured")
+	}
+
+	from, to, groupBy, err := statsWindow(c, analytics.DimensionTool)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.analytics.Query(analytics.Query{
+		From:      from,
+		To:        to,
+		Tool:      c.QueryParam("tool"),
+		TenantID:  c.QueryParam("tenant_id"),
+		ProjectID: c.QueryParam("project_id"),
+		GroupBy:   groupBy,
+	})
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidQuery) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		s.logger.Error("tool stats query failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to read tool stats")
+	}
+
+	resp := ToolStatsResponse{
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		GroupBy: groupBy,
+		Tools:   make([]analytics.Summary, 0, len(rows)),
+	}
+	for _, row := range rows {
+		resp.Tools = append(resp.Tools, row.Summary())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UsageStatsResponse is the response body for GET /api/v1/stats/usage.
+type UsageStatsResponse struct {
+	From    string                 `json:"from"`
+	To      string                 `json:"to"`
+	GroupBy []string               `json:"group_by"`
+	Total   analytics.UsageStats   `json:"total"`
+	Usage   []analytics.UsageStats `json:"usage"`
+}
+
+// handleUsageStats returns LLM token usage and estimated cost rollups.
+//
+// Query parameters:
+//   - days: window ending today, 1-366 (default 7)
+//   - feature, tenant_id, project_id, session_id: filters
+//   - group_by: comma-separated feature, provider, model, tenant, project,
+//     session, day (default feature)
+func (s *Server) handleUsageStats(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "stats endpoints are restricted to localhost")
+	}
+	if s.analytics == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "usage analytics not configured")
+	}
+
+	from, to, groupBy, err := statsWindow(c, analytics.DimensionFeature)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.analytics.QueryUsage(analytics.UsageQuery{
+		From:      from,
+		To:        to,
+		Feature:   c.QueryParam("feature"),
+		TenantID:  c.QueryParam("tenant_id"),
+		ProjectID: c.QueryParam("project_id"),
+		SessionID: c.QueryParam("session_id"),
+		GroupBy:   groupBy,
+	})
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidQuery) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		s.logger.Error("usage stats query failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to read usage stats")
+	}
+
+	resp := UsageStatsResponse{
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		GroupBy: groupBy,
+		Usage:   rows,
+	}
+	for _, row := range rows {
+		resp.Total.Requests += row.Requests
+		resp.Total.Errors += row.Errors
+		resp.Total.InputTokens += row.InputTokens
+		resp.Total.OutputTokens += row.OutputTokens
+		resp.Total.CostUSD += row.CostUSD
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// SessionReplayResponse is the response body for
+// GET /api/v1/stats/sessions/:id.
+type SessionReplayResponse struct {
+	SessionID string            `json:"session_id"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Events    []analytics.Event `json:"events"`
+}
+
+// handleSessionReplay returns the journaled tool calls of one session,
+// oldest first.
+//
+// Query parameters:
+//   - days: window ending today, 1-366 (default 7)
+func (s *Server) handleSessionReplay(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "stats endpoints are restricted to localhost")
+	}
+	if s.analytics == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "tool analytics not configured")
+	}
+
+	from, to, _, err := statsWindow(c, "")
+	if err != nil {
+		return err
+	}
+
+	sessionID := c.Param("id")
+	events, err := s.analytics.SessionEvents(analytics.SessionQuery{
+		SessionID: sessionID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidQuery) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		s.logger.Error("session replay query failed", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to read session journal")
+	}
+
+	resp := SessionReplayResponse{
+		SessionID: sessionID,
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Events:    events,
+	}
+	if resp.Events == nil {
+		resp.Events = []analytics.Event{}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// statsWindow parses the days and group_by parameters shared by the stats
+// endpoints.
+func statsWindow(c echo.Context, defaultGroupBy string) (from, to time.Time, groupBy []string, err error) {
+	days := DefaultStatsDays
+	if raw := c.QueryParam("days"); raw != "" {
+		n, convErr := strconv.Atoi(raw)
+		if convErr != nil || n < 1 || n > MaxStatsDays {
+			return from, to, nil, echo.NewHTTPError(http.StatusBadRequest, "days must be between 1 and 366")
+		}
+		days = n
+	}
+
+	if raw := c.QueryParam("group_by"); raw != "" {
+		for _, d := range strings.Split(raw, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				groupBy = append(groupBy, d)
+			}
+		}
+	}
+	if len(groupBy) == 0 {
+		groupBy = []string{defaultGroupBy}
+	}
+
+	to = time.Now().UTC()
+	from = to.AddDate(0, 0, -(days - 1))
+	return from, to, groupBy, nil
+}
+
+// handleExperimentStats returns the per-arm totals of the running search
+// ranking experiment.
+func (s *Server) handleExperimentStats(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "stats endpoints are restricted to localhost")
+	}
+	memorySvc := s.registry.Memory()
+	if memorySvc == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "memory service unavailable")
+	}
+	report := memorySvc.ExperimentReport()
+	if report == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "no search experiment configured")
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func statsRequest(t *testing.T, server *Server, remoteAddr, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	return statsRequestPath(t, server, "/api/v1/stats/tools", remoteAddr, query)
+}
+
+func statsRequestPath(t *testing.T, server *Server, path, remoteAddr, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path+query, nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestToolStats(t *testing.T) {
+	tracker, err := analytics.NewTracker(analytics.Config{Dir: t.TempDir()}, zap.NewNop())
+	require.NoError(t, err)
+	tracker.Record(analytics.Call{Tool: "memory_search", TenantID: "acme", ProjectID: "api", Duration: 10 * time.Millisecond})
+	tracker.Record(analytics.Call{Tool: "memory_search", TenantID: "acme", ProjectID: "web", Duration: 30 * time.Millisecond, Failed: true})
+	tracker.Record(analytics.Call{Tool: "checkpoint_save", TenantID: "acme", ProjectID: "api"})
+
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Analytics: tracker})
+	require.NoError(t, err)
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := statsRequest(t, server, "192.0.2.1:1234", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("grouped by tool", func(t *testing.T) {
+		rec := statsRequest(t, server, "127.0.0.1:1234", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp ToolStatsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"tool"}, resp.GroupBy)
+		require.Len(t, resp.Tools, 2)
+		assert.Equal(t, "memory_search", resp.Tools[0].Tool)
+		assert.Equal(t, int64(2), resp.Tools[0].Calls)
+		assert.InDelta(t, 0.5, resp.Tools[0].ErrorRate, 1e-9)
+		assert.InDelta(t, 20, resp.Tools[0].AvgLatencyMs, 1e-9)
+	})
+
+	t.Run("filtered and grouped by project", func(t *testing.T) {
+		rec := statsRequest(t, server, "127.0.0.1:1234", "?project_id=api&group_by=tool,project&days=30")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp ToolStatsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Tools, 2)
+		for _, row := range resp.Tools {
+			assert.Equal(t, "api", row.ProjectID)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, statsRequest(t, server, "127.0.0.1:1234", "?days=0").Code)
+		assert.Equal(t, http.StatusBadRequest, statsRequest(t, server, "127.0.0.1:1234", "?group_by=session").Code)
+	})
+}
+
+func TestToolStats_NotConfigured(t *testing.T) {
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{})
+	require.NoError(t, err)
+
+	rec := statsRequest(t, server, "127.0.0.1:1234", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestUsageStats(t *testing.T) {
+	tracker, err := analytics.NewTracker(analytics.Config{Dir: t.TempDir()}, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+	tracker.RecordUsage(ctx, analytics.LLMUsage{Feature: analytics.FeatureCompression, Provider: analytics.ProviderAnthropic, Model: "claude-3-haiku-20240307", TenantID: "acme", ProjectID: "api", SessionID: "s1", InputTokens: 4_000_000})
+	tracker.RecordUsage(ctx, analytics.LLMUsage{Feature: analytics.FeatureExtraction, Provider: analytics.ProviderOpenAI, Model: "gpt-4o-mini", TenantID: "acme", ProjectID: "web", InputTokens: 1000, Failed: true})
+
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Analytics: tracker})
+	require.NoError(t, err)
+	const path = "/api/v1/stats/usage"
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := statsRequestPath(t, server, path, "192.0.2.1:1234", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("grouped by feature with totals", func(t *testing.T) {
+		rec := statsRequestPath(t, server, path, "127.0.0.1:1234", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp UsageStatsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"feature"}, resp.GroupBy)
+		require.Len(t, resp.Usage, 2)
+		assert.Equal(t, analytics.FeatureCompression, resp.Usage[0].Feature)
+		assert.InDelta(t, 1.0, resp.Usage[0].CostUSD, 1e-9)
+		assert.Equal(t, int64(2), resp.Total.Requests)
+		assert.Equal(t, int64(1), resp.Total.Errors)
+		assert.Equal(t, int64(4_001_000), resp.Total.InputTokens)
+	})
+
+	t.Run("filtered by session", func(t *testing.T) {
+		rec := statsRequestPath(t, server, path, "127.0.0.1:1234", "?session_id=s1&group_by=session,model")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp UsageStatsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Usage, 1)
+		assert.Equal(t, "s1", resp.Usage[0].SessionID)
+		assert.Equal(t, "claude-3-haiku-20240307", resp.Usage[0].Model)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, statsRequestPath(t, server, path, "127.0.0.1:1234", "?days=400").Code)
+		assert.Equal(t, http.StatusBadRequest, statsRequestPath(t, server, path, "127.0.0.1:1234", "?group_by=tool").Code)
+	})
+}
+
+func TestExperimentStats(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 8,
+	}, &constantEmbedder{dim: 8}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	memorySvc, err := reasoningbank.NewService(store, zap.NewNop(),
+		reasoningbank.WithDefaultTenant("exp"),
+		reasoningbank.WithExperiment(&reasoningbank.Experiment{
+			Name:             "rerank",
+			TreatmentPercent: 100,
+			Treatment:        reasoningbank.RankingConfig{Rerank: true},
+		}))
+	require.NoError(t, err)
+
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "exp", ProjectID: "exp"})
+	mem, err := reasoningbank.NewMemory("exp", "Retry", "retry with backoff", reasoningbank.OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, memorySvc.Record(ctx, mem))
+
+	server := setupSearchServer(t, memorySvc)
+	rec := postJSON(t, server, "/api/v1/memories/search", MemorySearchRequest{ProjectID: "exp", Query: "retry", SessionID: "sess-1"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := statsRequestPath(t, server, "/api/v1/stats/experiment", "192.0.2.1:1234", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("per-arm totals", func(t *testing.T) {
+		rec := statsRequestPath(t, server, "/api/v1/stats/experiment", "127.0.0.1:1234", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var report reasoningbank.ExperimentReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, "rerank", report.Name)
+		require.Len(t, report.Arms, 2)
+		assert.Equal(t, reasoningbank.ArmTreatment, report.Arms[1].Arm)
+		assert.Equal(t, int64(1), report.Arms[1].Sessions)
+		assert.Equal(t, int64(1), report.Arms[1].Searches)
+		assert.Equal(t, int64(1), report.Arms[1].Served)
+		assert.Zero(t, report.Arms[0].Searches)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		plain, err := reasoningbank.NewService(store, zap.NewNop(), reasoningbank.WithDefaultTenant("exp"))
+		require.NoError(t, err)
+		rec := statsRequestPath(t, setupSearchServer(t, plain), "/api/v1/stats/experiment", "127.0.0.1:1234", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestSessionReplay(t *testing.T) {
+	tracker, err := analytics.NewTracker(analytics.Config{Dir: t.TempDir()}, zap.NewNop())
+	require.NoError(t, err)
+	now := time.Now()
+	tracker.Record(analytics.Call{Tool: "memory_search", ProjectID: "api", SessionID: "s1", Time: now.Add(-time.Minute), Args: map[string]any{"query": "retry"}, ResultIDs: []string{"mem-1"}})
+	tracker.Record(analytics.Call{Tool: "memory_feedback", ProjectID: "api", SessionID: "s1", Time: now, Args: map[string]any{"memory_id": "mem-1", "helpful": true}})
+	tracker.Record(analytics.Call{Tool: "memory_search", ProjectID: "api", SessionID: "s2", Time: now})
+
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Analytics: tracker})
+	require.NoError(t, err)
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := statsRequestPath(t, server, "/api/v1/stats/sessions/s1", "192.0.2.1:1234", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("events in order", func(t *testing.T) {
+		rec := statsRequestPath(t, server, "/api/v1/stats/sessions/s1", "127.0.0.1:1234", "?days=2")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp SessionReplayResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "s1", resp.SessionID)
+		require.Len(t, resp.Events, 2)
+		assert.Equal(t, "memory_search", resp.Events[0].Tool)
+		assert.Equal(t, []string{"mem-1"}, resp.Events[0].ResultIDs)
+		assert.Equal(t, "memory_feedback", resp.Events[1].Tool)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		rec := statsRequestPath(t, server, "/api/v1/stats/sessions/none", "127.0.0.1:1234", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"events":[]`)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		plain, err := NewServer(&mockRegistry{}, zap.NewNop(), nil)
+		require.NoError(t, err)
+		rec := statsRequestPath(t, plain, "/api/v1/stats/sessions/s1", "127.0.0.1:1234", "")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
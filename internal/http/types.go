@@ -1,6 +1,8 @@
 // Package http provides HTTP API for contextd.
 package http
 
+import "github.com/fyrsmithlabs/contextd/internal/readonly"
+
 // StatusResponse is the response body for GET /api/v1/status.
 type StatusResponse struct {
 	Status      string             `json:"status"`
@@ -10,6 +12,7 @@ type StatusResponse struct {
 	Context     *ContextStatus     `json:"context,omitempty"`
 	Compression *CompressionStatus `json:"compression,omitempty"`
 	Memory      *MemoryStatus      `json:"memory,omitempty"`
+	ReadOnly    *readonly.Status   `json:"read_only,omitempty"`
 }
 
 // StatusCounts contains count information for various resources.
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/workflows"
+)
+
+// WorkflowStarter starts Temporal workflows. client.Client satisfies it.
+type WorkflowStarter interface {
+	ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error)
+}
+
+// RepositoryIndexWorkflowRequest is the request body for
+// POST /api/v1/workflows/repository-index.
+type RepositoryIndexWorkflowRequest struct {
+	ProjectPath     string   `json:"project_path"`
+	TenantID        string   `json:"tenant_id,omitempty"`
+	Branch          string   `json:"branch,omitempty"`
+	IncludePatterns []string `json:"include_patterns,omitempty"`
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"`
+	MaxFileSize     int64    `json:"max_file_size,omitempty"`
+	BatchSize       int      `json:"batch_size,omitempty"`
+}
+
+// MemoryConsolidationWorkflowRequest is the request body for
+// POST /api/v1/workflows/memory-consolidation.
+type MemoryConsolidationWorkflowRequest struct {
+	ProjectID           string   `json:"project_id"`
+	SimilarityThreshold float64  `json:"similarity_threshold,omitempty"`
+	MaxClusters         int      `json:"max_clusters,omitempty"`
+	Tags                []string `json:"tags,omitempty"`
+	Outcome             string   `json:"outcome,omitempty"`
+	Query               string   `json:"query,omitempty"`
+	DryRun              bool     `json:"dry_run,omitempty"`
+}
+
+// WorkflowStartResponse identifies a started workflow run. Its progress can
+// be followed in the Temporal UI.
+type WorkflowStartResponse struct {
+	WorkflowID string `json:"workflow_id"`
+	RunID      string `json:"run_id"`
+}
+
+// handleRepositoryIndexWorkflow starts a durable repository indexing run.
+// Only one run per repository is active at a time; starting another while
+// one is running returns the running one.
+func (s *Server) handleRepositoryIndexWorkflow(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "workflow endpoints are restricted to localhost")
+	}
+
+	var req RepositoryIndexWorkflowRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.ProjectPath == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "project_path field is required")
+	}
+	if req.MaxFileSize < 0 || req.BatchSize < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "max_file_size and batch_size cannot be negative")
+	}
+
+	validPath, tenantID, err := resolveTenant(req.ProjectPath, req.TenantID)
+	if err != nil {
+		return err
+	}
+	projectID, err := projectIDFromPath(validPath)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_path")
+	}
+
+	return s.startWorkflow(c, "repository index", "repository-index-"+tenantID+"-"+projectID,
+		workflows.RepositoryIndexWorkflow, workflows.RepositoryIndexConfig{
+			Path:            validPath,
+			TenantID:        tenantID,
+			Branch:          req.Branch,
+			IncludePatterns: req.IncludePatterns,
+			ExcludePatterns: req.ExcludePatterns,
+			MaxFileSize:     req.MaxFileSize,
+			BatchSize:       req.BatchSize,
+		})
+}
+
+// handleMemoryConsolidationWorkflow starts a durable memory consolidation
+// run. Only one run per project is active at a time.
+func (s *Server) handleMemoryConsolidationWorkflow(c echo.Context) error {
+	if !isLoopbackRequest(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "workflow endpoints are restricted to localhost")
+	}
+
+	var req MemoryConsolidationWorkflowRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.ProjectID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "project_id field is required")
+	}
+	if err := sanitize.ValidateProjectID(req.ProjectID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid project_id")
+	}
+	if req.SimilarityThreshold < 0 || req.SimilarityThreshold > 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "similarity_threshold must be between 0 and 1")
+	}
+	outcome := reasoningbank.Outcome(req.Outcome)
+	if outcome != "" && outcome != reasoningbank.OutcomeSuccess && outcome != reasoningbank.OutcomeFailure {
+		return echo.NewHTTPError(http.StatusBadRequest, "outcome must be 'success' or 'failure'")
+	}
+
+	return s.startWorkflow(c, "memory consolidation", "memory-consolidation-"+req.ProjectID,
+		workflows.MemoryConsolidationWorkflow, workflows.MemoryConsolidationConfig{
+			ProjectID: req.ProjectID,
+			Options: reasoningbank.ConsolidationOptions{
+				SimilarityThreshold: req.SimilarityThreshold,
+				MaxClustersPerRun:   req.MaxClusters,
+				Tags:                req.Tags,
+				Outcome:             outcome,
+				Query:               req.Query,
+				DryRun:              req.DryRun,
+			},
+		})
+}
+
+// startWorkflow starts workflow with config under id and responds with the
+// run it started or joined.
+func (s *Server) startWorkflow(c echo.Context, kind, id string, workflow, config interface{}) error {
+	if s.workflows == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "workflows not configured")
+	}
+	if err := s.readOnly.Check(kind); err != nil {
+		return err
+	}
+
+	taskQueue := s.config.WorkflowTaskQueue
+	if taskQueue == "" {
+		taskQueue = workflows.DefaultTaskQueue
+	}
+	run, err := s.workflows.ExecuteWorkflow(c.Request().Context(), client.StartWorkflowOptions{
+		ID:        id,
+		TaskQueue: taskQueue,
+	}, workflow, config)
+	if err != nil {
+		s.logger.Error("failed to start workflow", zap.String("workflow_id", id), zap.Error(err))
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "failed to start "+kind+" workflow")
+	}
+
+	s.logger.Info("workflow started",
+		zap.String("workflow_id", run.GetID()),
+		zap.String("run_id", run.GetRunID()))
+	return c.JSON(http.StatusAccepted, WorkflowStartResponse{
+		WorkflowID: run.GetID(),
+		RunID:      run.GetRunID(),
+	})
+}
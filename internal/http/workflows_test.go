@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+	"github.com/fyrsmithlabs/contextd/internal/workflows"
+)
+
+// fakeWorkflowRun is a started workflow run.
+type fakeWorkflowRun struct {
+	id string
+}
+
+func (r *fakeWorkflowRun) GetID() string    { return r.id }
+func (r *fakeWorkflowRun) GetRunID() string { return "run-1" }
+func (r *fakeWorkflowRun) Get(ctx context.Context, valuePtr interface{}) error {
+	return nil
+}
+func (r *fakeWorkflowRun) GetWithOptions(ctx context.Context, valuePtr interface{}, options client.WorkflowRunGetOptions) error {
+	return nil
+}
+
+// fakeWorkflowStarter records the workflows it is asked to start.
+type fakeWorkflowStarter struct {
+	options client.StartWorkflowOptions
+	args    []interface{}
+	err     error
+}
+
+func (f *fakeWorkflowStarter) ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.options = options
+	f.args = args
+	return &fakeWorkflowRun{id: options.ID}, nil
+}
+
+func workflowRequest(t *testing.T, server *Server, path, remoteAddr string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleRepositoryIndexWorkflow(t *testing.T) {
+	starter := &fakeWorkflowStarter{}
+	server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Workflows: starter})
+	require.NoError(t, err)
+
+	t.Run("starts workflow", func(t *testing.T) {
+		dir := t.TempDir()
+		rec := workflowRequest(t, server, "/api/v1/workflows/repository-index", "127.0.0.1:1234", RepositoryIndexWorkflowRequest{
+			ProjectPath: dir,
+			TenantID:    "testuser",
+			BatchSize:   50,
+		})
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+		var resp WorkflowStartResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Contains(t, resp.WorkflowID, "repository-index-testuser-")
+		assert.Equal(t, "run-1", resp.RunID)
+		assert.Equal(t, workflows.DefaultTaskQueue, starter.options.TaskQueue)
+
+		require.Len(t, starter.args, 1)
+		config := starter.args[0].(workflows.RepositoryIndexConfig)
+		assert.Equal(t, dir, config.Path)
+		assert.Equal(t, 50, config.BatchSize)
+	})
+
+	t.Run("restricted to localhost", func(t *testing.T) {
+		rec := workflowRequest(t, server, "/api/v1/workflows/repository-index", "192.0.2.1:1234", RepositoryIndexWorkflowRequest{
+			ProjectPath: t.TempDir(),
+		})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("requires project path", func(t *testing.T) {
+		rec := workflowRequest(t, server, "/api/v1/workflows/repository-index", "127.0.0.1:1234", RepositoryIndexWorkflowRequest{})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandleMemoryConsolidationWorkflow(t *testing.T) {
+	t.Run("starts workflow", func(t *testing.T) {
+		starter := &fakeWorkflowStarter{}
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Workflows: starter, WorkflowTaskQueue: "custom-queue"})
+		require.NoError(t, err)
+
+		rec := workflowRequest(t, server, "/api/v1/workflows/memory-consolidation", "127.0.0.1:1234", MemoryConsolidationWorkflowRequest{
+			ProjectID:   "contextd",
+			MaxClusters: 5,
+			DryRun:      true,
+		})
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		assert.Equal(t, "memory-consolidation-contextd", starter.options.ID)
+		assert.Equal(t, "custom-queue", starter.options.TaskQueue)
+
+		config := starter.args[0].(workflows.MemoryConsolidationConfig)
+		assert.Equal(t, 5, config.Options.MaxClustersPerRun)
+		assert.True(t, config.Options.DryRun)
+	})
+
+	t.Run("invalid outcome", func(t *testing.T) {
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{Workflows: &fakeWorkflowStarter{}})
+		require.NoError(t, err)
+
+		rec := workflowRequest(t, server, "/api/v1/workflows/memory-consolidation", "127.0.0.1:1234", MemoryConsolidationWorkflowRequest{
+			ProjectID: "contextd",
+			Outcome:   "maybe",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{})
+		require.NoError(t, err)
+
+		rec := workflowRequest(t, server, "/api/v1/workflows/memory-consolidation", "127.0.0.1:1234", MemoryConsolidationWorkflowRequest{ProjectID: "contextd"})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{
+			Workflows: &fakeWorkflowStarter{},
+			ReadOnly:  readonly.New(true, "maintenance"),
+		})
+		require.NoError(t, err)
+
+		rec := workflowRequest(t, server, "/api/v1/workflows/memory-consolidation", "127.0.0.1:1234", MemoryConsolidationWorkflowRequest{ProjectID: "contextd"})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("temporal unavailable", func(t *testing.T) {
+		server, err := NewServer(&mockRegistry{}, zap.NewNop(), &Config{
+			Workflows: &fakeWorkflowStarter{err: errors.New("connection refused")},
+		})
+		require.NoError(t, err)
+
+		rec := workflowRequest(t, server, "/api/v1/workflows/memory-consolidation", "127.0.0.1:1234", MemoryConsolidationWorkflowRequest{ProjectID: "contextd"})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
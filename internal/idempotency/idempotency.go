@@ -0,0 +1,175 @@
+// Package idempotency deduplicates retried write operations.
+//
+// A client attaches a key to a write. The first call with that key runs;
+// later calls with the same key within the window return the first call's
+// result instead of writing again, so an agent that retries after a network
+// error does not create duplicate memories or double-count feedback.
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// DefaultWindow is how long a completed result is replayed for when no
+// window is configured.
+const DefaultWindow = 15 * time.Minute
+
+// DefaultMaxEntries is how many keys are remembered when no bound is
+// configured.
+const DefaultMaxEntries = 10000
+
+// MaxKeyLength bounds client-supplied keys.
+const MaxKeyLength = 255
+
+var (
+	// ErrKeyReused is returned when a key is reused with a different request.
+	ErrKeyReused = ctxerrors.New(ctxerrors.CodeInvalidInput, "idempotency key was already used for a different request")
+
+	// ErrInvalidKey is returned for keys longer than MaxKeyLength.
+	ErrInvalidKey = ctxerrors.New(ctxerrors.CodeInvalidInput, "idempotency key is too long")
+)
+
+// entry is one key's state. done is closed once the first call finishes.
+type entry[V any] struct {
+	key         string
+	fingerprint string
+	done        chan struct{}
+	value       V
+	ok          bool
+	expires     time.Time
+	elem        *list.Element // position in Cache.completed once ok
+}
+
+// Cache remembers successful results by scope and key for a fixed window,
+// up to a maximum number of keys. It is safe for concurrent use.
+type Cache[V any] struct {
+	window     time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry[V]
+
+	// completed holds stored entries oldest first. The window is fixed, so
+	// this is also expiry order.
+	completed list.List
+}
+
+// New creates a Cache that replays results for window and remembers at most
+// maxEntries keys, evicting the oldest stored result to make room. A
+// non-positive window uses DefaultWindow and a non-positive maxEntries uses
+// DefaultMaxEntries.
+func New[V any](window time.Duration, maxEntries int) *Cache[V] {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Cache[V]{
+		window:     window,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*entry[V]),
+	}
+}
+
+// Do runs fn once for key within scope. Scope separates callers that must
+// never share results, such as different tools or tenants, so the same key
+// in another scope runs independently. A later call with the same scope, key
+// and fingerprint returns the stored value with replayed set. A call that
+// arrives while the first is still running waits for it.
+//
+// Only successful results are stored: if fn fails, its value and error are
+// returned and the next call with the key runs fn again. Reusing a key with
+// a different fingerprint fails with ErrKeyReused.
+func (c *Cache[V]) Do(ctx context.Context, scope, key, fingerprint string, fn func() (V, error)) (value V, replayed bool, err error) {
+	if len(key) > MaxKeyLength {
+		return value, false, ErrInvalidKey
+	}
+	scoped := scope + "\x00" + key
+	for {
+		c.mu.Lock()
+		c.sweepLocked()
+		e, found := c.entries[scoped]
+		if !found {
+			c.evictLocked()
+			e = &entry[V]{key: scoped, fingerprint: fingerprint, done: make(chan struct{})}
+			c.entries[scoped] = e
+			c.mu.Unlock()
+			return c.run(e, fn)
+		}
+		c.mu.Unlock()
+
+		if e.fingerprint != fingerprint {
+			return value, false, ErrKeyReused
+		}
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return value, false, ctx.Err()
+		}
+		if e.ok {
+			return e.value, true, nil
+		}
+		// The first call failed and released the key; try to claim it
+	}
+}
+
+// run executes fn for a claimed entry and publishes the outcome.
+func (c *Cache[V]) run(e *entry[V], fn func() (V, error)) (V, bool, error) {
+	value, err := fn()
+	c.mu.Lock()
+	if err == nil {
+		e.value = value
+		e.ok = true
+		e.expires = c.now().Add(c.window)
+		e.elem = c.completed.PushBack(e)
+	} else if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+	c.mu.Unlock()
+	close(e.done)
+	return value, false, err
+}
+
+// sweepLocked drops expired entries.
+func (c *Cache[V]) sweepLocked() {
+	now := c.now()
+	for front := c.completed.Front(); front != nil; front = c.completed.Front() {
+		e := front.Value.(*entry[V])
+		if !now.After(e.expires) {
+			return
+		}
+		c.removeLocked(e)
+	}
+}
+
+// evictLocked drops the oldest stored entries until a new key fits. Calls
+// still running are never evicted, so a burst of concurrent calls may
+// briefly exceed the bound.
+func (c *Cache[V]) evictLocked() {
+	for len(c.entries) >= c.maxEntries && c.completed.Len() > 0 {
+		c.removeLocked(c.completed.Front().Value.(*entry[V]))
+	}
+}
+
+// removeLocked forgets a stored entry.
+func (c *Cache[V]) removeLocked(e *entry[V]) {
+	c.completed.Remove(e.elem)
+	if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+}
+
+// Len returns the number of stored and in-flight keys.
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,150 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Do(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("replays successful results", func(t *testing.T) {
+		c := New[string](time.Minute, 0)
+		calls := 0
+		fn := func() (string, error) {
+			calls++
+			return "mem-1", nil
+		}
+
+		v, replayed, err := c.Do(ctx, "memory_record", "k", "fp", fn)
+		require.NoError(t, err)
+		assert.Equal(t, "mem-1", v)
+		assert.False(t, replayed)
+
+		v, replayed, err = c.Do(ctx, "memory_record", "k", "fp", fn)
+		require.NoError(t, err)
+		assert.Equal(t, "mem-1", v)
+		assert.True(t, replayed)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("rejects reuse with a different request", func(t *testing.T) {
+		c := New[string](time.Minute, 0)
+		_, _, err := c.Do(ctx, "memory_record", "k", "fp-1", func() (string, error) { return "a", nil })
+		require.NoError(t, err)
+
+		_, _, err = c.Do(ctx, "memory_record", "k", "fp-2", func() (string, error) { return "b", nil })
+		assert.ErrorIs(t, err, ErrKeyReused)
+	})
+
+	t.Run("does not store failures", func(t *testing.T) {
+		c := New[string](time.Minute, 0)
+		boom := errors.New("store unavailable")
+
+		_, _, err := c.Do(ctx, "memory_record", "k", "fp", func() (string, error) { return "", boom })
+		assert.ErrorIs(t, err, boom)
+		assert.Equal(t, 0, c.Len())
+
+		v, replayed, err := c.Do(ctx, "memory_record", "k", "fp", func() (string, error) { return "ok", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "ok", v)
+		assert.False(t, replayed)
+	})
+
+	t.Run("concurrent duplicates run once", func(t *testing.T) {
+		c := New[int](time.Minute, 0)
+		var calls atomic.Int32
+		release := make(chan struct{})
+
+		var wg sync.WaitGroup
+		results := make([]int, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				v, _, err := c.Do(ctx, "memory_record", "k", "fp", func() (int, error) {
+					calls.Add(1)
+					<-release
+					return 42, nil
+				})
+				assert.NoError(t, err)
+				results[i] = v
+			}(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, []int{42, 42, 42, 42, 42}, results)
+	})
+
+	t.Run("expires after the window", func(t *testing.T) {
+		c := New[string](time.Minute, 0)
+		now := time.Now()
+		c.now = func() time.Time { return now }
+
+		_, _, err := c.Do(ctx, "memory_record", "k", "fp", func() (string, error) { return "first", nil })
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Minute)
+		v, replayed, err := c.Do(ctx, "memory_record", "k", "fp-other", func() (string, error) { return "second", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "second", v)
+		assert.False(t, replayed)
+	})
+
+	t.Run("keys are scoped", func(t *testing.T) {
+		c := New[string](time.Minute, 0)
+		_, _, err := c.Do(ctx, "tenant-a", "k", "fp-1", func() (string, error) { return "a", nil })
+		require.NoError(t, err)
+		v, replayed, err := c.Do(ctx, "tenant-b", "k", "fp-2", func() (string, error) { return "b", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "b", v)
+		assert.False(t, replayed)
+	})
+
+	t.Run("evicts the oldest result when full", func(t *testing.T) {
+		c := New[string](time.Minute, 2)
+		for _, key := range []string{"k1", "k2", "k3"} {
+			_, _, err := c.Do(ctx, "memory_record", key, "fp", func() (string, error) { return key, nil })
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, c.Len())
+
+		_, replayed, err := c.Do(ctx, "memory_record", "k3", "fp", func() (string, error) { return "", nil })
+		require.NoError(t, err)
+		assert.True(t, replayed)
+		v, replayed, err := c.Do(ctx, "memory_record", "k1", "fp", func() (string, error) { return "again", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "again", v)
+		assert.False(t, replayed)
+	})
+
+	t.Run("sweeps expired results", func(t *testing.T) {
+		c := New[string](time.Minute, 0)
+		now := time.Now()
+		c.now = func() time.Time { return now }
+		_, _, err := c.Do(ctx, "memory_record", "k1", "fp", func() (string, error) { return "a", nil })
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Minute)
+		_, _, err = c.Do(ctx, "memory_record", "k2", "fp", func() (string, error) { return "b", nil })
+		require.NoError(t, err)
+		assert.Equal(t, 1, c.Len())
+	})
+
+	t.Run("rejects long keys", func(t *testing.T) {
+		c := New[string](0, 0)
+		_, _, err := c.Do(ctx, "memory_record", strings.Repeat("k", MaxKeyLength+1), "fp", func() (string, error) { return "", nil })
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
@@ -0,0 +1,148 @@
+// Package injection flags likely prompt-injection payloads in content that
+// contextd stores and later serves to agents: memories, remediations and
+// checkpoints.
+//
+// Content is returned to agents verbatim by searches and resumes, so text
+// that tells the model to ignore its instructions, impersonates a system
+// prompt or smuggles tool calls can hijack a future session. Services scan
+// content when it is recorded and quarantine flagged items for review
+// instead of serving them.
+//
+// Scanning is heuristic: it flags content for a human to look at and does
+// not modify it.
+package injection
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rule IDs reported in Finding.RuleID.
+const (
+	RuleInstructionOverride = "instruction_override"
+	RuleRoleReassignment    = "role_reassignment"
+	RulePromptMarker        = "prompt_marker"
+	RulePromptExfiltration  = "prompt_exfiltration"
+	RuleConcealment         = "concealment"
+	RuleToolInvocation      = "tool_invocation"
+	RuleHiddenCharacters    = "hidden_characters"
+)
+
+// Finding is one suspected injection in scanned content. The matched text
+// is not included so findings can be logged and returned safely.
+type Finding struct {
+	// RuleID identifies the rule that matched.
+	RuleID string `json:"rule_id"`
+
+	// Description explains what the rule looks for.
+	Description string `json:"description"`
+
+	// Line is the 1-based line of the first match.
+	Line int `json:"line"`
+}
+
+// rule is a compiled detection pattern.
+type rule struct {
+	id          string
+	description string
+	pattern     *regexp.Regexp
+}
+
+// defaultRules are ordered from most to least specific. All text patterns
+// are case-insensitive.
+var defaultRules = []rule{
+	{
+		id:          RuleInstructionOverride,
+		description: "tells the model to ignore or replace its instructions",
+		pattern:     regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|bypass)\s+(?:all\s+|any\s+)?(?:of\s+)?(?:the\s+|your\s+|my\s+)?(?:previous|prior|above|earlier|preceding|original|system|existing)\s+(?:instructions?|prompts?|rules|directions|directives|guidelines|context)\b`),
+	},
+	{
+		id:          RuleRoleReassignment,
+		description: "assigns the model a new role or persona",
+		pattern:     regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(?:a|an|the|in|no\s+longer)\b|\bfrom\s+now\s+on,?\s+you\s+(?:are|will|must|should)\b|\b(?:act|behave)\s+as\s+(?:an?\s+)?(?:unrestricted|unfiltered|jailbroken|DAN)\b|\b(?:developer|god|jailbreak)\s+mode\s+(?:enabled|activated|on)\b`),
+	},
+	{
+		id:          RulePromptMarker,
+		description: "contains chat-template or system-prompt markers",
+		pattern:     regexp.MustCompile(`(?im)\[/?(?:SYSTEM|INST)\]|<</?SYS>>|<\|(?:system|user|assistant|im_start|im_end|endoftext)\|>|^\s*#{1,3}\s*(?:system|new\s+instructions?)\s*:?\s*$|^\s*(?:system|assistant)\s*:\s+\S`),
+	},
+	{
+		id:          RulePromptExfiltration,
+		description: "asks the model to reveal its prompt or secrets",
+		pattern:     regexp.MustCompile(`(?i)\b(?:reveal|print|output|repeat|show|leak|send)\s+(?:me\s+)?(?:your|the)\s+(?:full\s+|entire\s+)?(?:system\s+prompt|hidden\s+instructions|initial\s+instructions|api\s+keys?|credentials)\b`),
+	},
+	{
+		id:          RuleConcealment,
+		description: "tells the model to hide actions from the user",
+		pattern:     regexp.MustCompile(`(?i)\b(?:do\s+not|don't|never)\s+(?:tell|inform|alert|notify|mention\s+(?:this\s+|it\s+)?to)\s+the\s+user\b|\bwithout\s+(?:asking|telling|informing|confirming\s+with|notifying)\s+the\s+user\b`),
+	},
+	{
+		id:          RuleToolInvocation,
+		description: "contains tool or function call syntax",
+		pattern:     regexp.MustCompile(`(?i)</?(?:function_calls|invoke|tool_call|tool_use|tool_result|function_results)\b|"type"\s*:\s*"(?:tool_use|function_call)"|"tool_calls"\s*:\s*\[`),
+	},
+	{
+		id:          RuleHiddenCharacters,
+		description: "contains invisible characters that can hide instructions",
+		// Zero-width spaces and joiners used for smuggling, bidi overrides and
+		// Unicode tag characters. U+200D is left out because emoji use it.
+		pattern: regexp.MustCompile(`[\x{200B}\x{200C}\x{2060}-\x{2064}\x{202A}-\x{202E}\x{2066}-\x{2069}\x{E0000}-\x{E007F}]`),
+	},
+}
+
+// Scanner flags likely prompt-injection payloads. It is safe for
+// concurrent use.
+type Scanner struct {
+	rules []rule
+}
+
+// NewScanner creates a Scanner with the built-in rules.
+func NewScanner() *Scanner {
+	return &Scanner{rules: defaultRules}
+}
+
+// Scan returns one finding per rule that matches content, ordered by line.
+// A nil Scanner finds nothing.
+func (s *Scanner) Scan(content string) []Finding {
+	if s == nil || content == "" {
+		return nil
+	}
+
+	var findings []Finding
+	for _, r := range s.rules {
+		loc := r.pattern.FindStringIndex(content)
+		if loc == nil {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      r.id,
+			Description: r.description,
+			Line:        strings.Count(content[:loc[0]], "\n") + 1,
+		})
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// ScanFields scans each field and returns the IDs of the rules that matched
+// any of them, sorted and without duplicates, or nil when none did.
+func (s *Scanner) ScanFields(fields ...string) []string {
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		for _, f := range s.Scan(field) {
+			seen[f.RuleID] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	rules := make([]string, 0, len(seen))
+	for id := range seen {
+		rules = append(rules, id)
+	}
+	sort.Strings(rules)
+	return rules
+}
@@ -0,0 +1,568 @@
+// Package knowledge searches every kind of stored knowledge at once:
+// memories, remediations, checkpoints, indexed conversations and indexed
+// code.
+//
+// It backs the knowledge_search MCP tool and the HTTP endpoint
+// POST /api/v1/knowledge/search, replacing five separate search calls with
+// one. Sources are queried concurrently and their hits merged into a single
+// ranked list. A failing or unavailable source contributes no results and
+// adds a warning rather than failing the call.
+//
+// Raw similarity scores are comparable across sources because every store
+// ranks by cosine similarity, but not every hit is equally useful to an
+// agent. Each result's score is its similarity, clamped to [0, 1], scaled by
+// a per-type weight and, where the source tracks one, its confidence.
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/conversation"
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/fanout"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// DefaultLimit is the number of merged results returned by default.
+	DefaultLimit = 10
+
+	// MaxLimit caps the number of merged results.
+	MaxLimit = 50
+
+	// snippetLength is the maximum snippet length in runes.
+	snippetLength = 300
+
+	// titleLength is the maximum length of a title derived from content.
+	titleLength = 80
+)
+
+// Type identifies the kind of knowledge a result came from.
+type Type string
+
+const (
+	TypeMemory       Type = "memory"
+	TypeRemediation  Type = "remediation"
+	TypeCheckpoint   Type = "checkpoint"
+	TypeConversation Type = "conversation"
+	TypeCode         Type = "code"
+)
+
+// AllTypes lists every searchable type in display order.
+var AllTypes = []Type{TypeMemory, TypeRemediation, TypeCheckpoint, TypeConversation, TypeCode}
+
+// Valid reports whether t is a known type.
+func (t Type) Valid() bool {
+	for _, known := range AllTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// typeWeights scales each type's similarity. Distilled knowledge (memories
+// and remediations) outranks raw material (conversation messages and code)
+// at equal similarity.
+var typeWeights = map[Type]float64{
+	TypeMemory:       1.0,
+	TypeRemediation:  1.0,
+	TypeCheckpoint:   0.9,
+	TypeCode:         0.85,
+	TypeConversation: 0.8,
+}
+
+// conversationWeights refine the conversation weight by document type.
+// Extracted decisions are closer to memories than individual messages are.
+var conversationWeights = map[conversation.DocumentType]float64{
+	conversation.TypeDecision: 1.1,
+	conversation.TypeSummary:  1.05,
+	conversation.TypeMessage:  1.0,
+}
+
+var (
+	// ErrMissingQuery is returned when the request has no query.
+	ErrMissingQuery = ctxerrors.New(ctxerrors.CodeInvalidInput, "query is required")
+
+	// ErrInvalidType is returned when the request names an unknown type.
+	ErrInvalidType = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid knowledge type")
+)
+
+// Request describes a federated search.
+type Request struct {
+	Query string
+
+	// ProjectID scopes memories.
+	ProjectID string
+
+	// TenantID and ProjectPath scope remediations, checkpoints,
+	// conversations and code.
+	TenantID    string
+	TeamID      string
+	ProjectPath string
+
+	// Types restricts the search to these types. Empty searches all.
+	Types []Type
+
+	// Limit caps the merged results (default DefaultLimit, max MaxLimit).
+	Limit int
+
+	// IncludeContent fills Result.Content with the full scrubbed content,
+	// for callers that feed results to a model rather than display them.
+	IncludeContent bool
+}
+
+// Result is one hit from any source.
+type Result struct {
+	Type    Type   `json:"type"`
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+
+	// Content is the full content, set when Request.IncludeContent is.
+	Content string `json:"content,omitempty"`
+
+	// Score is the type-aware score results are ranked by.
+	Score float64 `json:"score"`
+
+	// Similarity is the raw score reported by the source.
+	Similarity float64 `json:"similarity"`
+
+	// Metadata holds type-specific fields, such as a memory's outcome or a
+	// code hit's file path.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Response is the merged, ranked result list.
+type Response struct {
+	Query    string       `json:"query"`
+	Results  []Result     `json:"results"`
+	Counts   map[Type]int `json:"counts"`
+	Warnings []string     `json:"warnings,omitempty"`
+}
+
+// CodeSearcher is the part of the repository service used for code hits.
+type CodeSearcher interface {
+	Search(ctx context.Context, query string, opts repository.SearchOptions) ([]repository.RepoSearchResult, error)
+}
+
+// Sources holds the services searched. Any may be nil, in which case its
+// type is skipped with a warning.
+type Sources struct {
+	Memory        *reasoningbank.Service
+	Remediations  remediation.Service
+	Checkpoints   checkpoint.Service
+	Conversations conversation.ConversationService
+	Code          CodeSearcher
+}
+
+// Searcher runs federated searches over Sources.
+type Searcher struct {
+	sources  Sources
+	scrubber secrets.Scrubber
+	logger   *zap.Logger
+}
+
+// NewSearcher creates a Searcher.
+func NewSearcher(sources Sources, scrubber secrets.Scrubber, logger *zap.Logger) *Searcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Searcher{
+		sources:  sources,
+		scrubber: scrubber,
+		logger:   logger,
+	}
+}
+
+// ParseTypes converts type names into Types, rejecting unknown names.
+func ParseTypes(names []string) ([]Type, error) {
+	types := make([]Type, 0, len(names))
+	for _, name := range names {
+		t := Type(strings.ToLower(strings.TrimSpace(name)))
+		if !t.Valid() {
+			return nil, fmt.Errorf("%w %q: must be one of memory, remediation, checkpoint, conversation, code", ErrInvalidType, name)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// Search queries every requested source concurrently and returns the merged
+// results ranked by score.
+func (s *Searcher) Search(ctx context.Context, req Request) (*Response, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, ErrMissingQuery
+	}
+	if err := validation.Default.Query("query", req.Query); err != nil {
+		return nil, err
+	}
+	for _, t := range req.Types {
+		if !t.Valid() {
+			return nil, fmt.Errorf("%w %q", ErrInvalidType, t)
+		}
+	}
+	if req.Limit <= 0 {
+		req.Limit = DefaultLimit
+	}
+	if req.Limit > MaxLimit {
+		req.Limit = MaxLimit
+	}
+	types := req.Types
+	if len(types) == 0 {
+		types = AllTypes
+	}
+
+	// Memory tools use the project ID as both tenant and project scope
+	memCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.ProjectID,
+		ProjectID: req.ProjectID,
+	})
+	pathCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  req.TenantID,
+		TeamID:    req.TeamID,
+		ProjectID: req.ProjectID,
+	})
+
+	resp := &Response{
+		Query:  req.Query,
+		Counts: make(map[Type]int),
+	}
+
+	var unique []Type
+	seen := make(map[Type]bool)
+	for _, t := range types {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+
+	// Each source fills its own slot; a failed source contributes nothing
+	hits := make([][]Result, len(unique))
+	sources := fanout.New(s.logger, "knowledge search source failed", "type",
+		zap.String("project_id", req.ProjectID))
+	for i, t := range unique {
+		sources.Go(string(t), func() error {
+			found, err := s.searchType(memCtx, pathCtx, t, req)
+			if err != nil {
+				return err
+			}
+			hits[i] = found
+			return nil
+		})
+	}
+	resp.Warnings = sources.Wait()
+
+	var results []Result
+	for _, found := range hits {
+		results = append(results, found...)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Type != results[j].Type {
+			return results[i].Type < results[j].Type
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > req.Limit {
+		results = results[:req.Limit]
+	}
+	if results == nil {
+		results = []Result{}
+	}
+	for _, r := range results {
+		resp.Counts[r.Type]++
+	}
+	resp.Results = results
+	return resp, nil
+}
+
+// searchType fetches up to req.Limit hits of one type, since any one type
+// may fill the whole merged list.
+func (s *Searcher) searchType(memCtx, pathCtx context.Context, t Type, req Request) ([]Result, error) {
+	switch t {
+	case TypeMemory:
+		return s.searchMemories(memCtx, req)
+	case TypeRemediation:
+		return s.searchRemediations(pathCtx, req)
+	case TypeCheckpoint:
+		return s.searchCheckpoints(pathCtx, req)
+	case TypeConversation:
+		return s.searchConversations(pathCtx, req)
+	case TypeCode:
+		return s.searchCode(pathCtx, req)
+	}
+	return nil, fmt.Errorf("%w %q", ErrInvalidType, t)
+}
+
+func (s *Searcher) searchMemories(ctx context.Context, req Request) ([]Result, error) {
+	if s.sources.Memory == nil {
+		return nil, errors.New("memory service unavailable")
+	}
+	if req.ProjectID == "" {
+		return nil, errors.New("project_id required")
+	}
+	scored, err := s.sources.Memory.SearchWithScores(ctx, req.ProjectID, req.Query, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(scored))
+	for _, sm := range scored {
+		content := sm.Memory.Content
+		if sm.Highlight != nil && sm.Highlight.Text != "" {
+			content = sm.Highlight.Text
+		}
+		results = append(results, Result{
+			Type:       TypeMemory,
+			ID:         sm.Memory.ID,
+			Title:      sm.Memory.Title,
+			Snippet:    s.snippet(content),
+			Content:    s.content(req, sm.Memory.Content),
+			Score:      weigh(TypeMemory, sm.Relevance, 1),
+			Similarity: sm.Relevance,
+			Metadata: map[string]interface{}{
+				"outcome":    string(sm.Memory.Outcome),
+				"confidence": sm.Memory.Confidence,
+				"tags":       sm.Memory.Tags,
+			},
+		})
+	}
+	return results, nil
+}
+
+func (s *Searcher) searchRemediations(ctx context.Context, req Request) ([]Result, error) {
+	if s.sources.Remediations == nil {
+		return nil, errors.New("remediation service unavailable")
+	}
+	if req.TenantID == "" {
+		return nil, errors.New("tenant_id or project_path required")
+	}
+	scored, err := s.sources.Remediations.Search(ctx, &remediation.SearchRequest{
+		Query:       req.Query,
+		TenantID:    req.TenantID,
+		TeamID:      req.TeamID,
+		ProjectPath: req.ProjectPath,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(scored))
+	for _, r := range scored {
+		results = append(results, Result{
+			Type:       TypeRemediation,
+			ID:         r.Remediation.ID,
+			Title:      r.Remediation.Title,
+			Snippet:    s.snippet(r.Remediation.Problem + "\n" + r.Remediation.Solution),
+			Content:    s.content(req, r.Remediation.Problem+"\n"+r.Remediation.Solution),
+			Score:      weigh(TypeRemediation, r.Score, confidenceFactor(r.Remediation.Confidence)),
+			Similarity: r.Score,
+			Metadata: map[string]interface{}{
+				"category":   string(r.Remediation.Category),
+				"confidence": r.Remediation.Confidence,
+			},
+		})
+	}
+	return results, nil
+}
+
+func (s *Searcher) searchCheckpoints(ctx context.Context, req Request) ([]Result, error) {
+	if s.sources.Checkpoints == nil {
+		return nil, errors.New("checkpoint service unavailable")
+	}
+	if req.TenantID == "" {
+		return nil, errors.New("tenant_id or project_path required")
+	}
+	scored, err := s.sources.Checkpoints.Search(ctx, &checkpoint.SearchRequest{
+		Query:       req.Query,
+		TenantID:    req.TenantID,
+		TeamID:      req.TeamID,
+		ProjectID:   req.ProjectID,
+		ProjectPath: req.ProjectPath,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(scored))
+	for _, cp := range scored {
+		metadata := map[string]interface{}{
+			"session_id": cp.SessionID,
+			"created_at": cp.CreatedAt.Format(time.RFC3339),
+		}
+		if cp.Annotation != nil && cp.Annotation.Outcome != "" {
+			metadata["outcome"] = string(cp.Annotation.Outcome)
+		}
+		results = append(results, Result{
+			Type:       TypeCheckpoint,
+			ID:         cp.ID,
+			Title:      cp.Name,
+			Snippet:    s.snippet(cp.Summary),
+			Content:    s.content(req, cp.Summary),
+			Score:      weigh(TypeCheckpoint, cp.Score, 1),
+			Similarity: cp.Score,
+			Metadata:   metadata,
+		})
+	}
+	return results, nil
+}
+
+func (s *Searcher) searchConversations(ctx context.Context, req Request) ([]Result, error) {
+	if s.sources.Conversations == nil {
+		return nil, errors.New("conversation service unavailable")
+	}
+	if req.TenantID == "" || req.ProjectPath == "" {
+		return nil, errors.New("project_path required")
+	}
+	found, err := s.sources.Conversations.Search(ctx, conversation.SearchOptions{
+		Query:       req.Query,
+		ProjectPath: req.ProjectPath,
+		TenantID:    req.TenantID,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(found.Results))
+	for _, hit := range found.Results {
+		doc := hit.Document
+		factor, ok := conversationWeights[doc.Type]
+		if !ok {
+			factor = 1
+		}
+		results = append(results, Result{
+			Type:       TypeConversation,
+			ID:         doc.ID,
+			Title:      s.title(doc.Content),
+			Snippet:    s.snippet(doc.Content),
+			Content:    s.content(req, doc.Content),
+			Score:      weigh(TypeConversation, hit.Score, factor),
+			Similarity: hit.Score,
+			Metadata: map[string]interface{}{
+				"document_type": string(doc.Type),
+				"session_id":    doc.SessionID,
+				"timestamp":     doc.Timestamp.Format(time.RFC3339),
+			},
+		})
+	}
+	return results, nil
+}
+
+func (s *Searcher) searchCode(ctx context.Context, req Request) ([]Result, error) {
+	if s.sources.Code == nil {
+		return nil, errors.New("repository service unavailable")
+	}
+	if req.ProjectPath == "" {
+		return nil, errors.New("project_path required")
+	}
+	found, err := s.sources.Code.Search(ctx, req.Query, repository.SearchOptions{
+		ProjectPath: req.ProjectPath,
+		TenantID:    req.TenantID,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(found))
+	for _, hit := range found {
+		result := Result{
+			Type:       TypeCode,
+			ID:         hit.FilePath,
+			Title:      hit.FilePath,
+			Snippet:    s.snippet(hit.Content),
+			Content:    s.content(req, hit.Content),
+			Score:      weigh(TypeCode, float64(hit.Score), 1),
+			Similarity: float64(hit.Score),
+			Metadata: map[string]interface{}{
+				"file_path": hit.FilePath,
+				"branch":    hit.Branch,
+			},
+		}
+		// ADRs and runbooks are cited by title
+		if hit.DocType != "" {
+			result.Title = hit.Title
+			result.Metadata["doc_type"] = hit.DocType
+			if status, ok := hit.Metadata[repository.MetadataDocStatus].(string); ok {
+				result.Metadata["doc_status"] = status
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// weigh clamps similarity to [0, 1] and applies the type weight and a
+// source-specific factor.
+func weigh(t Type, similarity, factor float64) float64 {
+	if similarity < 0 {
+		similarity = 0
+	}
+	if similarity > 1 {
+		similarity = 1
+	}
+	return similarity * typeWeights[t] * factor
+}
+
+// confidenceFactor maps a [0, 1] confidence onto [0.75, 1] so confidence
+// reorders near-ties without burying relevant low-confidence hits.
+func confidenceFactor(confidence float64) float64 {
+	if confidence < 0 {
+		confidence = 0
+	}
+	if confidence > 1 {
+		confidence = 1
+	}
+	return 0.75 + 0.25*confidence
+}
+
+// snippet scrubs content and shortens it to snippetLength runes.
+func (s *Searcher) snippet(content string) string {
+	return truncate(strings.TrimSpace(s.scrub(content)), snippetLength)
+}
+
+// content scrubs the full content when the request asks for it.
+func (s *Searcher) content(req Request, content string) string {
+	if !req.IncludeContent {
+		return ""
+	}
+	return strings.TrimSpace(s.scrub(content))
+}
+
+// title derives a title from the first line of content.
+func (s *Searcher) title(content string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s.scrub(content)), "\n")
+	return truncate(line, titleLength)
+}
+
+func (s *Searcher) scrub(content string) string {
+	if s.scrubber == nil {
+		return content
+	}
+	return s.scrubber.Scrub(content).Scrubbed
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-3]) + "..."
+}
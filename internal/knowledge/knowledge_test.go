@@ -0,0 +1,173 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/conversation"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// fakeRemediations implements only Search.
+type fakeRemediations struct {
+	remediation.Service
+	results []*remediation.ScoredRemediation
+}
+
+func (f *fakeRemediations) Search(ctx context.Context, req *remediation.SearchRequest) ([]*remediation.ScoredRemediation, error) {
+	return f.results, nil
+}
+
+// fakeCheckpoints implements only Search and records the tenant scope it saw.
+type fakeCheckpoints struct {
+	checkpoint.Service
+	results []*checkpoint.ScoredCheckpoint
+	tenant  *vectorstore.TenantInfo
+}
+
+func (f *fakeCheckpoints) Search(ctx context.Context, req *checkpoint.SearchRequest) ([]*checkpoint.ScoredCheckpoint, error) {
+	f.tenant, _ = vectorstore.TenantFromContext(ctx)
+	return f.results, nil
+}
+
+type fakeConversations struct {
+	conversation.ConversationService
+	hits []conversation.SearchHit
+}
+
+func (f *fakeConversations) Search(ctx context.Context, opts conversation.SearchOptions) (*conversation.SearchResult, error) {
+	return &conversation.SearchResult{Query: opts.Query, Results: f.hits}, nil
+}
+
+type fakeCode struct {
+	results []repository.RepoSearchResult
+	err     error
+}
+
+func (f *fakeCode) Search(ctx context.Context, query string, opts repository.SearchOptions) ([]repository.RepoSearchResult, error) {
+	return f.results, f.err
+}
+
+func TestSearcher_Search(t *testing.T) {
+	checkpoints := &fakeCheckpoints{results: []*checkpoint.ScoredCheckpoint{
+		{Checkpoint: &checkpoint.Checkpoint{ID: "cp1", Name: "Auth refactor", Summary: "token refresh", CreatedAt: time.Now()}, Score: 0.9},
+	}}
+	searcher := NewSearcher(Sources{
+		Remediations: &fakeRemediations{results: []*remediation.ScoredRemediation{
+			{Remediation: remediation.Remediation{ID: "r1", Title: "Refresh race", Confidence: 1}, Score: 0.8},
+			{Remediation: remediation.Remediation{ID: "r2", Title: "Stale token", Confidence: 0}, Score: 0.8},
+		}},
+		Checkpoints: checkpoints,
+		Conversations: &fakeConversations{hits: []conversation.SearchHit{
+			{Document: conversation.ConversationDocument{ID: "d1", Type: conversation.TypeDecision, Content: "Use middleware\nfor refresh"}, Score: 0.85},
+			{Document: conversation.ConversationDocument{ID: "m1", Type: conversation.TypeMessage, Content: "token"}, Score: 0.85},
+		}},
+		Code: &fakeCode{results: []repository.RepoSearchResult{
+			{FilePath: "auth/refresh.go", Content: "func Refresh()", Score: 1.5},
+		}},
+	}, nil, zap.NewNop())
+
+	resp, err := searcher.Search(context.Background(), Request{
+		Query:       "token refresh",
+		ProjectID:   "api",
+		TenantID:    "acme",
+		ProjectPath: "/home/user/api",
+	})
+	require.NoError(t, err)
+
+	ids := make([]string, len(resp.Results))
+	for i, r := range resp.Results {
+		ids[i] = r.ID
+	}
+	// code 1.5 clamps to 1 * 0.85; decision 0.85*0.8*1.1; r2 0.8*0.75
+	assert.Equal(t, []string{"auth/refresh.go", "cp1", "r1", "d1", "m1", "r2"}, ids)
+	assert.InDelta(t, 0.85, resp.Results[0].Score, 1e-9)
+	assert.Equal(t, 1.5, resp.Results[0].Similarity)
+	assert.Equal(t, "Use middleware", resp.Results[3].Title)
+	assert.Equal(t, map[Type]int{TypeCode: 1, TypeCheckpoint: 1, TypeRemediation: 2, TypeConversation: 2}, resp.Counts)
+
+	// Memory service is missing
+	assert.Equal(t, []string{"memory: memory service unavailable"}, resp.Warnings)
+
+	require.NotNil(t, checkpoints.tenant)
+	assert.Equal(t, "acme", checkpoints.tenant.TenantID)
+	assert.Equal(t, "api", checkpoints.tenant.ProjectID)
+}
+
+func TestSearcher_Search_TypesAndLimit(t *testing.T) {
+	searcher := NewSearcher(Sources{
+		Remediations: &fakeRemediations{results: []*remediation.ScoredRemediation{
+			{Remediation: remediation.Remediation{ID: "r1", Confidence: 1}, Score: 0.5},
+			{Remediation: remediation.Remediation{ID: "r2", Confidence: 1}, Score: 0.7},
+		}},
+		Code: &fakeCode{err: errors.New("collection missing")},
+	}, nil, zap.NewNop())
+
+	resp, err := searcher.Search(context.Background(), Request{
+		Query:       "q",
+		TenantID:    "acme",
+		ProjectPath: "/home/user/api",
+		Types:       []Type{TypeRemediation, TypeCode},
+		Limit:       1,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "r2", resp.Results[0].ID)
+	assert.Equal(t, []string{"code: collection missing"}, resp.Warnings)
+}
+
+func TestSearcher_Search_IncludeContent(t *testing.T) {
+	long := strings.Repeat("x", snippetLength*2)
+	searcher := NewSearcher(Sources{
+		Code: &fakeCode{results: []repository.RepoSearchResult{
+			{FilePath: "big.go", Content: long, Score: 0.9},
+		}},
+	}, nil, zap.NewNop())
+	req := Request{Query: "q", TenantID: "acme", ProjectPath: "/home/user/api", Types: []Type{TypeCode}}
+
+	resp, err := searcher.Search(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Empty(t, resp.Results[0].Content)
+
+	req.IncludeContent = true
+	resp, err = searcher.Search(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, long, resp.Results[0].Content)
+	assert.Len(t, resp.Results[0].Snippet, snippetLength)
+}
+
+func TestSearcher_Search_Validation(t *testing.T) {
+	searcher := NewSearcher(Sources{}, nil, nil)
+
+	_, err := searcher.Search(context.Background(), Request{Query: " "})
+	assert.True(t, errors.Is(err, ErrMissingQuery))
+
+	_, err = searcher.Search(context.Background(), Request{Query: "q", Types: []Type{"email"}})
+	assert.True(t, errors.Is(err, ErrInvalidType))
+
+	resp, err := searcher.Search(context.Background(), Request{Query: "q"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+	assert.Len(t, resp.Warnings, len(AllTypes))
+}
+
+func TestParseTypes(t *testing.T) {
+	types, err := ParseTypes([]string{"Memory", " code "})
+	require.NoError(t, err)
+	assert.Equal(t, []Type{TypeMemory, TypeCode}, types)
+
+	_, err = ParseTypes([]string{"issues"})
+	assert.True(t, errors.Is(err, ErrInvalidType))
+}
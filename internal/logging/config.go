@@ -3,7 +3,9 @@ package logging
 
 import (
 	"fmt"
+	"os"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/fyrsmithlabs/contextd/internal/config"
@@ -24,8 +26,20 @@ type Config struct {
 
 // OutputConfig controls where logs are written.
 type OutputConfig struct {
-	Stdout bool `koanf:"stdout"`
-	OTEL   bool `koanf:"otel"`
+	Stdout bool             `koanf:"stdout"`
+	OTEL   bool             `koanf:"otel"`
+	File   FileOutputConfig `koanf:"file"`
+}
+
+// FileOutputConfig controls the log file. Logs are written to Path, which
+// is rotated by size and age into files named after it with the rotation
+// time inserted, e.g. contextd-2026-01-02T15-04-05.000.log.
+type FileOutputConfig struct {
+	Path        string          `koanf:"path"`         // Log file; empty disables file output
+	MaxSizeMB   int             `koanf:"max_size_mb"`  // Rotate at this size (default: 100, 0 = no size limit)
+	RotateEvery config.Duration `koanf:"rotate_every"` // Rotate files written for this long (default: 0, never)
+	MaxBackups  int             `koanf:"max_backups"`  // Rotated files kept (default: 7, 0 = all)
+	Compress    bool            `koanf:"compress"`     // Gzip rotated files (default: true)
 }
 
 // SamplingConfig controls log volume reduction.
@@ -67,6 +81,11 @@ func NewDefaultConfig() *Config {
 		Output: OutputConfig{
 			Stdout: true,
 			OTEL:   false,
+			File: FileOutputConfig{
+				MaxSizeMB:  100,
+				MaxBackups: 7,
+				Compress:   true,
+			},
 		},
 		Sampling: SamplingConfig{
 			Enabled: true,
@@ -97,6 +116,46 @@ func NewDefaultConfig() *Config {
 	}
 }
 
+// LoadEnv overrides the file output with the environment variables that are
+// set:
+//   - CONTEXTD_LOGGING_FILE_PATH: Log file (default: none)
+//   - CONTEXTD_LOGGING_FILE_MAX_SIZE_MB: Rotate at this size (default: 100)
+//   - CONTEXTD_LOGGING_FILE_ROTATE_EVERY: Rotate files written for this long, e.g. 24h (default: never)
+//   - CONTEXTD_LOGGING_FILE_MAX_BACKUPS: Rotated files kept (default: 7, 0 = all)
+//   - CONTEXTD_LOGGING_FILE_COMPRESS: Gzip rotated files (default: true)
+func (o *FileOutputConfig) LoadEnv() error {
+	if v := os.Getenv("CONTEXTD_LOGGING_FILE_PATH"); v != "" {
+		o.Path = v
+	}
+	if v := os.Getenv("CONTEXTD_LOGGING_FILE_MAX_SIZE_MB"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("CONTEXTD_LOGGING_FILE_MAX_SIZE_MB: %w", err)
+		}
+		o.MaxSizeMB = n
+	}
+	if v := os.Getenv("CONTEXTD_LOGGING_FILE_ROTATE_EVERY"); v != "" {
+		if err := o.RotateEvery.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("CONTEXTD_LOGGING_FILE_ROTATE_EVERY: %w", err)
+		}
+	}
+	if v := os.Getenv("CONTEXTD_LOGGING_FILE_MAX_BACKUPS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("CONTEXTD_LOGGING_FILE_MAX_BACKUPS: %w", err)
+		}
+		o.MaxBackups = n
+	}
+	if v := os.Getenv("CONTEXTD_LOGGING_FILE_COMPRESS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("CONTEXTD_LOGGING_FILE_COMPRESS: %w", err)
+		}
+		o.Compress = b
+	}
+	return nil
+}
+
 // DefaultLevelSamplingConfig returns default sampling config by level.
 func DefaultLevelSamplingConfig() map[zapcore.Level]LevelSamplingConfig {
 	return map[zapcore.Level]LevelSamplingConfig{
@@ -113,8 +172,17 @@ func (c *Config) Validate() error {
 	if c.Format != "json" && c.Format != "console" {
 		return fmt.Errorf("format must be 'json' or 'console', got %q", c.Format)
 	}
-	if !c.Output.Stdout && !c.Output.OTEL {
-		return fmt.Errorf("at least one output must be enabled (stdout or otel)")
+	if !c.Output.Stdout && !c.Output.OTEL && c.Output.File.Path == "" {
+		return fmt.Errorf("at least one output must be enabled (stdout, otel or file)")
+	}
+	if c.Output.File.MaxSizeMB < 0 {
+		return fmt.Errorf("file max_size_mb must be >= 0, got %d", c.Output.File.MaxSizeMB)
+	}
+	if c.Output.File.RotateEvery < 0 {
+		return fmt.Errorf("file rotate_every must be >= 0, got %s", c.Output.File.RotateEvery.Duration())
+	}
+	if c.Output.File.MaxBackups < 0 {
+		return fmt.Errorf("file max_backups must be >= 0, got %d", c.Output.File.MaxBackups)
 	}
 	if c.Sampling.Enabled && c.Sampling.Tick.Duration() <= 0 {
 		return fmt.Errorf("sampling tick must be > 0 when sampling enabled")
@@ -56,6 +56,25 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "at least one output must be enabled",
 		},
+		{
+			name: "file output only",
+			config: &Config{
+				Level:  zapcore.InfoLevel,
+				Format: "json",
+				Output: OutputConfig{File: FileOutputConfig{Path: "/var/log/contextd.log"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative file max backups",
+			config: &Config{
+				Level:  zapcore.InfoLevel,
+				Format: "json",
+				Output: OutputConfig{Stdout: true, File: FileOutputConfig{Path: "/var/log/contextd.log", MaxBackups: -1}},
+			},
+			wantErr: true,
+			errMsg:  "file max_backups must be >= 0",
+		},
 		{
 			name: "invalid sampling tick",
 			config: &Config{
@@ -80,6 +80,18 @@
 //	tl.AssertField(t, "test message", "key", "value")
 //	tl.AssertNoSecrets(t)
 //
+// # File Output
+//
+// Set Output.File.Path to also write logs to a file, rotated once it
+// reaches MaxSizeMB or has been written for RotateEvery. The newest
+// MaxBackups rotated files are kept, gzipped when Compress is set. Close the
+// logger at exit so the last rotation finishes compressing:
+//
+//	cfg.Output.File.Path = "/var/log/contextd/contextd.log"
+//	cfg.Output.File.RotateEvery = config.Duration(24 * time.Hour)
+//	logger, err := logging.NewLogger(cfg, nil)
+//	defer logger.Close()
+//
 // # Concurrency Safety
 //
 // Logger is safe for concurrent use. Child loggers (With, Named) are
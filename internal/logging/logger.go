@@ -16,6 +16,7 @@ import (
 type Logger struct {
 	zap    *zap.Logger
 	config *Config
+	file   *rotatingFile // nil without a file output
 }
 
 // NewLogger creates a logger from config.
@@ -25,8 +26,21 @@ func NewLogger(cfg *Config, otelProvider log.LoggerProvider) (*Logger, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	core, err := newDualCore(cfg, otelProvider)
+	var file *rotatingFile
+	var fileSyncer zapcore.WriteSyncer
+	if cfg.Output.File.Path != "" {
+		var err error
+		if file, err = newRotatingFile(cfg.Output.File); err != nil {
+			return nil, err
+		}
+		fileSyncer = file
+	}
+
+	core, err := newDualCore(cfg, otelProvider, fileSyncer)
 	if err != nil {
+		if file != nil {
+			file.Close()
+		}
 		return nil, fmt.Errorf("failed to create core: %w", err)
 	}
 
@@ -53,6 +67,7 @@ func NewLogger(cfg *Config, otelProvider log.LoggerProvider) (*Logger, error) {
 	return &Logger{
 		zap:    zapLogger,
 		config: cfg,
+		file:   file,
 	}, nil
 }
 
@@ -138,6 +153,19 @@ func (l *Logger) Sync() error {
 	return err
 }
 
+// Close flushes buffered entries and closes the log file, waiting for
+// rotated files to be compressed. Loggers derived with With or Named share
+// the file and must not be used afterwards.
+func (l *Logger) Close() error {
+	err := l.Sync()
+	if l.file != nil {
+		if closeErr := l.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
 // Underlying returns the underlying zap.Logger.
 // Useful when integrating with libraries that require a *zap.Logger.
 func (l *Logger) Underlying() *zap.Logger {
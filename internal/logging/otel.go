@@ -10,9 +10,10 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// newDualCore creates core with stdout and/or OTEL outputs.
-func newDualCore(cfg *Config, otelProvider log.LoggerProvider) (zapcore.Core, error) {
-	cores := make([]zapcore.Core, 0, 2)
+// newDualCore creates core with stdout, OTEL and/or file outputs. file is
+// nil without a file output.
+func newDualCore(cfg *Config, otelProvider log.LoggerProvider, file zapcore.WriteSyncer) (zapcore.Core, error) {
+	cores := make([]zapcore.Core, 0, 3)
 
 	if cfg.Output.Stdout {
 		baseEncoder := newEncoder(cfg.Format)
@@ -24,6 +25,14 @@ func newDualCore(cfg *Config, otelProvider log.LoggerProvider) (zapcore.Core, er
 		cores = append(cores, zapcore.NewCore(encoder, writer, cfg.Level))
 	}
 
+	if file != nil {
+		encoder, err := NewRedactingEncoder(newEncoder(cfg.Format), cfg.Redaction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redacting encoder: %w", err)
+		}
+		cores = append(cores, zapcore.NewCore(encoder, file, cfg.Level))
+	}
+
 	if cfg.Output.OTEL && otelProvider != nil {
 		otelCore := otelzap.NewCore("contextd",
 			otelzap.WithLoggerProvider(otelProvider),
@@ -12,7 +12,7 @@ func TestNewDualCore_StdoutOnly(t *testing.T) {
 	cfg.Output.Stdout = true
 	cfg.Output.OTEL = false
 
-	core, err := newDualCore(cfg, nil)
+	core, err := newDualCore(cfg, nil, nil)
 	require.NoError(t, err)
 	assert.NotNil(t, core)
 }
@@ -24,7 +24,7 @@ func TestNewDualCore_BothOutputs(t *testing.T) {
 
 	// For testing, pass nil provider
 	// In production, would provide real OTEL provider
-	core, err := newDualCore(cfg, nil)
+	core, err := newDualCore(cfg, nil, nil)
 
 	// Should succeed with stdout, skip OTEL if provider nil
 	require.NoError(t, err)
@@ -36,7 +36,7 @@ func TestNewDualCore_NoOutputs(t *testing.T) {
 	cfg.Output.Stdout = false
 	cfg.Output.OTEL = false
 
-	_, err := newDualCore(cfg, nil)
+	_, err := newDualCore(cfg, nil, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "at least one output")
 }
@@ -0,0 +1,241 @@
+// internal/logging/rotate.go
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated files, e.g. contextd-2026-01-02T15-04-05.000.log.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is a log file that is moved aside once it reaches MaxSizeMB
+// or has been written for RotateEvery. Rotated files are gzipped when
+// Compress is set, and all but the newest MaxBackups are removed.
+type rotatingFile struct {
+	cfg FileOutputConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	millMu sync.Mutex     // serializes compression and cleanup
+	mills  sync.WaitGroup // running compression and cleanup
+}
+
+// newRotatingFile opens cfg.Path for appending, creating it and its
+// directory if needed.
+func newRotatingFile(cfg FileOutputConfig) (*rotatingFile, error) {
+	f := &rotatingFile{cfg: cfg, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the log file and records its size.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	f.file, f.size, f.openedAt = file, info.Size(), f.now()
+	return nil
+}
+
+// Write implements zapcore.WriteSyncer, rotating first when p would take
+// the file past its size or the file is due for time-based rotation.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.dueForRotation(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// dueForRotation reports whether the file must be rotated before writing n
+// bytes. Empty files are never rotated.
+func (f *rotatingFile) dueForRotation(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if maxSize := int64(f.cfg.MaxSizeMB) << 20; maxSize > 0 && f.size+int64(n) > maxSize {
+		return true
+	}
+	return f.cfg.RotateEvery > 0 && f.now().Sub(f.openedAt) >= f.cfg.RotateEvery.Duration()
+}
+
+// rotate moves the current file aside, opens a new one and compresses and
+// cleans up rotated files in the background.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	f.file = nil
+
+	backup := f.backupName(f.now())
+	if err := os.Rename(f.cfg.Path, backup); err != nil {
+		// Keep writing to the current file rather than losing entries
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.mills.Add(1)
+	go func() {
+		defer f.mills.Done()
+		f.millMu.Lock()
+		defer f.millMu.Unlock()
+		if f.cfg.Compress {
+			// A failed compression leaves the rotated file as it is
+			_ = compressFile(backup)
+		}
+		f.removeOldBackups()
+	}()
+	return nil
+}
+
+// backupName returns the name of the file rotated at t: the log file's
+// name with the time inserted before the extension.
+func (f *rotatingFile) backupName(t time.Time) string {
+	dir, base := filepath.Split(f.cfg.Path)
+	ext := filepath.Ext(base)
+	return filepath.Join(dir, strings.TrimSuffix(base, ext)+"-"+t.Format(backupTimeFormat)+ext)
+}
+
+// removeOldBackups deletes all but the newest MaxBackups rotated files.
+// Zero keeps every rotated file.
+func (f *rotatingFile) removeOldBackups() {
+	if f.cfg.MaxBackups <= 0 {
+		return
+	}
+	backups := f.backups()
+	if len(backups) <= f.cfg.MaxBackups {
+		return
+	}
+	for _, path := range backups[f.cfg.MaxBackups:] {
+		_ = os.Remove(path)
+	}
+}
+
+// backups returns the rotated files of the log file, newest first.
+func (f *rotatingFile) backups() []string {
+	dir, base := filepath.Split(f.cfg.Path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil
+	}
+	type backup struct {
+		path string
+		at   time.Time
+	}
+	var found []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		at, err := time.Parse(backupTimeFormat, strings.TrimPrefix(stamp, prefix))
+		if err != nil {
+			continue
+		}
+		found = append(found, backup{path: filepath.Join(dir, name), at: at})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].at.After(found[j].at) })
+
+	paths := make([]string, len(found))
+	for i, b := range found {
+		paths[i] = b.path
+	}
+	return paths
+}
+
+// compressFile gzips path to path.gz and removes path.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(path + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file and waits for background compression and cleanup.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.mills.Wait()
+	return err
+}
@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/config"
+)
+
+// logFiles returns the names of the files in dir, sorted.
+func logFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingFile(t *testing.T) {
+	t.Run("rotates by size and keeps max backups", func(t *testing.T) {
+		dir := t.TempDir()
+		f, err := newRotatingFile(FileOutputConfig{Path: filepath.Join(dir, "contextd.log"), MaxSizeMB: 1, MaxBackups: 2})
+		require.NoError(t, err)
+		clock := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+		f.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+		line := []byte(strings.Repeat("x", 1023) + "\n")
+		for range 4 * 1024 { // 4 MiB, so three rotations
+			_, err := f.Write(line)
+			require.NoError(t, err)
+		}
+		require.NoError(t, f.Close())
+
+		// Three rotations, of which the two newest are kept
+		files := logFiles(t, dir)
+		require.Len(t, files, 3)
+		assert.Equal(t, "contextd.log", files[2])
+		assert.Equal(t, f.backups(), []string{filepath.Join(dir, files[1]), filepath.Join(dir, files[0])})
+		for _, name := range files {
+			info, err := os.Stat(filepath.Join(dir, name))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1<<20), info.Size(), name)
+		}
+	})
+
+	t.Run("rotates by age", func(t *testing.T) {
+		dir := t.TempDir()
+		f, err := newRotatingFile(FileOutputConfig{
+			Path:        filepath.Join(dir, "contextd.log"),
+			RotateEvery: config.Duration(24 * time.Hour),
+		})
+		require.NoError(t, err)
+		clock := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+		f.now = func() time.Time { return clock }
+		f.openedAt = clock
+
+		_, err = f.Write([]byte("day one\n"))
+		require.NoError(t, err)
+		clock = clock.Add(23 * time.Hour)
+		_, err = f.Write([]byte("still day one\n"))
+		require.NoError(t, err)
+		clock = clock.Add(time.Hour)
+		_, err = f.Write([]byte("day two\n"))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		assert.Equal(t, []string{"contextd-2026-01-03T00-00-00.000.log", "contextd.log"}, logFiles(t, dir))
+		rotated, err := os.ReadFile(filepath.Join(dir, "contextd-2026-01-03T00-00-00.000.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "day one\nstill day one\n", string(rotated))
+	})
+
+	t.Run("compresses rotated files", func(t *testing.T) {
+		dir := t.TempDir()
+		f, err := newRotatingFile(FileOutputConfig{
+			Path:        filepath.Join(dir, "contextd.log"),
+			RotateEvery: config.Duration(time.Hour),
+			Compress:    true,
+		})
+		require.NoError(t, err)
+		clock := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+		f.now = func() time.Time { return clock }
+		f.openedAt = clock
+
+		_, err = f.Write([]byte("first hour\n"))
+		require.NoError(t, err)
+		clock = clock.Add(time.Hour)
+		_, err = f.Write([]byte("second hour\n"))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		assert.Equal(t, []string{"contextd-2026-01-02T01-00-00.000.log.gz", "contextd.log"}, logFiles(t, dir))
+		gzFile, err := os.Open(filepath.Join(dir, "contextd-2026-01-02T01-00-00.000.log.gz"))
+		require.NoError(t, err)
+		defer gzFile.Close()
+		gz, err := gzip.NewReader(gzFile)
+		require.NoError(t, err)
+		content, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, "first hour\n", string(content))
+	})
+}
+
+func TestNewLogger_FileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "contextd.log")
+	cfg := NewDefaultConfig()
+	cfg.Output.Stdout = false
+	cfg.Output.File.Path = path
+
+	logger, err := NewLogger(cfg, nil)
+	require.NoError(t, err)
+	logger.Underlying().Info("written to file", zap.String("component", "test"))
+	require.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"msg":"written to file"`)
+	assert.Contains(t, string(content), `"component":"test"`)
+}
+
+func TestFileOutputConfig_LoadEnv(t *testing.T) {
+	t.Setenv("CONTEXTD_LOGGING_FILE_PATH", "/var/log/contextd/contextd.log")
+	t.Setenv("CONTEXTD_LOGGING_FILE_MAX_SIZE_MB", "50")
+	t.Setenv("CONTEXTD_LOGGING_FILE_ROTATE_EVERY", "24h")
+	t.Setenv("CONTEXTD_LOGGING_FILE_MAX_BACKUPS", "30")
+	t.Setenv("CONTEXTD_LOGGING_FILE_COMPRESS", "false")
+
+	out := NewDefaultConfig().Output.File
+	require.NoError(t, out.LoadEnv())
+	assert.Equal(t, FileOutputConfig{
+		Path:        "/var/log/contextd/contextd.log",
+		MaxSizeMB:   50,
+		RotateEvery: config.Duration(24 * time.Hour),
+		MaxBackups:  30,
+		Compress:    false,
+	}, out)
+
+	t.Setenv("CONTEXTD_LOGGING_FILE_MAX_BACKUPS", "many")
+	assert.Error(t, out.LoadEnv())
+}
@@ -0,0 +1,293 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
+)
+
+// toolAlias accepts a tool's legacy name, and legacy argument names, for a
+// migration window after the tool was renamed.
+type toolAlias struct {
+	Legacy string // name clients used to call
+	Tool   string // name the tool is registered under
+
+	// Params maps legacy argument names to current ones. Arguments not
+	// listed pass through unchanged.
+	Params map[string]string
+
+	Since     string // version that renamed the tool
+	RemovedIn string // version that stops accepting the alias
+}
+
+// toolAliases lists the legacy names of renamed tools. Retire an alias with
+// SERVER_RETIRED_TOOL_ALIASES once its callers have migrated, and delete it
+// here in the release named by RemovedIn.
+var toolAliases = []toolAlias{
+	{
+		Legacy:    "memory_store",
+		Tool:      "memory_record",
+		Since:     "0.1.0",
+		RemovedIn: "1.0.0",
+	},
+	{
+		Legacy:    "checkpoint_restore",
+		Tool:      "checkpoint_resume",
+		Params:    map[string]string{"id": "checkpoint_id"},
+		Since:     "0.1.0",
+		RemovedIn: "1.0.0",
+	},
+}
+
+// Deprecation returns the notice announcing the alias's removal.
+func (a toolAlias) Deprecation() capabilities.Deprecation {
+	notice := fmt.Sprintf("%s was renamed to %s; calls to %s are translated until %s", a.Legacy, a.Tool, a.Legacy, a.RemovedIn)
+	if len(a.Params) > 0 {
+		notice += " (" + describeRenames(a.Params) + ")"
+	}
+	return capabilities.Deprecation{
+		Kind:        capabilities.DeprecatedTool,
+		Name:        a.Legacy,
+		Since:       a.Since,
+		RemovedIn:   a.RemovedIn,
+		Replacement: a.Tool,
+		Notice:      notice,
+	}
+}
+
+// describeRenames lists argument renames as "id is now checkpoint_id".
+func describeRenames(params map[string]string) string {
+	legacy := make([]string, 0, len(params))
+	for name := range params {
+		legacy = append(legacy, name)
+	}
+	sort.Strings(legacy)
+	renames := make([]string, len(legacy))
+	for i, name := range legacy {
+		renames[i] = name + " is now " + params[name]
+	}
+	return strings.Join(renames, ", ")
+}
+
+// aliasState holds the aliases the server accepts and counts their calls.
+type aliasState struct {
+	byLegacy map[string]toolAlias
+	byTool   map[string][]toolAlias
+
+	mu    sync.Mutex
+	calls map[string]int64 // calls per legacy name
+}
+
+// newAliasState returns the aliases in toolAliases, less the retired ones.
+// Disabled accepts none. Retiring an unknown alias is an error, so a typo
+// does not leave the alias accepted.
+func newAliasState(disabled bool, retired []string) (*aliasState, error) {
+	state := &aliasState{
+		byLegacy: make(map[string]toolAlias),
+		byTool:   make(map[string][]toolAlias),
+		calls:    make(map[string]int64),
+	}
+	known := make(map[string]bool, len(toolAliases))
+	for _, alias := range toolAliases {
+		known[alias.Legacy] = true
+	}
+	skip := make(map[string]bool, len(retired))
+	for _, name := range retired {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown tool alias %q", name)
+		}
+		skip[name] = true
+	}
+	if disabled {
+		return state, nil
+	}
+	for _, alias := range toolAliases {
+		if skip[alias.Legacy] {
+			continue
+		}
+		state.byLegacy[alias.Legacy] = alias
+		state.byTool[alias.Tool] = append(state.byTool[alias.Tool], alias)
+	}
+	return state, nil
+}
+
+// count records a call to the alias and returns its calls so far.
+func (a *aliasState) count(legacy string) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls[legacy]++
+	return a.calls[legacy]
+}
+
+// Calls returns the calls made to each alias since the server started.
+func (a *aliasState) Calls() map[string]int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make(map[string]int64, len(a.calls))
+	for name, n := range a.calls {
+		out[name] = n
+	}
+	return out
+}
+
+// ToolDeprecations returns the deprecation notices of the accepted tool
+// aliases, for the capabilities document.
+func (s *Server) ToolDeprecations() []capabilities.Deprecation {
+	notices := []capabilities.Deprecation{}
+	for _, alias := range toolAliases {
+		if _, ok := s.aliases.byLegacy[alias.Legacy]; ok {
+			notices = append(notices, alias.Deprecation())
+		}
+	}
+	return notices
+}
+
+// translateAliases is receiving middleware that serves the legacy names of
+// renamed tools. tools/list advertises each alias next to its tool with the
+// legacy argument names; tools/call rewrites the name and arguments to the
+// current ones, logs the deprecated use with the alias's call count and
+// attaches the deprecation notice to the result as _meta.deprecation. It is
+// added just before trackInFlight, so every other middleware sees the
+// current tool name.
+func (s *Server) translateAliases(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch method {
+		case "tools/list":
+			res, err := next(ctx, method, req)
+			if list, ok := res.(*mcp.ListToolsResult); ok && err == nil {
+				list.Tools = s.withAliasTools(list.Tools)
+			}
+			return res, err
+		case "tools/call":
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				break
+			}
+			alias, ok := s.aliases.byLegacy[call.Params.Name]
+			if !ok {
+				break
+			}
+			args, err := renameArguments(call.Params.Arguments, alias.Params)
+			if err != nil {
+				// Leave malformed arguments for the tool's schema to reject
+				args = call.Params.Arguments
+			}
+			call.Params.Name = alias.Tool
+			call.Params.Arguments = args
+
+			calls := s.aliases.count(alias.Legacy)
+			s.metrics.RecordAliasCall(ctx, alias.Legacy, alias.Tool)
+			s.logger.Warn("deprecated tool alias called",
+				zap.String("alias", alias.Legacy),
+				zap.String("tool", alias.Tool),
+				zap.String("removed_in", alias.RemovedIn),
+				zap.Int64("calls", calls))
+
+			res, err := next(ctx, method, req)
+			if result, ok := res.(*mcp.CallToolResult); ok && err == nil {
+				if result.Meta == nil {
+					result.Meta = mcp.Meta{}
+				}
+				result.Meta["deprecation"] = alias.Deprecation()
+			}
+			return res, err
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// withAliasTools returns tools with each accepted alias listed after the
+// tool it translates to.
+func (s *Server) withAliasTools(tools []*mcp.Tool) []*mcp.Tool {
+	if len(s.aliases.byLegacy) == 0 {
+		return tools
+	}
+	out := make([]*mcp.Tool, 0, len(tools)+len(s.aliases.byLegacy))
+	for _, tool := range tools {
+		out = append(out, tool)
+		for _, alias := range s.aliases.byTool[tool.Name] {
+			out = append(out, aliasTool(tool, alias))
+		}
+	}
+	return out
+}
+
+// aliasTool describes tool under its legacy name and argument names.
+func aliasTool(tool *mcp.Tool, alias toolAlias) *mcp.Tool {
+	legacy := *tool
+	legacy.Name = alias.Legacy
+	legacy.Title = ""
+	legacy.Description = fmt.Sprintf("Deprecated alias of %s, removed in %s; call %s instead. %s", alias.Tool, alias.RemovedIn, alias.Tool, tool.Description)
+	if len(alias.Params) > 0 {
+		if schema, err := legacySchema(tool.InputSchema, alias.Params); err == nil {
+			legacy.InputSchema = schema
+		}
+	}
+	return &legacy
+}
+
+// legacySchema returns the input schema with current argument names
+// replaced by their legacy names.
+func legacySchema(inputSchema any, params map[string]string) (map[string]any, error) {
+	data, err := json.Marshal(inputSchema)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	current := make(map[string]string, len(params))
+	for legacy, name := range params {
+		current[name] = legacy
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for name, legacy := range current {
+			if prop, ok := props[name]; ok {
+				delete(props, name)
+				props[legacy] = prop
+			}
+		}
+	}
+	if required, ok := schema["required"].([]any); ok {
+		for i, name := range required {
+			if s, ok := name.(string); ok {
+				if legacy, ok := current[s]; ok {
+					required[i] = legacy
+				}
+			}
+		}
+	}
+	return schema, nil
+}
+
+// renameArguments rewrites legacy argument names to current ones. A
+// current name given alongside its legacy name wins.
+func renameArguments(raw json.RawMessage, params map[string]string) (json.RawMessage, error) {
+	if len(params) == 0 || len(raw) == 0 {
+		return raw, nil
+	}
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	for legacy, name := range params {
+		value, ok := args[legacy]
+		if !ok {
+			continue
+		}
+		delete(args, legacy)
+		if _, ok := args[name]; !ok {
+			args[name] = value
+		}
+	}
+	return json.Marshal(args)
+}
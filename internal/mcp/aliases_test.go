@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type aliasTestInput struct {
+	CheckpointID string `json:"checkpoint_id" jsonschema:"required,Checkpoint ID to resume"`
+	Level        string `json:"level,omitempty"`
+}
+
+type aliasTestOutput struct {
+	CheckpointID string `json:"checkpoint_id"`
+	Level        string `json:"level"`
+}
+
+func TestTranslateAliases(t *testing.T) {
+	ctx := context.Background()
+
+	connect := func(t *testing.T, disabled bool, retired ...string) (*Server, *mcp.ClientSession, *observer.ObservedLogs) {
+		t.Helper()
+		aliases, err := newAliasState(disabled, retired)
+		require.NoError(t, err)
+		core, logs := observer.New(zapcore.DebugLevel)
+		s := &Server{logger: zap.New(core), metrics: NewMetrics(zap.NewNop()), aliases: aliases}
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+		server.AddReceivingMiddleware(s.errorPayloads)
+		server.AddReceivingMiddleware(s.translateAliases)
+		addTool(server, &mcp.Tool{Name: "checkpoint_resume", Description: "Resume a checkpoint."}, func(ctx context.Context, req *mcp.CallToolRequest, args aliasTestInput) (*mcp.CallToolResult, aliasTestOutput, error) {
+			return nil, aliasTestOutput(args), nil
+		})
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		_, err = server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { session.Close() })
+		return s, session, logs
+	}
+
+	t.Run("lists aliases with legacy arguments", func(t *testing.T) {
+		_, session, _ := connect(t, false)
+
+		list, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, list.Tools, 2)
+		assert.Equal(t, "checkpoint_resume", list.Tools[0].Name)
+
+		legacy := list.Tools[1]
+		assert.Equal(t, "checkpoint_restore", legacy.Name)
+		assert.Contains(t, legacy.Description, "Deprecated alias of checkpoint_resume")
+		schema, err := json.Marshal(legacy.InputSchema)
+		require.NoError(t, err)
+		var parsed struct {
+			Properties map[string]any `json:"properties"`
+			Required   []string       `json:"required"`
+		}
+		require.NoError(t, json.Unmarshal(schema, &parsed))
+		assert.Contains(t, parsed.Properties, "id")
+		assert.NotContains(t, parsed.Properties, "checkpoint_id")
+		assert.Equal(t, []string{"id"}, parsed.Required)
+	})
+
+	t.Run("translates calls and counts them", func(t *testing.T) {
+		s, session, logs := connect(t, false)
+
+		for i := 0; i < 2; i++ {
+			res, err := session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      "checkpoint_restore",
+				Arguments: map[string]any{"id": "cp-1", "level": "summary"},
+			})
+			require.NoError(t, err)
+			require.False(t, res.IsError)
+			assert.Equal(t, map[string]any{"checkpoint_id": "cp-1", "level": "summary"}, res.StructuredContent)
+
+			deprecation, ok := res.Meta["deprecation"].(map[string]any)
+			require.True(t, ok, "deprecation notice in _meta")
+			assert.Equal(t, "checkpoint_resume", deprecation["replacement"])
+			assert.Contains(t, deprecation["notice"], "id is now checkpoint_id")
+		}
+
+		// The current name is not counted
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "checkpoint_resume",
+			Arguments: map[string]any{"checkpoint_id": "cp-1"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]int64{"checkpoint_restore": 2}, s.aliases.Calls())
+		entries := logs.FilterMessage("deprecated tool alias called").All()
+		require.Len(t, entries, 2)
+		assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+		assert.Equal(t, "checkpoint_restore", entries[1].ContextMap()["alias"])
+		assert.Equal(t, int64(2), entries[1].ContextMap()["calls"])
+	})
+
+	t.Run("current argument wins over legacy", func(t *testing.T) {
+		_, session, _ := connect(t, false)
+
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "checkpoint_restore",
+			Arguments: map[string]any{"id": "old", "checkpoint_id": "new"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "new", res.StructuredContent.(map[string]any)["checkpoint_id"])
+	})
+
+	for name, retired := range map[string][]string{"disabled": nil, "retired": {"checkpoint_restore"}} {
+		t.Run(name, func(t *testing.T) {
+			s, session, _ := connect(t, name == "disabled", retired...)
+
+			list, err := session.ListTools(ctx, nil)
+			require.NoError(t, err)
+			require.Len(t, list.Tools, 1)
+			for _, notice := range s.ToolDeprecations() {
+				assert.NotEqual(t, "checkpoint_restore", notice.Name)
+			}
+
+			_, err = session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      "checkpoint_restore",
+				Arguments: map[string]any{"id": "cp-1"},
+			})
+			require.Error(t, err)
+			assert.Empty(t, s.aliases.Calls())
+		})
+	}
+}
+
+func TestNewAliasState_UnknownRetiredAlias(t *testing.T) {
+	_, err := newAliasState(false, []string{"memory_stor"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "memory_stor")
+}
+
+func TestToolDeprecations(t *testing.T) {
+	aliases, err := newAliasState(false, []string{"memory_store"})
+	require.NoError(t, err)
+	s := &Server{aliases: aliases}
+
+	notices := s.ToolDeprecations()
+	require.Len(t, notices, 1)
+	assert.Equal(t, "checkpoint_restore", notices[0].Name)
+	assert.Equal(t, "checkpoint_resume", notices[0].Replacement)
+	assert.NotEmpty(t, notices[0].RemovedIn)
+}
@@ -0,0 +1,290 @@
+package mcp
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/tenant"
+)
+
+// callScope holds the arguments that attribute a tool call to a tenant,
+// project and session. Every tool names them the same way.
+type callScope struct {
+	TenantID    string `json:"tenant_id"`
+	ProjectID   string `json:"project_id"`
+	ProjectPath string `json:"project_path"`
+	SessionID   string `json:"session_id"`
+}
+
+// replayArgKeys are the arguments kept in the session journal: what was
+// asked and about which item, not the content that was written.
+var replayArgKeys = []string{
+	"query", "task", "filter", "languages", "tags", "category", "decompose", "limit", "cursor",
+	"memory_id", "memory_ids", "checkpoint_id", "remediation_id", "branch_id", "proposal_id",
+	"name", "title", "summary", "error_message", "level", "helpful", "succeeded", "outcome",
+}
+
+// scopeKeys are ID arguments that scope a call rather than name a result.
+var scopeKeys = map[string]bool{"tenant_id": true, "team_id": true, "project_id": true, "session_id": true}
+
+const (
+	// maxReplayText truncates journaled strings, in runes.
+	maxReplayText = 200
+	// maxReplayIDs bounds the result IDs journaled per call.
+	maxReplayIDs = 50
+)
+
+// recordToolUsage is receiving middleware that reports every tool call to the
+// analytics tracker. It is added after compactResponses so it wraps it and
+// measures the result the client actually receives. The call's scope is put
+// on the context so LLM usage it causes is attributed to the same tenant,
+// project and session. Calls that name a session are journaled for replay
+// with their scrubbed arguments, error and result IDs.
+func (s *Server) recordToolUsage(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok {
+			return next(ctx, method, req)
+		}
+
+		scope := s.scopeOf(call.Params.Arguments)
+		ctx = analytics.ContextWithScope(ctx, scope)
+
+		start := time.Now()
+		res, err := next(ctx, method, req)
+
+		record := analytics.Call{
+			Tool:      call.Params.Name,
+			TenantID:  scope.TenantID,
+			ProjectID: scope.ProjectID,
+			Time:      start,
+			Duration:  time.Since(start),
+			Failed:    err != nil,
+		}
+		if result, ok := res.(*mcp.CallToolResult); ok && result != nil {
+			record.Failed = record.Failed || result.IsError
+			if data, marshalErr := json.Marshal(result); marshalErr == nil {
+				record.ResultBytes = len(data)
+			}
+		}
+		if scope.SessionID != "" {
+			s.addReplayDetails(&record, scope.SessionID, call.Params.Arguments, res, err)
+		}
+		s.analytics.Record(record)
+		return res, err
+	}
+}
+
+// scopeOf attributes a call the way the handlers do: explicit IDs win, and
+// otherwise both are derived from project_path. IDs become rollup keys, so
+// invalid explicit IDs are ignored as if absent. Tenant lookups open the git
+// repository, so they are cached per path.
+func (s *Server) scopeOf(args json.RawMessage) analytics.Scope {
+	var scope callScope
+	if len(args) == 0 || json.Unmarshal(args, &scope) != nil {
+		return analytics.Scope{}
+	}
+
+	tenantID, projectID := scope.TenantID, scope.ProjectID
+	if sanitize.ValidateTenantID(tenantID) != nil {
+		tenantID = ""
+	}
+	if sanitize.ValidateProjectID(projectID) != nil {
+		projectID = ""
+	}
+	if scope.ProjectPath != "" && (tenantID == "" || projectID == "") {
+		if path, err := sanitize.ValidateProjectPath(scope.ProjectPath); err == nil {
+			if projectID == "" {
+				projectID, _ = deriveProjectID(path)
+			}
+			if tenantID == "" {
+				var ok bool
+				if tenantID, ok = s.tenantByPath.get(path); !ok {
+					tenantID = tenant.GetTenantIDForPath(path)
+					s.tenantByPath.add(path, tenantID)
+				}
+			}
+		}
+	}
+	return analytics.Scope{TenantID: tenantID, ProjectID: projectID, SessionID: scope.SessionID}
+}
+
+// tenantCacheSize bounds the project paths whose tenant IDs are cached.
+const tenantCacheSize = 1024
+
+// tenantCache is a least recently used cache of tenant IDs by project path.
+// The zero value is ready to use.
+type tenantCache struct {
+	mu    sync.Mutex
+	order list.List // of *tenantCacheEntry, most recently used first
+	items map[string]*list.Element
+}
+
+type tenantCacheEntry struct {
+	path, tenantID string
+}
+
+// get returns the cached tenant ID for path.
+func (c *tenantCache) get(path string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[path]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*tenantCacheEntry).tenantID, true
+}
+
+// add caches the tenant ID for path, evicting the least recently used path
+// once tenantCacheSize paths are cached.
+func (c *tenantCache) add(path, tenantID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.items == nil {
+		c.items = make(map[string]*list.Element)
+	}
+	if elem, ok := c.items[path]; ok {
+		elem.Value.(*tenantCacheEntry).tenantID = tenantID
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[path] = c.order.PushFront(&tenantCacheEntry{path: path, tenantID: tenantID})
+	if c.order.Len() > tenantCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*tenantCacheEntry).path)
+	}
+}
+
+// addReplayDetails fills the session journal fields of record.
+func (s *Server) addReplayDetails(record *analytics.Call, sessionID string, args json.RawMessage, res mcp.Result, err error) {
+	record.SessionID = sessionID
+	record.Args = s.replayArgs(args)
+
+	result, _ := res.(*mcp.CallToolResult)
+	switch {
+	case err != nil:
+		record.Error = s.replayText(err.Error())
+	case result != nil && result.IsError:
+		for _, c := range result.Content {
+			if text, ok := c.(*mcp.TextContent); ok {
+				record.Error = s.replayText(text.Text)
+				break
+			}
+		}
+	case result != nil:
+		record.ResultIDs = resultIDs(result.StructuredContent)
+	}
+}
+
+// replayArgs returns the scrubbed replayArgKeys present in args.
+func (s *Server) replayArgs(args json.RawMessage) map[string]any {
+	var all map[string]any
+	if len(args) == 0 || json.Unmarshal(args, &all) != nil {
+		return nil
+	}
+	kept := make(map[string]any)
+	for _, key := range replayArgKeys {
+		switch v := all[key].(type) {
+		case nil:
+		case string:
+			if v != "" {
+				kept[key] = s.replayText(v)
+			}
+		case []any:
+			list := make([]any, 0, len(v))
+			for _, elem := range v {
+				if str, ok := elem.(string); ok {
+					elem = s.replayText(str)
+				}
+				list = append(list, elem)
+			}
+			kept[key] = list
+		default:
+			kept[key] = v
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// replayText scrubs secrets from text and truncates it for the journal.
+func (s *Server) replayText(text string) string {
+	if s.scrubber != nil {
+		text = s.scrubber.Scrub(text).Scrubbed
+	}
+	if runes := []rune(text); len(runes) > maxReplayText {
+		text = string(runes[:maxReplayText]) + "..."
+	}
+	return text
+}
+
+// resultIDs returns the IDs a tool result names: every "id" value, such as
+// those of returned memories, and top-level "*_id" values other than the
+// call scope. Compact aliases are resolved.
+func resultIDs(content any) []string {
+	if content == nil {
+		return nil
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+
+	aliases := map[string]any{}
+	top, _ := v.(map[string]any)
+	if lookup, ok := top[compactIDsKey].(map[string]any); ok {
+		aliases = lookup
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if orig, ok := aliases[id].(string); ok {
+			id = orig
+		}
+		if id != "" && !seen[id] && len(ids) < maxReplayIDs {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, key := range sortedKeys(top) {
+		if id, ok := top[key].(string); ok && strings.HasSuffix(key, "_id") && !scopeKeys[key] {
+			add(id)
+		}
+	}
+	walkResultIDs(v, add)
+	return ids
+}
+
+// walkResultIDs calls fn for every "id" value in v, in a deterministic order.
+func walkResultIDs(v any, fn func(string)) {
+	switch val := v.(type) {
+	case map[string]any:
+		if id, ok := val["id"].(string); ok {
+			fn(id)
+		}
+		for _, k := range sortedKeys(val) {
+			walkResultIDs(val[k], fn)
+		}
+	case []any:
+		for _, elem := range val {
+			walkResultIDs(elem, fn)
+		}
+	}
+}
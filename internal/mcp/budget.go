@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
+)
+
+const (
+	// budgetCharsPerToken estimates tokens from serialized result size.
+	budgetCharsPerToken = 4
+
+	// budgetEllipsis marks text trimmed to fit a token budget.
+	budgetEllipsis = "..."
+)
+
+// tokenBudget is embedded in search tool inputs to expose the max_tokens
+// parameter. Handlers fit their results with fitResultsToBudget.
+type tokenBudget struct {
+	MaxTokens int `json:"max_tokens,omitempty" jsonschema:"Token budget for the results (default: no limit). Text is trimmed to fit, keeping titles and the best-matching excerpt first; results that do not fit at all are dropped"`
+}
+
+// validate rejects negative budgets.
+func (b tokenBudget) validate() error {
+	if b.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative")
+	}
+	return nil
+}
+
+// budgetUsage is embedded in search tool outputs to report how results
+// were fitted to max_tokens. Both fields are zero without a budget.
+type budgetUsage struct {
+	TokensUsed int `json:"tokens_used,omitempty" jsonschema:"Estimated tokens used by the results (set when max_tokens is given)"`
+	Truncated  int `json:"truncated,omitempty" jsonschema:"Results trimmed or dropped to fit max_tokens"`
+}
+
+// fitResultsToBudget trims results in place so that together they fit in
+// maxTokens, and returns the results that were kept.
+//
+// textKeys lists each result's trimmable text fields, most important first;
+// a key of the form "parent.child" names a field of a nested map. Everything
+// else in a result (IDs, titles, scores) is kept as is. Fitting happens in
+// passes: first every result that fits gets its fixed fields, in rank order,
+// then the remaining budget is spent one text field at a time across all
+// results, so the key excerpt of every result comes before the rest of the
+// first one.
+func fitResultsToBudget(results []map[string]interface{}, textKeys []string, maxTokens int) ([]map[string]interface{}, budgetUsage) {
+	if maxTokens <= 0 {
+		return results, budgetUsage{}
+	}
+
+	// Set text aside and keep results whose fixed fields fit
+	type pending struct {
+		result map[string]interface{}
+		texts  []string
+	}
+	var usage budgetUsage
+	kept := make([]pending, 0, len(results))
+	remaining := maxTokens
+	for i, result := range results {
+		texts := make([]string, len(textKeys))
+		for j, key := range textKeys {
+			texts[j] = takeText(result, key)
+		}
+		cost := estimateJSONTokens(result)
+		if cost > remaining {
+			usage.Truncated += len(results) - i
+			break
+		}
+		remaining -= cost
+		kept = append(kept, pending{result: result, texts: texts})
+	}
+
+	// Spend what is left field by field, in rank order
+	trimmed := make([]bool, len(kept))
+	for j, key := range textKeys {
+		for i, p := range kept {
+			text := p.texts[j]
+			if text == "" {
+				continue
+			}
+			if remaining <= 0 {
+				trimmed[i] = true
+				continue
+			}
+			cost, cut := putText(p.result, key, text, remaining)
+			remaining -= cost
+			trimmed[i] = trimmed[i] || cut
+		}
+	}
+
+	out := make([]map[string]interface{}, len(kept))
+	for i, p := range kept {
+		out[i] = p.result
+		usage.TokensUsed += estimateJSONTokens(p.result)
+		if trimmed[i] {
+			usage.Truncated++
+		}
+	}
+	return out, usage
+}
+
+// budgetCursor returns the cursor for the page after a page of returned
+// results of which fitResultsToBudget kept the first kept. It is next when
+// every result was kept, and otherwise resumes at the first dropped result so
+// paging does not skip it. cursor and hash are the page's cursor and query
+// hash.
+func budgetCursor(cursor, next, hash string, returned, kept int) string {
+	if kept >= returned {
+		return next
+	}
+	return pagination.Resume(cursor, hash, kept)
+}
+
+// takeText removes a text field from result and returns its value. Nested
+// fields are blanked rather than removed so their parent keeps its place.
+func takeText(result map[string]interface{}, key string) string {
+	parent, field := textField(result, key)
+	if parent == nil {
+		return ""
+	}
+	text, _ := parent[field].(string)
+	if strings.Contains(key, ".") {
+		parent[field] = ""
+		return text
+	}
+	delete(parent, field)
+	return text
+}
+
+// putText sets a text field, trimming it to fit in budget tokens, and
+// returns the tokens it added and whether it was trimmed. A field that does
+// not fit at all is left out.
+func putText(result map[string]interface{}, key, text string, budget int) (int, bool) {
+	parent, field := textField(result, key)
+	if parent == nil {
+		return 0, false
+	}
+	before := estimateJSONTokens(result)
+	parent[field] = text
+	cost := estimateJSONTokens(result) - before
+	if cost <= budget {
+		return cost, false
+	}
+
+	// Shrink in proportion to the overrun; JSON escaping makes cost
+	// non-linear in length, so repeat until it fits
+	runes := []rune(text)
+	keep := len(runes)
+	for keep > 0 && cost > budget {
+		next := keep * budget / cost
+		if next >= keep {
+			next = keep - 1
+		}
+		keep = next
+		parent[field] = strings.TrimRight(string(runes[:keep]), " \t\n") + budgetEllipsis
+		cost = estimateJSONTokens(result) - before
+	}
+	if keep == 0 || cost > budget {
+		takeText(result, key)
+		return 0, true
+	}
+	return cost, true
+}
+
+// textField resolves key to the map holding the field and the field name.
+func textField(result map[string]interface{}, key string) (map[string]interface{}, string) {
+	parentKey, field, nested := strings.Cut(key, ".")
+	if !nested {
+		return result, key
+	}
+	parent, _ := result[parentKey].(map[string]interface{})
+	return parent, field
+}
+
+// estimateJSONTokens estimates the tokens v takes up in a tool response.
+func estimateJSONTokens(v interface{}) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + budgetCharsPerToken - 1) / budgetCharsPerToken
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
+)
+
+func TestFitResultsToBudget(t *testing.T) {
+	newResults := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{
+				"id":        "mem_1",
+				"title":     "Retry uploads with backoff",
+				"content":   strings.Repeat("retry the upload after waiting ", 40),
+				"highlight": map[string]interface{}{"text": "wait 2^n seconds between attempts", "start": 10, "end": 43},
+			},
+			{
+				"id":      "mem_2",
+				"title":   "Cap retry attempts",
+				"content": strings.Repeat("give up after five attempts ", 40),
+			},
+		}
+	}
+
+	t.Run("no budget", func(t *testing.T) {
+		results := newResults()
+		got, usage := fitResultsToBudget(results, []string{"highlight.text", "content"}, 0)
+		assert.Equal(t, newResults(), got)
+		assert.Equal(t, budgetUsage{}, usage)
+	})
+
+	t.Run("fits unchanged", func(t *testing.T) {
+		got, usage := fitResultsToBudget(newResults(), []string{"highlight.text", "content"}, 10000)
+		assert.Equal(t, newResults(), got)
+		assert.Zero(t, usage.Truncated)
+		assert.Equal(t, estimateJSONTokens(got[0])+estimateJSONTokens(got[1]), usage.TokensUsed)
+	})
+
+	t.Run("key excerpts before full content", func(t *testing.T) {
+		got, usage := fitResultsToBudget(newResults(), []string{"highlight.text", "content"}, 120)
+		require.Len(t, got, 2)
+		assert.LessOrEqual(t, usage.TokensUsed, 120)
+		assert.Equal(t, 2, usage.Truncated)
+
+		// Titles and the highlight survive; content is trimmed
+		assert.Equal(t, "Retry uploads with backoff", got[0]["title"])
+		assert.Equal(t, "Cap retry attempts", got[1]["title"])
+		highlight := got[0]["highlight"].(map[string]interface{})
+		assert.Equal(t, "wait 2^n seconds between attempts", highlight["text"])
+		assert.Equal(t, 10, highlight["start"])
+		content, _ := got[0]["content"].(string)
+		assert.True(t, strings.HasSuffix(content, budgetEllipsis), "content = %q", content)
+	})
+
+	t.Run("drops results that do not fit", func(t *testing.T) {
+		results := newResults()
+		first := estimateJSONTokens(map[string]interface{}{
+			"id":        "mem_1",
+			"title":     "Retry uploads with backoff",
+			"highlight": map[string]interface{}{"text": "", "start": 10, "end": 43},
+		})
+		got, usage := fitResultsToBudget(results, []string{"highlight.text", "content"}, first)
+		require.Len(t, got, 1)
+		assert.Equal(t, "mem_1", got[0]["id"])
+		assert.NotContains(t, got[0], "content")
+		assert.Equal(t, 2, usage.Truncated, "one trimmed, one dropped")
+		assert.LessOrEqual(t, usage.TokensUsed, first)
+	})
+}
+
+func TestTokenBudget_Validate(t *testing.T) {
+	assert.NoError(t, tokenBudget{}.validate())
+	assert.NoError(t, tokenBudget{MaxTokens: 500}.validate())
+	assert.Error(t, tokenBudget{MaxTokens: -1}.validate())
+}
+
+func TestBudgetCursor(t *testing.T) {
+	hash := pagination.QueryHash("q")
+	next := pagination.Encode(10, hash)
+
+	assert.Equal(t, next, budgetCursor("", next, hash, 10, 10), "all kept")
+	assert.Empty(t, budgetCursor("", "", hash, 3, 3), "last page")
+
+	offset, err := pagination.Decode(budgetCursor("", next, hash, 10, 4), hash)
+	require.NoError(t, err)
+	assert.Equal(t, 4, offset, "resumes at the first dropped result")
+
+	offset, err = pagination.Decode(budgetCursor(next, "", hash, 3, 1), hash)
+	require.NoError(t, err)
+	assert.Equal(t, 11, offset, "dropped results on the last page")
+}
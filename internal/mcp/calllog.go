@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// logToolCalls is receiving middleware that writes one "tool call" entry
+// per tool call, like an HTTP access log: the tool, duration, tenant,
+// project and session, the estimated tokens of the result and, for failed
+// calls, the error code. It is added after compactResponses so the tokens
+// are those the client received.
+//
+// Successful calls are sampled at toolCallSampleRate. Failed calls are
+// always logged: at error level for internal and dependency failures, at
+// warn level otherwise.
+func (s *Server) logToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok {
+			return next(ctx, method, req)
+		}
+
+		start := time.Now()
+		res, err := next(ctx, method, req)
+		duration := time.Since(start)
+
+		result, _ := res.(*mcp.CallToolResult)
+		failed := err != nil || (result != nil && result.IsError)
+		if !failed && (s.toolCallSampleRate <= 0 || rand.Float64() >= s.toolCallSampleRate) {
+			return res, err
+		}
+
+		scope := s.scopeOf(call.Params.Arguments)
+		fields := []zap.Field{
+			zap.String("tool", call.Params.Name),
+			zap.Duration("duration", duration),
+			zap.String("tenant_id", scope.TenantID),
+			zap.String("project_id", scope.ProjectID),
+		}
+		if scope.SessionID != "" {
+			fields = append(fields, zap.String("session_id", scope.SessionID))
+		}
+		if result != nil {
+			fields = append(fields, zap.Int("result_tokens", estimateJSONTokens(result)))
+		}
+
+		if !failed {
+			s.logger.Info("tool call", fields...)
+			return res, err
+		}
+		code := toolCallErrorCode(result, err)
+		fields = append(fields, zap.String("error_code", string(code)))
+		switch code {
+		case ctxerrors.CodeInternal, ctxerrors.CodeDependencyUnavailable:
+			s.logger.Error("tool call", fields...)
+		default:
+			s.logger.Warn("tool call", fields...)
+		}
+		return res, err
+	}
+}
+
+// toolCallErrorCode returns the code of a failed call: the one errorPayloads
+// attached to the result, or the protocol error's.
+func toolCallErrorCode(result *mcp.CallToolResult, err error) ctxerrors.Code {
+	if err != nil {
+		return ctxerrors.CodeOf(err)
+	}
+	if result != nil {
+		if payload, ok := result.Meta["error"].(*ctxerrors.Payload); ok && payload != nil {
+			return payload.Code
+		}
+	}
+	return ctxerrors.CodeInternal
+}
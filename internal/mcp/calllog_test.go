@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+func TestLogToolCalls(t *testing.T) {
+	ctx := context.Background()
+
+	connect := func(t *testing.T, sampleRate float64) (*mcp.ClientSession, *observer.ObservedLogs) {
+		t.Helper()
+		core, logs := observer.New(zapcore.DebugLevel)
+		s := &Server{logger: zap.New(core), toolCallSampleRate: sampleRate}
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+		server.AddReceivingMiddleware(s.errorPayloads)
+		server.AddReceivingMiddleware(s.logToolCalls)
+		addTool(server, &mcp.Tool{Name: "lookup"}, func(ctx context.Context, req *mcp.CallToolRequest, args analyticsTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+			switch {
+			case args.Fail && args.ProjectID == "missing":
+				return nil, formatTestOutput{}, ctxerrors.NotFound("memory not found")
+			case args.Fail:
+				return nil, formatTestOutput{}, errors.New("lookup failed")
+			}
+			return nil, formatTestOutput{ID: "abc", Summary: "a result"}, nil
+		})
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		_, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { session.Close() })
+		return session, logs
+	}
+
+	call := func(t *testing.T, session *mcp.ClientSession, args map[string]any) {
+		t.Helper()
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: args})
+		require.NoError(t, err)
+	}
+
+	t.Run("one entry per call", func(t *testing.T) {
+		session, logs := connect(t, 1)
+
+		call(t, session, map[string]any{"tenant_id": "acme", "project_id": "api"})
+		call(t, session, map[string]any{"tenant_id": "acme", "project_id": "missing", "fail": true})
+		call(t, session, map[string]any{"tenant_id": "acme", "project_id": "api", "fail": true})
+
+		// Non-tool requests are not logged
+		_, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+
+		entries := logs.FilterMessage("tool call").All()
+		require.Len(t, entries, 3)
+
+		ok := entries[0].ContextMap()
+		assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+		assert.Equal(t, "lookup", ok["tool"])
+		assert.Equal(t, "acme", ok["tenant_id"])
+		assert.Equal(t, "api", ok["project_id"])
+		assert.Positive(t, ok["result_tokens"])
+		assert.Contains(t, ok, "duration")
+		assert.NotContains(t, ok, "error_code")
+
+		// Caller errors are warnings, server failures errors
+		assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+		assert.Equal(t, "NOT_FOUND", entries[1].ContextMap()["error_code"])
+		assert.Equal(t, zapcore.ErrorLevel, entries[2].Level)
+		assert.Equal(t, "INTERNAL_ERROR", entries[2].ContextMap()["error_code"])
+	})
+
+	t.Run("failures are exempt from sampling", func(t *testing.T) {
+		session, logs := connect(t, 0)
+
+		call(t, session, map[string]any{"project_id": "api"})
+		call(t, session, map[string]any{"project_id": "api", "fail": true})
+
+		entries := logs.FilterMessage("tool call").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "INTERNAL_ERROR", entries[0].ContextMap()["error_code"])
+	})
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// ErrShuttingDown is returned for tool calls that arrive once Drain has
+// started.
+var ErrShuttingDown = ctxerrors.New(ctxerrors.CodeShuttingDown, "contextd is shutting down; retry once it restarts")
+
+// drainState counts in-flight tool calls and rejects new ones while the
+// server drains.
+type drainState struct {
+	mu       sync.Mutex
+	draining bool
+	inflight int
+	idle     chan struct{} // closed when draining and no calls remain
+}
+
+// trackInFlight is receiving middleware that counts running tool calls so
+// Drain can wait for them, and rejects new calls once draining. It is added
+// last, so it runs before every other middleware.
+func (s *Server) trackInFlight(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" {
+			return next(ctx, method, req)
+		}
+		d := &s.drain
+		d.mu.Lock()
+		if d.draining {
+			d.mu.Unlock()
+			return toolErrorResult(ErrShuttingDown), nil
+		}
+		d.inflight++
+		d.mu.Unlock()
+
+		defer func() {
+			d.mu.Lock()
+			d.inflight--
+			if d.draining && d.inflight == 0 && d.idle != nil {
+				close(d.idle)
+				d.idle = nil
+			}
+			d.mu.Unlock()
+		}()
+		return next(ctx, method, req)
+	}
+}
+
+// Drain stops accepting tool calls and waits for in-flight calls to finish
+// or ctx to expire. New calls fail with ErrShuttingDown. Sessions stay open
+// so running calls can deliver their results; cancel the context passed to
+// Run or Serve afterwards to close them.
+func (s *Server) Drain(ctx context.Context) error {
+	d := &s.drain
+	d.mu.Lock()
+	d.draining = true
+	if d.inflight == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle, inflight := d.idle, d.inflight
+	d.mu.Unlock()
+
+	s.logger.Info("draining in-flight MCP tool calls", zap.Int("in_flight", inflight))
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d tool calls still running: %w", s.InFlight(), ctx.Err())
+	}
+}
+
+// InFlight returns the number of tool calls currently running.
+func (s *Server) InFlight() int {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	return s.drain.inflight
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDrain(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{
+		mcp:    mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil),
+		logger: zap.NewNop(),
+	}
+	s.mcp.AddReceivingMiddleware(s.trackInFlight)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mcp.AddTool(s.mcp, &mcp.Tool{Name: "slow"}, func(ctx context.Context, req *mcp.CallToolRequest, args analyticsTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+		close(started)
+		<-release
+		return nil, formatTestOutput{ID: "done"}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := s.mcp.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	// Draining an idle server returns at once.
+	idle := &Server{logger: zap.NewNop()}
+	require.NoError(t, idle.Drain(ctx))
+
+	slow := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "slow", Arguments: map[string]any{}})
+		assert.NoError(t, err)
+		slow <- res
+	}()
+	<-started
+	assert.Equal(t, 1, s.InFlight())
+
+	// The bound expires while the call runs.
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err = s.Drain(short)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 tool calls still running")
+
+	// New calls are rejected while draining.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "slow", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	payload, ok := res.Meta["error"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "SHUTTING_DOWN", payload["code"])
+
+	// The running call completes and delivers its result.
+	drained := make(chan error, 1)
+	go func() { drained <- s.Drain(ctx) }()
+	close(release)
+	select {
+	case err := <-drained:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain did not return after the call finished")
+	}
+	res = <-slow
+	assert.False(t, res.IsError)
+	assert.Equal(t, 0, s.InFlight())
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// toolErrorKey is the context key for the slot addTool records a handler's
+// error in.
+type toolErrorKey struct{}
+
+// toolError holds the error a tool handler returned. The SDK turns handler
+// errors into text, so the typed error is passed to errorPayloads through
+// the request context instead.
+type toolError struct {
+	err error
+}
+
+// addTool registers a typed tool handler whose errors are recorded for
+// errorPayloads. Use it instead of mcp.AddTool for every contextd tool.
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	mcp.AddTool(server, tool, func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, Out, error) {
+		res, out, err := handler(ctx, req, in)
+		if slot, ok := ctx.Value(toolErrorKey{}).(*toolError); ok && err != nil {
+			slot.err = err
+		}
+		return res, out, err
+	})
+}
+
+// errorPayloads is receiving middleware that attaches a ctxerrors.Payload to
+// failed tool calls as _meta.error, so clients can branch on the code and
+// retry flag instead of parsing the error text.
+func (s *Server) errorPayloads(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" {
+			return next(ctx, method, req)
+		}
+
+		slot := &toolError{}
+		res, err := next(context.WithValue(ctx, toolErrorKey{}, slot), method, req)
+		if err != nil || slot.err == nil {
+			return res, err
+		}
+		if result, ok := res.(*mcp.CallToolResult); ok && result.IsError {
+			if result.Meta == nil {
+				result.Meta = mcp.Meta{}
+			}
+			result.Meta["error"] = ctxerrors.PayloadFor(slot.err)
+		}
+		return res, nil
+	}
+}
+
+// toolErrorResult reports err as a failed tool call with its payload in
+// _meta.error, for failures detected before a handler runs.
+func toolErrorResult(err error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+		Meta:    mcp.Meta{"error": ctxerrors.PayloadFor(err)},
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+func TestErrorPayloads(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{logger: zap.NewNop()}
+
+	var toolErr error
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	server.AddReceivingMiddleware(s.errorPayloads)
+	addTool(server, &mcp.Tool{Name: "lookup"}, func(ctx context.Context, req *mcp.CallToolRequest, args formatTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+		if toolErr != nil {
+			return nil, formatTestOutput{}, toolErr
+		}
+		return nil, formatTestOutput{ID: "abc"}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	call := func(t *testing.T) *mcp.CallToolResult {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: map[string]any{"query": "q"}})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("coded error", func(t *testing.T) {
+		toolErr = fmt.Errorf("search failed: %w", ctxerrors.DependencyUnavailable("qdrant", errors.New("connection refused")))
+		res := call(t)
+		require.True(t, res.IsError)
+		assert.Equal(t, toolErr.Error(), res.Content[0].(*mcp.TextContent).Text)
+
+		meta, err := json.Marshal(res.Meta["error"])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"code": "DEPENDENCY_UNAVAILABLE",
+			"message": "search failed: qdrant unavailable: connection refused",
+			"retryable": true,
+			"details": {"dependency": "qdrant"}
+		}`, string(meta))
+	})
+
+	t.Run("unclassified error", func(t *testing.T) {
+		toolErr = errors.New("boom")
+		res := call(t)
+		require.True(t, res.IsError)
+
+		meta, err := json.Marshal(res.Meta["error"])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"code":"INTERNAL_ERROR","message":"boom","retryable":false}`, string(meta))
+	})
+
+	t.Run("success has no error payload", func(t *testing.T) {
+		toolErr = nil
+		res := call(t)
+		require.False(t, res.IsError)
+		assert.NotContains(t, res.Meta, "error")
+	})
+}
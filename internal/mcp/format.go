@@ -0,0 +1,375 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// Response formats accepted by the format tool parameter.
+const (
+	formatFull    = "full"
+	formatCompact = "compact"
+)
+
+const (
+	// compactDecimals is the number of decimal places kept for fractional
+	// numbers (scores, confidences) in compact responses.
+	compactDecimals = 2
+
+	// compactMinIDLen is the shortest ID worth replacing with an alias.
+	compactMinIDLen = 12
+
+	// compactIDsKey holds the alias lookup map in compact responses.
+	compactIDsKey = "_ids"
+)
+
+// ownFormatTools have a format argument of their own whose values their
+// handlers validate. format=compact still compacts their responses.
+var ownFormatTools = map[string]bool{
+	"reflect_report":      true,
+	"reflect_team_report": true,
+}
+
+// responseFormat is embedded in tool inputs to expose the format parameter.
+// The conversion itself happens in compactResponses, so handlers never read it.
+type responseFormat struct {
+	Format string `json:"format,omitempty" jsonschema:"Response format: full or compact (default: full). compact drops empty fields, rounds scores and replaces repeated IDs with short aliases listed in _ids"`
+}
+
+// compactResponses is receiving middleware that rewrites tool results when a
+// call asks for format=compact.
+//
+// The typed handler has already validated its output against the full
+// schema, so compaction happens afterwards on the JSON. Because compact
+// output omits empty fields and adds _ids, tools/list advertises a relaxed
+// output schema that allows both forms. Unknown formats are rejected before
+// the handler runs.
+func (s *Server) compactResponses(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, isCall := req.(*mcp.CallToolRequest)
+		format := formatFull
+		if method == "tools/call" && isCall {
+			format = requestedFormat(call.Params.Arguments)
+			if !ownFormatTools[call.Params.Name] && format != formatFull && format != formatCompact {
+				return toolErrorResult(ctxerrors.New(ctxerrors.CodeInvalidInput,
+					fmt.Sprintf("invalid format %q: must be %q or %q", format, formatFull, formatCompact))), nil
+			}
+		}
+
+		res, err := next(ctx, method, req)
+		if err != nil {
+			return res, err
+		}
+
+		switch method {
+		case "tools/list":
+			if list, ok := res.(*mcp.ListToolsResult); ok {
+				list.Tools = relaxOutputSchemas(list.Tools)
+			}
+		case "tools/call":
+			if format != formatCompact {
+				return res, nil
+			}
+			result, ok := res.(*mcp.CallToolResult)
+			if !ok || result.IsError {
+				return res, nil
+			}
+			if err := compactResult(result); err != nil {
+				// Fall back to the full response rather than failing the call
+				s.logger.Warn("failed to compact tool response",
+					zap.String("tool", call.Params.Name),
+					zap.Error(err))
+			}
+		}
+		return res, nil
+	}
+}
+
+// requestedFormat extracts the format argument, defaulting to full. Other
+// values are returned as given for the caller to reject.
+func requestedFormat(args json.RawMessage) string {
+	if len(args) == 0 {
+		return formatFull
+	}
+	var f responseFormat
+	if err := json.Unmarshal(args, &f); err != nil || f.Format == "" {
+		return formatFull
+	}
+	return f.Format
+}
+
+// compactResult compacts the structured content of result in place. A text
+// block that mirrors the structured content is replaced as well; handler
+// summaries are left alone.
+func compactResult(result *mcp.CallToolResult) error {
+	if result.StructuredContent == nil {
+		return nil
+	}
+	full, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return fmt.Errorf("marshaling structured content: %w", err)
+	}
+	compact, err := compactJSON(full)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok && text.Text == string(full) {
+			text.Text = string(compact)
+		}
+	}
+	result.StructuredContent = json.RawMessage(compact)
+	return nil
+}
+
+// compactJSON converts a full tool response into its compact form:
+//   - null values, empty strings, empty arrays and empty objects are dropped
+//   - fractional numbers are rounded to compactDecimals places
+//   - long IDs that appear more than once are replaced by aliases ("i1",
+//     "i2", ...) with the originals listed under _ids
+//
+// IDs are values of "id" and "*_id" keys and elements of "*_ids" arrays.
+// IDs that appear only once are kept, as aliasing them costs more than it saves.
+func compactJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	walkIDs(v, "", func(id string) {
+		if counts[id] == 0 {
+			order = append(order, id)
+		}
+		counts[id]++
+	})
+
+	aliases := make(map[string]string)
+	lookup := make(map[string]string)
+	for _, id := range order {
+		if counts[id] > 1 && len(id) >= compactMinIDLen {
+			alias := "i" + strconv.Itoa(len(lookup)+1)
+			aliases[id] = alias
+			lookup[alias] = id
+		}
+	}
+
+	v = compactValue(v, "", aliases)
+	if obj, ok := v.(map[string]any); ok && len(lookup) > 0 {
+		obj[compactIDsKey] = lookup
+	}
+	if v == nil {
+		v = map[string]any{}
+	}
+	return json.Marshal(v)
+}
+
+// walkIDs calls fn for every ID value in v, in a deterministic order.
+func walkIDs(v any, key string, fn func(string)) {
+	switch val := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(val) {
+			walkIDs(val[k], k, fn)
+		}
+	case []any:
+		for _, elem := range val {
+			if s, ok := elem.(string); ok && isIDListKey(key) {
+				fn(s)
+				continue
+			}
+			walkIDs(elem, "", fn)
+		}
+	case string:
+		if isIDKey(key) {
+			fn(val)
+		}
+	}
+}
+
+// compactValue returns the compact form of v, or nil if v should be dropped.
+func compactValue(v any, key string, aliases map[string]string) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, elem := range val {
+			if c := compactValue(elem, k, aliases); c != nil {
+				val[k] = c
+			} else {
+				delete(val, k)
+			}
+		}
+		if len(val) == 0 {
+			return nil
+		}
+		return val
+	case []any:
+		out := val[:0]
+		for _, elem := range val {
+			if s, ok := elem.(string); ok && isIDListKey(key) {
+				if alias, ok := aliases[s]; ok {
+					elem = alias
+				}
+				out = append(out, elem)
+				continue
+			}
+			if c := compactValue(elem, "", aliases); c != nil {
+				out = append(out, c)
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case string:
+		if val == "" {
+			return nil
+		}
+		if alias, ok := aliases[val]; ok && isIDKey(key) {
+			return alias
+		}
+		return val
+	case json.Number:
+		return roundNumber(val)
+	default:
+		// bool; nil falls through and is dropped
+		return val
+	}
+}
+
+// roundNumber rounds fractional numbers, leaving integers untouched so large
+// counts and token totals keep full precision.
+func roundNumber(n json.Number) json.Number {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		return n
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return n
+	}
+	scale := math.Pow10(compactDecimals)
+	return json.Number(strconv.FormatFloat(math.Round(f*scale)/scale, 'f', -1, 64))
+}
+
+func isIDKey(key string) bool {
+	return key == "id" || strings.HasSuffix(key, "_id")
+}
+
+func isIDListKey(key string) bool {
+	return key == "ids" || strings.HasSuffix(key, "_ids")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// relaxOutputSchemas returns copies of tools whose output schemas accept
+// compact responses: required fields that compaction can drop are optional
+// and the top level may carry _ids. The registered tools are not modified.
+func relaxOutputSchemas(tools []*mcp.Tool) []*mcp.Tool {
+	out := make([]*mcp.Tool, len(tools))
+	for i, t := range tools {
+		out[i] = t
+		if t.OutputSchema == nil {
+			continue
+		}
+		data, err := json.Marshal(t.OutputSchema)
+		if err != nil {
+			continue
+		}
+		var schema map[string]any
+		if err := json.Unmarshal(data, &schema); err != nil {
+			continue
+		}
+		relaxRequired(schema)
+		if props, ok := schema["properties"].(map[string]any); ok {
+			props[compactIDsKey] = map[string]any{
+				"type":                 "object",
+				"description":          "Alias to full ID lookup (compact format only)",
+				"additionalProperties": map[string]any{"type": "string"},
+			}
+		}
+
+		relaxed := *t
+		relaxed.OutputSchema = schema
+		out[i] = &relaxed
+	}
+	return out
+}
+
+// relaxRequired removes the properties compaction can drop from "required"
+// in a JSON schema and all nested schemas. Compaction only drops null and
+// empty values, so required numbers and booleans stay required.
+func relaxRequired(v any) {
+	switch val := v.(type) {
+	case map[string]any:
+		if required, ok := val["required"].([]any); ok {
+			props, _ := val["properties"].(map[string]any)
+			kept := []any{}
+			for _, name := range required {
+				if key, ok := name.(string); ok && !compactDroppable(props[key]) {
+					kept = append(kept, name)
+				}
+			}
+			if len(kept) > 0 {
+				val["required"] = kept
+			} else {
+				delete(val, "required")
+			}
+		}
+		for _, elem := range val {
+			relaxRequired(elem)
+		}
+	case []any:
+		for _, elem := range val {
+			relaxRequired(elem)
+		}
+	}
+}
+
+// compactDroppable reports whether compaction may drop a value of the given
+// property schema: anything that can be null, a string, an array or an
+// object. Schemas without a type are assumed droppable.
+func compactDroppable(schema any) bool {
+	prop, ok := schema.(map[string]any)
+	if !ok {
+		return true
+	}
+	var types []any
+	switch t := prop["type"].(type) {
+	case string:
+		types = []any{t}
+	case []any:
+		types = t
+	}
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		switch t {
+		case "number", "integer", "boolean":
+		default:
+			return true
+		}
+	}
+	return false
+}
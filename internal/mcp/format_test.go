@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCompactJSON(t *testing.T) {
+	const longID = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	const otherID = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	full := `{
+		"memories": [
+			{"id": "` + longID + `", "title": "Retry", "content": "", "tags": [], "confidence": 0.876543, "usage_count": 12},
+			{"id": "` + otherID + `", "title": "Backoff", "metadata": {}, "relevance": 0.5, "helpful": false}
+		],
+		"source_ids": ["` + longID + `"],
+		"count": 2,
+		"next": null
+	}`
+
+	out, err := compactJSON([]byte(full))
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(out, &got))
+
+	memories := got["memories"].([]any)
+	first := memories[0].(map[string]any)
+	second := memories[1].(map[string]any)
+
+	// Repeated long IDs are aliased; single-use IDs are kept
+	assert.Equal(t, "i1", first["id"])
+	assert.Equal(t, []any{"i1"}, got["source_ids"])
+	assert.Equal(t, otherID, second["id"])
+	assert.Equal(t, map[string]any{"i1": longID}, got["_ids"])
+
+	// Empty values are dropped, false and integers are kept
+	assert.NotContains(t, first, "content")
+	assert.NotContains(t, first, "tags")
+	assert.NotContains(t, second, "metadata")
+	assert.NotContains(t, got, "next")
+	assert.Equal(t, false, second["helpful"])
+	assert.Equal(t, float64(12), first["usage_count"])
+
+	assert.Equal(t, 0.88, first["confidence"])
+	assert.Equal(t, 0.5, second["relevance"])
+}
+
+type formatTestInput struct {
+	responseFormat
+
+	Query string `json:"query"`
+}
+
+type formatTestOutput struct {
+	ID      string  `json:"id"`
+	Score   float64 `json:"score"`
+	Summary string  `json:"summary"`
+}
+
+func TestCompactResponses(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{logger: zap.NewNop()}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	server.AddReceivingMiddleware(s.compactResponses)
+	mcp.AddTool(server, &mcp.Tool{Name: "lookup"}, func(ctx context.Context, req *mcp.CallToolRequest, args formatTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+		return nil, formatTestOutput{ID: "abc", Score: 0.123456}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	t.Run("full by default", func(t *testing.T) {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: map[string]any{"query": "q"}})
+		require.NoError(t, err)
+		require.False(t, res.IsError)
+		assert.JSONEq(t, `{"id":"abc","score":0.123456,"summary":""}`, res.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: map[string]any{"query": "q", "format": "compact"}})
+		require.NoError(t, err)
+		require.False(t, res.IsError)
+		assert.JSONEq(t, `{"id":"abc","score":0.12}`, res.Content[0].(*mcp.TextContent).Text)
+
+		structured, err := json.Marshal(res.StructuredContent)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"abc","score":0.12}`, string(structured))
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: map[string]any{"query": "q", "format": "terse"}})
+		require.NoError(t, err)
+		require.True(t, res.IsError)
+		assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, `invalid format "terse"`)
+	})
+
+	t.Run("list relaxes output schema", func(t *testing.T) {
+		list, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, list.Tools, 1)
+
+		data, err := json.Marshal(list.Tools[0].OutputSchema)
+		require.NoError(t, err)
+		var schema map[string]any
+		require.NoError(t, json.Unmarshal(data, &schema))
+		assert.Equal(t, []any{"score"}, schema["required"], "only fields compaction can drop are relaxed")
+		assert.Contains(t, schema["properties"], compactIDsKey)
+
+		input, err := json.Marshal(list.Tools[0].InputSchema)
+		require.NoError(t, err)
+		assert.Contains(t, string(input), `"format"`)
+	})
+}
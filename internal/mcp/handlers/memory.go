@@ -11,10 +11,14 @@ import (
 
 // MemoryConsolidateInput is the input for memory_consolidate tool.
 type MemoryConsolidateInput struct {
-	ProjectID           string  `json:"project_id"`
-	SimilarityThreshold float64 `json:"similarity_threshold,omitempty"`
-	DryRun              bool    `json:"dry_run,omitempty"`
-	MaxClusters         int     `json:"max_clusters,omitempty"`
+	ProjectID           string   `json:"project_id"`
+	SimilarityThreshold float64  `json:"similarity_threshold,omitempty"`
+	DryRun              bool     `json:"dry_run,omitempty"`
+	MaxClusters         int      `json:"max_clusters,omitempty"`
+	Tags                []string `json:"tags,omitempty"`
+	Outcome             string   `json:"outcome,omitempty"`
+	Query               string   `json:"query,omitempty"`
+	RequireApproval     bool     `json:"require_approval,omitempty"`
 }
 
 // MemoryConsolidateOutput is the output for memory_consolidate tool.
@@ -24,6 +28,7 @@ type MemoryConsolidateOutput struct {
 	SkippedCount     int      `json:"skipped_count"`
 	TotalProcessed   int      `json:"total_processed"`
 	DurationSeconds  float64  `json:"duration_seconds"`
+	ProposalIDs      []string `json:"proposal_ids,omitempty"`
 }
 
 // MemoryConsolidator defines the interface for memory consolidation operations.
@@ -71,6 +76,10 @@ func (h *MemoryHandler) Consolidate(ctx context.Context, input json.RawMessage)
 		SimilarityThreshold: threshold,
 		DryRun:              req.DryRun,
 		MaxClustersPerRun:   req.MaxClusters,
+		Tags:                req.Tags,
+		Outcome:             reasoningbank.Outcome(req.Outcome),
+		Query:               req.Query,
+		RequireApproval:     req.RequireApproval,
 	}
 
 	// Execute consolidation
@@ -89,6 +98,7 @@ func (h *MemoryHandler) Consolidate(ctx context.Context, input json.RawMessage)
 		SkippedCount:     result.SkippedCount,
 		TotalProcessed:   result.TotalProcessed,
 		DurationSeconds:  durationSeconds,
+		ProposalIDs:      result.ProposalIDs,
 	}
 
 	return output, nil
@@ -9,6 +9,7 @@ import (
 
 	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
 	"github.com/fyrsmithlabs/contextd/internal/compression"
+	"github.com/fyrsmithlabs/contextd/internal/conversation"
 	"github.com/fyrsmithlabs/contextd/internal/hooks"
 	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
 	"github.com/fyrsmithlabs/contextd/internal/remediation"
@@ -173,6 +174,8 @@ func (m *mockRegistry) Scrubber() secrets.Scrubber { return nil }
 
 func (m *mockRegistry) Compression() *compression.Service { return nil }
 
+func (m *mockRegistry) Conversations() *conversation.Service { return nil }
+
 func (m *mockRegistry) VectorStore() vectorstore.Store { return nil }
 
 // mockCheckpointSvc implements checkpoint.Service
@@ -205,6 +208,20 @@ func (m *mockCheckpointSvc) List(ctx context.Context, req *checkpoint.ListReques
 	return result, nil
 }
 
+func (m *mockCheckpointSvc) Search(ctx context.Context, req *checkpoint.SearchRequest) ([]*checkpoint.ScoredCheckpoint, error) {
+	return nil, nil
+}
+
+func (m *mockCheckpointSvc) Stream(ctx context.Context, req *checkpoint.ListRequest, fn func(*checkpoint.Checkpoint) error) error {
+	checkpoints, _ := m.List(ctx, req)
+	for _, cp := range checkpoints {
+		if err := fn(cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *mockCheckpointSvc) Resume(ctx context.Context, req *checkpoint.ResumeRequest) (*checkpoint.ResumeResponse, error) {
 	return nil, nil
 }
@@ -217,6 +234,22 @@ func (m *mockCheckpointSvc) Delete(ctx context.Context, tenantID, teamID, projec
 	return nil
 }
 
+func (m *mockCheckpointSvc) Annotate(ctx context.Context, req *checkpoint.AnnotateRequest) (*checkpoint.Checkpoint, error) {
+	return nil, nil
+}
+
+func (m *mockCheckpointSvc) Release(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*checkpoint.Checkpoint, error) {
+	return nil, nil
+}
+
+func (m *mockCheckpointSvc) LinkConversation(ctx context.Context, tenantID, teamID, projectID, checkpointID string, origin checkpoint.ConversationOrigin) error {
+	return nil
+}
+
+func (m *mockCheckpointSvc) Lineage(ctx context.Context, tenantID, teamID, projectID, checkpointID string) (*checkpoint.Lineage, error) {
+	return nil, nil
+}
+
 func (m *mockCheckpointSvc) Close() error {
 	return nil
 }
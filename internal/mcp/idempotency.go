@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/idempotency"
+)
+
+// idempotentTools are the write tools that accept an idempotency_key.
+var idempotentTools = map[string]bool{
+	"memory_record":        true,
+	"memory_record_batch":  true,
+	"memory_feedback":      true,
+	"memory_outcome":       true,
+	"remediation_record":   true,
+	"remediation_feedback": true,
+	"checkpoint_save":      true,
+}
+
+// idempotencyKey is embedded in the inputs of write tools to expose the
+// idempotency_key parameter. Deduplication happens in idempotentCalls, so
+// handlers never read it.
+type idempotencyKey struct {
+	IdempotencyKey string `json:"idempotency_key,omitempty" jsonschema:"Client-chosen key that makes retries safe: repeating the call with the same key and arguments within 15 minutes returns the original result instead of writing again"`
+}
+
+// errToolFailed marks a failed tool result so it is not stored for replay.
+var errToolFailed = errors.New("tool call failed")
+
+// idempotentCalls is receiving middleware that replays the result of an
+// earlier write tool call carrying the same idempotency_key. Keys are scoped
+// by tool and tenant, and a key reused with different arguments is rejected.
+// Failed calls are not stored, so a retry after an error runs again.
+func (s *Server) idempotentCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok || !idempotentTools[call.Params.Name] {
+			return next(ctx, method, req)
+		}
+
+		var args struct {
+			idempotencyKey
+			ProjectPath string `json:"project_path"`
+		}
+		if err := json.Unmarshal(call.Params.Arguments, &args); err != nil || args.IdempotencyKey == "" {
+			return next(ctx, method, req)
+		}
+
+		// Scope by the tenant and project the handler will write to,
+		// including a tenant derived from project_path
+		attributed := s.scopeOf(call.Params.Arguments)
+		scope := strings.Join([]string{call.Params.Name, attributed.TenantID, attributed.ProjectID, args.ProjectPath}, "\x00")
+		res, replayed, err := s.idempotency.Do(ctx, scope, args.IdempotencyKey, fingerprint(call.Params.Arguments), func() (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if result, ok := res.(*mcp.CallToolResult); ok && err == nil && result.IsError {
+				return res, errToolFailed
+			}
+			return res, err
+		})
+		switch {
+		case errors.Is(err, errToolFailed):
+			return res, nil
+		case errors.Is(err, idempotency.ErrKeyReused), errors.Is(err, idempotency.ErrInvalidKey):
+			return toolErrorResult(err), nil
+		case err != nil:
+			return res, err
+		case replayed:
+			return replayResult(res), nil
+		}
+		return res, nil
+	}
+}
+
+// fingerprint identifies a call's arguments independent of key order.
+func fingerprint(raw json.RawMessage) string {
+	var args any
+	canonical := []byte(raw)
+	if json.Unmarshal(raw, &args) == nil {
+		if data, err := json.Marshal(args); err == nil {
+			canonical = data
+		}
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// replayResult returns a copy of a stored result marked as replayed in
+// _meta, leaving the stored result untouched.
+func replayResult(res mcp.Result) mcp.Result {
+	result, ok := res.(*mcp.CallToolResult)
+	if !ok {
+		return res
+	}
+	replay := *result
+	replay.Meta = mcp.Meta{}
+	for k, v := range result.Meta {
+		replay.Meta[k] = v
+	}
+	replay.Meta["replayed"] = true
+	return &replay
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/idempotency"
+)
+
+type idempotencyTestInput struct {
+	idempotencyKey
+
+	TenantID  string `json:"tenant_id,omitempty"`
+	ProjectID string `json:"project_id"`
+	Title     string `json:"title"`
+}
+
+func TestIdempotentCalls(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{logger: zap.NewNop(), idempotency: idempotency.New[mcp.Result](time.Minute, 0)}
+
+	calls := 0
+	var fail error
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	server.AddReceivingMiddleware(s.errorPayloads)
+	server.AddReceivingMiddleware(s.idempotentCalls)
+	addTool(server, &mcp.Tool{Name: "memory_record"}, func(ctx context.Context, req *mcp.CallToolRequest, args idempotencyTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+		calls++
+		if fail != nil {
+			return nil, formatTestOutput{}, fail
+		}
+		return nil, formatTestOutput{ID: args.Title}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	record := func(t *testing.T, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "memory_record", Arguments: args})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("replays a repeated key", func(t *testing.T) {
+		calls = 0
+		args := map[string]any{"project_id": "p1", "title": "retry", "idempotency_key": "k1"}
+
+		first := record(t, args)
+		require.False(t, first.IsError)
+		second := record(t, args)
+		require.False(t, second.IsError)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, first.Content[0].(*mcp.TextContent).Text, second.Content[0].(*mcp.TextContent).Text)
+		assert.Equal(t, true, second.Meta["replayed"])
+		assert.NotContains(t, first.Meta, "replayed")
+	})
+
+	t.Run("keys are scoped by project", func(t *testing.T) {
+		calls = 0
+		record(t, map[string]any{"project_id": "p1", "title": "a", "idempotency_key": "k2"})
+		record(t, map[string]any{"project_id": "p2", "title": "a", "idempotency_key": "k2"})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("keys are scoped by tenant", func(t *testing.T) {
+		calls = 0
+		record(t, map[string]any{"tenant_id": "acme", "project_id": "p1", "title": "a", "idempotency_key": "k5"})
+		record(t, map[string]any{"tenant_id": "globex", "project_id": "p1", "title": "a", "idempotency_key": "k5"})
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("rejects reuse with different arguments", func(t *testing.T) {
+		record(t, map[string]any{"project_id": "p1", "title": "a", "idempotency_key": "k3"})
+		res := record(t, map[string]any{"project_id": "p1", "title": "b", "idempotency_key": "k3"})
+		require.True(t, res.IsError)
+
+		meta, err := json.Marshal(res.Meta["error"])
+		require.NoError(t, err)
+		assert.Contains(t, string(meta), `"code":"INVALID_INPUT"`)
+	})
+
+	t.Run("failed calls run again", func(t *testing.T) {
+		calls = 0
+		args := map[string]any{"project_id": "p1", "title": "c", "idempotency_key": "k4"}
+
+		fail = errors.New("store unavailable")
+		require.True(t, record(t, args).IsError)
+		fail = nil
+		res := record(t, args)
+		require.False(t, res.IsError)
+		assert.NotContains(t, res.Meta, "replayed")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("no key runs every time", func(t *testing.T) {
+		calls = 0
+		args := map[string]any{"project_id": "p1", "title": "d"}
+		record(t, args)
+		record(t, args)
+		assert.Equal(t, 2, calls)
+	})
+}
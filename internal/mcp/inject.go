@@ -0,0 +1,23 @@
+package mcp
+
+import (
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+// formatInjection renders memory search results as the prompt-ready block
+// of memory_search's inject option, scrubbing content first. maxTokens caps
+// the block; zero means no cap.
+func (s *Server) formatInjection(results []reasoningbank.ScoredMemory, maxTokens int) string {
+	scrubbed := make([]reasoningbank.ScoredMemory, len(results))
+	for i, sm := range results {
+		sm.Memory.Title = s.scrubber.Scrub(sm.Memory.Title).Scrubbed
+		sm.Memory.Content = s.scrubber.Scrub(sm.Memory.Content).Scrubbed
+		if sm.Highlight != nil {
+			h := *sm.Highlight
+			h.Text = s.scrubber.Scrub(h.Text).Scrubbed
+			sm.Highlight = &h
+		}
+		scrubbed[i] = sm
+	}
+	return reasoningbank.FormatInjection(scrubbed, reasoningbank.InjectionOptions{MaxTokens: maxTokens}).Text
+}
@@ -0,0 +1,24 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+)
+
+func TestFormatInjection_Scrubs(t *testing.T) {
+	s := &Server{logger: zap.NewNop(), scrubber: secrets.MustNew(secrets.DefaultConfig())}
+	secret := "ghp_" + "abcdefghijklmnopqrstuvwxyz0123456789"
+	results := []reasoningbank.ScoredMemory{
+		{Memory: reasoningbank.Memory{ID: "m1", Title: "Rotate tokens", Content: "Old token " + secret, Outcome: reasoningbank.OutcomeFailure, Confidence: 0.9}},
+	}
+
+	text := s.formatInjection(results, 0)
+	assert.Contains(t, text, "1. AVOID [high confidence 0.90] Rotate tokens")
+	assert.NotContains(t, text, secret)
+	assert.Contains(t, results[0].Memory.Content, secret, "results are not modified")
+}
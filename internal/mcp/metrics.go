@@ -22,6 +22,9 @@ type Metrics struct {
 	duration       metric.Float64Histogram
 	errors         metric.Int64Counter
 	activeRequests metric.Int64UpDownCounter
+	aliasCalls     metric.Int64Counter
+	oversized      metric.Int64Counter
+	finalized      metric.Int64Counter
 }
 
 // NewMetrics creates a new Metrics instance.
@@ -77,6 +80,36 @@ func (m *Metrics) init() {
 	if err != nil {
 		m.logger.Warn("failed to create active requests gauge", zap.Error(err))
 	}
+
+	// Calls made through deprecated tool aliases
+	m.aliasCalls, err = m.meter.Int64Counter(
+		"contextd.mcp.tool.alias_calls_total",
+		metric.WithDescription("Total MCP tool calls made through a deprecated legacy tool name, labeled by alias and the tool it translates to"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		m.logger.Warn("failed to create alias calls counter", zap.Error(err))
+	}
+
+	// Calls rejected for an oversized query or content argument
+	m.oversized, err = m.meter.Int64Counter(
+		"contextd.mcp.tool.oversized_inputs_total",
+		metric.WithDescription("Total MCP tool calls rejected because a query or content argument exceeded its size limit, labeled by tool, argument and client name"),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		m.logger.Warn("failed to create oversized inputs counter", zap.Error(err))
+	}
+
+	// Sessions finalized after going idle
+	m.finalized, err = m.meter.Int64Counter(
+		"contextd.mcp.sessions_finalized_total",
+		metric.WithDescription("Total MCP sessions finalized after going idle, labeled by inferred outcome (succeeded, failed or unknown)"),
+		metric.WithUnit("{session}"),
+	)
+	if err != nil {
+		m.logger.Warn("failed to create finalized sessions counter", zap.Error(err))
+	}
 }
 
 // RecordInvocation records a tool invocation metric.
@@ -134,6 +167,36 @@ func (m *Metrics) DecrementActive(ctx context.Context, toolName string) {
 	}
 }
 
+// RecordAliasCall counts a call made through a deprecated tool alias.
+func (m *Metrics) RecordAliasCall(ctx context.Context, alias, toolName string) {
+	if m.aliasCalls != nil {
+		m.aliasCalls.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("alias", alias),
+			attribute.String("tool", toolName),
+		))
+	}
+}
+
+// RecordOversizedInput counts a call rejected because field exceeded its
+// size limit. client is the name the MCP client gave when it initialized.
+func (m *Metrics) RecordOversizedInput(ctx context.Context, toolName, field, client string) {
+	if m.oversized != nil {
+		m.oversized.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("tool", toolName),
+			attribute.String("field", field),
+			attribute.String("client", client),
+		))
+	}
+}
+
+// RecordSessionFinalized counts a session finalized after going idle.
+// outcome is the session outcome inferred from its memory_outcome reports.
+func (m *Metrics) RecordSessionFinalized(ctx context.Context, outcome string) {
+	if m.finalized != nil {
+		m.finalized.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+	}
+}
+
 // startMetrics begins tracking a tool invocation and returns a cleanup function.
 // The cleanup function reads the final value of *toolErr at defer time, ensuring
 // the error recorded reflects the actual outcome of the handler.
@@ -7,19 +7,28 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"net"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.uber.org/zap"
 
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
 	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
 	"github.com/fyrsmithlabs/contextd/internal/conversation"
 	"github.com/fyrsmithlabs/contextd/internal/folding"
+	"github.com/fyrsmithlabs/contextd/internal/idempotency"
 	"github.com/fyrsmithlabs/contextd/internal/ignore"
+	"github.com/fyrsmithlabs/contextd/internal/notify"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
 	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
 	"github.com/fyrsmithlabs/contextd/internal/remediation"
 	"github.com/fyrsmithlabs/contextd/internal/repository"
 	"github.com/fyrsmithlabs/contextd/internal/secrets"
 	"github.com/fyrsmithlabs/contextd/internal/troubleshoot"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
 // Server is a simplified MCP server that calls internal packages directly.
@@ -37,6 +46,34 @@ type Server struct {
 	ignoreParser     *ignore.Parser
 	logger           *zap.Logger
 	metrics          *Metrics
+	readOnly         *readonly.Mode
+	analytics        *analytics.Tracker
+	idempotency      *idempotency.Cache[mcp.Result]
+	notifier         *notify.Notifier
+	requester        vectorstore.Requester
+	version          string
+	features         capabilities.Features
+
+	// catalog caches the registered tools for help and capabilities
+	catalog toolCatalog
+
+	// help is the contextd_help index over the server's own tools and docs
+	help helpState
+
+	// toolCallSampleRate is the fraction of successful tool calls logged
+	toolCallSampleRate float64
+
+	// tenantByPath caches tenant IDs derived for analytics attribution
+	tenantByPath tenantCache
+
+	// drain tracks in-flight tool calls for graceful shutdown
+	drain drainState
+
+	// aliases are the accepted legacy names of renamed tools
+	aliases *aliasState
+
+	// sessions tracks sessions for idle finalization; nil when disabled
+	sessions *sessionRegistry
 }
 
 // Config configures the MCP server.
@@ -57,6 +94,55 @@ type Config struct {
 	// FallbackExcludes are used when no ignore files are found.
 	// Default: [".git/**", "node_modules/**", "vendor/**", "__pycache__/**"]
 	FallbackExcludes []string
+
+	// ReadOnly, when set and enabled, makes write tools fail with
+	// readonly.ErrReadOnly. Search and resume tools are unaffected.
+	ReadOnly *readonly.Mode
+
+	// Analytics, when set, receives a record of every tool call.
+	Analytics *analytics.Tracker
+
+	// IdempotencyWindow is how long write tool results are replayed for
+	// calls that repeat an idempotency_key (default: 15 minutes).
+	IdempotencyWindow time.Duration
+
+	// Notifier, when set, announces generated reflection reports.
+	Notifier *notify.Notifier
+
+	// Conversations, when set, enables the conversation_index and
+	// conversation_search tools and conversation knowledge search.
+	Conversations conversation.ConversationService
+
+	// UserID identifies the developer using this server. It owns the
+	// private and team memories they record and decides which of those
+	// they see. Without it only org memories can be recorded.
+	UserID string
+
+	// TeamID is the developer's team, which sees their team memories.
+	TeamID string
+
+	// LogToolCalls writes a "tool call" log entry per tool call with its
+	// duration, scope, result tokens and error code.
+	LogToolCalls bool
+
+	// ToolCallSampleRate is the fraction of successful tool calls logged,
+	// from 0 to 1. Failed calls are always logged.
+	ToolCallSampleRate float64
+
+	// Features describes the optional parts of contextd that are on, for
+	// the contextd_capabilities tool. Read-only state is filled in per call.
+	Features capabilities.Features
+
+	// DisableToolAliases rejects the legacy names of renamed tools instead
+	// of translating them. RetiredToolAliases rejects only the named ones,
+	// once their migration window has passed.
+	DisableToolAliases bool
+	RetiredToolAliases []string
+
+	// SessionIdleTimeout finalizes sessions after this long without tool
+	// calls: a final checkpoint, branch cleanup, a feedback flush and
+	// outcome inference. Zero disables it.
+	SessionIdleTimeout time.Duration
 }
 
 // DefaultConfig returns sensible defaults.
@@ -114,6 +200,11 @@ func NewServer(
 		return nil, fmt.Errorf("scrubber is required")
 	}
 
+	aliases, err := newAliasState(cfg.DisableToolAliases, cfg.RetiredToolAliases)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create MCP server
 	mcpServer := mcp.NewServer(
 		&mcp.Implementation{
@@ -127,25 +218,81 @@ func NewServer(
 	ignoreParser := ignore.NewParser(cfg.IgnoreFiles, cfg.FallbackExcludes)
 
 	s := &Server{
-		mcp:              mcpServer,
-		checkpointSvc:    checkpointSvc,
-		remediationSvc:   remediationSvc,
-		repositorySvc:    repositorySvc,
-		troubleshootSvc:  troubleshootSvc,
-		reasoningbankSvc: reasoningbankSvc,
-		foldingSvc:       foldingSvc,
-		distiller:        distiller,
-		scrubber:         scrubber,
-		ignoreParser:     ignoreParser,
-		logger:           cfg.Logger,
-		metrics:          NewMetrics(cfg.Logger),
+		mcp:                mcpServer,
+		checkpointSvc:      checkpointSvc,
+		remediationSvc:     remediationSvc,
+		repositorySvc:      repositorySvc,
+		troubleshootSvc:    troubleshootSvc,
+		reasoningbankSvc:   reasoningbankSvc,
+		foldingSvc:         foldingSvc,
+		distiller:          distiller,
+		scrubber:           scrubber,
+		ignoreParser:       ignoreParser,
+		logger:             cfg.Logger,
+		metrics:            NewMetrics(cfg.Logger),
+		readOnly:           cfg.ReadOnly,
+		analytics:          cfg.Analytics,
+		idempotency:        idempotency.New[mcp.Result](cfg.IdempotencyWindow, 0),
+		notifier:           cfg.Notifier,
+		conversationSvc:    cfg.Conversations,
+		requester:          vectorstore.Requester{UserID: cfg.UserID, TeamID: cfg.TeamID},
+		toolCallSampleRate: cfg.ToolCallSampleRate,
+		version:            cfg.Version,
+		features:           cfg.Features,
+		aliases:            aliases,
+	}
+
+	// Attach the requester so document access labels apply to every tool call
+	mcpServer.AddReceivingMiddleware(s.attachRequester)
+
+	// Reject oversized arguments before any handler runs
+	mcpServer.AddReceivingMiddleware(s.validateArguments)
+
+	// Attach coded error payloads to failed tool calls
+	mcpServer.AddReceivingMiddleware(s.errorPayloads)
+
+	// Honor format=compact on every tool call
+	mcpServer.AddReceivingMiddleware(s.compactResponses)
+
+	// Replay retried writes; added after compaction so the stored result is
+	// the one the client received
+	mcpServer.AddReceivingMiddleware(s.idempotentCalls)
+
+	// Count tool usage; added after compaction so it sees the compacted result
+	if s.analytics != nil {
+		mcpServer.AddReceivingMiddleware(s.recordToolUsage)
 	}
 
+	// Log each tool call; added after compaction so result tokens are those
+	// the client receives
+	if cfg.LogToolCalls {
+		mcpServer.AddReceivingMiddleware(s.logToolCalls)
+	}
+
+	// Track sessions for idle finalization; added before translateAliases
+	// so it sees the current tool name
+	if cfg.SessionIdleTimeout > 0 {
+		s.sessions = newSessionRegistry(cfg.SessionIdleTimeout)
+		mcpServer.AddReceivingMiddleware(s.trackSessions)
+	}
+
+	// Translate legacy tool names; added late so the middleware above sees
+	// the current tool name
+	mcpServer.AddReceivingMiddleware(s.translateAliases)
+
+	// Track in-flight calls for Drain; added last so it runs first and
+	// rejects calls during shutdown before any other work
+	mcpServer.AddReceivingMiddleware(s.trackInFlight)
+
 	// Register tools
 	if err := s.registerTools(); err != nil {
 		return nil, fmt.Errorf("failed to register tools: %w", err)
 	}
 
+	if s.sessions != nil {
+		go s.sweepIdleSessions()
+	}
+
 	return s, nil
 }
 
@@ -165,10 +312,73 @@ func (s *Server) Run(ctx context.Context) error {
 	return nil
 }
 
+// Serve runs an MCP session for each connection accepted on ln, typically a
+// Unix socket from unixsock.Listen, until ctx is cancelled. Sessions share
+// the server's tools and services. Serve closes ln and waits for open
+// sessions to end before returning.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	s.logger.Info("starting MCP server on socket transport", zap.String("addr", ln.Addr().String()))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	conns := make(map[net.Conn]struct{})
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for conn := range conns {
+			conn.Close()
+		}
+	})
+	defer stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accepting MCP connection: %w", err)
+		}
+		mu.Lock()
+		if ctx.Err() != nil {
+			// Accepted while shutting down, after open connections were closed.
+			mu.Unlock()
+			conn.Close()
+			continue
+		}
+		conns[conn] = struct{}{}
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+				conn.Close()
+			}()
+			session, err := s.mcp.Connect(ctx, &mcp.IOTransport{Reader: conn, Writer: conn}, nil)
+			if err != nil {
+				s.logger.Warn("MCP socket session failed to start", zap.Error(err))
+				return
+			}
+			_ = session.Wait()
+		}()
+	}
+}
+
 // Close closes the server and all services.
 func (s *Server) Close() error {
 	s.logger.Info("closing MCP server and services")
 
+	if s.sessions != nil {
+		s.sessions.stopOnce.Do(func() { close(s.sessions.stop) })
+		<-s.sessions.done
+	}
+
 	var errs []error
 
 	if err := s.checkpointSvc.Close(); err != nil {
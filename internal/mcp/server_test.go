@@ -4,10 +4,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
 	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
 	"github.com/fyrsmithlabs/contextd/internal/remediation"
 	"github.com/fyrsmithlabs/contextd/internal/repository"
@@ -224,3 +227,99 @@ func TestServerClose(t *testing.T) {
 	err = server.Close()
 	require.NoError(t, err)
 }
+
+func TestServer_ReadOnlyRejectsWrites(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	vectorStore := &mockVectorStore{}
+
+	checkpointSvc, err := checkpoint.NewServiceWithStore(checkpoint.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	remediationSvc, err := remediation.NewService(remediation.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	troubleshootSvc, err := troubleshoot.NewService(&mockTroubleshootStore{}, logger, nil)
+	require.NoError(t, err)
+	reasoningbankSvc, err := reasoningbank.NewService(vectorStore, logger)
+	require.NoError(t, err)
+
+	cfg := DefaultConfig()
+	cfg.ReadOnly = readonly.New(true, "store migration")
+	server, err := NewServer(cfg, checkpointSvc, remediationSvc, repository.NewService(vectorStore), troubleshootSvc, reasoningbankSvc, nil, nil, secrets.MustNew(secrets.DefaultConfig()))
+	require.NoError(t, err)
+	defer server.Close()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err = server.mcp.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "memory_record",
+		Arguments: map[string]any{
+			"project_id": "proj",
+			"title":      "t",
+			"content":    "c",
+			"outcome":    "success",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	text := res.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "read-only")
+	assert.Contains(t, text, "store migration")
+}
+
+func TestServer_MemoryRecordQualityGate(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	vectorStore := &mockVectorStore{}
+
+	checkpointSvc, err := checkpoint.NewServiceWithStore(checkpoint.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	remediationSvc, err := remediation.NewService(remediation.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	troubleshootSvc, err := troubleshoot.NewService(&mockTroubleshootStore{}, logger, nil)
+	require.NoError(t, err)
+	reasoningbankSvc, err := reasoningbank.NewService(vectorStore, logger,
+		reasoningbank.WithQualityGate(reasoningbank.NewQualityGate(reasoningbank.QualityStandard, nil)))
+	require.NoError(t, err)
+
+	server, err := NewServer(DefaultConfig(), checkpointSvc, remediationSvc, repository.NewService(vectorStore), troubleshootSvc, reasoningbankSvc, nil, nil, secrets.MustNew(secrets.DefaultConfig()))
+	require.NoError(t, err)
+	defer server.Close()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err = server.mcp.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "memory_record",
+		Arguments: map[string]any{
+			"project_id": "proj",
+			"title":      "Fix",
+			"content":    "Fixed the bug.",
+			"outcome":    "success",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "memory rejected by quality checks")
+
+	payload, ok := res.Meta["error"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "INVALID_INPUT", payload["code"])
+	details, ok := payload["details"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "low_quality", details["reason"])
+	issues, ok := details["issues"].([]any)
+	require.True(t, ok)
+	require.Len(t, issues, 1)
+	assert.Equal(t, reasoningbank.IssueContentTooShort, issues[0].(map[string]any)["code"])
+}
@@ -0,0 +1,338 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// Inferred outcomes of a finalized session, as labeled on the finalized
+// sessions counter.
+const (
+	sessionOutcomeSucceeded = "succeeded"
+	sessionOutcomeFailed    = "failed"
+	sessionOutcomeUnknown   = "unknown"
+)
+
+// sessionCall holds the arguments the session registry reads from a tool
+// call: its scope and, for feedback and outcome calls, what was reported.
+type sessionCall struct {
+	callScope
+	MemoryID  string `json:"memory_id"`
+	Helpful   *bool  `json:"helpful"`
+	Succeeded *bool  `json:"succeeded"`
+}
+
+// sessionState is what the registry knows about one session.
+type sessionState struct {
+	lastCall    time.Time
+	tenantID    string
+	projectID   string
+	projectPath string
+	calls       int
+	lastTool    string
+
+	// helpful are the memories given helpful feedback, and outcomes the
+	// memory_outcome reports, by memory ID.
+	helpful  map[string]bool
+	outcomes map[string]bool
+
+	closed   bool
+	closedAt time.Time
+}
+
+// sessionRegistry tracks the sessions named by tool calls so those left
+// idle can be finalized.
+type sessionRegistry struct {
+	timeout time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*sessionState
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func newSessionRegistry(timeout time.Duration) *sessionRegistry {
+	return &sessionRegistry{
+		timeout:  timeout,
+		now:      time.Now,
+		sessions: make(map[string]*sessionState),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// touch records a call to tool in sessionID. A call to a finalized session
+// starts it afresh and reports true.
+func (r *sessionRegistry) touch(sessionID, tool string, call sessionCall, projectID string, succeeded bool) (reopened bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st := r.sessions[sessionID]
+	if st != nil && st.closed {
+		reopened = true
+		st = nil
+	}
+	if st == nil {
+		st = &sessionState{helpful: make(map[string]bool), outcomes: make(map[string]bool)}
+		r.sessions[sessionID] = st
+	}
+	st.lastCall = r.now()
+	st.calls++
+	st.lastTool = tool
+	if call.TenantID != "" {
+		st.tenantID = call.TenantID
+	}
+	if call.ProjectPath != "" {
+		st.projectPath = call.ProjectPath
+	}
+	if projectID != "" {
+		st.projectID = projectID
+	}
+
+	if !succeeded || call.MemoryID == "" {
+		return reopened
+	}
+	switch {
+	case tool == "memory_feedback" && call.Helpful != nil:
+		if *call.Helpful {
+			st.helpful[call.MemoryID] = true
+		} else {
+			delete(st.helpful, call.MemoryID)
+		}
+	case tool == "memory_outcome" && call.Succeeded != nil:
+		st.outcomes[call.MemoryID] = *call.Succeeded
+	}
+	return reopened
+}
+
+// idleSession is a session taken for finalization, with a copy of its state.
+type idleSession struct {
+	id    string
+	state sessionState
+	idle  time.Duration
+}
+
+// closeIdle marks the sessions idle for at least the timeout closed and
+// returns them. Sessions closed for longer than the timeout are forgotten.
+func (r *sessionRegistry) closeIdle() []idleSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var idle []idleSession
+	for id, st := range r.sessions {
+		if st.closed {
+			if now.Sub(st.closedAt) >= r.timeout {
+				delete(r.sessions, id)
+			}
+			continue
+		}
+		if since := now.Sub(st.lastCall); since >= r.timeout {
+			st.closed = true
+			st.closedAt = now
+			idle = append(idle, idleSession{id: id, state: *st, idle: since})
+		}
+	}
+	return idle
+}
+
+// closed reports whether sessionID was finalized and has not been used since.
+func (r *sessionRegistry) closed(sessionID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.sessions[sessionID]
+	return st != nil && st.closed
+}
+
+// trackSessions is receiving middleware that records each tool call naming
+// a session_id, so sessions that stop calling tools can be finalized by
+// finalizeIdleSessions. It is added before translateAliases so it sees the
+// current tool name.
+func (s *Server) trackSessions(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok {
+			return next(ctx, method, req)
+		}
+
+		var args sessionCall
+		if len(call.Params.Arguments) == 0 || json.Unmarshal(call.Params.Arguments, &args) != nil || args.SessionID == "" {
+			return next(ctx, method, req)
+		}
+
+		res, err := next(ctx, method, req)
+
+		result, _ := res.(*mcp.CallToolResult)
+		succeeded := err == nil && (result == nil || !result.IsError)
+		projectID := args.ProjectID
+		if projectID == "" {
+			projectID = s.scopeOf(call.Params.Arguments).ProjectID
+		}
+		if s.sessions.touch(args.SessionID, call.Params.Name, args, projectID, succeeded) {
+			s.logger.Info("finalized session resumed", zap.String("session_id", args.SessionID))
+		}
+		return res, err
+	}
+}
+
+// sweepIdleSessions finalizes idle sessions until Close is called.
+func (s *Server) sweepIdleSessions() {
+	defer close(s.sessions.done)
+
+	// Check a few times per timeout so sessions are finalized close to it
+	interval := max(s.sessions.timeout/4, time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.sessions.stop:
+			return
+		case <-ticker.C:
+			s.finalizeIdleSessions()
+		}
+	}
+}
+
+// finalizeIdleSessions runs the end-of-session actions for every session
+// without tool calls for the idle timeout, and marks it closed.
+func (s *Server) finalizeIdleSessions() {
+	for _, sess := range s.sessions.closeIdle() {
+		s.finalizeSession(sess)
+	}
+}
+
+// finalizeSession runs the end-of-session actions for an abandoned session:
+// a final checkpoint, cleanup of its active branches, a flush of its
+// buffered turns and batched feedback, and outcome inference for the
+// memories it found helpful. Each action is best effort; failures are
+// logged and the rest still run.
+func (s *Server) finalizeSession(sess idleSession) {
+	ctx := vectorstore.ContextWithRequester(context.Background(), s.requester)
+	logger := s.logger.With(zap.String("session_id", sess.id))
+	st := sess.state
+
+	if err := s.saveFinalCheckpoint(ctx, sess); err != nil {
+		logger.Warn("idle session checkpoint failed", zap.Error(err))
+	}
+
+	if s.foldingSvc != nil {
+		if err := s.foldingSvc.CleanupSession(ctx, sess.id); err != nil {
+			logger.Warn("idle session branch cleanup failed", zap.Error(err))
+		}
+	}
+
+	if st.projectID != "" {
+		if _, err := s.reasoningbankSvc.FlushSession(ctx, st.projectID, sess.id); err != nil {
+			logger.Warn("idle session buffer flush failed", zap.Error(err))
+		}
+	}
+
+	outcome, inferred := s.inferSessionOutcome(ctx, logger, sess)
+
+	// Flush last so outcomes recorded above are written too
+	if err := s.reasoningbankSvc.FlushWrites(ctx); err != nil {
+		logger.Warn("idle session feedback flush failed", zap.Error(err))
+	}
+
+	s.metrics.RecordSessionFinalized(ctx, outcome)
+	logger.Info("finalized idle session",
+		zap.Duration("idle", sess.idle),
+		zap.Int("tool_calls", st.calls),
+		zap.String("last_tool", st.lastTool),
+		zap.String("outcome", outcome),
+		zap.Int("inferred_outcomes", inferred),
+	)
+}
+
+// saveFinalCheckpoint saves an auto-created checkpoint recording that the
+// session went idle. Sessions that never named a project path are skipped.
+func (s *Server) saveFinalCheckpoint(ctx context.Context, sess idleSession) error {
+	st := sess.state
+	if st.projectPath == "" {
+		return nil
+	}
+	if err := s.readOnly.Check("checkpoint_save"); err != nil {
+		return err
+	}
+
+	validPath, tenantID, projectID, err := s.validateAndDeriveProjectPath(st.projectPath, st.tenantID)
+	if err != nil {
+		return err
+	}
+	ctx, err = withTenantContext(ctx, tenantID, "", projectID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.checkpointSvc.Save(ctx, &checkpoint.SaveRequest{
+		SessionID:   sess.id,
+		TenantID:    tenantID,
+		ProjectID:   projectID,
+		ProjectPath: validPath,
+		Name:        "Idle session " + sess.id,
+		Summary: fmt.Sprintf("Session finalized after %s without tool calls (%d calls, last: %s).",
+			sess.idle.Round(time.Second), st.calls, st.lastTool),
+		AutoCreated: true,
+		Metadata: map[string]string{
+			"finalized_by": "idle_timeout",
+			"tool_calls":   strconv.Itoa(st.calls),
+			"last_tool":    st.lastTool,
+		},
+	})
+	return err
+}
+
+// inferSessionOutcome records an outcome for the memories the session
+// found helpful but reported no outcome for. The outcome is inferred only
+// when every outcome the session did report agrees; sessions that reported
+// none, or both, are left alone. It returns the session outcome and the
+// number of outcomes recorded.
+func (s *Server) inferSessionOutcome(ctx context.Context, logger *zap.Logger, sess idleSession) (string, int) {
+	st := sess.state
+	var succeeded, failed int
+	for _, ok := range st.outcomes {
+		if ok {
+			succeeded++
+		} else {
+			failed++
+		}
+	}
+	var outcome string
+	switch {
+	case succeeded > 0 && failed == 0:
+		outcome = sessionOutcomeSucceeded
+	case failed > 0 && succeeded == 0:
+		outcome = sessionOutcomeFailed
+	default:
+		return sessionOutcomeUnknown, 0
+	}
+	if err := s.readOnly.Check("memory_outcome"); err != nil {
+		return outcome, 0
+	}
+
+	inferred := 0
+	for memoryID := range st.helpful {
+		if _, reported := st.outcomes[memoryID]; reported {
+			continue
+		}
+		if _, err := s.reasoningbankSvc.RecordOutcome(ctx, memoryID, outcome == sessionOutcomeSucceeded, sess.id); err != nil {
+			logger.Warn("idle session outcome inference failed", zap.String("memory_id", memoryID), zap.Error(err))
+			continue
+		}
+		inferred++
+	}
+	return outcome, inferred
+}
@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/folding"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/troubleshoot"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore/storetest"
+)
+
+func TestSessionRegistry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := newSessionRegistry(10 * time.Minute)
+	r.now = func() time.Time { return now }
+
+	helpful, unhelpful, succeeded := true, false, true
+	r.touch("s1", "memory_search", sessionCall{callScope: callScope{TenantID: "acme", ProjectPath: "/repo"}}, "proj", true)
+	r.touch("s1", "memory_feedback", sessionCall{MemoryID: "m1", Helpful: &helpful}, "", true)
+	r.touch("s1", "memory_feedback", sessionCall{MemoryID: "m2", Helpful: &helpful}, "", true)
+	r.touch("s1", "memory_feedback", sessionCall{MemoryID: "m2", Helpful: &unhelpful}, "", true)
+	r.touch("s1", "memory_feedback", sessionCall{MemoryID: "m3", Helpful: &helpful}, "", false)
+	r.touch("s1", "memory_outcome", sessionCall{MemoryID: "m1", Succeeded: &succeeded}, "", true)
+
+	now = now.Add(9 * time.Minute)
+	assert.Empty(t, r.closeIdle(), "sessions are kept open until the timeout")
+
+	now = now.Add(time.Minute)
+	idle := r.closeIdle()
+	require.Len(t, idle, 1)
+	st := idle[0].state
+	assert.Equal(t, "s1", idle[0].id)
+	assert.Equal(t, 10*time.Minute, idle[0].idle)
+	assert.Equal(t, "acme", st.tenantID)
+	assert.Equal(t, "proj", st.projectID, "later calls without a project keep the earlier one")
+	assert.Equal(t, "/repo", st.projectPath)
+	assert.Equal(t, 6, st.calls)
+	assert.Equal(t, "memory_outcome", st.lastTool)
+	assert.Equal(t, map[string]bool{"m1": true}, st.helpful, "unhelpful and failed feedback is not kept")
+	assert.Equal(t, map[string]bool{"m1": true}, st.outcomes)
+	assert.True(t, r.closed("s1"))
+	assert.Empty(t, r.closeIdle(), "closed sessions are finalized once")
+
+	// A later call starts the session afresh
+	assert.True(t, r.touch("s1", "memory_search", sessionCall{}, "", true))
+	assert.False(t, r.closed("s1"))
+	assert.False(t, r.touch("s1", "memory_search", sessionCall{}, "", true))
+
+	// Closed sessions are forgotten a timeout after they closed
+	now = now.Add(10 * time.Minute)
+	require.Len(t, r.closeIdle(), 1)
+	now = now.Add(10 * time.Minute)
+	assert.Empty(t, r.closeIdle())
+	assert.NotContains(t, r.sessions, "s1")
+}
+
+type sessionTestInput struct {
+	SessionID string `json:"session_id,omitempty"`
+	MemoryID  string `json:"memory_id,omitempty"`
+	Helpful   bool   `json:"helpful,omitempty"`
+}
+
+func TestTrackSessions(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{
+		mcp:      mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil),
+		logger:   zap.NewNop(),
+		sessions: newSessionRegistry(time.Hour),
+	}
+	s.mcp.AddReceivingMiddleware(s.trackSessions)
+	mcp.AddTool(s.mcp, &mcp.Tool{Name: "memory_feedback"}, func(ctx context.Context, req *mcp.CallToolRequest, args sessionTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+		if args.MemoryID == "missing" {
+			return nil, formatTestOutput{}, errors.New("memory not found")
+		}
+		return nil, formatTestOutput{ID: args.MemoryID}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := s.mcp.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	for _, args := range []map[string]any{
+		{"session_id": "s1", "memory_id": "m1", "helpful": true},
+		{"session_id": "s1", "memory_id": "missing", "helpful": true},
+		{"memory_id": "m2", "helpful": true},
+	} {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "memory_feedback", Arguments: args})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, s.sessions.sessions, 1, "calls without a session_id are not tracked")
+	st := s.sessions.sessions["s1"]
+	require.NotNil(t, st)
+	assert.Equal(t, 2, st.calls)
+	assert.Equal(t, map[string]bool{"m1": true}, st.helpful, "feedback that failed is not kept")
+}
+
+// recordingCheckpoints records the checkpoints saved through it.
+type recordingCheckpoints struct {
+	checkpoint.Service
+	saved []*checkpoint.SaveRequest
+}
+
+func (r *recordingCheckpoints) Save(ctx context.Context, req *checkpoint.SaveRequest) (*checkpoint.Checkpoint, error) {
+	r.saved = append(r.saved, req)
+	return &checkpoint.Checkpoint{ID: "cp-1", SessionID: req.SessionID}, nil
+}
+
+func TestServer_FinalizeIdleSessions(t *testing.T) {
+	logger := zap.NewNop()
+	vectorStore := &mockVectorStore{}
+
+	memoryStore, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: storetest.VectorSize,
+	}, storetest.Embedder{}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = memoryStore.Close() })
+
+	baseCheckpoints, err := checkpoint.NewServiceWithStore(checkpoint.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	checkpoints := &recordingCheckpoints{Service: baseCheckpoints}
+	remediationSvc, err := remediation.NewService(remediation.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	troubleshootSvc, err := troubleshoot.NewService(&mockTroubleshootStore{}, logger, nil)
+	require.NoError(t, err)
+	reasoningbankSvc, err := reasoningbank.NewService(memoryStore, logger, reasoningbank.WithDefaultTenant("acme"))
+	require.NoError(t, err)
+	scrubber := secrets.MustNew(secrets.DefaultConfig())
+
+	foldingEmitter := folding.NewSimpleEventEmitter()
+	foldingSvc := folding.NewBranchManager(
+		folding.NewMemoryBranchRepository(),
+		folding.NewBudgetTracker(foldingEmitter),
+		&testScrubberAdapter{scrubber: scrubber},
+		foldingEmitter,
+		folding.DefaultFoldingConfig(),
+	)
+
+	cfg := DefaultConfig()
+	cfg.SessionIdleTimeout = 30 * time.Minute
+	server, err := NewServer(cfg, checkpoints, remediationSvc, repository.NewService(vectorStore), troubleshootSvc, reasoningbankSvc, foldingSvc, nil, scrubber)
+	require.NoError(t, err)
+	defer server.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	server.sessions.now = func() time.Time { return now }
+
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "acme", ProjectID: "proj"})
+	record := func(title string) string {
+		memory, err := reasoningbank.NewMemory("proj", title, title+" before deploying", reasoningbank.OutcomeSuccess, nil)
+		require.NoError(t, err)
+		require.NoError(t, reasoningbankSvc.Record(ctx, memory))
+		return memory.ID
+	}
+	confidence := func(id string) float64 {
+		memory, err := reasoningbankSvc.Get(ctx, id)
+		require.NoError(t, err)
+		return memory.Confidence
+	}
+	reported, unreported := record("run migrations"), record("warm caches")
+	reportedBefore, unreportedBefore := confidence(reported), confidence(unreported)
+
+	branch, err := foldingSvc.Create(ctx, folding.BranchRequest{SessionID: "s1", Description: "explore", Prompt: "look around"})
+	require.NoError(t, err)
+
+	helpful, succeeded := true, true
+	scope := callScope{TenantID: "acme", ProjectID: "proj", ProjectPath: t.TempDir()}
+	server.sessions.touch("s1", "memory_search", sessionCall{callScope: scope}, "proj", true)
+	server.sessions.touch("s1", "memory_feedback", sessionCall{MemoryID: reported, Helpful: &helpful}, "", true)
+	server.sessions.touch("s1", "memory_feedback", sessionCall{MemoryID: unreported, Helpful: &helpful}, "", true)
+	server.sessions.touch("s1", "memory_outcome", sessionCall{MemoryID: reported, Succeeded: &succeeded}, "", true)
+
+	now = now.Add(29 * time.Minute)
+	server.finalizeIdleSessions()
+	assert.False(t, server.sessions.closed("s1"))
+	assert.Empty(t, checkpoints.saved)
+
+	now = now.Add(time.Minute)
+	server.finalizeIdleSessions()
+	assert.True(t, server.sessions.closed("s1"))
+
+	t.Run("saves a final checkpoint", func(t *testing.T) {
+		require.Len(t, checkpoints.saved, 1)
+		saved := checkpoints.saved[0]
+		assert.Equal(t, "s1", saved.SessionID)
+		assert.Equal(t, "acme", saved.TenantID)
+		assert.True(t, saved.AutoCreated)
+		assert.Equal(t, "idle_timeout", saved.Metadata["finalized_by"])
+		assert.Equal(t, "4", saved.Metadata["tool_calls"])
+	})
+
+	t.Run("returns active branches", func(t *testing.T) {
+		got, err := foldingSvc.Get(ctx, branch.BranchID)
+		require.NoError(t, err)
+		assert.NotEqual(t, folding.BranchStatusActive, got.Status)
+	})
+
+	t.Run("infers the outcome of unreported helpful memories", func(t *testing.T) {
+		assert.NotEqual(t, unreportedBefore, confidence(unreported), "an outcome is recorded")
+		assert.Equal(t, reportedBefore, confidence(reported), "reported outcomes are not recorded again")
+	})
+
+	t.Run("mixed outcomes are not inferred", func(t *testing.T) {
+		failed := false
+		other := record("pin the linter")
+		before := confidence(other)
+		server.sessions.touch("s2", "memory_feedback", sessionCall{MemoryID: other, Helpful: &helpful}, "proj", true)
+		server.sessions.touch("s2", "memory_outcome", sessionCall{MemoryID: reported, Succeeded: &succeeded}, "", true)
+		server.sessions.touch("s2", "memory_outcome", sessionCall{MemoryID: unreported, Succeeded: &failed}, "", true)
+
+		now = now.Add(30 * time.Minute)
+		server.finalizeIdleSessions()
+		assert.True(t, server.sessions.closed("s2"))
+		assert.Equal(t, before, confidence(other))
+		assert.Len(t, checkpoints.saved, 1, "sessions without a project path get no checkpoint")
+	})
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/unixsock"
+)
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &Server{
+		mcp:    mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil),
+		logger: zap.NewNop(),
+	}
+	mcp.AddTool(s.mcp, &mcp.Tool{Name: "lookup"}, func(ctx context.Context, req *mcp.CallToolRequest, args analyticsTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+		return nil, formatTestOutput{ID: args.ProjectID}, nil
+	})
+
+	dir, err := os.MkdirTemp("", "ctxd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "mcp.sock")
+	ln, err := unixsock.Listen(socketPath, 0)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln) }()
+
+	// Two clients get independent sessions on the same server.
+	for _, project := range []string{"api", "web"} {
+		conn, err := unixsock.Dial(ctx, socketPath)
+		require.NoError(t, err)
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+		session, err := client.Connect(ctx, &mcp.IOTransport{Reader: conn, Writer: conn}, nil)
+		require.NoError(t, err)
+
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: map[string]any{"project_id": project}})
+		require.NoError(t, err)
+		assert.Equal(t, project, res.StructuredContent.(map[string]any)["id"])
+		defer session.Close()
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after cancel")
+	}
+	_, err = os.Lstat(socketPath)
+	assert.True(t, os.IsNotExist(err), "socket should be removed")
+}
@@ -125,6 +125,15 @@ func (s *Server) registerTools() error {
 	// Memory tools (ReasoningBank)
 	s.registerMemoryTools()
 
+	// Memory batch tools (several memories per call)
+	s.registerMemoryBatchTools()
+
+	// Visibility tools (memory access labels)
+	s.registerVisibilityTools()
+
+	// Similarity tools (more like this)
+	s.registerSimilarTools()
+
 	// Folding tools (context-folding branch/return)
 	s.registerFoldingTools()
 
@@ -134,12 +143,30 @@ func (s *Server) registerTools() error {
 	// Reflection tools (pattern analysis and reporting)
 	s.registerReflectionTools()
 
+	// Session tools (session-start bundle)
+	s.registerSessionTools()
+
+	// Knowledge tools (federated search)
+	s.registerKnowledgeTools()
+
+	// Handoff tools (cross-session handoff bundles)
+	s.registerHandoffTools()
+
+	// Help tools (questions about contextd itself)
+	s.registerHelpTools()
+
+	// Capability tools (version negotiation and feature discovery)
+	s.registerCapabilityTools()
+
 	return nil
 }
 
 // ===== CHECKPOINT TOOLS =====
 
 type checkpointSaveInput struct {
+	responseFormat
+	idempotencyKey
+
 	SessionID   string            `json:"session_id" jsonschema:"required,Session identifier"`
 	TenantID    string            `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
 	ProjectPath string            `json:"project_path" jsonschema:"required,Project path"`
@@ -152,10 +179,12 @@ type checkpointSaveInput struct {
 	Threshold   float64           `json:"threshold" jsonschema:"Context threshold that triggered checkpoint"`
 	AutoCreated bool              `json:"auto_created" jsonschema:"True if auto-created by system"`
 	Metadata    map[string]string `json:"metadata,omitempty" jsonschema:"Additional metadata"`
+	ParentID    string            `json:"parent_id,omitempty" jsonschema:"Checkpoint this one continues from (defaults to the session's last saved or resumed checkpoint)"`
 }
 
 type checkpointSaveOutput struct {
 	ID          string `json:"id" jsonschema:"Checkpoint ID"`
+	ParentID    string `json:"parent_id,omitempty" jsonschema:"Checkpoint this one continues from"`
 	SessionID   string `json:"session_id" jsonschema:"Session ID"`
 	Summary     string `json:"summary" jsonschema:"Checkpoint summary"`
 	TokenCount  int32  `json:"token_count" jsonschema:"Token count"`
@@ -163,11 +192,15 @@ type checkpointSaveOutput struct {
 }
 
 type checkpointListInput struct {
+	responseFormat
+
 	SessionID   string `json:"session_id,omitempty" jsonschema:"Filter by session ID"`
 	TenantID    string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
 	ProjectPath string `json:"project_path,omitempty" jsonschema:"Filter by project path (used to derive tenant_id via git remote)"`
 	Limit       int    `json:"limit,omitempty" jsonschema:"Maximum results to return (default: 20)"`
 	AutoOnly    bool   `json:"auto_only,omitempty" jsonschema:"Only return auto-created checkpoints"`
+
+	Outcome checkpoint.Outcome `json:"outcome,omitempty" jsonschema:"Only return checkpoints annotated with this outcome (succeeded failed or partial)"`
 }
 
 type checkpointListOutput struct {
@@ -175,10 +208,32 @@ type checkpointListOutput struct {
 	Count       int                      `json:"count" jsonschema:"Number of checkpoints returned"`
 }
 
+type checkpointAnnotateInput struct {
+	responseFormat
+
+	CheckpointID string             `json:"checkpoint_id" jsonschema:"required,Checkpoint ID to annotate"`
+	TenantID     string             `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
+	ProjectPath  string             `json:"project_path" jsonschema:"required,Project path the checkpoint was saved under"`
+	Outcome      checkpoint.Outcome `json:"outcome,omitempty" jsonschema:"How the task turned out (succeeded failed or partial)"`
+	PRURL        string             `json:"pr_url,omitempty" jsonschema:"Pull request URL for the work"`
+	Notes        string             `json:"notes,omitempty" jsonschema:"Free-form notes such as what went wrong (max 4096 characters)"`
+}
+
+type checkpointAnnotateOutput struct {
+	CheckpointID string    `json:"checkpoint_id" jsonschema:"Checkpoint ID"`
+	Outcome      string    `json:"outcome,omitempty" jsonschema:"Recorded outcome"`
+	PRURL        string    `json:"pr_url,omitempty" jsonschema:"Recorded pull request URL"`
+	Notes        string    `json:"notes,omitempty" jsonschema:"Recorded notes"`
+	AnnotatedAt  time.Time `json:"annotated_at" jsonschema:"When the annotation was last changed"`
+}
+
 type checkpointResumeInput struct {
+	responseFormat
+
 	CheckpointID string                 `json:"checkpoint_id" jsonschema:"required,Checkpoint ID to resume"`
 	TenantID     string                 `json:"tenant_id" jsonschema:"required,Tenant identifier"`
 	Level        checkpoint.ResumeLevel `json:"level" jsonschema:"required,Resume level (summary context or full)"`
+	SessionID    string                 `json:"session_id,omitempty" jsonschema:"Resuming session ID; its next checkpoint records this one as parent"`
 }
 
 type checkpointResumeOutput struct {
@@ -191,13 +246,18 @@ type checkpointResumeOutput struct {
 
 func (s *Server) registerCheckpointTools() {
 	// checkpoint_save
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "checkpoint_save",
 		Description: "Save a session checkpoint for later resumption",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args checkpointSaveInput) (*mcp.CallToolResult, checkpointSaveOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "checkpoint_save", &toolErr)()
 
+		if err := s.readOnly.Check("checkpoint_save"); err != nil {
+			toolErr = err
+			return nil, checkpointSaveOutput{}, toolErr
+		}
+
 		// Validate and derive tenant context from project path
 		validPath, tenantID, projectID, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
 		if err != nil {
@@ -220,6 +280,7 @@ func (s *Server) registerCheckpointTools() {
 			Threshold:   args.Threshold,
 			AutoCreated: args.AutoCreated,
 			Metadata:    args.Metadata,
+			ParentID:    args.ParentID,
 		}
 
 		// Add tenant context to Go context for vectorstore operations
@@ -237,6 +298,7 @@ func (s *Server) registerCheckpointTools() {
 
 		result := checkpointSaveOutput{
 			ID:          cp.ID,
+			ParentID:    cp.ParentID,
 			SessionID:   cp.SessionID,
 			Summary:     cp.Summary,
 			TokenCount:  cp.TokenCount,
@@ -255,7 +317,7 @@ func (s *Server) registerCheckpointTools() {
 	})
 
 	// checkpoint_list
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "checkpoint_list",
 		Description: "List checkpoints for a session or project",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args checkpointListInput) (*mcp.CallToolResult, checkpointListOutput, error) {
@@ -268,6 +330,10 @@ func (s *Server) registerCheckpointTools() {
 			toolErr = err
 			return nil, checkpointListOutput{}, err
 		}
+		if args.Outcome != "" && !args.Outcome.Valid() {
+			toolErr = fmt.Errorf("invalid outcome %q: must be succeeded, failed or partial", args.Outcome)
+			return nil, checkpointListOutput{}, toolErr
+		}
 
 		listReq := &checkpoint.ListRequest{
 			SessionID:   args.SessionID,
@@ -277,6 +343,7 @@ func (s *Server) registerCheckpointTools() {
 			ProjectPath: validPath,
 			Limit:       args.Limit,
 			AutoOnly:    args.AutoOnly,
+			Outcome:     args.Outcome,
 		}
 
 		// Add tenant context to Go context for vectorstore operations
@@ -298,7 +365,7 @@ func (s *Server) registerCheckpointTools() {
 			scrubbedSummary := s.scrubber.Scrub(cp.Summary).Scrubbed
 			scrubbedDesc := s.scrubber.Scrub(cp.Description).Scrubbed
 
-			results = append(results, map[string]interface{}{
+			result := map[string]interface{}{
 				"id":           cp.ID,
 				"session_id":   cp.SessionID,
 				"name":         cp.Name,
@@ -308,7 +375,22 @@ func (s *Server) registerCheckpointTools() {
 				"threshold":    cp.Threshold,
 				"auto_created": cp.AutoCreated,
 				"created_at":   cp.CreatedAt,
-			})
+			}
+			if a := cp.Annotation; a != nil {
+				result["outcome"] = a.Outcome
+				result["pr_url"] = a.PRURL
+				result["notes"] = s.scrubber.Scrub(a.Notes).Scrubbed
+				result["annotated_at"] = a.AnnotatedAt
+			}
+			if o := cp.Origin; o != nil {
+				result["origin"] = map[string]interface{}{
+					"session_id":   o.SessionID,
+					"message_uuid": o.MessageUUID,
+					"timestamp":    o.Timestamp,
+					"excerpt":      s.scrubber.Scrub(o.Excerpt).Scrubbed,
+				}
+			}
+			results = append(results, result)
 		}
 
 		output := checkpointListOutput{
@@ -323,8 +405,63 @@ func (s *Server) registerCheckpointTools() {
 		}, output, nil
 	})
 
+	// checkpoint_annotate
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "checkpoint_annotate",
+		Description: "Record how the work captured by a checkpoint turned out (outcome, PR link, notes). Annotated checkpoints can be filtered by outcome in checkpoint_list",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args checkpointAnnotateInput) (*mcp.CallToolResult, checkpointAnnotateOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "checkpoint_annotate", &toolErr)()
+
+		if err := s.readOnly.Check("checkpoint_annotate"); err != nil {
+			toolErr = err
+			return nil, checkpointAnnotateOutput{}, toolErr
+		}
+
+		// Validate and derive tenant context from project path
+		_, tenantID, projectID, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
+		if err != nil {
+			toolErr = err
+			return nil, checkpointAnnotateOutput{}, err
+		}
+
+		// Add tenant context to Go context for vectorstore operations
+		ctx, err = withTenantContext(ctx, tenantID, "", projectID)
+		if err != nil {
+			toolErr = err
+			return nil, checkpointAnnotateOutput{}, err
+		}
+
+		cp, err := s.checkpointSvc.Annotate(ctx, &checkpoint.AnnotateRequest{
+			CheckpointID: args.CheckpointID,
+			TenantID:     tenantID,
+			ProjectID:    projectID,
+			Outcome:      args.Outcome,
+			PRURL:        args.PRURL,
+			Notes:        args.Notes,
+		})
+		if err != nil {
+			toolErr = fmt.Errorf("checkpoint annotate failed: %w", err)
+			return nil, checkpointAnnotateOutput{}, toolErr
+		}
+
+		result := checkpointAnnotateOutput{
+			CheckpointID: cp.ID,
+			Outcome:      string(cp.Annotation.Outcome),
+			PRURL:        cp.Annotation.PRURL,
+			Notes:        s.scrubber.Scrub(cp.Annotation.Notes).Scrubbed,
+			AnnotatedAt:  cp.Annotation.AnnotatedAt,
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Annotated checkpoint %s", result.CheckpointID)},
+			},
+		}, result, nil
+	})
+
 	// checkpoint_resume
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "checkpoint_resume",
 		Description: "Resume from a checkpoint at specified level (summary, context, or full)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args checkpointResumeInput) (*mcp.CallToolResult, checkpointResumeOutput, error) {
@@ -340,6 +477,7 @@ func (s *Server) registerCheckpointTools() {
 		resumeReq := &checkpoint.ResumeRequest{
 			CheckpointID: args.CheckpointID,
 			TenantID:     args.TenantID,
+			SessionID:    args.SessionID,
 			Level:        args.Level,
 		}
 
@@ -379,6 +517,9 @@ func (s *Server) registerCheckpointTools() {
 // ===== REMEDIATION TOOLS =====
 
 type remediationSearchInput struct {
+	responseFormat
+	tokenBudget
+
 	Query            string                    `json:"query" jsonschema:"required,Error message or pattern to search for"`
 	TenantID         string                    `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
 	Scope            remediation.Scope         `json:"scope,omitempty" jsonschema:"Search scope (project team or org)"`
@@ -388,14 +529,21 @@ type remediationSearchInput struct {
 	TeamID           string                    `json:"team_id,omitempty" jsonschema:"Team ID for team/project scope"`
 	ProjectPath      string                    `json:"project_path,omitempty" jsonschema:"Project path for project scope (used to auto-derive tenant_id if empty)"`
 	IncludeHierarchy bool                      `json:"include_hierarchy,omitempty" jsonschema:"Search parent scopes (project→team→org)"`
+	Status           remediation.Status        `json:"status,omitempty" jsonschema:"active (default), pending to list drafts awaiting confirmation, or quarantined to list possible prompt injections awaiting review"`
+	Cursor           string                    `json:"cursor,omitempty" jsonschema:"Cursor from a previous next_cursor to fetch the next page"`
 }
 
 type remediationSearchOutput struct {
 	Remediations []map[string]interface{} `json:"remediations" jsonschema:"Matching remediations with scores"`
 	Count        int                      `json:"count" jsonschema:"Number of results"`
+	NextCursor   string                   `json:"next_cursor,omitempty" jsonschema:"Cursor for the next page (empty when there are no more results)"`
+	budgetUsage
 }
 
 type remediationRecordInput struct {
+	responseFormat
+	idempotencyKey
+
 	Title         string                    `json:"title" jsonschema:"required,Brief title"`
 	Problem       string                    `json:"problem" jsonschema:"required,Problem description"`
 	Symptoms      []string                  `json:"symptoms,omitempty" jsonschema:"Observable symptoms"`
@@ -403,7 +551,7 @@ type remediationRecordInput struct {
 	Solution      string                    `json:"solution" jsonschema:"required,How to fix it"`
 	CodeDiff      string                    `json:"code_diff,omitempty" jsonschema:"Code changes (diff format)"`
 	AffectedFiles []string                  `json:"affected_files,omitempty" jsonschema:"Files that were changed"`
-	Category      remediation.ErrorCategory `json:"category" jsonschema:"required,Error category"`
+	Category      remediation.ErrorCategory `json:"category" jsonschema:"required,Error category; must be in the tenant's category taxonomy when one is configured"`
 	Confidence    float64                   `json:"confidence,omitempty" jsonschema:"Confidence score (0-1 default 0.5)"`
 	Tags          []string                  `json:"tags,omitempty" jsonschema:"Tags for categorization"`
 	TenantID      string                    `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
@@ -417,14 +565,20 @@ type remediationRecordOutput struct {
 	ID         string  `json:"id" jsonschema:"Remediation ID"`
 	Title      string  `json:"title" jsonschema:"Remediation title"`
 	Category   string  `json:"category" jsonschema:"Error category"`
+	Builtin    string  `json:"builtin_category,omitempty" jsonschema:"Built-in category the recorded category refines"`
 	Confidence float64 `json:"confidence" jsonschema:"Confidence score"`
 }
 
 type remediationFeedbackInput struct {
-	RemediationID string `json:"remediation_id" jsonschema:"required,Remediation ID to provide feedback on"`
-	Helpful       bool   `json:"helpful" jsonschema:"required,Whether the remediation was helpful (true) or not (false)"`
-	TenantID      string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived if not provided)"`
-	ProjectPath   string `json:"project_path,omitempty" jsonschema:"Project path (used to auto-derive tenant_id if empty)"`
+	responseFormat
+	idempotencyKey
+
+	RemediationID    string `json:"remediation_id" jsonschema:"required,Remediation ID to provide feedback on"`
+	Helpful          bool   `json:"helpful" jsonschema:"required,Whether the remediation was helpful (true) or not (false)"`
+	TenantID         string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived if not provided)"`
+	ProjectPath      string `json:"project_path,omitempty" jsonschema:"Project path (used to auto-derive tenant_id if empty)"`
+	Evidence         string `json:"evidence,omitempty" jsonschema:"What you actually did with the remediation and what happened (e.g. the command run and its result). Evidence-backed feedback weighs more and is kept for review"`
+	ExpectedRevision int64  `json:"expected_revision,omitempty" jsonschema:"Only apply if the remediation is still at this revision (from a search result); fails with a conflict otherwise"`
 }
 
 type remediationFeedbackOutput struct {
@@ -433,11 +587,26 @@ type remediationFeedbackOutput struct {
 	Helpful       bool    `json:"helpful" jsonschema:"Feedback provided (helpful or not)"`
 }
 
+type remediationConfirmInput struct {
+	responseFormat
+
+	RemediationID    string `json:"remediation_id" jsonschema:"required,ID of the pending or quarantined remediation"`
+	Reject           bool   `json:"reject,omitempty" jsonschema:"Delete the draft instead of confirming it"`
+	TenantID         string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived if not provided)"`
+	ProjectPath      string `json:"project_path,omitempty" jsonschema:"Project path (used to auto-derive tenant_id if empty)"`
+	ExpectedRevision int64  `json:"expected_revision,omitempty" jsonschema:"Only apply if the remediation is still at this revision (from a search result); fails with a conflict otherwise"`
+}
+
+type remediationConfirmOutput struct {
+	RemediationID string `json:"remediation_id" jsonschema:"Remediation ID"`
+	Status        string `json:"status" jsonschema:"active if confirmed or deleted if rejected"`
+}
+
 func (s *Server) registerRemediationTools() {
 	// remediation_search
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "remediation_search",
-		Description: "Search for remediations by error message or pattern",
+		Description: "Search for remediations by error message or pattern. Pasting the raw error or stack trace finds remediations recorded for the same error exactly (signature_match: true) even when addresses, line numbers or IDs differ; otherwise results are ranked by semantic similarity.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args remediationSearchInput) (*mcp.CallToolResult, remediationSearchOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "remediation_search", &toolErr)()
@@ -454,6 +623,16 @@ func (s *Server) registerRemediationTools() {
 			toolErr = fmt.Errorf("invalid team_id: %w", err)
 			return nil, remediationSearchOutput{}, toolErr
 		}
+		switch args.Status {
+		case "", remediation.StatusActive, remediation.StatusPending, remediation.StatusQuarantined:
+		default:
+			toolErr = fmt.Errorf("status must be 'active', 'pending' or 'quarantined'")
+			return nil, remediationSearchOutput{}, toolErr
+		}
+		if err := args.tokenBudget.validate(); err != nil {
+			toolErr = err
+			return nil, remediationSearchOutput{}, toolErr
+		}
 
 		searchReq := &remediation.SearchRequest{
 			Query:            args.Query,
@@ -465,6 +644,8 @@ func (s *Server) registerRemediationTools() {
 			TeamID:           args.TeamID,
 			ProjectPath:      validPath,
 			IncludeHierarchy: args.IncludeHierarchy,
+			Status:           args.Status,
+			Cursor:           args.Cursor,
 		}
 
 		// Add tenant context to Go context for vectorstore operations
@@ -474,7 +655,7 @@ func (s *Server) registerRemediationTools() {
 			return nil, remediationSearchOutput{}, err
 		}
 
-		results, err := s.remediationSvc.Search(ctx, searchReq)
+		results, nextCursor, err := s.remediationSvc.SearchPage(ctx, searchReq)
 		if err != nil {
 			toolErr = fmt.Errorf("remediation search failed: %w", err)
 			return nil, remediationSearchOutput{}, toolErr
@@ -482,7 +663,7 @@ func (s *Server) registerRemediationTools() {
 
 		remediations := make([]map[string]interface{}, 0, len(results))
 		for _, r := range results {
-			remediations = append(remediations, map[string]interface{}{
+			item := map[string]interface{}{
 				"id":          r.Remediation.ID,
 				"title":       r.Remediation.Title,
 				"problem":     r.Remediation.Problem,
@@ -492,12 +673,26 @@ func (s *Server) registerRemediationTools() {
 				"confidence":  r.Remediation.Confidence,
 				"score":       r.Score,
 				"usage_count": r.Remediation.UsageCount,
-			})
+				"status":      string(r.Remediation.Status),
+				"revision":    r.Remediation.Revision,
+			}
+			if r.SignatureMatch {
+				item["signature_match"] = true
+			}
+			if len(r.Remediation.QuarantineRules) > 0 {
+				item["quarantine_rules"] = r.Remediation.QuarantineRules
+			}
+			remediations = append(remediations, item)
 		}
+		// The solution is the key excerpt under a token budget
+		remediations, usage := fitResultsToBudget(remediations, []string{"solution", "problem", "root_cause"}, args.MaxTokens)
+		nextCursor = budgetCursor(args.Cursor, nextCursor, remediation.CursorHash(searchReq), len(results), len(remediations))
 
 		output := remediationSearchOutput{
 			Remediations: remediations,
 			Count:        len(remediations),
+			NextCursor:   nextCursor,
+			budgetUsage:  usage,
 		}
 
 		return &mcp.CallToolResult{
@@ -508,13 +703,18 @@ func (s *Server) registerRemediationTools() {
 	})
 
 	// remediation_record
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "remediation_record",
 		Description: "Record a new remediation for an error that was successfully fixed",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args remediationRecordInput) (*mcp.CallToolResult, remediationRecordOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "remediation_record", &toolErr)()
 
+		if err := s.readOnly.Check("remediation_record"); err != nil {
+			toolErr = err
+			return nil, remediationRecordOutput{}, toolErr
+		}
+
 		// Validate and derive tenant context from project path
 		validPath, tenantID, _, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
 		if err != nil {
@@ -563,6 +763,7 @@ func (s *Server) registerRemediationTools() {
 			ID:         rem.ID,
 			Title:      rem.Title,
 			Category:   string(rem.Category),
+			Builtin:    string(rem.BuiltinCategory),
 			Confidence: rem.Confidence,
 		}
 
@@ -574,13 +775,18 @@ func (s *Server) registerRemediationTools() {
 	})
 
 	// remediation_feedback
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "remediation_feedback",
 		Description: "Provide feedback on whether a remediation was helpful. Updates confidence score based on real-world success/failure.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args remediationFeedbackInput) (*mcp.CallToolResult, remediationFeedbackOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "remediation_feedback", &toolErr)()
 
+		if err := s.readOnly.Check("remediation_feedback"); err != nil {
+			toolErr = err
+			return nil, remediationFeedbackOutput{}, toolErr
+		}
+
 		// Validate input
 		if args.RemediationID == "" {
 			toolErr = fmt.Errorf("remediation_id is required")
@@ -616,8 +822,10 @@ func (s *Server) registerRemediationTools() {
 			RemediationID: args.RemediationID,
 			TenantID:      tenantID,
 			Rating:        rating,
+			Evidence:      args.Evidence,
 		}
 
+		ctx = remediation.ContextWithExpectedRevision(ctx, args.ExpectedRevision)
 		if err := s.remediationSvc.Feedback(ctx, feedbackReq); err != nil {
 			toolErr = fmt.Errorf("remediation feedback failed: %w", err)
 			return nil, remediationFeedbackOutput{}, toolErr
@@ -650,11 +858,83 @@ func (s *Server) registerRemediationTools() {
 			},
 		}, output, nil
 	})
+
+	// remediation_confirm
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "remediation_confirm",
+		Description: "Confirm or reject a pending remediation, such as one drafted from a CI failure, or a remediation quarantined as possible prompt injection. Confirmed remediations are returned by searches.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args remediationConfirmInput) (*mcp.CallToolResult, remediationConfirmOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "remediation_confirm", &toolErr)()
+
+		if err := s.readOnly.Check("remediation_confirm"); err != nil {
+			toolErr = err
+			return nil, remediationConfirmOutput{}, toolErr
+		}
+
+		if args.RemediationID == "" {
+			toolErr = fmt.Errorf("remediation_id is required")
+			return nil, remediationConfirmOutput{}, toolErr
+		}
+
+		tenantID := args.TenantID
+		if tenantID == "" && args.ProjectPath != "" {
+			tenantID = tenant.GetTenantIDForPath(args.ProjectPath)
+		}
+		if tenantID == "" {
+			toolErr = fmt.Errorf("tenant_id is required for data isolation. It is usually auto-detected from your git repository. Try running from within a git repository, or set tenant_id explicitly")
+			return nil, remediationConfirmOutput{}, toolErr
+		}
+		if err := sanitize.ValidateTenantID(tenantID); err != nil {
+			toolErr = fmt.Errorf("invalid tenant_id: %w", err)
+			return nil, remediationConfirmOutput{}, toolErr
+		}
+
+		ctx = remediation.ContextWithExpectedRevision(ctx, args.ExpectedRevision)
+		if args.Reject {
+			rem, err := s.remediationSvc.Get(ctx, tenantID, args.RemediationID)
+			if err != nil {
+				toolErr = fmt.Errorf("remediation confirm failed: %w", err)
+				return nil, remediationConfirmOutput{}, toolErr
+			}
+			if err := remediation.CheckExpectedRevision(ctx, rem); err != nil {
+				toolErr = fmt.Errorf("remediation reject failed: %w", err)
+				return nil, remediationConfirmOutput{}, toolErr
+			}
+			if rem.Status != remediation.StatusPending && rem.Status != remediation.StatusQuarantined {
+				toolErr = fmt.Errorf("remediation %s is not pending or quarantined", args.RemediationID)
+				return nil, remediationConfirmOutput{}, toolErr
+			}
+			if err := s.remediationSvc.Delete(ctx, tenantID, args.RemediationID); err != nil {
+				toolErr = fmt.Errorf("remediation reject failed: %w", err)
+				return nil, remediationConfirmOutput{}, toolErr
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Remediation %s rejected and deleted", args.RemediationID)},
+				},
+			}, remediationConfirmOutput{RemediationID: args.RemediationID, Status: "deleted"}, nil
+		}
+
+		rem, err := s.remediationSvc.Confirm(ctx, tenantID, args.RemediationID)
+		if err != nil {
+			toolErr = fmt.Errorf("remediation confirm failed: %w", err)
+			return nil, remediationConfirmOutput{}, toolErr
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Remediation %s confirmed", rem.ID)},
+			},
+		}, remediationConfirmOutput{RemediationID: rem.ID, Status: string(rem.Status)}, nil
+	})
 }
 
 // ===== REPOSITORY TOOLS =====
 
 type semanticSearchInput struct {
+	responseFormat
+
 	Query       string `json:"query" jsonschema:"required,Search query (natural language or pattern)"`
 	ProjectPath string `json:"project_path" jsonschema:"required,Project path to search within"`
 	TenantID    string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (defaults to git username)"`
@@ -670,12 +950,21 @@ type semanticSearchOutput struct {
 }
 
 type repositoryIndexInput struct {
+	responseFormat
+
 	Path            string   `json:"path" jsonschema:"required,Repository path to index"`
 	TenantID        string   `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (defaults to git username)"`
 	Branch          string   `json:"branch,omitempty" jsonschema:"Git branch to index (auto-detects if empty)"`
 	IncludePatterns []string `json:"include_patterns,omitempty" jsonschema:"Glob patterns to include (e.g. *.go)"`
 	ExcludePatterns []string `json:"exclude_patterns,omitempty" jsonschema:"Glob patterns to exclude (e.g. vendor/**)"`
 	MaxFileSize     int64    `json:"max_file_size,omitempty" jsonschema:"Maximum file size in bytes (default 1MB)"`
+
+	Preprocessors []repositoryPreprocessorRule `json:"preprocessors,omitempty" jsonschema:"Transformations of matching files before embedding, applied after the server's configured rules"`
+}
+
+type repositoryPreprocessorRule struct {
+	Pattern string   `json:"pattern" jsonschema:"required,Glob matched against the file name or repository-relative path (e.g. *.ipynb, infra/**)"`
+	Use     []string `json:"use" jsonschema:"required,Preprocessors to run in order: strip_license_header, render_notebook, expand_terraform_modules"`
 }
 
 type repositoryIndexOutput struct {
@@ -686,16 +975,24 @@ type repositoryIndexOutput struct {
 	IncludePatterns []string `json:"include_patterns" jsonschema:"Include patterns used"`
 	ExcludePatterns []string `json:"exclude_patterns" jsonschema:"Exclude patterns used"`
 	MaxFileSize     int64    `json:"max_file_size" jsonschema:"Max file size used"`
+
+	Preprocessed     int      `json:"preprocessed,omitempty" jsonschema:"Files transformed by a preprocessor"`
+	PreprocessErrors []string `json:"preprocess_errors,omitempty" jsonschema:"Failed preprocessors; those files were indexed untransformed"`
 }
 
 type repositorySearchInput struct {
+	responseFormat
+	tokenBudget
+
 	Query          string `json:"query" jsonschema:"required,Semantic search query"`
 	ProjectPath    string `json:"project_path,omitempty" jsonschema:"Project path to search within (optional if collection_name provided)"`
 	CollectionName string `json:"collection_name,omitempty" jsonschema:"Collection name from repository_index (preferred - avoids tenant_id derivation issues)"`
 	TenantID       string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (defaults to git username)"`
 	Branch         string `json:"branch,omitempty" jsonschema:"Filter by branch (empty = all branches)"`
+	DocType        string `json:"doc_type,omitempty" jsonschema:"Only knowledge documents of this type: adr, rfc, runbook or postmortem. A type:adr token in the query does the same"`
 	Limit          int    `json:"limit,omitempty" jsonschema:"Maximum results (default: 10)"`
 	ContentMode    string `json:"content_mode,omitempty" jsonschema:"Content mode: minimal (default), preview, or full"`
+	Cursor         string `json:"cursor,omitempty" jsonschema:"Cursor from a previous next_cursor to fetch the next page"`
 }
 
 type repositorySearchOutput struct {
@@ -704,11 +1001,13 @@ type repositorySearchOutput struct {
 	Query       string                   `json:"query" jsonschema:"Original search query"`
 	Branch      string                   `json:"branch,omitempty" jsonschema:"Branch filter applied (if any)"`
 	ContentMode string                   `json:"content_mode" jsonschema:"Content mode used"`
+	NextCursor  string                   `json:"next_cursor,omitempty" jsonschema:"Cursor for the next page (empty when there are no more results)"`
+	budgetUsage
 }
 
 func (s *Server) registerRepositoryTools() {
 	// semantic_search
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "semantic_search",
 		Description: "Smart search that uses semantic understanding, falling back to grep if needed. Use this when the agent would normally use the Search tool.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args semanticSearchInput) (*mcp.CallToolResult, semanticSearchOutput, error) {
@@ -825,9 +1124,9 @@ func (s *Server) registerRepositoryTools() {
 	})
 
 	// repository_search
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "repository_search",
-		Description: "Semantic search over indexed repository code in _codebase collection. Prefer using collection_name from repository_index output.",
+		Description: "Semantic search over indexed repository code in _codebase collection. Prefer using collection_name from repository_index output. Set doc_type (or add type:adr to the query) to search only architecture decision records, RFCs, runbooks or postmortems.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args repositorySearchInput) (*mcp.CallToolResult, repositorySearchOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "repository_search", &toolErr)()
@@ -850,7 +1149,9 @@ func (s *Server) registerRepositoryTools() {
 			ProjectPath:    validPath,
 			TenantID:       tenantID,
 			Branch:         args.Branch,
+			DocType:        args.DocType,
 			Limit:          args.Limit,
+			Cursor:         args.Cursor,
 		}
 
 		// Add tenant context to Go context for vectorstore operations
@@ -860,12 +1161,6 @@ func (s *Server) registerRepositoryTools() {
 			return nil, repositorySearchOutput{}, toolErr
 		}
 
-		results, err := s.repositorySvc.Search(ctx, args.Query, opts)
-		if err != nil {
-			toolErr = fmt.Errorf("repository search failed: %w", err)
-			return nil, repositorySearchOutput{}, toolErr
-		}
-
 		// Content mode constants
 		const (
 			previewMaxRunes = 200
@@ -886,6 +1181,16 @@ func (s *Server) registerRepositoryTools() {
 			toolErr = fmt.Errorf("invalid content_mode: %q (must be 'minimal', 'preview', or 'full')", contentMode)
 			return nil, repositorySearchOutput{}, toolErr
 		}
+		if err := args.tokenBudget.validate(); err != nil {
+			toolErr = err
+			return nil, repositorySearchOutput{}, toolErr
+		}
+
+		results, nextCursor, err := s.repositorySvc.SearchPage(ctx, args.Query, opts)
+		if err != nil {
+			toolErr = fmt.Errorf("repository search failed: %w", err)
+			return nil, repositorySearchOutput{}, toolErr
+		}
 
 		// Convert to output format based on content mode
 		outputResults := make([]map[string]interface{}, 0, len(results))
@@ -895,6 +1200,14 @@ func (s *Server) registerRepositoryTools() {
 				"score":     r.Score,
 				"branch":    r.Branch,
 			}
+			// Knowledge documents carry what an agent needs to cite them
+			if r.DocType != "" {
+				result["doc_type"] = r.DocType
+				result["doc_title"] = r.Title
+				if status, ok := r.Metadata[repository.MetadataDocStatus].(string); ok {
+					result["doc_status"] = status
+				}
+			}
 
 			// Scrub content once before use (only if needed)
 			var scrubbedContent string
@@ -921,6 +1234,8 @@ func (s *Server) registerRepositoryTools() {
 
 			outputResults = append(outputResults, result)
 		}
+		outputResults, usage := fitResultsToBudget(outputResults, []string{"content_preview", "content"}, args.MaxTokens)
+		nextCursor = budgetCursor(args.Cursor, nextCursor, repository.CursorHash(args.Query, opts), len(results), len(outputResults))
 
 		output := repositorySearchOutput{
 			Results:     outputResults,
@@ -928,6 +1243,8 @@ func (s *Server) registerRepositoryTools() {
 			Query:       args.Query,
 			Branch:      args.Branch,
 			ContentMode: contentMode,
+			NextCursor:  nextCursor,
+			budgetUsage: usage,
 		}
 
 		return &mcp.CallToolResult{
@@ -938,13 +1255,18 @@ func (s *Server) registerRepositoryTools() {
 	})
 
 	// repository_index
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "repository_index",
 		Description: "Index a repository for semantic code search",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args repositoryIndexInput) (*mcp.CallToolResult, repositoryIndexOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "repository_index", &toolErr)()
 
+		if err := s.readOnly.Check("repository_index"); err != nil {
+			toolErr = err
+			return nil, repositoryIndexOutput{}, toolErr
+		}
+
 		// Path is required
 		if args.Path == "" {
 			toolErr = fmt.Errorf("path is required")
@@ -1002,6 +1324,9 @@ func (s *Server) registerRepositoryTools() {
 			ExcludePatterns: excludePatterns,
 			MaxFileSize:     args.MaxFileSize,
 		}
+		for _, rule := range args.Preprocessors {
+			opts.Preprocessors = append(opts.Preprocessors, repository.PreprocessorRule{Pattern: rule.Pattern, Use: rule.Use})
+		}
 
 		// Add tenant context to Go context for vectorstore operations
 		ctx, err = withTenantContext(ctx, tenantID, "", projectID)
@@ -1034,6 +1359,9 @@ func (s *Server) registerRepositoryTools() {
 			IncludePatterns: outputInclude,
 			ExcludePatterns: outputExclude,
 			MaxFileSize:     result.MaxFileSize,
+
+			Preprocessed:     result.Preprocessed,
+			PreprocessErrors: result.PreprocessErrors,
 		}
 
 		return &mcp.CallToolResult{
@@ -1047,29 +1375,46 @@ func (s *Server) registerRepositoryTools() {
 // ===== TROUBLESHOOT TOOLS =====
 
 type troubleshootDiagnoseInput struct {
+	responseFormat
+
 	ErrorMessage string `json:"error_message" jsonschema:"required,Error message to diagnose"`
 	ErrorContext string `json:"error_context,omitempty" jsonschema:"Additional context (stack trace logs etc)"`
+	ProjectPath  string `json:"project_path,omitempty" jsonschema:"Project the error occurred in. When its repository is indexed, code at file:line locations in the error and context is included in the diagnosis"`
+	TenantID     string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
+	Branch       string `json:"branch,omitempty" jsonschema:"Indexed branch to read code from (empty = any)"`
 }
 
 type troubleshootDiagnoseOutput struct {
-	ErrorMessage    string                    `json:"error_message" jsonschema:"Original error message"`
-	RootCause       string                    `json:"root_cause" jsonschema:"Likely root cause"`
-	Hypotheses      []troubleshoot.Hypothesis `json:"hypotheses" jsonschema:"Diagnostic hypotheses"`
-	Recommendations []string                  `json:"recommendations" jsonschema:"Recommended actions"`
-	RelatedPatterns []troubleshoot.Pattern    `json:"related_patterns" jsonschema:"Similar known patterns"`
-	Confidence      float64                   `json:"confidence" jsonschema:"Overall confidence (0-1)"`
+	ErrorMessage    string                     `json:"error_message" jsonschema:"Original error message"`
+	RootCause       string                     `json:"root_cause" jsonschema:"Likely root cause"`
+	Hypotheses      []troubleshoot.Hypothesis  `json:"hypotheses" jsonschema:"Diagnostic hypotheses"`
+	Recommendations []string                   `json:"recommendations" jsonschema:"Recommended actions"`
+	RelatedPatterns []troubleshoot.Pattern     `json:"related_patterns" jsonschema:"Similar known patterns"`
+	Confidence      float64                    `json:"confidence" jsonschema:"Overall confidence (0-1)"`
+	Warnings        []string                   `json:"warnings,omitempty" jsonschema:"Degraded diagnosis, e.g. pattern-only because the LLM budget is exhausted"`
+	CodeSnippets    []troubleshoot.CodeSnippet `json:"code_snippets,omitempty" jsonschema:"Indexed repository code at locations the error references"`
 }
 
 func (s *Server) registerTroubleshootTools() {
 	// troubleshoot_diagnose
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "troubleshoot_diagnose",
-		Description: "Diagnose an error using AI and known patterns",
+		Description: "Diagnose an error using AI and known patterns. Pass project_path to ground the diagnosis in the project's indexed code: files and lines referenced by the error or stack trace are looked up and returned as code_snippets",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args troubleshootDiagnoseInput) (*mcp.CallToolResult, troubleshootDiagnoseOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "troubleshoot_diagnose", &toolErr)()
 
-		diagnosis, err := s.troubleshootSvc.Diagnose(ctx, args.ErrorMessage, args.ErrorContext)
+		var opts troubleshoot.DiagnoseOptions
+		if args.ProjectPath != "" {
+			validPath, tenantID, _, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
+			if err != nil {
+				toolErr = err
+				return nil, troubleshootDiagnoseOutput{}, err
+			}
+			opts = troubleshoot.DiagnoseOptions{ProjectPath: validPath, TenantID: tenantID, Branch: args.Branch}
+		}
+
+		diagnosis, err := s.troubleshootSvc.DiagnoseWithOptions(ctx, args.ErrorMessage, args.ErrorContext, opts)
 		if err != nil {
 			toolErr = fmt.Errorf("troubleshoot diagnose failed: %w", err)
 			return nil, troubleshootDiagnoseOutput{}, toolErr
@@ -1082,11 +1427,21 @@ func (s *Server) registerTroubleshootTools() {
 			Recommendations: diagnosis.Recommendations,
 			RelatedPatterns: diagnosis.RelatedPatterns,
 			Confidence:      diagnosis.Confidence,
+			Warnings:        diagnosis.Warnings,
+			CodeSnippets:    diagnosis.CodeSnippets,
+		}
+
+		text := fmt.Sprintf("Diagnosis complete (confidence: %.2f): %s", output.Confidence, output.RootCause)
+		if len(output.CodeSnippets) > 0 {
+			text += fmt.Sprintf("\nGrounded in %d code snippet(s) from the indexed repository", len(output.CodeSnippets))
+		}
+		for _, w := range output.Warnings {
+			text += "\nWarning: " + w
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Diagnosis complete (confidence: %.2f): %s", output.Confidence, output.RootCause)},
+				&mcp.TextContent{Text: text},
 			},
 		}, output, nil
 	})
@@ -1095,25 +1450,44 @@ func (s *Server) registerTroubleshootTools() {
 // ===== MEMORY TOOLS (ReasoningBank) =====
 
 type memorySearchInput struct {
+	responseFormat
+	tokenBudget
+
 	ProjectID string `json:"project_id" jsonschema:"required,Project identifier"`
 	Query     string `json:"query" jsonschema:"required,Search query for relevant memories"`
 	Limit     int    `json:"limit,omitempty" jsonschema:"Maximum results (default: 5)"`
+	Cursor    string `json:"cursor,omitempty" jsonschema:"Cursor from a previous next_cursor to fetch the next page"`
+	Decompose bool   `json:"decompose,omitempty" jsonschema:"Split a multi-part task (e.g. 'add OAuth and write integration tests') into sub-queries, search them in parallel and fuse the results"`
+
+	Languages []string `json:"languages,omitempty" jsonschema:"Only return memories about these languages or stacks (e.g. python, terraform) plus language-agnostic ones. Without it, languages named in the query are preferred"`
+	Filter    string   `json:"filter,omitempty" jsonschema:"Metadata filter expression, e.g. confidence >= 0.7 AND outcome IN (success, partial) AND created_at > 2025-06-01. Supports AND, OR, NOT, parentheses, =, !=, <, <=, >, >= and IN"`
+	SessionID string   `json:"session_id,omitempty" jsonschema:"Session making the search. When a search experiment runs, assigns the session to a ranking arm; pass the same session_id to memory_outcome"`
+	Inject    bool     `json:"inject,omitempty" jsonschema:"Also return the results as a prompt-ready block in injection: guidance first, then memories by confidence with anti-patterns marked AVOID, fitted to max_tokens"`
 }
 
 type memorySearchOutput struct {
-	Memories []map[string]interface{} `json:"memories" jsonschema:"Matching memories"`
-	Count    int                      `json:"count" jsonschema:"Number of results"`
-	Metadata map[string]interface{}   `json:"metadata,omitempty" jsonschema:"Search metadata for iterative refinement"`
+	Memories   []map[string]interface{} `json:"memories" jsonschema:"Matching memories"`
+	Count      int                      `json:"count" jsonschema:"Number of results"`
+	Metadata   map[string]interface{}   `json:"metadata,omitempty" jsonschema:"Search metadata for iterative refinement"`
+	NextCursor string                   `json:"next_cursor,omitempty" jsonschema:"Cursor for the next page (empty when there are no more results)"`
+	SubQueries []string                 `json:"sub_queries,omitempty" jsonschema:"Queries searched when decompose is set"`
+	Injection  string                   `json:"injection,omitempty" jsonschema:"Prompt-ready block of the results, set when inject is given"`
+	budgetUsage
 }
 
 type memoryRecordInput struct {
+	responseFormat
+	idempotencyKey
+
 	ProjectID   string   `json:"project_id" jsonschema:"required,Project identifier"`
 	Title       string   `json:"title" jsonschema:"required,Brief title for the memory"`
 	Content     string   `json:"content" jsonschema:"required,The strategy or learning to remember"`
 	Outcome     string   `json:"outcome" jsonschema:"required,Outcome type (success or failure)"`
 	Tags        []string `json:"tags,omitempty" jsonschema:"Tags for categorization"`
+	Languages   []string `json:"languages,omitempty" jsonschema:"Languages or stacks the memory is about (e.g. go, terraform). Inferred from tags and content when omitted"`
 	SessionID   string   `json:"session_id,omitempty" jsonschema:"Session ID for session-level buffering (when granularity=session)"`
 	SessionDate string   `json:"session_date,omitempty" jsonschema:"Session date in RFC3339 format (optional, defaults to now)"`
+	Visibility  string   `json:"visibility,omitempty" jsonschema:"Who can find the memory: private (only you), team (your team) or org (everyone in the project, default)"`
 }
 
 type memoryRecordOutput struct {
@@ -1124,8 +1498,14 @@ type memoryRecordOutput struct {
 }
 
 type memoryFeedbackInput struct {
-	MemoryID string `json:"memory_id" jsonschema:"required,Memory ID to provide feedback on"`
-	Helpful  bool   `json:"helpful" jsonschema:"required,Whether the memory was helpful"`
+	responseFormat
+	idempotencyKey
+
+	MemoryID         string `json:"memory_id" jsonschema:"required,Memory ID to provide feedback on"`
+	Helpful          bool   `json:"helpful" jsonschema:"required,Whether the memory was helpful"`
+	SessionID        string `json:"session_id,omitempty" jsonschema:"Optional session ID for correlation"`
+	Evidence         string `json:"evidence,omitempty" jsonschema:"What you actually did with the memory and what happened (e.g. the fix applied and whether tests passed). Evidence-backed feedback weighs more and is kept in the memory's confidence history"`
+	ExpectedRevision int64  `json:"expected_revision,omitempty" jsonschema:"Only apply if the memory is still at this revision (from a search result); fails with a conflict otherwise"`
 }
 
 type memoryFeedbackOutput struct {
@@ -1135,9 +1515,13 @@ type memoryFeedbackOutput struct {
 }
 
 type memoryOutcomeInput struct {
-	MemoryID  string `json:"memory_id" jsonschema:"required,ID of the memory that was used"`
-	Succeeded bool   `json:"succeeded" jsonschema:"required,Whether the task succeeded after using this memory"`
-	SessionID string `json:"session_id,omitempty" jsonschema:"Optional session ID for correlation"`
+	responseFormat
+	idempotencyKey
+
+	MemoryID         string `json:"memory_id" jsonschema:"required,ID of the memory that was used"`
+	Succeeded        bool   `json:"succeeded" jsonschema:"required,Whether the task succeeded after using this memory"`
+	SessionID        string `json:"session_id,omitempty" jsonschema:"Optional session ID for correlation"`
+	ExpectedRevision int64  `json:"expected_revision,omitempty" jsonschema:"Only apply if the memory is still at this revision (from a search result); fails with a conflict otherwise"`
 }
 
 type memoryOutcomeOutput struct {
@@ -1147,10 +1531,16 @@ type memoryOutcomeOutput struct {
 }
 
 type memoryConsolidateInput struct {
-	ProjectID           string  `json:"project_id" jsonschema:"required,Project identifier"`
-	SimilarityThreshold float64 `json:"similarity_threshold,omitempty" jsonschema:"Minimum similarity score for consolidation (0-1 default 0.8)"`
-	DryRun              bool    `json:"dry_run,omitempty" jsonschema:"Preview consolidation without making changes (default false)"`
-	MaxClusters         int     `json:"max_clusters,omitempty" jsonschema:"Maximum number of clusters to consolidate in one run (0 = no limit)"`
+	responseFormat
+
+	ProjectID           string   `json:"project_id" jsonschema:"required,Project identifier"`
+	SimilarityThreshold float64  `json:"similarity_threshold,omitempty" jsonschema:"Minimum similarity score for consolidation (0-1 default 0.8)"`
+	DryRun              bool     `json:"dry_run,omitempty" jsonschema:"Preview consolidation without making changes (default false)"`
+	MaxClusters         int      `json:"max_clusters,omitempty" jsonschema:"Maximum number of clusters to consolidate in one run (0 = no limit)"`
+	Tags                []string `json:"tags,omitempty" jsonschema:"Only consolidate memories with at least one of these tags"`
+	Outcome             string   `json:"outcome,omitempty" jsonschema:"Only consolidate memories with this outcome: success or failure"`
+	Query               string   `json:"query,omitempty" jsonschema:"Only consolidate memories relevant to this query (e.g. 'testing')"`
+	RequireApproval     bool     `json:"require_approval,omitempty" jsonschema:"Save clusters as pending proposals for memory_consolidation_review instead of merging (default false)"`
 }
 
 type memoryConsolidateOutput struct {
@@ -1159,11 +1549,39 @@ type memoryConsolidateOutput struct {
 	SkippedCount     int      `json:"skipped_count" jsonschema:"Number of memories skipped (below threshold)"`
 	TotalProcessed   int      `json:"total_processed" jsonschema:"Total number of memories examined"`
 	DurationSeconds  float64  `json:"duration_seconds" jsonschema:"Time taken for consolidation operation"`
+	ProposalIDs      []string `json:"proposal_ids,omitempty" jsonschema:"IDs of proposals awaiting review (require_approval only)"`
+	Warnings         []string `json:"warnings,omitempty" jsonschema:"Why consolidation stopped early, e.g. paused because the LLM budget is exhausted"`
+}
+
+type memoryConsolidationReviewInput struct {
+	responseFormat
+
+	ProjectID  string `json:"project_id" jsonschema:"required,Project identifier"`
+	Action     string `json:"action" jsonschema:"required,One of: list, approve, reject"`
+	ProposalID string `json:"proposal_id,omitempty" jsonschema:"Proposal to approve or reject (required for approve and reject)"`
+	Status     string `json:"status,omitempty" jsonschema:"Status filter for list: pending (default), approved, rejected, merged, expired, or all"`
+}
+
+type consolidationProposalSummary struct {
+	ID                string   `json:"id" jsonschema:"Proposal ID"`
+	Status            string   `json:"status" jsonschema:"pending, approved, rejected, merged or expired"`
+	MemberIDs         []string `json:"member_ids" jsonschema:"Memories that would be merged"`
+	MemberTitles      []string `json:"member_titles" jsonschema:"Titles of the memories that would be merged"`
+	AverageSimilarity float64  `json:"average_similarity" jsonschema:"Mean pairwise similarity of members"`
+	DraftTitle        string   `json:"draft_title,omitempty" jsonschema:"Title of the extractive draft"`
+	DraftContent      string   `json:"draft_content,omitempty" jsonschema:"Content of the extractive draft"`
+	ConsolidatedID    string   `json:"consolidated_id,omitempty" jsonschema:"Merged memory ID once merged"`
+	ExpiresAt         string   `json:"expires_at" jsonschema:"When a pending proposal expires (RFC3339)"`
+}
+
+type memoryConsolidationReviewOutput struct {
+	Proposals []consolidationProposalSummary `json:"proposals" jsonschema:"Listed proposals, or the reviewed proposal"`
+	Count     int                            `json:"count" jsonschema:"Number of proposals returned"`
 }
 
 func (s *Server) registerMemoryTools() {
 	// memory_search
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "memory_search",
 		Description: "Search for relevant memories/strategies from past sessions",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args memorySearchInput) (*mcp.CallToolResult, memorySearchOutput, error) {
@@ -1179,6 +1597,10 @@ func (s *Server) registerMemoryTools() {
 			toolErr = fmt.Errorf("invalid project_id: %w", err)
 			return nil, memorySearchOutput{}, toolErr
 		}
+		if err := args.tokenBudget.validate(); err != nil {
+			toolErr = err
+			return nil, memorySearchOutput{}, toolErr
+		}
 
 		limit := args.Limit
 		if limit <= 0 {
@@ -1192,25 +1614,72 @@ func (s *Server) registerMemoryTools() {
 			toolErr = fmt.Errorf("failed to set tenant context: %w", err)
 			return nil, memorySearchOutput{}, toolErr
 		}
+		ctx = reasoningbank.ContextWithLanguages(ctx, args.Languages...)
+		ctx = reasoningbank.ContextWithSession(ctx, args.SessionID)
+		if args.Filter != "" {
+			filter, err := vectorstore.ParseFilterExpr(args.Filter)
+			if err != nil {
+				toolErr = fmt.Errorf("invalid filter: %w", err)
+				return nil, memorySearchOutput{}, toolErr
+			}
+			ctx = reasoningbank.ContextWithFilter(ctx, filter)
+		}
 
-		scoredMemories, metadata, err := s.reasoningbankSvc.SearchWithMetadata(ctx, args.ProjectID, args.Query, limit)
+		var page *reasoningbank.MemoryPage
+		if args.Decompose {
+			page, err = s.reasoningbankSvc.SearchPageMultiQuery(ctx, args.ProjectID, args.Query, limit, args.Cursor)
+		} else {
+			page, err = s.reasoningbankSvc.SearchPage(ctx, args.ProjectID, args.Query, limit, args.Cursor)
+		}
 		if err != nil {
 			toolErr = fmt.Errorf("memory search failed: %w", err)
 			return nil, memorySearchOutput{}, toolErr
 		}
+		metadata := page.Metadata
+
+		var injection string
+		if args.Inject {
+			injection = s.formatInjection(page.Memories, args.MaxTokens)
+		}
 
-		results := make([]map[string]interface{}, 0, len(scoredMemories))
-		for _, sm := range scoredMemories {
-			results = append(results, map[string]interface{}{
+		results := make([]map[string]interface{}, 0, len(page.Memories))
+		for _, sm := range page.Memories {
+			result := map[string]interface{}{
 				"id":         sm.Memory.ID,
 				"title":      sm.Memory.Title,
 				"content":    s.scrubber.Scrub(sm.Memory.Content).Scrubbed,
 				"outcome":    sm.Memory.Outcome,
 				"confidence": sm.Memory.Confidence,
+				"importance": sm.Memory.Importance,
 				"relevance":  sm.Relevance, // Search similarity score (0.0-1.0)
 				"tags":       sm.Memory.Tags,
-			})
+				"revision":   sm.Memory.Revision,
+			}
+			if len(sm.Memory.Languages) > 0 {
+				result["languages"] = sm.Memory.Languages
+			}
+			// The conversation message that recorded the memory
+			if o := sm.Memory.Origin; o != nil {
+				result["origin"] = map[string]interface{}{
+					"session_id":   o.SessionID,
+					"message_uuid": o.MessageUUID,
+					"timestamp":    o.Timestamp.Unix(),
+					"excerpt":      s.scrubber.Scrub(o.Excerpt).Scrubbed,
+				}
+			}
+			// Long memories report the chunk that matched best
+			if sm.Highlight != nil {
+				result["highlight"] = map[string]interface{}{
+					"text":  s.scrubber.Scrub(sm.Highlight.Text).Scrubbed,
+					"start": sm.Highlight.Start,
+					"end":   sm.Highlight.End,
+				}
+			}
+			results = append(results, result)
 		}
+		// The best-matching chunk is the key excerpt under a token budget
+		results, usage := fitResultsToBudget(results, []string{"highlight.text", "content"}, args.MaxTokens)
+		nextCursor := budgetCursor(args.Cursor, page.NextCursor, reasoningbank.CursorHash(ctx, args.ProjectID, args.Query, args.Decompose), len(page.Memories), len(results))
 
 		// Convert metadata to map for output
 		metadataMap := map[string]interface{}{
@@ -1220,9 +1689,13 @@ func (s *Server) registerMemoryTools() {
 		}
 
 		output := memorySearchOutput{
-			Memories: results,
-			Count:    len(results),
-			Metadata: metadataMap,
+			Memories:    results,
+			Count:       len(results),
+			Metadata:    metadataMap,
+			NextCursor:  nextCursor,
+			SubQueries:  page.SubQueries,
+			Injection:   injection,
+			budgetUsage: usage,
 		}
 
 		return &mcp.CallToolResult{
@@ -1233,13 +1706,18 @@ func (s *Server) registerMemoryTools() {
 	})
 
 	// memory_record
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "memory_record",
 		Description: "Record a new memory/learning from the current session",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args memoryRecordInput) (*mcp.CallToolResult, memoryRecordOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "memory_record", &toolErr)()
 
+		if err := s.readOnly.Check("memory_record"); err != nil {
+			toolErr = err
+			return nil, memoryRecordOutput{}, toolErr
+		}
+
 		// Validate project_id (CWE-287 authentication bypass protection)
 		if args.ProjectID == "" {
 			toolErr = fmt.Errorf("project_id is required (typically your repository name, e.g., 'my-app')")
@@ -1260,6 +1738,15 @@ func (s *Server) registerMemoryTools() {
 			toolErr = fmt.Errorf("invalid memory: %w", err)
 			return nil, memoryRecordOutput{}, toolErr
 		}
+		memory.Languages = args.Languages
+		visibility, err := vectorstore.ParseVisibility(args.Visibility)
+		if err != nil {
+			toolErr = err
+			return nil, memoryRecordOutput{}, toolErr
+		}
+		if args.Visibility != "" {
+			memory.Visibility = visibility
+		}
 
 		// Set optional session fields for session-level buffering
 		if args.SessionID != "" {
@@ -1278,6 +1765,10 @@ func (s *Server) registerMemoryTools() {
 			return nil, memoryRecordOutput{}, toolErr
 		}
 
+		if err := s.reasoningbankSvc.CheckQuality(ctx, memory); err != nil {
+			toolErr = err
+			return nil, memoryRecordOutput{}, toolErr
+		}
 		if err := s.reasoningbankSvc.Record(ctx, memory); err != nil {
 			toolErr = fmt.Errorf("memory record failed: %w", err)
 			return nil, memoryRecordOutput{}, toolErr
@@ -1292,20 +1783,26 @@ func (s *Server) registerMemoryTools() {
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Memory recorded: %s (confidence: %.2f)", output.Title, output.Confidence)},
+				&mcp.TextContent{Text: fmt.Sprintf("Memory recorded: %s (id: %s, confidence: %.2f)", output.Title, output.ID, output.Confidence)},
 			},
 		}, output, nil
 	})
 
 	// memory_feedback
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "memory_feedback",
 		Description: "Provide feedback on a memory to adjust its confidence",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args memoryFeedbackInput) (*mcp.CallToolResult, memoryFeedbackOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "memory_feedback", &toolErr)()
 
-		if err := s.reasoningbankSvc.Feedback(ctx, args.MemoryID, args.Helpful); err != nil {
+		if err := s.readOnly.Check("memory_feedback"); err != nil {
+			toolErr = err
+			return nil, memoryFeedbackOutput{}, toolErr
+		}
+
+		ctx = reasoningbank.ContextWithExpectedRevision(ctx, args.ExpectedRevision)
+		if err := s.reasoningbankSvc.FeedbackWithEvidence(ctx, args.MemoryID, args.Helpful, args.Evidence); err != nil {
 			toolErr = fmt.Errorf("memory feedback failed: %w", err)
 			return nil, memoryFeedbackOutput{}, toolErr
 		}
@@ -1331,14 +1828,20 @@ func (s *Server) registerMemoryTools() {
 	})
 
 	// memory_outcome
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "memory_outcome",
 		Description: "Report whether a task succeeded after using a memory. Call this after completing a task that used a retrieved memory to help the system learn which memories are actually useful.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args memoryOutcomeInput) (*mcp.CallToolResult, memoryOutcomeOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "memory_outcome", &toolErr)()
 
+		if err := s.readOnly.Check("memory_outcome"); err != nil {
+			toolErr = err
+			return nil, memoryOutcomeOutput{}, toolErr
+		}
+
 		// Record the outcome signal
+		ctx = reasoningbank.ContextWithExpectedRevision(ctx, args.ExpectedRevision)
 		newConfidence, err := s.reasoningbankSvc.RecordOutcome(ctx, args.MemoryID, args.Succeeded, args.SessionID)
 		if err != nil {
 			toolErr = fmt.Errorf("memory outcome failed: %w", err)
@@ -1359,13 +1862,18 @@ func (s *Server) registerMemoryTools() {
 	})
 
 	// memory_consolidate
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "memory_consolidate",
-		Description: "Consolidate similar memories to reduce redundancy and improve knowledge quality. Merges memories with similarity above threshold into synthesized consolidated memories.",
+		Description: "Consolidate similar memories to reduce redundancy and improve knowledge quality. Merges memories with similarity above threshold into synthesized consolidated memories. Use tags, outcome, or query to consolidate only a subset of memories.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args memoryConsolidateInput) (*mcp.CallToolResult, memoryConsolidateOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "memory_consolidate", &toolErr)()
 
+		if err := s.readOnly.Check("memory_consolidate"); err != nil {
+			toolErr = err
+			return nil, memoryConsolidateOutput{}, toolErr
+		}
+
 		// Validate project_id (CWE-287 authentication bypass protection)
 		if args.ProjectID == "" {
 			toolErr = fmt.Errorf("project_id is required (typically your repository name, e.g., 'my-app')")
@@ -1393,6 +1901,10 @@ func (s *Server) registerMemoryTools() {
 			SimilarityThreshold: threshold,
 			DryRun:              args.DryRun,
 			MaxClustersPerRun:   args.MaxClusters,
+			Tags:                args.Tags,
+			Outcome:             reasoningbank.Outcome(args.Outcome),
+			Query:               args.Query,
+			RequireApproval:     args.RequireApproval,
 		}
 
 		// Execute consolidation
@@ -1412,6 +1924,8 @@ func (s *Server) registerMemoryTools() {
 			SkippedCount:     result.SkippedCount,
 			TotalProcessed:   result.TotalProcessed,
 			DurationSeconds:  durationSeconds,
+			ProposalIDs:      result.ProposalIDs,
+			Warnings:         result.Warnings,
 		}
 
 		// Build result message
@@ -1425,6 +1939,12 @@ func (s *Server) registerMemoryTools() {
 		if args.DryRun {
 			resultMsg = "[DRY RUN] " + resultMsg
 		}
+		if len(output.ProposalIDs) > 0 {
+			resultMsg += fmt.Sprintf("; %d proposals awaiting memory_consolidation_review", len(output.ProposalIDs))
+		}
+		for _, w := range output.Warnings {
+			resultMsg += "\nWarning: " + w
+		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1433,8 +1953,98 @@ func (s *Server) registerMemoryTools() {
 		}, output, nil
 	})
 
+	// memory_consolidation_review
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "memory_consolidation_review",
+		Description: "List, approve, or reject consolidation proposals created by memory_consolidate with require_approval. Only approved proposals are merged; pending proposals expire after a week.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args memoryConsolidationReviewInput) (*mcp.CallToolResult, memoryConsolidationReviewOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "memory_consolidation_review", &toolErr)()
+
+		if args.ProjectID == "" {
+			toolErr = fmt.Errorf("project_id is required (typically your repository name, e.g., 'my-app')")
+			return nil, memoryConsolidationReviewOutput{}, toolErr
+		}
+		if err := sanitize.ValidateProjectID(args.ProjectID); err != nil {
+			toolErr = fmt.Errorf("invalid project_id: %w", err)
+			return nil, memoryConsolidationReviewOutput{}, toolErr
+		}
+		if s.distiller == nil {
+			toolErr = fmt.Errorf("memory consolidation not available: distiller not configured")
+			return nil, memoryConsolidationReviewOutput{}, toolErr
+		}
+
+		var proposals []reasoningbank.ConsolidationProposal
+		switch args.Action {
+		case "list":
+			status := reasoningbank.ProposalStatus(args.Status)
+			switch args.Status {
+			case "":
+				status = reasoningbank.ProposalPending
+			case "all":
+				status = ""
+			}
+			list, err := s.distiller.ListProposals(ctx, args.ProjectID, status)
+			if err != nil {
+				toolErr = fmt.Errorf("listing proposals failed: %w", err)
+				return nil, memoryConsolidationReviewOutput{}, toolErr
+			}
+			proposals = list
+		case "approve", "reject":
+			if err := s.readOnly.Check("memory_consolidation_review " + args.Action); err != nil {
+				toolErr = err
+				return nil, memoryConsolidationReviewOutput{}, toolErr
+			}
+			if args.ProposalID == "" {
+				toolErr = fmt.Errorf("proposal_id is required for %s", args.Action)
+				return nil, memoryConsolidationReviewOutput{}, toolErr
+			}
+			p, err := s.distiller.ReviewProposal(ctx, args.ProjectID, args.ProposalID, args.Action == "approve")
+			if err != nil {
+				toolErr = fmt.Errorf("review failed: %w", err)
+				return nil, memoryConsolidationReviewOutput{}, toolErr
+			}
+			proposals = []reasoningbank.ConsolidationProposal{*p}
+		default:
+			toolErr = fmt.Errorf("action must be one of: list, approve, reject")
+			return nil, memoryConsolidationReviewOutput{}, toolErr
+		}
+
+		output := memoryConsolidationReviewOutput{
+			Proposals: make([]consolidationProposalSummary, 0, len(proposals)),
+			Count:     len(proposals),
+		}
+		for _, p := range proposals {
+			summary := consolidationProposalSummary{
+				ID:                p.ID,
+				Status:            string(p.Status),
+				MemberIDs:         p.MemberIDs,
+				MemberTitles:      p.MemberTitles,
+				AverageSimilarity: p.AverageSimilarity,
+				ConsolidatedID:    p.ConsolidatedID,
+				ExpiresAt:         p.ExpiresAt.Format(time.RFC3339),
+			}
+			if p.Draft != nil {
+				summary.DraftTitle = p.Draft.Title
+				summary.DraftContent = s.scrubber.Scrub(p.Draft.Content).Scrubbed
+			}
+			output.Proposals = append(output.Proposals, summary)
+		}
+
+		msg := fmt.Sprintf("%d proposals", output.Count)
+		if args.Action != "list" && len(proposals) == 1 {
+			msg = fmt.Sprintf("Proposal %s is now %s", proposals[0].ID, proposals[0].Status)
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: msg},
+			},
+		}, output, nil
+	})
+
 	// memory_consolidate_session
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "memory_consolidate_session",
 		Description: "Flush and summarize a session's buffered turns into session-level memories. Only effective when granularity is set to 'session'.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
@@ -1454,6 +2064,11 @@ func (s *Server) registerMemoryTools() {
 			Message   string   `json:"message"`
 		}
 
+		if err := s.readOnly.Check("memory_consolidate_session"); err != nil {
+			toolErr = err
+			return nil, output{}, toolErr
+		}
+
 		if args.ProjectID == "" {
 			toolErr = fmt.Errorf("project_id is required (typically your repository name, e.g., 'my-app')")
 			return nil, output{}, toolErr
@@ -1501,6 +2116,8 @@ func (s *Server) registerMemoryTools() {
 // ===== FOLDING TOOLS (Context-Folding) =====
 
 type branchCreateInput struct {
+	responseFormat
+
 	SessionID      string `json:"session_id" jsonschema:"required,Session identifier"`
 	ProjectID      string `json:"project_id,omitempty" jsonschema:"Project identifier for metrics tracking"`
 	Description    string `json:"description" jsonschema:"required,Brief description of what the branch will do"`
@@ -1516,17 +2133,27 @@ type branchCreateOutput struct {
 }
 
 type branchReturnInput struct {
-	BranchID string `json:"branch_id" jsonschema:"required,Branch ID to return from"`
-	Message  string `json:"message" jsonschema:"Result message/summary from the branch"`
+	responseFormat
+
+	BranchID     string `json:"branch_id" jsonschema:"required,Branch ID to return from"`
+	Message      string `json:"message" jsonschema:"Result message/summary from the branch"`
+	TargetTokens int    `json:"target_tokens,omitempty" jsonschema:"Token target for the returned message; longer results are compressed, then truncated (default: server setting, capped by the parent budget)"`
 }
 
 type branchReturnOutput struct {
-	Success    bool   `json:"success" jsonschema:"Whether return succeeded"`
-	TokensUsed int    `json:"tokens_used" jsonschema:"Tokens consumed by the branch"`
-	Message    string `json:"message" jsonschema:"Scrubbed result message"`
+	Success        bool    `json:"success" jsonschema:"Whether return succeeded"`
+	TokensUsed     int     `json:"tokens_used" jsonschema:"Tokens consumed by the branch"`
+	Message        string  `json:"message" jsonschema:"Scrubbed result message"`
+	OriginalTokens int     `json:"original_tokens" jsonschema:"Tokens in the scrubbed message before fitting to the target"`
+	ResultTokens   int     `json:"result_tokens" jsonschema:"Tokens in the returned message"`
+	Compressed     bool    `json:"compressed,omitempty" jsonschema:"Whether the message was compressed to fit the target"`
+	Truncated      bool    `json:"truncated,omitempty" jsonschema:"Whether the message was truncated to fit the target"`
+	QualityScore   float64 `json:"quality_score,omitempty" jsonschema:"Compression quality score (0.0-1.0)"`
 }
 
 type branchStatusInput struct {
+	responseFormat
+
 	BranchID  string `json:"branch_id,omitempty" jsonschema:"Specific branch ID to check"`
 	SessionID string `json:"session_id,omitempty" jsonschema:"Session ID to get active branch for"`
 }
@@ -1549,7 +2176,7 @@ func (s *Server) registerFoldingTools() {
 	}
 
 	// branch_create - Create a new context branch
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "branch_create",
 		Description: "Create a new context-folding branch. Branches allow isolated sub-tasks with their own token budget, automatically cleaned up on return. Use for complex multi-step operations that need context isolation.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args branchCreateInput) (*mcp.CallToolResult, branchCreateOutput, error) {
@@ -1593,16 +2220,17 @@ func (s *Server) registerFoldingTools() {
 	})
 
 	// branch_return - Return from a branch with results
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "branch_return",
-		Description: "Return from a context-folding branch with results. The message will be scrubbed for secrets before being returned to the parent context. Any child branches will be force-returned first.",
+		Description: "Return from a context-folding branch with results. The message will be scrubbed for secrets before being returned to the parent context, then compressed or truncated to fit target_tokens and the parent's remaining budget. Any child branches will be force-returned first.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args branchReturnInput) (*mcp.CallToolResult, branchReturnOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "branch_return", &toolErr)()
 
 		returnReq := folding.ReturnRequest{
-			BranchID: args.BranchID,
-			Message:  args.Message,
+			BranchID:     args.BranchID,
+			Message:      args.Message,
+			TargetTokens: args.TargetTokens,
 		}
 
 		resp, err := s.foldingSvc.Return(ctx, returnReq)
@@ -1612,20 +2240,30 @@ func (s *Server) registerFoldingTools() {
 		}
 
 		output := branchReturnOutput{
-			Success:    resp.Success,
-			TokensUsed: resp.TokensUsed,
-			Message:    resp.ScrubbedMsg,
+			Success:        resp.Success,
+			TokensUsed:     resp.TokensUsed,
+			Message:        resp.ScrubbedMsg,
+			OriginalTokens: resp.OriginalTokens,
+			ResultTokens:   resp.ResultTokens,
+			Compressed:     resp.Compressed,
+			Truncated:      resp.Truncated,
+			QualityScore:   resp.QualityScore,
+		}
+
+		text := fmt.Sprintf("Branch returned successfully (tokens used: %d)", output.TokensUsed)
+		if output.Compressed || output.Truncated {
+			text += fmt.Sprintf("; result fitted from %d to %d tokens", output.OriginalTokens, output.ResultTokens)
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Branch returned successfully (tokens used: %d)", output.TokensUsed)},
+				&mcp.TextContent{Text: text},
 			},
 		}, output, nil
 	})
 
 	// branch_status - Get branch status
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "branch_status",
 		Description: "Get the status of a specific branch or the active branch for a session. Returns branch state, budget usage, and depth information.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args branchStatusInput) (*mcp.CallToolResult, branchStatusOutput, error) {
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
+)
+
+// ===== CAPABILITY TOOLS =====
+
+type contextdCapabilitiesInput struct {
+	ProtocolVersion int `json:"protocol_version,omitempty" jsonschema:"Protocol version the client was built against; the response then reports whether the server is compatible"`
+}
+
+// toolCatalog caches the registered tools, which do not change once the
+// server is built.
+type toolCatalog struct {
+	mu    sync.Mutex
+	tools []*mcp.Tool
+}
+
+func (s *Server) registerCapabilityTools() {
+	// contextd_capabilities - Version, features, tool schema versions and deprecations
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "contextd_capabilities",
+		Description: "Describe this contextd server: its version and protocol version, enabled features (vector store, embeddings, LLM providers, auth mode, read-only mode, conversations, folding, consolidation, workflows), the schema version of each tool and deprecation notices. Call it once per session to adapt to older or newer servers; pass protocol_version to check compatibility.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args contextdCapabilitiesInput) (*mcp.CallToolResult, capabilities.Capabilities, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "contextd_capabilities", &toolErr)()
+
+		caps, err := s.Capabilities(ctx)
+		if err != nil {
+			toolErr = fmt.Errorf("describing capabilities: %w", err)
+			return nil, capabilities.Capabilities{}, toolErr
+		}
+		caps.Negotiate(args.ProtocolVersion)
+		return nil, *caps, nil
+	})
+}
+
+// Capabilities describes the server: its configured features with the
+// current read-only state, the contract of every tool and the deprecation
+// notices of its tool aliases.
+func (s *Server) Capabilities(ctx context.Context) (*capabilities.Capabilities, error) {
+	tools, err := s.ToolContracts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	features := s.features
+	features.ReadOnly = s.readOnly != nil && s.readOnly.Enabled()
+	return capabilities.New(s.version, features, tools, s.ToolDeprecations()...), nil
+}
+
+// ToolContracts returns the schema version and hash of every registered
+// tool.
+func (s *Server) ToolContracts(ctx context.Context) ([]capabilities.Tool, error) {
+	tools, err := s.registeredTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contracts := make([]capabilities.Tool, len(tools))
+	for i, tool := range tools {
+		contracts[i] = capabilities.ToolContract(tool.Name, tool.InputSchema)
+	}
+	return contracts, nil
+}
+
+// registeredTools returns the registered tools as clients see them, listing
+// them on first use. A failed listing is retried on the next call.
+func (s *Server) registeredTools(ctx context.Context) ([]*mcp.Tool, error) {
+	s.catalog.mu.Lock()
+	defer s.catalog.mu.Unlock()
+	if s.catalog.tools != nil {
+		return s.catalog.tools, nil
+	}
+	tools, err := s.listTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.catalog.tools = tools
+	return tools, nil
+}
+
+// listTools lists the registered tools through an in-memory session.
+func (s *Server) listTools(ctx context.Context) ([]*mcp.Tool, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to server: %w", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "contextd-catalog", Version: s.version}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting client: %w", err)
+	}
+	defer session.Close()
+
+	tools := []*mcp.Tool{}
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("listing tools: %w", err)
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
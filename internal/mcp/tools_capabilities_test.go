@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/capabilities"
+)
+
+func TestContextdCapabilities(t *testing.T) {
+	server, _ := setupFoldingTestServer(t)
+	defer server.Close()
+	server.features = capabilities.Features{VectorStore: "chromem", Folding: true}
+	ctx := context.Background()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.mcp.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "contextd_capabilities",
+		Arguments: map[string]any{"protocol_version": capabilities.ProtocolVersion},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+
+	data, err := json.Marshal(res.StructuredContent)
+	require.NoError(t, err)
+	var caps capabilities.Capabilities
+	require.NoError(t, json.Unmarshal(data, &caps))
+
+	assert.Equal(t, "1.0.0", caps.Version)
+	assert.Equal(t, "chromem", caps.Features.VectorStore)
+	assert.True(t, caps.Features.Folding)
+	require.NotNil(t, caps.Compatibility)
+	assert.True(t, caps.Compatibility.Compatible)
+
+	// Every registered tool is listed with its contract
+	listed, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, caps.Tools, len(listed.Tools))
+	byName := make(map[string]capabilities.Tool, len(caps.Tools))
+	for _, tool := range caps.Tools {
+		byName[tool.Name] = tool
+	}
+	for _, tool := range listed.Tools {
+		contract, ok := byName[tool.Name]
+		require.True(t, ok, "tool %s missing from capabilities", tool.Name)
+		assert.Equal(t, capabilities.SchemaVersion(tool.Name), contract.SchemaVersion)
+		assert.NotEmpty(t, contract.SchemaHash)
+	}
+
+	// Legacy aliases are listed as deprecated tools with a notice
+	assert.True(t, byName["memory_store"].Deprecated)
+	assert.False(t, byName["memory_record"].Deprecated)
+	names := make([]string, len(caps.Deprecations))
+	for i, notice := range caps.Deprecations {
+		names[i] = notice.Name
+	}
+	assert.Contains(t, names, "checkpoint_restore")
+}
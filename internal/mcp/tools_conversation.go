@@ -14,6 +14,8 @@ import (
 // ===== CONVERSATION TOOLS =====
 
 type conversationIndexInput struct {
+	responseFormat
+
 	ProjectPath string   `json:"project_path" jsonschema:"required,Path to project to index conversations for"`
 	TenantID    string   `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
 	SessionIDs  []string `json:"session_ids,omitempty" jsonschema:"Specific session IDs to index (empty = all)"`
@@ -26,25 +28,30 @@ type conversationIndexOutput struct {
 	MessagesIndexed    int      `json:"messages_indexed" jsonschema:"Number of messages indexed"`
 	DecisionsExtracted int      `json:"decisions_extracted" jsonschema:"Number of decisions extracted"`
 	FilesReferenced    []string `json:"files_referenced" jsonschema:"Files referenced in conversations"`
+	ArtifactsLinked    int      `json:"artifacts_linked" jsonschema:"Number of memories and checkpoints linked to the messages that created them"`
 	ErrorCount         int      `json:"error_count" jsonschema:"Number of errors during indexing"`
 }
 
 type conversationSearchInput struct {
+	responseFormat
+
 	Query       string   `json:"query" jsonschema:"required,Semantic search query"`
 	ProjectPath string   `json:"project_path" jsonschema:"required,Project path to search within"`
 	TenantID    string   `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
 	Types       []string `json:"types,omitempty" jsonschema:"Filter by document types: 'message', 'decision', or 'summary'"`
 	Tags        []string `json:"tags,omitempty" jsonschema:"Filter by tags"`
 	FilePath    string   `json:"file_path,omitempty" jsonschema:"Filter by file path discussed"`
-	Domain      string   `json:"domain,omitempty" jsonschema:"Filter by domain (e.g., 'kubernetes', 'frontend', 'database')"`
+	Domain      string   `json:"domain,omitempty" jsonschema:"Filter by domain assigned at index time (default vocabulary: frontend, infra, database, auth)"`
 	Limit       int      `json:"limit,omitempty" jsonschema:"Maximum results to return (default: 10)"`
+	Cursor      string   `json:"cursor,omitempty" jsonschema:"Cursor from a previous next_cursor to fetch the next page"`
 }
 
 type conversationSearchOutput struct {
-	Query   string                   `json:"query" jsonschema:"Search query used"`
-	Results []map[string]interface{} `json:"results" jsonschema:"Search results with score and content"`
-	Total   int                      `json:"total" jsonschema:"Total number of results"`
-	TookMs  int64                    `json:"took_ms" jsonschema:"Search duration in milliseconds"`
+	Query      string                   `json:"query" jsonschema:"Search query used"`
+	Results    []map[string]interface{} `json:"results" jsonschema:"Search results with score and content"`
+	Total      int                      `json:"total" jsonschema:"Total number of results"`
+	TookMs     int64                    `json:"took_ms" jsonschema:"Search duration in milliseconds"`
+	NextCursor string                   `json:"next_cursor,omitempty" jsonschema:"Cursor for the next page (empty when there are no more results)"`
 }
 
 func (s *Server) registerConversationTools() {
@@ -54,13 +61,18 @@ func (s *Server) registerConversationTools() {
 	}
 
 	// conversation_index
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "conversation_index",
 		Description: "Index Claude Code conversation files for a project. Parses JSONL files, extracts messages and decisions, and stores them for semantic search. Note: LLM-based decision extraction (enable_llm) is not yet implemented - currently uses heuristic pattern matching only.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args conversationIndexInput) (*mcp.CallToolResult, conversationIndexOutput, error) {
 		var toolErr error
 		defer s.startMetrics(ctx, "conversation_index", &toolErr)()
 
+		if err := s.readOnly.Check("conversation_index"); err != nil {
+			toolErr = err
+			return nil, conversationIndexOutput{}, toolErr
+		}
+
 		// Validate project_path (CWE-22 path traversal protection)
 		if args.ProjectPath == "" {
 			toolErr = fmt.Errorf("project_path is required")
@@ -111,21 +123,22 @@ func (s *Server) registerConversationTools() {
 			MessagesIndexed:    result.MessagesIndexed,
 			DecisionsExtracted: result.DecisionsExtracted,
 			FilesReferenced:    result.FilesReferenced,
+			ArtifactsLinked:    result.ArtifactsLinked,
 			ErrorCount:         len(result.Errors),
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: fmt.Sprintf(
-					"Indexed %d sessions, %d messages, %d decisions. %d files referenced.",
-					output.SessionsIndexed, output.MessagesIndexed, output.DecisionsExtracted, len(output.FilesReferenced),
+					"Indexed %d sessions, %d messages, %d decisions. %d files referenced, %d memories and checkpoints linked.",
+					output.SessionsIndexed, output.MessagesIndexed, output.DecisionsExtracted, len(output.FilesReferenced), output.ArtifactsLinked,
 				)},
 			},
 		}, output, nil
 	})
 
 	// conversation_search
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "conversation_search",
 		Description: "Search indexed Claude Code conversations for relevant past context, decisions, and patterns.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args conversationSearchInput) (*mcp.CallToolResult, conversationSearchOutput, error) {
@@ -182,6 +195,7 @@ func (s *Server) registerConversationTools() {
 			FilePath:    validFilePath,
 			Domain:      args.Domain,
 			Limit:       args.Limit,
+			Cursor:      args.Cursor,
 		}
 
 		result, err := s.conversationSvc.Search(ctx, opts)
@@ -213,14 +227,18 @@ func (s *Server) registerConversationTools() {
 			if hit.Document.Domain != "" {
 				r["domain"] = hit.Document.Domain
 			}
+			if len(hit.Document.Artifacts) > 0 {
+				r["artifacts"] = hit.Document.Artifacts
+			}
 			results = append(results, r)
 		}
 
 		output := conversationSearchOutput{
-			Query:   result.Query,
-			Results: results,
-			Total:   result.Total,
-			TookMs:  result.Took.Milliseconds(),
+			Query:      result.Query,
+			Results:    results,
+			Total:      result.Total,
+			TookMs:     result.Took.Milliseconds(),
+			NextCursor: result.NextCursor,
 		}
 
 		return &mcp.CallToolResult{
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/handoff"
+)
+
+// ===== HANDOFF TOOLS =====
+
+type handoffExportInput struct {
+	responseFormat
+
+	CheckpointID     string   `json:"checkpoint_id" jsonschema:"required,Checkpoint the recipient resumes from"`
+	ProjectPath      string   `json:"project_path" jsonschema:"required,Project path the checkpoint was saved under"`
+	TenantID         string   `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
+	To               string   `json:"to" jsonschema:"required,User or agent the handoff is addressed to"`
+	From             string   `json:"from,omitempty" jsonschema:"User or agent handing the work off"`
+	Note             string   `json:"note,omitempty" jsonschema:"Message to the recipient"`
+	MemoryIDs        []string `json:"memory_ids,omitempty" jsonschema:"Memories to include besides those the checkpoint's session retrieved"`
+	MemoryLimit      int      `json:"memory_limit,omitempty" jsonschema:"Maximum memories to include (default: 10)"`
+	RemediationLimit int      `json:"remediation_limit,omitempty" jsonschema:"Maximum open remediation drafts to include (default: 5)"`
+}
+
+type handoffImportInput struct {
+	responseFormat
+
+	Bundle      handoff.Bundle `json:"bundle" jsonschema:"required,Bundle returned by handoff_export"`
+	ProjectPath string         `json:"project_path" jsonschema:"required,Project path to import the work into"`
+	TenantID    string         `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
+	SessionID   string         `json:"session_id,omitempty" jsonschema:"Importing session ID; its next checkpoint continues from the imported one"`
+	Recipient   string         `json:"recipient,omitempty" jsonschema:"Reject the bundle unless it is addressed to this recipient"`
+}
+
+func (s *Server) registerHandoffTools() {
+	if s.checkpointSvc == nil {
+		s.logger.Warn("checkpoint service not configured, skipping handoff tools")
+		return
+	}
+
+	svc := handoff.NewService(s.reasoningbankSvc, s.checkpointSvc, s.remediationSvc, s.analytics, s.scrubber, s.logger)
+
+	// handoff_export - Package a checkpoint and its knowledge for someone else
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "handoff_export",
+		Description: "Hand work off to another user or agent. Packages a checkpoint, the memories its session used, open (unconfirmed) remediation drafts and a generated state-of-the-work summary into one JSON bundle. Pass the bundle to handoff_import in the recipient's project.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args handoffExportInput) (*mcp.CallToolResult, handoff.Bundle, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "handoff_export", &toolErr)()
+
+		if args.CheckpointID == "" || args.To == "" {
+			toolErr = fmt.Errorf("checkpoint_id and to are required")
+			return nil, handoff.Bundle{}, toolErr
+		}
+		validPath, tenantID, projectID, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
+		if err != nil {
+			toolErr = err
+			return nil, handoff.Bundle{}, toolErr
+		}
+
+		bundle, err := svc.Export(ctx, handoff.ExportRequest{
+			CheckpointID:     args.CheckpointID,
+			TenantID:         tenantID,
+			ProjectID:        projectID,
+			ProjectPath:      validPath,
+			To:               args.To,
+			From:             args.From,
+			Note:             args.Note,
+			MemoryIDs:        args.MemoryIDs,
+			MemoryLimit:      args.MemoryLimit,
+			RemediationLimit: args.RemediationLimit,
+		})
+		if err != nil {
+			toolErr = fmt.Errorf("handoff export failed: %w", err)
+			return nil, handoff.Bundle{}, toolErr
+		}
+
+		return nil, *bundle, nil
+	})
+
+	// handoff_import - Reconstruct a handed-off bundle in the recipient's project
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "handoff_import",
+		Description: "Import a handoff bundle from handoff_export into your project. Saves its checkpoint (resume it with checkpoint_resume), records its memories and records its remediations as pending drafts. Returns the IDs created; items that fail are listed in warnings.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args handoffImportInput) (*mcp.CallToolResult, handoff.ImportResult, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "handoff_import", &toolErr)()
+
+		if err := s.readOnly.Check("handoff_import"); err != nil {
+			toolErr = err
+			return nil, handoff.ImportResult{}, toolErr
+		}
+		validPath, tenantID, projectID, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
+		if err != nil {
+			toolErr = err
+			return nil, handoff.ImportResult{}, toolErr
+		}
+
+		result, err := svc.Import(ctx, handoff.ImportRequest{
+			Bundle:      &args.Bundle,
+			TenantID:    tenantID,
+			ProjectID:   projectID,
+			ProjectPath: validPath,
+			SessionID:   args.SessionID,
+			Recipient:   args.Recipient,
+		})
+		if err != nil {
+			toolErr = fmt.Errorf("handoff import failed: %w", err)
+			return nil, handoff.ImportResult{}, toolErr
+		}
+
+		return nil, *result, nil
+	})
+}
@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/docs"
+	"github.com/fyrsmithlabs/contextd/internal/help"
+)
+
+// ===== HELP TOOLS =====
+
+type contextdHelpInput struct {
+	responseFormat
+
+	Query string   `json:"query" jsonschema:"required,Question about contextd, e.g. how do I save a checkpoint"`
+	Kinds []string `json:"kinds,omitempty" jsonschema:"Restrict to these kinds: tool, config, capability (default: all)"`
+	Limit int      `json:"limit,omitempty" jsonschema:"Maximum results (default: 5, max: 20)"`
+}
+
+type contextdHelpOutput struct {
+	Query   string        `json:"query" jsonschema:"The question asked"`
+	Version string        `json:"version" jsonschema:"Version of the running contextd the answers describe"`
+	Results []help.Result `json:"results" jsonschema:"Matching tools, settings and capabilities, best first"`
+}
+
+// helpState holds the help index, built on first use because it lists the
+// tools registered after the help tool.
+type helpState struct {
+	mu    sync.Mutex
+	index *help.Index
+}
+
+func (s *Server) registerHelpTools() {
+	// contextd_help - Answer questions about contextd itself
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "contextd_help",
+		Description: "Answer questions about contextd itself: which tool to call and with which parameters, how a setting is configured, and which features this server has enabled. Answers come from the running server's tool schemas and configuration reference, so they match its version.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args contextdHelpInput) (*mcp.CallToolResult, contextdHelpOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "contextd_help", &toolErr)()
+
+		kinds, err := help.ParseKinds(args.Kinds)
+		if err != nil {
+			toolErr = err
+			return nil, contextdHelpOutput{}, toolErr
+		}
+		index, err := s.helpIndex(ctx)
+		if err != nil {
+			toolErr = fmt.Errorf("building help index: %w", err)
+			return nil, contextdHelpOutput{}, toolErr
+		}
+		results, err := index.Search(args.Query, kinds, args.Limit)
+		if err != nil {
+			toolErr = err
+			return nil, contextdHelpOutput{}, toolErr
+		}
+
+		return nil, contextdHelpOutput{
+			Query:   args.Query,
+			Version: s.version,
+			Results: results,
+		}, nil
+	})
+}
+
+// helpIndex returns the help index, building it on first use. A failed
+// build is retried on the next call.
+func (s *Server) helpIndex(ctx context.Context) (*help.Index, error) {
+	s.help.mu.Lock()
+	defer s.help.mu.Unlock()
+	if s.help.index != nil {
+		return s.help.index, nil
+	}
+
+	tools, err := s.registeredTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	documents := toolDocuments(tools)
+	documents = append(documents, s.capabilityDocuments(tools)...)
+	documents = append(documents, help.ConfigDocuments(docs.Configuration)...)
+	s.help.index = help.NewIndex(documents)
+	return s.help.index, nil
+}
+
+// toolDocuments describes each tool by its description and parameters.
+func toolDocuments(tools []*mcp.Tool) []help.Document {
+	documents := make([]help.Document, 0, len(tools))
+	for _, tool := range tools {
+		text := tool.Description
+		if params := describeParameters(tool.InputSchema); params != "" {
+			text += "\n\nParameters:\n" + params
+		}
+		documents = append(documents, help.Document{
+			ID:    "tool:" + tool.Name,
+			Kind:  help.KindTool,
+			Title: tool.Name,
+			Text:  text,
+		})
+	}
+	return documents
+}
+
+// describeParameters lists the properties of an input schema, one per line,
+// as "- name (type, required): description".
+func describeParameters(schema any) string {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return ""
+	}
+	var parsed struct {
+		Properties map[string]struct {
+			Type        any    `json:"type"`
+			Description string `json:"description"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return ""
+	}
+
+	required := make(map[string]bool, len(parsed.Required))
+	for _, name := range parsed.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(parsed.Properties))
+	for name := range parsed.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		prop := parsed.Properties[name]
+		attrs := []string{}
+		if t, ok := prop.Type.(string); ok {
+			attrs = append(attrs, t)
+		}
+		if required[name] {
+			attrs = append(attrs, "required")
+		}
+		fmt.Fprintf(&b, "- %s", name)
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(attrs, ", "))
+		}
+		if prop.Description != "" {
+			fmt.Fprintf(&b, ": %s", prop.Description)
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// capabilityDocuments describes the server: one document for its version
+// and optional features, and one per tool group listing the group's tools.
+func (s *Server) capabilityDocuments(tools []*mcp.Tool) []help.Document {
+	enabled := func(on bool) string {
+		if on {
+			return "enabled"
+		}
+		return "disabled"
+	}
+	readOnly := s.readOnly != nil && s.readOnly.Enabled()
+	server := []string{
+		fmt.Sprintf("contextd version %s with %d tools.", s.version, len(tools)),
+		"Read-only mode: " + enabled(readOnly) + ". Write tools fail while it is on.",
+		"Conversation indexing: " + enabled(s.conversationSvc != nil) + ".",
+		"Context folding (branch tools): " + enabled(s.foldingSvc != nil) + ".",
+		"Memory consolidation: " + enabled(s.distiller != nil) + ".",
+		"Tool usage analytics: " + enabled(s.analytics != nil) + ".",
+	}
+	documents := []help.Document{{
+		ID:    "capability:server",
+		Kind:  help.KindCapability,
+		Title: "contextd server features and version",
+		Text:  strings.Join(server, "\n"),
+	}}
+
+	groups := make(map[string][]string)
+	for _, tool := range tools {
+		group, _, _ := strings.Cut(tool.Name, "_")
+		summary, _, _ := strings.Cut(tool.Description, ". ")
+		groups[group] = append(groups[group], fmt.Sprintf("- %s: %s", tool.Name, strings.TrimSuffix(summary, ".")))
+	}
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	for _, group := range names {
+		documents = append(documents, help.Document{
+			ID:    "capability:" + group,
+			Kind:  help.KindCapability,
+			Title: group + " tools",
+			Text:  strings.Join(groups[group], "\n"),
+		})
+	}
+	return documents
+}
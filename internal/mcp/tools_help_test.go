@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextdHelp(t *testing.T) {
+	server, _ := setupFoldingTestServer(t)
+	defer server.Close()
+	ctx := context.Background()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.mcp.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	ask := func(t *testing.T, args map[string]any) (contextdHelpOutput, *mcp.CallToolResult) {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "contextd_help", Arguments: args})
+		require.NoError(t, err)
+		var out contextdHelpOutput
+		if !res.IsError {
+			data, err := json.Marshal(res.StructuredContent)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &out))
+		}
+		return out, res
+	}
+
+	t.Run("tools are described by their registered schema", func(t *testing.T) {
+		out, _ := ask(t, map[string]any{"query": "how do I save a checkpoint", "kinds": []string{"tool"}})
+		require.NotEmpty(t, out.Results)
+		assert.Equal(t, "1.0.0", out.Version)
+		assert.Equal(t, "checkpoint_save", out.Results[0].Title)
+		assert.Contains(t, out.Results[0].Text, "- session_id (string, required)")
+	})
+
+	t.Run("config reference is searchable", func(t *testing.T) {
+		out, _ := ask(t, map[string]any{"query": "checkpoint content size", "kinds": []string{"config"}})
+		require.NotEmpty(t, out.Results)
+		assert.Equal(t, "CHECKPOINT_MAX_CONTENT_SIZE_KB", out.Results[0].Title)
+		assert.Contains(t, out.Results[0].Text, "1024")
+	})
+
+	t.Run("capabilities reflect the running server", func(t *testing.T) {
+		out, _ := ask(t, map[string]any{"query": "context folding enabled", "kinds": []string{"capability"}})
+		require.NotEmpty(t, out.Results)
+		assert.Equal(t, "capability:server", out.Results[0].ID)
+		assert.Contains(t, out.Results[0].Text, "contextd version 1.0.0")
+		assert.Contains(t, out.Results[0].Text, "Context folding (branch tools): enabled.")
+		assert.Contains(t, out.Results[0].Text, "Conversation indexing: disabled.")
+	})
+
+	t.Run("unknown kinds are rejected", func(t *testing.T) {
+		_, res := ask(t, map[string]any{"query": "checkpoint", "kinds": []string{"recipe"}})
+		assert.True(t, res.IsError)
+	})
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/knowledge"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+)
+
+// ===== KNOWLEDGE TOOLS =====
+
+type knowledgeSearchInput struct {
+	responseFormat
+
+	Query       string   `json:"query" jsonschema:"required,Search query"`
+	ProjectPath string   `json:"project_path,omitempty" jsonschema:"Project path for remediations, checkpoints, conversations and code (used to derive tenant_id via git remote)"`
+	ProjectID   string   `json:"project_id,omitempty" jsonschema:"Project identifier for memories (derived from project_path if not provided)"`
+	TenantID    string   `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
+	Types       []string `json:"types,omitempty" jsonschema:"Restrict to these types: memory, remediation, checkpoint, conversation, code (default: all)"`
+	Limit       int      `json:"limit,omitempty" jsonschema:"Maximum merged results (default: 10, max: 50)"`
+}
+
+func (s *Server) registerKnowledgeTools() {
+	// knowledge_search - Search every knowledge type in one call
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "knowledge_search",
+		Description: "Search memories, remediations, checkpoints, indexed conversations and indexed code in one call. Returns a single list ranked by type-aware score, each result tagged with its type. Sources that are unavailable or fail are reported in warnings instead of failing the call.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args knowledgeSearchInput) (*mcp.CallToolResult, knowledge.Response, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "knowledge_search", &toolErr)()
+
+		if args.Query == "" {
+			toolErr = fmt.Errorf("query is required")
+			return nil, knowledge.Response{}, toolErr
+		}
+		types, err := knowledge.ParseTypes(args.Types)
+		if err != nil {
+			toolErr = err
+			return nil, knowledge.Response{}, toolErr
+		}
+
+		searchReq := knowledge.Request{
+			Query:     args.Query,
+			ProjectID: args.ProjectID,
+			Types:     types,
+			Limit:     args.Limit,
+		}
+
+		// Everything but memories is tenant-scoped. Without a path or
+		// explicit tenant those types are skipped with a warning.
+		if args.ProjectPath != "" || args.TenantID != "" {
+			validPath, tenantID, projectID, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
+			if err != nil {
+				toolErr = err
+				return nil, knowledge.Response{}, toolErr
+			}
+			searchReq.ProjectPath = validPath
+			searchReq.TenantID = tenantID
+			if searchReq.ProjectID == "" {
+				searchReq.ProjectID = projectID
+			}
+		}
+		if searchReq.ProjectID != "" {
+			if err := sanitize.ValidateProjectID(searchReq.ProjectID); err != nil {
+				toolErr = fmt.Errorf("invalid project_id: %w", err)
+				return nil, knowledge.Response{}, toolErr
+			}
+		}
+
+		resp, err := s.knowledgeSearcher().Search(ctx, searchReq)
+		if err != nil {
+			toolErr = fmt.Errorf("knowledge search failed: %w", err)
+			return nil, knowledge.Response{}, toolErr
+		}
+
+		return nil, *resp, nil
+	})
+}
+
+// knowledgeSearcher builds a searcher over the services configured now.
+// The conversation service is attached after tool registration, so the
+// searcher is built per call rather than once.
+func (s *Server) knowledgeSearcher() *knowledge.Searcher {
+	sources := knowledge.Sources{
+		Memory:        s.reasoningbankSvc,
+		Remediations:  s.remediationSvc,
+		Checkpoints:   s.checkpointSvc,
+		Conversations: s.conversationSvc,
+	}
+	// Avoid wrapping a nil *repository.Service in a non-nil interface
+	if s.repositorySvc != nil {
+		sources.Code = s.repositorySvc
+	}
+	return knowledge.NewSearcher(sources, s.scrubber, s.logger)
+}
@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// ===== MEMORY BATCH TOOLS =====
+
+type memoryBatchEntry struct {
+	Title      string   `json:"title" jsonschema:"required,Brief title for the memory"`
+	Content    string   `json:"content" jsonschema:"required,The strategy or learning to remember"`
+	Outcome    string   `json:"outcome" jsonschema:"required,Outcome type (success or failure)"`
+	Tags       []string `json:"tags,omitempty" jsonschema:"Tags for categorization"`
+	Languages  []string `json:"languages,omitempty" jsonschema:"Languages or stacks the memory is about (e.g. go, terraform). Inferred from tags and content when omitted"`
+	Visibility string   `json:"visibility,omitempty" jsonschema:"Who can find the memory: private (only you), team (your team) or org (everyone in the project, default)"`
+}
+
+type memoryRecordBatchInput struct {
+	responseFormat
+	idempotencyKey
+
+	ProjectID string             `json:"project_id" jsonschema:"required,Project identifier"`
+	Memories  []memoryBatchEntry `json:"memories" jsonschema:"required,Memories to record (at most 50)"`
+	Mode      string             `json:"mode,omitempty" jsonschema:"all_or_nothing (default): record every memory or none; best_effort: record the valid memories and report the others"`
+}
+
+type memoryBatchResult struct {
+	Index      int     `json:"index" jsonschema:"Position of the memory in the request"`
+	Status     string  `json:"status" jsonschema:"recorded, rejected (invalid or low quality), failed (could not be stored) or skipped (valid, but the all_or_nothing batch was not recorded)"`
+	ID         string  `json:"id,omitempty" jsonschema:"Memory ID, when recorded"`
+	Title      string  `json:"title" jsonschema:"Memory title"`
+	Confidence float64 `json:"confidence,omitempty" jsonschema:"Initial confidence, when recorded"`
+	Error      string  `json:"error,omitempty" jsonschema:"Why the memory was not recorded"`
+	ErrorCode  string  `json:"error_code,omitempty" jsonschema:"Error code of a rejected or failed memory"`
+}
+
+type memoryRecordBatchOutput struct {
+	Mode     string              `json:"mode" jsonschema:"Batch mode used"`
+	Recorded int                 `json:"recorded" jsonschema:"Number of memories recorded"`
+	Results  []memoryBatchResult `json:"results" jsonschema:"Outcome of each memory, in request order"`
+}
+
+func (s *Server) registerMemoryBatchTools() {
+	// memory_record_batch - Record several memories in one call
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "memory_record_batch",
+		Description: "Record several memories at once, e.g. the learnings distilled from a long session. Memories are embedded and stored together and validated one by one; the result reports each memory's outcome. mode all_or_nothing (default) records every memory or none, best_effort records the valid ones.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args memoryRecordBatchInput) (*mcp.CallToolResult, memoryRecordBatchOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "memory_record_batch", &toolErr)()
+
+		if err := s.readOnly.Check("memory_record_batch"); err != nil {
+			toolErr = err
+			return nil, memoryRecordBatchOutput{}, toolErr
+		}
+		if err := sanitize.ValidateProjectID(args.ProjectID); err != nil {
+			toolErr = fmt.Errorf("invalid project_id: %w", err)
+			return nil, memoryRecordBatchOutput{}, toolErr
+		}
+		mode, err := reasoningbank.ParseBatchMode(args.Mode)
+		if err != nil {
+			toolErr = err
+			return nil, memoryRecordBatchOutput{}, toolErr
+		}
+		switch {
+		case len(args.Memories) == 0:
+			toolErr = reasoningbank.ErrEmptyBatch
+			return nil, memoryRecordBatchOutput{}, toolErr
+		case len(args.Memories) > reasoningbank.MaxRecordBatch:
+			toolErr = fmt.Errorf("%w: %d, at most %d", reasoningbank.ErrBatchTooLarge, len(args.Memories), reasoningbank.MaxRecordBatch)
+			return nil, memoryRecordBatchOutput{}, toolErr
+		}
+
+		ctx, err = withTenantContext(ctx, args.ProjectID, "", args.ProjectID)
+		if err != nil {
+			toolErr = fmt.Errorf("failed to set tenant context: %w", err)
+			return nil, memoryRecordBatchOutput{}, toolErr
+		}
+
+		// Entries that do not make a memory are rejected here; the service
+		// validates and records the rest
+		results := make([]memoryBatchResult, len(args.Memories))
+		var (
+			memories []*reasoningbank.Memory
+			indexes  []int // request index of each memory
+			rejected bool
+		)
+		for i, entry := range args.Memories {
+			results[i] = memoryBatchResult{Index: i, Title: entry.Title}
+			memory, err := newBatchMemory(args.ProjectID, entry)
+			if err != nil {
+				results[i].reject(string(reasoningbank.BatchRejected), err)
+				rejected = true
+				continue
+			}
+			memories = append(memories, memory)
+			indexes = append(indexes, i)
+		}
+
+		output := memoryRecordBatchOutput{Mode: string(mode), Results: results}
+		if (rejected && mode == reasoningbank.BatchAllOrNothing) || len(memories) == 0 {
+			skipped := errors.New("not recorded: other memories in the batch were rejected")
+			for i := range results {
+				if results[i].Status == "" {
+					results[i].reject(string(reasoningbank.BatchSkipped), skipped)
+				}
+			}
+			return batchResult(output), output, nil
+		}
+
+		batch, err := s.reasoningbankSvc.RecordBatch(ctx, memories, reasoningbank.BatchOptions{Mode: mode, CheckQuality: true})
+		if err != nil {
+			toolErr = fmt.Errorf("memory record batch failed: %w", err)
+			return nil, memoryRecordBatchOutput{}, toolErr
+		}
+		for j, item := range batch.Items {
+			result := &results[indexes[j]]
+			if item.Status != reasoningbank.BatchRecorded {
+				result.reject(string(item.Status), item.Err)
+				continue
+			}
+			result.Status = string(item.Status)
+			result.ID = item.Memory.ID
+			result.Confidence = item.Memory.Confidence
+		}
+		output.Recorded = batch.Recorded
+		return batchResult(output), output, nil
+	})
+}
+
+// reject records why a memory was not recorded. Skipped memories have no
+// error code, since nothing was wrong with them.
+func (r *memoryBatchResult) reject(status string, err error) {
+	r.Status = status
+	if err == nil {
+		return
+	}
+	r.Error = err.Error()
+	if status != string(reasoningbank.BatchSkipped) {
+		r.ErrorCode = string(ctxerrors.CodeOf(err))
+	}
+}
+
+// newBatchMemory makes the memory described by a batch entry.
+func newBatchMemory(projectID string, entry memoryBatchEntry) (*reasoningbank.Memory, error) {
+	outcome := reasoningbank.OutcomeSuccess
+	if entry.Outcome == "failure" {
+		outcome = reasoningbank.OutcomeFailure
+	}
+	memory, err := reasoningbank.NewMemory(projectID, entry.Title, entry.Content, outcome, entry.Tags)
+	if err != nil {
+		return nil, err
+	}
+	memory.Languages = entry.Languages
+	visibility, err := vectorstore.ParseVisibility(entry.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Visibility != "" {
+		memory.Visibility = visibility
+	}
+	return memory, nil
+}
+
+// batchResult summarizes a batch for the text content.
+func batchResult(output memoryRecordBatchOutput) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Recorded %d of %d memories (%s)", output.Recorded, len(output.Results), output.Mode)},
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/checkpoint"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/troubleshoot"
+)
+
+func TestServer_MemoryRecordBatch(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	vectorStore := &mockVectorStore{}
+
+	checkpointSvc, err := checkpoint.NewServiceWithStore(checkpoint.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	remediationSvc, err := remediation.NewService(remediation.DefaultServiceConfig(), vectorStore, logger)
+	require.NoError(t, err)
+	troubleshootSvc, err := troubleshoot.NewService(&mockTroubleshootStore{}, logger, nil)
+	require.NoError(t, err)
+	reasoningbankSvc, err := reasoningbank.NewService(vectorStore, logger)
+	require.NoError(t, err)
+
+	server, err := NewServer(DefaultConfig(), checkpointSvc, remediationSvc, repository.NewService(vectorStore), troubleshootSvc, reasoningbankSvc, nil, nil, secrets.MustNew(secrets.DefaultConfig()))
+	require.NoError(t, err)
+	defer server.Close()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err = server.mcp.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	memories := []any{
+		map[string]any{"title": "Run migrations first", "content": "Run the migrations before the integration tests", "outcome": "success"},
+		map[string]any{"title": "", "content": "Untitled learning about the test database", "outcome": "success"},
+		map[string]any{"title": "Pin the linter", "content": "Pin golangci-lint so CI and local runs agree", "outcome": "failure", "tags": []any{"ci"}},
+	}
+	call := func(t *testing.T, mode string) *mcp.CallToolResult {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name: "memory_record_batch",
+			Arguments: map[string]any{
+				"project_id": "proj",
+				"memories":   memories,
+				"mode":       mode,
+			},
+		})
+		require.NoError(t, err)
+		return res
+	}
+	results := func(t *testing.T, res *mcp.CallToolResult) []map[string]any {
+		t.Helper()
+		require.False(t, res.IsError, "tool error: %v", res.Content)
+		output, ok := res.StructuredContent.(map[string]any)
+		require.True(t, ok)
+		items, ok := output["results"].([]any)
+		require.True(t, ok)
+		out := make([]map[string]any, len(items))
+		for i, item := range items {
+			out[i] = item.(map[string]any)
+		}
+		return out
+	}
+
+	t.Run("best effort records the valid memories", func(t *testing.T) {
+		res := call(t, "best_effort")
+		items := results(t, res)
+		require.Len(t, items, 3)
+		assert.Equal(t, "recorded", items[0]["status"])
+		assert.NotEmpty(t, items[0]["id"])
+		assert.Equal(t, "rejected", items[1]["status"])
+		assert.Equal(t, "INVALID_INPUT", items[1]["error_code"])
+		assert.Equal(t, "recorded", items[2]["status"])
+		assert.EqualValues(t, 2, res.StructuredContent.(map[string]any)["recorded"])
+		assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "Recorded 2 of 3 memories (best_effort)")
+	})
+
+	t.Run("all or nothing records none", func(t *testing.T) {
+		res := call(t, "")
+		items := results(t, res)
+		require.Len(t, items, 3)
+		assert.Equal(t, "all_or_nothing", res.StructuredContent.(map[string]any)["mode"])
+		assert.Equal(t, "skipped", items[0]["status"])
+		assert.Empty(t, items[0]["error_code"])
+		assert.Equal(t, "rejected", items[1]["status"])
+		assert.Equal(t, "skipped", items[2]["status"])
+		assert.EqualValues(t, 0, res.StructuredContent.(map[string]any)["recorded"])
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		res := call(t, "partial")
+		require.True(t, res.IsError)
+		assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "invalid batch mode")
+	})
+}
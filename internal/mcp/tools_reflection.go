@@ -11,6 +11,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.uber.org/zap"
 
+	"github.com/fyrsmithlabs/contextd/internal/notify"
 	"github.com/fyrsmithlabs/contextd/internal/reflection"
 	"github.com/fyrsmithlabs/contextd/internal/sanitize"
 )
@@ -25,7 +26,7 @@ type reflectReportInput struct {
 	IncludeCorrelations bool   `json:"include_correlations,omitempty" jsonschema:"Include correlation analysis (default: true)"`
 	IncludeInsights     bool   `json:"include_insights,omitempty" jsonschema:"Include insights (default: true)"`
 	MaxInsights         int    `json:"max_insights,omitempty" jsonschema:"Maximum insights to include (default: 10)"`
-	Format              string `json:"format,omitempty" jsonschema:"Output format: json, text, markdown (default: json)"`
+	Format              string `json:"format,omitempty" jsonschema:"Output format: json, text, markdown, compact (default: json). compact is json with empty fields dropped and scores rounded"`
 }
 
 type reflectReportOutput struct {
@@ -42,7 +43,35 @@ type reflectReportOutput struct {
 	ReportPath    string                      `json:"report_path,omitempty" jsonschema:"Path where report was saved (if project_path provided)"`
 }
 
+type reflectTeamReportInput struct {
+	TeamID       string   `json:"team_id" jsonschema:"required,Team identifier"`
+	ProjectIDs   []string `json:"project_ids" jsonschema:"required,The team's project identifiers (at least 2)"`
+	PeriodDays   int      `json:"period_days,omitempty" jsonschema:"Number of days to analyze (default: 30)"`
+	MinProjects  int      `json:"min_projects,omitempty" jsonschema:"Projects a tag must appear in to be compared (default: 2)"`
+	MinFrequency int      `json:"min_frequency,omitempty" jsonschema:"Memories with a tag a project needs for the tag to count (default: 2)"`
+	MaxItems     int      `json:"max_items,omitempty" jsonschema:"Maximum entries per section (default: 10)"`
+	Format       string   `json:"format,omitempty" jsonschema:"Output format: json or markdown (default: json)"`
+}
+
+type reflectTeamReportOutput struct {
+	ReportID        string                            `json:"report_id" jsonschema:"Report identifier"`
+	TeamID          string                            `json:"team_id" jsonschema:"Team analyzed"`
+	GeneratedAt     time.Time                         `json:"generated_at" jsonschema:"Report generation time"`
+	PeriodDays      int                               `json:"period_days" jsonschema:"Days analyzed"`
+	Summary         string                            `json:"summary" jsonschema:"High-level summary"`
+	Statistics      reflection.ReportStatistics       `json:"statistics" jsonschema:"Statistics across all projects"`
+	Projects        []reflection.ProjectSummary       `json:"projects" jsonschema:"Statistics per project"`
+	SharedPatterns  []reflection.CrossProjectPattern  `json:"shared_patterns" jsonschema:"Tags that succeed in every project using them"`
+	AntiPatterns    []reflection.CrossProjectPattern  `json:"anti_patterns" jsonschema:"Tags that fail in every project using them"`
+	Divergences     []reflection.ConventionDivergence `json:"divergences" jsonschema:"Tags that succeed in some projects and fail in others"`
+	Recommendations []string                          `json:"recommendations" jsonschema:"Recommendations for the team"`
+	Format          string                            `json:"format" jsonschema:"Output format used"`
+	FormattedText   string                            `json:"formatted_text,omitempty" jsonschema:"Formatted report (for markdown)"`
+}
+
 type reflectAnalyzeInput struct {
+	responseFormat
+
 	ProjectID     string   `json:"project_id" jsonschema:"required,Project identifier"`
 	MinConfidence float64  `json:"min_confidence,omitempty" jsonschema:"Minimum confidence threshold (default: 0.3)"`
 	MinFrequency  int      `json:"min_frequency,omitempty" jsonschema:"Minimum pattern frequency (default: 2)"`
@@ -63,11 +92,12 @@ func (s *Server) registerReflectionTools() {
 		return
 	}
 
-	reporter := reflection.NewReporter(s.reasoningbankSvc)
+	teamReporter := reflection.NewReporter(s.reasoningbankSvc)
+	reporter := notify.Reporter(teamReporter, s.notifier)
 	analyzer := reflection.NewAnalyzer(s.reasoningbankSvc)
 
 	// reflect_report - Generate a reflection report
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "reflect_report",
 		Description: "Generate a self-reflection report analyzing memories and patterns for a project. Returns insights about behavior patterns, success/failure trends, and recommendations.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args reflectReportInput) (*mcp.CallToolResult, reflectReportOutput, error) {
@@ -170,8 +200,9 @@ func (s *Server) registerReflectionTools() {
 			}
 		}
 
-		// Persist report to disk if project path is provided
-		if args.ProjectPath != "" {
+		// Persist report to disk if project path is provided; in read-only
+		// mode the report is still returned, just not saved
+		if args.ProjectPath != "" && !s.readOnly.Enabled() {
 			if path, err := StoreReflectionReport(report, args.ProjectPath); err == nil {
 				output.ReportPath = path
 			} else {
@@ -191,8 +222,92 @@ func (s *Server) registerReflectionTools() {
 		}, output, nil
 	})
 
+	// reflect_team_report - Compare reflection across a team's projects
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "reflect_team_report",
+		Description: "Generate a team reflection report across several projects. Returns practices that succeed in every project, anti-patterns that fail everywhere, and conventions that work in some repositories but fail in others.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args reflectTeamReportInput) (*mcp.CallToolResult, reflectTeamReportOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "reflect_team_report", &toolErr)()
+
+		if err := sanitize.ValidateTeamID(args.TeamID); err != nil {
+			toolErr = fmt.Errorf("invalid team_id: %w", err)
+			return nil, reflectTeamReportOutput{}, toolErr
+		}
+		for _, projectID := range args.ProjectIDs {
+			if err := sanitize.ValidateProjectID(projectID); err != nil {
+				toolErr = fmt.Errorf("invalid project_id %q: %w", projectID, err)
+				return nil, reflectTeamReportOutput{}, toolErr
+			}
+		}
+
+		format := args.Format
+		if format == "" {
+			format = "json"
+		}
+		if format != "json" && format != "markdown" {
+			toolErr = fmt.Errorf("invalid format %q: must be json or markdown", format)
+			return nil, reflectTeamReportOutput{}, toolErr
+		}
+
+		periodDays := args.PeriodDays
+		if periodDays <= 0 {
+			periodDays = 30
+		}
+		now := time.Now()
+
+		report, err := teamReporter.GenerateTeam(ctx, reflection.TeamReportOptions{
+			TeamID:     args.TeamID,
+			ProjectIDs: args.ProjectIDs,
+			Period: reflection.ReportPeriod{
+				Start:       now.AddDate(0, 0, -periodDays),
+				End:         now,
+				Description: fmt.Sprintf("Last %d days", periodDays),
+			},
+			MinProjects:  args.MinProjects,
+			MinFrequency: args.MinFrequency,
+			MaxItems:     args.MaxItems,
+		})
+		if err != nil {
+			toolErr = fmt.Errorf("team report generation failed: %w", err)
+			return nil, reflectTeamReportOutput{}, toolErr
+		}
+
+		output := reflectTeamReportOutput{
+			ReportID:        report.ID,
+			TeamID:          report.TeamID,
+			GeneratedAt:     report.GeneratedAt,
+			PeriodDays:      periodDays,
+			Summary:         report.Summary,
+			Statistics:      report.Statistics,
+			Projects:        report.Projects,
+			SharedPatterns:  report.SharedPatterns,
+			AntiPatterns:    report.AntiPatterns,
+			Divergences:     report.Divergences,
+			Recommendations: report.Recommendations,
+			Format:          format,
+		}
+		if format == "markdown" {
+			output.FormattedText = reflection.FormatTeamReport(report, format)
+		}
+
+		// Scrub summary if needed
+		if s.scrubber != nil {
+			output.Summary = s.scrubber.Scrub(output.Summary).Scrubbed
+			if output.FormattedText != "" {
+				output.FormattedText = s.scrubber.Scrub(output.FormattedText).Scrubbed
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Generated team reflection report: %s - %s", report.ID, output.Summary)},
+			},
+		}, output, nil
+	})
+
 	// reflect_analyze - Analyze patterns in memories
-	mcp.AddTool(s.mcp, &mcp.Tool{
+	addTool(s.mcp, &mcp.Tool{
 		Name:        "reflect_analyze",
 		Description: "Analyze memories for behavioral patterns. Returns patterns grouped by category (success, failure, recurring, improving, declining) with confidence scores.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args reflectAnalyzeInput) (*mcp.CallToolResult, reflectAnalyzeOutput, error) {
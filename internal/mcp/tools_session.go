@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/bootstrap"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+)
+
+// ===== SESSION TOOLS =====
+
+type sessionBootstrapInput struct {
+	responseFormat
+
+	ProjectID        string `json:"project_id" jsonschema:"required,Project identifier for memories and insights"`
+	Task             string `json:"task" jsonschema:"required,Description of the task the session is starting"`
+	ProjectPath      string `json:"project_path,omitempty" jsonschema:"Project path for checkpoints and remediations (used to derive tenant_id via git remote)"`
+	TenantID         string `json:"tenant_id,omitempty" jsonschema:"Tenant identifier (auto-derived from project_path via git remote if not provided)"`
+	MemoryLimit      int    `json:"memory_limit,omitempty" jsonschema:"Maximum memories to include (default: 5)"`
+	RemediationLimit int    `json:"remediation_limit,omitempty" jsonschema:"Maximum remediations to include (default: 3)"`
+	InsightLimit     int    `json:"insight_limit,omitempty" jsonschema:"Maximum reflection insights to include (default: 3)"`
+}
+
+func (s *Server) registerSessionTools() {
+	// Unconfigured services are nil; their sections become warnings
+	builder := bootstrap.NewBuilder(s.reasoningbankSvc, s.checkpointSvc, s.remediationSvc, s.scrubber, s.logger)
+
+	// session_bootstrap - Compose session-start context in one call
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "session_bootstrap",
+		Description: "Start a session in one call. Returns the top memories for the task, the latest checkpoint summary, remediations for recent error categories, and active reflection insights. Sections that fail are reported in warnings instead of failing the call.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args sessionBootstrapInput) (*mcp.CallToolResult, bootstrap.Bundle, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "session_bootstrap", &toolErr)()
+
+		if args.ProjectID == "" || args.Task == "" {
+			toolErr = fmt.Errorf("project_id and task are required")
+			return nil, bootstrap.Bundle{}, toolErr
+		}
+		if err := sanitize.ValidateProjectID(args.ProjectID); err != nil {
+			toolErr = fmt.Errorf("invalid project_id: %w", err)
+			return nil, bootstrap.Bundle{}, toolErr
+		}
+
+		bootstrapReq := bootstrap.Request{
+			ProjectID:        args.ProjectID,
+			Task:             args.Task,
+			MemoryLimit:      args.MemoryLimit,
+			RemediationLimit: args.RemediationLimit,
+			InsightLimit:     args.InsightLimit,
+		}
+
+		// Checkpoints and remediations are tenant-scoped. Without a path or
+		// explicit tenant those sections are skipped with a warning.
+		if args.ProjectPath != "" || args.TenantID != "" {
+			validPath, tenantID, _, err := s.validateAndDeriveProjectPath(args.ProjectPath, args.TenantID)
+			if err != nil {
+				toolErr = err
+				return nil, bootstrap.Bundle{}, toolErr
+			}
+			bootstrapReq.ProjectPath = validPath
+			bootstrapReq.TenantID = tenantID
+		}
+
+		bundle, err := builder.Build(ctx, bootstrapReq)
+		if err != nil {
+			toolErr = fmt.Errorf("session bootstrap failed: %w", err)
+			return nil, bootstrap.Bundle{}, toolErr
+		}
+
+		return nil, *bundle, nil
+	})
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+)
+
+// maxSimilarLimit caps the neighbors memory_similar returns.
+const maxSimilarLimit = 50
+
+// ===== SIMILARITY TOOLS =====
+
+type memorySimilarInput struct {
+	responseFormat
+	tokenBudget
+
+	ProjectID string `json:"project_id" jsonschema:"required,Project identifier"`
+	MemoryID  string `json:"memory_id" jsonschema:"required,Memory to find similar memories for"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"Maximum results (default: 5, max: 50)"`
+}
+
+type memorySimilarOutput struct {
+	MemoryID string                   `json:"memory_id" jsonschema:"Memory the results are similar to"`
+	Memories []map[string]interface{} `json:"memories" jsonschema:"Similar memories, most similar first"`
+	Count    int                      `json:"count" jsonschema:"Number of results"`
+	budgetUsage
+}
+
+func (s *Server) registerSimilarTools() {
+	// memory_similar - "More like this" for a memory
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "memory_similar",
+		Description: "Find the memories most similar to a given memory (\"more like this\"), excluding the memory itself and archived memories. Use it to explore related knowledge or to pick memories to consolidate by hand.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args memorySimilarInput) (*mcp.CallToolResult, memorySimilarOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "memory_similar", &toolErr)()
+
+		if err := sanitize.ValidateProjectID(args.ProjectID); err != nil {
+			toolErr = fmt.Errorf("invalid project_id: %w", err)
+			return nil, memorySimilarOutput{}, toolErr
+		}
+		if args.MemoryID == "" {
+			toolErr = fmt.Errorf("memory_id is required")
+			return nil, memorySimilarOutput{}, toolErr
+		}
+		if err := args.tokenBudget.validate(); err != nil {
+			toolErr = err
+			return nil, memorySimilarOutput{}, toolErr
+		}
+
+		limit := args.Limit
+		if limit <= 0 {
+			limit = 5
+		}
+		if limit > maxSimilarLimit {
+			limit = maxSimilarLimit
+		}
+
+		ctx, err := withTenantContext(ctx, args.ProjectID, "", args.ProjectID)
+		if err != nil {
+			toolErr = fmt.Errorf("failed to set tenant context: %w", err)
+			return nil, memorySimilarOutput{}, toolErr
+		}
+
+		similar, err := s.reasoningbankSvc.SimilarByProjectID(ctx, args.ProjectID, args.MemoryID, limit)
+		if err != nil {
+			toolErr = fmt.Errorf("memory similar failed: %w", err)
+			return nil, memorySimilarOutput{}, toolErr
+		}
+
+		results := make([]map[string]interface{}, 0, len(similar))
+		for _, sm := range similar {
+			result := map[string]interface{}{
+				"id":         sm.Memory.ID,
+				"title":      sm.Memory.Title,
+				"content":    s.scrubber.Scrub(sm.Memory.Content).Scrubbed,
+				"outcome":    sm.Memory.Outcome,
+				"confidence": sm.Memory.Confidence,
+				"similarity": sm.Relevance, // Vector similarity to memory_id (0.0-1.0)
+				"tags":       sm.Memory.Tags,
+			}
+			results = append(results, result)
+		}
+		results, usage := fitResultsToBudget(results, []string{"content"}, args.MaxTokens)
+
+		output := memorySimilarOutput{
+			MemoryID:    args.MemoryID,
+			Memories:    results,
+			Count:       len(results),
+			budgetUsage: usage,
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Found %d memories similar to %s", output.Count, output.MemoryID)},
+			},
+		}, output, nil
+	})
+}
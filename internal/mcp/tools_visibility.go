@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// attachRequester is receiving middleware that records the server's user
+// and team on tool calls, so private and team memories are owned by and
+// visible to them.
+func (s *Server) attachRequester(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "tools/call" {
+			ctx = vectorstore.ContextWithRequester(ctx, s.requester)
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// ===== VISIBILITY TOOLS =====
+
+type memoryShareInput struct {
+	responseFormat
+
+	ProjectID        string `json:"project_id" jsonschema:"required,Project identifier"`
+	MemoryID         string `json:"memory_id" jsonschema:"required,Memory ID to share or restrict"`
+	Visibility       string `json:"visibility" jsonschema:"required,Who can find the memory: private (only you), team (your team) or org (everyone in the project)"`
+	ExpectedRevision int64  `json:"expected_revision,omitempty" jsonschema:"Only apply if the memory is still at this revision (from a search result); fails with a conflict otherwise"`
+}
+
+type memoryShareOutput struct {
+	MemoryID   string `json:"memory_id" jsonschema:"Memory ID"`
+	Visibility string `json:"visibility" jsonschema:"Visibility after the change"`
+}
+
+func (s *Server) registerVisibilityTools() {
+	// memory_share - Change who can find a memory
+	addTool(s.mcp, &mcp.Tool{
+		Name:        "memory_share",
+		Description: "Change the visibility of a memory you recorded: private (only you), team (your team) or org (everyone in the project). Use it to share an experimental private memory once it has proven useful.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args memoryShareInput) (*mcp.CallToolResult, memoryShareOutput, error) {
+		var toolErr error
+		defer s.startMetrics(ctx, "memory_share", &toolErr)()
+
+		if err := s.readOnly.Check("memory_share"); err != nil {
+			toolErr = err
+			return nil, memoryShareOutput{}, toolErr
+		}
+		if err := sanitize.ValidateProjectID(args.ProjectID); err != nil {
+			toolErr = fmt.Errorf("invalid project_id: %w", err)
+			return nil, memoryShareOutput{}, toolErr
+		}
+		visibility, err := vectorstore.ParseVisibility(args.Visibility)
+		if err != nil || args.Visibility == "" {
+			toolErr = fmt.Errorf("visibility must be private, team or org")
+			return nil, memoryShareOutput{}, toolErr
+		}
+
+		ctx, err = withTenantContext(ctx, args.ProjectID, "", args.ProjectID)
+		if err != nil {
+			toolErr = fmt.Errorf("failed to set tenant context: %w", err)
+			return nil, memoryShareOutput{}, toolErr
+		}
+
+		ctx = reasoningbank.ContextWithExpectedRevision(ctx, args.ExpectedRevision)
+		memory, err := s.reasoningbankSvc.SetVisibility(ctx, args.ProjectID, args.MemoryID, visibility)
+		if err != nil {
+			toolErr = fmt.Errorf("memory share failed: %w", err)
+			return nil, memoryShareOutput{}, toolErr
+		}
+
+		output := memoryShareOutput{
+			MemoryID:   memory.ID,
+			Visibility: string(memory.Visibility),
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Memory %s is now %s", output.MemoryID, output.Visibility)},
+			},
+		}, output, nil
+	})
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+)
+
+// queryArguments and contentArguments name the tool arguments checked
+// against a service's query and content limits. Tools share argument names,
+// so one list covers every tool.
+var (
+	queryArguments   = []string{"query", "task"}
+	contentArguments = []string{
+		"title", "description", "content", "summary", "context", "full_state",
+		"problem", "root_cause", "solution", "code_diff", "notes", "comment",
+	}
+)
+
+// limitsForTool returns the limits of the service behind a tool.
+func limitsForTool(name string) validation.Limits {
+	switch {
+	case strings.HasPrefix(name, "memory_"):
+		return validation.Memory
+	case strings.HasPrefix(name, "remediation_"):
+		return validation.Remediation
+	case strings.HasPrefix(name, "checkpoint_"):
+		return validation.Checkpoint
+	case strings.HasPrefix(name, "repository_"), name == "semantic_search":
+		return validation.Repository
+	case strings.HasPrefix(name, "conversation_"):
+		return validation.Conversation
+	default:
+		return validation.Default
+	}
+}
+
+// validateArguments is receiving middleware that rejects tool calls whose
+// arguments exceed the service limits before the handler runs. Rejections
+// carry the ERR_VALIDATION_* code in the message and, as a ctxerrors.Payload,
+// in the result's _meta.error. Oversized queries and content also get a
+// sizeAdvisory in _meta.advisory and are counted per client. It is added
+// first so analytics count rejections as failed calls.
+func (s *Server) validateArguments(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok {
+			return next(ctx, method, req)
+		}
+		limits := limitsForTool(call.Params.Name)
+		if verr := checkArguments(limits, call.Params.Arguments); verr != nil {
+			result := toolErrorResult(verr)
+			if advisory := s.inputAdvisory(call.Params.Name, limits, verr); advisory != nil {
+				s.recordOversizedInput(ctx, call, verr)
+				result.Content = []mcp.Content{&mcp.TextContent{Text: verr.Error() + "\n" + advisory.text()}}
+				result.Meta["advisory"] = advisory
+			}
+			return result, nil
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// sizeAdvisory is attached as _meta.advisory to calls rejected because a
+// query or content argument is too long. It tells the agent how to bring the
+// input under the limit rather than only that it is over.
+type sizeAdvisory struct {
+	Tool   string `json:"tool"`
+	Field  string `json:"field"`
+	Limit  int    `json:"limit"`
+	Actual int    `json:"actual"`
+
+	// Limits are the tool's other size limits, so a rewritten call does not
+	// trip the next one.
+	Limits sizeLimits `json:"limits"`
+
+	Suggestions []sizeSuggestion `json:"suggestions"`
+}
+
+// sizeLimits is the wire form of the size limits in validation.Limits.
+type sizeLimits struct {
+	MaxQueryLength   int `json:"max_query_length,omitempty"`
+	MaxContentLength int `json:"max_content_length,omitempty"`
+	MaxTags          int `json:"max_tags,omitempty"`
+	MaxTagLength     int `json:"max_tag_length,omitempty"`
+	MaxMetadataKeys  int `json:"max_metadata_keys,omitempty"`
+}
+
+// sizeSuggestion is one way to bring an oversized argument under its limit.
+type sizeSuggestion struct {
+	// Action is "compress" or "fold".
+	Action string `json:"action"`
+
+	// Tool is the tool to call for the action, if any.
+	Tool string `json:"tool,omitempty"`
+
+	Detail string `json:"detail"`
+}
+
+// inputAdvisory returns the advisory for verr, or nil if verr is not an
+// oversized query or content argument.
+func (s *Server) inputAdvisory(tool string, limits validation.Limits, verr *validation.Error) *sizeAdvisory {
+	var suggestions []sizeSuggestion
+	switch verr.Code {
+	case validation.CodeQueryTooLong:
+		suggestions = append(suggestions, sizeSuggestion{
+			Action: "compress",
+			Detail: fmt.Sprintf("Reduce %s to its key terms, at most %d characters; long queries match no better than short ones.", verr.Field, verr.Limit),
+		})
+	case validation.CodeContentTooLong:
+		suggestions = append(suggestions, sizeSuggestion{
+			Action: "compress",
+			Detail: fmt.Sprintf("Summarize %s to at most %d characters, keeping decisions, identifiers and error messages verbatim.", verr.Field, verr.Limit),
+		})
+		if s.foldingSvc != nil {
+			suggestions = append(suggestions, sizeSuggestion{
+				Action: "fold",
+				Tool:   "branch_create",
+				Detail: fmt.Sprintf("Work through the full input in a context-folding branch, then call %s with what branch_return hands back.", tool),
+			})
+		}
+	default:
+		return nil
+	}
+
+	return &sizeAdvisory{
+		Tool:   tool,
+		Field:  verr.Field,
+		Limit:  verr.Limit,
+		Actual: verr.Actual,
+		Limits: sizeLimits{
+			MaxQueryLength:   limits.MaxQueryLength,
+			MaxContentLength: limits.MaxContentLength,
+			MaxTags:          limits.MaxTags,
+			MaxTagLength:     limits.MaxTagLength,
+			MaxMetadataKeys:  limits.MaxMetadataKeys,
+		},
+		Suggestions: suggestions,
+	}
+}
+
+// text renders the suggestions for the call's text content, which is all
+// many agents read.
+func (a *sizeAdvisory) text() string {
+	var b strings.Builder
+	b.WriteString("To fit the limit:")
+	for _, sug := range a.Suggestions {
+		b.WriteString("\n- ")
+		b.WriteString(sug.Action)
+		if sug.Tool != "" {
+			fmt.Fprintf(&b, " (%s)", sug.Tool)
+		}
+		b.WriteString(": ")
+		b.WriteString(sug.Detail)
+	}
+	return b.String()
+}
+
+// recordOversizedInput counts and logs a call rejected for oversized input,
+// labeled by the client that made it.
+func (s *Server) recordOversizedInput(ctx context.Context, call *mcp.CallToolRequest, verr *validation.Error) {
+	client, version := "unknown", ""
+	if call.Session != nil {
+		if params := call.Session.InitializeParams(); params != nil && params.ClientInfo != nil {
+			client, version = params.ClientInfo.Name, params.ClientInfo.Version
+		}
+	}
+	if s.metrics != nil {
+		s.metrics.RecordOversizedInput(ctx, call.Params.Name, verr.Field, client)
+	}
+	s.logger.Warn("tool input exceeds size limit",
+		zap.String("tool", call.Params.Name),
+		zap.String("field", verr.Field),
+		zap.Int("limit", verr.Limit),
+		zap.Int("actual", verr.Actual),
+		zap.String("client", client),
+		zap.String("client_version", version))
+}
+
+// checkArguments checks the arguments it recognizes. Arguments of an
+// unexpected type are left to the tool's input schema.
+func checkArguments(limits validation.Limits, raw json.RawMessage) *validation.Error {
+	var args map[string]json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &args) != nil {
+		return nil
+	}
+	verr, _ := validation.As(checkArgumentValues(limits, args))
+	return verr
+}
+
+func checkArgumentValues(limits validation.Limits, args map[string]json.RawMessage) error {
+	for _, name := range queryArguments {
+		var value string
+		if json.Unmarshal(args[name], &value) != nil {
+			continue
+		}
+		if err := limits.Query(name, value); err != nil {
+			return err
+		}
+	}
+	for _, name := range contentArguments {
+		var value string
+		if json.Unmarshal(args[name], &value) != nil {
+			continue
+		}
+		if err := limits.Content(name, value); err != nil {
+			return err
+		}
+	}
+
+	var tags []string
+	if json.Unmarshal(args["tags"], &tags) == nil {
+		if err := limits.Tags(tags); err != nil {
+			return err
+		}
+	}
+	var metadata map[string]json.RawMessage
+	if json.Unmarshal(args["metadata"], &metadata) == nil {
+		return limits.Metadata("metadata", len(metadata))
+	}
+	return nil
+}
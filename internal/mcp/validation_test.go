@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/folding"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+)
+
+func TestLimitsForTool(t *testing.T) {
+	assert.Equal(t, validation.Memory, limitsForTool("memory_search"))
+	assert.Equal(t, validation.Remediation, limitsForTool("remediation_record"))
+	assert.Equal(t, validation.Checkpoint, limitsForTool("checkpoint_save"))
+	assert.Equal(t, validation.Repository, limitsForTool("semantic_search"))
+	assert.Equal(t, validation.Conversation, limitsForTool("conversation_search"))
+	assert.Equal(t, validation.Default, limitsForTool("knowledge_search"))
+}
+
+func TestCheckArguments(t *testing.T) {
+	limits := validation.Limits{MaxQueryLength: 5, MaxContentLength: 10, MaxTags: 2, MaxTagLength: 3, MaxMetadataKeys: 1}
+
+	tests := []struct {
+		name  string
+		args  string
+		code  validation.Code
+		field string
+	}{
+		{"within limits", `{"query":"hello","content":"short","tags":["a"],"metadata":{"k":"v"}}`, "", ""},
+		{"query too long", `{"query":"hello!"}`, validation.CodeQueryTooLong, "query"},
+		{"task too long", `{"task":"refactor"}`, validation.CodeQueryTooLong, "task"},
+		{"content too long", `{"solution":"` + strings.Repeat("x", 11) + `"}`, validation.CodeContentTooLong, "solution"},
+		{"too many tags", `{"tags":["a","b","c"]}`, validation.CodeTooManyTags, "tags"},
+		{"too many metadata keys", `{"metadata":{"a":1,"b":2}}`, validation.CodeTooManyMetadataKeys, "metadata"},
+		{"unexpected types are ignored", `{"query":42,"tags":"a,b,c"}`, "", ""},
+		{"empty arguments", ``, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verr := checkArguments(limits, json.RawMessage(tt.args))
+			if tt.code == "" {
+				assert.Nil(t, verr)
+				return
+			}
+			require.NotNil(t, verr)
+			assert.Equal(t, tt.code, verr.Code)
+			assert.Equal(t, tt.field, verr.Field)
+		})
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{logger: zap.NewNop()}
+
+	called := false
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	server.AddReceivingMiddleware(s.validateArguments)
+	mcp.AddTool(server, &mcp.Tool{Name: "memory_search"}, func(ctx context.Context, req *mcp.CallToolRequest, args formatTestInput) (*mcp.CallToolResult, formatTestOutput, error) {
+		called = true
+		return nil, formatTestOutput{ID: "abc"}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	query := strings.Repeat("x", validation.Memory.MaxQueryLength+1)
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "memory_search", Arguments: map[string]any{"query": query}})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	assert.False(t, called)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "[ERR_VALIDATION_QUERY_TOO_LONG] query exceeds maximum length of 2000 characters")
+
+	meta, err := json.Marshal(res.Meta["error"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"code": "ERR_VALIDATION_QUERY_TOO_LONG",
+		"message": "[ERR_VALIDATION_QUERY_TOO_LONG] query exceeds maximum length of 2000 characters (got 2001)",
+		"retryable": false,
+		"details": {"field": "query", "limit": 2000, "actual": 2001}
+	}`, string(meta))
+
+	res, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "memory_search", Arguments: map[string]any{"query": "q"}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.True(t, called)
+}
+
+func TestValidateArguments_SizeAdvisory(t *testing.T) {
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	metrics := &Metrics{meter: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter(instrumentationName), logger: zap.NewNop()}
+	metrics.init()
+	s := &Server{logger: zap.NewNop(), metrics: metrics, foldingSvc: &folding.BranchManager{}}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	server.AddReceivingMiddleware(s.validateArguments)
+	mcp.AddTool(server, &mcp.Tool{Name: "memory_record"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, formatTestOutput, error) {
+		return nil, formatTestOutput{ID: "abc"}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "noisy-agent", Version: "1.2.3"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	content := strings.Repeat("x", validation.Memory.MaxContentLength+1)
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "memory_record", Arguments: map[string]any{"content": content}})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	text := res.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "[ERR_VALIDATION_CONTENT_TOO_LONG] content exceeds maximum length of 50000 characters")
+	assert.Contains(t, text, "- compress: Summarize content to at most 50000 characters")
+	assert.Contains(t, text, "- fold (branch_create):")
+
+	advisory, err := json.Marshal(res.Meta["advisory"])
+	require.NoError(t, err)
+	var got sizeAdvisory
+	require.NoError(t, json.Unmarshal(advisory, &got))
+	assert.Equal(t, "memory_record", got.Tool)
+	assert.Equal(t, "content", got.Field)
+	assert.Equal(t, 50000, got.Limit)
+	assert.Equal(t, 50001, got.Actual)
+	assert.Equal(t, validation.Memory.MaxQueryLength, got.Limits.MaxQueryLength)
+	require.Len(t, got.Suggestions, 2)
+	assert.Equal(t, "compress", got.Suggestions[0].Action)
+	assert.Equal(t, "fold", got.Suggestions[1].Action)
+	assert.Equal(t, "branch_create", got.Suggestions[1].Tool)
+	assert.NotNil(t, res.Meta["error"], "the error payload is still attached")
+
+	// Limits other than length get no advisory and are not counted
+	res, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "memory_record", Arguments: map[string]any{"tags": make([]string, 21)}})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	assert.Nil(t, res.Meta["advisory"])
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	var points []metricdata.DataPoint[int64]
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "contextd.mcp.tool.oversized_inputs_total" {
+				points = m.Data.(metricdata.Sum[int64]).DataPoints
+			}
+		}
+	}
+	require.Len(t, points, 1)
+	assert.Equal(t, int64(1), points[0].Value)
+	clientName, _ := points[0].Attributes.Value("client")
+	assert.Equal(t, "noisy-agent", clientName.AsString())
+}
+
+func TestInputAdvisory(t *testing.T) {
+	s := &Server{logger: zap.NewNop()}
+
+	advisory := s.inputAdvisory("memory_search", validation.Memory, validation.NewError(validation.CodeQueryTooLong, "query", 2000, 2500))
+	require.NotNil(t, advisory)
+	require.Len(t, advisory.Suggestions, 1)
+	assert.Equal(t, "compress", advisory.Suggestions[0].Action)
+
+	// Without context folding only compression is suggested
+	advisory = s.inputAdvisory("memory_record", validation.Memory, validation.NewError(validation.CodeContentTooLong, "content", 50000, 60000))
+	require.NotNil(t, advisory)
+	require.Len(t, advisory.Suggestions, 1)
+	assert.NotContains(t, advisory.text(), "branch_create")
+
+	assert.Nil(t, s.inputAdvisory("memory_record", validation.Memory, validation.NewError(validation.CodeTooManyTags, "tags", 20, 21)))
+}
@@ -0,0 +1,313 @@
+// Package notify posts notable knowledge events to Slack or Matrix.
+//
+// Services report events through small adapters (see sources.go); the
+// Notifier matches each event against the configured routes and delivers it
+// to every matching channel in the background, so a slow or unreachable
+// chat server never delays a tool call. Events are scrubbed of secrets
+// before they leave the process.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/config"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+)
+
+// EventType names a kind of notable event. Routes select events by type.
+type EventType string
+
+const (
+	// EventRemediationRecorded is an active remediation that is org-scoped or
+	// has a confidence above HighConfidence.
+	EventRemediationRecorded EventType = "remediation.recorded"
+
+	// EventMemoryRecorded is a memory recorded with org visibility, shared
+	// with the whole tenant.
+	EventMemoryRecorded EventType = "memory.recorded"
+
+	// EventConsolidationCompleted is a consolidation run that merged memories.
+	EventConsolidationCompleted EventType = "consolidation.completed"
+
+	// EventReflectionReportReady is a generated reflection report.
+	EventReflectionReportReady EventType = "reflection.report_ready"
+
+	// EventDuplicateBurst is a memory recorded again and again within
+	// minutes, with the copies suppressed.
+	EventDuplicateBurst EventType = "memory.duplicate_burst"
+
+	// EventDigestReady is a generated knowledge digest.
+	EventDigestReady EventType = "digest.ready"
+)
+
+// knownEvents are the event types routes may name.
+var knownEvents = map[EventType]bool{
+	EventRemediationRecorded:    true,
+	EventMemoryRecorded:         true,
+	EventConsolidationCompleted: true,
+	EventReflectionReportReady:  true,
+	EventDuplicateBurst:         true,
+	EventDigestReady:            true,
+}
+
+const (
+	// queueSize bounds the events waiting for delivery; more are dropped.
+	queueSize = 100
+
+	// sendTimeout bounds one delivery to one channel.
+	sendTimeout = 10 * time.Second
+)
+
+// Event is a notable event.
+type Event struct {
+	Type   EventType
+	Title  string  // One-line headline
+	Text   string  // Optional body
+	Fields []Field // Key facts, shown as a list
+
+	// Routing attributes
+	TenantID   string
+	ProjectID  string
+	Scope      string  // Remediation scope or memory visibility
+	Confidence float64 // Remediation or memory confidence
+}
+
+// Field is a labelled value shown with an event.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Sink delivers events to one channel.
+type Sink interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// route sends events matching its filters to sinks.
+type route struct {
+	channels      []string
+	events        map[EventType]bool
+	tenants       map[string]bool
+	projects      map[string]bool
+	scopes        map[string]bool
+	minConfidence float64
+}
+
+func (r *route) matches(e Event) bool {
+	if len(r.events) > 0 && !r.events[e.Type] {
+		return false
+	}
+	if len(r.tenants) > 0 && !r.tenants[e.TenantID] {
+		return false
+	}
+	if len(r.projects) > 0 && !r.projects[e.ProjectID] {
+		return false
+	}
+	if len(r.scopes) > 0 && !r.scopes[e.Scope] {
+		return false
+	}
+	return r.minConfidence == 0 || e.Confidence >= r.minConfidence
+}
+
+// Notifier routes events to channels. A nil *Notifier discards events, so
+// callers need not check whether notifications are enabled.
+type Notifier struct {
+	sinks    map[string]Sink
+	routes   []route
+	scrubber secrets.Scrubber
+	logger   *zap.Logger
+
+	queue     chan Event
+	done      chan struct{} // closed by Close
+	stopped   chan struct{} // closed when queued events are delivered
+	closeOnce sync.Once
+}
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithScrubber redacts secrets from event text before it is sent.
+func WithScrubber(s secrets.Scrubber) Option {
+	return func(n *Notifier) {
+		n.scrubber = s
+	}
+}
+
+// WithSink replaces or adds the channel named name. Used by tests.
+func WithSink(name string, s Sink) Option {
+	return func(n *Notifier) {
+		n.sinks[name] = s
+	}
+}
+
+// New creates a Notifier for cfg and starts its delivery goroutine. Call
+// Close to deliver queued events and stop it.
+func New(cfg config.NotificationsConfig, logger *zap.Logger, opts ...Option) (*Notifier, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: sendTimeout}
+	n := &Notifier{
+		sinks:   make(map[string]Sink),
+		logger:  logger,
+		queue:   make(chan Event, queueSize),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if cfg.SlackWebhookURL.IsSet() {
+		n.sinks["slack"] = &SlackSink{WebhookURL: cfg.SlackWebhookURL, Client: client}
+	}
+	for name, ch := range cfg.Channels {
+		switch ch.Type {
+		case "slack":
+			n.sinks[name] = &SlackSink{WebhookURL: ch.WebhookURL, Client: client}
+		case "matrix":
+			n.sinks[name] = &MatrixSink{
+				Homeserver:  ch.Homeserver,
+				RoomID:      ch.RoomID,
+				AccessToken: ch.AccessToken,
+				Client:      client,
+			}
+		}
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	if len(cfg.Routes) == 0 {
+		// Without routes, every event goes to every channel
+		r := route{}
+		for name := range n.sinks {
+			r.channels = append(r.channels, name)
+		}
+		n.routes = []route{r}
+	}
+	for i, rc := range cfg.Routes {
+		r := route{
+			channels:      rc.Channels,
+			events:        make(map[EventType]bool),
+			tenants:       toSet(rc.Tenants),
+			projects:      toSet(rc.Projects),
+			scopes:        toSet(rc.Scopes),
+			minConfidence: rc.MinConfidence,
+		}
+		for _, e := range rc.Events {
+			if !knownEvents[EventType(e)] {
+				return nil, fmt.Errorf("route %d: unknown event %q", i+1, e)
+			}
+			r.events[EventType(e)] = true
+		}
+		n.routes = append(n.routes, r)
+	}
+
+	go n.run()
+	return n, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// Notify queues e for delivery and returns immediately. Events are dropped
+// when the queue is full or the Notifier is closed.
+func (n *Notifier) Notify(e Event) {
+	if n == nil {
+		return
+	}
+	select {
+	case <-n.done:
+		return
+	default:
+	}
+	select {
+	case n.queue <- e:
+	default:
+		n.logger.Warn("notification queue full, dropping event", zap.String("event", string(e.Type)))
+	}
+}
+
+// Close delivers queued events and stops the Notifier.
+func (n *Notifier) Close() {
+	if n == nil {
+		return
+	}
+	n.closeOnce.Do(func() {
+		close(n.done)
+	})
+	<-n.stopped
+}
+
+func (n *Notifier) run() {
+	defer close(n.stopped)
+	for {
+		select {
+		case e := <-n.queue:
+			n.deliver(e)
+		case <-n.done:
+			for {
+				select {
+				case e := <-n.queue:
+					n.deliver(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends e once to every channel of every matching route.
+func (n *Notifier) deliver(e Event) {
+	e = n.scrub(e)
+	sent := make(map[string]bool)
+	for _, r := range n.routes {
+		if !r.matches(e) {
+			continue
+		}
+		for _, name := range r.channels {
+			sink, ok := n.sinks[name]
+			if !ok || sent[name] {
+				continue
+			}
+			sent[name] = true
+
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			err := sink.Send(ctx, e)
+			cancel()
+			if err != nil {
+				n.logger.Warn("failed to send notification",
+					zap.String("channel", name),
+					zap.String("event", string(e.Type)),
+					zap.Error(err))
+			}
+		}
+	}
+}
+
+func (n *Notifier) scrub(e Event) Event {
+	if n.scrubber == nil {
+		return e
+	}
+	e.Title = n.scrubber.Scrub(e.Title).Scrubbed
+	e.Text = n.scrubber.Scrub(e.Text).Scrubbed
+	fields := make([]Field, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = Field{Name: f.Name, Value: n.scrubber.Scrub(f.Value).Scrubbed}
+	}
+	e.Fields = fields
+	return e
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/fyrsmithlabs/contextd/internal/config"
+)
+
+// SlackSink posts events to a Slack incoming webhook.
+type SlackSink struct {
+	WebhookURL config.Secret
+	Client     *http.Client
+}
+
+// Send implements Sink.
+func (s *SlackSink) Send(ctx context.Context, e Event) error {
+	payload, err := json.Marshal(map[string]string{"text": slackText(e)})
+	if err != nil {
+		return fmt.Errorf("marshaling Slack message: %w", err)
+	}
+	return post(ctx, s.Client, http.MethodPost, s.WebhookURL.Value(), "", payload)
+}
+
+// slackText formats e as Slack mrkdwn.
+func slackText(e Event) string {
+	esc := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*", esc.Replace(e.Title))
+	if e.Text != "" {
+		fmt.Fprintf(&b, "\n%s", esc.Replace(e.Text))
+	}
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, "\n• *%s:* %s", esc.Replace(f.Name), esc.Replace(f.Value))
+	}
+	return b.String()
+}
+
+// MatrixSink posts events to a Matrix room as the user owning AccessToken.
+type MatrixSink struct {
+	Homeserver  string
+	RoomID      string
+	AccessToken config.Secret
+	Client      *http.Client
+}
+
+// Send implements Sink.
+func (s *MatrixSink) Send(ctx context.Context, e Event) error {
+	plain, formatted := matrixText(e)
+	payload, err := json.Marshal(map[string]string{
+		"msgtype":        "m.notice",
+		"body":           plain,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling Matrix message: %w", err)
+	}
+
+	// The transaction ID makes a retried request idempotent
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(s.Homeserver, "/"), url.PathEscape(s.RoomID), uuid.NewString())
+	return post(ctx, s.Client, http.MethodPut, endpoint, s.AccessToken.Value(), payload)
+}
+
+// matrixText formats e as plain text and as Matrix HTML.
+func matrixText(e Event) (plain, formatted string) {
+	var p, f strings.Builder
+	p.WriteString(e.Title)
+	fmt.Fprintf(&f, "<strong>%s</strong>", html.EscapeString(e.Title))
+	if e.Text != "" {
+		fmt.Fprintf(&p, "\n%s", e.Text)
+		fmt.Fprintf(&f, "<br>%s", strings.ReplaceAll(html.EscapeString(e.Text), "\n", "<br>"))
+	}
+	if len(e.Fields) > 0 {
+		f.WriteString("<ul>")
+		for _, field := range e.Fields {
+			fmt.Fprintf(&p, "\n- %s: %s", field.Name, field.Value)
+			fmt.Fprintf(&f, "<li><strong>%s:</strong> %s</li>", html.EscapeString(field.Name), html.EscapeString(field.Value))
+		}
+		f.WriteString("</ul>")
+	}
+	return p.String(), f.String()
+}
+
+// post sends payload as JSON. Errors never include the endpoint, which may
+// embed a secret (Slack webhook URLs do).
+func post(ctx context.Context, client *http.Client, method, endpoint, bearer string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return errors.New("invalid notification endpoint")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
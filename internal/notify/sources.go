@@ -0,0 +1,214 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/digest"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/reflection"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// HighConfidence is the confidence above which a recorded remediation is
+// notable regardless of its scope.
+const HighConfidence = 0.9
+
+// maxTextLen bounds the body of a notification.
+const maxTextLen = 500
+
+// maxDigestLen bounds the digest shown in a notification.
+const maxDigestLen = 3000
+
+// Remediations wraps svc so that notable remediations are reported to n when
+// they become active: org-scoped ones, and ones recorded with a confidence
+// above HighConfidence. Pending drafts are reported when confirmed.
+func Remediations(svc remediation.Service, n *Notifier) remediation.Service {
+	if n == nil {
+		return svc
+	}
+	return &remediationNotifier{Service: svc, notifier: n}
+}
+
+type remediationNotifier struct {
+	remediation.Service
+	notifier *Notifier
+}
+
+func (r *remediationNotifier) Record(ctx context.Context, req *remediation.RecordRequest) (*remediation.Remediation, error) {
+	rem, err := r.Service.Record(ctx, req)
+	if err == nil {
+		r.report(rem)
+	}
+	return rem, err
+}
+
+func (r *remediationNotifier) Confirm(ctx context.Context, tenantID, remediationID string) (*remediation.Remediation, error) {
+	rem, err := r.Service.Confirm(ctx, tenantID, remediationID)
+	if err == nil {
+		r.report(rem)
+	}
+	return rem, err
+}
+
+func (r *remediationNotifier) report(rem *remediation.Remediation) {
+	if rem == nil || rem.Status == remediation.StatusPending {
+		return
+	}
+	if rem.Scope != remediation.ScopeOrg && rem.Confidence <= HighConfidence {
+		return
+	}
+	r.notifier.Notify(Event{
+		Type:  EventRemediationRecorded,
+		Title: "Remediation recorded: " + rem.Title,
+		Text:  truncate(rem.Problem, maxTextLen),
+		Fields: []Field{
+			{Name: "Category", Value: string(rem.Category)},
+			{Name: "Confidence", Value: strconv.FormatFloat(rem.Confidence, 'f', 2, 64)},
+			{Name: "Scope", Value: string(rem.Scope)},
+			{Name: "ID", Value: rem.ID},
+		},
+		TenantID:   rem.TenantID,
+		ProjectID:  rem.ProjectPath,
+		Scope:      string(rem.Scope),
+		Confidence: rem.Confidence,
+	})
+}
+
+// MemoryListener reports memories recorded with org visibility to n.
+// Memories without a visibility are visible tenant-wide too, but are the
+// routine case and are not reported, so that channels only hear about
+// memories deliberately shared with the organization.
+func MemoryListener(n *Notifier) reasoningbank.RecordListener {
+	return func(ctx context.Context, memory *reasoningbank.Memory) {
+		if memory.Visibility != vectorstore.VisibilityOrg {
+			return
+		}
+		var tenantID string
+		if tenant, err := vectorstore.TenantFromContext(ctx); err == nil {
+			tenantID = tenant.TenantID
+		}
+		n.Notify(Event{
+			Type:  EventMemoryRecorded,
+			Title: "Memory shared with the organization: " + memory.Title,
+			Text:  truncate(memory.Content, maxTextLen),
+			Fields: []Field{
+				{Name: "Outcome", Value: string(memory.Outcome)},
+				{Name: "Confidence", Value: strconv.FormatFloat(memory.Confidence, 'f', 2, 64)},
+				{Name: "Project", Value: memory.ProjectID},
+				{Name: "ID", Value: memory.ID},
+			},
+			TenantID:   tenantID,
+			ProjectID:  memory.ProjectID,
+			Scope:      string(memory.Visibility),
+			Confidence: memory.Confidence,
+		})
+	}
+}
+
+// ConsolidationListener reports consolidation runs that created memories to n.
+func ConsolidationListener(n *Notifier) reasoningbank.ConsolidationListener {
+	return func(ctx context.Context, projectID string, result *reasoningbank.ConsolidationResult) {
+		n.Notify(Event{
+			Type:  EventConsolidationCompleted,
+			Title: "Memory consolidation completed for " + projectID,
+			Fields: []Field{
+				{Name: "Created", Value: strconv.Itoa(len(result.CreatedMemories))},
+				{Name: "Archived", Value: strconv.Itoa(len(result.ArchivedMemories))},
+				{Name: "Skipped", Value: strconv.Itoa(result.SkippedCount)},
+				{Name: "Duration", Value: result.Duration.Round(time.Millisecond).String()},
+			},
+			ProjectID: projectID,
+		})
+	}
+}
+
+// DuplicateListener reports bursts of duplicate memories to n, one event
+// per burst.
+func DuplicateListener(n *Notifier) reasoningbank.DuplicateListener {
+	return func(ctx context.Context, burst reasoningbank.DuplicateBurst) {
+		n.Notify(Event{
+			Type:  EventDuplicateBurst,
+			Title: "Duplicate memories suppressed: " + burst.Title,
+			Text:  "The same memory was recorded again and again; the copies were not stored. An agent may be stuck in a loop.",
+			Fields: []Field{
+				{Name: "Suppressed", Value: strconv.Itoa(burst.Suppressed)},
+				{Name: "Within", Value: burst.LastSeen.Sub(burst.FirstSeen).Round(time.Second).String()},
+				{Name: "Memory", Value: burst.MemoryID},
+			},
+			TenantID:  burst.TenantID,
+			ProjectID: burst.ProjectID,
+		})
+	}
+}
+
+// Reporter wraps r so that every generated report is announced to n.
+func Reporter(r reflection.Reporter, n *Notifier) reflection.Reporter {
+	if n == nil {
+		return r
+	}
+	return &reportNotifier{Reporter: r, notifier: n}
+}
+
+type reportNotifier struct {
+	reflection.Reporter
+	notifier *Notifier
+}
+
+func (r *reportNotifier) Generate(ctx context.Context, opts reflection.ReportOptions) (*reflection.ReflectionReport, error) {
+	report, err := r.Reporter.Generate(ctx, opts)
+	if err != nil {
+		return report, err
+	}
+
+	period := report.Period.Description
+	if period == "" {
+		period = fmt.Sprintf("%s to %s", report.Period.Start.Format(time.DateOnly), report.Period.End.Format(time.DateOnly))
+	}
+	r.notifier.Notify(Event{
+		Type:  EventReflectionReportReady,
+		Title: "Reflection report ready for " + report.ProjectID,
+		Text:  truncate(report.Summary, maxTextLen),
+		Fields: []Field{
+			{Name: "Period", Value: period},
+			{Name: "Memories", Value: strconv.Itoa(report.Statistics.TotalMemories)},
+			{Name: "Insights", Value: strconv.Itoa(len(report.Insights))},
+			{Name: "ID", Value: report.ID},
+		},
+		ProjectID: report.ProjectID,
+	})
+	return report, nil
+}
+
+// DigestListener posts every scheduled digest to n.
+func DigestListener(n *Notifier) digest.Listener {
+	return func(ctx context.Context, d *digest.Digest, path string) {
+		fields := []Field{
+			{Name: "Memories", Value: strconv.Itoa(d.Memories)},
+			{Name: "Remediations", Value: strconv.Itoa(d.Remediations)},
+		}
+		if path != "" {
+			fields = append(fields, Field{Name: "File", Value: path})
+		}
+		n.Notify(Event{
+			Type: EventDigestReady,
+			Title: fmt.Sprintf("Knowledge digest for %s, %s to %s",
+				d.Scope(), d.Since.Format(time.DateOnly), d.Until.Format(time.DateOnly)),
+			Text:     truncate(d.Markdown, maxDigestLen),
+			Fields:   fields,
+			TenantID: d.TenantID,
+		})
+	}
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
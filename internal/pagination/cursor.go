@@ -0,0 +1,114 @@
+// Package pagination provides opaque cursors for paging through search results.
+//
+// A cursor encodes the offset of the next page and a hash of the query that
+// produced it. Cursors are only valid for the query they were issued for:
+// replaying a cursor against a different query (or different filters) is
+// rejected rather than silently returning an unrelated page.
+//
+// Services page by fetching offset+limit+1 ranked results and slicing, so
+// pages are deterministic as long as the underlying data does not change.
+package pagination
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+const (
+	// MaxOffset bounds how deep a cursor may page. Vector searches fetch
+	// offset+limit results, so deep offsets are expensive.
+	MaxOffset = 1000
+
+	// hashLength is the number of hex characters of the query hash kept in
+	// a cursor.
+	hashLength = 16
+)
+
+var (
+	// ErrInvalidCursor indicates the cursor could not be decoded.
+	ErrInvalidCursor = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid cursor")
+
+	// ErrCursorMismatch indicates the cursor was issued for a different query.
+	ErrCursorMismatch = ctxerrors.New(ctxerrors.CodeInvalidInput, "cursor does not match query")
+)
+
+// cursor is the decoded form of an opaque cursor string.
+type cursor struct {
+	Offset int    `json:"o"`
+	Hash   string `json:"h"`
+}
+
+// QueryHash returns a stable hash of the parts that identify a query,
+// such as the query text, project, and filters. Order matters.
+func QueryHash(parts ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:])[:hashLength]
+}
+
+// Encode returns an opaque cursor pointing at offset for the given query hash.
+func Encode(offset int, hash string) string {
+	data, _ := json.Marshal(cursor{Offset: offset, Hash: hash})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode returns the offset stored in a cursor, verifying that it was issued
+// for the given query hash. An empty cursor decodes to offset 0.
+func Decode(c, hash string) (int, error) {
+	if c == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return 0, ErrInvalidCursor
+	}
+	if cur.Offset < 0 || cur.Offset > MaxOffset {
+		return 0, ErrInvalidCursor
+	}
+	if cur.Hash != hash {
+		return 0, ErrCursorMismatch
+	}
+
+	return cur.Offset, nil
+}
+
+// Paginate returns the page of items starting at offset and the cursor for
+// the next page. items should hold at least offset+limit+1 ranked results
+// when more are available; the next cursor is empty on the last page.
+func Paginate[T any](items []T, offset, limit int, hash string) ([]T, string) {
+	if offset >= len(items) {
+		return []T{}, ""
+	}
+
+	end := offset + limit
+	if end >= len(items) {
+		return items[offset:], ""
+	}
+
+	next := ""
+	if end <= MaxOffset {
+		next = Encode(end, hash)
+	}
+	return items[offset:end], next
+}
+
+// Resume returns the cursor for the item kept places past cursor, for callers
+// that return only the first kept items of the page cursor selects. It is
+// empty when cursor does not match hash or the offset would exceed MaxOffset.
+func Resume(c, hash string, kept int) string {
+	offset, err := Decode(c, hash)
+	if err != nil || offset+kept > MaxOffset {
+		return ""
+	}
+	return Encode(offset+kept, hash)
+}
@@ -0,0 +1,93 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryHash(t *testing.T) {
+	assert.Equal(t, QueryHash("a", "b"), QueryHash("a", "b"))
+	assert.NotEqual(t, QueryHash("a", "b"), QueryHash("b", "a"))
+	assert.NotEqual(t, QueryHash("ab", ""), QueryHash("a", "b"))
+	assert.Len(t, QueryHash("query"), hashLength)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	hash := QueryHash("retry timeout", "proj")
+
+	offset, err := Decode(Encode(20, hash), hash)
+	require.NoError(t, err)
+	assert.Equal(t, 20, offset)
+
+	offset, err = Decode("", hash)
+	require.NoError(t, err)
+	assert.Equal(t, 0, offset)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	hash := QueryHash("q")
+
+	tests := []struct {
+		name   string
+		cursor string
+		want   error
+	}{
+		{"not base64", "!!!", ErrInvalidCursor},
+		{"not json", "bm90LWpzb24", ErrInvalidCursor},
+		{"negative offset", Encode(-1, hash), ErrInvalidCursor},
+		{"offset too deep", Encode(MaxOffset+1, hash), ErrInvalidCursor},
+		{"other query", Encode(10, QueryHash("other")), ErrCursorMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.cursor, hash)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	hash := QueryHash("q")
+	items := []int{0, 1, 2, 3, 4, 5, 6}
+
+	var pages [][]int
+	offset := 0
+	for {
+		page, next := Paginate(items, offset, 3, hash)
+		pages = append(pages, page)
+		if next == "" {
+			break
+		}
+		var err error
+		offset, err = Decode(next, hash)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}, {6}}, pages)
+
+	page, next := Paginate(items, 10, 3, hash)
+	assert.Empty(t, page)
+	assert.Empty(t, next)
+
+	page, next = Paginate(items, 4, 3, hash)
+	assert.Equal(t, []int{4, 5, 6}, page)
+	assert.Empty(t, next, "exact final page should not emit a cursor")
+}
+
+func TestResume(t *testing.T) {
+	hash := QueryHash("q")
+
+	offset, err := Decode(Resume("", hash, 2), hash)
+	require.NoError(t, err)
+	assert.Equal(t, 2, offset, "first page")
+
+	offset, err = Decode(Resume(Encode(10, hash), hash, 3), hash)
+	require.NoError(t, err)
+	assert.Equal(t, 13, offset)
+
+	assert.Empty(t, Resume(Encode(10, QueryHash("other")), hash, 3), "other query")
+	assert.Empty(t, Resume(Encode(MaxOffset, hash), hash, 1), "past MaxOffset")
+}
@@ -24,6 +24,20 @@ const (
 
 	// CollectionCodebase stores code embeddings.
 	CollectionCodebase CollectionType = "codebase"
+
+	// CollectionProposals stores pending memory consolidation proposals.
+	CollectionProposals CollectionType = "proposals"
+
+	// CollectionMemoryChunks stores chunk vectors for long memories.
+	CollectionMemoryChunks CollectionType = "memory_chunks"
+
+	// CollectionSettings stores per-project settings, such as a tuned
+	// consolidation threshold.
+	CollectionSettings CollectionType = "settings"
+
+	// CollectionAudit stores the audit log of reviewed changes to a
+	// project's memories, such as undone consolidations.
+	CollectionAudit CollectionType = "audit"
 )
 
 // GetCollectionName returns the collection name for a project and type.
@@ -65,6 +79,10 @@ func GetAllCollectionNames(projectID string) ([]string, error) {
 		CollectionRemediations,
 		CollectionSessions,
 		CollectionCodebase,
+		CollectionProposals,
+		CollectionMemoryChunks,
+		CollectionSettings,
+		CollectionAudit,
 	}
 
 	names := make([]string, 0, len(types))
@@ -104,7 +104,7 @@ func TestGetAllCollectionNames(t *testing.T) {
 		{
 			name:      "valid project ID",
 			projectID: projectID,
-			wantCount: 5, // memories, checkpoints, remediations, sessions, codebase
+			wantCount: 9, // memories, checkpoints, remediations, sessions, codebase, proposals, memory_chunks, settings, audit
 			wantErr:   false,
 		},
 		{
@@ -128,7 +128,7 @@ func TestGetAllCollectionNames(t *testing.T) {
 				}
 
 				// Verify all expected collections are present (using sanitized ID)
-				expectedSuffixes := []string{"memories", "checkpoints", "remediations", "sessions", "codebase"}
+				expectedSuffixes := []string{"memories", "checkpoints", "remediations", "sessions", "codebase", "proposals", "memory_chunks", "settings", "audit"}
 				for _, suffix := range expectedSuffixes {
 					expected := sanitizedID + "_" + suffix
 					found := false
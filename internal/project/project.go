@@ -5,11 +5,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 // Common errors.
 var (
-	ErrProjectNotFound    = errors.New("project not found")
+	ErrProjectNotFound    = ctxerrors.NotFound("project not found")
 	ErrProjectExists      = errors.New("project already exists")
 	ErrInvalidProjectID   = errors.New("invalid project ID")
 	ErrInvalidProjectName = errors.New("invalid project name")
@@ -0,0 +1,92 @@
+// Package readonly provides the server-wide emergency read-only switch.
+//
+// In read-only mode contextd rejects writes (recording memories, saving
+// checkpoints, indexing) while search and resume keep working. It is meant
+// for migrations, disk-full conditions and store corruption investigations.
+// The mode can be set at startup (flag or environment) and toggled at runtime
+// through the HTTP admin API.
+//
+// A nil *Mode is valid and always writable, so components that were not
+// given a mode behave as before.
+package readonly
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// ErrReadOnly is returned for write operations while read-only mode is on.
+var ErrReadOnly = ctxerrors.New(ctxerrors.CodeReadOnly, "contextd is in read-only mode; write operations are disabled")
+
+// Status describes the current mode.
+type Status struct {
+	Enabled bool      `json:"enabled"`
+	Reason  string    `json:"reason,omitempty"`
+	Since   time.Time `json:"since,omitempty"`
+}
+
+// Mode is the read-only switch shared by all entry points. It is safe for
+// concurrent use.
+type Mode struct {
+	mu     sync.RWMutex
+	status Status
+}
+
+// New creates a Mode, optionally already enabled.
+func New(enabled bool, reason string) *Mode {
+	m := &Mode{}
+	m.Set(enabled, reason)
+	return m
+}
+
+// Enabled reports whether writes are currently rejected.
+func (m *Mode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Enabled
+}
+
+// Set turns read-only mode on or off. The reason is shown in errors and
+// status; it is cleared when the mode is turned off.
+func (m *Mode) Set(enabled bool, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !enabled {
+		m.status = Status{}
+		return
+	}
+	if !m.status.Enabled {
+		m.status.Since = time.Now()
+	}
+	m.status.Enabled = true
+	m.status.Reason = reason
+}
+
+// Status returns a snapshot of the current mode.
+func (m *Mode) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Check returns an error wrapping ErrReadOnly if operation must be rejected.
+func (m *Mode) Check(operation string) error {
+	status := m.Status()
+	if !status.Enabled {
+		return nil
+	}
+	if status.Reason != "" {
+		return fmt.Errorf("%s rejected: %w (reason: %s)", operation, ErrReadOnly, status.Reason)
+	}
+	return fmt.Errorf("%s rejected: %w", operation, ErrReadOnly)
+}
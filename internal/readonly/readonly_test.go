@@ -0,0 +1,43 @@
+package readonly
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMode(t *testing.T) {
+	m := New(false, "")
+	assert.False(t, m.Enabled())
+	assert.NoError(t, m.Check("memory_record"))
+
+	m.Set(true, "disk full")
+	assert.True(t, m.Enabled())
+	status := m.Status()
+	assert.Equal(t, "disk full", status.Reason)
+	assert.False(t, status.Since.IsZero())
+
+	err := m.Check("memory_record")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrReadOnly))
+	assert.Contains(t, err.Error(), "memory_record")
+	assert.Contains(t, err.Error(), "disk full")
+
+	// Updating the reason keeps the original start time
+	m.Set(true, "migration")
+	assert.Equal(t, status.Since, m.Status().Since)
+	assert.Equal(t, "migration", m.Status().Reason)
+
+	m.Set(false, "ignored")
+	assert.Equal(t, Status{}, m.Status())
+	assert.NoError(t, m.Check("memory_record"))
+}
+
+func TestMode_Nil(t *testing.T) {
+	var m *Mode
+	assert.False(t, m.Enabled())
+	assert.NoError(t, m.Check("checkpoint_save"))
+	assert.Equal(t, Status{}, m.Status())
+}
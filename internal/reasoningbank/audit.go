@@ -0,0 +1,157 @@
+package reasoningbank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// maxAuditEntries caps how many audit entries are read for a project from
+// stores that cannot scroll.
+const maxAuditEntries = 1000
+
+// AuditAction is a kind of change recorded in a project's audit log.
+type AuditAction string
+
+const (
+	// AuditConsolidationUndone is a consolidation reverted by Distiller.Undo.
+	AuditConsolidationUndone AuditAction = "consolidation_undone"
+)
+
+// AuditEntry records a change to a project's memories that reviewers may
+// need to trace later.
+type AuditEntry struct {
+	ID        string      `json:"id"`
+	ProjectID string      `json:"project_id"`
+	Action    AuditAction `json:"action"`
+
+	// MemoryID is the memory the action was applied to.
+	MemoryID string `json:"memory_id"`
+
+	// RelatedIDs are other memories the action changed, such as the sources
+	// an undone consolidation restored.
+	RelatedIDs []string `json:"related_ids,omitempty"`
+
+	// Detail is a free-form explanation, such as why a consolidation was
+	// undone.
+	Detail string `json:"detail,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditLog returns a project's audit entries, newest first.
+func (s *Service) AuditLog(ctx context.Context, projectID string) ([]AuditEntry, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	store, collectionName, err := s.getCollectionStore(ctx, projectID, project.CollectionAudit)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = s.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("checking collection existence: %w", err)
+	}
+	if !exists {
+		return []AuditEntry{}, nil
+	}
+
+	entries := []AuditEntry{}
+	collect := func(results []vectorstore.SearchResult) error {
+		for _, r := range results {
+			raw, _ := r.Metadata["entry"].(string)
+			var e AuditEntry
+			if err := json.Unmarshal([]byte(raw), &e); err != nil {
+				s.logger.Warn("skipping invalid audit entry",
+					zap.String("id", r.ID),
+					zap.Error(err))
+				continue
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	}
+
+	err = vectorstore.Scroll(ctx, store, collectionName, DefaultStreamBatchSize, nil, collect)
+	if errors.Is(err, vectorstore.ErrScrollUnsupported) {
+		var results []vectorstore.SearchResult
+		results, err = store.SearchInCollection(ctx, collectionName, "audit entry", maxAuditEntries, nil)
+		if err == nil {
+			err = collect(results)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing audit entries: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+// recordAudit appends entry to its project's audit log, filling in its ID
+// and CreatedAt.
+func (s *Service) recordAudit(ctx context.Context, entry *AuditEntry) error {
+	if entry.ProjectID == "" {
+		return ErrEmptyProjectID
+	}
+	store, collectionName, err := s.getCollectionStore(ctx, entry.ProjectID, project.CollectionAudit)
+	if err != nil {
+		return err
+	}
+	ctx, err = s.withTenant(ctx, entry.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("checking collection existence: %w", err)
+	}
+	if !exists {
+		if err := store.CreateCollection(ctx, collectionName, 0); err != nil {
+			return fmt.Errorf("creating collection: %w", err)
+		}
+	}
+
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now()
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+	_, err = store.AddDocuments(ctx, []vectorstore.Document{{
+		ID:         entry.ID,
+		Content:    string(entry.Action) + " " + entry.MemoryID,
+		Collection: collectionName,
+		Metadata: map[string]interface{}{
+			"id":         entry.ID,
+			"project_id": entry.ProjectID,
+			"action":     string(entry.Action),
+			"memory_id":  entry.MemoryID,
+			"entry":      string(raw),
+		},
+	}})
+	if err != nil {
+		return fmt.Errorf("storing audit entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// ChunkThreshold is the content length, in bytes, above which a memory
+	// is also stored as chunk vectors. Shorter memories embed well as a
+	// single vector.
+	ChunkThreshold = 1500
+
+	// chunkSize is the target chunk length in bytes.
+	chunkSize = 800
+
+	// maxChunksPerMemory caps the chunk vectors stored for one memory.
+	// Content past the last chunk is only reachable via the parent vector.
+	maxChunksPerMemory = 32
+)
+
+var wordPattern = regexp.MustCompile(`\S+`)
+
+// ChunkHighlight identifies the part of a memory's content that best matched
+// a search. Start and End are byte offsets into Memory.Content.
+type ChunkHighlight struct {
+	Index int     `json:"index"`
+	Start int     `json:"start"`
+	End   int     `json:"end"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// chunkSpan is a chunk's byte range within memory content.
+type chunkSpan struct {
+	start, end int
+}
+
+// chunkMatch is the best-scoring chunk of a parent memory in a search.
+type chunkMatch struct {
+	index int
+	span  chunkSpan
+	score float32
+}
+
+// splitIntoChunks splits content into spans of roughly size bytes. Spans
+// break between words, preferring paragraph breaks once a chunk is at least
+// half full, so offsets always fall on rune boundaries.
+func splitIntoChunks(content string, size int) []chunkSpan {
+	words := wordPattern.FindAllStringIndex(content, -1)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var spans []chunkSpan
+	cur := chunkSpan{start: words[0][0], end: words[0][1]}
+	for _, w := range words[1:] {
+		paragraphBreak := strings.Contains(content[cur.end:w[0]], "\n\n") && cur.end-cur.start >= size/2
+		if w[1]-cur.start > size || paragraphBreak {
+			spans = append(spans, cur)
+			if len(spans) == maxChunksPerMemory {
+				return spans
+			}
+			cur = chunkSpan{start: w[0], end: w[1]}
+			continue
+		}
+		cur.end = w[1]
+	}
+	return append(spans, cur)
+}
+
+// chunkID derives a stable, UUID-formatted ID for a memory chunk so stores
+// that require UUID IDs accept it.
+func chunkID(memoryID string, index int) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(memoryID+"#"+strconv.Itoa(index))).String()
+}
+
+// recordChunks replaces the chunk vectors for memory. Archived and
+// quarantined memories and memories under ChunkThreshold get none.
+func (s *Service) recordChunks(ctx context.Context, memory *Memory) error {
+	store, collectionName, err := s.getCollectionStore(ctx, memory.ProjectID, project.CollectionMemoryChunks)
+	if err != nil {
+		return err
+	}
+	if err := s.deleteChunks(ctx, store, collectionName, memory.ID); err != nil {
+		return err
+	}
+	if len(memory.Content) <= ChunkThreshold || memory.State != MemoryStateActive {
+		return nil
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("checking chunk collection: %w", err)
+	}
+	if !exists {
+		if err := store.CreateCollection(ctx, collectionName, 0); err != nil {
+			return fmt.Errorf("creating chunk collection: %w", err)
+		}
+	}
+
+	docs := chunkDocuments(memory, collectionName)
+	if _, err := store.AddDocuments(ctx, docs); err != nil {
+		return fmt.Errorf("storing memory chunks: %w", err)
+	}
+
+	s.logger.Debug("memory chunks recorded",
+		zap.String("id", memory.ID),
+		zap.Int("chunks", len(docs)))
+	return nil
+}
+
+// chunkDocuments returns the chunk vectors of memory in collectionName.
+// Callers check ChunkThreshold and the memory's state.
+func chunkDocuments(memory *Memory, collectionName string) []vectorstore.Document {
+	spans := splitIntoChunks(memory.Content, chunkSize)
+	docs := make([]vectorstore.Document, 0, len(spans))
+	for i, span := range spans {
+		metadata := map[string]interface{}{
+			"parent_id":   memory.ID,
+			"project_id":  memory.ProjectID,
+			"chunk_index": i,
+			"chunk_start": span.start,
+			"chunk_end":   span.end,
+		}
+		// Chunks carry the memory's access label so they are not a way
+		// around it
+		if memory.Visibility != "" {
+			metadata[vectorstore.VisibilityKey] = string(memory.Visibility)
+		}
+		if memory.OwnerID != "" {
+			metadata[vectorstore.OwnerKey] = memory.OwnerID
+		}
+		if memory.OwnerTeam != "" {
+			metadata[vectorstore.OwnerTeamKey] = memory.OwnerTeam
+		}
+		docs = append(docs, vectorstore.Document{
+			ID: chunkID(memory.ID, i),
+			// The title gives each chunk the memory's context
+			Content:    fmt.Sprintf("%s\n\n%s", memory.Title, memory.Content[span.start:span.end]),
+			Metadata:   metadata,
+			Collection: collectionName,
+		})
+	}
+	return docs
+}
+
+// deleteChunks removes all chunk vectors for a memory. Chunks are found by
+// their parent_id label rather than by a search, which only returns the
+// nearest matches.
+func (s *Service) deleteChunks(ctx context.Context, store vectorstore.Store, collectionName, memoryID string) error {
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil || !exists {
+		return err
+	}
+
+	var ids []string
+	err = vectorstore.Scroll(ctx, store, collectionName, DefaultStreamBatchSize, map[string]interface{}{
+		"parent_id": memoryID,
+	}, func(results []vectorstore.SearchResult) error {
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return nil
+	})
+	if errors.Is(err, vectorstore.ErrScrollUnsupported) {
+		// Chunk IDs are derived from the parent, so every possible one can
+		// be deleted instead
+		ids = make([]string, 0, maxChunksPerMemory)
+		for i := 0; i < maxChunksPerMemory; i++ {
+			ids = append(ids, chunkID(memoryID, i))
+		}
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("finding memory chunks: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := store.DeleteDocumentsFromCollection(ctx, collectionName, ids); err != nil {
+		return fmt.Errorf("deleting memory chunks: %w", err)
+	}
+	return nil
+}
+
+// deleteProjectChunks removes chunk vectors for a memory in projectID.
+func (s *Service) deleteProjectChunks(ctx context.Context, projectID, memoryID string) error {
+	store, collectionName, err := s.getCollectionStore(ctx, projectID, project.CollectionMemoryChunks)
+	if err != nil {
+		return err
+	}
+	return s.deleteChunks(ctx, store, collectionName, memoryID)
+}
+
+// mergeChunkMatches folds chunk-level matches into memory search results.
+//
+// Each parent memory takes the better of its own score and its best chunk's
+// score. Parents found only through a chunk are fetched and appended. The
+// returned map holds the best chunk per parent for highlighting. Chunk
+// search failures are logged and leave results unchanged.
+func (s *Service) mergeChunkMatches(
+	ctx context.Context,
+	store vectorstore.Store,
+	collectionName, projectID, query string,
+	k int,
+	results []vectorstore.SearchResult,
+) ([]vectorstore.SearchResult, map[string]chunkMatch) {
+	matches, err := s.searchChunks(ctx, projectID, query, k)
+	if err != nil {
+		s.logger.Warn("memory chunk search failed",
+			zap.String("project_id", projectID),
+			zap.Error(err))
+		return results, nil
+	}
+	if len(matches) == 0 {
+		return results, nil
+	}
+
+	found := make(map[string]bool, len(results))
+	for i := range results {
+		id := results[i].ID
+		found[id] = true
+		if m, ok := matches[id]; ok && m.score > results[i].Score {
+			results[i].Score = m.score
+		}
+	}
+
+	for parentID, m := range matches {
+		if found[parentID] {
+			continue
+		}
+		parents, err := store.SearchInCollection(ctx, collectionName, "dummy", 1, map[string]interface{}{
+			"id": parentID,
+		})
+		if err != nil || len(parents) == 0 {
+			// Orphaned chunk; the parent was deleted outside this service
+			delete(matches, parentID)
+			continue
+		}
+		parent := parents[0]
+		parent.Score = m.score
+		results = append(results, parent)
+	}
+
+	return results, matches
+}
+
+// searchChunks returns the best-matching chunk per parent memory.
+func (s *Service) searchChunks(ctx context.Context, projectID, query string, k int) (map[string]chunkMatch, error) {
+	store, collectionName, err := s.getCollectionStore(ctx, projectID, project.CollectionMemoryChunks)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	results, err := store.SearchInCollection(ctx, collectionName, query, k, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[string]chunkMatch)
+	for _, r := range results {
+		parentID, _ := r.Metadata["parent_id"].(string)
+		if parentID == "" {
+			continue
+		}
+		if best, ok := matches[parentID]; ok && best.score >= r.Score {
+			continue
+		}
+		matches[parentID] = chunkMatch{
+			index: int(parseInt64(r.Metadata["chunk_index"])),
+			span: chunkSpan{
+				start: int(parseInt64(r.Metadata["chunk_start"])),
+				end:   int(parseInt64(r.Metadata["chunk_end"])),
+			},
+			score: r.Score,
+		}
+	}
+	return matches, nil
+}
+
+// highlight builds the ChunkHighlight for a memory, or nil if the chunk no
+// longer lines up with the memory's content.
+func (m chunkMatch) highlight(memory *Memory) *ChunkHighlight {
+	if m.span.start < 0 || m.span.end > len(memory.Content) || m.span.start >= m.span.end {
+		return nil
+	}
+	return &ChunkHighlight{
+		Index: m.index,
+		Start: m.span.start,
+		End:   m.span.end,
+		Text:  memory.Content[m.span.start:m.span.end],
+		Score: float64(m.score),
+	}
+}
@@ -0,0 +1,150 @@
+package reasoningbank
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// keywordEmbedder embeds text as keyword presence, so a chunk mentioning
+// only the query keyword scores higher than a parent mentioning several.
+type keywordEmbedder struct{ keywords []string }
+
+func (e *keywordEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i], _ = e.EmbedQuery(ctx, text)
+	}
+	return out, nil
+}
+
+func (e *keywordEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	v := make([]float32, len(e.keywords)+1)
+	v[0] = 0.1
+	for i, kw := range e.keywords {
+		if strings.Contains(strings.ToLower(text), kw) {
+			v[i+1] = 1
+		}
+	}
+	return v, nil
+}
+
+func TestSplitIntoChunks(t *testing.T) {
+	t.Run("short content is one chunk", func(t *testing.T) {
+		spans := splitIntoChunks("  just a few words  ", 100)
+		require.Len(t, spans, 1)
+		assert.Equal(t, chunkSpan{start: 2, end: 18}, spans[0])
+	})
+
+	t.Run("breaks between words within size", func(t *testing.T) {
+		content := strings.Repeat("héllo wörld ", 50)
+		spans := splitIntoChunks(content, 100)
+		require.Greater(t, len(spans), 1)
+		for i, span := range spans {
+			assert.LessOrEqual(t, span.end-span.start, 100)
+			assert.True(t, utf8.ValidString(content[span.start:span.end]))
+			if i > 0 {
+				assert.Greater(t, span.start, spans[i-1].end)
+			}
+		}
+		assert.Equal(t, strings.TrimSpace(content), content[spans[0].start:spans[len(spans)-1].end])
+	})
+
+	t.Run("prefers paragraph breaks", func(t *testing.T) {
+		first := strings.Repeat("a ", 30)
+		content := first + "\n\n" + strings.Repeat("b ", 30)
+		spans := splitIntoChunks(content, 100)
+		require.Len(t, spans, 2)
+		assert.Equal(t, strings.TrimSpace(first), content[spans[0].start:spans[0].end])
+	})
+
+	t.Run("caps chunk count", func(t *testing.T) {
+		spans := splitIntoChunks(strings.Repeat("word ", 10000), 20)
+		assert.Len(t, spans, maxChunksPerMemory)
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		assert.Empty(t, splitIntoChunks(" \n ", 100))
+	})
+}
+
+func TestService_ChunkedMemories(t *testing.T) {
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "chunks", ProjectID: "chunks"})
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 3,
+	}, &keywordEmbedder{keywords: []string{"database", "kubernetes"}}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("chunks"))
+	require.NoError(t, err)
+
+	content := strings.Repeat("Tune the database indexes before scaling reads. ", 40) +
+		"\n\nFinally, raise the kubernetes pod memory limit."
+	require.Greater(t, len(content), ChunkThreshold)
+	long, err := NewMemory("chunks", "Scaling notes", content, OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.Record(ctx, long))
+
+	short, err := NewMemory("chunks", "Short note", "database vacuum", OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.Record(ctx, short))
+
+	chunkStore, chunkCollection, err := svc.getCollectionStore(ctx, "chunks", project.CollectionMemoryChunks)
+	require.NoError(t, err)
+	chunkCount := func(parentID string) int {
+		results, err := chunkStore.SearchInCollection(ctx, chunkCollection, "chunk", 100, map[string]interface{}{"parent_id": parentID})
+		require.NoError(t, err)
+		return len(results)
+	}
+	assert.Equal(t, len(splitIntoChunks(content, chunkSize)), chunkCount(long.ID))
+	assert.Zero(t, chunkCount(short.ID))
+
+	t.Run("search highlights best chunk", func(t *testing.T) {
+		results, err := svc.SearchWithScores(ctx, "chunks", "kubernetes", 5)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, long.ID, results[0].Memory.ID)
+
+		hl := results[0].Highlight
+		require.NotNil(t, hl)
+		assert.Contains(t, hl.Text, "kubernetes")
+		assert.Equal(t, hl.Text, results[0].Memory.Content[hl.Start:hl.End])
+		assert.GreaterOrEqual(t, results[0].Relevance, hl.Score)
+	})
+
+	t.Run("chunk match recovers parent missing from results", func(t *testing.T) {
+		memStore, collection, err := svc.getStore(ctx, "chunks")
+		require.NoError(t, err)
+
+		results, matches := svc.mergeChunkMatches(ctx, memStore, collection, "chunks", "kubernetes", 10, nil)
+		require.Len(t, results, 1)
+		assert.Equal(t, long.ID, results[0].ID)
+		assert.Contains(t, matches, long.ID)
+	})
+
+	t.Run("archive removes chunks", func(t *testing.T) {
+		archived, err := NewMemory("chunks", "Archived notes", content, OutcomeSuccess, nil)
+		require.NoError(t, err)
+		require.NoError(t, svc.Record(ctx, archived))
+		require.NotZero(t, chunkCount(archived.ID))
+
+		require.NoError(t, svc.pruneMemory(ctx, "chunks", archived.ID, PruneArchive))
+		assert.Zero(t, chunkCount(archived.ID))
+		assert.NotZero(t, chunkCount(long.ID), "other memories keep their chunks")
+	})
+
+	t.Run("delete removes chunks", func(t *testing.T) {
+		require.NoError(t, svc.DeleteByProjectID(ctx, "chunks", long.ID))
+		assert.Zero(t, chunkCount(long.ID))
+	})
+}
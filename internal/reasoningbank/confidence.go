@@ -37,6 +37,9 @@ func ComputeConfidenceFromHybrid(agg *SignalAggregate, recentSignals []Signal, w
 	// Add recent signal contributions
 	for _, sig := range recentSignals {
 		w := weights.WeightFor(sig.Type)
+		if sig.Evidence != "" {
+			w *= EvidenceWeight
+		}
 		if sig.Positive {
 			alpha += w
 		} else {
@@ -116,6 +116,27 @@ func TestConfidenceCalculator_ComputeFromAggregatesAndRecent(t *testing.T) {
 	assert.Less(t, confidence, 0.9)
 }
 
+func TestComputeConfidenceFromHybrid_EvidenceWeighsMore(t *testing.T) {
+	weights := NewProjectWeights("proj_123")
+	agg := NewSignalAggregate("mem_abc123", "proj_123")
+
+	bare := ComputeConfidenceFromHybrid(agg, []Signal{
+		{Type: SignalExplicit, Positive: true},
+	}, weights)
+	backed := ComputeConfidenceFromHybrid(agg, []Signal{
+		{Type: SignalExplicit, Positive: true, Evidence: "applied the fix, tests pass"},
+	}, weights)
+	assert.Greater(t, backed, bare)
+
+	bareNeg := ComputeConfidenceFromHybrid(agg, []Signal{
+		{Type: SignalExplicit, Positive: false},
+	}, weights)
+	backedNeg := ComputeConfidenceFromHybrid(agg, []Signal{
+		{Type: SignalExplicit, Positive: false, Evidence: "the suggested flag does not exist"},
+	}, weights)
+	assert.Less(t, backedNeg, bareNeg)
+}
+
 func TestSignalStore_PersistAndRetrieve(t *testing.T) {
 	// Test signal storage interface
 	// This tests the interface that will be implemented with vectorstore
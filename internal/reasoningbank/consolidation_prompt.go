@@ -0,0 +1,142 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
+)
+
+// DefaultMaxPromptTokens is the default limit on the estimated tokens of a
+// single consolidation prompt, leaving room in a 200k context window for
+// the response.
+const DefaultMaxPromptTokens = 100_000
+
+// ErrPromptTooLarge is returned when a cluster cannot be consolidated within
+// the prompt token limit: a single memory alone exceeds it, or no two
+// memories fit in one prompt.
+var ErrPromptTooLarge = errors.New("consolidation prompt exceeds token limit")
+
+// synthesize asks the LLM to merge members into one unsaved memory. Clusters
+// whose prompt exceeds the token limit are merged in parts first.
+func (d *Distiller) synthesize(ctx context.Context, projectID string, members []*Memory) (*Memory, error) {
+	prompt := buildConsolidationPrompt(members)
+	tokens := analytics.EstimateTokens(prompt)
+	if tokens > d.maxPromptTokens {
+		return d.synthesizeInParts(ctx, projectID, members, tokens)
+	}
+
+	d.logger.Debug("calling LLM for memory synthesis",
+		zap.String("project_id", projectID),
+		zap.Int("prompt_length", len(prompt)),
+		zap.Int64("prompt_tokens", tokens))
+
+	if d.budget != nil {
+		if err := d.budget.Allow(ctx, analytics.FeatureDistiller); err != nil {
+			return nil, fmt.Errorf("LLM synthesis paused: %w", err)
+		}
+	}
+	var llmResponse string
+	err := d.policy.Do(ctx, analytics.FeatureDistiller, func(ctx context.Context) error {
+		var err error
+		llmResponse, err = d.complete(ctx, prompt)
+		d.recordUsage(ctx, prompt, llmResponse, err)
+		return err
+	})
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		return nil, fmt.Errorf("LLM synthesis paused: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LLM synthesis failed: %w", err)
+	}
+
+	d.logger.Debug("received LLM synthesis response",
+		zap.String("project_id", projectID),
+		zap.Int("response_length", len(llmResponse)))
+
+	sourceIDs := make([]string, len(members))
+	for i, mem := range members {
+		sourceIDs[i] = mem.ID
+	}
+	memory, err := parseConsolidatedMemory(llmResponse, sourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("parsing LLM response: %w", err)
+	}
+	return memory, nil
+}
+
+// synthesizeInParts merges groups of members that fit in one prompt, then
+// merges the partial syntheses in a final pass.
+func (d *Distiller) synthesizeInParts(ctx context.Context, projectID string, members []*Memory, tokens int64) (*Memory, error) {
+	groups, err := d.splitForPrompt(members)
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("consolidation prompt too large, merging in parts",
+		zap.String("project_id", projectID),
+		zap.Int("cluster_size", len(members)),
+		zap.Int64("prompt_tokens", tokens),
+		zap.Int64("max_prompt_tokens", d.maxPromptTokens),
+		zap.Int("parts", len(groups)))
+
+	parts := make([]*Memory, 0, len(groups))
+	for _, group := range groups {
+		if len(group) == 1 {
+			parts = append(parts, group[0])
+			continue
+		}
+		part, err := d.synthesize(ctx, projectID, group)
+		if err != nil {
+			return nil, err
+		}
+		part.ProjectID = projectID
+		part.Confidence = calculateConsolidatedConfidence(group)
+		part.UsageCount = totalUsage(group)
+		parts = append(parts, part)
+	}
+	return d.synthesize(ctx, projectID, parts)
+}
+
+// splitForPrompt splits members, in order, into groups whose consolidation
+// prompt fits the token limit. It fails when a memory alone does not fit,
+// or when every group would hold a single memory, since merging such parts
+// would never shrink the cluster.
+func (d *Distiller) splitForPrompt(members []*Memory) ([][]*Memory, error) {
+	var groups [][]*Memory
+	var current []*Memory
+	for _, mem := range members {
+		if tokens := analytics.EstimateTokens(buildConsolidationPrompt([]*Memory{mem})); tokens > d.maxPromptTokens {
+			return nil, fmt.Errorf("%w: memory %s alone needs %d tokens, limit is %d",
+				ErrPromptTooLarge, mem.ID, tokens, d.maxPromptTokens)
+		}
+		candidate := append(current[:len(current):len(current)], mem)
+		if len(current) > 0 && analytics.EstimateTokens(buildConsolidationPrompt(candidate)) > d.maxPromptTokens {
+			groups = append(groups, current)
+			candidate = []*Memory{mem}
+		}
+		current = candidate
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+
+	if len(groups) == len(members) {
+		return nil, fmt.Errorf("%w: no two of the cluster's %d memories fit in a %d token prompt",
+			ErrPromptTooLarge, len(members), d.maxPromptTokens)
+	}
+	return groups, nil
+}
+
+// totalUsage sums the usage counts of memories.
+func totalUsage(memories []*Memory) int {
+	total := 0
+	for _, mem := range memories {
+		total += mem.UsageCount
+	}
+	return total
+}
@@ -0,0 +1,113 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+)
+
+// newPromptTestMemories records n memories whose content is long enough
+// that the consolidation prompt grows noticeably with each one.
+func newPromptTestMemories(t *testing.T, svc *Service, projectID string, n int) []*Memory {
+	t.Helper()
+	memories := make([]*Memory, n)
+	for i := range memories {
+		content := strings.Repeat(fmt.Sprintf("Retry step %d of the deployment with backoff. ", i), 20)
+		mem, err := NewMemory(projectID, fmt.Sprintf("Deployment retry pattern %d", i), content, OutcomeSuccess, []string{"deploy"})
+		require.NoError(t, err)
+		require.NoError(t, svc.Record(context.Background(), mem))
+		memories[i] = mem
+	}
+	return memories
+}
+
+func TestMergeCluster_SplitsOversizedPrompt(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	mockLLM := newMockLLMClient()
+
+	svc, err := NewService(newMockStore(), logger,
+		WithDefaultTenant("test-tenant"),
+		WithEmbedder(newMockEmbedder(10)))
+	require.NoError(t, err)
+
+	memories := newPromptTestMemories(t, svc, "split-project", 4)
+
+	// Two memories fit in a prompt, three do not
+	limit := analytics.EstimateTokens(buildConsolidationPrompt(memories[:2]))
+	distiller, err := NewDistiller(svc, logger, WithLLMClient(mockLLM), WithMaxPromptTokens(limit))
+	require.NoError(t, err)
+
+	merged, err := distiller.MergeCluster(ctx, &SimilarityCluster{Members: memories})
+	require.NoError(t, err)
+	assert.Equal(t, "split-project", merged.ProjectID)
+	assert.Equal(t, 3, mockLLM.CallCount(), "two sub-merges and a final merge")
+	assert.NotContains(t, mockLLM.lastPrompt, "Deployment retry pattern", "the final merge sees only the partial syntheses")
+
+	for _, mem := range memories {
+		source, err := svc.GetByProjectID(ctx, "split-project", mem.ID)
+		require.NoError(t, err)
+		require.NotNil(t, source.ConsolidationID, "every source links to the final memory")
+		assert.Equal(t, merged.ID, *source.ConsolidationID)
+	}
+}
+
+func TestMergeCluster_MemoryExceedsPromptLimit(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	mockLLM := newMockLLMClient()
+
+	svc, err := NewService(newMockStore(), logger,
+		WithDefaultTenant("test-tenant"),
+		WithEmbedder(newMockEmbedder(10)))
+	require.NoError(t, err)
+
+	memories := newPromptTestMemories(t, svc, "oversized-project", 2)
+
+	// Not even one memory fits
+	limit := analytics.EstimateTokens(buildConsolidationPrompt(nil))
+	distiller, err := NewDistiller(svc, logger, WithLLMClient(mockLLM), WithMaxPromptTokens(limit))
+	require.NoError(t, err)
+
+	_, err = distiller.MergeCluster(ctx, &SimilarityCluster{Members: memories})
+	require.ErrorIs(t, err, ErrPromptTooLarge)
+	assert.Contains(t, err.Error(), memories[0].ID)
+	assert.Zero(t, mockLLM.CallCount())
+}
+
+func TestSplitForPrompt(t *testing.T) {
+	memories := make([]*Memory, 3)
+	for i := range memories {
+		memories[i] = &Memory{
+			ID:      fmt.Sprintf("mem-%d", i),
+			Title:   fmt.Sprintf("Memory %d", i),
+			Content: strings.Repeat("x", 400),
+			Outcome: OutcomeSuccess,
+		}
+	}
+	one := analytics.EstimateTokens(buildConsolidationPrompt(memories[:1]))
+	two := analytics.EstimateTokens(buildConsolidationPrompt(memories[:2]))
+
+	t.Run("groups in order", func(t *testing.T) {
+		d := &Distiller{maxPromptTokens: two}
+		groups, err := d.splitForPrompt(memories)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, []*Memory{memories[0], memories[1]}, groups[0])
+		assert.Equal(t, []*Memory{memories[2]}, groups[1])
+	})
+
+	t.Run("fails when no two memories fit", func(t *testing.T) {
+		d := &Distiller{maxPromptTokens: one}
+		_, err := d.splitForPrompt(memories)
+		require.ErrorIs(t, err, ErrPromptTooLarge)
+		assert.Contains(t, err.Error(), "no two")
+	})
+}
@@ -0,0 +1,127 @@
+package reasoningbank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StructuredLLMClient is an LLMClient that can constrain its output to a
+// JSON schema, with tool use or a JSON response mode. When the Distiller's
+// client implements it, consolidation requests use CompleteJSON instead of
+// Complete.
+type StructuredLLMClient interface {
+	LLMClient
+
+	// CompleteJSON generates a completion for prompt that is a single JSON
+	// object conforming to schema (a JSON Schema document).
+	CompleteJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error)
+}
+
+// ConsolidationSchema is the JSON Schema of a consolidation response. It is
+// passed to StructuredLLMClient.CompleteJSON and described in the prompt
+// for clients without structured output.
+var ConsolidationSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "title": {"type": "string", "description": "A clear, concise title for the consolidated memory"},
+    "content": {"type": "string", "description": "The synthesized content"},
+    "tags": {"type": "array", "items": {"type": "string"}, "description": "Tags that apply to the consolidated knowledge"},
+    "outcome": {"type": "string", "enum": ["success", "failure"], "description": "The predominant outcome of the source memories"},
+    "source_attribution": {"type": "string", "description": "How the source memories contributed to the synthesis"}
+  },
+  "required": ["title", "content", "outcome"],
+  "additionalProperties": false
+}`)
+
+// errNoJSONObject is returned by parseConsolidationJSON for responses that
+// contain no JSON object at all.
+var errNoJSONObject = errors.New("no JSON object in LLM response")
+
+// consolidationResponse is a consolidation response as described by
+// ConsolidationSchema.
+type consolidationResponse struct {
+	Title             string   `json:"title"`
+	Content           string   `json:"content"`
+	Tags              []string `json:"tags"`
+	Outcome           string   `json:"outcome"`
+	SourceAttribution string   `json:"source_attribution"`
+}
+
+// complete asks the LLM to synthesize a consolidated memory, constrained to
+// ConsolidationSchema when the client supports structured output.
+func (d *Distiller) complete(ctx context.Context, prompt string) (string, error) {
+	if c, ok := d.llmClient.(StructuredLLMClient); ok {
+		return c.CompleteJSON(ctx, prompt, ConsolidationSchema)
+	}
+	return d.llmClient.Complete(ctx, prompt)
+}
+
+// parseConsolidationJSON decodes and validates a JSON consolidation
+// response. The object may be wrapped in a Markdown code fence or
+// surrounded by prose. Validation reports every problem at once, so a
+// failure can be traced to the field that drifted.
+func parseConsolidationJSON(llmResponse string) (*consolidationResponse, error) {
+	start := strings.Index(llmResponse, "{")
+	end := strings.LastIndex(llmResponse, "}")
+	if start == -1 || end < start {
+		return nil, errNoJSONObject
+	}
+
+	// Unknown fields are ignored: extra keys are harmless drift
+	var resp consolidationResponse
+	if err := json.Unmarshal([]byte(llmResponse[start:end+1]), &resp); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("invalid consolidation response: field %q must be %s, got %s",
+				typeErr.Field, schemaType(typeErr.Type.Kind().String()), typeErr.Value)
+		}
+		return nil, fmt.Errorf("invalid consolidation response JSON: %w", err)
+	}
+
+	resp.Title = strings.TrimSpace(resp.Title)
+	resp.Content = strings.TrimSpace(resp.Content)
+	resp.Outcome = strings.ToLower(strings.TrimSpace(resp.Outcome))
+	resp.SourceAttribution = strings.TrimSpace(resp.SourceAttribution)
+
+	var problems []string
+	if resp.Title == "" {
+		problems = append(problems, `"title" is required`)
+	}
+	if resp.Content == "" {
+		problems = append(problems, `"content" is required`)
+	}
+	switch resp.Outcome {
+	case "":
+		problems = append(problems, `"outcome" is required`)
+	case string(OutcomeSuccess), string(OutcomeFailure):
+	default:
+		problems = append(problems, fmt.Sprintf(`"outcome" must be "success" or "failure", got %q`, resp.Outcome))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid consolidation response: %s", strings.Join(problems, "; "))
+	}
+
+	tags := resp.Tags[:0]
+	for _, tag := range resp.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	resp.Tags = tags
+	return &resp, nil
+}
+
+// schemaType names a Go kind the way ConsolidationSchema does.
+func schemaType(kind string) string {
+	switch kind {
+	case "slice":
+		return "an array"
+	case "string":
+		return "a string"
+	default:
+		return kind
+	}
+}
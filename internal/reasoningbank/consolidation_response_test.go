@@ -0,0 +1,128 @@
+package reasoningbank
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseConsolidationJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     *consolidationResponse
+		wantErr  string
+	}{
+		{
+			name:     "plain object",
+			response: `{"title": " Wrap errors ", "content": "Use %w.", "tags": ["go", " ", "errors"], "outcome": "Success"}`,
+			want:     &consolidationResponse{Title: "Wrap errors", Content: "Use %w.", Tags: []string{"go", "errors"}, Outcome: "success"},
+		},
+		{
+			name:     "fenced with prose and extra fields",
+			response: "Here is the memory:\n```json\n{\"title\": \"T\", \"content\": \"func f() {}\", \"outcome\": \"failure\", \"confidence\": 0.9, \"source_attribution\": \"both\"}\n```",
+			want:     &consolidationResponse{Title: "T", Content: "func f() {}", Outcome: "failure", SourceAttribution: "both"},
+		},
+		{
+			name:     "reports every missing field",
+			response: `{"tags": []}`,
+			wantErr:  `invalid consolidation response: "title" is required; "content" is required; "outcome" is required`,
+		},
+		{
+			name:     "invalid outcome",
+			response: `{"title": "T", "content": "C", "outcome": "maybe"}`,
+			wantErr:  `"outcome" must be "success" or "failure", got "maybe"`,
+		},
+		{
+			name:     "wrong type",
+			response: `{"title": "T", "content": "C", "outcome": "success", "tags": "go, errors"}`,
+			wantErr:  `field "tags" must be an array, got string`,
+		},
+		{
+			name:     "malformed",
+			response: `{"title": "T",}`,
+			wantErr:  "invalid consolidation response JSON",
+		},
+		{
+			name:     "no object",
+			response: "TITLE: T",
+			wantErr:  errNoJSONObject.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConsolidationJSON(tt.response)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseConsolidatedMemory_JSONAndFallback(t *testing.T) {
+	sourceIDs := []string{"mem-1"}
+
+	memory, err := parseConsolidatedMemory(`{"title": "Wrap errors", "content": "Use %w.", "tags": ["go"], "outcome": "success", "source_attribution": "From two fixes"}`, sourceIDs)
+	require.NoError(t, err)
+	assert.Equal(t, "Wrap errors", memory.Title)
+	assert.Equal(t, "Use %w.", memory.Content)
+	assert.Equal(t, []string{"go"}, memory.Tags)
+	assert.Equal(t, OutcomeSuccess, memory.Outcome)
+	assert.Equal(t, "From two fixes", memory.Description)
+
+	// Text responses whose content contains braces still parse
+	memory, err = parseConsolidatedMemory("TITLE: Closures\n\nCONTENT:\nfunc() { return }\n\nOUTCOME: failure", sourceIDs)
+	require.NoError(t, err)
+	assert.Equal(t, "Closures", memory.Title)
+	assert.Equal(t, OutcomeFailure, memory.Outcome)
+
+	// A broken JSON answer reports the JSON problem, not missing TITLE:
+	_, err = parseConsolidatedMemory(`{"title": "T", "content": "C"}`, sourceIDs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"outcome" is required`)
+}
+
+// mockStructuredLLMClient records whether consolidation used CompleteJSON.
+type mockStructuredLLMClient struct {
+	*mockLLMClient
+	schema json.RawMessage
+}
+
+func (m *mockStructuredLLMClient) CompleteJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	m.schema = schema
+	m.lastPrompt = prompt
+	return `{"title": "Structured", "content": "From JSON mode", "outcome": "success"}`, nil
+}
+
+func TestMergeCluster_UsesStructuredOutput(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	client := &mockStructuredLLMClient{mockLLMClient: newMockLLMClient()}
+
+	svc, err := NewService(newMockStore(), logger,
+		WithDefaultTenant("test-tenant"),
+		WithEmbedder(newMockEmbedder(10)))
+	require.NoError(t, err)
+	distiller, err := NewDistiller(svc, logger, WithLLMClient(client))
+	require.NoError(t, err)
+
+	mem1, _ := NewMemory("structured-project", "Go Error Pattern 1", "Always wrap errors", OutcomeSuccess, nil)
+	mem2, _ := NewMemory("structured-project", "Go Error Pattern 2", "Use fmt.Errorf for wrapping", OutcomeSuccess, nil)
+	require.NoError(t, svc.Record(ctx, mem1))
+	require.NoError(t, svc.Record(ctx, mem2))
+
+	merged, err := distiller.MergeCluster(ctx, &SimilarityCluster{Members: []*Memory{mem1, mem2}})
+	require.NoError(t, err)
+	assert.Equal(t, "Structured", merged.Title)
+	assert.JSONEq(t, string(ConsolidationSchema), string(client.schema))
+	assert.Zero(t, client.CallCount(), "Complete should not be called")
+}
@@ -2,14 +2,22 @@ package reasoningbank
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
 // SessionOutcome represents the overall outcome of a session.
@@ -76,22 +84,22 @@ type SessionSummary struct {
 //	## Your Task
 //	...
 //	## Output Format
-//	```
-//	TITLE: [consolidated title]
-//	CONTENT: [consolidated content]
-//	TAGS: [comma-separated tags]
-//	OUTCOME: [success|failure]
-//	SOURCE_ATTRIBUTION: [attribution note]
-//	```
+//	Respond with a single JSON object ... matching this JSON Schema:
+//	[ConsolidationSchema]
 //
 // # Expected Response Format
 //
-// The LLM response MUST include these fields in the exact format above:
-//   - TITLE: (required) A clear, concise title
-//   - CONTENT: (required) The synthesized content
-//   - TAGS: (optional) Comma-separated tags
-//   - OUTCOME: (required) Either "success" or "failure"
-//   - SOURCE_ATTRIBUTION: (optional) Attribution note
+// The LLM response should be a JSON object matching ConsolidationSchema:
+//   - title: (required) A clear, concise title
+//   - content: (required) The synthesized content
+//   - tags: (optional) Array of tags
+//   - outcome: (required) Either "success" or "failure"
+//   - source_attribution: (optional) Attribution note
+//
+// Clients that support tool use or a JSON response mode should also
+// implement StructuredLLMClient so the schema is enforced by the provider.
+// Responses in the older TITLE:, CONTENT:, TAGS:, OUTCOME: and
+// SOURCE_ATTRIBUTION: text format are still accepted as a fallback.
 //
 // # Example Implementation
 //
@@ -145,8 +153,8 @@ type LLMClient interface {
 	// The context can be used for cancellation and deadline control.
 	// The prompt will be a structured memory consolidation request.
 	//
-	// Returns the generated text containing TITLE:, CONTENT:, TAGS:,
-	// OUTCOME:, and optionally SOURCE_ATTRIBUTION: fields.
+	// Returns the generated text, a JSON object matching
+	// ConsolidationSchema for consolidation prompts.
 	//
 	// Returns an error if:
 	//   - The API request fails
@@ -163,13 +171,27 @@ type Distiller struct {
 	service   *Service
 	logger    *zap.Logger
 	llmClient LLMClient // Optional LLM client for memory consolidation
+	usage     analytics.UsageRecorder
+	budget    analytics.LLMBudget // Optional; pauses consolidation when exhausted
+	policy    *resilience.Policy  // Optional; timeouts, retries and circuit breaking
+
+	maxPromptTokens int64 // Larger consolidation prompts are merged in parts
 
 	// Consolidation tracking
 	lastConsolidation   map[string]time.Time // projectID -> last consolidation time
 	consolidationMu     sync.RWMutex         // protects lastConsolidation
 	consolidationWindow time.Duration        // minimum time between consolidations (default: 24h)
+
+	// Approval workflow
+	proposalTTL     time.Duration // how long proposals stay pending (default: 7 days)
+	proposalCounter metric.Int64Counter
+
+	onConsolidated ConsolidationListener // Optional; called after runs that merge
 }
 
+// ConsolidationListener is called after a consolidation run merged memories.
+type ConsolidationListener func(ctx context.Context, projectID string, result *ConsolidationResult)
+
 // DistillerOption configures a Distiller.
 type DistillerOption func(*Distiller)
 
@@ -181,6 +203,43 @@ func WithLLMClient(client LLMClient) DistillerOption {
 	}
 }
 
+// WithUsageRecorder reports the estimated token usage of consolidation
+// prompts to r. Clients implementing analytics.ModelDescriber are priced.
+func WithUsageRecorder(r analytics.UsageRecorder) DistillerOption {
+	return func(d *Distiller) {
+		d.usage = r
+	}
+}
+
+// WithBudget checks b before each consolidation request. When the tenant's
+// LLM budget is exhausted, consolidation pauses until the next run.
+func WithBudget(b analytics.LLMBudget) DistillerOption {
+	return func(d *Distiller) {
+		d.budget = b
+	}
+}
+
+// WithLLMPolicy applies p's timeouts, retries and circuit breaking to
+// consolidation requests. While the distiller's breaker is open,
+// consolidation pauses until the next run.
+func WithLLMPolicy(p *resilience.Policy) DistillerOption {
+	return func(d *Distiller) {
+		d.policy = p
+	}
+}
+
+// WithMaxPromptTokens sets the limit on the estimated tokens of a
+// consolidation prompt. Larger clusters are merged in parts, and a memory
+// that alone exceeds the limit fails its cluster with ErrPromptTooLarge.
+// Non-positive values keep DefaultMaxPromptTokens.
+func WithMaxPromptTokens(n int64) DistillerOption {
+	return func(d *Distiller) {
+		if n > 0 {
+			d.maxPromptTokens = n
+		}
+	}
+}
+
 // WithConsolidationWindow sets the minimum time between consolidations.
 // If not set, defaults to 24 hours.
 func WithConsolidationWindow(window time.Duration) DistillerOption {
@@ -189,6 +248,14 @@ func WithConsolidationWindow(window time.Duration) DistillerOption {
 	}
 }
 
+// WithConsolidationListener sets a listener called after every
+// consolidation run that merged at least one cluster.
+func WithConsolidationListener(l ConsolidationListener) DistillerOption {
+	return func(d *Distiller) {
+		d.onConsolidated = l
+	}
+}
+
 // NewDistiller creates a new session distiller.
 func NewDistiller(service *Service, logger *zap.Logger, opts ...DistillerOption) (*Distiller, error) {
 	if service == nil {
@@ -203,6 +270,7 @@ func NewDistiller(service *Service, logger *zap.Logger, opts ...DistillerOption)
 		logger:              logger,
 		lastConsolidation:   make(map[string]time.Time),
 		consolidationWindow: 24 * time.Hour, // Default: 24 hours
+		maxPromptTokens:     DefaultMaxPromptTokens,
 	}
 
 	// Apply options
@@ -210,6 +278,17 @@ func NewDistiller(service *Service, logger *zap.Logger, opts ...DistillerOption)
 		opt(d)
 	}
 
+	counter, err := otel.Meter(instrumentationName).Int64Counter(
+		"contextd.memory.consolidation_proposals_total",
+		metric.WithDescription("Consolidation proposals by decision (pending, approved, rejected, merged, expired)"),
+		metric.WithUnit("{proposal}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create consolidation proposal counter", zap.Error(err))
+	} else {
+		d.proposalCounter = counter
+	}
+
 	return d, nil
 }
 
@@ -500,6 +579,12 @@ func CosineSimilarity(vec1, vec2 []float32) float64 {
 //   - Slice of similarity clusters, each containing related memories
 //   - Error if clustering fails
 func (d *Distiller) FindSimilarClusters(ctx context.Context, projectID string, threshold float64) ([]SimilarityCluster, error) {
+	return d.findSimilarClusters(ctx, projectID, threshold, nil)
+}
+
+// findSimilarClusters implements FindSimilarClusters, considering only
+// memories for which keep returns true (all memories when keep is nil).
+func (d *Distiller) findSimilarClusters(ctx context.Context, projectID string, threshold float64, keep func(*Memory) bool) ([]SimilarityCluster, error) {
 	if projectID == "" {
 		return nil, ErrEmptyProjectID
 	}
@@ -511,59 +596,89 @@ func (d *Distiller) FindSimilarClusters(ctx context.Context, projectID string, t
 		zap.String("project_id", projectID),
 		zap.Float64("threshold", threshold))
 
-	// Get all memories for the project
-	memories, err := d.service.ListMemories(ctx, projectID, 0, 0)
+	memVecs, total, err := d.memoryVectors(ctx, projectID, keep)
 	if err != nil {
-		return nil, fmt.Errorf("listing memories: %w", err)
+		return nil, err
 	}
 
-	if len(memories) < 2 {
-		// Need at least 2 memories to form a cluster
-		d.logger.Debug("not enough memories for clustering",
-			zap.Int("count", len(memories)))
+	d.logger.Debug("retrieved memories for clustering",
+		zap.Int("count", total))
+
+	if len(memVecs) < 2 {
+		d.logger.Debug("not enough memories with vectors for clustering",
+			zap.Int("count", len(memVecs)))
 		return []SimilarityCluster{}, nil
 	}
 
-	d.logger.Debug("retrieved memories for clustering",
-		zap.Int("count", len(memories)))
+	clusters := d.clusterMemoryVectors(memVecs, threshold)
 
-	// Get embedding vectors for all memories
-	type memoryWithVector struct {
-		memory *Memory
-		vector []float32
+	clustered := 0
+	for _, c := range clusters {
+		clustered += len(c.Members)
 	}
+	d.logger.Info("clustering completed",
+		zap.String("project_id", projectID),
+		zap.Int("clusters", len(clusters)),
+		zap.Int("total_memories", total),
+		zap.Int("clustered_memories", clustered))
+
+	return clusters, nil
+}
+
+// memoryWithVector is a memory together with its embedding.
+type memoryWithVector struct {
+	memory *Memory
+	vector []float32
+}
+
+// memoryVectors loads the memories of a project for which keep returns true
+// (all memories when keep is nil) along with their embeddings. Memories whose
+// vector cannot be read are skipped. total counts the kept memories,
+// including skipped ones.
+func (d *Distiller) memoryVectors(ctx context.Context, projectID string, keep func(*Memory) bool) ([]memoryWithVector, int, error) {
+	// Stream memories so only their vectors, not a second full copy of the
+	// project, are held while clustering.
+	var memVecs []memoryWithVector
+	total := 0
+	err := d.service.StreamMemories(ctx, projectID, 0, func(m Memory) error {
+		// Quarantined content must not reach the LLM through a merge
+		if m.State == MemoryStateQuarantined || (keep != nil && !keep(&m)) {
+			return nil
+		}
+		total++
 
-	memVecs := make([]memoryWithVector, 0, len(memories))
-	for i := range memories {
 		var vector []float32
 		var err error
 
 		// Try project-specific method first (for StoreProvider), fall back to legacy
 		if d.service.stores != nil {
-			vector, err = d.service.GetMemoryVectorByProjectID(ctx, projectID, memories[i].ID)
+			vector, err = d.service.GetMemoryVectorByProjectID(ctx, projectID, m.ID)
 		} else {
-			vector, err = d.service.GetMemoryVector(ctx, memories[i].ID)
+			vector, err = d.service.GetMemoryVector(ctx, m.ID)
 		}
 
 		if err != nil {
 			d.logger.Warn("failed to get memory vector, skipping",
-				zap.String("memory_id", memories[i].ID),
+				zap.String("memory_id", m.ID),
 				zap.Error(err))
-			continue
+			return nil
 		}
 
 		memVecs = append(memVecs, memoryWithVector{
-			memory: &memories[i],
+			memory: &m,
 			vector: vector,
 		})
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing memories: %w", err)
 	}
+	return memVecs, total, nil
+}
 
-	if len(memVecs) < 2 {
-		d.logger.Debug("not enough memories with vectors for clustering",
-			zap.Int("count", len(memVecs)))
-		return []SimilarityCluster{}, nil
-	}
-
+// clusterMemoryVectors greedily groups memories whose similarity to a seed
+// memory exceeds threshold. Clusters have at least two members.
+func (d *Distiller) clusterMemoryVectors(memVecs []memoryWithVector, threshold float64) []SimilarityCluster {
 	// Track which memories have already been clustered
 	clustered := make(map[string]bool)
 	var clusters []SimilarityCluster
@@ -625,13 +740,7 @@ func (d *Distiller) FindSimilarClusters(ctx context.Context, projectID string, t
 			zap.Float64("min_similarity", minSim))
 	}
 
-	d.logger.Info("clustering completed",
-		zap.String("project_id", projectID),
-		zap.Int("clusters", len(clusters)),
-		zap.Int("total_memories", len(memories)),
-		zap.Int("clustered_memories", len(clustered)))
-
-	return clusters, nil
+	return clusters
 }
 
 // calculateCentroid computes the average (centroid) vector from a set of vectors.
@@ -883,17 +992,18 @@ func buildConsolidationPrompt(memories []*Memory) string {
 	b.WriteString("consolidated knowledge should be applied. Help future sessions recognize when this memory is relevant.\n\n")
 
 	b.WriteString("## Output Format\n\n")
-	b.WriteString("Provide your consolidated memory in the following format:\n\n")
-
-	b.WriteString("```\n")
-	b.WriteString("TITLE: [A clear, concise title for the consolidated memory]\n\n")
-	b.WriteString("CONTENT:\n")
-	b.WriteString("[The synthesized content following the structure above]\n\n")
-	b.WriteString("TAGS: [Comma-separated tags that apply to this consolidated knowledge]\n\n")
-	b.WriteString("OUTCOME: [Either 'success' or 'failure' based on the predominant outcome type]\n\n")
-	b.WriteString("SOURCE_ATTRIBUTION:\n")
-	b.WriteString("[A brief note about how the source memories contributed to this synthesis]\n")
-	b.WriteString("```\n\n")
+	b.WriteString("Respond with a single JSON object and nothing else, matching this JSON Schema:\n\n")
+
+	b.WriteString("```json\n")
+	b.Write(ConsolidationSchema)
+	b.WriteString("\n```\n\n")
+
+	b.WriteString("For example:\n\n")
+	b.WriteString("```json\n")
+	b.WriteString(`{"title": "A clear, concise title", "content": "The synthesized content following the structure above", `)
+	b.WriteString(`"tags": ["tag-one", "tag-two"], "outcome": "success", `)
+	b.WriteString(`"source_attribution": "How the source memories contributed to this synthesis"}`)
+	b.WriteString("\n```\n\n")
 
 	b.WriteString("Remember: The goal is to create a MORE valuable memory than any individual source. ")
 	b.WriteString("Synthesize insights, don't just summarize.\n")
@@ -903,14 +1013,16 @@ func buildConsolidationPrompt(memories []*Memory) string {
 
 // parseConsolidatedMemory parses an LLM response into a Memory struct.
 //
-// This function extracts structured fields from the LLM's consolidation response
-// and creates a Memory suitable for storage. The LLM response is expected to
-// contain the following fields in the format produced by buildConsolidationPrompt:
-//   - TITLE: A clear, concise title for the consolidated memory
-//   - CONTENT: The synthesized content
-//   - TAGS: Comma-separated tags (optional)
-//   - OUTCOME: Either 'success' or 'failure'
-//   - SOURCE_ATTRIBUTION: Attribution note about source memories (optional)
+// The response is expected to be a JSON object matching ConsolidationSchema,
+// as requested by buildConsolidationPrompt:
+//   - title: A clear, concise title for the consolidated memory
+//   - content: The synthesized content
+//   - tags: Tags (optional)
+//   - outcome: Either 'success' or 'failure'
+//   - source_attribution: Attribution note about source memories (optional)
+//
+// Responses that are not valid JSON fall back to the legacy TITLE:,
+// CONTENT:, TAGS:, OUTCOME: and SOURCE_ATTRIBUTION: text format.
 //
 // Parameters:
 //   - llmResponse: The raw text response from the LLM
@@ -921,7 +1033,7 @@ func buildConsolidationPrompt(memories []*Memory) string {
 //   - Error if required fields are missing or invalid
 //
 // The projectID field in the returned Memory will be empty and must be set by the caller.
-// The SOURCE_ATTRIBUTION is stored in the Memory's Description field.
+// The source attribution is stored in the Memory's Description field.
 func parseConsolidatedMemory(llmResponse string, sourceIDs []string) (*Memory, error) {
 	if llmResponse == "" {
 		return nil, fmt.Errorf("llm response cannot be empty")
@@ -930,6 +1042,43 @@ func parseConsolidatedMemory(llmResponse string, sourceIDs []string) (*Memory, e
 		return nil, fmt.Errorf("sourceIDs cannot be empty")
 	}
 
+	resp, err := parseConsolidationJSON(llmResponse)
+	if err != nil {
+		var textErr error
+		resp, textErr = parseConsolidationText(llmResponse)
+		if textErr != nil {
+			// Report the JSON problem unless the LLM answered in the text format
+			if errors.Is(err, errNoJSONObject) || strings.Contains(llmResponse, "TITLE:") {
+				return nil, textErr
+			}
+			return nil, err
+		}
+	}
+
+	// Create the memory with a generated UUID
+	// Note: ProjectID must be set by caller
+	now := time.Now()
+	memory := &Memory{
+		ID:          uuid.New().String(), // Generate unique ID for consolidated memory
+		ProjectID:   "",                  // Must be set by caller
+		Title:       resp.Title,
+		Description: resp.SourceAttribution, // Store attribution in description
+		Content:     resp.Content,
+		Outcome:     Outcome(resp.Outcome),
+		Confidence:  DistilledConfidence, // Start with distilled confidence
+		UsageCount:  0,
+		Tags:        resp.Tags,
+		State:       MemoryStateActive, // Consolidated memories are active
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	return memory, nil
+}
+
+// parseConsolidationText parses a consolidation response in the legacy
+// TITLE:/CONTENT: text format.
+func parseConsolidationText(llmResponse string) (*consolidationResponse, error) {
 	// Extract fields from the LLM response
 	title := extractField(llmResponse, "TITLE:")
 	content := extractField(llmResponse, "CONTENT:")
@@ -950,12 +1099,8 @@ func parseConsolidatedMemory(llmResponse string, sourceIDs []string) (*Memory, e
 
 	// Parse outcome
 	outcomeStr = strings.ToLower(strings.TrimSpace(outcomeStr))
-	var outcome Outcome
-	switch outcomeStr {
-	case "success":
-		outcome = OutcomeSuccess
-	case "failure":
-		outcome = OutcomeFailure
+	switch Outcome(outcomeStr) {
+	case OutcomeSuccess, OutcomeFailure:
 	default:
 		return nil, fmt.Errorf("invalid OUTCOME value: %s (must be 'success' or 'failure')", outcomeStr)
 	}
@@ -971,25 +1116,13 @@ func parseConsolidatedMemory(llmResponse string, sourceIDs []string) (*Memory, e
 		}
 	}
 
-	// Create the memory with a generated UUID
-	// Note: ProjectID must be set by caller
-	now := time.Now()
-	memory := &Memory{
-		ID:          uuid.New().String(), // Generate unique ID for consolidated memory
-		ProjectID:   "",                  // Must be set by caller
-		Title:       strings.TrimSpace(title),
-		Description: strings.TrimSpace(sourceAttribution), // Store attribution in description
-		Content:     strings.TrimSpace(content),
-		Outcome:     outcome,
-		Confidence:  DistilledConfidence, // Start with distilled confidence
-		UsageCount:  0,
-		Tags:        tags,
-		State:       MemoryStateActive, // Consolidated memories are active
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	return memory, nil
+	return &consolidationResponse{
+		Title:             strings.TrimSpace(title),
+		Content:           strings.TrimSpace(content),
+		Tags:              tags,
+		Outcome:           outcomeStr,
+		SourceAttribution: strings.TrimSpace(sourceAttribution),
+	}, nil
 }
 
 // extractField extracts the value of a field from the LLM response.
@@ -1077,6 +1210,41 @@ func extractField(text, fieldLabel string) string {
 //   - The newly created consolidated memory
 //   - Error if LLM client not configured, synthesis fails, or storage fails
 func (d *Distiller) MergeCluster(ctx context.Context, cluster *SimilarityCluster) (*Memory, error) {
+	return d.mergeCluster(ctx, cluster, "")
+}
+
+// usageScope attributes consolidation requests to projectID and the tenant
+// of the caller's scope or, failing that, of the vectorstore tenant in ctx.
+func usageScope(ctx context.Context, projectID string) context.Context {
+	scope, _ := analytics.ScopeFromContext(ctx)
+	if scope.TenantID == "" {
+		if tenant, err := vectorstore.TenantFromContext(ctx); err == nil {
+			scope.TenantID = tenant.TenantID
+		}
+	}
+	scope.ProjectID = projectID
+	return analytics.ContextWithScope(ctx, scope)
+}
+
+// recordUsage reports one consolidation request. LLMClient does not expose
+// token counts, so they are estimated from the prompt and response.
+func (d *Distiller) recordUsage(ctx context.Context, prompt, response string, err error) {
+	if d.usage == nil {
+		return
+	}
+	u := analytics.LLMUsage{
+		Feature:      analytics.FeatureDistiller,
+		InputTokens:  analytics.EstimateTokens(prompt),
+		OutputTokens: analytics.EstimateTokens(response),
+		Failed:       err != nil,
+	}
+	u.Provider, u.Model = analytics.DescribeModel(d.llmClient)
+	d.usage.RecordUsage(ctx, u)
+}
+
+// mergeCluster implements MergeCluster. A non-empty id is used as the
+// consolidated memory's ID instead of a random one.
+func (d *Distiller) mergeCluster(ctx context.Context, cluster *SimilarityCluster, id string) (*Memory, error) {
 	// Validate inputs
 	if cluster == nil {
 		return nil, fmt.Errorf("cluster cannot be nil")
@@ -1099,50 +1267,37 @@ func (d *Distiller) MergeCluster(ctx context.Context, cluster *SimilarityCluster
 		zap.Int("cluster_size", len(cluster.Members)),
 		zap.Float64("avg_similarity", cluster.AverageSimilarity))
 
-	// Build consolidation prompt
-	prompt := buildConsolidationPrompt(cluster.Members)
-
-	// Call LLM to synthesize memories
-	d.logger.Debug("calling LLM for memory synthesis",
-		zap.String("project_id", projectID),
-		zap.Int("prompt_length", len(prompt)))
-
-	llmResponse, err := d.llmClient.Complete(ctx, prompt)
+	// Synthesize the cluster, in parts if its prompt is too large
+	consolidatedMemory, err := d.synthesize(usageScope(ctx, projectID), projectID, cluster.Members)
 	if err != nil {
-		return nil, fmt.Errorf("LLM synthesis failed: %w", err)
+		return nil, err
 	}
 
-	d.logger.Debug("received LLM synthesis response",
-		zap.String("project_id", projectID),
-		zap.Int("response_length", len(llmResponse)))
-
-	// Extract source IDs
 	sourceIDs := make([]string, len(cluster.Members))
 	for i, mem := range cluster.Members {
 		sourceIDs[i] = mem.ID
 	}
 
-	// Parse LLM response into Memory
-	consolidatedMemory, err := parseConsolidatedMemory(llmResponse, sourceIDs)
-	if err != nil {
-		return nil, fmt.Errorf("parsing LLM response: %w", err)
-	}
-
 	// Set project ID (parseConsolidatedMemory leaves it empty)
 	consolidatedMemory.ProjectID = projectID
+	if id != "" {
+		consolidatedMemory.ID = id
+	}
 
 	// Calculate consolidated confidence from source memories with consensus bonus
 	// Uses calculateConsolidatedConfidence which provides a consensus bonus when
 	// multiple sources agree, producing higher confidence for well-corroborated knowledge
 	consolidatedMemory.Confidence = calculateConsolidatedConfidence(cluster.Members)
+	consolidatedMemory.Importance = consolidatedImportance(cluster.Members)
 
 	d.logger.Debug("calculated consolidated confidence",
 		zap.String("project_id", projectID),
 		zap.Float64("confidence", consolidatedMemory.Confidence),
 		zap.Int("source_count", len(cluster.Members)))
 
-	// Store the consolidated memory
-	if err := d.service.Record(ctx, consolidatedMemory); err != nil {
+	// Store the consolidated memory. It restates its sources, so it is not
+	// checked for duplicates of them.
+	if err := d.service.record(ctx, consolidatedMemory, false); err != nil {
 		return nil, fmt.Errorf("storing consolidated memory: %w", err)
 	}
 
@@ -1336,12 +1491,18 @@ func (d *Distiller) Consolidate(ctx context.Context, projectID string, opts Cons
 	if projectID == "" {
 		return nil, ErrEmptyProjectID
 	}
-	if opts.SimilarityThreshold < 0.0 || opts.SimilarityThreshold > 1.0 {
-		return nil, fmt.Errorf("similarity threshold must be between 0.0 and 1.0, got %f", opts.SimilarityThreshold)
+	threshold, err := consolidationThreshold(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.UseProjectThreshold {
+		threshold = d.projectThreshold(ctx, projectID, threshold)
+		opts.SimilarityThreshold = threshold
 	}
 
-	// Check if consolidation should be skipped (recently consolidated)
-	if skip, remaining := d.shouldSkipConsolidation(projectID, opts.ForceAll); skip {
+	// Check if consolidation should be skipped (recently consolidated).
+	// Scoped runs are targeted cleanups and are never skipped.
+	if skip, remaining := d.shouldSkipConsolidation(projectID, opts.ForceAll || opts.Scoped()); skip {
 		d.logger.Info("skipping consolidation - recently consolidated",
 			zap.String("project_id", projectID),
 			zap.Duration("time_remaining", remaining),
@@ -1357,12 +1518,6 @@ func (d *Distiller) Consolidate(ctx context.Context, projectID string, opts Cons
 		}, nil
 	}
 
-	// Use default threshold if not set
-	threshold := opts.SimilarityThreshold
-	if threshold == 0.0 {
-		threshold = 0.8 // Default threshold
-	}
-
 	startTime := time.Now()
 
 	d.logger.Info("starting memory consolidation",
@@ -1370,25 +1525,10 @@ func (d *Distiller) Consolidate(ctx context.Context, projectID string, opts Cons
 		zap.Float64("threshold", threshold),
 		zap.Int("max_clusters", opts.MaxClustersPerRun),
 		zap.Bool("dry_run", opts.DryRun),
-		zap.Bool("force_all", opts.ForceAll))
-
-	// Find similar clusters
-	clusters, err := d.FindSimilarClusters(ctx, projectID, threshold)
-	if err != nil {
-		return nil, fmt.Errorf("finding similar clusters: %w", err)
-	}
-
-	d.logger.Info("found similarity clusters",
-		zap.String("project_id", projectID),
-		zap.Int("cluster_count", len(clusters)))
-
-	// Apply MaxClustersPerRun limit if set
-	if opts.MaxClustersPerRun > 0 && len(clusters) > opts.MaxClustersPerRun {
-		d.logger.Info("limiting clusters to process",
-			zap.Int("total_clusters", len(clusters)),
-			zap.Int("max_clusters", opts.MaxClustersPerRun))
-		clusters = clusters[:opts.MaxClustersPerRun]
-	}
+		zap.Bool("force_all", opts.ForceAll),
+		zap.Strings("tags", opts.Tags),
+		zap.String("outcome", string(opts.Outcome)),
+		zap.Bool("query", opts.Query != ""))
 
 	// Initialize result tracking
 	result := &ConsolidationResult{
@@ -1398,12 +1538,35 @@ func (d *Distiller) Consolidate(ctx context.Context, projectID string, opts Cons
 		TotalProcessed:   0,
 	}
 
+	// Merge clusters approved since the last run before finding new ones
+	if !opts.DryRun {
+		if err := d.mergeApprovedProposals(ctx, projectID, result); err != nil {
+			return nil, fmt.Errorf("merging approved proposals: %w", err)
+		}
+	}
+
+	// Find similar clusters
+	clusters, err := d.FindConsolidationClusters(ctx, projectID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// In approval mode, persist clusters for review instead of merging them
+	if opts.RequireApproval && !opts.DryRun {
+		result.ProposalIDs, err = d.proposeClusters(ctx, projectID, clusters)
+		if err != nil {
+			return nil, fmt.Errorf("saving consolidation proposals: %w", err)
+		}
+		clusters = nil
+	}
+
 	// Count total memories to process
 	for _, cluster := range clusters {
 		result.TotalProcessed += len(cluster.Members)
 	}
 
 	// Process each cluster
+	paused := false
 	for i, cluster := range clusters {
 		d.logger.Debug("processing cluster",
 			zap.Int("cluster_index", i+1),
@@ -1428,12 +1591,30 @@ func (d *Distiller) Consolidate(ctx context.Context, projectID string, opts Cons
 
 		// Merge the cluster into a consolidated memory
 		consolidatedMemory, err := d.MergeCluster(ctx, &cluster)
+		if errors.Is(err, analytics.ErrBudgetExhausted) || errors.Is(err, resilience.ErrCircuitOpen) {
+			// Pause: the remaining clusters wait for the next run
+			for _, rest := range clusters[i:] {
+				result.SkippedCount += len(rest.Members)
+			}
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("consolidation paused with %d of %d clusters left: %v", len(clusters)-i, len(clusters), err))
+			d.logger.Warn("consolidation paused, LLM unavailable",
+				zap.String("project_id", projectID),
+				zap.Int("clusters_left", len(clusters)-i),
+				zap.Error(err))
+			paused = true
+			break
+		}
 		if err != nil {
 			d.logger.Warn("failed to merge cluster, skipping",
 				zap.Int("cluster_index", i+1),
 				zap.Int("members", len(cluster.Members)),
 				zap.Error(err))
 			result.SkippedCount += len(cluster.Members)
+			if errors.Is(err, ErrPromptTooLarge) {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("cluster %d of %d skipped: %v", i+1, len(clusters), err))
+			}
 			continue
 		}
 
@@ -1454,8 +1635,11 @@ func (d *Distiller) Consolidate(ctx context.Context, projectID string, opts Cons
 	// Calculate duration
 	result.Duration = time.Since(startTime)
 
-	// Update last consolidation time (unless dry run)
-	if !opts.DryRun {
+	// Update last consolidation time (unless dry run). Scoped runs leave the
+	// rest of the project untouched, and paused runs have clusters left, so
+	// neither resets the window. Approval-mode runs only propose; merging a
+	// proposal resets the window instead (see mergeProposal).
+	if !opts.DryRun && !opts.Scoped() && !paused && !opts.RequireApproval {
 		d.setLastConsolidationTime(projectID, time.Now())
 		d.logger.Debug("updated last consolidation time",
 			zap.String("project_id", projectID),
@@ -1471,9 +1655,181 @@ func (d *Distiller) Consolidate(ctx context.Context, projectID string, opts Cons
 		zap.Duration("duration", result.Duration),
 		zap.Bool("dry_run", opts.DryRun))
 
+	if !opts.DryRun {
+		d.ReportConsolidation(ctx, projectID, result)
+	}
 	return result, nil
 }
 
+// ReportConsolidation passes the result of a consolidation run to the
+// consolidation listener, if the run merged anything. Consolidate reports
+// its own runs; callers that merge clusters with MergeMembers report theirs.
+func (d *Distiller) ReportConsolidation(ctx context.Context, projectID string, result *ConsolidationResult) {
+	if d.onConsolidated == nil || result == nil || len(result.CreatedMemories) == 0 {
+		return
+	}
+	d.onConsolidated(ctx, projectID, result)
+}
+
+// FindConsolidationClusters returns the clusters Consolidate would merge
+// for opts, without merging them. It applies the similarity threshold, the
+// tag, outcome and query filters and MaxClustersPerRun, but ignores the
+// consolidation window and approval mode.
+func (d *Distiller) FindConsolidationClusters(ctx context.Context, projectID string, opts ConsolidationOptions) ([]SimilarityCluster, error) {
+	threshold, err := consolidationThreshold(opts)
+	if err != nil {
+		return nil, err
+	}
+	keep, err := d.consolidationFilter(ctx, projectID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := d.findSimilarClusters(ctx, projectID, threshold, keep)
+	if err != nil {
+		return nil, fmt.Errorf("finding similar clusters: %w", err)
+	}
+
+	d.logger.Info("found similarity clusters",
+		zap.String("project_id", projectID),
+		zap.Int("cluster_count", len(clusters)))
+
+	// Apply MaxClustersPerRun limit if set
+	if opts.MaxClustersPerRun > 0 && len(clusters) > opts.MaxClustersPerRun {
+		d.logger.Info("limiting clusters to process",
+			zap.Int("total_clusters", len(clusters)),
+			zap.Int("max_clusters", opts.MaxClustersPerRun))
+		clusters = clusters[:opts.MaxClustersPerRun]
+	}
+	return clusters, nil
+}
+
+// MergeMembers merges the memories with the given IDs, as found by
+// FindConsolidationClusters, into one consolidated memory.
+//
+// It is safe to retry. The consolidated memory's ID is derived from the
+// project and member IDs, so if an earlier attempt already stored it, the
+// remaining members are linked to it and it is returned without another LLM
+// call. Members that were archived or deleted in the meantime are left out;
+// if fewer than two remain, MergeMembers returns ErrClusterChanged.
+func (d *Distiller) MergeMembers(ctx context.Context, projectID string, memberIDs []string) (*Memory, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	ctx, err := d.service.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := append([]string(nil), memberIDs...)
+	sort.Strings(ids)
+	consolidatedID := uuid.NewSHA1(uuid.NameSpaceOID, []byte(projectID+"\x00"+strings.Join(ids, "\x00"))).String()
+
+	existing, err := d.service.GetByProjectID(ctx, projectID, consolidatedID)
+	switch {
+	case err == nil:
+		var unlinked []string
+		for _, id := range ids {
+			m, err := d.service.GetByProjectID(ctx, projectID, id)
+			if err == nil && (m.ConsolidationID == nil || *m.ConsolidationID != consolidatedID) {
+				unlinked = append(unlinked, id)
+			}
+		}
+		if len(unlinked) > 0 {
+			if err := d.linkMemoriesToConsolidated(ctx, projectID, unlinked, consolidatedID); err != nil {
+				return nil, err
+			}
+		}
+		return existing, nil
+	case !errors.Is(err, ErrMemoryNotFound):
+		return nil, err
+	}
+
+	var cluster SimilarityCluster
+	for _, id := range ids {
+		m, err := d.service.GetByProjectID(ctx, projectID, id)
+		if errors.Is(err, ErrMemoryNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.State == MemoryStateActive {
+			cluster.Members = append(cluster.Members, m)
+		}
+	}
+	if len(cluster.Members) < 2 {
+		return nil, ErrClusterChanged
+	}
+	return d.mergeCluster(ctx, &cluster, consolidatedID)
+}
+
+// consolidationThreshold validates opts and returns the similarity threshold
+// to cluster with, applying the 0.8 default when unset.
+func consolidationThreshold(opts ConsolidationOptions) (float64, error) {
+	if opts.SimilarityThreshold < 0.0 || opts.SimilarityThreshold > 1.0 {
+		return 0, fmt.Errorf("similarity threshold must be between 0.0 and 1.0, got %f", opts.SimilarityThreshold)
+	}
+	if opts.Outcome != "" && opts.Outcome != OutcomeSuccess && opts.Outcome != OutcomeFailure {
+		return 0, fmt.Errorf("outcome must be %q or %q, got %q", OutcomeSuccess, OutcomeFailure, opts.Outcome)
+	}
+	if opts.SimilarityThreshold == 0.0 {
+		return DefaultSimilarityThreshold, nil
+	}
+	return opts.SimilarityThreshold, nil
+}
+
+// defaultConsolidationQueryLimit is how many memories a consolidation Query
+// selects when QueryLimit is not set.
+const defaultConsolidationQueryLimit = 100
+
+// consolidationFilter builds the pre-clustering filter for scoped
+// consolidation runs. It returns nil when opts are not scoped.
+func (d *Distiller) consolidationFilter(ctx context.Context, projectID string, opts ConsolidationOptions) (func(*Memory) bool, error) {
+	if !opts.Scoped() {
+		return nil, nil
+	}
+
+	var queryIDs map[string]bool
+	if opts.Query != "" {
+		limit := opts.QueryLimit
+		if limit <= 0 {
+			limit = defaultConsolidationQueryLimit
+		}
+		matches, err := d.service.Search(ctx, projectID, opts.Query, limit)
+		if err != nil {
+			return nil, fmt.Errorf("selecting memories for query: %w", err)
+		}
+		queryIDs = make(map[string]bool, len(matches))
+		for _, m := range matches {
+			queryIDs[m.ID] = true
+		}
+	}
+
+	tags := make(map[string]bool, len(opts.Tags))
+	for _, t := range opts.Tags {
+		tags[t] = true
+	}
+
+	return func(m *Memory) bool {
+		if opts.Outcome != "" && m.Outcome != opts.Outcome {
+			return false
+		}
+		if queryIDs != nil && !queryIDs[m.ID] {
+			return false
+		}
+		if len(tags) == 0 {
+			return true
+		}
+		for _, t := range m.Tags {
+			if tags[t] {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
 // ConsolidateAll runs memory consolidation across all specified projects.
 //
 // This method is designed for scheduled background runs and batch processing.
@@ -1548,6 +1904,7 @@ func (d *Distiller) ConsolidateAll(ctx context.Context, projectIDs []string, opt
 		aggregatedResult.ArchivedMemories = append(aggregatedResult.ArchivedMemories, result.ArchivedMemories...)
 		aggregatedResult.SkippedCount += result.SkippedCount
 		aggregatedResult.TotalProcessed += result.TotalProcessed
+		aggregatedResult.Warnings = append(aggregatedResult.Warnings, result.Warnings...)
 
 		successCount++
 
@@ -1591,31 +1948,21 @@ func (d *Distiller) ConsolidateAll(ctx context.Context, projectIDs []string, opt
 // failing the consolidation if linking fails (the consolidated memory is already created).
 func (d *Distiller) linkMemoriesToConsolidated(ctx context.Context, projectID string, sourceIDs []string, consolidatedID string) error {
 	for _, sourceID := range sourceIDs {
-		// Get the source memory
-		memory, err := d.service.GetByProjectID(ctx, projectID, sourceID)
-		if err != nil {
-			d.logger.Warn("failed to get source memory for linking",
-				zap.String("source_id", sourceID),
-				zap.Error(err))
-			continue
-		}
-
-		// Set consolidation ID and mark as archived
-		memory.ConsolidationID = &consolidatedID
-		memory.State = MemoryStateArchived
-		memory.UpdatedAt = time.Now()
-
-		// Update the memory in storage
-		// We need to delete and re-add to update the ConsolidationID field
-		if err := d.service.DeleteByProjectID(ctx, projectID, sourceID); err != nil {
-			d.logger.Warn("failed to delete source memory for update",
-				zap.String("source_id", sourceID),
-				zap.Error(err))
-			continue
-		}
+		// Re-read and rewrite in place until no concurrent write intervenes
+		err := retryOnConflict(func(int) error {
+			memory, err := d.service.GetByProjectID(ctx, projectID, sourceID)
+			if err != nil {
+				return fmt.Errorf("getting source memory: %w", err)
+			}
 
-		if err := d.service.Record(ctx, memory); err != nil {
-			d.logger.Warn("failed to re-add source memory with consolidation link",
+			// Set consolidation ID and mark as archived
+			memory.ConsolidationID = &consolidatedID
+			memory.State = MemoryStateArchived
+			memory.UpdatedAt = time.Now()
+			return d.service.replaceMemory(ctx, projectID, memory)
+		})
+		if err != nil {
+			d.logger.Warn("failed to link source memory to consolidated version",
 				zap.String("source_id", sourceID),
 				zap.Error(err))
 			continue
@@ -8,9 +8,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
 // TestSanitizePromptContent tests the prompt content sanitization function.
@@ -1081,11 +1086,11 @@ func TestBuildConsolidationPrompt_SingleMemory(t *testing.T) {
 	assert.Contains(t, prompt, "Note When to Apply")
 
 	// Verify output format specification
-	assert.Contains(t, prompt, "TITLE:")
-	assert.Contains(t, prompt, "CONTENT:")
-	assert.Contains(t, prompt, "TAGS:")
-	assert.Contains(t, prompt, "OUTCOME:")
-	assert.Contains(t, prompt, "SOURCE_ATTRIBUTION:")
+	assert.Contains(t, prompt, "single JSON object")
+	assert.Contains(t, prompt, string(ConsolidationSchema))
+	for _, field := range []string{`"title"`, `"content"`, `"tags"`, `"outcome"`, `"source_attribution"`} {
+		assert.Contains(t, prompt, field)
+	}
 }
 
 // TestBuildConsolidationPrompt_MultipleMemories tests prompt with multiple memories.
@@ -1509,6 +1514,65 @@ func TestMergeCluster_ValidCluster(t *testing.T) {
 }
 
 // TestMergeCluster_ConfidenceCalculation tests that merged confidence is calculated correctly.
+func TestMergeMembers_RetryReturnsSameMemory(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	logger := zap.NewNop()
+	mockLLM := newMockLLMClient()
+
+	svc, err := NewService(store, logger,
+		WithDefaultTenant("test-tenant"),
+		WithEmbedder(newMockEmbedder(10)))
+	require.NoError(t, err)
+
+	distiller, err := NewDistiller(svc, logger, WithLLMClient(mockLLM))
+	require.NoError(t, err)
+
+	projectID := "merge-members-project"
+	mem1, _ := NewMemory(projectID, "Retry pattern 1", "Retry with backoff", OutcomeSuccess, []string{"retry"})
+	require.NoError(t, svc.Record(ctx, mem1))
+	mem2, _ := NewMemory(projectID, "Retry pattern 2", "Back off before retrying", OutcomeSuccess, []string{"retry"})
+	require.NoError(t, svc.Record(ctx, mem2))
+
+	first, err := distiller.MergeMembers(ctx, projectID, []string{mem1.ID, mem2.ID})
+	require.NoError(t, err)
+
+	// A retried activity passes the same members, possibly in another order
+	second, err := distiller.MergeMembers(ctx, projectID, []string{mem2.ID, mem1.ID})
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, 1, mockLLM.CallCount(), "retry should not call the LLM again")
+
+	for _, id := range []string{mem1.ID, mem2.ID} {
+		source, err := svc.GetByProjectID(ctx, projectID, id)
+		require.NoError(t, err)
+		assert.Equal(t, MemoryStateArchived, source.State)
+		require.NotNil(t, source.ConsolidationID)
+		assert.Equal(t, first.ID, *source.ConsolidationID)
+	}
+}
+
+func TestMergeMembers_ClusterChanged(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	svc, err := NewService(newMockStore(), logger,
+		WithDefaultTenant("test-tenant"),
+		WithEmbedder(newMockEmbedder(10)))
+	require.NoError(t, err)
+
+	distiller, err := NewDistiller(svc, logger, WithLLMClient(newMockLLMClient()))
+	require.NoError(t, err)
+
+	projectID := "merge-members-changed"
+	mem, _ := NewMemory(projectID, "Only survivor", "Content", OutcomeSuccess, nil)
+	require.NoError(t, svc.Record(ctx, mem))
+
+	_, err = distiller.MergeMembers(ctx, projectID, []string{mem.ID, uuid.New().String()})
+	assert.ErrorIs(t, err, ErrClusterChanged)
+}
+
 func TestMergeCluster_ConfidenceCalculation(t *testing.T) {
 	ctx := context.Background()
 	store := newMockStore()
@@ -1885,6 +1949,55 @@ func TestMergeCluster_LLMError(t *testing.T) {
 	assert.Equal(t, 1, mockLLM.CallCount())
 }
 
+// describedLLMClient is a mock LLM client that names its model.
+type describedLLMClient struct {
+	*mockLLMClient
+}
+
+func (describedLLMClient) LLMModel() (string, string) {
+	return analytics.ProviderAnthropic, "claude-3-haiku-20240307"
+}
+
+// TestMergeCluster_RecordsUsage tests that consolidation requests are
+// reported with estimated tokens and priced by model.
+func TestMergeCluster_RecordsUsage(t *testing.T) {
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "acme"})
+	store := newMockStore()
+	logger := zap.NewNop()
+
+	svc, err := NewService(store, logger,
+		WithDefaultTenant("test-tenant"),
+		WithEmbedder(newMockEmbedder(10)))
+	require.NoError(t, err)
+
+	tracker, err := analytics.NewTracker(analytics.Config{Dir: t.TempDir()}, logger)
+	require.NoError(t, err)
+	distiller, err := NewDistiller(svc, logger,
+		WithLLMClient(describedLLMClient{newMockLLMClient()}),
+		WithUsageRecorder(tracker))
+	require.NoError(t, err)
+
+	projectID := "usage-project"
+	mem1, _ := NewMemory(projectID, "Memory 1", "Content 1", OutcomeSuccess, []string{"test"})
+	mem2, _ := NewMemory(projectID, "Memory 2", "Content 2", OutcomeSuccess, []string{"test"})
+	_, err = distiller.MergeCluster(ctx, &SimilarityCluster{Members: []*Memory{mem1, mem2}})
+	require.NoError(t, err)
+
+	rows, err := tracker.QueryUsage(analytics.UsageQuery{GroupBy: []string{
+		analytics.DimensionFeature, analytics.DimensionModel, analytics.DimensionTenant, analytics.DimensionProject,
+	}})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, analytics.FeatureDistiller, rows[0].Feature)
+	assert.Equal(t, "claude-3-haiku-20240307", rows[0].Model)
+	assert.Equal(t, "acme", rows[0].TenantID)
+	assert.Equal(t, projectID, rows[0].ProjectID)
+	assert.Equal(t, int64(1), rows[0].Requests)
+	assert.Positive(t, rows[0].InputTokens)
+	assert.Positive(t, rows[0].OutputTokens)
+	assert.Positive(t, rows[0].CostUSD)
+}
+
 // TestMergeCluster_InvalidLLMResponse tests error handling with malformed LLM response.
 func TestMergeCluster_InvalidLLMResponse(t *testing.T) {
 	ctx := context.Background()
@@ -2356,10 +2469,13 @@ func TestMemoryState_Validation(t *testing.T) {
 	mem.State = MemoryStateArchived
 	assert.NoError(t, mem.Validate(), "archived state should be valid")
 
+	mem.State = MemoryStateQuarantined
+	assert.NoError(t, mem.Validate(), "quarantined state should be valid")
+
 	// Invalid state should fail validation
 	mem.State = "invalid"
 	assert.Error(t, mem.Validate(), "invalid state should fail validation")
-	assert.Contains(t, mem.Validate().Error(), "state must be 'active' or 'archived'")
+	assert.Contains(t, mem.Validate().Error(), "state must be 'active', 'archived' or 'quarantined'")
 }
 
 // TestConsolidate_ValidConsolidation tests successful consolidation with multiple clusters.
@@ -2429,6 +2545,98 @@ func TestConsolidate_ValidConsolidation(t *testing.T) {
 	assert.Equal(t, 2, mockLLM.CallCount(), "LLM should be called twice for 2 clusters")
 }
 
+// budgetFunc adapts a function to analytics.LLMBudget.
+type budgetFunc func(ctx context.Context, feature string) error
+
+func (f budgetFunc) Allow(ctx context.Context, feature string) error { return f(ctx, feature) }
+
+// TestConsolidate_BudgetExhausted tests that consolidation pauses once the
+// tenant's LLM budget runs out and retries on the next run.
+func TestConsolidate_BudgetExhausted(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+	mockLLM := newMockLLMClient()
+
+	svc := &Service{
+		store:         newMockStore(),
+		embedder:      newMockEmbedder(10),
+		logger:        zap.NewNop(),
+		defaultTenant: "test-tenant",
+	}
+
+	// The budget allows a single request
+	allowed := 1
+	distiller, err := NewDistiller(svc, zap.NewNop(),
+		WithLLMClient(mockLLM),
+		WithBudget(budgetFunc(func(ctx context.Context, feature string) error {
+			assert.Equal(t, analytics.FeatureDistiller, feature)
+			scope, ok := analytics.ScopeFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, projectID, scope.ProjectID)
+			if allowed == 0 {
+				return fmt.Errorf("%w: tenant %q used 10 of 10 tokens this month", analytics.ErrBudgetExhausted, scope.TenantID)
+			}
+			allowed--
+			return nil
+		})))
+	require.NoError(t, err)
+
+	mem1, _ := NewMemory(projectID, "API error handling pattern one", "Use structured error responses", OutcomeSuccess, []string{"api"})
+	mem2, _ := NewMemory(projectID, "API error handling pattern two", "Implement proper error codes", OutcomeSuccess, []string{"api"})
+	mem3, _ := NewMemory(projectID, "Database connection best practice", "Use connection pooling", OutcomeSuccess, []string{"database"})
+	mem4, _ := NewMemory(projectID, "Database connection pooling strategy", "Configure max connections properly", OutcomeSuccess, []string{"database"})
+	for _, m := range []*Memory{mem1, mem2, mem3, mem4} {
+		require.NoError(t, svc.Record(ctx, m))
+	}
+
+	result, err := distiller.Consolidate(ctx, projectID, ConsolidationOptions{SimilarityThreshold: 0.85})
+	require.NoError(t, err)
+	assert.Len(t, result.CreatedMemories, 1)
+	assert.Equal(t, 2, result.SkippedCount, "the second cluster waits for the next run")
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "consolidation paused with 1 of 2 clusters left")
+	assert.Equal(t, 1, mockLLM.CallCount())
+	assert.True(t, distiller.getLastConsolidationTime(projectID).IsZero(), "paused runs do not reset the window")
+}
+
+// TestConsolidate_CircuitOpen tests that consolidation pauses once the
+// distiller's LLM circuit breaker opens.
+func TestConsolidate_CircuitOpen(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+	mockLLM := newMockLLMClientWithError(&resilience.StatusError{StatusCode: 503, Message: "overloaded"})
+
+	svc := &Service{
+		store:         newMockStore(),
+		embedder:      newMockEmbedder(10),
+		logger:        zap.NewNop(),
+		defaultTenant: "test-tenant",
+	}
+
+	// The first failure opens the breaker
+	policy, err := resilience.New(resilience.Config{MaxRetries: -1, FailureThreshold: 1}, zap.NewNop())
+	require.NoError(t, err)
+	distiller, err := NewDistiller(svc, zap.NewNop(), WithLLMClient(mockLLM), WithLLMPolicy(policy))
+	require.NoError(t, err)
+
+	mem1, _ := NewMemory(projectID, "API error handling pattern one", "Use structured error responses", OutcomeSuccess, []string{"api"})
+	mem2, _ := NewMemory(projectID, "API error handling pattern two", "Implement proper error codes", OutcomeSuccess, []string{"api"})
+	mem3, _ := NewMemory(projectID, "Database connection best practice", "Use connection pooling", OutcomeSuccess, []string{"database"})
+	mem4, _ := NewMemory(projectID, "Database connection pooling strategy", "Configure max connections properly", OutcomeSuccess, []string{"database"})
+	for _, m := range []*Memory{mem1, mem2, mem3, mem4} {
+		require.NoError(t, svc.Record(ctx, m))
+	}
+
+	result, err := distiller.Consolidate(ctx, projectID, ConsolidationOptions{SimilarityThreshold: 0.85})
+	require.NoError(t, err)
+	assert.Empty(t, result.CreatedMemories)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "consolidation paused with 1 of 2 clusters left")
+	assert.Contains(t, result.Warnings[0], "circuit breaker open")
+	assert.Equal(t, 1, mockLLM.CallCount(), "the open breaker skips the second cluster's request")
+	assert.True(t, distiller.getLastConsolidationTime(projectID).IsZero(), "paused runs do not reset the window")
+}
+
 // TestConsolidate_EmptyProject tests consolidation with no memories.
 func TestConsolidate_EmptyProject(t *testing.T) {
 	ctx := context.Background()
@@ -2654,6 +2862,79 @@ func TestConsolidate_MaxClustersLimit(t *testing.T) {
 	assert.LessOrEqual(t, mockLLM.CallCount(), 2, "LLM should be called at most twice")
 }
 
+// TestConsolidate_Scoped tests that tag and outcome options restrict
+// consolidation to matching memories only.
+func TestConsolidate_Scoped(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+
+	mockStore := newMockStore()
+	mockEmbedder := newMockEmbedder(384)
+	mockLLM := newMockLLMClient()
+
+	svc := &Service{
+		store:         mockStore,
+		embedder:      mockEmbedder,
+		logger:        zap.NewNop(),
+		defaultTenant: "test-tenant",
+	}
+
+	distiller, err := NewDistiller(svc, zap.NewNop(), WithLLMClient(mockLLM))
+	require.NoError(t, err)
+
+	// Two clusters: one tagged testing, one tagged database
+	testingIDs := map[string]bool{}
+	for i := 1; i <= 2; i++ {
+		mem, _ := NewMemory(projectID, fmt.Sprintf("Error handling pattern %d", i),
+			"Content for Error handling", OutcomeSuccess, []string{"testing"})
+		require.NoError(t, svc.Record(ctx, mem))
+		testingIDs[mem.ID] = true
+
+		mem, _ = NewMemory(projectID, fmt.Sprintf("Database connection pattern %d", i),
+			"Content for Database connection", OutcomeSuccess, []string{"database"})
+		require.NoError(t, svc.Record(ctx, mem))
+	}
+
+	t.Run("tag filter", func(t *testing.T) {
+		result, err := distiller.Consolidate(ctx, projectID, ConsolidationOptions{
+			SimilarityThreshold: 0.85,
+			DryRun:              true,
+			Tags:                []string{"testing"},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.CreatedMemories, 1)
+		require.Len(t, result.ArchivedMemories, 2)
+		for _, id := range result.ArchivedMemories {
+			assert.True(t, testingIDs[id], "memory %s is outside the requested tag", id)
+		}
+	})
+
+	t.Run("outcome filter excludes everything", func(t *testing.T) {
+		result, err := distiller.Consolidate(ctx, projectID, ConsolidationOptions{
+			SimilarityThreshold: 0.85,
+			DryRun:              true,
+			Outcome:             OutcomeFailure,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.CreatedMemories)
+	})
+
+	t.Run("invalid outcome", func(t *testing.T) {
+		_, err := distiller.Consolidate(ctx, projectID, ConsolidationOptions{Outcome: "maybe"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "outcome must be")
+	})
+
+	t.Run("scoped run does not start consolidation window", func(t *testing.T) {
+		_, err := distiller.Consolidate(ctx, projectID, ConsolidationOptions{
+			SimilarityThreshold: 0.85,
+			Tags:                []string{"database"},
+		})
+		require.NoError(t, err)
+		assert.True(t, distiller.getLastConsolidationTime(projectID).IsZero())
+	})
+}
+
 // TestConsolidate_NoLLMClient tests error handling when LLM client is not configured.
 func TestConsolidate_NoLLMClient(t *testing.T) {
 	ctx := context.Background()
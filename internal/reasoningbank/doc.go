@@ -31,6 +31,28 @@
 //   - Archives source memories with back-links for attribution
 //   - Consolidated memories receive 20% similarity boost in search
 //
+// # Multi-Query Search
+//
+// SearchMultiQuery improves recall for multi-part tasks such as "add OAuth
+// and write integration tests":
+//   - A QueryDecomposer splits the task into sub-queries (HeuristicDecomposer
+//     by default, or LLMDecomposer via WithQueryDecomposer)
+//   - The full query and each sub-query are searched in parallel
+//   - Results are fused with reciprocal rank fusion, so memories relevant to
+//     several parts rank first
+//
+// # Language Scopes
+//
+// Memories record the languages or stacks they are about (Memory.Languages),
+// so learnings from one part of a polyglot repository do not surface in
+// another:
+//   - Record infers languages from tags ("golang", "tf") or, without them,
+//     from file extensions, toolchain commands and syntax in the content
+//   - ContextWithLanguages scopes a search: memories only about other
+//     languages are filtered out, language-agnostic memories are kept
+//   - Without a scope, languages named in the query boost matching memories
+//     and penalize memories about other languages
+//
 // # Security
 //
 // The package implements defense-in-depth security:
@@ -77,7 +99,7 @@
 // # MCP Integration
 //
 // The package is exposed via MCP tools:
-//   - memory_search: Find relevant memories by semantic similarity
+//   - memory_search: Find relevant memories by semantic similarity (decompose for multi-query)
 //   - memory_record: Save new memory explicitly (bypasses distillation)
 //   - memory_feedback: Rate memory helpfulness (helpful/unhelpful)
 //   - memory_outcome: Report task success/failure after using memory
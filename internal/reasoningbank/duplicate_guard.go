@@ -0,0 +1,292 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// DefaultDuplicateWindow is how long a recorded memory suppresses its
+	// duplicates when DuplicateGuardConfig.Window is zero.
+	DefaultDuplicateWindow = 10 * time.Minute
+
+	// DefaultDuplicateThreshold is the word overlap above which a memory is
+	// a duplicate when DuplicateGuardConfig.Threshold is zero.
+	DefaultDuplicateThreshold = 0.85
+
+	// DefaultDuplicateBurst is the number of suppressed duplicates of one
+	// memory that raises a warning when DuplicateGuardConfig.BurstSize is
+	// zero.
+	DefaultDuplicateBurst = 3
+
+	// maxRecentRecords bounds the memories remembered per project; the
+	// oldest are forgotten first.
+	maxRecentRecords = 256
+)
+
+// ErrDuplicateMemory is returned by Record for a memory that repeats one
+// recorded recently.
+var ErrDuplicateMemory = ctxerrors.New(ctxerrors.CodeInvalidInput, "duplicate of a recently recorded memory")
+
+// DuplicateGuardConfig configures the suppression of duplicate records.
+type DuplicateGuardConfig struct {
+	// Window is how long after it was recorded, or last repeated, a memory
+	// suppresses its duplicates.
+	Window time.Duration
+
+	// Threshold is the share of distinct words two memories must have in
+	// common (0-1) to be duplicates.
+	Threshold float64
+
+	// BurstSize is the number of suppressed duplicates of one memory that
+	// raises a DuplicateBurst.
+	BurstSize int
+}
+
+// DuplicateBurst reports a memory recorded again and again within the
+// duplicate window, typically by an agent stuck in a loop.
+type DuplicateBurst struct {
+	TenantID  string
+	ProjectID string
+	MemoryID  string // the memory the duplicates repeat
+	Title     string
+
+	Suppressed int       // duplicates suppressed so far
+	FirstSeen  time.Time // first suppressed duplicate
+	LastSeen   time.Time // latest suppressed duplicate
+}
+
+// DuplicateListener is called once per burst, when BurstSize duplicates of
+// a memory have been suppressed.
+type DuplicateListener func(ctx context.Context, burst DuplicateBurst)
+
+// WithDuplicateGuard suppresses memories that repeat one the same requester
+// recorded in the same project within cfg.Window. Record rejects them with
+// ErrDuplicateMemory instead of letting the bank fill with copies until the
+// next consolidation run, and a burst of cfg.BurstSize duplicates is logged
+// and reported to the DuplicateListener once.
+//
+// Memories are compared by the words of their title and content, so the
+// check costs no embedding. Recent memories are remembered in process only.
+func WithDuplicateGuard(cfg DuplicateGuardConfig) ServiceOption {
+	return func(s *Service) {
+		if cfg.Window <= 0 {
+			cfg.Window = DefaultDuplicateWindow
+		}
+		if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+			cfg.Threshold = DefaultDuplicateThreshold
+		}
+		if cfg.BurstSize <= 0 {
+			cfg.BurstSize = DefaultDuplicateBurst
+		}
+		s.duplicates = &duplicateGuard{
+			config: cfg,
+			now:    time.Now,
+			recent: make(map[string][]*recentRecord),
+		}
+	}
+}
+
+// WithDuplicateListener sets a listener called for each burst of
+// duplicates. It has no effect without WithDuplicateGuard.
+func WithDuplicateListener(l DuplicateListener) ServiceOption {
+	return func(s *Service) {
+		s.onDuplicateBurst = l
+	}
+}
+
+// duplicateGuard remembers recently recorded memories per project.
+type duplicateGuard struct {
+	config DuplicateGuardConfig
+	now    func() time.Time
+
+	mu     sync.Mutex
+	recent map[string][]*recentRecord // keyed by tenant and project, oldest first
+}
+
+// recentRecord is a recently recorded memory and the duplicates it
+// suppressed.
+type recentRecord struct {
+	id      string
+	title   string
+	owner   string
+	outcome Outcome
+	words   map[string]struct{}
+
+	lastSeen   time.Time // recorded, or last repeated
+	suppressed int
+	firstDup   time.Time
+}
+
+// admit remembers memory unless it duplicates a recent memory, which it
+// returns instead with its suppressed count updated. burst reports that
+// this duplicate completes a burst.
+func (g *duplicateGuard) admit(key string, memory *Memory) (dup recentRecord, burst bool, ok bool) {
+	words := wordSet(memory.Title + " " + memory.Content)
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	records := g.unexpired(key, now)
+	for _, r := range records {
+		if r.owner != memory.OwnerID || r.outcome != memory.Outcome || wordOverlap(r.words, words) < g.config.Threshold {
+			continue
+		}
+		r.lastSeen = now
+		r.suppressed++
+		if r.suppressed == 1 {
+			r.firstDup = now
+		}
+		g.recent[key] = records
+		return *r, r.suppressed == g.config.BurstSize, false
+	}
+
+	records = append(records, &recentRecord{
+		id:       memory.ID,
+		title:    memory.Title,
+		owner:    memory.OwnerID,
+		outcome:  memory.Outcome,
+		words:    words,
+		lastSeen: now,
+	})
+	if len(records) > maxRecentRecords {
+		records = records[len(records)-maxRecentRecords:]
+	}
+	g.recent[key] = records
+	return recentRecord{}, false, true
+}
+
+// forget drops a memory admitted but not stored, so a retry is not taken
+// for its duplicate.
+func (g *duplicateGuard) forget(key, id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	records := g.recent[key]
+	for i, r := range records {
+		if r.id == id {
+			g.recent[key] = append(records[:i], records[i+1:]...)
+			return
+		}
+	}
+}
+
+// unexpired returns the memories of key last seen within the window.
+// Callers hold g.mu.
+func (g *duplicateGuard) unexpired(key string, now time.Time) []*recentRecord {
+	records := g.recent[key]
+	live := records[:0]
+	for _, r := range records {
+		if now.Sub(r.lastSeen) <= g.config.Window {
+			live = append(live, r)
+		}
+	}
+	if len(live) == 0 {
+		delete(g.recent, key)
+		return nil
+	}
+	return live
+}
+
+// duplicateKey scopes recent memories to the tenant and project.
+func duplicateKey(ctx context.Context, projectID string) (tenantID, key string) {
+	if tenant, err := vectorstore.TenantFromContext(ctx); err == nil {
+		tenantID = tenant.TenantID
+	}
+	return tenantID, tenantID + "/" + projectID
+}
+
+// admitRecord checks a memory about to be recorded against the duplicate
+// guard. ctx must be scoped to the memory's tenant.
+func (s *Service) admitRecord(ctx context.Context, memory *Memory) error {
+	if s.duplicates == nil {
+		return nil
+	}
+	tenantID, key := duplicateKey(ctx, memory.ProjectID)
+	dup, burst, ok := s.duplicates.admit(key, memory)
+	if ok {
+		return nil
+	}
+
+	if s.duplicateCounter != nil {
+		s.duplicateCounter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("project_id", memory.ProjectID),
+		))
+	}
+	s.logger.Debug("duplicate memory suppressed",
+		zap.String("project_id", memory.ProjectID),
+		zap.String("duplicate_of", dup.id),
+		zap.Int("suppressed", dup.suppressed))
+	if burst {
+		s.logger.Warn("burst of duplicate memories suppressed",
+			zap.String("project_id", memory.ProjectID),
+			zap.String("memory_id", dup.id),
+			zap.String("title", dup.title),
+			zap.Int("suppressed", dup.suppressed),
+			zap.Duration("within", dup.lastSeen.Sub(dup.firstDup)))
+		if s.onDuplicateBurst != nil {
+			s.onDuplicateBurst(ctx, DuplicateBurst{
+				TenantID:   tenantID,
+				ProjectID:  memory.ProjectID,
+				MemoryID:   dup.id,
+				Title:      dup.title,
+				Suppressed: dup.suppressed,
+				FirstSeen:  dup.firstDup,
+				LastSeen:   dup.lastSeen,
+			})
+		}
+	}
+
+	return &ctxerrors.Error{
+		Code:    ctxerrors.CodeInvalidInput,
+		Message: fmt.Sprintf("memory repeats %s (%q), recorded recently; not recorded again", dup.id, dup.title),
+		Details: map[string]any{"reason": "duplicate", "duplicate_of": dup.id, "suppressed": dup.suppressed},
+		Err:     ErrDuplicateMemory,
+	}
+}
+
+// forgetRecord undoes admitRecord for a memory that was not stored.
+func (s *Service) forgetRecord(ctx context.Context, memory *Memory) {
+	if s.duplicates == nil {
+		return
+	}
+	_, key := duplicateKey(ctx, memory.ProjectID)
+	s.duplicates.forget(key, memory.ID)
+}
+
+// wordSet returns the distinct lowercased words of text.
+func wordSet(text string) map[string]struct{} {
+	words := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = struct{}{}
+	}
+	return words
+}
+
+// wordOverlap is the Jaccard similarity of two word sets.
+func wordOverlap(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if _, ok := b[word]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,146 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func newDuplicateTestService(t *testing.T, store vectorstore.Store, opts ...ServiceOption) (*Service, *time.Time) {
+	t.Helper()
+	opts = append([]ServiceOption{WithDefaultTenant("test-tenant"), WithDuplicateGuard(DuplicateGuardConfig{Window: time.Minute})}, opts...)
+	svc, err := NewService(store, zap.NewNop(), opts...)
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	svc.duplicates.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestService_RecordSuppressesDuplicates(t *testing.T) {
+	ctx := context.Background()
+	collection, _ := project.GetCollectionName("project-a", project.CollectionMemories)
+
+	t.Run("rejects a repeat within the window", func(t *testing.T) {
+		store := newBatchStore()
+		svc, now := newDuplicateTestService(t, store)
+
+		original := batchMemory(t, "project-a", "Run migrations first")
+		require.NoError(t, svc.Record(ctx, original))
+
+		repeat := batchMemory(t, "project-a", "Run migrations first!")
+		err := svc.Record(ctx, repeat)
+		require.ErrorIs(t, err, ErrDuplicateMemory)
+		assert.Equal(t, ctxerrors.CodeInvalidInput, ctxerrors.CodeOf(err))
+		details := ctxerrors.From(err).Details
+		assert.Equal(t, "duplicate", details["reason"])
+		assert.Equal(t, original.ID, details["duplicate_of"])
+		assert.Len(t, store.collections[collection], 1)
+
+		// Each repeat extends the window
+		*now = now.Add(50 * time.Second)
+		assert.ErrorIs(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")), ErrDuplicateMemory)
+		*now = now.Add(50 * time.Second)
+		assert.ErrorIs(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")), ErrDuplicateMemory)
+
+		*now = now.Add(2 * time.Minute)
+		assert.NoError(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")))
+	})
+
+	t.Run("records distinct memories", func(t *testing.T) {
+		svc, _ := newDuplicateTestService(t, newBatchStore())
+		require.NoError(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")))
+
+		different := batchMemory(t, "project-a", "Cache modules in CI")
+		different.Content = "Cache the Go module download directory between CI runs to halve build times"
+		assert.NoError(t, svc.Record(ctx, different))
+
+		failure := batchMemory(t, "project-a", "Run migrations first")
+		failure.Outcome = OutcomeFailure
+		assert.NoError(t, svc.Record(ctx, failure), "a different outcome is not a duplicate")
+
+		otherOwner := batchMemory(t, "project-a", "Run migrations first")
+		otherOwner.OwnerID = "bob"
+		assert.NoError(t, svc.Record(ctx, otherOwner), "another requester's memory is not a duplicate")
+
+		assert.NoError(t, svc.Record(ctx, batchMemory(t, "project-b", "Run migrations first")), "another project's memory is not a duplicate")
+	})
+
+	t.Run("reports one warning per burst", func(t *testing.T) {
+		var bursts []DuplicateBurst
+		svc, now := newDuplicateTestService(t, newBatchStore(), WithDuplicateListener(func(ctx context.Context, burst DuplicateBurst) {
+			bursts = append(bursts, burst)
+		}))
+
+		original := batchMemory(t, "project-a", "Run migrations first")
+		require.NoError(t, svc.Record(ctx, original))
+		for i := 0; i < 5; i++ {
+			*now = now.Add(time.Second)
+			assert.ErrorIs(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")), ErrDuplicateMemory)
+		}
+
+		require.Len(t, bursts, 1)
+		assert.Equal(t, "test-tenant", bursts[0].TenantID)
+		assert.Equal(t, "project-a", bursts[0].ProjectID)
+		assert.Equal(t, original.ID, bursts[0].MemoryID)
+		assert.Equal(t, DefaultDuplicateBurst, bursts[0].Suppressed)
+		assert.Equal(t, 2*time.Second, bursts[0].LastSeen.Sub(bursts[0].FirstSeen))
+	})
+
+	t.Run("forgets a memory that was not stored", func(t *testing.T) {
+		store := newBatchStore()
+		store.failCollection = collection
+		svc, _ := newDuplicateTestService(t, store)
+
+		require.Error(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")))
+		store.failCollection = ""
+		assert.NoError(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")))
+	})
+
+	t.Run("consolidated memories are not checked", func(t *testing.T) {
+		svc, _ := newDuplicateTestService(t, newBatchStore())
+		require.NoError(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")))
+		assert.NoError(t, svc.record(ctx, batchMemory(t, "project-a", "Run migrations first"), false))
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		store := newBatchStore()
+		svc, _ := newDuplicateTestService(t, store)
+
+		result, err := svc.RecordBatch(ctx, []*Memory{
+			batchMemory(t, "project-a", "Run migrations first"),
+			batchMemory(t, "project-a", "Run migrations first"),
+		}, BatchOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, BatchSkipped, result.Items[0].Status)
+		assert.Equal(t, BatchRejected, result.Items[1].Status)
+		assert.ErrorIs(t, result.Items[1].Err, ErrDuplicateMemory)
+
+		// The skipped memory was forgotten, so it can be recorded
+		result, err = svc.RecordBatch(ctx, []*Memory{batchMemory(t, "project-a", "Run migrations first")}, BatchOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Recorded)
+		assert.Len(t, store.collections[collection], 1)
+	})
+
+	t.Run("off without the option", func(t *testing.T) {
+		svc, err := NewService(newBatchStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+		require.NoError(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")))
+		assert.NoError(t, svc.Record(ctx, batchMemory(t, "project-a", "Run migrations first")))
+	})
+}
+
+func TestWordOverlap(t *testing.T) {
+	a := wordSet("Run the migrations before the integration tests")
+	assert.Equal(t, 1.0, wordOverlap(a, wordSet("run the MIGRATIONS, before the integration-tests!")))
+	assert.InDelta(t, 5.0/7.0, wordOverlap(a, wordSet("Run the migrations before the unit tests")), 0.001)
+	assert.Zero(t, wordOverlap(a, wordSet("")))
+}
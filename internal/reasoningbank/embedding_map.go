@@ -0,0 +1,374 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ProjectionPCA is the projection method of an EmbeddingMap.
+	ProjectionPCA = "pca"
+
+	// pcaIterations bounds the power iterations per principal component.
+	pcaIterations = 200
+
+	// pcaTolerance stops power iteration once the component stops moving.
+	pcaTolerance = 1e-9
+
+	// clusterLabelLength caps a cluster label derived from a title.
+	clusterLabelLength = 40
+)
+
+// EmbeddingMap is a 2D projection of a project's memory embeddings, with
+// the clusters consolidation would merge, for visualizing where knowledge
+// is concentrated or duplicated.
+type EmbeddingMap struct {
+	ProjectID string `json:"project_id"`
+
+	// Method is the projection used, currently always ProjectionPCA.
+	Method string `json:"method"`
+
+	// Threshold is the similarity threshold clusters were formed with.
+	Threshold float64 `json:"threshold"`
+
+	// ExplainedVariance is the share of the embeddings' variance captured
+	// by the x and y axes. A low total means the map flattens structure
+	// that is present in the full embedding space.
+	ExplainedVariance [2]float64 `json:"explained_variance"`
+
+	Clusters []EmbeddingCluster `json:"clusters"`
+	Points   []EmbeddingPoint   `json:"points"`
+}
+
+// EmbeddingCluster is a cluster of similar memories in an EmbeddingMap.
+type EmbeddingCluster struct {
+	// Index is the 1-based cluster number points refer to.
+	Index int `json:"index"`
+
+	// Label is the tag most members share, or the title of the most
+	// trusted member when they share none.
+	Label string `json:"label"`
+
+	Size              int     `json:"size"`
+	AverageSimilarity float64 `json:"average_similarity"`
+	MinSimilarity     float64 `json:"min_similarity"`
+}
+
+// EmbeddingPoint is one memory in an EmbeddingMap.
+type EmbeddingPoint struct {
+	ID    string  `json:"id"`
+	Title string  `json:"title"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+
+	// Cluster is the index of the memory's cluster, 0 when it is in none.
+	Cluster int `json:"cluster"`
+
+	Outcome    Outcome `json:"outcome"`
+	Confidence float64 `json:"confidence"`
+
+	// Color encodes confidence from red (0) through yellow to green (1)
+	// as a hex RGB string.
+	Color string `json:"color"`
+}
+
+// EmbeddingMap projects a project's memory embeddings onto their first two
+// principal components and labels the clusters PreviewClusters would
+// report with the same options. Tags, Outcome and Query select memories as
+// they do for Consolidate; nothing is changed and the LLM is not called.
+func (d *Distiller) EmbeddingMap(ctx context.Context, projectID string, opts ConsolidationOptions) (*EmbeddingMap, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	threshold, err := consolidationThreshold(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keep, err := d.consolidationFilter(ctx, projectID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	memVecs, _, err := d.memoryVectors(ctx, projectID, keep)
+	if err != nil {
+		return nil, err
+	}
+	memVecs = sameDimension(memVecs)
+
+	m := &EmbeddingMap{
+		ProjectID: projectID,
+		Method:    ProjectionPCA,
+		Threshold: threshold,
+		Clusters:  []EmbeddingCluster{},
+		Points:    make([]EmbeddingPoint, 0, len(memVecs)),
+	}
+
+	clusterOf := make(map[string]int)
+	if len(memVecs) >= 2 {
+		clusters := d.clusterMemoryVectors(memVecs, threshold)
+		for i, c := range clusters {
+			for _, member := range c.Members {
+				clusterOf[member.ID] = i + 1
+			}
+			m.Clusters = append(m.Clusters, EmbeddingCluster{
+				Index:             i + 1,
+				Label:             clusterLabel(c.Members),
+				Size:              len(c.Members),
+				AverageSimilarity: c.AverageSimilarity,
+				MinSimilarity:     c.MinSimilarity,
+			})
+		}
+	}
+
+	vectors := make([][]float32, len(memVecs))
+	for i, mv := range memVecs {
+		vectors[i] = mv.vector
+	}
+	coords, explained := projectPCA(vectors)
+	m.ExplainedVariance = explained
+
+	for i, mv := range memVecs {
+		m.Points = append(m.Points, EmbeddingPoint{
+			ID:         mv.memory.ID,
+			Title:      mv.memory.Title,
+			X:          coords[i][0],
+			Y:          coords[i][1],
+			Cluster:    clusterOf[mv.memory.ID],
+			Outcome:    mv.memory.Outcome,
+			Confidence: mv.memory.Confidence,
+			Color:      confidenceColor(mv.memory.Confidence),
+		})
+	}
+
+	d.logger.Info("built embedding map",
+		zap.String("project_id", projectID),
+		zap.Int("points", len(m.Points)),
+		zap.Int("clusters", len(m.Clusters)))
+
+	return m, nil
+}
+
+// sameDimension drops vectors whose dimension differs from the first one's,
+// which happens only when memories were embedded by different models.
+func sameDimension(memVecs []memoryWithVector) []memoryWithVector {
+	if len(memVecs) == 0 {
+		return memVecs
+	}
+	dim := len(memVecs[0].vector)
+	kept := memVecs[:0]
+	for _, mv := range memVecs {
+		if len(mv.vector) == dim {
+			kept = append(kept, mv)
+		}
+	}
+	return kept
+}
+
+// projectPCA projects vectors onto their first two principal components,
+// found by power iteration on the covariance so the d×d matrix is never
+// built. It returns the coordinates and the share of variance each axis
+// explains. Component signs are fixed so the result is deterministic.
+func projectPCA(vectors [][]float32) ([][2]float64, [2]float64) {
+	coords := make([][2]float64, len(vectors))
+	var explained [2]float64
+	if len(vectors) < 2 {
+		return coords, explained
+	}
+
+	n, dim := len(vectors), len(vectors[0])
+	mean := make([]float64, dim)
+	for _, v := range vectors {
+		for j, x := range v {
+			mean[j] += float64(x)
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(n)
+	}
+	centered := make([][]float64, n)
+	total := 0.0
+	for i, v := range vectors {
+		centered[i] = make([]float64, dim)
+		for j, x := range v {
+			c := float64(x) - mean[j]
+			centered[i][j] = c
+			total += c * c
+		}
+	}
+	if total == 0 {
+		return coords, explained
+	}
+
+	var components [][]float64
+	for k := 0; k < 2; k++ {
+		component, variance := principalComponent(centered, components)
+		if component == nil {
+			break
+		}
+		components = append(components, component)
+		explained[k] = variance / total
+		for i, row := range centered {
+			coords[i][k] = dot(row, component)
+		}
+	}
+	return coords, explained
+}
+
+// principalComponent finds the direction of greatest variance of rows
+// orthogonal to the given components, and the variance along it. It returns
+// nil when no variance is left.
+func principalComponent(rows [][]float64, orthogonalTo [][]float64) ([]float64, float64) {
+	// Start from the row with the most remaining variance, which is never
+	// orthogonal to the component being sought
+	var v []float64
+	best := 0.0
+	for _, row := range rows {
+		candidate := append([]float64(nil), row...)
+		orthogonalize(candidate, orthogonalTo)
+		if norm := dot(candidate, candidate); norm > best {
+			best, v = norm, candidate
+		}
+	}
+	if v == nil || best < pcaTolerance {
+		return nil, 0
+	}
+	normalize(v)
+
+	next := make([]float64, len(v))
+	variance := 0.0
+	for iter := 0; iter < pcaIterations; iter++ {
+		// next = Xᵀ(Xv)
+		for j := range next {
+			next[j] = 0
+		}
+		variance = 0
+		for _, row := range rows {
+			p := dot(row, v)
+			variance += p * p
+			for j, x := range row {
+				next[j] += p * x
+			}
+		}
+		orthogonalize(next, orthogonalTo)
+		if normalize(next) == 0 {
+			return nil, 0
+		}
+		delta := 0.0
+		for j := range v {
+			delta += math.Abs(next[j] - v[j])
+		}
+		copy(v, next)
+		if delta < pcaTolerance {
+			break
+		}
+	}
+
+	// Make the largest coordinate positive so signs are stable
+	largest := 0
+	for j := range v {
+		if math.Abs(v[j]) > math.Abs(v[largest]) {
+			largest = j
+		}
+	}
+	if v[largest] < 0 {
+		for j := range v {
+			v[j] = -v[j]
+		}
+	}
+	return v, variance
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// orthogonalize removes the projections of v onto the unit vectors basis.
+func orthogonalize(v []float64, basis [][]float64) {
+	for _, b := range basis {
+		p := dot(v, b)
+		for j := range v {
+			v[j] -= p * b[j]
+		}
+	}
+}
+
+// normalize scales v to unit length and returns its previous length.
+func normalize(v []float64) float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return 0
+	}
+	for j := range v {
+		v[j] /= norm
+	}
+	return norm
+}
+
+// clusterLabel names a cluster after the tag most of its members share,
+// breaking ties alphabetically, or after its most confident member's title
+// when no tag is shared.
+func clusterLabel(members []*Memory) string {
+	counts := make(map[string]int)
+	for _, m := range members {
+		seen := make(map[string]bool)
+		for _, tag := range m.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				counts[tag]++
+			}
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag, count := range counts {
+		if count >= 2 {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		sort.Slice(tags, func(i, j int) bool {
+			if counts[tags[i]] != counts[tags[j]] {
+				return counts[tags[i]] > counts[tags[j]]
+			}
+			return tags[i] < tags[j]
+		})
+		return tags[0]
+	}
+
+	var best *Memory
+	for _, m := range members {
+		if best == nil || m.Confidence > best.Confidence {
+			best = m
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	runes := []rune(best.Title)
+	if len(runes) > clusterLabelLength {
+		return string(runes[:clusterLabelLength-3]) + "..."
+	}
+	return best.Title
+}
+
+// confidenceColor maps confidence onto a red-yellow-green scale.
+func confidenceColor(confidence float64) string {
+	confidence = math.Max(0, math.Min(1, confidence))
+	type rgb struct{ r, g, b float64 }
+	low, mid, high := rgb{215, 48, 39}, rgb{254, 224, 139}, rgb{26, 152, 80}
+
+	from, to, t := low, mid, confidence*2
+	if confidence > 0.5 {
+		from, to, t = mid, high, (confidence-0.5)*2
+	}
+	lerp := func(a, b float64) int { return int(math.Round(a + (b-a)*t)) }
+	return fmt.Sprintf("#%02x%02x%02x", lerp(from.r, to.r), lerp(from.g, to.g), lerp(from.b, to.b))
+}
@@ -0,0 +1,113 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEmbeddingMap(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+
+	svc := &Service{
+		store:         newMockStore(),
+		embedder:      newMockEmbedder(384),
+		logger:        zap.NewNop(),
+		defaultTenant: "test-tenant",
+	}
+	distiller, err := NewDistiller(svc, zap.NewNop())
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		mem, _ := NewMemory(projectID, fmt.Sprintf("Error handling pattern %d", i),
+			"Content for Error handling\n\nWrap errors with context", OutcomeSuccess, []string{"errors", fmt.Sprintf("tag-%d", i)})
+		require.NoError(t, svc.Record(ctx, mem))
+	}
+	other, _ := NewMemory(projectID, "Database migrations",
+		"Run migrations in a transaction and keep them reversible", OutcomeFailure, []string{"db"})
+	require.NoError(t, svc.Record(ctx, other))
+
+	m, err := distiller.EmbeddingMap(ctx, projectID, ConsolidationOptions{SimilarityThreshold: 0.85})
+	require.NoError(t, err)
+
+	assert.Equal(t, ProjectionPCA, m.Method)
+	assert.Equal(t, 0.85, m.Threshold)
+	require.Len(t, m.Points, 3)
+	require.Len(t, m.Clusters, 1)
+	assert.Equal(t, "errors", m.Clusters[0].Label)
+	assert.Equal(t, 2, m.Clusters[0].Size)
+
+	clustered := 0
+	for _, p := range m.Points {
+		if p.ID == other.ID {
+			assert.Equal(t, 0, p.Cluster)
+		} else {
+			assert.Equal(t, 1, p.Cluster)
+			clustered++
+		}
+		assert.Regexp(t, `^#[0-9a-f]{6}$`, p.Color)
+	}
+	assert.Equal(t, 2, clustered)
+	assert.LessOrEqual(t, m.ExplainedVariance[0]+m.ExplainedVariance[1], 1.0+1e-9)
+
+	t.Run("validates options", func(t *testing.T) {
+		_, err := distiller.EmbeddingMap(ctx, "", ConsolidationOptions{})
+		assert.Equal(t, ErrEmptyProjectID, err)
+
+		_, err = distiller.EmbeddingMap(ctx, projectID, ConsolidationOptions{SimilarityThreshold: 2})
+		assert.Error(t, err)
+	})
+}
+
+func TestProjectPCA(t *testing.T) {
+	// Points spread along x, with a little uncorrelated spread along y and
+	// none along z
+	vectors := [][]float32{
+		{-2, 0.5, 1},
+		{-1, -0.5, 1},
+		{1, -0.5, 1},
+		{2, 0.5, 1},
+	}
+	coords, explained := projectPCA(vectors)
+	require.Len(t, coords, 4)
+
+	assert.InDelta(t, 10.0/11.0, explained[0], 1e-6)
+	assert.InDelta(t, 1.0/11.0, explained[1], 1e-6)
+	for i, want := range [][2]float64{{-2, 0.5}, {-1, -0.5}, {1, -0.5}, {2, 0.5}} {
+		assert.InDelta(t, want[0], coords[i][0], 1e-6)
+		assert.InDelta(t, want[1], coords[i][1], 1e-6)
+	}
+
+	t.Run("degenerate input", func(t *testing.T) {
+		coords, explained := projectPCA([][]float32{{1, 2}})
+		assert.Equal(t, [][2]float64{{0, 0}}, coords)
+		assert.Equal(t, [2]float64{}, explained)
+
+		coords, _ = projectPCA([][]float32{{1, 2}, {1, 2}})
+		assert.Equal(t, [][2]float64{{0, 0}, {0, 0}}, coords)
+	})
+}
+
+func TestClusterLabel(t *testing.T) {
+	assert.Equal(t, "auth", clusterLabel([]*Memory{
+		{Title: "A", Tags: []string{"auth", "tokens"}},
+		{Title: "B", Tags: []string{"auth", "tokens"}},
+		{Title: "C", Tags: []string{"auth"}},
+	}))
+	assert.Equal(t, "Trusted", clusterLabel([]*Memory{
+		{Title: "Untrusted", Confidence: 0.2, Tags: []string{"a"}},
+		{Title: "Trusted", Confidence: 0.9, Tags: []string{"b"}},
+	}))
+}
+
+func TestConfidenceColor(t *testing.T) {
+	assert.Equal(t, "#d73027", confidenceColor(0))
+	assert.Equal(t, "#fee08b", confidenceColor(0.5))
+	assert.Equal(t, "#1a9850", confidenceColor(1))
+	assert.Equal(t, "#1a9850", confidenceColor(1.5))
+}
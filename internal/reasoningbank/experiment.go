@@ -0,0 +1,372 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/reranker"
+)
+
+// Arm identifies the ranking configuration that served a search.
+type Arm string
+
+const (
+	// ArmControl serves Experiment.Control, usually the current ranking.
+	ArmControl Arm = "control"
+
+	// ArmTreatment serves Experiment.Treatment, the ranking under test.
+	ArmTreatment Arm = "treatment"
+)
+
+// maxExperimentSessions bounds the sessions whose served memories are kept
+// for joining with outcomes. The oldest sessions are forgotten first.
+const maxExperimentSessions = 10000
+
+// RankingConfig is the ranking an experiment arm serves. Boosts are score
+// multipliers: 0 keeps the default and 1 turns the boost off.
+type RankingConfig struct {
+	// Rerank reorders results with the experiment's reranker.
+	Rerank bool `json:"rerank"`
+
+	// ConsolidationBoost multiplies the score of consolidated memories
+	// (default: the service's ScoringConfig.ConsolidationBoost).
+	ConsolidationBoost float32 `json:"consolidation_boost,omitempty"`
+
+	// EntityBoost multiplies the score of memories that mention entities
+	// from the query (default 1.3).
+	EntityBoost float32 `json:"entity_boost,omitempty"`
+}
+
+// consolidationBoost returns the consolidated-memory multiplier; a nil
+// config is the default ranking, which multiplies by def.
+func (r *RankingConfig) consolidationBoost(def float32) float32 {
+	if r == nil || r.ConsolidationBoost == 0 {
+		return def
+	}
+	return r.ConsolidationBoost
+}
+
+// entityBoost returns the entity-match multiplier; a nil config is the
+// default ranking.
+func (r *RankingConfig) entityBoost() float32 {
+	if r == nil || r.EntityBoost == 0 {
+		return entityBoostFactor
+	}
+	return r.EntityBoost
+}
+
+// Experiment compares two ranking configurations on live searches.
+//
+// Each session is assigned to an arm by hashing the experiment name and
+// session ID, so a session keeps its arm across searches and restarts.
+// Searches without a session (see ContextWithSession) get the default
+// ranking and are not counted. Outcomes reported for a memory an arm served
+// to the session are credited to that arm.
+type Experiment struct {
+	// Name identifies the experiment in logs, metrics and reports. Renaming
+	// it reshuffles the assignment of sessions to arms.
+	Name string
+
+	// Control and Treatment are the rankings being compared.
+	Control   RankingConfig
+	Treatment RankingConfig
+
+	// TreatmentPercent is the share of sessions served Treatment, 0-100
+	// (default 50).
+	TreatmentPercent int
+
+	// Reranker serves arms with Rerank set (default: the simple reranker).
+	Reranker reranker.Reranker
+}
+
+// Validate checks the experiment and applies defaults.
+func (e *Experiment) Validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if e.TreatmentPercent < 0 || e.TreatmentPercent > 100 {
+		return fmt.Errorf("experiment treatment percent must be between 0 and 100, got %d", e.TreatmentPercent)
+	}
+	for arm, r := range map[Arm]RankingConfig{ArmControl: e.Control, ArmTreatment: e.Treatment} {
+		if r.ConsolidationBoost < 0 || r.EntityBoost < 0 {
+			return fmt.Errorf("experiment %s boosts must not be negative", arm)
+		}
+	}
+	if e.TreatmentPercent == 0 {
+		e.TreatmentPercent = 50
+	}
+	if e.Reranker == nil {
+		e.Reranker = reranker.NewSimpleReranker()
+	}
+	return nil
+}
+
+// Assign returns the arm serving sessionID.
+func (e *Experiment) Assign(sessionID string) Arm {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(e.Name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(sessionID))
+	if int(h.Sum32()%100) < e.TreatmentPercent {
+		return ArmTreatment
+	}
+	return ArmControl
+}
+
+// Ranking returns the ranking configuration of arm.
+func (e *Experiment) Ranking(arm Arm) *RankingConfig {
+	if arm == ArmTreatment {
+		return &e.Treatment
+	}
+	return &e.Control
+}
+
+// WithExperiment serves memory searches under a ranking experiment.
+func WithExperiment(e *Experiment) ServiceOption {
+	return func(s *Service) {
+		if err := e.Validate(); err != nil {
+			s.initErr = fmt.Errorf("invalid search experiment: %w", err)
+			return
+		}
+		s.experiment = e
+	}
+}
+
+type sessionKey struct{}
+
+// ContextWithSession attributes memory searches and outcomes made with ctx
+// to sessionID, which assigns them to an experiment arm.
+func ContextWithSession(ctx context.Context, sessionID string) context.Context {
+	if sessionID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// SessionFromContext returns the session set by ContextWithSession, or "".
+func SessionFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionKey{}).(string)
+	return sessionID
+}
+
+// ArmStats are the totals of one experiment arm.
+type ArmStats struct {
+	Arm         Arm           `json:"arm"`
+	Ranking     RankingConfig `json:"ranking"`
+	Sessions    int64         `json:"sessions"`
+	Searches    int64         `json:"searches"`
+	Served      int64         `json:"served"`    // Memories returned across searches
+	Outcomes    int64         `json:"outcomes"`  // Outcomes reported for served memories
+	Successes   int64         `json:"successes"` // Outcomes that succeeded
+	SuccessRate float64       `json:"success_rate"`
+}
+
+// ExperimentReport summarizes a running experiment since the service
+// started. Each search a session makes counts, including the sub-queries of
+// decomposed searches.
+type ExperimentReport struct {
+	Name             string     `json:"name"`
+	TreatmentPercent int        `json:"treatment_percent"`
+	Since            time.Time  `json:"since"`
+	Arms             []ArmStats `json:"arms"`
+}
+
+// experimentTracker joins the memories each session was served with the
+// outcomes it later reports.
+type experimentTracker struct {
+	mu       sync.Mutex
+	since    time.Time
+	arms     map[Arm]*ArmStats
+	sessions map[string]*experimentSession
+	order    []string // session IDs, oldest first
+
+	searchCounter  metric.Int64Counter
+	outcomeCounter metric.Int64Counter
+}
+
+type experimentSession struct {
+	arm    Arm
+	served map[string]struct{} // memory IDs
+}
+
+func newExperimentTracker(meter metric.Meter, logger *zap.Logger) *experimentTracker {
+	t := &experimentTracker{
+		since:    time.Now(),
+		arms:     map[Arm]*ArmStats{ArmControl: {Arm: ArmControl}, ArmTreatment: {Arm: ArmTreatment}},
+		sessions: make(map[string]*experimentSession),
+	}
+
+	var err error
+	t.searchCounter, err = meter.Int64Counter(
+		"contextd.memory.experiment.searches_total",
+		metric.WithDescription("Memory searches served under a ranking experiment, labeled by experiment and arm"),
+		metric.WithUnit("{search}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create experiment search counter", zap.Error(err))
+	}
+	t.outcomeCounter, err = meter.Int64Counter(
+		"contextd.memory.experiment.outcomes_total",
+		metric.WithDescription("Outcomes reported for memories served under a ranking experiment, labeled by experiment, arm and result"),
+		metric.WithUnit("{outcome}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create experiment outcome counter", zap.Error(err))
+	}
+	return t
+}
+
+// session returns the tracked session, starting it in arm if new. Must be
+// called with t.mu held.
+func (t *experimentTracker) session(sessionID string, arm Arm) *experimentSession {
+	if sess, ok := t.sessions[sessionID]; ok {
+		return sess
+	}
+	if len(t.order) >= maxExperimentSessions {
+		delete(t.sessions, t.order[0])
+		t.order = t.order[1:]
+	}
+	sess := &experimentSession{arm: arm, served: make(map[string]struct{})}
+	t.sessions[sessionID] = sess
+	t.order = append(t.order, sessionID)
+	t.arms[arm].Sessions++
+	return sess
+}
+
+// experimentArm returns the arm and ranking serving ctx's session. ok is
+// false when no experiment runs or the search has no session.
+func (s *Service) experimentArm(ctx context.Context) (arm Arm, ranking *RankingConfig, ok bool) {
+	if s.experiment == nil {
+		return "", nil, false
+	}
+	sessionID := SessionFromContext(ctx)
+	if sessionID == "" {
+		return "", nil, false
+	}
+	arm = s.experiment.Assign(sessionID)
+	return arm, s.experiment.Ranking(arm), true
+}
+
+// recordExposure logs that arm served memoryIDs to ctx's session.
+func (s *Service) recordExposure(ctx context.Context, projectID string, arm Arm, memoryIDs []string) {
+	sessionID := SessionFromContext(ctx)
+	t := s.experimentTracker
+
+	t.mu.Lock()
+	sess := t.session(sessionID, arm)
+	for _, id := range memoryIDs {
+		sess.served[id] = struct{}{}
+	}
+	stats := t.arms[arm]
+	stats.Searches++
+	stats.Served += int64(len(memoryIDs))
+	t.mu.Unlock()
+
+	if t.searchCounter != nil {
+		t.searchCounter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("experiment", s.experiment.Name),
+			attribute.String("arm", string(arm)),
+		))
+	}
+	s.logger.Info("search experiment exposure",
+		zap.String("experiment", s.experiment.Name),
+		zap.String("arm", string(arm)),
+		zap.String("session_id", sessionID),
+		zap.String("project_id", projectID),
+		zap.Strings("memory_ids", memoryIDs))
+}
+
+// recordExperimentOutcome credits an outcome to the arm that served
+// memoryID to sessionID, if any.
+func (s *Service) recordExperimentOutcome(ctx context.Context, memoryID, sessionID string, succeeded bool) {
+	if s.experiment == nil || sessionID == "" {
+		return
+	}
+	t := s.experimentTracker
+
+	t.mu.Lock()
+	sess, ok := t.sessions[sessionID]
+	if ok {
+		_, ok = sess.served[memoryID]
+	}
+	if ok {
+		stats := t.arms[sess.arm]
+		stats.Outcomes++
+		if succeeded {
+			stats.Successes++
+		}
+	}
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	result := "failure"
+	if succeeded {
+		result = "success"
+	}
+	if t.outcomeCounter != nil {
+		t.outcomeCounter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("experiment", s.experiment.Name),
+			attribute.String("arm", string(sess.arm)),
+			attribute.String("result", result),
+		))
+	}
+	s.logger.Info("search experiment outcome",
+		zap.String("experiment", s.experiment.Name),
+		zap.String("arm", string(sess.arm)),
+		zap.String("session_id", sessionID),
+		zap.String("memory_id", memoryID),
+		zap.Bool("succeeded", succeeded))
+}
+
+// ExperimentReport returns the per-arm totals of the running experiment, or
+// nil when none is configured.
+func (s *Service) ExperimentReport() *ExperimentReport {
+	if s.experiment == nil {
+		return nil
+	}
+	t := s.experimentTracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := &ExperimentReport{
+		Name:             s.experiment.Name,
+		TreatmentPercent: s.experiment.TreatmentPercent,
+		Since:            t.since,
+	}
+	for _, arm := range []Arm{ArmControl, ArmTreatment} {
+		stats := *t.arms[arm]
+		stats.Ranking = *s.experiment.Ranking(arm)
+		if stats.Outcomes > 0 {
+			stats.SuccessRate = float64(stats.Successes) / float64(stats.Outcomes)
+		}
+		report.Arms = append(report.Arms, stats)
+	}
+	return report
+}
+
+// memoryIDs returns the IDs of memories.
+func memoryIDs(memories []Memory) []string {
+	ids := make([]string, len(memories))
+	for i, m := range memories {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// scoredMemoryIDs returns the IDs of scored memories, in order.
+func scoredMemoryIDs(scored []ScoredMemory) []string {
+	ids := make([]string, len(scored))
+	for i, sm := range scored {
+		ids[i] = sm.Memory.ID
+	}
+	return ids
+}
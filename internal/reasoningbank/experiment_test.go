@@ -0,0 +1,164 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestExperiment_Validate(t *testing.T) {
+	e := &Experiment{Name: "rerank"}
+	require.NoError(t, e.Validate())
+	assert.Equal(t, 50, e.TreatmentPercent, "defaults to an even split")
+	assert.NotNil(t, e.Reranker)
+
+	assert.Error(t, (&Experiment{}).Validate(), "name is required")
+	assert.Error(t, (&Experiment{Name: "x", TreatmentPercent: 101}).Validate())
+	assert.Error(t, (&Experiment{Name: "x", Treatment: RankingConfig{EntityBoost: -1}}).Validate())
+
+	_, err := NewService(&mockStore{}, zap.NewNop(), WithExperiment(&Experiment{}))
+	assert.ErrorContains(t, err, "invalid search experiment")
+}
+
+func TestExperiment_Assign(t *testing.T) {
+	e := &Experiment{Name: "boost"}
+	require.NoError(t, e.Validate())
+
+	counts := map[Arm]int{}
+	for i := 0; i < 1000; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		arm := e.Assign(sessionID)
+		assert.Equal(t, arm, e.Assign(sessionID), "a session keeps its arm")
+		counts[arm]++
+	}
+	assert.InDelta(t, 500, counts[ArmTreatment], 75)
+
+	all := &Experiment{Name: "boost", TreatmentPercent: 100}
+	assert.Equal(t, ArmTreatment, all.Assign("session-1"))
+	assert.Equal(t, &all.Treatment, all.Ranking(ArmTreatment))
+	assert.Equal(t, &all.Control, all.Ranking(ArmControl))
+}
+
+func TestApplyScoreBoosting_ExperimentRanking(t *testing.T) {
+	svc := &Service{}
+	consolidated := &Memory{
+		Title:       "Retry policy",
+		Description: consolidatedPrefixSynthesized + " 3 memories",
+		State:       MemoryStateActive,
+	}
+
+	assert.InDelta(t, consolidatedMemoryBoost, svc.applyScoreBoosting(consolidated, 1, querySignals{}), 1e-6,
+		"no experiment keeps the default boost")
+	assert.InDelta(t, 1, svc.applyScoreBoosting(consolidated, 1, querySignals{ranking: &RankingConfig{ConsolidationBoost: 1}}), 1e-6,
+		"a boost of 1 turns it off")
+	assert.InDelta(t, 2, svc.applyScoreBoosting(consolidated, 1, querySignals{ranking: &RankingConfig{ConsolidationBoost: 2}}), 1e-6)
+}
+
+func TestService_ExperimentReport(t *testing.T) {
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "exp", ProjectID: "exp"})
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 3,
+	}, &keywordEmbedder{keywords: []string{"retry", "cache"}}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	exp := &Experiment{Name: "rerank", Treatment: RankingConfig{Rerank: true}}
+	svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("exp"), WithExperiment(exp))
+	require.NoError(t, err)
+
+	retry, err := NewMemory("exp", "Retry", "retry with backoff", OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.Record(ctx, retry))
+	cache, err := NewMemory("exp", "Cache", "cache warmup", OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.Record(ctx, cache))
+
+	// Find one session per arm
+	sessions := map[Arm]string{}
+	for i := 0; len(sessions) < 2; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		if _, ok := sessions[exp.Assign(sessionID)]; !ok {
+			sessions[exp.Assign(sessionID)] = sessionID
+		}
+	}
+
+	// Searches without a session are not part of the experiment
+	_, err = svc.Search(ctx, "exp", "retry", 1)
+	require.NoError(t, err)
+
+	for _, arm := range []Arm{ArmControl, ArmTreatment} {
+		results, err := svc.Search(ContextWithSession(ctx, sessions[arm]), "exp", "retry", 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, retry.ID, results[0].ID)
+	}
+
+	// Outcomes join with what the session was served
+	_, err = svc.RecordOutcome(ctx, retry.ID, true, sessions[ArmTreatment])
+	require.NoError(t, err)
+	_, err = svc.RecordOutcome(ctx, retry.ID, false, sessions[ArmControl])
+	require.NoError(t, err)
+	_, err = svc.RecordOutcome(ctx, cache.ID, true, sessions[ArmControl])
+	require.NoError(t, err, "memories the session was not served are not credited")
+
+	report := svc.ExperimentReport()
+	require.NotNil(t, report)
+	assert.Equal(t, "rerank", report.Name)
+	require.Len(t, report.Arms, 2)
+
+	control, treatment := report.Arms[0], report.Arms[1]
+	assert.Equal(t, ArmControl, control.Arm)
+	assert.Equal(t, int64(1), control.Sessions)
+	assert.Equal(t, int64(1), control.Searches)
+	assert.Equal(t, int64(1), control.Served)
+	assert.Equal(t, int64(1), control.Outcomes)
+	assert.Zero(t, control.SuccessRate)
+
+	assert.Equal(t, ArmTreatment, treatment.Arm)
+	assert.True(t, treatment.Ranking.Rerank)
+	assert.Equal(t, int64(1), treatment.Outcomes)
+	assert.Equal(t, int64(1), treatment.Successes)
+	assert.InDelta(t, 1, treatment.SuccessRate, 1e-9)
+
+	plain, err := NewService(store, zap.NewNop(), WithDefaultTenant("exp"))
+	require.NoError(t, err)
+	assert.Nil(t, plain.ExperimentReport())
+}
+
+func TestService_ExperimentMultiQueryExposure(t *testing.T) {
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "exp", ProjectID: "exp"})
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 3,
+	}, &keywordEmbedder{keywords: []string{"retry", "cache"}}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	exp := &Experiment{Name: "rerank", Treatment: RankingConfig{Rerank: true}}
+	svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("exp"), WithExperiment(exp))
+	require.NoError(t, err)
+	for _, content := range []string{"retry with backoff", "cache warmup"} {
+		mem, err := NewMemory("exp", content, content, OutcomeSuccess, nil)
+		require.NoError(t, err)
+		require.NoError(t, svc.Record(ctx, mem))
+	}
+
+	results, queries, err := svc.SearchMultiQuery(ContextWithSession(ctx, "session-1"), "exp", "retry the upload and warm the cache", 1)
+	require.NoError(t, err)
+	require.Greater(t, len(queries), 1)
+	require.Len(t, results, 1)
+
+	stats := svc.ExperimentReport().Arms[0]
+	if exp.Assign("session-1") == ArmTreatment {
+		stats = svc.ExperimentReport().Arms[1]
+	}
+	assert.Equal(t, int64(1), stats.Searches, "one search for the whole task")
+	assert.Equal(t, int64(1), stats.Served, "only fused results are served")
+}
@@ -0,0 +1,26 @@
+package reasoningbank
+
+import (
+	"context"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// filterExprKey is the context key for a query-time filter expression.
+type filterExprKey struct{}
+
+// ContextWithFilter restricts memory searches made with ctx to memories
+// whose metadata matches expr, e.g. "confidence >= 0.7 AND outcome = success".
+// A nil expr leaves ctx unchanged.
+func ContextWithFilter(ctx context.Context, expr *vectorstore.FilterExpr) context.Context {
+	if expr == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, filterExprKey{}, expr)
+}
+
+// FilterFromContext returns the filter set by ContextWithFilter, or nil.
+func FilterFromContext(ctx context.Context) *vectorstore.FilterExpr {
+	expr, _ := ctx.Value(filterExprKey{}).(*vectorstore.FilterExpr)
+	return expr
+}
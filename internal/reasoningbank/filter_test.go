@@ -0,0 +1,64 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestContextWithFilter(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FilterFromContext(ctx))
+	assert.Equal(t, ctx, ContextWithFilter(ctx, nil))
+
+	expr, err := vectorstore.ParseFilterExpr("confidence >= 0.7")
+	require.NoError(t, err)
+	scoped := ContextWithFilter(ctx, expr)
+	assert.Same(t, expr, FilterFromContext(scoped))
+
+	assert.NotEqual(t,
+		searchHash(ctx, "memory", "p", "q"),
+		searchHash(scoped, "memory", "p", "q"),
+		"cursors are bound to the filter")
+}
+
+func TestService_SearchFilter(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+	projectID := "project-filtered"
+
+	record := func(outcome Outcome, confidence float64) *Memory {
+		t.Helper()
+		m, err := NewMemory(projectID, "database migrations", "run migrations before deploy", outcome, nil)
+		require.NoError(t, err)
+		m.Confidence = confidence
+		require.NoError(t, svc.Record(ctx, m))
+		return m
+	}
+	strong := record(OutcomeSuccess, 0.9)
+	weak := record(OutcomeSuccess, 0.72)
+	failed := record(OutcomeFailure, 0.8)
+
+	search := func(filter string) []string {
+		t.Helper()
+		expr, err := vectorstore.ParseFilterExpr(filter)
+		require.NoError(t, err)
+		results, err := svc.SearchWithScores(ContextWithFilter(ctx, expr), projectID, "database migrations", 10)
+		require.NoError(t, err)
+		ids := make([]string, len(results))
+		for i, r := range results {
+			ids[i] = r.Memory.ID
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{strong.ID, failed.ID}, search("confidence >= 0.75"))
+	assert.ElementsMatch(t, []string{strong.ID}, search("confidence >= 0.75 AND outcome = success"))
+	assert.ElementsMatch(t, []string{weak.ID, failed.ID}, search("confidence < 0.75 OR outcome IN (failure)"))
+	assert.Empty(t, search("created_at < 2000-01-01"))
+}
@@ -0,0 +1,113 @@
+package reasoningbank
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// importanceUsageScale controls how quickly reach saturates. A memory
+	// retrieved or used in a successful session this many times has reach
+	// of roughly 0.63.
+	importanceUsageScale = 10.0
+
+	// importanceReachWeight and importanceImpactWeight split importance
+	// between how often a memory is used and how much it moves outcomes.
+	importanceReachWeight  = 0.4
+	importanceImpactWeight = 0.6
+
+	// importanceBoostFactor caps the search boost for a memory with
+	// importance 1.0. Kept small so importance only reorders results of
+	// similar relevance.
+	importanceBoostFactor = 0.1
+)
+
+// ComputeImportanceFromHybrid calculates how much a memory matters, as
+// opposed to how reliable it is (Confidence).
+//
+// Importance combines two parts, both learned from signals:
+//   - Reach: how often the memory is retrieved and used in successful
+//     sessions, saturating as usage grows.
+//   - Impact: how far sessions that used the memory lean towards success,
+//     smoothed so a single outcome does not dominate.
+//
+// A memory with no signals has importance 0. The result is in [0, 1].
+func ComputeImportanceFromHybrid(agg *SignalAggregate, recentSignals []Signal) float64 {
+	var usage, successes, failures float64
+	if agg != nil {
+		usage += float64(agg.UsagePos)
+		successes += float64(agg.OutcomePos)
+		failures += float64(agg.OutcomeNeg)
+	}
+	for _, sig := range recentSignals {
+		switch sig.Type {
+		case SignalUsage:
+			if sig.Positive {
+				usage++
+			}
+		case SignalOutcome:
+			if sig.Positive {
+				successes++
+			} else {
+				failures++
+			}
+		}
+	}
+
+	// Successful sessions count double towards reach: they show the memory
+	// was used, not just retrieved.
+	reach := 1 - math.Exp(-(usage+2*successes)/importanceUsageScale)
+
+	// Net success rate with a +1/+1 prior; memories tied to failures have
+	// no positive impact.
+	impact := (successes - failures) / (successes + failures + 2)
+	if impact < 0 {
+		impact = 0
+	}
+
+	return importanceReachWeight*reach + importanceImpactWeight*impact
+}
+
+// ComputeImportance calculates the current importance for a memory.
+func (c *ConfidenceCalculator) ComputeImportance(ctx context.Context, memoryID string) (float64, error) {
+	agg, err := c.store.GetAggregate(ctx, memoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	recentSignals, err := c.store.GetRecentSignals(ctx, memoryID, 30*24*time.Hour)
+	if err != nil {
+		return 0, err
+	}
+
+	return ComputeImportanceFromHybrid(agg, recentSignals), nil
+}
+
+// RankForRetention orders memories from most to least worth keeping:
+// by importance, then confidence, then most recently updated.
+// Pruning removes memories from the end of the slice first.
+func RankForRetention(memories []Memory) {
+	sort.SliceStable(memories, func(i, j int) bool {
+		a, b := memories[i], memories[j]
+		if a.Importance != b.Importance {
+			return a.Importance > b.Importance
+		}
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		return a.UpdatedAt.After(b.UpdatedAt)
+	})
+}
+
+// consolidatedImportance carries importance over to a consolidated memory.
+// Signals stay attached to the archived sources, so the merged memory starts
+// from the most important source rather than from zero.
+func consolidatedImportance(sources []*Memory) float64 {
+	var importance float64
+	for _, mem := range sources {
+		importance = math.Max(importance, mem.Importance)
+	}
+	return importance
+}
@@ -0,0 +1,95 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestComputeImportanceFromHybrid(t *testing.T) {
+	assert.Zero(t, ComputeImportanceFromHybrid(nil, nil), "no signals means no importance")
+
+	used := []Signal{
+		{Type: SignalUsage, Positive: true},
+		{Type: SignalUsage, Positive: true},
+	}
+	usedOnly := ComputeImportanceFromHybrid(nil, used)
+	assert.Greater(t, usedOnly, 0.0)
+
+	successful := append(used, Signal{Type: SignalOutcome, Positive: true}, Signal{Type: SignalOutcome, Positive: true})
+	assert.Greater(t, ComputeImportanceFromHybrid(nil, successful), usedOnly,
+		"use in successful sessions should matter more than retrieval alone")
+
+	failing := append(used, Signal{Type: SignalOutcome, Positive: false}, Signal{Type: SignalOutcome, Positive: false})
+	assert.Less(t, ComputeImportanceFromHybrid(nil, failing), ComputeImportanceFromHybrid(nil, successful))
+
+	// Explicit feedback speaks to reliability, not importance
+	assert.Equal(t, usedOnly, ComputeImportanceFromHybrid(nil, append(used, Signal{Type: SignalExplicit, Positive: true})))
+
+	// Aggregated history counts like recent signals and stays within range
+	heavy := ComputeImportanceFromHybrid(&SignalAggregate{UsagePos: 1000, OutcomePos: 1000}, nil)
+	assert.LessOrEqual(t, heavy, 1.0)
+	assert.Greater(t, heavy, 0.9)
+}
+
+func TestService_RecordOutcome_UpdatesImportance(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+
+	memory, _ := NewMemory("project-123", "Test Memory", "Test content", OutcomeSuccess, []string{"test"})
+	require.NoError(t, svc.Record(ctx, memory))
+
+	_, err = svc.RecordOutcome(ctx, memory.ID, true, "session-1")
+	require.NoError(t, err)
+
+	updated, err := svc.Get(ctx, memory.ID)
+	require.NoError(t, err)
+	assert.Greater(t, updated.Importance, 0.0)
+}
+
+func TestService_Search_RaisesImportance(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+
+	memory, _ := NewMemory("project-123", "Test Memory", "Test content", OutcomeSuccess, []string{"test"})
+	require.NoError(t, svc.Record(ctx, memory))
+
+	first, err := svc.SearchWithScores(ctx, "project-123", "test", 5)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	assert.Greater(t, first[0].Memory.Importance, 0.0, "a search hit is usage")
+
+	second, err := svc.SearchWithScores(ctx, "project-123", "test", 5)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Greater(t, second[0].Memory.Importance, first[0].Memory.Importance)
+}
+
+func TestRankForRetention(t *testing.T) {
+	now := time.Now()
+	memories := []Memory{
+		{ID: "old-unimportant", Confidence: 0.9, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "important", Importance: 0.5, Confidence: 0.2},
+		{ID: "new-unimportant", Confidence: 0.9, UpdatedAt: now},
+		{ID: "less-confident", Confidence: 0.5},
+	}
+
+	RankForRetention(memories)
+
+	ids := make([]string, len(memories))
+	for i, m := range memories {
+		ids[i] = m.ID
+	}
+	assert.Equal(t, []string{"important", "new-unimportant", "old-unimportant", "less-confident"}, ids)
+}
+
+func TestConsolidatedImportance(t *testing.T) {
+	assert.Zero(t, consolidatedImportance(nil))
+	assert.Equal(t, 0.7, consolidatedImportance([]*Memory{{Importance: 0.2}, {Importance: 0.7}}))
+}
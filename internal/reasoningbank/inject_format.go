@@ -0,0 +1,180 @@
+package reasoningbank
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+)
+
+// injectionGuidance opens every injection block. It tells the model how to
+// weigh the memories that follow.
+const injectionGuidance = `These memories come from earlier sessions, strongest first. Apply one only
+when its situation matches the current task; when memories disagree, prefer
+the higher-confidence one, and treat low-confidence memories as hints to
+verify. Entries marked AVOID record approaches that failed: do not repeat
+them.`
+
+const (
+	injectionOpen  = "<relevant_memories>"
+	injectionClose = "</relevant_memories>"
+
+	// minInjectionExcerpt is the fewest tokens of content worth keeping
+	// when a memory is trimmed to fit the budget.
+	minInjectionExcerpt = 16
+)
+
+// InjectionOptions controls FormatInjection.
+type InjectionOptions struct {
+	// MaxTokens caps the estimated size of the whole block. Zero means no
+	// cap.
+	MaxTokens int
+
+	// MinConfidence leaves out memories below this confidence.
+	MinConfidence float64
+}
+
+// Injection is a block of memories ready to insert into an agent prompt.
+type Injection struct {
+	// Text is the block, empty when no memory was included.
+	Text string `json:"text"`
+
+	// MemoryIDs are the included memories, in block order.
+	MemoryIDs []string `json:"memory_ids"`
+
+	// Omitted counts memories left out by MinConfidence or MaxTokens.
+	Omitted int `json:"omitted,omitempty"`
+
+	// Truncated is set when the last included memory was trimmed to fit.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Tokens is the estimated size of Text.
+	Tokens int `json:"tokens"`
+}
+
+// FormatInjection renders search results as a block for an agent prompt:
+// applicability guidance first, then the memories ordered by confidence,
+// with anti-patterns marked AVOID, cut off at opts.MaxTokens. Results of
+// equal confidence keep their search order. The best-matching chunk of a
+// long memory stands in for its content.
+//
+// Callers scrub memory content before formatting it.
+func FormatInjection(results []ScoredMemory, opts InjectionOptions) *Injection {
+	ranked := make([]ScoredMemory, 0, len(results))
+	for _, r := range results {
+		if r.Memory.Confidence >= opts.MinConfidence {
+			ranked = append(ranked, r)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Memory.Confidence > ranked[j].Memory.Confidence
+	})
+
+	out := &Injection{MemoryIDs: []string{}, Omitted: len(results) - len(ranked)}
+	if len(ranked) == 0 {
+		return out
+	}
+
+	var b strings.Builder
+	b.WriteString(injectionOpen + "\n" + injectionGuidance + "\n")
+	fixed := tokensOf(b.String()) + tokensOf("\n"+injectionClose+"\n")
+	remaining := opts.MaxTokens - fixed
+	if opts.MaxTokens > 0 && remaining <= 0 {
+		out.Omitted += len(ranked)
+		return out
+	}
+
+	for i, r := range ranked {
+		heading := "\n" + injectionHeading(i+1, &r.Memory) + "\n"
+		body := injectionBody(r)
+		entry := heading + body
+		if opts.MaxTokens > 0 && tokensOf(entry) > remaining {
+			// Trim the memory if a useful excerpt still fits, then stop
+			room := remaining - tokensOf(heading) - tokensOf(budgetMarker)
+			if room >= minInjectionExcerpt {
+				b.WriteString(heading + trimToTokens(body, room) + budgetMarker)
+				out.MemoryIDs = append(out.MemoryIDs, r.Memory.ID)
+				out.Truncated = true
+			}
+			out.Omitted += len(ranked) - len(out.MemoryIDs)
+			break
+		}
+		b.WriteString(entry)
+		remaining -= tokensOf(entry)
+		out.MemoryIDs = append(out.MemoryIDs, r.Memory.ID)
+	}
+	if len(out.MemoryIDs) == 0 {
+		return out
+	}
+
+	b.WriteString("\n" + injectionClose + "\n")
+	out.Text = b.String()
+	out.Tokens = tokensOf(out.Text)
+	return out
+}
+
+// budgetMarker ends a memory trimmed to fit the budget.
+const budgetMarker = " [...]\n"
+
+// injectionHeading is the numbered first line of a memory's entry.
+func injectionHeading(n int, m *Memory) string {
+	marker := ""
+	if m.Outcome == OutcomeFailure {
+		marker = "AVOID "
+	}
+	return fmt.Sprintf("%d. %s[%s confidence %.2f] %s",
+		n, marker, confidenceLabel(m.Confidence), m.Confidence, strings.Join(strings.Fields(m.Title), " "))
+}
+
+// injectionBody is a memory's content, indented under its heading.
+func injectionBody(r ScoredMemory) string {
+	text := r.Memory.Content
+	if r.Highlight != nil && r.Highlight.Text != "" {
+		text = r.Highlight.Text
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "   " + strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// confidenceLabel buckets a confidence score for the model.
+func confidenceLabel(confidence float64) string {
+	switch {
+	case confidence >= 0.8:
+		return "high"
+	case confidence >= 0.5:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// trimToTokens cuts text to about tokens estimated tokens, at a word
+// boundary when one is near.
+func trimToTokens(text string, tokens int) string {
+	limit := tokens * 4
+	if len(text) <= limit {
+		return strings.TrimRight(text, " \t\n")
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	cut := text[:limit]
+	if i := strings.LastIndexAny(cut, " \n"); i > limit/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \t\n")
+}
+
+// tokensOf estimates the tokens of s the way LLM usage is estimated.
+func tokensOf(s string) int {
+	return int(analytics.EstimateTokens(s))
+}
@@ -0,0 +1,74 @@
+package reasoningbank
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func injectionResults() []ScoredMemory {
+	return []ScoredMemory{
+		{Memory: Memory{ID: "low", Title: "Maybe cache DNS", Content: "Unverified.", Outcome: OutcomeSuccess, Confidence: 0.3}, Relevance: 0.95},
+		{Memory: Memory{ID: "avoid", Title: "Disable TLS  verification", Content: "Broke prod.\nNever again.", Outcome: OutcomeFailure, Confidence: 0.85}, Relevance: 0.9},
+		{Memory: Memory{ID: "best", Title: "Use idempotency keys", Content: "Long content.", Outcome: OutcomeSuccess, Confidence: 0.92}, Relevance: 0.8,
+			Highlight: &ChunkHighlight{Text: "Retry writes with idempotency keys."}},
+		{Memory: Memory{ID: "mid", Title: "Pin the toolchain", Content: "Pin Go in go.mod.", Outcome: OutcomeSuccess, Confidence: 0.6}, Relevance: 0.7},
+	}
+}
+
+func TestFormatInjection(t *testing.T) {
+	inj := FormatInjection(injectionResults(), InjectionOptions{})
+
+	assert.Equal(t, []string{"best", "avoid", "mid", "low"}, inj.MemoryIDs, "ordered by confidence")
+	assert.Zero(t, inj.Omitted)
+	assert.False(t, inj.Truncated)
+	assert.Equal(t, tokensOf(inj.Text), inj.Tokens)
+
+	assert.True(t, strings.HasPrefix(inj.Text, injectionOpen+"\n"+injectionGuidance+"\n"))
+	assert.True(t, strings.HasSuffix(inj.Text, injectionClose+"\n"))
+	assert.Contains(t, inj.Text, "1. [high confidence 0.92] Use idempotency keys\n   Retry writes with idempotency keys.\n")
+	assert.Contains(t, inj.Text, "2. AVOID [high confidence 0.85] Disable TLS verification\n   Broke prod.\n   Never again.\n")
+	assert.Contains(t, inj.Text, "3. [medium confidence 0.60] Pin the toolchain")
+	assert.Contains(t, inj.Text, "4. [low confidence 0.30] Maybe cache DNS")
+}
+
+func TestFormatInjection_MinConfidence(t *testing.T) {
+	inj := FormatInjection(injectionResults(), InjectionOptions{MinConfidence: 0.5})
+	assert.Equal(t, []string{"best", "avoid", "mid"}, inj.MemoryIDs)
+	assert.Equal(t, 1, inj.Omitted)
+
+	inj = FormatInjection(injectionResults(), InjectionOptions{MinConfidence: 0.99})
+	assert.Empty(t, inj.Text, "nothing to inject")
+	assert.Equal(t, 4, inj.Omitted)
+
+	assert.Empty(t, FormatInjection(nil, InjectionOptions{}).Text)
+}
+
+func TestFormatInjection_MaxTokens(t *testing.T) {
+	results := injectionResults()
+	results[3].Memory.Content = strings.Repeat("Pin the Go toolchain in go.mod and CI. ", 40)
+	full := FormatInjection(results, InjectionOptions{})
+
+	// Room for the first two memories and part of the third
+	budget := full.Tokens - 300
+	inj := FormatInjection(results, InjectionOptions{MaxTokens: budget})
+	require.Equal(t, []string{"best", "avoid", "mid"}, inj.MemoryIDs)
+	assert.True(t, inj.Truncated)
+	assert.Equal(t, 1, inj.Omitted)
+	assert.LessOrEqual(t, inj.Tokens, budget)
+	assert.Contains(t, inj.Text, budgetMarker)
+	assert.True(t, strings.HasSuffix(inj.Text, injectionClose+"\n"))
+
+	// Too small for even the guidance
+	inj = FormatInjection(results, InjectionOptions{MaxTokens: 10})
+	assert.Empty(t, inj.Text)
+	assert.Equal(t, 4, inj.Omitted)
+}
+
+func TestTrimToTokens(t *testing.T) {
+	assert.Equal(t, "short", trimToTokens("short\n", 10))
+	assert.Equal(t, "one two", trimToTokens("one two three four", 2))
+	assert.Equal(t, "日本", trimToTokens("日本語", 2), "never splits a rune")
+}
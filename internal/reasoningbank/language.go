@@ -0,0 +1,187 @@
+package reasoningbank
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
+)
+
+const (
+	// maxMemoryLanguages caps the languages kept on a memory. A memory
+	// that seems to be about more languages than this is not specific to any.
+	maxMemoryLanguages = 3
+
+	// languageMatchBoost is applied to memories in a language of the query.
+	languageMatchBoost float32 = 1.2
+
+	// languageMismatchPenalty is applied to memories only in other
+	// languages when the query's languages were inferred rather than scoped.
+	languageMismatchPenalty float32 = 0.6
+)
+
+// languageAliases maps tag spellings to canonical language names.
+var languageAliases = map[string]string{
+	"go":         "go",
+	"golang":     "go",
+	"python":     "python",
+	"py":         "python",
+	"typescript": "typescript",
+	"ts":         "typescript",
+	"javascript": "javascript",
+	"js":         "javascript",
+	"node":       "javascript",
+	"nodejs":     "javascript",
+	"terraform":  "terraform",
+	"tf":         "terraform",
+	"hcl":        "terraform",
+	"rust":       "rust",
+	"java":       "java",
+	"kotlin":     "kotlin",
+	"ruby":       "ruby",
+	"rails":      "ruby",
+	"kubernetes": "kubernetes",
+	"k8s":        "kubernetes",
+	"helm":       "kubernetes",
+}
+
+// languageSignals detect a language from free text: file extensions,
+// toolchain commands, syntax and the language's name. Signals must be
+// specific; "go" alone is an English word and only counts in commands.
+var languageSignals = []struct {
+	language string
+	regex    *regexp.Regexp
+}{
+	{"go", regexp.MustCompile(`(?i)\w\.go\b|\bgo\s+(?:test|build|run|mod|vet|get|generate)\b|\bgolang\b|\bgoroutines?\b|\bpackage\s+main\b|\bfunc\s+(?:\(\w+\s+\*?\w+\)\s*)?\w+\(`)},
+	{"python", regexp.MustCompile(`(?i)\w\.py\b|\bpython3?\b|\bpip3?\s+install\b|\bpytest\b|\bdef\s+\w+\(|__init__|\bvirtualenv\b|\bdjango\b|\bfastapi\b`)},
+	{"typescript", regexp.MustCompile(`(?i)\w\.tsx?\b|\btypescript\b|\btsc\b|\btsconfig\b`)},
+	{"javascript", regexp.MustCompile(`(?i)\w\.jsx?\b|\bjavascript\b|\bnode\.?js\b|\bnpm\s+(?:install|run|test|ci)\b|\bpackage\.json\b|\bnode_modules\b`)},
+	{"terraform", regexp.MustCompile(`(?i)\w\.tf\b|\bterraform\b|\bresource\s+"\w+"\s+"\w+"`)},
+	{"rust", regexp.MustCompile(`(?i)\w\.rs\b|\brust\b|\bcargo\s+(?:build|test|run|add)\b|\bfn\s+\w+\(|\bimpl\s+\w+`)},
+	{"java", regexp.MustCompile(`(?i)\w\.java\b|\bjava\b|\bmaven\b|\bgradle\b|\bpublic\s+(?:static\s+)?(?:class|void)\b`)},
+	{"kotlin", regexp.MustCompile(`(?i)\w\.kts?\b|\bkotlin\b`)},
+	{"ruby", regexp.MustCompile(`(?i)\w\.rb\b|\bruby\b|\brails\b|\bbundle\s+exec\b|\bgem\s+install\b`)},
+	{"kubernetes", regexp.MustCompile(`(?i)\bkubernetes\b|\bk8s\b|\bkubectl\b|\bhelm\s+(?:install|upgrade|chart)\b`)},
+}
+
+// validLanguage matches language names accepted on memories and scopes.
+var validLanguage = regexp.MustCompile(`^[a-z0-9][a-z0-9+#.-]{0,31}$`)
+
+// NormalizeLanguages lowercases languages, maps known aliases ("golang",
+// "ts") to canonical names and drops invalid names and duplicates. The
+// result is sorted.
+func NormalizeLanguages(languages []string) []string {
+	seen := make(map[string]bool, len(languages))
+	out := make([]string, 0, len(languages))
+	for _, l := range languages {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if canonical, ok := languageAliases[l]; ok {
+			l = canonical
+		}
+		if !validLanguage.MatchString(l) || seen[l] {
+			continue
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// InferLanguages infers the languages or stacks a memory is about. Tags
+// naming a language ("go", "golang", "terraform") are authoritative;
+// without them, languages are detected from file extensions, toolchain
+// commands and syntax in the title and content. Returns nil when nothing
+// specific is found, including when the text mentions too many languages
+// to be about any one of them.
+func InferLanguages(title, content string, tags []string) []string {
+	var fromTags []string
+	for _, tag := range tags {
+		if l, ok := languageAliases[strings.ToLower(strings.TrimSpace(tag))]; ok {
+			fromTags = append(fromTags, l)
+		}
+	}
+	if len(fromTags) > 0 {
+		return NormalizeLanguages(fromTags)
+	}
+
+	text := title + "\n" + content
+	var found []string
+	for _, signal := range languageSignals {
+		if signal.regex.MatchString(text) {
+			found = append(found, signal.language)
+		}
+	}
+	if len(found) == 0 || len(found) > maxMemoryLanguages {
+		return nil
+	}
+	return NormalizeLanguages(found)
+}
+
+// languageScopeKey is the context key for an explicit language scope.
+type languageScopeKey struct{}
+
+// ContextWithLanguages scopes memory searches made with ctx to languages.
+// Memories tagged only with other languages are filtered out; memories
+// without languages are language-agnostic and always kept. Without a
+// scope, languages are inferred from the query and used as a boost.
+func ContextWithLanguages(ctx context.Context, languages ...string) context.Context {
+	languages = NormalizeLanguages(languages)
+	if len(languages) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, languageScopeKey{}, languages)
+}
+
+// LanguagesFromContext returns the language scope set by
+// ContextWithLanguages, or nil.
+func LanguagesFromContext(ctx context.Context) []string {
+	languages, _ := ctx.Value(languageScopeKey{}).([]string)
+	return languages
+}
+
+// queryLanguages returns the languages a search is about and whether they
+// are an explicit scope (filter) rather than inferred from the query (boost).
+func queryLanguages(ctx context.Context, query string) ([]string, bool) {
+	if scoped := LanguagesFromContext(ctx); len(scoped) > 0 {
+		return scoped, true
+	}
+	return InferLanguages(query, "", nil), false
+}
+
+// languagesOverlap reports whether a and b share a language.
+func languagesOverlap(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CursorHash returns the hash SearchPage (or SearchPageMultiQuery when
+// multiQuery is set) binds its cursors to for ctx. Callers that return part
+// of a page use it to resume with pagination.Resume.
+func CursorHash(ctx context.Context, projectID, query string, multiQuery bool) string {
+	if multiQuery {
+		return searchHash(ctx, "memory-multi", projectID, query)
+	}
+	return searchHash(ctx, "memory", projectID, query)
+}
+
+// searchHash binds pagination cursors to a search, including its language
+// scope and filter so a cursor cannot be reused across them.
+func searchHash(ctx context.Context, kind, projectID, query string) string {
+	parts := []string{kind, projectID, query}
+	if scoped := LanguagesFromContext(ctx); len(scoped) > 0 {
+		parts = append(parts, "languages="+strings.Join(scoped, ","))
+	}
+	if filter := FilterFromContext(ctx); filter != nil {
+		parts = append(parts, "filter="+filter.String())
+	}
+	return pagination.QueryHash(parts...)
+}
@@ -0,0 +1,107 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestInferLanguages(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		content string
+		tags    []string
+		want    []string
+	}{
+		{"tags are authoritative", "fix flaky test", "run pytest with -x", []string{"golang", "ci"}, []string{"go"}},
+		{"tag aliases", "", "", []string{"TS", "tf"}, []string{"terraform", "typescript"}},
+		{"go toolchain", "race in handler", "go test -race ./... found it", nil, []string{"go"}},
+		{"python file", "import cycle", "moved helpers out of utils.py", nil, []string{"python"}},
+		{"terraform resource", "state drift", `resource "aws_s3_bucket" "logs" was recreated`, nil, []string{"terraform"}},
+		{"several languages", "proto codegen", "regenerate main.go and client.ts", nil, []string{"go", "typescript"}},
+		{"go as an English word", "go ahead with the rollout", "let it go", nil, nil},
+		{"too many languages", "polyglot build", "main.go app.py index.ts lib.rs", nil, nil},
+		{"nothing specific", "retry strategy", "back off exponentially", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, InferLanguages(tt.title, tt.content, tt.tags))
+		})
+	}
+}
+
+func TestNormalizeLanguages(t *testing.T) {
+	assert.Equal(t, []string{"go", "python"}, NormalizeLanguages([]string{" Python", "golang", "go", ""}))
+	assert.Equal(t, []string{"c#", "c++"}, NormalizeLanguages([]string{"C++", "c#"}))
+	assert.Empty(t, NormalizeLanguages([]string{"not a language", "../etc"}))
+}
+
+func TestContextWithLanguages(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, LanguagesFromContext(ctx))
+	assert.Equal(t, ctx, ContextWithLanguages(ctx), "no languages leaves ctx unscoped")
+	assert.Equal(t, []string{"go"}, LanguagesFromContext(ContextWithLanguages(ctx, "Golang")))
+
+	assert.NotEqual(t,
+		searchHash(ctx, "memory", "p", "q"),
+		searchHash(ContextWithLanguages(ctx, "go"), "memory", "p", "q"),
+		"cursors are bound to the language scope")
+}
+
+func TestService_LanguageScopes(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+	projectID := "project-polyglot"
+
+	record := func(title, content string, tags []string) *Memory {
+		t.Helper()
+		m, err := NewMemory(projectID, title, content, OutcomeSuccess, tags)
+		require.NoError(t, err)
+		require.NoError(t, svc.Record(ctx, m))
+		return m
+	}
+	goMem := record("retry transient errors", "wrap calls in a backoff loop, see retry.go", nil)
+	pyMem := record("retry transient errors", "use tenacity around requests calls", []string{"python"})
+	anyMem := record("retry transient errors", "cap retries and add jitter", nil)
+
+	assert.Equal(t, []string{"go"}, goMem.Languages, "inferred from content")
+	assert.Equal(t, []string{"python"}, pyMem.Languages, "taken from tags")
+	assert.Empty(t, anyMem.Languages)
+
+	ids := func(results []ScoredMemory) []string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.Memory.ID
+		}
+		return out
+	}
+
+	t.Run("scope filters other languages", func(t *testing.T) {
+		results, err := svc.SearchWithScores(ContextWithLanguages(ctx, "py"), projectID, "retry transient errors", 10)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{pyMem.ID, anyMem.ID}, ids(results))
+		for _, r := range results {
+			if r.Memory.ID == pyMem.ID {
+				assert.Equal(t, []string{"python"}, r.Memory.Languages, "languages round-trip through metadata")
+			}
+		}
+	})
+
+	t.Run("inferred languages boost", func(t *testing.T) {
+		results, err := svc.SearchWithScores(ctx, projectID, "retry transient errors in python", 10)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, []string{pyMem.ID, anyMem.ID, goMem.ID}, ids(results))
+	})
+
+	t.Run("no languages", func(t *testing.T) {
+		results, err := svc.SearchWithScores(ctx, projectID, "retry transient errors", 10)
+		require.NoError(t, err)
+		assert.Len(t, results, 3)
+	})
+}
@@ -139,3 +139,42 @@ func TestCounterWithAttributes(t *testing.T) {
 		}
 	}
 }
+
+func TestThresholdCrossingMetric(t *testing.T) {
+	ctx := context.Background()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	oldMP := otel.GetMeterProvider()
+	otel.SetMeterProvider(mp)
+	defer otel.SetMeterProvider(oldMP)
+
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+	memory, _ := NewMemory("project-123", "Test Memory", "Test content", OutcomeSuccess, []string{"test"})
+	require.NoError(t, svc.Record(ctx, memory))
+	require.GreaterOrEqual(t, memory.Confidence, MinConfidence)
+
+	// The first unhelpful feedback drops the memory below the threshold;
+	// the second keeps it there and is not a crossing.
+	require.NoError(t, svc.Feedback(ctx, memory.ID, false))
+	require.NoError(t, svc.Feedback(ctx, memory.ID, false))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	var points []metricdata.DataPoint[int64]
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "contextd.memory.confidence_threshold_crossings_total" {
+				points = m.Data.(metricdata.Sum[int64]).DataPoints
+			}
+		}
+	}
+	require.Len(t, points, 1)
+	assert.Equal(t, int64(1), points[0].Value)
+	direction, _ := points[0].Attributes.Value("direction")
+	cause, _ := points[0].Attributes.Value("cause")
+	assert.Equal(t, string(ThresholdFilteredOut), direction.AsString())
+	assert.Equal(t, "feedback", cause.AsString())
+}
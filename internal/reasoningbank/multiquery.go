@@ -0,0 +1,276 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
+)
+
+const (
+	// MaxSubQueries caps how many sub-queries a task is decomposed into.
+	MaxSubQueries = 4
+
+	// minSubQueryWords is the shortest part the heuristic keeps as a
+	// sub-query; shorter parts ("search and replace") are not separate tasks.
+	minSubQueryWords = 2
+
+	// rrfK dampens the weight of top ranks in reciprocal rank fusion.
+	rrfK = 60
+)
+
+// QueryDecomposer splits a task description into independent sub-queries.
+type QueryDecomposer interface {
+	// Decompose returns the sub-queries for query. A single-part query
+	// returns one sub-query or none.
+	Decompose(ctx context.Context, query string) ([]string, error)
+}
+
+// subQuerySplit matches sentence ends, list separators and conjunctions
+// that join the parts of a multi-part task.
+var subQuerySplit = regexp.MustCompile(`(?i)[.;!?\n]+\s*|,\s*(?:and\s+|then\s+)?|\s+(?:and then|and also|then|and|also|plus)\s+`)
+
+// leadingConnective matches a conjunction left at the start of a sentence.
+var leadingConnective = regexp.MustCompile(`(?i)^(?:and|then|also|plus)\s+`)
+
+// HeuristicDecomposer splits tasks on sentence ends, commas and
+// conjunctions, e.g. "add OAuth and write integration tests" becomes
+// "add OAuth" and "write integration tests".
+type HeuristicDecomposer struct{}
+
+// Decompose implements QueryDecomposer.
+func (HeuristicDecomposer) Decompose(_ context.Context, query string) ([]string, error) {
+	var parts []string
+	for _, part := range subQuerySplit.Split(query, -1) {
+		part = leadingConnective.ReplaceAllString(strings.TrimSpace(part), "")
+		if len(strings.Fields(part)) >= minSubQueryWords {
+			parts = append(parts, part)
+		}
+	}
+	return dedupeQueries(parts), nil
+}
+
+// LLMDecomposer asks an LLM to split a task into search queries.
+type LLMDecomposer struct {
+	client LLMClient
+}
+
+// NewLLMDecomposer creates an LLM-based QueryDecomposer.
+func NewLLMDecomposer(client LLMClient) *LLMDecomposer {
+	return &LLMDecomposer{client: client}
+}
+
+// Decompose implements QueryDecomposer.
+func (d *LLMDecomposer) Decompose(ctx context.Context, query string) ([]string, error) {
+	prompt := fmt.Sprintf(`Split the following task into at most %d independent search queries for a knowledge base of past engineering work.
+Return one query per line with no numbering or commentary. If the task has a single part, return it unchanged.
+
+Task: %s`, MaxSubQueries, query)
+
+	response, err := d.client.Complete(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("decomposing query: %w", err)
+	}
+
+	var parts []string
+	for _, line := range strings.Split(response, "\n") {
+		// Models number or bullet lists despite being asked not to
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•0123456789.)"))
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return dedupeQueries(parts), nil
+}
+
+// dedupeQueries drops repeated queries (case-insensitively) and caps the
+// result at MaxSubQueries.
+func dedupeQueries(queries []string) []string {
+	seen := make(map[string]bool, len(queries))
+	out := make([]string, 0, len(queries))
+	for _, q := range queries {
+		key := strings.ToLower(q)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, q)
+		if len(out) == MaxSubQueries {
+			break
+		}
+	}
+	return out
+}
+
+// WithQueryDecomposer sets the decomposer used by multi-query search.
+// If not provided, HeuristicDecomposer is used.
+func WithQueryDecomposer(d QueryDecomposer) ServiceOption {
+	return func(s *Service) {
+		s.decomposer = d
+	}
+}
+
+// SearchMultiQuery decomposes a long task description into sub-queries,
+// searches them in parallel alongside the full query, and fuses the results
+// with reciprocal rank fusion. A memory that ranks well for several parts of
+// the task ranks above one that matches a single part.
+//
+// It returns the sub-queries that were searched; when the task does not
+// decompose, that is just the query and results equal SearchWithScores.
+// Relevance of a fused memory is its best relevance across sub-queries.
+func (s *Service) SearchMultiQuery(ctx context.Context, projectID, query string, limit int) ([]ScoredMemory, []string, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	queries := s.subQueries(ctx, query)
+	if len(queries) == 1 {
+		results, err := s.SearchWithScores(ctx, projectID, query, limit)
+		return results, queries, err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		ranked  = make([][]ScoredMemory, len(queries))
+		lastErr error
+		failed  int
+	)
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q string) {
+			defer wg.Done()
+			results, err := s.searchWithScores(ctx, projectID, q, limit, false)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				s.logger.Warn("sub-query search failed",
+					zap.String("project_id", projectID),
+					zap.String("sub_query", q),
+					zap.Error(err))
+				lastErr = err
+				failed++
+				return
+			}
+			ranked[i] = results
+		}(i, q)
+	}
+	wg.Wait()
+	if failed == len(queries) {
+		return nil, nil, lastErr
+	}
+
+	fused := fuseRankings(ranked)
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+	// Only the fused results are served, once for the whole task
+	if arm, ranking, ok := s.experimentArm(ctx); ok && ranking != nil {
+		s.recordExposure(ctx, projectID, arm, scoredMemoryIDs(fused))
+	}
+	return fused, queries, nil
+}
+
+// SearchPageMultiQuery is SearchPage for SearchMultiQuery. The page reports
+// the sub-queries that were searched.
+func (s *Service) SearchPageMultiQuery(ctx context.Context, projectID, query string, limit int, cursor string) (*MemoryPage, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	hash := searchHash(ctx, "memory-multi", projectID, query)
+	offset, err := pagination.Decode(cursor, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra result to know whether another page exists.
+	results, queries, err := s.SearchMultiQuery(ctx, projectID, query, offset+limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page, next := pagination.Paginate(results, offset, limit, hash)
+	return &MemoryPage{
+		Memories:   page,
+		Metadata:   s.searchMetadata(query, page),
+		NextCursor: next,
+		SubQueries: queries,
+	}, nil
+}
+
+// subQueries returns the queries to search for a task: the full query
+// followed by its parts, or just the query when it has a single part.
+// Decomposer failures fall back to the heuristic.
+func (s *Service) subQueries(ctx context.Context, query string) []string {
+	decomposer := s.decomposer
+	if decomposer == nil {
+		decomposer = HeuristicDecomposer{}
+	}
+	parts, err := decomposer.Decompose(ctx, query)
+	if err != nil {
+		s.logger.Warn("query decomposition failed, using heuristic", zap.Error(err))
+		parts, _ = HeuristicDecomposer{}.Decompose(ctx, query)
+	}
+	if len(parts) < 2 {
+		return []string{query}
+	}
+	// The full query still finds memories about the task as a whole
+	queries := []string{query}
+	for _, part := range parts {
+		if !strings.EqualFold(part, query) {
+			queries = append(queries, part)
+		}
+	}
+	return queries
+}
+
+// fuseRankings merges ranked result lists with reciprocal rank fusion,
+// breaking ties by ID so pages are deterministic.
+func fuseRankings(rankings [][]ScoredMemory) []ScoredMemory {
+	type fused struct {
+		memory ScoredMemory
+		score  float64
+	}
+	byID := make(map[string]*fused)
+	for _, results := range rankings {
+		for rank, sm := range results {
+			f, ok := byID[sm.Memory.ID]
+			if !ok {
+				f = &fused{memory: sm}
+				byID[sm.Memory.ID] = f
+			}
+			f.score += 1.0 / float64(rrfK+rank+1)
+			if sm.Relevance > f.memory.Relevance {
+				highlight := f.memory.Highlight
+				f.memory = sm
+				if f.memory.Highlight == nil {
+					f.memory.Highlight = highlight
+				}
+			}
+		}
+	}
+
+	all := make([]*fused, 0, len(byID))
+	for _, f := range byID {
+		all = append(all, f)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].memory.Memory.ID < all[j].memory.Memory.ID
+	})
+
+	out := make([]ScoredMemory, len(all))
+	for i, f := range all {
+		out[i] = f.memory
+	}
+	return out
+}
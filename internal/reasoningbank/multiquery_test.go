@@ -0,0 +1,100 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHeuristicDecomposer(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"add OAuth and write integration tests", []string{"add OAuth", "write integration tests"}},
+		{"Fix the login redirect. Then update the session cookie, and add a regression test",
+			[]string{"Fix the login redirect", "update the session cookie", "add a regression test"}},
+		{"migrate the schema; backfill user rows; Migrate the schema", []string{"migrate the schema", "backfill user rows"}},
+		{"search and replace in config files", []string{"replace in config files"}},
+		{"retry with backoff", []string{"retry with backoff"}},
+		{"a b, c d, e f, g h, i j, k l", []string{"a b", "c d", "e f", "g h"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := HeuristicDecomposer{}.Decompose(context.Background(), tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLLMDecomposer(t *testing.T) {
+	client := newMockLLMClientWithResponse("1. Add OAuth login\n- write integration tests\n\n* add OAuth login\n")
+	got, err := NewLLMDecomposer(client).Decompose(context.Background(), "add OAuth and write integration tests")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Add OAuth login", "write integration tests"}, got)
+	assert.Contains(t, client.lastPrompt, "add OAuth and write integration tests")
+
+	_, err = NewLLMDecomposer(newMockLLMClientWithError(errors.New("rate limited"))).Decompose(context.Background(), "x")
+	assert.ErrorContains(t, err, "rate limited")
+}
+
+func TestFuseRankings(t *testing.T) {
+	mem := func(id string, relevance float64) ScoredMemory {
+		return ScoredMemory{Memory: Memory{ID: id}, Relevance: relevance}
+	}
+	fused := fuseRankings([][]ScoredMemory{
+		{mem("oauth", 0.9), mem("both", 0.6)},
+		{mem("tests", 0.8), mem("both", 0.7)},
+		nil, // failed sub-query
+	})
+
+	ids := make([]string, len(fused))
+	for i, sm := range fused {
+		ids[i] = sm.Memory.ID
+	}
+	// Matching both parts outranks topping one list
+	assert.Equal(t, []string{"both", "oauth", "tests"}, ids)
+	assert.Equal(t, 0.7, fused[0].Relevance, "fused relevance is the best sub-query relevance")
+}
+
+func TestService_SearchMultiQuery(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+
+	projectID := "project-multi"
+	for _, title := range []string{"OAuth provider setup", "Integration test harness"} {
+		memory, err := NewMemory(projectID, title, "notes on "+title, OutcomeSuccess, nil)
+		require.NoError(t, err)
+		require.NoError(t, svc.Record(ctx, memory))
+	}
+
+	t.Run("decomposes multi-part tasks", func(t *testing.T) {
+		page, err := svc.SearchPageMultiQuery(ctx, projectID, "add OAuth and write integration tests", 5, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"add OAuth and write integration tests", "add OAuth", "write integration tests"}, page.SubQueries)
+		assert.Len(t, page.Memories, 2, "memories found by several sub-queries are returned once")
+		assert.NotNil(t, page.Metadata)
+	})
+
+	t.Run("single-part query searches once", func(t *testing.T) {
+		results, queries, err := svc.SearchMultiQuery(ctx, projectID, "OAuth provider", 5)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"OAuth provider"}, queries)
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("falls back to heuristic when the decomposer fails", func(t *testing.T) {
+		llmSvc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"),
+			WithQueryDecomposer(NewLLMDecomposer(newMockLLMClientWithError(errors.New("timeout")))))
+		require.NoError(t, err)
+		_, queries, err := llmSvc.SearchMultiQuery(ctx, projectID, "add OAuth and write integration tests", 5)
+		require.NoError(t, err)
+		assert.Len(t, queries, 3)
+	})
+}
@@ -0,0 +1,50 @@
+package reasoningbank
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ConversationOrigin is the moment in an indexed conversation a memory was
+// recorded from.
+type ConversationOrigin struct {
+	SessionID   string    `json:"session_id"`
+	MessageUUID string    `json:"message_uuid"`
+	Timestamp   time.Time `json:"timestamp"`
+
+	// Excerpt is the start of the redacted message, to show without
+	// searching the conversation.
+	Excerpt string `json:"excerpt,omitempty"`
+}
+
+// SetOrigin links a memory to the conversation message that recorded it.
+// Indexing a conversation again sets the same origin, which is a no-op.
+func (s *Service) SetOrigin(ctx context.Context, projectID, memoryID string, origin ConversationOrigin) error {
+	ctx, err := s.withTenant(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	memory, err := s.GetByProjectID(ctx, projectID, memoryID)
+	if err != nil {
+		return err
+	}
+	if memory.Origin != nil && memory.Origin.SessionID == origin.SessionID &&
+		memory.Origin.MessageUUID == origin.MessageUUID && memory.Origin.Excerpt == origin.Excerpt {
+		return nil
+	}
+
+	// The link is provenance, not an edit, so UpdatedAt is left alone
+	memory.Origin = &origin
+	if err := s.replaceMemory(ctx, projectID, memory); err != nil {
+		return err
+	}
+
+	s.logger.Debug("memory linked to conversation",
+		zap.String("id", memoryID),
+		zap.String("session_id", origin.SessionID),
+		zap.String("message_uuid", origin.MessageUUID))
+	return nil
+}
@@ -0,0 +1,45 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestService_SetOrigin(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+	projectID := "project-123"
+
+	memory, _ := NewMemory(projectID, "Retry flaky uploads", "Wrap uploads in exponential backoff", OutcomeSuccess, []string{"go"})
+	require.NoError(t, svc.Record(ctx, memory))
+
+	stored, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.Origin)
+
+	origin := ConversationOrigin{
+		SessionID:   "session-1",
+		MessageUUID: "msg-7",
+		Timestamp:   time.Unix(1700000000, 0).UTC(),
+		Excerpt:     "Let me record this as a memory",
+	}
+	require.NoError(t, svc.SetOrigin(ctx, projectID, memory.ID, origin))
+
+	stored, err = svc.GetByProjectID(ctx, projectID, memory.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.Origin)
+	assert.Equal(t, origin, *stored.Origin)
+	assert.Equal(t, memory.Confidence, stored.Confidence)
+
+	// Linking again is a no-op
+	require.NoError(t, svc.SetOrigin(ctx, projectID, memory.ID, origin))
+
+	err = svc.SetOrigin(ctx, projectID, "8f14e45f-ceea-467f-a0e6-0b6c3a1c1d2e", origin)
+	assert.ErrorIs(t, err, ErrMemoryNotFound)
+}
@@ -0,0 +1,145 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// maxDraftContentLength caps the content of an extractive draft.
+const maxDraftContentLength = 4000
+
+// ClusterPreview describes a cluster that Consolidate would merge, together
+// with an extractive draft of the merged memory. Building a preview never
+// calls the LLM, so users can review clusters before paying for synthesis.
+type ClusterPreview struct {
+	// Index is the 1-based position of the cluster in this preview.
+	Index int `json:"index"`
+
+	// Members are the memories that would be merged.
+	Members []*Memory `json:"members"`
+
+	// AverageSimilarity is the mean pairwise similarity between members.
+	AverageSimilarity float64 `json:"average_similarity"`
+
+	// MinSimilarity is the lowest pairwise similarity between members.
+	MinSimilarity float64 `json:"min_similarity"`
+
+	// Draft approximates the consolidated memory by extracting, rather than
+	// synthesizing, content from the members. It is not stored.
+	Draft *Memory `json:"draft"`
+}
+
+// PreviewClusters returns the clusters Consolidate would process for a
+// project with the same options, without merging anything or calling the
+// LLM. MaxClustersPerRun, Tags, Outcome and Query apply as they do for
+// Consolidate; DryRun and ForceAll are ignored.
+func (d *Distiller) PreviewClusters(ctx context.Context, projectID string, opts ConsolidationOptions) ([]ClusterPreview, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	threshold, err := consolidationThreshold(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keep, err := d.consolidationFilter(ctx, projectID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := d.findSimilarClusters(ctx, projectID, threshold, keep)
+	if err != nil {
+		return nil, fmt.Errorf("finding similar clusters: %w", err)
+	}
+	if opts.MaxClustersPerRun > 0 && len(clusters) > opts.MaxClustersPerRun {
+		clusters = clusters[:opts.MaxClustersPerRun]
+	}
+
+	previews := make([]ClusterPreview, 0, len(clusters))
+	for i := range clusters {
+		previews = append(previews, ClusterPreview{
+			Index:             i + 1,
+			Members:           clusters[i].Members,
+			AverageSimilarity: clusters[i].AverageSimilarity,
+			MinSimilarity:     clusters[i].MinSimilarity,
+			Draft:             extractiveDraft(projectID, clusters[i].Members),
+		})
+	}
+
+	d.logger.Info("previewed consolidation clusters",
+		zap.String("project_id", projectID),
+		zap.Int("clusters", len(previews)))
+
+	return previews, nil
+}
+
+// extractiveDraft builds a stand-in for the LLM-merged memory of a cluster.
+// It takes the title of the most trusted member, concatenates the distinct
+// paragraphs of all members in confidence order, unions their tags and uses
+// the majority outcome.
+func extractiveDraft(projectID string, members []*Memory) *Memory {
+	if len(members) == 0 {
+		return nil
+	}
+
+	ordered := make([]*Memory, len(members))
+	copy(ordered, members)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Confidence > ordered[j].Confidence
+	})
+
+	seen := make(map[string]bool)
+	var paragraphs []string
+	length := 0
+	for _, m := range ordered {
+		for _, p := range strings.Split(m.Content, "\n\n") {
+			p = strings.TrimSpace(p)
+			key := strings.ToLower(strings.Join(strings.Fields(p), " "))
+			if key == "" || seen[key] {
+				continue
+			}
+			if length+len(p) > maxDraftContentLength {
+				break
+			}
+			seen[key] = true
+			paragraphs = append(paragraphs, p)
+			length += len(p) + 2
+		}
+	}
+
+	var tags []string
+	tagSeen := make(map[string]bool)
+	successes := 0
+	for _, m := range ordered {
+		if m.Outcome == OutcomeSuccess {
+			successes++
+		}
+		for _, t := range m.Tags {
+			if !tagSeen[t] {
+				tagSeen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	outcome := OutcomeSuccess
+	if successes*2 < len(ordered) {
+		outcome = OutcomeFailure
+	}
+
+	return &Memory{
+		ProjectID:   projectID,
+		Title:       ordered[0].Title,
+		Description: fmt.Sprintf("Extractive draft of %d memories", len(members)),
+		Content:     strings.Join(paragraphs, "\n\n"),
+		Outcome:     outcome,
+		Confidence:  calculateConsolidatedConfidence(members),
+		Importance:  consolidatedImportance(members),
+		Tags:        tags,
+		State:       MemoryStateActive,
+	}
+}
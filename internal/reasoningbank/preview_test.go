@@ -0,0 +1,76 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPreviewClusters(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+
+	mockLLM := newMockLLMClient()
+	svc := &Service{
+		store:         newMockStore(),
+		embedder:      newMockEmbedder(384),
+		logger:        zap.NewNop(),
+		defaultTenant: "test-tenant",
+	}
+	distiller, err := NewDistiller(svc, zap.NewNop(), WithLLMClient(mockLLM))
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		mem, _ := NewMemory(projectID, fmt.Sprintf("Error handling pattern %d", i),
+			"Content for Error handling\n\nWrap errors with context", OutcomeSuccess, []string{"errors", fmt.Sprintf("tag-%d", i)})
+		require.NoError(t, svc.Record(ctx, mem))
+	}
+
+	previews, err := distiller.PreviewClusters(ctx, projectID, ConsolidationOptions{SimilarityThreshold: 0.85})
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+
+	p := previews[0]
+	assert.Equal(t, 1, p.Index)
+	assert.Len(t, p.Members, 2)
+	assert.Greater(t, p.AverageSimilarity, 0.85)
+	require.NotNil(t, p.Draft)
+	assert.Equal(t, "Content for Error handling\n\nWrap errors with context", p.Draft.Content, "duplicate paragraphs should be dropped")
+	assert.ElementsMatch(t, []string{"errors", "tag-1", "tag-2"}, p.Draft.Tags)
+	assert.Equal(t, OutcomeSuccess, p.Draft.Outcome)
+
+	assert.Equal(t, 0, mockLLM.CallCount(), "preview must not call the LLM")
+
+	memories, err := svc.ListMemories(ctx, projectID, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, memories, 2, "preview must not change the bank")
+
+	t.Run("validates options", func(t *testing.T) {
+		_, err := distiller.PreviewClusters(ctx, "", ConsolidationOptions{})
+		assert.Equal(t, ErrEmptyProjectID, err)
+
+		_, err = distiller.PreviewClusters(ctx, projectID, ConsolidationOptions{SimilarityThreshold: 2})
+		assert.Error(t, err)
+	})
+}
+
+func TestExtractiveDraft(t *testing.T) {
+	members := []*Memory{
+		{ID: "a", Title: "Low", Content: "shared", Outcome: OutcomeFailure, Confidence: 0.3},
+		{ID: "b", Title: "High", Content: "first\n\nShared", Outcome: OutcomeFailure, Confidence: 0.9},
+		{ID: "c", Title: "Mid", Content: "third", Outcome: OutcomeSuccess, Confidence: 0.5},
+	}
+
+	draft := extractiveDraft("proj", members)
+	require.NotNil(t, draft)
+	assert.Equal(t, "High", draft.Title, "title comes from the most confident member")
+	assert.Equal(t, "first\n\nShared\n\nthird", draft.Content)
+	assert.Equal(t, OutcomeFailure, draft.Outcome)
+	assert.Equal(t, "proj", draft.ProjectID)
+
+	assert.Nil(t, extractiveDraft("proj", nil))
+}
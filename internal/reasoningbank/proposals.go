@@ -0,0 +1,430 @@
+package reasoningbank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// DefaultProposalTTL is how long a consolidation proposal stays pending
+// before it expires.
+const DefaultProposalTTL = 7 * 24 * time.Hour
+
+// maxProposals caps how many proposals are read for a project from stores
+// that cannot scroll.
+const maxProposals = 1000
+
+// Proposal-related errors.
+var (
+	ErrProposalNotFound   = ctxerrors.NotFound("consolidation proposal not found")
+	ErrProposalNotPending = errors.New("consolidation proposal is not pending")
+)
+
+// ProposalStatus is the review state of a consolidation proposal.
+type ProposalStatus string
+
+const (
+	// ProposalPending is awaiting review.
+	ProposalPending ProposalStatus = "pending"
+
+	// ProposalApproved was approved and will be merged by the next
+	// consolidation run with an LLM client.
+	ProposalApproved ProposalStatus = "approved"
+
+	// ProposalRejected was rejected; its cluster is left unmerged.
+	ProposalRejected ProposalStatus = "rejected"
+
+	// ProposalMerged was approved and merged into ConsolidatedID.
+	ProposalMerged ProposalStatus = "merged"
+
+	// ProposalExpired was not reviewed before ExpiresAt, or its members
+	// changed before it could be merged.
+	ProposalExpired ProposalStatus = "expired"
+)
+
+// ConsolidationProposal is a cluster awaiting approval before it is merged.
+// Proposals are created by Consolidate when RequireApproval is set.
+type ConsolidationProposal struct {
+	ID        string         `json:"id"`
+	ProjectID string         `json:"project_id"`
+	Status    ProposalStatus `json:"status"`
+
+	// MemberIDs are the memories that would be merged.
+	MemberIDs []string `json:"member_ids"`
+
+	// MemberTitles are the titles of MemberIDs, in the same order, for review.
+	MemberTitles []string `json:"member_titles"`
+
+	AverageSimilarity float64 `json:"average_similarity"`
+	MinSimilarity     float64 `json:"min_similarity"`
+
+	// Draft is an extractive preview of the merged memory.
+	Draft *Memory `json:"draft,omitempty"`
+
+	// ConsolidatedID is the merged memory, once Status is merged.
+	ConsolidatedID string `json:"consolidated_id,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// open reports whether the proposal still reserves its members.
+func (p *ConsolidationProposal) open() bool {
+	return p.Status == ProposalPending || p.Status == ProposalApproved
+}
+
+// WithProposalTTL sets how long consolidation proposals stay pending.
+// If not set, defaults to DefaultProposalTTL.
+func WithProposalTTL(ttl time.Duration) DistillerOption {
+	return func(d *Distiller) {
+		d.proposalTTL = ttl
+	}
+}
+
+// ListProposals returns a project's consolidation proposals, newest first.
+// An empty status returns proposals in every state. Pending proposals past
+// their expiry are marked expired as they are read.
+func (d *Distiller) ListProposals(ctx context.Context, projectID string, status ProposalStatus) ([]ConsolidationProposal, error) {
+	proposals, err := d.loadProposals(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ConsolidationProposal, 0, len(proposals))
+	for i := range proposals {
+		if status == "" || proposals[i].Status == status {
+			out = append(out, proposals[i])
+		}
+	}
+	return out, nil
+}
+
+// ReviewProposal records a decision on a pending proposal. Rejected
+// proposals are left unmerged. Approved proposals are merged immediately
+// when the Distiller has an LLM client, and otherwise by the next
+// Consolidate run that has one.
+func (d *Distiller) ReviewProposal(ctx context.Context, projectID, proposalID string, approve bool) (*ConsolidationProposal, error) {
+	if proposalID == "" {
+		return nil, fmt.Errorf("proposal ID cannot be empty")
+	}
+
+	proposals, err := d.loadProposals(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *ConsolidationProposal
+	for i := range proposals {
+		if proposals[i].ID == proposalID {
+			p = &proposals[i]
+			break
+		}
+	}
+	if p == nil {
+		return nil, ErrProposalNotFound
+	}
+	if p.Status != ProposalPending {
+		return p, fmt.Errorf("%w: %s", ErrProposalNotPending, p.Status)
+	}
+
+	now := time.Now()
+	p.ReviewedAt = &now
+	p.Status = ProposalRejected
+	if approve {
+		p.Status = ProposalApproved
+	}
+	if err := d.saveProposal(ctx, p); err != nil {
+		return nil, err
+	}
+	d.recordProposalDecision(ctx, projectID, p.Status)
+
+	d.logger.Info("consolidation proposal reviewed",
+		zap.String("project_id", projectID),
+		zap.String("proposal_id", p.ID),
+		zap.String("status", string(p.Status)))
+
+	if approve && d.llmClient != nil {
+		if err := d.mergeProposal(ctx, p); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// proposeClusters saves a pending proposal for each cluster that does not
+// overlap an open proposal, returning the new proposal IDs.
+func (d *Distiller) proposeClusters(ctx context.Context, projectID string, clusters []SimilarityCluster) ([]string, error) {
+	existing, err := d.loadProposals(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	reserved := make(map[string]bool)
+	for i := range existing {
+		if existing[i].open() {
+			for _, id := range existing[i].MemberIDs {
+				reserved[id] = true
+			}
+		}
+	}
+
+	ttl := d.proposalTTL
+	if ttl <= 0 {
+		ttl = DefaultProposalTTL
+	}
+
+	ids := []string{}
+	for _, cluster := range clusters {
+		overlaps := false
+		for _, m := range cluster.Members {
+			if reserved[m.ID] {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		now := time.Now()
+		p := &ConsolidationProposal{
+			ID:                uuid.New().String(),
+			ProjectID:         projectID,
+			Status:            ProposalPending,
+			AverageSimilarity: cluster.AverageSimilarity,
+			MinSimilarity:     cluster.MinSimilarity,
+			Draft:             extractiveDraft(projectID, cluster.Members),
+			CreatedAt:         now,
+			ExpiresAt:         now.Add(ttl),
+		}
+		for _, m := range cluster.Members {
+			p.MemberIDs = append(p.MemberIDs, m.ID)
+			p.MemberTitles = append(p.MemberTitles, m.Title)
+			reserved[m.ID] = true
+		}
+
+		if err := d.saveProposal(ctx, p); err != nil {
+			return ids, err
+		}
+		d.recordProposalDecision(ctx, projectID, ProposalPending)
+		ids = append(ids, p.ID)
+	}
+	return ids, nil
+}
+
+// mergeApprovedProposals merges every approved proposal for a project,
+// adding the outcome to result. It is a no-op without an LLM client.
+func (d *Distiller) mergeApprovedProposals(ctx context.Context, projectID string, result *ConsolidationResult) error {
+	if d.llmClient == nil {
+		return nil
+	}
+
+	proposals, err := d.ListProposals(ctx, projectID, ProposalApproved)
+	if err != nil {
+		return err
+	}
+	for i := range proposals {
+		p := &proposals[i]
+		if err := d.mergeProposal(ctx, p); err != nil {
+			d.logger.Warn("failed to merge approved proposal",
+				zap.String("proposal_id", p.ID),
+				zap.Error(err))
+			result.SkippedCount += len(p.MemberIDs)
+			continue
+		}
+		result.TotalProcessed += len(p.MemberIDs)
+		if p.Status == ProposalMerged {
+			result.CreatedMemories = append(result.CreatedMemories, p.ConsolidatedID)
+			result.ArchivedMemories = append(result.ArchivedMemories, p.MemberIDs...)
+		}
+	}
+	return nil
+}
+
+// mergeProposal merges an approved proposal's members and resets the
+// project's consolidation window. If fewer than two members are still
+// active, the proposal expires instead.
+func (d *Distiller) mergeProposal(ctx context.Context, p *ConsolidationProposal) error {
+	ctx, err := d.service.withTenant(ctx, p.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	cluster := SimilarityCluster{
+		AverageSimilarity: p.AverageSimilarity,
+		MinSimilarity:     p.MinSimilarity,
+	}
+	for _, id := range p.MemberIDs {
+		m, err := d.service.GetByProjectID(ctx, p.ProjectID, id)
+		if err != nil || m.State != MemoryStateActive {
+			continue
+		}
+		cluster.Members = append(cluster.Members, m)
+	}
+
+	if len(cluster.Members) < 2 {
+		p.Status = ProposalExpired
+		d.recordProposalDecision(ctx, p.ProjectID, ProposalExpired)
+		return d.saveProposal(ctx, p)
+	}
+
+	merged, err := d.MergeCluster(ctx, &cluster)
+	if err != nil {
+		return fmt.Errorf("merging proposal %s: %w", p.ID, err)
+	}
+
+	p.Status = ProposalMerged
+	p.ConsolidatedID = merged.ID
+	d.recordProposalDecision(ctx, p.ProjectID, ProposalMerged)
+	d.setLastConsolidationTime(p.ProjectID, time.Now())
+	return d.saveProposal(ctx, p)
+}
+
+// loadProposals reads all proposals for a project, newest first, expiring
+// stale pending ones.
+func (d *Distiller) loadProposals(ctx context.Context, projectID string) ([]ConsolidationProposal, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	store, collectionName, err := d.service.getCollectionStore(ctx, projectID, project.CollectionProposals)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = d.service.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("checking collection existence: %w", err)
+	}
+	if !exists {
+		return []ConsolidationProposal{}, nil
+	}
+
+	var proposals []ConsolidationProposal
+	collect := func(results []vectorstore.SearchResult) error {
+		for _, r := range results {
+			raw, _ := r.Metadata["proposal"].(string)
+			var p ConsolidationProposal
+			if err := json.Unmarshal([]byte(raw), &p); err != nil {
+				d.logger.Warn("skipping invalid consolidation proposal",
+					zap.String("id", r.ID),
+					zap.Error(err))
+				continue
+			}
+			proposals = append(proposals, p)
+		}
+		return nil
+	}
+
+	err = vectorstore.Scroll(ctx, store, collectionName, DefaultStreamBatchSize, nil, collect)
+	if errors.Is(err, vectorstore.ErrScrollUnsupported) {
+		var results []vectorstore.SearchResult
+		results, err = store.SearchInCollection(ctx, collectionName, "consolidation proposal", maxProposals, nil)
+		if err == nil {
+			err = collect(results)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing consolidation proposals: %w", err)
+	}
+
+	now := time.Now()
+	for i := range proposals {
+		p := &proposals[i]
+		if p.Status == ProposalPending && now.After(p.ExpiresAt) {
+			p.Status = ProposalExpired
+			if err := d.saveProposal(ctx, p); err != nil {
+				return nil, err
+			}
+			d.recordProposalDecision(ctx, projectID, ProposalExpired)
+		}
+	}
+
+	sortProposals(proposals)
+	return proposals, nil
+}
+
+// saveProposal writes p, replacing any previous version.
+func (d *Distiller) saveProposal(ctx context.Context, p *ConsolidationProposal) error {
+	store, collectionName, err := d.service.getCollectionStore(ctx, p.ProjectID, project.CollectionProposals)
+	if err != nil {
+		return err
+	}
+	ctx, err = d.service.withTenant(ctx, p.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("checking collection existence: %w", err)
+	}
+	if !exists {
+		if err := store.CreateCollection(ctx, collectionName, 0); err != nil {
+			return fmt.Errorf("creating collection: %w", err)
+		}
+	} else if err := store.DeleteDocumentsFromCollection(ctx, collectionName, []string{p.ID}); err != nil {
+		return fmt.Errorf("replacing consolidation proposal: %w", err)
+	}
+
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("encoding consolidation proposal: %w", err)
+	}
+
+	content := "consolidation proposal"
+	if p.Draft != nil {
+		content = p.Draft.Title + "\n\n" + p.Draft.Content
+	}
+	_, err = store.AddDocuments(ctx, []vectorstore.Document{{
+		ID:         p.ID,
+		Content:    content,
+		Collection: collectionName,
+		Metadata: map[string]interface{}{
+			"id":         p.ID,
+			"project_id": p.ProjectID,
+			"status":     string(p.Status),
+			"proposal":   string(raw),
+		},
+	}})
+	if err != nil {
+		return fmt.Errorf("storing consolidation proposal: %w", err)
+	}
+	return nil
+}
+
+// recordProposalDecision counts a proposal entering status.
+func (d *Distiller) recordProposalDecision(ctx context.Context, projectID string, status ProposalStatus) {
+	if d.proposalCounter == nil {
+		return
+	}
+	d.proposalCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("project_id", projectID),
+		attribute.String("decision", string(status)),
+	))
+}
+
+// sortProposals orders proposals newest first, breaking ties by ID.
+func sortProposals(proposals []ConsolidationProposal) {
+	sort.Slice(proposals, func(i, j int) bool {
+		if !proposals[i].CreatedAt.Equal(proposals[j].CreatedAt) {
+			return proposals[i].CreatedAt.After(proposals[j].CreatedAt)
+		}
+		return proposals[i].ID < proposals[j].ID
+	})
+}
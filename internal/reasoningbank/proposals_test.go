@@ -0,0 +1,156 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newProposalTestDistiller returns a distiller over a bank holding one
+// two-member cluster.
+func newProposalTestDistiller(t *testing.T, opts ...DistillerOption) (*Distiller, *Service) {
+	t.Helper()
+	ctx := context.Background()
+
+	svc := &Service{
+		store:         newMockStore(),
+		embedder:      newMockEmbedder(384),
+		logger:        zap.NewNop(),
+		defaultTenant: "test-tenant",
+	}
+	distiller, err := NewDistiller(svc, zap.NewNop(), opts...)
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		mem, _ := NewMemory("test-project", fmt.Sprintf("Error handling pattern %d", i),
+			"Content for Error handling", OutcomeSuccess, []string{"errors"})
+		require.NoError(t, svc.Record(ctx, mem))
+	}
+	return distiller, svc
+}
+
+func proposeAll(t *testing.T, d *Distiller) []string {
+	t.Helper()
+	result, err := d.Consolidate(context.Background(), "test-project", ConsolidationOptions{
+		SimilarityThreshold: 0.85,
+		RequireApproval:     true,
+		ForceAll:            true,
+	})
+	require.NoError(t, err)
+	return result.ProposalIDs
+}
+
+func TestConsolidate_RequireApproval(t *testing.T) {
+	ctx := context.Background()
+	mockLLM := newMockLLMClient()
+	distiller, svc := newProposalTestDistiller(t, WithLLMClient(mockLLM))
+
+	ids := proposeAll(t, distiller)
+	require.Len(t, ids, 1)
+	assert.Equal(t, 0, mockLLM.CallCount(), "proposing must not call the LLM")
+
+	memories, err := svc.ListMemories(ctx, "test-project", 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, memories, 2, "proposing must not change the bank")
+
+	proposals, err := distiller.ListProposals(ctx, "test-project", ProposalPending)
+	require.NoError(t, err)
+	require.Len(t, proposals, 1)
+	assert.Len(t, proposals[0].MemberIDs, 2)
+	assert.Len(t, proposals[0].MemberTitles, 2)
+	require.NotNil(t, proposals[0].Draft)
+
+	// Members of an open proposal are not proposed again
+	assert.Empty(t, proposeAll(t, distiller))
+
+	// Nothing was merged, so the consolidation window is not reset
+	assert.True(t, distiller.getLastConsolidationTime("test-project").IsZero())
+}
+
+func TestReviewProposal(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reject", func(t *testing.T) {
+		distiller, _ := newProposalTestDistiller(t)
+		ids := proposeAll(t, distiller)
+		require.Len(t, ids, 1)
+
+		p, err := distiller.ReviewProposal(ctx, "test-project", ids[0], false)
+		require.NoError(t, err)
+		assert.Equal(t, ProposalRejected, p.Status)
+		assert.NotNil(t, p.ReviewedAt)
+
+		_, err = distiller.ReviewProposal(ctx, "test-project", ids[0], true)
+		assert.ErrorIs(t, err, ErrProposalNotPending)
+	})
+
+	t.Run("approve merges with LLM client", func(t *testing.T) {
+		mockLLM := newMockLLMClient()
+		distiller, _ := newProposalTestDistiller(t, WithLLMClient(mockLLM))
+		ids := proposeAll(t, distiller)
+		require.Len(t, ids, 1)
+
+		p, err := distiller.ReviewProposal(ctx, "test-project", ids[0], true)
+		require.NoError(t, err)
+		assert.Equal(t, ProposalMerged, p.Status)
+		assert.NotEmpty(t, p.ConsolidatedID)
+		assert.Equal(t, 1, mockLLM.CallCount())
+		assert.False(t, distiller.getLastConsolidationTime("test-project").IsZero(), "merging resets the window")
+	})
+
+	t.Run("approve without LLM client defers merge", func(t *testing.T) {
+		distiller, svc := newProposalTestDistiller(t)
+		ids := proposeAll(t, distiller)
+		require.Len(t, ids, 1)
+
+		p, err := distiller.ReviewProposal(ctx, "test-project", ids[0], true)
+		require.NoError(t, err)
+		assert.Equal(t, ProposalApproved, p.Status)
+
+		// A later run with an LLM client merges the approved proposal
+		mockLLM := newMockLLMClient()
+		merger, err := NewDistiller(svc, zap.NewNop(), WithLLMClient(mockLLM))
+		require.NoError(t, err)
+		result, err := merger.Consolidate(ctx, "test-project", ConsolidationOptions{
+			SimilarityThreshold: 0.85,
+			RequireApproval:     true,
+		})
+		require.NoError(t, err)
+		assert.Len(t, result.CreatedMemories, 1)
+		assert.Len(t, result.ArchivedMemories, 2)
+
+		merged, err := merger.ListProposals(ctx, "test-project", ProposalMerged)
+		require.NoError(t, err)
+		assert.Len(t, merged, 1)
+	})
+
+	t.Run("unknown proposal", func(t *testing.T) {
+		distiller, _ := newProposalTestDistiller(t)
+		_, err := distiller.ReviewProposal(ctx, "test-project", "missing", true)
+		assert.ErrorIs(t, err, ErrProposalNotFound)
+	})
+}
+
+func TestListProposals_Expiry(t *testing.T) {
+	ctx := context.Background()
+	distiller, _ := newProposalTestDistiller(t, WithProposalTTL(time.Millisecond))
+	ids := proposeAll(t, distiller)
+	require.Len(t, ids, 1)
+
+	time.Sleep(5 * time.Millisecond)
+
+	expired, err := distiller.ListProposals(ctx, "test-project", ProposalExpired)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+
+	_, err = distiller.ReviewProposal(ctx, "test-project", ids[0], true)
+	assert.ErrorIs(t, err, ErrProposalNotPending)
+
+	// Expired proposals release their members for new proposals
+	assert.Len(t, proposeAll(t, distiller), 1)
+}
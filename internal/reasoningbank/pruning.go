@@ -0,0 +1,273 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// PruneAction is what happens to a memory selected for pruning.
+type PruneAction string
+
+const (
+	// PruneArchive marks memories archived. They drop out of search but stay
+	// in the bank for attribution and can be restored.
+	PruneArchive PruneAction = "archive"
+
+	// PruneDelete removes memories from the bank permanently.
+	PruneDelete PruneAction = "delete"
+)
+
+// PruneRule identifies the policy rule that selected a memory.
+type PruneRule string
+
+const (
+	// PruneRuleMinConfidence selects memories below the confidence floor.
+	PruneRuleMinConfidence PruneRule = "min_confidence"
+
+	// PruneRuleUnusedAge selects never-used memories older than the max age.
+	PruneRuleUnusedAge PruneRule = "unused_age"
+
+	// PruneRuleMaxMemories selects the lowest-ranked memories over the bank
+	// size limit, using RankForRetention.
+	PruneRuleMaxMemories PruneRule = "max_memories"
+)
+
+// PruningPolicy configures which memories a pruning run removes.
+// Zero values disable the corresponding rule.
+type PruningPolicy struct {
+	// MaxMemories is the maximum number of active memories kept per project.
+	MaxMemories int `json:"max_memories,omitempty"`
+
+	// MinConfidence is the confidence floor; memories below it are pruned.
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+
+	// MaxUnusedAge prunes memories that have never been used and are older
+	// than this.
+	MaxUnusedAge time.Duration `json:"max_unused_age,omitempty"`
+
+	// Action is what happens to pruned memories. Defaults to PruneArchive.
+	Action PruneAction `json:"action,omitempty"`
+
+	// DryRun reports what would be pruned without changing anything.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Validate checks that the policy is well-formed.
+func (p PruningPolicy) Validate() error {
+	if p.MaxMemories < 0 {
+		return fmt.Errorf("max memories must be non-negative")
+	}
+	if p.MinConfidence < 0 || p.MinConfidence > 1 {
+		return ErrInvalidConfidence
+	}
+	if p.MaxUnusedAge < 0 {
+		return fmt.Errorf("max unused age must be non-negative")
+	}
+	switch p.Action {
+	case "", PruneArchive, PruneDelete:
+	default:
+		return fmt.Errorf("invalid prune action %q: must be %q or %q", p.Action, PruneArchive, PruneDelete)
+	}
+	return nil
+}
+
+// PruneCandidate is a memory selected for pruning and the rule that selected it.
+type PruneCandidate struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	Confidence float64   `json:"confidence"`
+	Importance float64   `json:"importance"`
+	CreatedAt  time.Time `json:"created_at"`
+	Rule       PruneRule `json:"rule"`
+}
+
+// PruneReport describes a pruning run. It is built in full before any
+// memory is changed, so a dry run shows exactly what a real run would do.
+type PruneReport struct {
+	ProjectID  string            `json:"project_id"`
+	Action     PruneAction       `json:"action"`
+	DryRun     bool              `json:"dry_run"`
+	Scanned    int               `json:"scanned"`
+	Candidates []PruneCandidate  `json:"candidates"`
+	ByRule     map[PruneRule]int `json:"by_rule"`
+	Pruned     int               `json:"pruned"`
+	Failed     []string          `json:"failed,omitempty"`
+}
+
+// Prune applies policy to a project's active memories.
+//
+// Rules are applied in order: the confidence floor, then the unused-age
+// limit, then the bank size limit over whatever remains. Each memory is
+// attributed to the first rule that selects it. Archived memories are
+// neither scanned nor counted against MaxMemories.
+//
+// Failures to prune individual memories are recorded in the report and do
+// not abort the run.
+func (s *Service) Prune(ctx context.Context, projectID string, policy PruningPolicy) (*PruneReport, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if policy.Action == "" {
+		policy.Action = PruneArchive
+	}
+
+	report := &PruneReport{
+		ProjectID:  projectID,
+		Action:     policy.Action,
+		DryRun:     policy.DryRun,
+		Candidates: []PruneCandidate{},
+		ByRule:     map[PruneRule]int{},
+	}
+
+	now := time.Now()
+	var kept []Memory
+	err := s.StreamMemories(ctx, projectID, 0, func(m Memory) error {
+		// Quarantined memories wait for review rather than pruning
+		if m.State != MemoryStateActive {
+			return nil
+		}
+		report.Scanned++
+
+		switch {
+		case policy.MinConfidence > 0 && m.Confidence < policy.MinConfidence:
+			report.add(m, PruneRuleMinConfidence)
+		case policy.MaxUnusedAge > 0 && now.Sub(m.CreatedAt) > policy.MaxUnusedAge && !s.everUsed(ctx, &m):
+			report.add(m, PruneRuleUnusedAge)
+		default:
+			kept = append(kept, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning memories: %w", err)
+	}
+
+	if policy.MaxMemories > 0 && len(kept) > policy.MaxMemories {
+		RankForRetention(kept)
+		for _, m := range kept[policy.MaxMemories:] {
+			report.add(m, PruneRuleMaxMemories)
+		}
+	}
+
+	s.logger.Info("memory pruning report",
+		zap.String("project_id", projectID),
+		zap.String("action", string(policy.Action)),
+		zap.Bool("dry_run", policy.DryRun),
+		zap.Int("scanned", report.Scanned),
+		zap.Int("candidates", len(report.Candidates)),
+		zap.Int(string(PruneRuleMinConfidence), report.ByRule[PruneRuleMinConfidence]),
+		zap.Int(string(PruneRuleUnusedAge), report.ByRule[PruneRuleUnusedAge]),
+		zap.Int(string(PruneRuleMaxMemories), report.ByRule[PruneRuleMaxMemories]))
+
+	if policy.DryRun {
+		return report, nil
+	}
+
+	for _, c := range report.Candidates {
+		if err := s.pruneMemory(ctx, projectID, c.ID, policy.Action); err != nil {
+			s.logger.Warn("failed to prune memory",
+				zap.String("project_id", projectID),
+				zap.String("memory_id", c.ID),
+				zap.Error(err))
+			report.Failed = append(report.Failed, c.ID)
+			continue
+		}
+		report.Pruned++
+		if s.pruneCounter != nil {
+			s.pruneCounter.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("project_id", projectID),
+				attribute.String("rule", string(c.Rule)),
+				attribute.String("action", string(policy.Action)),
+			))
+		}
+	}
+
+	return report, nil
+}
+
+func (r *PruneReport) add(m Memory, rule PruneRule) {
+	r.Candidates = append(r.Candidates, PruneCandidate{
+		ID:         m.ID,
+		Title:      m.Title,
+		Confidence: m.Confidence,
+		Importance: m.Importance,
+		CreatedAt:  m.CreatedAt,
+		Rule:       rule,
+	})
+	r.ByRule[rule]++
+}
+
+// everUsed reports whether a memory has any record of use: a usage count,
+// learned importance, or recent signals not yet reflected in either.
+func (s *Service) everUsed(ctx context.Context, m *Memory) bool {
+	if m.UsageCount > 0 || m.Importance > 0 {
+		return true
+	}
+	if s.signalStore == nil {
+		return false
+	}
+	signals, err := s.signalStore.GetRecentSignals(ctx, m.ID, 30*24*time.Hour)
+	if err != nil {
+		// Err on the side of keeping the memory
+		return true
+	}
+	return len(signals) > 0
+}
+
+// pruneMemory archives or deletes a single memory.
+func (s *Service) pruneMemory(ctx context.Context, projectID, memoryID string, action PruneAction) error {
+	if action == PruneDelete {
+		return s.DeleteByProjectID(ctx, projectID, memoryID)
+	}
+
+	memory, err := s.GetByProjectID(ctx, projectID, memoryID)
+	if err != nil {
+		return err
+	}
+	memory.State = MemoryStateArchived
+	memory.UpdatedAt = time.Now()
+	return s.replaceMemory(ctx, projectID, memory)
+}
+
+// replaceMemory overwrites a stored memory. It writes the document directly
+// rather than through Record, which would reset a zero confidence, buffer
+// session memories and rescan the content. memory is expected to have been
+// read with its batched updates, which the write absorbs. It fails with
+// ErrRevisionConflict if the memory was written since it was read.
+//
+// Archived and quarantined memories lose their chunk vectors, so search
+// cannot reach them through a chunk. Callers that reactivate a memory or
+// change its content or labels call recordChunks.
+func (s *Service) replaceMemory(ctx context.Context, projectID string, memory *Memory) error {
+	if err := s.storeMemory(ctx, projectID, memory); err != nil {
+		return err
+	}
+	s.writes.discard(memory.ID)
+
+	if memory.State != MemoryStateActive {
+		if err := s.deleteProjectChunks(ctx, projectID, memory.ID); err != nil {
+			s.logger.Warn("failed to delete chunks of inactive memory",
+				zap.String("id", memory.ID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// storeMemory writes memory over the stored document, checking its
+// revision.
+func (s *Service) storeMemory(ctx context.Context, projectID string, memory *Memory) error {
+	store, collectionName, err := s.getStore(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return s.commitMemory(ctx, store, collectionName, memory)
+}
@@ -0,0 +1,162 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
+)
+
+// PruningScheduler runs memory pruning periodically for configured projects.
+//
+// Like ConsolidationScheduler, it is started and stopped explicitly and
+// recovers from panics in individual runs. Each run logs the full pruning
+// report before any memory is changed.
+type PruningScheduler struct {
+	service    *Service
+	policy     PruningPolicy
+	interval   time.Duration
+	projectIDs []string
+
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+
+	readOnly *readonly.Mode
+	logger   *zap.Logger
+}
+
+// NewPruningScheduler creates a pruning scheduler. The policy is validated
+// up front so a misconfiguration fails at startup rather than on every run.
+func NewPruningScheduler(service *Service, policy PruningPolicy, interval time.Duration, projectIDs []string, logger *zap.Logger) (*PruningScheduler, error) {
+	if service == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pruning policy: %w", err)
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	return &PruningScheduler{
+		service:    service,
+		policy:     policy,
+		interval:   interval,
+		projectIDs: projectIDs,
+		stopCh:     make(chan struct{}),
+		logger:     logger,
+	}, nil
+}
+
+// SetReadOnly makes the scheduler skip runs while read-only mode is on.
+// Call it before Start.
+func (s *PruningScheduler) SetReadOnly(mode *readonly.Mode) {
+	s.readOnly = mode
+}
+
+// Start begins scheduled pruning. Returns an error if already running.
+func (s *PruningScheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("scheduler is already running")
+	}
+
+	s.stopCh = make(chan struct{})
+	s.running = true
+
+	s.logger.Info("pruning scheduler started",
+		zap.Duration("interval", s.interval),
+		zap.Int("project_count", len(s.projectIDs)),
+	)
+
+	go s.run()
+
+	return nil
+}
+
+// Stop stops scheduled pruning. Calling Stop on a stopped scheduler is a no-op.
+func (s *PruningScheduler) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.logger.Info("stopping pruning scheduler")
+	s.running = false
+	close(s.stopCh)
+
+	return nil
+}
+
+func (s *PruningScheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.safeRunPruning()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// safeRunPruning wraps runPruning with panic recovery.
+func (s *PruningScheduler) safeRunPruning() {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("pruning run panicked, continuing scheduler",
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+		}
+	}()
+	s.runPruning()
+}
+
+// runPruning prunes each configured project. Errors are logged per project
+// and do not stop the run.
+func (s *PruningScheduler) runPruning() {
+	if len(s.projectIDs) == 0 {
+		s.logger.Debug("no projects configured for pruning, skipping")
+		return
+	}
+	if s.readOnly.Enabled() {
+		s.logger.Info("read-only mode enabled, skipping scheduled pruning",
+			zap.String("reason", s.readOnly.Status().Reason))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	for _, projectID := range s.projectIDs {
+		report, err := s.service.Prune(ctx, projectID, s.policy)
+		if err != nil {
+			s.logger.Error("pruning failed",
+				zap.String("project_id", projectID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		s.logger.Info("scheduled pruning completed",
+			zap.String("project_id", projectID),
+			zap.Int("pruned", report.Pruned),
+			zap.Int("failed", len(report.Failed)),
+		)
+	}
+}
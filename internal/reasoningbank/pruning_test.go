@@ -0,0 +1,131 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newPruningTestService returns a service holding memories with the given
+// confidences. The memory at index old was created 100 days ago.
+func newPruningTestService(t *testing.T, confidences []float64, old int) (*Service, []*Memory) {
+	t.Helper()
+	ctx := context.Background()
+
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+
+	memories := make([]*Memory, len(confidences))
+	for i, c := range confidences {
+		mem, _ := NewMemory("test-project", fmt.Sprintf("Memory %d", i), "content", OutcomeSuccess, nil)
+		mem.Confidence = c
+		if i == old {
+			mem.CreatedAt = time.Now().Add(-100 * 24 * time.Hour)
+		}
+		require.NoError(t, svc.Record(ctx, mem))
+		memories[i] = mem
+	}
+	return svc, memories
+}
+
+func TestService_Prune(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dry run reports without changing", func(t *testing.T) {
+		svc, memories := newPruningTestService(t, []float64{0.1, 0.9, 0.9}, 2)
+
+		report, err := svc.Prune(ctx, "test-project", PruningPolicy{
+			MinConfidence: 0.3,
+			MaxUnusedAge:  90 * 24 * time.Hour,
+			DryRun:        true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Scanned)
+		assert.Equal(t, PruneArchive, report.Action)
+		assert.Equal(t, 1, report.ByRule[PruneRuleMinConfidence])
+		assert.Equal(t, 1, report.ByRule[PruneRuleUnusedAge])
+		assert.Zero(t, report.Pruned)
+
+		for _, m := range memories {
+			got, err := svc.Get(ctx, m.ID)
+			require.NoError(t, err)
+			assert.Equal(t, MemoryStateActive, got.State)
+		}
+	})
+
+	t.Run("archives memories over the size limit by retention rank", func(t *testing.T) {
+		svc, memories := newPruningTestService(t, []float64{0.6, 0.9, 0.7}, -1)
+
+		report, err := svc.Prune(ctx, "test-project", PruningPolicy{MaxMemories: 2})
+		require.NoError(t, err)
+		require.Len(t, report.Candidates, 1)
+		assert.Equal(t, memories[0].ID, report.Candidates[0].ID, "least confident memory goes first")
+		assert.Equal(t, PruneRuleMaxMemories, report.Candidates[0].Rule)
+		assert.Equal(t, 1, report.Pruned)
+
+		got, err := svc.Get(ctx, memories[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, MemoryStateArchived, got.State)
+		assert.Equal(t, 0.6, got.Confidence)
+
+		// Archived memories no longer count against the limit
+		report, err = svc.Prune(ctx, "test-project", PruningPolicy{MaxMemories: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Scanned)
+		assert.Empty(t, report.Candidates)
+	})
+
+	t.Run("delete removes memories", func(t *testing.T) {
+		svc, memories := newPruningTestService(t, []float64{0.1, 0.9}, -1)
+
+		report, err := svc.Prune(ctx, "test-project", PruningPolicy{MinConfidence: 0.3, Action: PruneDelete})
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Pruned)
+
+		_, err = svc.Get(ctx, memories[0].ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("used memories survive the age rule", func(t *testing.T) {
+		svc, memories := newPruningTestService(t, []float64{0.9}, 0)
+		_, err := svc.RecordOutcome(ctx, memories[0].ID, true, "session-1")
+		require.NoError(t, err)
+
+		report, err := svc.Prune(ctx, "test-project", PruningPolicy{MaxUnusedAge: 90 * 24 * time.Hour})
+		require.NoError(t, err)
+		assert.Empty(t, report.Candidates)
+	})
+
+	t.Run("validates policy", func(t *testing.T) {
+		svc, _ := newPruningTestService(t, nil, -1)
+
+		_, err := svc.Prune(ctx, "", PruningPolicy{})
+		assert.ErrorIs(t, err, ErrEmptyProjectID)
+
+		_, err = svc.Prune(ctx, "test-project", PruningPolicy{Action: "shred"})
+		assert.Error(t, err)
+
+		_, err = svc.Prune(ctx, "test-project", PruningPolicy{MinConfidence: 2})
+		assert.ErrorIs(t, err, ErrInvalidConfidence)
+	})
+}
+
+func TestPruningScheduler_StartStop(t *testing.T) {
+	svc, _ := newPruningTestService(t, nil, -1)
+
+	_, err := NewPruningScheduler(svc, PruningPolicy{Action: "shred"}, time.Hour, nil, zap.NewNop())
+	assert.Error(t, err)
+
+	scheduler, err := NewPruningScheduler(svc, PruningPolicy{MaxMemories: 10}, time.Hour, []string{"test-project"}, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, scheduler.Start())
+	assert.Error(t, scheduler.Start(), "second start should fail")
+	require.NoError(t, scheduler.Stop())
+	require.NoError(t, scheduler.Stop(), "stop is idempotent")
+}
@@ -0,0 +1,314 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// QualityStrictness selects how hard CheckQuality is on agent-recorded
+// memories.
+type QualityStrictness string
+
+const (
+	// QualityOff disables quality checks.
+	QualityOff QualityStrictness = "off"
+	// QualityLenient rejects only near-empty memories and raw tool output.
+	QualityLenient QualityStrictness = "lenient"
+	// QualityStandard also rejects short fragments and consults the
+	// strategy classifier when one is configured.
+	QualityStandard QualityStrictness = "standard"
+	// QualityStrict also requires the memory to explain itself (why, when
+	// or instead of what) and consults the classifier.
+	QualityStrict QualityStrictness = "strict"
+)
+
+// ParseQualityStrictness parses a strictness level. Empty means
+// QualityStandard.
+func ParseQualityStrictness(s string) (QualityStrictness, error) {
+	switch QualityStrictness(strings.ToLower(strings.TrimSpace(s))) {
+	case "", QualityStandard:
+		return QualityStandard, nil
+	case QualityOff:
+		return QualityOff, nil
+	case QualityLenient:
+		return QualityLenient, nil
+	case QualityStrict:
+		return QualityStrict, nil
+	default:
+		return "", fmt.Errorf("invalid quality strictness %q (must be off, lenient, standard or strict)", s)
+	}
+}
+
+// Quality issue codes returned in QualityIssue.Code.
+const (
+	IssueContentTooShort = "content_too_short"
+	IssueTooFewWords     = "too_few_words"
+	IssueToolOutput      = "tool_output"
+	IssueNoRationale     = "no_rationale"
+	IssueNotReusable     = "not_reusable"
+)
+
+// QualityIssue is one reason a memory was rejected.
+type QualityIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// Hint tells the agent how to rewrite the memory so it is accepted.
+	Hint string `json:"hint"`
+}
+
+// qualityThresholds are the heuristic limits of one strictness level.
+type qualityThresholds struct {
+	minChars int
+	minWords int
+
+	// maxToolOutputShare is the largest share of lines that may look like
+	// tool output, over at least minToolOutputLines lines.
+	maxToolOutputShare float64
+
+	requireRationale bool
+	useClassifier    bool
+}
+
+// minToolOutputLines is the fewest lines judged as tool output; a memory
+// quoting one error line is fine.
+const minToolOutputLines = 3
+
+var qualityLevels = map[QualityStrictness]qualityThresholds{
+	QualityLenient:  {minChars: 20, minWords: 4, maxToolOutputShare: 0.9},
+	QualityStandard: {minChars: 40, minWords: 8, maxToolOutputShare: 0.7, useClassifier: true},
+	QualityStrict:   {minChars: 80, minWords: 15, maxToolOutputShare: 0.5, requireRationale: true, useClassifier: true},
+}
+
+// toolOutputLine matches lines that are typical of pasted command output:
+// compiler and linter locations, stack frames, test runner and log lines,
+// diff hunks, shell prompts and bare JSON punctuation.
+var toolOutputLine = regexp.MustCompile(`^(?:` +
+	`\S+\.\w+:\d+(?::\d+)?:` + // file.go:12:3:
+	`|\s*at \S+\(.*\)$` + // JS/Java stack frame
+	`|\s*File ".*", line \d+` + // Python stack frame
+	`|goroutine \d+ \[` +
+	`|\s+\S+\.go:\d+` + // Go stack frame location
+	`|(?:---|===) (?:FAIL|PASS|RUN|SKIP)` +
+	`|(?:ok|FAIL|PASS)\s+\S+` +
+	`|\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}` + // timestamped log line
+	`|\[?(?:DEBUG|INFO|WARN|WARNING|ERROR|FATAL)\]?[\s:]` +
+	`|@@ .* @@` +
+	`|[+-]{3} \S` +
+	`|[$#>] \S` + // shell prompt
+	`|[\[\]{}(),]+$` +
+	`|\s*"[^"]+":\s*.+,?$` + // JSON member
+	`|\x1b\[` +
+	`)`)
+
+// rationaleWords indicate a memory explains why or when to act, which
+// separates a strategy from a bare observation.
+var rationaleWords = regexp.MustCompile(`(?i)\b(?:because|since|so that|due to|caused?|when|whenever|if|unless|instead|rather than|avoid|prefer|should|must|never|always|otherwise|works? by|fix(?:ed|es)? by|the trick|root cause)\b`)
+
+// StrategyClassifier judges whether a memory is a reusable strategy rather
+// than a one-off note.
+type StrategyClassifier interface {
+	// IsReusable reports whether the memory would help in a future task,
+	// and if not, why.
+	IsReusable(ctx context.Context, title, content string) (bool, string, error)
+}
+
+// LLMStrategyClassifier asks an LLM whether a memory is a reusable strategy.
+type LLMStrategyClassifier struct {
+	client LLMClient
+}
+
+// NewLLMStrategyClassifier creates an LLM-based StrategyClassifier.
+func NewLLMStrategyClassifier(client LLMClient) *LLMStrategyClassifier {
+	return &LLMStrategyClassifier{client: client}
+}
+
+// IsReusable implements StrategyClassifier.
+func (c *LLMStrategyClassifier) IsReusable(ctx context.Context, title, content string) (bool, string, error) {
+	prompt := fmt.Sprintf(`You review entries for a knowledge base of engineering strategies that coding agents consult in future tasks.
+Answer whether the entry below is a reusable strategy or lesson (it would help someone facing a similar problem later)
+or not (a status update, raw output, a restatement of the task, or something only meaningful in its original session).
+
+Reply with REUSABLE, or NOT_REUSABLE followed by a colon and a one-sentence reason.
+
+Title: %s
+Content: %s`, sanitizePromptContent(title, maxMemoryTitleLength), sanitizePromptContent(content, maxMemoryContentLength))
+
+	response, err := c.client.Complete(ctx, prompt)
+	if err != nil {
+		return false, "", fmt.Errorf("classifying memory: %w", err)
+	}
+
+	response = strings.TrimSpace(response)
+	verdict, reason, _ := strings.Cut(response, ":")
+	switch strings.ToUpper(strings.TrimSpace(verdict)) {
+	case "NOT_REUSABLE", "NOT REUSABLE":
+		return false, strings.TrimSpace(reason), nil
+	case "REUSABLE":
+		return true, "", nil
+	default:
+		// An unparseable answer must not block recording
+		return true, "", nil
+	}
+}
+
+// QualityGate rejects low-quality memories before they are recorded.
+type QualityGate struct {
+	strictness QualityStrictness
+	classifier StrategyClassifier
+}
+
+// NewQualityGate creates a gate with the given strictness. classifier may
+// be nil, in which case only the heuristics run.
+func NewQualityGate(strictness QualityStrictness, classifier StrategyClassifier) *QualityGate {
+	return &QualityGate{strictness: strictness, classifier: classifier}
+}
+
+// Strictness returns the gate's strictness level.
+func (g *QualityGate) Strictness() QualityStrictness {
+	return g.strictness
+}
+
+// Check returns the reasons memory should be rejected, or nil. Heuristics
+// run first so the classifier is only asked about plausible memories; a
+// classifier error is returned rather than treated as a rejection.
+func (g *QualityGate) Check(ctx context.Context, memory *Memory) ([]QualityIssue, error) {
+	levels, ok := qualityLevels[g.strictness]
+	if !ok {
+		return nil, nil
+	}
+
+	content := strings.TrimSpace(memory.Content)
+	var issues []QualityIssue
+
+	if n := utf8.RuneCountInString(content); n < levels.minChars {
+		issues = append(issues, QualityIssue{
+			Code:    IssueContentTooShort,
+			Message: fmt.Sprintf("content is %d characters, need at least %d", n, levels.minChars),
+			Hint:    "Describe the problem, what worked and why, in full sentences.",
+		})
+	} else if n := len(strings.Fields(content)); n < levels.minWords {
+		issues = append(issues, QualityIssue{
+			Code:    IssueTooFewWords,
+			Message: fmt.Sprintf("content is %d words, need at least %d", n, levels.minWords),
+			Hint:    "Describe the problem, what worked and why, in full sentences.",
+		})
+	}
+
+	if lines, matched := toolOutputShare(content); lines >= minToolOutputLines && float64(matched)/float64(lines) > levels.maxToolOutputShare {
+		issues = append(issues, QualityIssue{
+			Code:    IssueToolOutput,
+			Message: fmt.Sprintf("%d of %d lines look like pasted tool output", matched, lines),
+			Hint:    "Summarize what the output showed and what to do about it; quote at most the key line.",
+		})
+	}
+
+	if levels.requireRationale && !rationaleWords.MatchString(memory.Title+" "+content) {
+		issues = append(issues, QualityIssue{
+			Code:    IssueNoRationale,
+			Message: "content does not say why or when the approach applies",
+			Hint:    "Add the reason it works, when to use it, or what to avoid instead.",
+		})
+	}
+
+	if len(issues) > 0 || !levels.useClassifier || g.classifier == nil {
+		return issues, nil
+	}
+
+	reusable, reason, err := g.classifier.IsReusable(ctx, memory.Title, content)
+	if err != nil {
+		return nil, err
+	}
+	if !reusable {
+		message := "content is not a reusable strategy"
+		if reason != "" {
+			message += ": " + reason
+		}
+		issues = append(issues, QualityIssue{
+			Code:    IssueNotReusable,
+			Message: message,
+			Hint:    "Record the general lesson a future task could apply, not a status update or session-specific detail.",
+		})
+	}
+	return issues, nil
+}
+
+// toolOutputShare counts the non-blank lines of content and how many of
+// them look like tool output.
+func toolOutputShare(content string) (lines, matched int) {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines++
+		if toolOutputLine.MatchString(line) {
+			matched++
+		}
+	}
+	return lines, matched
+}
+
+// lowQualityError reports issues as an INVALID_INPUT error whose details
+// list each issue, so agents can fix the memory and record it again.
+func lowQualityError(issues []QualityIssue) error {
+	messages := make([]string, len(issues))
+	for i, issue := range issues {
+		messages[i] = issue.Message
+	}
+	return &ctxerrors.Error{
+		Code:    ctxerrors.CodeInvalidInput,
+		Message: "memory rejected by quality checks: " + strings.Join(messages, "; "),
+		Details: map[string]any{"reason": "low_quality", "issues": issues},
+	}
+}
+
+// WithQualityGate enables CheckQuality. Without it, CheckQuality accepts
+// every memory.
+func WithQualityGate(g *QualityGate) ServiceOption {
+	return func(s *Service) {
+		s.qualityGate = g
+	}
+}
+
+// CheckQuality runs the quality gate on a memory an agent is about to
+// record. It returns an INVALID_INPUT error listing every issue when the
+// memory is rejected.
+//
+// Record does not call it, so distilled and consolidated memories are never
+// rejected; callers recording on an agent's behalf call it first.
+func (s *Service) CheckQuality(ctx context.Context, memory *Memory) error {
+	if memory == nil {
+		return ErrInvalidMemory
+	}
+	if s.qualityGate == nil {
+		return nil
+	}
+
+	issues, err := s.qualityGate.Check(ctx, memory)
+	if err != nil {
+		// The classifier is advisory; an outage must not block recording
+		s.logger.Warn("memory quality classifier failed", zap.Error(err))
+		return nil
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	codes := make([]string, len(issues))
+	for i, issue := range issues {
+		codes[i] = issue.Code
+	}
+	s.recordError(ctx, "record", "low_quality")
+	s.logger.Info("memory rejected by quality checks",
+		zap.String("project_id", memory.ProjectID),
+		zap.String("title", memory.Title),
+		zap.Strings("issues", codes))
+	return lowQualityError(issues)
+}
@@ -0,0 +1,168 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+const goodMemoryContent = "When the Qdrant client times out during bulk upserts, split batches into 100 points. " +
+	"Large batches exceed the gRPC message limit, so smaller batches avoid the retry storm."
+
+const pastedTestOutput = `--- FAIL: TestRecord (0.00s)
+    service_test.go:42: expected 1, got 2
+FAIL
+FAIL	github.com/acme/api/internal/store	0.012s
+ok  	github.com/acme/api/internal/http	0.034s`
+
+func qualityIssueCodes(issues []QualityIssue) []string {
+	codes := make([]string, len(issues))
+	for i, issue := range issues {
+		codes[i] = issue.Code
+	}
+	return codes
+}
+
+func TestParseQualityStrictness(t *testing.T) {
+	for input, want := range map[string]QualityStrictness{
+		"":         QualityStandard,
+		"off":      QualityOff,
+		"Lenient":  QualityLenient,
+		"standard": QualityStandard,
+		" strict ": QualityStrict,
+	} {
+		got, err := ParseQualityStrictness(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseQualityStrictness("paranoid")
+	assert.Error(t, err)
+}
+
+func TestQualityGate_Check(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		strictness QualityStrictness
+		title      string
+		content    string
+		want       []string
+	}{
+		{"good memory passes strict", QualityStrict, "Batch Qdrant upserts", goodMemoryContent, nil},
+		{"off accepts anything", QualityOff, "x", "ok", nil},
+		{"too short", QualityStandard, "Fix", "Fixed the bug.", []string{IssueContentTooShort}},
+		{"too few words", QualityStandard, "Fix", "Refactored internal/reasoningbank/service.go thoroughly", []string{IssueTooFewWords}},
+		{"lenient allows short notes", QualityLenient, "Fix", "Use go mod tidy first.", nil},
+		{"tool output", QualityStandard, "Tests", pastedTestOutput, []string{IssueToolOutput}},
+		{
+			"no rationale under strict", QualityStrict, "Upserts",
+			"Changed the Qdrant upsert batch size from 1000 points to 100 points in the indexing worker configuration today.",
+			[]string{IssueNoRationale},
+		},
+		{
+			"rationale not required under standard", QualityStandard, "Upserts",
+			"Changed the Qdrant upsert batch size from 1000 points to 100 points in the indexing worker configuration today.",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := NewQualityGate(tt.strictness, nil)
+			issues, err := gate.Check(ctx, &Memory{Title: tt.title, Content: tt.content})
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Empty(t, issues)
+				return
+			}
+			assert.Equal(t, tt.want, qualityIssueCodes(issues))
+			for _, issue := range issues {
+				assert.NotEmpty(t, issue.Message)
+				assert.NotEmpty(t, issue.Hint)
+			}
+		})
+	}
+}
+
+func TestQualityGate_Classifier(t *testing.T) {
+	ctx := context.Background()
+	memory := &Memory{Title: "Batch Qdrant upserts", Content: goodMemoryContent}
+
+	t.Run("rejection includes the reason", func(t *testing.T) {
+		llm := &mockLLMClient{response: "NOT_REUSABLE: it only reports the current task status"}
+		gate := NewQualityGate(QualityStandard, NewLLMStrategyClassifier(llm))
+
+		issues, err := gate.Check(ctx, memory)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, IssueNotReusable, issues[0].Code)
+		assert.Contains(t, issues[0].Message, "current task status")
+		assert.Contains(t, llm.lastPrompt, "Batch Qdrant upserts")
+	})
+
+	t.Run("reusable and unparseable answers pass", func(t *testing.T) {
+		for _, response := range []string{"REUSABLE", "I think so"} {
+			gate := NewQualityGate(QualityStandard, NewLLMStrategyClassifier(&mockLLMClient{response: response}))
+			issues, err := gate.Check(ctx, memory)
+			require.NoError(t, err)
+			assert.Empty(t, issues, response)
+		}
+	})
+
+	t.Run("not consulted when heuristics reject or under lenient", func(t *testing.T) {
+		llm := &mockLLMClient{response: "NOT_REUSABLE: no"}
+
+		_, err := NewQualityGate(QualityStandard, NewLLMStrategyClassifier(llm)).
+			Check(ctx, &Memory{Title: "Fix", Content: "Fixed it."})
+		require.NoError(t, err)
+		_, err = NewQualityGate(QualityLenient, NewLLMStrategyClassifier(llm)).Check(ctx, memory)
+		require.NoError(t, err)
+		assert.Zero(t, llm.callCount)
+	})
+}
+
+func TestService_CheckQuality(t *testing.T) {
+	ctx := context.Background()
+	short := &Memory{ProjectID: "project-123", Title: "Fix", Content: "Fixed the bug."}
+
+	t.Run("accepts everything without a gate", func(t *testing.T) {
+		svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+		assert.NoError(t, svc.CheckQuality(ctx, short))
+	})
+
+	t.Run("returns structured issues", func(t *testing.T) {
+		svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"),
+			WithQualityGate(NewQualityGate(QualityStandard, nil)))
+		require.NoError(t, err)
+
+		err = svc.CheckQuality(ctx, short)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "memory rejected by quality checks")
+		assert.Equal(t, ctxerrors.CodeInvalidInput, ctxerrors.CodeOf(err))
+
+		payload := ctxerrors.PayloadFor(err)
+		assert.Equal(t, "low_quality", payload.Details["reason"])
+		issues, ok := payload.Details["issues"].([]QualityIssue)
+		require.True(t, ok)
+		assert.Equal(t, []string{IssueContentTooShort}, qualityIssueCodes(issues))
+	})
+
+	t.Run("classifier failure does not block recording", func(t *testing.T) {
+		llm := &mockLLMClient{err: errors.New("rate limited")}
+		svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"),
+			WithQualityGate(NewQualityGate(QualityStandard, NewLLMStrategyClassifier(llm))))
+		require.NoError(t, err)
+
+		assert.NoError(t, svc.CheckQuality(ctx, &Memory{Title: "Batch Qdrant upserts", Content: goodMemoryContent}))
+		assert.Equal(t, 1, llm.callCount)
+	})
+}
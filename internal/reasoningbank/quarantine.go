@@ -0,0 +1,85 @@
+package reasoningbank
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// quarantineInjection moves a new memory to MemoryStateQuarantined when its
+// title, description, content or tags look like a prompt injection.
+func (s *Service) quarantineInjection(memory *Memory) {
+	if s.scanner == nil || memory.State != MemoryStateActive {
+		return
+	}
+	rules := s.scanner.ScanFields(memory.Title, memory.Description, memory.Content, strings.Join(memory.Tags, " "))
+	if len(rules) == 0 {
+		return
+	}
+	memory.State = MemoryStateQuarantined
+	memory.QuarantineRules = rules
+	s.logger.Warn("memory quarantined as possible prompt injection",
+		zap.String("id", memory.ID),
+		zap.String("project_id", memory.ProjectID),
+		zap.Strings("rules", rules))
+}
+
+// ListQuarantined returns a project's quarantined memories for review.
+// Release them with ReleaseQuarantine or remove them with DeleteByProjectID.
+func (s *Service) ListQuarantined(ctx context.Context, projectID string) ([]Memory, error) {
+	ctx, err := s.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	memories := []Memory{}
+	err = s.StreamMemories(ctx, projectID, 0, func(m Memory) error {
+		if m.State == MemoryStateQuarantined {
+			memories = append(memories, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return memories, nil
+}
+
+// ReleaseQuarantine makes a quarantined memory searchable after a reviewer
+// has judged it safe. Releasing a memory that is not quarantined is a no-op.
+func (s *Service) ReleaseQuarantine(ctx context.Context, projectID, memoryID string) (*Memory, error) {
+	ctx, err := s.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	memory, err := s.GetByProjectID(ctx, projectID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.State != MemoryStateQuarantined {
+		return memory, nil
+	}
+
+	previousRules := memory.QuarantineRules
+	memory.State = MemoryStateActive
+	memory.QuarantineRules = nil
+	memory.UpdatedAt = time.Now()
+	if err := s.replaceMemory(ctx, projectID, memory); err != nil {
+		return nil, err
+	}
+
+	// Quarantined memories have no chunks; long ones need them now
+	if err := s.recordChunks(ctx, memory); err != nil {
+		s.logger.Warn("failed to chunk released memory",
+			zap.String("id", memoryID),
+			zap.Error(err))
+	}
+
+	s.logger.Info("memory released from quarantine",
+		zap.String("id", memoryID),
+		zap.Strings("rules", previousRules))
+	return memory, nil
+}
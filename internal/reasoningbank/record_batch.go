@@ -0,0 +1,331 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// MaxRecordBatch is the most memories RecordBatch accepts at once.
+const MaxRecordBatch = 50
+
+// BatchMode says what RecordBatch does when some memories cannot be
+// recorded.
+type BatchMode string
+
+const (
+	// BatchAllOrNothing records every memory or none: one invalid memory or
+	// a failed write leaves the batch unrecorded.
+	BatchAllOrNothing BatchMode = "all_or_nothing"
+
+	// BatchBestEffort records the memories that can be and reports the
+	// others.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+var (
+	// ErrEmptyBatch is returned by RecordBatch for a batch without memories.
+	ErrEmptyBatch = ctxerrors.New(ctxerrors.CodeInvalidInput, "batch has no memories")
+
+	// ErrBatchTooLarge is returned by RecordBatch for more than
+	// MaxRecordBatch memories.
+	ErrBatchTooLarge = ctxerrors.New(ctxerrors.CodeInvalidInput, "batch has too many memories")
+
+	// ErrInvalidBatchMode is returned for an unknown batch mode.
+	ErrInvalidBatchMode = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid batch mode")
+)
+
+// ParseBatchMode parses a batch mode name. Empty means BatchAllOrNothing.
+func ParseBatchMode(name string) (BatchMode, error) {
+	switch mode := BatchMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case "":
+		return BatchAllOrNothing, nil
+	case BatchAllOrNothing, BatchBestEffort:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w %q: must be all_or_nothing or best_effort", ErrInvalidBatchMode, name)
+	}
+}
+
+// BatchStatus is the outcome of one memory of a batch.
+type BatchStatus string
+
+const (
+	// BatchRecorded memories were stored.
+	BatchRecorded BatchStatus = "recorded"
+
+	// BatchRejected memories failed validation or the quality checks.
+	BatchRejected BatchStatus = "rejected"
+
+	// BatchFailed memories were valid but could not be stored.
+	BatchFailed BatchStatus = "failed"
+
+	// BatchSkipped memories were valid but not stored, or stored and rolled
+	// back, because another memory of an all-or-nothing batch failed.
+	BatchSkipped BatchStatus = "skipped"
+)
+
+// BatchOptions configures RecordBatch.
+type BatchOptions struct {
+	// Mode defaults to BatchAllOrNothing.
+	Mode BatchMode
+
+	// CheckQuality runs the quality gate on each memory, as callers
+	// recording on an agent's behalf do before Record.
+	CheckQuality bool
+}
+
+// BatchItem is the outcome of the memory at Index of a batch.
+type BatchItem struct {
+	Index  int
+	Memory *Memory
+	Status BatchStatus
+	Err    error // why the memory was rejected, failed or skipped
+}
+
+// BatchResult is the outcome of RecordBatch, one item per memory in order.
+type BatchResult struct {
+	Mode     BatchMode
+	Items    []BatchItem
+	Recorded int
+}
+
+// RecordBatch records several memories at once. Memories of the same
+// project are embedded and stored in one write, and each memory is
+// validated on its own so the result says which ones were rejected and
+// why. In BatchAllOrNothing mode nothing is recorded unless every memory
+// is; in BatchBestEffort mode the valid memories are recorded regardless.
+//
+// Memories are prepared as Record prepares them, but always stored
+// directly: session-level buffering applies to Record only. RecordBatch
+// returns an error only for a batch it cannot attempt.
+func (s *Service) RecordBatch(ctx context.Context, memories []*Memory, opts BatchOptions) (*BatchResult, error) {
+	mode, err := ParseBatchMode(string(opts.Mode))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(memories) == 0:
+		return nil, ErrEmptyBatch
+	case len(memories) > MaxRecordBatch:
+		return nil, fmt.Errorf("%w: %d, at most %d", ErrBatchTooLarge, len(memories), MaxRecordBatch)
+	}
+
+	result := &BatchResult{Mode: mode, Items: make([]BatchItem, len(memories))}
+	var (
+		projects []string // in order of first appearance
+		groups   = make(map[string]*batchGroup)
+		rejected bool
+	)
+	for i, memory := range memories {
+		item := &result.Items[i]
+		item.Index, item.Memory = i, memory
+
+		memoryCtx, err := s.prepareBatchItem(ctx, memory, opts.CheckQuality)
+		if err != nil {
+			item.Status, item.Err = BatchRejected, err
+			rejected = true
+			continue
+		}
+		group, ok := groups[memory.ProjectID]
+		if !ok {
+			group = &batchGroup{ctx: memoryCtx, projectID: memory.ProjectID}
+			groups[memory.ProjectID] = group
+			projects = append(projects, memory.ProjectID)
+		}
+		group.items = append(group.items, i)
+	}
+
+	// Memories the duplicate guard admitted but that end up unrecorded must
+	// not suppress their retries
+	defer s.forgetUnrecorded(groups, result)
+
+	if rejected && mode == BatchAllOrNothing {
+		s.skipPending(result, errors.New("not recorded: other memories in the batch were rejected"))
+		return result, nil
+	}
+
+	var stored []*batchGroup
+	for _, projectID := range projects {
+		group := groups[projectID]
+		if err := s.storeBatchGroup(group, result); err != nil {
+			for _, i := range group.items {
+				result.Items[i].Status, result.Items[i].Err = BatchFailed, err
+			}
+			if mode == BatchAllOrNothing {
+				s.rollBackBatch(stored, result)
+				s.skipPending(result, errors.New("not recorded: storing other memories in the batch failed"))
+				return result, nil
+			}
+			continue
+		}
+		stored = append(stored, group)
+	}
+
+	for _, group := range stored {
+		for _, i := range group.items {
+			result.Items[i].Status = BatchRecorded
+			result.Recorded++
+			s.countRecorded(group.ctx, result.Items[i].Memory)
+		}
+		// Chunk vectors are an aid to retrieval; the memories are already
+		// stored and searchable through their own vectors if they fail.
+		if err := s.recordBatchChunks(group, result); err != nil {
+			s.recordError(group.ctx, "record", "chunk_store_failed")
+			s.logger.Warn("failed to record memory chunks",
+				zap.String("project_id", group.projectID),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("memory batch recorded",
+		zap.String("mode", string(mode)),
+		zap.Int("memories", len(memories)),
+		zap.Int("recorded", result.Recorded),
+		zap.Int("projects", len(projects)))
+	return result, nil
+}
+
+// batchGroup is the memories of one project in a batch, stored in one
+// write.
+type batchGroup struct {
+	ctx        context.Context // scoped to the project's tenant
+	projectID  string
+	items      []int // indexes into the batch
+	store      vectorstore.Store
+	collection string
+}
+
+// prepareBatchItem checks and prepares one memory of a batch. A memory
+// repeating one recorded recently, including earlier in the batch, is
+// rejected as Record rejects it.
+func (s *Service) prepareBatchItem(ctx context.Context, memory *Memory, checkQuality bool) (context.Context, error) {
+	if memory == nil {
+		return nil, ErrInvalidMemory
+	}
+	if err := checkMemoryLimits(memory); err != nil {
+		return nil, err
+	}
+	if checkQuality {
+		if err := s.CheckQuality(ctx, memory); err != nil {
+			return nil, err
+		}
+	}
+	ctx, err := s.prepareRecord(ctx, memory)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.admitRecord(ctx, memory); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// forgetUnrecorded undoes admitRecord for the memories of a batch that were
+// not recorded.
+func (s *Service) forgetUnrecorded(groups map[string]*batchGroup, result *BatchResult) {
+	for _, group := range groups {
+		for _, i := range group.items {
+			if result.Items[i].Status != BatchRecorded {
+				s.forgetRecord(group.ctx, result.Items[i].Memory)
+			}
+		}
+	}
+}
+
+// storeBatchGroup embeds and stores a project's memories in one write.
+func (s *Service) storeBatchGroup(group *batchGroup, result *BatchResult) error {
+	ctx := group.ctx
+	store, collectionName, err := s.getStore(ctx, group.projectID)
+	if err != nil {
+		s.recordError(ctx, "record", "get_store_failed")
+		return err
+	}
+	if err := s.ensureMemoryCollection(ctx, store, collectionName, group.projectID); err != nil {
+		return err
+	}
+	group.store, group.collection = store, collectionName
+
+	docs := make([]vectorstore.Document, len(group.items))
+	for j, i := range group.items {
+		docs[j] = s.memoryToDocument(result.Items[i].Memory, collectionName)
+	}
+	if _, err := store.AddDocuments(ctx, docs); err != nil {
+		s.recordError(ctx, "record", "store_failed")
+		return fmt.Errorf("storing memories: %w", err)
+	}
+	return nil
+}
+
+// rollBackBatch deletes the memories of groups already stored when an
+// all-or-nothing batch fails. A failed delete is logged; those memories
+// stay recorded.
+func (s *Service) rollBackBatch(stored []*batchGroup, result *BatchResult) {
+	for _, group := range stored {
+		ids := make([]string, len(group.items))
+		for j, i := range group.items {
+			ids[j] = result.Items[i].Memory.ID
+		}
+		if err := group.store.DeleteDocumentsFromCollection(group.ctx, group.collection, ids); err != nil {
+			s.recordError(group.ctx, "record", "rollback_failed")
+			s.logger.Error("failed to roll back memory batch",
+				zap.String("project_id", group.projectID),
+				zap.Strings("ids", ids),
+				zap.Error(err))
+			for _, i := range group.items {
+				result.Items[i].Status = BatchRecorded
+				result.Recorded++
+			}
+		}
+	}
+}
+
+// skipPending marks every memory without an outcome skipped.
+func (s *Service) skipPending(result *BatchResult, reason error) {
+	for i := range result.Items {
+		if result.Items[i].Status == "" {
+			result.Items[i].Status, result.Items[i].Err = BatchSkipped, reason
+		}
+	}
+}
+
+// recordBatchChunks stores the chunk vectors of a project's long memories
+// in one write.
+func (s *Service) recordBatchChunks(group *batchGroup, result *BatchResult) error {
+	ctx := group.ctx
+	store, collectionName, err := s.getCollectionStore(ctx, group.projectID, project.CollectionMemoryChunks)
+	if err != nil {
+		return err
+	}
+	var docs []vectorstore.Document
+	for _, i := range group.items {
+		memory := result.Items[i].Memory
+		if len(memory.Content) > ChunkThreshold && memory.State == MemoryStateActive {
+			docs = append(docs, chunkDocuments(memory, collectionName)...)
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("checking chunk collection: %w", err)
+	}
+	if !exists {
+		if err := store.CreateCollection(ctx, collectionName, 0); err != nil {
+			return fmt.Errorf("creating chunk collection: %w", err)
+		}
+	}
+	if _, err := store.AddDocuments(ctx, docs); err != nil {
+		return fmt.Errorf("storing memory chunks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// batchStore counts writes and fails those to failCollection.
+type batchStore struct {
+	*mockStore
+	writes         map[string]int
+	failCollection string
+}
+
+func newBatchStore() *batchStore {
+	return &batchStore{mockStore: newMockStore(), writes: make(map[string]int)}
+}
+
+func (b *batchStore) AddDocuments(ctx context.Context, docs []vectorstore.Document) ([]string, error) {
+	if len(docs) > 0 {
+		b.writes[docs[0].Collection]++
+		if docs[0].Collection == b.failCollection {
+			return nil, errors.New("store unavailable")
+		}
+	}
+	return b.mockStore.AddDocuments(ctx, docs)
+}
+
+func batchMemory(t *testing.T, projectID, title string) *Memory {
+	t.Helper()
+	memory, err := NewMemory(projectID, title, "Run the migrations before the integration tests", OutcomeSuccess, []string{"testing"})
+	require.NoError(t, err)
+	return memory
+}
+
+func TestService_RecordListener(t *testing.T) {
+	ctx := context.Background()
+	var recorded []string
+	svc, err := NewService(newBatchStore(), zap.NewNop(), WithDefaultTenant("test-tenant"),
+		WithRecordListener(func(ctx context.Context, memory *Memory) {
+			recorded = append(recorded, memory.Title)
+		}))
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Record(ctx, batchMemory(t, "project-a", "Single")))
+	_, err = svc.RecordBatch(ctx, []*Memory{
+		batchMemory(t, "project-a", "First"),
+		batchMemory(t, "project-b", "Second"),
+	}, BatchOptions{})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"Single", "First", "Second"}, recorded)
+}
+
+func TestService_RecordBatch(t *testing.T) {
+	ctx := context.Background()
+	memories, _ := project.GetCollectionName("project-a", project.CollectionMemories)
+	otherMemories, _ := project.GetCollectionName("project-b", project.CollectionMemories)
+
+	t.Run("stores a project's memories in one write", func(t *testing.T) {
+		store := newBatchStore()
+		svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+
+		result, err := svc.RecordBatch(ctx, []*Memory{
+			batchMemory(t, "project-a", "First"),
+			batchMemory(t, "project-a", "Second"),
+			batchMemory(t, "project-b", "Third"),
+		}, BatchOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, BatchAllOrNothing, result.Mode)
+		assert.Equal(t, 3, result.Recorded)
+		for i, item := range result.Items {
+			assert.Equal(t, i, item.Index)
+			assert.Equal(t, BatchRecorded, item.Status)
+			assert.NoError(t, item.Err)
+			assert.Equal(t, ExplicitRecordConfidence, item.Memory.Confidence)
+			assert.EqualValues(t, 1, item.Memory.Revision)
+		}
+		assert.Equal(t, 1, store.writes[memories])
+		assert.Equal(t, 1, store.writes[otherMemories])
+		assert.Len(t, store.collections[memories], 2)
+		assert.Len(t, store.collections[otherMemories], 1)
+	})
+
+	t.Run("all or nothing rejects the batch for one invalid memory", func(t *testing.T) {
+		store := newBatchStore()
+		svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+
+		invalid := batchMemory(t, "project-a", "Too long")
+		invalid.Title = strings.Repeat("x", 100000)
+		result, err := svc.RecordBatch(ctx, []*Memory{batchMemory(t, "project-a", "Valid"), invalid, nil}, BatchOptions{})
+		require.NoError(t, err)
+
+		assert.Zero(t, result.Recorded)
+		assert.Equal(t, BatchSkipped, result.Items[0].Status)
+		assert.Error(t, result.Items[0].Err)
+		assert.Equal(t, BatchRejected, result.Items[1].Status)
+		assert.Error(t, result.Items[1].Err)
+		assert.Equal(t, BatchRejected, result.Items[2].Status)
+		assert.ErrorIs(t, result.Items[2].Err, ErrInvalidMemory)
+		assert.Zero(t, store.writes[memories])
+	})
+
+	t.Run("best effort records the valid memories", func(t *testing.T) {
+		store := newBatchStore()
+		svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+
+		invalid := batchMemory(t, "project-a", "Invalid")
+		invalid.Outcome = "maybe"
+		result, err := svc.RecordBatch(ctx, []*Memory{batchMemory(t, "project-a", "Valid"), invalid}, BatchOptions{Mode: BatchBestEffort})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Recorded)
+		assert.Equal(t, BatchRecorded, result.Items[0].Status)
+		assert.Equal(t, BatchRejected, result.Items[1].Status)
+		assert.ErrorIs(t, result.Items[1].Err, ErrInvalidOutcome)
+		assert.Len(t, store.collections[memories], 1)
+	})
+
+	t.Run("all or nothing rolls back on a failed write", func(t *testing.T) {
+		store := newBatchStore()
+		store.failCollection = otherMemories
+		svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+
+		result, err := svc.RecordBatch(ctx, []*Memory{
+			batchMemory(t, "project-a", "First"),
+			batchMemory(t, "project-b", "Second"),
+		}, BatchOptions{})
+		require.NoError(t, err)
+
+		assert.Zero(t, result.Recorded)
+		assert.Equal(t, BatchSkipped, result.Items[0].Status)
+		assert.Equal(t, BatchFailed, result.Items[1].Status)
+		assert.ErrorContains(t, result.Items[1].Err, "store unavailable")
+		assert.Empty(t, store.collections[memories], "stored memories are rolled back")
+	})
+
+	t.Run("best effort keeps other projects on a failed write", func(t *testing.T) {
+		store := newBatchStore()
+		store.failCollection = otherMemories
+		svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+
+		result, err := svc.RecordBatch(ctx, []*Memory{
+			batchMemory(t, "project-a", "First"),
+			batchMemory(t, "project-b", "Second"),
+		}, BatchOptions{Mode: BatchBestEffort})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Recorded)
+		assert.Equal(t, BatchRecorded, result.Items[0].Status)
+		assert.Equal(t, BatchFailed, result.Items[1].Status)
+		assert.Len(t, store.collections[memories], 1)
+	})
+
+	t.Run("quality checks", func(t *testing.T) {
+		store := newBatchStore()
+		svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"), WithQualityGate(NewQualityGate(QualityLenient, nil)))
+		require.NoError(t, err)
+
+		vague := batchMemory(t, "project-a", "Note")
+		vague.Content = "fixed it"
+		result, err := svc.RecordBatch(ctx, []*Memory{batchMemory(t, "project-a", "Valid"), vague}, BatchOptions{Mode: BatchBestEffort, CheckQuality: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, BatchRecorded, result.Items[0].Status)
+		assert.Equal(t, BatchRejected, result.Items[1].Status)
+	})
+
+	t.Run("rejects batches it cannot attempt", func(t *testing.T) {
+		svc, err := NewService(newBatchStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+
+		_, err = svc.RecordBatch(ctx, nil, BatchOptions{})
+		assert.ErrorIs(t, err, ErrEmptyBatch)
+
+		tooMany := make([]*Memory, MaxRecordBatch+1)
+		for i := range tooMany {
+			tooMany[i] = batchMemory(t, "project-a", "Memory")
+		}
+		_, err = svc.RecordBatch(ctx, tooMany, BatchOptions{})
+		assert.ErrorIs(t, err, ErrBatchTooLarge)
+
+		_, err = svc.RecordBatch(ctx, tooMany[:1], BatchOptions{Mode: "some"})
+		assert.ErrorIs(t, err, ErrInvalidBatchMode)
+	})
+}
+
+func TestParseBatchMode(t *testing.T) {
+	mode, err := ParseBatchMode("")
+	require.NoError(t, err)
+	assert.Equal(t, BatchAllOrNothing, mode)
+
+	mode, err = ParseBatchMode("Best_Effort")
+	require.NoError(t, err)
+	assert.Equal(t, BatchBestEffort, mode)
+
+	_, err = ParseBatchMode("partial")
+	assert.ErrorIs(t, err, ErrInvalidBatchMode)
+}
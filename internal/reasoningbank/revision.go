@@ -0,0 +1,150 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// ErrRevisionConflict is returned when a memory was written by someone else
+// between being read and being updated. Updates that derive from stored
+// signals retry on their own; callers that get it should re-read the memory
+// and retry.
+var ErrRevisionConflict = ctxerrors.Conflict("memory was modified concurrently; re-read it and retry")
+
+// maxConflictRetries is how often an update that re-reads the memory is
+// retried after a revision conflict.
+const maxConflictRetries = 3
+
+// revisionConflict describes a failed revision check.
+func revisionConflict(memoryID string, expected, actual int64) error {
+	return &ctxerrors.Error{
+		Code:    ctxerrors.CodeConflict,
+		Message: fmt.Sprintf("memory %s: expected revision %d, found %d", memoryID, expected, actual),
+		Details: map[string]any{"id": memoryID, "expected_revision": expected, "actual_revision": actual},
+		Err:     ErrRevisionConflict,
+	}
+}
+
+type expectedRevisionKey struct{}
+
+// ContextWithExpectedRevision makes feedback, outcomes and visibility
+// changes made with ctx fail with ErrRevisionConflict unless the memory is
+// still at revision, for callers that decided on the update from a copy
+// they read earlier. Zero sets no expectation.
+func ContextWithExpectedRevision(ctx context.Context, revision int64) context.Context {
+	if revision == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, expectedRevisionKey{}, revision)
+}
+
+// checkExpectedRevision fails with a revision conflict if ctx expects memory
+// at another revision.
+func checkExpectedRevision(ctx context.Context, memory *Memory) error {
+	expected, ok := ctx.Value(expectedRevisionKey{}).(int64)
+	if !ok || expected == memory.Revision {
+		return nil
+	}
+	return revisionConflict(memory.ID, expected, memory.Revision)
+}
+
+// retryOnConflict runs a read-modify-write until it does not conflict with
+// a concurrent write. fn must re-read what it writes when attempt > 0.
+func retryOnConflict(fn func(attempt int) error) error {
+	var err error
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		if err = fn(attempt); !errors.Is(err, ErrRevisionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// memoryLocks serializes the revision check and write of each memory within
+// the process, so two updates cannot both pass the check. Writers in other
+// processes sharing the store are caught by the check alone.
+type memoryLocks struct {
+	mu    sync.Mutex
+	locks map[string]*memoryLock
+}
+
+type memoryLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks id and returns its unlock function.
+func (l *memoryLocks) lock(id string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*memoryLock)
+	}
+	ml, ok := l.locks[id]
+	if !ok {
+		ml = &memoryLock{}
+		l.locks[id] = ml
+	}
+	ml.refs++
+	l.mu.Unlock()
+
+	ml.Lock()
+	return func() {
+		ml.Unlock()
+		l.mu.Lock()
+		if ml.refs--; ml.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// commitMemory overwrites the stored memory with memory if the stored copy
+// is still at memory.Revision, and advances the revision. It fails with
+// ErrRevisionConflict otherwise, and restores the stored copy if the write
+// fails halfway.
+func (s *Service) commitMemory(ctx context.Context, store vectorstore.Store, collectionName string, memory *Memory) error {
+	ctx, err := s.withTenant(ctx, memory.ProjectID)
+	if err != nil {
+		return err
+	}
+	unlock := s.locks.lock(memory.ID)
+	defer unlock()
+
+	results, err := store.SearchInCollection(ctx, collectionName, "dummy", 1, map[string]interface{}{"id": memory.ID})
+	if err != nil {
+		return fmt.Errorf("reading stored memory: %w", err)
+	}
+	if len(results) == 0 {
+		return ErrMemoryNotFound
+	}
+	stored, err := s.resultToMemory(results[0])
+	if err != nil {
+		return fmt.Errorf("reading stored memory: %w", err)
+	}
+	if stored.Revision != memory.Revision {
+		return revisionConflict(memory.ID, memory.Revision, stored.Revision)
+	}
+
+	memory.Revision++
+	if err := store.DeleteDocumentsFromCollection(ctx, collectionName, []string{memory.ID}); err != nil {
+		memory.Revision--
+		return fmt.Errorf("deleting old memory: %w", err)
+	}
+	if _, err := store.AddDocuments(ctx, []vectorstore.Document{s.memoryToDocument(memory, collectionName)}); err != nil {
+		memory.Revision--
+		if _, rollbackErr := store.AddDocuments(ctx, []vectorstore.Document{s.memoryToDocument(stored, collectionName)}); rollbackErr != nil {
+			s.logger.Error("failed to rollback memory after update failure",
+				zap.String("id", memory.ID),
+				zap.Error(rollbackErr))
+		}
+		return fmt.Errorf("updating memory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+func TestService_Revisions(t *testing.T) {
+	ctx := context.Background()
+	projectID := "project-123"
+
+	newRecorded := func(t *testing.T) (*Service, *Memory) {
+		t.Helper()
+		svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+		memory, _ := NewMemory(projectID, "Retry flaky uploads", "Wrap uploads in exponential backoff", OutcomeSuccess, []string{"go"})
+		require.NoError(t, svc.Record(ctx, memory))
+		return svc, memory
+	}
+
+	t.Run("record starts at revision 1 and writes advance it", func(t *testing.T) {
+		svc, memory := newRecorded(t)
+
+		stored, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stored.Revision)
+
+		require.NoError(t, svc.Feedback(ctx, memory.ID, true))
+		stored, err = svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stored.Revision)
+	})
+
+	t.Run("stale write conflicts", func(t *testing.T) {
+		svc, memory := newRecorded(t)
+
+		first, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		second, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+
+		first.Title = "Retry flaky uploads with jitter"
+		require.NoError(t, svc.replaceMemory(ctx, projectID, first))
+
+		second.State = MemoryStateArchived
+		err = svc.replaceMemory(ctx, projectID, second)
+		require.ErrorIs(t, err, ErrRevisionConflict)
+		assert.Equal(t, ctxerrors.CodeConflict, ctxerrors.CodeOf(err))
+		assert.True(t, ctxerrors.Retryable(err))
+
+		var coded *ctxerrors.Error
+		require.True(t, errors.As(err, &coded))
+		assert.Equal(t, int64(1), coded.Details["expected_revision"])
+		assert.Equal(t, int64(2), coded.Details["actual_revision"])
+
+		// The first write survives
+		stored, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Retry flaky uploads with jitter", stored.Title)
+		assert.Equal(t, MemoryStateActive, stored.State)
+	})
+
+	t.Run("expected revision", func(t *testing.T) {
+		svc, memory := newRecorded(t)
+
+		stale := ContextWithExpectedRevision(ctx, 2)
+		err := svc.Feedback(stale, memory.ID, true)
+		require.ErrorIs(t, err, ErrRevisionConflict)
+		_, err = svc.RecordOutcome(stale, memory.ID, true, "")
+		require.ErrorIs(t, err, ErrRevisionConflict)
+
+		stored, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stored.Revision, "conflicting updates are not applied")
+
+		require.NoError(t, svc.Feedback(ContextWithExpectedRevision(ctx, 1), memory.ID, true))
+		stored, err = svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stored.Revision)
+	})
+
+	t.Run("concurrent feedback loses no updates", func(t *testing.T) {
+		svc, memory := newRecorded(t)
+
+		const n = 3
+		var wg sync.WaitGroup
+		errs := make([]error, n)
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = svc.Feedback(ctx, memory.ID, true)
+			}()
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		stored, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1+n), stored.Revision)
+		assert.Len(t, stored.ConfidenceHistory, 1+n) // recorded, plus each feedback
+	})
+}
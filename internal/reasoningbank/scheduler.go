@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
 )
 
 // ConsolidationScheduler manages automatic scheduled memory consolidation.
@@ -41,6 +43,9 @@ type ConsolidationScheduler struct {
 
 	// logger for structured logging
 	logger *zap.Logger
+
+	// readOnly, when enabled, makes scheduled runs skip consolidation
+	readOnly *readonly.Mode
 }
 
 // SchedulerOption configures a ConsolidationScheduler.
@@ -64,12 +69,20 @@ func WithProjectIDs(projectIDs []string) SchedulerOption {
 
 // WithConsolidationOptions sets the consolidation options.
 // If not set, uses default options (threshold: 0.8, dry_run: false).
+// Projects with a tuned threshold use it instead of opts.SimilarityThreshold.
 func WithConsolidationOptions(opts ConsolidationOptions) SchedulerOption {
 	return func(s *ConsolidationScheduler) {
 		s.opts = opts
 	}
 }
 
+// WithReadOnly makes the scheduler skip runs while read-only mode is on.
+func WithReadOnly(mode *readonly.Mode) SchedulerOption {
+	return func(s *ConsolidationScheduler) {
+		s.readOnly = mode
+	}
+}
+
 // NewConsolidationScheduler creates a new consolidation scheduler.
 //
 // The scheduler does not start automatically - call Start() to begin
@@ -97,7 +110,7 @@ func NewConsolidationScheduler(distiller *Distiller, logger *zap.Logger, opts ..
 		interval:   24 * time.Hour, // Default: daily consolidation
 		projectIDs: []string{},
 		opts: ConsolidationOptions{
-			SimilarityThreshold: 0.8, // Default threshold
+			SimilarityThreshold: DefaultSimilarityThreshold,
 			DryRun:              false,
 			ForceAll:            false,
 			MaxClustersPerRun:   0, // No limit
@@ -243,6 +256,11 @@ func (s *ConsolidationScheduler) runConsolidation() {
 		s.logger.Debug("no projects configured for consolidation, skipping")
 		return
 	}
+	if s.readOnly.Enabled() {
+		s.logger.Info("read-only mode enabled, skipping scheduled consolidation",
+			zap.String("reason", s.readOnly.Status().Reason))
+		return
+	}
 
 	s.logger.Info("starting scheduled consolidation",
 		zap.Int("project_count", len(s.projectIDs)),
@@ -254,8 +272,11 @@ func (s *ConsolidationScheduler) runConsolidation() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	// Run consolidation across all configured projects
-	result, err := s.distiller.ConsolidateAll(ctx, s.projectIDs, s.opts)
+	// Run consolidation across all configured projects, each with its tuned
+	// threshold when it has one
+	opts := s.opts
+	opts.UseProjectThreshold = true
+	result, err := s.distiller.ConsolidateAll(ctx, s.projectIDs, opts)
 	if err != nil {
 		s.logger.Error("consolidation failed",
 			zap.Error(err),
@@ -8,6 +8,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/readonly"
 )
 
 // schedulerTestEnv holds the test environment for scheduler tests.
@@ -333,6 +335,31 @@ func TestScheduler_NoProjectsConfigured(t *testing.T) {
 	assert.False(t, store.SearchCalled(), "expected no consolidation when no projects configured")
 }
 
+// TestScheduler_ReadOnlySkipsRuns tests that runs are skipped while read-only mode is on.
+func TestScheduler_ReadOnlySkipsRuns(t *testing.T) {
+	logger := zap.NewNop()
+	store := newMockStore()
+	distiller := &Distiller{
+		service: &Service{
+			store:         store,
+			logger:        logger,
+			defaultTenant: "test-tenant",
+		},
+		logger: logger,
+	}
+
+	scheduler, err := NewConsolidationScheduler(
+		distiller,
+		logger,
+		WithProjectIDs([]string{"project"}),
+		WithReadOnly(readonly.New(true, "migration")),
+	)
+	require.NoError(t, err)
+
+	scheduler.runConsolidation()
+	assert.False(t, store.SearchCalled(), "expected no consolidation in read-only mode")
+}
+
 // TestScheduler_WithConsolidationOptions tests that custom consolidation options are used.
 func TestScheduler_WithConsolidationOptions(t *testing.T) {
 	logger := zap.NewNop()
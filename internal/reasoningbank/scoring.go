@@ -0,0 +1,318 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fyrsmithlabs/contextd/internal/validation"
+)
+
+// DefaultRecencyHalfLife is how long a memory takes to lose half its
+// recency score when ScoringConfig.RecencyHalfLife is unset.
+const DefaultRecencyHalfLife = 30 * 24 * time.Hour
+
+// ScoringConfig weighs the signals that rank memory search results.
+//
+// A result's base score is
+//
+//	SimilarityWeight*similarity + ConfidenceWeight*confidence + RecencyWeight*recency
+//
+// where recency is 1 for a memory created now and halves every
+// RecencyHalfLife. The base score is then multiplied by the boosts:
+// ConsolidationBoost for consolidated memories, the CategoryBoosts entry of
+// the memory's category, and the built-in entity, temporal, importance and
+// language boosts.
+//
+// The zero value is the default ranking: similarity alone, with the
+// built-in boosts. All weights zero means similarity alone, a zero
+// RecencyHalfLife is DefaultRecencyHalfLife and a zero ConsolidationBoost
+// is 1.2.
+type ScoringConfig struct {
+	SimilarityWeight float64 `json:"similarity_weight"`
+	ConfidenceWeight float64 `json:"confidence_weight"`
+	RecencyWeight    float64 `json:"recency_weight"`
+
+	RecencyHalfLife time.Duration `json:"recency_half_life"`
+
+	// ConsolidationBoost multiplies the score of consolidated memories.
+	// A search experiment arm that sets its own boost overrides it.
+	ConsolidationBoost float64 `json:"consolidation_boost"`
+
+	// CategoryBoosts multiplies the score of memories in a category, as
+	// assigned by the regex category classifier. Values below 1 sink them.
+	CategoryBoosts map[MemoryCategory]float64 `json:"category_boosts,omitempty"`
+}
+
+// Validate checks the weights and boosts.
+func (c *ScoringConfig) Validate() error {
+	if c.SimilarityWeight < 0 || c.ConfidenceWeight < 0 || c.RecencyWeight < 0 {
+		return fmt.Errorf("scoring weights must not be negative")
+	}
+	if c.RecencyHalfLife < 0 {
+		return fmt.Errorf("recency half-life must not be negative, got %s", c.RecencyHalfLife)
+	}
+	if c.ConsolidationBoost < 0 {
+		return fmt.Errorf("consolidation boost must not be negative, got %g", c.ConsolidationBoost)
+	}
+	for category, boost := range c.CategoryBoosts {
+		if !IsValidCategory(string(category)) {
+			return fmt.Errorf("unknown memory category %q in category boosts", category)
+		}
+		if boost <= 0 {
+			return fmt.Errorf("category %s boost must be positive, got %g", category, boost)
+		}
+	}
+	return nil
+}
+
+// weights returns the similarity, confidence and recency weights, with
+// all zero meaning similarity alone.
+func (c *ScoringConfig) weights() (similarity, confidence, recency float64) {
+	if c.SimilarityWeight == 0 && c.ConfidenceWeight == 0 && c.RecencyWeight == 0 {
+		return 1, 0, 0
+	}
+	return c.SimilarityWeight, c.ConfidenceWeight, c.RecencyWeight
+}
+
+// halfLife returns the recency half-life, or the default.
+func (c *ScoringConfig) halfLife() time.Duration {
+	if c.RecencyHalfLife == 0 {
+		return DefaultRecencyHalfLife
+	}
+	return c.RecencyHalfLife
+}
+
+// consolidationBoost returns the consolidated-memory multiplier, or the
+// default.
+func (c *ScoringConfig) consolidationBoost() float32 {
+	if c.ConsolidationBoost == 0 {
+		return consolidatedMemoryBoost
+	}
+	return float32(c.ConsolidationBoost)
+}
+
+// Effective returns c with the defaults of unset fields filled in.
+func (c ScoringConfig) Effective() ScoringConfig {
+	c.SimilarityWeight, c.ConfidenceWeight, c.RecencyWeight = c.weights()
+	c.RecencyHalfLife = c.halfLife()
+	c.ConsolidationBoost = float64(c.consolidationBoost())
+	return c
+}
+
+// WithScoring ranks memory searches with cfg instead of the default
+// scoring.
+func WithScoring(cfg ScoringConfig) ServiceOption {
+	return func(s *Service) {
+		if err := cfg.Validate(); err != nil {
+			s.initErr = fmt.Errorf("invalid scoring config: %w", err)
+			return
+		}
+		s.scoring = cfg
+		if len(cfg.CategoryBoosts) > 0 {
+			s.categorizer = NewRegexCategoryClassifier()
+		}
+	}
+}
+
+// Scoring returns the scoring configuration searches use, with defaults
+// filled in.
+func (s *Service) Scoring() ScoringConfig {
+	return s.scoring.Effective()
+}
+
+// recencyScore is 1 for a memory created now, halving every halfLife.
+// Memories without a creation time score 0.
+func recencyScore(createdAt time.Time, halfLife time.Duration, now time.Time) float64 {
+	if createdAt.IsZero() {
+		return 0
+	}
+	age := now.Sub(createdAt)
+	if age <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// ScoreBoost is one multiplier applied to a memory's base score.
+type ScoreBoost struct {
+	Name   string  `json:"name"`
+	Factor float64 `json:"factor"`
+}
+
+// ScoreExplanation breaks down how a memory scores for a query under the
+// active scoring configuration.
+type ScoreExplanation struct {
+	MemoryID string `json:"memory_id"`
+	Query    string `json:"query"`
+
+	// Similarity, Confidence and Recency are the signals weighed into
+	// BaseScore.
+	Similarity float64 `json:"similarity"`
+	Confidence float64 `json:"confidence"`
+	Recency    float64 `json:"recency"`
+	BaseScore  float64 `json:"base_score"`
+
+	// Category is the memory's category when category boosts are set.
+	Category MemoryCategory `json:"category,omitempty"`
+
+	// Boosts are the multipliers applied to BaseScore, in order.
+	Boosts []ScoreBoost `json:"boosts,omitempty"`
+
+	// Score is the score searches rank the memory by, before reranking.
+	Score float64 `json:"score"`
+
+	// Excluded says why searches do not return the memory at all, such as
+	// low confidence or an archived state. Empty when they do.
+	Excluded string `json:"excluded,omitempty"`
+
+	// Arm is the search experiment arm whose ranking applied, if any.
+	Arm Arm `json:"arm,omitempty"`
+
+	// Scoring is the configuration used, with defaults filled in.
+	Scoring ScoringConfig `json:"scoring"`
+}
+
+// ExplainScore scores one memory against query the way Search would, and
+// returns the breakdown. A session set with ContextWithSession applies its
+// experiment arm's ranking. Similarity is that of the whole memory; Search
+// may also match a long memory through one of its chunks.
+func (s *Service) ExplainScore(ctx context.Context, projectID, query, memoryID string) (*ScoreExplanation, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	if query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if err := validation.Memory.Query("query", query); err != nil {
+		return nil, err
+	}
+	// Validate UUID format to prevent filter injection
+	if _, err := uuid.Parse(memoryID); err != nil {
+		return nil, fmt.Errorf("invalid memory ID format: must be a valid UUID")
+	}
+
+	ctx, err := s.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	store, collectionName, err := s.getStore(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("checking collection existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrMemoryNotFound
+	}
+
+	// Filtering by ID scores the query against just this memory
+	results, err := store.SearchInCollection(ctx, collectionName, query, 1, map[string]interface{}{"id": memoryID})
+	if err != nil {
+		return nil, fmt.Errorf("searching for memory: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, ErrMemoryNotFound
+	}
+	memory, err := s.resultToMemory(results[0])
+	if err != nil {
+		return nil, fmt.Errorf("converting result to memory: %w", err)
+	}
+	memory = s.writes.overlay(memory)
+
+	q := s.querySignals(ctx, query)
+	explanation := s.scoreMemory(memory, results[0].Score, q, true)
+	explanation.MemoryID = memory.ID
+	explanation.Query = query
+	explanation.Arm = q.arm
+	explanation.Scoring = s.Scoring()
+
+	switch {
+	case memory.State != MemoryStateActive:
+		explanation.Excluded = fmt.Sprintf("memory is %s", memory.State)
+	case memory.Confidence < MinConfidence:
+		explanation.Excluded = fmt.Sprintf("confidence %.2f is below the search minimum %.2f", memory.Confidence, MinConfidence)
+	case !q.filter.Match(results[0].Metadata):
+		explanation.Excluded = "memory does not match the filter"
+	case q.languageScoped && len(memory.Languages) > 0 && !languagesOverlap(memory.Languages, q.languages):
+		explanation.Excluded = "memory is about other languages than the language scope"
+	}
+	return explanation, nil
+}
+
+// scoreMemory computes a memory's search score from its similarity to the
+// query. The boosts applied are listed only when explain is set.
+func (s *Service) scoreMemory(memory *Memory, similarity float32, q querySignals, explain bool) *ScoreExplanation {
+	out := &ScoreExplanation{Similarity: float64(similarity), Confidence: memory.Confidence}
+
+	wSim, wConf, wRec := s.scoring.weights()
+	score := float32(wSim) * similarity
+	if wConf > 0 {
+		score += float32(wConf * memory.Confidence)
+	}
+	if wRec > 0 || explain {
+		out.Recency = recencyScore(memory.CreatedAt, s.scoring.halfLife(), time.Now())
+		score += float32(wRec * out.Recency)
+	}
+	out.BaseScore = float64(score)
+
+	boost := func(name string, factor float32) {
+		score *= factor
+		if explain {
+			out.Boosts = append(out.Boosts, ScoreBoost{Name: name, Factor: float64(factor)})
+		}
+	}
+
+	// Boost consolidated memories (synthesized from multiple sources)
+	isConsolidated := memory.ConsolidationID == nil && memory.State == MemoryStateActive &&
+		(strings.Contains(memory.Description, consolidatedPrefixSynthesized) ||
+			strings.Contains(memory.Description, consolidatedPrefixConsolidated))
+	if isConsolidated {
+		boost("consolidated", q.ranking.consolidationBoost(s.scoring.consolidationBoost()))
+	}
+
+	// Boost or sink categories the scoring config names
+	if s.categorizer != nil {
+		category, _ := s.categorizer.Classify(memory.Title, memory.Content, memory.Tags)
+		out.Category = category
+		if factor, ok := s.scoring.CategoryBoosts[category]; ok {
+			boost("category:"+string(category), float32(factor))
+		}
+	}
+
+	// Boost memories mentioning entities from the query
+	if len(q.entities) > 0 && s.memoryContainsEntity(memory, q.entities) {
+		boost("entity", q.ranking.entityBoost())
+	}
+
+	// Apply temporal weighting for time-sensitive queries
+	if q.temporal {
+		if multiplier := s.getTemporalMultiplier(memory); multiplier != 1.0 {
+			boost("temporal", multiplier)
+		}
+	}
+
+	// Nudge important memories ahead of equally relevant ones
+	if memory.Importance > 0 {
+		boost("importance", 1+float32(importanceBoostFactor*memory.Importance))
+	}
+
+	// Prefer memories in the query's languages; sink memories that are
+	// only about other languages (same project, different stack)
+	if len(q.languages) > 0 && len(memory.Languages) > 0 {
+		if languagesOverlap(memory.Languages, q.languages) {
+			boost("language_match", languageMatchBoost)
+		} else if !q.languageScoped {
+			boost("language_mismatch", languageMismatchPenalty)
+		}
+	}
+
+	out.Score = float64(score)
+	return out
+}
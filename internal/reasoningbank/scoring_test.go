@@ -0,0 +1,140 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestScoringConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ScoringConfig
+		wantErr string
+	}{
+		{name: "zero value", cfg: ScoringConfig{}},
+		{name: "weights and boosts", cfg: ScoringConfig{
+			SimilarityWeight: 0.7, ConfidenceWeight: 0.2, RecencyWeight: 0.1,
+			RecencyHalfLife: 14 * 24 * time.Hour, ConsolidationBoost: 1,
+			CategoryBoosts: map[MemoryCategory]float64{CategorySecurity: 1.5, CategoryGeneral: 0.8},
+		}},
+		{name: "negative weight", cfg: ScoringConfig{ConfidenceWeight: -0.1}, wantErr: "weights must not be negative"},
+		{name: "negative half-life", cfg: ScoringConfig{RecencyHalfLife: -time.Hour}, wantErr: "half-life"},
+		{name: "negative consolidation boost", cfg: ScoringConfig{ConsolidationBoost: -1}, wantErr: "consolidation boost"},
+		{name: "unknown category", cfg: ScoringConfig{CategoryBoosts: map[MemoryCategory]float64{"frontend": 2}}, wantErr: "unknown memory category"},
+		{name: "zero category boost", cfg: ScoringConfig{CategoryBoosts: map[MemoryCategory]float64{CategoryDebugging: 0}}, wantErr: "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := NewService(&mockStore{}, zap.NewNop(), WithScoring(ScoringConfig{RecencyWeight: -1}))
+	assert.ErrorContains(t, err, "invalid scoring config")
+}
+
+func TestScoringConfig_Effective(t *testing.T) {
+	assert.Equal(t, ScoringConfig{
+		SimilarityWeight:   1,
+		RecencyHalfLife:    DefaultRecencyHalfLife,
+		ConsolidationBoost: float64(consolidatedMemoryBoost),
+	}, ScoringConfig{}.Effective())
+
+	cfg := ScoringConfig{ConfidenceWeight: 0.5, RecencyHalfLife: time.Hour, ConsolidationBoost: 2}
+	assert.Equal(t, cfg, cfg.Effective(), "set weights are kept, including a zero similarity weight")
+}
+
+func TestApplyScoreBoosting_Scoring(t *testing.T) {
+	now := time.Now()
+	memory := &Memory{
+		Title:      "Retry policy",
+		Content:    "Retry with exponential backoff",
+		Confidence: 0.5,
+		State:      MemoryStateActive,
+		CreatedAt:  now,
+	}
+
+	t.Run("default is similarity alone", func(t *testing.T) {
+		svc := &Service{}
+		assert.InDelta(t, 0.8, svc.applyScoreBoosting(memory, 0.8, querySignals{}), 1e-6)
+	})
+
+	t.Run("weighs confidence and recency", func(t *testing.T) {
+		svc := &Service{scoring: ScoringConfig{SimilarityWeight: 0.5, ConfidenceWeight: 0.3, RecencyWeight: 0.2}}
+		assert.InDelta(t, 0.5*0.8+0.3*0.5+0.2*1, svc.applyScoreBoosting(memory, 0.8, querySignals{}), 1e-3)
+
+		old := *memory
+		old.CreatedAt = now.Add(-DefaultRecencyHalfLife)
+		assert.InDelta(t, 0.5*0.8+0.3*0.5+0.2*0.5, svc.applyScoreBoosting(&old, 0.8, querySignals{}), 1e-3,
+			"recency halves every half-life")
+	})
+
+	t.Run("boosts categories", func(t *testing.T) {
+		category, _ := NewRegexCategoryClassifier().Classify(memory.Title, memory.Content, memory.Tags)
+		svc, err := NewService(&mockStore{}, zap.NewNop(), WithScoring(ScoringConfig{
+			CategoryBoosts: map[MemoryCategory]float64{category: 2},
+		}))
+		require.NoError(t, err)
+		assert.InDelta(t, 1.6, svc.applyScoreBoosting(memory, 0.8, querySignals{}), 1e-6)
+	})
+
+	t.Run("consolidation boost yields to experiment arms", func(t *testing.T) {
+		consolidated := *memory
+		consolidated.Description = consolidatedPrefixConsolidated + " 2 memories"
+		svc := &Service{scoring: ScoringConfig{ConsolidationBoost: 1.5}}
+		assert.InDelta(t, 1.5, svc.applyScoreBoosting(&consolidated, 1, querySignals{}), 1e-6)
+		assert.InDelta(t, 3, svc.applyScoreBoosting(&consolidated, 1, querySignals{ranking: &RankingConfig{ConsolidationBoost: 3}}), 1e-6)
+	})
+}
+
+func TestService_ExplainScore(t *testing.T) {
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "acme", ProjectID: "app"})
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 3,
+	}, &keywordEmbedder{keywords: []string{"retry", "cache"}}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	scoring := ScoringConfig{SimilarityWeight: 0.8, ConfidenceWeight: 0.2}
+	svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("acme"), WithScoring(scoring))
+	require.NoError(t, err)
+
+	memory, err := NewMemory("app", "Retry", "retry with backoff", OutcomeSuccess, nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.Record(ctx, memory))
+
+	explanation, err := svc.ExplainScore(ctx, "app", "retry the request", memory.ID)
+	require.NoError(t, err)
+	assert.Equal(t, memory.ID, explanation.MemoryID)
+	assert.Greater(t, explanation.Similarity, 0.9)
+	assert.InDelta(t, memory.Confidence, explanation.Confidence, 1e-9)
+	assert.InDelta(t, 1, explanation.Recency, 1e-3)
+	assert.InDelta(t, 0.8*explanation.Similarity+0.2*explanation.Confidence, explanation.BaseScore, 1e-3)
+	assert.Empty(t, explanation.Excluded)
+	assert.Equal(t, scoring.Effective(), explanation.Scoring)
+
+	// Less similar queries score lower
+	unrelated, err := svc.ExplainScore(ctx, "app", "cache warmup", memory.ID)
+	require.NoError(t, err)
+	assert.Less(t, unrelated.Score, explanation.Score)
+
+	_, err = svc.ExplainScore(ctx, "app", "retry", "00000000-0000-0000-0000-000000000000")
+	assert.ErrorIs(t, err, ErrMemoryNotFound)
+	_, err = svc.ExplainScore(ctx, "app", "retry", "not-a-uuid")
+	assert.ErrorContains(t, err, "invalid memory ID")
+	_, err = svc.ExplainScore(ctx, "app", "", memory.ID)
+	assert.Error(t, err)
+}
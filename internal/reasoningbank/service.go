@@ -2,6 +2,8 @@ package reasoningbank
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
@@ -10,8 +12,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fyrsmithlabs/contextd/internal/injection"
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
 	"github.com/fyrsmithlabs/contextd/internal/project"
 	"github.com/fyrsmithlabs/contextd/internal/reranker"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
@@ -44,6 +49,14 @@ const (
 	// DefaultSearchLimit is the default maximum number of search results.
 	DefaultSearchLimit = 10
 
+	// DefaultStreamBatchSize is the number of memories read per batch by
+	// StreamMemories.
+	DefaultStreamBatchSize = 500
+
+	// maxListFetch caps the single read used to list memories from stores
+	// that cannot scroll.
+	maxListFetch = 10000
+
 	// entityBoostFactor multiplies relevance score when a memory mentions
 	// named entities extracted from the query. This improves precision for
 	// questions like "What is Caroline's identity?" by prioritizing memories
@@ -121,6 +134,12 @@ type Service struct {
 	defaultTenant string                    // Default tenant for StoreProvider (usually git username)
 	embedder      vectorstore.Embedder      // For re-embedding content to retrieve vectors
 	reranker      reranker.Reranker         // Optional reranker for improving search quality
+	experiment    *Experiment               // Optional ranking experiment (A/B test)
+	scoring       ScoringConfig             // Search score weights and boosts (zero: default)
+	categorizer   CategoryClassifier        // Non-nil when scoring has category boosts
+	decomposer    QueryDecomposer           // Splits tasks for multi-query search (default: heuristic)
+	qualityGate   *QualityGate              // Optional checks for agent-recorded memories
+	scanner       *injection.Scanner        // Quarantines likely prompt injections (nil disables)
 	signalStore   SignalStore
 	confCalc      *ConfidenceCalculator
 	logger        *zap.Logger
@@ -130,9 +149,12 @@ type Service struct {
 	totalGauge          metric.Int64ObservableGauge
 	searchCounter       metric.Int64Counter
 	recordCounter       metric.Int64Counter
+	duplicateCounter    metric.Int64Counter
 	feedbackCounter     metric.Int64Counter
 	outcomeCounter      metric.Int64Counter
 	errorCounter        metric.Int64Counter
+	pruneCounter        metric.Int64Counter
+	thresholdCounter    metric.Int64Counter
 	searchDuration      metric.Float64Histogram
 	confidenceHistogram metric.Float64Histogram
 
@@ -141,6 +163,13 @@ type Service struct {
 	bufferMgr   *SessionBufferManager // Non-nil when granularity=session
 	summarizer  *SessionSummarizer    // Non-nil when granularity=session
 
+	experimentTracker *experimentTracker // Non-nil when experiment is set
+	writes            *writeBuffer       // Non-nil with WithWriteBatching
+	duplicates        *duplicateGuard    // Non-nil with WithDuplicateGuard
+	onDuplicateBurst  DuplicateListener  // Optional; called once per burst of duplicates
+	onRecorded        RecordListener     // Optional; called for each stored memory
+	locks             memoryLocks        // Serializes revision-checked writes
+
 	// Stats tracking for statusline
 	statsMu        sync.RWMutex
 	lastConfidence float64
@@ -211,6 +240,14 @@ func WithSessionGranularity(extractor FactExtractor, logger *zap.Logger, maxBuff
 	}
 }
 
+// WithInjectionScanner replaces the scanner that quarantines recorded
+// memories that look like prompt injections. nil disables scanning.
+func WithInjectionScanner(scanner *injection.Scanner) ServiceOption {
+	return func(s *Service) {
+		s.scanner = scanner
+	}
+}
+
 // NewService creates a new ReasoningBank service.
 func NewService(store vectorstore.Store, logger *zap.Logger, opts ...ServiceOption) (*Service, error) {
 	if store == nil {
@@ -221,9 +258,10 @@ func NewService(store vectorstore.Store, logger *zap.Logger, opts ...ServiceOpti
 	}
 
 	svc := &Service{
-		store:  store,
-		logger: logger,
-		meter:  otel.Meter(instrumentationName),
+		store:   store,
+		logger:  logger,
+		meter:   otel.Meter(instrumentationName),
+		scanner: injection.NewScanner(),
 	}
 
 	// Apply options
@@ -246,6 +284,9 @@ func NewService(store vectorstore.Store, logger *zap.Logger, opts ...ServiceOpti
 
 	// Initialize metrics
 	svc.initMetrics()
+	if svc.experiment != nil {
+		svc.experimentTracker = newExperimentTracker(svc.meter, logger)
+	}
 
 	return svc, nil
 }
@@ -296,6 +337,9 @@ func NewServiceWithStoreProvider(stores vectorstore.StoreProvider, defaultTenant
 
 	// Initialize metrics
 	svc.initMetrics()
+	if svc.experiment != nil {
+		svc.experimentTracker = newExperimentTracker(svc.meter, logger)
+	}
 
 	return svc, nil
 }
@@ -304,6 +348,12 @@ func NewServiceWithStoreProvider(stores vectorstore.StoreProvider, defaultTenant
 // If StoreProvider is configured, it uses database-per-project isolation.
 // Otherwise, it falls back to the legacy single-store approach.
 func (s *Service) getStore(ctx context.Context, projectID string) (vectorstore.Store, string, error) {
+	return s.getCollectionStore(ctx, projectID, project.CollectionMemories)
+}
+
+// getCollectionStore returns the store and collection name for a collection
+// type within a project.
+func (s *Service) getCollectionStore(ctx context.Context, projectID string, collectionType project.CollectionType) (vectorstore.Store, string, error) {
 	if s.stores != nil {
 		// Use StoreProvider for database-per-project isolation
 		// Team is empty for direct project path (tenant/project)
@@ -312,20 +362,35 @@ func (s *Service) getStore(ctx context.Context, projectID string) (vectorstore.S
 			return nil, "", fmt.Errorf("getting project store: %w", err)
 		}
 		// With StoreProvider, we use simple collection names (no prefix)
-		return store, collectionMemories, nil
+		return store, string(collectionType), nil
 	}
 
 	// Legacy: single store with prefixed collection names
 	if s.store == nil {
 		return nil, "", fmt.Errorf("no store configured")
 	}
-	collectionName, err := project.GetCollectionName(projectID, project.CollectionMemories)
+	collectionName, err := project.GetCollectionName(projectID, collectionType)
 	if err != nil {
 		return nil, "", fmt.Errorf("getting collection name: %w", err)
 	}
 	return s.store, collectionName, nil
 }
 
+// withTenant returns ctx unchanged if the caller set a tenant, otherwise a
+// context scoped to the default tenant and projectID.
+func (s *Service) withTenant(ctx context.Context, projectID string) (context.Context, error) {
+	if _, err := vectorstore.TenantFromContext(ctx); err == nil {
+		return ctx, nil
+	}
+	if s.defaultTenant == "" {
+		return nil, fmt.Errorf("tenant ID not configured for reasoningbank service")
+	}
+	return vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  s.defaultTenant,
+		ProjectID: projectID,
+	}), nil
+}
+
 // initMetrics initializes OpenTelemetry metrics.
 func (s *Service) initMetrics() {
 	var err error
@@ -364,6 +429,15 @@ func (s *Service) initMetrics() {
 		s.logger.Warn("failed to create record counter", zap.Error(err))
 	}
 
+	s.duplicateCounter, err = s.meter.Int64Counter(
+		"contextd.memory.duplicates_suppressed_total",
+		metric.WithDescription("Total number of duplicate memories suppressed on record"),
+		metric.WithUnit("{record}"),
+	)
+	if err != nil {
+		s.logger.Warn("failed to create duplicate counter", zap.Error(err))
+	}
+
 	s.feedbackCounter, err = s.meter.Int64Counter(
 		"contextd.memory.feedbacks_total",
 		metric.WithDescription("Total number of feedback events"),
@@ -391,6 +465,24 @@ func (s *Service) initMetrics() {
 		s.logger.Warn("failed to create error counter", zap.Error(err))
 	}
 
+	s.pruneCounter, err = s.meter.Int64Counter(
+		"contextd.memory.pruned_total",
+		metric.WithDescription("Total number of memories pruned by policy rule"),
+		metric.WithUnit("{memory}"),
+	)
+	if err != nil {
+		s.logger.Warn("failed to create prune counter", zap.Error(err))
+	}
+
+	s.thresholdCounter, err = s.meter.Int64Counter(
+		"contextd.memory.confidence_threshold_crossings_total",
+		metric.WithDescription("Total number of memories crossing the search confidence threshold by direction and cause"),
+		metric.WithUnit("{memory}"),
+	)
+	if err != nil {
+		s.logger.Warn("failed to create confidence threshold counter", zap.Error(err))
+	}
+
 	s.searchDuration, err = s.meter.Float64Histogram(
 		"contextd.memory.search_duration_seconds",
 		metric.WithDescription("Duration of memory search operations"),
@@ -477,6 +569,9 @@ func (s *Service) Search(ctx context.Context, projectID, query string, limit int
 	if query == "" {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
+	if err := validation.Memory.Query("query", query); err != nil {
+		return nil, err
+	}
 	if limit <= 0 {
 		limit = DefaultSearchLimit
 	}
@@ -520,8 +615,10 @@ func (s *Service) Search(ctx context.Context, projectID, query string, limit int
 	if searchLimit < 30 {
 		searchLimit = 30
 	}
+	// Cap the over-fetch, but never below limit: SearchPage asks for
+	// offset+limit+1 and deep pages must not be truncated at the cap
 	if searchLimit > 200 {
-		searchLimit = 200
+		searchLimit = max(200, limit)
 	}
 
 	results, err := store.SearchInCollection(ctx, collectionName, query, searchLimit, nil)
@@ -529,23 +626,32 @@ func (s *Service) Search(ctx context.Context, projectID, query string, limit int
 		s.recordError(ctx, "search", "search_failed")
 		return nil, fmt.Errorf("searching memories: %w", err)
 	}
+	results, _ = s.mergeChunkMatches(ctx, store, collectionName, projectID, query, searchLimit, results)
 
 	// Score, filter, and boost results
-	queryEntities := s.extractQueryEntities(query)
-	isTemporalQuery := s.isTemporalQuery(query)
-	scoredMemories := s.scoreAndFilterResults(ctx, results, projectID, queryEntities, isTemporalQuery)
+	q := s.querySignals(ctx, query)
+	scoredMemories := s.scoreAndFilterResults(ctx, results, projectID, q)
 
-	// Sort by boosted scores then apply reranking
+	// Sort by boosted scores then apply reranking; experiment arms decide
+	// whether to rerank themselves
 	sort.Slice(scoredMemories, func(i, j int) bool {
 		return scoredMemories[i].score > scoredMemories[j].score
 	})
-	scoredMemories = s.applyReranking(ctx, query, projectID, scoredMemories)
+	switch {
+	case q.ranking == nil:
+		scoredMemories = s.applyReranking(ctx, query, projectID, scoredMemories)
+	case q.ranking.Rerank:
+		scoredMemories = s.rerankWith(ctx, s.experiment.Reranker, query, projectID, scoredMemories)
+	}
 
 	// Extract memories up to limit
 	memories := make([]Memory, 0, limit)
 	for i := 0; i < len(scoredMemories) && i < limit; i++ {
 		memories = append(memories, scoredMemories[i].memory)
 	}
+	if q.ranking != nil {
+		s.recordExposure(ctx, projectID, q.arm, memoryIDs(memories))
+	}
 
 	s.recordSearchMetrics(ctx, projectID, startTime, memories)
 
@@ -558,14 +664,47 @@ func (s *Service) Search(ctx context.Context, projectID, query string, limit int
 	return memories, nil
 }
 
+// querySignals holds what a query says about the memories it wants,
+// used to boost and filter results.
+type querySignals struct {
+	entities []string
+	temporal bool
+
+	// languages are the languages the query is about; languageScoped is
+	// set when they come from ContextWithLanguages and filter results
+	// rather than only boosting them.
+	languages      []string
+	languageScoped bool
+
+	// filter is the caller's filter expression from ContextWithFilter
+	filter *vectorstore.FilterExpr
+
+	// arm and ranking are set when an experiment serves the search;
+	// a nil ranking is the default ranking.
+	arm     Arm
+	ranking *RankingConfig
+}
+
+// querySignals extracts entities, temporal intent and languages from a query.
+func (s *Service) querySignals(ctx context.Context, query string) querySignals {
+	q := querySignals{
+		entities: s.extractQueryEntities(query),
+		temporal: s.isTemporalQuery(query),
+	}
+	q.languages, q.languageScoped = queryLanguages(ctx, query)
+	q.filter = FilterFromContext(ctx)
+	q.arm, q.ranking, _ = s.experimentArm(ctx)
+	return q
+}
+
 // scoreAndFilterResults converts raw search results to scored memories,
-// applying confidence filtering, deduplication, and relevance boosting.
+// applying filter expression, confidence and language filtering,
+// deduplication, and relevance boosting.
 func (s *Service) scoreAndFilterResults(
 	ctx context.Context,
 	results []vectorstore.SearchResult,
 	projectID string,
-	queryEntities []string,
-	isTemporalQuery bool,
+	q querySignals,
 ) []scoredMemory {
 	scored := make([]scoredMemory, 0, len(results))
 	seenIDs := make(map[string]struct{}, len(results))
@@ -577,6 +716,10 @@ func (s *Service) scoreAndFilterResults(
 		}
 		seenIDs[result.ID] = struct{}{}
 
+		if !q.filter.Match(result.Metadata) {
+			continue
+		}
+
 		memory, err := s.resultToMemory(result)
 		if err != nil {
 			s.logger.Warn("skipping invalid memory",
@@ -584,60 +727,84 @@ func (s *Service) scoreAndFilterResults(
 				zap.Error(err))
 			continue
 		}
+		memory = s.writes.overlay(memory)
 
-		if memory.Confidence < MinConfidence || memory.State == MemoryStateArchived {
+		if memory.Confidence < MinConfidence || memory.State != MemoryStateActive {
 			continue
 		}
 
-		score := s.applyScoreBoosting(memory, result.Score, queryEntities, isTemporalQuery)
+		// A language scope excludes memories about other languages only;
+		// memories without languages apply everywhere
+		if q.languageScoped && len(memory.Languages) > 0 && !languagesOverlap(memory.Languages, q.languages) {
+			continue
+		}
 
-		// Record usage signal for this memory
+		// Record usage signal for this memory and rank it with the
+		// importance that usage earns
 		signal, sigErr := NewSignal(memory.ID, projectID, SignalUsage, true, "")
 		if sigErr == nil {
 			if storeErr := s.signalStore.StoreSignal(ctx, signal); storeErr != nil {
 				s.logger.Warn("failed to record usage signal",
 					zap.String("memory_id", memory.ID),
 					zap.Error(storeErr))
+			} else {
+				s.raiseImportance(ctx, memory)
 			}
 		}
 
+		score := s.applyScoreBoosting(memory, result.Score, q)
+
 		scored = append(scored, scoredMemory{memory: *memory, score: score})
 	}
 
 	return scored
 }
 
-// applyScoreBoosting applies consolidation, entity, and temporal boosts to a memory's score.
-func (s *Service) applyScoreBoosting(memory *Memory, baseScore float32, queryEntities []string, isTemporalQuery bool) float32 {
-	score := baseScore
-
-	// Boost consolidated memories (synthesized from multiple sources)
-	isConsolidated := memory.ConsolidationID == nil && memory.State == MemoryStateActive &&
-		(strings.Contains(memory.Description, consolidatedPrefixSynthesized) ||
-			strings.Contains(memory.Description, consolidatedPrefixConsolidated))
-	if isConsolidated {
-		score *= consolidatedMemoryBoost
-	}
+// applyScoreBoosting weighs a memory's similarity, confidence and recency
+// into a base score and applies the consolidation, category, entity,
+// temporal, importance and language boosts (see ScoringConfig).
+func (s *Service) applyScoreBoosting(memory *Memory, similarity float32, q querySignals) float32 {
+	return float32(s.scoreMemory(memory, similarity, q, false).Score)
+}
 
-	// Boost memories mentioning entities from the query
-	if len(queryEntities) > 0 && s.memoryContainsEntity(memory, queryEntities) {
-		score *= entityBoostFactor
+// updateImportance recomputes memory.Importance from its signals. Failures
+// leave the previous value in place; importance is only a ranking hint.
+func (s *Service) updateImportance(ctx context.Context, memory *Memory) {
+	importance, err := s.confCalc.ComputeImportance(ctx, memory.ID)
+	if err != nil {
+		s.logger.Warn("failed to compute importance",
+			zap.String("memory_id", memory.ID),
+			zap.Error(err))
+		return
 	}
+	memory.Importance = importance
+}
 
-	// Apply temporal weighting for time-sensitive queries
-	if isTemporalQuery {
-		if multiplier := s.getTemporalMultiplier(memory); multiplier != 1.0 {
-			score *= multiplier
-		}
+// raiseImportance recomputes memory.Importance after a usage signal. Usage
+// only adds reach, so the stored value is kept when it is higher, such as
+// when older signals were lost on restart. The raised value is used for
+// ranking and persisted with the next feedback or outcome update.
+func (s *Service) raiseImportance(ctx context.Context, memory *Memory) {
+	importance, err := s.confCalc.ComputeImportance(ctx, memory.ID)
+	if err != nil {
+		s.logger.Warn("failed to compute importance",
+			zap.String("memory_id", memory.ID),
+			zap.Error(err))
+		return
 	}
-
-	return score
+	memory.Importance = max(memory.Importance, importance)
 }
 
 // applyReranking uses the configured reranker to improve result ordering.
 // Falls back to the original order if reranking fails or no reranker is configured.
 func (s *Service) applyReranking(ctx context.Context, query, projectID string, scoredMemories []scoredMemory) []scoredMemory {
-	if s.reranker == nil || len(scoredMemories) == 0 {
+	return s.rerankWith(ctx, s.reranker, query, projectID, scoredMemories)
+}
+
+// rerankWith reorders scoredMemories with r, keeping the order when r is
+// nil or fails.
+func (s *Service) rerankWith(ctx context.Context, r reranker.Reranker, query, projectID string, scoredMemories []scoredMemory) []scoredMemory {
+	if r == nil || len(scoredMemories) == 0 {
 		return scoredMemories
 	}
 
@@ -650,7 +817,7 @@ func (s *Service) applyReranking(ctx context.Context, query, projectID string, s
 		}
 	}
 
-	rerankedDocs, err := s.reranker.Rerank(ctx, query, docs, len(docs))
+	rerankedDocs, err := r.Rerank(ctx, query, docs, len(docs))
 	if err != nil {
 		s.logger.Warn("reranking failed, using original ranking",
 			zap.String("project_id", projectID),
@@ -713,6 +880,14 @@ func (s *Service) recordSearchMetrics(ctx context.Context, projectID string, sta
 // the query semantically, distinct from the memory's Confidence which
 // represents reliability based on feedback.
 func (s *Service) SearchWithScores(ctx context.Context, projectID, query string, limit int) ([]ScoredMemory, error) {
+	return s.searchWithScores(ctx, projectID, query, limit, true)
+}
+
+// searchWithScores implements SearchWithScores. exposure records the results
+// as served by the session's experiment arm; searches whose results are
+// combined before they are served, like sub-queries, record the combined
+// results instead.
+func (s *Service) searchWithScores(ctx context.Context, projectID, query string, limit int, exposure bool) ([]ScoredMemory, error) {
 	startTime := time.Now()
 
 	if projectID == "" {
@@ -721,6 +896,9 @@ func (s *Service) SearchWithScores(ctx context.Context, projectID, query string,
 	if query == "" {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
+	if err := validation.Memory.Query("query", query); err != nil {
+		return nil, err
+	}
 	if limit <= 0 {
 		limit = DefaultSearchLimit
 	}
@@ -750,8 +928,10 @@ func (s *Service) SearchWithScores(ctx context.Context, projectID, query string,
 	if searchLimit < 30 {
 		searchLimit = 30
 	}
+	// Cap the over-fetch, but never below limit: SearchPage asks for
+	// offset+limit+1 and deep pages must not be truncated at the cap
 	if searchLimit > 200 {
-		searchLimit = 200
+		searchLimit = max(200, limit)
 	}
 
 	results, err := store.SearchInCollection(ctx, collectionName, query, searchLimit, nil)
@@ -759,24 +939,38 @@ func (s *Service) SearchWithScores(ctx context.Context, projectID, query string,
 		s.recordError(ctx, "search", "search_failed")
 		return nil, fmt.Errorf("searching memories: %w", err)
 	}
+	results, chunkMatches := s.mergeChunkMatches(ctx, store, collectionName, projectID, query, searchLimit, results)
 
 	// Reuse shared scoring/filtering logic
-	queryEntities := s.extractQueryEntities(query)
-	isTemporalQuery := s.isTemporalQuery(query)
-	scored := s.scoreAndFilterResults(ctx, results, projectID, queryEntities, isTemporalQuery)
+	q := s.querySignals(ctx, query)
+	scored := s.scoreAndFilterResults(ctx, results, projectID, q)
 
-	// Sort by score (descending)
+	// Sort by score (descending), breaking ties by ID so paginated results
+	// are deterministic even when the store returns equal scores in any order.
 	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].score > scored[j].score
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].memory.ID < scored[j].memory.ID
 	})
+	if q.ranking != nil && q.ranking.Rerank {
+		scored = s.rerankWith(ctx, s.experiment.Reranker, query, projectID, scored)
+	}
 
 	// Convert to ScoredMemory and limit
 	scoredMemories := make([]ScoredMemory, 0, limit)
 	for i := 0; i < len(scored) && i < limit; i++ {
-		scoredMemories = append(scoredMemories, ScoredMemory{
+		sm := ScoredMemory{
 			Memory:    scored[i].memory,
 			Relevance: float64(scored[i].score),
-		})
+		}
+		if m, ok := chunkMatches[sm.Memory.ID]; ok {
+			sm.Highlight = m.highlight(&sm.Memory)
+		}
+		scoredMemories = append(scoredMemories, sm)
+	}
+	if exposure && q.ranking != nil {
+		s.recordExposure(ctx, projectID, q.arm, scoredMemoryIDs(scoredMemories))
 	}
 
 	// Record metrics
@@ -801,6 +995,36 @@ func (s *Service) SearchWithScores(ctx context.Context, projectID, query string,
 	return scoredMemories, nil
 }
 
+// SearchPage returns one page of scored memories, metadata for that page, and
+// a cursor for the next page. An empty cursor requests the first page; an
+// empty NextCursor means there are no more results. Cursors are bound to
+// projectID and query and return pagination.ErrCursorMismatch if reused for
+// another search.
+func (s *Service) SearchPage(ctx context.Context, projectID, query string, limit int, cursor string) (*MemoryPage, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	hash := searchHash(ctx, "memory", projectID, query)
+	offset, err := pagination.Decode(cursor, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra result to know whether another page exists.
+	results, err := s.SearchWithScores(ctx, projectID, query, offset+limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page, next := pagination.Paginate(results, offset, limit, hash)
+	return &MemoryPage{
+		Memories:   page,
+		Metadata:   s.searchMetadata(query, page),
+		NextCursor: next,
+	}, nil
+}
+
 // SearchWithMetadata returns memories with search relevance scores and metadata for iterative refinement.
 //
 // In addition to ranked results, provides SearchMetadata containing:
@@ -819,13 +1043,18 @@ func (s *Service) SearchWithMetadata(ctx context.Context, projectID, query strin
 		return nil, nil, err
 	}
 
+	return scoredMemories, s.searchMetadata(query, scoredMemories), nil
+}
+
+// searchMetadata derives refinement suggestions and coverage from results.
+func (s *Service) searchMetadata(query string, scoredMemories []ScoredMemory) *SearchMetadata {
 	// If no results, return empty metadata
 	if len(scoredMemories) == 0 {
-		return scoredMemories, &SearchMetadata{
+		return &SearchMetadata{
 			SuggestedRefinements: []string{},
 			QueryCoverage:        0.0,
 			EntityMatches:        0,
-		}, nil
+		}
 	}
 
 	// Extract entities from results that weren't in the original query
@@ -870,13 +1099,26 @@ func (s *Service) SearchWithMetadata(ctx context.Context, projectID, query strin
 	}
 	queryCoverage := totalRelevance / float64(len(scoredMemories))
 
-	metadata := &SearchMetadata{
+	return &SearchMetadata{
 		SuggestedRefinements: suggestedRefinements,
 		QueryCoverage:        queryCoverage,
 		EntityMatches:        len(resultEntities),
 	}
+}
 
-	return scoredMemories, metadata, nil
+// checkMemoryLimits rejects memories larger than validation.Memory allows.
+func checkMemoryLimits(memory *Memory) error {
+	limits := validation.Memory
+	for _, field := range []struct{ name, value string }{
+		{"title", memory.Title},
+		{"description", memory.Description},
+		{"content", memory.Content},
+	} {
+		if err := limits.Content(field.name, field.value); err != nil {
+			return err
+		}
+	}
+	return limits.Tags(memory.Tags)
 }
 
 // Record creates a new memory explicitly (bypasses distillation).
@@ -886,10 +1128,22 @@ func (s *Service) SearchWithMetadata(ctx context.Context, projectID, query strin
 //
 // FR-007: Explicit capture via memory_record
 // FR-002: Memory schema validation
+//
+// With WithDuplicateGuard, a memory repeating one recorded recently is
+// rejected with ErrDuplicateMemory.
 func (s *Service) Record(ctx context.Context, memory *Memory) error {
+	return s.record(ctx, memory, true)
+}
+
+// record implements Record. checkDuplicates is false for memories that
+// replace near-duplicates, such as consolidated memories.
+func (s *Service) record(ctx context.Context, memory *Memory, checkDuplicates bool) error {
 	if memory == nil {
 		return ErrInvalidMemory
 	}
+	if err := checkMemoryLimits(memory); err != nil {
+		return err
+	}
 
 	// Session buffering: when granularity=session and the memory has a SessionID,
 	// buffer the turn instead of storing immediately.
@@ -903,6 +1157,7 @@ func (s *Service) Record(ctx context.Context, memory *Memory) error {
 		if err := s.bufferMgr.BufferTurn(memory.ProjectID, memory.SessionID, entry); err != nil {
 			return fmt.Errorf("buffering turn: %w", err)
 		}
+		s.bufferMgr.captureScope(ctx, memory.ProjectID, memory.SessionID)
 		s.logger.Debug("buffered turn for session",
 			zap.String("session_id", memory.SessionID),
 			zap.String("project_id", memory.ProjectID),
@@ -910,6 +1165,46 @@ func (s *Service) Record(ctx context.Context, memory *Memory) error {
 		return nil
 	}
 
+	ctx, err := s.prepareRecord(ctx, memory)
+	if err != nil {
+		return err
+	}
+	if checkDuplicates {
+		if err := s.admitRecord(ctx, memory); err != nil {
+			return err
+		}
+	}
+	if err := s.addMemory(ctx, memory); err != nil {
+		if checkDuplicates {
+			s.forgetRecord(ctx, memory)
+		}
+		return err
+	}
+
+	// Chunk vectors are an aid to retrieval; the memory is already stored
+	// and searchable through its own vector if they fail.
+	if err := s.recordChunks(ctx, memory); err != nil {
+		s.recordError(ctx, "record", "chunk_store_failed")
+		s.logger.Warn("failed to record memory chunks",
+			zap.String("id", memory.ID),
+			zap.Error(err))
+	}
+
+	s.countRecorded(ctx, memory)
+	s.logger.Info("memory recorded",
+		zap.String("id", memory.ID),
+		zap.String("project_id", memory.ProjectID),
+		zap.String("title", memory.Title),
+		zap.Float64("confidence", memory.Confidence))
+
+	return nil
+}
+
+// prepareRecord fills in the defaults of a memory about to be recorded
+// (confidence, languages, timestamps, owner and revision), quarantines
+// suspected prompt injection and validates it. It returns ctx scoped to the
+// default tenant when the caller set none.
+func (s *Service) prepareRecord(ctx context.Context, memory *Memory) (context.Context, error) {
 	// Set explicit record confidence ONLY if default from NewMemory (0.5)
 	// AND the description doesn't indicate it's from distillation
 	// This allows distilled memories and custom confidence to be preserved
@@ -923,6 +1218,17 @@ func (s *Service) Record(ctx context.Context, memory *Memory) error {
 	if memory.Confidence == 0.0 {
 		memory.Confidence = ExplicitRecordConfidence
 	}
+	if len(memory.ConfidenceHistory) == 0 {
+		memory.recordConfidenceChange(CauseRecorded, memory.Confidence, "")
+	}
+	s.quarantineInjection(memory)
+
+	// Scope the memory to the languages it is about
+	if len(memory.Languages) > 0 {
+		memory.Languages = NormalizeLanguages(memory.Languages)
+	} else {
+		memory.Languages = InferLanguages(memory.Title, memory.Content, memory.Tags)
+	}
 
 	// Set timestamps
 	now := time.Now()
@@ -934,74 +1240,96 @@ func (s *Service) Record(ctx context.Context, memory *Memory) error {
 	// Validate memory
 	if err := memory.Validate(); err != nil {
 		s.recordError(ctx, "record", "validation_failed")
-		return fmt.Errorf("validating memory: %w", err)
+		return nil, fmt.Errorf("validating memory: %w", err)
 	}
 
-	// Get store and collection name
+	// Use tenant context from caller if set (MCP tools set this)
+	// Otherwise fall back to defaultTenant for backward compatibility
+	tenantCtx, err := s.withTenant(ctx, memory.ProjectID)
+	if err != nil {
+		s.recordError(ctx, "record", "tenant_not_configured")
+		return nil, err
+	}
+	ctx = tenantCtx
+
+	if memory.OwnerID == "" {
+		requester := vectorstore.RequesterFromContext(ctx)
+		memory.OwnerID, memory.OwnerTeam = requester.UserID, requester.TeamID
+	}
+
+	// A new memory starts at revision 1; later writes check and advance it
+	if memory.Revision == 0 {
+		memory.Revision = 1
+	}
+	return ctx, nil
+}
+
+// addMemory embeds and stores a new, prepared memory in its project's
+// memories collection.
+func (s *Service) addMemory(ctx context.Context, memory *Memory) error {
 	store, collectionName, err := s.getStore(ctx, memory.ProjectID)
 	if err != nil {
 		s.recordError(ctx, "record", "get_store_failed")
 		return err
 	}
+	if err := s.ensureMemoryCollection(ctx, store, collectionName, memory.ProjectID); err != nil {
+		return err
+	}
 
-	// Use tenant context from caller if set (MCP tools set this)
-	// Otherwise fall back to defaultTenant for backward compatibility
-	if _, err := vectorstore.TenantFromContext(ctx); err != nil {
-		// No tenant context set by caller, inject default
-		tenantID := s.defaultTenant
-		if tenantID == "" {
-			s.recordError(ctx, "record", "tenant_not_configured")
-			return fmt.Errorf("tenant ID not configured for reasoningbank service")
-		}
-		ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
-			TenantID:  tenantID,
-			ProjectID: memory.ProjectID,
-		})
+	doc := s.memoryToDocument(memory, collectionName)
+	if _, err := store.AddDocuments(ctx, []vectorstore.Document{doc}); err != nil {
+		s.recordError(ctx, "record", "store_failed")
+		return fmt.Errorf("storing memory: %w", err)
 	}
-	// Note: If tenant context is already set, we respect it (don't overwrite)
+	return nil
+}
 
-	// Ensure collection exists
+// ensureMemoryCollection creates a project's memories collection if it
+// does not exist yet.
+func (s *Service) ensureMemoryCollection(ctx context.Context, store vectorstore.Store, collectionName, projectID string) error {
 	exists, err := store.CollectionExists(ctx, collectionName)
 	if err != nil {
 		s.recordError(ctx, "record", "check_collection_failed")
 		return fmt.Errorf("checking collection existence: %w", err)
 	}
-	if !exists {
-		// Create collection with store's configured vector size (0 = use default)
-		if err := store.CreateCollection(ctx, collectionName, 0); err != nil {
-			s.recordError(ctx, "record", "create_collection_failed")
-			return fmt.Errorf("creating collection: %w", err)
-		}
-		s.logger.Info("created memories collection",
-			zap.String("collection", collectionName),
-			zap.String("project_id", memory.ProjectID))
+	if exists {
+		return nil
+	}
+	// Create collection with store's configured vector size (0 = use default)
+	if err := store.CreateCollection(ctx, collectionName, 0); err != nil {
+		s.recordError(ctx, "record", "create_collection_failed")
+		return fmt.Errorf("creating collection: %w", err)
 	}
+	s.logger.Info("created memories collection",
+		zap.String("collection", collectionName),
+		zap.String("project_id", projectID))
+	return nil
+}
 
-	// Convert to document
-	doc := s.memoryToDocument(memory, collectionName)
+// RecordListener is called after a new memory is stored, with the context
+// it was stored under.
+type RecordListener func(ctx context.Context, memory *Memory)
 
-	// Store in vector store
-	_, err = store.AddDocuments(ctx, []vectorstore.Document{doc})
-	if err != nil {
-		s.recordError(ctx, "record", "store_failed")
-		return fmt.Errorf("storing memory: %w", err)
+// WithRecordListener sets a listener called for each memory Record or
+// RecordBatch stores.
+func WithRecordListener(l RecordListener) ServiceOption {
+	return func(s *Service) {
+		s.onRecorded = l
 	}
+}
 
-	// Record metric
+// countRecorded adds a recorded memory to the record counter and reports it
+// to the record listener.
+func (s *Service) countRecorded(ctx context.Context, memory *Memory) {
 	if s.recordCounter != nil {
 		s.recordCounter.Add(ctx, 1, metric.WithAttributes(
 			attribute.String("project_id", memory.ProjectID),
 			attribute.String("outcome", string(memory.Outcome)),
 		))
 	}
-
-	s.logger.Info("memory recorded",
-		zap.String("id", memory.ID),
-		zap.String("project_id", memory.ProjectID),
-		zap.String("title", memory.Title),
-		zap.Float64("confidence", memory.Confidence))
-
-	return nil
+	if s.onRecorded != nil {
+		s.onRecorded(ctx, memory)
+	}
 }
 
 // FlushSession summarizes and persists a session's buffered turns.
@@ -1077,6 +1405,31 @@ func (s *Service) FlushSession(ctx context.Context, projectID, sessionID string)
 	return createdIDs, nil
 }
 
+// FlushAllSessions flushes every buffered session, as FlushSession does, in
+// the tenant and for the requester the turns were recorded with. It is
+// called at shutdown so buffered turns are not lost. Errors for individual
+// sessions are joined; the other sessions are still flushed.
+func (s *Service) FlushAllSessions(ctx context.Context) ([]string, error) {
+	if s.bufferMgr == nil {
+		return nil, nil
+	}
+	var createdIDs []string
+	var errs []error
+	for _, buf := range s.bufferMgr.pending() {
+		sessionCtx := vectorstore.ContextWithRequester(ctx, buf.requester)
+		if buf.tenant != nil {
+			sessionCtx = vectorstore.ContextWithTenant(sessionCtx, buf.tenant)
+		}
+		ids, err := s.FlushSession(sessionCtx, buf.ProjectID, buf.SessionID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", buf.SessionID, err))
+			continue
+		}
+		createdIDs = append(createdIDs, ids...)
+	}
+	return createdIDs, errors.Join(errs...)
+}
+
 // Feedback updates a memory's confidence based on user feedback.
 //
 // This method:
@@ -1087,9 +1440,22 @@ func (s *Service) FlushSession(ctx context.Context, projectID, sessionID string)
 // FR-008: Feedback loop affecting confidence
 // FR-005: Confidence tracking
 func (s *Service) Feedback(ctx context.Context, memoryID string, helpful bool) error {
+	return s.FeedbackWithEvidence(ctx, memoryID, helpful, "")
+}
+
+// FeedbackWithEvidence is Feedback with a description of what the agent
+// actually did with the memory. Evidence is stored with the signal and the
+// memory's confidence history, and evidence-backed feedback weighs
+// EvidenceWeight times as much as bare feedback. Empty evidence is the same
+// as calling Feedback.
+func (s *Service) FeedbackWithEvidence(ctx context.Context, memoryID string, helpful bool, evidence string) error {
 	if memoryID == "" {
 		return fmt.Errorf("memory ID cannot be empty")
 	}
+	evidence = strings.TrimSpace(evidence)
+	if err := validation.Memory.Evidence(evidence); err != nil {
+		return err
+	}
 
 	// Get the memory first
 	memory, err := s.Get(ctx, memoryID)
@@ -1097,10 +1463,9 @@ func (s *Service) Feedback(ctx context.Context, memoryID string, helpful bool) e
 		s.recordError(ctx, "feedback", "get_memory_failed")
 		return fmt.Errorf("getting memory: %w", err)
 	}
-
-	// Capture original state for potential rollback
-	originalConfidence := memory.Confidence
-	originalUpdatedAt := memory.UpdatedAt
+	if err := checkExpectedRevision(ctx, memory); err != nil {
+		return err
+	}
 
 	// Record explicit signal
 	signal, err := NewSignal(memoryID, memory.ProjectID, SignalExplicit, helpful, "")
@@ -1108,6 +1473,7 @@ func (s *Service) Feedback(ctx context.Context, memoryID string, helpful bool) e
 		s.recordError(ctx, "feedback", "create_signal_failed")
 		return fmt.Errorf("creating signal: %w", err)
 	}
+	signal.Evidence = evidence
 	if err := s.signalStore.StoreSignal(ctx, signal); err != nil {
 		s.recordError(ctx, "feedback", "store_signal_failed")
 		return fmt.Errorf("storing signal: %w", err)
@@ -1120,53 +1486,64 @@ func (s *Service) Feedback(ctx context.Context, memoryID string, helpful bool) e
 			zap.Error(err))
 	}
 
-	// Compute new confidence using Bayesian system
-	newConfidence, err := s.confCalc.ComputeConfidence(ctx, memoryID, memory.ProjectID)
-	if err != nil {
-		// Fall back to simple adjustment if Bayesian calculation fails
-		s.logger.Warn("falling back to simple confidence adjustment",
-			zap.String("memory_id", memoryID),
-			zap.Error(err))
-		memory.AdjustConfidence(helpful)
-	} else {
-		memory.Confidence = newConfidence
+	cause := CauseFeedbackUnhelpful
+	if helpful {
+		cause = CauseFeedbackHelpful
 	}
-	memory.UpdatedAt = time.Now()
+	// The signal is stored, so a concurrent write is resolved by applying
+	// it to the newer memory rather than by asking the caller to retry
+	var previous float64
+	err = retryOnConflict(func(attempt int) error {
+		if attempt > 0 {
+			if memory, err = s.Get(ctx, memoryID); err != nil {
+				return fmt.Errorf("getting memory: %w", err)
+			}
+		}
+		previous = memory.Confidence
+		s.applyConfidenceSignal(ctx, memory, cause, evidence, func() { memory.AdjustConfidence(helpful) })
 
-	// Get store and collection name
-	store, collectionName, err := s.getStore(ctx, memory.ProjectID)
+		if s.writes != nil {
+			s.queueConfidenceChange(ctx, memory)
+			return nil
+		}
+		store, collectionName, err := s.getStore(ctx, memory.ProjectID)
+		if err != nil {
+			return err
+		}
+		return s.commitMemory(ctx, store, collectionName, memory)
+	})
 	if err != nil {
-		s.recordError(ctx, "feedback", "get_store_failed")
+		s.recordError(ctx, "feedback", "update_failed")
 		return err
 	}
 
-	// Delete-then-add with rollback: delete old version, add updated version.
-	// If add fails, attempt to restore the original document using originalConfidence
-	// captured at the start of the function (line 929).
-	if err := store.DeleteDocumentsFromCollection(ctx, collectionName, []string{memoryID}); err != nil {
-		s.recordError(ctx, "feedback", "delete_old_failed")
-		return fmt.Errorf("deleting old memory: %w", err)
-	}
+	s.recordFeedbackMetric(ctx, memory, helpful, evidence)
+	s.recordThresholdCrossing(ctx, memory, cause, previous)
+	return nil
+}
 
-	// Re-add with updated confidence
-	doc := s.memoryToDocument(memory, collectionName)
-	_, err = store.AddDocuments(ctx, []vectorstore.Document{doc})
+// applyConfidenceSignal recomputes a memory's confidence from its signals,
+// falling back to fallback when the Bayesian calculation fails, and records
+// the change with cause.
+func (s *Service) applyConfidenceSignal(ctx context.Context, memory *Memory, cause ConfidenceCause, evidence string, fallback func()) {
+	originalConfidence := memory.Confidence
+	newConfidence, err := s.confCalc.ComputeConfidence(ctx, memory.ID, memory.ProjectID)
 	if err != nil {
-		// Attempt rollback: restore original document with original state
-		memory.Confidence = originalConfidence
-		memory.UpdatedAt = originalUpdatedAt
-		rollbackDoc := s.memoryToDocument(memory, collectionName)
-		_, rollbackErr := store.AddDocuments(ctx, []vectorstore.Document{rollbackDoc})
-		if rollbackErr != nil {
-			s.logger.Error("failed to rollback memory after update failure",
-				zap.String("id", memoryID),
-				zap.Error(rollbackErr))
-		}
-		s.recordError(ctx, "feedback", "update_failed")
-		return fmt.Errorf("updating memory: %w", err)
+		// Fall back to simple adjustment if Bayesian calculation fails
+		s.logger.Warn("falling back to simple confidence adjustment",
+			zap.String("memory_id", memory.ID),
+			zap.Error(err))
+		fallback()
+	} else {
+		memory.Confidence = newConfidence
 	}
+	memory.recordConfidenceChange(cause, originalConfidence, evidence)
+	s.updateImportance(ctx, memory)
+	memory.UpdatedAt = time.Now()
+}
 
-	// Record feedback metric
+// recordFeedbackMetric counts and logs feedback applied to a memory.
+func (s *Service) recordFeedbackMetric(ctx context.Context, memory *Memory, helpful bool, evidence string) {
 	if s.feedbackCounter != nil {
 		helpfulStr := "negative"
 		if helpful {
@@ -1179,11 +1556,10 @@ func (s *Service) Feedback(ctx context.Context, memoryID string, helpful bool) e
 	}
 
 	s.logger.Info("memory feedback recorded",
-		zap.String("id", memoryID),
+		zap.String("id", memory.ID),
 		zap.Bool("helpful", helpful),
+		zap.Bool("evidence", evidence != ""),
 		zap.Float64("new_confidence", memory.Confidence))
-
-	return nil
 }
 
 // Get retrieves a memory by ID.
@@ -1255,7 +1631,7 @@ func (s *Service) Get(ctx context.Context, id string) (*Memory, error) {
 			if err != nil {
 				return nil, fmt.Errorf("converting result to memory: %w", err)
 			}
-			return memory, nil
+			return s.writes.overlay(memory), nil
 		}
 	}
 
@@ -1279,6 +1655,15 @@ func (s *Service) GetByProjectID(ctx context.Context, projectID, memoryID string
 		return nil, fmt.Errorf("invalid memory ID format: must be a valid UUID")
 	}
 
+	memory, err := s.loadByProjectID(ctx, projectID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.writes.overlay(memory), nil
+}
+
+// loadByProjectID reads a memory as stored, without batched updates.
+func (s *Service) loadByProjectID(ctx context.Context, projectID, memoryID string) (*Memory, error) {
 	// Get store and collection name
 	store, collectionName, err := s.getStore(ctx, projectID)
 	if err != nil {
@@ -1333,6 +1718,12 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 	if err := s.store.DeleteDocuments(ctx, []string{id}); err != nil {
 		return fmt.Errorf("deleting memory: %w", err)
 	}
+	s.writes.discard(id)
+	if err := s.deleteProjectChunks(ctx, memory.ProjectID, id); err != nil {
+		s.logger.Warn("failed to delete memory chunks",
+			zap.String("id", id),
+			zap.Error(err))
+	}
 
 	s.logger.Info("memory deleted",
 		zap.String("id", id),
@@ -1368,6 +1759,12 @@ func (s *Service) DeleteByProjectID(ctx context.Context, projectID, memoryID str
 	if err := store.DeleteDocumentsFromCollection(ctx, collectionName, []string{memoryID}); err != nil {
 		return fmt.Errorf("deleting memory: %w", err)
 	}
+	s.writes.discard(memoryID)
+	if err := s.deleteProjectChunks(ctx, projectID, memoryID); err != nil {
+		s.logger.Warn("failed to delete memory chunks",
+			zap.String("id", memoryID),
+			zap.Error(err))
+	}
 
 	s.logger.Info("memory deleted",
 		zap.String("id", memoryID),
@@ -1399,10 +1796,9 @@ func (s *Service) RecordOutcome(ctx context.Context, memoryID string, succeeded
 		s.recordError(ctx, "outcome", "get_memory_failed")
 		return 0, fmt.Errorf("getting memory: %w", err)
 	}
-
-	// Capture original state for potential rollback
-	originalConfidence := memory.Confidence
-	originalUpdatedAt := memory.UpdatedAt
+	if err := checkExpectedRevision(ctx, memory); err != nil {
+		return 0, err
+	}
 
 	// Create and store outcome signal
 	signal, err := NewSignal(memoryID, memory.ProjectID, SignalOutcome, succeeded, sessionID)
@@ -1414,65 +1810,51 @@ func (s *Service) RecordOutcome(ctx context.Context, memoryID string, succeeded
 		s.recordError(ctx, "outcome", "store_signal_failed")
 		return 0, fmt.Errorf("storing signal: %w", err)
 	}
+	s.recordExperimentOutcome(ctx, memoryID, sessionID, succeeded)
 
-	// Compute new confidence using Bayesian system
-	newConfidence, err := s.confCalc.ComputeConfidence(ctx, memoryID, memory.ProjectID)
-	if err != nil {
-		// Fall back to simple adjustment if Bayesian calculation fails
-		s.logger.Warn("falling back to simple confidence adjustment",
-			zap.String("memory_id", memoryID),
-			zap.Error(err))
-		if succeeded {
-			memory.Confidence += 0.05
-			if memory.Confidence > 1.0 {
-				memory.Confidence = 1.0
-			}
-		} else {
-			memory.Confidence -= 0.08
-			if memory.Confidence < 0.0 {
-				memory.Confidence = 0.0
+	cause := CauseOutcomeFailure
+	if succeeded {
+		cause = CauseOutcomeSuccess
+	}
+	// As in FeedbackWithEvidence, conflicts are retried on the newer memory
+	var previous float64
+	err = retryOnConflict(func(attempt int) error {
+		if attempt > 0 {
+			if memory, err = s.Get(ctx, memoryID); err != nil {
+				return fmt.Errorf("getting memory: %w", err)
 			}
 		}
-		// newConfidence not needed in fallback - memory.Confidence is already updated
-	} else {
-		memory.Confidence = newConfidence
-	}
-	memory.UpdatedAt = time.Now()
+		previous = memory.Confidence
+		s.applyConfidenceSignal(ctx, memory, cause, "", func() {
+			if succeeded {
+				memory.Confidence = min(memory.Confidence+0.05, 1.0)
+			} else {
+				memory.Confidence = max(memory.Confidence-0.08, 0.0)
+			}
+		})
 
-	// Get store and collection name
-	store, collectionName, err := s.getStore(ctx, memory.ProjectID)
+		if s.writes != nil {
+			s.queueConfidenceChange(ctx, memory)
+			return nil
+		}
+		store, collectionName, err := s.getStore(ctx, memory.ProjectID)
+		if err != nil {
+			return err
+		}
+		return s.commitMemory(ctx, store, collectionName, memory)
+	})
 	if err != nil {
-		s.recordError(ctx, "outcome", "get_store_failed")
+		s.recordError(ctx, "outcome", "update_failed")
 		return 0, err
 	}
 
-	// Delete-then-add with rollback: delete old version, add updated version.
-	// If add fails, attempt to restore the original document using originalConfidence
-	// captured at the start of the function.
-	if err := store.DeleteDocumentsFromCollection(ctx, collectionName, []string{memoryID}); err != nil {
-		s.recordError(ctx, "outcome", "delete_old_failed")
-		return 0, fmt.Errorf("deleting old memory: %w", err)
-	}
-
-	// Re-add with updated confidence
-	doc := s.memoryToDocument(memory, collectionName)
-	_, err = store.AddDocuments(ctx, []vectorstore.Document{doc})
-	if err != nil {
-		// Attempt rollback: restore original document with original state
-		memory.Confidence = originalConfidence
-		memory.UpdatedAt = originalUpdatedAt
-		rollbackDoc := s.memoryToDocument(memory, collectionName)
-		_, rollbackErr := store.AddDocuments(ctx, []vectorstore.Document{rollbackDoc})
-		if rollbackErr != nil {
-			s.logger.Error("failed to rollback memory after update failure",
-				zap.String("id", memoryID),
-				zap.Error(rollbackErr))
-		}
-		s.recordError(ctx, "outcome", "update_failed")
-		return 0, fmt.Errorf("updating memory: %w", err)
-	}
+	s.recordOutcomeMetric(ctx, memory, signal, succeeded)
+	s.recordThresholdCrossing(ctx, memory, cause, previous)
+	return memory.Confidence, nil
+}
 
-	// Record outcome metric
+// recordOutcomeMetric counts and logs an outcome applied to a memory.
+func (s *Service) recordOutcomeMetric(ctx context.Context, memory *Memory, signal *Signal, succeeded bool) {
 	if s.outcomeCounter != nil {
 		successStr := "failure"
 		if succeeded {
@@ -1485,12 +1867,54 @@ func (s *Service) RecordOutcome(ctx context.Context, memoryID string, succeeded
 	}
 
 	s.logger.Info("outcome recorded",
-		zap.String("id", memoryID),
+		zap.String("id", memory.ID),
 		zap.String("signal_id", signal.ID),
 		zap.Bool("succeeded", succeeded),
 		zap.Float64("new_confidence", memory.Confidence))
+}
 
-	return memory.Confidence, nil
+// recordThresholdCrossing counts and logs a confidence change from previous
+// that moved memory into or out of search results. Changes that stay on one
+// side of MinConfidence are not recorded.
+func (s *Service) recordThresholdCrossing(ctx context.Context, memory *Memory, cause ConfidenceCause, previous float64) {
+	direction, crossed := thresholdCrossing(previous, memory.Confidence)
+	if !crossed {
+		return
+	}
+
+	if s.thresholdCounter != nil {
+		s.thresholdCounter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("project_id", memory.ProjectID),
+			attribute.String("direction", string(direction)),
+			attribute.String("cause", cause.Source()),
+		))
+	}
+
+	s.logger.Info("memory confidence crossed search threshold",
+		zap.String("id", memory.ID),
+		zap.String("project_id", memory.ProjectID),
+		zap.String("direction", string(direction)),
+		zap.String("cause", cause.Source()),
+		zap.String("confidence_cause", string(cause)),
+		zap.Float64("previous_confidence", previous),
+		zap.Float64("new_confidence", memory.Confidence),
+		zap.Float64("threshold", MinConfidence))
+}
+
+// ConfidenceHistory returns every recorded confidence adjustment of a
+// memory, oldest first. Memories recorded before history was kept return
+// only the changes made since.
+func (s *Service) ConfidenceHistory(ctx context.Context, memoryID string) ([]ConfidenceChange, error) {
+	if memoryID == "" {
+		return nil, fmt.Errorf("memory ID cannot be empty")
+	}
+	memory, err := s.Get(ctx, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("getting memory: %w", err)
+	}
+	history := make([]ConfidenceChange, len(memory.ConfidenceHistory))
+	copy(history, memory.ConfidenceHistory)
+	return history, nil
 }
 
 // extractQueryEntities extracts named entities (proper nouns) from a query.
@@ -1629,6 +2053,8 @@ func (s *Service) memoryToDocument(memory *Memory, collectionName string) vector
 		"outcome":     string(memory.Outcome),
 		"confidence":  memory.Confidence,
 		"usage_count": memory.UsageCount,
+		"revision":    memory.Revision,
+		"importance":  memory.Importance,
 		"tags":        memory.Tags,
 		"state":       string(memory.State),
 		"created_at":  memory.CreatedAt.Unix(),
@@ -1650,6 +2076,33 @@ func (s *Service) memoryToDocument(memory *Memory, collectionName string) vector
 	if memory.Granularity != "" {
 		metadata["granularity"] = string(memory.Granularity)
 	}
+	if memory.Visibility != "" {
+		metadata[vectorstore.VisibilityKey] = string(memory.Visibility)
+	}
+	if memory.OwnerID != "" {
+		metadata[vectorstore.OwnerKey] = memory.OwnerID
+	}
+	if memory.OwnerTeam != "" {
+		metadata[vectorstore.OwnerTeamKey] = memory.OwnerTeam
+	}
+	if len(memory.Languages) > 0 {
+		// Comma-separated so it survives stores with string-only metadata
+		metadata["languages"] = strings.Join(memory.Languages, ",")
+	}
+	if len(memory.ConfidenceHistory) > 0 {
+		// JSON-encoded for the same reason
+		if history, err := json.Marshal(memory.ConfidenceHistory); err == nil {
+			metadata["confidence_history"] = string(history)
+		}
+	}
+	if len(memory.QuarantineRules) > 0 {
+		metadata["quarantine_rules"] = strings.Join(memory.QuarantineRules, ",")
+	}
+	if memory.Origin != nil {
+		if origin, err := json.Marshal(memory.Origin); err == nil {
+			metadata["origin"] = string(origin)
+		}
+	}
 
 	return vectorstore.Document{
 		ID:         memory.ID,
@@ -1734,9 +2187,6 @@ func (s *Service) Count(ctx context.Context, projectID string) (int, error) {
 // Returns memories in storage order. For large projects, use pagination to avoid
 // loading all memories at once.
 func (s *Service) ListMemories(ctx context.Context, projectID string, limit, offset int) ([]Memory, error) {
-	if projectID == "" {
-		return nil, ErrEmptyProjectID
-	}
 	if limit < 0 {
 		return nil, fmt.Errorf("limit cannot be negative")
 	}
@@ -1744,88 +2194,108 @@ func (s *Service) ListMemories(ctx context.Context, projectID string, limit, off
 		return nil, fmt.Errorf("offset cannot be negative")
 	}
 
+	memories := []Memory{}
+	skipped := 0
+	err := s.StreamMemories(ctx, projectID, 0, func(m Memory) error {
+		if skipped < offset {
+			skipped++
+			return nil
+		}
+		memories = append(memories, m)
+		if limit > 0 && len(memories) >= limit {
+			return ErrStopStream
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("list memories completed",
+		zap.String("project_id", projectID),
+		zap.Int("limit", limit),
+		zap.Int("offset", offset),
+		zap.Int("results", len(memories)))
+
+	return memories, nil
+}
+
+// StreamMemories calls fn for every memory in a project, reading the
+// collection in batches of batchSize (DefaultStreamBatchSize when <= 0) so
+// consolidation and analysis passes over very large projects hold only one
+// batch at a time. Return ErrStopStream from fn to stop early without error;
+// any other error from fn stops iteration and is returned.
+//
+// Stores that do not implement vectorstore.Scroller fall back to a single
+// read capped at maxListFetch memories.
+func (s *Service) StreamMemories(ctx context.Context, projectID string, batchSize int, fn func(Memory) error) error {
+	if projectID == "" {
+		return ErrEmptyProjectID
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultStreamBatchSize
+	}
+
 	// Get store and collection name for this project
 	store, collectionName, err := s.getStore(ctx, projectID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Use tenant context from caller if set (MCP tools set this)
 	// Otherwise fall back to defaultTenant for backward compatibility
-	if _, err := vectorstore.TenantFromContext(ctx); err != nil {
-		tenantID := s.defaultTenant
-		if tenantID == "" {
-			return nil, fmt.Errorf("tenant ID not configured for reasoningbank service")
-		}
-		ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
-			TenantID:  tenantID,
-			ProjectID: projectID,
-		})
+	ctx, err = s.withTenant(ctx, projectID)
+	if err != nil {
+		return err
 	}
 
 	// Check if collection exists
 	exists, err := store.CollectionExists(ctx, collectionName)
 	if err != nil {
-		return nil, fmt.Errorf("checking collection existence: %w", err)
+		return fmt.Errorf("checking collection existence: %w", err)
 	}
 	if !exists {
 		// No memories yet for this project
 		s.logger.Debug("collection does not exist",
 			zap.String("collection", collectionName),
 			zap.String("project_id", projectID))
-		return []Memory{}, nil
-	}
-
-	// Calculate fetch limit: need offset + limit documents
-	// Use a high limit if limit=0 (return all)
-	fetchLimit := limit + offset
-	if limit == 0 {
-		// Fetch all - use a very high limit
-		// Most projects won't have more than 10k memories
-		fetchLimit = 10000
-	}
-	if fetchLimit > 10000 {
-		fetchLimit = 10000 // Cap to prevent excessive fetching
-	}
-
-	// Use SearchInCollection with an empty query to get all documents
-	// The vectorstore will return results in storage order
-	results, err := store.SearchInCollection(ctx, collectionName, "", fetchLimit, nil)
-	if err != nil {
-		return nil, fmt.Errorf("listing memories: %w", err)
-	}
-
-	// Skip offset documents and take up to limit
-	start := offset
-	if start > len(results) {
-		return []Memory{}, nil
+		return nil
 	}
 
-	end := len(results)
-	if limit > 0 && start+limit < len(results) {
-		end = start + limit
+	emit := func(results []vectorstore.SearchResult) error {
+		for _, r := range results {
+			memory, err := s.resultToMemory(r)
+			if err != nil {
+				s.logger.Warn("skipping invalid memory",
+					zap.String("id", r.ID),
+					zap.Error(err))
+				continue
+			}
+			if err := fn(*memory); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	// Convert results to Memory structs
-	memories := make([]Memory, 0, end-start)
-	for i := start; i < end; i++ {
-		memory, err := s.resultToMemory(results[i])
+	err = vectorstore.Scroll(ctx, store, collectionName, batchSize, nil, emit)
+	if errors.Is(err, vectorstore.ErrScrollUnsupported) {
+		// Use SearchInCollection with an empty query to get all documents
+		// The vectorstore will return results in storage order
+		var results []vectorstore.SearchResult
+		results, err = store.SearchInCollection(ctx, collectionName, "", maxListFetch, nil)
 		if err != nil {
-			s.logger.Warn("skipping invalid memory",
-				zap.String("id", results[i].ID),
-				zap.Error(err))
-			continue
+			return fmt.Errorf("listing memories: %w", err)
 		}
-		memories = append(memories, *memory)
+		err = emit(results)
 	}
-
-	s.logger.Debug("list memories completed",
-		zap.String("project_id", projectID),
-		zap.Int("limit", limit),
-		zap.Int("offset", offset),
-		zap.Int("results", len(memories)))
-
-	return memories, nil
+	if errors.Is(err, ErrStopStream) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("streaming memories: %w", err)
+	}
+	return nil
 }
 
 // GetMemoryVector retrieves the embedding vector for a memory by ID.
@@ -1944,6 +2414,24 @@ func parseInt64(v interface{}) int64 {
 	}
 }
 
+// parseStringList parses a list stored in metadata, handling both
+// []string (in-memory) and []interface{} (JSON deserialized).
+func parseStringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if str, ok := item.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
 // resultToMemory converts a vectorstore SearchResult to a Memory.
 func (s *Service) resultToMemory(result vectorstore.SearchResult) (*Memory, error) {
 	// Extract fields from metadata
@@ -1958,19 +2446,23 @@ func (s *Service) resultToMemory(result vectorstore.SearchResult) (*Memory, erro
 	outcomeStr, _ := result.Metadata["outcome"].(string)
 	confidence := parseFloat64(result.Metadata["confidence"])
 	usageCount := int(parseInt64(result.Metadata["usage_count"]))
-
-	// Parse tags - handle both []string (in-memory) and []interface{} (JSON deserialized)
-	tags := []string{}
-	if tagsIface, ok := result.Metadata["tags"]; ok {
-		switch tagsList := tagsIface.(type) {
-		case []string:
-			tags = tagsList
-		case []interface{}:
-			for _, t := range tagsList {
-				if tag, ok := t.(string); ok {
-					tags = append(tags, tag)
-				}
-			}
+	revision := parseInt64(result.Metadata["revision"])
+	importance := parseFloat64(result.Metadata["importance"])
+
+	tags := parseStringList(result.Metadata["tags"])
+	if tags == nil {
+		tags = []string{}
+	}
+	var languages []string
+	if joined, _ := result.Metadata["languages"].(string); joined != "" {
+		languages = strings.Split(joined, ",")
+	}
+	var confidenceHistory []ConfidenceChange
+	if encoded, _ := result.Metadata["confidence_history"].(string); encoded != "" {
+		if err := json.Unmarshal([]byte(encoded), &confidenceHistory); err != nil {
+			s.logger.Warn("ignoring unreadable confidence history",
+				zap.String("id", id),
+				zap.Error(err))
 		}
 	}
 
@@ -1984,8 +2476,22 @@ func (s *Service) resultToMemory(result vectorstore.SearchResult) (*Memory, erro
 	// Parse state (default to Active for backwards compatibility with existing memories)
 	stateStr, _ := result.Metadata["state"].(string)
 	state := MemoryStateActive
-	if stateStr == string(MemoryStateArchived) {
-		state = MemoryStateArchived
+	switch MemoryState(stateStr) {
+	case MemoryStateArchived, MemoryStateQuarantined:
+		state = MemoryState(stateStr)
+	}
+	var quarantineRules []string
+	if joined, _ := result.Metadata["quarantine_rules"].(string); joined != "" {
+		quarantineRules = strings.Split(joined, ",")
+	}
+	var origin *ConversationOrigin
+	if encoded, _ := result.Metadata["origin"].(string); encoded != "" {
+		if err := json.Unmarshal([]byte(encoded), &origin); err != nil {
+			s.logger.Warn("ignoring unreadable conversation origin",
+				zap.String("id", id),
+				zap.Error(err))
+			origin = nil
+		}
 	}
 
 	// Parse consolidation_id if present
@@ -2003,6 +2509,9 @@ func (s *Service) resultToMemory(result vectorstore.SearchResult) (*Memory, erro
 	}
 	granularityStr, _ := result.Metadata["granularity"].(string)
 	granularity := MemoryGranularity(granularityStr)
+	visibility, _ := result.Metadata[vectorstore.VisibilityKey].(string)
+	ownerID, _ := result.Metadata[vectorstore.OwnerKey].(string)
+	ownerTeam, _ := result.Metadata[vectorstore.OwnerTeamKey].(string)
 
 	// Parse content (strip title from beginning if present)
 	content := result.Content
@@ -2013,22 +2522,31 @@ func (s *Service) resultToMemory(result vectorstore.SearchResult) (*Memory, erro
 	}
 
 	memory := &Memory{
-		ID:              id,
-		ProjectID:       projectID,
-		Title:           title,
-		Description:     description,
-		Content:         content,
-		Outcome:         Outcome(outcomeStr),
-		Confidence:      confidence,
-		UsageCount:      usageCount,
-		Tags:            tags,
-		ConsolidationID: consolidationID,
-		State:           state,
-		SessionID:       sessionID,
-		SessionDate:     sessionDate,
-		Granularity:     granularity,
-		CreatedAt:       createdAt,
-		UpdatedAt:       updatedAt,
+		ID:                id,
+		ProjectID:         projectID,
+		Title:             title,
+		Description:       description,
+		Content:           content,
+		Outcome:           Outcome(outcomeStr),
+		Confidence:        confidence,
+		ConfidenceHistory: confidenceHistory,
+		QuarantineRules:   quarantineRules,
+		UsageCount:        usageCount,
+		Revision:          revision,
+		Importance:        importance,
+		Tags:              tags,
+		Languages:         languages,
+		ConsolidationID:   consolidationID,
+		State:             state,
+		SessionID:         sessionID,
+		SessionDate:       sessionDate,
+		Granularity:       granularity,
+		Visibility:        vectorstore.Visibility(visibility),
+		OwnerID:           ownerID,
+		OwnerTeam:         ownerTeam,
+		Origin:            origin,
+		CreatedAt:         createdAt,
+		UpdatedAt:         updatedAt,
 	}
 
 	return memory, nil
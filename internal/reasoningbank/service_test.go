@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
 	"github.com/fyrsmithlabs/contextd/internal/project"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 	"github.com/stretchr/testify/assert"
@@ -521,6 +522,106 @@ func TestService_Feedback(t *testing.T) {
 	})
 }
 
+func TestService_ConfidenceHistory(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	svc, _ := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+	memory, _ := NewMemory("project-123", "Test Memory", "Test content", OutcomeSuccess, []string{"test"})
+	require.NoError(t, svc.Record(ctx, memory))
+
+	require.NoError(t, svc.Feedback(ctx, memory.ID, true))
+	_, err := svc.RecordOutcome(ctx, memory.ID, false, "session-1")
+	require.NoError(t, err)
+
+	history, err := svc.ConfidenceHistory(ctx, memory.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	assert.Equal(t, CauseRecorded, history[0].Cause)
+	assert.Equal(t, ExplicitRecordConfidence, history[0].Confidence)
+	assert.Zero(t, history[0].Delta)
+
+	assert.Equal(t, CauseFeedbackHelpful, history[1].Cause)
+	assert.InDelta(t, history[1].Confidence-history[0].Confidence, history[1].Delta, 1e-9)
+
+	assert.Equal(t, CauseOutcomeFailure, history[2].Cause)
+	assert.Less(t, history[2].Delta, 0.0)
+
+	updated, err := svc.Get(ctx, memory.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Confidence, history[2].Confidence)
+
+	_, err = svc.ConfidenceHistory(ctx, "")
+	assert.Error(t, err)
+}
+
+func TestThresholdCrossing(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  float64
+		current   float64
+		direction ThresholdDirection
+		crossed   bool
+	}{
+		{"rises to threshold", 0.65, MinConfidence, ThresholdPromoted, true},
+		{"rises past threshold", 0.5, 0.9, ThresholdPromoted, true},
+		{"falls below threshold", MinConfidence, 0.65, ThresholdFilteredOut, true},
+		{"stays above", 0.8, 0.9, "", false},
+		{"stays below", 0.2, 0.5, "", false},
+		{"unchanged at threshold", MinConfidence, MinConfidence, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direction, crossed := thresholdCrossing(tt.previous, tt.current)
+			assert.Equal(t, tt.crossed, crossed)
+			assert.Equal(t, tt.direction, direction)
+		})
+	}
+}
+
+func TestConfidenceCause_Source(t *testing.T) {
+	assert.Equal(t, "feedback", CauseFeedbackHelpful.Source())
+	assert.Equal(t, "feedback", CauseFeedbackUnhelpful.Source())
+	assert.Equal(t, "outcome", CauseOutcomeSuccess.Source())
+	assert.Equal(t, "outcome", CauseOutcomeFailure.Source())
+	assert.Equal(t, "recorded", CauseRecorded.Source())
+}
+
+func TestService_FeedbackWithEvidence(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	svc, _ := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+	memory, _ := NewMemory("project-123", "Test Memory", "Test content", OutcomeSuccess, []string{"test"})
+	require.NoError(t, svc.Record(ctx, memory))
+
+	require.NoError(t, svc.FeedbackWithEvidence(ctx, memory.ID, true, "  ran the migration as described  "))
+
+	history, err := svc.ConfidenceHistory(ctx, memory.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, CauseFeedbackHelpful, history[1].Cause)
+	assert.Equal(t, "ran the migration as described", history[1].Evidence)
+
+	err = svc.FeedbackWithEvidence(ctx, memory.ID, true, strings.Repeat("x", 2001))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "evidence")
+}
+
+func TestMemory_RecordConfidenceChange_Caps(t *testing.T) {
+	m := &Memory{Confidence: 0.5}
+	for i := 0; i < MaxConfidenceHistory+5; i++ {
+		m.recordConfidenceChange(CauseFeedbackHelpful, 0.5, "")
+	}
+	saved := m.ConfidenceHistory
+
+	m.Confidence = 0.7
+	m.recordConfidenceChange(CauseOutcomeSuccess, 0.5, "")
+	require.Len(t, m.ConfidenceHistory, MaxConfidenceHistory)
+	assert.Equal(t, CauseOutcomeSuccess, m.ConfidenceHistory[MaxConfidenceHistory-1].Cause)
+	assert.InDelta(t, 0.2, m.ConfidenceHistory[MaxConfidenceHistory-1].Delta, 1e-9)
+	assert.Equal(t, CauseFeedbackHelpful, saved[MaxConfidenceHistory-1].Cause, "saved history must not change")
+}
+
 func TestService_RecordOutcome(t *testing.T) {
 	ctx := context.Background()
 
@@ -1145,6 +1246,99 @@ func TestService_WithStoreProvider_Operations(t *testing.T) {
 	})
 }
 
+// scrollingMockStore adds vectorstore.Scroller support to mockStore and
+// records the size of each batch it hands out.
+type scrollingMockStore struct {
+	*mockStore
+	batches []int
+}
+
+func (m *scrollingMockStore) ScrollCollection(ctx context.Context, collectionName string, batchSize int, filters map[string]interface{}, fn func([]vectorstore.SearchResult) error) error {
+	m.mu.RLock()
+	docs := append([]vectorstore.Document(nil), m.collections[collectionName]...)
+	m.mu.RUnlock()
+
+	for start := 0; start < len(docs); start += batchSize {
+		end := start + batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		batch := make([]vectorstore.SearchResult, 0, end-start)
+		for _, doc := range docs[start:end] {
+			batch = append(batch, vectorstore.SearchResult{ID: doc.ID, Content: doc.Content, Metadata: doc.Metadata})
+		}
+		m.batches = append(m.batches, len(batch))
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestService_StreamMemories(t *testing.T) {
+	ctx := context.Background()
+	store := &scrollingMockStore{mockStore: newMockStore()}
+	svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+
+	projectID := "stream-project"
+	for i := 0; i < 7; i++ {
+		memory, _ := NewMemory(projectID, fmt.Sprintf("Memory %d", i), "content", OutcomeSuccess, []string{"test"})
+		require.NoError(t, svc.Record(ctx, memory))
+	}
+
+	t.Run("reads in batches", func(t *testing.T) {
+		store.batches = nil
+		seen := make(map[string]bool)
+		err := svc.StreamMemories(ctx, projectID, 3, func(m Memory) error {
+			seen[m.ID] = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, seen, 7)
+		assert.Equal(t, []int{3, 3, 1}, store.batches)
+	})
+
+	t.Run("ErrStopStream stops without error", func(t *testing.T) {
+		count := 0
+		err := svc.StreamMemories(ctx, projectID, 3, func(m Memory) error {
+			count++
+			if count == 4 {
+				return ErrStopStream
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("returns callback error", func(t *testing.T) {
+		errBoom := fmt.Errorf("boom")
+		err := svc.StreamMemories(ctx, projectID, 0, func(m Memory) error {
+			return errBoom
+		})
+		assert.ErrorIs(t, err, errBoom)
+	})
+
+	t.Run("falls back for stores without scroll", func(t *testing.T) {
+		fallbackSvc, err := NewService(store.mockStore, zap.NewNop(), WithDefaultTenant("test-tenant"))
+		require.NoError(t, err)
+
+		count := 0
+		err = fallbackSvc.StreamMemories(ctx, projectID, 3, func(m Memory) error {
+			count++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 7, count)
+	})
+
+	t.Run("validates project ID", func(t *testing.T) {
+		err := svc.StreamMemories(ctx, "", 0, func(Memory) error { return nil })
+		assert.Equal(t, ErrEmptyProjectID, err)
+	})
+}
+
 func TestService_ListMemories(t *testing.T) {
 	ctx := context.Background()
 	store := newMockStore()
@@ -2180,3 +2374,104 @@ func TestService_SanitizeRefinements(t *testing.T) {
 		assert.Equal(t, input, result)
 	})
 }
+
+func TestService_SearchPage(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newMockStore(), zap.NewNop(), WithDefaultTenant("test-tenant"))
+	require.NoError(t, err)
+
+	projectID := "project-paging"
+	for i := 0; i < 5; i++ {
+		memory, err := NewMemory(projectID, fmt.Sprintf("Paging memory %d", i), "retry with backoff", OutcomeSuccess, nil)
+		require.NoError(t, err)
+		require.NoError(t, svc.Record(ctx, memory))
+	}
+
+	var ids []string
+	cursor := ""
+	pages := 0
+	for {
+		page, err := svc.SearchPage(ctx, projectID, "retry backoff", 2, cursor)
+		require.NoError(t, err)
+		assert.NotNil(t, page.Metadata)
+		for _, sm := range page.Memories {
+			ids = append(ids, sm.Memory.ID)
+		}
+		pages++
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Len(t, ids, 5)
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		assert.False(t, seen[id], "memory %s returned on more than one page", id)
+		seen[id] = true
+	}
+
+	t.Run("cursor bound to query", func(t *testing.T) {
+		first, err := svc.SearchPage(ctx, projectID, "retry backoff", 2, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, first.NextCursor)
+
+		_, err = svc.SearchPage(ctx, projectID, "something else", 2, first.NextCursor)
+		assert.ErrorIs(t, err, pagination.ErrCursorMismatch)
+	})
+
+	t.Run("pages past the candidate cap", func(t *testing.T) {
+		deepProject := "project-deep-paging"
+		for i := 0; i < 230; i++ {
+			memory, err := NewMemory(deepProject, fmt.Sprintf("Deep memory %d", i), "retry with backoff", OutcomeSuccess, nil)
+			require.NoError(t, err)
+			require.NoError(t, svc.Record(ctx, memory))
+		}
+
+		// Offset 200 is past the 200-candidate over-fetch cap
+		cursor := pagination.Encode(200, searchHash(ctx, "memory", deepProject, "retry backoff"))
+		page, err := svc.SearchPage(ctx, deepProject, "retry backoff", 50, cursor)
+		require.NoError(t, err)
+		assert.Len(t, page.Memories, 30)
+		assert.Empty(t, page.NextCursor)
+	})
+}
+
+func TestService_FlushAllSessions(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 3,
+	}, &keywordEmbedder{keywords: []string{"database", "kubernetes"}}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	svc, err := NewService(store, zap.NewNop(),
+		WithDefaultTenant("acme"),
+		WithSessionGranularity(NewSimpleExtractor(), zap.NewNop(), 0))
+	require.NoError(t, err)
+
+	projectID := "app"
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "acme", ProjectID: projectID})
+	ctx = vectorstore.ContextWithRequester(ctx, vectorstore.Requester{UserID: "alice"})
+	for _, session := range []string{"sess-1", "sess-2"} {
+		memory, err := NewMemory(projectID, "database migration", "Run the database migration before deploying", OutcomeSuccess, nil)
+		require.NoError(t, err)
+		memory.SessionID = session
+		require.NoError(t, svc.Record(ctx, memory))
+	}
+	require.Equal(t, 2, svc.bufferMgr.ActiveSessions())
+
+	// Shutdown flushes without the request's tenant; the buffers carry it.
+	ids, err := svc.FlushAllSessions(context.Background())
+	require.NoError(t, err)
+	assert.NotEmpty(t, ids)
+	assert.Equal(t, 0, svc.bufferMgr.ActiveSessions())
+
+	for _, id := range ids {
+		memory, err := svc.GetByProjectID(ctx, projectID, id)
+		require.NoError(t, err, "flushed session memories are stored in the recording tenant")
+		assert.Equal(t, GranularitySession, memory.Granularity)
+	}
+}
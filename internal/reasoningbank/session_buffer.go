@@ -1,9 +1,12 @@
 package reasoningbank
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
 // TurnEntry represents a single turn buffered for session summarization.
@@ -21,6 +24,11 @@ type SessionBuffer struct {
 	ProjectID   string
 	SessionDate time.Time
 	Turns       []TurnEntry
+
+	// tenant and requester are captured from the first buffered turn, so a
+	// flush without a request (at shutdown) writes where the turns came from.
+	tenant    *vectorstore.TenantInfo
+	requester vectorstore.Requester
 }
 
 // SessionBufferManager manages in-memory buffers for session-level memory accumulation.
@@ -141,3 +149,33 @@ func (m *SessionBufferManager) ActiveSessions() int {
 	defer m.mu.RUnlock()
 	return len(m.buffers)
 }
+
+// captureScope records the tenant and requester of ctx on the session's
+// buffer if it has none yet.
+func (m *SessionBufferManager) captureScope(ctx context.Context, projectID, sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.buffers[bufferKey(projectID, sessionID)]
+	if !ok || buf.tenant != nil {
+		return
+	}
+	if info, err := vectorstore.TenantFromContext(ctx); err == nil {
+		buf.tenant = info
+	}
+	buf.requester = vectorstore.RequesterFromContext(ctx)
+}
+
+// pending returns the sessions with buffered turns, without their turns.
+func (m *SessionBufferManager) pending() []SessionBuffer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]SessionBuffer, 0, len(m.buffers))
+	for _, buf := range m.buffers {
+		cp := *buf
+		cp.Turns = nil
+		out = append(out, cp)
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package reasoningbank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fyrsmithlabs/contextd/internal/project"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// ProjectSettings are per-project ReasoningBank settings, stored in the
+// project's settings collection next to its memories.
+type ProjectSettings struct {
+	ProjectID string `json:"project_id"`
+
+	// ConsolidationThreshold is the similarity threshold scheduled
+	// consolidation uses for the project. Zero means the configured default.
+	ConsolidationThreshold float64 `json:"consolidation_threshold,omitempty"`
+
+	// ConsolidationTuning is the tuning run ConsolidationThreshold was
+	// recommended by, if any.
+	ConsolidationTuning *ThresholdTuning `json:"consolidation_tuning,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// settingsDocumentID is the ID of a project's settings document. It is a
+// UUID because some stores only accept UUID point IDs.
+func settingsDocumentID(projectID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("settings\x00"+projectID)).String()
+}
+
+// ProjectSettings returns the settings of a project. A project that has
+// none yet gets zero-valued settings.
+func (s *Service) ProjectSettings(ctx context.Context, projectID string) (*ProjectSettings, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	store, collectionName, err := s.getCollectionStore(ctx, projectID, project.CollectionSettings)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = s.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &ProjectSettings{ProjectID: projectID}
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("checking collection existence: %w", err)
+	}
+	if !exists {
+		return settings, nil
+	}
+
+	results, err := store.SearchInCollection(ctx, collectionName, "project settings", 1,
+		map[string]interface{}{"id": settingsDocumentID(projectID)})
+	if err != nil {
+		return nil, fmt.Errorf("reading project settings: %w", err)
+	}
+	if len(results) == 0 {
+		return settings, nil
+	}
+	raw, _ := results[0].Metadata["settings"].(string)
+	if err := json.Unmarshal([]byte(raw), settings); err != nil {
+		return nil, fmt.Errorf("decoding project settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveProjectSettings replaces the settings of settings.ProjectID.
+func (s *Service) SaveProjectSettings(ctx context.Context, settings *ProjectSettings) error {
+	if settings.ProjectID == "" {
+		return ErrEmptyProjectID
+	}
+	if settings.ConsolidationThreshold < 0 || settings.ConsolidationThreshold > 1 {
+		return fmt.Errorf("consolidation threshold must be between 0.0 and 1.0, got %f", settings.ConsolidationThreshold)
+	}
+	store, collectionName, err := s.getCollectionStore(ctx, settings.ProjectID, project.CollectionSettings)
+	if err != nil {
+		return err
+	}
+	ctx, err = s.withTenant(ctx, settings.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	id := settingsDocumentID(settings.ProjectID)
+	exists, err := store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("checking collection existence: %w", err)
+	}
+	if !exists {
+		if err := store.CreateCollection(ctx, collectionName, 0); err != nil {
+			return fmt.Errorf("creating collection: %w", err)
+		}
+	} else if err := store.DeleteDocumentsFromCollection(ctx, collectionName, []string{id}); err != nil {
+		return fmt.Errorf("replacing project settings: %w", err)
+	}
+
+	settings.UpdatedAt = time.Now()
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("encoding project settings: %w", err)
+	}
+	_, err = store.AddDocuments(ctx, []vectorstore.Document{{
+		ID:         id,
+		Content:    "project settings",
+		Collection: collectionName,
+		Metadata: map[string]interface{}{
+			"id":         id,
+			"project_id": settings.ProjectID,
+			"settings":   string(raw),
+		},
+	}})
+	if err != nil {
+		return fmt.Errorf("storing project settings: %w", err)
+	}
+	return nil
+}
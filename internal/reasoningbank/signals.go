@@ -26,6 +26,10 @@ const (
 	SignalOutcome SignalType = "outcome"
 )
 
+// EvidenceWeight multiplies the weight of a recent signal that carries
+// evidence. It does not survive rollup into a SignalAggregate.
+const EvidenceWeight = 1.5
+
 // Signal represents a single confidence event.
 //
 // Signals are recorded when:
@@ -51,6 +55,11 @@ type Signal struct {
 	// SessionID is optional session context for correlation.
 	SessionID string `json:"session_id,omitempty"`
 
+	// Evidence is what the agent actually did with the memory, given with
+	// explicit feedback. Evidence-backed signals weigh EvidenceWeight times
+	// as much as bare ones.
+	Evidence string `json:"evidence,omitempty"`
+
 	// Timestamp is when this signal was recorded.
 	Timestamp time.Time `json:"timestamp"`
 }
@@ -0,0 +1,116 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// Similar returns up to limit memories most similar to memoryID, most
+// similar first ("more like this"). The memory itself and archived memories,
+// which were consolidated into another, are excluded. Relevance is the
+// vector similarity to the memory.
+//
+// Note: This method requires the legacy single-store configuration.
+// When using StoreProvider (database-per-project), use SimilarByProjectID instead.
+func (s *Service) Similar(ctx context.Context, memoryID string, limit int) ([]ScoredMemory, error) {
+	memory, err := s.Get(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.similarTo(ctx, memory, limit)
+}
+
+// SimilarByProjectID returns up to limit memories of projectID most similar
+// to memoryID, excluding the memory itself and archived memories.
+//
+// This is the preferred method when using StoreProvider (database-per-project isolation)
+// as it directly accesses the project's store without enumeration.
+func (s *Service) SimilarByProjectID(ctx context.Context, projectID, memoryID string, limit int) ([]ScoredMemory, error) {
+	memory, err := s.GetByProjectID(ctx, projectID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.similarTo(ctx, memory, limit)
+}
+
+// similarTo finds the nearest neighbors of memory in its project. Memories
+// are embedded as title and content, so searching with the same text
+// queries the memory's own vector.
+func (s *Service) similarTo(ctx context.Context, memory *Memory, limit int) ([]ScoredMemory, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	ctx, err := s.withTenant(ctx, memory.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	store, collectionName, err := s.getStore(ctx, memory.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch extra results to make up for the memory itself and archived
+	// memories, which sit right next to their consolidated memory
+	searchLimit := limit * 3
+	if searchLimit < 30 {
+		searchLimit = 30
+	}
+	if searchLimit > 200 {
+		searchLimit = 200
+	}
+
+	content := fmt.Sprintf("%s\n\n%s", memory.Title, memory.Content)
+	results, err := store.SearchInCollection(ctx, collectionName, content, searchLimit, nil)
+	if err != nil {
+		s.recordError(ctx, "similar", "search_failed")
+		return nil, fmt.Errorf("searching similar memories: %w", err)
+	}
+
+	similar := s.similarResults(memory.ID, results)
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+
+	s.logger.Debug("similar memories found",
+		zap.String("project_id", memory.ProjectID),
+		zap.String("memory_id", memory.ID),
+		zap.Int("limit", limit),
+		zap.Int("results", len(similar)))
+
+	return similar, nil
+}
+
+// similarResults converts neighbors of memoryID to scored memories, dropping
+// the memory itself, duplicates and archived memories, sorted by score.
+func (s *Service) similarResults(memoryID string, results []vectorstore.SearchResult) []ScoredMemory {
+	similar := make([]ScoredMemory, 0, len(results))
+	seen := map[string]bool{memoryID: true}
+	for _, result := range results {
+		m, err := s.resultToMemory(result)
+		if err != nil {
+			s.logger.Warn("skipping invalid memory",
+				zap.String("id", result.ID),
+				zap.Error(err))
+			continue
+		}
+		if seen[m.ID] || m.State != MemoryStateActive {
+			continue
+		}
+		seen[m.ID] = true
+		similar = append(similar, ScoredMemory{Memory: *m, Relevance: float64(result.Score)})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		if similar[i].Relevance != similar[j].Relevance {
+			return similar[i].Relevance > similar[j].Relevance
+		}
+		return similar[i].Memory.ID < similar[j].Memory.ID
+	})
+	return similar
+}
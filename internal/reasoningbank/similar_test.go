@@ -0,0 +1,58 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestService_Similar(t *testing.T) {
+	ctx := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "similar", ProjectID: "similar"})
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 4,
+	}, &keywordEmbedder{keywords: []string{"database", "replica", "kubernetes"}}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("similar"))
+	require.NoError(t, err)
+
+	record := func(title, content string, state MemoryState) *Memory {
+		m, err := NewMemory("similar", title, content, OutcomeSuccess, nil)
+		require.NoError(t, err)
+		m.State = state
+		require.NoError(t, svc.Record(ctx, m))
+		return m
+	}
+	seed := record("Replica lag", "Database replica reads fall behind the primary", MemoryStateActive)
+	near := record("Replica failover", "Promote the database replica with the least lag", MemoryStateActive)
+	mid := record("Vacuum", "Schedule database vacuum off-peak", MemoryStateActive)
+	record("Pod limits", "Raise kubernetes memory limits", MemoryStateActive)
+	record("Replica lag (old)", "Database replica lag source memory", MemoryStateArchived)
+
+	similar, err := svc.SimilarByProjectID(ctx, "similar", seed.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, similar, 2)
+	assert.Equal(t, near.ID, similar[0].Memory.ID)
+	assert.Equal(t, mid.ID, similar[1].Memory.ID)
+	assert.Greater(t, similar[0].Relevance, similar[1].Relevance)
+
+	all, err := svc.Similar(ctx, seed.ID, 10)
+	require.NoError(t, err)
+	ids := make([]string, len(all))
+	for i, sm := range all {
+		ids[i] = sm.Memory.ID
+		assert.NotEqual(t, MemoryStateArchived, sm.Memory.State, "archived memories are excluded")
+	}
+	assert.Len(t, ids, 3)
+	assert.NotContains(t, ids, seed.ID, "the memory itself is excluded")
+
+	_, err = svc.SimilarByProjectID(ctx, "similar", "7d0c4f5e-8a1b-4c2d-9e3f-1a2b3c4d5e6f", 5)
+	assert.ErrorIs(t, err, ErrMemoryNotFound)
+}
@@ -0,0 +1,271 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultSimilarityThreshold is the consolidation threshold used when
+	// neither the caller nor the project's settings choose one.
+	DefaultSimilarityThreshold = 0.8
+
+	// MinTuningMemories is the fewest active memories with embeddings
+	// TuneThreshold needs to judge cluster quality.
+	MinTuningMemories = 10
+
+	// maxTuningMemories caps the memories TuneThreshold scores, since
+	// silhouettes cost O(n²) per threshold. Larger projects are sampled.
+	maxTuningMemories = 1000
+)
+
+// DefaultTuningThresholds are the similarity thresholds TuneThreshold
+// evaluates when none are given.
+var DefaultTuningThresholds = []float64{0.70, 0.75, 0.80, 0.85, 0.90, 0.95}
+
+// ErrNotEnoughMemories is returned by TuneThreshold for projects with fewer
+// than MinTuningMemories active memories.
+var ErrNotEnoughMemories = errors.New("not enough memories to tune the consolidation threshold")
+
+// ThresholdScore is the cluster quality consolidation would produce at one
+// similarity threshold.
+type ThresholdScore struct {
+	Threshold float64 `json:"threshold"`
+
+	// Clusters is the number of clusters formed, and Clustered the number of
+	// memories in them.
+	Clusters  int `json:"clusters"`
+	Clustered int `json:"clustered"`
+
+	// Silhouette is the mean silhouette over all scored memories, from -1
+	// to 1, using cosine distance. Memories left out of every cluster score
+	// 0, so thresholds that merge nothing or everything score near 0 and
+	// well-separated, tight clusters score highest.
+	Silhouette float64 `json:"silhouette"`
+
+	// Cohesion is the mean pairwise similarity within clusters.
+	Cohesion float64 `json:"cohesion"`
+}
+
+// ThresholdTuning is the result of TuneThreshold.
+type ThresholdTuning struct {
+	ProjectID string `json:"project_id"`
+
+	// Memories is the number of memories scored, after sampling.
+	Memories int `json:"memories"`
+
+	// Recommended is the evaluated threshold with the highest silhouette,
+	// preferring the higher threshold on ties, or DefaultSimilarityThreshold
+	// when no threshold produced a positive silhouette.
+	Recommended float64 `json:"recommended"`
+
+	Scores  []ThresholdScore `json:"scores"`
+	TunedAt time.Time        `json:"tuned_at"`
+}
+
+// TuneThreshold scores the clusters consolidation would form at each of
+// thresholds (DefaultTuningThresholds when empty) on the project's active
+// memories and recommends the threshold with the best silhouette. Nothing
+// is changed; save the recommendation with SaveTunedThreshold.
+func (d *Distiller) TuneThreshold(ctx context.Context, projectID string, thresholds []float64) (*ThresholdTuning, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	if len(thresholds) == 0 {
+		thresholds = DefaultTuningThresholds
+	}
+	for _, t := range thresholds {
+		if t <= 0.0 || t >= 1.0 {
+			return nil, fmt.Errorf("tuning thresholds must be between 0.0 and 1.0 exclusive, got %f", t)
+		}
+	}
+
+	memVecs, _, err := d.memoryVectors(ctx, projectID, func(m *Memory) bool {
+		return m.State == MemoryStateActive
+	})
+	if err != nil {
+		return nil, err
+	}
+	memVecs = sampleMemoryVectors(sameDimension(memVecs), maxTuningMemories)
+	if len(memVecs) < MinTuningMemories {
+		return nil, fmt.Errorf("%w: project %s has %d, need %d",
+			ErrNotEnoughMemories, projectID, len(memVecs), MinTuningMemories)
+	}
+
+	index := make(map[string]int, len(memVecs))
+	for i, mv := range memVecs {
+		index[mv.memory.ID] = i
+	}
+	sim := similarityMatrix(memVecs)
+
+	tuning := &ThresholdTuning{
+		ProjectID:   projectID,
+		Memories:    len(memVecs),
+		Recommended: DefaultSimilarityThreshold,
+		Scores:      make([]ThresholdScore, 0, len(thresholds)),
+		TunedAt:     time.Now(),
+	}
+	best := 0.0
+	for _, t := range thresholds {
+		labels := make([]int, len(memVecs))
+		for i := range labels {
+			labels[i] = -1
+		}
+		clusters := d.clusterMemoryVectors(memVecs, t)
+		score := ThresholdScore{Threshold: t, Clusters: len(clusters)}
+		for c, cluster := range clusters {
+			for _, m := range cluster.Members {
+				labels[index[m.ID]] = c
+			}
+			score.Clustered += len(cluster.Members)
+		}
+		score.Silhouette, score.Cohesion = silhouette(sim, labels, len(clusters))
+		tuning.Scores = append(tuning.Scores, score)
+
+		if score.Silhouette > best || (score.Silhouette == best && best > 0 && t > tuning.Recommended) {
+			best = score.Silhouette
+			tuning.Recommended = t
+		}
+	}
+	sort.Slice(tuning.Scores, func(i, j int) bool {
+		return tuning.Scores[i].Threshold < tuning.Scores[j].Threshold
+	})
+
+	d.logger.Info("tuned consolidation threshold",
+		zap.String("project_id", projectID),
+		zap.Int("memories", tuning.Memories),
+		zap.Float64("recommended", tuning.Recommended),
+		zap.Float64("silhouette", best))
+
+	return tuning, nil
+}
+
+// SaveTunedThreshold stores tuning.Recommended in the project's settings,
+// where scheduled consolidation picks it up.
+func (d *Distiller) SaveTunedThreshold(ctx context.Context, tuning *ThresholdTuning) error {
+	settings, err := d.service.ProjectSettings(ctx, tuning.ProjectID)
+	if err != nil {
+		return err
+	}
+	settings.ConsolidationThreshold = tuning.Recommended
+	settings.ConsolidationTuning = tuning
+	return d.service.SaveProjectSettings(ctx, settings)
+}
+
+// projectThreshold returns the project's tuned consolidation threshold, or
+// fallback when it has none or its settings cannot be read.
+func (d *Distiller) projectThreshold(ctx context.Context, projectID string, fallback float64) float64 {
+	settings, err := d.service.ProjectSettings(ctx, projectID)
+	if err != nil {
+		d.logger.Warn("failed to read project settings, using default threshold",
+			zap.String("project_id", projectID),
+			zap.Error(err))
+		return fallback
+	}
+	if settings.ConsolidationThreshold == 0 {
+		return fallback
+	}
+	return settings.ConsolidationThreshold
+}
+
+// sampleMemoryVectors returns at most limit memories, evenly spaced in ID
+// order so repeated runs score the same sample.
+func sampleMemoryVectors(memVecs []memoryWithVector, limit int) []memoryWithVector {
+	if len(memVecs) <= limit {
+		return memVecs
+	}
+	sort.Slice(memVecs, func(i, j int) bool {
+		return memVecs[i].memory.ID < memVecs[j].memory.ID
+	})
+	sample := make([]memoryWithVector, limit)
+	for i := range sample {
+		sample[i] = memVecs[i*len(memVecs)/limit]
+	}
+	return sample
+}
+
+// similarityMatrix returns the pairwise cosine similarities of memVecs.
+func similarityMatrix(memVecs []memoryWithVector) [][]float64 {
+	sim := make([][]float64, len(memVecs))
+	for i := range sim {
+		sim[i] = make([]float64, len(memVecs))
+	}
+	for i := range memVecs {
+		sim[i][i] = 1
+		for j := i + 1; j < len(memVecs); j++ {
+			s := CosineSimilarity(memVecs[i].vector, memVecs[j].vector)
+			sim[i][j], sim[j][i] = s, s
+		}
+	}
+	return sim
+}
+
+// silhouette returns the mean silhouette of a clustering and the mean
+// pairwise similarity within its clusters. labels holds each point's
+// cluster in [0, clusters), or -1 for points in no cluster, which count as
+// singletons: they score 0 and are candidate neighbours of clustered points.
+func silhouette(sim [][]float64, labels []int, clusters int) (score, cohesion float64) {
+	n := len(labels)
+	if n == 0 || clusters == 0 {
+		return 0, 0
+	}
+
+	var total, withinSum float64
+	var withinPairs int
+	for i := 0; i < n; i++ {
+		if labels[i] < 0 {
+			continue
+		}
+
+		// Mean distance to each cluster, and to each unclustered point
+		distSum := make([]float64, clusters)
+		size := make([]int, clusters)
+		nearestSingleton := -1.0
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			dist := 1 - sim[i][j]
+			if labels[j] < 0 {
+				if nearestSingleton < 0 || dist < nearestSingleton {
+					nearestSingleton = dist
+				}
+				continue
+			}
+			distSum[labels[j]] += dist
+			size[labels[j]]++
+		}
+
+		own := labels[i]
+		a := distSum[own] / float64(size[own])
+		withinSum += float64(size[own]) - distSum[own]
+		withinPairs += size[own]
+
+		b := nearestSingleton
+		for c := 0; c < clusters; c++ {
+			if c == own || size[c] == 0 {
+				continue
+			}
+			if mean := distSum[c] / float64(size[c]); b < 0 || mean < b {
+				b = mean
+			}
+		}
+		if b < 0 {
+			// Everything is in one cluster: silhouette is undefined
+			continue
+		}
+		if m := max(a, b); m > 0 {
+			total += (b - a) / m
+		}
+	}
+
+	if withinPairs > 0 {
+		cohesion = withinSum / float64(withinPairs)
+	}
+	return total / float64(n), cohesion
+}
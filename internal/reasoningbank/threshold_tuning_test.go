@@ -0,0 +1,132 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDistiller_TuneThreshold(t *testing.T) {
+	ctx := context.Background()
+	projectID := "test-project"
+
+	svc := &Service{
+		store:         newMockStore(),
+		embedder:      newMockEmbedder(384),
+		logger:        zap.NewNop(),
+		defaultTenant: "test-tenant",
+	}
+	distiller, err := NewDistiller(svc, zap.NewNop())
+	require.NoError(t, err)
+
+	record := func(topic string, i int) {
+		mem, _ := NewMemory(projectID, fmt.Sprintf("%s note %d", topic, i),
+			fmt.Sprintf("%s variant %d of the same advice", topic, i), OutcomeSuccess, nil)
+		require.NoError(t, svc.Record(ctx, mem))
+	}
+	for i := 0; i < 3; i++ {
+		record("Database migrations", i)
+	}
+
+	_, err = distiller.TuneThreshold(ctx, projectID, nil)
+	require.ErrorIs(t, err, ErrNotEnoughMemories)
+
+	for _, topic := range []string{"Database migrations", "Error wrapping", "Flaky tests"} {
+		for i := 3; i < 7; i++ {
+			record(topic, i)
+		}
+	}
+
+	tuning, err := distiller.TuneThreshold(ctx, projectID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, projectID, tuning.ProjectID)
+	assert.Equal(t, 15, tuning.Memories)
+	require.Len(t, tuning.Scores, len(DefaultTuningThresholds))
+	assert.Contains(t, DefaultTuningThresholds, tuning.Recommended)
+
+	var recommended ThresholdScore
+	for i, s := range tuning.Scores {
+		if i > 0 {
+			assert.Greater(t, s.Threshold, tuning.Scores[i-1].Threshold)
+		}
+		if s.Threshold == tuning.Recommended {
+			recommended = s
+		}
+	}
+	for _, s := range tuning.Scores {
+		assert.LessOrEqual(t, s.Silhouette, recommended.Silhouette)
+	}
+	assert.Equal(t, 3, recommended.Clusters, "one cluster per topic")
+	assert.Greater(t, recommended.Silhouette, 0.5)
+	assert.Greater(t, recommended.Cohesion, 0.9)
+
+	t.Run("saved threshold becomes the project default", func(t *testing.T) {
+		assert.Equal(t, 0.8, distiller.projectThreshold(ctx, projectID, 0.8))
+
+		tuning.Recommended = 0.9
+		require.NoError(t, distiller.SaveTunedThreshold(ctx, tuning))
+
+		settings, err := svc.ProjectSettings(ctx, projectID)
+		require.NoError(t, err)
+		assert.Equal(t, 0.9, settings.ConsolidationThreshold)
+		require.NotNil(t, settings.ConsolidationTuning)
+		assert.Len(t, settings.ConsolidationTuning.Scores, len(DefaultTuningThresholds))
+		assert.Equal(t, 0.9, distiller.projectThreshold(ctx, projectID, 0.8))
+		assert.Equal(t, 0.8, distiller.projectThreshold(ctx, "other-project", 0.8))
+	})
+
+	t.Run("validates thresholds", func(t *testing.T) {
+		_, err := distiller.TuneThreshold(ctx, "", nil)
+		assert.Equal(t, ErrEmptyProjectID, err)
+
+		_, err = distiller.TuneThreshold(ctx, projectID, []float64{0.8, 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestSilhouette(t *testing.T) {
+	// Two tight pairs far from each other
+	sim := [][]float64{
+		{1, 0.9, 0.1, 0.1},
+		{0.9, 1, 0.1, 0.1},
+		{0.1, 0.1, 1, 0.9},
+		{0.1, 0.1, 0.9, 1},
+	}
+
+	score, cohesion := silhouette(sim, []int{0, 0, 1, 1}, 2)
+	assert.InDelta(t, (0.9-0.1)/0.9, score, 1e-9)
+	assert.InDelta(t, 0.9, cohesion, 1e-9)
+
+	// Merging both pairs gives no separation to measure
+	score, _ = silhouette(sim, []int{0, 0, 0, 0}, 1)
+	assert.Zero(t, score)
+
+	// Clustering nothing scores 0
+	score, cohesion = silhouette(sim, []int{-1, -1, -1, -1}, 0)
+	assert.Zero(t, score)
+	assert.Zero(t, cohesion)
+
+	// One pair clustered: the other two count as singletons
+	score, _ = silhouette(sim, []int{0, 0, -1, -1}, 1)
+	assert.InDelta(t, 2*(0.9-0.1)/0.9/4, score, 1e-9)
+}
+
+func TestSampleMemoryVectors(t *testing.T) {
+	memVecs := make([]memoryWithVector, 10)
+	for i := range memVecs {
+		memVecs[i] = memoryWithVector{memory: &Memory{ID: fmt.Sprintf("m%02d", 9-i)}}
+	}
+
+	assert.Len(t, sampleMemoryVectors(memVecs, 20), 10)
+
+	sample := sampleMemoryVectors(memVecs, 5)
+	ids := make([]string, len(sample))
+	for i, mv := range sample {
+		ids[i] = mv.memory.ID
+	}
+	assert.Equal(t, []string{"m00", "m02", "m04", "m06", "m08"}, ids)
+}
@@ -6,17 +6,32 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
 // Common errors for ReasoningBank operations.
 var (
-	ErrMemoryNotFound    = errors.New("memory not found")
-	ErrInvalidMemory     = errors.New("invalid memory")
-	ErrEmptyTitle        = errors.New("memory title cannot be empty")
-	ErrEmptyContent      = errors.New("memory content cannot be empty")
-	ErrInvalidConfidence = errors.New("confidence must be between 0.0 and 1.0")
-	ErrInvalidOutcome    = errors.New("outcome must be 'success' or 'failure'")
-	ErrEmptyProjectID    = errors.New("project ID cannot be empty")
+	ErrMemoryNotFound    = ctxerrors.NotFound("memory not found")
+	ErrInvalidMemory     = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid memory")
+	ErrEmptyTitle        = ctxerrors.New(ctxerrors.CodeInvalidInput, "memory title cannot be empty")
+	ErrEmptyContent      = ctxerrors.New(ctxerrors.CodeInvalidInput, "memory content cannot be empty")
+	ErrInvalidConfidence = ctxerrors.New(ctxerrors.CodeInvalidInput, "confidence must be between 0.0 and 1.0")
+	ErrInvalidOutcome    = ctxerrors.New(ctxerrors.CodeInvalidInput, "outcome must be 'success' or 'failure'")
+	ErrEmptyProjectID    = ctxerrors.New(ctxerrors.CodeInvalidInput, "project ID cannot be empty")
+
+	// ErrClusterChanged is returned by MergeMembers when fewer than two of
+	// the cluster's memories are still active.
+	ErrClusterChanged = ctxerrors.New(ctxerrors.CodeInvalidInput, "cluster has fewer than two active memories")
+
+	// ErrNotConsolidated is returned by Undo for a memory no archived
+	// memories are linked to.
+	ErrNotConsolidated = ctxerrors.New(ctxerrors.CodeInvalidInput, "memory has no consolidated source memories")
+
+	// ErrStopStream may be returned by a StreamMemories callback to stop
+	// iteration early. StreamMemories then returns nil.
+	ErrStopStream = errors.New("stop stream")
 )
 
 // Outcome represents the result type of a memory.
@@ -40,6 +55,11 @@ const (
 	// MemoryStateArchived indicates the memory has been consolidated into another memory.
 	// Archived memories are preserved for attribution but excluded from normal searches.
 	MemoryStateArchived MemoryState = "archived"
+
+	// MemoryStateQuarantined indicates the memory looked like a prompt-injection
+	// payload when it was recorded. Quarantined memories are excluded from
+	// searches until released with ReleaseQuarantine.
+	MemoryStateQuarantined MemoryState = "quarantined"
 )
 
 // MemoryGranularity indicates the granularity at which a memory was stored.
@@ -87,12 +107,36 @@ type Memory struct {
 	// Adjusted based on feedback and usage patterns.
 	Confidence float64 `json:"confidence"`
 
+	// ConfidenceHistory records every confidence adjustment, oldest first,
+	// capped at MaxConfidenceHistory entries. It is persisted with the
+	// memory but left out of its JSON; read it with
+	// Service.ConfidenceHistory.
+	ConfidenceHistory []ConfidenceChange `json:"-"`
+
 	// UsageCount tracks how many times this memory has been retrieved.
 	UsageCount int `json:"usage_count"`
 
+	// Revision counts the writes of the stored memory, starting at 1. An
+	// update of a memory read at an older revision fails with
+	// ErrRevisionConflict instead of overwriting the newer write. Memories
+	// stored before revisions were tracked read as revision 0.
+	Revision int64 `json:"revision"`
+
+	// Importance is a score from 0.0 to 1.0 indicating how much this memory
+	// matters, learned from how often it is used in successful sessions.
+	// Unlike Confidence it says nothing about correctness; it is a secondary
+	// ranking signal and decides which memories survive pruning.
+	Importance float64 `json:"importance"`
+
 	// Tags are labels for categorization (e.g., "go", "error-handling", "auth").
 	Tags []string `json:"tags,omitempty"`
 
+	// Languages are the languages or stacks the memory is about (e.g., "go",
+	// "terraform"). Inferred from tags and content on Record when empty.
+	// Searches in a polyglot project prefer memories in the query's
+	// languages; memories without languages apply to any language.
+	Languages []string `json:"languages,omitempty"`
+
 	// ConsolidationID links this memory to a consolidated memory it was merged into.
 	// When a memory is consolidated with others, this field is set to the ID of the
 	// resulting ConsolidatedMemory. The original memory is preserved for attribution.
@@ -103,6 +147,10 @@ type Memory struct {
 	// for attribution and traceability. They are excluded from normal searches.
 	State MemoryState `json:"state"`
 
+	// QuarantineRules are the injection rules (see package injection) that
+	// flagged a quarantined memory.
+	QuarantineRules []string `json:"quarantine_rules,omitempty"`
+
 	// SessionID links this memory to the session that produced it.
 	// Empty for turn-granularity memories recorded individually.
 	SessionID string `json:"session_id,omitempty"`
@@ -115,6 +163,23 @@ type Memory struct {
 	// Defaults to GranularityTurn for backward compatibility.
 	Granularity MemoryGranularity `json:"granularity,omitempty"`
 
+	// Visibility limits who in the tenant can find this memory: private
+	// memories are only returned to OwnerID, team memories to the owner's
+	// team. Empty means org, visible to the whole tenant.
+	Visibility vectorstore.Visibility `json:"visibility,omitempty"`
+
+	// OwnerID is the user who recorded the memory, taken from the request
+	// context (see vectorstore.Requester). Only the owner can change
+	// Visibility.
+	OwnerID string `json:"owner_id,omitempty"`
+
+	// OwnerTeam is the owner's team, which can find team memories.
+	OwnerTeam string `json:"owner_team,omitempty"`
+
+	// Origin is the conversation message that recorded the memory, linked
+	// when the conversation is indexed. Nil until then.
+	Origin *ConversationOrigin `json:"origin,omitempty"`
+
 	// CreatedAt is when the memory was created.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -129,6 +194,17 @@ type Memory struct {
 type ScoredMemory struct {
 	Memory    Memory  `json:"memory"`
 	Relevance float64 `json:"relevance"`
+
+	// Highlight is the best-matching chunk of a long memory, if any.
+	Highlight *ChunkHighlight `json:"highlight,omitempty"`
+}
+
+// MemoryPage is one page of a paginated memory search.
+type MemoryPage struct {
+	Memories   []ScoredMemory  `json:"memories"`
+	Metadata   *SearchMetadata `json:"metadata"`
+	NextCursor string          `json:"next_cursor,omitempty"` // Empty on the last page
+	SubQueries []string        `json:"sub_queries,omitempty"` // Queries searched by multi-query search
 }
 
 // SearchMetadata provides insights into search quality and suggestions for refinement.
@@ -207,12 +283,15 @@ func (m *Memory) Validate() error {
 	if m.UsageCount < 0 {
 		return errors.New("usage count cannot be negative")
 	}
-	if m.State != MemoryStateActive && m.State != MemoryStateArchived {
-		return errors.New("state must be 'active' or 'archived'")
+	if m.State != MemoryStateActive && m.State != MemoryStateArchived && m.State != MemoryStateQuarantined {
+		return errors.New("state must be 'active', 'archived' or 'quarantined'")
 	}
 	if m.Granularity != "" && m.Granularity != GranularityTurn && m.Granularity != GranularitySession {
 		return errors.New("granularity must be 'turn' or 'session'")
 	}
+	if _, err := vectorstore.ParseVisibility(string(m.Visibility)); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -238,6 +317,101 @@ func (m *Memory) AdjustConfidence(helpful bool) {
 	m.UpdatedAt = time.Now()
 }
 
+// MaxConfidenceHistory is how many confidence changes a memory keeps; the
+// oldest are dropped first.
+const MaxConfidenceHistory = 100
+
+// ConfidenceCause is why a memory's confidence changed.
+type ConfidenceCause string
+
+const (
+	// CauseRecorded is the initial confidence of a new memory.
+	CauseRecorded ConfidenceCause = "recorded"
+
+	// CauseFeedbackHelpful and CauseFeedbackUnhelpful come from
+	// memory_feedback.
+	CauseFeedbackHelpful   ConfidenceCause = "feedback_helpful"
+	CauseFeedbackUnhelpful ConfidenceCause = "feedback_unhelpful"
+
+	// CauseOutcomeSuccess and CauseOutcomeFailure come from memory_outcome.
+	CauseOutcomeSuccess ConfidenceCause = "outcome_success"
+	CauseOutcomeFailure ConfidenceCause = "outcome_failure"
+)
+
+// Source groups causes by the kind of signal behind them: "feedback" for
+// memory_feedback, "outcome" for memory_outcome, and the cause itself
+// otherwise.
+func (c ConfidenceCause) Source() string {
+	switch c {
+	case CauseFeedbackHelpful, CauseFeedbackUnhelpful:
+		return "feedback"
+	case CauseOutcomeSuccess, CauseOutcomeFailure:
+		return "outcome"
+	default:
+		return string(c)
+	}
+}
+
+// ThresholdDirection is which way a confidence change crossed MinConfidence.
+type ThresholdDirection string
+
+const (
+	// ThresholdPromoted is a memory rising to MinConfidence, so search
+	// returns it again.
+	ThresholdPromoted ThresholdDirection = "promoted"
+
+	// ThresholdFilteredOut is a memory falling below MinConfidence, so
+	// search stops returning it.
+	ThresholdFilteredOut ThresholdDirection = "filtered_out"
+)
+
+// thresholdCrossing reports whether a change from previous to current
+// confidence moves a memory across MinConfidence, and in which direction.
+func thresholdCrossing(previous, current float64) (ThresholdDirection, bool) {
+	switch {
+	case previous < MinConfidence && current >= MinConfidence:
+		return ThresholdPromoted, true
+	case previous >= MinConfidence && current < MinConfidence:
+		return ThresholdFilteredOut, true
+	default:
+		return "", false
+	}
+}
+
+// ConfidenceChange is one entry of a memory's confidence history.
+type ConfidenceChange struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Cause     ConfidenceCause `json:"cause"`
+
+	// Delta is the change from the previous confidence, zero for
+	// CauseRecorded.
+	Delta float64 `json:"delta"`
+
+	// Confidence is the confidence after the change.
+	Confidence float64 `json:"confidence"`
+
+	// Evidence is what the agent did with the memory, when feedback
+	// included it.
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// recordConfidenceChange appends the change from previous to the current
+// confidence to the memory's history.
+func (m *Memory) recordConfidenceChange(cause ConfidenceCause, previous float64, evidence string) {
+	history := m.ConfidenceHistory
+	if len(history) >= MaxConfidenceHistory {
+		// Copy rather than reslice so a caller's saved history is untouched
+		history = append([]ConfidenceChange(nil), history[len(history)-MaxConfidenceHistory+1:]...)
+	}
+	m.ConfidenceHistory = append(history, ConfidenceChange{
+		Timestamp:  time.Now(),
+		Cause:      cause,
+		Delta:      m.Confidence - previous,
+		Confidence: m.Confidence,
+		Evidence:   evidence,
+	})
+}
+
 // IncrementUsage increments the usage count and updates timestamp.
 func (m *Memory) IncrementUsage() {
 	m.UsageCount++
@@ -325,6 +499,15 @@ type ConsolidationResult struct {
 
 	// Duration is how long the consolidation operation took to complete.
 	Duration time.Duration `json:"duration"`
+
+	// ProposalIDs lists the consolidation proposals created when
+	// RequireApproval was set. Their clusters are not merged until approved.
+	ProposalIDs []string `json:"proposal_ids,omitempty"`
+
+	// Warnings explain why consolidation stopped early or skipped clusters,
+	// e.g. a paused run because the tenant's LLM budget is exhausted or a
+	// memory too large for the consolidation prompt.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // ConsolidationOptions configures the behavior of memory consolidation operations.
@@ -353,6 +536,36 @@ type ConsolidationOptions struct {
 	// all memories for consolidation, even if they were recently processed.
 	// Use this to force a complete re-consolidation of the project's memory base.
 	ForceAll bool `json:"force_all"`
+
+	// Tags, when set, restricts consolidation to memories carrying at least
+	// one of these tags.
+	Tags []string `json:"tags,omitempty"`
+
+	// Outcome, when set, restricts consolidation to memories with this outcome.
+	Outcome Outcome `json:"outcome,omitempty"`
+
+	// Query, when set, restricts consolidation to the QueryLimit memories most
+	// relevant to it (e.g. "testing" to tidy up only testing-related memories).
+	Query string `json:"query,omitempty"`
+
+	// QueryLimit caps how many memories Query selects. Default: 100
+	QueryLimit int `json:"query_limit,omitempty"`
+
+	// RequireApproval, when true, saves each cluster as a pending
+	// ConsolidationProposal instead of merging it. Only proposals approved
+	// via Distiller.ReviewProposal are merged.
+	RequireApproval bool `json:"require_approval,omitempty"`
+
+	// UseProjectThreshold, when true, makes projects whose settings hold a
+	// tuned threshold (see Distiller.TuneThreshold) use it instead of
+	// SimilarityThreshold. Scheduled runs always set it.
+	UseProjectThreshold bool `json:"use_project_threshold,omitempty"`
+}
+
+// Scoped reports whether the options restrict consolidation to a subset of
+// the project's memories.
+func (o ConsolidationOptions) Scoped() bool {
+	return len(o.Tags) > 0 || o.Outcome != "" || o.Query != ""
 }
 
 // MemoryConsolidator defines the interface for memory consolidation operations.
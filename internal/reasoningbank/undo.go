@@ -0,0 +1,129 @@
+package reasoningbank
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// UndoOptions controls how Undo disposes of a consolidated memory.
+type UndoOptions struct {
+	// Delete removes the consolidated memory instead of archiving it.
+	Delete bool
+
+	// Reason is recorded in the audit entry, e.g. "merged unrelated fixes".
+	Reason string
+}
+
+// UndoResult describes a reverted consolidation.
+type UndoResult struct {
+	ConsolidatedID string `json:"consolidated_id"`
+
+	// RestoredIDs are the source memories made active again.
+	RestoredIDs []string `json:"restored_ids"`
+
+	// Deleted reports whether the consolidated memory was deleted rather
+	// than archived.
+	Deleted bool `json:"deleted"`
+
+	// AuditID is the audit entry recording the undo.
+	AuditID string `json:"audit_id"`
+}
+
+// Undo reverts a consolidation whose merged memory turned out to be wrong.
+// The archived source memories linked to consolidatedID are made active
+// again and unlinked, the consolidated memory is archived (or deleted with
+// opts.Delete), and the undo is recorded in the project's audit log.
+//
+// Sources are restored first, so a failure part way through never loses
+// knowledge; calling Undo again finishes the job. Undo does not stop a
+// later consolidation run from merging the restored memories again.
+func (d *Distiller) Undo(ctx context.Context, projectID, consolidatedID string, opts UndoOptions) (*UndoResult, error) {
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+	ctx, err := d.service.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	consolidated, err := d.service.GetByProjectID(ctx, projectID, consolidatedID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []Memory
+	err = d.service.StreamMemories(ctx, projectID, 0, func(m Memory) error {
+		if m.ConsolidationID != nil && *m.ConsolidationID == consolidatedID {
+			sources = append(sources, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding source memories: %w", err)
+	}
+	if len(sources) == 0 {
+		return nil, ErrNotConsolidated
+	}
+
+	result := &UndoResult{
+		ConsolidatedID: consolidatedID,
+		RestoredIDs:    make([]string, 0, len(sources)),
+		Deleted:        opts.Delete,
+	}
+	for i := range sources {
+		source := &sources[i]
+		source.ConsolidationID = nil
+		source.State = MemoryStateActive
+		source.UpdatedAt = time.Now()
+		if err := d.service.replaceMemory(ctx, projectID, source); err != nil {
+			return nil, fmt.Errorf("restoring source memory %s: %w", source.ID, err)
+		}
+		// Archiving dropped the source's chunks; long ones need them back
+		if err := d.service.recordChunks(ctx, source); err != nil {
+			d.logger.Warn("failed to chunk restored memory",
+				zap.String("id", source.ID),
+				zap.Error(err))
+		}
+		result.RestoredIDs = append(result.RestoredIDs, source.ID)
+	}
+
+	if opts.Delete {
+		err = d.service.DeleteByProjectID(ctx, projectID, consolidatedID)
+	} else {
+		consolidated.State = MemoryStateArchived
+		consolidated.UpdatedAt = time.Now()
+		err = d.service.replaceMemory(ctx, projectID, consolidated)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("removing consolidated memory: %w", err)
+	}
+
+	entry := &AuditEntry{
+		ProjectID:  projectID,
+		Action:     AuditConsolidationUndone,
+		MemoryID:   consolidatedID,
+		RelatedIDs: result.RestoredIDs,
+		Detail:     opts.Reason,
+	}
+	if err := d.service.recordAudit(ctx, entry); err != nil {
+		// The undo itself succeeded; don't report it as failed
+		d.logger.Error("failed to record consolidation undo in audit log",
+			zap.String("project_id", projectID),
+			zap.String("consolidated_id", consolidatedID),
+			zap.Error(err))
+	} else {
+		result.AuditID = entry.ID
+	}
+
+	d.logger.Info("consolidation undone",
+		zap.String("project_id", projectID),
+		zap.String("consolidated_id", consolidatedID),
+		zap.String("title", consolidated.Title),
+		zap.Strings("restored_ids", result.RestoredIDs),
+		zap.Bool("deleted", opts.Delete))
+
+	return result, nil
+}
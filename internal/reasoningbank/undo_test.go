@@ -0,0 +1,87 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDistiller_Undo(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	projectID := "undo-project"
+
+	setup := func(t *testing.T) (*Service, *Distiller, *Memory, []string) {
+		svc, err := NewService(newMockStore(), logger,
+			WithDefaultTenant("test-tenant"),
+			WithEmbedder(newMockEmbedder(10)))
+		require.NoError(t, err)
+		distiller, err := NewDistiller(svc, logger, WithLLMClient(newMockLLMClient()))
+		require.NoError(t, err)
+
+		mem1, _ := NewMemory(projectID, "Go Error Pattern 1", "Always wrap errors", OutcomeSuccess, nil)
+		mem2, _ := NewMemory(projectID, "Go Error Pattern 2", "Use fmt.Errorf for wrapping", OutcomeSuccess, nil)
+		require.NoError(t, svc.Record(ctx, mem1))
+		require.NoError(t, svc.Record(ctx, mem2))
+
+		consolidated, err := distiller.MergeCluster(ctx, &SimilarityCluster{Members: []*Memory{mem1, mem2}})
+		require.NoError(t, err)
+		return svc, distiller, consolidated, []string{mem1.ID, mem2.ID}
+	}
+
+	t.Run("restores sources and archives the merge", func(t *testing.T) {
+		svc, distiller, consolidated, sourceIDs := setup(t)
+
+		result, err := distiller.Undo(ctx, projectID, consolidated.ID, UndoOptions{Reason: "hallucinated API"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, sourceIDs, result.RestoredIDs)
+		assert.False(t, result.Deleted)
+		assert.NotEmpty(t, result.AuditID)
+
+		for _, id := range sourceIDs {
+			m, err := svc.GetByProjectID(ctx, projectID, id)
+			require.NoError(t, err)
+			assert.Equal(t, MemoryStateActive, m.State)
+			assert.Nil(t, m.ConsolidationID)
+		}
+		m, err := svc.GetByProjectID(ctx, projectID, consolidated.ID)
+		require.NoError(t, err)
+		assert.Equal(t, MemoryStateArchived, m.State)
+
+		log, err := svc.AuditLog(ctx, projectID)
+		require.NoError(t, err)
+		require.Len(t, log, 1)
+		assert.Equal(t, result.AuditID, log[0].ID)
+		assert.Equal(t, AuditConsolidationUndone, log[0].Action)
+		assert.Equal(t, consolidated.ID, log[0].MemoryID)
+		assert.ElementsMatch(t, sourceIDs, log[0].RelatedIDs)
+		assert.Equal(t, "hallucinated API", log[0].Detail)
+
+		_, err = distiller.Undo(ctx, projectID, consolidated.ID, UndoOptions{})
+		assert.ErrorIs(t, err, ErrNotConsolidated)
+	})
+
+	t.Run("deletes the merge", func(t *testing.T) {
+		svc, distiller, consolidated, _ := setup(t)
+
+		result, err := distiller.Undo(ctx, projectID, consolidated.ID, UndoOptions{Delete: true})
+		require.NoError(t, err)
+		assert.True(t, result.Deleted)
+
+		_, err = svc.GetByProjectID(ctx, projectID, consolidated.ID)
+		assert.ErrorIs(t, err, ErrMemoryNotFound)
+	})
+
+	t.Run("rejects unknown memories", func(t *testing.T) {
+		_, distiller, _, sourceIDs := setup(t)
+
+		_, err := distiller.Undo(ctx, "", sourceIDs[0], UndoOptions{})
+		assert.Equal(t, ErrEmptyProjectID, err)
+
+		_, err = distiller.Undo(ctx, projectID, "7d9c2c5e-2f55-4a7f-9a44-5f0c6c1b7e21", UndoOptions{})
+		assert.ErrorIs(t, err, ErrMemoryNotFound)
+	})
+}
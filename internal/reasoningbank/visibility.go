@@ -0,0 +1,64 @@
+package reasoningbank
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// ErrNotMemoryOwner is returned when someone other than a memory's owner
+// tries to change its visibility.
+var ErrNotMemoryOwner = ctxerrors.Unauthorized("only the memory's owner can change its visibility")
+
+// SetVisibility changes who in the tenant can find a memory, e.g. sharing a
+// private memory with the team once it has proven itself. Only the user who
+// recorded the memory (its OwnerID) may change it; memories recorded without
+// a user have no owner and keep their visibility.
+func (s *Service) SetVisibility(ctx context.Context, projectID, memoryID string, visibility vectorstore.Visibility) (*Memory, error) {
+	v, err := vectorstore.ParseVisibility(string(visibility))
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = s.withTenant(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	memory, err := s.GetByProjectID(ctx, projectID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.OwnerID == "" || memory.OwnerID != vectorstore.RequesterFromContext(ctx).UserID {
+		return nil, ErrNotMemoryOwner
+	}
+	if err := checkExpectedRevision(ctx, memory); err != nil {
+		return nil, err
+	}
+	if current, _ := vectorstore.ParseVisibility(string(memory.Visibility)); current == v {
+		return memory, nil
+	}
+
+	previous := memory.Visibility
+	memory.Visibility = v
+	memory.UpdatedAt = time.Now()
+	if err := s.replaceMemory(ctx, projectID, memory); err != nil {
+		return nil, err
+	}
+
+	// Chunks carry the label too; the memory itself is already relabelled
+	if err := s.recordChunks(ctx, memory); err != nil {
+		s.logger.Warn("failed to relabel memory chunks",
+			zap.String("id", memoryID),
+			zap.Error(err))
+	}
+
+	s.logger.Info("memory visibility changed",
+		zap.String("id", memoryID),
+		zap.String("from", string(previous)),
+		zap.String("to", string(v)))
+	return memory, nil
+}
@@ -0,0 +1,70 @@
+package reasoningbank
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+func TestService_SetVisibility(t *testing.T) {
+	store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+		Path:       t.TempDir(),
+		VectorSize: 3,
+	}, &keywordEmbedder{keywords: []string{"database", "kubernetes"}}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("acme"))
+	require.NoError(t, err)
+
+	projectID := "app"
+	tenant := vectorstore.ContextWithTenant(context.Background(), &vectorstore.TenantInfo{TenantID: "acme", ProjectID: projectID})
+	alice := vectorstore.ContextWithRequester(tenant, vectorstore.Requester{UserID: "alice", TeamID: "core"})
+	bob := vectorstore.ContextWithRequester(tenant, vectorstore.Requester{UserID: "bob", TeamID: "core"})
+	carol := vectorstore.ContextWithRequester(tenant, vectorstore.Requester{UserID: "carol", TeamID: "infra"})
+
+	memory, err := NewMemory(projectID, "database experiment", "Try partial indexes on the database", OutcomeSuccess, nil)
+	require.NoError(t, err)
+	memory.Visibility = vectorstore.VisibilityPrivate
+	require.NoError(t, svc.Record(alice, memory))
+	assert.Equal(t, "alice", memory.OwnerID)
+
+	found := func(ctx context.Context) bool {
+		t.Helper()
+		results, err := svc.SearchWithScores(ctx, projectID, "database", 10)
+		require.NoError(t, err)
+		for _, r := range results {
+			if r.Memory.ID == memory.ID {
+				return true
+			}
+		}
+		return false
+	}
+	assert.True(t, found(alice))
+	assert.False(t, found(bob), "private memories are hidden from teammates")
+
+	_, err = svc.SetVisibility(bob, projectID, memory.ID, vectorstore.VisibilityOrg)
+	assert.ErrorIs(t, err, ErrMemoryNotFound, "bob cannot even see it")
+
+	shared, err := svc.SetVisibility(alice, projectID, memory.ID, vectorstore.VisibilityTeam)
+	require.NoError(t, err)
+	assert.Equal(t, vectorstore.VisibilityTeam, shared.Visibility)
+	assert.True(t, found(bob), "team memories reach the owner's team")
+	assert.False(t, found(carol))
+
+	_, err = svc.SetVisibility(alice, projectID, memory.ID, vectorstore.VisibilityOrg)
+	require.NoError(t, err)
+	assert.True(t, found(bob))
+	assert.True(t, found(carol))
+
+	_, err = svc.SetVisibility(bob, projectID, memory.ID, vectorstore.VisibilityPrivate)
+	assert.ErrorIs(t, err, ErrNotMemoryOwner, "only the owner can restrict it again")
+
+	_, err = svc.SetVisibility(alice, projectID, memory.ID, "public")
+	assert.ErrorIs(t, err, vectorstore.ErrInvalidVisibility)
+}
@@ -0,0 +1,302 @@
+package reasoningbank
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// DefaultWriteBatchInterval is how often batched memory updates are
+	// written when WriteBatchConfig.FlushInterval is zero.
+	DefaultWriteBatchInterval = 5 * time.Second
+
+	// DefaultWriteBatchSize is the number of memories with pending updates
+	// that triggers an early flush when WriteBatchConfig.MaxPending is zero.
+	DefaultWriteBatchSize = 100
+)
+
+// WriteBatchConfig configures write-behind batching of feedback and outcome
+// updates.
+type WriteBatchConfig struct {
+	// FlushInterval is how often pending updates are written.
+	FlushInterval time.Duration
+
+	// MaxPending flushes early once this many memories have pending
+	// updates.
+	MaxPending int
+}
+
+// WithWriteBatching coalesces the confidence updates of feedback and
+// outcomes per memory and writes them on an interval or once cfg.MaxPending
+// memories are pending, instead of rewriting the memory document on every
+// event. Signals are still stored immediately, and Get,
+// GetByProjectID and Search see pending updates.
+//
+// Call StartWriteBatching to flush in the background and StopWriteBatching
+// at shutdown; updates still pending when the process dies are lost.
+func WithWriteBatching(cfg WriteBatchConfig) ServiceOption {
+	return func(s *Service) {
+		if cfg.FlushInterval <= 0 {
+			cfg.FlushInterval = DefaultWriteBatchInterval
+		}
+		if cfg.MaxPending <= 0 {
+			cfg.MaxPending = DefaultWriteBatchSize
+		}
+		s.writes = &writeBuffer{
+			config:  cfg,
+			pending: make(map[string]*pendingWrite),
+			flushCh: make(chan struct{}, 1),
+			stopCh:  make(chan struct{}),
+			doneCh:  make(chan struct{}),
+		}
+	}
+}
+
+// pendingWrite is the coalesced update of one memory since the last flush.
+type pendingWrite struct {
+	projectID string
+	tenant    *vectorstore.TenantInfo
+	requester vectorstore.Requester
+
+	// confidence and importance are absolute, as computed by the last
+	// event; history holds the changes made since the last flush.
+	hasConfidence bool
+	confidence    float64
+	importance    float64
+	history       []ConfidenceChange
+
+	updatedAt time.Time
+}
+
+// apply writes the update to memory.
+func (p *pendingWrite) apply(memory *Memory) {
+	if p.hasConfidence {
+		memory.Confidence = p.confidence
+		memory.Importance = p.importance
+		history := append(append([]ConfidenceChange(nil), memory.ConfidenceHistory...), p.history...)
+		if len(history) > MaxConfidenceHistory {
+			history = history[len(history)-MaxConfidenceHistory:]
+		}
+		memory.ConfidenceHistory = history
+	}
+	if p.updatedAt.After(memory.UpdatedAt) {
+		memory.UpdatedAt = p.updatedAt
+	}
+}
+
+// merge folds a newer update for the same memory into p.
+func (p *pendingWrite) merge(newer *pendingWrite) {
+	if newer.hasConfidence {
+		p.hasConfidence = true
+		p.confidence = newer.confidence
+		p.importance = newer.importance
+		p.history = append(p.history, newer.history...)
+	}
+	if newer.updatedAt.After(p.updatedAt) {
+		p.updatedAt = newer.updatedAt
+	}
+	if newer.tenant != nil {
+		p.tenant, p.requester = newer.tenant, newer.requester
+	}
+}
+
+// writeBuffer holds memory updates until they are flushed.
+type writeBuffer struct {
+	config WriteBatchConfig
+
+	mu      sync.Mutex
+	pending map[string]*pendingWrite // keyed by memory ID
+	flushMu sync.Mutex               // serializes flushes
+
+	flushCh  chan struct{}
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// add queues update for a memory, signalling an early flush when the buffer
+// is full.
+func (b *writeBuffer) add(ctx context.Context, memoryID string, update *pendingWrite) {
+	if info, err := vectorstore.TenantFromContext(ctx); err == nil {
+		update.tenant = info
+		update.requester = vectorstore.RequesterFromContext(ctx)
+	}
+
+	b.mu.Lock()
+	if p, ok := b.pending[memoryID]; ok {
+		p.merge(update)
+	} else {
+		b.pending[memoryID] = update
+	}
+	full := len(b.pending) >= b.config.MaxPending
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// overlay applies the pending update of memory, if any. It is nil-safe so
+// callers need not check whether batching is enabled.
+func (b *writeBuffer) overlay(memory *Memory) *Memory {
+	if b == nil || memory == nil {
+		return memory
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p, ok := b.pending[memory.ID]; ok {
+		p.apply(memory)
+	}
+	return memory
+}
+
+// discard drops the pending update of a memory that was deleted or
+// rewritten with its pending update applied.
+func (b *writeBuffer) discard(memoryID string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	delete(b.pending, memoryID)
+	b.mu.Unlock()
+}
+
+// size returns the number of memories with pending updates.
+func (b *writeBuffer) size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// queueConfidenceChange batches the confidence change just recorded on
+// memory by feedback or an outcome.
+func (s *Service) queueConfidenceChange(ctx context.Context, memory *Memory) {
+	update := &pendingWrite{
+		projectID:     memory.ProjectID,
+		hasConfidence: true,
+		confidence:    memory.Confidence,
+		importance:    memory.Importance,
+		updatedAt:     memory.UpdatedAt,
+	}
+	if n := len(memory.ConfidenceHistory); n > 0 {
+		update.history = []ConfidenceChange{memory.ConfidenceHistory[n-1]}
+	}
+	s.writes.add(ctx, memory.ID, update)
+}
+
+// StartWriteBatching flushes batched memory updates every
+// WriteBatchConfig.FlushInterval and whenever MaxPending memories are
+// pending, until ctx is done or StopWriteBatching is called. It does nothing
+// without WithWriteBatching.
+func (s *Service) StartWriteBatching(ctx context.Context) {
+	b := s.writes
+	if b == nil {
+		return
+	}
+	go func() {
+		defer close(b.doneCh)
+		ticker := time.NewTicker(b.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.stopCh:
+				return
+			case <-ticker.C:
+			case <-b.flushCh:
+			}
+			if err := s.FlushWrites(ctx); err != nil {
+				s.logger.Warn("failed to flush batched memory updates", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// StopWriteBatching halts background flushes and writes what is still
+// pending. It must only be called after StartWriteBatching.
+func (s *Service) StopWriteBatching(ctx context.Context) error {
+	b := s.writes
+	if b == nil {
+		return nil
+	}
+	b.stopOnce.Do(func() { close(b.stopCh) })
+	<-b.doneCh
+	return s.FlushWrites(ctx)
+}
+
+// FlushWrites writes all batched memory updates, one rewrite per memory.
+// Updates that fail to write are kept for the next flush, except for
+// memories that no longer exist.
+func (s *Service) FlushWrites(ctx context.Context) error {
+	b := s.writes
+	if b == nil {
+		return nil
+	}
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[string]*pendingWrite)
+	b.mu.Unlock()
+
+	var errs []error
+	written := 0
+	for id, update := range pending {
+		err := s.flushWrite(ctx, id, update)
+		switch {
+		case err == nil:
+			written++
+		case errors.Is(err, ErrMemoryNotFound):
+			// Deleted or consolidated away since the update was queued
+		default:
+			errs = append(errs, err)
+			// Put the update back, ahead of any that arrived meanwhile
+			b.mu.Lock()
+			if newer, ok := b.pending[id]; ok {
+				update.merge(newer)
+			}
+			b.pending[id] = update
+			b.mu.Unlock()
+		}
+	}
+
+	if written > 0 {
+		s.logger.Debug("flushed batched memory updates",
+			zap.Int("memories", written),
+			zap.Int("failed", len(errs)))
+	}
+	return errors.Join(errs...)
+}
+
+// flushWrite applies one memory's pending update to the stored memory.
+func (s *Service) flushWrite(ctx context.Context, memoryID string, update *pendingWrite) error {
+	ctx = vectorstore.ContextWithRequester(ctx, update.requester)
+	if update.tenant != nil {
+		ctx = vectorstore.ContextWithTenant(ctx, update.tenant)
+	}
+	ctx, err := s.withTenant(ctx, update.projectID)
+	if err != nil {
+		return err
+	}
+
+	return retryOnConflict(func(int) error {
+		memory, err := s.loadByProjectID(ctx, update.projectID, memoryID)
+		if err != nil {
+			return err
+		}
+		update.apply(memory)
+		return s.storeMemory(ctx, update.projectID, memory)
+	})
+}
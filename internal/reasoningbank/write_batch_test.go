@@ -0,0 +1,91 @@
+package reasoningbank
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// writeCountingStore counts document writes.
+type writeCountingStore struct {
+	*mockStore
+	writes atomic.Int32
+}
+
+func (s *writeCountingStore) AddDocuments(ctx context.Context, docs []vectorstore.Document) ([]string, error) {
+	s.writes.Add(1)
+	return s.mockStore.AddDocuments(ctx, docs)
+}
+
+func TestService_WriteBatching(t *testing.T) {
+	ctx := context.Background()
+	projectID := "project-123"
+
+	newBatchedService := func(t *testing.T, cfg WriteBatchConfig) (*Service, *writeCountingStore, *Memory) {
+		t.Helper()
+		store := &writeCountingStore{mockStore: newMockStore()}
+		svc, err := NewService(store, zap.NewNop(), WithDefaultTenant("test-tenant"), WithWriteBatching(cfg))
+		require.NoError(t, err)
+		memory, _ := NewMemory(projectID, "Retry flaky uploads", "Wrap uploads in exponential backoff", OutcomeSuccess, []string{"go"})
+		require.NoError(t, svc.Record(ctx, memory))
+		store.writes.Store(0)
+		return svc, store, memory
+	}
+
+	t.Run("coalesces feedback into one write per flush", func(t *testing.T) {
+		svc, store, memory := newBatchedService(t, WriteBatchConfig{})
+
+		require.NoError(t, svc.Feedback(ctx, memory.ID, true))
+		require.NoError(t, svc.Feedback(ctx, memory.ID, true))
+		confidence, err := svc.RecordOutcome(ctx, memory.ID, true, "session-1")
+		require.NoError(t, err)
+		assert.Zero(t, store.writes.Load(), "updates were written before the flush")
+
+		// Reads see the pending updates
+		pending, err := svc.GetByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, confidence, pending.Confidence)
+		assert.Len(t, pending.ConfidenceHistory, 4) // recorded, plus the three updates
+
+		require.NoError(t, svc.FlushWrites(ctx))
+		assert.Equal(t, int32(1), store.writes.Load())
+
+		stored, err := svc.loadByProjectID(ctx, projectID, memory.ID)
+		require.NoError(t, err)
+		assert.Equal(t, confidence, stored.Confidence)
+		require.Len(t, stored.ConfidenceHistory, 4)
+		assert.Equal(t, CauseOutcomeSuccess, stored.ConfidenceHistory[3].Cause)
+
+		// Nothing left to write
+		require.NoError(t, svc.FlushWrites(ctx))
+		assert.Equal(t, int32(1), store.writes.Load())
+	})
+
+	t.Run("deleted memories are skipped", func(t *testing.T) {
+		svc, store, memory := newBatchedService(t, WriteBatchConfig{})
+
+		require.NoError(t, svc.Feedback(ctx, memory.ID, false))
+		require.NoError(t, svc.DeleteByProjectID(ctx, projectID, memory.ID))
+		require.NoError(t, svc.FlushWrites(ctx))
+		assert.Zero(t, store.writes.Load())
+	})
+
+	t.Run("flushes in the background when full", func(t *testing.T) {
+		svc, store, memory := newBatchedService(t, WriteBatchConfig{FlushInterval: time.Hour, MaxPending: 1})
+		svc.StartWriteBatching(ctx)
+
+		require.NoError(t, svc.Feedback(ctx, memory.ID, true))
+		assert.Eventually(t, func() bool { return store.writes.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, svc.Feedback(ctx, memory.ID, true))
+		require.NoError(t, svc.StopWriteBatching(ctx))
+		assert.Zero(t, svc.writes.size())
+	})
+}
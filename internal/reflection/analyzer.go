@@ -40,27 +40,24 @@ func (a *DefaultAnalyzer) Analyze(ctx context.Context, opts AnalyzeOptions) ([]P
 		opts.MaxPatterns = 20
 	}
 
-	// Retrieve all memories for the project
-	rawMemories, err := a.memorySvc.Search(ctx, opts.ProjectID, "", 1000)
+	// Stream the project's memories, keeping only those in the period so
+	// large projects are never held in full.
+	var memories []*reasoningbank.Memory
+	err := a.memorySvc.StreamMemories(ctx, opts.ProjectID, 0, func(m reasoningbank.Memory) error {
+		if opts.Period != nil && !inPeriod(&m, opts.Period) {
+			return nil
+		}
+		memories = append(memories, &m)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve memories: %w", err)
 	}
 
-	if len(rawMemories) == 0 {
+	if len(memories) == 0 {
 		return []Pattern{}, nil
 	}
 
-	// Convert to pointer slice for filtering
-	memories := make([]*reasoningbank.Memory, len(rawMemories))
-	for i := range rawMemories {
-		memories[i] = &rawMemories[i]
-	}
-
-	// Filter by period if specified
-	if opts.Period != nil {
-		memories = filterByPeriod(memories, opts.Period)
-	}
-
 	// Filter by tags if specified
 	if len(opts.IncludeTags) > 0 || len(opts.ExcludeTags) > 0 {
 		memories = filterByTags(memories, opts.IncludeTags, opts.ExcludeTags)
@@ -173,13 +170,18 @@ func (a *DefaultAnalyzer) extractPatterns(memories []*reasoningbank.Memory, opts
 func filterByPeriod(memories []*reasoningbank.Memory, period *ReportPeriod) []*reasoningbank.Memory {
 	filtered := make([]*reasoningbank.Memory, 0)
 	for _, m := range memories {
-		if m.CreatedAt.After(period.Start) && m.CreatedAt.Before(period.End) {
+		if inPeriod(m, period) {
 			filtered = append(filtered, m)
 		}
 	}
 	return filtered
 }
 
+// inPeriod reports whether a memory was created strictly within period.
+func inPeriod(m *reasoningbank.Memory, period *ReportPeriod) bool {
+	return m.CreatedAt.After(period.Start) && m.CreatedAt.Before(period.End)
+}
+
 // filterByTags filters memories by inclusion/exclusion tags.
 func filterByTags(memories []*reasoningbank.Memory, include, exclude []string) []*reasoningbank.Memory {
 	includeSet := make(map[string]bool)
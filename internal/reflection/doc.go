@@ -40,6 +40,20 @@
 //	    Format:              "markdown",
 //	})
 //
+// Compare the projects of a team:
+//
+//	teamReport, err := reporter.GenerateTeam(ctx, reflection.TeamReportOptions{
+//	    TeamID:     "platform",
+//	    ProjectIDs: []string{"api", "web", "worker"},
+//	    Period:     period,
+//	})
+//	markdown := reflection.FormatTeamReport(teamReport, "markdown")
+//
+// Team reports compare memory tags across projects: tags that succeed in
+// every project are shared practices, tags that fail in every project are
+// common anti-patterns, and tags that succeed in some projects but fail in
+// others are diverging conventions between repositories.
+//
 // # Pattern Categories
 //
 // Patterns are grouped into categories:
@@ -42,23 +42,21 @@ func (r *DefaultReporter) Generate(ctx context.Context, opts ReportOptions) (*Re
 		opts.Format = "json"
 	}
 
-	// Retrieve memories for statistics
-	rawMemories, err := r.memorySvc.Search(ctx, opts.ProjectID, "", 1000)
+	// Stream memories for statistics. Search needs a query and caps results,
+	// so it cannot enumerate a project.
+	filterPeriod := !opts.Period.Start.IsZero() && !opts.Period.End.IsZero()
+	var memories []*reasoningbank.Memory
+	err := r.memorySvc.StreamMemories(ctx, opts.ProjectID, 0, func(m reasoningbank.Memory) error {
+		if filterPeriod && !inPeriod(&m, &opts.Period) {
+			return nil
+		}
+		memories = append(memories, &m)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve memories: %w", err)
 	}
 
-	// Convert to pointer slice for filtering
-	memories := make([]*reasoningbank.Memory, len(rawMemories))
-	for i := range rawMemories {
-		memories[i] = &rawMemories[i]
-	}
-
-	// Filter by period
-	if !opts.Period.Start.IsZero() && !opts.Period.End.IsZero() {
-		memories = filterMemoriesByPeriod(memories, opts.Period)
-	}
-
 	report := &ReflectionReport{
 		ID:          uuid.New().String(),
 		ProjectID:   opts.ProjectID,
@@ -110,17 +108,6 @@ func (r *DefaultReporter) Generate(ctx context.Context, opts ReportOptions) (*Re
 	return report, nil
 }
 
-// filterMemoriesByPeriod filters memories to those within the period.
-func filterMemoriesByPeriod(memories []*reasoningbank.Memory, period ReportPeriod) []*reasoningbank.Memory {
-	filtered := make([]*reasoningbank.Memory, 0)
-	for _, m := range memories {
-		if m.CreatedAt.After(period.Start) && m.CreatedAt.Before(period.End) {
-			filtered = append(filtered, m)
-		}
-	}
-	return filtered
-}
-
 // calculateStatistics computes summary statistics.
 func (r *DefaultReporter) calculateStatistics(memories []*reasoningbank.Memory) ReportStatistics {
 	stats := ReportStatistics{
@@ -228,23 +228,6 @@ func TestFormatReport_Text(t *testing.T) {
 	assert.Contains(t, output, "RECOMMENDATIONS")
 }
 
-func TestFilterMemoriesByPeriod(t *testing.T) {
-	now := time.Now()
-	memories := []*reasoningbank.Memory{
-		{ID: "old", CreatedAt: now.Add(-7 * 24 * time.Hour)},
-		{ID: "recent", CreatedAt: now.Add(-2 * 24 * time.Hour)},
-		{ID: "new", CreatedAt: now.Add(-1 * time.Hour)},
-	}
-
-	period := ReportPeriod{
-		Start: now.Add(-3 * 24 * time.Hour),
-		End:   now,
-	}
-
-	filtered := filterMemoriesByPeriod(memories, period)
-	assert.Len(t, filtered, 2)
-}
-
 func TestGetTopTags(t *testing.T) {
 	tagCounts := map[string]int{
 		"golang":   15,
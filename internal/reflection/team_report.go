@@ -0,0 +1,358 @@
+package reflection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+// Thresholds for classifying a tag's outcomes within one project.
+const (
+	// teamSuccessRate is the success rate at or above which a tag is
+	// working in a project.
+	teamSuccessRate = 0.7
+	// teamFailureRate is the success rate at or below which a tag is
+	// failing in a project.
+	teamFailureRate = 0.3
+)
+
+// GenerateTeam creates a report across the projects of a team. Memories
+// are streamed from each project; tags seen in several projects are
+// compared to find practices that work everywhere, anti-patterns that
+// fail everywhere and conventions that work in some repositories but
+// fail in others.
+func (r *DefaultReporter) GenerateTeam(ctx context.Context, opts TeamReportOptions) (*TeamReport, error) {
+	if opts.TeamID == "" {
+		return nil, fmt.Errorf("team_id is required")
+	}
+	if len(opts.ProjectIDs) < 2 {
+		return nil, fmt.Errorf("at least 2 project_ids are required for a team report")
+	}
+
+	filterPeriod := !opts.Period.Start.IsZero() && !opts.Period.End.IsZero()
+	byProject := make(map[string][]*reasoningbank.Memory, len(opts.ProjectIDs))
+	for _, projectID := range opts.ProjectIDs {
+		if _, seen := byProject[projectID]; seen {
+			continue
+		}
+		memories := []*reasoningbank.Memory{}
+		err := r.memorySvc.StreamMemories(ctx, projectID, 0, func(m reasoningbank.Memory) error {
+			if filterPeriod && !inPeriod(&m, &opts.Period) {
+				return nil
+			}
+			memories = append(memories, &m)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve memories for project %s: %w", projectID, err)
+		}
+		byProject[projectID] = memories
+	}
+
+	return r.buildTeamReport(opts, byProject), nil
+}
+
+// buildTeamReport aggregates per-project memories into a team report.
+func (r *DefaultReporter) buildTeamReport(opts TeamReportOptions, byProject map[string][]*reasoningbank.Memory) *TeamReport {
+	if opts.MinProjects < 2 {
+		opts.MinProjects = 2
+	}
+	if opts.MinFrequency <= 0 {
+		opts.MinFrequency = 2
+	}
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = 10
+	}
+
+	report := &TeamReport{
+		ID:          uuid.New().String(),
+		TeamID:      opts.TeamID,
+		GeneratedAt: time.Now(),
+		Period:      opts.Period,
+	}
+
+	projectIDs := make([]string, 0, len(byProject))
+	for projectID := range byProject {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Strings(projectIDs)
+
+	// Per-project statistics, and the outcome of every tag in every project
+	var all []*reasoningbank.Memory
+	tagOutcomes := make(map[string][]TagOutcome)
+	for _, projectID := range projectIDs {
+		memories := byProject[projectID]
+		all = append(all, memories...)
+
+		stats := r.calculateStatistics(memories)
+		report.Projects = append(report.Projects, ProjectSummary{
+			ProjectID:         projectID,
+			TotalMemories:     stats.TotalMemories,
+			SuccessRate:       stats.SuccessRate,
+			AverageConfidence: stats.AverageConfidence,
+			TopTags:           getTopTags(tagCountsOf(memories), 5),
+		})
+
+		for tag, outcome := range projectTagOutcomes(projectID, memories) {
+			if outcome.Count >= opts.MinFrequency {
+				tagOutcomes[tag] = append(tagOutcomes[tag], outcome)
+			}
+		}
+	}
+	report.Statistics = r.calculateStatistics(all)
+
+	for tag, outcomes := range tagOutcomes {
+		if len(outcomes) < opts.MinProjects {
+			continue
+		}
+		var working, failing []TagOutcome
+		for _, o := range outcomes {
+			switch {
+			case o.SuccessRate >= teamSuccessRate:
+				working = append(working, o)
+			case o.SuccessRate <= teamFailureRate:
+				failing = append(failing, o)
+			}
+		}
+
+		switch {
+		case len(working) == len(outcomes):
+			report.SharedPatterns = append(report.SharedPatterns, newCrossProjectPattern(tag, PatternSuccess, outcomes))
+		case len(failing) == len(outcomes):
+			report.AntiPatterns = append(report.AntiPatterns, newCrossProjectPattern(tag, PatternFailure, outcomes))
+		case len(working) > 0 && len(failing) > 0:
+			report.Divergences = append(report.Divergences, ConventionDivergence{
+				Tag:     tag,
+				Working: working,
+				Failing: failing,
+				Spread:  maxSuccessRate(working) - minSuccessRate(failing),
+				Description: fmt.Sprintf("'%s' works in %s but fails in %s",
+					tag, joinProjects(working), joinProjects(failing)),
+			})
+		}
+	}
+
+	sortCrossProjectPatterns(report.SharedPatterns)
+	sortCrossProjectPatterns(report.AntiPatterns)
+	sort.Slice(report.Divergences, func(i, j int) bool {
+		if report.Divergences[i].Spread != report.Divergences[j].Spread {
+			return report.Divergences[i].Spread > report.Divergences[j].Spread
+		}
+		return report.Divergences[i].Tag < report.Divergences[j].Tag
+	})
+	if len(report.SharedPatterns) > opts.MaxItems {
+		report.SharedPatterns = report.SharedPatterns[:opts.MaxItems]
+	}
+	if len(report.AntiPatterns) > opts.MaxItems {
+		report.AntiPatterns = report.AntiPatterns[:opts.MaxItems]
+	}
+	if len(report.Divergences) > opts.MaxItems {
+		report.Divergences = report.Divergences[:opts.MaxItems]
+	}
+
+	report.Recommendations = generateTeamRecommendations(report)
+	report.Summary = generateTeamSummary(report)
+	return report
+}
+
+// tagCountsOf counts tag occurrences across memories.
+func tagCountsOf(memories []*reasoningbank.Memory) map[string]int {
+	counts := make(map[string]int)
+	for _, m := range memories {
+		for _, tag := range m.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// projectTagOutcomes computes how often each tag appears in a project and
+// how often memories with it succeeded.
+func projectTagOutcomes(projectID string, memories []*reasoningbank.Memory) map[string]TagOutcome {
+	outcomes := make(map[string]TagOutcome)
+	successes := make(map[string]int)
+	for _, m := range memories {
+		for _, tag := range m.Tags {
+			o := outcomes[tag]
+			o.ProjectID = projectID
+			o.Count++
+			outcomes[tag] = o
+			if m.Outcome == reasoningbank.OutcomeSuccess {
+				successes[tag]++
+			}
+		}
+	}
+	for tag, o := range outcomes {
+		o.SuccessRate = float64(successes[tag]) / float64(o.Count)
+		outcomes[tag] = o
+	}
+	return outcomes
+}
+
+// newCrossProjectPattern summarizes a tag that behaves the same way in
+// several projects.
+func newCrossProjectPattern(tag string, category PatternCategory, outcomes []TagOutcome) CrossProjectPattern {
+	p := CrossProjectPattern{
+		Tag:      tag,
+		Category: category,
+		Projects: outcomes,
+	}
+	var successes float64
+	for _, o := range outcomes {
+		p.Frequency += o.Count
+		successes += o.SuccessRate * float64(o.Count)
+	}
+	p.SuccessRate = successes / float64(p.Frequency)
+
+	if category == PatternSuccess {
+		p.Description = fmt.Sprintf("'%s' succeeds across %d projects (%.0f%% success over %d memories)",
+			tag, len(outcomes), p.SuccessRate*100, p.Frequency)
+	} else {
+		p.Description = fmt.Sprintf("'%s' fails across %d projects (%.0f%% success over %d memories)",
+			tag, len(outcomes), p.SuccessRate*100, p.Frequency)
+	}
+	return p
+}
+
+// sortCrossProjectPatterns orders patterns by project reach, then frequency.
+func sortCrossProjectPatterns(patterns []CrossProjectPattern) {
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i].Projects) != len(patterns[j].Projects) {
+			return len(patterns[i].Projects) > len(patterns[j].Projects)
+		}
+		if patterns[i].Frequency != patterns[j].Frequency {
+			return patterns[i].Frequency > patterns[j].Frequency
+		}
+		return patterns[i].Tag < patterns[j].Tag
+	})
+}
+
+func maxSuccessRate(outcomes []TagOutcome) float64 {
+	best := 0.0
+	for _, o := range outcomes {
+		if o.SuccessRate > best {
+			best = o.SuccessRate
+		}
+	}
+	return best
+}
+
+func minSuccessRate(outcomes []TagOutcome) float64 {
+	worst := 1.0
+	for _, o := range outcomes {
+		if o.SuccessRate < worst {
+			worst = o.SuccessRate
+		}
+	}
+	return worst
+}
+
+func joinProjects(outcomes []TagOutcome) string {
+	names := make([]string, len(outcomes))
+	for i, o := range outcomes {
+		names[i] = o.ProjectID
+	}
+	return strings.Join(names, ", ")
+}
+
+// generateTeamRecommendations creates actionable recommendations for a team.
+func generateTeamRecommendations(report *TeamReport) []string {
+	var recommendations []string
+
+	for _, d := range report.Divergences {
+		recommendations = append(recommendations,
+			fmt.Sprintf("Align '%s' conventions: adopt what works in %s for %s", d.Tag, joinProjects(d.Working), joinProjects(d.Failing)))
+	}
+	if len(report.AntiPatterns) > 0 {
+		recommendations = append(recommendations, "Document team-wide anti-patterns so every repository avoids them")
+	}
+	if len(report.SharedPatterns) > 0 {
+		recommendations = append(recommendations, "Promote shared practices to team-level guidelines")
+	}
+	for _, p := range report.Projects {
+		if p.TotalMemories == 0 {
+			recommendations = append(recommendations,
+				fmt.Sprintf("Start recording learnings in %s so it can be compared with the rest of the team", p.ProjectID))
+		}
+	}
+
+	return recommendations
+}
+
+// generateTeamSummary creates a high-level summary of a team report.
+func generateTeamSummary(report *TeamReport) string {
+	parts := []string{fmt.Sprintf("Analyzed %d memories across %d projects", report.Statistics.TotalMemories, len(report.Projects))}
+	if report.Statistics.TotalMemories > 0 {
+		parts[0] += fmt.Sprintf(" with %.0f%% success rate", report.Statistics.SuccessRate*100)
+	}
+	parts = append(parts, fmt.Sprintf("Found %d shared practices, %d common anti-patterns and %d diverging conventions",
+		len(report.SharedPatterns), len(report.AntiPatterns), len(report.Divergences)))
+	return strings.Join(parts, ". ") + "."
+}
+
+// FormatTeamReport formats a team report as markdown. JSON is handled by
+// the caller via json.Marshal.
+func FormatTeamReport(report *TeamReport, format string) string {
+	if format != "markdown" {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("# Team Reflection Report\n\n")
+	sb.WriteString(fmt.Sprintf("**Team:** %s\n", report.TeamID))
+	sb.WriteString(fmt.Sprintf("**Generated:** %s\n\n", report.GeneratedAt.Format(time.RFC3339)))
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString(report.Summary + "\n\n")
+
+	sb.WriteString("## Projects\n\n")
+	sb.WriteString("| Project | Memories | Success Rate | Avg Confidence |\n")
+	sb.WriteString("|---------|----------|--------------|----------------|\n")
+	for _, p := range report.Projects {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.1f%% | %.2f |\n", p.ProjectID, p.TotalMemories, p.SuccessRate*100, p.AverageConfidence))
+	}
+	sb.WriteString("\n")
+
+	if len(report.SharedPatterns) > 0 {
+		sb.WriteString("## Shared Practices\n\n")
+		for _, p := range report.SharedPatterns {
+			sb.WriteString(fmt.Sprintf("- %s\n", p.Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.AntiPatterns) > 0 {
+		sb.WriteString("## Common Anti-Patterns\n\n")
+		for _, p := range report.AntiPatterns {
+			sb.WriteString(fmt.Sprintf("- %s\n", p.Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.Divergences) > 0 {
+		sb.WriteString("## Diverging Conventions\n\n")
+		for _, d := range report.Divergences {
+			sb.WriteString(fmt.Sprintf("- %s\n", d.Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.Recommendations) > 0 {
+		sb.WriteString("## Recommendations\n\n")
+		for _, rec := range report.Recommendations {
+			sb.WriteString(fmt.Sprintf("- %s\n", rec))
+		}
+	}
+
+	return sb.String()
+}
+
+// Ensure DefaultReporter implements TeamReporter.
+var _ TeamReporter = (*DefaultReporter)(nil)
@@ -0,0 +1,127 @@
+package reflection
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// teamMemories builds memories with a tag, n successes and m failures.
+func teamMemories(tag string, successes, failures int) []*reasoningbank.Memory {
+	var memories []*reasoningbank.Memory
+	for i := 0; i < successes; i++ {
+		memories = append(memories, &reasoningbank.Memory{Outcome: reasoningbank.OutcomeSuccess, Confidence: 0.8, Tags: []string{tag}})
+	}
+	for i := 0; i < failures; i++ {
+		memories = append(memories, &reasoningbank.Memory{Outcome: reasoningbank.OutcomeFailure, Confidence: 0.4, Tags: []string{tag}})
+	}
+	return memories
+}
+
+func concat(groups ...[]*reasoningbank.Memory) []*reasoningbank.Memory {
+	var out []*reasoningbank.Memory
+	for _, g := range groups {
+		out = append(out, g...)
+	}
+	return out
+}
+
+func TestReporter_BuildTeamReport(t *testing.T) {
+	reporter := &DefaultReporter{}
+	byProject := map[string][]*reasoningbank.Memory{
+		"api": concat(
+			teamMemories("testing", 3, 0),
+			teamMemories("mocks", 0, 2),
+			teamMemories("migrations", 3, 0),
+			teamMemories("caching", 2, 0),
+		),
+		"web": concat(
+			teamMemories("testing", 2, 0),
+			teamMemories("mocks", 0, 3),
+			teamMemories("migrations", 0, 2),
+		),
+		"worker": concat(
+			teamMemories("testing", 4, 1),
+			teamMemories("caching", 1, 0), // below MinFrequency
+		),
+	}
+
+	report := reporter.buildTeamReport(TeamReportOptions{TeamID: "platform"}, byProject)
+
+	assert.Equal(t, "platform", report.TeamID)
+	require.Len(t, report.Projects, 3)
+	assert.Equal(t, "api", report.Projects[0].ProjectID)
+	assert.Equal(t, 10, report.Projects[0].TotalMemories)
+	assert.Equal(t, 23, report.Statistics.TotalMemories)
+
+	require.Len(t, report.SharedPatterns, 1)
+	shared := report.SharedPatterns[0]
+	assert.Equal(t, "testing", shared.Tag)
+	assert.Equal(t, PatternSuccess, shared.Category)
+	assert.Len(t, shared.Projects, 3)
+	assert.Equal(t, 10, shared.Frequency)
+	assert.InDelta(t, 0.9, shared.SuccessRate, 0.001)
+
+	require.Len(t, report.AntiPatterns, 1)
+	assert.Equal(t, "mocks", report.AntiPatterns[0].Tag)
+	assert.Equal(t, PatternFailure, report.AntiPatterns[0].Category)
+
+	require.Len(t, report.Divergences, 1)
+	div := report.Divergences[0]
+	assert.Equal(t, "migrations", div.Tag)
+	assert.Equal(t, "api", div.Working[0].ProjectID)
+	assert.Equal(t, "web", div.Failing[0].ProjectID)
+	assert.Equal(t, 1.0, div.Spread)
+	assert.Equal(t, "'migrations' works in api but fails in web", div.Description)
+
+	assert.Contains(t, report.Recommendations, "Align 'migrations' conventions: adopt what works in api for web")
+	assert.Equal(t, "Analyzed 23 memories across 3 projects with 65% success rate. Found 1 shared practices, 1 common anti-patterns and 1 diverging conventions.", report.Summary)
+}
+
+func TestReporter_BuildTeamReport_MinProjects(t *testing.T) {
+	reporter := &DefaultReporter{}
+	byProject := map[string][]*reasoningbank.Memory{
+		"a": teamMemories("testing", 2, 0),
+		"b": teamMemories("testing", 2, 0),
+		"c": {},
+	}
+
+	report := reporter.buildTeamReport(TeamReportOptions{TeamID: "t", MinProjects: 3}, byProject)
+	assert.Empty(t, report.SharedPatterns, "tag seen in 2 of the required 3 projects")
+	assert.Contains(t, report.Recommendations, "Start recording learnings in c so it can be compared with the rest of the team")
+}
+
+func TestReporter_GenerateTeam_Validation(t *testing.T) {
+	reporter := &DefaultReporter{}
+	ctx := context.Background()
+
+	_, err := reporter.GenerateTeam(ctx, TeamReportOptions{ProjectIDs: []string{"a", "b"}})
+	assert.Error(t, err)
+	_, err = reporter.GenerateTeam(ctx, TeamReportOptions{TeamID: "t", ProjectIDs: []string{"a"}})
+	assert.Error(t, err)
+}
+
+func TestFormatTeamReport(t *testing.T) {
+	reporter := &DefaultReporter{}
+	report := reporter.buildTeamReport(TeamReportOptions{TeamID: "platform"}, map[string][]*reasoningbank.Memory{
+		"api": concat(teamMemories("testing", 2, 0), teamMemories("migrations", 2, 0)),
+		"web": concat(teamMemories("testing", 2, 0), teamMemories("migrations", 0, 2)),
+	})
+
+	md := FormatTeamReport(report, "markdown")
+	assert.Contains(t, md, "# Team Reflection Report")
+	assert.Contains(t, md, "**Team:** platform")
+	assert.Contains(t, md, "| api | 4 | 100.0% | 0.80 |")
+	assert.Contains(t, md, "## Shared Practices\n\n- 'testing' succeeds across 2 projects")
+	assert.Contains(t, md, "## Diverging Conventions\n\n- 'migrations' works in api but fails in web")
+	assert.NotContains(t, md, "## Common Anti-Patterns")
+
+	assert.Empty(t, FormatTeamReport(report, "json"))
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"divergences":[{"tag":"migrations"`)
+}
@@ -209,6 +209,95 @@ type ReportOptions struct {
 	Format string
 }
 
+// TeamReportOptions configures a team report across projects.
+type TeamReportOptions struct {
+	// TeamID identifies the team being reported on.
+	TeamID string
+	// ProjectIDs are the team's projects (at least 2).
+	ProjectIDs []string
+	// Period to cover.
+	Period ReportPeriod
+	// MinProjects a tag must appear in to be compared (default: 2).
+	MinProjects int
+	// MinFrequency of a tag within a project for it to count (default: 2).
+	MinFrequency int
+	// MaxItems per section (default: 10).
+	MaxItems int
+}
+
+// TeamReport compares reflection across the projects of a team.
+type TeamReport struct {
+	// ID is the unique identifier for this report.
+	ID string `json:"id"`
+	// TeamID is the team this report covers.
+	TeamID string `json:"team_id"`
+	// GeneratedAt is when the report was created.
+	GeneratedAt time.Time `json:"generated_at"`
+	// Period describes the time period covered.
+	Period ReportPeriod `json:"period"`
+	// Summary provides a high-level overview.
+	Summary string `json:"summary"`
+	// Projects summarizes each project.
+	Projects []ProjectSummary `json:"projects"`
+	// Statistics across all projects.
+	Statistics ReportStatistics `json:"statistics"`
+	// SharedPatterns are tags that succeed in every project using them.
+	SharedPatterns []CrossProjectPattern `json:"shared_patterns"`
+	// AntiPatterns are tags that fail in every project using them.
+	AntiPatterns []CrossProjectPattern `json:"anti_patterns"`
+	// Divergences are tags that succeed in some projects and fail in others.
+	Divergences []ConventionDivergence `json:"divergences"`
+	// Recommendations for the team.
+	Recommendations []string `json:"recommendations"`
+}
+
+// ProjectSummary is one project's statistics within a team report.
+type ProjectSummary struct {
+	ProjectID         string     `json:"project_id"`
+	TotalMemories     int        `json:"total_memories"`
+	SuccessRate       float64    `json:"success_rate"`
+	AverageConfidence float64    `json:"average_confidence"`
+	TopTags           []TagCount `json:"top_tags"`
+}
+
+// TagOutcome is how a tag fares within one project.
+type TagOutcome struct {
+	ProjectID   string  `json:"project_id"`
+	Count       int     `json:"count"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// CrossProjectPattern is a tag with the same outcome in several projects.
+type CrossProjectPattern struct {
+	// Tag the pattern is about.
+	Tag string `json:"tag"`
+	// Category is PatternSuccess or PatternFailure.
+	Category PatternCategory `json:"category"`
+	// Description summarizes the pattern.
+	Description string `json:"description"`
+	// Frequency is the number of memories with the tag across projects.
+	Frequency int `json:"frequency"`
+	// SuccessRate across all those memories.
+	SuccessRate float64 `json:"success_rate"`
+	// Projects where the tag appears.
+	Projects []TagOutcome `json:"projects"`
+}
+
+// ConventionDivergence is a tag whose approach works in some projects but
+// fails in others, suggesting the repositories follow different conventions.
+type ConventionDivergence struct {
+	// Tag the conventions diverge on.
+	Tag string `json:"tag"`
+	// Description explains the divergence.
+	Description string `json:"description"`
+	// Spread between the best working and worst failing success rates.
+	Spread float64 `json:"spread"`
+	// Working are the projects where the tag succeeds.
+	Working []TagOutcome `json:"working"`
+	// Failing are the projects where the tag fails.
+	Failing []TagOutcome `json:"failing"`
+}
+
 // Analyzer identifies patterns in memories.
 type Analyzer interface {
 	// Analyze finds patterns in memories for a project.
@@ -226,3 +315,9 @@ type Reporter interface {
 	// Generate creates a reflection report.
 	Generate(ctx context.Context, opts ReportOptions) (*ReflectionReport, error)
 }
+
+// TeamReporter generates reports across the projects of a team.
+type TeamReporter interface {
+	// GenerateTeam creates a team report.
+	GenerateTeam(ctx context.Context, opts TeamReportOptions) (*TeamReport, error)
+}
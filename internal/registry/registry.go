@@ -29,13 +29,15 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 // Errors for registry operations.
 var (
-	ErrTenantNotFound    = errors.New("tenant not found")
-	ErrProjectNotFound   = errors.New("project not found")
-	ErrTeamNotFound      = errors.New("team not found")
+	ErrTenantNotFound    = ctxerrors.NotFound("tenant not found")
+	ErrProjectNotFound   = ctxerrors.NotFound("project not found")
+	ErrTeamNotFound      = ctxerrors.NotFound("team not found")
 	ErrInvalidName       = errors.New("invalid name: must be alphanumeric with hyphens/underscores")
 	ErrPathTraversal     = errors.New("path traversal detected")
 	ErrRegistryCorrupted = errors.New("registry file corrupted")
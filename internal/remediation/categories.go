@@ -0,0 +1,291 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// scanBatchSize is the batch size for scrolling remediation collections.
+const scanBatchSize = 500
+
+// CategoryStats implements Service.CategoryStats. Categories are ordered by
+// count, then name.
+func (s *service) CategoryStats(ctx context.Context, req *CategoryStatsRequest) ([]CategoryStats, error) {
+	ctx, span := s.tracer.Start(ctx, "remediation.category_stats")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", req.TenantID),
+		attribute.String("scope", string(req.Scope)),
+	)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	taxonomy := s.taxonomy(req.TenantID)
+	stats := make(map[ErrorCategory]*CategoryStats)
+	for _, c := range taxonomy.Categories() {
+		stats[c.Name] = &CategoryStats{
+			Category:    c.Name,
+			Builtin:     c.Builtin,
+			Description: c.Description,
+			InTaxonomy:  true,
+		}
+	}
+
+	confidence := make(map[ErrorCategory]float64)
+	scopes := s.getSearchScopes(&SearchRequest{
+		Scope:            req.Scope,
+		TeamID:           req.TeamID,
+		ProjectPath:      req.ProjectPath,
+		IncludeHierarchy: req.IncludeHierarchy,
+	})
+	err := s.scanScopes(ctx, req.TenantID, scopes, nil, func(_ scopeInfo, rem *Remediation) {
+		st, ok := stats[rem.Category]
+		if !ok {
+			// A category retired from the taxonomy, or free-form without one
+			st = &CategoryStats{Category: rem.Category, Builtin: rem.BuiltinCategory}
+			if c, ok := taxonomy.Resolve(rem.Category); ok {
+				st.Builtin = c.Builtin
+			}
+			stats[rem.Category] = st
+		}
+		st.Count++
+		switch rem.Status {
+		case StatusPending:
+			st.Pending++
+		case StatusQuarantined:
+			st.Quarantined++
+		}
+		confidence[rem.Category] += rem.Confidence
+		st.UsageCount += rem.UsageCount
+		if rem.CreatedAt.After(st.LastRecorded) {
+			st.LastRecorded = rem.CreatedAt
+		}
+	})
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "category_stats", "no_stores_accessible")
+		return nil, err
+	}
+
+	out := make([]CategoryStats, 0, len(stats))
+	for name, st := range stats {
+		if st.Count > 0 {
+			st.AvgConfidence = confidence[name] / float64(st.Count)
+		}
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Category < out[j].Category
+	})
+	return out, nil
+}
+
+// MigrateCategory implements Service.MigrateCategory. Targets must be
+// categories of the tenant's taxonomy; moved remediations keep their IDs,
+// confidence and feedback.
+func (s *service) MigrateCategory(ctx context.Context, req *MigrateCategoryRequest) (*MigrateCategoryResult, error) {
+	ctx, span := s.tracer.Start(ctx, "remediation.migrate_category")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", req.TenantID),
+		attribute.String("from", string(req.From)),
+		attribute.Bool("dry_run", req.DryRun),
+	)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	if req.From == "" {
+		return nil, ctxerrors.New(ctxerrors.CodeInvalidInput, "from category is required")
+	}
+	if req.To == "" && len(req.Split) == 0 {
+		return nil, ctxerrors.New(ctxerrors.CodeInvalidInput, "a target category or split rules are required")
+	}
+
+	var to Category
+	if req.To != "" {
+		var err error
+		if to, err = s.resolveCategory(req.TenantID, req.To); err != nil {
+			return nil, err
+		}
+		if to.Name == req.From {
+			return nil, ctxerrors.New(ctxerrors.CodeInvalidInput, "target category must differ from the migrated one")
+		}
+	}
+	rules := make([]Category, len(req.Split))
+	keywords := make([][]string, len(req.Split))
+	for i, rule := range req.Split {
+		c, err := s.resolveCategory(req.TenantID, rule.Category)
+		if err != nil {
+			return nil, err
+		}
+		if c.Name == req.From {
+			return nil, ctxerrors.New(ctxerrors.CodeInvalidInput, "split rules must move to another category")
+		}
+		for _, kw := range rule.Keywords {
+			if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" {
+				keywords[i] = append(keywords[i], kw)
+			}
+		}
+		if len(keywords[i]) == 0 {
+			return nil, ctxerrors.New(ctxerrors.CodeInvalidInput,
+				fmt.Sprintf("split rule %d (%s) needs at least one keyword", i+1, c.Name))
+		}
+		rules[i] = c
+	}
+
+	type move struct {
+		scope scopeInfo
+		rem   *Remediation
+		to    Category
+	}
+	var moves []move
+	result := &MigrateCategoryResult{From: req.From, Moves: []CategoryMove{}, DryRun: req.DryRun}
+
+	scopes := s.getSearchScopes(&SearchRequest{
+		Scope:            req.Scope,
+		TeamID:           req.TeamID,
+		ProjectPath:      req.ProjectPath,
+		IncludeHierarchy: req.IncludeHierarchy,
+	})
+	filters := map[string]interface{}{"category": string(req.From)}
+	err := s.scanScopes(ctx, req.TenantID, scopes, filters, func(scope scopeInfo, rem *Remediation) {
+		// Stores that ignore unknown filter keys return every document
+		if rem.Category != req.From {
+			return
+		}
+		target := to
+		fields := []string{rem.Title, rem.Problem, rem.RootCause}
+		fields = append(fields, rem.Symptoms...)
+		fields = append(fields, rem.Tags...)
+		text := strings.ToLower(strings.Join(fields, "\n"))
+	match:
+		for i, kws := range keywords {
+			for _, kw := range kws {
+				if strings.Contains(text, kw) {
+					target = rules[i]
+					break match
+				}
+			}
+		}
+		if target.Name == "" {
+			result.Unmatched++
+			return
+		}
+		moves = append(moves, move{scope: scope, rem: rem, to: target})
+		result.Moves = append(result.Moves, CategoryMove{ID: rem.ID, Title: rem.Title, To: target.Name})
+	})
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "migrate_category", "no_stores_accessible")
+		return nil, err
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	for _, m := range moves {
+		m.rem.Category = m.to.Name
+		m.rem.BuiltinCategory = m.to.Builtin
+		m.rem.UpdatedAt = time.Now()
+		if err := s.rewrite(ctx, req.TenantID, m.scope, m.rem); err != nil {
+			span.RecordError(err)
+			s.recordError(ctx, "migrate_category", "update_failed")
+			return nil, fmt.Errorf("failed to migrate remediation %s: %w", m.rem.ID, err)
+		}
+	}
+
+	s.logger.Info("migrated remediation category",
+		zap.String("tenant_id", req.TenantID),
+		zap.String("from", string(req.From)),
+		zap.Int("moved", len(moves)),
+		zap.Int("unmatched", result.Unmatched),
+	)
+	span.SetAttributes(attribute.Int("moved", len(moves)))
+	return result, nil
+}
+
+// scanScopes calls fn with every remediation stored in scopes that matches
+// filters. Scopes whose collection does not exist are skipped; it fails only
+// when no store could be read.
+func (s *service) scanScopes(ctx context.Context, tenantID string, scopes []scopeInfo, filters map[string]interface{}, fn func(scopeInfo, *Remediation)) error {
+	var storesAccessed int
+	var lastStoreErr error
+
+	for _, scope := range scopes {
+		store, collection, err := s.getStore(ctx, tenantID, scope.scope, scope.teamID, scope.projectPath)
+		if err != nil {
+			s.logger.Warn("failed to get store", zap.String("scope", string(scope.scope)), zap.Error(err))
+			lastStoreErr = err
+			continue
+		}
+
+		scopedCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+			TenantID:  tenantID,
+			TeamID:    scope.teamID,
+			ProjectID: scope.projectPath,
+		})
+
+		exists, err := store.CollectionExists(scopedCtx, collection)
+		if err != nil {
+			s.logger.Warn("failed to check collection", zap.String("collection", collection), zap.Error(err))
+			lastStoreErr = err
+			continue
+		}
+		if !exists {
+			continue
+		}
+
+		err = vectorstore.Scroll(scopedCtx, store, collection, scanBatchSize, filters, func(batch []vectorstore.SearchResult) error {
+			for _, r := range batch {
+				if rem := s.resultToRemediation(r); rem != nil {
+					fn(scope, rem)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			s.logger.Warn("scan failed", zap.String("collection", collection), zap.Error(err))
+			lastStoreErr = err
+			continue
+		}
+		storesAccessed++
+	}
+
+	if storesAccessed == 0 && lastStoreErr != nil {
+		return fmt.Errorf("failed to access any stores: %w", lastStoreErr)
+	}
+	return nil
+}
+
+// rewrite replaces the stored copy of rem in scope's collection.
+func (s *service) rewrite(ctx context.Context, tenantID string, scope scopeInfo, rem *Remediation) error {
+	store, collection, err := s.getStore(ctx, tenantID, scope.scope, scope.teamID, scope.projectPath)
+	if err != nil {
+		return err
+	}
+	return s.commit(ctx, store, collection, rem)
+}
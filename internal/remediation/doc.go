@@ -61,6 +61,26 @@
 //   - Team scope searches: team → org
 //   - Org scope searches: org only
 //
+// # Error Signatures
+//
+// Record stores the SignatureHash of each remediation's Problem: the hash of
+// the problem text with timestamps, UUIDs, addresses, line numbers, file
+// directories and long IDs replaced by placeholders. Search first looks for
+// remediations with the query's signature and, when there are any, returns
+// only those, marked SignatureMatch. Other queries fall back to semantic
+// search, so recurrences of an identical stack trace find their fix even
+// where similar-looking errors would outrank it.
+//
+// # Category Taxonomies
+//
+// Config.Taxonomies gives tenants their own categories. Each Category
+// refines a built-in category, stored as BuiltinCategory, and may list
+// aliases such as its former names. Record rejects categories outside the
+// tenant's taxonomy with ErrUnknownCategory; built-in categories are always
+// accepted. CategoryStats reports per-category counts, including categories
+// dropped from the taxonomy, and MigrateCategory renames or keyword-splits a
+// category in place. Tenants without a taxonomy keep free-form categories.
+//
 // # Confidence Scoring
 //
 // Remediations start with default confidence (0.5) and are adjusted via feedback:
@@ -0,0 +1,56 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// List implements Service.List. Pending and quarantined remediations are
+// left out.
+func (s *service) List(ctx context.Context, req *ListRequest) ([]*Remediation, error) {
+	ctx, span := s.tracer.Start(ctx, "remediation.list")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", req.TenantID),
+		attribute.String("scope", string(req.Scope)),
+	)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	scopes := s.getSearchScopes(&SearchRequest{
+		Scope:            req.Scope,
+		TeamID:           req.TeamID,
+		ProjectPath:      req.ProjectPath,
+		IncludeHierarchy: req.IncludeHierarchy,
+	})
+	var out []*Remediation
+	err := s.scanScopes(ctx, req.TenantID, scopes, nil, func(_ scopeInfo, rem *Remediation) {
+		if statusOrActive(rem.Status) != StatusActive || rem.Confidence < req.MinConfidence {
+			return
+		}
+		if !req.Since.IsZero() && rem.CreatedAt.Before(req.Since) {
+			return
+		}
+		out = append(out, rem)
+	})
+	if err != nil {
+		span.RecordError(err)
+		s.recordError(ctx, "list", "no_stores_accessible")
+		return nil, err
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	span.SetAttributes(attribute.Int("count", len(out)))
+	return out, nil
+}
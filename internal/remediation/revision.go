@@ -0,0 +1,149 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// ErrRevisionConflict is returned when a remediation was written by someone
+// else between being read and being updated. Feedback and Confirm retry on
+// their own; callers that get it should re-read the remediation and retry.
+var ErrRevisionConflict = ctxerrors.Conflict("remediation was modified concurrently; re-read it and retry")
+
+// maxConflictRetries is how often an update that re-reads the remediation is
+// retried after a revision conflict.
+const maxConflictRetries = 3
+
+// revisionConflict describes a failed revision check.
+func revisionConflict(id string, expected, actual int64) error {
+	return &ctxerrors.Error{
+		Code:    ctxerrors.CodeConflict,
+		Message: fmt.Sprintf("remediation %s: expected revision %d, found %d", id, expected, actual),
+		Details: map[string]any{"id": id, "expected_revision": expected, "actual_revision": actual},
+		Err:     ErrRevisionConflict,
+	}
+}
+
+type expectedRevisionKey struct{}
+
+// ContextWithExpectedRevision makes Feedback and Confirm made with ctx fail
+// with ErrRevisionConflict unless the remediation is still at revision, for
+// callers that decided on the update from a copy they read earlier. Zero
+// sets no expectation.
+func ContextWithExpectedRevision(ctx context.Context, revision int64) context.Context {
+	if revision == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, expectedRevisionKey{}, revision)
+}
+
+// CheckExpectedRevision fails with a revision conflict if ctx expects rem at
+// another revision, for callers that act on a remediation themselves.
+func CheckExpectedRevision(ctx context.Context, rem *Remediation) error {
+	expected, ok := ctx.Value(expectedRevisionKey{}).(int64)
+	if !ok || expected == rem.Revision {
+		return nil
+	}
+	return revisionConflict(rem.ID, expected, rem.Revision)
+}
+
+// retryOnConflict runs a read-modify-write until it does not conflict with
+// a concurrent write.
+func retryOnConflict(fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		if err = fn(); !errors.Is(err, ErrRevisionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// idLocks serializes the revision check and write of each remediation within
+// the process. Writers in other processes are caught by the check alone.
+type idLocks struct {
+	mu    sync.Mutex
+	locks map[string]*idLock
+}
+
+type idLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks id and returns its unlock function.
+func (l *idLocks) lock(id string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*idLock)
+	}
+	il, ok := l.locks[id]
+	if !ok {
+		il = &idLock{}
+		l.locks[id] = il
+	}
+	il.refs++
+	l.mu.Unlock()
+
+	il.Lock()
+	return func() {
+		il.Unlock()
+		l.mu.Lock()
+		if il.refs--; il.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// commit overwrites the stored remediation with rem if the stored copy is
+// still at rem.Revision, and advances the revision. It fails with
+// ErrRevisionConflict otherwise, and restores the stored copy if the write
+// fails halfway.
+func (s *service) commit(ctx context.Context, store vectorstore.Store, collection string, rem *Remediation) error {
+	ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  rem.TenantID,
+		TeamID:    rem.TeamID,
+		ProjectID: rem.ProjectPath,
+	})
+	unlock := s.locks.lock(rem.ID)
+	defer unlock()
+
+	results, err := store.SearchInCollection(ctx, collection, "remediation", 1, map[string]interface{}{"id": rem.ID})
+	if err != nil {
+		return fmt.Errorf("failed to read stored remediation: %w", err)
+	}
+	var stored *Remediation
+	if len(results) > 0 {
+		stored = s.resultToRemediation(results[0])
+	}
+	if stored == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, rem.ID)
+	}
+	if stored.Revision != rem.Revision {
+		return revisionConflict(rem.ID, rem.Revision, stored.Revision)
+	}
+
+	rem.Revision++
+	if err := store.DeleteDocumentsFromCollection(ctx, collection, []string{rem.ID}); err != nil {
+		rem.Revision--
+		return fmt.Errorf("failed to delete old remediation: %w", err)
+	}
+	if _, err := store.AddDocuments(ctx, []vectorstore.Document{s.remediationToDocument(rem, collection)}); err != nil {
+		rem.Revision--
+		if _, rollbackErr := store.AddDocuments(ctx, []vectorstore.Document{s.remediationToDocument(stored, collection)}); rollbackErr != nil {
+			s.logger.Error("failed to rollback remediation after update failure",
+				zap.String("id", rem.ID),
+				zap.Error(rollbackErr))
+		}
+		return fmt.Errorf("failed to update remediation: %w", err)
+	}
+	return nil
+}
@@ -2,13 +2,20 @@ package remediation
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/fyrsmithlabs/contextd/internal/injection"
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
 	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
@@ -21,11 +28,18 @@ import (
 
 const instrumentationName = "github.com/fyrsmithlabs/contextd/internal/remediation"
 
+// ErrNotFound is returned when a remediation does not exist.
+var ErrNotFound = ctxerrors.NotFound("remediation not found")
+
 // Service provides remediation management operations.
 type Service interface {
 	// Search finds remediations by semantic similarity to error message/pattern.
 	Search(ctx context.Context, req *SearchRequest) ([]*ScoredRemediation, error)
 
+	// SearchPage returns one page of results starting at req.Cursor and the
+	// cursor for the next page (empty when there are no more results).
+	SearchPage(ctx context.Context, req *SearchRequest) ([]*ScoredRemediation, string, error)
+
 	// Record creates a new remediation.
 	Record(ctx context.Context, req *RecordRequest) (*Remediation, error)
 
@@ -35,9 +49,23 @@ type Service interface {
 	// Feedback records feedback on a remediation, adjusting confidence.
 	Feedback(ctx context.Context, req *FeedbackRequest) error
 
+	// Confirm activates a pending or quarantined remediation.
+	Confirm(ctx context.Context, tenantID, remediationID string) (*Remediation, error)
+
 	// Delete removes a remediation.
 	Delete(ctx context.Context, tenantID, remediationID string) error
 
+	// CategoryStats counts remediations per category, including every
+	// category of the tenant's taxonomy.
+	CategoryStats(ctx context.Context, req *CategoryStatsRequest) ([]CategoryStats, error)
+
+	// MigrateCategory renames or splits a category.
+	MigrateCategory(ctx context.Context, req *MigrateCategoryRequest) (*MigrateCategoryResult, error)
+
+	// List returns the active remediations of the requested scopes, newest
+	// first.
+	List(ctx context.Context, req *ListRequest) ([]*Remediation, error)
+
 	// Close closes the service.
 	Close() error
 }
@@ -56,11 +84,23 @@ type Config struct {
 	// FeedbackDelta is how much feedback changes confidence (default: 0.1)
 	FeedbackDelta float64
 
+	// EvidenceWeight multiplies FeedbackDelta for feedback that includes
+	// evidence (default: 1.5)
+	EvidenceWeight float64
+
 	// MinConfidence is the minimum confidence threshold (default: 0.1)
 	MinConfidence float64
 
 	// MaxConfidence is the maximum confidence (default: 1.0)
 	MaxConfidence float64
+
+	// ScanInjection quarantines recorded remediations that look like prompt
+	// injection (default: true)
+	ScanInjection bool
+
+	// Taxonomies are the category taxonomies of organizations by tenant ID.
+	// Tenants without one record any category.
+	Taxonomies map[string]*Taxonomy
 }
 
 // DefaultServiceConfig returns sensible defaults.
@@ -70,8 +110,10 @@ func DefaultServiceConfig() *Config {
 		VectorSize:        1536,
 		DefaultConfidence: 0.5,
 		FeedbackDelta:     0.1,
+		EvidenceWeight:    1.5,
 		MinConfidence:     0.1,
 		MaxConfidence:     1.0,
+		ScanInjection:     true,
 	}
 }
 
@@ -82,6 +124,11 @@ type service struct {
 	stores vectorstore.StoreProvider // Database-per-project isolation mode
 	logger *zap.Logger
 
+	// scanner flags prompt injection on Record; nil disables scanning
+	scanner *injection.Scanner
+
+	locks idLocks // Serializes revision-checked writes
+
 	// Telemetry
 	tracer          trace.Tracer
 	meter           metric.Meter
@@ -115,6 +162,9 @@ func NewService(cfg *Config, store vectorstore.Store, logger *zap.Logger) (Servi
 		meter:  otel.Meter(instrumentationName),
 	}
 
+	if cfg.ScanInjection {
+		s.scanner = injection.NewScanner()
+	}
 	s.initMetrics()
 
 	return s, nil
@@ -147,6 +197,9 @@ func NewServiceWithStoreProvider(cfg *Config, stores vectorstore.StoreProvider,
 		meter:  otel.Meter(instrumentationName),
 	}
 
+	if cfg.ScanInjection {
+		s.scanner = injection.NewScanner()
+	}
 	s.initMetrics()
 
 	return s, nil
@@ -288,6 +341,33 @@ func sanitizePath(path string) string {
 	return result
 }
 
+// statusOrActive treats an empty status as active.
+func statusOrActive(status Status) Status {
+	if status == "" {
+		return StatusActive
+	}
+	return status
+}
+
+// quarantineInjection moves a new remediation to StatusQuarantined when its
+// text looks like a prompt injection.
+func (s *service) quarantineInjection(rem *Remediation) {
+	if s.scanner == nil {
+		return
+	}
+	rules := s.scanner.ScanFields(rem.Title, rem.Problem, strings.Join(rem.Symptoms, "\n"),
+		rem.RootCause, rem.Solution, rem.CodeDiff, strings.Join(rem.Tags, " "))
+	if len(rules) == 0 {
+		return
+	}
+	rem.Status = StatusQuarantined
+	rem.QuarantineRules = rules
+	s.logger.Warn("remediation quarantined as possible prompt injection",
+		zap.String("id", rem.ID),
+		zap.String("tenant_id", rem.TenantID),
+		zap.Strings("rules", rules))
+}
+
 // Search finds remediations by semantic similarity.
 func (s *service) Search(ctx context.Context, req *SearchRequest) ([]*ScoredRemediation, error) {
 	start := time.Now()
@@ -312,6 +392,9 @@ func (s *service) Search(ctx context.Context, req *SearchRequest) ([]*ScoredReme
 	if req.Query == "" {
 		return nil, errors.New("query is required")
 	}
+	if err := validation.Remediation.Query("query", req.Query); err != nil {
+		return nil, err
+	}
 
 	limit := req.Limit
 	if limit == 0 {
@@ -331,9 +414,71 @@ func (s *service) Search(ctx context.Context, req *SearchRequest) ([]*ScoredReme
 		searchLimit = 30
 	}
 	if searchLimit > 200 {
-		searchLimit = 200 // Cap to prevent excessive fetching
+		// Cap to prevent excessive fetching, but never below limit so
+		// deep SearchPage offsets are not truncated at the cap
+		searchLimit = max(200, limit)
 	}
 
+	// Fast path: a query with the same error signature as a stored
+	// problem is a recurrence of that error, so exact matches are returned
+	// without the semantic results that would dilute them.
+	var allResults []*ScoredRemediation
+	if sig := SignatureHash(req.Query); sig != "" {
+		exactFilters := map[string]interface{}{"signature": sig}
+		for k, v := range filters {
+			exactFilters[k] = v
+		}
+		exact, err := s.searchScopes(ctx, req, scopes, exactFilters, searchLimit)
+		if err != nil {
+			s.logger.Warn("signature search failed, falling back to semantic search", zap.Error(err))
+		}
+		for _, r := range exact {
+			// Stores that ignore unknown filter keys return every document
+			if r.Signature != sig {
+				continue
+			}
+			r.Score = 1.0
+			r.SignatureMatch = true
+			allResults = append(allResults, r)
+		}
+		span.SetAttributes(attribute.Int("signature_matches", len(allResults)))
+	}
+
+	if len(allResults) == 0 {
+		var err error
+		allResults, err = s.searchScopes(ctx, req, scopes, filters, searchLimit)
+		if err != nil {
+			s.recordError(ctx, "search", "no_stores_accessible")
+			return nil, err
+		}
+	}
+
+	// Sort by score and limit
+	allResults = sortAndLimit(allResults, limit)
+
+	// Record metrics
+	duration := time.Since(start)
+	if s.searchCounter != nil {
+		s.searchCounter.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("scope", string(req.Scope)),
+			attribute.String("project_id", req.ProjectPath),
+			attribute.Int("result_count", len(allResults)),
+		))
+	}
+	if s.searchDuration != nil {
+		s.searchDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+			attribute.String("scope", string(req.Scope)),
+		))
+	}
+
+	span.SetAttributes(attribute.Int("result_count", len(allResults)))
+	return allResults, nil
+}
+
+// searchScopes searches each scope's collection with filters and returns
+// the remediations that pass the status, confidence and tag post-filters.
+// It fails only when no store could be searched.
+func (s *service) searchScopes(ctx context.Context, req *SearchRequest, scopes []scopeInfo, filters map[string]interface{}, searchLimit int) ([]*ScoredRemediation, error) {
 	var allResults []*ScoredRemediation
 	var storesAccessed int
 	var lastStoreErr error
@@ -384,6 +529,12 @@ func (s *service) Search(ctx context.Context, req *SearchRequest) ([]*ScoredReme
 				continue
 			}
 
+			// Post-filter: drafts and quarantined remediations only match
+			// searches for their own status
+			if statusOrActive(rem.Status) != statusOrActive(req.Status) {
+				continue
+			}
+
 			// Post-filter: skip remediations below confidence threshold
 			if req.MinConfidence > 0 && rem.Confidence < req.MinConfidence {
 				s.logger.Debug("skipping low-confidence remediation",
@@ -425,30 +576,58 @@ func (s *service) Search(ctx context.Context, req *SearchRequest) ([]*ScoredReme
 
 	// If no stores were accessible, return error instead of empty results
 	if storesAccessed == 0 && lastStoreErr != nil {
-		s.recordError(ctx, "search", "no_stores_accessible")
 		return nil, fmt.Errorf("failed to access any stores: %w", lastStoreErr)
 	}
+	return allResults, nil
 
-	// Sort by score and limit
-	allResults = sortAndLimit(allResults, limit)
+}
 
-	// Record metrics
-	duration := time.Since(start)
-	if s.searchCounter != nil {
-		s.searchCounter.Add(ctx, 1, metric.WithAttributes(
-			attribute.String("scope", string(req.Scope)),
-			attribute.String("project_id", req.ProjectPath),
-			attribute.Int("result_count", len(allResults)),
-		))
+// SearchPage implements Service.SearchPage. The cursor is bound to every
+// field that affects ranking, so changing filters between pages is rejected
+// with pagination.ErrCursorMismatch.
+func (s *service) SearchPage(ctx context.Context, req *SearchRequest) ([]*ScoredRemediation, string, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 10
 	}
-	if s.searchDuration != nil {
-		s.searchDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
-			attribute.String("scope", string(req.Scope)),
-		))
+
+	hash := CursorHash(req)
+	offset, err := pagination.Decode(req.Cursor, hash)
+	if err != nil {
+		return nil, "", err
 	}
 
-	span.SetAttributes(attribute.Int("result_count", len(allResults)))
-	return allResults, nil
+	// Fetch one extra result to know whether another page exists.
+	paged := *req
+	paged.Limit = offset + limit + 1
+	results, err := s.Search(ctx, &paged)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, next := pagination.Paginate(results, offset, limit, hash)
+	return page, next, nil
+}
+
+// CursorHash identifies a search request for cursor validation. Callers
+// that return part of a page use it to resume with pagination.Resume.
+func CursorHash(req *SearchRequest) string {
+	tags := append([]string(nil), req.Tags...)
+	sort.Strings(tags)
+
+	return pagination.QueryHash(
+		"remediation",
+		req.Query,
+		req.TenantID,
+		req.TeamID,
+		req.ProjectPath,
+		string(req.Scope),
+		string(req.Category),
+		strconv.FormatFloat(req.MinConfidence, 'f', -1, 64),
+		strconv.FormatBool(req.IncludeHierarchy),
+		strings.Join(tags, ","),
+		string(req.Status),
+	)
 }
 
 // scopeInfo holds scope information for searching.
@@ -497,9 +676,16 @@ func (s *service) getSearchScopes(req *SearchRequest) []scopeInfo {
 func (s *service) buildSearchFilters(req *SearchRequest) map[string]interface{} {
 	filters := make(map[string]interface{})
 
-	// Category filter (exact match - supported by all stores)
+	// Category filter (exact match - supported by all stores). Aliases of
+	// the tenant's taxonomy search their category.
 	if req.Category != "" {
-		filters["category"] = string(req.Category)
+		category := req.Category
+		if t := s.taxonomy(req.TenantID); t != nil {
+			if c, ok := t.Resolve(category); ok {
+				category = c.Name
+			}
+		}
+		filters["category"] = string(category)
 	}
 
 	if len(filters) == 0 {
@@ -509,6 +695,29 @@ func (s *service) buildSearchFilters(req *SearchRequest) map[string]interface{}
 	return filters
 }
 
+// checkRecordLimits rejects remediations larger than validation.Remediation
+// allows.
+func checkRecordLimits(req *RecordRequest) error {
+	limits := validation.Remediation
+	for _, field := range []struct{ name, value string }{
+		{"title", req.Title},
+		{"problem", req.Problem},
+		{"root_cause", req.RootCause},
+		{"solution", req.Solution},
+		{"code_diff", req.CodeDiff},
+	} {
+		if err := limits.Content(field.name, field.value); err != nil {
+			return err
+		}
+	}
+	for i, symptom := range req.Symptoms {
+		if err := limits.Content(fmt.Sprintf("symptoms[%d]", i), symptom); err != nil {
+			return err
+		}
+	}
+	return limits.Tags(req.Tags)
+}
+
 // Record creates a new remediation.
 func (s *service) Record(ctx context.Context, req *RecordRequest) (*Remediation, error) {
 	ctx, span := s.tracer.Start(ctx, "remediation.record")
@@ -527,6 +736,30 @@ func (s *service) Record(ctx context.Context, req *RecordRequest) (*Remediation,
 	}
 	s.mu.RUnlock()
 
+	if err := checkRecordLimits(req); err != nil {
+		return nil, err
+	}
+	category, err := s.resolveCategory(req.TenantID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	id := req.ID
+	if id == "" {
+		id = uuid.New().String()
+	} else if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid remediation id: %w", err)
+	}
+
+	status := req.Status
+	switch status {
+	case "":
+		status = StatusActive
+	case StatusActive, StatusPending:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+
 	// Create remediation
 	now := time.Now()
 	confidence := req.Confidence
@@ -535,26 +768,31 @@ func (s *service) Record(ctx context.Context, req *RecordRequest) (*Remediation,
 	}
 
 	rem := &Remediation{
-		ID:            uuid.New().String(),
-		Title:         req.Title,
-		Problem:       req.Problem,
-		Symptoms:      req.Symptoms,
-		RootCause:     req.RootCause,
-		Solution:      req.Solution,
-		CodeDiff:      req.CodeDiff,
-		AffectedFiles: req.AffectedFiles,
-		Category:      req.Category,
-		Confidence:    confidence,
-		UsageCount:    0,
-		Tags:          req.Tags,
-		Scope:         req.Scope,
-		TenantID:      req.TenantID,
-		TeamID:        req.TeamID,
-		ProjectPath:   req.ProjectPath,
-		SessionID:     req.SessionID,
-		CreatedAt:     now,
-		UpdatedAt:     now,
-	}
+		ID:              id,
+		Title:           req.Title,
+		Problem:         req.Problem,
+		Signature:       SignatureHash(req.Problem),
+		Symptoms:        req.Symptoms,
+		RootCause:       req.RootCause,
+		Solution:        req.Solution,
+		CodeDiff:        req.CodeDiff,
+		AffectedFiles:   req.AffectedFiles,
+		Category:        category.Name,
+		BuiltinCategory: category.Builtin,
+		Confidence:      confidence,
+		UsageCount:      0,
+		Revision:        1,
+		Tags:            req.Tags,
+		Scope:           req.Scope,
+		TenantID:        req.TenantID,
+		TeamID:          req.TeamID,
+		ProjectPath:     req.ProjectPath,
+		SessionID:       req.SessionID,
+		Status:          status,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	s.quarantineInjection(rem)
 
 	// Get store and collection name
 	store, collection, err := s.getStore(ctx, req.TenantID, req.Scope, req.TeamID, req.ProjectPath)
@@ -602,7 +840,7 @@ func (s *service) Record(ctx context.Context, req *RecordRequest) (*Remediation,
 		s.recordCounter.Add(ctx, 1, metric.WithAttributes(
 			attribute.String("scope", string(req.Scope)),
 			attribute.String("project_id", req.ProjectPath),
-			attribute.String("category", string(req.Category)),
+			attribute.String("category", string(rem.Category)),
 		))
 	}
 
@@ -647,8 +885,11 @@ func (s *service) Get(ctx context.Context, tenantID, remediationID string) (*Rem
 		}
 
 		exists, err := store.CollectionExists(ctx, collection)
-		if err != nil || !exists {
-			return nil, fmt.Errorf("remediation not found: %s", remediationID)
+		if err != nil {
+			return nil, fmt.Errorf("checking remediation collection: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 		}
 
 		filters := map[string]interface{}{
@@ -665,7 +906,7 @@ func (s *service) Get(ctx context.Context, tenantID, remediationID string) (*Rem
 				return rem, nil
 			}
 		}
-		return nil, fmt.Errorf("remediation not found: %s", remediationID)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 	}
 
 	// Legacy mode: try each scope level (can enumerate collections)
@@ -694,7 +935,7 @@ func (s *service) Get(ctx context.Context, tenantID, remediationID string) (*Rem
 		}
 	}
 
-	return nil, fmt.Errorf("remediation not found: %s", remediationID)
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 }
 
 // GetByScope retrieves a remediation by ID within a specific scope.
@@ -723,7 +964,7 @@ func (s *service) GetByScope(ctx context.Context, tenantID, remediationID string
 
 	exists, err := store.CollectionExists(ctx, collection)
 	if err != nil || !exists {
-		return nil, fmt.Errorf("remediation not found: %s", remediationID)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 	}
 
 	filters := map[string]interface{}{
@@ -741,7 +982,7 @@ func (s *service) GetByScope(ctx context.Context, tenantID, remediationID string
 		}
 	}
 
-	return nil, fmt.Errorf("remediation not found: %s", remediationID)
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 }
 
 // Feedback records feedback on a remediation.
@@ -762,49 +1003,51 @@ func (s *service) Feedback(ctx context.Context, req *FeedbackRequest) error {
 	}
 	s.mu.RUnlock()
 
-	// Get the remediation
-	rem, err := s.Get(ctx, req.TenantID, req.RemediationID)
-	if err != nil {
+	evidence := strings.TrimSpace(req.Evidence)
+	if err := validation.Remediation.Evidence(evidence); err != nil {
 		span.RecordError(err)
-		s.recordError(ctx, "feedback", "get_remediation_failed")
+		s.recordError(ctx, "feedback", "validation_failed")
 		return err
 	}
 
-	// Adjust confidence based on feedback
-	switch req.Rating {
-	case RatingHelpful:
-		rem.Confidence = min(rem.Confidence+s.config.FeedbackDelta, s.config.MaxConfidence)
-	case RatingNotHelpful:
-		rem.Confidence = max(rem.Confidence-s.config.FeedbackDelta, s.config.MinConfidence)
-	case RatingOutdated:
-		rem.Confidence = max(rem.Confidence-s.config.FeedbackDelta*2, s.config.MinConfidence)
-	}
-
-	rem.UsageCount++
-	rem.UpdatedAt = time.Now()
-
-	// Get store for the remediation's scope
-	store, collection, err := s.getStore(ctx, req.TenantID, rem.Scope, rem.TeamID, rem.ProjectPath)
-	if err != nil {
-		span.RecordError(err)
-		s.recordError(ctx, "feedback", "get_store_failed")
-		return err
-	}
+	// Adjust confidence on a fresh read until no concurrent write intervenes
+	var rem *Remediation
+	err := retryOnConflict(func() error {
+		var err error
+		rem, err = s.Get(ctx, req.TenantID, req.RemediationID)
+		if err != nil {
+			span.RecordError(err)
+			s.recordError(ctx, "feedback", "get_remediation_failed")
+			return err
+		}
+		if err := CheckExpectedRevision(ctx, rem); err != nil {
+			return err
+		}
+		s.applyFeedback(rem, req.Rating, req.SessionID, evidence)
 
-	// Delete old version
-	if err := store.DeleteDocumentsFromCollection(ctx, collection, []string{rem.ID}); err != nil {
+		// Get store for the remediation's scope
+		store, collection, err := s.getStore(ctx, req.TenantID, rem.Scope, rem.TeamID, rem.ProjectPath)
+		if err != nil {
+			span.RecordError(err)
+			s.recordError(ctx, "feedback", "get_store_failed")
+			return err
+		}
+		if err := s.commit(ctx, store, collection, rem); err != nil {
+			if !errors.Is(err, ErrRevisionConflict) {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				s.recordError(ctx, "feedback", "update_failed")
+			}
+			return err
+		}
+		return nil
+	})
+	if errors.Is(err, ErrRevisionConflict) {
 		span.RecordError(err)
-		s.recordError(ctx, "feedback", "delete_old_failed")
-		return fmt.Errorf("failed to delete old remediation: %w", err)
+		s.recordError(ctx, "feedback", "revision_conflict")
 	}
-
-	// Re-add with updated confidence
-	doc := s.remediationToDocument(rem, collection)
-	if _, err := store.AddDocuments(ctx, []vectorstore.Document{doc}); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		s.recordError(ctx, "feedback", "update_failed")
-		return fmt.Errorf("failed to update remediation: %w", err)
+	if err != nil {
+		return err
 	}
 
 	// Record metrics
@@ -818,12 +1061,123 @@ func (s *service) Feedback(ctx context.Context, req *FeedbackRequest) error {
 	s.logger.Info("recorded feedback",
 		zap.String("remediation_id", req.RemediationID),
 		zap.String("rating", string(req.Rating)),
+		zap.Bool("evidence", evidence != ""),
 		zap.Float64("new_confidence", rem.Confidence),
 	)
 
 	return nil
 }
 
+// applyFeedback adjusts rem's confidence for a rating and records it.
+func (s *service) applyFeedback(rem *Remediation, rating FeedbackRating, sessionID, evidence string) {
+	delta := s.config.FeedbackDelta
+	if evidence != "" && s.config.EvidenceWeight > 0 {
+		delta *= s.config.EvidenceWeight
+	}
+	previous := rem.Confidence
+	switch rating {
+	case RatingHelpful:
+		rem.Confidence = min(rem.Confidence+delta, s.config.MaxConfidence)
+	case RatingNotHelpful:
+		rem.Confidence = max(rem.Confidence-delta, s.config.MinConfidence)
+	case RatingOutdated:
+		rem.Confidence = max(rem.Confidence-delta*2, s.config.MinConfidence)
+	}
+
+	rem.Feedback = append(rem.Feedback, FeedbackRecord{
+		Timestamp:  time.Now(),
+		Rating:     rating,
+		SessionID:  sessionID,
+		Evidence:   evidence,
+		Delta:      rem.Confidence - previous,
+		Confidence: rem.Confidence,
+	})
+	if len(rem.Feedback) > MaxFeedbackRecords {
+		rem.Feedback = rem.Feedback[len(rem.Feedback)-MaxFeedbackRecords:]
+	}
+
+	rem.UsageCount++
+	rem.UpdatedAt = time.Now()
+}
+
+// Confirm activates a pending or quarantined remediation so searches return
+// it. Confirming an active remediation is a no-op.
+//
+// Note: Like Get, this method finds org-level remediations only when the
+// service uses a StoreProvider.
+func (s *service) Confirm(ctx context.Context, tenantID, remediationID string) (*Remediation, error) {
+	ctx, span := s.tracer.Start(ctx, "remediation.confirm")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant_id", tenantID),
+		attribute.String("remediation_id", remediationID),
+	)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, errors.New("service is closed")
+	}
+	s.mu.RUnlock()
+
+	var rem *Remediation
+	changed := false
+	err := retryOnConflict(func() error {
+		var err error
+		rem, err = s.Get(ctx, tenantID, remediationID)
+		if err != nil {
+			span.RecordError(err)
+			s.recordError(ctx, "confirm", "get_remediation_failed")
+			return err
+		}
+		if err := CheckExpectedRevision(ctx, rem); err != nil {
+			return err
+		}
+		if rem.Status != StatusPending && rem.Status != StatusQuarantined {
+			return nil
+		}
+
+		rem.Status = StatusActive
+		rem.QuarantineRules = nil
+		rem.UpdatedAt = time.Now()
+
+		store, collection, err := s.getStore(ctx, tenantID, rem.Scope, rem.TeamID, rem.ProjectPath)
+		if err != nil {
+			span.RecordError(err)
+			s.recordError(ctx, "confirm", "get_store_failed")
+			return err
+		}
+		if err := s.commit(ctx, store, collection, rem); err != nil {
+			if !errors.Is(err, ErrRevisionConflict) {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				s.recordError(ctx, "confirm", "update_failed")
+			}
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if errors.Is(err, ErrRevisionConflict) {
+		span.RecordError(err)
+		s.recordError(ctx, "confirm", "revision_conflict")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return rem, nil
+	}
+
+	s.logger.Info("confirmed remediation",
+		zap.String("id", rem.ID),
+		zap.String("title", rem.Title),
+	)
+
+	return rem, nil
+}
+
 // Delete removes a remediation.
 //
 // Note: This method searches org-level scope only. For project or team-scoped
@@ -853,14 +1207,14 @@ func (s *service) Delete(ctx context.Context, tenantID, remediationID string) er
 
 		exists, err := store.CollectionExists(ctx, collection)
 		if err != nil || !exists {
-			return fmt.Errorf("remediation not found: %s", remediationID)
+			return fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 		}
 
 		if err := store.DeleteDocumentsFromCollection(ctx, collection, []string{remediationID}); err == nil {
 			s.logger.Info("deleted remediation", zap.String("id", remediationID))
 			return nil
 		}
-		return fmt.Errorf("remediation not found: %s", remediationID)
+		return fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 	}
 
 	// Legacy mode: try each scope level
@@ -878,7 +1232,7 @@ func (s *service) Delete(ctx context.Context, tenantID, remediationID string) er
 		}
 	}
 
-	return fmt.Errorf("remediation not found: %s", remediationID)
+	return fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 }
 
 // DeleteByScope removes a remediation from a specific scope.
@@ -907,7 +1261,7 @@ func (s *service) DeleteByScope(ctx context.Context, tenantID, remediationID str
 
 	exists, err := store.CollectionExists(ctx, collection)
 	if err != nil || !exists {
-		return fmt.Errorf("remediation not found: %s", remediationID)
+		return fmt.Errorf("%w: %s", ErrNotFound, remediationID)
 	}
 
 	if err := store.DeleteDocumentsFromCollection(ctx, collection, []string{remediationID}); err != nil {
@@ -940,20 +1294,29 @@ func (s *service) remediationToDocument(r *Remediation, collectionName string) v
 		content += "\n\nSymptoms: " + joinStrings(r.Symptoms, ", ")
 	}
 
+	// Remediations recorded before signatures existed get one when rewritten
+	signature := r.Signature
+	if signature == "" {
+		signature = SignatureHash(r.Problem)
+	}
+
 	metadata := map[string]interface{}{
 		"id":           r.ID,
 		"title":        r.Title,
 		"problem":      r.Problem,
+		"signature":    signature,
 		"root_cause":   r.RootCause,
 		"solution":     r.Solution,
 		"category":     string(r.Category),
 		"confidence":   r.Confidence,
 		"usage_count":  r.UsageCount,
+		"revision":     r.Revision,
 		"scope":        string(r.Scope),
 		"tenant_id":    r.TenantID,
 		"team_id":      r.TeamID,
 		"project_path": r.ProjectPath,
 		"session_id":   r.SessionID,
+		"status":       string(r.Status),
 		"created_at":   r.CreatedAt.Unix(),
 		"updated_at":   r.UpdatedAt.Unix(),
 	}
@@ -962,6 +1325,10 @@ func (s *service) remediationToDocument(r *Remediation, collectionName string) v
 		metadata["code_diff"] = r.CodeDiff
 	}
 
+	if r.BuiltinCategory != "" {
+		metadata["builtin_category"] = string(r.BuiltinCategory)
+	}
+
 	if len(r.Symptoms) > 0 {
 		metadata["symptoms"] = joinStrings(r.Symptoms, "||")
 	}
@@ -974,6 +1341,14 @@ func (s *service) remediationToDocument(r *Remediation, collectionName string) v
 		metadata["tags"] = joinStrings(r.Tags, "||")
 	}
 
+	if feedback := encodeFeedback(r.Feedback); feedback != "" {
+		metadata["feedback"] = feedback
+	}
+
+	if len(r.QuarantineRules) > 0 {
+		metadata["quarantine_rules"] = joinStrings(r.QuarantineRules, "||")
+	}
+
 	return vectorstore.Document{
 		ID:         r.ID,
 		Content:    content,
@@ -1003,6 +1378,9 @@ func (s *service) resultToRemediation(result vectorstore.SearchResult) *Remediat
 	if v, ok := result.Metadata["problem"].(string); ok {
 		r.Problem = v
 	}
+	if v, ok := result.Metadata["signature"].(string); ok {
+		r.Signature = v
+	}
 	if v, ok := result.Metadata["root_cause"].(string); ok {
 		r.RootCause = v
 	}
@@ -1015,6 +1393,9 @@ func (s *service) resultToRemediation(result vectorstore.SearchResult) *Remediat
 	if v, ok := result.Metadata["category"].(string); ok {
 		r.Category = ErrorCategory(v)
 	}
+	if v, ok := result.Metadata["builtin_category"].(string); ok {
+		r.BuiltinCategory = ErrorCategory(v)
+	}
 	if v, ok := result.Metadata["confidence"].(float64); ok {
 		r.Confidence = v
 	}
@@ -1023,6 +1404,11 @@ func (s *service) resultToRemediation(result vectorstore.SearchResult) *Remediat
 	} else if v, ok := result.Metadata["usage_count"].(float64); ok {
 		r.UsageCount = int64(v)
 	}
+	if v, ok := result.Metadata["revision"].(int64); ok {
+		r.Revision = v
+	} else if v, ok := result.Metadata["revision"].(float64); ok {
+		r.Revision = int64(v)
+	}
 	if v, ok := result.Metadata["scope"].(string); ok {
 		r.Scope = Scope(v)
 	}
@@ -1038,6 +1424,10 @@ func (s *service) resultToRemediation(result vectorstore.SearchResult) *Remediat
 	if v, ok := result.Metadata["session_id"].(string); ok {
 		r.SessionID = v
 	}
+	r.Status = StatusActive
+	if v, ok := result.Metadata["status"].(string); ok && v != "" {
+		r.Status = Status(v)
+	}
 	if v, ok := result.Metadata["created_at"].(int64); ok {
 		r.CreatedAt = time.Unix(v, 0)
 	} else if v, ok := result.Metadata["created_at"].(float64); ok {
@@ -1064,9 +1454,43 @@ func (s *service) resultToRemediation(result vectorstore.SearchResult) *Remediat
 		r.Tags = splitByDelimiter(v, "||")
 	}
 
+	if v, ok := result.Metadata["feedback"].(string); ok {
+		r.Feedback = decodeFeedback(v)
+	}
+
+	if v, ok := result.Metadata["quarantine_rules"].(string); ok && v != "" {
+		r.QuarantineRules = splitByDelimiter(v, "||")
+	}
+
 	return r
 }
 
+// encodeFeedback serializes feedback records as a JSON string, since
+// metadata values must be scalars. It returns "" when there are none.
+func encodeFeedback(records []FeedbackRecord) string {
+	if len(records) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// decodeFeedback parses feedback stored by encodeFeedback, ignoring
+// malformed values.
+func decodeFeedback(value string) []FeedbackRecord {
+	if value == "" {
+		return nil
+	}
+	var records []FeedbackRecord
+	if err := json.Unmarshal([]byte(value), &records); err != nil {
+		return nil
+	}
+	return records
+}
+
 // joinStrings joins strings with a delimiter.
 func joinStrings(strs []string, delimiter string) string {
 	result := ""
@@ -1083,16 +1507,19 @@ func remediationToPayload(r *Remediation) map[string]interface{} {
 	payload := map[string]interface{}{
 		"title":        r.Title,
 		"problem":      r.Problem,
+		"signature":    r.Signature,
 		"root_cause":   r.RootCause,
 		"solution":     r.Solution,
 		"category":     string(r.Category),
 		"confidence":   r.Confidence,
 		"usage_count":  r.UsageCount,
+		"revision":     r.Revision,
 		"scope":        string(r.Scope),
 		"tenant_id":    r.TenantID,
 		"team_id":      r.TeamID,
 		"project_path": r.ProjectPath,
 		"session_id":   r.SessionID,
+		"status":       string(r.Status),
 		"created_at":   r.CreatedAt.Unix(),
 		"updated_at":   r.UpdatedAt.Unix(),
 	}
@@ -1101,6 +1528,10 @@ func remediationToPayload(r *Remediation) map[string]interface{} {
 		payload["code_diff"] = r.CodeDiff
 	}
 
+	if r.BuiltinCategory != "" {
+		payload["builtin_category"] = string(r.BuiltinCategory)
+	}
+
 	// Note: Symptoms and AffectedFiles are slices, we'll store them as JSON strings or comma-separated
 	// For now, store as comma-separated strings for simple Qdrant payload compatibility
 	if len(r.Symptoms) > 0 {
@@ -1136,6 +1567,14 @@ func remediationToPayload(r *Remediation) map[string]interface{} {
 		payload["tags"] = tagsStr
 	}
 
+	if feedback := encodeFeedback(r.Feedback); feedback != "" {
+		payload["feedback"] = feedback
+	}
+
+	if len(r.QuarantineRules) > 0 {
+		payload["quarantine_rules"] = joinStrings(r.QuarantineRules, "||")
+	}
+
 	return payload
 }
 
@@ -1152,6 +1591,9 @@ func payloadToRemediation(payload map[string]interface{}) *Remediation {
 	if v, ok := payload["problem"].(string); ok {
 		r.Problem = v
 	}
+	if v, ok := payload["signature"].(string); ok {
+		r.Signature = v
+	}
 	if v, ok := payload["root_cause"].(string); ok {
 		r.RootCause = v
 	}
@@ -1164,12 +1606,18 @@ func payloadToRemediation(payload map[string]interface{}) *Remediation {
 	if v, ok := payload["category"].(string); ok {
 		r.Category = ErrorCategory(v)
 	}
+	if v, ok := payload["builtin_category"].(string); ok {
+		r.BuiltinCategory = ErrorCategory(v)
+	}
 	if v, ok := payload["confidence"].(float64); ok {
 		r.Confidence = v
 	}
 	if v, ok := payload["usage_count"].(int64); ok {
 		r.UsageCount = v
 	}
+	if v, ok := payload["revision"].(int64); ok {
+		r.Revision = v
+	}
 	if v, ok := payload["scope"].(string); ok {
 		r.Scope = Scope(v)
 	}
@@ -1185,6 +1633,10 @@ func payloadToRemediation(payload map[string]interface{}) *Remediation {
 	if v, ok := payload["session_id"].(string); ok {
 		r.SessionID = v
 	}
+	r.Status = StatusActive
+	if v, ok := payload["status"].(string); ok && v != "" {
+		r.Status = Status(v)
+	}
 	if v, ok := payload["created_at"].(int64); ok {
 		r.CreatedAt = time.Unix(v, 0)
 	}
@@ -1222,6 +1674,14 @@ func payloadToRemediation(payload map[string]interface{}) *Remediation {
 		}
 	}
 
+	if v, ok := payload["feedback"].(string); ok {
+		r.Feedback = decodeFeedback(v)
+	}
+
+	if v, ok := payload["quarantine_rules"].(string); ok && v != "" {
+		r.QuarantineRules = splitByDelimiter(v, "||")
+	}
+
 	return r
 }
 
@@ -1246,8 +1706,13 @@ func splitByDelimiter(s, delimiter string) []string {
 }
 
 func sortAndLimit(remediations []*ScoredRemediation, limit int) []*ScoredRemediation {
+	// Break score ties by ID so results merged from several scopes have a
+	// deterministic order across paginated calls.
 	sort.Slice(remediations, func(i, j int) bool {
-		return remediations[i].Score > remediations[j].Score
+		if remediations[i].Score != remediations[j].Score {
+			return remediations[i].Score > remediations[j].Score
+		}
+		return remediations[i].ID < remediations[j].ID
 	})
 
 	if len(remediations) > limit {
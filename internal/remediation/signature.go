@@ -0,0 +1,56 @@
+package remediation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// signatureRules rewrite the parts of an error that change between
+// occurrences of the same failure. Order matters: timestamps and UUIDs are
+// replaced before the generic number rules see their digits.
+var signatureRules = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// 2026-01-21T09:00:00.123Z, 2026/01/21 09:00:00
+	{regexp.MustCompile(`\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), "<ts>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	// Pointers, offsets and other hex values: 0xc000123456, +0x1d
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), "<addr>"},
+	// Directories of file paths, so traces from different checkouts match
+	{regexp.MustCompile(`(?:[A-Za-z]:)?(?:[\w.~-]*[/\\])+([\w.-]+\.\w+)`), "$1"},
+	// file.go:42:7, File.java:42
+	{regexp.MustCompile(`(\.\w+):\d+(?::\d+)?`), "$1:<line>"},
+	{regexp.MustCompile(`(?i)\b(line|col|column) \d+`), "$1 <n>"},
+	{regexp.MustCompile(`\bgoroutine \d+`), "goroutine <n>"},
+	// Long hex strings: commit SHAs, request and trace IDs
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{12,}\b`), "<id>"},
+	// Numeric IDs, ports and PIDs; short numbers such as status codes stay
+	{regexp.MustCompile(`\b\d{4,}\b`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// Signature canonicalizes an error message or stack trace so recurrences of
+// the same failure compare equal: timestamps, UUIDs, addresses, line
+// numbers, file directories and long numeric or hex IDs are replaced with
+// placeholders and whitespace is collapsed.
+func Signature(text string) string {
+	sig := text
+	for _, rule := range signatureRules {
+		sig = rule.re.ReplaceAllString(sig, rule.repl)
+	}
+	return strings.TrimSpace(sig)
+}
+
+// SignatureHash returns the hex SHA-256 of the text's Signature, or "" when
+// the text has no signature.
+func SignatureHash(text string) string {
+	sig := Signature(text)
+	if sig == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(sig))
+	return hex.EncodeToString(sum[:])
+}
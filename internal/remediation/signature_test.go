@@ -0,0 +1,53 @@
+package remediation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "go panic",
+			in:   "panic: nil map\ngoroutine 17 [running]:\nmain.run(0xc000123456)\n\t/home/alice/api/main.go:42 +0x1d",
+			want: "panic: nil map goroutine <n> [running]: main.run(<addr>) main.go:<line> +<addr>",
+		},
+		{
+			name: "timestamps and ids",
+			in:   "2026-01-21T09:00:00.123Z request 550e8400-e29b-41d4-a716-446655440000 failed: pid 48213",
+			want: "<ts> request <uuid> failed: pid <n>",
+		},
+		{
+			name: "python traceback",
+			in:   `File "/srv/app/views.py", line 88, in get`,
+			want: `File "views.py", line <n>, in get`,
+		},
+		{
+			name: "commit and status code",
+			in:   "fetch 3f786850e387550fdab836ed7e6dc881de23001b: HTTP 404",
+			want: "fetch <id>: HTTP 404",
+		},
+		{name: "whitespace only", in: " \n\t", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Signature(tt.in))
+		})
+	}
+}
+
+func TestSignatureHash(t *testing.T) {
+	a := SignatureHash("open /tmp/build-1234/config.yaml: no such file or directory")
+	b := SignatureHash("open  /var/tmp/build-98765/config.yaml: no such file or directory")
+	assert.Equal(t, a, b)
+	assert.Len(t, a, 64)
+
+	assert.NotEqual(t, a, SignatureHash("open config.yaml: permission denied"))
+	assert.Empty(t, SignatureHash("   "))
+}
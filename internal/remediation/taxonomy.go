@@ -0,0 +1,170 @@
+package remediation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// builtinCategories are the categories every tenant can record under.
+var builtinCategories = []ErrorCategory{
+	ErrorCompile, ErrorRuntime, ErrorTest, ErrorLint, ErrorSecurity, ErrorPerformance, ErrorOther,
+}
+
+// BuiltinCategories returns the built-in error categories.
+func BuiltinCategories() []ErrorCategory {
+	return slices.Clone(builtinCategories)
+}
+
+// IsBuiltin reports whether c is a built-in error category.
+func IsBuiltin(c ErrorCategory) bool {
+	return slices.Contains(builtinCategories, c)
+}
+
+// ErrUnknownCategory is returned when a remediation is recorded or migrated
+// under a category that is not in the tenant's taxonomy.
+var ErrUnknownCategory = ctxerrors.New(ctxerrors.CodeInvalidInput, "unknown remediation category")
+
+// Category is one category of an organization's taxonomy.
+type Category struct {
+	// Name is the category remediations are stored under.
+	Name ErrorCategory `json:"name"`
+
+	// Builtin is the built-in category Name refines. Consumers that only
+	// understand built-in categories use it.
+	Builtin ErrorCategory `json:"builtin"`
+
+	// Description explains when to use the category.
+	Description string `json:"description,omitempty"`
+
+	// Aliases are former names of the category, such as the name before a
+	// rename. Records and searches under an alias use Name; remediations
+	// stored under an alias keep it until migrated with MigrateCategory.
+	Aliases []ErrorCategory `json:"aliases,omitempty"`
+}
+
+// Taxonomy is the set of categories an organization records remediations
+// under, in addition to the built-in ones.
+type Taxonomy struct {
+	categories []Category
+	byName     map[ErrorCategory]int // index into categories by name and alias
+}
+
+// NewTaxonomy validates categories and builds a taxonomy. Names and aliases
+// must be unique, every category must refine a built-in category, and a
+// category named after a built-in one must refine itself.
+func NewTaxonomy(categories []Category) (*Taxonomy, error) {
+	t := &Taxonomy{byName: make(map[ErrorCategory]int)}
+	for i, c := range categories {
+		c.Name = normalizeCategory(c.Name)
+		c.Builtin = normalizeCategory(c.Builtin)
+		if c.Name == "" {
+			return nil, fmt.Errorf("category %d: name is required", i+1)
+		}
+		if !IsBuiltin(c.Builtin) {
+			return nil, fmt.Errorf("category %q: builtin must be one of %s, got %q", c.Name, joinCategories(builtinCategories), c.Builtin)
+		}
+		if IsBuiltin(c.Name) && c.Name != c.Builtin {
+			return nil, fmt.Errorf("category %q: a built-in category can only refine itself", c.Name)
+		}
+		aliases := make([]ErrorCategory, 0, len(c.Aliases))
+		for _, alias := range c.Aliases {
+			aliases = append(aliases, normalizeCategory(alias))
+		}
+		c.Aliases = aliases
+
+		for _, name := range append([]ErrorCategory{c.Name}, c.Aliases...) {
+			if name == "" {
+				return nil, fmt.Errorf("category %q: empty alias", c.Name)
+			}
+			if j, ok := t.byName[name]; ok {
+				return nil, fmt.Errorf("category %q: %q is already used by %q", c.Name, name, t.categories[j].Name)
+			}
+			t.byName[name] = len(t.categories)
+		}
+		t.categories = append(t.categories, c)
+	}
+	return t, nil
+}
+
+// Categories returns the taxonomy's categories followed by the built-in
+// categories it does not redefine.
+func (t *Taxonomy) Categories() []Category {
+	var out []Category
+	if t != nil {
+		out = append(out, t.categories...)
+	}
+	for _, b := range builtinCategories {
+		if _, ok := t.lookup(b); !ok {
+			out = append(out, Category{Name: b, Builtin: b})
+		}
+	}
+	return out
+}
+
+// Resolve returns the category name refers to, following aliases. Built-in
+// categories always resolve, so automated sources such as CI failure drafts
+// keep working under any taxonomy.
+func (t *Taxonomy) Resolve(name ErrorCategory) (Category, bool) {
+	name = normalizeCategory(name)
+	if c, ok := t.lookup(name); ok {
+		return c, true
+	}
+	if IsBuiltin(name) {
+		return Category{Name: name, Builtin: name}, true
+	}
+	return Category{}, false
+}
+
+func (t *Taxonomy) lookup(name ErrorCategory) (Category, bool) {
+	if t == nil {
+		return Category{}, false
+	}
+	i, ok := t.byName[name]
+	if !ok {
+		return Category{}, false
+	}
+	return t.categories[i], true
+}
+
+// taxonomy returns the tenant's taxonomy, or nil when it has none.
+func (s *service) taxonomy(tenantID string) *Taxonomy {
+	return s.config.Taxonomies[tenantID]
+}
+
+// resolveCategory maps a requested category onto the tenant's taxonomy.
+// Tenants without a taxonomy record any category unchanged.
+func (s *service) resolveCategory(tenantID string, name ErrorCategory) (Category, error) {
+	t := s.taxonomy(tenantID)
+	if t == nil {
+		c := Category{Name: name}
+		if IsBuiltin(name) {
+			c.Builtin = name
+		}
+		return c, nil
+	}
+	c, ok := t.Resolve(name)
+	if !ok {
+		names := make([]ErrorCategory, 0, len(t.Categories()))
+		for _, c := range t.Categories() {
+			names = append(names, c.Name)
+		}
+		return Category{}, ctxerrors.Wrap(ctxerrors.CodeInvalidInput,
+			fmt.Errorf("%w %q (valid: %s)", ErrUnknownCategory, name, joinCategories(names)))
+	}
+	return c, nil
+}
+
+func normalizeCategory(c ErrorCategory) ErrorCategory {
+	return ErrorCategory(strings.ToLower(strings.TrimSpace(string(c))))
+}
+
+func joinCategories(categories []ErrorCategory) string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,211 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewTaxonomy(t *testing.T) {
+	tax, err := NewTaxonomy([]Category{
+		{Name: "Flaky-Test", Builtin: "test", Aliases: []ErrorCategory{"flaky"}},
+		{Name: "security", Builtin: "security", Description: "Vulnerabilities and secrets"},
+	})
+	require.NoError(t, err)
+
+	c, ok := tax.Resolve("flaky")
+	require.True(t, ok)
+	assert.Equal(t, ErrorCategory("flaky-test"), c.Name)
+	assert.Equal(t, ErrorTest, c.Builtin)
+
+	c, ok = tax.Resolve("compile")
+	require.True(t, ok, "built-in categories always resolve")
+	assert.Equal(t, ErrorCompile, c.Builtin)
+
+	_, ok = tax.Resolve("dependency")
+	assert.False(t, ok)
+
+	names := make([]ErrorCategory, 0)
+	for _, c := range tax.Categories() {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []ErrorCategory{"flaky-test", "security", "compile", "runtime", "test", "lint", "performance", "other"}, names)
+
+	for name, categories := range map[string][]Category{
+		"unknown builtin":  {{Name: "dependency", Builtin: "build"}},
+		"builtin refining": {{Name: "lint", Builtin: "compile"}},
+		"duplicate alias":  {{Name: "a", Builtin: "test"}, {Name: "b", Builtin: "test", Aliases: []ErrorCategory{"a"}}},
+	} {
+		_, err := NewTaxonomy(categories)
+		assert.Error(t, err, name)
+	}
+}
+
+func newTaxonomyService(t *testing.T) Service {
+	t.Helper()
+	tax, err := NewTaxonomy([]Category{
+		{Name: "flaky-test", Builtin: "test", Aliases: []ErrorCategory{"flaky"}},
+		{Name: "timeout", Builtin: "runtime"},
+		{Name: "dependency", Builtin: "compile"},
+	})
+	require.NoError(t, err)
+	cfg := DefaultServiceConfig()
+	cfg.Taxonomies = map[string]*Taxonomy{"acme": tax}
+	svc, err := NewService(cfg, newMockStore(), zap.NewNop())
+	require.NoError(t, err)
+	return svc
+}
+
+func recordCategory(t *testing.T, svc Service, tenantID string, category ErrorCategory, title string) (*Remediation, error) {
+	t.Helper()
+	return svc.Record(context.Background(), &RecordRequest{
+		Title:     title,
+		Problem:   title,
+		RootCause: "Test root cause",
+		Solution:  "Test solution",
+		Category:  category,
+		Scope:     ScopeOrg,
+		TenantID:  tenantID,
+	})
+}
+
+func TestService_Record_Taxonomy(t *testing.T) {
+	svc := newTaxonomyService(t)
+
+	rem, err := recordCategory(t, svc, "acme", "flaky", "Race in upload test")
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCategory("flaky-test"), rem.Category, "aliases record under their category")
+	assert.Equal(t, ErrorTest, rem.BuiltinCategory)
+
+	rem, err = recordCategory(t, svc, "acme", ErrorCompile, "Missing import")
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCompile, rem.BuiltinCategory)
+
+	_, err = recordCategory(t, svc, "acme", "network", "Connection refused")
+	require.ErrorIs(t, err, ErrUnknownCategory)
+	assert.Equal(t, ctxerrors.CodeInvalidInput, ctxerrors.CodeOf(err))
+	assert.Contains(t, err.Error(), "flaky-test")
+
+	// Tenants without a taxonomy record any category
+	rem, err = recordCategory(t, svc, "other", "network", "Connection refused")
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCategory("network"), rem.Category)
+	assert.Empty(t, rem.BuiltinCategory)
+}
+
+func TestService_CategoryStats(t *testing.T) {
+	ctx := context.Background()
+	svc := newTaxonomyService(t)
+
+	for _, title := range []string{"Race in upload test", "Clock skew in token test"} {
+		_, err := recordCategory(t, svc, "acme", "flaky-test", title)
+		require.NoError(t, err)
+	}
+	_, err := recordCategory(t, svc, "acme", ErrorRuntime, "Nil session")
+	require.NoError(t, err)
+
+	stats, err := svc.CategoryStats(ctx, &CategoryStatsRequest{TenantID: "acme", Scope: ScopeOrg})
+	require.NoError(t, err)
+
+	byName := make(map[ErrorCategory]CategoryStats)
+	for _, st := range stats {
+		byName[st.Category] = st
+	}
+	assert.Equal(t, ErrorCategory("flaky-test"), stats[0].Category, "ordered by count")
+	assert.Equal(t, 2, byName["flaky-test"].Count)
+	assert.Equal(t, ErrorTest, byName["flaky-test"].Builtin)
+	assert.InDelta(t, 0.5, byName["flaky-test"].AvgConfidence, 1e-9)
+	assert.False(t, byName["flaky-test"].LastRecorded.IsZero())
+	assert.Equal(t, 1, byName["runtime"].Count)
+
+	// Every taxonomy category is listed, recorded under or not
+	assert.Equal(t, 0, byName["dependency"].Count)
+	assert.True(t, byName["dependency"].InTaxonomy)
+}
+
+func TestService_MigrateCategory(t *testing.T) {
+	ctx := context.Background()
+
+	// Remediations recorded before the taxonomy existed
+	store := newMockStore()
+	legacy, err := NewService(DefaultServiceConfig(), store, zap.NewNop())
+	require.NoError(t, err)
+	for _, title := range []string{"Upload test times out", "Flaky retry test", "Connection reset by peer"} {
+		_, err := recordCategory(t, legacy, "acme", "network", title)
+		require.NoError(t, err)
+	}
+
+	tax, err := NewTaxonomy([]Category{
+		{Name: "timeout", Builtin: "runtime"},
+		{Name: "flaky-test", Builtin: "test"},
+		{Name: "connectivity", Builtin: "runtime"},
+	})
+	require.NoError(t, err)
+	cfg := DefaultServiceConfig()
+	cfg.Taxonomies = map[string]*Taxonomy{"acme": tax}
+	svc, err := NewService(cfg, store, zap.NewNop())
+	require.NoError(t, err)
+
+	stats, err := svc.CategoryStats(ctx, &CategoryStatsRequest{TenantID: "acme", Scope: ScopeOrg})
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCategory("network"), stats[0].Category)
+	assert.False(t, stats[0].InTaxonomy, "retired categories are flagged for migration")
+
+	req := &MigrateCategoryRequest{
+		TenantID: "acme",
+		Scope:    ScopeOrg,
+		From:     "network",
+		To:       "connectivity",
+		Split: []SplitRule{
+			{Category: "timeout", Keywords: []string{"times out", "deadline"}},
+			{Category: "flaky-test", Keywords: []string{"FLAKY"}},
+		},
+		DryRun: true,
+	}
+	result, err := svc.MigrateCategory(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, result.Moves, 3)
+	moved := make(map[string]ErrorCategory)
+	for _, m := range result.Moves {
+		moved[m.Title] = m.To
+	}
+	assert.Equal(t, map[string]ErrorCategory{
+		"Upload test times out":    "timeout",
+		"Flaky retry test":         "flaky-test",
+		"Connection reset by peer": "connectivity",
+	}, moved)
+
+	stats, err = svc.CategoryStats(ctx, &CategoryStatsRequest{TenantID: "acme", Scope: ScopeOrg})
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCategory("network"), stats[0].Category, "dry runs move nothing")
+
+	req.DryRun = false
+	result, err = svc.MigrateCategory(ctx, req)
+	require.NoError(t, err)
+	assert.Len(t, result.Moves, 3)
+
+	rem, err := svc.Get(ctx, "acme", result.Moves[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, result.Moves[0].To, rem.Category)
+	assert.NotEmpty(t, rem.BuiltinCategory)
+
+	stats, err = svc.CategoryStats(ctx, &CategoryStatsRequest{TenantID: "acme", Scope: ScopeOrg})
+	require.NoError(t, err)
+	for _, st := range stats {
+		assert.NotEqual(t, ErrorCategory("network"), st.Category)
+	}
+
+	t.Run("rejects targets outside the taxonomy", func(t *testing.T) {
+		_, err := svc.MigrateCategory(ctx, &MigrateCategoryRequest{TenantID: "acme", Scope: ScopeOrg, From: "timeout", To: "dns"})
+		assert.ErrorIs(t, err, ErrUnknownCategory)
+	})
+
+	t.Run("requires a target", func(t *testing.T) {
+		_, err := svc.MigrateCategory(ctx, &MigrateCategoryRequest{TenantID: "acme", Scope: ScopeOrg, From: "timeout"})
+		assert.Equal(t, ctxerrors.CodeInvalidInput, ctxerrors.CodeOf(err))
+	})
+}
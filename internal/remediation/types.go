@@ -36,6 +36,22 @@ const (
 	ScopeOrg Scope = "org"
 )
 
+// Status is the review state of a remediation.
+type Status string
+
+const (
+	// StatusActive remediations are returned by searches. Remediations stored
+	// without a status are active.
+	StatusActive Status = "active"
+	// StatusPending remediations are drafts awaiting human confirmation and
+	// are only returned by searches for pending remediations.
+	StatusPending Status = "pending"
+	// StatusQuarantined remediations were flagged as possible prompt
+	// injection when recorded. Like drafts, they are only returned by
+	// searches for quarantined remediations until confirmed.
+	StatusQuarantined Status = "quarantined"
+)
+
 // Remediation represents a stored error fix pattern.
 type Remediation struct {
 	// ID is the unique identifier for this remediation.
@@ -47,6 +63,10 @@ type Remediation struct {
 	// Problem is a description of the error or issue.
 	Problem string `json:"problem"`
 
+	// Signature is the SignatureHash of Problem. Searches whose query has
+	// the same signature return this remediation as an exact match.
+	Signature string `json:"signature,omitempty"`
+
 	// Symptoms are observable symptoms of this error.
 	Symptoms []string `json:"symptoms"`
 
@@ -65,12 +85,21 @@ type Remediation struct {
 	// Category is the error category.
 	Category ErrorCategory `json:"category"`
 
+	// BuiltinCategory is the built-in category Category refines under the
+	// tenant's taxonomy. It is empty for categories outside any taxonomy.
+	BuiltinCategory ErrorCategory `json:"builtin_category,omitempty"`
+
 	// Confidence is the current confidence score (0.0 - 1.0).
 	Confidence float64 `json:"confidence"`
 
 	// UsageCount is how many times this remediation has been retrieved.
 	UsageCount int64 `json:"usage_count"`
 
+	// Revision counts writes to the remediation, starting at 1. Updates
+	// made from a stale copy fail with ErrRevisionConflict. Remediations
+	// stored before revisions existed read as 0.
+	Revision int64 `json:"revision"`
+
 	// Tags are labels for categorization and filtering.
 	Tags []string `json:"tags"`
 
@@ -89,6 +118,17 @@ type Remediation struct {
 	// SessionID is the session this remediation was extracted from.
 	SessionID string `json:"session_id,omitempty"`
 
+	// Status is the review state; empty means active.
+	Status Status `json:"status,omitempty"`
+
+	// QuarantineRules lists the injection rules that matched a quarantined
+	// remediation.
+	QuarantineRules []string `json:"quarantine_rules,omitempty"`
+
+	// Feedback is the most recent feedback, oldest first, capped at
+	// MaxFeedbackRecords, so reviewers can audit confidence changes.
+	Feedback []FeedbackRecord `json:"feedback,omitempty"`
+
 	// CreatedAt is when this remediation was created.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -103,6 +143,10 @@ type Remediation struct {
 type ScoredRemediation struct {
 	Remediation
 	Score float64 `json:"score"`
+
+	// SignatureMatch is set when the remediation was found by an exact
+	// error-signature match rather than by semantic similarity.
+	SignatureMatch bool `json:"signature_match,omitempty"`
 }
 
 // SearchRequest represents parameters for remediation search.
@@ -140,10 +184,18 @@ type SearchRequest struct {
 	// IncludeHierarchy includes parent scopes in search.
 	// If searching project scope, also searches team and org.
 	IncludeHierarchy bool
+
+	// Status selects active (default), pending or quarantined remediations.
+	Status Status
+
+	// Cursor resumes a previous SearchPage call (optional).
+	// Ignored by Search.
+	Cursor string
 }
 
 // RecordRequest represents parameters for recording a remediation.
 type RecordRequest struct {
+	ID            string // Optional; generated when empty
 	Title         string
 	Problem       string
 	Symptoms      []string
@@ -159,6 +211,7 @@ type RecordRequest struct {
 	ProjectPath   string
 	SessionID     string
 	Confidence    float64 // Initial confidence (default: 0.5)
+	Status        Status  // StatusPending records a draft (default: active)
 }
 
 // FeedbackRequest represents parameters for providing feedback on a remediation.
@@ -168,6 +221,28 @@ type FeedbackRequest struct {
 	Rating        FeedbackRating
 	SessionID     string
 	Comment       string
+
+	// Evidence is what the agent actually did with the remediation, such
+	// as the command it ran and what happened. Evidence-backed feedback
+	// moves confidence Config.EvidenceWeight times as far.
+	Evidence string
+}
+
+// MaxFeedbackRecords caps the feedback kept on a remediation.
+const MaxFeedbackRecords = 50
+
+// FeedbackRecord is one piece of feedback on a remediation.
+type FeedbackRecord struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Rating    FeedbackRating `json:"rating"`
+	SessionID string         `json:"session_id,omitempty"`
+	Evidence  string         `json:"evidence,omitempty"`
+
+	// Delta is the confidence change the feedback caused.
+	Delta float64 `json:"delta"`
+
+	// Confidence is the confidence after the feedback.
+	Confidence float64 `json:"confidence"`
 }
 
 // FeedbackRating represents a feedback rating.
@@ -181,3 +256,95 @@ const (
 	// RatingOutdated indicates the remediation is outdated.
 	RatingOutdated FeedbackRating = "outdated"
 )
+
+// CategoryStatsRequest selects the remediations CategoryStats counts. Scopes
+// are chosen as for SearchRequest.
+type CategoryStatsRequest struct {
+	TenantID         string
+	Scope            Scope
+	TeamID           string
+	ProjectPath      string
+	IncludeHierarchy bool
+}
+
+// ListRequest selects the remediations List returns. Scopes are chosen as
+// for SearchRequest.
+type ListRequest struct {
+	TenantID         string
+	Scope            Scope
+	TeamID           string
+	ProjectPath      string
+	IncludeHierarchy bool
+
+	// Since keeps remediations created at or after it; zero keeps all.
+	Since time.Time
+
+	// MinConfidence keeps remediations at or above it.
+	MinConfidence float64
+}
+
+// CategoryStats summarizes the remediations stored under one category.
+type CategoryStats struct {
+	Category    ErrorCategory `json:"category"`
+	Builtin     ErrorCategory `json:"builtin,omitempty"`
+	Description string        `json:"description,omitempty"`
+
+	// InTaxonomy is false for categories the tenant's taxonomy no longer
+	// defines, such as names retired by a rename; migrate them with
+	// MigrateCategory.
+	InTaxonomy bool `json:"in_taxonomy"`
+
+	Count         int       `json:"count"`
+	Pending       int       `json:"pending"`
+	Quarantined   int       `json:"quarantined"`
+	AvgConfidence float64   `json:"avg_confidence"`
+	UsageCount    int64     `json:"usage_count"`
+	LastRecorded  time.Time `json:"last_recorded,omitzero"`
+}
+
+// MigrateCategoryRequest moves remediations out of category From, renaming
+// it to To or splitting it by keyword. Scopes are chosen as for
+// SearchRequest.
+type MigrateCategoryRequest struct {
+	TenantID         string
+	Scope            Scope
+	TeamID           string
+	ProjectPath      string
+	IncludeHierarchy bool
+
+	From ErrorCategory
+
+	// To receives remediations that match no Split rule. When empty,
+	// unmatched remediations stay in From.
+	To ErrorCategory
+
+	// Split rules are tried in order; a remediation moves to the first
+	// rule with a keyword in its title, problem, symptoms, root cause or
+	// tags (case-insensitive).
+	Split []SplitRule
+
+	// DryRun reports the moves without making them.
+	DryRun bool
+}
+
+// SplitRule moves remediations mentioning any of Keywords to Category.
+type SplitRule struct {
+	Category ErrorCategory `json:"category"`
+	Keywords []string      `json:"keywords"`
+}
+
+// CategoryMove is one remediation moved by MigrateCategory.
+type CategoryMove struct {
+	ID    string        `json:"id"`
+	Title string        `json:"title"`
+	To    ErrorCategory `json:"to"`
+}
+
+// MigrateCategoryResult reports what MigrateCategory moved, or would move
+// for a dry run.
+type MigrateCategoryResult struct {
+	From      ErrorCategory  `json:"from"`
+	Moves     []CategoryMove `json:"moves"`
+	Unmatched int            `json:"unmatched"`
+	DryRun    bool           `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// DocType is the kind of a knowledge document, such as an architecture
+// decision record, found while indexing a repository.
+type DocType string
+
+const (
+	// DocTypeADR is an architecture decision record.
+	DocTypeADR DocType = "adr"
+
+	// DocTypeRFC is a request for comments or design proposal.
+	DocTypeRFC DocType = "rfc"
+
+	// DocTypeRunbook is an operational runbook or playbook.
+	DocTypeRunbook DocType = "runbook"
+
+	// DocTypePostmortem is an incident postmortem.
+	DocTypePostmortem DocType = "postmortem"
+)
+
+// Metadata keys of the knowledge documents stored by IndexFiles.
+const (
+	MetadataDocType   = "doc_type"
+	MetadataDocTitle  = "doc_title"
+	MetadataDocStatus = "doc_status"
+	MetadataDocOwners = "doc_owners" // comma-separated
+)
+
+// ErrInvalidDocType is returned for an unknown document type.
+var ErrInvalidDocType = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid document type")
+
+// ParseDocType parses a document type name, case-insensitively. Empty
+// means no type.
+func ParseDocType(name string) (DocType, error) {
+	switch t := DocType(strings.ToLower(strings.TrimSpace(name))); t {
+	case "", DocTypeADR, DocTypeRFC, DocTypeRunbook, DocTypePostmortem:
+		return t, nil
+	default:
+		return "", fmt.Errorf("%w %q: must be adr, rfc, runbook or postmortem", ErrInvalidDocType, name)
+	}
+}
+
+// DocInfo is what indexing extracts from a knowledge document.
+type DocInfo struct {
+	Type   DocType
+	Title  string
+	Status string   // lowercased first word, e.g. "accepted"
+	Owners []string // owners, authors or deciders
+}
+
+// docExtensions are the file extensions of prose documents.
+var docExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+	".mdx":      true,
+	".rst":      true,
+	".adoc":     true,
+	".txt":      true,
+}
+
+// docDirs maps directory names to the type of the documents they hold.
+var docDirs = map[string]DocType{
+	"adr":                    DocTypeADR,
+	"adrs":                   DocTypeADR,
+	"decisions":              DocTypeADR,
+	"decision-records":       DocTypeADR,
+	"architecture-decisions": DocTypeADR,
+	"rfc":                    DocTypeRFC,
+	"rfcs":                   DocTypeRFC,
+	"runbook":                DocTypeRunbook,
+	"runbooks":               DocTypeRunbook,
+	"playbook":               DocTypeRunbook,
+	"playbooks":              DocTypeRunbook,
+	"postmortem":             DocTypePostmortem,
+	"postmortems":            DocTypePostmortem,
+	"post-mortems":           DocTypePostmortem,
+}
+
+// docNamePrefix matches file names such as ADR-0001-use-postgres.md.
+var docNamePrefix = regexp.MustCompile(`^(?i)(adr|rfc|runbook|postmortem)[-_ ]`)
+
+// docHeadings lists the section headings that identify a document of each
+// type by its template; a document needs all headings of one set.
+var docHeadings = []struct {
+	docType  DocType
+	headings []string
+}{
+	{DocTypeADR, []string{"status", "context", "decision"}},
+	{DocTypePostmortem, []string{"timeline", "root cause"}},
+	{DocTypeRFC, []string{"summary", "motivation"}},
+	{DocTypeRFC, []string{"abstract", "motivation"}},
+	{DocTypeRunbook, []string{"symptoms", "mitigation"}},
+	{DocTypeRunbook, []string{"diagnosis", "mitigation"}},
+	{DocTypeRunbook, []string{"symptoms", "escalation"}},
+}
+
+// fieldLine matches "Status: Accepted" style lines, optionally bold or in a
+// list item.
+var fieldLine = regexp.MustCompile(`(?i)^(?:[-*]\s+)?\**(status|owners?|authors?|deciders)\**\s*:\**\s*(.+)$`)
+
+// ParseDocument reports whether the file at relPath is a knowledge
+// document and extracts its type, title, status and owners. The type comes
+// from front matter, the directory or file name (docs/adr, runbooks,
+// ADR-0001-*.md), or the template's section headings, in that order. Code
+// and prose that fits no type are not knowledge documents.
+func ParseDocument(relPath, content string) (DocInfo, bool) {
+	if !docExtensions[strings.ToLower(filepath.Ext(relPath))] {
+		return DocInfo{}, false
+	}
+
+	front, body := splitFrontMatter(content)
+	info := DocInfo{
+		Title:  frontString(front, "title"),
+		Status: normalizeStatus(frontString(front, "status")),
+		Owners: frontList(front, "owners", "owner", "authors", "author", "deciders"),
+	}
+	if t, err := ParseDocType(frontString(front, "type")); err == nil && t != "" {
+		info.Type = t
+	} else {
+		info.Type = pathDocType(relPath)
+	}
+
+	headings := make(map[string]bool)
+	var statusSection, fenced bool
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Shell comments in code blocks are not headings
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			heading := strings.TrimSpace(line[level:])
+			if level == 1 && info.Title == "" {
+				info.Title = heading
+			}
+			name := strings.ToLower(strings.TrimRight(heading, ":"))
+			headings[name] = true
+			statusSection = name == "status"
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if m := fieldLine.FindStringSubmatch(line); m != nil {
+			if strings.EqualFold(m[1], "status") {
+				if info.Status == "" {
+					info.Status = normalizeStatus(m[2])
+				}
+			} else if len(info.Owners) == 0 {
+				info.Owners = splitOwners(m[2])
+			}
+		} else if statusSection && info.Status == "" {
+			info.Status = normalizeStatus(line)
+		}
+		statusSection = false
+	}
+
+	if info.Type == "" {
+		info.Type = headingDocType(headings)
+	}
+	if info.Type == "" {
+		return DocInfo{}, false
+	}
+	if info.Title == "" {
+		info.Title = strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath))
+	}
+	return info, true
+}
+
+// pathDocType returns the type implied by the directories or name of a
+// file, or "".
+func pathDocType(relPath string) DocType {
+	if m := docNamePrefix.FindStringSubmatch(filepath.Base(relPath)); m != nil {
+		return DocType(strings.ToLower(m[1]))
+	}
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(relPath)), "/")
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if t, ok := docDirs[strings.ToLower(dirs[i])]; ok {
+			return t
+		}
+	}
+	return ""
+}
+
+// headingDocType returns the type whose template headings are all
+// present, or "".
+func headingDocType(headings map[string]bool) DocType {
+	for _, set := range docHeadings {
+		matched := true
+		for _, h := range set.headings {
+			if !headings[h] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return set.docType
+		}
+	}
+	return ""
+}
+
+// splitFrontMatter separates YAML front matter delimited by "---" lines
+// from the body. Malformed front matter is left in the body.
+func splitFrontMatter(content string) (map[string]any, string) {
+	content = strings.TrimPrefix(content, "\ufeff") // byte order mark
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return nil, content
+	}
+	rest := content[strings.Index(content, "\n")+1:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return nil, content
+	}
+	var front map[string]any
+	if err := yaml.Unmarshal([]byte(rest[:end]), &front); err != nil {
+		return nil, content
+	}
+	body := rest[end+len("\n---"):]
+	if i := strings.Index(body, "\n"); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+	return front, body
+}
+
+// frontString returns a scalar front matter field as a string.
+func frontString(front map[string]any, key string) string {
+	switch v := front[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// frontList returns the first of keys present in the front matter as a
+// list, splitting comma-separated strings.
+func frontList(front map[string]any, keys ...string) []string {
+	for _, key := range keys {
+		switch v := front[key].(type) {
+		case string:
+			return splitOwners(v)
+		case []any:
+			var owners []string
+			for _, item := range v {
+				owners = append(owners, splitOwners(fmt.Sprint(item))...)
+			}
+			return owners
+		}
+	}
+	return nil
+}
+
+// splitOwners splits "alice, bob and carol" into names.
+func splitOwners(value string) []string {
+	value = strings.ReplaceAll(value, " and ", ",")
+	var owners []string
+	seen := make(map[string]bool)
+	for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		name = strings.Trim(strings.TrimSpace(name), "*_`")
+		if name != "" && !seen[name] {
+			seen[name] = true
+			owners = append(owners, name)
+		}
+	}
+	return owners
+}
+
+// normalizeStatus returns the first word of a status line, lowercased:
+// "**Superseded** by ADR-0005" is "superseded".
+func normalizeStatus(value string) string {
+	fields := strings.Fields(strings.Trim(value, "*_` "))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.Trim(fields[0], "*_`.,;:()[]"))
+}
+
+// typeFilter matches a "type:adr" token in a search query.
+var typeFilter = regexp.MustCompile(`(?i)(?:^|\s)type:(\S+)`)
+
+// extractDocType removes a "type:<doc type>" token from query and returns
+// the remaining query and the type.
+func extractDocType(query string) (string, string) {
+	m := typeFilter.FindStringSubmatchIndex(query)
+	if m == nil {
+		return query, ""
+	}
+	docType := query[m[2]:m[3]]
+	rest := strings.Join(strings.Fields(query[:m[0]]+" "+query[m[1]:]), " ")
+	return rest, docType
+}
+
+// docMetadata returns the metadata IndexFiles stores for a knowledge
+// document.
+func docMetadata(info DocInfo) map[string]interface{} {
+	metadata := map[string]interface{}{
+		MetadataDocType:  string(info.Type),
+		MetadataDocTitle: info.Title,
+	}
+	if info.Status != "" {
+		metadata[MetadataDocStatus] = info.Status
+	}
+	// Stores keep scalar metadata only
+	if len(info.Owners) > 0 {
+		metadata[MetadataDocOwners] = strings.Join(info.Owners, ", ")
+	}
+	return metadata
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    DocInfo
+		wantOK  bool
+	}{
+		{
+			name: "adr in an adr directory",
+			path: "docs/adr/0003-use-postgres.md",
+			content: `# 3. Use Postgres for metadata
+
+Date: 2025-02-01
+
+## Status
+
+Superseded by ADR-0007
+
+## Context
+
+We need a relational store.
+
+## Decision
+
+Use Postgres.
+`,
+			want:   DocInfo{Type: DocTypeADR, Title: "3. Use Postgres for metadata", Status: "superseded"},
+			wantOK: true,
+		},
+		{
+			name: "runbook with front matter",
+			path: "ops/restart.md",
+			content: `---
+type: runbook
+title: Restarting the indexer
+owners: [alice, bob]
+---
+
+# Restart
+
+## Symptoms
+`,
+			want:   DocInfo{Type: DocTypeRunbook, Title: "Restarting the indexer", Owners: []string{"alice", "bob"}},
+			wantOK: true,
+		},
+		{
+			name: "adr by file name with field lines",
+			path: "ADR-0001-record-decisions.md",
+			content: `# Record architecture decisions
+
+* **Status:** Accepted
+* Deciders: carol and dave
+`,
+			want:   DocInfo{Type: DocTypeADR, Title: "Record architecture decisions", Status: "accepted", Owners: []string{"carol", "dave"}},
+			wantOK: true,
+		},
+		{
+			name:    "postmortem by template headings",
+			path:    "notes/2025-03-outage.md",
+			content: "Written after the outage.\n\n## Timeline\n\n```sh\n# Status: ignored\n```\n\n## Root Cause\n",
+			want:    DocInfo{Type: DocTypePostmortem, Title: "2025-03-outage"},
+			wantOK:  true,
+		},
+		{
+			name:    "code is not a document",
+			path:    "docs/adr/generate.go",
+			content: "package adr",
+		},
+		{
+			name:    "prose without a type",
+			path:    "README.md",
+			content: "# contextd\n\n## Status\n\nStable\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseDocument(tt.path, tt.content)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseDocument() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDocument() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDocType(t *testing.T) {
+	got, err := ParseDocType(" ADR ")
+	if err != nil || got != DocTypeADR {
+		t.Errorf("ParseDocType(ADR) = %q, %v, want adr", got, err)
+	}
+	if _, err := ParseDocType("wiki"); !errors.Is(err, ErrInvalidDocType) {
+		t.Errorf("ParseDocType(wiki) error = %v, want ErrInvalidDocType", err)
+	}
+}
+
+func TestExtractDocType(t *testing.T) {
+	tests := []struct {
+		query    string
+		wantRest string
+		wantType string
+	}{
+		{"type:adr database choice", "database choice", "adr"},
+		{"restart indexer Type:Runbook", "restart indexer", "Runbook"},
+		{"filetype:go handlers", "filetype:go handlers", ""},
+		{"plain query", "plain query", ""},
+	}
+	for _, tt := range tests {
+		rest, docType := extractDocType(tt.query)
+		if rest != tt.wantRest || docType != tt.wantType {
+			t.Errorf("extractDocType(%q) = %q, %q, want %q, %q", tt.query, rest, docType, tt.wantRest, tt.wantType)
+		}
+	}
+}
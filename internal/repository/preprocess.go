@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// Names of the built-in preprocessors.
+const (
+	PreprocessorStripLicenseHeader     = "strip_license_header"
+	PreprocessorRenderNotebook         = "render_notebook"
+	PreprocessorExpandTerraformModules = "expand_terraform_modules"
+)
+
+// ErrInvalidPreprocessorRule is returned for a rule naming an unknown
+// preprocessor or with an invalid pattern.
+var ErrInvalidPreprocessorRule = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid preprocessor rule")
+
+// SourceFile is a file about to be indexed.
+type SourceFile struct {
+	RepoPath string // cleaned repository path
+	Path     string // relative to RepoPath
+	Content  string
+}
+
+// Preprocessor transforms the content of a file before it is embedded, to
+// drop noise such as license headers or to turn a format that embeds poorly
+// into text. Returning empty content leaves the file unindexed.
+type Preprocessor interface {
+	Preprocess(ctx context.Context, file SourceFile) (string, error)
+}
+
+// PreprocessorFunc adapts a function to Preprocessor.
+type PreprocessorFunc func(ctx context.Context, file SourceFile) (string, error)
+
+// Preprocess calls f.
+func (f PreprocessorFunc) Preprocess(ctx context.Context, file SourceFile) (string, error) {
+	return f(ctx, file)
+}
+
+// PreprocessorRule runs preprocessors on the files matching a glob pattern.
+// Patterns match like include patterns: against the file name or the path
+// relative to the repository, and "dir/**" matches everything under dir.
+type PreprocessorRule struct {
+	Pattern string   `json:"pattern"`
+	Use     []string `json:"use"` // preprocessor names, run in order
+}
+
+// builtinPreprocessors returns the preprocessors every Service knows.
+func builtinPreprocessors() map[string]Preprocessor {
+	return map[string]Preprocessor{
+		PreprocessorStripLicenseHeader:     PreprocessorFunc(stripLicenseHeader),
+		PreprocessorRenderNotebook:         PreprocessorFunc(renderNotebook),
+		PreprocessorExpandTerraformModules: PreprocessorFunc(expandTerraformModules),
+	}
+}
+
+// RegisterPreprocessor makes a preprocessor available to rules under name,
+// replacing any preprocessor registered under it. Register preprocessors
+// before indexing starts.
+func (s *Service) RegisterPreprocessor(name string, p Preprocessor) {
+	if s.preprocessors == nil {
+		s.preprocessors = builtinPreprocessors()
+	}
+	s.preprocessors[name] = p
+}
+
+// Preprocessors returns the names of the registered preprocessors, sorted.
+func (s *Service) Preprocessors() []string {
+	names := make([]string, 0, len(s.preprocessors))
+	for name := range s.preprocessors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetPreprocessorRules sets the rules applied to every repository indexed.
+// Rules given in IndexOptions apply after them.
+func (s *Service) SetPreprocessorRules(rules []PreprocessorRule) error {
+	if err := s.ValidatePreprocessorRules(rules); err != nil {
+		return err
+	}
+	s.preprocessorRules = rules
+	return nil
+}
+
+// ValidatePreprocessorRules checks that rules have valid patterns and name
+// registered preprocessors.
+func (s *Service) ValidatePreprocessorRules(rules []PreprocessorRule) error {
+	for i, rule := range rules {
+		if rule.Pattern == "" {
+			return fmt.Errorf("%w %d: pattern is required", ErrInvalidPreprocessorRule, i+1)
+		}
+		if _, err := filepath.Match(rule.Pattern, "test"); err != nil {
+			return fmt.Errorf("%w %d: pattern %q: %v", ErrInvalidPreprocessorRule, i+1, rule.Pattern, err)
+		}
+		if len(rule.Use) == 0 {
+			return fmt.Errorf("%w %d (%s): no preprocessors", ErrInvalidPreprocessorRule, i+1, rule.Pattern)
+		}
+		for _, name := range rule.Use {
+			if _, ok := s.preprocessors[name]; !ok {
+				return fmt.Errorf("%w %d (%s): unknown preprocessor %q: must be one of %s",
+					ErrInvalidPreprocessorRule, i+1, rule.Pattern, name, strings.Join(s.Preprocessors(), ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// preprocessorsFor returns the names of the preprocessors to run on a file,
+// in order and each once.
+func (s *Service) preprocessorsFor(relPath string, extra []PreprocessorRule) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, rules := range [][]PreprocessorRule{s.preprocessorRules, extra} {
+		for _, rule := range rules {
+			if !matchesRulePattern(rule.Pattern, relPath) {
+				continue
+			}
+			for _, name := range rule.Use {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// preprocess runs the preprocessors of the rules matching a file. A failing
+// preprocessor is skipped, so the file is indexed as the others leave it,
+// and its error returned.
+func (s *Service) preprocess(ctx context.Context, file SourceFile, extra []PreprocessorRule) (string, []string, []error) {
+	names := s.preprocessorsFor(file.Path, extra)
+	if len(names) == 0 {
+		return file.Content, nil, nil
+	}
+
+	var (
+		applied []string
+		errs    []error
+	)
+	for _, name := range names {
+		p, ok := s.preprocessors[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown preprocessor %q", file.Path, name))
+			continue
+		}
+		content, err := p.Preprocess(ctx, file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", file.Path, name, err))
+			continue
+		}
+		file.Content = content
+		applied = append(applied, name)
+	}
+	return file.Content, applied, errs
+}
+
+// matchesRulePattern reports whether a preprocessor rule pattern matches a
+// file, the way shouldIncludeFile matches include patterns.
+func matchesRulePattern(pattern, relPath string) bool {
+	if matched, _ := filepath.Match(pattern, filepath.Base(relPath)); matched {
+		return true
+	}
+	if matched, _ := filepath.Match(pattern, relPath); matched {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/**") {
+		prefix := strings.TrimSuffix(pattern, "/**")
+		return strings.HasPrefix(relPath, prefix+string(filepath.Separator))
+	}
+	return false
+}
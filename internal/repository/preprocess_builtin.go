@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ===== strip_license_header =====
+
+// licenseMarkers identify a comment block as a license header.
+var licenseMarkers = []string{"copyright", "license", "licence", "spdx-license-identifier"}
+
+// lineCommentPrefixes start the lines of a line comment block.
+var lineCommentPrefixes = []string{"//", "#", "--", ";"}
+
+// stripLicenseHeader removes the comment block at the top of a file when it
+// is a license or copyright notice, keeping any shebang line. License
+// headers repeat across every file of a repository and crowd out the code
+// in its embedding.
+func stripLicenseHeader(_ context.Context, file SourceFile) (string, error) {
+	lines := strings.SplitAfter(file.Content, "\n")
+	keep := 0
+	if strings.HasPrefix(lines[0], "#!") {
+		keep = 1
+	}
+	start := skipBlankLines(lines, keep)
+	end := commentBlockEnd(lines, start)
+	if end == start || !isLicenseText(strings.Join(lines[start:end], "")) {
+		return file.Content, nil
+	}
+	return strings.Join(lines[:keep], "") + strings.Join(lines[skipBlankLines(lines, end):], ""), nil
+}
+
+// skipBlankLines returns the index of the first non-blank line from i.
+func skipBlankLines(lines []string, i int) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return i
+}
+
+// commentBlockEnd returns the index after the comment block starting at
+// lines[start], or start when no comment starts there.
+func commentBlockEnd(lines []string, start int) int {
+	if start >= len(lines) {
+		return start
+	}
+	first := strings.TrimSpace(lines[start])
+	for _, delims := range [][2]string{{"/*", "*/"}, {"<!--", "-->"}} {
+		if !strings.HasPrefix(first, delims[0]) {
+			continue
+		}
+		for i := start; i < len(lines); i++ {
+			line := lines[i]
+			if i == start {
+				line = strings.TrimPrefix(strings.TrimSpace(line), delims[0])
+			}
+			if strings.Contains(line, delims[1]) {
+				return i + 1
+			}
+		}
+		return start // unterminated
+	}
+	for _, prefix := range lineCommentPrefixes {
+		if !strings.HasPrefix(first, prefix) {
+			continue
+		}
+		i := start
+		for i < len(lines) {
+			line := strings.TrimSpace(lines[i])
+			// Build constraints directly below a header are code
+			if !strings.HasPrefix(line, prefix) || strings.HasPrefix(line, "//go:") || strings.HasPrefix(line, "// +build") {
+				break
+			}
+			i++
+		}
+		return i
+	}
+	return start
+}
+
+// isLicenseText reports whether a comment block is a license notice.
+func isLicenseText(text string) bool {
+	text = strings.ToLower(text)
+	for _, marker := range licenseMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ===== render_notebook =====
+
+// notebook is the part of a Jupyter notebook (nbformat 4) that is rendered.
+type notebook struct {
+	Cells []struct {
+		CellType string       `json:"cell_type"`
+		Source   notebookText `json:"source"`
+	} `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// notebookText is cell source, stored as a string or a list of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*t = notebookText(text)
+	return nil
+}
+
+// renderNotebook renders a Jupyter notebook as text: markdown cells as they
+// are and code cells in fenced blocks. Outputs are left out; they are
+// mostly data and images, and change on every run.
+func renderNotebook(_ context.Context, file SourceFile) (string, error) {
+	var nb notebook
+	if err := json.Unmarshal([]byte(file.Content), &nb); err != nil {
+		return "", fmt.Errorf("parsing notebook: %w", err)
+	}
+	if nb.Cells == nil {
+		return "", errors.New("unsupported notebook format: no cells")
+	}
+
+	language := nb.Metadata.LanguageInfo.Name
+	if language == "" {
+		language = nb.Metadata.Kernelspec.Language
+	}
+	var parts []string
+	for _, cell := range nb.Cells {
+		source := strings.TrimSpace(string(cell.Source))
+		if source == "" {
+			continue
+		}
+		if cell.CellType == "code" {
+			source = "```" + language + "\n" + source + "\n```"
+		}
+		parts = append(parts, source)
+	}
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
+
+// ===== expand_terraform_modules =====
+
+var (
+	tfModuleBlock = regexp.MustCompile(`(?m)^\s*module\s+"([^"]+)"\s*\{`)
+	tfDeclBlock   = regexp.MustCompile(`(?m)^\s*(variable|output)\s+"([^"]+)"\s*\{`)
+	tfSource      = regexp.MustCompile(`(?m)^\s*source\s*=\s*"([^"]+)"`)
+	tfDescription = regexp.MustCompile(`(?m)^\s*description\s*=\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// expandTerraformModules appends, for each module called from a local
+// source, the variables and outputs the module declares, with their
+// descriptions. A module call alone says little about what the module
+// does; its interface makes the call findable. Remote modules and sources
+// outside the repository are left alone.
+func expandTerraformModules(ctx context.Context, file SourceFile) (string, error) {
+	var summaries []string
+	for _, m := range tfModuleBlock.FindAllStringSubmatchIndex(file.Content, -1) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		name := file.Content[m[2]:m[3]]
+		source := tfSource.FindStringSubmatch(hclBlock(file.Content, m[1]-1))
+		if source == nil || !(strings.HasPrefix(source[1], "./") || strings.HasPrefix(source[1], "../")) {
+			continue
+		}
+		dir := filepath.Join(filepath.Dir(file.Path), filepath.FromSlash(source[1]))
+		if !filepath.IsLocal(dir) {
+			continue
+		}
+		decls, err := terraformDeclarations(filepath.Join(file.RepoPath, dir))
+		if err != nil {
+			return "", fmt.Errorf("module %q: %w", name, err)
+		}
+		if len(decls) == 0 {
+			continue
+		}
+		summaries = append(summaries, fmt.Sprintf("# module %q (%s)\n%s", name, source[1], strings.Join(decls, "\n")))
+	}
+	if len(summaries) == 0 {
+		return file.Content, nil
+	}
+	return strings.TrimRight(file.Content, "\n") + "\n\n" + strings.Join(summaries, "\n\n") + "\n", nil
+}
+
+// terraformDeclarations lists the variables and outputs declared by the
+// .tf files of a module directory, as comment lines. A missing directory
+// has none.
+func terraformDeclarations(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var decls []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content := string(data)
+		for _, m := range tfDeclBlock.FindAllStringSubmatchIndex(content, -1) {
+			decl := fmt.Sprintf("#   %s %s", content[m[2]:m[3]], content[m[4]:m[5]])
+			if d := tfDescription.FindStringSubmatch(hclBlock(content, m[1]-1)); d != nil {
+				decl += ": " + d[1]
+			}
+			decls = append(decls, decl)
+		}
+	}
+	return decls, nil
+}
+
+// hclBlock returns the body of the block whose opening brace is at
+// content[open], up to its closing brace or the end of content. Braces in
+// quoted strings do not count.
+func hclBlock(content string, open int) string {
+	depth := 0
+	inString := false
+	for i := open; i < len(content); i++ {
+		switch c := content[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return content[open+1 : i]
+			}
+		}
+	}
+	return content[open+1:]
+}
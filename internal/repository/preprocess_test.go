@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStripLicenseHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "line comment header",
+			content: "// Copyright 2025 Acme Inc.\n// SPDX-License-Identifier: Apache-2.0\n\npackage main\n",
+			want:    "package main\n",
+		},
+		{
+			name:    "block comment header",
+			content: "/*\n * Licensed under the MIT License.\n */\n\nimport x from 'y';\n",
+			want:    "import x from 'y';\n",
+		},
+		{
+			name:    "keeps shebang",
+			content: "#!/usr/bin/env python\n# Copyright (c) Acme\n\nprint('hi')\n",
+			want:    "#!/usr/bin/env python\nprint('hi')\n",
+		},
+		{
+			name:    "keeps build constraint",
+			content: "// Copyright 2025 Acme Inc.\n//go:build linux\n\npackage main\n",
+			want:    "//go:build linux\n\npackage main\n",
+		},
+		{
+			name:    "keeps other comments",
+			content: "// Package main runs the server.\npackage main\n",
+			want:    "// Package main runs the server.\npackage main\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripLicenseHeader(context.Background(), SourceFile{Path: "f", Content: tt.content})
+			if err != nil {
+				t.Fatalf("stripLicenseHeader() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("stripLicenseHeader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNotebook(t *testing.T) {
+	nb := `{
+  "cells": [
+    {"cell_type": "markdown", "source": ["# Churn model\n", "Trains the model."]},
+    {"cell_type": "code", "source": "import pandas as pd", "outputs": [{"output_type": "stream", "text": ["noise"]}]},
+    {"cell_type": "code", "source": []}
+  ],
+  "metadata": {"language_info": {"name": "python"}}
+}`
+	got, err := renderNotebook(context.Background(), SourceFile{Path: "model.ipynb", Content: nb})
+	if err != nil {
+		t.Fatalf("renderNotebook() error = %v", err)
+	}
+	want := "# Churn model\nTrains the model.\n\n```python\nimport pandas as pd\n```\n"
+	if got != want {
+		t.Errorf("renderNotebook() = %q, want %q", got, want)
+	}
+
+	if _, err := renderNotebook(context.Background(), SourceFile{Path: "bad.ipynb", Content: "not json"}); err == nil {
+		t.Error("renderNotebook() error = nil for invalid notebook")
+	}
+}
+
+func TestExpandTerraformModules(t *testing.T) {
+	repo := t.TempDir()
+	createTestFile(t, repo, "modules/network/variables.tf", `variable "cidr" {
+  description = "CIDR block of the VPC"
+  type        = string
+}
+`)
+	createTestFile(t, repo, "modules/network/outputs.tf", `output "vpc_id" {
+  value = aws_vpc.main.id
+}
+`)
+	main := `module "network" {
+  source = "../modules/network"
+  cidr   = "10.0.0.0/16"
+}
+
+module "dns" {
+  source = "terraform-aws-modules/route53/aws"
+}
+`
+	got, err := expandTerraformModules(context.Background(), SourceFile{RepoPath: repo, Path: "envs/main.tf", Content: main})
+	if err != nil {
+		t.Fatalf("expandTerraformModules() error = %v", err)
+	}
+	want := main + "\n# module \"network\" (../modules/network)\n#   output vpc_id\n#   variable cidr: CIDR block of the VPC\n"
+	if got != want {
+		t.Errorf("expandTerraformModules() = %q, want %q", got, want)
+	}
+
+	escaping := `module "x" { source = "../../../etc" }`
+	got, err = expandTerraformModules(context.Background(), SourceFile{RepoPath: repo, Path: "main.tf", Content: escaping})
+	if err != nil || got != escaping {
+		t.Errorf("expandTerraformModules() = %q, %v for a source outside the repository", got, err)
+	}
+}
+
+func TestIndexRepository_Preprocessors(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "main.go", "// Copyright 2025 Acme Inc.\n\npackage main\n")
+	createTestFile(t, tmpDir, "lib/util.go", "// Copyright 2025 Acme Inc.\n\npackage lib\n")
+	createTestFile(t, tmpDir, "data.txt", "plain")
+
+	store := &mockStore{}
+	svc := NewService(store)
+	svc.RegisterPreprocessor("fail", PreprocessorFunc(func(ctx context.Context, file SourceFile) (string, error) {
+		return "", errors.New("boom")
+	}))
+	if err := svc.SetPreprocessorRules([]PreprocessorRule{{Pattern: "*.go", Use: []string{PreprocessorStripLicenseHeader}}}); err != nil {
+		t.Fatalf("SetPreprocessorRules() error = %v", err)
+	}
+
+	result, err := svc.IndexRepository(context.Background(), tmpDir, IndexOptions{
+		TenantID:      "testuser",
+		Preprocessors: []PreprocessorRule{{Pattern: "lib/**", Use: []string{"fail"}}},
+	})
+	if err != nil {
+		t.Fatalf("IndexRepository() error = %v", err)
+	}
+	if result.Preprocessed != 2 {
+		t.Errorf("Preprocessed = %d, want 2", result.Preprocessed)
+	}
+	if len(result.PreprocessErrors) != 1 || !strings.Contains(result.PreprocessErrors[0], "boom") {
+		t.Errorf("PreprocessErrors = %v, want the failing preprocessor", result.PreprocessErrors)
+	}
+	for _, doc := range store.documents {
+		switch doc.Metadata["file_path"] {
+		case "main.go":
+			if doc.Content != "package main\n" || doc.Metadata["preprocessors"] != PreprocessorStripLicenseHeader {
+				t.Errorf("main.go = %q, %v", doc.Content, doc.Metadata["preprocessors"])
+			}
+		case "data.txt":
+			if doc.Content != "plain" {
+				t.Errorf("data.txt = %q, want it untouched", doc.Content)
+			}
+		}
+	}
+
+	_, err = svc.IndexRepository(context.Background(), tmpDir, IndexOptions{
+		TenantID:      "testuser",
+		Preprocessors: []PreprocessorRule{{Pattern: "*.go", Use: []string{"minify"}}},
+	})
+	if !errors.Is(err, ErrInvalidPreprocessorRule) {
+		t.Errorf("IndexRepository() error = %v, want ErrInvalidPreprocessorRule", err)
+	}
+}
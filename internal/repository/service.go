@@ -7,15 +7,19 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/uuid"
 
+	"github.com/fyrsmithlabs/contextd/internal/pagination"
 	"github.com/fyrsmithlabs/contextd/internal/sanitize"
 	"github.com/fyrsmithlabs/contextd/internal/tenant"
+	"github.com/fyrsmithlabs/contextd/internal/validation"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
@@ -57,12 +61,16 @@ type Store interface {
 type Service struct {
 	store  Store                     // Legacy single-store mode
 	stores vectorstore.StoreProvider // Database-per-project isolation mode
+
+	preprocessors     map[string]Preprocessor // by name
+	preprocessorRules []PreprocessorRule
 }
 
 // NewService creates a new repository indexing service.
 func NewService(store Store) *Service {
 	return &Service{
-		store: store,
+		store:         store,
+		preprocessors: builtinPreprocessors(),
 	}
 }
 
@@ -73,7 +81,8 @@ func NewService(store Store) *Service {
 // and the collection name is simplified to just "codebase".
 func NewServiceWithStoreProvider(stores vectorstore.StoreProvider) *Service {
 	return &Service{
-		stores: stores,
+		stores:        stores,
+		preprocessors: builtinPreprocessors(),
 	}
 }
 
@@ -121,7 +130,9 @@ type SearchOptions struct {
 	ProjectPath    string // Required if CollectionName not provided
 	TenantID       string // Required if CollectionName not provided
 	Branch         string // Optional: filter by branch (empty = all branches)
+	DocType        string // Optional: only knowledge documents of this type (adr, rfc, runbook, postmortem)
 	Limit          int    // Max results (default: 10)
+	Cursor         string // Optional: resume a previous SearchPage call
 }
 
 // RepoSearchResult from repository search.
@@ -130,6 +141,8 @@ type RepoSearchResult struct {
 	Content  string                 `json:"content"`
 	Score    float32                `json:"score"`
 	Branch   string                 `json:"branch"`
+	DocType  string                 `json:"doc_type,omitempty"`  // Knowledge document type, if any
+	Title    string                 `json:"doc_title,omitempty"` // Knowledge document title, if any
 	Metadata map[string]interface{} `json:"metadata"`
 }
 
@@ -267,10 +280,24 @@ func (s *Service) Grep(ctx context.Context, pattern string, opts GrepOptions) ([
 }
 
 // Search performs semantic search over indexed repository files.
+//
+// A "type:adr" token in the query, or opts.DocType, restricts the search to
+// knowledge documents of that type.
 func (s *Service) Search(ctx context.Context, query string, opts SearchOptions) ([]RepoSearchResult, error) {
+	query, queryType := extractDocType(query)
+	if opts.DocType == "" {
+		opts.DocType = queryType
+	}
+	docType, err := ParseDocType(opts.DocType)
+	if err != nil {
+		return nil, err
+	}
 	if query == "" {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
+	if err := validation.Repository.Query("query", query); err != nil {
+		return nil, err
+	}
 
 	limit := opts.Limit
 	if limit <= 0 {
@@ -280,7 +307,6 @@ func (s *Service) Search(ctx context.Context, query string, opts SearchOptions)
 	// Determine store and collection name
 	var store Store
 	var collectionName string
-	var err error
 
 	if opts.CollectionName != "" {
 		// Use provided collection name directly (legacy behavior)
@@ -328,6 +354,9 @@ func (s *Service) Search(ctx context.Context, query string, opts SearchOptions)
 	if opts.Branch != "" {
 		filters["branch"] = opts.Branch
 	}
+	if docType != "" {
+		filters[MetadataDocType] = string(docType)
+	}
 
 	results, err := store.SearchInCollection(ctx, collectionName, query, limit, filters)
 	if err != nil {
@@ -346,11 +375,16 @@ func (s *Service) Search(ctx context.Context, query string, opts SearchOptions)
 			filePath = fp
 		}
 
+		docType, _ := r.Metadata[MetadataDocType].(string)
+		title, _ := r.Metadata[MetadataDocTitle].(string)
+
 		repoResults = append(repoResults, RepoSearchResult{
 			FilePath: filePath,
 			Content:  r.Content,
 			Score:    r.Score,
 			Branch:   branch,
+			DocType:  docType,
+			Title:    title,
 			Metadata: r.Metadata,
 		})
 	}
@@ -358,6 +392,49 @@ func (s *Service) Search(ctx context.Context, query string, opts SearchOptions)
 	return repoResults, nil
 }
 
+// SearchPage performs a semantic search and returns one page of results with
+// the cursor for the next page (empty when there are no more results).
+// opts.Cursor selects the page; it is bound to the query and search scope.
+func (s *Service) SearchPage(ctx context.Context, query string, opts SearchOptions) ([]RepoSearchResult, string, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	hash := CursorHash(query, opts)
+	offset, err := pagination.Decode(opts.Cursor, hash)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra result to know whether another page exists.
+	opts.Limit = offset + limit + 1
+	results, err := s.Search(ctx, query, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Break score ties by file path and content so pages are stable across calls
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].FilePath != results[j].FilePath {
+			return results[i].FilePath < results[j].FilePath
+		}
+		return results[i].Content < results[j].Content
+	})
+
+	page, next := pagination.Paginate(results, offset, limit, hash)
+	return page, next, nil
+}
+
+// CursorHash binds SearchPage cursors to the query and search scope. Callers
+// that return part of a page use it to resume with pagination.Resume.
+func CursorHash(query string, opts SearchOptions) string {
+	return pagination.QueryHash("repository", query, opts.CollectionName, opts.ProjectPath, opts.TenantID, opts.Branch, opts.DocType)
+}
+
 // IndexRepository indexes all files in a repository matching the given options.
 //
 // Files are stored in a dedicated {tenant}_{project}_codebase collection,
@@ -368,54 +445,25 @@ func (s *Service) Search(ctx context.Context, query string, opts SearchOptions)
 //
 // Returns IndexResult with statistics, or an error if indexing fails.
 func (s *Service) IndexRepository(ctx context.Context, path string, opts IndexOptions) (*IndexResult, error) {
-	// Validate and clean path
-	cleanPath, err := validatePath(path)
+	list, err := s.ListFiles(ctx, path, opts)
 	if err != nil {
-		return nil, fmt.Errorf("invalid path: %w", err)
-	}
-
-	// Set defaults
-	if opts.MaxFileSize == 0 {
-		opts.MaxFileSize = 1024 * 1024 // 1MB default
-	}
-	if opts.MaxFileSize > 10*1024*1024 {
-		return nil, fmt.Errorf("max_file_size cannot exceed 10MB")
-	}
-
-	// Validate patterns
-	if err := validatePatterns(opts.IncludePatterns); err != nil {
-		return nil, fmt.Errorf("invalid include pattern: %w", err)
-	}
-	if err := validatePatterns(opts.ExcludePatterns); err != nil {
-		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+		return nil, err
 	}
 
-	// Detect branch (auto-detect if not specified)
-	branch := opts.Branch
-	if branch == "" {
-		branch = detectGitBranch(cleanPath)
-	}
+	opts.Branch = list.Branch
+	return s.IndexFiles(ctx, list.Path, list.Files, opts)
+}
 
-	// Get store and collection name using getStore()
-	store, collectionName, tenantID, err := s.getStore(ctx, cleanPath, opts.TenantID)
+// ListFiles walks a repository and returns the files IndexRepository would
+// index, without reading or storing them. Together with IndexFiles it lets
+// callers index a large repository in batches.
+func (s *Service) ListFiles(ctx context.Context, path string, opts IndexOptions) (*FileList, error) {
+	cleanPath, opts, err := prepareIndex(path, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Sanitize tenant ID for metadata consistency (store what we use for lookups)
-	sanitizedTenant := sanitize.Identifier(tenantID)
-
-	// Inject tenant context for payload-based isolation
-	projectName := filepath.Base(cleanPath)
-	ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
-		TenantID:  sanitizedTenant,
-		ProjectID: sanitize.Identifier(projectName),
-	})
-
-	// Collect documents to index
-	var docs []vectorstore.Document
-
-	// Walk file tree
+	var files []string
 	err = filepath.Walk(cleanPath, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -444,48 +492,140 @@ func (s *Service) IndexRepository(ctx context.Context, path string, opts IndexOp
 		}
 
 		// Apply filters
-		if !shouldIncludeFile(relPath, info, opts) {
-			return nil
+		if shouldIncludeFile(relPath, info, opts) {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking file tree: %w", err)
+	}
+
+	return &FileList{
+		Path:   cleanPath,
+		Branch: opts.Branch,
+		Files:  files,
+	}, nil
+}
+
+// IndexFiles reads and stores the given files of a repository. Paths are
+// relative to the repository root, as returned by ListFiles; paths that
+// escape the root are rejected. Binary and empty files are skipped.
+//
+// Each file is stored under an ID derived from its tenant, repository,
+// branch and path, so indexing the same files again replaces them rather
+// than adding duplicates.
+//
+// Files matching a preprocessor rule, of the service or opts, are
+// transformed before they are stored. A failing preprocessor does not fail
+// the run; the error is reported in the result.
+func (s *Service) IndexFiles(ctx context.Context, path string, files []string, opts IndexOptions) (*IndexResult, error) {
+	cleanPath, opts, err := prepareIndex(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ValidatePreprocessorRules(opts.Preprocessors); err != nil {
+		return nil, err
+	}
+
+	// Get store and collection name using getStore()
+	store, collectionName, tenantID, err := s.getStore(ctx, cleanPath, opts.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sanitize tenant ID for metadata consistency (store what we use for lookups)
+	sanitizedTenant := sanitize.Identifier(tenantID)
+
+	// Inject tenant context for payload-based isolation
+	projectName := filepath.Base(cleanPath)
+	ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{
+		TenantID:  sanitizedTenant,
+		ProjectID: sanitize.Identifier(projectName),
+	})
+
+	// Collect documents to index
+	var (
+		docs         []vectorstore.Document
+		preprocessed int
+		preErrs      []string
+	)
+	for _, relPath := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		relPath = filepath.Clean(relPath)
+		if !filepath.IsLocal(relPath) {
+			return nil, fmt.Errorf("invalid file path %q: must be relative to the repository", relPath)
+		}
+		filePath := filepath.Join(cleanPath, relPath)
+
+		// Re-check filters; the file may have changed since it was listed
+		info, err := os.Lstat(filePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("stat file %s: %w", filePath, err)
+		}
+		if !info.Mode().IsRegular() || !shouldIncludeFile(relPath, info, opts) {
+			continue
 		}
 
 		// Read file content
 		content, err := os.ReadFile(filePath)
 		if err != nil {
-			return fmt.Errorf("reading file %s: %w", filePath, err)
+			return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 		}
 
 		// Skip binary files (invalid UTF-8)
 		if !utf8.Valid(content) {
-			return nil
+			continue
+		}
+
+		text, applied, errs := s.preprocess(ctx, SourceFile{RepoPath: cleanPath, Path: relPath, Content: string(content)}, opts.Preprocessors)
+		for _, err := range errs {
+			preErrs = append(preErrs, err.Error())
+		}
+		if len(applied) > 0 {
+			preprocessed++
 		}
 
 		// Skip empty files (embedding layer rejects empty content)
-		contentStr := strings.TrimSpace(string(content))
-		if contentStr == "" {
-			return nil
+		if strings.TrimSpace(text) == "" {
+			continue
 		}
 
 		// Create document for vector store
 		doc := vectorstore.Document{
-			Content:    string(content),
+			ID:         fileDocumentID(sanitizedTenant, cleanPath, opts.Branch, relPath),
+			Content:    text,
 			Collection: collectionName,
 			Metadata: map[string]interface{}{
 				"file_path":    relPath,
 				"file_size":    info.Size(),
 				"extension":    filepath.Ext(relPath),
-				"branch":       branch,
+				"branch":       opts.Branch,
 				"project_path": cleanPath,
 				"tenant_id":    sanitizedTenant, // Use sanitized for consistency with collection name
 				"indexed_at":   time.Now().UTC().Format(time.RFC3339),
 			},
 		}
 
-		docs = append(docs, doc)
-		return nil
-	})
+		if len(applied) > 0 {
+			doc.Metadata["preprocessors"] = strings.Join(applied, ",")
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("walking file tree: %w", err)
+		// Tag ADRs, RFCs, runbooks and postmortems so searches can be
+		// narrowed to them and hits cited by title
+		if info, ok := ParseDocument(relPath, doc.Content); ok {
+			for k, v := range docMetadata(info) {
+				doc.Metadata[k] = v
+			}
+		}
+
+		docs = append(docs, doc)
 	}
 
 	// Add all documents to vector store
@@ -497,17 +637,59 @@ func (s *Service) IndexRepository(ctx context.Context, path string, opts IndexOp
 
 	// Return result
 	return &IndexResult{
-		Path:            cleanPath,
-		Branch:          branch,
-		CollectionName:  collectionName,
-		FilesIndexed:    len(docs),
-		IncludePatterns: opts.IncludePatterns,
-		ExcludePatterns: opts.ExcludePatterns,
-		MaxFileSize:     opts.MaxFileSize,
-		IndexedAt:       time.Now(),
+		Path:             cleanPath,
+		Branch:           opts.Branch,
+		CollectionName:   collectionName,
+		FilesIndexed:     len(docs),
+		IncludePatterns:  opts.IncludePatterns,
+		ExcludePatterns:  opts.ExcludePatterns,
+		MaxFileSize:      opts.MaxFileSize,
+		Preprocessed:     preprocessed,
+		PreprocessErrors: preErrs,
+		IndexedAt:        time.Now(),
 	}, nil
 }
 
+// prepareIndex validates the path and options shared by ListFiles and
+// IndexFiles, and fills in the default file size limit and the branch.
+func prepareIndex(path string, opts IndexOptions) (string, IndexOptions, error) {
+	// Validate and clean path
+	cleanPath, err := validatePath(path)
+	if err != nil {
+		return "", opts, fmt.Errorf("invalid path: %w", err)
+	}
+
+	// Set defaults
+	if opts.MaxFileSize == 0 {
+		opts.MaxFileSize = 1024 * 1024 // 1MB default
+	}
+	if opts.MaxFileSize > 10*1024*1024 {
+		return "", opts, fmt.Errorf("max_file_size cannot exceed 10MB")
+	}
+
+	// Validate patterns
+	if err := validatePatterns(opts.IncludePatterns); err != nil {
+		return "", opts, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	if err := validatePatterns(opts.ExcludePatterns); err != nil {
+		return "", opts, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	// Detect branch (auto-detect if not specified)
+	if opts.Branch == "" {
+		opts.Branch = detectGitBranch(cleanPath)
+	}
+
+	return cleanPath, opts, nil
+}
+
+// fileDocumentID returns a stable document ID for an indexed file. Qdrant
+// requires point IDs to be UUIDs, so the ID is a name-based UUID.
+func fileDocumentID(tenantID, repoPath, branch, relPath string) string {
+	name := strings.Join([]string{tenantID, repoPath, branch, filepath.ToSlash(relPath)}, "\x00")
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
+
 // detectGitBranch detects the current git branch for a path.
 // Returns "unknown" if not a git repository or detection fails.
 func detectGitBranch(path string) string {
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -401,6 +402,72 @@ func TestIndexRepository_AutoDetectsBranchWhenNotSpecified(t *testing.T) {
 	t.Logf("Auto-detected branch: %s", result.Branch)
 }
 
+func TestListFiles_AppliesFilters(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "main.go", "package main")
+	createTestFile(t, tmpDir, "pkg/util.go", "package pkg")
+	createTestFile(t, tmpDir, "README.md", "# readme")
+	createTestFile(t, tmpDir, "node_modules/dep.go", "package dep")
+
+	svc := NewService(&mockStore{})
+
+	list, err := svc.ListFiles(context.Background(), tmpDir, IndexOptions{
+		TenantID:        "testuser",
+		Branch:          "main",
+		IncludePatterns: []string{"*.go"},
+	})
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+
+	want := []string{"main.go", filepath.Join("pkg", "util.go")}
+	if fmt.Sprint(list.Files) != fmt.Sprint(want) {
+		t.Errorf("Files = %v, want %v", list.Files, want)
+	}
+	if list.Branch != "main" {
+		t.Errorf("Branch = %q, want %q", list.Branch, "main")
+	}
+}
+
+func TestIndexFiles_ReindexUsesSameIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "main.go", "package main")
+	createTestFile(t, tmpDir, "empty.go", "   ")
+
+	store := &mockStore{}
+	svc := NewService(store)
+	opts := IndexOptions{TenantID: "testuser", Branch: "main"}
+
+	for i := 0; i < 2; i++ {
+		result, err := svc.IndexFiles(context.Background(), tmpDir, []string{"main.go", "empty.go", "gone.go"}, opts)
+		if err != nil {
+			t.Fatalf("IndexFiles() error = %v", err)
+		}
+		if result.FilesIndexed != 1 {
+			t.Errorf("FilesIndexed = %d, want 1", result.FilesIndexed)
+		}
+	}
+
+	if len(store.documents) != 2 {
+		t.Fatalf("stored %d documents, want 2", len(store.documents))
+	}
+	if store.documents[0].ID == "" || store.documents[0].ID != store.documents[1].ID {
+		t.Errorf("document IDs = %q and %q, want the same non-empty ID", store.documents[0].ID, store.documents[1].ID)
+	}
+}
+
+func TestIndexFiles_RejectsPathsOutsideRepository(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(&mockStore{})
+
+	for _, file := range []string{"../secret.txt", "/etc/passwd"} {
+		_, err := svc.IndexFiles(context.Background(), tmpDir, []string{file}, IndexOptions{TenantID: "testuser"})
+		if err == nil {
+			t.Errorf("IndexFiles(%q) should error", file)
+		}
+	}
+}
+
 // ===== NEW TESTS: SEARCH WITH BRANCH FILTER =====
 
 func TestSearch_UsesCodebaseCollection(t *testing.T) {
@@ -1261,3 +1328,63 @@ func TestGrep_SkipsDefaultDirs(t *testing.T) {
 		t.Errorf("Got %d results, want 1 (should skip .git and node_modules)", len(results))
 	}
 }
+
+func TestIndexRepository_StoresDocumentMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, tmpDir, "main.go", "package main")
+	createTestFile(t, tmpDir, "docs/adr/0001-use-grpc.md", "# Use gRPC\n\n## Status\n\nAccepted\n")
+
+	store := &mockStore{}
+	svc := NewService(store)
+
+	if _, err := svc.IndexRepository(context.Background(), tmpDir, IndexOptions{TenantID: "testuser"}); err != nil {
+		t.Fatalf("IndexRepository() error = %v", err)
+	}
+
+	var found bool
+	for _, doc := range store.documents {
+		switch doc.Metadata["file_path"] {
+		case "main.go":
+			if _, ok := doc.Metadata[MetadataDocType]; ok {
+				t.Errorf("main.go has doc_type %v, want none", doc.Metadata[MetadataDocType])
+			}
+		case filepath.Join("docs", "adr", "0001-use-grpc.md"):
+			found = true
+			if doc.Metadata[MetadataDocType] != "adr" || doc.Metadata[MetadataDocTitle] != "Use gRPC" || doc.Metadata[MetadataDocStatus] != "accepted" {
+				t.Errorf("ADR metadata = %v", doc.Metadata)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("ADR was not indexed")
+	}
+}
+
+func TestSearch_FiltersByDocType(t *testing.T) {
+	store := &mockStore{
+		searchResults: []vectorstore.SearchResult{
+			{ID: "1", Content: "Use gRPC", Score: 0.9, Metadata: map[string]interface{}{"file_path": "docs/adr/0001.md", "doc_type": "adr", "doc_title": "Use gRPC"}},
+		},
+	}
+	svc := NewService(store)
+	opts := SearchOptions{ProjectPath: "/path/to/project", TenantID: "testuser", Limit: 10}
+
+	results, err := svc.Search(context.Background(), "type:adr transport", opts)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if store.lastQuery != "transport" {
+		t.Errorf("Search query = %q, want %q", store.lastQuery, "transport")
+	}
+	if store.lastFilters["doc_type"] != "adr" {
+		t.Errorf("Search doc_type filter = %v, want %q", store.lastFilters["doc_type"], "adr")
+	}
+	if len(results) != 1 || results[0].DocType != "adr" || results[0].Title != "Use gRPC" {
+		t.Errorf("Search() results = %+v", results)
+	}
+
+	opts.DocType = "wiki"
+	if _, err := svc.Search(context.Background(), "transport", opts); !errors.Is(err, ErrInvalidDocType) {
+		t.Errorf("Search() error = %v, want ErrInvalidDocType", err)
+	}
+}
@@ -23,6 +23,10 @@ type IndexOptions struct {
 	// MaxFileSize is the maximum file size in bytes to index.
 	// Default: 1MB (1048576), Maximum: 10MB (10485760).
 	MaxFileSize int64
+
+	// Preprocessors are rules transforming matching files before they are
+	// embedded, applied after the service's own rules.
+	Preprocessors []PreprocessorRule
 }
 
 // IndexResult contains the results of a repository indexing operation.
@@ -48,6 +52,25 @@ type IndexResult struct {
 	// MaxFileSize applied during indexing.
 	MaxFileSize int64
 
+	// Preprocessed is the number of files transformed by a preprocessor.
+	Preprocessed int
+
+	// PreprocessErrors describes the preprocessors that failed; those files
+	// were indexed without their transformation.
+	PreprocessErrors []string
+
 	// IndexedAt is the timestamp when indexing completed.
 	IndexedAt time.Time
 }
+
+// FileList is the set of files selected for indexing by ListFiles.
+type FileList struct {
+	// Path is the cleaned repository path.
+	Path string
+
+	// Branch is the git branch the files will be indexed under.
+	Branch string
+
+	// Files are the selected file paths, relative to Path.
+	Files []string
+}
@@ -0,0 +1,398 @@
+// Package resilience guards LLM calls with a per-call timeout, retries with
+// jitter on rate limits and server errors, and a circuit breaker per
+// feature that temporarily disables the feature's LLM use while its
+// provider is failing.
+//
+// Features check for ErrCircuitOpen and degrade the same way they do when
+// the tenant's LLM budget is exhausted: compression falls back to
+// extractive, consolidation pauses and troubleshooting runs pattern-only.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+const instrumentationName = "github.com/fyrsmithlabs/contextd/internal/resilience"
+
+// Defaults for zero Config fields.
+const (
+	DefaultTimeout          = 60 * time.Second
+	DefaultMaxRetries       = 2
+	DefaultBaseBackoff      = 500 * time.Millisecond
+	DefaultMaxBackoff       = 10 * time.Second
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned by Policy.Do without calling the LLM while a
+// feature's circuit breaker is open.
+var ErrCircuitOpen = errors.New("LLM circuit breaker open")
+
+// Config configures a Policy. Zero fields use the defaults above.
+type Config struct {
+	// Timeout bounds each attempt, including the response body.
+	Timeout time.Duration
+
+	// MaxRetries is how many times a retryable failure is retried. Negative
+	// disables retries.
+	MaxRetries int
+
+	// BaseBackoff is the wait before the first retry. It doubles for each
+	// further retry, up to MaxBackoff, and is jittered.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// FailureThreshold is how many calls in a row must fail, after retries,
+	// before a feature's breaker opens.
+	FailureThreshold int
+
+	// Cooldown is how long an open breaker rejects calls before letting a
+	// single probe through.
+	Cooldown time.Duration
+}
+
+// withDefaults returns c with zero fields set to their defaults.
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	switch {
+	case c.MaxRetries == 0:
+		c.MaxRetries = DefaultMaxRetries
+	case c.MaxRetries < 0:
+		c.MaxRetries = 0
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = DefaultBaseBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = DefaultFailureThreshold
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = DefaultCooldown
+	}
+	return c
+}
+
+// StatusError is an unsuccessful HTTP response from an LLM provider. LLM
+// clients return it (or wrap it) so the Policy can tell retryable failures
+// from bad requests.
+type StatusError struct {
+	StatusCode int
+
+	// RetryAfter is the provider's Retry-After hint, if any.
+	RetryAfter time.Duration
+
+	// Message describes the failure, typically the provider's error message.
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// NewStatusError returns a StatusError for resp, reading its Retry-After
+// header.
+func NewStatusError(resp *http.Response, message string) *StatusError {
+	err := &StatusError{StatusCode: resp.StatusCode, Message: message}
+	if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && secs > 0 {
+		err.RetryAfter = time.Duration(secs) * time.Second
+	}
+	return err
+}
+
+// Retryable reports whether err is a transient provider failure: a 429 or
+// 5xx response, a timeout, or a network error.
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// State is the state of a feature's circuit breaker.
+type State int
+
+const (
+	// StateClosed lets calls through.
+	StateClosed State = iota
+
+	// StateHalfOpen lets a single probe call through after the cooldown.
+	StateHalfOpen
+
+	// StateOpen rejects calls with ErrCircuitOpen.
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker is the circuit breaker of one feature.
+type breaker struct {
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Policy applies timeouts, retries and circuit breaking to LLM calls. It is
+// safe for concurrent use and meant to be shared by all LLM features. A nil
+// *Policy calls through without any of them.
+type Policy struct {
+	config Config
+	logger *zap.Logger
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+
+	retries  metric.Int64Counter
+	rejected metric.Int64Counter
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// New creates a Policy and registers its metrics: the
+// contextd.llm.circuit_state gauge (0 closed, 1 half-open, 2 open) and the
+// contextd.llm.retries_total and contextd.llm.circuit_rejections_total
+// counters, all labeled by feature.
+func New(cfg Config, logger *zap.Logger) (*Policy, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	p := &Policy{
+		config:   cfg.withDefaults(),
+		logger:   logger,
+		now:      time.Now,
+		sleep:    sleep,
+		breakers: make(map[string]*breaker),
+	}
+
+	meter := otel.Meter(instrumentationName)
+	var err error
+	p.retries, err = meter.Int64Counter(
+		"contextd.llm.retries_total",
+		metric.WithDescription("LLM calls retried after a rate limit, server error or timeout, labeled by feature."),
+		metric.WithUnit("{retry}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create LLM retry counter", zap.Error(err))
+	}
+	p.rejected, err = meter.Int64Counter(
+		"contextd.llm.circuit_rejections_total",
+		metric.WithDescription("LLM calls skipped because the feature's circuit breaker is open, labeled by feature."),
+		metric.WithUnit("{operation}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create LLM circuit rejection counter", zap.Error(err))
+	}
+	_, err = meter.Int64ObservableGauge(
+		"contextd.llm.circuit_state",
+		metric.WithDescription("LLM circuit breaker state per feature: 0 closed, 1 half-open, 2 open."),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			for feature, state := range p.States() {
+				o.Observe(int64(state), metric.WithAttributes(attribute.String("feature", feature)))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		logger.Warn("failed to create LLM circuit state gauge", zap.Error(err))
+	}
+	return p, nil
+}
+
+// Do calls fn for feature, bounding each attempt by the configured timeout
+// and retrying retryable failures with jittered exponential backoff. It
+// returns an error wrapping ErrCircuitOpen without calling fn while the
+// feature's breaker is open.
+func (p *Policy) Do(ctx context.Context, feature string, fn func(ctx context.Context) error) error {
+	if p == nil {
+		return fn(ctx)
+	}
+	if err := p.acquire(feature); err != nil {
+		if p.rejected != nil {
+			p.rejected.Add(ctx, 1, metric.WithAttributes(attribute.String("feature", feature)))
+		}
+		return err
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+		err = fn(attemptCtx)
+		cancel()
+		if err == nil || !Retryable(err) || ctx.Err() != nil || attempt >= p.config.MaxRetries {
+			break
+		}
+
+		if p.retries != nil {
+			p.retries.Add(ctx, 1, metric.WithAttributes(attribute.String("feature", feature)))
+		}
+		wait := p.backoff(attempt, err)
+		p.logger.Debug("retrying LLM call",
+			zap.String("feature", feature),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", wait),
+			zap.Error(err))
+		if sleepErr := p.sleep(ctx, wait); sleepErr != nil {
+			break
+		}
+	}
+
+	// Only provider failures count against the breaker; a rejected request
+	// or a caller's cancellation says nothing about the provider's health.
+	p.release(feature, err != nil && Retryable(err) && ctx.Err() == nil)
+	return err
+}
+
+// State returns the breaker state of feature.
+func (p *Policy) State(feature string) State {
+	if p == nil {
+		return StateClosed
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stateLocked(feature)
+}
+
+// States returns the breaker state of every feature that has made a call.
+func (p *Policy) States() map[string]State {
+	if p == nil {
+		return map[string]State{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	states := make(map[string]State, len(p.breakers))
+	for feature := range p.breakers {
+		states[feature] = p.stateLocked(feature)
+	}
+	return states
+}
+
+// stateLocked returns feature's state, reporting an open breaker whose
+// cooldown has passed as half-open.
+func (p *Policy) stateLocked(feature string) State {
+	b, ok := p.breakers[feature]
+	if !ok {
+		return StateClosed
+	}
+	if b.state == StateOpen && p.now().Sub(b.openedAt) >= p.config.Cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// acquire admits a call for feature, or returns ErrCircuitOpen. After the
+// cooldown one call at a time is admitted as a probe.
+func (p *Policy) acquire(feature string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, ok := p.breakers[feature]
+	if !ok {
+		b = &breaker{}
+		p.breakers[feature] = b
+	}
+	switch p.stateLocked(feature) {
+	case StateClosed:
+		return nil
+	case StateHalfOpen:
+		if !b.probing {
+			b.state = StateHalfOpen
+			b.probing = true
+			return nil
+		}
+	}
+	if retryIn := p.config.Cooldown - p.now().Sub(b.openedAt); retryIn > 0 {
+		return fmt.Errorf("%w for %s, retrying in %s", ErrCircuitOpen, feature, retryIn.Round(time.Second))
+	}
+	return fmt.Errorf("%w for %s, probe in progress", ErrCircuitOpen, feature)
+}
+
+// release records the outcome of a call admitted by acquire.
+func (p *Policy) release(feature string, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b := p.breakers[feature]
+	wasProbe := b.probing
+	b.probing = false
+
+	if !failed {
+		if b.state != StateClosed {
+			p.logger.Info("LLM circuit breaker closed", zap.String("feature", feature))
+		}
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if wasProbe || (b.state == StateClosed && b.failures >= p.config.FailureThreshold) {
+		b.state = StateOpen
+		b.openedAt = p.now()
+		p.logger.Warn("LLM circuit breaker opened, feature degraded",
+			zap.String("feature", feature),
+			zap.Int("consecutive_failures", b.failures),
+			zap.Duration("cooldown", p.config.Cooldown))
+	}
+}
+
+// backoff returns the wait before retry number attempt+1: exponential from
+// BaseBackoff, capped at MaxBackoff, with jitter between half and all of
+// it, and never shorter than the provider's Retry-After hint.
+func (p *Policy) backoff(attempt int, err error) time.Duration {
+	d := p.config.BaseBackoff << attempt
+	if d <= 0 || d > p.config.MaxBackoff {
+		d = p.config.MaxBackoff
+	}
+	d = d/2 + rand.N(d/2+1)
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > d {
+		d = min(statusErr.RetryAfter, p.config.MaxBackoff)
+	}
+	return d
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
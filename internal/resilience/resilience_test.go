@@ -0,0 +1,171 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestPolicy returns a Policy with a fake clock that never sleeps.
+func newTestPolicy(t *testing.T, cfg Config) (*Policy, *time.Time, *[]time.Duration) {
+	t.Helper()
+	p, err := New(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var waits []time.Duration
+	p.now = func() time.Time { return now }
+	p.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return p, &now, &waits
+}
+
+func TestPolicy_Retries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries rate limits and server errors", func(t *testing.T) {
+		p, _, waits := newTestPolicy(t, Config{MaxRetries: 3, BaseBackoff: 100 * time.Millisecond})
+		calls := 0
+		err := p.Do(ctx, "distiller", func(context.Context) error {
+			calls++
+			switch calls {
+			case 1:
+				return &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second}
+			case 2:
+				return &StatusError{StatusCode: http.StatusBadGateway}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		require.Len(t, *waits, 2)
+		assert.Equal(t, 2*time.Second, (*waits)[0], "Retry-After wins over a shorter backoff")
+		assert.GreaterOrEqual(t, (*waits)[1], 100*time.Millisecond)
+		assert.LessOrEqual(t, (*waits)[1], 200*time.Millisecond)
+	})
+
+	t.Run("does not retry bad requests", func(t *testing.T) {
+		p, _, _ := newTestPolicy(t, Config{})
+		calls := 0
+		err := p.Do(ctx, "distiller", func(context.Context) error {
+			calls++
+			return &StatusError{StatusCode: http.StatusBadRequest, Message: "bad prompt"}
+		})
+		assert.EqualError(t, err, "API returned status 400: bad prompt")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		p, _, _ := newTestPolicy(t, Config{MaxRetries: 2})
+		calls := 0
+		err := p.Do(ctx, "distiller", func(context.Context) error {
+			calls++
+			return &StatusError{StatusCode: http.StatusServiceUnavailable}
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("negative max retries disables retries", func(t *testing.T) {
+		p, _, _ := newTestPolicy(t, Config{MaxRetries: -1})
+		calls := 0
+		_ = p.Do(ctx, "distiller", func(context.Context) error {
+			calls++
+			return &StatusError{StatusCode: http.StatusServiceUnavailable}
+		})
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("bounds each attempt", func(t *testing.T) {
+		p, _, _ := newTestPolicy(t, Config{Timeout: 10 * time.Millisecond, MaxRetries: -1})
+		err := p.Do(ctx, "distiller", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestPolicy_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	p, now, _ := newTestPolicy(t, Config{MaxRetries: -1, FailureThreshold: 2, Cooldown: time.Minute})
+
+	unavailable := func(context.Context) error { return &StatusError{StatusCode: http.StatusServiceUnavailable} }
+	ok := func(context.Context) error { return nil }
+
+	// Bad requests don't count as provider failures
+	_ = p.Do(ctx, "compression", func(context.Context) error { return &StatusError{StatusCode: http.StatusBadRequest} })
+	_ = p.Do(ctx, "compression", unavailable)
+	assert.Equal(t, StateClosed, p.State("compression"))
+	_ = p.Do(ctx, "compression", unavailable)
+	assert.Equal(t, StateOpen, p.State("compression"))
+
+	called := false
+	err := p.Do(ctx, "compression", func(context.Context) error { called = true; return nil })
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Contains(t, err.Error(), "retrying in 1m0s")
+	assert.False(t, called)
+
+	// Other features are unaffected
+	require.NoError(t, p.Do(ctx, "troubleshoot", ok))
+	assert.Equal(t, map[string]State{"compression": StateOpen, "troubleshoot": StateClosed}, p.States())
+
+	// After the cooldown a failed probe reopens the breaker
+	*now = now.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, p.State("compression"))
+	_ = p.Do(ctx, "compression", unavailable)
+	assert.Equal(t, StateOpen, p.State("compression"))
+
+	// A successful probe closes it
+	*now = now.Add(time.Minute)
+	require.NoError(t, p.Do(ctx, "compression", ok))
+	assert.Equal(t, StateClosed, p.State("compression"))
+}
+
+func TestPolicy_HalfOpenAdmitsOneProbe(t *testing.T) {
+	ctx := context.Background()
+	p, now, _ := newTestPolicy(t, Config{MaxRetries: -1, FailureThreshold: 1, Cooldown: time.Minute})
+	_ = p.Do(ctx, "distiller", func(context.Context) error { return &StatusError{StatusCode: http.StatusInternalServerError} })
+	*now = now.Add(time.Minute)
+
+	err := p.Do(ctx, "distiller", func(ctx context.Context) error {
+		inner := p.Do(ctx, "distiller", func(context.Context) error { return nil })
+		assert.ErrorIs(t, inner, ErrCircuitOpen)
+		assert.Contains(t, inner.Error(), "probe in progress")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StateClosed, p.State("distiller"))
+}
+
+func TestNilPolicy(t *testing.T) {
+	var p *Policy
+	calls := 0
+	err := p.Do(context.Background(), "distiller", func(context.Context) error {
+		calls++
+		return &StatusError{StatusCode: http.StatusServiceUnavailable}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, StateClosed, p.State("distiller"))
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, Retryable(&StatusError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, Retryable(&StatusError{StatusCode: http.StatusInternalServerError}))
+	assert.False(t, Retryable(&StatusError{StatusCode: http.StatusUnauthorized}))
+	assert.True(t, Retryable(context.DeadlineExceeded))
+	assert.False(t, Retryable(context.Canceled))
+	assert.False(t, Retryable(errors.New("failed to parse response")))
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"7"}}}
+	assert.Equal(t, 7*time.Second, NewStatusError(resp, "slow down").RetryAfter)
+}
@@ -43,6 +43,28 @@ func GetTenantIDForPath(repoPath string) string {
 	return "local"
 }
 
+// GetDefaultUserID returns the local developer's identity, which owns the
+// private and team documents they record.
+// Priority: $CONTEXTD_USER → git user.email → $USER → "" (no user).
+func GetDefaultUserID() string {
+	if user := strings.TrimSpace(os.Getenv("CONTEXTD_USER")); user != "" {
+		return user
+	}
+
+	cfg, err := config.LoadConfig(config.GlobalScope)
+	if err == nil && cfg.User.Email != "" {
+		return strings.ToLower(cfg.User.Email)
+	}
+
+	return os.Getenv("USER")
+}
+
+// GetDefaultTeamID returns the local developer's team from $CONTEXTD_TEAM,
+// or "" if unset. Teammates see each other's team documents.
+func GetDefaultTeamID() string {
+	return strings.TrimSpace(os.Getenv("CONTEXTD_TEAM"))
+}
+
 // getGitHubUsernameFromRepo extracts the GitHub username from the origin remote URL.
 func getGitHubUsernameFromRepo(repoPath string) string {
 	repo, err := git.PlainOpen(repoPath)
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 // Scope defines the hierarchy level for multi-tenant collections.
@@ -48,7 +50,7 @@ var (
 	ErrInvalidTeamID     = errors.New("invalid team ID")
 	ErrInvalidProjectID  = errors.New("invalid project ID")
 	ErrInvalidCollection = errors.New("invalid collection type")
-	ErrAccessDenied      = errors.New("access denied")
+	ErrAccessDenied      = ctxerrors.Unauthorized("access denied")
 )
 
 // CollectionRouter routes requests to the appropriate collection based on tenant scope.
@@ -0,0 +1,214 @@
+package troubleshoot
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+)
+
+const (
+	// maxCodeFiles caps the files looked up in the repository per diagnosis.
+	maxCodeFiles = 5
+
+	// maxCodeSnippets caps the snippets attached to a diagnosis.
+	maxCodeSnippets = 5
+
+	// snippetRadius is the number of lines shown on each side of the line an
+	// error references.
+	snippetRadius = 6
+
+	// codeSearchLimit is the number of indexed files searched for each
+	// referenced file.
+	codeSearchLimit = 10
+)
+
+// CodeSearcher searches a project's indexed repository.
+// *repository.Service implements it.
+type CodeSearcher interface {
+	Search(ctx context.Context, query string, opts repository.SearchOptions) ([]repository.RepoSearchResult, error)
+}
+
+// SetCodeSearcher grounds diagnoses of errors in projects with an indexed
+// repository: code at the file:line locations referenced by the error is
+// added to the AI prompt and returned as Diagnosis.CodeSnippets. Secrets are
+// scrubbed from the code with scrubber first, unless it is nil. Must be
+// called before the service is used.
+func (s *Service) SetCodeSearcher(c CodeSearcher, scrubber secrets.Scrubber) {
+	s.code = c
+	s.scrubber = scrubber
+}
+
+// locationPatterns match source locations in errors and stack traces. The
+// first group is the file, the second the line.
+var locationPatterns = []*regexp.Regexp{
+	// Python: File "/app/svc/handler.py", line 42
+	regexp.MustCompile(`File "([^"]+)", line (\d+)`),
+	// Go, Rust, JavaScript, C, Java and most compilers:
+	// /src/api/handler.go:42 +0x1d, at fn (src/app.ts:10:5), (Handler.java:42)
+	regexp.MustCompile(`(?:^|[\s(\["'@])((?:[A-Za-z]:)?[\w.~/\\-]*[\w-]+\.[A-Za-z]\w{0,5}):(\d+)`),
+}
+
+// Location is a source file position referenced by an error.
+type Location struct {
+	File string
+	Line int
+}
+
+// ExtractLocations returns the file:line positions referenced by text, in
+// order of appearance and without duplicates.
+func ExtractLocations(text string) []Location {
+	type match struct {
+		pos int
+		loc Location
+	}
+	var matches []match
+	for _, re := range locationPatterns {
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			line, err := strconv.Atoi(text[m[4]:m[5]])
+			if err != nil || line <= 0 {
+				continue
+			}
+			file := strings.ReplaceAll(text[m[2]:m[3]], `\`, "/")
+			matches = append(matches, match{pos: m[2], loc: Location{File: file, Line: line}})
+		}
+	}
+
+	// Order by position so the first frame of a trace comes first
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].pos < matches[j].pos })
+
+	seen := make(map[Location]bool)
+	var locations []Location
+	for _, m := range matches {
+		if !seen[m.loc] {
+			seen[m.loc] = true
+			locations = append(locations, m.loc)
+		}
+	}
+	return locations
+}
+
+// findCode looks up the locations referenced by the error in the project's
+// indexed repository and returns the code around them. Files that are not
+// indexed are skipped.
+func (s *Service) findCode(ctx context.Context, errorMsg, errorContext string, opts DiagnoseOptions) []CodeSnippet {
+	if s.code == nil || opts.ProjectPath == "" {
+		return nil
+	}
+	ctx, span := s.tracer.Start(ctx, "findCode")
+	defer span.End()
+
+	locations := ExtractLocations(errorMsg + "\n" + errorContext)
+	if len(locations) == 0 {
+		return nil
+	}
+
+	// Group lines by file so each file is searched once
+	var files []string
+	lines := make(map[string][]int)
+	for _, loc := range locations {
+		if _, ok := lines[loc.File]; !ok {
+			if len(files) == maxCodeFiles {
+				continue
+			}
+			files = append(files, loc.File)
+		}
+		lines[loc.File] = append(lines[loc.File], loc.Line)
+	}
+
+	var snippets []CodeSnippet
+	for _, file := range files {
+		results, err := s.code.Search(ctx, errorMsg+"\n"+path.Base(file), repository.SearchOptions{
+			ProjectPath: opts.ProjectPath,
+			TenantID:    opts.TenantID,
+			Branch:      opts.Branch,
+			Limit:       codeSearchLimit,
+		})
+		if err != nil {
+			// Most often the project was never indexed
+			s.logger.Debug("repository search failed", zap.String("file", file), zap.Error(err))
+			return snippets
+		}
+
+		var best *repository.RepoSearchResult
+		for i, r := range results {
+			if sameFile(file, r.FilePath) && (best == nil || r.Score > best.Score) {
+				best = &results[i]
+			}
+		}
+		if best == nil {
+			continue
+		}
+		for _, line := range lines[file] {
+			snippet, ok := codeSnippet(*best, line)
+			if !ok {
+				continue
+			}
+			if s.scrubber != nil {
+				snippet.Content = s.scrubber.Scrub(snippet.Content).Scrubbed
+			}
+			snippets = append(snippets, snippet)
+			if len(snippets) == maxCodeSnippets {
+				return snippets
+			}
+		}
+	}
+	return snippets
+}
+
+// sameFile reports whether a file referenced by an error, absolute or
+// relative to any directory, is the repository-relative indexed path.
+func sameFile(referenced, indexed string) bool {
+	indexed = strings.ReplaceAll(indexed, `\`, "/")
+	if referenced == "" || indexed == "" {
+		return false
+	}
+	return referenced == indexed ||
+		strings.HasSuffix(referenced, "/"+indexed) ||
+		strings.HasSuffix(indexed, "/"+referenced)
+}
+
+// codeSnippet cuts the lines around line out of an indexed file. It fails
+// when the file is shorter than line, i.e. the index is stale.
+func codeSnippet(r repository.RepoSearchResult, line int) (CodeSnippet, bool) {
+	fileLines := strings.Split(r.Content, "\n")
+	if line > len(fileLines) {
+		return CodeSnippet{}, false
+	}
+	start := max(1, line-snippetRadius)
+	end := min(len(fileLines), line+snippetRadius)
+	return CodeSnippet{
+		FilePath:  r.FilePath,
+		Line:      line,
+		StartLine: start,
+		EndLine:   end,
+		Content:   strings.Join(fileLines[start-1:end], "\n"),
+		Branch:    r.Branch,
+	}, true
+}
+
+// writeCodeSnippets adds snippets to a diagnostic prompt, numbering lines
+// and marking the referenced one.
+func writeCodeSnippets(sb *strings.Builder, snippets []CodeSnippet) {
+	sb.WriteString("Code referenced by the error, from the indexed repository (>> marks the referenced line):\n")
+	for _, c := range snippets {
+		fmt.Fprintf(sb, "%s:%d\n```\n", c.FilePath, c.Line)
+		for i, l := range strings.Split(c.Content, "\n") {
+			marker := "  "
+			if c.StartLine+i == c.Line {
+				marker = ">>"
+			}
+			fmt.Fprintf(sb, "%s %4d | %s\n", marker, c.StartLine+i, l)
+		}
+		sb.WriteString("```\n")
+	}
+	sb.WriteString("\n")
+}
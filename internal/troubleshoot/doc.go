@@ -60,6 +60,15 @@
 // Pattern-based diagnosis is always attempted first for speed and cost efficiency.
 // AI diagnosis is only used when patterns don't provide high-confidence matches.
 //
+// # Code Grounding
+//
+// With SetCodeSearcher and a DiagnoseOptions.ProjectPath, DiagnoseWithOptions
+// extracts file:line locations from the error and its context (see
+// ExtractLocations), finds those files in the project's indexed repository and
+// returns the lines around each location as CodeSnippets. The snippets are
+// also added to the AI prompt, so hypotheses can cite the code. Referenced
+// files match indexed paths by suffix; unindexed files are skipped.
+//
 // # AI Client
 //
 // The AI client is optional. If not provided during service creation, the service
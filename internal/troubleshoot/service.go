@@ -18,6 +18,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 )
 
@@ -40,6 +43,11 @@ type Service struct {
 	logger   *zap.Logger
 	aiClient AIClient
 	tracer   trace.Tracer
+	usage    analytics.UsageRecorder
+	budget   analytics.LLMBudget
+	policy   *resilience.Policy
+	code     CodeSearcher
+	scrubber secrets.Scrubber
 }
 
 // NewService creates a new troubleshoot service.
@@ -62,6 +70,28 @@ func NewService(store VectorStore, logger *zap.Logger, aiClient AIClient) (*Serv
 	}, nil
 }
 
+// SetUsageRecorder reports the estimated token usage of AI diagnoses to r.
+// AI clients implementing analytics.ModelDescriber are priced. Must be
+// called before the service is used.
+func (s *Service) SetUsageRecorder(r analytics.UsageRecorder) {
+	s.usage = r
+}
+
+// SetLLMPolicy applies p's timeouts, retries and circuit breaking to AI
+// diagnoses. While the troubleshoot breaker is open, Diagnose runs
+// pattern-only and says so in the diagnosis warnings. Must be called before
+// the service is used.
+func (s *Service) SetLLMPolicy(p *resilience.Policy) {
+	s.policy = p
+}
+
+// SetBudget checks b before each AI diagnosis. When the tenant's LLM budget
+// is exhausted, Diagnose runs pattern-only and says so in the diagnosis
+// warnings. Must be called before the service is used.
+func (s *Service) SetBudget(b analytics.LLMBudget) {
+	s.budget = b
+}
+
 // SavePattern stores an error pattern for future matching.
 //
 // Patterns are stored in the shared database with embeddings for semantic
@@ -179,6 +209,14 @@ func (s *Service) GetPatterns(ctx context.Context) ([]Pattern, error) {
 // 4. Combine pattern matches with AI hypotheses
 // 5. Generate recommendations
 func (s *Service) Diagnose(ctx context.Context, errorMsg, errorContext string) (*Diagnosis, error) {
+	return s.DiagnoseWithOptions(ctx, errorMsg, errorContext, DiagnoseOptions{})
+}
+
+// DiagnoseWithOptions is Diagnose for an error in a project. When the
+// project's repository is indexed, the code at file:line locations the error
+// and its context reference is added to the AI prompt and returned in
+// CodeSnippets, so hypotheses can point at the actual code.
+func (s *Service) DiagnoseWithOptions(ctx context.Context, errorMsg, errorContext string, opts DiagnoseOptions) (*Diagnosis, error) {
 	ctx, span := s.tracer.Start(ctx, "Service.Diagnose")
 	defer span.End()
 
@@ -187,6 +225,8 @@ func (s *Service) Diagnose(ctx context.Context, errorMsg, errorContext string) (
 		return nil, ErrEmptyErrorMessage
 	}
 
+	code := s.findCode(ctx, errorMsg, errorContext, opts)
+
 	// 1. Search known patterns
 	patterns, err := s.searchPatterns(ctx, errorMsg)
 	if err != nil {
@@ -201,6 +241,7 @@ func (s *Service) Diagnose(ctx context.Context, errorMsg, errorContext string) (
 	// 2. Check for high-confidence pattern match
 	if len(patterns) > 0 && patterns[0].Confidence > 0.8 {
 		diagnosis := s.buildDiagnosisFromPattern(patterns[0], patterns)
+		diagnosis.CodeSnippets = code
 		s.logger.Info("high-confidence pattern match",
 			zap.String("pattern_id", patterns[0].ID),
 			zap.Float64("confidence", patterns[0].Confidence),
@@ -212,10 +253,24 @@ func (s *Service) Diagnose(ctx context.Context, errorMsg, errorContext string) (
 	hypotheses := []Hypothesis{}  // Initialize as empty slice, not nil (for JSON encoding)
 	recommendations := []string{} // Initialize as empty slice, not nil (for JSON encoding)
 	var aiRootCause string
+	var warnings []string
+
+	useAI := s.aiClient != nil
+	if useAI && s.budget != nil {
+		if err := s.budget.Allow(ctx, analytics.FeatureTroubleshoot); err != nil {
+			s.logger.Warn("LLM budget exhausted, diagnosing from patterns only", zap.Error(err))
+			warnings = append(warnings, fmt.Sprintf("AI diagnosis skipped, pattern matches only: %v", err))
+			useAI = false
+		}
+	}
 
-	if s.aiClient != nil {
-		aiResponse, err := s.generateHypotheses(ctx, errorMsg, errorContext, patterns)
-		if err != nil {
+	if useAI {
+		aiResponse, err := s.generateHypotheses(ctx, errorMsg, errorContext, patterns, code)
+		switch {
+		case errors.Is(err, resilience.ErrCircuitOpen):
+			s.logger.Warn("LLM circuit breaker open, diagnosing from patterns only", zap.Error(err))
+			warnings = append(warnings, fmt.Sprintf("AI diagnosis skipped, pattern matches only: %v", err))
+		case err != nil:
 			span.RecordError(err)
 			s.logger.Warn("AI hypothesis generation failed",
 				zap.Error(err),
@@ -223,13 +278,16 @@ func (s *Service) Diagnose(ctx context.Context, errorMsg, errorContext string) (
 			)
 			// Fallback to pattern-based diagnosis
 			if len(patterns) > 0 {
-				return s.buildDiagnosisFromPattern(patterns[0], patterns), nil
+				diagnosis := s.buildDiagnosisFromPattern(patterns[0], patterns)
+				diagnosis.CodeSnippets = code
+				return diagnosis, nil
 			}
 			return nil, fmt.Errorf("failed to generate diagnosis: %w", err)
+		default:
+			hypotheses = aiResponse.Hypotheses
+			aiRootCause = aiResponse.RootCause
+			recommendations = aiResponse.Recommendations
 		}
-		hypotheses = aiResponse.Hypotheses
-		aiRootCause = aiResponse.RootCause
-		recommendations = aiResponse.Recommendations
 	}
 
 	// 4. Build comprehensive diagnosis
@@ -240,6 +298,8 @@ func (s *Service) Diagnose(ctx context.Context, errorMsg, errorContext string) (
 		Recommendations: recommendations,
 		RelatedPatterns: patterns,
 		Confidence:      calculateConfidence(patterns, hypotheses),
+		Warnings:        warnings,
+		CodeSnippets:    code,
 	}
 
 	// Add pattern-based recommendations if available
@@ -251,6 +311,7 @@ func (s *Service) Diagnose(ctx context.Context, errorMsg, errorContext string) (
 		zap.String("error_msg", errorMsg),
 		zap.Int("pattern_count", len(patterns)),
 		zap.Int("hypothesis_count", len(hypotheses)),
+		zap.Int("code_snippet_count", len(code)),
 		zap.Float64("confidence", diagnosis.Confidence),
 	)
 
@@ -306,15 +367,31 @@ type aiDiagnosisResponse struct {
 }
 
 // generateHypotheses uses AI to generate diagnostic hypotheses.
-func (s *Service) generateHypotheses(ctx context.Context, errorMsg, errorContext string, patterns []Pattern) (*aiDiagnosisResponse, error) {
+func (s *Service) generateHypotheses(ctx context.Context, errorMsg, errorContext string, patterns []Pattern, code []CodeSnippet) (*aiDiagnosisResponse, error) {
 	ctx, span := s.tracer.Start(ctx, "generateHypotheses")
 	defer span.End()
 
 	// Build prompt for AI
-	prompt := buildDiagnosticPrompt(errorMsg, errorContext, patterns)
+	prompt := buildDiagnosticPrompt(errorMsg, errorContext, patterns, code)
 
 	// Call AI
-	responseText, err := s.aiClient.Generate(ctx, prompt)
+	var responseText string
+	err := s.policy.Do(ctx, analytics.FeatureTroubleshoot, func(ctx context.Context) error {
+		var err error
+		responseText, err = s.aiClient.Generate(ctx, prompt)
+		if s.usage != nil {
+			// AIClient does not expose token counts, so estimate them
+			u := analytics.LLMUsage{
+				Feature:      analytics.FeatureTroubleshoot,
+				InputTokens:  analytics.EstimateTokens(prompt),
+				OutputTokens: analytics.EstimateTokens(responseText),
+				Failed:       err != nil,
+			}
+			u.Provider, u.Model = analytics.DescribeModel(s.aiClient)
+			s.usage.RecordUsage(ctx, u)
+		}
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("AI generation failed: %w", err)
 	}
@@ -347,7 +424,7 @@ func (s *Service) buildDiagnosisFromPattern(primary Pattern, related []Pattern)
 }
 
 // buildDiagnosticPrompt creates the AI prompt for diagnosis.
-func buildDiagnosticPrompt(errorMsg, errorContext string, patterns []Pattern) string {
+func buildDiagnosticPrompt(errorMsg, errorContext string, patterns []Pattern, code []CodeSnippet) string {
 	var sb strings.Builder
 
 	sb.WriteString("You are an expert software engineer diagnosing an error.\n\n")
@@ -368,9 +445,17 @@ func buildDiagnosticPrompt(errorMsg, errorContext string, patterns []Pattern) st
 		sb.WriteString("\n")
 	}
 
+	if len(code) > 0 {
+		writeCodeSnippets(&sb, code)
+	}
+
 	sb.WriteString("Provide a JSON response with:\n")
 	sb.WriteString("1. root_cause: Brief description of the likely root cause\n")
-	sb.WriteString("2. hypotheses: Array of possible causes with description, likelihood (0-1), and evidence\n")
+	if len(code) > 0 {
+		sb.WriteString("2. hypotheses: Array of possible causes with description, likelihood (0-1), and evidence; cite file:line from the code above where it supports a hypothesis\n")
+	} else {
+		sb.WriteString("2. hypotheses: Array of possible causes with description, likelihood (0-1), and evidence\n")
+	}
 	sb.WriteString("3. recommendations: Array of actionable steps to fix the error\n\n")
 	sb.WriteString("Format: {\"root_cause\": \"...\", \"hypotheses\": [...], \"recommendations\": [...]}")
 
@@ -3,8 +3,11 @@ package troubleshoot
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/fyrsmithlabs/contextd/internal/analytics"
+	"github.com/fyrsmithlabs/contextd/internal/resilience"
 	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
 	"go.uber.org/zap"
 )
@@ -457,3 +460,109 @@ func TestDiagnose(t *testing.T) {
 		})
 	}
 }
+
+// usageRecorderFunc adapts a function to analytics.UsageRecorder
+type usageRecorderFunc func(ctx context.Context, u analytics.LLMUsage)
+
+func (f usageRecorderFunc) RecordUsage(ctx context.Context, u analytics.LLMUsage) { f(ctx, u) }
+
+func TestService_Diagnose_RecordsUsage(t *testing.T) {
+	ai := &mockAIClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return `{"root_cause": "Qdrant is not running", "hypotheses": [], "recommendations": ["Start Qdrant"]}`, nil
+		},
+	}
+	svc, err := NewService(&mockVectorStore{}, zap.NewNop(), ai)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	var got []analytics.LLMUsage
+	svc.SetUsageRecorder(usageRecorderFunc(func(ctx context.Context, u analytics.LLMUsage) {
+		got = append(got, u)
+	}))
+
+	if _, err := svc.Diagnose(context.Background(), "connection refused", ""); err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("recorded %d usages, want 1", len(got))
+	}
+	if got[0].Feature != analytics.FeatureTroubleshoot || got[0].Failed {
+		t.Errorf("usage = %+v", got[0])
+	}
+	if got[0].InputTokens == 0 || got[0].OutputTokens == 0 {
+		t.Errorf("usage should estimate tokens: %+v", got[0])
+	}
+}
+
+// budgetFunc adapts a function to analytics.LLMBudget
+type budgetFunc func(ctx context.Context, feature string) error
+
+func (f budgetFunc) Allow(ctx context.Context, feature string) error { return f(ctx, feature) }
+
+func TestService_Diagnose_BudgetExhausted(t *testing.T) {
+	ai := &mockAIClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			t.Error("AI client should not be called when the budget is exhausted")
+			return "", nil
+		},
+	}
+	svc, err := NewService(&mockVectorStore{}, zap.NewNop(), ai)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	svc.SetBudget(budgetFunc(func(ctx context.Context, feature string) error {
+		if feature != analytics.FeatureTroubleshoot {
+			t.Errorf("feature = %q, want %q", feature, analytics.FeatureTroubleshoot)
+		}
+		return analytics.ErrBudgetExhausted
+	}))
+
+	diagnosis, err := svc.Diagnose(context.Background(), "connection refused", "")
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	if len(diagnosis.Warnings) != 1 {
+		t.Fatalf("Warnings = %v, want one pattern-only warning", diagnosis.Warnings)
+	}
+	if len(diagnosis.Hypotheses) != 0 {
+		t.Errorf("Hypotheses = %v, want none without AI", diagnosis.Hypotheses)
+	}
+}
+
+func TestService_Diagnose_CircuitOpen(t *testing.T) {
+	calls := 0
+	ai := &mockAIClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			calls++
+			return "", &resilience.StatusError{StatusCode: 503, Message: "overloaded"}
+		},
+	}
+	svc, err := NewService(&mockVectorStore{}, zap.NewNop(), ai)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	policy, err := resilience.New(resilience.Config{MaxRetries: -1, FailureThreshold: 1}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("resilience.New() error = %v", err)
+	}
+	svc.SetLLMPolicy(policy)
+
+	// The first failure opens the breaker
+	if _, err := svc.Diagnose(context.Background(), "connection refused", ""); err == nil {
+		t.Fatal("Diagnose() expected error without patterns to fall back on")
+	}
+
+	diagnosis, err := svc.Diagnose(context.Background(), "connection refused", "")
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("AI client called %d times, want 1", calls)
+	}
+	if len(diagnosis.Warnings) != 1 || !strings.Contains(diagnosis.Warnings[0], "circuit breaker open") {
+		t.Errorf("Warnings = %v, want one circuit breaker warning", diagnosis.Warnings)
+	}
+}
@@ -15,12 +15,32 @@ var (
 
 // Diagnosis represents AI-powered error analysis.
 type Diagnosis struct {
-	ErrorMessage    string       `json:"error_message"`
-	RootCause       string       `json:"root_cause"`
-	Hypotheses      []Hypothesis `json:"hypotheses"`
-	Recommendations []string     `json:"recommendations"`
-	RelatedPatterns []Pattern    `json:"related_patterns"`
-	Confidence      float64      `json:"confidence"`
+	ErrorMessage    string        `json:"error_message"`
+	RootCause       string        `json:"root_cause"`
+	Hypotheses      []Hypothesis  `json:"hypotheses"`
+	Recommendations []string      `json:"recommendations"`
+	RelatedPatterns []Pattern     `json:"related_patterns"`
+	Confidence      float64       `json:"confidence"`
+	Warnings        []string      `json:"warnings,omitempty"`      // Degraded diagnosis, e.g. pattern-only when the LLM budget is exhausted
+	CodeSnippets    []CodeSnippet `json:"code_snippets,omitempty"` // Indexed code at locations the error references
+}
+
+// DiagnoseOptions identifies the project an error occurred in. With a
+// ProjectPath and a CodeSearcher, diagnoses include the project's code.
+type DiagnoseOptions struct {
+	ProjectPath string // Repository root the error occurred in
+	TenantID    string // Optional: derived from ProjectPath when empty
+	Branch      string // Optional: indexed branch to read code from (empty = any)
+}
+
+// CodeSnippet is indexed repository code around a line an error references.
+type CodeSnippet struct {
+	FilePath  string `json:"file_path"` // Relative to the repository root
+	Line      int    `json:"line"`      // Line referenced by the error
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Content   string `json:"content"`
+	Branch    string `json:"branch,omitempty"`
 }
 
 // Hypothesis represents a possible cause of the error.
@@ -0,0 +1,148 @@
+// Package unixsock listens on Unix domain sockets for local contextd
+// transports.
+//
+// A socket avoids localhost TCP port conflicts and is guarded by filesystem
+// permissions: only users who can write the socket file can connect, so on a
+// shared machine other users cannot reach the server the way they could a
+// localhost port.
+//
+// # Permissions
+//
+// The socket is bound inside a private (0700) staging directory, given its
+// final mode and only then renamed into place, so it is never reachable with
+// looser permissions. The parent directory is created with 0700 if missing.
+package unixsock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultMode is the socket file mode: owner read/write only.
+const DefaultMode os.FileMode = 0600
+
+// maxPathLen is the portable limit for socket paths (sun_path is 104 bytes
+// on macOS and BSDs, 108 on Linux, including the terminating NUL).
+const maxPathLen = 103
+
+var (
+	// ErrInUse is returned when another process is already serving the
+	// socket path.
+	ErrInUse = errors.New("socket already in use")
+
+	// ErrNotSocket is returned when the path exists and is not a socket, so
+	// it is not removed.
+	ErrNotSocket = errors.New("path exists and is not a socket")
+)
+
+// Listen listens on the Unix socket at path with the given file mode
+// (DefaultMode when zero). A stale socket left by a crashed server is
+// replaced; a live one returns ErrInUse. Closing the listener removes the
+// socket file.
+func Listen(path string, mode os.FileMode) (net.Listener, error) {
+	if mode == 0 {
+		mode = DefaultMode
+	}
+	if mode&^0777 != 0 || mode&0600 != 0600 {
+		return nil, fmt.Errorf("invalid socket mode %o: must grant the owner read/write", mode)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) > maxPathLen {
+		return nil, fmt.Errorf("socket path %q is longer than %d bytes", path, maxPathLen)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating socket directory: %w", err)
+	}
+	if err := removeStale(path); err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(dir, ".sock-")
+	if err != nil {
+		return nil, fmt.Errorf("creating socket staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	bindPath := filepath.Join(staging, "s")
+	if len(bindPath) > maxPathLen {
+		// No room for the staging name: bind in place and chmod right
+		// after.
+		bindPath = path
+	}
+
+	ln, err := net.Listen("unix", bindPath)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	ul := ln.(*net.UnixListener)
+	// The socket is renamed below, so listener removes it by its final path.
+	ul.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(bindPath, mode); err != nil {
+		ul.Close()
+		_ = os.Remove(bindPath)
+		return nil, fmt.Errorf("setting socket mode: %w", err)
+	}
+	if bindPath != path {
+		if err := os.Rename(bindPath, path); err != nil {
+			ul.Close()
+			_ = os.Remove(bindPath)
+			return nil, fmt.Errorf("moving socket into place: %w", err)
+		}
+	}
+	return &listener{UnixListener: ul, path: path}, nil
+}
+
+// removeStale removes a socket at path that no process is serving.
+func removeStale(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%w: %s", ErrNotSocket, path)
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%w: %s", ErrInUse, path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing stale socket: %w", err)
+	}
+	return nil
+}
+
+// listener removes its socket file when closed.
+type listener struct {
+	*net.UnixListener
+	path string
+	once sync.Once
+}
+
+// Close stops listening and removes the socket file.
+func (l *listener) Close() error {
+	err := l.UnixListener.Close()
+	l.once.Do(func() { _ = os.Remove(l.path) })
+	return err
+}
+
+// Dial connects to the Unix socket at path.
+func Dial(ctx context.Context, path string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", path)
+}
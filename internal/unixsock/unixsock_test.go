@@ -0,0 +1,110 @@
+package unixsock
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shortTempDir returns a temp dir short enough for socket paths; t.TempDir
+// can exceed the sun_path limit on macOS.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "uds")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestListen(t *testing.T) {
+	t.Run("creates socket with owner-only mode and removes it on close", func(t *testing.T) {
+		path := filepath.Join(shortTempDir(t), "run", "contextd.sock")
+		ln, err := Listen(path, 0)
+		require.NoError(t, err)
+
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.ModeSocket, info.Mode().Type())
+		assert.Equal(t, DefaultMode, info.Mode().Perm())
+
+		dirInfo, err := os.Stat(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0700), dirInfo.Mode().Perm())
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "staging directory should be cleaned up")
+
+		go func() {
+			conn, err := ln.Accept()
+			if err == nil {
+				_, _ = conn.Write([]byte("ok"))
+				conn.Close()
+			}
+		}()
+		conn, err := Dial(context.Background(), path)
+		require.NoError(t, err)
+		buf := make([]byte, 2)
+		_, err = conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(buf))
+		conn.Close()
+
+		require.NoError(t, ln.Close())
+		_, err = os.Lstat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("group mode", func(t *testing.T) {
+		path := filepath.Join(shortTempDir(t), "g.sock")
+		ln, err := Listen(path, 0660)
+		require.NoError(t, err)
+		defer ln.Close()
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+	})
+
+	t.Run("rejects a mode the owner cannot use", func(t *testing.T) {
+		_, err := Listen(filepath.Join(shortTempDir(t), "x.sock"), 0400)
+		assert.Error(t, err)
+	})
+
+	t.Run("live socket is in use", func(t *testing.T) {
+		path := filepath.Join(shortTempDir(t), "live.sock")
+		ln, err := Listen(path, 0)
+		require.NoError(t, err)
+		defer ln.Close()
+
+		_, err = Listen(path, 0)
+		assert.ErrorIs(t, err, ErrInUse)
+	})
+
+	t.Run("stale socket is replaced", func(t *testing.T) {
+		path := filepath.Join(shortTempDir(t), "stale.sock")
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		require.NoError(t, stale.Close())
+
+		ln, err := Listen(path, 0)
+		require.NoError(t, err)
+		assert.NoError(t, ln.Close())
+	})
+
+	t.Run("refuses to replace a regular file", func(t *testing.T) {
+		path := filepath.Join(shortTempDir(t), "file")
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+
+		_, err := Listen(path, 0)
+		assert.ErrorIs(t, err, ErrNotSocket)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "data", string(data))
+	})
+}
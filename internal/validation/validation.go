@@ -0,0 +1,183 @@
+// Package validation enforces request size and complexity limits shared by
+// the HTTP API, the MCP tools and the services behind them.
+//
+// Each service has its own Limits. A request that exceeds one is rejected
+// with an *Error carrying a stable ERR_VALIDATION_* code instead of being
+// truncated, so clients can tell which field to shorten and by how much.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Code identifies a validation failure. Codes are stable and can be used for
+// programmatic error handling.
+type Code string
+
+const (
+	CodeQueryTooLong        Code = "ERR_VALIDATION_QUERY_TOO_LONG"
+	CodeContentTooLong      Code = "ERR_VALIDATION_CONTENT_TOO_LONG"
+	CodeTooManyTags         Code = "ERR_VALIDATION_TOO_MANY_TAGS"
+	CodeTagTooLong          Code = "ERR_VALIDATION_TAG_TOO_LONG"
+	CodeTooManyMetadataKeys Code = "ERR_VALIDATION_TOO_MANY_METADATA_KEYS"
+)
+
+// ErrValidation matches every *Error with errors.Is.
+var ErrValidation = errors.New("validation failed")
+
+// Error reports a field that exceeds a limit.
+type Error struct {
+	Code   Code   `json:"code"`
+	Field  string `json:"field"`
+	Limit  int    `json:"limit"`
+	Actual int    `json:"actual"`
+}
+
+// NewError creates an Error reporting that field measured actual against
+// limit.
+func NewError(code Code, field string, limit, actual int) *Error {
+	return &Error{Code: code, Field: field, Limit: limit, Actual: actual}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message())
+}
+
+// Message describes the violation without the code.
+func (e *Error) Message() string {
+	switch e.Code {
+	case CodeTooManyTags:
+		return fmt.Sprintf("%s has %d tags, maximum is %d", e.Field, e.Actual, e.Limit)
+	case CodeTooManyMetadataKeys:
+		return fmt.Sprintf("%s has %d keys, maximum is %d", e.Field, e.Actual, e.Limit)
+	default:
+		return fmt.Sprintf("%s exceeds maximum length of %d characters (got %d)", e.Field, e.Limit, e.Actual)
+	}
+}
+
+// Is reports whether target is ErrValidation.
+func (e *Error) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var verr *Error
+	if errors.As(err, &verr) {
+		return verr, true
+	}
+	return nil, false
+}
+
+// Limits bounds the requests one service accepts. A zero field means no
+// limit. Lengths are counted in characters (runes).
+type Limits struct {
+	MaxQueryLength   int
+	MaxContentLength int
+	MaxTags          int
+	MaxTagLength     int
+	MaxMetadataKeys  int
+
+	// MaxEvidenceLength bounds the evidence attached to feedback, which is
+	// stored with every feedback event.
+	MaxEvidenceLength int
+}
+
+// Default holds the limits shared by every service, and applies as is to
+// requests that span services, such as federated search and session
+// bootstrap. Queries are embedded and run through entity extraction, so they
+// are kept short.
+var Default = Limits{
+	MaxQueryLength:   2000,
+	MaxContentLength: 50000,
+	MaxTags:          20,
+	MaxTagLength:     100,
+	MaxMetadataKeys:  32,
+}
+
+// Per-service limits, as overrides of Default. Services that store no
+// content or metadata of their own keep only the limits they check.
+var (
+	Memory      = Default.with(Limits{MaxEvidenceLength: 2000})
+	Remediation = Memory
+
+	// Checkpoint content leaves room for full session state
+	Checkpoint = Default.with(Limits{MaxContentLength: 1 << 20})
+
+	Repository   = Limits{MaxQueryLength: Default.MaxQueryLength}
+	Conversation = Limits{
+		MaxQueryLength: Default.MaxQueryLength,
+		MaxTags:        Default.MaxTags,
+		MaxTagLength:   Default.MaxTagLength,
+	}
+)
+
+// with returns l with the non-zero fields of overrides applied.
+func (l Limits) with(overrides Limits) Limits {
+	for _, f := range []struct {
+		dst *int
+		src int
+	}{
+		{&l.MaxQueryLength, overrides.MaxQueryLength},
+		{&l.MaxContentLength, overrides.MaxContentLength},
+		{&l.MaxTags, overrides.MaxTags},
+		{&l.MaxTagLength, overrides.MaxTagLength},
+		{&l.MaxMetadataKeys, overrides.MaxMetadataKeys},
+		{&l.MaxEvidenceLength, overrides.MaxEvidenceLength},
+	} {
+		if f.src != 0 {
+			*f.dst = f.src
+		}
+	}
+	return l
+}
+
+// Query checks a search query held in field.
+func (l Limits) Query(field, query string) error {
+	return checkLength(CodeQueryTooLong, field, query, l.MaxQueryLength)
+}
+
+// Content checks a free-text field such as content, summary or solution.
+func (l Limits) Content(field, value string) error {
+	return checkLength(CodeContentTooLong, field, value, l.MaxContentLength)
+}
+
+// Evidence checks the evidence attached to feedback.
+func (l Limits) Evidence(value string) error {
+	return checkLength(CodeContentTooLong, "evidence", value, l.MaxEvidenceLength)
+}
+
+// Tags checks the number and length of tags.
+func (l Limits) Tags(tags []string) error {
+	if l.MaxTags > 0 && len(tags) > l.MaxTags {
+		return NewError(CodeTooManyTags, "tags", l.MaxTags, len(tags))
+	}
+	for i, tag := range tags {
+		if err := checkLength(CodeTagTooLong, fmt.Sprintf("tags[%d]", i), tag, l.MaxTagLength); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Metadata checks the number of keys in a metadata map.
+func (l Limits) Metadata(field string, keys int) error {
+	if l.MaxMetadataKeys > 0 && keys > l.MaxMetadataKeys {
+		return NewError(CodeTooManyMetadataKeys, field, l.MaxMetadataKeys, keys)
+	}
+	return nil
+}
+
+func checkLength(code Code, field, value string, limit int) error {
+	if limit <= 0 || len(value) <= limit {
+		return nil
+	}
+	// Rune counts never exceed byte lengths, so only count long values
+	if n := utf8.RuneCountInString(value); n > limit {
+		return NewError(code, field, limit, n)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	limits := Limits{MaxQueryLength: 5, MaxContentLength: 10, MaxTags: 2, MaxTagLength: 3, MaxMetadataKeys: 1, MaxEvidenceLength: 4}
+
+	tests := []struct {
+		name   string
+		err    error
+		code   Code
+		field  string
+		actual int
+	}{
+		{"query within limit", limits.Query("query", "hello"), "", "", 0},
+		{"query counts runes", limits.Query("query", "héllo"), "", "", 0},
+		{"query too long", limits.Query("query", "hello!"), CodeQueryTooLong, "query", 6},
+		{"content too long", limits.Content("summary", strings.Repeat("x", 11)), CodeContentTooLong, "summary", 11},
+		{"too many tags", limits.Tags([]string{"a", "b", "c"}), CodeTooManyTags, "tags", 3},
+		{"tag too long", limits.Tags([]string{"a", "long"}), CodeTagTooLong, "tags[1]", 4},
+		{"evidence too long", limits.Evidence("tried"), CodeContentTooLong, "evidence", 5},
+		{"too many metadata keys", limits.Metadata("metadata", 2), CodeTooManyMetadataKeys, "metadata", 2},
+		{"unlimited", Limits{}.Query("query", strings.Repeat("x", 100000)), "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code == "" {
+				assert.NoError(t, tt.err)
+				return
+			}
+			verr, ok := As(fmt.Errorf("wrapped: %w", tt.err))
+			require.True(t, ok)
+			assert.Equal(t, tt.code, verr.Code)
+			assert.Equal(t, tt.field, verr.Field)
+			assert.Equal(t, tt.actual, verr.Actual)
+			assert.True(t, errors.Is(tt.err, ErrValidation))
+		})
+	}
+}
+
+func TestServiceLimits(t *testing.T) {
+	assert.Equal(t, Limits{MaxQueryLength: 2000, MaxContentLength: 50000, MaxTags: 20, MaxTagLength: 100, MaxMetadataKeys: 32, MaxEvidenceLength: 2000}, Memory)
+	assert.Equal(t, Memory, Remediation)
+	assert.Equal(t, Limits{MaxQueryLength: 2000, MaxContentLength: 1 << 20, MaxTags: 20, MaxTagLength: 100, MaxMetadataKeys: 32}, Checkpoint)
+	assert.Equal(t, Limits{MaxQueryLength: 2000}, Repository)
+	assert.Equal(t, Limits{MaxQueryLength: 2000, MaxTags: 20, MaxTagLength: 100}, Conversation)
+	assert.Zero(t, Default.MaxEvidenceLength, "overrides must not leak into Default")
+}
+
+func TestError_Error(t *testing.T) {
+	err := NewError(CodeQueryTooLong, "query", 2000, 2500)
+	assert.Equal(t, "[ERR_VALIDATION_QUERY_TOO_LONG] query exceeds maximum length of 2000 characters (got 2500)", err.Error())
+
+	err = NewError(CodeTooManyTags, "tags", 20, 25)
+	assert.Equal(t, "[ERR_VALIDATION_TOO_MANY_TAGS] tags has 25 tags, maximum is 20", err.Error())
+}
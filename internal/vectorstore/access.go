@@ -0,0 +1,195 @@
+// Package vectorstore provides vector storage implementations.
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qdrant/go-client/qdrant"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// Access label metadata keys.
+const (
+	// VisibilityKey holds a document's Visibility label.
+	VisibilityKey = "visibility"
+
+	// OwnerKey holds the user that owns a labelled document. It defaults to
+	// the writer and is kept on rewrites, so a teammate updating a team
+	// document does not take it over.
+	OwnerKey = "owner_id"
+
+	// OwnerTeamKey holds the owner's team, which can read team documents.
+	OwnerTeamKey = "owner_team"
+)
+
+// Visibility is an access label that narrows who can read a document within
+// its tenant. Tenant isolation decides which documents a query can reach at
+// all; the label then decides which of those the requesting user sees.
+type Visibility string
+
+const (
+	// VisibilityPrivate documents are only visible to their owner.
+	VisibilityPrivate Visibility = "private"
+
+	// VisibilityTeam documents are visible to their owner and to users
+	// on the owner's team.
+	VisibilityTeam Visibility = "team"
+
+	// VisibilityOrg documents are visible to everyone in the tenant. This is
+	// the default, and documents without a label are treated as org.
+	VisibilityOrg Visibility = "org"
+)
+
+var (
+	// ErrInvalidVisibility is returned for an unknown access label.
+	ErrInvalidVisibility = ctxerrors.New(ctxerrors.CodeInvalidInput, "invalid visibility")
+
+	// ErrMissingUser is returned when a private or team document is written
+	// without a requesting user, or a team document without a team, so it
+	// would have no owner.
+	ErrMissingUser = ctxerrors.Unauthorized("private and team documents require a user in the request context")
+)
+
+// ParseVisibility parses an access label. The empty string is VisibilityOrg.
+func ParseVisibility(s string) (Visibility, error) {
+	switch v := Visibility(s); v {
+	case "":
+		return VisibilityOrg, nil
+	case VisibilityPrivate, VisibilityTeam, VisibilityOrg:
+		return v, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want private, team or org)", ErrInvalidVisibility, s)
+	}
+}
+
+// VisibilityOf returns the label recorded in document metadata, defaulting
+// to VisibilityOrg. Unknown labels are returned as is so CanAccess rejects
+// them.
+func VisibilityOf(metadata map[string]interface{}) Visibility {
+	v, _ := metadata[VisibilityKey].(string)
+	if v == "" {
+		return VisibilityOrg
+	}
+	return Visibility(v)
+}
+
+// requesterContextKey is the context key for the Requester.
+type requesterContextKey struct{}
+
+// Requester identifies the individual making a request within its tenant.
+// It is not part of the isolation scope: tenant isolation decides which
+// documents a request can reach, and the requester decides which labelled
+// documents among them it can read.
+type Requester struct {
+	// UserID identifies the developer. It owns the private and team
+	// documents they write.
+	UserID string
+
+	// TeamID is the developer's team, which can read their team documents.
+	TeamID string
+}
+
+// ContextWithRequester records the requester on ctx. A zero Requester
+// leaves ctx unchanged.
+func ContextWithRequester(ctx context.Context, r Requester) context.Context {
+	if r == (Requester{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, requesterContextKey{}, r)
+}
+
+// RequesterFromContext returns the requester, or a zero Requester if none
+// is set.
+func RequesterFromContext(ctx context.Context) Requester {
+	r, _ := ctx.Value(requesterContextKey{}).(Requester)
+	return r
+}
+
+// CanAccess reports whether the context's requester may read a document
+// with the given metadata. Without a requester only org documents are
+// visible. Unknown labels are never visible (fail closed).
+func CanAccess(ctx context.Context, metadata map[string]interface{}) bool {
+	v := VisibilityOf(metadata)
+	if v == VisibilityOrg {
+		return true
+	}
+	r := RequesterFromContext(ctx)
+	if r.UserID != "" && metadata[OwnerKey] == r.UserID {
+		return v == VisibilityPrivate || v == VisibilityTeam
+	}
+	return v == VisibilityTeam && r.TeamID != "" && metadata[OwnerTeamKey] == r.TeamID
+}
+
+// stampAccess validates the access labels on docs and records the owner of
+// labelled documents that do not have one yet.
+func stampAccess(ctx context.Context, docs []Document) error {
+	r := RequesterFromContext(ctx)
+	for i := range docs {
+		raw, _ := docs[i].Metadata[VisibilityKey].(string)
+		v, err := ParseVisibility(raw)
+		if err != nil {
+			return err
+		}
+		if v == VisibilityOrg {
+			continue
+		}
+		meta := docs[i].Metadata
+		if owner, _ := meta[OwnerKey].(string); owner == "" {
+			if r.UserID == "" {
+				return ErrMissingUser
+			}
+			meta[OwnerKey] = r.UserID
+			if r.TeamID != "" {
+				meta[OwnerTeamKey] = r.TeamID
+			}
+		}
+		if team, _ := meta[OwnerTeamKey].(string); v == VisibilityTeam && team == "" {
+			return fmt.Errorf("%w: team visibility needs the owner's team", ErrMissingUser)
+		}
+	}
+	return nil
+}
+
+// filterAccessible drops the results the context's requester may not read.
+func filterAccessible(ctx context.Context, results []SearchResult) []SearchResult {
+	kept := results[:0]
+	for _, r := range results {
+		if CanAccess(ctx, r.Metadata) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// qdrantAccessFilter adds the access label rules for the context's
+// requester to a Qdrant filter, matching CanAccess.
+func qdrantAccessFilter(ctx context.Context, filter *qdrant.Filter) *qdrant.Filter {
+	if filter == nil {
+		filter = &qdrant.Filter{}
+	}
+	should := []*qdrant.Condition{
+		qdrant.NewIsEmpty(VisibilityKey),
+		qdrant.NewMatchKeyword(VisibilityKey, string(VisibilityOrg)),
+	}
+	r := RequesterFromContext(ctx)
+	if r.UserID != "" {
+		should = append(should, qdrant.NewFilterAsCondition(&qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewMatchKeyword(OwnerKey, r.UserID),
+				qdrant.NewMatchKeywords(VisibilityKey, string(VisibilityPrivate), string(VisibilityTeam)),
+			},
+		}))
+	}
+	if r.TeamID != "" {
+		should = append(should, qdrant.NewFilterAsCondition(&qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewMatchKeyword(VisibilityKey, string(VisibilityTeam)),
+				qdrant.NewMatchKeyword(OwnerTeamKey, r.TeamID),
+			},
+		}))
+	}
+	filter.Should = append(filter.Should, should...)
+	return filter
+}
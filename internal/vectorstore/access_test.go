@@ -0,0 +1,138 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseVisibility(t *testing.T) {
+	v, err := ParseVisibility("")
+	require.NoError(t, err)
+	assert.Equal(t, VisibilityOrg, v)
+
+	for _, s := range []string{"private", "team", "org"} {
+		v, err := ParseVisibility(s)
+		require.NoError(t, err)
+		assert.Equal(t, Visibility(s), v)
+	}
+
+	_, err = ParseVisibility("public")
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+}
+
+func TestCanAccess(t *testing.T) {
+	ctx := context.Background()
+	callers := map[string]context.Context{
+		"alice":     ContextWithRequester(ctx, Requester{UserID: "alice", TeamID: "core"}),
+		"bob":       ContextWithRequester(ctx, Requester{UserID: "bob", TeamID: "core"}),
+		"carol":     ContextWithRequester(ctx, Requester{UserID: "carol", TeamID: "infra"}),
+		"anonymous": ctx,
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		want     map[string]bool
+	}{
+		{"unlabelled", map[string]interface{}{}, map[string]bool{"alice": true, "bob": true, "carol": true, "anonymous": true}},
+		{"org", map[string]interface{}{VisibilityKey: "org", OwnerKey: "alice"}, map[string]bool{"alice": true, "bob": true, "carol": true, "anonymous": true}},
+		{"private", map[string]interface{}{VisibilityKey: "private", OwnerKey: "alice", OwnerTeamKey: "core"}, map[string]bool{"alice": true}},
+		{"team", map[string]interface{}{VisibilityKey: "team", OwnerKey: "alice", OwnerTeamKey: "core"}, map[string]bool{"alice": true, "bob": true}},
+		{"private without owner", map[string]interface{}{VisibilityKey: "private"}, map[string]bool{}},
+		{"unknown label", map[string]interface{}{VisibilityKey: "secret", OwnerKey: "alice"}, map[string]bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, ctx := range callers {
+				assert.Equal(t, tt.want[name], CanAccess(ctx, tt.metadata), name)
+			}
+		})
+	}
+}
+
+func TestStampAccess(t *testing.T) {
+	ctx := ContextWithRequester(context.Background(), Requester{UserID: "alice", TeamID: "core"})
+
+	docs := []Document{
+		{ID: "a", Metadata: map[string]interface{}{VisibilityKey: "team"}},
+		{ID: "b", Metadata: map[string]interface{}{VisibilityKey: "private", OwnerKey: "bob"}},
+		{ID: "c", Metadata: map[string]interface{}{}},
+	}
+	require.NoError(t, stampAccess(ctx, docs))
+	assert.Equal(t, "alice", docs[0].Metadata[OwnerKey])
+	assert.Equal(t, "core", docs[0].Metadata[OwnerTeamKey])
+	assert.Equal(t, "bob", docs[1].Metadata[OwnerKey], "rewrites keep the owner")
+	assert.NotContains(t, docs[2].Metadata, VisibilityKey)
+
+	err := stampAccess(ctx, []Document{{Metadata: map[string]interface{}{VisibilityKey: "everyone"}}})
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+
+	err = stampAccess(context.Background(), []Document{{Metadata: map[string]interface{}{VisibilityKey: "private"}}})
+	assert.ErrorIs(t, err, ErrMissingUser)
+
+	solo := ContextWithRequester(context.Background(), Requester{UserID: "alice"})
+	err = stampAccess(solo, []Document{{Metadata: map[string]interface{}{VisibilityKey: "team"}}})
+	assert.ErrorIs(t, err, ErrMissingUser, "team documents need a team")
+}
+
+func TestChromemStore_AccessLabels(t *testing.T) {
+	store, err := NewChromemStore(ChromemConfig{Path: t.TempDir(), DefaultCollection: "labelled", VectorSize: 16}, &driftTestEmbedder{dim: 16}, zap.NewNop())
+	require.NoError(t, err)
+	defer store.Close()
+
+	tenant := ContextWithTenant(context.Background(), &TenantInfo{TenantID: "acme", ProjectID: "app"})
+	alice := ContextWithRequester(tenant, Requester{UserID: "alice"})
+	bob := ContextWithRequester(tenant, Requester{UserID: "bob"})
+
+	// Alice's private documents outnumber the shared one, so a small k has
+	// to look past them for bob
+	docs := make([]Document, 0, 21)
+	for i := 0; i < 20; i++ {
+		docs = append(docs, Document{ID: fmt.Sprintf("private-%d", i), Content: "experiment", Metadata: map[string]interface{}{VisibilityKey: "private"}})
+	}
+	docs = append(docs, Document{ID: "shared", Content: "experiment", Metadata: map[string]interface{}{}})
+	_, err = store.AddDocuments(alice, docs)
+	require.NoError(t, err)
+
+	results, err := store.SearchInCollection(bob, "labelled", "experiment", 1, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "shared", results[0].ID)
+
+	results, err = store.SearchInCollection(alice, "labelled", "experiment", 5, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 5)
+
+	var scrolled []string
+	require.NoError(t, store.ScrollCollection(bob, "labelled", 8, nil, func(batch []SearchResult) error {
+		for _, r := range batch {
+			scrolled = append(scrolled, r.ID)
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"shared"}, scrolled)
+
+	_, err = store.AddDocuments(tenant, []Document{{ID: "orphan", Content: "x", Metadata: map[string]interface{}{VisibilityKey: "private"}}})
+	assert.ErrorIs(t, err, ErrMissingUser)
+}
+
+func TestQdrantAccessFilter(t *testing.T) {
+	ctx := ContextWithRequester(context.Background(), Requester{UserID: "alice", TeamID: "core"})
+
+	filter := qdrantAccessFilter(ctx, buildQdrantFilter(map[string]interface{}{"tenant_id": "acme"}))
+	assert.Len(t, filter.Must, 1, "existing conditions are kept")
+	assert.Len(t, filter.Should, 4, "unlabelled, org, owned and team")
+
+	anonymous := qdrantAccessFilter(context.Background(), nil)
+	assert.Empty(t, anonymous.Must)
+	assert.Equal(t, []*qdrant.Condition{
+		qdrant.NewIsEmpty(VisibilityKey),
+		qdrant.NewMatchKeyword(VisibilityKey, "org"),
+	}, anonymous.Should)
+}
@@ -4,8 +4,10 @@ package vectorstore
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 )
 
@@ -46,6 +49,23 @@ type ChromemConfig struct {
 	// Default: PayloadIsolation for fail-closed security.
 	// Set at construction time; immutable afterward to prevent race conditions.
 	Isolation IsolationMode
+
+	// LazyLoad defers loading persisted collections until first access.
+	// Startup only reads collection metadata, which keeps cold starts fast
+	// when the store holds many projects.
+	LazyLoad bool
+
+	// Warmup lists collections to load in the background right after a
+	// lazy startup, so hot collections don't pay the load on first request.
+	// Ignored unless LazyLoad is set.
+	Warmup []string
+
+	// MmapVectors packs loaded embeddings into anonymous memory maps outside
+	// the Go heap, which shrinks the heap and GC work for large collections.
+	// The memory is released on Close, once in-flight operations finish;
+	// later operations fail with ErrStoreClosed. Falls back to heap blocks
+	// where mmap is unavailable.
+	MmapVectors bool
 }
 
 // ApplyDefaults sets default values for unset fields.
@@ -72,7 +92,8 @@ func (c *ChromemConfig) Validate() error {
 // ChromemStore implements the Store interface using chromem-go.
 //
 // chromem-go is an embeddable vector database with zero third-party dependencies.
-// It provides in-memory storage with optional persistence to gob files.
+// It provides in-memory storage; the store persists it to gob files through
+// chromemPersister, which writes atomically and checksums every file.
 //
 // Key features:
 //   - Pure Go, no CGO required
@@ -87,9 +108,21 @@ type ChromemStore struct {
 	logger    *zap.Logger
 	isolation IsolationMode
 	metrics   *Metrics
+	persister *chromemPersister
+
+	// collectionGauge reports per-collection document counts until Close
+	collectionGauge metric.Registration
+
+	// lazy holds collections not loaded yet; nil unless LazyLoad is set
+	lazy *lazyCollections
 
 	// collections tracks which collections have been created
 	collections sync.Map
+
+	// closeMu is read-locked by every operation and locked by Close, so the
+	// vector arena is not unmapped under a query
+	closeMu sync.RWMutex
+	closed  bool
 }
 
 // NewChromemStore creates a new ChromemStore with the given configuration.
@@ -119,12 +152,6 @@ func NewChromemStore(config ChromemConfig, embedder Embedder, logger *zap.Logger
 		return nil, fmt.Errorf("creating directory %s: %w", expandedPath, err)
 	}
 
-	// Create persistent DB with graceful degradation for corrupt collections
-	db, err := NewResilientChromemDB(expandedPath, config.Compress, logger)
-	if err != nil {
-		return nil, fmt.Errorf("creating chromem DB: %w", err)
-	}
-
 	// Use isolation from config, defaulting to PayloadIsolation for fail-closed security
 	isolation := config.Isolation
 	if isolation == nil {
@@ -132,12 +159,38 @@ func NewChromemStore(config ChromemConfig, embedder Embedder, logger *zap.Logger
 	}
 
 	store := &ChromemStore{
-		db:        db,
+		db:        chromem.NewDB(),
 		embedder:  embedder,
 		config:    config,
 		logger:    logger,
 		isolation: isolation,
 		metrics:   NewMetrics(logger),
+		persister: newChromemPersister(expandedPath, config.Compress, logger),
+	}
+	if config.MmapVectors {
+		store.persister.arena = newVectorArena(true, logger)
+	}
+
+	// Load persisted collections, moving corrupt files aside instead of failing.
+	// With LazyLoad only metadata is read now; documents load on first access.
+	var report *chromemLoadReport
+	if config.LazyLoad {
+		var found map[string]string
+		found, report, err = store.persister.scan()
+		if err != nil {
+			return nil, fmt.Errorf("creating chromem DB: %w", err)
+		}
+		store.lazy = newLazyCollections(found)
+	} else {
+		report, err = store.persister.load(context.Background(), store.db, store.createEmbeddingFunc())
+		if err != nil {
+			return nil, fmt.Errorf("creating chromem DB: %w", err)
+		}
+	}
+
+	store.collectionGauge, err = persistIO.observeCollections(store, expandedPath)
+	if err != nil {
+		logger.Warn("failed to observe collection document counts", zap.Error(err))
 	}
 
 	logger.Info("ChromemStore initialized",
@@ -145,8 +198,22 @@ func NewChromemStore(config ChromemConfig, embedder Embedder, logger *zap.Logger
 		zap.Bool("compress", config.Compress),
 		zap.Int("vector_size", config.VectorSize),
 		zap.String("default_collection", config.DefaultCollection),
+		zap.Int("collections", report.Collections),
+		zap.Int("documents", report.Documents),
+		zap.Int("recovered_documents", len(report.Recovered)),
+		zap.Int("quarantined_collections", len(report.Quarantined)),
+		zap.Strings("upgraded_collections", report.Upgraded),
+		zap.Bool("lazy_load", config.LazyLoad),
+		zap.Bool("mmap_vectors", config.MmapVectors),
 	)
 
+	if store.lazy != nil {
+		logger.Info("deferring collection loads until first access",
+			zap.Int("pending_collections", len(store.lazy.names())),
+			zap.Strings("warmup", config.Warmup))
+		go store.warmup(config.Warmup)
+	}
+
 	return store, nil
 }
 
@@ -174,6 +241,19 @@ func (s *ChromemStore) IsolationMode() IsolationMode {
 	return s.isolation
 }
 
+// use read-locks the store against Close for the duration of an operation.
+// It fails once the store is closed; otherwise call done when finished.
+// Operations must not call each other while holding it: a pending Close
+// would deadlock the nested read lock.
+func (s *ChromemStore) use() (done func(), err error) {
+	s.closeMu.RLock()
+	if s.closed {
+		s.closeMu.RUnlock()
+		return nil, ErrStoreClosed
+	}
+	return s.closeMu.RUnlock, nil
+}
+
 // createEmbeddingFunc creates a chromem.EmbeddingFunc from our Embedder interface.
 func (s *ChromemStore) createEmbeddingFunc() chromem.EmbeddingFunc {
 	return func(ctx context.Context, text string) ([]float32, error) {
@@ -188,11 +268,19 @@ func (s *ChromemStore) getOrCreateCollection(ctx context.Context, name string) (
 		return nil, err
 	}
 
+	if err := s.ensureLoaded(ctx, name); err != nil {
+		return nil, err
+	}
+
 	collection, err := s.db.GetOrCreateCollection(name, nil, s.createEmbeddingFunc())
 	if err != nil {
 		return nil, fmt.Errorf("getting/creating collection %s: %w", name, err)
 	}
 
+	if err := s.persister.saveCollection(name, nil); err != nil {
+		return nil, fmt.Errorf("persisting collection %s: %w", name, err)
+	}
+
 	s.collections.Store(name, true)
 	return collection, nil
 }
@@ -210,6 +298,12 @@ func (s *ChromemStore) AddDocuments(ctx context.Context, docs []Document) ([]str
 		return nil, ErrEmptyDocuments
 	}
 
+	done, err := s.use()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	// Inject tenant metadata if isolation mode requires it
 	if s.isolation != nil {
 		if err := s.isolation.InjectMetadata(ctx, docs); err != nil {
@@ -218,6 +312,11 @@ func (s *ChromemStore) AddDocuments(ctx context.Context, docs []Document) ([]str
 			return nil, fmt.Errorf("injecting tenant metadata: %w", err)
 		}
 	}
+	if err := stampAccess(ctx, docs); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
 	// Determine collection name from first document
 	collectionName := s.config.DefaultCollection
@@ -283,6 +382,13 @@ func (s *ChromemStore) AddDocuments(ctx context.Context, docs []Document) ([]str
 		return nil, fmt.Errorf("adding documents: %w", err)
 	}
 
+	if err := s.persister.saveDocuments(collectionName, chromemDocs); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.metrics.RecordOperation(ctx, "add_documents", collectionName, time.Since(start), err)
+		return nil, fmt.Errorf("persisting documents: %w", err)
+	}
+
 	span.SetAttributes(attribute.Int("documents_added", len(ids)))
 	span.SetStatus(codes.Ok, "success")
 
@@ -331,6 +437,16 @@ func (s *ChromemStore) SearchInCollection(ctx context.Context, collectionName st
 	if query == "" {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
+	const maxQueryLength = 10000 // characters, matching QdrantStore
+	if len(query) > maxQueryLength {
+		return nil, fmt.Errorf("query exceeds maximum length of %d characters", maxQueryLength)
+	}
+
+	done, err := s.use()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 
 	// Inject tenant filters if isolation mode requires it
 	if s.isolation != nil {
@@ -343,6 +459,10 @@ func (s *ChromemStore) SearchInCollection(ctx context.Context, collectionName st
 		}
 	}
 
+	if err := s.ensureLoaded(ctx, collectionName); err != nil {
+		return nil, err
+	}
+
 	collection := s.db.GetCollection(collectionName, s.createEmbeddingFunc())
 	if collection == nil {
 		span.SetStatus(codes.Error, "collection not found")
@@ -361,23 +481,34 @@ func (s *ChromemStore) SearchInCollection(ctx context.Context, collectionName st
 	// Convert filters to string map
 	whereFilter := convertMetadataToString(filters)
 
-	// Query collection
-	results, err := collection.Query(ctx, query, k, whereFilter, nil)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("querying collection %s: %w", collectionName, err)
-	}
+	// Query collection. chromem filters by equality only, so access labels
+	// are applied to the results; widen the query until k accessible
+	// results are found or the collection is exhausted.
+	var searchResults []SearchResult
+	for n := k; ; n = min(2*n, docCount) {
+		results, err := collection.Query(ctx, query, n, whereFilter, nil)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("querying collection %s: %w", collectionName, err)
+		}
 
-	// Convert results
-	searchResults := make([]SearchResult, len(results))
-	for i, r := range results {
-		searchResults[i] = SearchResult{
-			ID:       r.ID,
-			Content:  r.Content,
-			Score:    r.Similarity,
-			Metadata: convertMetadataFromString(r.Metadata),
+		searchResults = make([]SearchResult, len(results))
+		for i, r := range results {
+			searchResults[i] = SearchResult{
+				ID:       r.ID,
+				Content:  r.Content,
+				Score:    r.Similarity,
+				Metadata: convertMetadataFromString(r.Metadata),
+			}
 		}
+		searchResults = filterAccessible(ctx, searchResults)
+		if len(searchResults) >= k || len(results) < n || n == docCount {
+			break
+		}
+	}
+	if len(searchResults) > k {
+		searchResults = searchResults[:k]
 	}
 
 	span.SetAttributes(attribute.Int("results_count", len(searchResults)))
@@ -395,12 +526,242 @@ func (s *ChromemStore) SearchInCollection(ctx context.Context, collectionName st
 	return searchResults, nil
 }
 
+// ScrollCollection iterates every document in a collection in batches.
+//
+// chromem-go has no cursor API, so the collection is read with a single
+// exhaustive query and handed to fn in batchSize slices. The documents are
+// already resident in memory; batching bounds what callers accumulate.
+func (s *ChromemStore) ScrollCollection(ctx context.Context, collectionName string, batchSize int, filters map[string]interface{}, fn func([]SearchResult) error) error {
+	ctx, span := chromemTracer.Start(ctx, "ChromemStore.ScrollCollection")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("batch_size", batchSize),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	if s.isolation != nil {
+		var err error
+		filters, err = s.isolation.InjectFilter(ctx, filters)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("injecting tenant filter: %w", err)
+		}
+	}
+
+	// fn may call back into the store, so the query runs under the close
+	// lock but the batches are handed out after it is released
+	results, err := s.queryAll(ctx, collectionName, filters)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	for start := 0; start < len(results); start += batchSize {
+		end := start + batchSize
+		if end > len(results) {
+			end = len(results)
+		}
+
+		batch := make([]SearchResult, 0, end-start)
+		for _, r := range results[start:end] {
+			batch = append(batch, SearchResult{
+				ID:       r.ID,
+				Content:  r.Content,
+				Metadata: convertMetadataFromString(r.Metadata),
+			})
+		}
+		if err := fn(filterAccessible(ctx, batch)); err != nil {
+			return err
+		}
+	}
+
+	span.SetAttributes(attribute.Int("documents", len(results)))
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// queryAll returns every document of a collection matching filters.
+func (s *ChromemStore) queryAll(ctx context.Context, collectionName string, filters map[string]interface{}) ([]chromem.Result, error) {
+	done, err := s.use()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if err := s.ensureLoaded(ctx, collectionName); err != nil {
+		return nil, err
+	}
+
+	collection := s.db.GetCollection(collectionName, s.createEmbeddingFunc())
+	if collection == nil {
+		return nil, ErrCollectionNotFound
+	}
+
+	docCount := collection.Count()
+	if docCount == 0 {
+		return nil, nil
+	}
+
+	// Any non-empty query works; ranking is irrelevant when every document
+	// is returned.
+	results, err := collection.Query(ctx, "document", docCount, convertMetadataToString(filters), nil)
+	if err != nil {
+		return nil, fmt.Errorf("scrolling collection %s: %w", collectionName, err)
+	}
+	return results, nil
+}
+
+// SampleVectors returns up to n documents of a collection with their stored
+// embeddings.
+//
+// Documents are read with a query for a random vector rather than a text
+// query, so sampling does not call the embedder and keeps working when the
+// embedder no longer matches the stored vectors. Only the n documents
+// nearest the probe are returned, and the probe differs between calls.
+func (s *ChromemStore) SampleVectors(ctx context.Context, collectionName string, n int) ([]StoredVector, error) {
+	ctx, span := chromemTracer.Start(ctx, "ChromemStore.SampleVectors")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("n", n),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("sample size must be positive, got %d", n)
+	}
+
+	done, err := s.use()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if err := s.ensureLoaded(ctx, collectionName); err != nil {
+		return nil, err
+	}
+
+	collection := s.db.GetCollection(collectionName, s.createEmbeddingFunc())
+	if collection == nil {
+		span.SetStatus(codes.Error, "collection not found")
+		return nil, ErrCollectionNotFound
+	}
+
+	docCount := collection.Count()
+	if docCount == 0 {
+		return nil, nil
+	}
+
+	probe := make([]float32, s.config.VectorSize)
+	for i := range probe {
+		probe[i] = float32(rand.NormFloat64())
+	}
+	results, err := collection.QueryEmbedding(ctx, probe, min(n, docCount), nil, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("sampling collection %s: %w", collectionName, err)
+	}
+
+	samples := make([]StoredVector, 0, len(results))
+	for _, r := range results {
+		// Copy the vector out of the collection, which may keep it in the
+		// vector arena that Close unmaps
+		samples = append(samples, StoredVector{ID: r.ID, Content: r.Content, Vector: slices.Clone(r.Embedding)})
+	}
+
+	span.SetAttributes(attribute.Int("sampled", len(samples)))
+	span.SetStatus(codes.Ok, "success")
+	return samples, nil
+}
+
+// ScanDocuments reads back every document of a collection from its files
+// on disk, so documents whose files no longer decode are reported even
+// though their in-memory copy still serves searches. Reading files rather
+// than querying also keeps working when stored vectors differ in dimension,
+// which makes chromem queries fail.
+func (s *ChromemStore) ScanDocuments(ctx context.Context, collectionName string, batchSize int, fn func([]ScannedDocument) error) error {
+	ctx, span := chromemTracer.Start(ctx, "ChromemStore.ScanDocuments")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("batch_size", batchSize),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	// Documents are read from disk, and fn may call back into the store,
+	// so the close lock is only held to load the collection
+	done, err := s.use()
+	if err != nil {
+		return err
+	}
+	err = s.ensureLoaded(ctx, collectionName)
+	if err == nil && s.db.GetCollection(collectionName, s.createEmbeddingFunc()) == nil {
+		span.SetStatus(codes.Error, "collection not found")
+		err = ErrCollectionNotFound
+	}
+	done()
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	batch := make([]ScannedDocument, 0, batchSize)
+	err = s.persister.readCollection(collectionName, func(doc ScannedDocument) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch = append(batch, doc)
+		total++
+		if len(batch) < batchSize {
+			return nil
+		}
+		err := fn(batch)
+		batch = make([]ScannedDocument, 0, batchSize)
+		return err
+	})
+	if err == nil && len(batch) > 0 {
+		err = fn(batch)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("documents", total))
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
 // DeleteDocuments deletes documents by their IDs from the default collection.
 func (s *ChromemStore) DeleteDocuments(ctx context.Context, ids []string) error {
 	return s.DeleteDocumentsFromCollection(ctx, s.config.DefaultCollection, ids)
 }
 
-// DeleteDocumentsFromCollection deletes documents by their IDs from a specific collection.
+// DeleteDocumentsFromCollection deletes documents by their IDs from a
+// specific collection. If isolation mode is set, documents of other tenants
+// are left in place, and a missing tenant is an error.
 func (s *ChromemStore) DeleteDocumentsFromCollection(ctx context.Context, collectionName string, ids []string) error {
 	start := time.Now()
 	ctx, span := chromemTracer.Start(ctx, "ChromemStore.DeleteDocumentsFromCollection")
@@ -419,6 +780,23 @@ func (s *ChromemStore) DeleteDocumentsFromCollection(ctx context.Context, collec
 		return err
 	}
 
+	tenantID, scoped, err := deleteScope(ctx, s.isolation)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	done, err := s.use()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if err := s.ensureLoaded(ctx, collectionName); err != nil {
+		return err
+	}
+
 	collection := s.db.GetCollection(collectionName, s.createEmbeddingFunc())
 	if collection == nil {
 		span.SetStatus(codes.Error, "collection not found")
@@ -428,7 +806,18 @@ func (s *ChromemStore) DeleteDocumentsFromCollection(ctx context.Context, collec
 	// Delete each document, collecting failures
 	var failures []string
 	for _, id := range ids {
-		if err := collection.Delete(ctx, nil, nil, id); err != nil {
+		if scoped {
+			// Unknown IDs and other tenants' documents are skipped alike
+			doc, err := collection.GetByID(ctx, id)
+			if err != nil || doc.Metadata["tenant_id"] != tenantID {
+				continue
+			}
+		}
+		err := collection.Delete(ctx, nil, nil, id)
+		if err == nil {
+			err = s.persister.deleteDocument(collectionName, id)
+		}
+		if err != nil {
 			span.RecordError(err)
 			s.logger.Error("failed to delete document",
 				zap.String("collection", collectionName),
@@ -483,6 +872,16 @@ func (s *ChromemStore) CreateCollection(ctx context.Context, collectionName stri
 		return fmt.Errorf("vector size %d does not match configured size %d", vectorSize, s.config.VectorSize)
 	}
 
+	done, err := s.use()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if err := s.ensureLoaded(ctx, collectionName); err != nil {
+		return err
+	}
+
 	// Check if collection already exists (chromem-go's CreateCollection is idempotent)
 	// IMPORTANT: Must pass embedding function, not nil, because chromem-go sets
 	// the default OpenAI embedder when nil is passed for persisted collections
@@ -490,7 +889,7 @@ func (s *ChromemStore) CreateCollection(ctx context.Context, collectionName stri
 		return ErrCollectionExists
 	}
 
-	_, err := s.db.CreateCollection(collectionName, nil, s.createEmbeddingFunc())
+	_, err = s.db.CreateCollection(collectionName, nil, s.createEmbeddingFunc())
 	if err != nil {
 		// Double-check in case of race condition
 		if strings.Contains(err.Error(), "already exists") {
@@ -501,6 +900,12 @@ func (s *ChromemStore) CreateCollection(ctx context.Context, collectionName stri
 		return fmt.Errorf("creating collection %s: %w", collectionName, err)
 	}
 
+	if err := s.persister.saveCollection(collectionName, nil); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("persisting collection %s: %w", collectionName, err)
+	}
+
 	s.collections.Store(collectionName, true)
 	span.SetStatus(codes.Ok, "success")
 
@@ -523,12 +928,29 @@ func (s *ChromemStore) DeleteCollection(ctx context.Context, collectionName stri
 		return err
 	}
 
+	done, err := s.use()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	// A collection that was never loaded only needs its files removed
+	if s.lazy != nil {
+		s.lazy.forget(collectionName)
+	}
+
 	if err := s.db.DeleteCollection(collectionName); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("deleting collection %s: %w", collectionName, err)
 	}
 
+	if err := s.persister.deleteCollection(collectionName); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("deleting collection %s from disk: %w", collectionName, err)
+	}
+
 	s.collections.Delete(collectionName)
 	span.SetStatus(codes.Ok, "success")
 
@@ -550,9 +972,15 @@ func (s *ChromemStore) CollectionExists(ctx context.Context, collectionName stri
 		return false, err
 	}
 
+	done, err := s.use()
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
 	// Must pass embedding function to avoid chromem-go setting OpenAI default
 	collection := s.db.GetCollection(collectionName, s.createEmbeddingFunc())
-	exists := collection != nil
+	exists := collection != nil || (s.lazy != nil && s.lazy.has(collectionName))
 
 	span.SetStatus(codes.Ok, "success")
 	return exists, nil
@@ -563,11 +991,25 @@ func (s *ChromemStore) ListCollections(ctx context.Context) ([]string, error) {
 	_, span := chromemTracer.Start(ctx, "ChromemStore.ListCollections")
 	defer span.End()
 
+	done, err := s.use()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
 	collectionsMap := s.db.ListCollections()
 	names := make([]string, 0, len(collectionsMap))
 	for name := range collectionsMap {
 		names = append(names, name)
 	}
+	if s.lazy != nil {
+		// Pending collections exist on disk even though they aren't loaded yet
+		for _, name := range s.lazy.names() {
+			if _, loaded := collectionsMap[name]; !loaded {
+				names = append(names, name)
+			}
+		}
+	}
 
 	span.SetAttributes(attribute.Int("collection_count", len(names)))
 	span.SetStatus(codes.Ok, "success")
@@ -586,6 +1028,16 @@ func (s *ChromemStore) GetCollectionInfo(ctx context.Context, collectionName str
 		return nil, err
 	}
 
+	done, err := s.use()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if err := s.ensureLoaded(ctx, collectionName); err != nil {
+		return nil, err
+	}
+
 	// Must pass embedding function to avoid chromem-go setting OpenAI default
 	collection := s.db.GetCollection(collectionName, s.createEmbeddingFunc())
 	if collection == nil {
@@ -613,9 +1065,28 @@ func (s *ChromemStore) ExactSearch(ctx context.Context, collectionName string, q
 	return s.SearchInCollection(ctx, collectionName, query, k, nil)
 }
 
-// Close closes the ChromemStore.
-// Note: chromem-go handles persistence automatically, no explicit close needed.
+// Close closes the ChromemStore. It waits for in-flight operations, and
+// operations after it fail with ErrStoreClosed.
+// Note: every write is persisted before it returns, so there is nothing to flush.
 func (s *ChromemStore) Close() error {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.collectionGauge != nil {
+		if err := s.collectionGauge.Unregister(); err != nil {
+			s.logger.Warn("failed to unregister collection document gauge", zap.Error(err))
+		}
+		s.collectionGauge = nil
+	}
+	if arena := s.persister.arena; arena != nil {
+		if err := arena.release(); err != nil {
+			s.logger.Warn("failed to release vector arena", zap.Error(err))
+		}
+	}
 	s.logger.Info("chromem store closed")
 	return nil
 }
@@ -659,5 +1130,11 @@ func convertMetadataFromString(metadata map[string]string) map[string]interface{
 	return result
 }
 
-// Ensure ChromemStore implements Store interface.
-var _ Store = (*ChromemStore)(nil)
+// Ensure ChromemStore implements Store, Scroller, VectorSampler and
+// DocumentScanner interfaces.
+var (
+	_ Store           = (*ChromemStore)(nil)
+	_ Scroller        = (*ChromemStore)(nil)
+	_ VectorSampler   = (*ChromemStore)(nil)
+	_ DocumentScanner = (*ChromemStore)(nil)
+)
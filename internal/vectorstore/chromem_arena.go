@@ -0,0 +1,113 @@
+package vectorstore
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// vectorArenaBlock is the number of floats per arena block (4 MiB).
+const vectorArenaBlock = 1 << 20
+
+// errMmapUnsupported is returned by mapVectorBlock on platforms without
+// anonymous mappings; the arena falls back to heap blocks.
+var errMmapUnsupported = errors.New("anonymous mmap not supported on this platform")
+
+// vectorArena packs loaded embeddings into large contiguous blocks instead
+// of one small allocation per document. With mmap the blocks live outside
+// the Go heap, so large collections neither count towards the GC target
+// nor get scanned by it.
+//
+// Memory is only returned by release, when the store closes; vectors of
+// deleted or overwritten documents stay in their block until then.
+type vectorArena struct {
+	mu     sync.Mutex
+	mmap   bool
+	logger *zap.Logger
+
+	cur      []float32 // unused tail of the current block
+	unmap    []func() error
+	reserved int // floats in all blocks
+}
+
+func newVectorArena(mmap bool, logger *zap.Logger) *vectorArena {
+	return &vectorArena{mmap: mmap, logger: logger}
+}
+
+// store copies v into the arena, normalized the way chromem-go expects so
+// the collection keeps the arena copy instead of allocating its own.
+func (a *vectorArena) store(v []float32) []float32 {
+	if len(v) == 0 {
+		return v
+	}
+
+	a.mu.Lock()
+	if len(a.cur) < len(v) {
+		a.grow(len(v))
+	}
+	// Full slice expression so an append never spills into the next vector
+	out := a.cur[:len(v):len(v)]
+	a.cur = a.cur[len(v):]
+	a.mu.Unlock()
+
+	var norm float32
+	for _, x := range v {
+		norm += x * x
+	}
+	norm = float32(math.Sqrt(float64(norm)))
+	if norm == 0 {
+		copy(out, v)
+		return out
+	}
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// grow starts a new block holding at least n floats. Callers hold mu.
+func (a *vectorArena) grow(n int) {
+	size := vectorArenaBlock
+	if n > size {
+		size = n
+	}
+	if a.mmap {
+		block, unmap, err := mapVectorBlock(size)
+		if err == nil {
+			a.cur = block
+			a.unmap = append(a.unmap, unmap)
+			a.reserved += size
+			return
+		}
+		a.logger.Warn("falling back to heap vector arena", zap.Error(err))
+		a.mmap = false
+	}
+	a.cur = make([]float32, size)
+	a.reserved += size
+}
+
+// bytes reports the memory reserved by the arena.
+func (a *vectorArena) bytes() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int64(a.reserved) * 4
+}
+
+// release unmaps every block. Vectors handed out must not be used
+// afterwards; ChromemStore.Close holds its close lock so no operation is
+// reading them, and vectors returned to callers are copies.
+func (a *vectorArena) release() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []error
+	for _, unmap := range a.unmap {
+		errs = append(errs, unmap())
+	}
+	a.unmap = nil
+	a.cur = nil
+	a.reserved = 0
+	return errors.Join(errs...)
+}
@@ -0,0 +1,283 @@
+package vectorstore
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	chromem "github.com/philippgille/chromem-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestVectorArena_Store(t *testing.T) {
+	for _, mmap := range []bool{false, true} {
+		t.Run(fmt.Sprintf("mmap=%v", mmap), func(t *testing.T) {
+			arena := newVectorArena(mmap, zap.NewNop())
+			defer arena.release()
+
+			a := arena.store([]float32{3, 4})
+			b := arena.store([]float32{0, 0, 2})
+			assert.InDeltaSlice(t, []float32{0.6, 0.8}, a, 1e-6)
+			assert.Equal(t, []float32{0, 0, 1}, b)
+			assert.Equal(t, 2, cap(a), "appending must not overwrite the next vector")
+			assert.Equal(t, []float32{0, 0}, arena.store([]float32{0, 0}), "zero vectors are copied as is")
+
+			big := arena.store(make([]float32, vectorArenaBlock+1))
+			assert.Len(t, big, vectorArenaBlock+1)
+			assert.Equal(t, int64(2*vectorArenaBlock+1)*4, arena.bytes())
+			require.NoError(t, arena.release())
+			assert.Zero(t, arena.bytes())
+		})
+	}
+}
+
+func TestChromemStore_StreamingLoad(t *testing.T) {
+	path := t.TempDir()
+	persister := newChromemPersister(path, false, zap.NewNop())
+	require.NoError(t, persister.saveCollection("streamed", nil))
+
+	// More than one batch, with unnormalized vectors
+	docs := make([]chromem.Document, chromemLoadBatch+3)
+	for i := range docs {
+		docs[i] = chromem.Document{ID: fmt.Sprintf("doc-%d", i), Content: "content", Embedding: []float32{float32(i + 1), 1, 0, 0}}
+	}
+	require.NoError(t, persister.saveDocuments("streamed", docs))
+
+	for _, mmap := range []bool{false, true} {
+		t.Run(fmt.Sprintf("mmap=%v", mmap), func(t *testing.T) {
+			config := ChromemConfig{Path: path, VectorSize: 4, Isolation: NewNoIsolation(), MmapVectors: mmap}
+			store, err := NewChromemStore(config, &driftTestEmbedder{dim: 4}, zap.NewNop())
+			require.NoError(t, err)
+			defer store.Close()
+
+			info, err := store.GetCollectionInfo(context.Background(), "streamed")
+			require.NoError(t, err)
+			assert.Equal(t, len(docs), info.PointCount)
+
+			collection := store.db.GetCollection("streamed", store.createEmbeddingFunc())
+			doc, err := collection.GetByID(context.Background(), "doc-2")
+			require.NoError(t, err)
+			norm := float32(math.Sqrt(10))
+			assert.InDeltaSlice(t, []float32{3 / norm, 1 / norm, 0, 0}, doc.Embedding, 1e-6)
+
+			if mmap {
+				assert.Positive(t, store.persister.arena.bytes())
+			} else {
+				assert.Nil(t, store.persister.arena)
+			}
+		})
+	}
+}
+
+func TestChromemStore_UseAfterClose(t *testing.T) {
+	path := t.TempDir()
+	persister := newChromemPersister(path, false, zap.NewNop())
+	require.NoError(t, persister.saveCollection("closing", nil))
+	docs := make([]chromem.Document, 50)
+	for i := range docs {
+		docs[i] = chromem.Document{ID: fmt.Sprintf("doc-%d", i), Content: "content", Embedding: []float32{float32(i + 1), 1, 0, 0}}
+	}
+	require.NoError(t, persister.saveDocuments("closing", docs))
+
+	ctx := context.Background()
+	open := func(t *testing.T) *ChromemStore {
+		config := ChromemConfig{Path: path, VectorSize: 4, Isolation: NewNoIsolation(), MmapVectors: true}
+		store, err := NewChromemStore(config, &driftTestEmbedder{dim: 4}, zap.NewNop())
+		require.NoError(t, err)
+		require.Positive(t, store.persister.arena.bytes())
+		return store
+	}
+
+	t.Run("operations fail after close", func(t *testing.T) {
+		store := open(t)
+		samples, err := store.SampleVectors(ctx, "closing", 5)
+		require.NoError(t, err)
+		require.NotEmpty(t, samples)
+		require.NoError(t, store.Close())
+
+		// Sampled vectors are copies, still readable once the arena is unmapped
+		for _, sample := range samples {
+			assert.Len(t, sample.Vector, 4)
+			assert.Positive(t, sample.Vector[0])
+		}
+
+		_, err = store.SearchInCollection(ctx, "closing", "content", 5, nil)
+		assert.ErrorIs(t, err, ErrStoreClosed)
+		_, err = store.SampleVectors(ctx, "closing", 5)
+		assert.ErrorIs(t, err, ErrStoreClosed)
+		err = store.ScrollCollection(ctx, "closing", 10, nil, func([]SearchResult) error { return nil })
+		assert.ErrorIs(t, err, ErrStoreClosed)
+		_, err = store.AddDocuments(ctx, []Document{{ID: "late", Content: "late", Collection: "closing"}})
+		assert.ErrorIs(t, err, ErrStoreClosed)
+		_, err = store.ListCollections(ctx)
+		assert.ErrorIs(t, err, ErrStoreClosed)
+		assert.NoError(t, store.Close(), "closing twice is a no-op")
+	})
+
+	t.Run("close waits for in-flight searches", func(t *testing.T) {
+		store := open(t)
+		var searches atomic.Int64
+		errs := make(chan error, 4)
+		for range 4 {
+			go func() {
+				for {
+					_, err := store.SearchInCollection(ctx, "closing", "content", 5, nil)
+					if err != nil {
+						errs <- err
+						return
+					}
+					searches.Add(1)
+				}
+			}()
+		}
+		for searches.Load() < 20 {
+			runtime.Gosched()
+		}
+		require.NoError(t, store.Close())
+		for range 4 {
+			assert.ErrorIs(t, <-errs, ErrStoreClosed)
+		}
+	})
+}
+
+// BenchmarkChromemLoad compares loading a large collection whole, streamed,
+// and streamed into an mmap arena. It reports the peak RSS growth during the
+// load and the live heap afterwards. CONTEXTD_BENCH_LOAD_DOCS sets the
+// collection size (default 100000).
+//
+//	go test ./internal/vectorstore -run '^$' -bench ChromemLoad -benchtime 1x
+func BenchmarkChromemLoad(b *testing.B) {
+	n := 100_000
+	if v, err := strconv.Atoi(os.Getenv("CONTEXTD_BENCH_LOAD_DOCS")); err == nil && v > 0 {
+		n = v
+	}
+
+	path := b.TempDir()
+	fixture := newChromemPersister(path, false, zap.NewNop())
+	require.NoError(b, fixture.saveCollection("bench", nil))
+	batch := make([]chromem.Document, 0, 1000)
+	for i := 0; i < n; i++ {
+		embedding := make([]float32, 384)
+		for j := range embedding {
+			embedding[j] = float32((i*31+j*7)%101) - 50
+		}
+		batch = append(batch, chromem.Document{ID: fmt.Sprintf("doc-%d", i), Content: "benchmark document", Embedding: embedding})
+		if len(batch) == cap(batch) || i == n-1 {
+			require.NoError(b, fixture.saveDocuments("bench", batch))
+			batch = batch[:0]
+		}
+	}
+	hash := hashName("bench")
+
+	cases := []struct {
+		name string
+		load func(p *chromemPersister, db *chromem.DB) error
+	}{
+		{"whole", func(p *chromemPersister, db *chromem.DB) error {
+			meta, docs, err := p.loadCollection(hash, &chromemLoadReport{})
+			if err != nil {
+				return err
+			}
+			collection, err := db.CreateCollection(meta.Name, meta.Metadata, nil)
+			if err != nil {
+				return err
+			}
+			return collection.AddDocuments(context.Background(), docs, 1)
+		}},
+		{"stream", func(p *chromemPersister, db *chromem.DB) error {
+			return p.loadInto(context.Background(), db, nil, hash, &chromemLoadReport{})
+		}},
+		{"stream_mmap", func(p *chromemPersister, db *chromem.DB) error {
+			p.arena = newVectorArena(true, zap.NewNop())
+			return p.loadInto(context.Background(), db, nil, hash, &chromemLoadReport{})
+		}},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			var peak, heap float64
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				debug.FreeOSMemory()
+				base := readRSS()
+				stop := sampleRSS(base)
+				p := newChromemPersister(path, false, zap.NewNop())
+				db := chromem.NewDB()
+				b.StartTimer()
+
+				require.NoError(b, tc.load(p, db))
+
+				b.StopTimer()
+				peak = math.Max(peak, stop())
+				runtime.GC()
+				var ms runtime.MemStats
+				runtime.ReadMemStats(&ms)
+				heap = float64(ms.HeapAlloc)
+				runtime.KeepAlive(db)
+				if p.arena != nil {
+					_ = p.arena.release()
+				}
+			}
+			b.ReportMetric(peak/(1<<20), "peak-rss-MB")
+			b.ReportMetric(heap/(1<<20), "heap-MB")
+		})
+	}
+}
+
+// sampleRSS polls the resident set until the returned function is called,
+// which reports the largest growth over base in bytes.
+func sampleRSS(base int64) func() float64 {
+	var peak atomic.Int64
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			if rss := readRSS() - base; rss > peak.Load() {
+				peak.Store(rss)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() float64 {
+		close(done)
+		<-stopped
+		return float64(peak.Load())
+	}
+}
+
+// readRSS returns the process resident set in bytes, or 0 where
+// /proc/self/status is unavailable.
+func readRSS() int64 {
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmRSS:" {
+			kb, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package vectorstore
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// mapVectorBlock maps n zeroed floats outside the Go heap.
+func mapVectorBlock(n int) ([]float32, func() error, error) {
+	mem, err := syscall.Mmap(-1, 0, n*4, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, nil, err
+	}
+	block := unsafe.Slice((*float32)(unsafe.Pointer(&mem[0])), n)
+	return block, func() error { return syscall.Munmap(mem) }, nil
+}
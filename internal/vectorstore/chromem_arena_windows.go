@@ -0,0 +1,8 @@
+//go:build windows
+
+package vectorstore
+
+// mapVectorBlock is not implemented on Windows; the arena uses the heap.
+func mapVectorBlock(n int) ([]float32, func() error, error) {
+	return nil, nil, errMmapUnsupported
+}
@@ -0,0 +1,124 @@
+package vectorstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// lazyCollections tracks collections found on disk but not loaded yet.
+// Each collection loads on first access under its own lock, so a slow load
+// only blocks callers of that collection.
+type lazyCollections struct {
+	mu      sync.Mutex
+	pending map[string]*pendingCollection
+}
+
+// pendingCollection is one collection waiting to be loaded.
+type pendingCollection struct {
+	mu   sync.Mutex
+	hash string // collection directory
+	done bool
+}
+
+func newLazyCollections(found map[string]string) *lazyCollections {
+	l := &lazyCollections{pending: make(map[string]*pendingCollection, len(found))}
+	for name, hash := range found {
+		l.pending[name] = &pendingCollection{hash: hash}
+	}
+	return l
+}
+
+// load runs fn for name if it is still pending. Concurrent callers for the
+// same collection wait for the first; a failed load is retried next time.
+func (l *lazyCollections) load(name string, fn func(hash string) error) error {
+	l.mu.Lock()
+	pc, ok := l.pending[name]
+	l.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.done {
+		return nil
+	}
+	if err := fn(pc.hash); err != nil {
+		return err
+	}
+	pc.done = true
+
+	l.mu.Lock()
+	delete(l.pending, name)
+	l.mu.Unlock()
+	return nil
+}
+
+// has reports whether name is pending.
+func (l *lazyCollections) has(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.pending[name]
+	return ok
+}
+
+// forget drops name without loading it, for deleted collections.
+func (l *lazyCollections) forget(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pending, name)
+}
+
+// names returns the pending collection names, sorted.
+func (l *lazyCollections) names() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.pending))
+	for name := range l.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ensureLoaded loads a lazily discovered collection into the database
+// before first use. It is a no-op when lazy loading is off or the
+// collection is already loaded.
+func (s *ChromemStore) ensureLoaded(ctx context.Context, name string) error {
+	if s.lazy == nil {
+		return nil
+	}
+	return s.lazy.load(name, func(hash string) error {
+		start := time.Now()
+		report := &chromemLoadReport{}
+		if err := s.persister.loadInto(ctx, s.db, s.createEmbeddingFunc(), hash, report); err != nil {
+			return err
+		}
+		s.logger.Debug("lazily loaded collection",
+			zap.String("collection", name),
+			zap.Int("documents", report.Documents),
+			zap.Int("recovered_documents", len(report.Recovered)),
+			zap.Strings("upgraded", report.Upgraded),
+			zap.Duration("duration", time.Since(start)))
+		return nil
+	})
+}
+
+// warmup loads collections expected to be used soon, so their first
+// request does not pay the load. Failures are logged and retried on access.
+func (s *ChromemStore) warmup(names []string) {
+	for _, name := range names {
+		done, err := s.use()
+		if err != nil {
+			return // closed before warmup finished
+		}
+		if err := s.ensureLoaded(context.Background(), name); err != nil {
+			s.logger.Warn("failed to warm up collection", zap.String("collection", name), zap.Error(err))
+		}
+		done()
+	}
+}
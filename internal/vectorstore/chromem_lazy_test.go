@@ -0,0 +1,138 @@
+package vectorstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// seedLazyStore persists documents in two collections and returns a config
+// that reopens the store with lazy loading.
+func seedLazyStore(t *testing.T, embedder Embedder) ChromemConfig {
+	t.Helper()
+	config := ChromemConfig{Path: t.TempDir(), DefaultCollection: "lazy_default", VectorSize: 16, Isolation: NewNoIsolation()}
+
+	store, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	_, err = store.AddDocuments(context.Background(), []Document{
+		{ID: "a", Content: "alpha", Collection: "lazy_hot"},
+		{ID: "b", Content: "beta", Collection: "lazy_hot"},
+	})
+	require.NoError(t, err)
+	_, err = store.AddDocuments(context.Background(), []Document{{ID: "c", Content: "gamma", Collection: "lazy_cold"}})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	config.LazyLoad = true
+	return config
+}
+
+func TestChromemStore_LazyLoad(t *testing.T) {
+	embedder := &driftTestEmbedder{dim: 16}
+	config := seedLazyStore(t, embedder)
+	ctx := context.Background()
+
+	store, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	defer store.Close()
+
+	assert.Equal(t, []string{"lazy_cold", "lazy_hot"}, store.lazy.names())
+	assert.Empty(t, store.db.ListCollections(), "nothing loaded at startup")
+
+	// Pending collections are listed and exist without being loaded
+	names, err := store.ListCollections(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"lazy_cold", "lazy_hot"}, names)
+	exists, err := store.CollectionExists(ctx, "lazy_cold")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.True(t, store.lazy.has("lazy_cold"))
+
+	// First access loads the collection
+	results, err := store.SearchInCollection(ctx, "lazy_hot", "alpha", 5, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.False(t, store.lazy.has("lazy_hot"))
+
+	info, err := store.GetCollectionInfo(ctx, "lazy_hot")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.PointCount, "loaded once, not twice")
+
+	// Writing to a pending collection keeps its persisted documents
+	_, err = store.AddDocuments(ctx, []Document{{ID: "d", Content: "delta", Collection: "lazy_cold"}})
+	require.NoError(t, err)
+	info, err = store.GetCollectionInfo(ctx, "lazy_cold")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.PointCount)
+}
+
+func TestChromemStore_LazyLoad_Concurrent(t *testing.T) {
+	embedder := &driftTestEmbedder{dim: 16}
+	config := seedLazyStore(t, embedder)
+	ctx := context.Background()
+
+	store, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	defer store.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.SearchInCollection(ctx, "lazy_hot", "beta", 5, nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	info, err := store.GetCollectionInfo(ctx, "lazy_hot")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.PointCount)
+}
+
+func TestChromemStore_LazyLoad_Warmup(t *testing.T) {
+	embedder := &driftTestEmbedder{dim: 16}
+	config := seedLazyStore(t, embedder)
+	config.Warmup = []string{"lazy_hot", "missing"}
+
+	store, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.Eventually(t, func() bool { return !store.lazy.has("lazy_hot") }, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, store.lazy.has("lazy_cold"), "collections outside the warmup list stay pending")
+}
+
+func TestChromemStore_LazyLoad_DeletePending(t *testing.T) {
+	embedder := &driftTestEmbedder{dim: 16}
+	config := seedLazyStore(t, embedder)
+	ctx := context.Background()
+
+	store, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteCollection(ctx, "lazy_cold"))
+
+	exists, err := store.CollectionExists(ctx, "lazy_cold")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	require.NoError(t, store.Close())
+
+	// The deleted collection is gone from disk too
+	reopened, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	defer reopened.Close()
+	names, err := reopened.ListCollections(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lazy_hot"}, names)
+}
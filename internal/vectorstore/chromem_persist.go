@@ -0,0 +1,728 @@
+package vectorstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	chromem "github.com/philippgille/chromem-go"
+	"go.uber.org/zap"
+)
+
+// chromem-go persists each write with a plain os.Create, so a crash or a full
+// disk mid-encode leaves a truncated gob behind and the next load fails. The
+// store therefore keeps chromem in memory and persists through
+// chromemPersister, which uses the same directory layout and gob encoding
+// (so ctxd tools and older versions still read the data) but:
+//
+//   - writes every file to a temp file, fsyncs it, and renames it into place
+//   - stores a SHA-256 sidecar (<file>.sha256) next to every file
+//   - verifies sidecars on load and moves corrupt documents to .recovery
+//     instead of failing the whole store
+//   - upgrades collections written with an older schema version on load
+//     (see chromem_schema.go)
+const (
+	chromemMetadataFile = "00000000"
+	checksumSuffix      = ".sha256"
+	tempFileMarker      = ".tmp-"
+	recoveryDir         = ".recovery"
+	quarantineDir       = ".quarantine"
+
+	// chromemLoadBatch is how many decoded documents are held before they
+	// are added to the collection during load.
+	chromemLoadBatch = 512
+)
+
+// persistedCollection is chromem-go's on-disk collection metadata.
+type persistedCollection struct {
+	Name     string
+	Metadata map[string]string
+}
+
+// chromemPersister reads and writes chromem collections under dir.
+type chromemPersister struct {
+	dir        string
+	compress   bool
+	logger     *zap.Logger
+	migrations []schemaMigration
+
+	// mu serializes writes so a document's data and sidecar stay paired
+	mu sync.Mutex
+
+	// arena holds loaded embeddings when vector packing is enabled; nil
+	// keeps each decoded embedding as its own allocation
+	arena *vectorArena
+
+	// dirs maps loaded collection names to their directory. Tools such as
+	// migrate-collection create directories that are not the name's hash.
+	dirs sync.Map
+}
+
+// chromemLoadReport summarizes what load found on disk.
+type chromemLoadReport struct {
+	Collections int
+	Documents   int
+	Recovered   []string // documents moved to the recovery directory
+	Quarantined []string // collections moved to the quarantine directory
+	Upgraded    []string // collections migrated to the current schema version
+}
+
+func newChromemPersister(dir string, compress bool, logger *zap.Logger) *chromemPersister {
+	return &chromemPersister{dir: dir, compress: compress, logger: logger, migrations: schemaMigrations}
+}
+
+// hashName mirrors chromem-go's file naming: the first 4 bytes of SHA-256 as hex.
+func hashName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:4])
+}
+
+func (p *chromemPersister) ext() string {
+	if p.compress {
+		return ".gob.gz"
+	}
+	return ".gob"
+}
+
+func (p *chromemPersister) collectionDir(name string) string {
+	if dir, ok := p.dirs.Load(name); ok {
+		return filepath.Join(p.dir, dir.(string))
+	}
+	return filepath.Join(p.dir, hashName(name))
+}
+
+func (p *chromemPersister) documentPath(collection, id string) string {
+	return filepath.Join(p.collectionDir(collection), hashName(id)+p.ext())
+}
+
+func (p *chromemPersister) metadataPath(name string) string {
+	return filepath.Join(p.collectionDir(name), chromemMetadataFile+p.ext())
+}
+
+// saveCollection writes collection metadata, stamped with the current schema
+// version, if it is not on disk yet.
+func (p *chromemPersister) saveCollection(name string, metadata map[string]string) error {
+	if _, err := os.Stat(p.metadataPath(name)); err == nil {
+		return nil
+	}
+
+	stamped := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		stamped[k] = v
+	}
+	stamped[SchemaVersionKey] = strconv.Itoa(CurrentSchemaVersion)
+
+	return p.writeCollection(name, stamped)
+}
+
+// writeCollection replaces collection metadata unconditionally.
+func (p *chromemPersister) writeCollection(name string, metadata map[string]string) error {
+	data, err := p.encode(persistedCollection{Name: name, Metadata: metadata})
+	if err != nil {
+		return fmt.Errorf("encoding collection metadata: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.writeChecked(p.metadataPath(name), data)
+}
+
+// saveDocuments writes documents, which must already carry their embeddings.
+func (p *chromemPersister) saveDocuments(collection string, docs []chromem.Document) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, doc := range docs {
+		data, err := p.encode(doc)
+		if err != nil {
+			return fmt.Errorf("encoding document %s: %w", doc.ID, err)
+		}
+		if err := p.writeChecked(p.documentPath(collection, doc.ID), data); err != nil {
+			return fmt.Errorf("persisting document %s: %w", doc.ID, err)
+		}
+		persistIO.recordDocuments("write", collection, 1)
+	}
+	return nil
+}
+
+// deleteDocument removes a document and its sidecar.
+func (p *chromemPersister) deleteDocument(collection, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	path := p.documentPath(collection, id)
+	if err := removeIfExists(path); err != nil {
+		return err
+	}
+	if err := removeIfExists(path + checksumSuffix); err != nil {
+		return err
+	}
+	persistIO.recordDocuments("delete", collection, 1)
+	return nil
+}
+
+// deleteCollection removes a collection directory.
+func (p *chromemPersister) deleteCollection(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err := os.RemoveAll(p.collectionDir(name))
+	p.dirs.Delete(name)
+	return err
+}
+
+// writeChecked atomically replaces path with data and updates its sidecar.
+//
+// The sidecar briefly lists both the new and the current checksum, so a
+// crash between the two renames leaves a file that still verifies.
+func (p *chromemPersister) writeChecked(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating collection directory: %w", err)
+	}
+
+	sum := checksum(data)
+	accepted := []string{sum}
+	if previous, err := readChecksums(path); err == nil && len(previous) > 0 && previous[0] != sum {
+		accepted = append(accepted, previous[0])
+		if err := writeFileAtomic(path+checksumSuffix, formatChecksums(accepted)); err != nil {
+			return fmt.Errorf("writing checksum: %w", err)
+		}
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	return writeFileAtomic(path+checksumSuffix, formatChecksums(accepted[:1]))
+}
+
+// encode serializes obj the way chromem-go does: gob, optionally gzipped.
+func (p *chromemPersister) encode(obj any) ([]byte, error) {
+	var buf bytes.Buffer
+	if !p.compress {
+		if err := gob.NewEncoder(&buf).Encode(obj); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	gzw := gzip.NewWriter(&buf)
+	if err := gob.NewEncoder(gzw).Encode(obj); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decode reads gob data, gunzipping it first if it starts with the gzip magic.
+func decode(data []byte, obj any) error {
+	var r io.Reader = bytes.NewReader(data)
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gzr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gzr.Close()
+		r = gzr
+	}
+	return gob.NewDecoder(r).Decode(obj)
+}
+
+// load reads every collection into db, quarantining what cannot be read.
+func (p *chromemPersister) load(ctx context.Context, db *chromem.DB, embed chromem.EmbeddingFunc) (*chromemLoadReport, error) {
+	start := time.Now()
+	report := &chromemLoadReport{}
+
+	hashes, err := p.collectionHashes()
+	if err != nil {
+		return nil, err
+	}
+	for _, hash := range hashes {
+		if err := p.loadInto(ctx, db, embed, hash, report); err != nil {
+			return nil, err
+		}
+	}
+
+	persistIO.recordLoad(time.Since(start))
+	return report, nil
+}
+
+// scan finds the collections on disk without loading their documents and
+// returns their directories by name, for loading on first access with
+// loadInto. Collections whose metadata cannot be read are loaded right away
+// so the usual quarantine applies.
+func (p *chromemPersister) scan() (map[string]string, *chromemLoadReport, error) {
+	start := time.Now()
+	report := &chromemLoadReport{}
+
+	hashes, err := p.collectionHashes()
+	if err != nil {
+		return nil, nil, err
+	}
+	found := make(map[string]string, len(hashes))
+	for _, hash := range hashes {
+		meta := p.readMetadata(hash)
+		if meta == nil {
+			// No collection is created without metadata, so db is not used
+			if err := p.loadInto(context.Background(), nil, nil, hash, report); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		p.dirs.Store(meta.Name, hash)
+		found[meta.Name] = hash
+	}
+
+	persistIO.recordLoad(time.Since(start))
+	return found, report, nil
+}
+
+// collectionHashes lists the collection directories under dir.
+func (p *chromemPersister) collectionHashes() ([]string, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading vectorstore directory: %w", err)
+	}
+
+	var hashes []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			hashes = append(hashes, entry.Name())
+		}
+	}
+	return hashes, nil
+}
+
+// readMetadata reads a collection directory's metadata, or returns nil if
+// it is missing or unreadable.
+func (p *chromemPersister) readMetadata(hash string) *persistedCollection {
+	data, err := p.readVerified(filepath.Join(p.dir, hash, chromemMetadataFile+p.ext()))
+	if err != nil {
+		return nil
+	}
+	var pc persistedCollection
+	if err := decode(data, &pc); err != nil || pc.Name == "" {
+		return nil
+	}
+	return &pc
+}
+
+// loadInto reads one collection directory into db, quarantining it if it
+// has documents but no readable metadata.
+//
+// Documents are added in batches of chromemLoadBatch as they are decoded, so
+// peak memory stays near one batch over the loaded collection. Collections
+// due a schema upgrade are read whole, since upgrades rewrite every document.
+func (p *chromemPersister) loadInto(ctx context.Context, db *chromem.DB, embed chromem.EmbeddingFunc, hash string, report *chromemLoadReport) error {
+	if meta := p.readMetadata(hash); meta != nil && !p.needsUpgrade(meta) {
+		return p.streamInto(ctx, db, embed, hash, meta, report)
+	}
+
+	meta, docs, err := p.loadCollection(hash, report)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		if len(docs) > 0 {
+			p.quarantineCollection(hash, report)
+		}
+		return nil
+	}
+
+	p.dirs.Store(meta.Name, hash)
+	if p.upgradeCollection(hash, meta, docs) {
+		report.Upgraded = append(report.Upgraded, meta.Name)
+	}
+
+	collection, err := db.CreateCollection(meta.Name, meta.Metadata, embed)
+	if err != nil {
+		return fmt.Errorf("loading collection %s: %w", meta.Name, err)
+	}
+	for i := range docs {
+		docs[i].Embedding = p.packVector(docs[i].Embedding)
+	}
+	if len(docs) > 0 {
+		if err := collection.AddDocuments(ctx, docs, 1); err != nil {
+			return fmt.Errorf("loading documents for collection %s: %w", meta.Name, err)
+		}
+	}
+	report.Collections++
+	report.Documents += len(docs)
+	persistIO.recordDocuments("load", meta.Name, len(docs))
+	return nil
+}
+
+// streamInto loads a collection with known metadata batch by batch.
+func (p *chromemPersister) streamInto(ctx context.Context, db *chromem.DB, embed chromem.EmbeddingFunc, hash string, meta *persistedCollection, report *chromemLoadReport) error {
+	p.dirs.Store(meta.Name, hash)
+	collection, err := db.CreateCollection(meta.Name, meta.Metadata, embed)
+	if err != nil {
+		return fmt.Errorf("loading collection %s: %w", meta.Name, err)
+	}
+
+	loaded := 0
+	batch := make([]chromem.Document, 0, chromemLoadBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := collection.AddDocuments(ctx, batch, 1); err != nil {
+			return fmt.Errorf("loading documents for collection %s: %w", meta.Name, err)
+		}
+		loaded += len(batch)
+		// chromem-go keeps its own copy of each document, so the batch is reusable
+		clear(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	_, err = p.walkCollection(hash, report, func(doc chromem.Document) error {
+		doc.Embedding = p.packVector(doc.Embedding)
+		batch = append(batch, doc)
+		if len(batch) == cap(batch) {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Collections++
+	report.Documents += loaded
+	persistIO.recordDocuments("load", meta.Name, loaded)
+	return nil
+}
+
+// packVector moves a decoded embedding into the vector arena, if any.
+func (p *chromemPersister) packVector(v []float32) []float32 {
+	if p.arena == nil {
+		return v
+	}
+	return p.arena.store(v)
+}
+
+// loadCollection reads one collection directory. A nil metadata result means
+// the metadata file is missing or unreadable.
+func (p *chromemPersister) loadCollection(hash string, report *chromemLoadReport) (*persistedCollection, []chromem.Document, error) {
+	var docs []chromem.Document
+	meta, err := p.walkCollection(hash, report, func(doc chromem.Document) error {
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta, docs, nil
+}
+
+// walkCollection decodes one collection directory, calling fn for each
+// readable document and moving unreadable ones to the recovery directory.
+// It returns the collection metadata, or nil if it is missing or unreadable.
+func (p *chromemPersister) walkCollection(hash string, report *chromemLoadReport, fn func(doc chromem.Document) error) (*persistedCollection, error) {
+	dir := filepath.Join(p.dir, hash)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading collection directory %s: %w", hash, err)
+	}
+
+	var meta *persistedCollection
+	ext := p.ext()
+
+	for _, f := range files {
+		name := f.Name()
+		path := filepath.Join(dir, name)
+
+		switch {
+		case f.IsDir():
+			continue
+		case strings.Contains(name, tempFileMarker):
+			// Leftover from an interrupted write; the target was never replaced
+			_ = os.Remove(path)
+			continue
+		case strings.HasSuffix(name, checksumSuffix):
+			if _, err := os.Stat(strings.TrimSuffix(path, checksumSuffix)); errors.Is(err, os.ErrNotExist) {
+				_ = os.Remove(path)
+			}
+			continue
+		case !strings.HasSuffix(name, ext):
+			continue
+		}
+
+		data, err := p.readVerified(path)
+		if name == chromemMetadataFile+ext {
+			var pc persistedCollection
+			if err == nil {
+				err = decode(data, &pc)
+			}
+			if err != nil || pc.Name == "" {
+				p.logger.Warn("collection metadata unreadable",
+					zap.String("collection_hash", hash),
+					zap.Error(err))
+				continue
+			}
+			meta = &pc
+			continue
+		}
+
+		var doc chromem.Document
+		if err == nil {
+			err = decode(data, &doc)
+		}
+		if err == nil && doc.ID == "" {
+			err = errors.New("document has no ID")
+		}
+		if err != nil {
+			p.recoverDocument(hash, name, err, report)
+			continue
+		}
+		if err := fn(doc); err != nil {
+			return meta, err
+		}
+	}
+
+	return meta, nil
+}
+
+// readCollection decodes the document files of a collection for integrity
+// checks. Unlike walkCollection it changes nothing on disk: files that fail
+// their checksum or do not decode are passed to fn with DecodeError set. A
+// collection that was never persisted has no files.
+func (p *chromemPersister) readCollection(name string, fn func(doc ScannedDocument) error) error {
+	dir := p.collectionDir(name)
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading collection directory: %w", err)
+	}
+
+	ext := p.ext()
+	for _, f := range files {
+		fileName := f.Name()
+		if f.IsDir() || fileName == chromemMetadataFile+ext ||
+			strings.Contains(fileName, tempFileMarker) || !strings.HasSuffix(fileName, ext) {
+			continue
+		}
+		path := filepath.Join(dir, fileName)
+
+		var doc chromem.Document
+		data, err := os.ReadFile(path)
+		if err == nil {
+			// Files without a sidecar predate checksums and are not at fault
+			if sums, sumErr := readChecksums(path); sumErr == nil && !slices.Contains(sums, checksum(data)) {
+				err = errors.New("checksum mismatch")
+			}
+		}
+		if err == nil {
+			err = decode(data, &doc)
+		}
+		if err == nil && doc.ID == "" {
+			err = errors.New("document has no ID")
+		}
+
+		scanned := ScannedDocument{Location: path}
+		if err != nil {
+			scanned.DecodeError = err.Error()
+		} else {
+			scanned.ID = doc.ID
+			scanned.Content = doc.Content
+			scanned.Metadata = convertMetadataFromString(doc.Metadata)
+			scanned.Vector = doc.Embedding
+		}
+		if err := fn(scanned); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readVerified reads a file and checks it against its sidecar. Files without
+// a sidecar (written by chromem-go or older versions) are adopted by writing
+// one; a sidecar left with two checksums by an interrupted write is reduced
+// to the one that matches.
+func (p *chromemPersister) readVerified(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := checksum(data)
+
+	expected, err := readChecksums(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := writeFileAtomic(path+checksumSuffix, formatChecksums([]string{sum})); err != nil {
+			p.logger.Warn("failed to write checksum", zap.String("path", path), zap.Error(err))
+		}
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checksum: %w", err)
+	}
+
+	for i, want := range expected {
+		if want != sum {
+			continue
+		}
+		if i > 0 || len(expected) > 1 {
+			_ = writeFileAtomic(path+checksumSuffix, formatChecksums([]string{sum}))
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("checksum mismatch: got %s, want %s", sum, strings.Join(expected, " or "))
+}
+
+// recoverDocument moves a corrupt document and its sidecar to
+// .recovery/<collection hash>/ so the rest of the collection still loads.
+func (p *chromemPersister) recoverDocument(hash, name string, cause error, report *chromemLoadReport) {
+	src := filepath.Join(p.dir, hash, name)
+	dstDir := filepath.Join(p.dir, recoveryDir, hash)
+
+	err := os.MkdirAll(dstDir, 0o700)
+	if err == nil {
+		err = os.Rename(src, filepath.Join(dstDir, name))
+	}
+	if err == nil {
+		if sidecarErr := os.Rename(src+checksumSuffix, filepath.Join(dstDir, name+checksumSuffix)); sidecarErr != nil && !errors.Is(sidecarErr, os.ErrNotExist) {
+			p.logger.Warn("failed to move checksum to recovery directory", zap.String("path", src), zap.Error(sidecarErr))
+		}
+	}
+	RecordQuarantineResult(err == nil)
+
+	if err != nil {
+		// Leave the file in place; it is skipped on every load until removed
+		p.logger.Error("failed to move corrupt document to recovery directory",
+			zap.String("path", src),
+			zap.NamedError("cause", cause),
+			zap.Error(err))
+		return
+	}
+
+	p.logger.Warn("moved corrupt document to recovery directory",
+		zap.String("collection_hash", hash),
+		zap.String("file", name),
+		zap.String("recovery_path", dstDir),
+		zap.Error(cause))
+	report.Recovered = append(report.Recovered, filepath.Join(hash, name))
+}
+
+// quarantineCollection moves a collection with documents but no readable
+// metadata to .quarantine, where ctxd metadata recover can restore it.
+func (p *chromemPersister) quarantineCollection(hash string, report *chromemLoadReport) {
+	if !isValidCollectionHash(hash) {
+		p.logger.Error("invalid collection hash format, skipping", zap.String("hash", hash))
+		return
+	}
+
+	dstDir := filepath.Join(p.dir, quarantineDir)
+	err := os.MkdirAll(dstDir, 0o755)
+	if err == nil {
+		err = os.Rename(filepath.Join(p.dir, hash), filepath.Join(dstDir, hash))
+	}
+	RecordQuarantineResult(err == nil)
+
+	if err != nil {
+		p.logger.Error("failed to quarantine collection",
+			zap.String("collection_hash", hash),
+			zap.Error(err))
+		return
+	}
+
+	p.logger.Warn("quarantined collection without metadata",
+		zap.String("collection_hash", hash),
+		zap.String("quarantine_path", filepath.Join(dstDir, hash)))
+	report.Quarantined = append(report.Quarantined, hash)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readChecksums returns the accepted checksums for path, current first.
+func readChecksums(path string) ([]string, error) {
+	data, err := os.ReadFile(path + checksumSuffix)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+func formatChecksums(sums []string) []byte {
+	return []byte(strings.Join(sums, "\n") + "\n")
+}
+
+// writeFileAtomic writes data to a temp file in the target directory, fsyncs
+// it, and renames it over path, so readers see either the old or the new
+// content and never a partial write.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+tempFileMarker+"*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	cleanup := func() { _ = os.Remove(tmpPath) }
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	syncStart := time.Now()
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("syncing %s: %w", path, err)
+	}
+	persistIO.recordWrite(len(data), time.Since(syncStart))
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, 0o600); err != nil {
+		cleanup()
+		return fmt.Errorf("setting permissions on %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		cleanup()
+		return fmt.Errorf("renaming into %s: %w", path, err)
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir makes a rename durable. Not all platforms support syncing a
+// directory, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package vectorstore
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// persistMetrics instruments chromem persistence I/O, so operators can tell
+// when disk writes, fsyncs or startup loads are the bottleneck on large
+// banks.
+type persistMetrics struct {
+	meter  metric.Meter
+	logger *zap.Logger
+
+	documents    metric.Int64Counter
+	bytesWritten metric.Int64Counter
+	fsync        metric.Float64Histogram
+	load         metric.Float64Histogram
+	collection   metric.Int64ObservableGauge
+}
+
+// persistIO is the persistence instrumentation shared by all chromem stores.
+var persistIO = newPersistMetrics(otel.Meter(vectorstoreInstrumentationName), zap.NewNop())
+
+func newPersistMetrics(meter metric.Meter, logger *zap.Logger) *persistMetrics {
+	m := &persistMetrics{meter: meter, logger: logger}
+	var err error
+
+	m.documents, err = meter.Int64Counter(
+		"contextd.vectorstore.persist_documents_total",
+		metric.WithDescription("Documents persisted to or removed from chromem files, labeled by operation (write, delete, load) and collection. Use rate() for documents persisted per second."),
+		metric.WithUnit("{document}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create persisted documents counter", zap.Error(err))
+	}
+
+	m.bytesWritten, err = meter.Int64Counter(
+		"contextd.vectorstore.persist_written",
+		metric.WithDescription("Bytes written to chromem files, including checksum sidecars."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		logger.Warn("failed to create persisted bytes counter", zap.Error(err))
+	}
+
+	m.fsync, err = meter.Float64Histogram(
+		"contextd.vectorstore.persist_fsync_duration_seconds",
+		metric.WithDescription("Duration of fsync calls when persisting chromem files. High latency means the disk, not the index, limits write throughput."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0),
+	)
+	if err != nil {
+		logger.Warn("failed to create fsync duration histogram", zap.Error(err))
+	}
+
+	m.load, err = meter.Float64Histogram(
+		"contextd.vectorstore.persist_load_duration_seconds",
+		metric.WithDescription("Time to load a chromem store from disk at startup, including checksum verification and schema upgrades."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0),
+	)
+	if err != nil {
+		logger.Warn("failed to create load duration histogram", zap.Error(err))
+	}
+
+	m.collection, err = meter.Int64ObservableGauge(
+		"contextd.vectorstore.collection_documents",
+		metric.WithDescription("Documents held in each chromem collection, labeled by collection and store path."),
+		metric.WithUnit("{document}"),
+	)
+	if err != nil {
+		logger.Warn("failed to create collection documents gauge", zap.Error(err))
+	}
+
+	return m
+}
+
+// recordDocuments counts documents written, deleted or loaded.
+func (m *persistMetrics) recordDocuments(op, collection string, count int) {
+	if m.documents != nil && count > 0 {
+		m.documents.Add(context.Background(), int64(count), metric.WithAttributes(
+			attribute.String("operation", op),
+			attribute.String("collection", collection),
+		))
+	}
+}
+
+// recordWrite records one file write and the fsync that made it durable.
+func (m *persistMetrics) recordWrite(bytes int, fsync time.Duration) {
+	ctx := context.Background()
+	if m.bytesWritten != nil {
+		m.bytesWritten.Add(ctx, int64(bytes))
+	}
+	if m.fsync != nil {
+		m.fsync.Record(ctx, fsync.Seconds())
+	}
+}
+
+// recordLoad records how long a store took to load from disk.
+func (m *persistMetrics) recordLoad(duration time.Duration) {
+	if m.load != nil {
+		m.load.Record(context.Background(), duration.Seconds())
+	}
+}
+
+// observeCollections registers a callback reporting the document count of
+// every collection in store. Unregister it when the store is closed.
+func (m *persistMetrics) observeCollections(store *ChromemStore, path string) (metric.Registration, error) {
+	if m.collection == nil {
+		return nil, nil
+	}
+	return m.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for name, collection := range store.db.ListCollections() {
+			o.ObserveInt64(m.collection, int64(collection.Count()), metric.WithAttributes(
+				attribute.String("collection", name),
+				attribute.String("path", path),
+			))
+		}
+		return nil
+	}, m.collection)
+}
@@ -0,0 +1,108 @@
+package vectorstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+// withPersistMetrics routes persistence metrics to a manual reader for the
+// duration of the test.
+func withPersistMetrics(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	old := persistIO
+	persistIO = newPersistMetrics(mp.Meter(vectorstoreInstrumentationName), zap.NewNop())
+	t.Cleanup(func() { persistIO = old })
+	return reader
+}
+
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Metrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	out := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			out[m.Name] = m
+		}
+	}
+	return out
+}
+
+// sumByOperation totals a counter's data points by their operation attribute.
+func sumByOperation(t *testing.T, m metricdata.Metrics) map[string]int64 {
+	t.Helper()
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	out := make(map[string]int64)
+	for _, dp := range sum.DataPoints {
+		op, _ := dp.Attributes.Value("operation")
+		out[op.AsString()] += dp.Value
+	}
+	return out
+}
+
+func TestChromemStore_PersistMetrics(t *testing.T) {
+	reader := withPersistMetrics(t)
+	embedder := &driftTestEmbedder{dim: 16}
+	path := t.TempDir()
+	config := ChromemConfig{Path: path, DefaultCollection: "metrics_default", VectorSize: 16, Isolation: NewNoIsolation()}
+	ctx := context.Background()
+
+	store, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	_, err = store.AddDocuments(ctx, []Document{
+		{ID: "a", Content: "first", Collection: "metrics_docs"},
+		{ID: "b", Content: "second", Collection: "metrics_docs"},
+		{ID: "c", Content: "third", Collection: "metrics_docs"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteDocumentsFromCollection(ctx, "metrics_docs", []string{"c"}))
+
+	metrics := collectMetrics(t, reader)
+	assert.Equal(t, map[string]int64{"write": 3, "delete": 1}, sumByOperation(t, metrics["contextd.vectorstore.persist_documents_total"]))
+
+	written, ok := metrics["contextd.vectorstore.persist_written"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.Greater(t, written.DataPoints[0].Value, int64(0))
+
+	fsync, ok := metrics["contextd.vectorstore.persist_fsync_duration_seconds"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, fsync.DataPoints[0].Count, uint64(3), "one fsync per document file at least")
+
+	gauge, ok := metrics["contextd.vectorstore.collection_documents"].Data.(metricdata.Gauge[int64])
+	require.True(t, ok)
+	counts := make(map[string]int64)
+	for _, dp := range gauge.DataPoints {
+		name, _ := dp.Attributes.Value("collection")
+		storePath, _ := dp.Attributes.Value("path")
+		assert.Equal(t, path, storePath.AsString())
+		counts[name.AsString()] = dp.Value
+	}
+	assert.Equal(t, int64(2), counts["metrics_docs"])
+
+	// Reloading the store records the load and its documents
+	require.NoError(t, store.Close())
+	reopened, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	metrics = collectMetrics(t, reader)
+	assert.Equal(t, int64(2), sumByOperation(t, metrics["contextd.vectorstore.persist_documents_total"])["load"])
+	load, ok := metrics["contextd.vectorstore.persist_load_duration_seconds"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), load.DataPoints[0].Count, "both opens recorded a load")
+
+	gauge, ok = metrics["contextd.vectorstore.collection_documents"].Data.(metricdata.Gauge[int64])
+	require.True(t, ok)
+	require.Len(t, gauge.DataPoints, 1, "the closed store no longer reports")
+	assert.Equal(t, int64(2), gauge.DataPoints[0].Value)
+}
@@ -0,0 +1,268 @@
+package vectorstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	chromem "github.com/philippgille/chromem-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestPersister(t *testing.T) *chromemPersister {
+	t.Helper()
+	return newChromemPersister(t.TempDir(), false, zap.NewNop())
+}
+
+func testEmbeddingFunc(_ context.Context, _ string) ([]float32, error) {
+	return []float32{1, 0, 0}, nil
+}
+
+func testDocument(id string) chromem.Document {
+	return chromem.Document{
+		ID:        id,
+		Content:   "content for " + id,
+		Metadata:  map[string]string{"tenant_id": "org"},
+		Embedding: []float32{1, 0, 0},
+	}
+}
+
+func loadTestDB(t *testing.T, p *chromemPersister) (*chromem.DB, *chromemLoadReport) {
+	t.Helper()
+	db := chromem.NewDB()
+	report, err := p.load(context.Background(), db, testEmbeddingFunc)
+	require.NoError(t, err)
+	return db, report
+}
+
+func TestChromemPersister_RoundTrip(t *testing.T) {
+	p := newTestPersister(t)
+
+	require.NoError(t, p.saveCollection("memories", map[string]string{"k": "v"}))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("a"), testDocument("b")}))
+
+	db, report := loadTestDB(t, p)
+	assert.Equal(t, 1, report.Collections)
+	assert.Equal(t, 2, report.Documents)
+	assert.Empty(t, report.Recovered)
+
+	collection := db.GetCollection("memories", testEmbeddingFunc)
+	require.NotNil(t, collection)
+	assert.Equal(t, 2, collection.Count())
+
+	doc, err := collection.GetByID(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, "content for a", doc.Content)
+	assert.Equal(t, "org", doc.Metadata["tenant_id"])
+}
+
+func TestChromemPersister_Compressed(t *testing.T) {
+	p := newChromemPersister(t.TempDir(), true, zap.NewNop())
+
+	require.NoError(t, p.saveCollection("memories", nil))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("a")}))
+
+	assert.FileExists(t, filepath.Join(p.collectionDir("memories"), chromemMetadataFile+".gob.gz"))
+
+	_, report := loadTestDB(t, p)
+	assert.Equal(t, 1, report.Documents)
+}
+
+func TestChromemPersister_WritesChecksumSidecars(t *testing.T) {
+	p := newTestPersister(t)
+
+	require.NoError(t, p.saveCollection("memories", nil))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("a")}))
+
+	path := p.documentPath("memories", "a")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	sums, err := readChecksums(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{checksum(data)}, sums)
+}
+
+func TestChromemPersister_CorruptDocumentMovedToRecovery(t *testing.T) {
+	p := newTestPersister(t)
+
+	require.NoError(t, p.saveCollection("memories", nil))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("good"), testDocument("bad")}))
+
+	// Simulate a torn write that left a truncated file behind
+	badPath := p.documentPath("memories", "bad")
+	data, err := os.ReadFile(badPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(badPath, data[:len(data)/2], 0o600))
+
+	db, report := loadTestDB(t, p)
+	assert.Equal(t, 1, report.Documents)
+	require.Len(t, report.Recovered, 1)
+
+	collection := db.GetCollection("memories", testEmbeddingFunc)
+	require.NotNil(t, collection)
+	assert.Equal(t, 1, collection.Count())
+
+	hash := hashName("memories")
+	name := filepath.Base(badPath)
+	assert.NoFileExists(t, badPath)
+	assert.FileExists(t, filepath.Join(p.dir, recoveryDir, hash, name))
+	assert.FileExists(t, filepath.Join(p.dir, recoveryDir, hash, name+checksumSuffix))
+
+	// The recovery directory is not loaded as a collection
+	_, report = loadTestDB(t, p)
+	assert.Equal(t, 1, report.Collections)
+	assert.Empty(t, report.Recovered)
+}
+
+func TestChromemPersister_AdoptsFilesWithoutSidecar(t *testing.T) {
+	p := newTestPersister(t)
+
+	require.NoError(t, p.saveCollection("memories", nil))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("a")}))
+
+	// Files written by chromem-go itself have no sidecar
+	path := p.documentPath("memories", "a")
+	require.NoError(t, os.Remove(path+checksumSuffix))
+
+	_, report := loadTestDB(t, p)
+	assert.Equal(t, 1, report.Documents)
+	assert.FileExists(t, path+checksumSuffix)
+}
+
+func TestChromemPersister_ReadsChromemGoLayout(t *testing.T) {
+	dir := t.TempDir()
+
+	// Write with chromem-go's own persistence, then load with the persister
+	legacy, err := chromem.NewPersistentDB(dir, false)
+	require.NoError(t, err)
+	collection, err := legacy.CreateCollection("memories", nil, testEmbeddingFunc)
+	require.NoError(t, err)
+	require.NoError(t, collection.AddDocument(context.Background(), testDocument("a")))
+
+	p := newChromemPersister(dir, false, zap.NewNop())
+	db, report := loadTestDB(t, p)
+	assert.Equal(t, 1, report.Documents)
+	require.NotNil(t, db.GetCollection("memories", testEmbeddingFunc))
+}
+
+func TestChromemPersister_InterruptedWrite(t *testing.T) {
+	p := newTestPersister(t)
+
+	require.NoError(t, p.saveCollection("memories", nil))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("a")}))
+
+	path := p.documentPath("memories", "a")
+	oldData, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// A crash after the sidecar listed both checksums but before the data
+	// rename leaves the old file plus a temp file behind.
+	newDoc := testDocument("a")
+	newDoc.Content = "updated"
+	newData, err := p.encode(newDoc)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+checksumSuffix, formatChecksums([]string{checksum(newData), checksum(oldData)}), 0o600))
+	tmp := path + tempFileMarker + "123"
+	require.NoError(t, os.WriteFile(tmp, newData[:10], 0o600))
+
+	db, report := loadTestDB(t, p)
+	assert.Equal(t, 1, report.Documents)
+	assert.Empty(t, report.Recovered)
+	assert.NoFileExists(t, tmp)
+
+	doc, err := db.GetCollection("memories", testEmbeddingFunc).GetByID(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, "content for a", doc.Content)
+
+	sums, err := readChecksums(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{checksum(oldData)}, sums)
+}
+
+func TestChromemPersister_MissingMetadataQuarantinesCollection(t *testing.T) {
+	p := newTestPersister(t)
+
+	require.NoError(t, p.saveCollection("memories", nil))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("a")}))
+	require.NoError(t, os.Remove(filepath.Join(p.collectionDir("memories"), chromemMetadataFile+".gob")))
+
+	_, report := loadTestDB(t, p)
+	assert.Equal(t, 0, report.Collections)
+	assert.Equal(t, []string{hashName("memories")}, report.Quarantined)
+	assert.DirExists(t, filepath.Join(p.dir, quarantineDir, hashName("memories")))
+}
+
+func TestChromemPersister_DeleteDocument(t *testing.T) {
+	p := newTestPersister(t)
+
+	require.NoError(t, p.saveCollection("memories", nil))
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("a")}))
+	require.NoError(t, p.deleteDocument("memories", "a"))
+
+	path := p.documentPath("memories", "a")
+	assert.NoFileExists(t, path)
+	assert.NoFileExists(t, path+checksumSuffix)
+
+	// Deleting twice is not an error
+	require.NoError(t, p.deleteDocument("memories", "a"))
+}
+
+func TestWriteFileAtomic_LeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.gob")
+
+	require.NoError(t, writeFileAtomic(path, []byte("one")))
+	require.NoError(t, writeFileAtomic(path, []byte("two")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.False(t, strings.Contains(e.Name(), tempFileMarker), "leftover temp file %s", e.Name())
+	}
+}
+
+func TestChromemStore_PersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	config := ChromemConfig{Path: dir, VectorSize: 3, Isolation: NewNoIsolation()}
+	embedder := &MockEmbedder{embedding: []float32{1, 0, 0}}
+
+	store, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = store.AddDocuments(ctx, []Document{
+		{ID: "keep", Content: "keep me", Collection: "memories"},
+		{ID: "drop", Content: "drop me", Collection: "memories"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteDocumentsFromCollection(ctx, "memories", []string{"drop"}))
+	require.NoError(t, store.CreateCollection(ctx, "empty", 3))
+	require.NoError(t, store.Close())
+
+	reopened, err := NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+
+	info, err := reopened.GetCollectionInfo(ctx, "memories")
+	require.NoError(t, err)
+	assert.Equal(t, 1, info.PointCount)
+
+	exists, err := reopened.CollectionExists(ctx, "empty")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, reopened.DeleteCollection(ctx, "empty"))
+	reopened, err = NewChromemStore(config, embedder, zap.NewNop())
+	require.NoError(t, err)
+	exists, err = reopened.CollectionExists(ctx, "empty")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,184 @@
+package vectorstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	chromem "github.com/philippgille/chromem-go"
+	"go.uber.org/zap"
+)
+
+// SchemaVersionKey is the collection metadata key recording the layout a
+// chromem collection was written with. Collections without it predate
+// versioning and are treated as version 0.
+const SchemaVersionKey = "contextd_schema_version"
+
+// CurrentSchemaVersion is the layout version new collections are written with.
+const CurrentSchemaVersion = 1
+
+// schemaBackupDir holds the original files of upgraded collections, under
+// <collection hash>/v<from version>-<unix nanos>/.
+const schemaBackupDir = ".backups"
+
+// schemaMigration upgrades a collection from Version-1 to Version.
+//
+// Steps must be idempotent: the version marker is written after each step,
+// so a step interrupted part way runs again in full on the next start.
+type schemaMigration struct {
+	Version     int
+	Description string
+
+	// Document rewrites one document in place and reports whether it
+	// changed. Nil means the step only bumps the version marker.
+	Document func(doc *chromem.Document) bool
+}
+
+// schemaMigrations lists every upgrade step in version order. When the
+// document layout changes, such as a renamed metadata key, append a step
+// whose Document rewrites the old layout and bump CurrentSchemaVersion.
+var schemaMigrations = []schemaMigration{
+	{Version: 1, Description: "add schema version marker"},
+}
+
+// schemaVersion reads the version marker from collection metadata.
+func schemaVersion(metadata map[string]string) (int, error) {
+	raw, ok := metadata[SchemaVersionKey]
+	if !ok {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid schema version %q", raw)
+	}
+	return version, nil
+}
+
+// needsUpgrade reports whether upgradeCollection has work or a problem to
+// report for meta's collection.
+func (p *chromemPersister) needsUpgrade(meta *persistedCollection) bool {
+	from, err := schemaVersion(meta.Metadata)
+	if err != nil {
+		return true
+	}
+	for _, m := range p.migrations {
+		if m.Version > from {
+			return true
+		}
+	}
+	return false
+}
+
+// upgradeCollection migrates a loaded collection to the newest version the
+// persister knows, rewriting changed documents and then the version marker.
+// Files are copied to the backup directory before they are overwritten.
+//
+// A failed upgrade is logged and the collection loads as far as it got; the
+// next start retries it. Reports whether the collection was upgraded.
+func (p *chromemPersister) upgradeCollection(hash string, meta *persistedCollection, docs []chromem.Document) bool {
+	target := 0
+	for _, m := range p.migrations {
+		if m.Version > target {
+			target = m.Version
+		}
+	}
+
+	from, err := schemaVersion(meta.Metadata)
+	if err != nil {
+		p.logger.Error("skipping schema upgrade",
+			zap.String("collection", meta.Name),
+			zap.Error(err))
+		return false
+	}
+	if from > target {
+		p.logger.Warn("collection was written by a newer contextd",
+			zap.String("collection", meta.Name),
+			zap.Int("schema_version", from),
+			zap.Int("supported_version", target))
+		return false
+	}
+	if from == target {
+		return false
+	}
+
+	backup := filepath.Join(p.dir, schemaBackupDir, hash, fmt.Sprintf("v%d-%d", from, timeNow().UnixNano()))
+	if err := p.upgradeDocuments(hash, meta, docs, from, backup); err != nil {
+		p.logger.Error("schema upgrade failed, will retry on next start",
+			zap.String("collection", meta.Name),
+			zap.Int("from_version", from),
+			zap.String("backup_path", backup),
+			zap.Error(err))
+		return false
+	}
+
+	p.logger.Info("upgraded collection schema",
+		zap.String("collection", meta.Name),
+		zap.Int("from_version", from),
+		zap.Int("to_version", target),
+		zap.String("backup_path", backup))
+	return true
+}
+
+func (p *chromemPersister) upgradeDocuments(hash string, meta *persistedCollection, docs []chromem.Document, from int, backup string) error {
+	if err := os.MkdirAll(backup, 0o700); err != nil {
+		return fmt.Errorf("creating backup directory: %w", err)
+	}
+	if err := copyFile(p.metadataPath(meta.Name), filepath.Join(backup, chromemMetadataFile+p.ext())); err != nil {
+		return fmt.Errorf("backing up collection metadata: %w", err)
+	}
+
+	for _, m := range p.migrations {
+		if m.Version <= from {
+			continue
+		}
+
+		if m.Document != nil {
+			for i := range docs {
+				if docs[i].Metadata == nil {
+					docs[i].Metadata = map[string]string{}
+				}
+				if !m.Document(&docs[i]) {
+					continue
+				}
+
+				path := p.documentPath(meta.Name, docs[i].ID)
+				dst := filepath.Join(backup, filepath.Base(path))
+				if _, err := os.Stat(dst); errors.Is(err, os.ErrNotExist) {
+					if err := copyFile(path, dst); err != nil {
+						return fmt.Errorf("backing up document %s: %w", docs[i].ID, err)
+					}
+				}
+				if err := p.saveDocuments(meta.Name, docs[i:i+1]); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Record progress after each step so a later failure does not rerun
+		// steps that completed.
+		if meta.Metadata == nil {
+			meta.Metadata = map[string]string{}
+		}
+		meta.Metadata[SchemaVersionKey] = strconv.Itoa(m.Version)
+		if err := p.writeCollection(meta.Name, meta.Metadata); err != nil {
+			return fmt.Errorf("writing schema version %d: %w", m.Version, err)
+		}
+		p.logger.Debug("applied schema migration",
+			zap.String("collection", meta.Name),
+			zap.String("collection_hash", hash),
+			zap.Int("version", m.Version),
+			zap.String("description", m.Description))
+	}
+	return nil
+}
+
+// copyFile copies src to dst; backups are plain files without sidecars.
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
@@ -0,0 +1,169 @@
+package vectorstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	chromem "github.com/philippgille/chromem-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLegacyCollection writes a collection without a schema version marker.
+func writeLegacyCollection(t *testing.T, p *chromemPersister, name string, docs ...chromem.Document) {
+	t.Helper()
+	require.NoError(t, p.writeCollection(name, nil))
+	require.NoError(t, p.saveDocuments(name, docs))
+}
+
+// persistedMetadata reads collection metadata back from disk.
+func persistedMetadata(t *testing.T, p *chromemPersister, name string) map[string]string {
+	t.Helper()
+	data, err := os.ReadFile(p.metadataPath(name))
+	require.NoError(t, err)
+	var pc persistedCollection
+	require.NoError(t, decode(data, &pc))
+	return pc.Metadata
+}
+
+func TestChromemPersister_NewCollectionsAreStamped(t *testing.T) {
+	p := newTestPersister(t)
+	require.NoError(t, p.saveCollection("memories", map[string]string{"k": "v"}))
+
+	_, report := loadTestDB(t, p)
+	assert.Empty(t, report.Upgraded)
+
+	metadata := persistedMetadata(t, p, "memories")
+	assert.Equal(t, strconv.Itoa(CurrentSchemaVersion), metadata[SchemaVersionKey])
+	assert.Equal(t, "v", metadata["k"])
+}
+
+func TestChromemPersister_UpgradesLegacyCollection(t *testing.T) {
+	p := newTestPersister(t)
+	writeLegacyCollection(t, p, "memories", testDocument("a"))
+
+	_, report := loadTestDB(t, p)
+	assert.Equal(t, []string{"memories"}, report.Upgraded)
+	assert.Equal(t, strconv.Itoa(CurrentSchemaVersion), persistedMetadata(t, p, "memories")[SchemaVersionKey])
+
+	// The marker is persisted, so the next load does nothing
+	_, report = loadTestDB(t, p)
+	assert.Empty(t, report.Upgraded)
+	assert.Equal(t, 1, report.Documents)
+}
+
+func TestChromemPersister_MigratesDocuments(t *testing.T) {
+	p := newTestPersister(t)
+	p.migrations = []schemaMigration{
+		{Version: 1, Description: "add schema version marker"},
+		{Version: 2, Description: "rename tenant key", Document: func(doc *chromem.Document) bool {
+			value, ok := doc.Metadata["tenant"]
+			if !ok {
+				return false
+			}
+			doc.Metadata["tenant_id"] = value
+			delete(doc.Metadata, "tenant")
+			return true
+		}},
+		{Version: 3, Description: "rename tenant", Document: func(doc *chromem.Document) bool {
+			if doc.Metadata["tenant_id"] != "old-org" {
+				return false
+			}
+			doc.Metadata["tenant_id"] = "new-org"
+			return true
+		}},
+	}
+
+	legacy := testDocument("legacy")
+	legacy.Metadata = map[string]string{"tenant": "old-org"}
+	current := testDocument("current")
+	current.Metadata = map[string]string{"tenant_id": "other-org"}
+	writeLegacyCollection(t, p, "memories", legacy, current)
+
+	originalLegacy, err := os.ReadFile(p.documentPath("memories", "legacy"))
+	require.NoError(t, err)
+
+	db, report := loadTestDB(t, p)
+	assert.Equal(t, []string{"memories"}, report.Upgraded)
+
+	collection := db.GetCollection("memories", testEmbeddingFunc)
+	assert.Equal(t, "3", persistedMetadata(t, p, "memories")[SchemaVersionKey])
+
+	doc, err := collection.GetByID(context.Background(), "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"tenant_id": "new-org"}, doc.Metadata)
+
+	doc, err = collection.GetByID(context.Background(), "current")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"tenant_id": "other-org"}, doc.Metadata)
+
+	// Only the rewritten document and the metadata were backed up
+	backups, err := filepath.Glob(filepath.Join(p.dir, schemaBackupDir, hashName("memories"), "v0-*"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	files, err := os.ReadDir(backups[0])
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	backedUp, err := os.ReadFile(filepath.Join(backups[0], filepath.Base(p.documentPath("memories", "legacy"))))
+	require.NoError(t, err)
+	assert.Equal(t, originalLegacy, backedUp)
+
+	// The rewrite survives a reload
+	db, report = loadTestDB(t, p)
+	assert.Empty(t, report.Upgraded)
+	doc, err = db.GetCollection("memories", testEmbeddingFunc).GetByID(context.Background(), "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, "new-org", doc.Metadata["tenant_id"])
+}
+
+func TestChromemPersister_SkipsNewerSchema(t *testing.T) {
+	p := newTestPersister(t)
+	require.NoError(t, p.writeCollection("memories", map[string]string{SchemaVersionKey: "99"}))
+
+	_, report := loadTestDB(t, p)
+	assert.Empty(t, report.Upgraded)
+	assert.Equal(t, "99", persistedMetadata(t, p, "memories")[SchemaVersionKey])
+	assert.NoDirExists(t, filepath.Join(p.dir, schemaBackupDir))
+}
+
+func TestChromemPersister_SkipsInvalidSchema(t *testing.T) {
+	p := newTestPersister(t)
+	require.NoError(t, p.writeCollection("memories", map[string]string{SchemaVersionKey: "not-a-number"}))
+
+	_, report := loadTestDB(t, p)
+	assert.Empty(t, report.Upgraded)
+	assert.Equal(t, 1, report.Collections)
+}
+
+func TestChromemPersister_UpgradesCollectionInUnhashedDirectory(t *testing.T) {
+	p := newTestPersister(t)
+	writeLegacyCollection(t, p, "memories", testDocument("a"))
+
+	// migrate-collection names copied directories after a timestamp
+	require.NoError(t, os.Rename(p.collectionDir("memories"), filepath.Join(p.dir, "mig12345")))
+
+	_, report := loadTestDB(t, p)
+	assert.Equal(t, []string{"memories"}, report.Upgraded)
+	assert.NoDirExists(t, filepath.Join(p.dir, hashName("memories")))
+
+	// Later writes go to the directory the collection was loaded from
+	require.NoError(t, p.saveDocuments("memories", []chromem.Document{testDocument("b")}))
+	assert.FileExists(t, filepath.Join(p.dir, "mig12345", hashName("b")+".gob"))
+}
+
+func TestSchemaVersion(t *testing.T) {
+	v, err := schemaVersion(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, v)
+
+	v, err = schemaVersion(map[string]string{SchemaVersionKey: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	_, err = schemaVersion(map[string]string{SchemaVersionKey: "-1"})
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package vectorstore_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -272,6 +274,72 @@ func TestChromemStore_SearchInCollection_NotFound(t *testing.T) {
 	assert.ErrorIs(t, err, vectorstore.ErrCollectionNotFound)
 }
 
+func TestChromemStore_ScrollCollection(t *testing.T) {
+	store, tmpDir := newTestChromemStore(t)
+	defer os.RemoveAll(tmpDir)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	docs := make([]vectorstore.Document, 0, 7)
+	for i := 0; i < 7; i++ {
+		owner := "alice"
+		if i%2 == 1 {
+			owner = "bob"
+		}
+		docs = append(docs, vectorstore.Document{
+			ID:         fmt.Sprintf("doc%d", i),
+			Content:    fmt.Sprintf("Scroll document %d", i),
+			Metadata:   map[string]interface{}{"owner": owner},
+			Collection: "scroll_collection",
+		})
+	}
+	_, err := store.AddDocuments(ctx, docs)
+	require.NoError(t, err)
+
+	var batchSizes []int
+	seen := make(map[string]bool)
+	err = store.ScrollCollection(ctx, "scroll_collection", 3, nil, func(batch []vectorstore.SearchResult) error {
+		batchSizes = append(batchSizes, len(batch))
+		for _, r := range batch {
+			seen[r.ID] = true
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 3, 1}, batchSizes)
+	assert.Len(t, seen, 7)
+
+	t.Run("filters", func(t *testing.T) {
+		count := 0
+		err := store.ScrollCollection(ctx, "scroll_collection", 10, map[string]interface{}{"owner": "bob"}, func(batch []vectorstore.SearchResult) error {
+			for _, r := range batch {
+				assert.Equal(t, "bob", r.Metadata["owner"])
+			}
+			count += len(batch)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("callback error stops iteration", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := store.ScrollCollection(ctx, "scroll_collection", 2, nil, func([]vectorstore.SearchResult) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		err := store.ScrollCollection(ctx, "nonexistent", 2, nil, func([]vectorstore.SearchResult) error { return nil })
+		assert.ErrorIs(t, err, vectorstore.ErrCollectionNotFound)
+	})
+}
+
 func TestChromemStore_SearchWithFilters(t *testing.T) {
 	store, tmpDir := newTestChromemStore(t)
 	defer os.RemoveAll(tmpDir)
@@ -0,0 +1,75 @@
+package vectorstore_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore/storetest"
+)
+
+func TestChromemStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, embedder vectorstore.Embedder) vectorstore.Store {
+		store, err := vectorstore.NewChromemStore(vectorstore.ChromemConfig{
+			Path:       t.TempDir(),
+			VectorSize: storetest.VectorSize,
+		}, embedder, zap.NewNop())
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+func TestQdrantStore_Conformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	// Requires Qdrant on localhost:6334; skipped when it is not running.
+	probe, err := vectorstore.NewQdrantStore(vectorstore.QdrantConfig{
+		Host:           "localhost",
+		Port:           6334,
+		CollectionName: "storetest_default",
+		VectorSize:     storetest.VectorSize,
+	}, storetest.Embedder{})
+	if err != nil {
+		t.Skipf("Qdrant not available: %v", err)
+	}
+	probe.Close()
+
+	storetest.Run(t, func(t *testing.T, embedder vectorstore.Embedder) vectorstore.Store {
+		store, err := vectorstore.NewQdrantStore(vectorstore.QdrantConfig{
+			Host:           "localhost",
+			Port:           6334,
+			CollectionName: "storetest_default",
+			VectorSize:     storetest.VectorSize,
+		}, embedder)
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+func TestPgvectorStore_Conformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	dsn := os.Getenv("CONTEXTD_TEST_PGVECTOR_DSN")
+	if dsn == "" {
+		t.Skip("CONTEXTD_TEST_PGVECTOR_DSN not set")
+	}
+
+	storetest.Run(t, func(t *testing.T, embedder vectorstore.Embedder) vectorstore.Store {
+		store, err := vectorstore.NewPgvectorStore(vectorstore.PgvectorConfig{
+			DSN:        dsn,
+			VectorSize: storetest.VectorSize,
+			BatchSize:  2,
+		}, embedder, zap.NewNop())
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
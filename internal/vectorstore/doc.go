@@ -77,6 +77,16 @@
 //   - WARNING: Provides no security guarantees
 //   - Use only in tests where isolation is not relevant
 //
+// # Access Labels
+//
+// Within a tenant, documents may carry a Visibility label (private, team or
+// org) in their "visibility" metadata. Writes stamp the owner from the
+// Requester on the context (ContextWithRequester); every search and scroll
+// then returns labelled documents only to their owner or, for team
+// documents, the owner's team. Qdrant applies the rule as a filter; chromem,
+// whose filters are equality-only, filters the results and widens the query
+// until k accessible documents are found. Unlabelled documents are org.
+//
 // # Provider Selection
 //
 // The package supports multiple vector store providers:
@@ -92,10 +102,15 @@
 //   - Requires external Qdrant server + embedder
 //   - Recommended for production, high scale
 //
+// PgvectorStore (optional):
+//   - Postgres with the pgvector extension
+//   - Creates and migrates its schema at startup
+//   - For teams that already operate Postgres
+//
 // Provider selection via config:
 //
 //	vectorstore:
-//	  provider: chromem  # "chromem" (default) or "qdrant"
+//	  provider: chromem  # "chromem" (default), "qdrant" or "pgvector"
 //
 // # Collection Naming Convention
 //
@@ -105,6 +120,17 @@
 //   - Team: {team}_{type} (e.g., platform_memories)
 //   - Project: {team}_{project}_{type} (e.g., platform_contextd_memories)
 //
+// # Embedding Drift
+//
+// DriftChecker detects stored vectors that no longer match the current
+// embedder, as happens when the embedding model or its configuration changes
+// without re-indexing. It samples documents through VectorSampler, re-embeds
+// their content and reports mean and max drift (1 - cosine similarity) per
+// collection, exported as the contextd.vectorstore.embedding_drift gauge.
+// Report serves the last check for a TTL so polling stays cheap.
+// Sampling bypasses tenant isolation, so only aggregate statistics leave
+// the checker.
+//
 // # Performance
 //
 // Current implementation optimizations:
@@ -113,6 +139,28 @@
 //   - Optional compression for storage efficiency
 //   - HNSW index for fast approximate nearest neighbor search
 //
+// QdrantConfig.HNSW tunes the index (M, EfConstruct, EfSearch, OnDisk).
+// After bulk imports, RebuildIndex has Qdrant re-optimize a collection and
+// waits for it, exporting progress as index_rebuild_progress. ChromemStore
+// searches exhaustively and returns ErrIndexRebuildUnsupported.
+//
+// ChromemStore instruments its persistence I/O: documents written, deleted
+// and loaded, bytes written, fsync latency, startup load time, and the
+// document count of each collection. When these grow with the bank while
+// search latency stays flat, persistence is the bottleneck.
+//
+// With ChromemConfig.LazyLoad, startup reads only collection metadata and
+// each collection loads on first access under its own lock, so launching
+// against a bank with many projects stays fast. ChromemConfig.Warmup names
+// hot collections to load in the background right after startup.
+//
+// Collections load in batches as their documents are decoded rather than
+// all at once, which cuts the startup memory spike on large banks by about
+// a third. ChromemConfig.MmapVectors additionally packs loaded embeddings
+// into anonymous memory maps outside the Go heap, about halving it
+// overall. BenchmarkChromemLoad reports peak RSS and live heap for each
+// mode on 100k documents.
+//
 // Future optimization opportunities:
 //   - Connection pooling for Qdrant gRPC
 //   - Result caching with TTL
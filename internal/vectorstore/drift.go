@@ -0,0 +1,319 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultDriftSampleSize is the number of documents sampled per collection.
+	DefaultDriftSampleSize = 20
+
+	// DefaultDriftThreshold is the mean drift above which a collection is
+	// reported as drifted. Re-embedding with the same model gives drift near
+	// zero; a different model or preprocessing gives drift well above it.
+	DefaultDriftThreshold = 0.05
+
+	// DefaultDriftCacheTTL is how long Report serves the last check before
+	// checking again.
+	DefaultDriftCacheTTL = 10 * time.Minute
+)
+
+// DriftConfig configures embedding drift checks.
+type DriftConfig struct {
+	// SampleSize is the number of documents sampled per collection.
+	// Default: DefaultDriftSampleSize
+	SampleSize int
+
+	// Threshold is the mean drift (1 - cosine similarity) above which a
+	// collection is reported as drifted.
+	// Default: DefaultDriftThreshold
+	Threshold float64
+
+	// CacheTTL is how long Report serves the last check before checking
+	// again.
+	// Default: DefaultDriftCacheTTL
+	CacheTTL time.Duration
+}
+
+// ApplyDefaults sets default values for unset fields.
+func (c *DriftConfig) ApplyDefaults() {
+	if c.SampleSize <= 0 {
+		c.SampleSize = DefaultDriftSampleSize
+	}
+	if c.Threshold <= 0 {
+		c.Threshold = DefaultDriftThreshold
+	}
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = DefaultDriftCacheTTL
+	}
+}
+
+// CollectionDrift is the drift measured for one collection.
+type CollectionDrift struct {
+	Collection        string  `json:"collection"`
+	Sampled           int     `json:"sampled"`                      // Documents compared
+	MeanDrift         float64 `json:"mean_drift"`                   // Mean of 1 - cosine similarity
+	MaxDrift          float64 `json:"max_drift"`                    // Largest single-document drift
+	DimensionMismatch bool    `json:"dimension_mismatch,omitempty"` // Stored and current vectors differ in size
+	Drifted           bool    `json:"drifted"`                      // Mean drift above threshold or dimension mismatch
+	Error             string  `json:"error,omitempty"`              // Why the collection could not be checked
+}
+
+// DriftReport is the result of an embedding drift check.
+type DriftReport struct {
+	Status        string            `json:"status"` // "ok" or "drifted"
+	Threshold     float64           `json:"threshold"`
+	Sampled       int               `json:"sampled"`    // Documents compared across all collections
+	MeanDrift     float64           `json:"mean_drift"` // Mean drift across all sampled documents
+	MaxDrift      float64           `json:"max_drift"`
+	Collections   []CollectionDrift `json:"collections"`
+	LastCheckTime time.Time         `json:"last_check_time"`
+	CheckDuration time.Duration     `json:"check_duration"`
+}
+
+// IsDrifted returns true if any collection drifted.
+func (r *DriftReport) IsDrifted() bool {
+	return r.Status == "drifted"
+}
+
+// DriftChecker detects embedding drift: stored vectors that no longer match
+// what the current embedder produces for the same content. Drift appears
+// when the embedding model or its configuration changes without
+// re-indexing, and silently degrades search because queries and documents
+// are embedded in different spaces.
+//
+// A check samples documents from every collection, re-embeds their content
+// and compares the result with the stored vector. Only aggregate statistics
+// are reported, never the sampled content.
+type DriftChecker struct {
+	store    Store
+	embedder Embedder
+	config   DriftConfig
+	logger   *zap.Logger
+
+	mu   sync.RWMutex
+	last *DriftReport
+
+	// checkMu serializes checks, so concurrent callers of Report wait for
+	// one check instead of each re-embedding samples
+	checkMu sync.Mutex
+
+	driftGauge metric.Float64ObservableGauge
+}
+
+// NewDriftChecker creates a drift checker for store, re-embedding sampled
+// content with embedder. The store must implement VectorSampler.
+func NewDriftChecker(store Store, embedder Embedder, config DriftConfig, logger *zap.Logger) (*DriftChecker, error) {
+	if store == nil {
+		return nil, errors.New("store is required")
+	}
+	if embedder == nil {
+		return nil, errors.New("embedder is required")
+	}
+	if _, ok := store.(VectorSampler); !ok {
+		return nil, ErrSamplingUnsupported
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	config.ApplyDefaults()
+
+	c := &DriftChecker{
+		store:    store,
+		embedder: embedder,
+		config:   config,
+		logger:   logger,
+	}
+	c.initMetrics(otel.Meter(vectorstoreInstrumentationName))
+	return c, nil
+}
+
+// initMetrics registers a gauge reporting the mean drift of each collection
+// from the last check.
+func (c *DriftChecker) initMetrics(meter metric.Meter) {
+	var err error
+	c.driftGauge, err = meter.Float64ObservableGauge(
+		"contextd.vectorstore.embedding_drift",
+		metric.WithDescription("Mean drift (1 - cosine similarity) between stored vectors and fresh embeddings of the same content, by collection, from the last drift check. Near zero when the embedding model is unchanged."),
+		metric.WithUnit("1"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			report := c.LastReport()
+			if report == nil {
+				return nil
+			}
+			for _, col := range report.Collections {
+				if col.Sampled > 0 {
+					o.Observe(col.MeanDrift, metric.WithAttributes(attribute.String("collection", col.Collection)))
+				}
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		c.logger.Warn("failed to create embedding drift gauge", zap.Error(err))
+	}
+}
+
+// LastReport returns the report of the last completed check, or nil.
+func (c *DriftChecker) LastReport() *DriftReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.last
+}
+
+// Report returns the last report while it is younger than the configured
+// cache TTL, and otherwise runs a check.
+func (c *DriftChecker) Report(ctx context.Context) (*DriftReport, error) {
+	c.checkMu.Lock()
+	defer c.checkMu.Unlock()
+
+	if last := c.LastReport(); last != nil && time.Since(last.LastCheckTime) < c.config.CacheTTL {
+		return last, nil
+	}
+	return c.check(ctx)
+}
+
+// Check samples every collection and measures drift. Collections that
+// cannot be sampled or embedded are reported with an error rather than
+// failing the check.
+func (c *DriftChecker) Check(ctx context.Context) (*DriftReport, error) {
+	c.checkMu.Lock()
+	defer c.checkMu.Unlock()
+	return c.check(ctx)
+}
+
+// check runs a check. The caller must hold checkMu.
+func (c *DriftChecker) check(ctx context.Context) (*DriftReport, error) {
+	start := time.Now()
+
+	names, err := c.store.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+	sort.Strings(names)
+
+	report := &DriftReport{
+		Status:        "ok",
+		Threshold:     c.config.Threshold,
+		Collections:   make([]CollectionDrift, 0, len(names)),
+		LastCheckTime: start,
+	}
+	var total float64
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		col, drifts := c.checkCollection(ctx, name)
+		report.Collections = append(report.Collections, col)
+		for _, d := range drifts {
+			total += d
+			report.MaxDrift = math.Max(report.MaxDrift, d)
+		}
+		report.Sampled += len(drifts)
+		if col.Drifted {
+			report.Status = "drifted"
+			c.logger.Warn("embedding drift detected",
+				zap.String("collection", name),
+				zap.Float64("mean_drift", col.MeanDrift),
+				zap.Float64("max_drift", col.MaxDrift),
+				zap.Bool("dimension_mismatch", col.DimensionMismatch))
+		}
+	}
+	if report.Sampled > 0 {
+		report.MeanDrift = total / float64(report.Sampled)
+	}
+	report.CheckDuration = time.Since(start)
+
+	c.mu.Lock()
+	c.last = report
+	c.mu.Unlock()
+
+	c.logger.Info("embedding drift check completed",
+		zap.String("status", report.Status),
+		zap.Int("collections", len(report.Collections)),
+		zap.Int("sampled", report.Sampled),
+		zap.Float64("mean_drift", report.MeanDrift),
+		zap.Duration("duration", report.CheckDuration))
+
+	return report, nil
+}
+
+// checkCollection measures drift for one collection and returns the drift
+// of each sampled document.
+func (c *DriftChecker) checkCollection(ctx context.Context, name string) (CollectionDrift, []float64) {
+	col := CollectionDrift{Collection: name}
+
+	samples, err := Sample(ctx, c.store, name, c.config.SampleSize)
+	if err != nil {
+		col.Error = err.Error()
+		return col, nil
+	}
+
+	// Documents without content or vector cannot be compared
+	texts := make([]string, 0, len(samples))
+	stored := make([][]float32, 0, len(samples))
+	for _, s := range samples {
+		if s.Content != "" && len(s.Vector) > 0 {
+			texts = append(texts, s.Content)
+			stored = append(stored, s.Vector)
+		}
+	}
+	if len(texts) == 0 {
+		return col, nil
+	}
+
+	fresh, err := c.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		col.Error = fmt.Sprintf("embedding samples: %v", err)
+		return col, nil
+	}
+	if len(fresh) != len(texts) {
+		col.Error = fmt.Sprintf("embedder returned %d vectors for %d samples", len(fresh), len(texts))
+		return col, nil
+	}
+
+	drifts := make([]float64, len(texts))
+	var total float64
+	for i := range texts {
+		if len(fresh[i]) != len(stored[i]) {
+			// Vectors from a model with another dimension are not comparable
+			col.DimensionMismatch = true
+			drifts[i] = 1
+		} else {
+			drifts[i] = 1 - cosineSimilarity(stored[i], fresh[i])
+		}
+		total += drifts[i]
+		col.MaxDrift = math.Max(col.MaxDrift, drifts[i])
+	}
+	col.Sampled = len(drifts)
+	col.MeanDrift = total / float64(len(drifts))
+	col.Drifted = col.DimensionMismatch || col.MeanDrift > c.config.Threshold
+	return col, drifts
+}
+
+// cosineSimilarity returns the cosine similarity of two equal-length
+// vectors, or 0 if either is a zero vector.
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,229 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+// driftTestEmbedder derives vectors from text; changing model simulates
+// switching to another embedding model of the same dimension.
+type driftTestEmbedder struct {
+	model int
+	dim   int
+	err   error
+}
+
+func (e *driftTestEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		hash := 0
+		for _, c := range text {
+			hash = (hash*31 + int(c)) % 997
+		}
+		vec := make([]float32, e.dim)
+		for j := range vec {
+			vec[j] = float32((hash*(e.model+1)+j*7*(e.model+3))%101) - 50
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func (e *driftTestEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vecs, err := e.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+func newDriftTestStore(t *testing.T, embedder Embedder) *ChromemStore {
+	t.Helper()
+
+	store, err := NewChromemStore(ChromemConfig{
+		Path:              t.TempDir(),
+		DefaultCollection: "drift_default",
+		VectorSize:        16,
+		Isolation:         NewNoIsolation(),
+	}, embedder, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	docs := make([]Document, 0, 30)
+	for i := 0; i < 30; i++ {
+		docs = append(docs, Document{
+			ID:         fmt.Sprintf("doc%d", i),
+			Content:    fmt.Sprintf("stored document number %d", i),
+			Collection: "drift_memories",
+		})
+	}
+	_, err = store.AddDocuments(context.Background(), docs)
+	require.NoError(t, err)
+	return store
+}
+
+func TestChromemStore_SampleVectors(t *testing.T) {
+	store := newDriftTestStore(t, &driftTestEmbedder{dim: 16})
+	ctx := context.Background()
+
+	samples, err := store.SampleVectors(ctx, "drift_memories", 10)
+	require.NoError(t, err)
+	require.Len(t, samples, 10)
+	ids := make(map[string]bool)
+	for _, s := range samples {
+		ids[s.ID] = true
+		assert.NotEmpty(t, s.Content)
+		assert.Len(t, s.Vector, 16)
+	}
+	assert.Len(t, ids, 10, "samples are distinct documents")
+
+	samples, err = store.SampleVectors(ctx, "drift_memories", 100)
+	require.NoError(t, err)
+	assert.Len(t, samples, 30, "small collections are sampled whole")
+
+	_, err = store.SampleVectors(ctx, "missing_collection", 10)
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+	_, err = store.SampleVectors(ctx, "drift_memories", 0)
+	assert.Error(t, err)
+}
+
+func TestDriftChecker_Check(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unchanged model", func(t *testing.T) {
+		embedder := &driftTestEmbedder{dim: 16}
+		checker, err := NewDriftChecker(newDriftTestStore(t, embedder), embedder, DriftConfig{SampleSize: 5}, zap.NewNop())
+		require.NoError(t, err)
+
+		report, err := checker.Check(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", report.Status)
+		assert.False(t, report.IsDrifted())
+		assert.Equal(t, DefaultDriftThreshold, report.Threshold)
+		assert.Equal(t, 5, report.Sampled)
+		assert.InDelta(t, 0, report.MaxDrift, 1e-5)
+		require.Len(t, report.Collections, 1)
+		assert.Equal(t, "drift_memories", report.Collections[0].Collection)
+		assert.Same(t, report, checker.LastReport())
+	})
+
+	t.Run("changed model", func(t *testing.T) {
+		embedder := &driftTestEmbedder{dim: 16}
+		store := newDriftTestStore(t, embedder)
+		checker, err := NewDriftChecker(store, &driftTestEmbedder{model: 1, dim: 16}, DriftConfig{}, zap.NewNop())
+		require.NoError(t, err)
+
+		report, err := checker.Check(ctx)
+		require.NoError(t, err)
+		assert.True(t, report.IsDrifted())
+		col := report.Collections[0]
+		assert.True(t, col.Drifted)
+		assert.False(t, col.DimensionMismatch)
+		assert.Greater(t, col.MeanDrift, DefaultDriftThreshold)
+		assert.GreaterOrEqual(t, col.MaxDrift, col.MeanDrift)
+	})
+
+	t.Run("changed dimension", func(t *testing.T) {
+		store := newDriftTestStore(t, &driftTestEmbedder{dim: 16})
+		checker, err := NewDriftChecker(store, &driftTestEmbedder{dim: 8}, DriftConfig{}, zap.NewNop())
+		require.NoError(t, err)
+
+		report, err := checker.Check(ctx)
+		require.NoError(t, err)
+		col := report.Collections[0]
+		assert.True(t, col.DimensionMismatch)
+		assert.True(t, col.Drifted)
+		assert.Equal(t, 1.0, col.MeanDrift)
+	})
+
+	t.Run("embedding failure is reported per collection", func(t *testing.T) {
+		store := newDriftTestStore(t, &driftTestEmbedder{dim: 16})
+		checker, err := NewDriftChecker(store, &driftTestEmbedder{dim: 16, err: errors.New("provider down")}, DriftConfig{}, zap.NewNop())
+		require.NoError(t, err)
+
+		report, err := checker.Check(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", report.Status)
+		assert.Contains(t, report.Collections[0].Error, "provider down")
+		assert.Zero(t, report.Sampled)
+	})
+}
+
+func TestDriftChecker_Report(t *testing.T) {
+	embedder := &driftTestEmbedder{dim: 16}
+	ctx := context.Background()
+
+	t.Run("serves the last check within the TTL", func(t *testing.T) {
+		checker, err := NewDriftChecker(newDriftTestStore(t, embedder), embedder, DriftConfig{}, zap.NewNop())
+		require.NoError(t, err)
+
+		first, err := checker.Report(ctx)
+		require.NoError(t, err)
+		second, err := checker.Report(ctx)
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+	})
+
+	t.Run("checks again after the TTL", func(t *testing.T) {
+		checker, err := NewDriftChecker(newDriftTestStore(t, embedder), embedder, DriftConfig{CacheTTL: time.Nanosecond}, zap.NewNop())
+		require.NoError(t, err)
+
+		first, err := checker.Report(ctx)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		second, err := checker.Report(ctx)
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+	})
+}
+
+func TestNewDriftChecker_Validation(t *testing.T) {
+	embedder := &driftTestEmbedder{dim: 16}
+	store := newDriftTestStore(t, embedder)
+
+	_, err := NewDriftChecker(nil, embedder, DriftConfig{}, nil)
+	assert.Error(t, err)
+	_, err = NewDriftChecker(store, nil, DriftConfig{}, nil)
+	assert.Error(t, err)
+	_, err = NewDriftChecker(&FailingStore{Store: store}, embedder, DriftConfig{}, nil)
+	assert.ErrorIs(t, err, ErrSamplingUnsupported)
+}
+
+func TestDriftChecker_Gauge(t *testing.T) {
+	embedder := &driftTestEmbedder{dim: 16}
+	checker, err := NewDriftChecker(newDriftTestStore(t, embedder), &driftTestEmbedder{model: 1, dim: 16}, DriftConfig{}, zap.NewNop())
+	require.NoError(t, err)
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	checker.initMetrics(mp.Meter(vectorstoreInstrumentationName))
+
+	ctx := context.Background()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	assert.Empty(t, rm.ScopeMetrics, "no observations before the first check")
+
+	report, err := checker.Check(ctx)
+	require.NoError(t, err)
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "contextd.vectorstore.embedding_drift", m.Name)
+	gauge, ok := m.Data.(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, gauge.DataPoints, 1)
+	assert.Equal(t, report.Collections[0].MeanDrift, gauge.DataPoints[0].Value)
+}
@@ -30,6 +30,7 @@ func WithIsolation(mode IsolationMode) StoreOption {
 // creates the appropriate store implementation:
 //   - "chromem" (default): Creates an embedded ChromemStore (no external deps)
 //   - "qdrant": Creates a QdrantStore (requires external Qdrant server)
+//   - "pgvector": Creates a PgvectorStore (requires Postgres with pgvector)
 //
 // If fallback is enabled, the store is wrapped with FallbackStore for graceful
 // degradation when the remote store (Qdrant) is unavailable.
@@ -67,6 +68,9 @@ func NewStore(cfg *config.Config, embedder Embedder, logger *zap.Logger, opts ..
 			Compress:          cfg.VectorStore.Chromem.Compress,
 			DefaultCollection: cfg.VectorStore.Chromem.DefaultCollection,
 			VectorSize:        cfg.VectorStore.Chromem.VectorSize,
+			LazyLoad:          cfg.VectorStore.Chromem.LazyLoad,
+			Warmup:            cfg.VectorStore.Chromem.Warmup,
+			MmapVectors:       cfg.VectorStore.Chromem.MmapVectors,
 		}
 		store, err = NewChromemStore(chromemCfg, embedder, logger)
 
@@ -77,6 +81,12 @@ func NewStore(cfg *config.Config, embedder Embedder, logger *zap.Logger, opts ..
 			Port:           cfg.Qdrant.Port,
 			CollectionName: cfg.Qdrant.CollectionName,
 			VectorSize:     cfg.Qdrant.VectorSize,
+			HNSW: HNSWConfig{
+				M:           cfg.Qdrant.HNSWM,
+				EfConstruct: cfg.Qdrant.HNSWEfConstruct,
+				EfSearch:    cfg.Qdrant.HNSWEfSearch,
+				OnDisk:      cfg.Qdrant.HNSWOnDisk,
+			},
 		}
 
 		// Check if fallback is enabled
@@ -173,8 +183,19 @@ func NewStore(cfg *config.Config, embedder Embedder, logger *zap.Logger, opts ..
 			store, err = NewQdrantStore(qdrantCfg, embedder)
 		}
 
+	case "pgvector":
+		// pgvector: requires Postgres with the vector extension available
+		pgCfg := cfg.VectorStore.Pgvector
+		store, err = NewPgvectorStore(PgvectorConfig{
+			DSN:               pgCfg.DSN.Value(),
+			DefaultCollection: pgCfg.DefaultCollection,
+			VectorSize:        pgCfg.VectorSize,
+			BatchSize:         pgCfg.BatchSize,
+			EfSearch:          pgCfg.EfSearch,
+		}, embedder, logger)
+
 	default:
-		return nil, fmt.Errorf("unsupported vectorstore provider: %s (supported: chromem, qdrant)", cfg.VectorStore.Provider)
+		return nil, fmt.Errorf("unsupported vectorstore provider: %s (supported: chromem, qdrant, pgvector)", cfg.VectorStore.Provider)
 	}
 
 	if err != nil {
@@ -548,6 +548,54 @@ func (fs *FallbackStore) SearchInCollection(ctx context.Context, collectionName
 	return fs.local.SearchInCollection(ctx, collectionName, query, k, filters)
 }
 
+// ScrollCollection iterates a collection on the remote store when healthy,
+// otherwise on the local store.
+func (fs *FallbackStore) ScrollCollection(ctx context.Context, collectionName string, batchSize int, filters map[string]interface{}, fn func([]SearchResult) error) error {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	if fs.health.IsHealthy() {
+		return Scroll(ctx, fs.remote, collectionName, batchSize, filters, fn)
+	}
+	return Scroll(ctx, fs.local, collectionName, batchSize, filters, fn)
+}
+
+// SampleVectors samples a collection on the remote store when healthy,
+// otherwise on the local store.
+func (fs *FallbackStore) SampleVectors(ctx context.Context, collectionName string, n int) ([]StoredVector, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	if fs.health.IsHealthy() {
+		return Sample(ctx, fs.remote, collectionName, n)
+	}
+	return Sample(ctx, fs.local, collectionName, n)
+}
+
+// ScanDocuments scans a collection on the remote store when healthy,
+// otherwise on the local store.
+func (fs *FallbackStore) ScanDocuments(ctx context.Context, collectionName string, batchSize int, fn func([]ScannedDocument) error) error {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	if fs.health.IsHealthy() {
+		return Scan(ctx, fs.remote, collectionName, batchSize, fn)
+	}
+	return Scan(ctx, fs.local, collectionName, batchSize, fn)
+}
+
+// RebuildIndex rebuilds a collection's index on the remote store when
+// healthy. The local store has no index to rebuild.
+func (fs *FallbackStore) RebuildIndex(ctx context.Context, collectionName string) error {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	if fs.health.IsHealthy() {
+		return RebuildIndex(ctx, fs.remote, collectionName)
+	}
+	return RebuildIndex(ctx, fs.local, collectionName)
+}
+
 // DeleteDocuments deletes documents by their IDs.
 func (fs *FallbackStore) DeleteDocuments(ctx context.Context, ids []string) error {
 	tenant, err := fs.validateTenantContext(ctx)
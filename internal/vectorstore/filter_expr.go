@@ -0,0 +1,543 @@
+package vectorstore
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidFilterExpr indicates a filter expression that cannot be parsed.
+var ErrInvalidFilterExpr = errors.New("invalid filter expression")
+
+// Filter expression limits keep parsing and matching cheap for
+// user-supplied input.
+const (
+	maxFilterExprLength     = 1024
+	maxFilterExprConditions = 32
+	maxFilterExprDepth      = 8
+)
+
+// FilterExpr is a parsed query-time filter over document metadata.
+//
+// The syntax combines comparisons with AND, OR, NOT and parentheses:
+//
+//	confidence >= 0.7 AND outcome IN ('success', 'partial')
+//	(created_at > 2025-06-01 OR usage_count >= 5) AND NOT state = archived
+//
+// Comparisons are =, !=, <, <=, > and >=; field IN (...) and field NOT IN
+// (...) match any of a list. Values are numbers, quoted or bare strings,
+// true/false, or dates (2025-06-01 or RFC 3339), which compare as Unix
+// seconds like the created_at and updated_at metadata. Range comparisons
+// need a number or date. Keywords are case-insensitive.
+//
+// A document without the field never satisfies a comparison on it. List
+// metadata such as tags satisfies = and IN when any element does.
+type FilterExpr struct {
+	root filterNode
+}
+
+// ParseFilterExpr parses and validates a filter expression. Expressions on
+// tenant fields are rejected with ErrTenantFilterInUserFilters, since tenant
+// scoping comes from the isolation layer only.
+func ParseFilterExpr(input string) (*FilterExpr, error) {
+	if len(input) > maxFilterExprLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidFilterExpr, maxFilterExprLength)
+	}
+	tokens, err := lexFilterExpr(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidFilterExpr)
+	}
+
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilterExpr, p.peek().text)
+	}
+	return &FilterExpr{root: root}, nil
+}
+
+// Match reports whether metadata satisfies the expression. A nil expression
+// matches everything.
+func (e *FilterExpr) Match(metadata map[string]interface{}) bool {
+	if e == nil {
+		return true
+	}
+	return e.root.match(metadata)
+}
+
+// String returns the expression in canonical form, suitable for hashing.
+func (e *FilterExpr) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.root.String()
+}
+
+// filterNode is one node of a parsed filter expression.
+type filterNode interface {
+	match(metadata map[string]interface{}) bool
+	String() string
+}
+
+type filterAnd []filterNode
+
+func (n filterAnd) match(metadata map[string]interface{}) bool {
+	for _, c := range n {
+		if !c.match(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n filterAnd) String() string { return joinFilterNodes(n, " AND ") }
+
+type filterOr []filterNode
+
+func (n filterOr) match(metadata map[string]interface{}) bool {
+	for _, c := range n {
+		if c.match(metadata) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n filterOr) String() string { return joinFilterNodes(n, " OR ") }
+
+type filterNot struct{ node filterNode }
+
+func (n filterNot) match(metadata map[string]interface{}) bool { return !n.node.match(metadata) }
+
+func (n filterNot) String() string { return "NOT " + n.node.String() }
+
+func joinFilterNodes(nodes []filterNode, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, c := range nodes {
+		parts[i] = c.String()
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+// filterValue is a literal; num is set for numbers, dates and numeric strings.
+type filterValue struct {
+	text  string
+	num   float64
+	isNum bool
+}
+
+func (v filterValue) String() string {
+	if v.isNum {
+		return strconv.FormatFloat(v.num, 'g', -1, 64)
+	}
+	return strconv.Quote(v.text)
+}
+
+// equals compares a literal with one metadata value, numerically when both
+// are numbers.
+func (v filterValue) equals(value interface{}) bool {
+	if v.isNum {
+		if n, ok := filterNumber(value); ok {
+			return n == v.num
+		}
+		return false
+	}
+	return fmt.Sprint(value) == v.text
+}
+
+type filterCompare struct {
+	field string
+	op    string
+	value filterValue
+}
+
+func (n filterCompare) match(metadata map[string]interface{}) bool {
+	value, ok := metadata[n.field]
+	if !ok || value == nil {
+		return false
+	}
+	switch n.op {
+	case "=":
+		return anyFilterElement(value, n.value.equals)
+	case "!=":
+		return !anyFilterElement(value, n.value.equals)
+	}
+
+	num, ok := filterNumber(value)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return num < n.value.num
+	case "<=":
+		return num <= n.value.num
+	case ">":
+		return num > n.value.num
+	default:
+		return num >= n.value.num
+	}
+}
+
+func (n filterCompare) String() string {
+	return n.field + " " + n.op + " " + n.value.String()
+}
+
+type filterIn struct {
+	field  string
+	values []filterValue
+}
+
+func (n filterIn) match(metadata map[string]interface{}) bool {
+	value, ok := metadata[n.field]
+	if !ok || value == nil {
+		return false
+	}
+	for _, v := range n.values {
+		if anyFilterElement(value, v.equals) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n filterIn) String() string {
+	parts := make([]string, len(n.values))
+	for i, v := range n.values {
+		parts[i] = v.String()
+	}
+	return n.field + " IN (" + strings.Join(parts, ", ") + ")"
+}
+
+// anyFilterElement applies fn to value, or to each element of a list value.
+func anyFilterElement(value interface{}, fn func(interface{}) bool) bool {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return fn(value)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if fn(rv.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterNumber converts numeric metadata, including numbers stored as
+// strings by string-only stores such as chromem.
+func filterNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil && !math.IsNaN(n)
+	}
+	return 0, false
+}
+
+// filterToken is a lexed token; quoted strings are never keywords.
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+// is reports whether t is the unquoted keyword or symbol s.
+func (t filterToken) is(s string) bool {
+	return !t.quoted && strings.EqualFold(t.text, s)
+}
+
+func lexFilterExpr(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, filterToken{text: string(c)})
+			i++
+		case c == '<' || c == '>' || c == '!':
+			op := string(c)
+			if i+1 < len(input) && input[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("%w: '!' must be followed by '='", ErrInvalidFilterExpr)
+			}
+			tokens = append(tokens, filterToken{text: op})
+			i += len(op)
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(input[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilterExpr)
+			}
+			tokens = append(tokens, filterToken{text: input[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		default:
+			start := i
+			for i < len(input) && isFilterWordByte(input[i]) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilterExpr, string(c))
+			}
+			tokens = append(tokens, filterToken{text: input[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+func isFilterWordByte(c byte) bool {
+	return c < unicode.MaxASCII && (c == '_' || c == '.' || c == '-' || c == ':' || c == '+' ||
+		unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
+
+// filterParser is a recursive descent parser over lexed tokens.
+type filterParser struct {
+	tokens     []filterToken
+	pos        int
+	conditions int
+}
+
+func (p *filterParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	if p.done() {
+		return filterToken{}, fmt.Errorf("%w: unexpected end", ErrInvalidFilterExpr)
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) expect(s string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !t.is(s) {
+		return fmt.Errorf("%w: expected %q, got %q", ErrInvalidFilterExpr, s, t.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr(depth int) (filterNode, error) {
+	var nodes filterOr
+	for {
+		node, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.peek().is("OR") {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *filterParser) parseAnd(depth int) (filterNode, error) {
+	var nodes filterAnd
+	for {
+		node, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.peek().is("AND") {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *filterParser) parseNot(depth int) (filterNode, error) {
+	if p.peek().is("NOT") {
+		p.pos++
+		node, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{node: node}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *filterParser) parsePrimary(depth int) (filterNode, error) {
+	if p.peek().is("(") {
+		if depth >= maxFilterExprDepth {
+			return nil, fmt.Errorf("%w: nested deeper than %d", ErrInvalidFilterExpr, maxFilterExprDepth)
+		}
+		p.pos++
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	p.conditions++
+	if p.conditions > maxFilterExprConditions {
+		return nil, fmt.Errorf("%w: more than %d conditions", ErrInvalidFilterExpr, maxFilterExprConditions)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case op.is("IN"):
+		return p.parseIn(field)
+	case op.is("NOT"):
+		if err := p.expect("IN"); err != nil {
+			return nil, err
+		}
+		in, err := p.parseIn(field)
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{node: in}, nil
+	case op.quoted:
+		return nil, fmt.Errorf("%w: expected operator after %s, got %q", ErrInvalidFilterExpr, field, op.text)
+	}
+
+	switch op.text {
+	case "=", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("%w: expected operator after %s, got %q", ErrInvalidFilterExpr, field, op.text)
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if op.text != "=" && op.text != "!=" && !value.isNum {
+		return nil, fmt.Errorf("%w: %s %s needs a number or date, got %q", ErrInvalidFilterExpr, field, op.text, value.text)
+	}
+	return filterCompare{field: field, op: op.text, value: value}, nil
+}
+
+func (p *filterParser) parseField() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.quoted || !isFilterField(t.text) {
+		return "", fmt.Errorf("%w: expected field name, got %q", ErrInvalidFilterExpr, t.text)
+	}
+	field := strings.ToLower(t.text)
+	for _, key := range tenantFilterKeys {
+		if field == key {
+			return "", ErrTenantFilterInUserFilters
+		}
+	}
+	return field, nil
+}
+
+func isFilterField(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || r == '.' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return s != "" && !strings.EqualFold(s, "AND") && !strings.EqualFold(s, "OR") && !strings.EqualFold(s, "NOT")
+}
+
+func (p *filterParser) parseIn(field string) (filterNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var values []filterValue
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.is(")") {
+			return filterIn{field: field, values: values}, nil
+		}
+		if !t.is(",") {
+			return nil, fmt.Errorf("%w: expected ',' or ')' in IN list, got %q", ErrInvalidFilterExpr, t.text)
+		}
+	}
+}
+
+func (p *filterParser) parseValue() (filterValue, error) {
+	t, err := p.next()
+	if err != nil {
+		return filterValue{}, err
+	}
+	if !t.quoted {
+		switch t.text {
+		case "(", ")", ",", "=", "!=", "<", "<=", ">", ">=":
+			return filterValue{}, fmt.Errorf("%w: expected value, got %q", ErrInvalidFilterExpr, t.text)
+		}
+	}
+	return newFilterValue(t.text), nil
+}
+
+// newFilterValue classifies a literal. Dates become Unix seconds so they
+// compare with timestamp metadata.
+func newFilterValue(text string) filterValue {
+	if n, err := strconv.ParseFloat(text, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+		return filterValue{text: text, num: n, isNum: true}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if ts, err := time.Parse(layout, text); err == nil {
+			return filterValue{text: text, num: float64(ts.Unix()), isNum: true}
+		}
+	}
+	if strings.EqualFold(text, "true") || strings.EqualFold(text, "false") {
+		return filterValue{text: strings.ToLower(text)}
+	}
+	return filterValue{text: text}
+}
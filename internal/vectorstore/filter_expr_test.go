@@ -0,0 +1,106 @@
+package vectorstore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilterExpr_Match(t *testing.T) {
+	june := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC).Unix()
+
+	// Typed metadata as returned by Qdrant, and numbers stored as strings as
+	// chromem returns them (with a JSON-decoded list)
+	typed := map[string]interface{}{
+		"confidence":  0.85,
+		"outcome":     "success",
+		"usage_count": int64(7),
+		"created_at":  june,
+		"tags":        []string{"go", "testing"},
+	}
+	stringified := map[string]interface{}{
+		"confidence":  "0.85",
+		"outcome":     "success",
+		"usage_count": "7",
+		"created_at":  "1749945600",
+		"tags":        []interface{}{"go", "testing"},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"confidence >= 0.8", true},
+		{"confidence > 0.85", false},
+		{"confidence <= 0.85 AND usage_count < 10", true},
+		{"outcome = success", true},
+		{"outcome = 'failure' OR usage_count >= 7", true},
+		{"outcome != success", false},
+		{"outcome IN ('failure', \"success\")", true},
+		{"outcome NOT IN (failure, partial)", true},
+		{"NOT (outcome = success AND confidence > 0.9)", true},
+		{"created_at >= 2025-06-01 AND created_at < 2025-07-01T00:00:00Z", true},
+		{"created_at > 2025-06-16", false},
+		{"tags = testing", true},
+		{"tags IN (rust, python)", false},
+		{"missing = x", false},
+		{"missing != x", false},
+		{"NOT missing = x", true},
+		{"(confidence > 0.9 OR outcome = success) and usage_count = 7", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseFilterExpr(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr.Match(typed), "typed metadata")
+			assert.Equal(t, tt.want, expr.Match(stringified), "stringified metadata")
+		})
+	}
+}
+
+func TestParseFilterExpr_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want error
+	}{
+		{"", ErrInvalidFilterExpr},
+		{"confidence >", ErrInvalidFilterExpr},
+		{"confidence >= high", ErrInvalidFilterExpr},
+		{"confidence ~ 1", ErrInvalidFilterExpr},
+		{"outcome = 'unterminated", ErrInvalidFilterExpr},
+		{"(outcome = success", ErrInvalidFilterExpr},
+		{"outcome = success)", ErrInvalidFilterExpr},
+		{"outcome IN (a b)", ErrInvalidFilterExpr},
+		{"outcome ! success", ErrInvalidFilterExpr},
+		{"'outcome' = success", ErrInvalidFilterExpr},
+		{strings.Repeat("(", 9) + "a = 1" + strings.Repeat(")", 9), ErrInvalidFilterExpr},
+		{strings.Repeat("a = 1 OR ", 32) + "a = 1", ErrInvalidFilterExpr},
+		{strings.Repeat("x", maxFilterExprLength+1), ErrInvalidFilterExpr},
+		{"tenant_id = other", ErrTenantFilterInUserFilters},
+		{"confidence > 0.5 OR Project_ID IN (p1, p2)", ErrTenantFilterInUserFilters},
+		{"NOT team_id = t", ErrTenantFilterInUserFilters},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseFilterExpr(tt.expr)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestFilterExpr_String(t *testing.T) {
+	a, err := ParseFilterExpr("confidence>=0.7 and (outcome in (success,'partial') or NOT tags = wip)")
+	require.NoError(t, err)
+	b, err := ParseFilterExpr("confidence >= 0.70 AND (outcome IN ('success', \"partial\") OR not tags = 'wip')")
+	require.NoError(t, err)
+
+	assert.Equal(t, `(confidence >= 0.7 AND (outcome IN ("success", "partial") OR NOT tags = "wip"))`, a.String())
+	assert.Equal(t, a.String(), b.String(), "equivalent spellings share a canonical form")
+
+	var none *FilterExpr
+	assert.True(t, none.Match(nil))
+	assert.Empty(t, none.String())
+}
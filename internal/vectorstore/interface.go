@@ -4,12 +4,14 @@ package vectorstore
 import (
 	"context"
 	"errors"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 // Sentinel errors for vector store operations.
 var (
 	// ErrCollectionNotFound is returned when a collection does not exist.
-	ErrCollectionNotFound = errors.New("collection not found")
+	ErrCollectionNotFound = ctxerrors.NotFound("collection not found")
 
 	// ErrCollectionExists is returned when attempting to create an existing collection.
 	ErrCollectionExists = errors.New("collection already exists")
@@ -21,13 +23,32 @@ var (
 	ErrEmptyDocuments = errors.New("empty or nil documents")
 
 	// ErrConnectionFailed indicates gRPC connection issues.
-	ErrConnectionFailed = errors.New("failed to connect to Qdrant")
+	ErrConnectionFailed = ctxerrors.New(ctxerrors.CodeDependencyUnavailable, "failed to connect to Qdrant")
 
 	// ErrEmbeddingFailed indicates embedding generation failure.
-	ErrEmbeddingFailed = errors.New("failed to generate embeddings")
+	ErrEmbeddingFailed = ctxerrors.New(ctxerrors.CodeDependencyUnavailable, "failed to generate embeddings")
 
 	// ErrInvalidCollectionName indicates collection name validation failure.
 	ErrInvalidCollectionName = errors.New("invalid collection name")
+
+	// ErrScrollUnsupported is returned by Scroll when the store cannot
+	// iterate a collection without a query.
+	ErrScrollUnsupported = errors.New("store does not support scrolling")
+
+	// ErrSamplingUnsupported is returned by Sample when the store cannot
+	// return stored vectors.
+	ErrSamplingUnsupported = errors.New("store does not support vector sampling")
+
+	// ErrIndexRebuildUnsupported is returned by RebuildIndex when the store
+	// has no index to rebuild.
+	ErrIndexRebuildUnsupported = errors.New("store does not support index rebuilds")
+
+	// ErrScanUnsupported is returned by Scan when the store cannot read back
+	// stored documents with their embeddings.
+	ErrScanUnsupported = errors.New("store does not support document scans")
+
+	// ErrStoreClosed is returned by operations on a store after Close.
+	ErrStoreClosed = ctxerrors.New(ctxerrors.CodeShuttingDown, "vector store is closed")
 )
 
 // CollectionInfo contains metadata about a vector collection.
@@ -137,6 +158,8 @@ type Store interface {
 	DeleteDocuments(ctx context.Context, ids []string) error
 
 	// DeleteDocumentsFromCollection deletes documents by their IDs from a specific collection.
+	// If isolation mode is set, only the context tenant's documents are
+	// deleted, and a missing tenant fails with ErrMissingTenant.
 	//
 	// Returns an error if deletion fails.
 	DeleteDocumentsFromCollection(ctx context.Context, collectionName string, ids []string) error
@@ -202,3 +225,110 @@ type Store interface {
 	// Close closes the vector store connection and releases resources.
 	Close() error
 }
+
+// Scroller is implemented by stores that can iterate every document in a
+// collection without a similarity query. Streaming list operations use it to
+// process very large collections in bounded batches.
+type Scroller interface {
+	// ScrollCollection calls fn with successive batches of at most batchSize
+	// documents, applying filters (and tenant isolation) the same way as
+	// SearchInCollection. Result scores are zero. Iteration stops at the first
+	// error returned by fn, which ScrollCollection returns unchanged.
+	ScrollCollection(ctx context.Context, collectionName string, batchSize int, filters map[string]interface{}, fn func([]SearchResult) error) error
+}
+
+// Scroll iterates a collection via store's Scroller implementation.
+// Returns ErrScrollUnsupported if the store does not implement Scroller.
+func Scroll(ctx context.Context, store Store, collectionName string, batchSize int, filters map[string]interface{}, fn func([]SearchResult) error) error {
+	scroller, ok := store.(Scroller)
+	if !ok {
+		return ErrScrollUnsupported
+	}
+	return scroller.ScrollCollection(ctx, collectionName, batchSize, filters, fn)
+}
+
+// StoredVector is a document together with the embedding it was stored with.
+type StoredVector struct {
+	ID      string
+	Content string
+	Vector  []float32
+}
+
+// VectorSampler is implemented by stores that can return stored embeddings.
+// Diagnostics such as DriftChecker use it to compare stored vectors with
+// fresh embeddings of the same content.
+type VectorSampler interface {
+	// SampleVectors returns up to n documents from a collection with their
+	// stored embeddings. Tenant isolation is not applied: sampling serves
+	// store-wide diagnostics, which must not return sampled content to
+	// callers. Returns ErrCollectionNotFound for unknown collections.
+	SampleVectors(ctx context.Context, collectionName string, n int) ([]StoredVector, error)
+}
+
+// Sample returns stored vectors via store's VectorSampler implementation.
+// Returns ErrSamplingUnsupported if the store does not implement VectorSampler.
+func Sample(ctx context.Context, store Store, collectionName string, n int) ([]StoredVector, error) {
+	sampler, ok := store.(VectorSampler)
+	if !ok {
+		return nil, ErrSamplingUnsupported
+	}
+	return sampler.SampleVectors(ctx, collectionName, n)
+}
+
+// ScannedDocument is a stored document as read back by a DocumentScanner.
+type ScannedDocument struct {
+	ID       string
+	Content  string
+	Metadata map[string]interface{}
+	Vector   []float32
+
+	// Location is where the document is stored, such as a file or point ID,
+	// for reporting documents whose ID cannot be read.
+	Location string
+
+	// DecodeError says why the stored document could not be decoded. The
+	// other fields hold whatever could be read.
+	DecodeError string
+}
+
+// DocumentScanner is implemented by stores that can read back every stored
+// document with its embedding.
+type DocumentScanner interface {
+	// ScanDocuments calls fn with successive batches of at most batchSize
+	// documents of a collection. Documents that do not decode are passed
+	// with DecodeError set rather than failing the scan. Tenant isolation is
+	// not applied: scans serve store-wide integrity checks, which must not
+	// return scanned content to callers. Returns ErrCollectionNotFound for
+	// unknown collections.
+	ScanDocuments(ctx context.Context, collectionName string, batchSize int, fn func([]ScannedDocument) error) error
+}
+
+// Scan reads back a collection via store's DocumentScanner implementation.
+// Returns ErrScanUnsupported if the store does not implement DocumentScanner.
+func Scan(ctx context.Context, store Store, collectionName string, batchSize int, fn func([]ScannedDocument) error) error {
+	scanner, ok := store.(DocumentScanner)
+	if !ok {
+		return ErrScanUnsupported
+	}
+	return scanner.ScanDocuments(ctx, collectionName, batchSize, fn)
+}
+
+// IndexRebuilder is implemented by stores with an approximate nearest
+// neighbor index that can fall behind, typically after bulk imports.
+type IndexRebuilder interface {
+	// RebuildIndex rebuilds the index of a collection with the store's
+	// current index settings and blocks until it is ready or ctx is done.
+	// Returns ErrCollectionNotFound for unknown collections.
+	RebuildIndex(ctx context.Context, collectionName string) error
+}
+
+// RebuildIndex rebuilds a collection's index via store's IndexRebuilder
+// implementation. Returns ErrIndexRebuildUnsupported if the store does not
+// implement IndexRebuilder, as for chromem, which searches exhaustively.
+func RebuildIndex(ctx context.Context, store Store, collectionName string) error {
+	rebuilder, ok := store.(IndexRebuilder)
+	if !ok {
+		return ErrIndexRebuildUnsupported
+	}
+	return rebuilder.RebuildIndex(ctx, collectionName)
+}
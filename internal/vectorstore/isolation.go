@@ -208,6 +208,23 @@ var (
 	_ IsolationMode = (*NoIsolation)(nil)
 )
 
+// deleteScope returns the tenant ID a store scopes deletes to, and whether
+// deletes are scoped at all. Without isolation, deletes match by ID alone;
+// with it, a missing or invalid tenant fails closed.
+func deleteScope(ctx context.Context, isolation IsolationMode) (string, bool, error) {
+	if isolation == nil || isolation.Mode() == "none" {
+		return "", false, nil
+	}
+	if err := isolation.ValidateTenant(ctx); err != nil {
+		return "", false, fmt.Errorf("validating tenant: %w", err)
+	}
+	tenant, err := TenantFromContext(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	return tenant.TenantID, true, nil
+}
+
 // IsolationModeFromString creates an IsolationMode from a string name.
 func IsolationModeFromString(mode string) (IsolationMode, error) {
 	switch mode {
@@ -21,6 +21,8 @@ type Metrics struct {
 	documentsOp   metric.Int64Counter
 	searchResults metric.Int64Histogram
 	errors        metric.Int64Counter
+	indexRebuild  metric.Float64Histogram
+	indexProgress metric.Float64Gauge
 }
 
 // NewMetrics creates a new Metrics instance for vectorstore.
@@ -77,6 +79,27 @@ func (m *Metrics) init() {
 	if err != nil {
 		m.logger.Warn("failed to create errors counter", zap.Error(err))
 	}
+
+	// Index rebuild duration by collection and result
+	m.indexRebuild, err = m.meter.Float64Histogram(
+		"contextd.vectorstore.index_rebuild_duration_seconds",
+		metric.WithDescription("Duration of explicit index rebuilds, labeled by collection and result (success, error). Long rebuilds after bulk imports suggest lowering ef_construct or importing in smaller batches."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(1, 5, 15, 30, 60, 120, 300, 600, 1800),
+	)
+	if err != nil {
+		m.logger.Warn("failed to create index rebuild duration histogram", zap.Error(err))
+	}
+
+	// Share of vectors indexed while a rebuild runs
+	m.indexProgress, err = m.meter.Float64Gauge(
+		"contextd.vectorstore.index_rebuild_progress",
+		metric.WithDescription("Share of a collection's vectors indexed during the latest rebuild, from 0 to 1, labeled by collection. Reaches 1 when the rebuild completes."),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		m.logger.Warn("failed to create index rebuild progress gauge", zap.Error(err))
+	}
 }
 
 // RecordOperation records a vectorstore operation metric.
@@ -116,6 +139,30 @@ func (m *Metrics) RecordSearchResults(ctx context.Context, collection string, co
 	}
 }
 
+// RecordIndexRebuild records a finished index rebuild.
+func (m *Metrics) RecordIndexRebuild(ctx context.Context, collection string, duration time.Duration, err error) {
+	if m.indexRebuild == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.indexRebuild.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("collection", collection),
+		attribute.String("result", result),
+	))
+}
+
+// RecordIndexProgress records the indexed share of a rebuilding collection.
+func (m *Metrics) RecordIndexProgress(ctx context.Context, collection string, progress float64) {
+	if m.indexProgress != nil {
+		m.indexProgress.Record(ctx, progress, metric.WithAttributes(
+			attribute.String("collection", collection),
+		))
+	}
+}
+
 // Global metrics instance for health check functions
 var globalMetrics *Metrics
 
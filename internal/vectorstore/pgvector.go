@@ -0,0 +1,1178 @@
+// Package vectorstore provides vector storage implementations.
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// pgvectorTracer is the tracer for PgvectorStore operations.
+var pgvectorTracer = otel.Tracer("contextd.vectorstore.pgvector")
+
+// Tables of the pgvector schema. Put them in their own Postgres schema by
+// setting search_path in the DSN.
+const (
+	pgvectorMigrationsTable  = "contextd_schema_migrations"
+	pgvectorCollectionsTable = "contextd_collections"
+	pgvectorDocumentsTable   = "contextd_documents"
+)
+
+// pgvectorMaxEfSearch is the largest hnsw.ef_search pgvector accepts.
+const pgvectorMaxEfSearch = 1000
+
+// PgvectorConfig holds configuration for the Postgres pgvector store.
+type PgvectorConfig struct {
+	// DSN is the Postgres connection string, as a URL or key=value pairs.
+	// Pool settings such as pool_max_conns are read from it.
+	DSN string
+
+	// DefaultCollection is the default collection name.
+	// Default: "contextd_default"
+	DefaultCollection string
+
+	// VectorSize is the dimensionality of embeddings. It sizes the
+	// embedding column when the schema is created and must match it after.
+	// Default: 384 (for FastEmbed bge-small-en-v1.5)
+	VectorSize int
+
+	// BatchSize is the number of documents sent to Postgres per round trip
+	// when upserting.
+	// Default: 500
+	BatchSize int
+
+	// EfSearch is the HNSW candidate list size at query time. Searches use
+	// at least k, up to pgvector's maximum of 1000. Filtered searches,
+	// including every tenant-isolated one, recall better with larger values.
+	// Default: 100
+	EfSearch int
+
+	// Isolation is the tenant isolation mode.
+	// Default: PayloadIsolation for fail-closed security.
+	// Set at construction time; immutable afterward to prevent race conditions.
+	Isolation IsolationMode
+}
+
+// ApplyDefaults sets default values for unset fields.
+func (c *PgvectorConfig) ApplyDefaults() {
+	if c.DefaultCollection == "" {
+		c.DefaultCollection = "contextd_default"
+	}
+	if c.VectorSize == 0 {
+		c.VectorSize = 384
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 500
+	}
+	if c.EfSearch == 0 {
+		c.EfSearch = 100
+	}
+}
+
+// Validate validates the configuration.
+func (c PgvectorConfig) Validate() error {
+	if c.DSN == "" {
+		return fmt.Errorf("%w: DSN required", ErrInvalidConfig)
+	}
+	if c.VectorSize <= 0 {
+		return fmt.Errorf("%w: vector size must be positive", ErrInvalidConfig)
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("%w: batch size must be positive", ErrInvalidConfig)
+	}
+	if c.EfSearch < 0 || c.EfSearch > pgvectorMaxEfSearch {
+		return fmt.Errorf("%w: ef_search must be between 1 and %d", ErrInvalidConfig, pgvectorMaxEfSearch)
+	}
+	return ValidateCollectionName(c.DefaultCollection)
+}
+
+// pgvectorMigration is one versioned step of the pgvector schema.
+type pgvectorMigration struct {
+	version int
+	name    string
+
+	// sql returns the statements of the step for the configured vector size.
+	sql func(vectorSize int) string
+}
+
+// pgvectorMigrations are applied in order by NewPgvectorStore. Released
+// migrations never change; schema changes are appended as new versions.
+var pgvectorMigrations = []pgvectorMigration{
+	{
+		version: 1,
+		name:    "create collections and documents",
+		sql: func(vectorSize int) string {
+			return fmt.Sprintf(`
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE %[1]s (
+	name        TEXT PRIMARY KEY,
+	vector_size INTEGER NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE %[2]s (
+	collection TEXT NOT NULL REFERENCES %[1]s (name) ON DELETE CASCADE,
+	id         TEXT NOT NULL,
+	tenant_id  TEXT NOT NULL DEFAULT '',
+	content    TEXT NOT NULL,
+	metadata   JSONB NOT NULL DEFAULT '{}',
+	embedding  vector(%[3]d) NOT NULL,
+	PRIMARY KEY (collection, tenant_id, id)
+);
+
+CREATE INDEX %[2]s_metadata_idx ON %[2]s USING gin (metadata jsonb_path_ops);
+CREATE INDEX %[2]s_embedding_idx ON %[2]s USING hnsw (embedding vector_cosine_ops);
+`, pgvectorCollectionsTable, pgvectorDocumentsTable, vectorSize)
+		},
+	},
+}
+
+// PgvectorStore is a Store implementation on Postgres with the pgvector
+// extension, for teams that already run Postgres and would rather not
+// operate Qdrant as well.
+//
+// All collections share one documents table keyed by collection, tenant
+// and document ID, so tenants cannot overwrite or delete each other's
+// documents.
+// Tenant isolation is payload isolation: the tenant ID is copied from the
+// injected metadata into the tenant_id key column, and the other scope
+// fields are matched in the JSONB metadata. Access labels
+// are enforced in SQL, as for Qdrant.
+//
+// Key features:
+//   - Versioned schema migrations, applied at startup under an advisory lock
+//   - Batched, transactional upserts
+//   - HNSW cosine index; ExactSearch bypasses it
+type PgvectorStore struct {
+	pool      *pgxpool.Pool
+	embedder  Embedder
+	config    PgvectorConfig
+	logger    *zap.Logger
+	isolation IsolationMode
+
+	// collections caches the names of collections known to exist.
+	collections sync.Map
+}
+
+// NewPgvectorStore connects to Postgres, applies pending schema migrations
+// and returns a ready-to-use store.
+//
+// Returns an error if the configuration is invalid, Postgres cannot be
+// reached, the pgvector extension cannot be created, or the existing
+// embedding column does not match VectorSize.
+func NewPgvectorStore(config PgvectorConfig, embedder Embedder, logger *zap.Logger) (*PgvectorStore, error) {
+	if embedder == nil {
+		return nil, fmt.Errorf("%w: embedder is required", ErrInvalidConfig)
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("validating config: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, config.DSN)
+	if err != nil {
+		// Parse errors may quote the connection string; keep the password out of logs
+		return nil, fmt.Errorf("%w: invalid postgres DSN", ErrInvalidConfig)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("connecting to postgres: %w", err)
+	}
+
+	isolation := config.Isolation
+	if isolation == nil {
+		isolation = NewPayloadIsolation()
+	}
+
+	store := &PgvectorStore{
+		pool:      pool,
+		embedder:  embedder,
+		config:    config,
+		logger:    logger,
+		isolation: isolation,
+	}
+
+	if err := store.migrate(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	if err := store.checkVectorSize(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// migrate applies the pending schema migrations, each in its own
+// transaction. A transaction-scoped advisory lock keeps concurrently
+// starting servers from applying the same migration twice.
+func (s *PgvectorStore) migrate(ctx context.Context) error {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.Migrate")
+	defer span.End()
+
+	applied := 0
+	for _, m := range pgvectorMigrations {
+		done := false
+		err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, pgvectorMigrationsTable); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+pgvectorMigrationsTable+` (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`)
+			if err != nil {
+				return err
+			}
+			err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+pgvectorMigrationsTable+` WHERE version = $1)`, m.version).Scan(&done)
+			if err != nil || done {
+				return err
+			}
+			if _, err := tx.Exec(ctx, m.sql(s.config.VectorSize)); err != nil {
+				return err
+			}
+			_, err = tx.Exec(ctx, `INSERT INTO `+pgvectorMigrationsTable+` (version, name) VALUES ($1, $2)`, m.version, m.name)
+			return err
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("applying migration %d (%s): %w", m.version, m.name, err)
+		}
+		if !done {
+			applied++
+			s.logger.Info("applied pgvector schema migration",
+				zap.Int("version", m.version),
+				zap.String("name", m.name),
+			)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("applied", applied))
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// checkVectorSize compares the dimension of the embedding column, fixed
+// when the schema was created, with the configured vector size.
+func (s *PgvectorStore) checkVectorSize(ctx context.Context) error {
+	var size int
+	err := s.pool.QueryRow(ctx, `SELECT atttypmod FROM pg_attribute
+WHERE attrelid = $1::regclass AND attname = 'embedding'`, pgvectorDocumentsTable).Scan(&size)
+	if err != nil {
+		return fmt.Errorf("reading embedding column: %w", err)
+	}
+	if size != s.config.VectorSize {
+		return fmt.Errorf("%w: vector size %d does not match the embedding column's %d; use a new database or schema for a different embedding model",
+			ErrInvalidConfig, s.config.VectorSize, size)
+	}
+	return nil
+}
+
+// Close closes the connection pool.
+func (s *PgvectorStore) Close() error {
+	s.pool.Close()
+	return nil
+}
+
+// SetIsolationMode sets the tenant isolation mode for this store.
+//
+// DEPRECATED: Prefer setting isolation via config at construction time
+// (PgvectorConfig.Isolation) for thread-safety. This method should only be
+// called once before any operations.
+func (s *PgvectorStore) SetIsolationMode(mode IsolationMode) {
+	s.isolation = mode
+}
+
+// IsolationMode returns the current isolation mode.
+func (s *PgvectorStore) IsolationMode() IsolationMode {
+	return s.isolation
+}
+
+// AddDocuments embeds and upserts documents in batches of BatchSize within
+// one transaction, creating the target collection if needed. All documents
+// must target the same collection. If isolation mode is set, tenant
+// metadata is automatically injected.
+func (s *PgvectorStore) AddDocuments(ctx context.Context, docs []Document) ([]string, error) {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.AddDocuments")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("document_count", len(docs)))
+
+	if len(docs) == 0 {
+		return nil, ErrEmptyDocuments
+	}
+
+	if s.isolation != nil {
+		if err := s.isolation.InjectMetadata(ctx, docs); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("injecting tenant metadata: %w", err)
+		}
+	}
+	if err := stampAccess(ctx, docs); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	collectionName := s.config.DefaultCollection
+	if docs[0].Collection != "" {
+		collectionName = docs[0].Collection
+	}
+	for i, doc := range docs {
+		if doc.Collection != "" && doc.Collection != collectionName {
+			return nil, fmt.Errorf("document at index %d has collection %q but batch targets %q - all documents must target the same collection",
+				i, doc.Collection, collectionName)
+		}
+	}
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("collection", collectionName))
+
+	ids := make([]string, len(docs))
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+		if ids[i] == "" {
+			ids[i] = fmt.Sprintf("doc_%d_%d", time.Now().UnixNano(), i)
+		}
+		texts[i] = doc.Content
+	}
+
+	embeddings, err := s.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("%w: %v", ErrEmbeddingFailed, err)
+	}
+	for i, e := range embeddings {
+		if len(e) != s.config.VectorSize {
+			return nil, fmt.Errorf("%w: embedding %d has %d dimensions, want %d", ErrEmbeddingFailed, i, len(e), s.config.VectorSize)
+		}
+	}
+
+	rows := make([][]any, len(docs))
+	for i, doc := range docs {
+		metadata, err := encodePgvectorMetadata(doc.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("encoding metadata of document %s: %w", ids[i], err)
+		}
+		tenantID, _ := doc.Metadata["tenant_id"].(string)
+		rows[i] = []any{collectionName, ids[i], tenantID, doc.Content, metadata, encodePgvector(embeddings[i])}
+	}
+
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO `+pgvectorCollectionsTable+` (name, vector_size) VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING`, collectionName, s.config.VectorSize)
+		if err != nil {
+			return fmt.Errorf("creating collection %s: %w", collectionName, err)
+		}
+		for start := 0; start < len(rows); start += s.config.BatchSize {
+			batch := &pgx.Batch{}
+			for _, row := range rows[start:min(start+s.config.BatchSize, len(rows))] {
+				batch.Queue(`INSERT INTO `+pgvectorDocumentsTable+` (collection, id, tenant_id, content, metadata, embedding)
+VALUES ($1, $2, $3, $4, $5, $6::vector)
+ON CONFLICT (collection, tenant_id, id) DO UPDATE SET
+	content = EXCLUDED.content,
+	metadata = EXCLUDED.metadata,
+	embedding = EXCLUDED.embedding`, row...)
+			}
+			if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+				return fmt.Errorf("upserting documents to collection %s: %w", collectionName, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	s.collections.Store(collectionName, true)
+
+	span.SetAttributes(attribute.Int("documents_added", len(ids)))
+	span.SetStatus(codes.Ok, "success")
+
+	s.logger.Debug("upserted documents to pgvector",
+		zap.String("collection", collectionName),
+		zap.Int("count", len(ids)),
+	)
+
+	return ids, nil
+}
+
+// Search performs similarity search in the default collection.
+func (s *PgvectorStore) Search(ctx context.Context, query string, k int) ([]SearchResult, error) {
+	return s.SearchInCollection(ctx, s.config.DefaultCollection, query, k, nil)
+}
+
+// SearchWithFilters performs similarity search with metadata filters.
+func (s *PgvectorStore) SearchWithFilters(ctx context.Context, query string, k int, filters map[string]interface{}) ([]SearchResult, error) {
+	return s.SearchInCollection(ctx, s.config.DefaultCollection, query, k, filters)
+}
+
+// SearchInCollection performs similarity search in a specific collection
+// using the HNSW index. If isolation mode is set, tenant filters are
+// automatically injected.
+func (s *PgvectorStore) SearchInCollection(ctx context.Context, collectionName string, query string, k int, filters map[string]interface{}) ([]SearchResult, error) {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.SearchInCollection")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("k", k),
+	)
+
+	results, err := s.search(ctx, collectionName, query, k, filters, false)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("results_count", len(results)))
+	span.SetStatus(codes.Ok, "success")
+	return results, nil
+}
+
+// ExactSearch performs brute-force similarity search without the HNSW
+// index. Tenant filters are injected as for SearchInCollection.
+func (s *PgvectorStore) ExactSearch(ctx context.Context, collectionName string, query string, k int) ([]SearchResult, error) {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.ExactSearch")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("k", k),
+		attribute.Bool("exact", true),
+	)
+
+	results, err := s.search(ctx, collectionName, query, k, nil, true)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("results_count", len(results)))
+	span.SetStatus(codes.Ok, "success")
+	return results, nil
+}
+
+// search runs a similarity query with tenant and access filters. exact
+// disables index scans so every row of the collection is compared.
+func (s *PgvectorStore) search(ctx context.Context, collectionName string, query string, k int, filters map[string]interface{}, exact bool) ([]SearchResult, error) {
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return nil, err
+	}
+
+	// Validate k parameter (security: prevent resource exhaustion)
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+	const maxK = 10000
+	if k > maxK {
+		k = maxK
+	}
+
+	// Validate query (security: prevent DoS via oversized queries)
+	if query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	const maxQueryLength = 10000 // characters
+	if len(query) > maxQueryLength {
+		return nil, fmt.Errorf("query exceeds maximum length of %d characters", maxQueryLength)
+	}
+
+	if s.isolation != nil {
+		var err error
+		filters, err = s.isolation.InjectFilter(ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("injecting tenant filter: %w", err)
+		}
+	}
+
+	if err := s.requireCollection(ctx, collectionName); err != nil {
+		return nil, err
+	}
+
+	queryVector, err := s.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEmbeddingFailed, err)
+	}
+
+	var args pgvectorArgs
+	vector := args.add(encodePgvector(queryVector))
+	where := pgvectorWhere(ctx, &args, collectionName, filters)
+	sql := `SELECT id, content, metadata, 1 - (embedding <=> ` + vector + `::vector)
+FROM ` + pgvectorDocumentsTable + `
+WHERE ` + where + `
+ORDER BY embedding <=> ` + vector + `::vector
+LIMIT ` + args.add(k)
+
+	var results []SearchResult
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if exact {
+			if _, err := tx.Exec(ctx, `SET LOCAL enable_indexscan = off`); err != nil {
+				return err
+			}
+		} else {
+			ef := min(max(s.config.EfSearch, k), pgvectorMaxEfSearch)
+			if _, err := tx.Exec(ctx, `SET LOCAL hnsw.ef_search = `+strconv.Itoa(ef)); err != nil {
+				return err
+			}
+		}
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		results, err = pgx.CollectRows(rows, scanPgvectorResult)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching collection %s: %w", collectionName, err)
+	}
+	return results, nil
+}
+
+// ScrollCollection iterates every document in a collection in batches,
+// paging by tenant and document ID so only one batch is held in memory at a
+// time.
+func (s *PgvectorStore) ScrollCollection(ctx context.Context, collectionName string, batchSize int, filters map[string]interface{}, fn func([]SearchResult) error) error {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.ScrollCollection")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("batch_size", batchSize),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	if s.isolation != nil {
+		var err error
+		filters, err = s.isolation.InjectFilter(ctx, filters)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("injecting tenant filter: %w", err)
+		}
+	}
+	if err := s.requireCollection(ctx, collectionName); err != nil {
+		return err
+	}
+
+	total := 0
+	var afterTenant, afterID string
+	for {
+		var args pgvectorArgs
+		where := pgvectorWhere(ctx, &args, collectionName, filters)
+		rows, err := s.pool.Query(ctx, `SELECT id, content, metadata, 0::float8, tenant_id
+FROM `+pgvectorDocumentsTable+`
+WHERE `+where+` AND (tenant_id, id) > (`+args.add(afterTenant)+`, `+args.add(afterID)+`)
+ORDER BY tenant_id, id
+LIMIT `+args.add(batchSize), args...)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("scrolling collection %s: %w", collectionName, err)
+		}
+		var lastTenant string
+		batch, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (SearchResult, error) {
+			var r SearchResult
+			var metadata []byte
+			var score float64
+			if err := row.Scan(&r.ID, &r.Content, &metadata, &score, &lastTenant); err != nil {
+				return r, err
+			}
+			var err error
+			r.Metadata, err = decodePgvectorMetadata(metadata)
+			return r, err
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("scrolling collection %s: %w", collectionName, err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		total += len(batch)
+		afterTenant, afterID = lastTenant, batch[len(batch)-1].ID
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	span.SetAttributes(attribute.Int("documents", total))
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// SampleVectors returns up to n randomly chosen documents of a collection
+// with their stored embeddings.
+func (s *PgvectorStore) SampleVectors(ctx context.Context, collectionName string, n int) ([]StoredVector, error) {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.SampleVectors")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("n", n),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("sample size must be positive, got %d", n)
+	}
+	if err := s.requireCollection(ctx, collectionName); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT id, content, embedding::text
+FROM `+pgvectorDocumentsTable+`
+WHERE collection = $1
+ORDER BY random()
+LIMIT $2`, collectionName, n)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("sampling collection %s: %w", collectionName, err)
+	}
+	samples, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (StoredVector, error) {
+		var sv StoredVector
+		var vector string
+		if err := row.Scan(&sv.ID, &sv.Content, &vector); err != nil {
+			return sv, err
+		}
+		sv.Vector, _ = decodePgvector(vector)
+		return sv, nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("sampling collection %s: %w", collectionName, err)
+	}
+
+	span.SetAttributes(attribute.Int("sampled", len(samples)))
+	span.SetStatus(codes.Ok, "success")
+	return samples, nil
+}
+
+// ScanDocuments iterates every document of a collection with its embedding,
+// paging by tenant and document ID, one batch in memory at a time.
+func (s *PgvectorStore) ScanDocuments(ctx context.Context, collectionName string, batchSize int, fn func([]ScannedDocument) error) error {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.ScanDocuments")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("batch_size", batchSize),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	if err := s.requireCollection(ctx, collectionName); err != nil {
+		return err
+	}
+
+	total := 0
+	var afterTenant, afterID string
+	for {
+		rows, err := s.pool.Query(ctx, `SELECT tenant_id, id, content, metadata::text, embedding::text
+FROM `+pgvectorDocumentsTable+`
+WHERE collection = $1 AND (tenant_id, id) > ($2, $3)
+ORDER BY tenant_id, id
+LIMIT $4`, collectionName, afterTenant, afterID, batchSize)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("scanning collection %s: %w", collectionName, err)
+		}
+		var lastTenant string
+		batch, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ScannedDocument, error) {
+			var id, content, metadata, vector string
+			if err := row.Scan(&lastTenant, &id, &content, &metadata, &vector); err != nil {
+				return ScannedDocument{}, err
+			}
+			return scannedPgvectorRow(collectionName, lastTenant, id, content, metadata, vector), nil
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("scanning collection %s: %w", collectionName, err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		total += len(batch)
+		afterTenant, afterID = lastTenant, batch[len(batch)-1].ID
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	span.SetAttributes(attribute.Int("documents", total))
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// scannedPgvectorRow decodes a row read back by ScanDocuments. A row
+// decodes when its metadata is an object of the scalar values AddDocuments
+// writes and its embedding parses.
+func scannedPgvectorRow(collectionName, tenantID, id, content, metadata, vector string) ScannedDocument {
+	location := "row " + collectionName + "/" + id
+	if tenantID != "" {
+		location = "row " + collectionName + "/" + tenantID + "/" + id
+	}
+	doc := ScannedDocument{
+		ID:       id,
+		Content:  content,
+		Location: location,
+	}
+	var err error
+	doc.Metadata, err = decodePgvectorMetadata([]byte(metadata))
+	if err != nil {
+		doc.DecodeError = err.Error()
+		// Keep the key column so a repair can delete the row as its tenant
+		if tenantID != "" {
+			doc.Metadata = map[string]interface{}{"tenant_id": tenantID}
+		}
+	}
+	doc.Vector, err = decodePgvector(vector)
+	if err != nil {
+		doc.DecodeError = err.Error()
+	}
+	if id == "" {
+		doc.DecodeError = "row has no document ID"
+	}
+	return doc
+}
+
+// DeleteDocuments deletes documents by their IDs from the default collection.
+func (s *PgvectorStore) DeleteDocuments(ctx context.Context, ids []string) error {
+	return s.DeleteDocumentsFromCollection(ctx, s.config.DefaultCollection, ids)
+}
+
+// DeleteDocumentsFromCollection deletes documents by their IDs from a
+// specific collection. If isolation mode is set, only the documents of the
+// context's tenant are deleted, and a missing tenant is an error.
+func (s *PgvectorStore) DeleteDocumentsFromCollection(ctx context.Context, collectionName string, ids []string) error {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.DeleteDocumentsFromCollection")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("id_count", len(ids)),
+		attribute.String("collection", collectionName),
+	)
+
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+
+	tenantID, scoped, err := deleteScope(ctx, s.isolation)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	query := `DELETE FROM ` + pgvectorDocumentsTable + ` WHERE collection = $1 AND id = ANY($2)`
+	args := []any{collectionName, ids}
+	if scoped {
+		// Rows are keyed by tenant, so another tenant's document with the
+		// same ID must survive the delete
+		query += ` AND tenant_id = $3`
+		args = append(args, tenantID)
+	}
+
+	_, err = s.pool.Exec(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("deleting documents from collection %s: %w", collectionName, err)
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// CreateCollection creates a new collection. A vectorSize of 0 means the
+// configured size; any other size must match it, since all collections
+// share the embedding column.
+func (s *PgvectorStore) CreateCollection(ctx context.Context, collectionName string, vectorSize int) error {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.CreateCollection")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("vector_size", vectorSize),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+	if vectorSize == 0 {
+		vectorSize = s.config.VectorSize
+	}
+	if vectorSize != s.config.VectorSize {
+		return fmt.Errorf("vector size %d does not match configured size %d", vectorSize, s.config.VectorSize)
+	}
+
+	tag, err := s.pool.Exec(ctx, `INSERT INTO `+pgvectorCollectionsTable+` (name, vector_size) VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING`, collectionName, vectorSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("creating collection %s: %w", collectionName, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCollectionExists
+	}
+
+	s.collections.Store(collectionName, true)
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// DeleteCollection deletes a collection and all its documents.
+func (s *PgvectorStore) DeleteCollection(ctx context.Context, collectionName string) error {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.DeleteCollection")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("collection", collectionName))
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+
+	s.collections.Delete(collectionName)
+	tag, err := s.pool.Exec(ctx, `DELETE FROM `+pgvectorCollectionsTable+` WHERE name = $1`, collectionName)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("deleting collection %s: %w", collectionName, err)
+	}
+	if tag.RowsAffected() == 0 {
+		span.SetStatus(codes.Error, "collection not found")
+		return ErrCollectionNotFound
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// CollectionExists checks if a collection exists.
+func (s *PgvectorStore) CollectionExists(ctx context.Context, collectionName string) (bool, error) {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.CollectionExists")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("collection", collectionName))
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return false, err
+	}
+	if _, ok := s.collections.Load(collectionName); ok {
+		return true, nil
+	}
+
+	var exists bool
+	err := s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+pgvectorCollectionsTable+` WHERE name = $1)`, collectionName).Scan(&exists)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("checking collection %s: %w", collectionName, err)
+	}
+	if exists {
+		s.collections.Store(collectionName, true)
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return exists, nil
+}
+
+// requireCollection returns ErrCollectionNotFound for unknown collections.
+func (s *PgvectorStore) requireCollection(ctx context.Context, collectionName string) error {
+	exists, err := s.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+// ListCollections returns a list of all collection names.
+func (s *PgvectorStore) ListCollections(ctx context.Context) ([]string, error) {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.ListCollections")
+	defer span.End()
+
+	rows, err := s.pool.Query(ctx, `SELECT name FROM `+pgvectorCollectionsTable+` ORDER BY name`)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("collection_count", len(names)))
+	span.SetStatus(codes.Ok, "success")
+	return names, nil
+}
+
+// GetCollectionInfo returns metadata about a collection.
+func (s *PgvectorStore) GetCollectionInfo(ctx context.Context, collectionName string) (*CollectionInfo, error) {
+	ctx, span := pgvectorTracer.Start(ctx, "PgvectorStore.GetCollectionInfo")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("collection", collectionName))
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return nil, err
+	}
+
+	info := &CollectionInfo{Name: collectionName}
+	err := s.pool.QueryRow(ctx, `SELECT c.vector_size,
+	(SELECT count(*) FROM `+pgvectorDocumentsTable+` d WHERE d.collection = c.name)
+FROM `+pgvectorCollectionsTable+` c
+WHERE c.name = $1`, collectionName).Scan(&info.VectorSize, &info.PointCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		span.SetStatus(codes.Error, "collection not found")
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("getting collection info for %s: %w", collectionName, err)
+	}
+
+	span.SetAttributes(attribute.Int("point_count", info.PointCount))
+	span.SetStatus(codes.Ok, "success")
+	return info, nil
+}
+
+// pgvectorArgs collects the positional arguments of a query.
+type pgvectorArgs []any
+
+// add appends v and returns its placeholder.
+func (a *pgvectorArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// pgvectorWhere builds the WHERE clause for a collection's documents that
+// match filters and the access rules of the context's requester. The
+// tenant ID is matched on its column; other string-valued filters must be
+// contained in the metadata. Non-string values are ignored, as for Qdrant.
+func pgvectorWhere(ctx context.Context, args *pgvectorArgs, collectionName string, filters map[string]interface{}) string {
+	conds := []string{"collection = " + args.add(collectionName)}
+
+	contained := make(map[string]string)
+	for key, value := range filters {
+		v, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if key == "tenant_id" {
+			conds = append(conds, "tenant_id = "+args.add(v))
+			continue
+		}
+		contained[key] = v
+	}
+	if len(contained) > 0 {
+		data, _ := json.Marshal(contained)
+		conds = append(conds, "metadata @> "+args.add(string(data))+"::jsonb")
+	}
+
+	return strings.Join(append(conds, pgvectorAccessFilter(ctx, args)), " AND ")
+}
+
+// pgvectorAccessFilter returns the access label rules for the context's
+// requester as a SQL condition, matching CanAccess.
+func pgvectorAccessFilter(ctx context.Context, args *pgvectorArgs) string {
+	should := []string{
+		fmt.Sprintf("COALESCE(metadata->>'%s', '') IN ('', '%s')", VisibilityKey, VisibilityOrg),
+	}
+	r := RequesterFromContext(ctx)
+	if r.UserID != "" {
+		should = append(should, fmt.Sprintf("(metadata->>'%s' = %s AND metadata->>'%s' IN ('%s', '%s'))",
+			OwnerKey, args.add(r.UserID), VisibilityKey, VisibilityPrivate, VisibilityTeam))
+	}
+	if r.TeamID != "" {
+		should = append(should, fmt.Sprintf("(metadata->>'%s' = '%s' AND metadata->>'%s' = %s)",
+			VisibilityKey, VisibilityTeam, OwnerTeamKey, args.add(r.TeamID)))
+	}
+	return "(" + strings.Join(should, " OR ") + ")"
+}
+
+// scanPgvectorResult reads an id, content, metadata, score row.
+func scanPgvectorResult(row pgx.CollectableRow) (SearchResult, error) {
+	var r SearchResult
+	var metadata []byte
+	var score float64
+	if err := row.Scan(&r.ID, &r.Content, &metadata, &score); err != nil {
+		return r, err
+	}
+	r.Score = float32(score)
+	var err error
+	r.Metadata, err = decodePgvectorMetadata(metadata)
+	return r, err
+}
+
+// encodePgvectorMetadata encodes the scalar values of metadata as a JSON
+// object. Other values are dropped, as for Qdrant.
+func encodePgvectorMetadata(metadata map[string]interface{}) (string, error) {
+	kept := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		switch v.(type) {
+		case string, int, int64, float64, bool:
+			kept[k] = v
+		}
+	}
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodePgvectorMetadata decodes stored metadata. JSON does not tell
+// integers from floats, so whole numbers written without a fraction come
+// back as int64 and the rest as float64.
+func decodePgvectorMetadata(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("metadata is not a JSON object: %w", err)
+	}
+
+	metadata := make(map[string]interface{}, len(raw))
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := raw[k].(type) {
+		case string, bool:
+			metadata[k] = v
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				metadata[k] = i
+			} else if f, err := v.Float64(); err == nil {
+				metadata[k] = f
+			} else {
+				return metadata, fmt.Errorf("metadata field %q is not a number", k)
+			}
+		default:
+			return metadata, fmt.Errorf("metadata field %q has an unsupported type", k)
+		}
+	}
+	return metadata, nil
+}
+
+// encodePgvector formats a vector in pgvector's text form, [1,2,3].
+func encodePgvector(v []float32) string {
+	var b strings.Builder
+	b.Grow(len(v) * 8)
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// decodePgvector parses a vector in pgvector's text form.
+func decodePgvector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("embedding %q is not a vector", truncateForError(s))
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return []float32{}, nil
+	}
+	parts := strings.Split(s, ",")
+	v := make([]float32, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("embedding element %d is not a finite number", i)
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
+
+// truncateForError shortens s for an error message.
+func truncateForError(s string) string {
+	const limit = 32
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit] + "..."
+}
+
+// Ensure PgvectorStore implements Store, Scroller, VectorSampler and
+// DocumentScanner interfaces.
+var (
+	_ Store           = (*PgvectorStore)(nil)
+	_ Scroller        = (*PgvectorStore)(nil)
+	_ VectorSampler   = (*PgvectorStore)(nil)
+	_ DocumentScanner = (*PgvectorStore)(nil)
+)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"sync"
 	"time"
 
@@ -76,6 +77,63 @@ type QdrantConfig struct {
 	// Default: PayloadIsolation for fail-closed security.
 	// Set at construction time; immutable afterward to prevent race conditions.
 	Isolation IsolationMode
+
+	// HNSW tunes the approximate nearest neighbor index of new collections
+	// and of searches. Zero values keep Qdrant's defaults.
+	HNSW HNSWConfig
+}
+
+// HNSWConfig holds HNSW index tuning parameters.
+type HNSWConfig struct {
+	// M is the number of edges per node. Higher values improve recall for
+	// more memory and slower builds. Qdrant default: 16.
+	M int
+
+	// EfConstruct is the candidate list size while building the index.
+	// Higher values build a better graph, more slowly. Qdrant default: 100.
+	EfConstruct int
+
+	// EfSearch is the candidate list size at query time. Higher values
+	// improve recall for more latency. Qdrant default: equal to the limit.
+	EfSearch int
+
+	// OnDisk keeps the index on disk instead of in memory. Qdrant always
+	// persists the index; this only controls where it is served from.
+	OnDisk bool
+}
+
+// validate rejects negative parameters.
+func (h HNSWConfig) validate() error {
+	if h.M < 0 || h.EfConstruct < 0 || h.EfSearch < 0 {
+		return fmt.Errorf("%w: HNSW parameters must not be negative", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// diff returns the index settings to send to Qdrant, or nil if all are defaults.
+func (h HNSWConfig) diff() *qdrant.HnswConfigDiff {
+	if h.M == 0 && h.EfConstruct == 0 && !h.OnDisk {
+		return nil
+	}
+	d := &qdrant.HnswConfigDiff{}
+	if h.M > 0 {
+		d.M = qdrant.PtrOf(uint64(h.M))
+	}
+	if h.EfConstruct > 0 {
+		d.EfConstruct = qdrant.PtrOf(uint64(h.EfConstruct))
+	}
+	if h.OnDisk {
+		d.OnDisk = qdrant.PtrOf(true)
+	}
+	return d
+}
+
+// searchParams returns query-time index settings, or nil for defaults.
+func (h HNSWConfig) searchParams() *qdrant.SearchParams {
+	if h.EfSearch == 0 {
+		return nil
+	}
+	return &qdrant.SearchParams{HnswEf: qdrant.PtrOf(uint64(h.EfSearch))}
 }
 
 // Validate validates the configuration.
@@ -92,7 +150,7 @@ func (c QdrantConfig) Validate() error {
 	if c.VectorSize == 0 {
 		return fmt.Errorf("%w: vector size required", ErrInvalidConfig)
 	}
-	return nil
+	return c.HNSW.validate()
 }
 
 // ApplyDefaults sets default values for unset fields.
@@ -399,6 +457,11 @@ func (s *QdrantStore) AddDocuments(ctx context.Context, docs []Document) ([]stri
 			return nil, fmt.Errorf("injecting tenant metadata: %w", err)
 		}
 	}
+	if err := stampAccess(ctx, docs); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
 	// Generate embeddings
 	texts := make([]string, len(docs))
@@ -577,29 +640,8 @@ func (s *QdrantStore) SearchInCollection(ctx context.Context, collectionName str
 		queryVector = make([]float32, s.config.VectorSize)
 	}
 
-	// Build filter if provided
-	var filter *qdrant.Filter
-	if len(filters) > 0 {
-		conditions := make([]*qdrant.Condition, 0, len(filters))
-		for key, value := range filters {
-			switch v := value.(type) {
-			case string:
-				conditions = append(conditions, &qdrant.Condition{
-					ConditionOneOf: &qdrant.Condition_Field{
-						Field: &qdrant.FieldCondition{
-							Key: key,
-							Match: &qdrant.Match{
-								MatchValue: &qdrant.Match_Keyword{Keyword: v},
-							},
-						},
-					},
-				})
-			}
-		}
-		if len(conditions) > 0 {
-			filter = &qdrant.Filter{Must: conditions}
-		}
-	}
+	// Build filter if provided, always enforcing access labels
+	filter := qdrantAccessFilter(ctx, buildQdrantFilter(filters))
 
 	// Search
 	var results []*qdrant.ScoredPoint
@@ -610,6 +652,7 @@ func (s *QdrantStore) SearchInCollection(ctx context.Context, collectionName str
 			Limit:          qdrant.PtrOf(uint64(k)),
 			WithPayload:    qdrant.NewWithPayload(true),
 			Filter:         filter,
+			Params:         s.config.HNSW.searchParams(),
 		})
 		if err != nil {
 			return err
@@ -626,34 +669,8 @@ func (s *QdrantStore) SearchInCollection(ctx context.Context, collectionName str
 	// Convert to SearchResult
 	searchResults := make([]SearchResult, len(results))
 	for i, point := range results {
-		result := SearchResult{
-			Score: point.Score,
-		}
-
-		// Extract metadata from payload
-		if point.Payload != nil {
-			result.Metadata = make(map[string]interface{})
-			for k, v := range point.Payload {
-				switch val := v.Kind.(type) {
-				case *qdrant.Value_StringValue:
-					// Always add to metadata for consistent access
-					result.Metadata[k] = val.StringValue
-					// Also set dedicated fields for commonly accessed values
-					if k == "content" {
-						result.Content = val.StringValue
-					} else if k == "id" {
-						result.ID = val.StringValue
-					}
-				case *qdrant.Value_IntegerValue:
-					result.Metadata[k] = val.IntegerValue
-				case *qdrant.Value_DoubleValue:
-					result.Metadata[k] = val.DoubleValue
-				case *qdrant.Value_BoolValue:
-					result.Metadata[k] = val.BoolValue
-				}
-			}
-		}
-
+		result := payloadToResult(point.Payload)
+		result.Score = point.Score
 		searchResults[i] = result
 	}
 
@@ -681,28 +698,46 @@ func (s *QdrantStore) DeleteDocumentsFromCollection(ctx context.Context, collect
 		return nil
 	}
 
-	// Delete by filter matching document IDs
-	err := s.retryOperation(ctx, "delete", func() error {
+	tenantID, scoped, err := deleteScope(ctx, s.isolation)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// Delete by filter matching document IDs, and the tenant when scoped
+	must := []*qdrant.Condition{
+		{
+			ConditionOneOf: &qdrant.Condition_Field{
+				Field: &qdrant.FieldCondition{
+					Key: "id",
+					Match: &qdrant.Match{
+						MatchValue: &qdrant.Match_Keywords{
+							Keywords: &qdrant.RepeatedStrings{Strings: ids},
+						},
+					},
+				},
+			},
+		},
+	}
+	if scoped {
+		must = append(must, &qdrant.Condition{
+			ConditionOneOf: &qdrant.Condition_Field{
+				Field: &qdrant.FieldCondition{
+					Key: "tenant_id",
+					Match: &qdrant.Match{
+						MatchValue: &qdrant.Match_Keyword{Keyword: tenantID},
+					},
+				},
+			},
+		})
+	}
+	err = s.retryOperation(ctx, "delete", func() error {
 		_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
 			CollectionName: collectionName,
 			Points: &qdrant.PointsSelector{
 				PointsSelectorOneOf: &qdrant.PointsSelector_Filter{
-					Filter: &qdrant.Filter{
-						Must: []*qdrant.Condition{
-							{
-								ConditionOneOf: &qdrant.Condition_Field{
-									Field: &qdrant.FieldCondition{
-										Key: "id",
-										Match: &qdrant.Match{
-											MatchValue: &qdrant.Match_Keywords{
-												Keywords: &qdrant.RepeatedStrings{Strings: ids},
-											},
-										},
-									},
-								},
-							},
-						},
-					},
+					Filter: &qdrant.Filter{Must: must},
 				},
 			},
 		})
@@ -740,6 +775,7 @@ func (s *QdrantStore) CreateCollection(ctx context.Context, collectionName strin
 				Size:     uint64(vectorSize),
 				Distance: s.config.Distance,
 			}),
+			HnswConfig: s.config.HNSW.diff(),
 		})
 	})
 	if err != nil {
@@ -952,34 +988,8 @@ func (s *QdrantStore) ExactSearch(ctx context.Context, collectionName string, qu
 	// Convert to SearchResult
 	searchResults := make([]SearchResult, len(results))
 	for i, point := range results {
-		result := SearchResult{
-			Score: point.Score,
-		}
-
-		// Extract metadata from payload
-		if point.Payload != nil {
-			result.Metadata = make(map[string]interface{})
-			for k, v := range point.Payload {
-				switch val := v.Kind.(type) {
-				case *qdrant.Value_StringValue:
-					// Always add to metadata for consistent access
-					result.Metadata[k] = val.StringValue
-					// Also set dedicated fields for commonly accessed values
-					if k == "content" {
-						result.Content = val.StringValue
-					} else if k == "id" {
-						result.ID = val.StringValue
-					}
-				case *qdrant.Value_IntegerValue:
-					result.Metadata[k] = val.IntegerValue
-				case *qdrant.Value_DoubleValue:
-					result.Metadata[k] = val.DoubleValue
-				case *qdrant.Value_BoolValue:
-					result.Metadata[k] = val.BoolValue
-				}
-			}
-		}
-
+		result := payloadToResult(point.Payload)
+		result.Score = point.Score
 		searchResults[i] = result
 	}
 
@@ -988,5 +998,303 @@ func (s *QdrantStore) ExactSearch(ctx context.Context, collectionName string, qu
 	return searchResults, nil
 }
 
-// Ensure QdrantStore implements Store interface.
-var _ Store = (*QdrantStore)(nil)
+// ScrollCollection iterates every point in a collection in batches using the
+// Qdrant scroll API, so only one batch is held in memory at a time.
+func (s *QdrantStore) ScrollCollection(ctx context.Context, collectionName string, batchSize int, filters map[string]interface{}, fn func([]SearchResult) error) error {
+	ctx, span := tracer.Start(ctx, "QdrantStore.ScrollCollection")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("batch_size", batchSize),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	if s.isolation != nil {
+		var err error
+		filters, err = s.isolation.InjectFilter(ctx, filters)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("injecting tenant filter: %w", err)
+		}
+	}
+	filter := qdrantAccessFilter(ctx, buildQdrantFilter(filters))
+
+	var offset *qdrant.PointId
+	total := 0
+	for {
+		var points []*qdrant.RetrievedPoint
+		var next *qdrant.PointId
+		err := s.retryOperation(ctx, "scroll", func() error {
+			var err error
+			points, next, err = s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+				CollectionName: collectionName,
+				Filter:         filter,
+				Offset:         offset,
+				Limit:          qdrant.PtrOf(uint32(batchSize)),
+				WithPayload:    qdrant.NewWithPayload(true),
+			})
+			return err
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("scrolling collection %s: %w", collectionName, err)
+		}
+		if len(points) == 0 {
+			break
+		}
+
+		batch := make([]SearchResult, len(points))
+		for i, point := range points {
+			batch[i] = payloadToResult(point.Payload)
+		}
+		total += len(batch)
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		if next == nil {
+			break
+		}
+		offset = next
+	}
+
+	span.SetAttributes(attribute.Int("documents", total))
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// SampleVectors returns up to n points from a collection with their stored
+// vectors. Qdrant returns points in ID order; with UUID point IDs that is
+// effectively a random sample.
+func (s *QdrantStore) SampleVectors(ctx context.Context, collectionName string, n int) ([]StoredVector, error) {
+	ctx, span := tracer.Start(ctx, "QdrantStore.SampleVectors")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("n", n),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("sample size must be positive, got %d", n)
+	}
+
+	var points []*qdrant.RetrievedPoint
+	err := s.retryOperation(ctx, "sample", func() error {
+		var err error
+		points, err = s.client.Scroll(ctx, &qdrant.ScrollPoints{
+			CollectionName: collectionName,
+			Limit:          qdrant.PtrOf(uint32(n)),
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors:    qdrant.NewWithVectors(true),
+		})
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("sampling collection %s: %w", collectionName, err)
+	}
+
+	samples := make([]StoredVector, 0, len(points))
+	for _, point := range points {
+		vector := point.GetVectors().GetVector()
+		data := vector.GetDense().GetData()
+		if len(data) == 0 {
+			// Older servers fill the deprecated flat field instead
+			data = vector.GetData()
+		}
+		result := payloadToResult(point.Payload)
+		samples = append(samples, StoredVector{ID: result.ID, Content: result.Content, Vector: data})
+	}
+
+	span.SetAttributes(attribute.Int("sampled", len(samples)))
+	span.SetStatus(codes.Ok, "success")
+	return samples, nil
+}
+
+// ScanDocuments iterates every point of a collection with its vector using
+// the Qdrant scroll API, one batch in memory at a time.
+func (s *QdrantStore) ScanDocuments(ctx context.Context, collectionName string, batchSize int, fn func([]ScannedDocument) error) error {
+	ctx, span := tracer.Start(ctx, "QdrantStore.ScanDocuments")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("collection", collectionName),
+		attribute.Int("batch_size", batchSize),
+	)
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	var offset *qdrant.PointId
+	total := 0
+	for {
+		var points []*qdrant.RetrievedPoint
+		var next *qdrant.PointId
+		err := s.retryOperation(ctx, "scan", func() error {
+			var err error
+			points, next, err = s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+				CollectionName: collectionName,
+				Offset:         offset,
+				Limit:          qdrant.PtrOf(uint32(batchSize)),
+				WithPayload:    qdrant.NewWithPayload(true),
+				WithVectors:    qdrant.NewWithVectors(true),
+			})
+			return err
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("scanning collection %s: %w", collectionName, err)
+		}
+		if len(points) == 0 {
+			break
+		}
+
+		batch := make([]ScannedDocument, len(points))
+		for i, point := range points {
+			batch[i] = scannedPoint(point)
+		}
+		total += len(batch)
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		if next == nil {
+			break
+		}
+		offset = next
+	}
+
+	span.SetAttributes(attribute.Int("documents", total))
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+// scannedPoint decodes a point read back by ScanDocuments. A point decodes
+// when its payload holds the document ID and content as strings and only
+// the scalar values AddDocuments writes.
+func scannedPoint(point *qdrant.RetrievedPoint) ScannedDocument {
+	vector := point.GetVectors().GetVector()
+	data := vector.GetDense().GetData()
+	if len(data) == 0 {
+		data = vector.GetData()
+	}
+	pointID := point.GetId().GetUuid()
+	if pointID == "" {
+		pointID = fmt.Sprint(point.GetId().GetNum())
+	}
+	result := payloadToResult(point.Payload)
+	doc := ScannedDocument{
+		ID:       result.ID,
+		Content:  result.Content,
+		Metadata: result.Metadata,
+		Vector:   data,
+		Location: "point " + pointID,
+	}
+
+	keys := make([]string, 0, len(point.Payload))
+	for k := range point.Payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch point.Payload[k].GetKind().(type) {
+		case *qdrant.Value_StringValue, *qdrant.Value_IntegerValue, *qdrant.Value_DoubleValue, *qdrant.Value_BoolValue:
+		default:
+			doc.DecodeError = fmt.Sprintf("payload field %q has an unsupported type", k)
+		}
+	}
+	if _, ok := point.Payload["content"].GetKind().(*qdrant.Value_StringValue); !ok {
+		doc.DecodeError = "payload has no content"
+	}
+	if doc.ID == "" {
+		doc.DecodeError = "payload has no document ID"
+	}
+	return doc
+}
+
+// buildQdrantFilter converts string-valued metadata filters to a Qdrant
+// filter. Non-string values are ignored. Returns nil when there are none.
+func buildQdrantFilter(filters map[string]interface{}) *qdrant.Filter {
+	if len(filters) == 0 {
+		return nil
+	}
+
+	conditions := make([]*qdrant.Condition, 0, len(filters))
+	for key, value := range filters {
+		switch v := value.(type) {
+		case string:
+			conditions = append(conditions, &qdrant.Condition{
+				ConditionOneOf: &qdrant.Condition_Field{
+					Field: &qdrant.FieldCondition{
+						Key: key,
+						Match: &qdrant.Match{
+							MatchValue: &qdrant.Match_Keyword{Keyword: v},
+						},
+					},
+				},
+			})
+		}
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+	return &qdrant.Filter{Must: conditions}
+}
+
+// payloadToResult converts a Qdrant point payload to a SearchResult without a score.
+func payloadToResult(payload map[string]*qdrant.Value) SearchResult {
+	var result SearchResult
+	if payload == nil {
+		return result
+	}
+
+	result.Metadata = make(map[string]interface{})
+	for k, v := range payload {
+		switch val := v.Kind.(type) {
+		case *qdrant.Value_StringValue:
+			// Always add to metadata for consistent access
+			result.Metadata[k] = val.StringValue
+			// Also set dedicated fields for commonly accessed values
+			if k == "content" {
+				result.Content = val.StringValue
+			} else if k == "id" {
+				result.ID = val.StringValue
+			}
+		case *qdrant.Value_IntegerValue:
+			result.Metadata[k] = val.IntegerValue
+		case *qdrant.Value_DoubleValue:
+			result.Metadata[k] = val.DoubleValue
+		case *qdrant.Value_BoolValue:
+			result.Metadata[k] = val.BoolValue
+		}
+	}
+	return result
+}
+
+// Ensure QdrantStore implements Store, Scroller, VectorSampler and
+// DocumentScanner interfaces.
+var (
+	_ Store           = (*QdrantStore)(nil)
+	_ Scroller        = (*QdrantStore)(nil)
+	_ VectorSampler   = (*QdrantStore)(nil)
+	_ DocumentScanner = (*QdrantStore)(nil)
+)
@@ -0,0 +1,119 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// indexPollInterval is how often RebuildIndex checks indexing progress.
+var indexPollInterval = time.Second
+
+// errIndexFailed is returned when Qdrant reports a collection as broken
+// while rebuilding its index.
+var errIndexFailed = errors.New("qdrant reported the collection as failed")
+
+// RebuildIndex re-applies the configured HNSW settings to a collection and
+// waits until Qdrant has finished optimizing it. Call it after bulk imports,
+// or after changing HNSWConfig, so searches don't fall back to full scans on
+// unindexed segments. Progress is exported as
+// contextd.vectorstore.index_rebuild_progress.
+func (s *QdrantStore) RebuildIndex(ctx context.Context, collectionName string) error {
+	ctx, span := tracer.Start(ctx, "QdrantStore.RebuildIndex")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("collection", collectionName))
+
+	if err := ValidateCollectionName(collectionName); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := s.rebuildIndex(ctx, collectionName)
+	globalMetrics.RecordIndexRebuild(ctx, collectionName, time.Since(start), err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return nil
+}
+
+func (s *QdrantStore) rebuildIndex(ctx context.Context, collectionName string) error {
+	// An update with the HNSW settings (or an empty optimizer diff when all
+	// are defaults) makes Qdrant re-run its optimizers on every segment.
+	update := &qdrant.UpdateCollection{
+		CollectionName:   collectionName,
+		OptimizersConfig: &qdrant.OptimizersConfigDiff{},
+		HnswConfig:       s.config.HNSW.diff(),
+	}
+	err := s.retryOperation(ctx, "rebuild_index", func() error {
+		if err := s.client.UpdateCollection(ctx, update); err != nil {
+			st, ok := status.FromError(err)
+			if ok && st.Code() == grpccodes.NotFound {
+				return ErrCollectionNotFound
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrCollectionNotFound) {
+			return ErrCollectionNotFound
+		}
+		return fmt.Errorf("rebuilding index of collection %s: %w", collectionName, err)
+	}
+
+	return waitForIndex(ctx, collectionName, func(ctx context.Context) (*qdrant.CollectionInfo, error) {
+		return s.client.GetCollectionInfo(ctx, collectionName)
+	})
+}
+
+// waitForIndex polls a collection until its optimizers are idle, recording
+// the share of vectors indexed so far.
+func waitForIndex(ctx context.Context, collectionName string, info func(context.Context) (*qdrant.CollectionInfo, error)) error {
+	ticker := time.NewTicker(indexPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Let the optimizers pick up the update before the first check
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		ci, err := info(ctx)
+		if err != nil {
+			return fmt.Errorf("checking index of collection %s: %w", collectionName, err)
+		}
+
+		// Segments below Qdrant's indexing threshold are never indexed, so
+		// the ratio can stay below 1 once optimization is done.
+		progress := 1.0
+		if points := ci.GetPointsCount(); points > 0 {
+			progress = min(float64(ci.GetIndexedVectorsCount())/float64(points), 1)
+		}
+		globalMetrics.RecordIndexProgress(ctx, collectionName, progress)
+
+		switch ci.GetStatus() {
+		case qdrant.CollectionStatus_Green:
+			globalMetrics.RecordIndexProgress(ctx, collectionName, 1)
+			return nil
+		case qdrant.CollectionStatus_Red:
+			return fmt.Errorf("rebuilding index of collection %s: %w", collectionName, errIndexFailed)
+		}
+	}
+}
+
+// Ensure QdrantStore implements IndexRebuilder.
+var _ IndexRebuilder = (*QdrantStore)(nil)
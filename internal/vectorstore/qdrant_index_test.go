@@ -0,0 +1,117 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+func TestHNSWConfig(t *testing.T) {
+	assert.Nil(t, HNSWConfig{}.diff(), "defaults leave the index config alone")
+	assert.Nil(t, HNSWConfig{}.searchParams())
+
+	h := HNSWConfig{M: 32, EfConstruct: 200, EfSearch: 128, OnDisk: true}
+	diff := h.diff()
+	require.NotNil(t, diff)
+	assert.Equal(t, uint64(32), diff.GetM())
+	assert.Equal(t, uint64(200), diff.GetEfConstruct())
+	assert.True(t, diff.GetOnDisk())
+	assert.Equal(t, uint64(128), h.searchParams().GetHnswEf())
+
+	assert.Nil(t, HNSWConfig{EfSearch: 64}.diff(), "ef_search is a query-time setting")
+
+	cfg := QdrantConfig{Host: "localhost", Port: 6334, CollectionName: "c", VectorSize: 384, HNSW: HNSWConfig{M: -1}}
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidConfig)
+}
+
+// withIndexMetrics routes vectorstore metrics to a manual reader and speeds
+// up index polling for the duration of the test.
+func withIndexMetrics(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	oldMetrics, oldInterval := globalMetrics, indexPollInterval
+	globalMetrics = &Metrics{meter: mp.Meter(vectorstoreInstrumentationName), logger: zap.NewNop()}
+	globalMetrics.init()
+	indexPollInterval = time.Millisecond
+	t.Cleanup(func() {
+		globalMetrics, indexPollInterval = oldMetrics, oldInterval
+	})
+	return reader
+}
+
+func TestWaitForIndex(t *testing.T) {
+	reader := withIndexMetrics(t)
+
+	var progress []float64
+	states := []*qdrant.CollectionInfo{
+		{Status: qdrant.CollectionStatus_Grey, PointsCount: qdrant.PtrOf(uint64(100)), IndexedVectorsCount: qdrant.PtrOf(uint64(0))},
+		{Status: qdrant.CollectionStatus_Yellow, PointsCount: qdrant.PtrOf(uint64(100)), IndexedVectorsCount: qdrant.PtrOf(uint64(40))},
+		{Status: qdrant.CollectionStatus_Green, PointsCount: qdrant.PtrOf(uint64(100)), IndexedVectorsCount: qdrant.PtrOf(uint64(90))},
+	}
+	calls := 0
+	err := waitForIndex(context.Background(), "memories", func(context.Context) (*qdrant.CollectionInfo, error) {
+		ci := states[calls]
+		calls++
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		progress = append(progress, gaugeValue(rm, "contextd.vectorstore.index_rebuild_progress"))
+		return ci, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls, "polls until the collection is green")
+	assert.Equal(t, []float64{0, 0, 0.4}, progress)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	assert.Equal(t, 1.0, gaugeValue(rm, "contextd.vectorstore.index_rebuild_progress"), "complete once green")
+}
+
+func TestWaitForIndex_Failures(t *testing.T) {
+	withIndexMetrics(t)
+
+	err := waitForIndex(context.Background(), "memories", func(context.Context) (*qdrant.CollectionInfo, error) {
+		return &qdrant.CollectionInfo{Status: qdrant.CollectionStatus_Red}, nil
+	})
+	assert.ErrorIs(t, err, errIndexFailed)
+
+	boom := errors.New("boom")
+	err = waitForIndex(context.Background(), "memories", func(context.Context) (*qdrant.CollectionInfo, error) {
+		return nil, boom
+	})
+	assert.ErrorIs(t, err, boom)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = waitForIndex(ctx, "memories", func(context.Context) (*qdrant.CollectionInfo, error) {
+		cancel()
+		return &qdrant.CollectionInfo{Status: qdrant.CollectionStatus_Yellow}, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRebuildIndex_Unsupported(t *testing.T) {
+	store := newDriftTestStore(t, &driftTestEmbedder{dim: 16})
+	assert.ErrorIs(t, RebuildIndex(context.Background(), store, "memories"), ErrIndexRebuildUnsupported)
+}
+
+// gaugeValue returns the value of a float gauge's first data point, or 0.
+func gaugeValue(rm metricdata.ResourceMetrics, name string) float64 {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if g, ok := m.Data.(metricdata.Gauge[float64]); ok && m.Name == name && len(g.DataPoints) > 0 {
+				return g.DataPoints[0].Value
+			}
+		}
+	}
+	return 0
+}
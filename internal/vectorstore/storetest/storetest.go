@@ -0,0 +1,383 @@
+// Package storetest provides a conformance suite for vectorstore.Store
+// implementations.
+//
+// Every provider is expected to enforce the same contract: tenant isolation
+// fails closed, callers cannot smuggle tenant fields in through user filters,
+// search limits are validated and capped, metadata survives a round trip, and
+// deletes remove the tenant's own documents from subsequent reads. Run the
+// suite from each
+// provider's tests so behavioral drift shows up as a test failure:
+//
+//	func TestMyStore_Conformance(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T, embedder vectorstore.Embedder) vectorstore.Store {
+//			store, err := NewMyStore(MyConfig{VectorSize: storetest.VectorSize}, embedder)
+//			require.NoError(t, err)
+//			t.Cleanup(func() { store.Close() })
+//			return store
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// VectorSize is the embedding dimension produced by the suite's embedder.
+// Stores passed to Run must be configured for vectors of this size.
+const VectorSize = 16
+
+// MaxK and MaxQueryLength are the search limits every store enforces.
+const (
+	MaxK           = 10000
+	MaxQueryLength = 10000
+)
+
+// NewStore returns a store that uses embedder and the default payload
+// isolation mode. It is called once per subtest; register any cleanup with
+// t.Cleanup. Collections created by the suite are deleted when each subtest
+// finishes.
+type NewStore func(t *testing.T, embedder vectorstore.Embedder) vectorstore.Store
+
+// Run exercises the vectorstore.Store contract against stores built by
+// newStore.
+func Run(t *testing.T, newStore NewStore) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, h *harness)
+	}{
+		{"TenantFailClosed", testTenantFailClosed},
+		{"TenantIsolation", testTenantIsolation},
+		{"FilterInjection", testFilterInjection},
+		{"Limits", testLimits},
+		{"MetadataRoundTrip", testMetadataRoundTrip},
+		{"DeleteDocuments", testDeleteDocuments},
+		{"DeleteCollection", testDeleteCollection},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore(t, Embedder{})
+			require.NotNil(t, store)
+			require.Equal(t, "payload", store.IsolationMode().Mode(),
+				"conformance suite requires the default payload isolation mode")
+			tt.fn(t, &harness{store: store})
+		})
+	}
+}
+
+// Embedder is a deterministic bag-of-words embedder producing VectorSize
+// dimensional unit vectors. Texts sharing words have similar vectors.
+type Embedder struct{}
+
+// EmbedDocuments embeds each text.
+func (Embedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = embed(text)
+	}
+	return out, nil
+}
+
+// EmbedQuery embeds a single query.
+func (Embedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	return embed(text), nil
+}
+
+func embed(text string) []float32 {
+	vec := make([]float32, VectorSize)
+	// A constant component keeps texts without words off the zero vector,
+	// which cosine similarity cannot score.
+	vec[0] = 0.1
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(word))
+		vec[h.Sum32()%VectorSize]++
+	}
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] = float32(float64(vec[i]) / norm)
+	}
+	return vec
+}
+
+// collectionSeq keeps collection names unique within a process; the
+// timestamp keeps them unique across runs against a shared server.
+var collectionSeq atomic.Int64
+
+type harness struct {
+	store vectorstore.Store
+}
+
+// collection returns a fresh collection name that is deleted when t ends.
+func (h *harness) collection(t *testing.T) string {
+	t.Helper()
+	name := fmt.Sprintf("storetest_%d_%d", time.Now().UnixNano(), collectionSeq.Add(1))
+	t.Cleanup(func() {
+		_ = h.store.DeleteCollection(context.Background(), name)
+	})
+	return name
+}
+
+// seed adds docs to collection as tenant and returns their IDs.
+func (h *harness) seed(t *testing.T, tenant *vectorstore.TenantInfo, collection string, docs ...vectorstore.Document) []string {
+	t.Helper()
+	for i := range docs {
+		docs[i].Collection = collection
+	}
+	ids, err := h.store.AddDocuments(tenantCtx(tenant), docs)
+	require.NoError(t, err)
+	require.Len(t, ids, len(docs))
+	return ids
+}
+
+// searchAll returns every document in collection visible to tenant.
+func (h *harness) searchAll(t *testing.T, tenant *vectorstore.TenantInfo, collection string) []vectorstore.SearchResult {
+	t.Helper()
+	results, err := h.store.SearchInCollection(tenantCtx(tenant), collection, "conformance document", 100, nil)
+	require.NoError(t, err)
+	return results
+}
+
+func tenantCtx(tenant *vectorstore.TenantInfo) context.Context {
+	return vectorstore.ContextWithTenant(context.Background(), tenant)
+}
+
+func resultIDs(results []vectorstore.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+var (
+	tenantA = &vectorstore.TenantInfo{TenantID: "storetest_org_a", TeamID: "platform", ProjectID: "contextd"}
+	tenantB = &vectorstore.TenantInfo{TenantID: "storetest_org_b", TeamID: "platform", ProjectID: "contextd"}
+)
+
+func doc(id, content string) vectorstore.Document {
+	return vectorstore.Document{ID: id, Content: "conformance document " + content}
+}
+
+func testTenantFailClosed(t *testing.T, h *harness) {
+	coll := h.collection(t)
+	h.seed(t, tenantA, coll, doc("a1", "alpha"))
+
+	noTenant := context.Background()
+
+	_, err := h.store.AddDocuments(noTenant, []vectorstore.Document{{ID: "x", Content: "no tenant", Collection: coll}})
+	assert.ErrorIs(t, err, vectorstore.ErrMissingTenant, "AddDocuments without tenant")
+
+	_, err = h.store.SearchInCollection(noTenant, coll, "alpha", 10, nil)
+	assert.ErrorIs(t, err, vectorstore.ErrMissingTenant, "SearchInCollection without tenant")
+
+	_, err = h.store.SearchInCollection(noTenant, coll, "alpha", 10, map[string]interface{}{"kind": "note"})
+	assert.ErrorIs(t, err, vectorstore.ErrMissingTenant, "SearchInCollection with filters but without tenant")
+
+	_, err = h.store.ExactSearch(noTenant, coll, "alpha", 10)
+	assert.ErrorIs(t, err, vectorstore.ErrMissingTenant, "ExactSearch without tenant")
+
+	_, err = h.store.SearchInCollection(tenantCtx(&vectorstore.TenantInfo{}), coll, "alpha", 10, nil)
+	assert.Error(t, err, "SearchInCollection with empty tenant ID")
+
+	err = h.store.DeleteDocumentsFromCollection(noTenant, coll, []string{"a1"})
+	assert.ErrorIs(t, err, vectorstore.ErrMissingTenant, "DeleteDocumentsFromCollection without tenant")
+	assert.Error(t, h.store.DeleteDocumentsFromCollection(tenantCtx(&vectorstore.TenantInfo{}), coll, []string{"a1"}),
+		"DeleteDocumentsFromCollection with empty tenant ID")
+	assert.Equal(t, []string{"a1"}, resultIDs(h.searchAll(t, tenantA, coll)), "a rejected delete must not remove documents")
+
+	if _, ok := h.store.(vectorstore.Scroller); ok {
+		err = vectorstore.Scroll(noTenant, h.store, coll, 10, nil, func([]vectorstore.SearchResult) error {
+			t.Error("Scroll without tenant returned documents")
+			return nil
+		})
+		assert.ErrorIs(t, err, vectorstore.ErrMissingTenant, "Scroll without tenant")
+	}
+}
+
+func testTenantIsolation(t *testing.T, h *harness) {
+	coll := h.collection(t)
+	h.seed(t, tenantA, coll, doc("a1", "alpha"), doc("a2", "alpha beta"))
+	h.seed(t, tenantB, coll, doc("b1", "alpha"))
+
+	assert.ElementsMatch(t, []string{"a1", "a2"}, resultIDs(h.searchAll(t, tenantA, coll)))
+	assert.ElementsMatch(t, []string{"b1"}, resultIDs(h.searchAll(t, tenantB, coll)))
+
+	otherProject := *tenantA
+	otherProject.ProjectID = "other_project"
+	assert.Empty(t, h.searchAll(t, &otherProject, coll), "project scope must not see sibling projects")
+
+	exact, err := h.store.ExactSearch(tenantCtx(tenantB), coll, "alpha", 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"b1"}, resultIDs(exact))
+
+	require.NoError(t, h.store.DeleteDocumentsFromCollection(tenantCtx(tenantB), coll, []string{"a1"}),
+		"deleting another tenant's IDs is not an error")
+	assert.ElementsMatch(t, []string{"a1", "a2"}, resultIDs(h.searchAll(t, tenantA, coll)),
+		"a delete must leave other tenants' documents in place")
+
+	if _, ok := h.store.(vectorstore.Scroller); ok {
+		var scrolled []string
+		err := vectorstore.Scroll(tenantCtx(tenantA), h.store, coll, 1, nil, func(batch []vectorstore.SearchResult) error {
+			assert.LessOrEqual(t, len(batch), 1, "Scroll batch exceeds batch size")
+			scrolled = append(scrolled, resultIDs(batch)...)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a1", "a2"}, scrolled)
+	}
+}
+
+func testFilterInjection(t *testing.T, h *harness) {
+	coll := h.collection(t)
+	h.seed(t, tenantA, coll, doc("a1", "alpha"))
+	h.seed(t, tenantB, coll, doc("b1", "alpha"))
+
+	for _, key := range []string{"tenant_id", "team_id", "project_id"} {
+		filters := map[string]interface{}{key: tenantB.TenantID}
+
+		results, err := h.store.SearchInCollection(tenantCtx(tenantA), coll, "alpha", 10, filters)
+		assert.ErrorIs(t, err, vectorstore.ErrTenantFilterInUserFilters, "SearchInCollection filter on %s", key)
+		assert.Empty(t, results)
+
+		if _, ok := h.store.(vectorstore.Scroller); ok {
+			err := vectorstore.Scroll(tenantCtx(tenantA), h.store, coll, 10, filters, func([]vectorstore.SearchResult) error {
+				t.Errorf("Scroll with %s filter returned documents", key)
+				return nil
+			})
+			assert.ErrorIs(t, err, vectorstore.ErrTenantFilterInUserFilters, "Scroll filter on %s", key)
+		}
+	}
+
+	// Injected documents carry the caller's tenant, not one supplied in
+	// metadata.
+	forged := doc("forged", "alpha")
+	forged.Metadata = map[string]interface{}{"tenant_id": tenantB.TenantID}
+	h.seed(t, tenantA, coll, forged)
+	assert.NotContains(t, resultIDs(h.searchAll(t, tenantB, coll)), "forged")
+}
+
+func testLimits(t *testing.T, h *harness) {
+	coll := h.collection(t)
+	h.seed(t, tenantA, coll, doc("a1", "alpha"), doc("a2", "beta"), doc("a3", "gamma"))
+	ctx := tenantCtx(tenantA)
+
+	for _, k := range []int{0, -1} {
+		_, err := h.store.SearchInCollection(ctx, coll, "alpha", k, nil)
+		assert.Error(t, err, "k=%d must be rejected", k)
+	}
+
+	_, err := h.store.SearchInCollection(ctx, coll, "", 10, nil)
+	assert.Error(t, err, "empty query must be rejected")
+
+	_, err = h.store.SearchInCollection(ctx, coll, strings.Repeat("a", MaxQueryLength+1), 10, nil)
+	assert.Error(t, err, "query longer than %d characters must be rejected", MaxQueryLength)
+
+	results, err := h.store.SearchInCollection(ctx, coll, "alpha", 2, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 2, "k bounds the result count")
+	for i := 1; i < len(results); i++ {
+		assert.GreaterOrEqual(t, results[i-1].Score, results[i].Score, "results must be ordered by score")
+	}
+
+	results, err = h.store.SearchInCollection(ctx, coll, "alpha", MaxK+1, nil)
+	require.NoError(t, err, "k above the cap is clamped, not rejected")
+	assert.Len(t, results, 3)
+
+	_, err = h.store.SearchInCollection(ctx, "Invalid-Name", "alpha", 10, nil)
+	assert.ErrorIs(t, err, vectorstore.ErrInvalidCollectionName)
+}
+
+func testMetadataRoundTrip(t *testing.T, h *harness) {
+	coll := h.collection(t)
+	d := doc("a1", "alpha")
+	// Floats are left out: chromem stores metadata as strings, so only
+	// values with a canonical text form compare equal across providers.
+	d.Metadata = map[string]interface{}{
+		"kind":    "note",
+		"unicode": "héllo, wörld",
+		"count":   42,
+		"pinned":  true,
+	}
+	h.seed(t, tenantA, coll, d)
+
+	results := h.searchAll(t, tenantA, coll)
+	require.Len(t, results, 1)
+	got := results[0]
+
+	assert.Equal(t, "a1", got.ID)
+	assert.Equal(t, d.Content, got.Content)
+	assert.Equal(t, "note", got.Metadata["kind"])
+	assert.Equal(t, "héllo, wörld", got.Metadata["unicode"])
+	assert.Equal(t, "42", fmt.Sprint(got.Metadata["count"]))
+	assert.Equal(t, "true", fmt.Sprint(got.Metadata["pinned"]))
+	assert.Equal(t, tenantA.TenantID, got.Metadata["tenant_id"])
+	assert.Equal(t, tenantA.TeamID, got.Metadata["team_id"])
+	assert.Equal(t, tenantA.ProjectID, got.Metadata["project_id"])
+
+	filtered, err := h.store.SearchInCollection(tenantCtx(tenantA), coll, "alpha", 10, map[string]interface{}{"kind": "note"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1"}, resultIDs(filtered))
+
+	filtered, err = h.store.SearchInCollection(tenantCtx(tenantA), coll, "alpha", 10, map[string]interface{}{"kind": "other"})
+	require.NoError(t, err)
+	assert.Empty(t, filtered)
+}
+
+func testDeleteDocuments(t *testing.T, h *harness) {
+	coll := h.collection(t)
+	h.seed(t, tenantA, coll, doc("a1", "alpha"), doc("a2", "beta"), doc("a3", "gamma"))
+	ctx := tenantCtx(tenantA)
+
+	require.NoError(t, h.store.DeleteDocumentsFromCollection(ctx, coll, nil), "deleting no IDs is a no-op")
+	require.NoError(t, h.store.DeleteDocumentsFromCollection(ctx, coll, []string{"missing"}), "deleting unknown IDs is not an error")
+	assert.Len(t, h.searchAll(t, tenantA, coll), 3)
+
+	require.NoError(t, h.store.DeleteDocumentsFromCollection(ctx, coll, []string{"a1", "a3"}))
+	assert.Equal(t, []string{"a2"}, resultIDs(h.searchAll(t, tenantA, coll)))
+
+	exact, err := h.store.ExactSearch(ctx, coll, "alpha", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a2"}, resultIDs(exact))
+}
+
+func testDeleteCollection(t *testing.T, h *harness) {
+	coll := h.collection(t)
+	h.seed(t, tenantA, coll, doc("a1", "alpha"))
+	ctx := tenantCtx(tenantA)
+
+	exists, err := h.store.CollectionExists(ctx, coll)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.NoError(t, h.store.DeleteCollection(ctx, coll))
+
+	exists, err = h.store.CollectionExists(ctx, coll)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = h.store.GetCollectionInfo(ctx, coll)
+	assert.True(t, errors.Is(err, vectorstore.ErrCollectionNotFound), "GetCollectionInfo after delete: %v", err)
+
+	collections, err := h.store.ListCollections(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, collections, coll)
+}
@@ -3,17 +3,18 @@ package vectorstore
 
 import (
 	"context"
-	"errors"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
 )
 
 // Tenant isolation error types - fail closed security model.
 var (
 	// ErrMissingTenant is returned when tenant info is missing from context.
 	// This triggers "fail closed" behavior - no empty results, just errors.
-	ErrMissingTenant = errors.New("tenant info missing from context")
+	ErrMissingTenant = ctxerrors.Unauthorized("tenant info missing from context")
 
 	// ErrInvalidTenant is returned when tenant identifier is invalid.
-	ErrInvalidTenant = errors.New("invalid tenant identifier")
+	ErrInvalidTenant = ctxerrors.Unauthorized("invalid tenant identifier")
 )
 
 // tenantContextKey is the context key for TenantInfo.
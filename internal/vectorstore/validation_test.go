@@ -114,6 +114,10 @@ func TestProductionHardening_PathInjection(t *testing.T) {
 		},
 	}
 
+	// Resolve relative paths in a temp dir so the WAL and its HMAC key
+	// are not created in the source tree
+	t.Chdir(t.TempDir())
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			wal, err := NewWAL(tc.path, scrubber, logger)
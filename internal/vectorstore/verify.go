@@ -0,0 +1,516 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Kinds of integrity issue reported by Verifier.
+const (
+	// IssueUndecodable is a stored document that cannot be read back.
+	IssueUndecodable = "undecodable"
+
+	// IssueDimensionMismatch is an embedding whose dimension differs from
+	// the collection's, which breaks or skews similarity search.
+	IssueDimensionMismatch = "dimension_mismatch"
+
+	// IssueMissingTenant is a document without tenant_id, invisible to
+	// every tenant under tenant isolation.
+	IssueMissingTenant = "missing_tenant"
+
+	// IssueDanglingReference is a metadata field naming a document that
+	// does not exist, such as the consolidated memory of a source memory.
+	IssueDanglingReference = "dangling_reference"
+)
+
+// Repairs a Verifier can apply. Issues without one need manual attention.
+const (
+	// RepairDelete deletes the document.
+	RepairDelete = "delete"
+
+	// RepairReembed stores the document again, embedding its content with
+	// the store's current embedder.
+	RepairReembed = "reembed"
+
+	// RepairSetMetadata sets Field to Value and stores the document again.
+	RepairSetMetadata = "set_metadata"
+
+	// RepairClearMetadata removes Field and stores the document again.
+	RepairClearMetadata = "clear_metadata"
+)
+
+// DefaultVerifyBatchSize is the number of documents read per scan batch.
+const DefaultVerifyBatchSize = 256
+
+// referenceFields lists, by collection type, the metadata fields holding
+// the ID of another document of the same collection.
+var referenceFields = map[string][]string{
+	"memories":    {"consolidation_id"}, // consolidated memory of a source memory
+	"checkpoints": {"parent_id"},        // previous checkpoint of the session
+}
+
+// VerifyConfig configures integrity verification.
+type VerifyConfig struct {
+	// Dimension is the embedding dimension every document must have.
+	// Default: the vector size of each collection.
+	Dimension int
+
+	// BatchSize is the number of documents read per scan batch.
+	// Default: DefaultVerifyBatchSize
+	BatchSize int
+}
+
+// ApplyDefaults sets default values for unset fields.
+func (c *VerifyConfig) ApplyDefaults() {
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultVerifyBatchSize
+	}
+}
+
+// VerifyIssue is one integrity problem found by Verify. A report's issues
+// can be handed back to Repair, which re-checks each before fixing it.
+type VerifyIssue struct {
+	Kind       string `json:"kind"`
+	Collection string `json:"collection"`
+	DocumentID string `json:"document_id,omitempty"`
+	Location   string `json:"location,omitempty"` // Where an undecodable document is stored
+	Field      string `json:"field,omitempty"`    // Metadata field at fault
+	Value      string `json:"value,omitempty"`    // Value RepairSetMetadata sets
+	Detail     string `json:"detail"`
+	Repair     string `json:"repair,omitempty"` // Empty when the issue needs manual attention
+}
+
+// CollectionVerification summarizes the verification of one collection.
+type CollectionVerification struct {
+	Collection string `json:"collection"`
+	Documents  int    `json:"documents"`
+	Dimension  int    `json:"dimension"` // Expected embedding dimension
+	Issues     int    `json:"issues"`
+	Error      string `json:"error,omitempty"` // Why the collection could not be verified
+}
+
+// VerifyReport is the result of an integrity verification.
+type VerifyReport struct {
+	Status        string                   `json:"status"` // "ok" or "issues"
+	Documents     int                      `json:"documents"`
+	Repairable    int                      `json:"repairable"`
+	Collections   []CollectionVerification `json:"collections"`
+	Issues        []VerifyIssue            `json:"issues"`
+	CheckedAt     time.Time                `json:"checked_at"`
+	CheckDuration time.Duration            `json:"check_duration"`
+}
+
+// HasIssues returns true if any document or collection failed verification.
+func (r *VerifyReport) HasIssues() bool {
+	return r.Status == "issues"
+}
+
+// RepairFailure is an issue whose repair failed.
+type RepairFailure struct {
+	Issue VerifyIssue `json:"issue"`
+	Error string      `json:"error"`
+}
+
+// RepairResult is the result of Repair.
+type RepairResult struct {
+	Repaired []VerifyIssue   `json:"repaired"`
+	Skipped  []VerifyIssue   `json:"skipped"` // Not repairable, or no longer present
+	Failed   []RepairFailure `json:"failed"`
+}
+
+// Verifier checks the integrity of every collection of a store: that each
+// document decodes, that its embedding has the expected dimension, that it
+// carries tenant metadata when the store isolates tenants, and that
+// references between documents resolve.
+//
+// Verification reads every document, unlike drift checks, so it runs on
+// demand rather than on a schedule. Issues are reported with the repair
+// that fixes them; Repair applies the repairs of a report.
+type Verifier struct {
+	store  Store
+	config VerifyConfig
+	logger *zap.Logger
+}
+
+// NewVerifier creates a verifier for store, which must implement
+// DocumentScanner.
+func NewVerifier(store Store, config VerifyConfig, logger *zap.Logger) (*Verifier, error) {
+	if store == nil {
+		return nil, errors.New("store is required")
+	}
+	if _, ok := store.(DocumentScanner); !ok {
+		return nil, ErrScanUnsupported
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	config.ApplyDefaults()
+
+	return &Verifier{store: store, config: config, logger: logger}, nil
+}
+
+// Verify checks every collection. Collections that cannot be read are
+// reported with an error rather than failing verification.
+func (v *Verifier) Verify(ctx context.Context) (*VerifyReport, error) {
+	start := time.Now()
+
+	names, err := v.store.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing collections: %w", err)
+	}
+	sort.Strings(names)
+
+	report := &VerifyReport{
+		Status:      "ok",
+		Collections: make([]CollectionVerification, 0, len(names)),
+		Issues:      []VerifyIssue{},
+		CheckedAt:   start,
+	}
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		check := v.checkCollection(ctx, name)
+		report.Collections = append(report.Collections, check.summary)
+		report.Documents += check.summary.Documents
+		report.Issues = append(report.Issues, check.issues...)
+		if check.summary.Error != "" || len(check.issues) > 0 {
+			report.Status = "issues"
+		}
+	}
+	for _, issue := range report.Issues {
+		if issue.Repair != "" {
+			report.Repairable++
+		}
+	}
+	report.CheckDuration = time.Since(start)
+
+	v.logger.Info("vectorstore verification completed",
+		zap.String("status", report.Status),
+		zap.Int("collections", len(report.Collections)),
+		zap.Int("documents", report.Documents),
+		zap.Int("issues", len(report.Issues)),
+		zap.Int("repairable", report.Repairable),
+		zap.Duration("duration", report.CheckDuration))
+
+	return report, nil
+}
+
+// Repair applies the repairs of issues from an earlier report. Each
+// collection is verified again first and only issues still present are
+// repaired, so a stale report never deletes or rewrites a document that
+// has since changed.
+func (v *Verifier) Repair(ctx context.Context, issues []VerifyIssue) (*RepairResult, error) {
+	result := &RepairResult{
+		Repaired: []VerifyIssue{},
+		Skipped:  []VerifyIssue{},
+		Failed:   []RepairFailure{},
+	}
+
+	byCollection := make(map[string][]VerifyIssue)
+	for _, issue := range issues {
+		if issue.Repair == "" || issue.DocumentID == "" {
+			result.Skipped = append(result.Skipped, issue)
+			continue
+		}
+		byCollection[issue.Collection] = append(byCollection[issue.Collection], issue)
+	}
+	names := make([]string, 0, len(byCollection))
+	for name := range byCollection {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		requested := byCollection[name]
+		check := v.checkCollection(ctx, name)
+		if check.summary.Error != "" {
+			for _, issue := range requested {
+				result.Failed = append(result.Failed, RepairFailure{Issue: issue, Error: "verifying collection: " + check.summary.Error})
+			}
+			continue
+		}
+
+		current := make(map[string]VerifyIssue, len(check.issues))
+		for _, issue := range check.issues {
+			current[issueKey(issue)] = issue
+		}
+		plans := make(map[string][]VerifyIssue)
+		for _, issue := range requested {
+			cur, ok := current[issueKey(issue)]
+			if !ok || cur.Repair != issue.Repair || cur.Value != issue.Value {
+				result.Skipped = append(result.Skipped, issue)
+				continue
+			}
+			plans[cur.DocumentID] = append(plans[cur.DocumentID], cur)
+		}
+
+		ids := make([]string, 0, len(plans))
+		for id := range plans {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if err := v.repairDocument(ctx, name, check.docs[id], plans[id]); err != nil {
+				v.logger.Warn("vectorstore repair failed",
+					zap.String("collection", name),
+					zap.String("document_id", id),
+					zap.Error(err))
+				for _, issue := range plans[id] {
+					result.Failed = append(result.Failed, RepairFailure{Issue: issue, Error: err.Error()})
+				}
+				continue
+			}
+			result.Repaired = append(result.Repaired, plans[id]...)
+		}
+	}
+
+	v.logger.Info("vectorstore repair completed",
+		zap.Int("repaired", len(result.Repaired)),
+		zap.Int("skipped", len(result.Skipped)),
+		zap.Int("failed", len(result.Failed)))
+
+	return result, nil
+}
+
+// issueKey identifies an issue across verifications.
+func issueKey(issue VerifyIssue) string {
+	return strings.Join([]string{issue.Kind, issue.DocumentID, issue.Field}, "\x00")
+}
+
+// collectionCheck is the outcome of verifying one collection.
+type collectionCheck struct {
+	summary CollectionVerification
+	issues  []VerifyIssue
+	docs    map[string]ScannedDocument // Documents with issues, by ID
+}
+
+func (c *collectionCheck) add(issue VerifyIssue, doc ScannedDocument) {
+	c.issues = append(c.issues, issue)
+	c.summary.Issues++
+	if doc.ID != "" {
+		c.docs[doc.ID] = doc
+	}
+}
+
+// reference is a reference field of a document, resolved once the whole
+// collection has been read.
+type reference struct {
+	doc    ScannedDocument
+	field  string
+	target string
+}
+
+// checkCollection reads one collection and reports its issues.
+func (v *Verifier) checkCollection(ctx context.Context, name string) collectionCheck {
+	check := collectionCheck{
+		summary: CollectionVerification{Collection: name},
+		docs:    make(map[string]ScannedDocument),
+	}
+
+	dim := v.config.Dimension
+	if dim <= 0 {
+		info, err := v.store.GetCollectionInfo(ctx, name)
+		if err != nil {
+			check.summary.Error = fmt.Sprintf("reading collection info: %v", err)
+			return check
+		}
+		dim = info.VectorSize
+	}
+	check.summary.Dimension = dim
+
+	isolation := v.store.IsolationMode()
+	requireTenant := isolation != nil && isolation.Mode() != "none"
+	refFields := referenceFields[collectionType(name)]
+
+	ids := make(map[string]bool)
+	tenants := make(map[string]bool)
+	var untenanted []ScannedDocument
+	var refs []reference
+
+	err := Scan(ctx, v.store, name, v.config.BatchSize, func(batch []ScannedDocument) error {
+		for _, doc := range batch {
+			check.summary.Documents++
+			if doc.ID != "" {
+				ids[doc.ID] = true
+			}
+
+			if doc.DecodeError != "" {
+				issue := VerifyIssue{
+					Kind:       IssueUndecodable,
+					Collection: name,
+					DocumentID: doc.ID,
+					Location:   doc.Location,
+					Detail:     doc.DecodeError,
+				}
+				if doc.ID != "" {
+					issue.Repair = RepairDelete
+				}
+				check.add(issue, doc)
+				continue
+			}
+
+			if len(doc.Vector) != dim {
+				issue := VerifyIssue{
+					Kind:       IssueDimensionMismatch,
+					Collection: name,
+					DocumentID: doc.ID,
+					Detail:     fmt.Sprintf("embedding has %d dimensions, want %d", len(doc.Vector), dim),
+				}
+				if strings.TrimSpace(doc.Content) != "" {
+					issue.Repair = RepairReembed
+				} else {
+					issue.Detail += "; no content to re-embed"
+				}
+				check.add(issue, doc)
+			}
+
+			if requireTenant {
+				if tenant := metadataString(doc.Metadata, "tenant_id"); tenant != "" {
+					tenants[tenant] = true
+				} else {
+					untenanted = append(untenanted, doc)
+				}
+			}
+
+			for _, field := range refFields {
+				if target := metadataString(doc.Metadata, field); target != "" {
+					refs = append(refs, reference{doc: doc, field: field, target: target})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		check.summary.Error = err.Error()
+		return check
+	}
+
+	for _, doc := range untenanted {
+		issue := VerifyIssue{
+			Kind:       IssueMissingTenant,
+			Collection: name,
+			DocumentID: doc.ID,
+			Field:      "tenant_id",
+			Detail:     "document has no tenant_id and is invisible to every tenant",
+		}
+		// A collection holding a single tenant's documents tells whose the
+		// document is; with several tenants it cannot be guessed
+		if len(tenants) == 1 {
+			for tenant := range tenants {
+				issue.Value = tenant
+			}
+			issue.Repair = RepairSetMetadata
+			issue.Detail += fmt.Sprintf("; every other document belongs to tenant %q", issue.Value)
+		}
+		check.add(issue, doc)
+	}
+
+	for _, ref := range refs {
+		if ids[ref.target] {
+			continue
+		}
+		check.add(VerifyIssue{
+			Kind:       IssueDanglingReference,
+			Collection: name,
+			DocumentID: ref.doc.ID,
+			Field:      ref.field,
+			Detail:     fmt.Sprintf("%s %q does not resolve to a document of the collection", ref.field, ref.target),
+			Repair:     RepairClearMetadata,
+		}, ref.doc)
+	}
+
+	return check
+}
+
+// repairDocument applies the repairs of one document's issues: a delete,
+// or metadata edits and re-embedding in a single rewrite.
+func (v *Verifier) repairDocument(ctx context.Context, collection string, doc ScannedDocument, issues []VerifyIssue) error {
+	metadata := make(map[string]interface{}, len(doc.Metadata))
+	for k, val := range doc.Metadata {
+		metadata[k] = val
+	}
+	for _, issue := range issues {
+		switch issue.Repair {
+		case RepairDelete:
+			// Deletes are scoped to the tenant the document belongs to
+			return v.store.DeleteDocumentsFromCollection(documentTenantContext(ctx, doc.Metadata), collection, []string{doc.ID})
+		case RepairReembed:
+		case RepairSetMetadata:
+			metadata[issue.Field] = issue.Value
+		case RepairClearMetadata:
+			delete(metadata, issue.Field)
+		default:
+			return fmt.Errorf("unknown repair %q", issue.Repair)
+		}
+	}
+
+	// Store the document as the tenant it belongs to
+	ctx = documentTenantContext(ctx, metadata)
+	docs := []Document{{ID: doc.ID, Content: doc.Content, Metadata: metadata, Collection: collection}}
+
+	// Stores check tenant and owner metadata on write; check them before
+	// the old document is deleted so a rejected rewrite loses nothing
+	if isolation := v.store.IsolationMode(); isolation != nil {
+		if err := isolation.InjectMetadata(ctx, docs); err != nil {
+			return fmt.Errorf("checking tenant metadata: %w", err)
+		}
+	}
+	if err := stampAccess(ctx, docs); err != nil {
+		return fmt.Errorf("checking access metadata: %w", err)
+	}
+
+	if err := v.store.DeleteDocumentsFromCollection(ctx, collection, []string{doc.ID}); err != nil {
+		return fmt.Errorf("deleting old document: %w", err)
+	}
+	if _, err := v.store.AddDocuments(ctx, docs); err != nil {
+		original := []Document{{ID: doc.ID, Content: doc.Content, Metadata: doc.Metadata, Collection: collection}}
+		if _, rollbackErr := v.store.AddDocuments(ctx, original); rollbackErr != nil {
+			v.logger.Error("failed to restore document after repair failure",
+				zap.String("collection", collection),
+				zap.String("document_id", doc.ID),
+				zap.Error(rollbackErr))
+			return fmt.Errorf("storing repaired document (the old document was deleted): %w", err)
+		}
+		return fmt.Errorf("storing repaired document: %w", err)
+	}
+	return nil
+}
+
+// collectionType returns the type suffix of a collection name, such as
+// "memories" for "platform_contextd_memories".
+func collectionType(name string) string {
+	for t := range referenceFields {
+		if name == t || strings.HasSuffix(name, "_"+t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// documentTenantContext scopes ctx to the tenant named by a document's
+// metadata.
+func documentTenantContext(ctx context.Context, metadata map[string]interface{}) context.Context {
+	return ContextWithTenant(ctx, &TenantInfo{
+		TenantID:  metadataString(metadata, "tenant_id"),
+		TeamID:    metadataString(metadata, "team_id"),
+		ProjectID: metadataString(metadata, "project_id"),
+	})
+}
+
+// metadataString returns a string metadata value, or "".
+func metadataString(metadata map[string]interface{}, key string) string {
+	s, _ := metadata[key].(string)
+	return s
+}
@@ -0,0 +1,225 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newVerifyTestStore returns a store whose collections hold one issue of
+// every kind besides sound documents.
+func newVerifyTestStore(t *testing.T) *ChromemStore {
+	t.Helper()
+
+	store, err := NewChromemStore(ChromemConfig{
+		Path:              t.TempDir(),
+		DefaultCollection: "verify_default",
+		VectorSize:        16,
+	}, &driftTestEmbedder{dim: 16}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	ctx := ContextWithTenant(context.Background(), &TenantInfo{TenantID: "acme"})
+	_, err = store.AddDocuments(ctx, []Document{
+		{ID: "m1", Content: "consolidated memory", Collection: "proj_memories"},
+		{ID: "m2", Content: "source memory", Collection: "proj_memories", Metadata: map[string]interface{}{"consolidation_id": "m1"}},
+		{ID: "m3", Content: "orphaned source memory", Collection: "proj_memories", Metadata: map[string]interface{}{"consolidation_id": "gone"}},
+	})
+	require.NoError(t, err)
+	_, err = store.AddDocuments(ctx, []Document{
+		{ID: "c1", Content: "first checkpoint", Collection: "proj_checkpoints"},
+		{ID: "c2", Content: "second checkpoint", Collection: "proj_checkpoints", Metadata: map[string]interface{}{"parent_id": "c0"}},
+	})
+	require.NoError(t, err)
+
+	// Written without isolation, so it has no tenant
+	store.SetIsolationMode(NewNoIsolation())
+	_, err = store.AddDocuments(ctx, []Document{{ID: "m4", Content: "untenanted memory", Collection: "proj_memories"}})
+	require.NoError(t, err)
+	store.SetIsolationMode(NewPayloadIsolation())
+
+	// Written by a model with another dimension
+	store.embedder = &driftTestEmbedder{dim: 8}
+	_, err = store.AddDocuments(ctx, []Document{{ID: "m5", Content: "memory from the old model", Collection: "proj_memories"}})
+	require.NoError(t, err)
+	store.embedder = &driftTestEmbedder{dim: 16}
+
+	garbage := filepath.Join(store.persister.collectionDir("proj_memories"), "deadbeef.gob")
+	require.NoError(t, os.WriteFile(garbage, []byte("not a gob"), 0o600))
+
+	return store
+}
+
+// issuesByDocument indexes issues by kind and document ID.
+func issuesByDocument(issues []VerifyIssue) map[string]VerifyIssue {
+	byDoc := make(map[string]VerifyIssue, len(issues))
+	for _, issue := range issues {
+		byDoc[issue.Kind+"/"+issue.DocumentID] = issue
+	}
+	return byDoc
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	store := newVerifyTestStore(t)
+	verifier, err := NewVerifier(store, VerifyConfig{}, zap.NewNop())
+	require.NoError(t, err)
+
+	report, err := verifier.Verify(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.HasIssues())
+	assert.Equal(t, 4, report.Repairable)
+
+	byDoc := issuesByDocument(report.Issues)
+	require.Len(t, byDoc, 5, "issues: %+v", report.Issues)
+
+	undecodable := byDoc[IssueUndecodable+"/"]
+	assert.Equal(t, "proj_memories", undecodable.Collection)
+	assert.Contains(t, undecodable.Location, "deadbeef.gob")
+	assert.Empty(t, undecodable.Repair, "a document without ID is repaired by hand")
+
+	assert.Equal(t, RepairReembed, byDoc[IssueDimensionMismatch+"/m5"].Repair)
+	assert.Contains(t, byDoc[IssueDimensionMismatch+"/m5"].Detail, "8 dimensions, want 16")
+
+	missing := byDoc[IssueMissingTenant+"/m4"]
+	assert.Equal(t, RepairSetMetadata, missing.Repair)
+	assert.Equal(t, "tenant_id", missing.Field)
+	assert.Equal(t, "acme", missing.Value)
+
+	assert.Equal(t, "consolidation_id", byDoc[IssueDanglingReference+"/m3"].Field)
+	assert.Equal(t, "parent_id", byDoc[IssueDanglingReference+"/c2"].Field)
+	assert.Equal(t, RepairClearMetadata, byDoc[IssueDanglingReference+"/c2"].Repair)
+
+	for _, col := range report.Collections {
+		if col.Collection == "proj_memories" {
+			assert.Equal(t, 6, col.Documents)
+			assert.Equal(t, 16, col.Dimension)
+			assert.Equal(t, 4, col.Issues)
+		}
+	}
+}
+
+func TestVerifier_Repair(t *testing.T) {
+	store := newVerifyTestStore(t)
+	verifier, err := NewVerifier(store, VerifyConfig{}, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	report, err := verifier.Verify(ctx)
+	require.NoError(t, err)
+
+	// Fixed since the report was made; the repair must not touch it
+	acme := ContextWithTenant(ctx, &TenantInfo{TenantID: "acme"})
+	require.NoError(t, store.DeleteDocumentsFromCollection(acme, "proj_memories", []string{"m3"}))
+
+	result, err := verifier.Repair(ctx, report.Issues)
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed)
+	assert.Len(t, result.Repaired, 3)
+	skipped := issuesByDocument(result.Skipped)
+	assert.Contains(t, skipped, IssueUndecodable+"/")
+	assert.Contains(t, skipped, IssueDanglingReference+"/m3")
+
+	report, err = verifier.Verify(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueUndecodable, report.Issues[0].Kind)
+
+	// Repaired documents keep their content and are visible to their tenant
+	var m4 ScannedDocument
+	require.NoError(t, store.ScanDocuments(ctx, "proj_memories", 10, func(batch []ScannedDocument) error {
+		for _, doc := range batch {
+			if doc.ID == "m4" {
+				m4 = doc
+			}
+		}
+		return nil
+	}))
+	assert.Equal(t, "untenanted memory", m4.Content)
+	assert.Equal(t, "acme", m4.Metadata["tenant_id"])
+}
+
+// failOnceAddStore fails its first AddDocuments call.
+type failOnceAddStore struct {
+	*ChromemStore
+	failed bool
+}
+
+func (s *failOnceAddStore) AddDocuments(ctx context.Context, docs []Document) ([]string, error) {
+	if !s.failed {
+		s.failed = true
+		return nil, errors.New("simulated add failure")
+	}
+	return s.ChromemStore.AddDocuments(ctx, docs)
+}
+
+func TestVerifier_RepairRestoresOnAddFailure(t *testing.T) {
+	store := newVerifyTestStore(t)
+	ctx := context.Background()
+
+	verifier, err := NewVerifier(store, VerifyConfig{}, zap.NewNop())
+	require.NoError(t, err)
+	report, err := verifier.Verify(ctx)
+	require.NoError(t, err)
+	issue, ok := issuesByDocument(report.Issues)[IssueDanglingReference+"/m3"]
+	require.True(t, ok, "issues: %+v", report.Issues)
+
+	verifier, err = NewVerifier(&failOnceAddStore{ChromemStore: store}, VerifyConfig{}, zap.NewNop())
+	require.NoError(t, err)
+	result, err := verifier.Repair(ctx, []VerifyIssue{issue})
+	require.NoError(t, err)
+	require.Len(t, result.Failed, 1)
+	assert.NotContains(t, result.Failed[0].Error, "deleted")
+
+	// The failed rewrite leaves the original document in place
+	var m3 *ScannedDocument
+	require.NoError(t, store.ScanDocuments(ctx, "proj_memories", 10, func(batch []ScannedDocument) error {
+		for i := range batch {
+			if batch[i].ID == "m3" {
+				m3 = &batch[i]
+			}
+		}
+		return nil
+	}))
+	require.NotNil(t, m3, "document was lost")
+	assert.Equal(t, "orphaned source memory", m3.Content)
+	assert.Equal(t, "gone", m3.Metadata["consolidation_id"])
+}
+
+func TestNewVerifier(t *testing.T) {
+	_, err := NewVerifier(nil, VerifyConfig{}, nil)
+	assert.Error(t, err)
+}
+
+func TestScannedPoint(t *testing.T) {
+	point := &qdrant.RetrievedPoint{
+		Id: qdrant.NewIDUUID("5f0c8f4e-1a8e-4c52-9d2b-3c1f3f1f3f1f"),
+		Payload: map[string]*qdrant.Value{
+			"id":        qdrant.NewValueString("doc1"),
+			"content":   qdrant.NewValueString("text"),
+			"tenant_id": qdrant.NewValueString("acme"),
+		},
+		Vectors: &qdrant.VectorsOutput{VectorsOptions: &qdrant.VectorsOutput_Vector{
+			Vector: &qdrant.VectorOutput{Data: []float32{1, 2, 3}},
+		}},
+	}
+	doc := scannedPoint(point)
+	assert.Empty(t, doc.DecodeError)
+	assert.Equal(t, "doc1", doc.ID)
+	assert.Equal(t, "acme", doc.Metadata["tenant_id"])
+	assert.Len(t, doc.Vector, 3)
+
+	point.Payload["tags"] = qdrant.NewValueFromList(qdrant.NewValueString("a"))
+	assert.Contains(t, scannedPoint(point).DecodeError, `"tags"`)
+
+	delete(point.Payload, "id")
+	doc = scannedPoint(point)
+	assert.Equal(t, "payload has no document ID", doc.DecodeError)
+	assert.Equal(t, "point 5f0c8f4e-1a8e-4c52-9d2b-3c1f3f1f3f1f", doc.Location)
+}
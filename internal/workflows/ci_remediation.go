@@ -0,0 +1,443 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/secrets"
+	"github.com/fyrsmithlabs/contextd/internal/troubleshoot"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// maxCIFailedJobs bounds the jobs drafted per run, so a failing matrix
+	// does not produce dozens of near-identical drafts.
+	maxCIFailedJobs = 10
+
+	// maxCIJobLogBytes bounds how much of the end of a job log is kept.
+	maxCIJobLogBytes = 10 << 20
+
+	// ciExcerptLines and maxCIExcerptBytes bound the log excerpt kept per job.
+	ciExcerptLines    = 40
+	maxCIExcerptBytes = 8000
+
+	// maxCIErrorLines bounds the error lines kept per job.
+	maxCIErrorLines = 10
+)
+
+// CIFailureRemediationWorkflow drafts remediations for failed CI jobs.
+//
+// This workflow:
+// 1. Finds the failed jobs of the run and extracts their error output
+// 2. Diagnoses each failure with the troubleshoot service
+// 3. Records a pending remediation per job, linked to the commit and job
+//
+// Drafts are recorded with status pending and are hidden from searches
+// until someone confirms them with remediation_confirm. Each job maps to one
+// draft, so a redelivered event or a retried activity does not create
+// duplicates.
+func CIFailureRemediationWorkflow(ctx workflow.Context, config CIFailureRemediationConfig) (*CIFailureRemediationResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidConfig", err)
+	}
+
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting CI failure remediation",
+		"owner", config.Owner,
+		"repo", config.Repo,
+		"sha", config.HeadSHA)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var a *CIRemediationActivities
+
+	// Step 1: Find failed jobs
+	var jobs []CIFailedJob
+	if err := workflow.ExecuteActivity(ctx, a.FetchFailedCIJobs, config).Get(ctx, &jobs); err != nil {
+		return nil, WrapActivityError("failed to fetch failed CI jobs", err)
+	}
+
+	result := &CIFailureRemediationResult{
+		FailedJobs:     len(jobs),
+		RemediationIDs: []string{},
+	}
+	logger.Info("Found failed CI jobs", "jobs", len(jobs))
+
+	for _, job := range jobs {
+		// Step 2: Diagnose
+		var diagnosis *troubleshoot.Diagnosis
+		if err := workflow.ExecuteActivity(ctx, a.DiagnoseCIFailure, job).Get(ctx, &diagnosis); err != nil {
+			// LOW - draft without a diagnosis; the log excerpt is still useful
+			logger.Warn("Failed to diagnose CI failure", "job", job.Name, "error", err)
+			diagnosis = nil
+		}
+
+		// Step 3: Draft remediation
+		var id string
+		err := workflow.ExecuteActivity(ctx, a.DraftCIRemediation, DraftCIRemediationInput{
+			Config:    config,
+			Job:       job,
+			Diagnosis: diagnosis,
+		}).Get(ctx, &id)
+		if err != nil {
+			// HIGH - record but continue with the other jobs
+			logger.Error("Failed to draft remediation", "job", job.Name, "error", err)
+			result.Errors = append(result.Errors, FormatErrorForResult(fmt.Sprintf("failed to draft remediation for %s", job.Name), err))
+			continue
+		}
+		result.RemediationIDs = append(result.RemediationIDs, id)
+	}
+
+	logger.Info("CI failure remediation complete",
+		"drafted", len(result.RemediationIDs),
+		"errors", len(result.Errors))
+	return result, nil
+}
+
+// CIRemediationActivities holds the dependencies of the CI failure
+// remediation activities. Register a pointer to it with the worker.
+type CIRemediationActivities struct {
+	GitHub       *github.Client
+	Troubleshoot *troubleshoot.Service
+	Remediation  remediation.Service
+	Scrubber     secrets.Scrubber // Redacts secrets from job logs (optional)
+	LogClient    *http.Client     // Downloads job logs (optional)
+}
+
+// FetchFailedCIJobs returns the failed jobs of a run with the error lines
+// and excerpt of each job's log. Secrets are scrubbed before the output
+// leaves the activity, so they never reach the workflow history.
+func (a *CIRemediationActivities) FetchFailedCIJobs(ctx context.Context, config CIFailureRemediationConfig) ([]CIFailedJob, error) {
+	if config.RunID <= 0 && config.JobID <= 0 {
+		errs, excerpt := parseJobLog(config.CheckOutput)
+		return []CIFailedJob{a.scrubJob(CIFailedJob{
+			Name:    config.CheckName,
+			URL:     config.CheckURL,
+			Errors:  errs,
+			Excerpt: excerpt,
+		})}, nil
+	}
+
+	failed, err := a.failedJobs(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]CIFailedJob, 0, len(failed))
+	for _, wj := range failed {
+		job := CIFailedJob{
+			ID:   wj.GetID(),
+			Name: wj.GetName(),
+			URL:  wj.GetHTMLURL(),
+		}
+		for _, step := range wj.Steps {
+			if isFailedConclusion(step.GetConclusion()) {
+				job.FailedStep = step.GetName()
+				break
+			}
+		}
+
+		log, err := a.downloadJobLog(ctx, config.Owner, config.Repo, job.ID)
+		if err != nil {
+			// Logs expire or may be withheld; draft from the job metadata
+			activity.GetLogger(ctx).Warn("Failed to download job log", "job_id", job.ID, "error", err)
+		} else {
+			job.Errors, job.Excerpt = parseJobLog(log)
+		}
+		jobs = append(jobs, a.scrubJob(job))
+	}
+	return jobs, nil
+}
+
+// failedJobs lists the failed jobs of config's run, or config's single job.
+func (a *CIRemediationActivities) failedJobs(ctx context.Context, config CIFailureRemediationConfig) ([]*github.WorkflowJob, error) {
+	if config.RunID <= 0 {
+		job, resp, err := a.GitHub.Actions.GetWorkflowJobByID(ctx, config.Owner, config.Repo, config.JobID)
+		if err != nil {
+			return nil, githubActivityError(resp, err)
+		}
+		return []*github.WorkflowJob{job}, nil
+	}
+
+	var failed []*github.WorkflowJob
+	opts := &github.ListWorkflowJobsOptions{
+		Filter:      "latest",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		jobs, resp, err := a.GitHub.Actions.ListWorkflowJobs(ctx, config.Owner, config.Repo, config.RunID, opts)
+		if err != nil {
+			return nil, githubActivityError(resp, err)
+		}
+		for _, job := range jobs.Jobs {
+			if isFailedConclusion(job.GetConclusion()) && len(failed) < maxCIFailedJobs {
+				failed = append(failed, job)
+			}
+		}
+		if resp.NextPage == 0 || len(failed) == maxCIFailedJobs {
+			return failed, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// downloadJobLog returns the last maxCIJobLogBytes of a job's log, where
+// the failure is.
+func (a *CIRemediationActivities) downloadJobLog(ctx context.Context, owner, repo string, jobID int64) (string, error) {
+	logURL, resp, err := a.GitHub.Actions.GetWorkflowJobLogs(ctx, owner, repo, jobID, 1)
+	if err != nil {
+		return "", githubActivityError(resp, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	client := a.LogClient
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	logResp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer logResp.Body.Close()
+	if logResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("log download returned %d", logResp.StatusCode)
+	}
+
+	var tail []byte
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := logResp.Body.Read(buf)
+		tail = append(tail, buf[:n]...)
+		if len(tail) > 2*maxCIJobLogBytes {
+			tail = append(tail[:0], tail[len(tail)-maxCIJobLogBytes:]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(tail) > maxCIJobLogBytes {
+		tail = tail[len(tail)-maxCIJobLogBytes:]
+	}
+	return string(tail), nil
+}
+
+func (a *CIRemediationActivities) scrubJob(job CIFailedJob) CIFailedJob {
+	if a.Scrubber == nil {
+		return job
+	}
+	for i, line := range job.Errors {
+		job.Errors[i] = a.Scrubber.Scrub(line).Scrubbed
+	}
+	job.Excerpt = a.Scrubber.Scrub(job.Excerpt).Scrubbed
+	return job
+}
+
+// DiagnoseCIFailure diagnoses a failed job from its error lines and log
+// excerpt.
+func (a *CIRemediationActivities) DiagnoseCIFailure(ctx context.Context, job CIFailedJob) (*troubleshoot.Diagnosis, error) {
+	errorMsg := strings.Join(job.Errors, "\n")
+	if errorMsg == "" {
+		errorMsg = fmt.Sprintf("CI job %q failed", job.Name)
+	}
+	errorContext := fmt.Sprintf("CI job %q failed", job.Name)
+	if job.FailedStep != "" {
+		errorContext += fmt.Sprintf(" at step %q", job.FailedStep)
+	}
+	if job.Excerpt != "" {
+		errorContext += ". Log excerpt:\n" + job.Excerpt
+	}
+
+	diagnosis, err := a.Troubleshoot.Diagnose(ctx, errorMsg, errorContext)
+	if err != nil {
+		return nil, activityError(err)
+	}
+	return diagnosis, nil
+}
+
+// DraftCIRemediation records a pending remediation for a failed job and
+// returns its ID. If the job already has a draft, its ID is returned and
+// nothing is written, so a confirmed draft is never reset to pending.
+func (a *CIRemediationActivities) DraftCIRemediation(ctx context.Context, input DraftCIRemediationInput) (string, error) {
+	config, job := input.Config, input.Job
+	tenantID := sanitize.Identifier(strings.ToLower(config.Owner))
+	id := ciRemediationID(tenantID, config, job)
+
+	ctx = vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{TenantID: tenantID})
+	existing, err := a.Remediation.Get(ctx, tenantID, id)
+	switch {
+	case err == nil:
+		return existing.ID, nil
+	case !errors.Is(err, remediation.ErrNotFound):
+		// Recording over a draft that could not be read would reset it
+		return "", activityError(err)
+	}
+
+	rem, err := a.Remediation.Record(ctx, ciRemediationRequest(tenantID, id, input))
+	if err != nil {
+		return "", activityError(err)
+	}
+	activity.GetLogger(ctx).Info("Drafted CI remediation", "remediation_id", rem.ID, "job", job.Name)
+	return rem.ID, nil
+}
+
+// ciRemediationID derives a remediation ID from the job, so each job has at
+// most one draft.
+func ciRemediationID(tenantID string, config CIFailureRemediationConfig, job CIFailedJob) string {
+	key := fmt.Sprintf("job-%d", job.ID)
+	if job.ID == 0 {
+		key = "check-" + config.HeadSHA + "-" + job.Name
+	}
+	name := strings.Join([]string{"ci-remediation", tenantID, config.Owner, config.Repo, key}, "\x00")
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
+
+// ciRemediationRequest builds the draft for a failed job.
+func ciRemediationRequest(tenantID, id string, input DraftCIRemediationInput) *remediation.RecordRequest {
+	config, job, diagnosis := input.Config, input.Job, input.Diagnosis
+
+	title := fmt.Sprintf("CI failure: %s", job.Name)
+	if job.FailedStep != "" {
+		title += " / " + job.FailedStep
+	}
+
+	var problem strings.Builder
+	fmt.Fprintf(&problem, "CI job %q failed in %s/%s at commit %s", job.Name, config.Owner, config.Repo, config.HeadSHA)
+	if config.HeadBranch != "" {
+		fmt.Fprintf(&problem, " on branch %s", config.HeadBranch)
+	}
+	problem.WriteString(".\n")
+	if job.URL != "" {
+		fmt.Fprintf(&problem, "Job: %s\n", job.URL)
+	}
+	if job.Excerpt != "" {
+		fmt.Fprintf(&problem, "\nLog excerpt:\n%s\n", job.Excerpt)
+	}
+
+	rootCause := "Not diagnosed yet; review the job log."
+	solution := "Pending review."
+	var confidence float64
+	if diagnosis != nil {
+		if diagnosis.RootCause != "" {
+			rootCause = diagnosis.RootCause
+		} else if len(diagnosis.Hypotheses) > 0 {
+			rootCause = diagnosis.Hypotheses[0].Description
+		}
+		if len(diagnosis.Recommendations) > 0 {
+			solution = "- " + strings.Join(diagnosis.Recommendations, "\n- ")
+		}
+		confidence = diagnosis.Confidence
+	}
+
+	sha := config.HeadSHA
+	if len(sha) > 12 {
+		sha = sha[:12]
+	}
+
+	return &remediation.RecordRequest{
+		ID:         id,
+		Title:      title,
+		Problem:    problem.String(),
+		Symptoms:   job.Errors,
+		RootCause:  rootCause,
+		Solution:   solution,
+		Category:   ciErrorCategory(job),
+		Tags:       []string{"ci", "repo:" + config.Owner + "/" + config.Repo, "commit:" + sha},
+		Scope:      remediation.ScopeOrg,
+		TenantID:   tenantID,
+		Confidence: confidence,
+		Status:     remediation.StatusPending,
+	}
+}
+
+// ciErrorCategory guesses the error category from the job and step names.
+func ciErrorCategory(job CIFailedJob) remediation.ErrorCategory {
+	name := strings.ToLower(job.Name + " " + job.FailedStep)
+	switch {
+	case strings.Contains(name, "lint") || strings.Contains(name, "vet") || strings.Contains(name, "fmt"):
+		return remediation.ErrorLint
+	case strings.Contains(name, "test"):
+		return remediation.ErrorTest
+	case strings.Contains(name, "build") || strings.Contains(name, "compile"):
+		return remediation.ErrorCompile
+	case strings.Contains(name, "security") || strings.Contains(name, "scan") || strings.Contains(name, "codeql"):
+		return remediation.ErrorSecurity
+	default:
+		return remediation.ErrorOther
+	}
+}
+
+func isFailedConclusion(conclusion string) bool {
+	return conclusion == "failure" || conclusion == "timed_out"
+}
+
+// logTimestamp matches the timestamp GitHub Actions prefixes log lines with.
+var logTimestamp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z `)
+
+// parseJobLog extracts the ##[error] lines of a job log and an excerpt of
+// the ciExcerptLines lines up to the first of them, or the last lines of the
+// log when it has none.
+func parseJobLog(log string) (errs []string, excerpt string) {
+	lines := strings.Split(strings.ReplaceAll(log, "\r\n", "\n"), "\n")
+	end := len(lines)
+	for i, line := range lines {
+		line = logTimestamp.ReplaceAllString(line, "")
+		lines[i] = line
+		if msg, ok := strings.CutPrefix(line, "##[error]"); ok {
+			if len(errs) == 0 {
+				end = i + 1
+			}
+			if len(errs) < maxCIErrorLines {
+				errs = append(errs, msg)
+			}
+		}
+	}
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	excerpt = strings.Join(lines[max(0, end-ciExcerptLines):end], "\n")
+	if len(excerpt) > maxCIExcerptBytes {
+		excerpt = strings.ToValidUTF8(excerpt[len(excerpt)-maxCIExcerptBytes:], "")
+	}
+	return errs, excerpt
+}
+
+// githubActivityError marks GitHub errors that cannot succeed on retry as
+// non-retryable.
+func githubActivityError(resp *github.Response, err error) error {
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+			if _, rateLimited := err.(*github.RateLimitError); !rateLimited {
+				return temporal.NewNonRetryableApplicationError(err.Error(), "GitHubRequestFailed", err)
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+// MemoryConsolidationWorkflow merges clusters of similar memories.
+//
+// This workflow:
+// 1. Finds clusters of similar memories in the project
+// 2. Merges each cluster with an LLM in its own activity
+//
+// Unlike memory_consolidate, it always runs (the consolidation window does
+// not apply) and never creates proposals. A cluster that fails to merge is
+// recorded in Errors and the remaining clusters are still merged. Merges are
+// safe to retry: a retried merge returns the memory an earlier attempt
+// created instead of calling the LLM again.
+func MemoryConsolidationWorkflow(ctx workflow.Context, config MemoryConsolidationConfig) (*MemoryConsolidationResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidConfig", err)
+	}
+
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting memory consolidation",
+		"project_id", config.ProjectID,
+		"dry_run", config.Options.DryRun)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var a *ConsolidationActivities
+
+	// Step 1: Find clusters
+	var clusters []ConsolidationCluster
+	if err := workflow.ExecuteActivity(ctx, a.FindConsolidationClusters, config).Get(ctx, &clusters); err != nil {
+		return nil, WrapActivityError("failed to find consolidation clusters", err)
+	}
+
+	result := &MemoryConsolidationResult{
+		ProjectID:        config.ProjectID,
+		ClustersFound:    len(clusters),
+		CreatedMemories:  []string{},
+		ArchivedMemories: []string{},
+		DryRun:           config.Options.DryRun,
+	}
+	logger.Info("Found consolidation clusters", "clusters", len(clusters))
+
+	if config.Options.DryRun {
+		for _, cluster := range clusters {
+			result.ArchivedMemories = append(result.ArchivedMemories, cluster.MemberIDs...)
+		}
+		return result, nil
+	}
+
+	// Step 2: Merge clusters
+	for i, cluster := range clusters {
+		var merged MergeClusterResult
+		err := workflow.ExecuteActivity(ctx, a.MergeConsolidationCluster, MergeClusterInput{
+			ProjectID: config.ProjectID,
+			MemberIDs: cluster.MemberIDs,
+		}).Get(ctx, &merged)
+		if err != nil {
+			// HIGH - record but continue with the other clusters
+			logger.Error("Failed to merge cluster", "cluster", i+1, "error", err)
+			result.Errors = append(result.Errors, FormatErrorForResult("failed to merge cluster", err))
+			result.SkippedCount += len(cluster.MemberIDs)
+			continue
+		}
+		if merged.Skipped {
+			result.SkippedCount += len(cluster.MemberIDs)
+			continue
+		}
+
+		result.CreatedMemories = append(result.CreatedMemories, merged.ConsolidatedID)
+		result.ArchivedMemories = append(result.ArchivedMemories, cluster.MemberIDs...)
+	}
+
+	// Step 3: Report the run
+	if len(result.CreatedMemories) > 0 {
+		if err := workflow.ExecuteActivity(ctx, a.ReportMemoryConsolidation, result).Get(ctx, nil); err != nil {
+			// LOW - the merges are done; only the report is lost
+			logger.Warn("Failed to report consolidation", "error", err)
+		}
+	}
+
+	logger.Info("Memory consolidation complete",
+		"created", len(result.CreatedMemories),
+		"skipped", result.SkippedCount)
+	return result, nil
+}
+
+// ConsolidationActivities holds the dependencies of the memory
+// consolidation activities. Register a pointer to it with the worker.
+type ConsolidationActivities struct {
+	Distiller *reasoningbank.Distiller
+}
+
+// FindConsolidationClusters finds the clusters a consolidation run will merge.
+func (a *ConsolidationActivities) FindConsolidationClusters(ctx context.Context, config MemoryConsolidationConfig) ([]ConsolidationCluster, error) {
+	found, err := a.Distiller.FindConsolidationClusters(ctx, config.ProjectID, config.Options)
+	if err != nil {
+		return nil, activityError(err)
+	}
+
+	clusters := make([]ConsolidationCluster, len(found))
+	for i, c := range found {
+		clusters[i].AverageSimilarity = c.AverageSimilarity
+		for _, m := range c.Members {
+			clusters[i].MemberIDs = append(clusters[i].MemberIDs, m.ID)
+		}
+	}
+	return clusters, nil
+}
+
+// MergeConsolidationCluster merges one cluster into a consolidated memory.
+// A cluster whose members were archived or deleted since it was found is
+// reported as skipped.
+func (a *ConsolidationActivities) MergeConsolidationCluster(ctx context.Context, input MergeClusterInput) (*MergeClusterResult, error) {
+	merged, err := a.Distiller.MergeMembers(ctx, input.ProjectID, input.MemberIDs)
+	if errors.Is(err, reasoningbank.ErrClusterChanged) {
+		return &MergeClusterResult{Skipped: true}, nil
+	}
+	if err != nil {
+		return nil, activityError(err)
+	}
+	return &MergeClusterResult{ConsolidatedID: merged.ID}, nil
+}
+
+// ReportMemoryConsolidation passes a finished run to the distiller's
+// consolidation listener, like runs of memory_consolidate are.
+func (a *ConsolidationActivities) ReportMemoryConsolidation(ctx context.Context, result *MemoryConsolidationResult) error {
+	a.Distiller.ReportConsolidation(ctx, result.ProjectID, &reasoningbank.ConsolidationResult{
+		CreatedMemories:  result.CreatedMemories,
+		ArchivedMemories: result.ArchivedMemories,
+		SkippedCount:     result.SkippedCount,
+		TotalProcessed:   len(result.ArchivedMemories) + result.SkippedCount,
+	})
+	return nil
+}
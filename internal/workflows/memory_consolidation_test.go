@@ -0,0 +1,99 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+)
+
+// matchMembers matches a MergeClusterInput by its first member ID.
+func matchMembers(first string) interface{} {
+	return mock.MatchedBy(func(input MergeClusterInput) bool {
+		return len(input.MemberIDs) > 0 && input.MemberIDs[0] == first
+	})
+}
+
+// TestMemoryConsolidationWorkflow tests the memory consolidation workflow.
+func TestMemoryConsolidationWorkflow(t *testing.T) {
+	clusters := []ConsolidationCluster{
+		{MemberIDs: []string{"m1", "m2"}, AverageSimilarity: 0.9},
+		{MemberIDs: []string{"m3", "m4"}, AverageSimilarity: 0.85},
+		{MemberIDs: []string{"m5", "m6", "m7"}, AverageSimilarity: 0.82},
+	}
+
+	t.Run("merges clusters and continues past failures", func(t *testing.T) {
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(MemoryConsolidationWorkflow)
+		a := &ConsolidationActivities{}
+		env.RegisterActivity(a)
+
+		env.OnActivity(a.FindConsolidationClusters, mock.Anything, mock.Anything).Return(clusters, nil)
+		env.OnActivity(a.MergeConsolidationCluster, mock.Anything, matchMembers("m1")).Return(&MergeClusterResult{ConsolidatedID: "c1"}, nil)
+		env.OnActivity(a.MergeConsolidationCluster, mock.Anything, matchMembers("m3")).Return(&MergeClusterResult{Skipped: true}, nil)
+		env.OnActivity(a.MergeConsolidationCluster, mock.Anything, matchMembers("m5")).Return(nil, errors.New("LLM synthesis failed"))
+		env.OnActivity(a.ReportMemoryConsolidation, mock.Anything, mock.MatchedBy(func(r *MemoryConsolidationResult) bool {
+			return len(r.CreatedMemories) == 1 && r.CreatedMemories[0] == "c1"
+		})).Return(nil).Once()
+
+		env.ExecuteWorkflow(MemoryConsolidationWorkflow, MemoryConsolidationConfig{ProjectID: "contextd"})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result MemoryConsolidationResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, 3, result.ClustersFound)
+		assert.Equal(t, []string{"c1"}, result.CreatedMemories)
+		assert.Equal(t, []string{"m1", "m2"}, result.ArchivedMemories)
+		assert.Equal(t, 5, result.SkippedCount)
+		require.Len(t, result.Errors, 1)
+		assert.Contains(t, result.Errors[0], "LLM synthesis failed")
+		env.AssertExpectations(t)
+	})
+
+	t.Run("dry run does not merge", func(t *testing.T) {
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(MemoryConsolidationWorkflow)
+		a := &ConsolidationActivities{}
+		env.RegisterActivity(a)
+
+		env.OnActivity(a.FindConsolidationClusters, mock.Anything, mock.Anything).Return(clusters[:1], nil)
+
+		env.ExecuteWorkflow(MemoryConsolidationWorkflow, MemoryConsolidationConfig{
+			ProjectID: "contextd",
+			Options:   reasoningbank.ConsolidationOptions{DryRun: true},
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result MemoryConsolidationResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.True(t, result.DryRun)
+		assert.Empty(t, result.CreatedMemories)
+		assert.Equal(t, []string{"m1", "m2"}, result.ArchivedMemories)
+		env.AssertNotCalled(t, "MergeConsolidationCluster", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects approval mode", func(t *testing.T) {
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(MemoryConsolidationWorkflow)
+
+		env.ExecuteWorkflow(MemoryConsolidationWorkflow, MemoryConsolidationConfig{
+			ProjectID: "contextd",
+			Options:   reasoningbank.ConsolidationOptions{RequireApproval: true},
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.Error(t, env.GetWorkflowError())
+	})
+}
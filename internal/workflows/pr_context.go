@@ -0,0 +1,326 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+	"github.com/fyrsmithlabs/contextd/internal/sanitize"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+const (
+	// maxPRContextFiles bounds the changed files searched per pull request.
+	maxPRContextFiles = 30
+
+	// prContextCandidates is how many search results are checked per file
+	// for a reference to it.
+	prContextCandidates = 20
+
+	// defaultPRContextResults is how many remediations and memories are
+	// listed per file by default.
+	defaultPRContextResults = 3
+
+	// prContextMarker identifies the comment so it is updated, not duplicated.
+	prContextMarker = "<!-- contextd:pr-context -->"
+)
+
+// PRContextWorkflow comments past fixes and memories relevant to the files
+// a pull request changes.
+//
+// This workflow:
+// 1. Lists the files the pull request changes
+// 2. Searches remediations whose fix changed each file and memories that mention it
+// 3. Posts a summary comment, or updates the one it posted before
+//
+// Nothing is posted when no file has past context.
+func PRContextWorkflow(ctx workflow.Context, config PRContextConfig) (*PRContextResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidConfig", err)
+	}
+
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting PR context",
+		"owner", config.Owner,
+		"repo", config.Repo,
+		"pr", config.PRNumber)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var a *PRContextActivities
+
+	// Step 1: List changed files
+	var files []string
+	if err := workflow.ExecuteActivity(ctx, a.ListPRChangedFiles, config).Get(ctx, &files); err != nil {
+		return nil, WrapActivityError("failed to list changed files", err)
+	}
+
+	result := &PRContextResult{FilesSearched: len(files)}
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	// Step 2: Find past context
+	var found []PRFileContext
+	err := workflow.ExecuteActivity(ctx, a.FindPRContext, FindPRContextInput{
+		Config: config,
+		Files:  files,
+	}).Get(ctx, &found)
+	if err != nil {
+		return nil, WrapActivityError("failed to find PR context", err)
+	}
+
+	result.FilesMatched = len(found)
+	if len(found) == 0 {
+		logger.Info("No past context for changed files", "files", len(files))
+		return result, nil
+	}
+
+	// Step 3: Comment
+	var comment PostCommentResult
+	err = workflow.ExecuteActivity(ctx, a.PostPRContextComment, PostPRContextCommentInput{
+		Config: config,
+		Files:  found,
+		Total:  len(files),
+	}).Get(ctx, &comment)
+	if err != nil {
+		return nil, WrapActivityError("failed to post PR context comment", err)
+	}
+	result.CommentPosted = true
+	result.CommentURL = comment.URL
+
+	logger.Info("PR context complete",
+		"files_matched", result.FilesMatched,
+		"comment_url", result.CommentURL)
+	return result, nil
+}
+
+// MemorySearcher searches the memories of a project.
+// *reasoningbank.Service satisfies it.
+type MemorySearcher interface {
+	Search(ctx context.Context, projectID, query string, limit int) ([]reasoningbank.Memory, error)
+}
+
+// PRContextActivities holds the dependencies of the PR context activities.
+// Register a pointer to it with the worker. Remediation or Memory may be nil
+// to skip that source.
+type PRContextActivities struct {
+	GitHub      *github.Client
+	Remediation remediation.Service
+	Memory      MemorySearcher
+}
+
+// ListPRChangedFiles returns the paths a pull request adds or modifies, up
+// to maxPRContextFiles.
+func (a *PRContextActivities) ListPRChangedFiles(ctx context.Context, config PRContextConfig) ([]string, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var files []string
+	for {
+		page, resp, err := a.GitHub.PullRequests.ListFiles(ctx, config.Owner, config.Repo, config.PRNumber, opts)
+		if err != nil {
+			return nil, githubActivityError(resp, fmt.Errorf("failed to list PR files: %w", err))
+		}
+		for _, f := range page {
+			if f.GetStatus() == "removed" {
+				continue
+			}
+			files = append(files, f.GetFilename())
+			if len(files) == maxPRContextFiles {
+				return files, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return files, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// FindPRContext returns the files that active remediations changed or
+// memories mention, with up to MaxResults of each per file. Search results
+// that do not reference the file are dropped, so the comment only lists
+// context that is about the file rather than merely similar to its path.
+func (a *PRContextActivities) FindPRContext(ctx context.Context, input FindPRContextInput) ([]PRFileContext, error) {
+	config := input.Config
+	limit := config.MaxResults
+	if limit == 0 {
+		limit = defaultPRContextResults
+	}
+	tenantID, projectID := config.TenantID, config.ProjectID
+	if tenantID == "" {
+		tenantID = sanitize.Identifier(config.Owner)
+	}
+	if projectID == "" {
+		projectID = sanitize.Identifier(config.Repo)
+	}
+
+	remCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{TenantID: tenantID})
+	// For memories, ProjectID serves as both tenant and project scope
+	memCtx := vectorstore.ContextWithTenant(ctx, &vectorstore.TenantInfo{TenantID: projectID, ProjectID: projectID})
+
+	var found []PRFileContext
+	for _, file := range input.Files {
+		fc := PRFileContext{Path: file}
+
+		if a.Remediation != nil {
+			results, err := a.Remediation.Search(remCtx, &remediation.SearchRequest{
+				Query:    file,
+				Limit:    prContextCandidates,
+				TenantID: tenantID,
+			})
+			if err != nil {
+				return nil, activityError(err)
+			}
+			for _, r := range results {
+				if len(fc.Remediations) < limit && touchesFile(r.AffectedFiles, file) {
+					fc.Remediations = append(fc.Remediations, PRContextItem{ID: r.ID, Title: r.Title})
+				}
+			}
+		}
+
+		if a.Memory != nil {
+			memories, err := a.Memory.Search(memCtx, projectID, file, prContextCandidates)
+			if err != nil {
+				return nil, activityError(err)
+			}
+			for _, m := range memories {
+				if len(fc.Memories) < limit && mentionsFile(m, file) {
+					fc.Memories = append(fc.Memories, PRContextItem{ID: m.ID, Title: m.Title})
+				}
+			}
+		}
+
+		if len(fc.Remediations) > 0 || len(fc.Memories) > 0 {
+			found = append(found, fc)
+		}
+	}
+
+	activity.GetLogger(ctx).Info("Found PR context", "files", len(input.Files), "matched", len(found))
+	return found, nil
+}
+
+// touchesFile reports whether any of affected is file. Paths recorded
+// relative to a subdirectory match by suffix.
+func touchesFile(affected []string, file string) bool {
+	for _, f := range affected {
+		f = strings.TrimPrefix(path.Clean(f), "./")
+		if f == file || strings.HasSuffix(file, "/"+f) || strings.HasSuffix(f, "/"+file) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsFile reports whether a memory names file.
+func mentionsFile(m reasoningbank.Memory, file string) bool {
+	return strings.Contains(m.Title, file) ||
+		strings.Contains(m.Description, file) ||
+		strings.Contains(m.Content, file)
+}
+
+// PostPRContextComment posts the PR context comment, or updates the one
+// posted for an earlier run.
+func (a *PRContextActivities) PostPRContextComment(ctx context.Context, input PostPRContextCommentInput) (*PostCommentResult, error) {
+	config := input.Config
+	body := buildPRContextComment(input.Files, input.Total)
+
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var existing *github.IssueComment
+	for existing == nil {
+		comments, resp, err := a.GitHub.Issues.ListComments(ctx, config.Owner, config.Repo, config.PRNumber, opts)
+		if err != nil {
+			return nil, githubActivityError(resp, fmt.Errorf("failed to list comments: %w", err))
+		}
+		for _, comment := range comments {
+			if strings.Contains(comment.GetBody(), prContextMarker) {
+				existing = comment
+				break
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if existing != nil {
+		updated, resp, err := a.GitHub.Issues.EditComment(ctx, config.Owner, config.Repo, existing.GetID(), &github.IssueComment{
+			Body: &body,
+		})
+		if err != nil {
+			return nil, githubActivityError(resp, fmt.Errorf("failed to update comment: %w", err))
+		}
+		return &PostCommentResult{URL: updated.GetHTMLURL()}, nil
+	}
+
+	created, resp, err := a.GitHub.Issues.CreateComment(ctx, config.Owner, config.Repo, config.PRNumber, &github.IssueComment{
+		Body: &body,
+	})
+	if err != nil {
+		return nil, githubActivityError(resp, fmt.Errorf("failed to create comment: %w", err))
+	}
+	return &PostCommentResult{URL: created.GetHTMLURL()}, nil
+}
+
+// buildPRContextComment summarizes past context per file, one line each,
+// with the remediation and memory titles folded underneath.
+func buildPRContextComment(files []PRFileContext, total int) string {
+	var b strings.Builder
+	b.WriteString(prContextMarker + "\n")
+	b.WriteString("## 🧠 Past context for this change\n\n")
+	fmt.Fprintf(&b, "contextd found past fixes or memories for %d of %d changed files.\n\n", len(files), total)
+
+	for _, f := range files {
+		var counts []string
+		switch n := len(f.Remediations); n {
+		case 0:
+		case 1:
+			counts = append(counts, "1 past fix touches")
+		default:
+			counts = append(counts, fmt.Sprintf("%d past fixes touch", n))
+		}
+		switch n := len(f.Memories); n {
+		case 0:
+		case 1:
+			counts = append(counts, "1 memory mentions")
+		default:
+			counts = append(counts, fmt.Sprintf("%d memories mention", n))
+		}
+		fmt.Fprintf(&b, "<details>\n<summary>%s <code>%s</code></summary>\n\n", strings.Join(counts, ", "), sanitizeMarkdown(f.Path))
+		for _, r := range f.Remediations {
+			fmt.Fprintf(&b, "- 🔧 %s (`%s`)\n", commentTitle(r.Title), r.ID)
+		}
+		for _, m := range f.Memories {
+			fmt.Fprintf(&b, "- 💡 %s (`%s`)\n", commentTitle(m.Title), m.ID)
+		}
+		b.WriteString("\n</details>\n\n")
+	}
+
+	b.WriteString("---\n")
+	b.WriteString("*Look these up with `remediation_search` or `memory_search`.*\n")
+	return b.String()
+}
+
+// commentTitle puts a title on one line and keeps it from mentioning users
+// or linking issues.
+func commentTitle(title string) string {
+	title = sanitizeMarkdown(strings.Join(strings.Fields(title), " "))
+	return strings.NewReplacer("@", "@\u200b", "#", "#\u200b").Replace(title)
+}
@@ -0,0 +1,178 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/remediation"
+)
+
+// fakeMemorySearcher returns the same memories for every query.
+type fakeMemorySearcher struct {
+	memories  []reasoningbank.Memory
+	projectID string
+}
+
+func (f *fakeMemorySearcher) Search(ctx context.Context, projectID, query string, limit int) ([]reasoningbank.Memory, error) {
+	f.projectID = projectID
+	return f.memories, nil
+}
+
+// newFakePRGitHub serves a pull request changing two files and records the
+// comments posted to it. existing, if set, is a comment already on the PR.
+func newFakePRGitHub(t *testing.T, existing string) (*github.Client, *[]string) {
+	t.Helper()
+
+	var posted []string
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/repos/acme/api/pulls/5/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"filename": "middleware/auth.go", "status": "modified"},
+			{"filename": "README.md", "status": "modified"},
+			{"filename": "legacy/old.go", "status": "removed"}
+		]`)
+	})
+	mux.HandleFunc("/repos/acme/api/issues/5/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if existing == "" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]*github.IssueComment{{ID: github.Int64(99), Body: github.String(existing)}})
+			return
+		}
+		var c github.IssueComment
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&c))
+		posted = append(posted, c.GetBody())
+		fmt.Fprint(w, `{"id": 100, "html_url": "https://github.com/acme/api/pull/5#issuecomment-100"}`)
+	})
+	mux.HandleFunc("/repos/acme/api/issues/comments/99", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		var c github.IssueComment
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&c))
+		posted = append(posted, c.GetBody())
+		fmt.Fprint(w, `{"id": 99, "html_url": "https://github.com/acme/api/pull/5#issuecomment-99"}`)
+	})
+
+	client := github.NewClient(server.Client())
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return client, &posted
+}
+
+func newPRContextRemediations() *fakeRemediationService {
+	return &fakeRemediationService{records: map[string]*remediation.Remediation{
+		"r1": {ID: "r1", TenantID: "acme", Title: "Refresh expired tokens", AffectedFiles: []string{"middleware/auth.go"}},
+		"r2": {ID: "r2", TenantID: "acme", Title: "Fix @admin check for #12", AffectedFiles: []string{"./middleware/auth.go", "go.mod"}},
+		"r3": {ID: "r3", TenantID: "acme", Title: "Similar path, other file", AffectedFiles: []string{"middleware/cors.go"}},
+		"r4": {ID: "r4", TenantID: "other", Title: "Other tenant", AffectedFiles: []string{"middleware/auth.go"}},
+	}}
+}
+
+func runPRContextWorkflow(t *testing.T, activities *PRContextActivities, config PRContextConfig) *PRContextResult {
+	t.Helper()
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	RegisterPRContextWorkflow(env, activities)
+	env.ExecuteWorkflow(PRContextWorkflow, config)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result PRContextResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	return &result
+}
+
+// TestPRContextWorkflow tests commenting past context on a pull request.
+func TestPRContextWorkflow(t *testing.T) {
+	config := PRContextConfig{Owner: "acme", Repo: "api", PRNumber: 5}
+
+	t.Run("comments remediations and memories per file", func(t *testing.T) {
+		gh, posted := newFakePRGitHub(t, "")
+		memories := &fakeMemorySearcher{memories: []reasoningbank.Memory{
+			{ID: "m1", Title: "Auth middleware ordering", Content: "Register middleware/auth.go before the router."},
+			{ID: "m2", Title: "Unrelated", Content: "Nothing about the changed files."},
+		}}
+		result := runPRContextWorkflow(t, &PRContextActivities{
+			GitHub:      gh,
+			Remediation: newPRContextRemediations(),
+			Memory:      memories,
+		}, config)
+
+		assert.Equal(t, 2, result.FilesSearched, "removed files are skipped")
+		assert.Equal(t, 1, result.FilesMatched)
+		assert.True(t, result.CommentPosted)
+		assert.Equal(t, "https://github.com/acme/api/pull/5#issuecomment-100", result.CommentURL)
+		assert.Equal(t, "api", memories.projectID)
+
+		require.Len(t, *posted, 1)
+		body := (*posted)[0]
+		assert.Contains(t, body, prContextMarker)
+		assert.Contains(t, body, "1 of 2 changed files")
+		assert.Contains(t, body, "2 past fixes touch, 1 memory mentions <code>middleware/auth.go</code>")
+		assert.Contains(t, body, "Refresh expired tokens (`r1`)")
+		assert.Contains(t, body, "Auth middleware ordering (`m1`)")
+		assert.NotContains(t, body, "r3")
+		assert.NotContains(t, body, "Other tenant")
+		assert.NotContains(t, body, "@admin", "mentions are defused")
+	})
+
+	t.Run("updates its earlier comment", func(t *testing.T) {
+		gh, posted := newFakePRGitHub(t, prContextMarker+"\nold")
+		result := runPRContextWorkflow(t, &PRContextActivities{
+			GitHub:      gh,
+			Remediation: newPRContextRemediations(),
+		}, config)
+
+		assert.Equal(t, "https://github.com/acme/api/pull/5#issuecomment-99", result.CommentURL)
+		require.Len(t, *posted, 1)
+		assert.Contains(t, (*posted)[0], "2 past fixes touch <code>middleware/auth.go</code>")
+	})
+
+	t.Run("no comment without context", func(t *testing.T) {
+		gh, posted := newFakePRGitHub(t, "")
+		result := runPRContextWorkflow(t, &PRContextActivities{
+			GitHub:      gh,
+			Remediation: &fakeRemediationService{records: map[string]*remediation.Remediation{}},
+		}, config)
+
+		assert.Equal(t, 0, result.FilesMatched)
+		assert.False(t, result.CommentPosted)
+		assert.Empty(t, *posted)
+	})
+
+	t.Run("max results per file", func(t *testing.T) {
+		gh, posted := newFakePRGitHub(t, "")
+		limited := config
+		limited.MaxResults = 1
+		runPRContextWorkflow(t, &PRContextActivities{
+			GitHub:      gh,
+			Remediation: newPRContextRemediations(),
+		}, limited)
+
+		require.Len(t, *posted, 1)
+		assert.Contains(t, (*posted)[0], "1 past fix touches <code>middleware/auth.go</code>")
+	})
+}
+
+func TestTouchesFile(t *testing.T) {
+	assert.True(t, touchesFile([]string{"middleware/auth.go"}, "middleware/auth.go"))
+	assert.True(t, touchesFile([]string{"./middleware/auth.go"}, "middleware/auth.go"))
+	assert.True(t, touchesFile([]string{"auth.go"}, "middleware/auth.go"), "recorded relative to a subdirectory")
+	assert.True(t, touchesFile([]string{"services/api/middleware/auth.go"}, "middleware/auth.go"))
+	assert.False(t, touchesFile([]string{"middleware/oauth.go"}, "middleware/auth.go"))
+	assert.False(t, touchesFile(nil, "middleware/auth.go"))
+}
@@ -0,0 +1,128 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+)
+
+// RepositoryIndexWorkflow indexes a repository in batches.
+//
+// This workflow:
+// 1. Lists the files to index, pinning the branch for the whole run
+// 2. Splits the list into batches of BatchSize files
+// 3. Reads, embeds and stores each batch in its own activity
+//
+// Completed batches are recorded in the workflow history, so a run that is
+// interrupted by a worker restart resumes with the next batch. Files are
+// stored under stable IDs, so a retried batch replaces what an earlier
+// attempt stored.
+func RepositoryIndexWorkflow(ctx workflow.Context, config RepositoryIndexConfig) (*RepositoryIndexResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidConfig", err)
+	}
+
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting repository indexing", "path", config.Path)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var a *IndexingActivities
+
+	// Step 1: List files
+	var list repository.FileList
+	if err := workflow.ExecuteActivity(ctx, a.ListRepositoryFiles, config).Get(ctx, &list); err != nil {
+		return nil, WrapActivityError("failed to list repository files", err)
+	}
+
+	// Index every batch under the same path and branch, even if HEAD moves
+	config.Path = list.Path
+	config.Branch = list.Branch
+
+	result := &RepositoryIndexResult{
+		Path:        list.Path,
+		Branch:      list.Branch,
+		FilesListed: len(list.Files),
+	}
+	logger.Info("Listed repository files", "files", len(list.Files), "branch", list.Branch)
+
+	// Step 2: Index batches
+	batchSize := config.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultIndexBatchSize
+	}
+	for start := 0; start < len(list.Files); start += batchSize {
+		end := min(start+batchSize, len(list.Files))
+
+		var batch repository.IndexResult
+		err := workflow.ExecuteActivity(ctx, a.IndexFileBatch, IndexFileBatchInput{
+			Config: config,
+			Files:  list.Files[start:end],
+		}).Get(ctx, &batch)
+		if err != nil {
+			return result, WrapActivityError(fmt.Sprintf("failed to index files %d-%d", start+1, end), err)
+		}
+
+		result.Batches++
+		result.FilesIndexed += batch.FilesIndexed
+		result.Preprocessed += batch.Preprocessed
+		result.PreprocessErrors = append(result.PreprocessErrors, batch.PreprocessErrors...)
+		result.CollectionName = batch.CollectionName
+		logger.Info("Indexed batch", "batch", result.Batches, "files_done", end, "files_total", len(list.Files))
+	}
+
+	logger.Info("Repository indexing complete",
+		"files_indexed", result.FilesIndexed,
+		"batches", result.Batches)
+	return result, nil
+}
+
+// IndexingActivities holds the dependencies of the repository indexing
+// activities. Register a pointer to it with the worker.
+type IndexingActivities struct {
+	Repository *repository.Service
+}
+
+// ListRepositoryFiles lists the files a repository indexing run will index.
+func (a *IndexingActivities) ListRepositoryFiles(ctx context.Context, config RepositoryIndexConfig) (*repository.FileList, error) {
+	list, err := a.Repository.ListFiles(ctx, config.Path, config.indexOptions())
+	if err != nil {
+		return nil, activityError(err)
+	}
+	return list, nil
+}
+
+// IndexFileBatch reads, embeds and stores one batch of files.
+func (a *IndexingActivities) IndexFileBatch(ctx context.Context, input IndexFileBatchInput) (*repository.IndexResult, error) {
+	activity.GetLogger(ctx).Info("Indexing batch", "files", len(input.Files))
+
+	result, err := a.Repository.IndexFiles(ctx, input.Config.Path, input.Files, input.Config.indexOptions())
+	if err != nil {
+		return nil, activityError(err)
+	}
+	return result, nil
+}
+
+// indexOptions converts the workflow configuration to repository options.
+func (c *RepositoryIndexConfig) indexOptions() repository.IndexOptions {
+	return repository.IndexOptions{
+		TenantID:        c.TenantID,
+		Branch:          c.Branch,
+		IncludePatterns: c.IncludePatterns,
+		ExcludePatterns: c.ExcludePatterns,
+		MaxFileSize:     c.MaxFileSize,
+		Preprocessors:   c.Preprocessors,
+	}
+}
@@ -0,0 +1,109 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/vectorstore"
+)
+
+// fakeRepositoryStore records the documents stored by the indexing activities.
+type fakeRepositoryStore struct {
+	batches [][]vectorstore.Document
+}
+
+func (s *fakeRepositoryStore) AddDocuments(ctx context.Context, docs []vectorstore.Document) ([]string, error) {
+	s.batches = append(s.batches, docs)
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	return ids, nil
+}
+
+func (s *fakeRepositoryStore) SearchInCollection(ctx context.Context, collectionName string, query string, k int, filters map[string]interface{}) ([]vectorstore.SearchResult, error) {
+	return nil, nil
+}
+
+// TestRepositoryIndexWorkflow tests batched repository indexing.
+func TestRepositoryIndexWorkflow(t *testing.T) {
+	t.Run("indexes files in batches", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"a.go", "b.go", "c.go", "d.go", "e.go"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("package main"), 0o644))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not indexed"), 0o644))
+
+		store := &fakeRepositoryStore{}
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		RegisterKnowledgeWorkflows(env, &IndexingActivities{Repository: repository.NewService(store)}, nil)
+
+		env.ExecuteWorkflow(RepositoryIndexWorkflow, RepositoryIndexConfig{
+			Path:            dir,
+			TenantID:        "testuser",
+			Branch:          "main",
+			IncludePatterns: []string{"*.go"},
+			BatchSize:       2,
+		})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.NoError(t, env.GetWorkflowError())
+
+		var result RepositoryIndexResult
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.Equal(t, 5, result.FilesListed)
+		assert.Equal(t, 5, result.FilesIndexed)
+		assert.Equal(t, 3, result.Batches)
+		assert.Equal(t, "main", result.Branch)
+		assert.NotEmpty(t, result.CollectionName)
+		assert.Len(t, store.batches, 3)
+	})
+
+	t.Run("fails when a batch fails", func(t *testing.T) {
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(RepositoryIndexWorkflow)
+		a := &IndexingActivities{}
+		env.RegisterActivity(a)
+
+		env.OnActivity(a.ListRepositoryFiles, mock.Anything, mock.Anything).Return(&repository.FileList{
+			Path:   "/repo",
+			Branch: "main",
+			Files:  []string{"a.go", "b.go", "c.go"},
+		}, nil)
+		env.OnActivity(a.IndexFileBatch, mock.Anything, mock.MatchedBy(func(input IndexFileBatchInput) bool {
+			return input.Files[0] == "a.go"
+		})).Return(&repository.IndexResult{FilesIndexed: 2}, nil)
+		env.OnActivity(a.IndexFileBatch, mock.Anything, mock.MatchedBy(func(input IndexFileBatchInput) bool {
+			return input.Files[0] == "c.go"
+		})).Return(nil, errors.New("embedder unavailable"))
+
+		env.ExecuteWorkflow(RepositoryIndexWorkflow, RepositoryIndexConfig{Path: "/repo", BatchSize: 2})
+
+		require.True(t, env.IsWorkflowCompleted())
+		err := env.GetWorkflowError()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to index files 3-3")
+	})
+
+	t.Run("rejects missing path", func(t *testing.T) {
+		testSuite := &testsuite.WorkflowTestSuite{}
+		env := testSuite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(RepositoryIndexWorkflow)
+
+		env.ExecuteWorkflow(RepositoryIndexWorkflow, RepositoryIndexConfig{})
+
+		require.True(t, env.IsWorkflowCompleted())
+		require.Error(t, env.GetWorkflowError())
+	})
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 
 	"github.com/fyrsmithlabs/contextd/internal/config"
+	"github.com/fyrsmithlabs/contextd/internal/reasoningbank"
+	"github.com/fyrsmithlabs/contextd/internal/repository"
+	"github.com/fyrsmithlabs/contextd/internal/troubleshoot"
 )
 
 // Common result types
@@ -201,3 +204,222 @@ type GitHubClientConfig struct {
 
 // Note: RetryConfig is defined in github_retry.go
 // Note: WorkflowError is defined in errors.go
+
+// Repository Indexing types
+
+// DefaultIndexBatchSize is the number of files indexed per activity when
+// RepositoryIndexConfig.BatchSize is not set.
+const DefaultIndexBatchSize = 100
+
+// RepositoryIndexConfig configures the repository indexing workflow.
+type RepositoryIndexConfig struct {
+	Path            string   // Repository path on the worker's host
+	TenantID        string   // Tenant identifier (derived from the path if empty)
+	Branch          string   // Branch to index under (auto-detected if empty)
+	IncludePatterns []string // Glob patterns of files to include
+	ExcludePatterns []string // Glob patterns of files to exclude
+	MaxFileSize     int64    // Per-file size limit in bytes (default 1MB)
+	BatchSize       int      // Files per indexing activity (default DefaultIndexBatchSize)
+
+	Preprocessors []repository.PreprocessorRule // Transformations of matching files, after the worker's own rules
+}
+
+// Validate checks that all required fields are set.
+func (c *RepositoryIndexConfig) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("Path is required")
+	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("BatchSize cannot be negative")
+	}
+	return nil
+}
+
+// RepositoryIndexResult contains indexing results.
+type RepositoryIndexResult struct {
+	Path           string // Cleaned repository path
+	Branch         string // Branch the files were indexed under
+	CollectionName string // Collection the files were stored in
+	FilesListed    int    // Files selected for indexing
+	FilesIndexed   int    // Files stored (binary and empty files are skipped)
+	Preprocessed   int    // Files transformed by a preprocessor
+	Batches        int    // Indexing activities run
+
+	PreprocessErrors []string // Failed preprocessors; those files were stored untransformed
+}
+
+// IndexFileBatchInput defines parameters for indexing one batch of files.
+type IndexFileBatchInput struct {
+	Config RepositoryIndexConfig // Run configuration, with Path and Branch resolved
+	Files  []string              // File paths relative to Config.Path
+}
+
+// Memory Consolidation types
+
+// MemoryConsolidationConfig configures the memory consolidation workflow.
+type MemoryConsolidationConfig struct {
+	ProjectID string                             // Project whose memories are consolidated
+	Options   reasoningbank.ConsolidationOptions // Threshold, filters, cluster limit and dry run
+}
+
+// Validate checks that all required fields are set.
+func (c *MemoryConsolidationConfig) Validate() error {
+	if c.ProjectID == "" {
+		return fmt.Errorf("ProjectID is required")
+	}
+	if c.Options.RequireApproval {
+		return fmt.Errorf("RequireApproval is not supported; use memory_consolidate to create proposals")
+	}
+	return nil
+}
+
+// MemoryConsolidationResult contains consolidation results.
+type MemoryConsolidationResult struct {
+	ProjectID        string   // Project that was consolidated
+	ClustersFound    int      // Clusters selected for merging
+	CreatedMemories  []string // IDs of consolidated memories
+	ArchivedMemories []string // IDs of memories archived into them
+	SkippedCount     int      // Memories in clusters that were not merged
+	DryRun           bool     // Whether merging was skipped
+	Errors           []string // Clusters that failed to merge
+}
+
+// ConsolidationCluster is a group of similar memories to merge.
+type ConsolidationCluster struct {
+	MemberIDs         []string // IDs of the memories in the cluster
+	AverageSimilarity float64  // Mean pairwise similarity of the members
+}
+
+// MergeClusterInput defines parameters for merging one cluster.
+type MergeClusterInput struct {
+	ProjectID string   // Project the memories belong to
+	MemberIDs []string // IDs of the memories to merge
+}
+
+// MergeClusterResult represents the outcome of merging one cluster.
+type MergeClusterResult struct {
+	ConsolidatedID string // ID of the consolidated memory
+	Skipped        bool   // Whether the cluster changed and was not merged
+}
+
+// CI failure remediation types
+
+// CIFailureRemediationConfig identifies a failed CI run. Set RunID to
+// diagnose every failed job of a GitHub Actions run, JobID for one Actions
+// job, or CheckOutput for a check run reported by another app.
+type CIFailureRemediationConfig struct {
+	Owner       string // Repository owner (user or org)
+	Repo        string // Repository name
+	HeadSHA     string // Commit the run tested
+	HeadBranch  string // Branch the run tested (optional)
+	RunID       int64  // GitHub Actions workflow run ID
+	JobID       int64  // GitHub Actions job ID
+	CheckName   string // Check run name (checks from other apps)
+	CheckURL    string // Check run URL (checks from other apps)
+	CheckOutput string // Check run summary and text (checks from other apps)
+}
+
+// Validate checks that required fields are set.
+func (c *CIFailureRemediationConfig) Validate() error {
+	if c.Owner == "" {
+		return fmt.Errorf("Owner is required")
+	}
+	if c.Repo == "" {
+		return fmt.Errorf("Repo is required")
+	}
+	if c.HeadSHA == "" {
+		return fmt.Errorf("HeadSHA is required")
+	}
+	if c.RunID <= 0 && c.JobID <= 0 && c.CheckOutput == "" {
+		return fmt.Errorf("one of RunID, JobID or CheckOutput is required")
+	}
+	return nil
+}
+
+// CIFailureRemediationResult contains CI remediation results.
+type CIFailureRemediationResult struct {
+	FailedJobs     int      // Failed jobs found
+	RemediationIDs []string // Pending remediations drafted or already drafted
+	Errors         []string // Jobs that could not be drafted
+}
+
+// CIFailedJob is a failed CI job and the part of its log that explains the
+// failure.
+type CIFailedJob struct {
+	ID         int64    // GitHub Actions job ID (0 for checks from other apps)
+	Name       string   // Job or check name
+	URL        string   // Link to the job or check
+	FailedStep string   // Name of the first failed step
+	Errors     []string // Error lines from the log
+	Excerpt    string   // Log lines leading up to the failure
+}
+
+// DraftCIRemediationInput defines parameters for drafting a remediation.
+type DraftCIRemediationInput struct {
+	Config    CIFailureRemediationConfig // Run the job belongs to
+	Job       CIFailedJob                // Failed job
+	Diagnosis *troubleshoot.Diagnosis    // Diagnosis of the failure (optional)
+}
+
+// PR context types
+
+// PRContextConfig identifies a pull request to comment past context on.
+type PRContextConfig struct {
+	Owner      string // Repository owner (user or org)
+	Repo       string // Repository name
+	PRNumber   int    // Pull request number
+	TenantID   string // Tenant of the remediations to search (defaults to the owner)
+	ProjectID  string // Project of the memories to search (defaults to the repository name)
+	MaxResults int    // Remediations and memories listed per file (default 3)
+}
+
+// Validate checks that required fields are set.
+func (c *PRContextConfig) Validate() error {
+	if c.Owner == "" {
+		return fmt.Errorf("Owner is required")
+	}
+	if c.Repo == "" {
+		return fmt.Errorf("Repo is required")
+	}
+	if c.PRNumber <= 0 {
+		return fmt.Errorf("PRNumber must be positive")
+	}
+	if c.MaxResults < 0 {
+		return fmt.Errorf("MaxResults cannot be negative")
+	}
+	return nil
+}
+
+// PRContextResult contains PR context results.
+type PRContextResult struct {
+	FilesSearched int    // Changed files searched
+	FilesMatched  int    // Changed files with past context
+	CommentPosted bool   // Whether a comment was posted or updated
+	CommentURL    string // URL of the comment
+}
+
+// FindPRContextInput defines parameters for finding context of changed files.
+type FindPRContextInput struct {
+	Config PRContextConfig // Pull request being commented on
+	Files  []string        // Changed file paths
+}
+
+// PRFileContext is the past context found for one changed file.
+type PRFileContext struct {
+	Path         string          // File path relative to repository root
+	Remediations []PRContextItem // Remediations whose fix changed the file
+	Memories     []PRContextItem // Memories that mention the file
+}
+
+// PRContextItem is a remediation or memory listed in a PR comment.
+type PRContextItem struct {
+	ID    string // Remediation or memory ID
+	Title string // Title shown in the comment
+}
+
+// PostPRContextCommentInput defines parameters for posting a PR context comment.
+type PostPRContextCommentInput struct {
+	Config PRContextConfig // Pull request to comment on
+	Files  []PRFileContext // Files with past context
+	Total  int             // Changed files searched
+}
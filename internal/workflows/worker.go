@@ -0,0 +1,70 @@
+package workflows
+
+import (
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+	"go.uber.org/zap"
+
+	ctxerrors "github.com/fyrsmithlabs/contextd/internal/errors"
+)
+
+// DefaultTaskQueue is the task queue contextd's indexing, consolidation, CI
+// remediation and PR context workflows run on.
+const DefaultTaskQueue = "contextd-workflows"
+
+// RegisterKnowledgeWorkflows registers the repository indexing and memory
+// consolidation workflows and their activities with w. Either set of
+// activities may be nil if the service behind it is unavailable; its
+// workflow is then not registered.
+func RegisterKnowledgeWorkflows(w worker.Registry, indexing *IndexingActivities, consolidation *ConsolidationActivities) {
+	if indexing != nil {
+		w.RegisterWorkflow(RepositoryIndexWorkflow)
+		w.RegisterActivity(indexing)
+	}
+	if consolidation != nil {
+		w.RegisterWorkflow(MemoryConsolidationWorkflow)
+		w.RegisterActivity(consolidation)
+	}
+}
+
+// RegisterCIRemediationWorkflow registers the CI failure remediation
+// workflow and its activities with w.
+func RegisterCIRemediationWorkflow(w worker.Registry, ci *CIRemediationActivities) {
+	w.RegisterWorkflow(CIFailureRemediationWorkflow)
+	w.RegisterActivity(ci)
+}
+
+// RegisterPRContextWorkflow registers the PR context workflow and its
+// activities with w.
+func RegisterPRContextWorkflow(w worker.Registry, pr *PRContextActivities) {
+	w.RegisterWorkflow(PRContextWorkflow)
+	w.RegisterActivity(pr)
+}
+
+// activityError marks errors that cannot succeed on retry, such as invalid
+// input or a missing project, as non-retryable so the workflow fails fast
+// instead of retrying them.
+func activityError(err error) error {
+	switch code := ctxerrors.CodeOf(err); code {
+	case ctxerrors.CodeInvalidInput, ctxerrors.CodeNotFound, ctxerrors.CodeUnauthorized, ctxerrors.CodeReadOnly:
+		return temporal.NewNonRetryableApplicationError(err.Error(), string(code), err)
+	default:
+		return err
+	}
+}
+
+// ZapLogger adapts a zap logger for Temporal clients and workers. The SDK's
+// default logger writes to stdout, which is the MCP transport in --mcp mode.
+func ZapLogger(logger *zap.Logger) log.Logger {
+	return zapLogger{logger.WithOptions(zap.AddCallerSkip(1)).Sugar()}
+}
+
+type zapLogger struct {
+	s *zap.SugaredLogger
+}
+
+func (l zapLogger) Debug(msg string, keyvals ...interface{}) { l.s.Debugw(msg, keyvals...) }
+func (l zapLogger) Info(msg string, keyvals ...interface{})  { l.s.Infow(msg, keyvals...) }
+func (l zapLogger) Warn(msg string, keyvals ...interface{})  { l.s.Warnw(msg, keyvals...) }
+func (l zapLogger) Error(msg string, keyvals ...interface{}) { l.s.Errorw(msg, keyvals...) }
@@ -0,0 +1,331 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the OpenTelemetry tracer name of the client.
+const instrumentationName = "github.com/fyrsmithlabs/contextd/pkg/client"
+
+// ProtocolVersion is the contextd protocol this client speaks. Capabilities
+// sends it so the server can report whether the two are compatible.
+const ProtocolVersion = 1
+
+// Defaults for New.
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxRetries  = 2
+	DefaultBaseBackoff = 200 * time.Millisecond
+)
+
+// Client calls the contextd HTTP API. It is safe for concurrent use.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	tenantID    string
+	teamID      string
+	maxRetries  int
+	baseBackoff time.Duration
+	tracer      trace.Tracer
+	propagator  propagation.TextMapPropagator
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client, for example one dialing a Unix socket.
+// The default has a DefaultTimeout timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithTenant sets the tenant and team of requests that do not name their
+// own. An empty team defaults to the tenant on the server.
+func WithTenant(tenantID, teamID string) Option {
+	return func(c *Client) { c.tenantID, c.teamID = tenantID, teamID }
+}
+
+// WithRetries sets how many times a retryable call is retried and the backoff
+// before the first retry, which doubles for each retry after it. Zero
+// retries disables retrying.
+func WithRetries(maxRetries int, baseBackoff time.Duration) Option {
+	return func(c *Client) { c.maxRetries, c.baseBackoff = maxRetries, baseBackoff }
+}
+
+// WithTracerProvider sets the tracer provider for client spans. The default
+// is the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) { c.tracer = tp.Tracer(instrumentationName) }
+}
+
+// New creates a client for the server at baseURL, such as
+// http://localhost:9090.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: must be http(s)://host[:port]", baseURL)
+	}
+
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient:  &http.Client{Timeout: DefaultTimeout},
+		maxRetries:  DefaultMaxRetries,
+		baseBackoff: DefaultBaseBackoff,
+		tracer:      otel.Tracer(instrumentationName),
+		propagator:  otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxRetries < 0 {
+		return nil, fmt.Errorf("max retries must not be negative, got %d", c.maxRetries)
+	}
+	return c, nil
+}
+
+// APIError is a response from the server with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("contextd: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsStatus reports whether err is an *APIError with the given status code.
+func IsStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+// SearchMemories returns one page of a project's memories matching a query.
+func (c *Client) SearchMemories(ctx context.Context, req MemorySearchRequest) (*MemorySearchResponse, error) {
+	var resp MemorySearchResponse
+	if err := c.do(ctx, "SearchMemories", http.MethodPost, "/api/v1/memories/search", req, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SearchRemediations returns one page of remediations matching an error.
+func (c *Client) SearchRemediations(ctx context.Context, req RemediationSearchRequest) (*RemediationSearchResponse, error) {
+	req.TenantID, req.TeamID = c.tenant(req.TenantID, req.TeamID)
+	var resp RemediationSearchResponse
+	if err := c.do(ctx, "SearchRemediations", http.MethodPost, "/api/v1/remediations/search", req, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SaveCheckpoint saves a checkpoint of a session, as the context threshold
+// hook does. It is not retried.
+func (c *Client) SaveCheckpoint(ctx context.Context, req CheckpointSaveRequest) (*CheckpointSaveResponse, error) {
+	if req.Percent == 0 {
+		req.Percent = 100
+	}
+	var resp CheckpointSaveResponse
+	if err := c.do(ctx, "SaveCheckpoint", http.MethodPost, "/api/v1/threshold", req, &resp, false); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AnnotateCheckpoint records how the work of a checkpoint turned out.
+func (c *Client) AnnotateCheckpoint(ctx context.Context, checkpointID string, req CheckpointAnnotateRequest) (*CheckpointAnnotateResponse, error) {
+	if checkpointID == "" {
+		return nil, errors.New("checkpoint ID is required")
+	}
+	req.TenantID, _ = c.tenant(req.TenantID, "")
+	var resp CheckpointAnnotateResponse
+	path := "/api/v1/checkpoints/" + url.PathEscape(checkpointID)
+	if err := c.do(ctx, "AnnotateCheckpoint", http.MethodPatch, path, req, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SynthesizeCheckpoint creates a checkpoint from a recorded conversation that
+// was never checkpointed. It is not retried.
+func (c *Client) SynthesizeCheckpoint(ctx context.Context, req CheckpointSynthesizeRequest) (*CheckpointSynthesizeResponse, error) {
+	req.TenantID, _ = c.tenant(req.TenantID, "")
+	var resp CheckpointSynthesizeResponse
+	if err := c.do(ctx, "SynthesizeCheckpoint", http.MethodPost, "/api/v1/checkpoints/synthesize", req, &resp, false); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Bootstrap returns the context to start a session on a task with: relevant
+// memories, the latest checkpoint, remediations and insights.
+func (c *Client) Bootstrap(ctx context.Context, req BootstrapRequest) (*Bundle, error) {
+	req.TenantID, req.TeamID = c.tenant(req.TenantID, req.TeamID)
+	var resp Bundle
+	if err := c.do(ctx, "Bootstrap", http.MethodPost, "/api/v1/session/bootstrap", req, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Status returns which services the server has available.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var resp Status
+	if err := c.do(ctx, "Status", http.MethodGet, "/api/v1/status", nil, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Capabilities returns the server's version, features, tool schema versions
+// and deprecation notices, negotiated against ProtocolVersion. Check
+// Compatibility before relying on other methods against an unknown server.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	var resp Capabilities
+	path := "/api/v1/capabilities?protocol_version=" + strconv.Itoa(ProtocolVersion)
+	if err := c.do(ctx, "Capabilities", http.MethodGet, path, nil, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// tenant fills an unset tenant and team from the client's.
+func (c *Client) tenant(tenantID, teamID string) (string, string) {
+	if tenantID == "" {
+		tenantID = c.tenantID
+	}
+	if teamID == "" {
+		teamID = c.teamID
+	}
+	return tenantID, teamID
+}
+
+// do sends a request with body in as JSON and decodes the response into out.
+// When retry is set, failures that may be transient are retried.
+func (c *Client) do(ctx context.Context, op, method, path string, in, out any, retry bool) (err error) {
+	ctx, span := c.tracer.Start(ctx, "contextd."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.path", path),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	var body []byte
+	if in != nil {
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("encoding %s request: %w", op, err)
+		}
+	}
+
+	attempts := 1
+	if retry {
+		attempts += c.maxRetries
+	}
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			backoff := c.baseBackoff * time.Duration(1<<(attempt-1))
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		span.SetAttributes(attribute.Int("contextd.client.attempts", attempt+1))
+
+		err = c.send(ctx, method, path, body, out)
+		if err == nil || !retryable(ctx, err) {
+			return err
+		}
+	}
+	return err
+}
+
+// send makes one attempt of a request.
+func (c *Client) send(ctx context.Context, method, path string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// newAPIError reads the error message of a failed response.
+func newAPIError(resp *http.Response) *APIError {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Message != "" {
+		apiErr.Message = body.Message
+	}
+	return apiErr
+}
+
+// retryable reports whether a failed attempt may succeed if repeated:
+// connection errors and overloaded or unavailable servers.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// Anything else before a response, such as a refused connection
+	return true
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithRetries(2, time.Millisecond)}, opts...)
+	c, err := New(server.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "localhost:9090", "ftp://localhost", "http://"} {
+		_, err := New(baseURL)
+		assert.Error(t, err, baseURL)
+	}
+	_, err := New("http://localhost:9090", WithRetries(-1, 0))
+	assert.Error(t, err)
+}
+
+func TestSearchRemediations_PropagatesTenant(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/remediations/search", r.URL.Path)
+		var req RemediationSearchRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "acme", req.TenantID)
+		assert.Equal(t, "platform", req.TeamID)
+		_ = json.NewEncoder(w).Encode(RemediationSearchResponse{
+			Remediations: []Remediation{{ID: "r1", Title: "nil map"}},
+			Count:        1,
+		})
+	}, WithTenant("acme", "platform"))
+
+	resp, err := c.SearchRemediations(context.Background(), RemediationSearchRequest{Query: "assignment to entry in nil map"})
+	require.NoError(t, err)
+	require.Len(t, resp.Remediations, 1)
+	assert.Equal(t, "r1", resp.Remediations[0].ID)
+}
+
+func TestAnnotateCheckpoint_RequestTenantWins(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/api/v1/checkpoints/cp-1", r.URL.Path)
+		var req CheckpointAnnotateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "other", req.TenantID)
+		_ = json.NewEncoder(w).Encode(CheckpointAnnotateResponse{
+			CheckpointID: "cp-1",
+			Annotation:   &Annotation{Outcome: req.Outcome},
+		})
+	}, WithTenant("acme", ""))
+
+	resp, err := c.AnnotateCheckpoint(context.Background(), "cp-1", CheckpointAnnotateRequest{
+		ProjectPath: "/src/api", TenantID: "other", Outcome: "failed",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "failed", resp.Annotation.Outcome)
+
+	_, err = c.AnnotateCheckpoint(context.Background(), "", CheckpointAnnotateRequest{})
+	assert.Error(t, err)
+}
+
+func TestCapabilities_SendsProtocolVersion(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/capabilities", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("protocol_version"))
+		_, _ = w.Write([]byte(`{"server":"contextd","version":"1.2.3","protocol_version":1,"min_protocol_version":1,
+			"features":{"vector_store":"qdrant","llm_providers":["anthropic"]},
+			"tools":[{"name":"memory_search","schema_version":1,"schema_hash":"abc"}],
+			"deprecations":[],"compatibility":{"client_protocol":1,"compatible":true}}`))
+	})
+
+	caps, err := c.Capabilities(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", caps.Version)
+	assert.Equal(t, "qdrant", caps.Features.VectorStore)
+	require.Len(t, caps.Tools, 1)
+	assert.Equal(t, "memory_search", caps.Tools[0].Name)
+	require.NotNil(t, caps.Compatibility)
+	assert.True(t, caps.Compatibility.Compatible)
+}
+
+func TestRetries(t *testing.T) {
+	t.Run("retries unavailable server", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"message":"memory service unavailable"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(MemorySearchResponse{Memories: []Memory{{ID: "m1"}}, Count: 1})
+		})
+
+		resp, err := c.SearchMemories(context.Background(), MemorySearchRequest{ProjectID: "api", Query: "retry"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Count)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"memory service unavailable"}`))
+		})
+
+		_, err := c.SearchMemories(context.Background(), MemorySearchRequest{ProjectID: "api", Query: "retry"})
+		require.Error(t, err)
+		assert.True(t, IsStatus(err, http.StatusServiceUnavailable))
+		assert.Contains(t, err.Error(), "memory service unavailable")
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"project_id and query fields are required"}`))
+		})
+
+		_, err := c.SearchMemories(context.Background(), MemorySearchRequest{})
+		assert.True(t, IsStatus(err, http.StatusBadRequest))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("does not retry checkpoint saves", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			var req CheckpointSaveRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, 100, req.Percent)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		_, err := c.SaveCheckpoint(context.Background(), CheckpointSaveRequest{ProjectID: "api", SessionID: "s1"})
+		assert.True(t, IsStatus(err, http.StatusServiceUnavailable))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestTracing(t *testing.T) {
+	oldPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(oldPropagator)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("traceparent"))
+		_ = json.NewEncoder(w).Encode(Status{Status: "ok", Services: map[string]string{"memory": "ok"}})
+	}, WithTracerProvider(tp))
+
+	status, err := c.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ok", status.Services["memory"])
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "contextd.Status", spans[0].Name())
+}
@@ -0,0 +1,28 @@
+// Package client is a Go client for the contextd HTTP API, for agents and
+// tooling that do not speak MCP, such as custom bots and CI jobs.
+//
+// It wraps the /api/v1 endpoints with typed methods for memories,
+// checkpoints, remediations and session bootstrap, and adds:
+//
+//   - Tenant propagation: WithTenant sets the tenant and team of every
+//     request that does not name its own.
+//   - Retries: reads and idempotent writes are retried with exponential
+//     backoff on connection errors, 429 and 502-504 responses. Requests that
+//     create checkpoints are not retried, so a timeout cannot save twice.
+//   - Tracing: each call is an OpenTelemetry client span, and the trace
+//     context is propagated to the server in the request headers.
+//
+// Writing memories and listing or resuming checkpoints are only available
+// through the MCP tools.
+//
+// # Example
+//
+//	c, err := client.New("http://localhost:9090", client.WithTenant("acme", ""))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	res, err := c.SearchMemories(ctx, client.MemorySearchRequest{
+//	    ProjectID: "api",
+//	    Query:     "retry with backoff",
+//	})
+package client
@@ -0,0 +1,239 @@
+package client
+
+import "time"
+
+// The types below mirror the JSON of the contextd HTTP API; see
+// internal/http/README.md for the field semantics.
+
+// MemorySearchRequest is the request of SearchMemories.
+type MemorySearchRequest struct {
+	ProjectID string   `json:"project_id"`
+	Query     string   `json:"query"`
+	Languages []string `json:"languages,omitempty"`
+	Filter    string   `json:"filter,omitempty"`
+	Limit     int      `json:"limit,omitempty"`
+	Cursor    string   `json:"cursor,omitempty"`
+	SessionID string   `json:"session_id,omitempty"`
+	Inject    bool     `json:"inject,omitempty"`
+	MaxTokens int      `json:"max_tokens,omitempty"`
+}
+
+// Memory is a memory returned by SearchMemories.
+type Memory struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Outcome    string   `json:"outcome"`
+	Confidence float64  `json:"confidence"`
+	Relevance  float64  `json:"relevance"`
+	Tags       []string `json:"tags,omitempty"`
+	Languages  []string `json:"languages,omitempty"`
+	Highlight  string   `json:"highlight,omitempty"`
+}
+
+// MemorySearchResponse is one page of memories. Pass NextCursor as Cursor to
+// fetch the next page; it is empty on the last page.
+type MemorySearchResponse struct {
+	Memories   []Memory   `json:"memories"`
+	Count      int        `json:"count"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	Injection  *Injection `json:"injection,omitempty"`
+}
+
+// Injection is the prompt-ready block of a search made with Inject. Text
+// is empty when no memory fit.
+type Injection struct {
+	Text      string   `json:"text"`
+	MemoryIDs []string `json:"memory_ids"`
+	Omitted   int      `json:"omitted,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Tokens    int      `json:"tokens"`
+}
+
+// RemediationSearchRequest is the request of SearchRemediations.
+type RemediationSearchRequest struct {
+	Query            string  `json:"query"`
+	ProjectPath      string  `json:"project_path,omitempty"`
+	TenantID         string  `json:"tenant_id,omitempty"`
+	TeamID           string  `json:"team_id,omitempty"`
+	Scope            string  `json:"scope,omitempty"`
+	Category         string  `json:"category,omitempty"`
+	MinConfidence    float64 `json:"min_confidence,omitempty"`
+	IncludeHierarchy bool    `json:"include_hierarchy,omitempty"`
+	Limit            int     `json:"limit,omitempty"`
+	Cursor           string  `json:"cursor,omitempty"`
+}
+
+// Remediation is a fix returned by SearchRemediations.
+type Remediation struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Problem    string  `json:"problem"`
+	RootCause  string  `json:"root_cause,omitempty"`
+	Solution   string  `json:"solution"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Score      float64 `json:"score"`
+}
+
+// RemediationSearchResponse is one page of remediations.
+type RemediationSearchResponse struct {
+	Remediations []Remediation `json:"remediations"`
+	Count        int           `json:"count"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+// CheckpointSaveRequest is the request of SaveCheckpoint.
+type CheckpointSaveRequest struct {
+	ProjectID   string `json:"project_id"`
+	SessionID   string `json:"session_id"`
+	Percent     int    `json:"percent"` // Context usage that triggered the save (default: 100)
+	Summary     string `json:"summary,omitempty"`
+	Context     string `json:"context,omitempty"`
+	ProjectPath string `json:"project_path,omitempty"`
+}
+
+// CheckpointSaveResponse is the result of SaveCheckpoint. CheckpointID is
+// empty when the server skipped the save, such as in read-only mode.
+type CheckpointSaveResponse struct {
+	CheckpointID string `json:"checkpoint_id"`
+	Message      string `json:"message"`
+}
+
+// CheckpointAnnotateRequest is the request of AnnotateCheckpoint. Omitted
+// fields keep their current value.
+type CheckpointAnnotateRequest struct {
+	ProjectPath string `json:"project_path"`
+	TenantID    string `json:"tenant_id,omitempty"`
+	Outcome     string `json:"outcome,omitempty"` // succeeded, failed or partial
+	PRURL       string `json:"pr_url,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// Annotation is the recorded outcome of a checkpoint's work.
+type Annotation struct {
+	Outcome     string    `json:"outcome,omitempty"`
+	PRURL       string    `json:"pr_url,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+	AnnotatedAt time.Time `json:"annotated_at"`
+}
+
+// CheckpointAnnotateResponse is the result of AnnotateCheckpoint.
+type CheckpointAnnotateResponse struct {
+	CheckpointID string      `json:"checkpoint_id"`
+	Annotation   *Annotation `json:"annotation"`
+}
+
+// CheckpointSynthesizeRequest is the request of SynthesizeCheckpoint.
+type CheckpointSynthesizeRequest struct {
+	ProjectPath  string `json:"project_path"`
+	TenantID     string `json:"tenant_id,omitempty"`
+	SessionID    string `json:"session_id,omitempty"`
+	MessageStart int    `json:"message_start,omitempty"`
+	MessageEnd   int    `json:"message_end,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Algorithm    string `json:"algorithm,omitempty"`
+}
+
+// CheckpointSynthesizeResponse is the result of SynthesizeCheckpoint.
+type CheckpointSynthesizeResponse struct {
+	CheckpointID string   `json:"checkpoint_id"`
+	SessionID    string   `json:"session_id"`
+	MessageStart int      `json:"message_start"`
+	MessageEnd   int      `json:"message_end"`
+	Summary      string   `json:"summary"`
+	TokenCount   int32    `json:"token_count"`
+	Truncated    bool     `json:"truncated,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// BootstrapRequest is the request of Bootstrap.
+type BootstrapRequest struct {
+	ProjectID        string `json:"project_id"`
+	Task             string `json:"task"`
+	ProjectPath      string `json:"project_path,omitempty"`
+	TenantID         string `json:"tenant_id,omitempty"`
+	TeamID           string `json:"team_id,omitempty"`
+	MemoryLimit      int    `json:"memory_limit,omitempty"`
+	RemediationLimit int    `json:"remediation_limit,omitempty"`
+	InsightLimit     int    `json:"insight_limit,omitempty"`
+}
+
+// Bundle is the session-start context returned by Bootstrap. Sections the
+// server could not build are listed in Warnings.
+type Bundle struct {
+	ProjectID    string             `json:"project_id"`
+	Task         string             `json:"task"`
+	Memories     []Memory           `json:"memories"`
+	Checkpoint   *CheckpointSummary `json:"checkpoint,omitempty"`
+	Remediations []Remediation      `json:"remediations"`
+	Insights     []map[string]any   `json:"insights"`
+	Warnings     []string           `json:"warnings,omitempty"`
+	GeneratedAt  time.Time          `json:"generated_at"`
+}
+
+// CheckpointSummary summarizes a project's most recent checkpoint.
+type CheckpointSummary struct {
+	ID         string    `json:"id"`
+	SessionID  string    `json:"session_id"`
+	Name       string    `json:"name"`
+	Summary    string    `json:"summary"`
+	TokenCount int32     `json:"token_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Status is the server's service availability, returned by Status.
+type Status struct {
+	Status   string            `json:"status"`
+	Version  string            `json:"version,omitempty"`
+	Services map[string]string `json:"services"`
+	Counts   struct {
+		Checkpoints int `json:"checkpoints"`
+		Memories    int `json:"memories"`
+	} `json:"counts"`
+	ReadOnly *struct {
+		Enabled bool   `json:"enabled"`
+		Reason  string `json:"reason,omitempty"`
+	} `json:"read_only,omitempty"`
+}
+
+// Capabilities is the response of Capabilities.
+type Capabilities struct {
+	Server             string `json:"server"`
+	Version            string `json:"version"`
+	ProtocolVersion    int    `json:"protocol_version"`
+	MinProtocolVersion int    `json:"min_protocol_version"`
+	Features           struct {
+		VectorStore   string   `json:"vector_store"`
+		Embeddings    string   `json:"embeddings"`
+		LLMProviders  []string `json:"llm_providers"`
+		AuthMode      string   `json:"auth_mode,omitempty"`
+		AdminToken    bool     `json:"admin_token"`
+		ReadOnly      bool     `json:"read_only"`
+		Analytics     bool     `json:"analytics"`
+		Conversations bool     `json:"conversations"`
+		Folding       bool     `json:"folding"`
+		Consolidation bool     `json:"consolidation"`
+		Workflows     bool     `json:"workflows"`
+	} `json:"features"`
+	Tools []struct {
+		Name          string `json:"name"`
+		SchemaVersion int    `json:"schema_version"`
+		SchemaHash    string `json:"schema_hash"`
+		Deprecated    bool   `json:"deprecated,omitempty"`
+	} `json:"tools,omitempty"`
+	Deprecations []struct {
+		Kind        string `json:"kind"`
+		Name        string `json:"name"`
+		Since       string `json:"since"`
+		RemovedIn   string `json:"removed_in,omitempty"`
+		Replacement string `json:"replacement,omitempty"`
+		Notice      string `json:"notice"`
+	} `json:"deprecations"`
+	Compatibility *struct {
+		ClientProtocol int    `json:"client_protocol"`
+		Compatible     bool   `json:"compatible"`
+		Reason         string `json:"reason,omitempty"`
+	} `json:"compatibility,omitempty"`
+}
@@ -33,6 +33,27 @@ type MemoryResult struct {
 	Tags       []string `json:"tags"`
 }
 
+// KnowledgeClient is implemented by clients that can also create and query
+// checkpoints and remediations. It enables the checkpoint and remediation
+// scenario actions and the memory-bank state assertions that inspect the
+// service after a scenario has run.
+type KnowledgeClient interface {
+	CheckpointSave(ctx context.Context, projectID, sessionID, name, summary string) (string, error)
+	CheckpointCount(ctx context.Context, projectID, sessionID string) (int, error)
+	RemediationRecord(ctx context.Context, projectID, title, problem, solution, category string) (string, error)
+	RemediationSearch(ctx context.Context, projectID, query string, limit int) ([]RemediationResult, error)
+}
+
+// RemediationResult represents a remediation returned from contextd.
+type RemediationResult struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Problem    string  `json:"problem"`
+	Solution   string  `json:"solution"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+}
+
 // LLMClient defines the interface for LLM interactions.
 // Allows swapping Claude for other models or mocks.
 type LLMClient interface {
@@ -195,6 +216,44 @@ func (a *Agent) ReportOutcome(ctx context.Context, memoryID string, succeeded bo
 	return newConfidence, nil
 }
 
+// SaveCheckpoint saves a checkpoint for the agent's session.
+func (a *Agent) SaveCheckpoint(ctx context.Context, name, summary string) (string, error) {
+	kc, ok := a.client.(KnowledgeClient)
+	if !ok {
+		return "", fmt.Errorf("client does not support checkpoints")
+	}
+
+	id, err := kc.CheckpointSave(ctx, a.projectID, a.sessionID, name, summary)
+	if err != nil {
+		return "", fmt.Errorf("saving checkpoint: %w", err)
+	}
+
+	a.logger.Info("saved checkpoint",
+		zap.String("checkpoint_id", id),
+		zap.String("name", name))
+
+	return id, nil
+}
+
+// RecordRemediation records an error fix.
+func (a *Agent) RecordRemediation(ctx context.Context, title, problem, solution, category string) (string, error) {
+	kc, ok := a.client.(KnowledgeClient)
+	if !ok {
+		return "", fmt.Errorf("client does not support remediations")
+	}
+
+	id, err := kc.RemediationRecord(ctx, a.projectID, title, problem, solution, category)
+	if err != nil {
+		return "", fmt.Errorf("recording remediation: %w", err)
+	}
+
+	a.logger.Info("recorded remediation",
+		zap.String("remediation_id", id),
+		zap.String("title", title))
+
+	return id, nil
+}
+
 // ShouldGiveFeedback decides if feedback should be given based on persona.
 func (a *Agent) ShouldGiveFeedback() bool {
 	switch a.persona.FeedbackStyle {
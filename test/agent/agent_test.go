@@ -206,6 +206,61 @@ func TestRunner_ScriptedScenario(t *testing.T) {
 	}
 }
 
+func TestRunner_StateAssertions(t *testing.T) {
+	ctx := context.Background()
+	client := NewMockContextdClient()
+
+	runner, err := NewRunner(RunnerConfig{
+		Client: client,
+		Logger: zap.NewNop(),
+	})
+	require.NoError(t, err)
+
+	scenario := Scenario{
+		Name:      "state-assertions",
+		Persona:   Persona{Name: "StateTester"},
+		ProjectID: "test-project",
+		Actions: []Action{
+			{Type: "record", Args: map[string]interface{}{"title": "Retry flaky HTTP calls", "content": "Wrap with backoff"}},
+			{Type: "checkpoint", Args: map[string]interface{}{"name": "after-fix", "summary": "Fixed retries"}},
+			{Type: "checkpoint", Args: map[string]interface{}{"name": "wrap-up", "summary": "Done"}},
+			{Type: "remediation", Args: map[string]interface{}{
+				"title":    "Connection reset",
+				"problem":  "ECONNRESET when calling upstream",
+				"solution": "Enable keepalive",
+			}},
+		},
+		Assertions: []Assertion{
+			{Type: "memory_exists", Target: "last"},
+			{Type: "memory_exists", Value: "flaky HTTP"},
+			{Type: "memory_confidence_gte", Target: "last", Value: 0.8},
+			{Type: "checkpoint_count", Target: "session", Value: 2},
+			{Type: "remediation_found_for_query", Target: "ECONNRESET upstream", Value: "Connection reset"},
+		},
+	}
+
+	result, err := runner.RunScenario(ctx, scenario)
+	require.NoError(t, err)
+	for _, ar := range result.Assertions {
+		assert.True(t, ar.Passed, "%s should pass: %s", ar.Assertion.Type, ar.Message)
+	}
+
+	// Failing variants report what was observed
+	scenario.Actions = nil
+	scenario.Assertions = []Assertion{
+		{Type: "checkpoint_count", Value: 5},
+		{Type: "remediation_found_for_query", Target: "segfault"},
+		{Type: "memory_confidence_gte", Target: "missing", Value: 0.5},
+	}
+	result, err = runner.RunScenario(ctx, scenario)
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	for _, ar := range result.Assertions {
+		assert.False(t, ar.Passed, "%s should fail", ar.Assertion.Type)
+		assert.NotEmpty(t, ar.Message)
+	}
+}
+
 func TestMockClient_BayesianBehavior(t *testing.T) {
 	ctx := context.Background()
 	client := NewMockContextdClient()
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Cassette is a recorded sequence of MCP tool calls and their responses.
+// Cassettes are captured against a live contextd server and replayed in CI
+// so scenarios run deterministically without a server or embedding model.
+type Cassette struct {
+	// Scenario names the scenario (or run) this cassette was recorded from.
+	Scenario string `json:"scenario,omitempty"`
+
+	// RecordedAt is when the cassette was captured.
+	RecordedAt time.Time `json:"recorded_at"`
+
+	// Interactions are the tool calls in the order they were made.
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded tool call.
+type Interaction struct {
+	Tool     string                 `json:"tool"`
+	Args     map[string]interface{} `json:"args"`
+	Response map[string]interface{} `json:"response,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// LoadCassette reads a cassette from a JSON file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unmarshal cassette: %w", err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to a JSON file.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder wraps a live ToolCaller and captures every call to a cassette.
+type Recorder struct {
+	next ToolCaller
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecorder creates a recorder that forwards calls to next.
+func NewRecorder(next ToolCaller) *Recorder {
+	return &Recorder{
+		next: next,
+		cassette: &Cassette{
+			RecordedAt:   time.Now().UTC(),
+			Interactions: make([]Interaction, 0),
+		},
+	}
+}
+
+// CallTool implements ToolCaller.
+func (r *Recorder) CallTool(ctx context.Context, name string, args map[string]interface{}) (map[string]interface{}, error) {
+	result, err := r.next.CallTool(ctx, name, args)
+
+	// Round-trip through JSON so the recorded values have the same types
+	// a replay will produce (e.g. []string tags become []interface{}).
+	interaction := Interaction{
+		Tool:     name,
+		Args:     normalizeJSON(args),
+		Response: normalizeJSON(result),
+	}
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return result, err
+}
+
+// Cassette returns the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := *r.cassette
+	out.Interactions = append([]Interaction(nil), r.cassette.Interactions...)
+	return &out
+}
+
+// DefaultReplayIgnoredArgs lists arguments that legitimately differ between
+// recording and replay runs and are excluded from argument matching.
+var DefaultReplayIgnoredArgs = []string{"session_id", "session_date"}
+
+// ErrCassetteExhausted is returned when a replay makes more calls than were recorded.
+var ErrCassetteExhausted = errors.New("cassette exhausted")
+
+// Replayer serves tool responses from a cassette in recorded order, failing
+// if a call's tool name or arguments diverge from the recording.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	pos      int
+	ignored  map[string]bool
+}
+
+// NewReplayer creates a replayer for the cassette. Arguments named in
+// ignoredArgs are not compared; nil uses DefaultReplayIgnoredArgs.
+func NewReplayer(cassette *Cassette, ignoredArgs []string) *Replayer {
+	if ignoredArgs == nil {
+		ignoredArgs = DefaultReplayIgnoredArgs
+	}
+	ignored := make(map[string]bool, len(ignoredArgs))
+	for _, a := range ignoredArgs {
+		ignored[a] = true
+	}
+	return &Replayer{cassette: cassette, ignored: ignored}
+}
+
+// CallTool implements ToolCaller.
+func (r *Replayer) CallTool(ctx context.Context, name string, args map[string]interface{}) (map[string]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pos >= len(r.cassette.Interactions) {
+		return nil, fmt.Errorf("%w: unexpected call %d to %s", ErrCassetteExhausted, r.pos, name)
+	}
+
+	want := r.cassette.Interactions[r.pos]
+	idx := r.pos
+	r.pos++
+
+	if want.Tool != name {
+		return nil, fmt.Errorf("replay mismatch at call %d: expected tool %s, got %s", idx, want.Tool, name)
+	}
+
+	got := r.stripIgnored(normalizeJSON(args))
+	expected := r.stripIgnored(want.Args)
+	if !reflect.DeepEqual(got, expected) {
+		return nil, fmt.Errorf("replay mismatch at call %d (%s): expected args %v, got %v", idx, name, expected, got)
+	}
+
+	if want.Error != "" {
+		return nil, errors.New(want.Error)
+	}
+	return want.Response, nil
+}
+
+// Remaining returns the number of recorded interactions not yet replayed.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cassette.Interactions) - r.pos
+}
+
+func (r *Replayer) stripIgnored(args map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if !r.ignored[k] {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// normalizeJSON round-trips a map through JSON so values compare equal
+// regardless of their original Go types.
+func normalizeJSON(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return m
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return m
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeServer is a ToolCaller that answers memory tools deterministically.
+type fakeServer struct {
+	nextID int
+}
+
+func (f *fakeServer) CallTool(ctx context.Context, name string, args map[string]interface{}) (map[string]interface{}, error) {
+	switch name {
+	case "memory_record":
+		f.nextID++
+		return map[string]interface{}{
+			"id":         fmt.Sprintf("mem-%d", f.nextID),
+			"title":      args["title"],
+			"confidence": 0.8,
+		}, nil
+	case "memory_search":
+		return map[string]interface{}{
+			"memories": []interface{}{
+				map[string]interface{}{"id": "mem-1", "title": "Pattern", "confidence": 0.8},
+			},
+			"count": 1,
+		}, nil
+	case "memory_feedback":
+		return map[string]interface{}{"memory_id": args["memory_id"], "new_confidence": 0.85}, nil
+	default:
+		return nil, fmt.Errorf("unknown tool %s", name)
+	}
+}
+
+func recordingScenario() Scenario {
+	return Scenario{
+		Name:      "cassette",
+		Persona:   Persona{Name: "Recorder"},
+		ProjectID: "test-project",
+		Actions: []Action{
+			{Type: "record", Args: map[string]interface{}{"title": "Pattern", "content": "Use table tests"}},
+			{Type: "search", Args: map[string]interface{}{"query": "tests"}},
+			{Type: "feedback", Args: map[string]interface{}{"memory_id": "last", "helpful": true}},
+		},
+		Assertions: []Assertion{
+			{Type: "tool_called", Target: "memory_record", Value: map[string]interface{}{"title": "Pattern"}},
+			{Type: "tool_response", Target: "memory_feedback", Value: map[string]interface{}{"new_confidence": 0.85}},
+			{Type: "confidence_increased", Target: "last"},
+		},
+	}
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+
+	// Record against the fake server
+	recorder := NewRecorder(&fakeServer{})
+	runner, err := NewRunner(RunnerConfig{Client: NewMCPClient(recorder), Logger: zap.NewNop()})
+	require.NoError(t, err)
+
+	result, err := runner.RunScenario(ctx, recordingScenario())
+	require.NoError(t, err)
+	require.True(t, result.Passed, "recording run should pass: %+v", result.Assertions)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recorder.Cassette().Save(path))
+
+	// Replay without the server
+	cassette, err := LoadCassette(path)
+	require.NoError(t, err)
+	require.Len(t, cassette.Interactions, 3)
+
+	replayer := NewReplayer(cassette, nil)
+	runner, err = NewRunner(RunnerConfig{Client: NewMCPClient(replayer), Logger: zap.NewNop()})
+	require.NoError(t, err)
+
+	result, err = runner.RunScenario(ctx, recordingScenario())
+	require.NoError(t, err)
+	assert.True(t, result.Passed, "replay run should pass: %+v", result.Assertions)
+	assert.Equal(t, 0, replayer.Remaining())
+}
+
+func TestReplayer_Mismatch(t *testing.T) {
+	ctx := context.Background()
+	cassette := &Cassette{
+		Interactions: []Interaction{
+			{Tool: "memory_feedback", Args: map[string]interface{}{"memory_id": "mem-1", "helpful": true}},
+		},
+	}
+
+	t.Run("wrong tool", func(t *testing.T) {
+		_, err := NewReplayer(cassette, nil).CallTool(ctx, "memory_search", nil)
+		assert.ErrorContains(t, err, "expected tool memory_feedback")
+	})
+
+	t.Run("wrong args", func(t *testing.T) {
+		_, err := NewReplayer(cassette, nil).CallTool(ctx, "memory_feedback",
+			map[string]interface{}{"memory_id": "mem-1", "helpful": false})
+		assert.ErrorContains(t, err, "expected args")
+	})
+
+	t.Run("ignored args", func(t *testing.T) {
+		_, err := NewReplayer(cassette, nil).CallTool(ctx, "memory_feedback",
+			map[string]interface{}{"memory_id": "mem-1", "helpful": true, "session_id": "new-session"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		r := NewReplayer(cassette, nil)
+		_, err := r.CallTool(ctx, "memory_feedback", map[string]interface{}{"memory_id": "mem-1", "helpful": true})
+		require.NoError(t, err)
+		_, err = r.CallTool(ctx, "memory_feedback", nil)
+		assert.ErrorIs(t, err, ErrCassetteExhausted)
+	})
+}
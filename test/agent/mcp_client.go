@@ -0,0 +1,386 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolCaller invokes a single MCP tool by name and returns its structured
+// result. It is the seam that lets scenarios run against a live server, a
+// recording proxy, or a cassette replay without changing the agent.
+type ToolCaller interface {
+	CallTool(ctx context.Context, name string, args map[string]interface{}) (map[string]interface{}, error)
+}
+
+// SessionCaller calls tools on a live MCP client session.
+type SessionCaller struct {
+	session *mcp.ClientSession
+}
+
+// ConnectCommand starts a contextd MCP server as a subprocess (stdio
+// transport) and returns a caller bound to it. The command line is split on
+// whitespace, e.g. "contextd --mcp --no-http".
+func ConnectCommand(ctx context.Context, command string) (*SessionCaller, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("server command is required")
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{
+		Name:    "contextd-testagent",
+		Version: "1.0.0",
+	}, nil)
+
+	transport := &mcp.CommandTransport{Command: exec.Command(fields[0], fields[1:]...)}
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to MCP server: %w", err)
+	}
+
+	return &SessionCaller{session: session}, nil
+}
+
+// CallTool implements ToolCaller.
+func (c *SessionCaller) CallTool(ctx context.Context, name string, args map[string]interface{}) (map[string]interface{}, error) {
+	res, err := c.session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      name,
+		Arguments: args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("calling %s: %w", name, err)
+	}
+
+	if res.IsError {
+		return nil, fmt.Errorf("%s: %s", name, textContent(res))
+	}
+
+	return structuredContent(res)
+}
+
+// Close terminates the MCP session and the server subprocess.
+func (c *SessionCaller) Close() error {
+	return c.session.Close()
+}
+
+// textContent joins the text parts of a tool result.
+func textContent(res *mcp.CallToolResult) string {
+	parts := make([]string, 0, len(res.Content))
+	for _, c := range res.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			parts = append(parts, tc.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// structuredContent normalizes a tool result's structured output to a map.
+func structuredContent(res *mcp.CallToolResult) (map[string]interface{}, error) {
+	if res.StructuredContent == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling structured content: %w", err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decoding structured content: %w", err)
+	}
+	return out, nil
+}
+
+// MCPClient implements ContextdClient by calling contextd MCP tools.
+// Every call is logged so scenarios can assert on tool arguments and
+// responses after they run.
+type MCPClient struct {
+	caller      ToolCaller
+	projectPath string
+
+	mu    sync.RWMutex
+	calls []ToolCall
+
+	// memories caches the last known state of each memory observed through
+	// tool responses, since contextd has no memory_get tool.
+	memories map[string]*MemoryResult
+}
+
+// NewMCPClient creates a ContextdClient backed by the given tool caller.
+func NewMCPClient(caller ToolCaller) *MCPClient {
+	return &MCPClient{
+		caller:      caller,
+		projectPath: ".",
+		calls:       make([]ToolCall, 0),
+		memories:    make(map[string]*MemoryResult),
+	}
+}
+
+// SetProjectPath sets the project_path sent with checkpoint and remediation
+// tools, which contextd validates against the filesystem. Defaults to ".".
+func (c *MCPClient) SetProjectPath(path string) {
+	c.projectPath = path
+}
+
+// call invokes a tool and appends it to the call log.
+func (c *MCPClient) call(ctx context.Context, name string, args map[string]interface{}) (map[string]interface{}, error) {
+	result, err := c.caller.CallTool(ctx, name, args)
+
+	tc := ToolCall{Name: name, Args: args}
+	if err != nil {
+		tc.Error = err.Error()
+	} else {
+		tc.Result = result
+	}
+
+	c.mu.Lock()
+	c.calls = append(c.calls, tc)
+	c.mu.Unlock()
+
+	return result, err
+}
+
+// ToolCalls returns a copy of every tool call made through this client.
+func (c *MCPClient) ToolCalls() []ToolCall {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]ToolCall, len(c.calls))
+	copy(out, c.calls)
+	return out
+}
+
+// MemoryRecord calls memory_record.
+func (c *MCPClient) MemoryRecord(ctx context.Context, projectID, title, content, outcome string, tags []string) (string, float64, error) {
+	args := map[string]interface{}{
+		"project_id": projectID,
+		"title":      title,
+		"content":    content,
+		"outcome":    outcome,
+	}
+	if len(tags) > 0 {
+		args["tags"] = tags
+	}
+
+	result, err := c.call(ctx, "memory_record", args)
+	if err != nil {
+		return "", 0, err
+	}
+
+	id, _ := result["id"].(string)
+	if id == "" {
+		return "", 0, fmt.Errorf("memory_record returned no id")
+	}
+	confidence, _ := result["confidence"].(float64)
+
+	c.mu.Lock()
+	c.memories[id] = &MemoryResult{
+		ID:         id,
+		Title:      title,
+		Content:    content,
+		Outcome:    outcome,
+		Confidence: confidence,
+		Tags:       tags,
+	}
+	c.mu.Unlock()
+
+	return id, confidence, nil
+}
+
+// MemorySearch calls memory_search.
+func (c *MCPClient) MemorySearch(ctx context.Context, projectID, query string, limit int) ([]MemoryResult, error) {
+	result, err := c.call(ctx, "memory_search", map[string]interface{}{
+		"project_id": projectID,
+		"query":      query,
+		"limit":      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, _ := result["memories"].([]interface{})
+	memories := make([]MemoryResult, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		mem := MemoryResult{}
+		mem.ID, _ = m["id"].(string)
+		mem.Title, _ = m["title"].(string)
+		mem.Content, _ = m["content"].(string)
+		mem.Outcome, _ = m["outcome"].(string)
+		mem.Confidence, _ = m["confidence"].(float64)
+		if tags, ok := m["tags"].([]interface{}); ok {
+			for _, t := range tags {
+				if s, ok := t.(string); ok {
+					mem.Tags = append(mem.Tags, s)
+				}
+			}
+		}
+		memories = append(memories, mem)
+
+		c.mu.Lock()
+		cached := mem
+		c.memories[mem.ID] = &cached
+		c.mu.Unlock()
+	}
+
+	return memories, nil
+}
+
+// MemoryFeedback calls memory_feedback.
+func (c *MCPClient) MemoryFeedback(ctx context.Context, memoryID string, helpful bool) (float64, error) {
+	result, err := c.call(ctx, "memory_feedback", map[string]interface{}{
+		"memory_id": memoryID,
+		"helpful":   helpful,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	confidence, _ := result["new_confidence"].(float64)
+	c.updateConfidence(memoryID, confidence)
+	return confidence, nil
+}
+
+// MemoryOutcome calls memory_outcome.
+func (c *MCPClient) MemoryOutcome(ctx context.Context, memoryID string, succeeded bool, sessionID string) (float64, error) {
+	args := map[string]interface{}{
+		"memory_id": memoryID,
+		"succeeded": succeeded,
+	}
+	if sessionID != "" {
+		args["session_id"] = sessionID
+	}
+
+	result, err := c.call(ctx, "memory_outcome", args)
+	if err != nil {
+		return 0, err
+	}
+
+	confidence, _ := result["new_confidence"].(float64)
+	c.updateConfidence(memoryID, confidence)
+	return confidence, nil
+}
+
+// GetMemory returns the last observed state of a memory.
+func (c *MCPClient) GetMemory(ctx context.Context, memoryID string) (*MemoryResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	mem, ok := c.memories[memoryID]
+	if !ok {
+		return nil, fmt.Errorf("memory not found: %s", memoryID)
+	}
+	out := *mem
+	return &out, nil
+}
+
+func (c *MCPClient) updateConfidence(memoryID string, confidence float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if mem, ok := c.memories[memoryID]; ok {
+		mem.Confidence = confidence
+		return
+	}
+	c.memories[memoryID] = &MemoryResult{ID: memoryID, Confidence: confidence}
+}
+
+// CheckpointSave calls checkpoint_save.
+func (c *MCPClient) CheckpointSave(ctx context.Context, projectID, sessionID, name, summary string) (string, error) {
+	result, err := c.call(ctx, "checkpoint_save", map[string]interface{}{
+		"tenant_id":    projectID,
+		"project_path": c.projectPath,
+		"session_id":   sessionID,
+		"name":         name,
+		"summary":      summary,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result["id"].(string)
+	return id, nil
+}
+
+// CheckpointCount calls checkpoint_list and returns the number of
+// checkpoints, optionally filtered to a session.
+func (c *MCPClient) CheckpointCount(ctx context.Context, projectID, sessionID string) (int, error) {
+	args := map[string]interface{}{
+		"tenant_id":    projectID,
+		"project_path": c.projectPath,
+		"limit":        100,
+	}
+	if sessionID != "" {
+		args["session_id"] = sessionID
+	}
+
+	result, err := c.call(ctx, "checkpoint_list", args)
+	if err != nil {
+		return 0, err
+	}
+
+	count, _ := result["count"].(float64)
+	return int(count), nil
+}
+
+// RemediationRecord calls remediation_record at project scope.
+func (c *MCPClient) RemediationRecord(ctx context.Context, projectID, title, problem, solution, category string) (string, error) {
+	result, err := c.call(ctx, "remediation_record", map[string]interface{}{
+		"tenant_id":    projectID,
+		"project_path": c.projectPath,
+		"scope":        "project",
+		"title":        title,
+		"problem":      problem,
+		"root_cause":   problem,
+		"solution":     solution,
+		"category":     category,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result["id"].(string)
+	return id, nil
+}
+
+// RemediationSearch calls remediation_search at project scope.
+func (c *MCPClient) RemediationSearch(ctx context.Context, projectID, query string, limit int) ([]RemediationResult, error) {
+	result, err := c.call(ctx, "remediation_search", map[string]interface{}{
+		"tenant_id":    projectID,
+		"project_path": c.projectPath,
+		"scope":        "project",
+		"query":        query,
+		"limit":        limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, _ := result["remediations"].([]interface{})
+	remediations := make([]RemediationResult, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rem := RemediationResult{}
+		rem.ID, _ = m["id"].(string)
+		rem.Title, _ = m["title"].(string)
+		rem.Problem, _ = m["problem"].(string)
+		rem.Solution, _ = m["solution"].(string)
+		rem.Category, _ = m["category"].(string)
+		rem.Confidence, _ = m["confidence"].(float64)
+		remediations = append(remediations, rem)
+	}
+
+	return remediations, nil
+}
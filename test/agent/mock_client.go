@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
@@ -19,6 +20,11 @@ type MockContextdClient struct {
 	// Signal tracking for Bayesian simulation
 	signals map[string][]mockSignal
 
+	// Checkpoints and remediations for state assertions
+	checkpoints  []mockCheckpoint
+	remediations []RemediationResult
+	remProjects  map[string]string
+
 	// Configuration
 	initialConfidence float64
 	confidenceStep    float64
@@ -34,6 +40,13 @@ type mockMemory struct {
 	Tags       []string
 }
 
+type mockCheckpoint struct {
+	ID        string
+	ProjectID string
+	SessionID string
+	Name      string
+}
+
 type mockSignal struct {
 	Type     string // "explicit", "usage", "outcome"
 	Positive bool
@@ -44,6 +57,7 @@ func NewMockContextdClient() *MockContextdClient {
 	return &MockContextdClient{
 		memories:          make(map[string]*mockMemory),
 		signals:           make(map[string][]mockSignal),
+		remProjects:       make(map[string]string),
 		initialConfidence: 0.5,  // Bayesian prior
 		confidenceStep:    0.05, // Per-signal adjustment
 	}
@@ -172,6 +186,83 @@ func (m *MockContextdClient) GetMemory(ctx context.Context, memoryID string) (*M
 	}, nil
 }
 
+// CheckpointSave stores a checkpoint.
+func (m *MockContextdClient) CheckpointSave(ctx context.Context, projectID, sessionID, name, summary string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := uuid.New().String()
+	m.checkpoints = append(m.checkpoints, mockCheckpoint{
+		ID:        id,
+		ProjectID: projectID,
+		SessionID: sessionID,
+		Name:      name,
+	})
+	return id, nil
+}
+
+// CheckpointCount counts checkpoints for a project, optionally filtered to a session.
+func (m *MockContextdClient) CheckpointCount(ctx context.Context, projectID, sessionID string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, cp := range m.checkpoints {
+		if cp.ProjectID != projectID {
+			continue
+		}
+		if sessionID != "" && cp.SessionID != sessionID {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// RemediationRecord stores a remediation.
+func (m *MockContextdClient) RemediationRecord(ctx context.Context, projectID, title, problem, solution, category string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := uuid.New().String()
+	m.remediations = append(m.remediations, RemediationResult{
+		ID:         id,
+		Title:      title,
+		Problem:    problem,
+		Solution:   solution,
+		Category:   category,
+		Confidence: 0.5,
+	})
+	m.remProjects[id] = projectID
+	return id, nil
+}
+
+// RemediationSearch returns remediations whose title or problem shares a
+// word with the query. This stands in for semantic similarity.
+func (m *MockContextdClient) RemediationSearch(ctx context.Context, projectID, query string, limit int) ([]RemediationResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	words := strings.Fields(strings.ToLower(query))
+	results := make([]RemediationResult, 0)
+	for _, rem := range m.remediations {
+		if m.remProjects[rem.ID] != projectID {
+			continue
+		}
+		text := strings.ToLower(rem.Title + " " + rem.Problem)
+		for _, w := range words {
+			if strings.Contains(text, w) {
+				results = append(results, rem)
+				break
+			}
+		}
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
 // computeConfidence simulates the Bayesian confidence calculation.
 // This is a simplified version that mimics the real behavior.
 func (m *MockContextdClient) computeConfidence(memoryID string) float64 {
@@ -220,6 +311,9 @@ func (m *MockContextdClient) Reset() {
 
 	m.memories = make(map[string]*mockMemory)
 	m.signals = make(map[string][]mockSignal)
+	m.checkpoints = nil
+	m.remediations = nil
+	m.remProjects = make(map[string]string)
 }
 
 // GetSignalCount returns the number of signals for a memory.
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"go.uber.org/zap"
@@ -173,6 +174,25 @@ func (r *Runner) executeScriptedActions(ctx context.Context, agent *Agent, actio
 				return fmt.Errorf("action %d (outcome): %w", i, err)
 			}
 
+		case "checkpoint":
+			name, _ := action.Args["name"].(string)
+			summary, _ := action.Args["summary"].(string)
+			if _, err := agent.SaveCheckpoint(ctx, name, summary); err != nil {
+				return fmt.Errorf("action %d (checkpoint): %w", i, err)
+			}
+
+		case "remediation":
+			title, _ := action.Args["title"].(string)
+			problem, _ := action.Args["problem"].(string)
+			solution, _ := action.Args["solution"].(string)
+			category, _ := action.Args["category"].(string)
+			if category == "" {
+				category = "runtime"
+			}
+			if _, err := agent.RecordRemediation(ctx, title, problem, solution, category); err != nil {
+				return fmt.Errorf("action %d (remediation): %w", i, err)
+			}
+
 		default:
 			return fmt.Errorf("action %d: unknown type %q", i, action.Type)
 		}
@@ -329,6 +349,151 @@ func (r *Runner) checkAssertion(ctx context.Context, agent *Agent, assertion Ass
 			result.Message = fmt.Sprintf("expected %d feedback events, got %d", expected, actual)
 		}
 
+	case "memory_exists":
+		// Query the service rather than trusting the agent's local view.
+		query, _ := assertion.Value.(string)
+		if query == "" && target != "" {
+			if mem, err := r.client.GetMemory(ctx, target); err == nil {
+				query = mem.Title
+			}
+		}
+		if query == "" {
+			result.Message = "memory_exists requires a target memory or a query value"
+			return result
+		}
+		memories, err := r.client.MemorySearch(ctx, agent.projectID, query, 20)
+		if err != nil {
+			result.Message = fmt.Sprintf("searching memories: %v", err)
+			return result
+		}
+		if target == "" {
+			result.Actual = len(memories)
+			result.Passed = len(memories) > 0
+		} else {
+			for _, m := range memories {
+				if m.ID == target {
+					result.Passed = true
+					break
+				}
+			}
+			result.Actual = target
+		}
+		if !result.Passed {
+			result.Message = fmt.Sprintf("no memory found for query %q", query)
+		}
+
+	case "memory_confidence_gte":
+		threshold, ok := assertion.Value.(float64)
+		if !ok {
+			result.Message = "invalid threshold value"
+			return result
+		}
+		confidence, found := r.liveConfidence(ctx, agent, target)
+		if !found {
+			result.Message = fmt.Sprintf("memory %s not found", target)
+			return result
+		}
+		result.Actual = confidence
+		result.Passed = confidence >= threshold
+		if !result.Passed {
+			result.Message = fmt.Sprintf("confidence %.4f below threshold %.4f", confidence, threshold)
+		}
+
+	case "checkpoint_count":
+		kc, ok := r.client.(KnowledgeClient)
+		if !ok {
+			result.Message = "client does not support checkpoints"
+			return result
+		}
+		expected, ok := assertion.Value.(int)
+		if !ok {
+			if f, ok := assertion.Value.(float64); ok {
+				expected = int(f)
+			} else {
+				result.Message = "invalid expected count"
+				return result
+			}
+		}
+		// Target "session" restricts the count to this scenario's session
+		sessionID := ""
+		if assertion.Target == "session" {
+			sessionID = agent.sessionID
+		}
+		actual, err := kc.CheckpointCount(ctx, agent.projectID, sessionID)
+		if err != nil {
+			result.Message = fmt.Sprintf("listing checkpoints: %v", err)
+			return result
+		}
+		result.Actual = actual
+		result.Passed = actual == expected
+		if !result.Passed {
+			result.Message = fmt.Sprintf("expected %d checkpoints, got %d", expected, actual)
+		}
+
+	case "remediation_found_for_query":
+		kc, ok := r.client.(KnowledgeClient)
+		if !ok {
+			result.Message = "client does not support remediations"
+			return result
+		}
+		if assertion.Target == "" {
+			result.Message = "remediation_found_for_query requires a query target"
+			return result
+		}
+		remediations, err := kc.RemediationSearch(ctx, agent.projectID, assertion.Target, 10)
+		if err != nil {
+			result.Message = fmt.Sprintf("searching remediations: %v", err)
+			return result
+		}
+		// Optional value narrows the match to a remediation title
+		wantTitle, _ := assertion.Value.(string)
+		for _, rem := range remediations {
+			if wantTitle == "" || rem.Title == wantTitle {
+				result.Passed = true
+				result.Actual = rem.ID
+				break
+			}
+		}
+		if !result.Passed {
+			result.Message = fmt.Sprintf("no remediation found for query %q", assertion.Target)
+		}
+
+	case "tool_called", "tool_response":
+		logger, ok := r.client.(ToolCallLogger)
+		if !ok {
+			result.Message = "client does not record tool calls"
+			return result
+		}
+		var expected map[string]interface{}
+		if assertion.Value != nil {
+			expected, ok = assertion.Value.(map[string]interface{})
+			if !ok {
+				result.Message = "invalid expected value (must be an object)"
+				return result
+			}
+			expected = normalizeJSON(expected)
+		}
+		matches := 0
+		for _, call := range logger.ToolCalls() {
+			if call.Name != assertion.Target {
+				continue
+			}
+			if assertion.Type == "tool_called" && containsSubset(normalizeJSON(call.Args), expected) {
+				matches++
+			}
+			if assertion.Type == "tool_response" && call.Error == "" {
+				resp, _ := call.Result.(map[string]interface{})
+				if containsSubset(normalizeJSON(resp), expected) {
+					matches++
+				}
+			}
+		}
+		result.Actual = matches
+		result.Passed = matches > 0
+		if !result.Passed {
+			result.Message = fmt.Sprintf("no %s call matched %v", assertion.Target, expected)
+		}
+
 	default:
 		result.Message = fmt.Sprintf("unknown assertion type: %s", assertion.Type)
 	}
@@ -336,6 +501,53 @@ func (r *Runner) checkAssertion(ctx context.Context, agent *Agent, assertion Ass
 	return result
 }
 
+// liveConfidence re-reads a memory's confidence from the service by searching
+// for its title, falling back to the client's view if search doesn't return it.
+func (r *Runner) liveConfidence(ctx context.Context, agent *Agent, memoryID string) (float64, bool) {
+	mem, err := r.client.GetMemory(ctx, memoryID)
+	if err != nil {
+		return 0, false
+	}
+
+	if memories, err := r.client.MemorySearch(ctx, agent.projectID, mem.Title, 20); err == nil {
+		for _, m := range memories {
+			if m.ID == memoryID {
+				return m.Confidence, true
+			}
+		}
+	}
+	return mem.Confidence, true
+}
+
+// ToolCallLogger is implemented by clients that record MCP tool traffic,
+// enabling tool_called and tool_response assertions.
+type ToolCallLogger interface {
+	ToolCalls() []ToolCall
+}
+
+// containsSubset reports whether every key in want is present in got with a
+// deeply equal value. Nested objects are matched recursively.
+func containsSubset(got, want map[string]interface{}) bool {
+	for k, wv := range want {
+		gv, ok := got[k]
+		if !ok {
+			return false
+		}
+		wm, wIsMap := wv.(map[string]interface{})
+		gm, gIsMap := gv.(map[string]interface{})
+		if wIsMap && gIsMap {
+			if !containsSubset(gm, wm) {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(gv, wv) {
+			return false
+		}
+	}
+	return true
+}
+
 // RunScenarios executes multiple scenarios and aggregates results.
 func (r *Runner) RunScenarios(ctx context.Context, scenarios []Scenario) ([]TestResult, error) {
 	results := make([]TestResult, 0, len(scenarios))
@@ -124,13 +124,19 @@ type Assertion struct {
 	// Type of assertion
 	// Options: "confidence_increased", "confidence_decreased",
 	//          "confidence_above", "confidence_below",
-	//          "memory_count", "weight_shifted"
+	//          "memory_count", "weight_shifted",
+	//          "tool_called", "tool_response",
+	//          "memory_exists", "memory_confidence_gte",
+	//          "checkpoint_count", "remediation_found_for_query"
 	Type string `json:"type"`
 
-	// Target identifies what to check (e.g., memory_id)
+	// Target identifies what to check (e.g., memory_id, or tool name
+	// for tool_called/tool_response)
 	Target string `json:"target,omitempty"`
 
-	// Value for comparison
+	// Value for comparison. For tool_called and tool_response this is an
+	// optional object whose fields must all match the call's arguments or
+	// response respectively.
 	Value interface{} `json:"value,omitempty"`
 
 	// Message to show on failure